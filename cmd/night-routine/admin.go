@@ -0,0 +1,41 @@
+package main
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// debugPathPrefix is where net/http/pprof and expvar register their handlers
+const debugPathPrefix = "/debug/"
+
+// newAdminServer serves the pprof profiles, the expvar runtime variables and the metrics on addr.
+// It is kept off the main server so that profiling is only reachable where the operator chose.
+func newAdminServer(addr string, metrics http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("GET /metrics", metrics)
+
+	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
+
+// hideDebugRoutes answers 404 for the debug routes. Importing net/http/pprof and expvar
+// registers them on http.DefaultServeMux, which the main server uses.
+func hideDebugRoutes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, debugPathPrefix) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
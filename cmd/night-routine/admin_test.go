@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminServer_ServesDebugRoutes(t *testing.T) {
+	srv := newAdminServer("127.0.0.1:0", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+
+	tests := []struct {
+		path         string
+		expectedCode int
+	}{
+		{path: "/debug/pprof/", expectedCode: http.StatusOK},
+		{path: "/debug/pprof/goroutine?debug=1", expectedCode: http.StatusOK},
+		{path: "/debug/vars", expectedCode: http.StatusOK},
+		{path: "/metrics", expectedCode: http.StatusOK},
+		{path: "/", expectedCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestHideDebugRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := hideDebugRoutes(mux)
+
+	tests := []struct {
+		path         string
+		expectedCode int
+	}{
+		{path: "/", expectedCode: http.StatusOK},
+		{path: "/statistics", expectedCode: http.StatusOK},
+		{path: "/debug/pprof/", expectedCode: http.StatusNotFound},
+		{path: "/debug/vars", expectedCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,703 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/alerting"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/consistency"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/features"
+	"github.com/belphemur/night-routine/internal/heartbeat"
+	"github.com/belphemur/night-routine/internal/hooks"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/maintenance"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/belphemur/night-routine/internal/reminder"
+	"github.com/belphemur/night-routine/internal/report"
+	appSignals "github.com/belphemur/night-routine/internal/signals"
+	"github.com/belphemur/night-routine/internal/telemetry"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/belphemur/night-routine/internal/tracing"
+)
+
+// newFlagSet creates the flag set of a subcommand
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("night-routine "+name, flag.ExitOnError)
+}
+
+// loadConfig loads the configuration file pointed to by CONFIG_FILE and applies its log level
+func loadConfig() (*config.Config, error) {
+	logger := logging.GetLogger("main")
+
+	// Get config file path from environment or use default
+	configPath := os.Getenv("CONFIG_FILE")
+	if configPath == "" {
+		configPath = "configs/routine.toml"
+	}
+
+	// Load configuration
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		// Log error before returning, as main's fatal log won't have config context
+		logger.Error().Err(err).Str("config_path", configPath).Msg("Failed to load configuration")
+		return nil, withExitCode(exitConfig, err)
+	}
+
+	// Set log level from configuration
+	logging.SetLogLevel(cfg.Service.LogLevel)
+	logger.Debug().Str("log_level", cfg.Service.LogLevel).Msg("Log level set")
+
+	return cfg, nil
+}
+
+// setupTracing starts exporting traces when enabled in the configuration. The returned
+// function flushes the pending spans and is meant to be deferred.
+func setupTracing(ctx context.Context, cfg *config.Config) (func(), error) {
+	shutdown, err := tracing.Setup(ctx, cfg.Tracing, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	return func() {
+		// The command context is usually cancelled by now, flush with a fresh one
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(flushCtx); err != nil {
+			logger := logging.GetLogger("main")
+			logger.Warn().Err(err).Msg("Failed to flush traces")
+		}
+	}, nil
+}
+
+// setupAlerting escalates repeated sync and webhook failures, and a Google token that can no longer
+// be refreshed, through the notification service. A token that can no longer be refreshed is always
+// logged; the alerts are only registered when a notification channel is configured.
+func setupAlerting(cfg *config.Config, notifications *notify.Service) {
+	logger := logging.GetLogger("main")
+	alertsEnabled := len(notifications.Channels()) > 0
+	appSignals.OnTokenRefreshFailed(func(ctx context.Context, data appSignals.TokenRefreshFailedData) {
+		// The home page banner and /readyz pick the state up from the token manager
+		logger.Error().Err(data.Err).Msg("Google token can no longer be refreshed - reconnect Google Calendar from the home page")
+		if !alertsEnabled {
+			return
+		}
+		// Delivered aside so that the token refresh is never blocked by a slow channel
+		ctx = context.WithoutCancel(ctx)
+		go func() {
+			if err := notifications.Send(ctx, notify.EventTokenRefreshFailed, notify.TokenRefreshFailedData{
+				Error:  data.Err.Error(),
+				AppURL: cfg.App.AppUrl,
+			}); err != nil {
+				logger.Error().Err(err).Msg("Failed to deliver token refresh failure alert")
+			}
+		}()
+	}, "main-token-refresh-failed-handler")
+	if !alertsEnabled {
+		logger.Debug().Msg("No notification channel configured, failure alerts disabled")
+		return
+	}
+
+	monitor := alerting.NewMonitor(notifications, cfg.Notify.FailureThreshold, cfg.Notify.FailureCooldown)
+	appSignals.OnSyncCompleted(func(ctx context.Context, data appSignals.SyncCompletedData) {
+		monitor.Record(ctx, alerting.SourceSync, data.Err)
+	}, "main-sync-failure-alert")
+	appSignals.OnWebhookProcessed(func(ctx context.Context, data appSignals.WebhookProcessedData) {
+		monitor.Record(ctx, alerting.SourceWebhook, data.Err)
+	}, "main-webhook-failure-alert")
+
+	logger.Info().
+		Strs("channels", notifications.Channels()).
+		Int("failure_threshold", cfg.Notify.FailureThreshold).
+		Dur("failure_cooldown", cfg.Notify.FailureCooldown).
+		Msg("Failure alerts enabled")
+}
+
+// setupImbalanceAlert checks the imbalance of the last 30 days after each successful sync and alerts
+// through the notification service once it reaches notify.imbalance_threshold, unless disabled by
+// the imbalance_alert feature flag. Nothing is registered without threshold or notification channel.
+func setupImbalanceAlert(cfg *config.Config, svc *services) {
+	if cfg.Notify.ImbalanceThreshold == 0 || len(svc.notifications.Channels()) == 0 {
+		return
+	}
+	logger := logging.GetLogger("main")
+	monitor := alerting.NewImbalanceMonitor(cfg.Notify.ImbalanceThreshold, svc.tracker, svc.runtimeConfig, svc.notifications, svc.deliveries, cfg.App.AppUrl)
+	appSignals.OnSyncCompleted(func(ctx context.Context, data appSignals.SyncCompletedData) {
+		if data.Err != nil || !svc.features.Enabled(features.ImbalanceAlert) {
+			return
+		}
+		// Checked aside so that the sync is never blocked by a slow channel
+		ctx = context.WithoutCancel(ctx)
+		go func() {
+			if _, err := monitor.Check(ctx); err != nil {
+				logger.Error().Err(err).Msg("Failed to check the imbalance of the last 30 days")
+			}
+		}()
+	}, "main-imbalance-alert")
+
+	logger.Info().Int("imbalance_threshold", cfg.Notify.ImbalanceThreshold).Msg("Imbalance alerts enabled")
+}
+
+// setupAbsenceSuggestion looks for the weekdays a parent is overridden on week after week after each
+// successful sync and suggests marking them unavailable through the notification service, unless
+// disabled by the absence_suggestions feature flag. Nothing is registered without notification channel.
+func setupAbsenceSuggestion(cfg *config.Config, svc *services) {
+	if len(svc.notifications.Channels()) == 0 {
+		return
+	}
+	logger := logging.GetLogger("main")
+	monitor := alerting.NewAbsenceMonitor(svc.tracker, svc.runtimeConfig, svc.notifications, svc.deliveries, cfg.App.AppUrl)
+	appSignals.OnSyncCompleted(func(ctx context.Context, data appSignals.SyncCompletedData) {
+		if data.Err != nil || !svc.features.Enabled(features.AbsenceSuggestions) {
+			return
+		}
+		// Checked aside so that the sync is never blocked by a slow channel
+		ctx = context.WithoutCancel(ctx)
+		go func() {
+			if _, err := monitor.Check(ctx); err != nil {
+				logger.Error().Err(err).Msg("Failed to check the override patterns")
+			}
+		}()
+	}, "main-absence-suggestion")
+
+	logger.Info().Msg("Absence suggestions enabled")
+}
+
+// setupMaintenance runs the heavy background jobs every day within service.maintenance_window until
+// ctx is cancelled: pruning the domain events, sync runs and notification deliveries past their
+// retention, cleaning up the orphaned rows, sending the monthly report when notify.monthly_report is
+// set and the weekly summary to the parents subscribed to it when the email channel is configured, then
+// checkpointing the write-ahead log.
+func setupMaintenance(ctx context.Context, cfg *config.Config, svc *services, db *database.DB) {
+	logger := logging.GetLogger("main")
+	coordinator := maintenance.NewCoordinator(svc.maintenance, svc.syncRuns)
+
+	coordinator.Register("prune_domain_events", func(ctx context.Context) error {
+		_, err := svc.eventStore.PruneEvents(ctx, time.Now())
+		return err
+	})
+	coordinator.Register("prune_sync_runs", func(ctx context.Context) error {
+		_, err := svc.syncRuns.PruneRuns(ctx, time.Now())
+		return err
+	})
+	coordinator.Register("prune_notification_deliveries", func(ctx context.Context) error {
+		_, err := svc.deliveries.PruneDeliveries(ctx, time.Now())
+		return err
+	})
+	coordinator.Register("cleanup_applied_events", func(ctx context.Context) error {
+		_, err := svc.tracker.DeleteOrphanedAppliedEvents(ctx)
+		return err
+	})
+	coordinator.Register("cleanup_notification_channels", func(ctx context.Context) error {
+		return svc.tokenStore.DeleteExpiredNotificationChannels()
+	})
+	if cfg.Notify.MonthlyReport {
+		if len(svc.notifications.Channels()) == 0 {
+			logger.Warn().Msg("Monthly report enabled without notification channel, nothing will be sent")
+		} else {
+			mailer := report.NewMailer(svc.reports, svc.notifications, svc.deliveries, cfg.App.AppUrl)
+			coordinator.Register("monthly_report", mailer.Check)
+			logger.Info().Msg("Monthly report emails enabled")
+		}
+	}
+	if slices.Contains(svc.notifications.Channels(), "email") {
+		coordinator.Register("weekly_summary", report.NewWeeklyMailer(svc.tracker, svc.notifications, svc.deliveries, cfg.App.AppUrl).Check)
+	}
+	coordinator.Register("checkpoint", db.Checkpoint)
+
+	go coordinator.Run(ctx)
+}
+
+// setupDutyReminder notifies the parent on duty every day at notify.reminder_time, and the other
+// parents when the reminder is not acknowledged within the escalation delay of the parent on duty,
+// until ctx is cancelled. Nothing is started without reminder time or notification channel.
+func setupDutyReminder(ctx context.Context, cfg *config.Config, svc *services) {
+	if cfg.Notify.ReminderTime == "" {
+		return
+	}
+	logger := logging.GetLogger("main")
+	if len(svc.notifications.Channels()) == 0 {
+		logger.Warn().Msg("Duty reminder enabled without notification channel, nothing will be sent")
+		return
+	}
+	dutyReminder, err := reminder.NewReminder(cfg.Notify.ReminderTime, svc.tracker, svc.checklists, svc.notifications, svc.deliveries, cfg.App.AppUrl)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to set up duty reminder")
+		return
+	}
+	go dutyReminder.Run(ctx)
+	go reminder.NewEscalator(svc.tracker, svc.checklists, svc.acknowledgements, svc.contacts, svc.runtimeConfig, svc.notifications, svc.deliveries, cfg.App.AppUrl).Run(ctx)
+}
+
+// setupConsistencyCheck repairs the nights of the look-ahead window left without assignment or calendar
+// event every service.consistency_check_interval until ctx is cancelled. Repairs are reported through the
+// notification channels when one is configured.
+func setupConsistencyCheck(ctx context.Context, cfg *config.Config, svc *services) {
+	if cfg.Service.ConsistencyCheckInterval == 0 {
+		return
+	}
+	var sender consistency.Sender
+	if len(svc.notifications.Channels()) > 0 {
+		sender = svc.notifications
+	}
+	checker := consistency.NewChecker(cfg.Service.ConsistencyCheckInterval, svc.tracker, svc.runtimeConfig, scheduleRepairer{svc: svc}, sender)
+	go checker.Run(ctx)
+}
+
+// reviewCheckInterval is how often the nights staged for review are checked for their timeout
+const reviewCheckInterval = time.Minute
+
+// setupReviewTimeout publishes the nights staged for review once they waited schedule.review_timeout,
+// until ctx is cancelled. Nothing is started without review or timeout.
+func setupReviewTimeout(ctx context.Context, cfg *config.Config, svc *services) {
+	if cfg.Schedule.ReviewAfterDays == 0 || cfg.Schedule.ReviewTimeout == 0 {
+		return
+	}
+	logger := logging.GetLogger("review")
+	go func() {
+		ticker := time.NewTicker(reviewCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := publishTimedOutReviews(ctx, svc, now.Add(-cfg.Schedule.ReviewTimeout)); err != nil {
+					logger.Error().Err(err).Msg("Failed to publish the nights staged for review")
+				}
+			}
+		}
+	}()
+	logger.Info().Int("review_after_days", cfg.Schedule.ReviewAfterDays).Dur("review_timeout", cfg.Schedule.ReviewTimeout).Msg("Staged nights published after the review timeout")
+}
+
+// publishTimedOutReviews publishes the nights staged at or before stagedBefore, like the other automatic
+// syncs not during the quiet hours. A publication is recorded in the sync history only when a night is due.
+func publishTimedOutReviews(ctx context.Context, svc *services, stagedBefore time.Time) error {
+	if !svc.calSvc.IsInitialized() || svc.quietHours.Contains(time.Now()) {
+		return nil
+	}
+	staged, err := svc.staging.ListStaged()
+	if err != nil {
+		return fmt.Errorf("failed to list staged assignments: %w", err)
+	}
+	if !slices.ContainsFunc(staged, func(a *database.StagedAssignment) bool { return !a.StagedAt.After(stagedBefore) }) {
+		return nil
+	}
+	return svc.syncRuns.RecordRun(ctx, constants.SyncTriggerPublish, func(ctx context.Context) (int, error) {
+		return svc.calSvc.PublishStaged(ctx, stagedBefore)
+	})
+}
+
+// setupHeartbeat pings the configured heartbeat URL after each successful scheduled sync, so an
+// external monitor notices when the service stops running. Nothing is registered without a URL.
+func setupHeartbeat(cfg *config.Config) {
+	if cfg.Service.HeartbeatURL == "" {
+		return
+	}
+	logger := logging.GetLogger("heartbeat")
+	pinger := heartbeat.NewPinger(cfg.Service.HeartbeatURL)
+
+	appSignals.OnSyncCompleted(func(ctx context.Context, data appSignals.SyncCompletedData) {
+		// Manual, webhook and settings syncs say nothing about the service loop still running
+		if data.Err != nil || (data.Trigger != constants.SyncTriggerScheduled.String() && data.Trigger != constants.SyncTriggerCLI.String()) {
+			return
+		}
+		if err := pinger.Ping(context.WithoutCancel(ctx)); err != nil {
+			logger.Warn().Err(err).Msg("Failed to ping heartbeat URL")
+			return
+		}
+		logger.Debug().Str("trigger", data.Trigger).Msg("Heartbeat pinged")
+	}, "main-heartbeat-ping")
+
+	logger.Info().Msg("Heartbeat pings enabled after scheduled syncs")
+}
+
+// setupTelemetry sends the anonymous usage report of collector every telemetry.interval until ctx is
+// cancelled. Nothing is sent unless telemetry.enabled is set; the report can be previewed either way.
+func setupTelemetry(ctx context.Context, cfg *config.Config, collector *telemetry.Collector) {
+	if !cfg.Telemetry.Enabled {
+		return
+	}
+	reporter := telemetry.NewReporter(cfg.Telemetry.Endpoint, cfg.Telemetry.Interval, collector)
+	go reporter.Run(ctx)
+}
+
+// setupHooks posts the schedule changes published on the event bus to the configured hook URLs.
+// Nothing is subscribed without URL.
+func setupHooks(cfg *config.Config, events *appSignals.Bus) {
+	if len(cfg.Hooks.URLs) == 0 {
+		return
+	}
+	hooks.Register(events, cfg.Hooks.URLs, cfg.Hooks.Secret)
+
+	logger := logging.GetLogger("main")
+	logger.Info().Int("urls", len(cfg.Hooks.URLs)).Msg("Outbound hooks enabled")
+}
+
+// openDatabase opens the state database, creating its directory if needed. Migrations are not applied.
+func openDatabase(cfg *config.Config) (*database.DB, error) {
+	logger := logging.GetLogger("main")
+
+	// Create data directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(cfg.Service.StateFile), 0755); err != nil {
+		logger.Error().Err(err).Str("path", filepath.Dir(cfg.Service.StateFile)).Msg("Failed to create data directory")
+		return nil, err
+	}
+
+	// Construct database options from config and desired settings
+	dbOpts := database.SQLiteOptions{
+		Path:        cfg.Service.StateFile,
+		Mode:        "rwc",                      // Read-Write-Create mode
+		Cache:       database.CacheShared,       // Use shared cache mode
+		Journal:     database.JournalWAL,        // Use WAL journal mode
+		ForeignKeys: true,                       // Enable foreign keys
+		AutoVacuum:  "incremental",              // Use incremental auto-vacuum
+		BusyTimeout: 5000,                       // Default busy timeout (ms)
+		Synchronous: database.SynchronousNormal, // Default synchronous mode
+		// CacheSize: 2000, // Default cache size (KB) - can be added if needed
+		SlowQueryThreshold: cfg.Service.SlowQueryThreshold,
+	}
+	db, err := database.New(dbOpts)
+	if err != nil {
+		// Wrap error for context, logger will handle Err field
+		wrappedErr := fmt.Errorf("failed to initialize database: %w", err)
+		logger.Error().Err(wrappedErr).Str("db_path", cfg.Service.StateFile).Msg("Database initialization failed")
+		return nil, wrappedErr
+	}
+	return db, nil
+}
+
+// migrateDatabase lists the pending migrations and applies them, after a snapshot of the state
+// file with [service] backup_before_migrate. With [service] skip_migrate it applies nothing and
+// fails while migrations are pending.
+func migrateDatabase(ctx context.Context, cfg *config.Config, db *database.DB) error {
+	logger := logging.GetLogger("main")
+
+	status, err := db.GetMigrationStatus()
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to read the migration status: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Migration status check failed")
+		return wrappedErr
+	}
+	if !status.Pending() {
+		logger.Debug().Uint("version", status.Current).Msg("Database schema up to date")
+		return nil
+	}
+	logger.Info().Uint("current", status.Current).Uints("pending", status.Waiting).Msg("Pending database migrations")
+
+	if cfg.Service.SkipMigrate {
+		wrappedErr := fmt.Errorf("%d database migrations pending with skip_migrate set, apply them with 'night-routine migrate'", len(status.Waiting))
+		logger.Error().Err(wrappedErr).Uints("pending", status.Waiting).Msg("Database schema out of date")
+		return wrappedErr
+	}
+
+	// A new database has nothing to keep
+	if cfg.Service.BackupBeforeMigrate && status.Current > 0 {
+		backupPath := defaultBackupPath(cfg, fmt.Sprintf("premigrate-v%d", status.Current))
+		if err := db.Backup(ctx, backupPath); err != nil {
+			wrappedErr := fmt.Errorf("failed to back up the database before migrating: %w", err)
+			logger.Error().Err(wrappedErr).Str("path", backupPath).Msg("Pre-migration backup failed")
+			return wrappedErr
+		}
+		logger.Info().Str("path", backupPath).Msg("Database backed up before migrating")
+	}
+
+	if err := db.MigrateDatabase(); err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize database schema: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Database schema initialization failed")
+		return wrappedErr
+	}
+	return nil
+}
+
+// services holds the components shared by the commands that generate and sync the schedule
+type services struct {
+	configStore   database.ConfigStoreInterface
+	runtimeConfig *config.Cache
+	tracker       *fairness.Tracker
+	monthlyStats  fairness.MonthlyStatsProvider
+	tokenStore    database.TokenStoreInterface
+	tokenManager  *token.TokenManager
+	syncRuns      *database.SyncRunStore
+	deliveries    *database.NotificationDeliveryStore
+	notifications *notify.Service
+	events        *appSignals.Bus
+	checklists    *database.ChecklistStore
+	comments      *database.CommentStore
+	staging       *database.StagingStore
+	parentLinks   *database.ParentLinkStore
+	swapRequests  *database.SwapRequestStore
+	contacts      *database.ParentContactStore
+	// Nights acknowledged by the parent on duty, read by the statistics and the escalation
+	acknowledgements *database.AcknowledgementStore
+	passkeys         *database.PasskeyStore
+	features         *features.Flags // Gate the experimental subsystems, set from the admin API
+	reports          *report.Generator
+	sched            *scheduler.Scheduler
+	calSvc           *calendar.Service
+	eventStore       *database.EventStore
+	quietHours       config.QuietHours // Window during which the automatic syncs are deferred
+	maintenance      config.QuietHours // Daily window of the heavy background jobs
+}
+
+// newServices migrates the database, seeds its configuration and wires the scheduling services
+func newServices(ctx context.Context, cfg *config.Config, db *database.DB) (*services, error) {
+	logger := logging.GetLogger("main")
+
+	// Initialize database schema
+	if err := migrateDatabase(context.Background(), cfg, db); err != nil {
+		return nil, err
+	}
+
+	// Initialize config store for database-backed configuration
+	configStore, err := database.NewConfigStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize config store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Config store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Seed configuration from TOML file to database (runs only once on initial setup or upgrade)
+	configSeeder := database.NewConfigSeeder(configStore)
+	if err := configSeeder.SeedFromConfig(ctx, cfg); err != nil {
+		wrappedErr := fmt.Errorf("failed to seed configuration: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Configuration seeding failed")
+		return nil, wrappedErr
+	}
+
+	// Build the runtime configuration: the single source of truth for all configuration.
+	// DB-backed settings (parents, availability, schedule) are served from memory and
+	// reloaded from the database after every settings write; the static OAuth2 config
+	// is provided here so handlers never need to touch *config.Config directly.
+	runtimeConfig := config.NewCache(database.NewConfigAdapter(configStore, cfg.OAuth))
+	appSignals.OnConfigChanged(func(ctx context.Context, data appSignals.ConfigChangedData) {
+		runtimeConfig.Invalidate()
+	}, "main-config-cache-invalidation")
+
+	parentA, parentB, _ := runtimeConfig.GetParents()
+	extraParents, _ := runtimeConfig.GetExtraParents()
+	logger.Info().
+		Str("parent_a", parentA).
+		Str("parent_b", parentB).
+		Strs("extra_parents", config.ExtraParentNames(extraParents)).
+		Msg("Configuration loaded from database")
+
+	// Initialize fairness tracker
+	tracker, err := fairness.New(db)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to initialize fairness tracker")
+		return nil, err // Return original error
+	}
+
+	// Serve the statistics page from memory, dropping the months of every written assignment
+	var monthlyStats fairness.MonthlyStatsProvider = tracker
+	if cfg.Service.StatsCacheTTL > 0 {
+		statsCache := fairness.NewStatsCache(tracker, cfg.Service.StatsCacheTTL)
+		appSignals.OnAssignmentsChanged(func(ctx context.Context, data appSignals.AssignmentsChangedData) {
+			statsCache.Invalidate(data.Date)
+		}, "main-stats-cache-invalidation")
+		monthlyStats = statsCache
+	}
+
+	// Initialize token store
+	tokenStore, err := database.NewTokenStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize token store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Token store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize token manager on the configured token storage
+	tokenStorage, err := newTokenStorage(cfg, tokenStore)
+	if err != nil {
+		return nil, err
+	}
+	tokenManager := token.NewTokenManager(tokenStorage, cfg.OAuth)
+
+	// Initialize sync run store recording the sync history
+	syncRuns, err := database.NewSyncRunStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize sync run store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Sync run store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the parent contact store, addressing the notifications meant for a parent
+	parentContacts, err := database.NewParentContactStore(db, runtimeConfig)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize parent contact store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Parent contact store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the notification service: the configured channels, enabled from the settings page,
+	// with every delivery recorded and the messages meant for a parent sent through their contact
+	deliveries, err := database.NewNotificationDeliveryStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize notification delivery store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Notification delivery store initialization failed")
+		return nil, wrappedErr
+	}
+	notifications := notify.NewService(notify.New(cfg.Notify), configStore, deliveries, parentContacts)
+
+	// Initialize the event bus: domain events are persisted before reaching the subscribers
+	eventStore, err := database.NewEventStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize event store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Event store initialization failed")
+		return nil, wrappedErr
+	}
+	events := appSignals.NewBus(eventStore)
+	events.PersistSignals()
+
+	// Initialize the checklist store, whose checklists are written in the calendar events
+	checklists, err := database.NewChecklistStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize checklist store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Checklist store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the comment store, whose comments are optionally written in the calendar events
+	comments, err := database.NewCommentStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize comment store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Comment store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the staging store, keeping the regenerated nights waiting for review
+	staging, err := database.NewStagingStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize staging store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Staging store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the parent link store, giving each parent access to their own availability
+	parentLinks, err := database.NewParentLinkStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize parent link store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Parent link store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the swap request store, holding the swaps of nights proposed between the parents
+	swapRequests, err := database.NewSwapRequestStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize swap request store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Swap request store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the acknowledgement store, holding the nights acknowledged by the parent on duty
+	acknowledgements, err := database.NewAcknowledgementStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize acknowledgement store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Acknowledgement store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the passkey store, signing in to the web interface with [app] passkey_login
+	passkeys, err := database.NewPasskeyStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize passkey store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Passkey store initialization failed")
+		return nil, wrappedErr
+	}
+
+	// Initialize the feature flags, gating the experimental subsystems at runtime
+	featureFlags, err := database.NewFeatureFlagStore(db)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize feature flag store: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Feature flag store initialization failed")
+		return nil, wrappedErr
+	}
+	flags := features.New(featureFlags)
+
+	// Create scheduler — reads parents/availability/schedule from the runtime configuration
+	sched := scheduler.New(runtimeConfig, tracker)
+
+	// Validated with the configuration
+	quietHours, err := config.ParseQuietHours(cfg.Service.QuietHours)
+	if err != nil {
+		return nil, withExitCode(exitConfig, err)
+	}
+	maintenanceWindow, err := config.ParseQuietHours(cfg.Service.MaintenanceWindow)
+	if err != nil {
+		return nil, withExitCode(exitConfig, err)
+	}
+
+	// Initialize calendar service without requiring a token
+	branding := calendar.Branding{
+		Emoji:      cfg.Branding.EventEmoji,
+		Identifier: cfg.Branding.EventIdentifier,
+		SourceURL:  cfg.Branding.EventSourceURL,
+	}
+	availability := calendar.AvailabilityCalendars{
+		ParentA: cfg.Availability.ParentACalendarID,
+		ParentB: cfg.Availability.ParentBCalendarID,
+		Keyword: cfg.Availability.CalendarKeyword,
+	}
+	review := calendar.Review{AfterDays: cfg.Schedule.ReviewAfterDays, Staging: staging}
+	calSvc := calendar.New(cfg.OAuth, branding, availability, cfg.App.PublicUrl, tokenStore, sched, tokenManager, checklists, comments, review)
+	calSvc.SetEndpoint(cfg.App.CalendarEndpoint)
+	calSvc.SetFeatureFlags(flags)
+
+	return &services{
+		configStore:      configStore,
+		runtimeConfig:    runtimeConfig,
+		tracker:          tracker,
+		monthlyStats:     monthlyStats,
+		tokenStore:       tokenStore,
+		tokenManager:     tokenManager,
+		syncRuns:         syncRuns,
+		deliveries:       deliveries,
+		notifications:    notifications,
+		events:           events,
+		checklists:       checklists,
+		comments:         comments,
+		staging:          staging,
+		parentLinks:      parentLinks,
+		swapRequests:     swapRequests,
+		contacts:         parentContacts,
+		acknowledgements: acknowledgements,
+		passkeys:         passkeys,
+		features:         flags,
+		reports:          report.NewGenerator(tracker),
+		sched:            sched,
+		calSvc:           calSvc,
+		eventStore:       eventStore,
+		quietHours:       quietHours,
+		maintenance:      maintenanceWindow,
+	}, nil
+}
+
+// newTokenStorage returns the token storage selected by service.token_storage
+func newTokenStorage(cfg *config.Config, tokenStore database.TokenStoreInterface) (token.Storage, error) {
+	logger := logging.GetLogger("main")
+
+	if cfg.Service.TokenStorage != "keyring" {
+		return tokenStore, nil
+	}
+
+	keyringStorage := token.NewKeyringStorage()
+	// Fail fast when no keyring service is reachable (e.g. headless Linux without Secret Service)
+	if _, err := keyringStorage.GetToken(); err != nil {
+		wrappedErr := fmt.Errorf("failed to access OS keyring: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Token storage initialization failed")
+		return nil, wrappedErr
+	}
+	logger.Info().Msg("Storing OAuth token in the OS keyring")
+	return keyringStorage, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+)
+
+// runBackup writes a consistent copy of the state database, safe to run while the server is up
+func runBackup(ctx context.Context, args []string) error {
+	fs := newFlagSet("backup")
+	output := fs.String("output", "", "backup file to create (default: next to the state file, timestamped)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	destPath := *output
+	if destPath == "" {
+		destPath = defaultBackupPath(cfg, "backup")
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.Backup(ctx, destPath); err != nil {
+		return err
+	}
+	fmt.Println(destPath)
+	return nil
+}
+
+// defaultBackupPath names a timestamped backup of the given kind next to the state file
+func defaultBackupPath(cfg *config.Config, kind string) string {
+	name := fmt.Sprintf("night-routine-%s-%s.db", kind, time.Now().Format("20060102-150405"))
+	return filepath.Join(filepath.Dir(cfg.Service.StateFile), name)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/handlers"
+)
+
+// newBuildInfo describes the binary and the optional features and backends cfg enables, the
+// notification channels being those configured
+func newBuildInfo(cfg *config.Config, notifyChannels []string) handlers.BuildInfo {
+	optional := []struct {
+		name    string
+		enabled bool
+	}{
+		{"tracing", cfg.Tracing.Enabled},
+		{"device_auth", cfg.App.DeviceAuth},
+		{"passkey_login", cfg.App.PasskeyLogin},
+		{"demo_mode", cfg.App.DemoMode},
+		{"admin_server", cfg.App.AdminAddr != ""},
+		{"webhook_debounce", cfg.App.WebhookDebounce > 0},
+		{"webhook_polling", cfg.App.WebhookPollInterval > 0},
+		{"heartbeat", cfg.Service.HeartbeatURL != ""},
+		{"stats_cache", cfg.Service.StatsCacheTTL > 0},
+		{"consistency_check", cfg.Service.ConsistencyCheckInterval > 0},
+		{"quiet_hours", cfg.Service.QuietHours != ""},
+		{"monthly_report", cfg.Notify.MonthlyReport},
+		{"duty_reminder", cfg.Notify.ReminderTime != ""},
+		{"imbalance_alert", cfg.Notify.ImbalanceThreshold > 0},
+		{"hooks", len(cfg.Hooks.URLs) > 0},
+		{"calendar_unavailability", cfg.Availability.CalendarKeyword != ""},
+		{"schedule_review", cfg.Schedule.ReviewAfterDays > 0},
+		{"telemetry", cfg.Telemetry.Enabled},
+	}
+	features := []string{}
+	for _, feature := range optional {
+		if feature.enabled {
+			features = append(features, feature.name)
+		}
+	}
+	slices.Sort(features)
+
+	calendar := "google"
+	if cfg.App.DemoMode {
+		calendar = "demo"
+	} else if cfg.App.CalendarEndpoint != "" {
+		calendar = "custom"
+	}
+
+	notifications := "none"
+	if len(notifyChannels) > 0 {
+		notifications = strings.Join(notifyChannels, ",")
+	}
+
+	return handlers.BuildInfo{
+		Version:  version,
+		Commit:   commit,
+		Date:     date,
+		Features: features,
+		Backends: map[string]string{
+			"database":      "sqlite",
+			"calendar":      calendar,
+			"token_storage": cfg.Service.TokenStorage,
+			"notifications": notifications,
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Service.TokenStorage = "database"
+
+	info := newBuildInfo(cfg, nil)
+	assert.Equal(t, version, info.Version)
+	assert.Empty(t, info.Features)
+	assert.Equal(t, map[string]string{"database": "sqlite", "calendar": "google", "token_storage": "database", "notifications": "none"}, info.Backends)
+
+	cfg.Tracing.Enabled = true
+	cfg.Hooks.URLs = []string{"https://example.com/hook"}
+	cfg.Service.StatsCacheTTL = time.Minute
+	cfg.Service.TokenStorage = "keyring"
+	info = newBuildInfo(cfg, []string{"slack", "email"})
+	assert.Equal(t, []string{"hooks", "stats_cache", "tracing"}, info.Features)
+	assert.Equal(t, "keyring", info.Backends["token_storage"])
+	assert.Equal(t, "slack,email", info.Backends["notifications"])
+
+	cfg.App.CalendarEndpoint = "http://127.0.0.1:9090/"
+	assert.Equal(t, "custom", newBuildInfo(cfg, nil).Backends["calendar"])
+	cfg.App.CalendarEndpoint = ""
+	cfg.App.DemoMode = true
+	info = newBuildInfo(cfg, nil)
+	assert.Equal(t, "demo", info.Backends["calendar"])
+	assert.True(t, info.DemoMode())
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fakecalendar"
+	"github.com/belphemur/night-routine/internal/logging"
+	"golang.org/x/oauth2"
+)
+
+// demoCalendarName is the name of the calendar the demo syncs to
+const demoCalendarName = "Night Routine demo"
+
+// startDemoCalendar serves an in-memory fake calendar on a loopback port for [app] demo_mode and
+// points [app] calendar_endpoint at it. The returned function stops it.
+func startDemoCalendar(cfg *config.Config) (func(), error) {
+	logger := logging.GetLogger("demo")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to listen for the demo calendar: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Demo calendar start failed")
+		return nil, wrappedErr
+	}
+
+	fake := fakecalendar.New()
+	fake.AddCalendar(fakecalendar.PrimaryCalendarID, demoCalendarName)
+	srv := &http.Server{Handler: fake, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			logger.Error().Err(err).Msg("Demo calendar server error")
+		}
+	}()
+
+	cfg.App.CalendarEndpoint = "http://" + listener.Addr().String() + "/"
+	logger.Warn().Str("endpoint", cfg.App.CalendarEndpoint).Msg("Demo mode: syncing to an in-memory calendar, nothing reaches Google")
+	return func() {
+		if err := srv.Close(); err != nil {
+			logger.Warn().Err(err).Msg("Failed to stop the demo calendar")
+		}
+	}, nil
+}
+
+// seedDemoAccount connects the demo calendar as if linked with Google: a token that never expires
+// and the selection of its primary calendar. A database already connected is left as it is.
+func seedDemoAccount(ctx context.Context, svc *services) error {
+	hasToken, err := svc.tokenManager.HasToken()
+	if err != nil {
+		return fmt.Errorf("failed to check the demo token: %w", err)
+	}
+	if !hasToken {
+		// Without expiry the token is never refreshed at Google
+		if err := svc.tokenManager.SaveToken(ctx, &oauth2.Token{AccessToken: "demo", TokenType: "Bearer"}); err != nil {
+			return fmt.Errorf("failed to save the demo token: %w", err)
+		}
+	}
+
+	calendarID, err := svc.tokenStore.GetSelectedCalendar()
+	if err != nil {
+		return fmt.Errorf("failed to get the selected calendar: %w", err)
+	}
+	if calendarID == "" {
+		if err := svc.tokenStore.SaveSelectedCalendarWithName(fakecalendar.PrimaryCalendarID, demoCalendarName); err != nil {
+			return fmt.Errorf("failed to select the demo calendar: %w", err)
+		}
+	}
+	return nil
+}
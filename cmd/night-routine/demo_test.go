@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gcalendar "google.golang.org/api/calendar/v3"
+)
+
+func TestDemoMode_SyncsToTheFakeCalendar(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "routine.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+[app]
+app_url = "http://localhost:8888"
+public_url = "http://localhost:8888"
+demo_mode = true
+[parents]
+parent_a = "Alice"
+parent_b = "Bob"
+[schedule]
+look_ahead_days = 7
+[service]
+state_file = "`+filepath.ToSlash(filepath.Join(dir, "state.db"))+`"
+`), 0o644))
+	for _, key := range []string{"GOOGLE_OAUTH_CLIENT_ID", "GOOGLE_OAUTH_CLIENT_SECRET", "NR_OAUTH__CLIENT_ID", "NR_OAUTH__CLIENT_SECRET"} {
+		t.Setenv(key, "")
+	}
+	cfg, err := config.Load(configFile)
+	require.NoError(t, err)
+
+	stop, err := startDemoCalendar(cfg)
+	require.NoError(t, err)
+	t.Cleanup(stop)
+	require.NotEmpty(t, cfg.App.CalendarEndpoint)
+
+	db, err := openDatabase(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	svc, err := newServices(t.Context(), cfg, db)
+	require.NoError(t, err)
+	require.NoError(t, seedDemoAccount(t.Context(), svc))
+	require.NoError(t, seedDemoAccount(t.Context(), svc), "seeding twice keeps the account")
+
+	require.NoError(t, svc.calSvc.Initialize(t.Context()))
+	require.NoError(t, syncSchedule(t.Context(), svc, constants.SyncTriggerManual))
+
+	resp, err := http.Get(cfg.App.CalendarEndpoint + "calendars/primary/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var events gcalendar.Events
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&events))
+	assert.GreaterOrEqual(t, len(events.Items), 7, "a night of the look-ahead window per event")
+	assert.Equal(t, constants.NightRoutineIdentifier, events.Items[0].ExtendedProperties.Private["app"])
+}
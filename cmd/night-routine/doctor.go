@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/token"
+)
+
+// doctorTimeout bounds each network check of the doctor command
+const doctorTimeout = 10 * time.Second
+
+// Outcomes of a doctor check
+const (
+	checkOK   = "OK"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+)
+
+// doctorReport prints check outcomes as they are produced and counts failures
+type doctorReport struct {
+	w        io.Writer
+	failures int
+}
+
+// add prints the outcome of a check, followed by the remediation hint when it did not pass
+func (r *doctorReport) add(outcome, name, detail, hint string) {
+	fmt.Fprintf(r.w, "[%-4s] %s: %s\n", outcome, name, detail)
+	if outcome != checkOK && hint != "" {
+		fmt.Fprintf(r.w, "       -> %s\n", hint)
+	}
+	if outcome == checkFail {
+		r.failures++
+	}
+}
+
+// runDoctor checks the installation and prints a remediation hint for every problem found.
+// It does not migrate the database; it only reads state, apart from refreshing an expired token.
+func runDoctor(ctx context.Context, args []string) error {
+	fs := newFlagSet("doctor")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	report := &doctorReport{w: os.Stdout}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		report.add(checkFail, "Configuration", err.Error(), "Check the file pointed to by CONFIG_FILE and the NR_* environment variables")
+		return err
+	}
+	report.add(checkOK, "Configuration", "loaded and valid", "")
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		report.add(checkFail, "Database", err.Error(), fmt.Sprintf("Make sure %s is writable by the service user", cfg.Service.StateFile))
+		return withExitCode(exitFailure, errors.New("doctor found problems"))
+	}
+	defer db.Close()
+
+	if schemaReady := checkDatabase(ctx, report, db); schemaReady {
+		checkGoogle(ctx, report, cfg, db)
+	}
+	checkPublicURL(ctx, report, cfg.App.PublicUrl)
+
+	if report.failures > 0 {
+		return withExitCode(exitFailure, fmt.Errorf("doctor found %d problem(s)", report.failures))
+	}
+	return nil
+}
+
+// checkDatabase verifies the database integrity and schema version. It returns whether the
+// schema exists, as the remaining checks read from it.
+func checkDatabase(ctx context.Context, report *doctorReport, db *database.DB) bool {
+	problems, err := db.CheckIntegrity(ctx)
+	switch {
+	case err != nil:
+		report.add(checkFail, "Database integrity", err.Error(), "The state file may not be a SQLite database; restore it from a backup")
+	case len(problems) > 0:
+		report.add(checkFail, "Database integrity", strings.Join(problems, "; "), "Restore the state file from a backup made with 'night-routine backup'")
+	default:
+		report.add(checkOK, "Database integrity", "ok", "")
+	}
+
+	status, err := db.GetMigrationStatus()
+	switch {
+	case err != nil:
+		report.add(checkFail, "Migrations", err.Error(), "Restore the state file from a backup")
+		return false
+	case status.Dirty:
+		report.add(checkFail, "Migrations", fmt.Sprintf("version %d is dirty, a migration failed halfway", status.Current), "Restore the state file from a backup taken before the upgrade")
+		return false
+	case status.Current == 0:
+		report.add(checkWarn, "Migrations", "database not initialized", "Run 'night-routine migrate' or start the server once")
+		return false
+	case status.Pending():
+		report.add(checkWarn, "Migrations", fmt.Sprintf("version %d, %d available", status.Current, status.Latest), "Run 'night-routine migrate' or restart the server to apply them")
+	default:
+		report.add(checkOK, "Migrations", fmt.Sprintf("up to date (version %d)", status.Current), "")
+	}
+	return true
+}
+
+// checkGoogle verifies the OAuth token, the calendar selection and the webhook channel
+func checkGoogle(ctx context.Context, report *doctorReport, cfg *config.Config, db *database.DB) {
+	tokenStore, err := database.NewTokenStore(db)
+	if err != nil {
+		report.add(checkFail, "Google token", err.Error(), "")
+		return
+	}
+	tokenStorage, err := newTokenStorage(cfg, tokenStore)
+	if err != nil {
+		report.add(checkFail, "Google token", err.Error(), "Unlock the OS keyring or set service.token_storage to \"database\"")
+		return
+	}
+	tokenManager := token.NewTokenManager(tokenStorage, cfg.OAuth)
+
+	hasToken, err := tokenManager.HasToken()
+	switch {
+	case err != nil:
+		report.add(checkFail, "Google token", err.Error(), "")
+		return
+	case !hasToken:
+		report.add(checkFail, "Google token", "missing", fmt.Sprintf("Open %s and link Google Calendar", cfg.App.AppUrl))
+		return
+	}
+
+	tokenCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	if _, err := tokenManager.GetValidToken(tokenCtx); err != nil {
+		if tokenManager.NeedsReauthentication() {
+			report.add(checkFail, "Google token", "revoked or expired", fmt.Sprintf("Reconnect Google Calendar from the home page at %s", cfg.App.AppUrl))
+		} else {
+			report.add(checkFail, "Google token", err.Error(), "Check that this host can reach oauth2.googleapis.com")
+		}
+		return
+	}
+	report.add(checkOK, "Google token", "valid", "")
+
+	calendarID, err := tokenStore.GetSelectedCalendar()
+	switch {
+	case err != nil:
+		report.add(checkFail, "Calendar", err.Error(), "")
+		return
+	case calendarID == "":
+		report.add(checkWarn, "Calendar", "none selected, the primary calendar is used", fmt.Sprintf("Pick a calendar at %s/calendars", strings.TrimSuffix(cfg.App.AppUrl, "/")))
+		return
+	default:
+		report.add(checkOK, "Calendar", calendarID, "")
+	}
+
+	channels, err := tokenStore.GetActiveNotificationChannels()
+	if err != nil {
+		report.add(checkFail, "Webhook channel", err.Error(), "")
+		return
+	}
+	for _, channel := range channels {
+		if channel.CalendarID == calendarID {
+			report.add(checkOK, "Webhook channel", fmt.Sprintf("active until %s", channel.Expiration.Format(time.RFC3339)), "")
+			return
+		}
+	}
+	report.add(checkWarn, "Webhook channel", "no active channel for the selected calendar",
+		"Run the server with an HTTPS public_url reachable by Google; until then calendar edits are only picked up by scheduled syncs")
+}
+
+// checkPublicURL verifies that the public URL, used by Google for webhooks, reaches this service
+func checkPublicURL(ctx context.Context, report *doctorReport, publicURL string) {
+	parsed, err := url.Parse(publicURL)
+	if err != nil {
+		report.add(checkFail, "Public URL", err.Error(), "Fix app.public_url")
+		return
+	}
+	if parsed.Scheme != "https" {
+		report.add(checkWarn, "Public URL", fmt.Sprintf("%s is not HTTPS", publicURL), "Google only delivers webhooks to HTTPS URLs; put the service behind a TLS reverse proxy")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	healthURL := strings.TrimSuffix(publicURL, "/") + "/healthz"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		report.add(checkFail, "Public URL", err.Error(), "Fix app.public_url")
+		return
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		report.add(checkFail, "Public URL", fmt.Sprintf("%s unreachable: %v", healthURL, err), "Make sure the server is running and that DNS and the reverse proxy route public_url to it")
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		report.add(checkFail, "Public URL", fmt.Sprintf("%s answered %s", healthURL, resp.Status), "Make sure public_url routes to night-routine and not to another service")
+		return
+	}
+	report.add(checkOK, "Public URL", fmt.Sprintf("%s reachable", healthURL), "")
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+// exportDateFormat is the date format of the export flags and output
+const exportDateFormat = "2006-01-02"
+
+// exportedAssignment is the serialized form of an assignment
+type exportedAssignment struct {
+	Date                  string `json:"date"`
+	Parent                string `json:"parent"`
+	CaregiverType         string `json:"caregiver_type"`
+	DecisionReason        string `json:"decision_reason"`
+	Override              bool   `json:"override"`
+	GoogleCalendarEventID string `json:"google_calendar_event_id,omitempty"`
+}
+
+// runExport writes the assignments of a date range as JSON or CSV
+func runExport(ctx context.Context, args []string) error {
+	fs := newFlagSet("export")
+	from := fs.String("from", "", "first date to export, YYYY-MM-DD (default: earliest assignment)")
+	to := fs.String("to", "", "last date to export, YYYY-MM-DD (default: latest assignment)")
+	format := fs.String("format", "json", "output format: json or csv")
+	output := fs.String("output", "", "file to write (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("invalid export format: %s (must be json or csv)", *format)
+	}
+	start, err := parseExportDate(*from, time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return err
+	}
+	end, err := parseExportDate(*to, time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tracker, err := fairness.New(db)
+	if err != nil {
+		return fmt.Errorf("failed to initialize fairness tracker: %w", err)
+	}
+	assignments, err := tracker.GetAssignmentsInRange(ctx, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to read assignments: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return writeAssignments(w, *format, assignments)
+}
+
+// parseExportDate parses a date flag, returning fallback when it is empty
+func parseExportDate(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse(exportDateFormat, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", value)
+	}
+	return parsed, nil
+}
+
+// writeAssignments serializes the assignments in the given format
+func writeAssignments(w io.Writer, format string, assignments []*fairness.Assignment) error {
+	exported := make([]exportedAssignment, 0, len(assignments))
+	for _, a := range assignments {
+		exported = append(exported, exportedAssignment{
+			Date:                  a.Date.Format(exportDateFormat),
+			Parent:                a.Parent,
+			CaregiverType:         string(a.CaregiverType),
+			DecisionReason:        string(a.DecisionReason),
+			Override:              a.Override,
+			GoogleCalendarEventID: a.GoogleCalendarEventID,
+		})
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(exported); err != nil {
+			return fmt.Errorf("failed to write JSON export: %w", err)
+		}
+		return nil
+	}
+
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"date", "parent", "caregiver_type", "decision_reason", "override", "google_calendar_event_id"}); err != nil {
+		return fmt.Errorf("failed to write CSV export: %w", err)
+	}
+	for _, a := range exported {
+		record := []string{a.Date, a.Parent, a.CaregiverType, a.DecisionReason, strconv.FormatBool(a.Override), a.GoogleCalendarEventID}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV export: %w", err)
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/dates"
+	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+)
+
+// runGenerate prints the computed schedule of the coming days without syncing it to Google Calendar
+func runGenerate(ctx context.Context, args []string) error {
+	fs := newFlagSet("generate")
+	days := fs.Int("days", 0, "days to look ahead from today, like look_ahead_days (default: the look-ahead days setting)")
+	format := fs.String("format", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "table" && *format != "json" {
+		return withExitCode(exitUsage, fmt.Errorf("invalid output format: %s (must be table or json)", *format))
+	}
+	if *days < 0 {
+		return withExitCode(exitUsage, fmt.Errorf("days must be positive, got %d", *days))
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	svc, err := newServices(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+
+	lookAheadDays := *days
+	if lookAheadDays == 0 {
+		_, lookAheadDays, _, _, err = svc.runtimeConfig.GetSchedule()
+		if err != nil {
+			return fmt.Errorf("failed to get schedule configuration: %w", err)
+		}
+	}
+
+	// The schedule is computed on a scratch copy of the recorded assignments, the locked nights of
+	// the look-ahead included, so that the state database is only read
+	now := time.Now()
+	end := dates.AddDays(now, lookAheadDays)
+	scratch, scratchTracker, err := openScratchTracker()
+	if err != nil {
+		return err
+	}
+	defer scratch.Close()
+	if err := copyHistory(ctx, svc.tracker, scratchTracker, end); err != nil {
+		return err
+	}
+	children, err := svc.tracker.GetChildren(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get children: %w", err)
+	}
+	for _, child := range children {
+		scratchChild, err := scratchTracker.AddChild(ctx, child.Name)
+		if err != nil {
+			return fmt.Errorf("failed to copy child %s: %w", child.Name, err)
+		}
+		if err := copyHistory(ctx, svc.tracker.ForChild(child), scratchTracker.ForChild(*scratchChild), end); err != nil {
+			return err
+		}
+	}
+
+	assignments, err := scheduler.New(svc.runtimeConfig, scratchTracker).GenerateSchedule(ctx, now, end, now)
+	if err != nil {
+		return withExitCode(exitSyncError, fmt.Errorf("failed to generate schedule: %w", err))
+	}
+
+	if *format == "json" {
+		return writeScheduleJSON(os.Stdout, assignments)
+	}
+	return writeScheduleTable(os.Stdout, assignments)
+}
+
+// writeScheduleJSON writes the schedule in the same shape as the export command
+func writeScheduleJSON(w io.Writer, assignments []*scheduler.Assignment) error {
+	exported := make([]exportedAssignment, 0, len(assignments))
+	for _, a := range assignments {
+		exported = append(exported, exportedAssignment{
+			Date:                  a.Date.Format(exportDateFormat),
+			Parent:                a.Parent,
+			CaregiverType:         string(a.CaregiverType),
+			DecisionReason:        string(a.DecisionReason),
+			Override:              a.Override,
+			GoogleCalendarEventID: a.GoogleCalendarEventID,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(exported); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	return nil
+}
+
+// writeScheduleTable writes the schedule as an aligned table
+func writeScheduleTable(w io.Writer, assignments []*scheduler.Assignment) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tDAY\tPARENT\tREASON")
+	for _, a := range assignments {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Date.Format(exportDateFormat), a.Date.Format("Mon"), a.Parent, a.DecisionReason)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	return nil
+}
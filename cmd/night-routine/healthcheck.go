@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// runHealthcheck queries the readiness endpoint of the local server and fails unless it is
+// ready, so container images can declare a HEALTHCHECK without shipping curl
+func runHealthcheck(ctx context.Context, args []string) error {
+	fs := newFlagSet("healthcheck")
+	url := fs.String("url", "", "readiness endpoint to query (default: http://localhost:<app.port>/readyz)")
+	timeout := fs.Duration("timeout", 5*time.Second, "maximum time to wait for an answer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	endpoint := *url
+	if endpoint == "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		endpoint = fmt.Sprintf("http://localhost:%d/readyz", cfg.App.Port)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create readiness request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("readiness check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return fmt.Errorf("failed to read readiness response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("service not ready: %s: %s", resp.Status, body)
+	}
+	return nil
+}
@@ -2,23 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
 	"os/signal"
-	"path/filepath"
+	"strings"
 	"syscall"
-	"time"
 
-	"github.com/belphemur/night-routine/internal/calendar"
-	"github.com/belphemur/night-routine/internal/config"
-	"github.com/belphemur/night-routine/internal/database"
-	"github.com/belphemur/night-routine/internal/fairness"
-	"github.com/belphemur/night-routine/internal/fairness/scheduler"
-	"github.com/belphemur/night-routine/internal/handlers"
 	"github.com/belphemur/night-routine/internal/logging"
-	appSignals "github.com/belphemur/night-routine/internal/signals"
-	"github.com/belphemur/night-routine/internal/token"
 )
 
 var (
@@ -27,22 +19,94 @@ var (
 	date    = "unknown"
 )
 
+// Process exit codes, stable so that cron jobs and systemd units can react to them
+const (
+	exitOK        = 0
+	exitFailure   = 1 // unexpected error
+	exitUsage     = 2 // unknown command or invalid flags
+	exitConfig    = 3 // configuration could not be loaded
+	exitAuth      = 4 // Google Calendar is not linked or needs re-authentication
+	exitSyncError = 5 // the schedule could not be generated or synced
+)
+
+// exitError carries the process exit code of a failed command
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode annotates err with the exit code the process should end with
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code for a command error
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
+// command is a night-routine subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands lists the available subcommands in the order they are shown in the usage
+var commands = []command{
+	{name: "serve", summary: "Run the web interface and the scheduling loop (default)", run: runServe},
+	{name: "sync", summary: "Run the scheduling loop without the web interface, or a single sync with --once", run: runSync},
+	{name: "generate", summary: "Print the computed schedule of the coming days without syncing it", run: runGenerate},
+	{name: "stats", summary: "Print per-parent totals, a monthly breakdown and the fairness score", run: runStats},
+	{name: "simulate", summary: "Project the distribution of nights under hypothetical settings", run: runSimulate},
+	{name: "migrate", summary: "Apply pending database migrations and exit", run: runMigrate},
+	{name: "backup", summary: "Write a consistent copy of the database to a file", run: runBackup},
+	{name: "export", summary: "Export assignments as JSON or CSV", run: runExport},
+	{name: "token", summary: "Export or import the Google token as an encrypted bundle to move hosts", run: runToken},
+	{name: "seed", summary: "Fill an empty database with demo data ('seed demo')", run: runSeed},
+	{name: "doctor", summary: "Diagnose the configuration, database, Google link and public URL", run: runDoctor},
+	{name: "healthcheck", summary: "Exit 0 when the local server is ready, 1 otherwise", run: runHealthcheck},
+	{name: "version", summary: "Print version information", run: runVersion},
+}
+
 func main() {
+	cmd, args, ok := parseCommand(os.Args[1:])
+	if !ok {
+		printUsage(os.Stderr)
+		os.Exit(exitUsage)
+	}
+	if cmd == nil {
+		printUsage(os.Stdout)
+		return
+	}
+
 	// Determine if we're in development mode
 	isDev := os.Getenv("ENV") != "production"
 
-	// Initialize logging
-	logging.Initialize(isDev)
+	// Initialize logging. Only the server logs to stdout; other commands keep
+	// stdout free for their own output.
+	if cmd.name == "serve" {
+		logging.Initialize(isDev)
+	} else {
+		logging.InitializeWithOutput(isDev, os.Stderr)
+	}
 
 	// Get a logger for the main component
 	logger := logging.GetLogger("main")
 
-	logger.Info().
-		Str("version", version).
-		Str("commit", commit).
-		Str("build_date", date).
-		Msg("Starting Night Routine Scheduler")
-
 	// Create context that's canceled on SIGINT/SIGTERM
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -56,428 +120,63 @@ func main() {
 		cancel()
 	}()
 
-	if err := run(ctx); err != nil {
-		logger.Fatal().Err(err).Msg("Application run failed")
+	if err := cmd.run(ctx, args); err != nil {
+		code := exitCode(err)
+		logger.Error().Err(err).Str("command", cmd.name).Int("exit_code", code).Msg("Command failed")
+		cancel()
+		os.Exit(code)
 	}
 }
 
-func run(ctx context.Context) error {
-	// Get logger for the run function
-	logger := logging.GetLogger("main")
-
-	// Get config file path from environment or use default
-	configPath := os.Getenv("CONFIG_FILE")
-	if configPath == "" {
-		configPath = "configs/routine.toml"
-	}
-
-	// Load configuration
-	cfg, err := config.Load(configPath)
-	if err != nil {
-		// Log error before returning, as main's fatal log won't have config context
-		logger.Error().Err(err).Str("config_path", configPath).Msg("Failed to load configuration")
-		return err
-	}
-
-	// Set log level from configuration
-	logging.SetLogLevel(cfg.Service.LogLevel)
-	logger.Info().Str("log_level", cfg.Service.LogLevel).Msg("Log level set")
-
-	// Create data directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(cfg.Service.StateFile), 0755); err != nil {
-		logger.Error().Err(err).Str("path", filepath.Dir(cfg.Service.StateFile)).Msg("Failed to create data directory")
-		return err
-	}
-
-	// Initialize database
-	// Construct database options from config and desired settings
-	dbOpts := database.SQLiteOptions{
-		Path:        cfg.Service.StateFile,
-		Mode:        "rwc",                      // Read-Write-Create mode
-		Cache:       database.CacheShared,       // Use shared cache mode
-		Journal:     database.JournalWAL,        // Use WAL journal mode
-		ForeignKeys: true,                       // Enable foreign keys
-		AutoVacuum:  "incremental",              // Use incremental auto-vacuum
-		BusyTimeout: 5000,                       // Default busy timeout (ms)
-		Synchronous: database.SynchronousNormal, // Default synchronous mode
-		// CacheSize: 2000, // Default cache size (KB) - can be added if needed
-	}
-	db, err := database.New(dbOpts) // Use the refactored New function
-	if err != nil {
-		// Wrap error for context, logger will handle Err field
-		wrappedErr := fmt.Errorf("failed to initialize database: %w", err)
-		logger.Error().Err(wrappedErr).Str("db_path", cfg.Service.StateFile).Msg("Database initialization failed")
-		return wrappedErr
-	}
-	defer db.Close()
-
-	// Initialize database schema
-	if err := db.MigrateDatabase(); err != nil {
-		wrappedErr := fmt.Errorf("failed to initialize database schema: %w", err)
-		logger.Error().Err(wrappedErr).Msg("Database schema initialization failed")
-		return wrappedErr
-	}
-
-	// Initialize config store for database-backed configuration
-	configStore, err := database.NewConfigStore(db)
-	if err != nil {
-		wrappedErr := fmt.Errorf("failed to initialize config store: %w", err)
-		logger.Error().Err(wrappedErr).Msg("Config store initialization failed")
-		return wrappedErr
-	}
-
-	// Seed configuration from TOML file to database (runs only once on initial setup or upgrade)
-	configSeeder := database.NewConfigSeeder(configStore)
-	if err := configSeeder.SeedFromConfig(cfg); err != nil {
-		wrappedErr := fmt.Errorf("failed to seed configuration: %w", err)
-		logger.Error().Err(wrappedErr).Msg("Configuration seeding failed")
-		return wrappedErr
-	}
-
-	// Build the ConfigAdapter: the single source of truth for all configuration.
-	// DB-backed settings (parents, availability, schedule) are read live from the
-	// database; the static OAuth2 config is provided here so handlers never need
-	// to touch *config.Config directly.
-	configAdapter := database.NewConfigAdapter(configStore, cfg.OAuth)
-
-	parentA, parentB, _ := configAdapter.GetParents()
-	logger.Info().
-		Str("parent_a", parentA).
-		Str("parent_b", parentB).
-		Msg("Configuration loaded from database")
-
-	// Initialize fairness tracker
-	tracker, err := fairness.New(db)
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to initialize fairness tracker")
-		return err // Return original error
-	}
-
-	// Initialize token store
-	tokenStore, err := database.NewTokenStore(db)
-	if err != nil {
-		wrappedErr := fmt.Errorf("failed to initialize token store: %w", err)
-		logger.Error().Err(wrappedErr).Msg("Token store initialization failed")
-		return wrappedErr
-	}
-
-	// Initialize token manager
-	tokenManager := token.NewTokenManager(tokenStore, cfg.OAuth)
-
-	// Create scheduler — reads parents/availability/schedule live from the database
-	sched := scheduler.New(configAdapter, tracker)
-
-	// Initialize calendar manager
-	calendarManager := calendar.NewManager(tokenStore, tokenManager, cfg.OAuth)
-
-	// Initialize calendar service without requiring a token
-	calSvc := calendar.New(cfg.OAuth, cfg.App.AppUrl, cfg.App.PublicUrl, tokenStore, sched, tokenManager)
-	logger.Info().Msg("Calendar service created. Waiting for authentication/initialization...")
-
-	// Initialize static file handler
-	staticHandler, err := handlers.NewStaticHandler()
-	if err != nil {
-		wrappedErr := fmt.Errorf("failed to initialize static handler: %w", err)
-		logger.Error().Err(wrappedErr).Msg("Static handler initialization failed")
-		return wrappedErr
+// parseCommand picks the subcommand from the command line. Without a subcommand, or when
+// the first argument is a flag, it falls back to serve so existing deployments keep working.
+// A nil command with ok set means help was requested.
+func parseCommand(args []string) (*command, []string, bool) {
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "--help") {
+		return findCommand("serve"), args, true
 	}
 
-	// Initialize base handler first, as other handlers depend on it.
-	// configAdapter is the single source of truth for all configuration.
-	baseHandler, err := handlers.NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, staticHandler.GetCSSETag(), staticHandler.GetLogoETag())
-	if err != nil {
-		wrappedErr := fmt.Errorf("failed to initialize base handler: %w", err)
-		logger.Error().Err(wrappedErr).Msg("Base handler initialization failed")
-		return wrappedErr
+	switch args[0] {
+	case "help", "-h", "--help":
+		return nil, nil, true
 	}
-	homeHandler := handlers.NewHomeHandler(baseHandler, sched)
 
-	oauthHandler, err := handlers.NewOAuthHandler(baseHandler)
-	if err != nil {
-		wrappedErr := fmt.Errorf("failed to initialize OAuth handler: %w", err)
-		logger.Error().Err(wrappedErr).Msg("OAuth handler initialization failed")
-		return wrappedErr
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		return nil, nil, false
 	}
-	calendarHandler := handlers.NewCalendarHandler(baseHandler, calendarManager)
-	syncHandler := handlers.NewSyncHandler(baseHandler, sched, tokenManager, calSvc, configAdapter)
-	settingsHandler := handlers.NewSettingsHandler(baseHandler, configStore, sched, tokenManager, calSvc)
-	statisticsHandler := handlers.NewStatisticsHandler(baseHandler, configStore)
-	unlockHandler := handlers.NewUnlockHandler(baseHandler, tracker, sched, calSvc, configAdapter)
-	assignmentDetailsHandler := handlers.NewAssignmentDetailsHandler(baseHandler, tracker, sched, calSvc, configAdapter)
-
-	// Register routes
-	staticHandler.RegisterRoutes()
-	homeHandler.RegisterRoutes()
-	oauthHandler.RegisterRoutes()
-	calendarHandler.RegisterRoutes()
-	syncHandler.RegisterRoutes()
-	settingsHandler.RegisterRoutes()
-	statisticsHandler.RegisterRoutes()
-	unlockHandler.RegisterRoutes()
-	assignmentDetailsHandler.RegisterRoutes()
-
-	// Start HTTP server
-	srv := &http.Server{
-		Addr: fmt.Sprintf(":%d", cfg.App.Port),
-	}
-
-	// Start HTTP server in a goroutine
-	go func() {
-		logger.Info().Int("port", cfg.App.Port).Msg("Starting OAuth web server")
-		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-			logger.Error().Err(err).Msg("HTTP server error")
-		}
-	}()
-
-	// Set up webhook handler using the calendar service (will be initialized later).
-	// configAdapter is passed so the handler reads all schedule settings live from
-	// the database, picking up UI setting changes without a restart.
-	webhookHandler := handlers.NewWebhookHandler(baseHandler, calSvc, sched, tokenManager, configAdapter)
-	webhookHandler.RegisterRoutes()
-
-	// Check for existing token and initialize calendar service if found
-	hasToken, _ := tokenManager.HasToken()
-	if hasToken {
-		logger.Info().Msg("Token found, attempting initial calendar service initialization and notification setup")
-		if !calSvc.IsInitialized() {
-			if err := calSvc.Initialize(ctx); err != nil {
-				// Log as warning, app can continue without calendar initially
-				logger.Warn().Err(err).Msg("Initial calendar service initialization failed")
-			} else {
-				logger.Info().Msg("Initial calendar service initialization successful")
-				// Set up notification channel for calendar changes only if initialized
-				if err := calSvc.SetupNotificationChannel(ctx); err != nil {
-					logger.Warn().Err(err).Msg("Failed to set up notification channel after initial check")
-				} else {
-					logger.Info().Msg("Successfully set up notification channel after initial check")
-				}
-			}
-		} else {
-			logger.Info().Msg("Calendar service already initialized")
-			// Ensure notification channel is set up if already initialized
-			if err := calSvc.SetupNotificationChannel(ctx); err != nil {
-				logger.Warn().Err(err).Msg("Failed to set up notification channel (service already initialized)")
-			} else {
-				logger.Info().Msg("Successfully set up notification channel (service already initialized)")
-			}
-		}
-	} else {
-		logger.Info().Msg("No token found initially. Waiting for OAuth flow.")
-	}
-
-	// Perform manual sync on startup if configured and possible
-	performManualStartupSync(ctx, cfg.Service.ManualSyncOnStartup, configAdapter, hasToken, calSvc, sched)
-
-	// Register handler for token setup signals
-	appSignals.OnTokenSetup(func(ctx context.Context, data appSignals.TokenSetupData) {
-		signalLogger := logging.GetLogger("signal-token-setup")
-		if data.Success {
-			signalLogger.Info().Msg("Token setup detected - initializing calendar service")
-
-			// Initialize the calendar service with the new token
-			// This might be redundant if already initialized above, but Initialize handles that.
-			if err := calSvc.Initialize(ctx); err != nil {
-				signalLogger.Error().Err(err).Msg("Failed to initialize calendar service after token setup")
-				return
-			}
-
-			signalLogger.Info().Msg("Calendar service initialized successfully after token setup")
-
-			// We don't set up notification channels here anymore,
-			// they will be set up when a calendar is selected
-		} else {
-			signalLogger.Warn().Msg("Token setup signal received, but setup was not successful")
-		}
-	}, "main-token-setup-handler")
-
-	// Register handler for calendar selection signals
-	appSignals.OnCalendarSelected(func(ctx context.Context, data appSignals.CalendarSelectedData) {
-		signalLogger := logging.GetLogger("signal-calendar-selected")
-		signalLogger.Info().Str("calendar_id", data.CalendarID).Msg("Calendar selection detected - setting up notification channel")
-
-		// Initialize calendar service if not already initialized (should be rare here)
-		if !calSvc.IsInitialized() {
-			signalLogger.Warn().Msg("Calendar service not initialized during calendar selection, attempting initialization")
-			if err := calSvc.Initialize(ctx); err != nil {
-				signalLogger.Error().Err(err).Msg("Failed to initialize calendar service on calendar selection")
-				return
-			}
-			signalLogger.Info().Msg("Calendar service initialized successfully during calendar selection")
-		}
-
-		// Set up notification channel for calendar changes
-		if err := calSvc.SetupNotificationChannel(ctx); err != nil {
-			signalLogger.Warn().Err(err).Msg("Failed to set up notification channel after calendar selection")
-		} else {
-			signalLogger.Info().Msg("Successfully set up notification channel after calendar selection")
-		}
-
-		// Update schedule immediately after calendar selection
-		if err := updateSchedule(ctx, configAdapter, sched, calSvc); err != nil {
-			signalLogger.Error().Err(err).Msg("Failed to update schedule after calendar selection")
-		}
-	}, "main-calendar-selected-handler")
-
-	// Main service loop.
-	// The ticker fires every minute so that any UpdateFrequency setting change
-	// is picked up quickly. The actual schedule update is only executed when
-	// enough time has elapsed since the last run according to the live
-	// UpdateFrequency value read from the database on every tick.
-	ticker := time.NewTicker(time.Minute)
-	defer ticker.Stop()
-	var lastScheduleRun time.Time
-
-	logger.Info().Msg("Starting main service loop")
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info().Msg("Context cancelled, initiating shutdown sequence")
-			// Stop notification channels if calendar service is available
-			if calSvc.IsInitialized() {
-				logger.Info().Msg("Stopping notification channels...")
-				if err := calSvc.StopAllNotificationChannels(context.Background()); err != nil { // Use background context for shutdown
-					logger.Warn().Err(err).Msg("Failed to stop notification channels")
-				} else {
-					logger.Info().Msg("Notification channels stopped")
-				}
-			}
-
-			// Shutdown HTTP server
-			logger.Info().Msg("Shutting down HTTP server...")
-			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer shutdownCancel()
-			if err := srv.Shutdown(shutdownCtx); err != nil {
-				logger.Error().Err(err).Msg("HTTP server shutdown error")
-			} else {
-				logger.Info().Msg("HTTP server shut down gracefully")
-			}
-			logger.Info().Msg("Shutdown complete")
-			return nil
-
-		case <-ticker.C:
-			logger.Debug().Msg("Update schedule tick received")
-			if !calSvc.IsInitialized() {
-				logger.Debug().Msg("Calendar service not initialized, attempting initialization on tick")
-				// Try to initialize calendar service if it wasn't available before
-				if err := calSvc.Initialize(ctx); err != nil {
-					logger.Warn().Err(err).Msg("Calendar service still not ready")
-				} else {
-					logger.Info().Msg("Calendar service initialized successfully on scheduled check")
-					// Notification channel setup will happen on calendar selection
-				}
-				continue
-			}
-
-			// Read UpdateFrequency live from the database so that changes made in
-			// the UI take effect without requiring an application restart.
-			// (updateFrequency is the only value we use here; the rest are intentionally ignored)
-			updateFrequency, _, _, _, err := configAdapter.GetSchedule()
-			if err != nil {
-				logger.Error().Err(err).Msg("Failed to read schedule config on tick; skipping update")
-				continue
-			}
-			if updateFrequency == "disabled" {
-				logger.Debug().Msg("Update frequency is disabled, skipping automatic schedule update")
-				continue
-			}
-			updateInterval := getUpdateInterval(updateFrequency)
+	return cmd, args[1:], true
+}
 
-			if lastScheduleRun.IsZero() || time.Since(lastScheduleRun) >= updateInterval {
-				logger.Debug().Str("update_frequency", updateFrequency).Msg("Running scheduled schedule update")
-				if err := updateSchedule(ctx, configAdapter, sched, calSvc); err != nil {
-					logger.Error().Err(err).Msg("Failed to update schedule on tick")
-				} else {
-					lastScheduleRun = time.Now()
-				}
-			} else {
-				logger.Debug().
-					Str("update_frequency", updateFrequency).
-					Dur("time_until_next_run", updateInterval-time.Since(lastScheduleRun)).
-					Msg("Skipping schedule update; next run not due yet")
-			}
+// findCommand returns the subcommand with the given name, or nil
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
 		}
 	}
+	return nil
 }
 
-// performManualStartupSync checks the config and performs a schedule sync if enabled and possible.
-// It assumes calSvc initialization was already attempted if hasToken is true.
-func performManualStartupSync(ctx context.Context, manualSyncOnStartup bool, configStore config.ConfigStoreInterface, hasToken bool, calSvc *calendar.Service, sched *scheduler.Scheduler) {
-	logger := logging.GetLogger("manual-startup-sync") // Get logger specific to this function
-
-	if !manualSyncOnStartup {
-		return // Feature not enabled
-	}
-
-	logger.Info().Msg("Manual sync on startup configured.")
-	if !hasToken {
-		logger.Warn().Msg("Manual sync on startup configured, but no token found. Skipping sync.")
-		return
-	}
-
-	// Check if the calendar service is actually initialized (initial attempt might have failed)
-	if !calSvc.IsInitialized() {
-		logger.Warn().Msg("Cannot perform manual sync on startup: Calendar service failed to initialize earlier.")
-		return
-	}
-
-	// Perform the sync
-	logger.Info().Msg("Performing manual schedule sync on startup...")
-	if err := updateSchedule(ctx, configStore, sched, calSvc); err != nil {
-		logger.Error().Err(err).Msg("Manual schedule sync on startup failed")
-	} else {
-		logger.Info().Msg("Manual schedule sync on startup completed successfully")
+// printUsage lists the available subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: night-routine <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'night-routine <command> -h' for the flags of a command.")
 }
 
-func updateSchedule(ctx context.Context, configStore config.ConfigStoreInterface, sched *scheduler.Scheduler, calSvc *calendar.Service) error {
-	scheduleLogger := logging.GetLogger("schedule-update")
-	scheduleLogger.Info().Msg("Starting schedule update")
-
-	// Read LookAheadDays live from the database so that UI setting changes
-	// take effect immediately without requiring an application restart.
-	// (updateFrequency, pastEventThresholdDays and statsOrder are intentionally ignored here)
-	_, lookAheadDays, _, _, err := configStore.GetSchedule()
-	if err != nil {
-		scheduleLogger.Error().Err(err).Msg("Failed to get schedule configuration")
-		return fmt.Errorf("failed to get schedule configuration: %w", err)
-	}
-
-	// Calculate date range
-	now := time.Now()
-	end := now.AddDate(0, 0, lookAheadDays)
-	scheduleLogger.Debug().Time("start_date", now).Time("end_date", end).Int("lookahead_days", lookAheadDays).Msg("Calculated date range")
-
-	// Generate schedule
-	assignments, err := sched.GenerateSchedule(now, end, time.Now())
-	if err != nil {
-		scheduleLogger.Error().Err(err).Msg("Failed to generate schedule")
+// runVersion prints the build information
+func runVersion(ctx context.Context, args []string) error {
+	fs := newFlagSet("version")
+	if err := fs.Parse(args); err != nil {
 		return err
 	}
-	scheduleLogger.Info().Int("assignments_generated", len(assignments)).Msg("Schedule generated")
-
-	// Sync with calendar
-	if err := calSvc.SyncSchedule(ctx, assignments); err != nil {
-		scheduleLogger.Error().Err(err).Msg("Failed to sync schedule with calendar")
-		return err
-	}
-
-	scheduleLogger.Info().Int("days", lookAheadDays).Int("assignments", len(assignments)).Msg("Updated schedule successfully")
+	fmt.Printf("night-routine %s (commit %s, built %s)\n", version, commit, date)
 	return nil
 }
-
-func getUpdateInterval(frequency string) time.Duration {
-	switch frequency {
-	case "daily":
-		return 24 * time.Hour
-	case "weekly":
-		return 7 * 24 * time.Hour
-	case "monthly":
-		return 30 * 24 * time.Hour // Approximation
-	case "disabled":
-		return 0 // Never triggers automatically; handled before this function is called
-	default:
-		logger := logging.GetLogger("main")
-		logger.Warn().Str("frequency", frequency).Msg("Invalid update frequency specified, defaulting to daily")
-		return 24 * time.Hour
-	}
-}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/logging"
+)
+
+// metricsPrefix prefixes the name of every exported metric
+const metricsPrefix = "night_routine_"
+
+// labelEscaper escapes the label values of the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsTracker reads the assignments the fairness gauges are computed from, implemented by fairness.Tracker
+type metricsTracker interface {
+	GetParentStatsUntil(ctx context.Context, until time.Time, parentNames ...string) (map[string]fairness.Stats, error)
+	GetAssignmentsInRange(ctx context.Context, start, end time.Time) ([]*fairness.Assignment, error)
+}
+
+// metricsParents reads the names of the parents, implemented by config.ConfigStoreInterface
+type metricsParents = config.RosterSource
+
+// newMetricsHandler serves the family balance as Prometheus gauges in the text exposition format,
+// followed by the integer expvar runtime variables (e.g. database_busy_retries). The gauges are
+// computed on each scrape, at the time of now.
+func newMetricsHandler(tracker metricsTracker, parents metricsParents, now func() time.Time) http.HandlerFunc {
+	logger := logging.GetLogger("metrics")
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		if err := writeFairnessMetrics(r.Context(), &body, tracker, parents, now()); err != nil {
+			logger.Error().Err(err).Msg("Failed to compute fairness metrics")
+			http.Error(w, "Failed to compute metrics", http.StatusInternalServerError)
+			return
+		}
+		writeExpvarMetrics(&body)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if _, err := body.WriteTo(w); err != nil {
+			logger.Debug().Err(err).Msg("Failed to write metrics")
+		}
+	}
+}
+
+// writeFairnessMetrics writes the nights of each parent over the last 30 days, the fairness score of
+// the totals as printed by the stats command, and the nights set by hand in the month of now
+func writeFairnessMetrics(ctx context.Context, w io.Writer, tracker metricsTracker, parents metricsParents, now time.Time) error {
+	roster, err := config.Roster(parents)
+	if err != nil {
+		return fmt.Errorf("failed to get parents: %w", err)
+	}
+	// Stats are computed strictly before the given date, so tomorrow includes tonight
+	stats, err := tracker.GetParentStatsUntil(ctx, now.AddDate(0, 0, 1), roster...)
+	if err != nil {
+		return fmt.Errorf("failed to get parent statistics: %w", err)
+	}
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	assignments, err := tracker.GetAssignmentsInRange(ctx, monthStart, monthStart.AddDate(0, 1, -1))
+	if err != nil {
+		return fmt.Errorf("failed to get assignments of the month: %w", err)
+	}
+	overrides := 0
+	for _, assignment := range assignments {
+		if assignment.Override {
+			overrides++
+		}
+	}
+
+	writeMetricHeader(w, "nights_last_30d", "gauge", "Nights of each parent over the last 30 days, tonight included.")
+	totals := make([]int, 0, len(roster))
+	for _, parent := range roster {
+		totals = append(totals, stats[parent].TotalAssignments)
+		fmt.Fprintf(w, "%snights_last_30d{parent=\"%s\"} %d\n", metricsPrefix, labelEscaper.Replace(parent), stats[parent].Last30Days)
+	}
+	writeMetricHeader(w, "fairness_score", "gauge", "Ratio between the smallest and the largest total of nights of the parents, 100 when evenly shared.")
+	fmt.Fprintf(w, "%sfairness_score %s\n", metricsPrefix, strconv.FormatFloat(fairnessScore(totals), 'f', -1, 64))
+	writeMetricHeader(w, "override_count_month", "gauge", "Nights of the current month set by hand.")
+	fmt.Fprintf(w, "%soverride_count_month %d\n", metricsPrefix, overrides)
+	return nil
+}
+
+// writeExpvarMetrics writes the integer expvar variables as untyped metrics, their dots and dashes
+// replaced by underscores
+func writeExpvarMetrics(w io.Writer) {
+	expvar.Do(func(kv expvar.KeyValue) {
+		value, ok := kv.Value.(*expvar.Int)
+		if !ok {
+			return
+		}
+		name := strings.NewReplacer(".", "_", "-", "_").Replace(kv.Key)
+		writeMetricHeader(w, name, "untyped", "Runtime variable "+kv.Key+" also served under /debug/vars.")
+		fmt.Fprintf(w, "%s%s %d\n", metricsPrefix, name, value.Value())
+	})
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric
+func writeMetricHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s%s %s\n", metricsPrefix, name, help)
+	fmt.Fprintf(w, "# TYPE %s%s %s\n", metricsPrefix, name, metricType)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticMetricsTracker struct {
+	stats       map[string]fairness.Stats
+	assignments []*fairness.Assignment
+	start, end  time.Time
+}
+
+func (s *staticMetricsTracker) GetParentStatsUntil(context.Context, time.Time, ...string) (map[string]fairness.Stats, error) {
+	return s.stats, nil
+}
+
+func (s *staticMetricsTracker) GetAssignmentsInRange(_ context.Context, start, end time.Time) ([]*fairness.Assignment, error) {
+	s.start, s.end = start, end
+	return s.assignments, nil
+}
+
+type staticMetricsParents struct{}
+
+func (staticMetricsParents) GetParents() (string, string, error) { return "Alice", `Bob "B"`, nil }
+
+func (staticMetricsParents) GetExtraParents() ([]config.ExtraParent, error) { return nil, nil }
+
+func TestMetricsHandler(t *testing.T) {
+	tracker := &staticMetricsTracker{
+		stats: map[string]fairness.Stats{
+			"Alice":   {TotalAssignments: 30, Last30Days: 14},
+			`Bob "B"`: {TotalAssignments: 40, Last30Days: 16},
+		},
+		assignments: []*fairness.Assignment{{Override: true}, {Override: false}, {Override: true}},
+	}
+	now := time.Date(2026, time.October, 16, 20, 0, 0, 0, time.UTC)
+	handler := newMetricsHandler(tracker, staticMetricsParents{}, func() time.Time { return now })
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE night_routine_nights_last_30d gauge\n")
+	assert.Contains(t, body, "night_routine_nights_last_30d{parent=\"Alice\"} 14\n")
+	assert.Contains(t, body, "night_routine_nights_last_30d{parent=\"Bob \\\"B\\\"\"} 16\n", "label values are escaped")
+	assert.Contains(t, body, "night_routine_fairness_score 75\n")
+	assert.Contains(t, body, "night_routine_override_count_month 2\n")
+	assert.Contains(t, body, "# TYPE night_routine_database_busy_retries untyped\n", "the expvar integers follow")
+	assert.Equal(t, time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), tracker.start)
+	assert.Equal(t, time.Date(2026, time.October, 31, 0, 0, 0, 0, time.UTC), tracker.end)
+}
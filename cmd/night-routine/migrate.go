@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// runMigrate applies pending database migrations without starting any service, or lists them
+// with --dry-run
+func runMigrate(ctx context.Context, args []string) error {
+	fs := newFlagSet("migrate")
+	dryRun := fs.Bool("dry-run", false, "print the versions of the pending migrations without applying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	// Applying the migrations is the purpose of this command
+	cfg.Service.SkipMigrate = false
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *dryRun {
+		status, err := db.GetMigrationStatus()
+		if err != nil {
+			return fmt.Errorf("failed to read the migration status: %w", err)
+		}
+		fmt.Printf("current version: %d, latest version: %d\n", status.Current, status.Latest)
+		for _, version := range status.Waiting {
+			fmt.Printf("pending: %d\n", version)
+		}
+		return nil
+	}
+
+	return migrateDatabase(ctx, cfg, db)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateDatabase(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{Service: config.ServiceConfig{
+		StateFile:           filepath.Join(dir, "state.db"),
+		BackupBeforeMigrate: true,
+		SkipMigrate:         true,
+	}}
+	db, err := openDatabase(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	err = migrateDatabase(t.Context(), cfg, db)
+	require.Error(t, err, "skip_migrate fails while migrations are pending")
+	assert.Contains(t, err.Error(), "night-routine migrate")
+
+	cfg.Service.SkipMigrate = false
+	require.NoError(t, migrateDatabase(t.Context(), cfg, db))
+	status, err := db.GetMigrationStatus()
+	require.NoError(t, err)
+	assert.False(t, status.Pending())
+	backups, err := filepath.Glob(filepath.Join(dir, "night-routine-premigrate-*.db"))
+	require.NoError(t, err)
+	assert.Empty(t, backups, "a new database is not backed up")
+
+	cfg.Service.SkipMigrate = true
+	assert.NoError(t, migrateDatabase(t.Context(), cfg, db), "an up to date schema starts with skip_migrate")
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("cmd/night-routine")
+
+// runScheduleLoop updates the schedule according to the configured update frequency until ctx is cancelled.
+// The ticker fires every minute so that any UpdateFrequency setting change
+// is picked up quickly. The actual schedule update is only executed when
+// enough time has elapsed since the last run according to the live
+// UpdateFrequency value read from the database on every tick.
+func runScheduleLoop(ctx context.Context, svc *services) {
+	logger := logging.GetLogger("main")
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	var lastScheduleRun time.Time
+
+	logger.Info().Msg("Starting main service loop")
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Msg("Context cancelled, stopping main service loop")
+			return
+
+		case <-ticker.C:
+			logger.Debug().Msg("Update schedule tick received")
+			if !svc.calSvc.IsInitialized() {
+				logger.Debug().Msg("Calendar service not initialized, attempting initialization on tick")
+				// Try to initialize calendar service if it wasn't available before
+				if err := svc.calSvc.Initialize(ctx); err != nil {
+					logger.Warn().Err(err).Msg("Calendar service still not ready")
+				} else {
+					logger.Info().Msg("Calendar service initialized successfully on scheduled check")
+					// Notification channel setup will happen on calendar selection
+				}
+				continue
+			}
+
+			// Syncing would fail on every tick until the user reconnects Google Calendar
+			if svc.tokenManager.NeedsReauthentication() {
+				logger.Debug().Msg("Google re-authentication required, skipping automatic schedule update")
+				continue
+			}
+
+			// The update runs on the first tick after the quiet hours
+			if svc.quietHours.Contains(time.Now()) {
+				logger.Debug().Str("quiet_hours", svc.quietHours.String()).Msg("Quiet hours, deferring automatic schedule update")
+				continue
+			}
+
+			// Read UpdateFrequency live from the database so that changes made in
+			// the UI take effect without requiring an application restart.
+			// (updateFrequency is the only value we use here; the rest are intentionally ignored)
+			updateFrequency, _, _, _, err := svc.runtimeConfig.GetSchedule()
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to read schedule config on tick; skipping update")
+				continue
+			}
+			if updateFrequency == "disabled" {
+				logger.Debug().Msg("Update frequency is disabled, skipping automatic schedule update")
+				continue
+			}
+			updateInterval := getUpdateInterval(updateFrequency)
+
+			if lastScheduleRun.IsZero() || time.Since(lastScheduleRun) >= updateInterval {
+				logger.Debug().Str("update_frequency", updateFrequency).Msg("Running scheduled schedule update")
+				if err := syncSchedule(ctx, svc, constants.SyncTriggerScheduled); err != nil {
+					logger.Error().Err(err).Msg("Failed to update schedule on tick")
+				} else {
+					lastScheduleRun = time.Now()
+				}
+			} else {
+				logger.Debug().
+					Str("update_frequency", updateFrequency).
+					Dur("time_until_next_run", updateInterval-time.Since(lastScheduleRun)).
+					Msg("Skipping schedule update; next run not due yet")
+			}
+		}
+	}
+}
+
+// syncSchedule updates the schedule and records the run in the sync history
+func syncSchedule(ctx context.Context, svc *services, trigger constants.SyncTrigger) error {
+	return svc.syncRuns.RecordRun(ctx, trigger, func(ctx context.Context) (int, error) {
+		return updateSchedule(ctx, svc.runtimeConfig, svc.sched, svc.calSvc, trigger)
+	})
+}
+
+// updateSchedule generates the schedule of the look-ahead window of trigger and syncs it with the
+// calendar. It returns the number of assignments synced.
+func updateSchedule(ctx context.Context, configStore config.ConfigStoreInterface, sched *scheduler.Scheduler, calSvc *calendar.Service, trigger constants.SyncTrigger) (assignmentsCount int, err error) {
+	ctx, span := tracer.Start(ctx, "schedule.update")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	scheduleLogger := logging.GetLogger("schedule-update")
+	scheduleLogger.Info().Msg("Starting schedule update")
+
+	// Read LookAheadDays live from the database so that UI setting changes
+	// take effect immediately without requiring an application restart.
+	// (updateFrequency, pastEventThresholdDays and statsOrder are intentionally ignored here)
+	_, lookAheadDays, _, _, err := configStore.GetSchedule()
+	if err != nil {
+		scheduleLogger.Error().Err(err).Msg("Failed to get schedule configuration")
+		return 0, fmt.Errorf("failed to get schedule configuration: %w", err)
+	}
+	windows, err := configStore.GetLookAheadWindows()
+	if err != nil {
+		scheduleLogger.Error().Err(err).Msg("Failed to get look-ahead windows")
+		return 0, fmt.Errorf("failed to get look-ahead windows: %w", err)
+	}
+	lookAheadDays = windows.Days(trigger, lookAheadDays)
+
+	// Calculate date range
+	now := time.Now()
+	end := now.AddDate(0, 0, lookAheadDays)
+	scheduleLogger.Debug().Time("start_date", now).Time("end_date", end).Int("lookahead_days", lookAheadDays).Msg("Calculated date range")
+
+	// The keyword events of the personal calendars of the parents make them unavailable
+	if err := calSvc.SyncAvailability(ctx, now, end); err != nil {
+		scheduleLogger.Warn().Err(err).Msg("Failed to sync the availability of the parents from their calendars")
+	}
+
+	// Generate schedule
+	generateCtx, generateSpan := tracer.Start(ctx, "schedule.generate", trace.WithAttributes(attribute.Int("schedule.look_ahead_days", lookAheadDays)))
+	assignments, err := sched.GenerateSchedule(generateCtx, now, end, time.Now())
+	tracing.RecordError(generateSpan, err)
+	generateSpan.End()
+	if err != nil {
+		scheduleLogger.Error().Err(err).Msg("Failed to generate schedule")
+		return 0, err
+	}
+	scheduleLogger.Info().Int("assignments_generated", len(assignments)).Msg("Schedule generated")
+
+	// Sync with calendar
+	if err := calSvc.SyncSchedule(ctx, assignments); err != nil {
+		scheduleLogger.Error().Err(err).Msg("Failed to sync schedule with calendar")
+		return len(assignments), err
+	}
+
+	scheduleLogger.Info().Int("days", lookAheadDays).Int("assignments", len(assignments)).Msg("Updated schedule successfully")
+	return len(assignments), nil
+}
+
+// scheduleRepairer lets the consistency checker resync the schedule with the services of the main loop
+type scheduleRepairer struct {
+	svc *services
+}
+
+// Ready reports whether a sync can succeed, automatic updates are not disabled and it is not the quiet hours
+func (r scheduleRepairer) Ready() bool {
+	if !r.svc.calSvc.IsInitialized() || r.svc.tokenManager.NeedsReauthentication() || r.svc.quietHours.Contains(time.Now()) {
+		return false
+	}
+	updateFrequency, _, _, _, err := r.svc.runtimeConfig.GetSchedule()
+	return err == nil && updateFrequency != "disabled"
+}
+
+// Repair syncs the schedule, recorded in the sync history as a repair
+func (r scheduleRepairer) Repair(ctx context.Context) error {
+	return syncSchedule(ctx, r.svc, constants.SyncTriggerRepair)
+}
+
+// getUpdateInterval returns the time between two scheduled syncs, daily for an invalid frequency
+func getUpdateInterval(frequency string) time.Duration {
+	interval, ok := config.UpdateInterval(frequency)
+	if !ok {
+		logger := logging.GetLogger("main")
+		logger.Warn().Str("frequency", frequency).Msg("Invalid update frequency specified, defaulting to daily")
+		return 24 * time.Hour
+	}
+	return interval
+}
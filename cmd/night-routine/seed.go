@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/dates"
+	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/logging"
+)
+
+// Demo data written by the seed command
+const (
+	demoParentA    = "Alice"
+	demoParentB    = "Bob"
+	demoBabysitter = "Grandma"
+)
+
+// demoUnavailability gives each demo parent a recurring unavailable day
+var demoUnavailability = map[string][]string{
+	"parent_a": {"Wednesday"},
+	"parent_b": {"Friday"},
+}
+
+// demoParentOverrides are the days, counted back from today, swapped to the other parent as manual overrides
+var demoParentOverrides = []int{9, 23, 41, 62}
+
+// demoBabysitterOverrides are the days, counted back from today, handed to a babysitter
+var demoBabysitterOverrides = []int{16, 48}
+
+// runSeed fills an empty database with sample data. The only dataset is "demo".
+func runSeed(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "demo" {
+		return withExitCode(exitUsage, errors.New("usage: night-routine seed demo [-days N]"))
+	}
+
+	fs := newFlagSet("seed demo")
+	days := fs.Int("days", 90, "days of assignment history to generate")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *days < 1 {
+		return withExitCode(exitUsage, fmt.Errorf("days must be at least 1, got %d", *days))
+	}
+
+	logger := logging.GetLogger("seed")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	svc, err := newServices(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+
+	// Never mix demo data with a real history
+	lastDate, err := svc.tracker.GetLastAssignmentDate(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check existing assignments: %w", err)
+	}
+	if !lastDate.IsZero() {
+		return fmt.Errorf("database %s already has assignments, seed demo only runs on an empty database", cfg.Service.StateFile)
+	}
+
+	if err := svc.configStore.SaveParents(ctx, demoParentA, demoParentB); err != nil {
+		return fmt.Errorf("failed to save demo parents: %w", err)
+	}
+	for parent, unavailable := range demoUnavailability {
+		if err := svc.configStore.SaveAvailability(ctx, parent, unavailable); err != nil {
+			return fmt.Errorf("failed to save demo availability: %w", err)
+		}
+	}
+
+	// Let the scheduler decide the history as if it had been running since the start
+	today := dates.Day(time.Now())
+	start := dates.AddDays(today, -*days)
+	assignments, err := svc.sched.GenerateSchedule(ctx, start, dates.AddDays(today, -1), start)
+	if err != nil {
+		return fmt.Errorf("failed to generate demo history: %w", err)
+	}
+	byDate := make(map[string]*scheduler.Assignment, len(assignments))
+	for _, a := range assignments {
+		byDate[a.Date.Format(exportDateFormat)] = a
+	}
+
+	overrides := 0
+	for _, daysAgo := range demoParentOverrides {
+		a, ok := byDate[today.AddDate(0, 0, -daysAgo).Format(exportDateFormat)]
+		if !ok {
+			continue
+		}
+		other := demoParentA
+		if a.Parent == demoParentA {
+			other = demoParentB
+		}
+		if err := svc.tracker.UpdateAssignmentParent(ctx, a.ID, other, true, a.Version); err != nil {
+			return fmt.Errorf("failed to add demo override: %w", err)
+		}
+		overrides++
+	}
+	for _, daysAgo := range demoBabysitterOverrides {
+		a, ok := byDate[today.AddDate(0, 0, -daysAgo).Format(exportDateFormat)]
+		if !ok {
+			continue
+		}
+		if err := svc.tracker.UpdateAssignmentToBabysitter(ctx, a.ID, demoBabysitter, true, a.Version); err != nil {
+			return fmt.Errorf("failed to add demo babysitter night: %w", err)
+		}
+		overrides++
+	}
+
+	logger.Info().
+		Int("assignments", len(assignments)).
+		Int("overrides", overrides).
+		Str("parent_a", demoParentA).
+		Str("parent_b", demoParentB).
+		Msg("Demo data seeded, start the server to explore it")
+	return nil
+}
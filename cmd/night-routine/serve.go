@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/handlers"
+	"github.com/belphemur/night-routine/internal/logging"
+	appSignals "github.com/belphemur/night-routine/internal/signals"
+	"github.com/belphemur/night-routine/internal/telemetry"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// runServe runs the web interface, the webhook receiver and the scheduling loop
+func runServe(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve")
+	// deviceAuth enables the OAuth device code flow, overriding app.device_auth
+	deviceAuth := fs.Bool("device-auth", false, "link Google Calendar with the OAuth device code flow (for headless servers)")
+	// adminAddr enables the pprof/expvar server, overriding app.admin_addr
+	adminAddr := fs.String("admin-addr", "", "serve pprof, expvar and the metrics on this address, e.g. 127.0.0.1:6060")
+	// demo runs offline on an in-memory calendar, overriding app.demo_mode
+	demo := fs.Bool("demo", false, "run offline on an in-memory calendar, without Google credentials")
+	// skipMigrate leaves the schema to the migrate command, overriding service.skip_migrate
+	skipMigrate := fs.Bool("skip-migrate", false, "do not apply database migrations on startup, fail while some are pending")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *demo {
+		// Set before loading, the configuration of the demo needs no OAuth credentials
+		if err := os.Setenv("NR_APP__DEMO_MODE", "true"); err != nil {
+			return fmt.Errorf("failed to enable the demo mode: %w", err)
+		}
+	}
+
+	// Get logger for the serve command
+	logger := logging.GetLogger("main")
+
+	logger.Info().
+		Str("version", version).
+		Str("commit", commit).
+		Str("build_date", date).
+		Msg("Starting Night Routine Scheduler")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if *deviceAuth {
+		cfg.App.DeviceAuth = true
+	}
+	if *adminAddr != "" {
+		cfg.App.AdminAddr = *adminAddr
+	}
+	if *skipMigrate {
+		cfg.Service.SkipMigrate = true
+	}
+
+	// The demo calendar must be listening before the calendar clients are created
+	if cfg.App.DemoMode {
+		stopDemoCalendar, err := startDemoCalendar(cfg)
+		if err != nil {
+			return err
+		}
+		defer stopDemoCalendar()
+	}
+
+	shutdownTracing, err := setupTracing(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+	setupHeartbeat(cfg)
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	svc, err := newServices(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+	if cfg.App.DemoMode {
+		if err := seedDemoAccount(ctx, svc); err != nil {
+			logger.Error().Err(err).Msg("Demo account seeding failed")
+			return err
+		}
+	}
+	setupAlerting(cfg, svc.notifications)
+	setupImbalanceAlert(cfg, svc)
+	setupAbsenceSuggestion(cfg, svc)
+	setupHooks(cfg, svc.events)
+	setupMaintenance(ctx, cfg, svc, db)
+	setupDutyReminder(ctx, cfg, svc)
+	setupConsistencyCheck(ctx, cfg, svc)
+	setupReviewTimeout(ctx, cfg, svc)
+	runtimeConfig := svc.runtimeConfig
+	tokenManager := svc.tokenManager
+	sched := svc.sched
+	calSvc := svc.calSvc
+
+	// Refresh the token ahead of expiry instead of during syncs
+	tokenManager.StartBackgroundRefresh(ctx, cfg.Service.TokenRefreshMargin)
+
+	// Initialize calendar manager
+	calendarManager := calendar.NewManager(svc.tokenStore, tokenManager, cfg.OAuth)
+	calendarManager.SetEndpoint(cfg.App.CalendarEndpoint)
+	logger.Info().Msg("Calendar service created. Waiting for authentication/initialization...")
+
+	// Initialize static file handler
+	staticHandler, err := handlers.NewStaticHandler()
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize static handler: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Static handler initialization failed")
+		return wrappedErr
+	}
+
+	// Initialize base handler first, as other handlers depend on it.
+	// runtimeConfig is the single source of truth for all configuration.
+	baseHandler, err := handlers.NewBaseHandler(runtimeConfig, svc.tokenStore, tokenManager, svc.tracker, svc.syncRuns)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize base handler: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Base handler initialization failed")
+		return wrappedErr
+	}
+	baseHandler.Build = newBuildInfo(cfg, svc.notifications.Channels())
+	baseHandler.Features = svc.features
+	telemetryCollector := telemetry.NewCollector(baseHandler.Build.Version, baseHandler.Build.Features, cfg.Telemetry.Interval, db, svc.features, svc.syncRuns)
+	setupTelemetry(ctx, cfg, telemetryCollector)
+	homeHandler := handlers.NewHomeHandler(baseHandler, sched, cfg.Notify.ImbalanceThreshold)
+
+	oauthHandler, err := handlers.NewOAuthHandler(baseHandler, calSvc, cfg.App.DeviceAuth)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize OAuth handler: %w", err)
+		logger.Error().Err(wrappedErr).Msg("OAuth handler initialization failed")
+		return wrappedErr
+	}
+	calendarHandler := handlers.NewCalendarHandler(baseHandler, calendarManager)
+	syncHandler := handlers.NewSyncHandler(baseHandler, sched, tokenManager, calSvc, runtimeConfig)
+	settingsHandler := handlers.NewSettingsHandler(baseHandler, svc.configStore, sched, tokenManager, calSvc, svc.notifications.Channels())
+	statisticsHandler := handlers.NewStatisticsHandler(baseHandler, svc.configStore, svc.monthlyStats, svc.checklists, svc.acknowledgements)
+	reportHandler := handlers.NewReportHandler(baseHandler, svc.reports)
+	exportHandler := handlers.NewExportHandler(baseHandler, svc.tracker)
+	searchHandler := handlers.NewAssignmentSearchHandler(baseHandler, svc.tracker)
+	upcomingHandler := handlers.NewUpcomingHandler(baseHandler)
+	unlockHandler := handlers.NewUnlockHandler(baseHandler, svc.tracker, sched, calSvc, runtimeConfig)
+	childrenHandler := handlers.NewChildrenHandler(baseHandler, sched, calSvc, runtimeConfig)
+	assignmentDetailsHandler := handlers.NewAssignmentDetailsHandler(baseHandler, svc.tracker, sched, calSvc, runtimeConfig)
+	assignmentEditHandler := handlers.NewAssignmentEditHandler(baseHandler, svc.tracker, sched, calSvc, runtimeConfig)
+	assignmentsHandler := handlers.NewAssignmentsHandler(baseHandler, svc.tracker, sched, calSvc, runtimeConfig)
+	claimHandler := handlers.NewClaimHandler(baseHandler, svc.tracker, sched, calSvc, runtimeConfig, svc.notifications)
+	acknowledgementHandler := handlers.NewAcknowledgementHandler(baseHandler, svc.acknowledgements, svc.parentLinks)
+	parentContactsHandler := handlers.NewParentContactsHandler(baseHandler, svc.contacts, runtimeConfig)
+	swapHandler := handlers.NewSwapHandler(baseHandler, svc.tracker, sched, calSvc, runtimeConfig, svc.swapRequests, svc.notifications, cfg.App.AppUrl)
+	voiceHandler := handlers.NewVoiceHandler(baseHandler, claimHandler, svc.parentLinks)
+	undoHandler := handlers.NewUndoHandler(baseHandler, svc.tracker, sched, calSvc)
+	wipeHandler := handlers.NewWipeHandler(baseHandler, calSvc, db)
+	lockHandler := handlers.NewLockHandler(baseHandler)
+	channelsHandler := handlers.NewChannelsHandler(baseHandler, calSvc)
+	checklistHandler := handlers.NewChecklistHandler(baseHandler, svc.checklists)
+	commentsHandler := handlers.NewCommentsHandler(baseHandler, svc.comments)
+	dayHandler := handlers.NewDayHandler(baseHandler, svc.checklists, svc.comments)
+	healthHandler := handlers.NewHealthHandler(baseHandler, db)
+	syncRunsHandler := handlers.NewSyncRunsHandler(baseHandler)
+	deliveriesHandler := handlers.NewNotificationDeliveriesHandler(baseHandler, svc.deliveries)
+	statusHandler := handlers.NewStatusHandler(baseHandler, db)
+	publicStatusHandler := handlers.NewPublicStatusHandler(baseHandler, db)
+	versionHandler := handlers.NewVersionHandler(baseHandler)
+	setupHandler := handlers.NewSetupHandler(baseHandler, svc.configStore)
+	devicesHandler := handlers.NewDevicesHandler(baseHandler, cfg.App.AppUrl)
+	absenceSuggestionHandler := handlers.NewAbsenceSuggestionHandler(baseHandler, svc.tracker, svc.configStore, sched, calSvc, runtimeConfig)
+	reviewHandler := handlers.NewReviewHandler(baseHandler, svc.staging, calSvc, cfg.Schedule.ReviewTimeout)
+	selfServiceHandler := handlers.NewSelfServiceHandler(baseHandler, svc.parentLinks, svc.configStore, sched, calSvc, cfg.App.AppUrl)
+	featureFlagsHandler := handlers.NewFeatureFlagsHandler(baseHandler, svc.features)
+	telemetryHandler := handlers.NewTelemetryHandler(baseHandler, telemetryCollector, cfg.Telemetry)
+	icsHandler := handlers.NewICSHandler(baseHandler, svc.parentLinks, svc.tracker, cfg.Branding.EventEmoji, cfg.Branding.EventIdentifier)
+	passkeyHandler, err := handlers.NewPasskeyHandler(baseHandler, svc.passkeys, cfg.App.AppUrl, cfg.App.PasskeyLogin)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to initialize passkey handler: %w", err)
+		logger.Error().Err(wrappedErr).Msg("Passkey handler initialization failed")
+		return wrappedErr
+	}
+
+	// Register routes
+	staticHandler.RegisterRoutes()
+	homeHandler.RegisterRoutes()
+	oauthHandler.RegisterRoutes()
+	calendarHandler.RegisterRoutes()
+	syncHandler.RegisterRoutes()
+	settingsHandler.RegisterRoutes()
+	statisticsHandler.RegisterRoutes()
+	reportHandler.RegisterRoutes()
+	exportHandler.RegisterRoutes()
+	searchHandler.RegisterRoutes()
+	upcomingHandler.RegisterRoutes()
+	unlockHandler.RegisterRoutes()
+	childrenHandler.RegisterRoutes()
+	assignmentDetailsHandler.RegisterRoutes()
+	assignmentEditHandler.RegisterRoutes()
+	assignmentsHandler.RegisterRoutes()
+	claimHandler.RegisterRoutes()
+	acknowledgementHandler.RegisterRoutes()
+	swapHandler.RegisterRoutes()
+	parentContactsHandler.RegisterRoutes()
+	voiceHandler.RegisterRoutes()
+	undoHandler.RegisterRoutes()
+	wipeHandler.RegisterRoutes()
+	lockHandler.RegisterRoutes()
+	channelsHandler.RegisterRoutes()
+	checklistHandler.RegisterRoutes()
+	commentsHandler.RegisterRoutes()
+	dayHandler.RegisterRoutes()
+	healthHandler.RegisterRoutes()
+	syncRunsHandler.RegisterRoutes()
+	deliveriesHandler.RegisterRoutes()
+	statusHandler.RegisterRoutes()
+	publicStatusHandler.RegisterRoutes()
+	versionHandler.RegisterRoutes()
+	setupHandler.RegisterRoutes()
+	devicesHandler.RegisterRoutes()
+	absenceSuggestionHandler.RegisterRoutes()
+	reviewHandler.RegisterRoutes()
+	selfServiceHandler.RegisterRoutes()
+	icsHandler.RegisterRoutes()
+	featureFlagsHandler.RegisterRoutes()
+	telemetryHandler.RegisterRoutes()
+	passkeyHandler.RegisterRoutes()
+
+	// Start HTTP server
+	srv := &http.Server{
+		Addr: fmt.Sprintf(":%d", cfg.App.Port),
+		Handler: otelhttp.NewHandler(hideDebugRoutes(passkeyHandler.RequireLogin(setupHandler.RequireSetup(http.DefaultServeMux))), "http.server",
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + r.URL.Path
+			}),
+		),
+	}
+
+	// Start HTTP server in a goroutine
+	go func() {
+		logger.Info().Int("port", cfg.App.Port).Msg("Starting OAuth web server")
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			logger.Error().Err(err).Msg("HTTP server error")
+		}
+	}()
+
+	// Start the admin server only when asked, it exposes profiles and memory statistics
+	var adminSrv *http.Server
+	if cfg.App.AdminAddr != "" {
+		adminSrv = newAdminServer(cfg.App.AdminAddr, newMetricsHandler(svc.tracker, runtimeConfig, time.Now))
+		go func() {
+			logger.Warn().Str("addr", cfg.App.AdminAddr).Msg("Starting admin server with pprof, expvar and metrics, do not expose it publicly")
+			if err := adminSrv.ListenAndServe(); err != http.ErrServerClosed {
+				logger.Error().Err(err).Msg("Admin server error")
+			}
+		}()
+	}
+
+	// Set up webhook handler using the calendar service (will be initialized later).
+	// runtimeConfig is passed so the handler reads the current schedule settings,
+	// picking up UI setting changes without a restart. Bursts of notifications are
+	// coalesced over app.webhook_debounce into a single recalculation and sync, deferred
+	// until the end of service.quiet_hours.
+	webhookHandler := handlers.NewWebhookHandler(baseHandler, calSvc, sched, tokenManager, runtimeConfig, cfg.Branding.EventIdentifier, cfg.App.WebhookDebounce, svc.quietHours)
+	webhookHandler.CalendarEndpoint = cfg.App.CalendarEndpoint
+	webhookHandler.OverrideNotices = svc.notifications
+	webhookHandler.AppURL = cfg.App.AppUrl
+	webhookHandler.RegisterRoutes()
+	// Overrides are still detected when the notifications cannot reach public_url, e.g. behind a home NAT
+	if cfg.App.WebhookPollInterval > 0 {
+		webhookHandler.StartPolling(ctx, cfg.App.WebhookPollInterval)
+	}
+
+	// Check for existing token and initialize calendar service if found
+	hasToken, _ := tokenManager.HasToken()
+	if hasToken {
+		logger.Info().Msg("Token found, attempting initial calendar service initialization and notification setup")
+		if !calSvc.IsInitialized() {
+			if err := calSvc.Initialize(ctx); err != nil {
+				// Log as warning, app can continue without calendar initially
+				logger.Warn().Err(err).Msg("Initial calendar service initialization failed")
+			} else {
+				logger.Info().Msg("Initial calendar service initialization successful")
+				// Set up notification channel for calendar changes only if initialized
+				if err := calSvc.SetupNotificationChannel(ctx); err != nil {
+					logger.Warn().Err(err).Msg("Failed to set up notification channel after initial check")
+				} else {
+					logger.Info().Msg("Successfully set up notification channel after initial check")
+				}
+			}
+		} else {
+			logger.Info().Msg("Calendar service already initialized")
+			// Ensure notification channel is set up if already initialized
+			if err := calSvc.SetupNotificationChannel(ctx); err != nil {
+				logger.Warn().Err(err).Msg("Failed to set up notification channel (service already initialized)")
+			} else {
+				logger.Info().Msg("Successfully set up notification channel (service already initialized)")
+			}
+		}
+	} else {
+		logger.Info().Msg("No token found initially. Waiting for OAuth flow.")
+	}
+
+	// Perform manual sync on startup if configured and possible
+	performManualStartupSync(ctx, cfg.Service.ManualSyncOnStartup, svc, hasToken)
+
+	// Register handler for token setup signals
+	appSignals.OnTokenSetup(func(ctx context.Context, data appSignals.TokenSetupData) {
+		signalLogger := logging.GetLogger("signal-token-setup")
+		if data.Success {
+			signalLogger.Info().Msg("Token setup detected - initializing calendar service")
+
+			// Initialize the calendar service with the new token
+			// This might be redundant if already initialized above, but Initialize handles that.
+			if err := calSvc.Initialize(ctx); err != nil {
+				signalLogger.Error().Err(err).Msg("Failed to initialize calendar service after token setup")
+				return
+			}
+
+			signalLogger.Info().Msg("Calendar service initialized successfully after token setup")
+
+			// We don't set up notification channels here anymore,
+			// they will be set up when a calendar is selected
+		} else {
+			signalLogger.Info().Msg("Token cleared - calendar service awaits a new authentication")
+		}
+	}, "main-token-setup-handler")
+
+	// Permanent token refresh failures are logged and alerted by setupAlerting
+
+	// Register handler for calendar selection signals
+	appSignals.OnCalendarSelected(func(ctx context.Context, data appSignals.CalendarSelectedData) {
+		signalLogger := logging.GetLogger("signal-calendar-selected")
+		signalLogger.Info().Str("calendar_id", data.CalendarID).Msg("Calendar selection detected - setting up notification channel")
+
+		// Initialize calendar service if not already initialized (should be rare here)
+		if !calSvc.IsInitialized() {
+			signalLogger.Warn().Msg("Calendar service not initialized during calendar selection, attempting initialization")
+			if err := calSvc.Initialize(ctx); err != nil {
+				signalLogger.Error().Err(err).Msg("Failed to initialize calendar service on calendar selection")
+				return
+			}
+			signalLogger.Info().Msg("Calendar service initialized successfully during calendar selection")
+		}
+
+		// Set up notification channel for calendar changes
+		if err := calSvc.SetupNotificationChannel(ctx); err != nil {
+			signalLogger.Warn().Err(err).Msg("Failed to set up notification channel after calendar selection")
+		} else {
+			signalLogger.Info().Msg("Successfully set up notification channel after calendar selection")
+		}
+
+		// Update schedule immediately after calendar selection
+		if err := syncSchedule(ctx, svc, constants.SyncTriggerCalendarSelected); err != nil {
+			signalLogger.Error().Err(err).Msg("Failed to update schedule after calendar selection")
+		}
+	}, "main-calendar-selected-handler")
+
+	// Main service loop, returns once the context is cancelled
+	runScheduleLoop(ctx, svc)
+
+	logger.Info().Msg("Initiating shutdown sequence")
+	// Stop notification channels if calendar service is available
+	if calSvc.IsInitialized() {
+		logger.Info().Msg("Stopping notification channels...")
+		if err := calSvc.StopAllNotificationChannels(context.Background()); err != nil { // Use background context for shutdown
+			logger.Warn().Err(err).Msg("Failed to stop notification channels")
+		} else {
+			logger.Info().Msg("Notification channels stopped")
+		}
+	}
+
+	// Shutdown HTTP server
+	logger.Info().Msg("Shutting down HTTP server...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error().Err(err).Msg("HTTP server shutdown error")
+	} else {
+		logger.Info().Msg("HTTP server shut down gracefully")
+	}
+	// Process the notifications still waiting for their coalescing window
+	webhookHandler.Close()
+	// Stop polling Google for a device authorization still pending
+	oauthHandler.Close()
+	// Deliver the domain events still queued for the subscribers
+	svc.events.Close(shutdownCtx)
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+			logger.Error().Err(err).Msg("Admin server shutdown error")
+		}
+	}
+	logger.Info().Msg("Shutdown complete")
+	return nil
+}
+
+// performManualStartupSync checks the config and performs a schedule sync if enabled and possible.
+// It assumes calSvc initialization was already attempted if hasToken is true.
+func performManualStartupSync(ctx context.Context, manualSyncOnStartup bool, svc *services, hasToken bool) {
+	logger := logging.GetLogger("manual-startup-sync") // Get logger specific to this function
+
+	if !manualSyncOnStartup {
+		return // Feature not enabled
+	}
+
+	logger.Info().Msg("Manual sync on startup configured.")
+	if !hasToken {
+		logger.Warn().Msg("Manual sync on startup configured, but no token found. Skipping sync.")
+		return
+	}
+
+	// Check if the calendar service is actually initialized (initial attempt might have failed)
+	if !svc.calSvc.IsInitialized() {
+		logger.Warn().Msg("Cannot perform manual sync on startup: Calendar service failed to initialize earlier.")
+		return
+	}
+
+	// Perform the sync
+	logger.Info().Msg("Performing manual schedule sync on startup...")
+	if err := syncSchedule(ctx, svc, constants.SyncTriggerStartup); err != nil {
+		logger.Error().Err(err).Msg("Manual schedule sync on startup failed")
+	} else {
+		logger.Info().Msg("Manual schedule sync on startup completed successfully")
+	}
+}
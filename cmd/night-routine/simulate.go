@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/dates"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/validation"
+)
+
+// simulationConfig serves the live settings, replaced by the hypothetical values of a simulation
+type simulationConfig struct {
+	config.ConfigStoreInterface
+	availability map[string][]string // Key: config.ParentKey
+}
+
+// GetAvailability returns the simulated availability of a parent when one was given
+func (c *simulationConfig) GetAvailability(parent string) ([]string, error) {
+	if days, ok := c.availability[parent]; ok {
+		return days, nil
+	}
+	return c.ConfigStoreInterface.GetAvailability(parent)
+}
+
+// unavailableFlag collects the repeatable --unavailable flag of the simulate command, each value
+// giving the unavailable days of a parent by the key of its settings, like parent_c=Monday,Tuesday
+type unavailableFlag map[string][]string
+
+// String returns the parents of the flag, for the flag package
+func (f unavailableFlag) String() string {
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// Set parses a parent_x=days value, see parseWeekdays for the days
+func (f unavailableFlag) Set(value string) error {
+	key, days, ok := strings.Cut(value, "=")
+	key = strings.TrimSpace(key)
+	if !ok {
+		return fmt.Errorf("invalid unavailability %q, expected parent_x=days", value)
+	}
+	if !config.IsParentKey(key) {
+		return fmt.Errorf("invalid parent key %q: must be %s to %s, at most %d parents take turns",
+			key, config.ParentKey(0), config.ParentKey(validation.MaxParents-1), validation.MaxParents)
+	}
+	unavailable, err := parseWeekdays(days)
+	if err != nil {
+		return err
+	}
+	f[key] = unavailable
+	return nil
+}
+
+// simulatedParent is the projected distribution of one parent
+type simulatedParent struct {
+	Parent        string         `json:"parent"`
+	Nights        int            `json:"nights"`
+	Share         float64        `json:"share"`
+	LongestStreak int            `json:"longest_streak"`
+	Weekdays      map[string]int `json:"weekdays"`
+}
+
+// simulationReport is the output of the simulate command
+type simulationReport struct {
+	Start         string            `json:"start"`
+	End           string            `json:"end"`
+	Parents       []simulatedParent `json:"parents"`
+	Reasons       map[string]int    `json:"reasons"`
+	FairnessScore float64           `json:"fairness_score"`
+}
+
+// runSimulate runs the scheduler on a scratch in-memory database with hypothetical settings and
+// prints the projected distribution. The state database is only read.
+func runSimulate(ctx context.Context, args []string) error {
+	fs := newFlagSet("simulate")
+	startFlag := fs.String("start", "", "first simulated day, YYYY-MM-DD (default: today)")
+	days := fs.Int("days", 90, "number of days to simulate")
+	history := fs.Bool("history", true, "start from the recorded history before the first day; false starts from a blank slate")
+	parentAUnavailable := fs.String("parent-a-unavailable", "", "comma-separated days parent A is unavailable, 'none' for no day (default: current setting)")
+	parentBUnavailable := fs.String("parent-b-unavailable", "", "comma-separated days parent B is unavailable, 'none' for no day (default: current setting)")
+	availability := make(unavailableFlag)
+	fs.Var(availability, "unavailable", "parent_x=days: comma-separated days the parent of the settings key parent_a to parent_f is unavailable, 'none' for no day; repeatable (default: current setting)")
+	format := fs.String("format", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "table" && *format != "json" {
+		return withExitCode(exitUsage, fmt.Errorf("invalid output format: %s (must be table or json)", *format))
+	}
+	if *days < 1 {
+		return withExitCode(exitUsage, fmt.Errorf("days must be at least 1, got %d", *days))
+	}
+	start, err := parseExportDate(*startFlag, dates.Day(time.Now()))
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	for key, value := range map[string]string{config.ParentKey(0): *parentAUnavailable, config.ParentKey(1): *parentBUnavailable} {
+		if value == "" {
+			continue
+		}
+		if err := availability.Set(key + "=" + value); err != nil {
+			return withExitCode(exitUsage, err)
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	svc, err := newServices(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+
+	scratch, scratchTracker, err := openScratchTracker()
+	if err != nil {
+		return err
+	}
+	defer scratch.Close()
+
+	if *history {
+		if err := copyHistory(ctx, svc.tracker, scratchTracker, dates.AddDays(start, -1)); err != nil {
+			return err
+		}
+	}
+
+	simConfig := &simulationConfig{ConfigStoreInterface: svc.runtimeConfig, availability: availability}
+	roster, err := config.Roster(simConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get parents: %w", err)
+	}
+	for key := range availability {
+		if index, _ := config.ParentKeyIndex(key); index >= len(roster) {
+			return withExitCode(exitUsage, fmt.Errorf("no parent has the key %s: %d parents take turns, %s to %s",
+				key, len(roster), config.ParentKey(0), config.ParentKey(len(roster)-1)))
+		}
+	}
+
+	end := dates.AddDays(start, *days-1)
+	assignments, err := scheduler.New(simConfig, scratchTracker).GenerateSchedule(ctx, start, end, start)
+	if err != nil {
+		return fmt.Errorf("failed to simulate schedule: %w", err)
+	}
+
+	report := buildSimulationReport(roster, start, end, assignments)
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to write simulation: %w", err)
+		}
+		return nil
+	}
+	return writeSimulationTable(os.Stdout, report)
+}
+
+// parseWeekdays parses a comma-separated list of day names; "none" is the empty list
+func parseWeekdays(value string) ([]string, error) {
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return []string{}, nil
+	}
+	var days []string
+	for _, part := range strings.Split(value, ",") {
+		day := strings.TrimSpace(part)
+		if day == "" {
+			continue
+		}
+		if !constants.IsValidDayOfWeek(day) {
+			return nil, fmt.Errorf("invalid day of week: %s", day)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// openScratchTracker opens a tracker on a scratch in-memory database, receiving the assignments
+// computed without touching the state database
+func openScratchTracker() (*database.DB, *fairness.Tracker, error) {
+	scratch, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create scratch database: %w", err)
+	}
+	if err := scratch.MigrateDatabase(); err != nil {
+		scratch.Close()
+		return nil, nil, fmt.Errorf("failed to initialize scratch database: %w", err)
+	}
+	tracker, err := fairness.New(scratch)
+	if err != nil {
+		scratch.Close()
+		return nil, nil, fmt.Errorf("failed to initialize scratch tracker: %w", err)
+	}
+	return scratch, tracker, nil
+}
+
+// copyHistory replays the assignments recorded through the last day into the scratch tracker,
+// with their calendar events
+func copyHistory(ctx context.Context, from, to fairness.TrackerInterface, last time.Time) error {
+	recorded, err := from.GetAssignmentsInRange(ctx, time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC), last)
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	for _, a := range recorded {
+		var copied *fairness.Assignment
+		if a.CaregiverType == fairness.CaregiverTypeBabysitter {
+			copied, err = to.RecordBabysitterAssignment(ctx, a.Parent, a.Date, a.Override)
+		} else {
+			copied, err = to.RecordAssignment(ctx, a.Parent, a.Date, a.Override, a.DecisionReason)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to copy history: %w", err)
+		}
+		if a.GoogleCalendarEventID != "" {
+			if err := to.UpdateAssignmentGoogleCalendarEventID(ctx, copied.ID, a.GoogleCalendarEventID); err != nil {
+				return fmt.Errorf("failed to copy history: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// buildSimulationReport summarizes the simulated assignments of the given parents
+func buildSimulationReport(parents []string, start, end time.Time, assignments []*scheduler.Assignment) simulationReport {
+	report := simulationReport{
+		Start:   start.Format(exportDateFormat),
+		End:     end.Format(exportDateFormat),
+		Reasons: make(map[string]int),
+	}
+
+	byParent := make(map[string]*simulatedParent, len(parents))
+	for _, parent := range parents {
+		byParent[parent] = &simulatedParent{Parent: parent, Weekdays: make(map[string]int)}
+	}
+
+	streakParent, streak := "", 0
+	for _, a := range assignments {
+		report.Reasons[a.DecisionReason.String()]++
+
+		if a.Parent == streakParent {
+			streak++
+		} else {
+			streakParent, streak = a.Parent, 1
+		}
+
+		p, ok := byParent[a.Parent]
+		if !ok {
+			continue // babysitter nights only count in the reasons
+		}
+		p.Nights++
+		p.Weekdays[a.Date.Weekday().String()]++
+		p.LongestStreak = max(p.LongestStreak, streak)
+	}
+
+	totals := make([]int, 0, len(parents))
+	for _, parent := range parents {
+		p := byParent[parent]
+		if len(assignments) > 0 {
+			p.Share = float64(p.Nights) / float64(len(assignments)) * 100
+		}
+		report.Parents = append(report.Parents, *p)
+		totals = append(totals, p.Nights)
+	}
+	report.FairnessScore = fairnessScore(totals)
+	return report
+}
+
+// writeSimulationTable writes the simulation report as aligned tables
+func writeSimulationTable(w io.Writer, report simulationReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Simulated %s to %s\n\n", report.Start, report.End)
+
+	fmt.Fprintln(tw, "PARENT\tNIGHTS\tSHARE\tLONGEST STREAK")
+	for _, p := range report.Parents {
+		fmt.Fprintf(tw, "%s\t%d\t%.0f%%\t%d\n", p.Parent, p.Nights, p.Share, p.LongestStreak)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "Fairness score: %.0f%% (100%% means evenly shared)\n\n", report.FairnessScore)
+
+	header := []string{"WEEKDAY"}
+	for _, p := range report.Parents {
+		header = append(header, p.Parent)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, day := range constants.GetAllDaysOfWeek() {
+		fmt.Fprint(tw, day)
+		for _, p := range report.Parents {
+			fmt.Fprintf(tw, "\t%d", p.Weekdays[day])
+		}
+		fmt.Fprintln(tw)
+	}
+
+	reasons := make([]string, 0, len(report.Reasons))
+	for reason := range report.Reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "REASON\tNIGHTS")
+	for _, reason := range reasons {
+		fmt.Fprintf(tw, "%s\t%d\n", reason, report.Reasons[reason])
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write simulation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWeekdays(t *testing.T) {
+	days, err := parseWeekdays("Monday, Friday")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Monday", "Friday"}, days)
+
+	days, err = parseWeekdays("none")
+	require.NoError(t, err)
+	assert.Empty(t, days)
+	assert.NotNil(t, days, "none must clear the availability instead of keeping the current setting")
+
+	_, err = parseWeekdays("Funday")
+	assert.Error(t, err)
+}
+
+func TestUnavailableFlag(t *testing.T) {
+	availability := make(unavailableFlag)
+	require.NoError(t, availability.Set("parent_c=Monday,Tuesday"))
+	require.NoError(t, availability.Set("parent_a=none"))
+	assert.Equal(t, unavailableFlag{"parent_a": {}, "parent_c": {"Monday", "Tuesday"}}, availability)
+	assert.Equal(t, "parent_a,parent_c", availability.String())
+
+	assert.Error(t, availability.Set("parent_c"), "the days are required")
+	assert.Error(t, availability.Set("parent_c=Funday"))
+	err := availability.Set("parent_g=Monday")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be parent_a to parent_f, at most 6 parents take turns")
+}
+
+func TestBuildSimulationReport(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC) // a Monday
+	parents := []string{"Alice", "Bob", "Alice", "Alice", "Grandma", "Bob"}
+	assignments := make([]*scheduler.Assignment, 0, len(parents))
+	for i, parent := range parents {
+		assignments = append(assignments, &scheduler.Assignment{
+			Date:           start.AddDate(0, 0, i),
+			Parent:         parent,
+			DecisionReason: fairness.DecisionReasonTotalCount,
+		})
+	}
+	assignments[4].DecisionReason = fairness.DecisionReasonOverride
+
+	report := buildSimulationReport([]string{"Alice", "Bob"}, start, start.AddDate(0, 0, 5), assignments)
+
+	assert.Equal(t, "2024-01-01", report.Start)
+	assert.Equal(t, "2024-01-06", report.End)
+	require.Len(t, report.Parents, 2)
+	alice, bob := report.Parents[0], report.Parents[1]
+	assert.Equal(t, 3, alice.Nights)
+	assert.Equal(t, 2, alice.LongestStreak)
+	assert.InDelta(t, 50, alice.Share, 0.001)
+	assert.Equal(t, 1, alice.Weekdays["Monday"])
+	assert.Equal(t, 2, bob.Nights)
+	assert.Equal(t, 1, bob.LongestStreak)
+	assert.Equal(t, map[string]int{"Total Count": 5, "Override": 1}, report.Reasons)
+	assert.InDelta(t, 66.667, report.FairnessScore, 0.001)
+}
+
+func TestCopyHistory(t *testing.T) {
+	state, err := database.New(database.SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "state.db"),
+		Mode:        "rwc",
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	defer state.Close()
+	require.NoError(t, state.MigrateDatabase())
+	stateTracker, err := fairness.New(state)
+	require.NoError(t, err)
+	scratch, scratchTracker, err := openScratchTracker()
+	require.NoError(t, err)
+	defer scratch.Close()
+
+	day := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.Local)
+	first, err := stateTracker.RecordAssignment(t.Context(), "Alice", day, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	require.NoError(t, stateTracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), first.ID, "event-1"))
+	_, err = stateTracker.RecordBabysitterAssignment(t.Context(), "Grandma", day.AddDate(0, 0, 1), true)
+	require.NoError(t, err)
+	_, err = stateTracker.RecordAssignment(t.Context(), "Bob", day.AddDate(0, 0, 2), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	require.NoError(t, copyHistory(t.Context(), stateTracker, scratchTracker, day.AddDate(0, 0, 1)))
+
+	copied, err := scratchTracker.GetAssignmentsInRange(t.Context(), day, day.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	require.Len(t, copied, 2, "the assignments after the last day are left out")
+	assert.Equal(t, "Alice", copied[0].Parent)
+	assert.Equal(t, "event-1", copied[0].GoogleCalendarEventID)
+	assert.Equal(t, "Grandma", copied[1].Parent)
+	assert.Equal(t, fairness.CaregiverTypeBabysitter, copied[1].CaregiverType)
+	assert.True(t, copied[1].Override)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+// parentReport holds the totals of a parent in the stats report
+type parentReport struct {
+	Parent     string `json:"parent"`
+	Total      int    `json:"total"`
+	Last30Days int    `json:"last_30_days"`
+}
+
+// statsReport is the output of the stats command
+type statsReport struct {
+	Parents       []parentReport            `json:"parents"`
+	FairnessScore float64                   `json:"fairness_score"`
+	Monthly       map[string]map[string]int `json:"monthly"` // Key: "YYYY-MM", then parent name
+}
+
+// runStats prints per-parent totals, a monthly breakdown and the fairness score
+func runStats(ctx context.Context, args []string) error {
+	fs := newFlagSet("stats")
+	months := fs.Int("months", 12, "number of months in the monthly breakdown")
+	format := fs.String("format", "table", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "table" && *format != "json" {
+		return withExitCode(exitUsage, fmt.Errorf("invalid output format: %s (must be table or json)", *format))
+	}
+	if *months < 1 {
+		return withExitCode(exitUsage, fmt.Errorf("months must be at least 1, got %d", *months))
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	svc, err := newServices(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+
+	roster, err := config.Roster(svc.runtimeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get parents: %w", err)
+	}
+
+	now := time.Now()
+	// Stats are computed strictly before the given date, so tomorrow includes tonight
+	stats, err := svc.tracker.GetParentStatsUntil(ctx, now.AddDate(0, 0, 1), roster...)
+	if err != nil {
+		return fmt.Errorf("failed to get parent statistics: %w", err)
+	}
+	monthlyRows, err := svc.tracker.GetParentMonthlyStatsForLastNMonths(ctx, now, *months)
+	if err != nil {
+		return fmt.Errorf("failed to get monthly statistics: %w", err)
+	}
+
+	report := buildStatsReport(roster, stats, monthlyRows)
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to write statistics: %w", err)
+		}
+		return nil
+	}
+	return writeStatsTable(os.Stdout, report)
+}
+
+// buildStatsReport assembles the report of the given parents
+func buildStatsReport(parents []string, stats map[string]fairness.Stats, monthlyRows []fairness.MonthlyStatRow) statsReport {
+	report := statsReport{Monthly: make(map[string]map[string]int)}
+	totals := make([]int, 0, len(parents))
+	for _, parent := range parents {
+		s := stats[parent]
+		report.Parents = append(report.Parents, parentReport{Parent: parent, Total: s.TotalAssignments, Last30Days: s.Last30Days})
+		totals = append(totals, s.TotalAssignments)
+	}
+	report.FairnessScore = fairnessScore(totals)
+
+	for _, row := range monthlyRows {
+		if report.Monthly[row.MonthYear] == nil {
+			report.Monthly[row.MonthYear] = make(map[string]int)
+		}
+		report.Monthly[row.MonthYear][row.ParentName] = row.Count
+	}
+	return report
+}
+
+// fairnessScore rates how evenly the nights are shared, from 0 to 100. It is the ratio between
+// the smallest and the largest total, so 100 means every parent has the same number of nights.
+func fairnessScore(totals []int) float64 {
+	if len(totals) == 0 {
+		return 100
+	}
+	lowest, highest := totals[0], totals[0]
+	for _, total := range totals[1:] {
+		lowest = min(lowest, total)
+		highest = max(highest, total)
+	}
+	if highest == 0 {
+		return 100
+	}
+	return float64(lowest) / float64(highest) * 100
+}
+
+// writeStatsTable writes the report as aligned tables
+func writeStatsTable(w io.Writer, report statsReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PARENT\tTOTAL\tLAST 30 DAYS")
+	for _, p := range report.Parents {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", p.Parent, p.Total, p.Last30Days)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "Fairness score: %.0f%% (100%% means evenly shared)\n", report.FairnessScore)
+
+	if len(report.Monthly) > 0 {
+		monthKeys := make([]string, 0, len(report.Monthly))
+		for month := range report.Monthly {
+			monthKeys = append(monthKeys, month)
+		}
+		sort.Strings(monthKeys)
+
+		header := []string{"MONTH"}
+		for _, p := range report.Parents {
+			header = append(header, p.Parent)
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, month := range monthKeys {
+			fmt.Fprint(tw, month)
+			for _, p := range report.Parents {
+				fmt.Fprintf(tw, "\t%d", report.Monthly[month][p.Parent])
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write statistics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairnessScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		totals   []int
+		expected float64
+	}{
+		{name: "no history", totals: []int{0, 0}, expected: 100},
+		{name: "evenly shared", totals: []int{10, 10}, expected: 100},
+		{name: "uneven", totals: []int{6, 8}, expected: 75},
+		{name: "one parent only", totals: []int{0, 4}, expected: 0},
+		{name: "no parents", totals: nil, expected: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, fairnessScore(tt.totals), 0.001)
+		})
+	}
+}
+
+func TestBuildStatsReport(t *testing.T) {
+	stats := map[string]fairness.Stats{
+		"Alice": {TotalAssignments: 9, Last30Days: 3},
+		"Bob":   {TotalAssignments: 12, Last30Days: 4},
+	}
+	rows := []fairness.MonthlyStatRow{
+		{ParentName: "Alice", MonthYear: "2024-01", Count: 5},
+		{ParentName: "Bob", MonthYear: "2024-01", Count: 6},
+		{ParentName: "Bob", MonthYear: "2024-02", Count: 2},
+	}
+
+	report := buildStatsReport([]string{"Alice", "Bob"}, stats, rows)
+
+	assert.Equal(t, []parentReport{
+		{Parent: "Alice", Total: 9, Last30Days: 3},
+		{Parent: "Bob", Total: 12, Last30Days: 4},
+	}, report.Parents)
+	assert.InDelta(t, 75, report.FairnessScore, 0.001)
+	assert.Equal(t, 5, report.Monthly["2024-01"]["Alice"])
+	assert.Equal(t, 2, report.Monthly["2024-02"]["Bob"])
+	assert.Zero(t, report.Monthly["2024-02"]["Alice"])
+}
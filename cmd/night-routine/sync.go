@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/logging"
+)
+
+// runSync runs the scheduling loop without the web interface, or a single schedule update
+// with --once. Google Calendar must already be linked and a calendar selected through the
+// web interface of the serve command.
+func runSync(ctx context.Context, args []string) error {
+	fs := newFlagSet("sync")
+	once := fs.Bool("once", false, "generate and sync the schedule once, then exit (for cron and systemd timers)")
+	// skipMigrate leaves the schema to the migrate command, overriding service.skip_migrate
+	skipMigrate := fs.Bool("skip-migrate", false, "do not apply database migrations on startup, fail while some are pending")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	logger := logging.GetLogger("main")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if *skipMigrate {
+		cfg.Service.SkipMigrate = true
+	}
+
+	shutdownTracing, err := setupTracing(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	setupHeartbeat(cfg)
+
+	svc, err := newServices(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+	setupHooks(cfg, svc.events)
+	defer func() {
+		// Deliver the domain events still queued before the database is closed
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		svc.events.Close(closeCtx)
+	}()
+
+	hasToken, err := svc.tokenManager.HasToken()
+	if err != nil {
+		return fmt.Errorf("failed to check token: %w", err)
+	}
+	if !hasToken {
+		return withExitCode(exitAuth, errors.New("no Google token found, link Google Calendar from the web interface first"))
+	}
+
+	if !*once {
+		// A one-shot run exits before an alert could be delivered; cron reports its exit code instead
+		setupAlerting(cfg, svc.notifications)
+		// Refresh the token ahead of expiry instead of during syncs
+		svc.tokenManager.StartBackgroundRefresh(ctx, cfg.Service.TokenRefreshMargin)
+	}
+
+	if err := svc.calSvc.Initialize(ctx); err != nil {
+		return withExitCode(syncFailureCode(svc, exitFailure), fmt.Errorf("failed to initialize calendar service: %w", err))
+	}
+
+	if *once {
+		if err := syncSchedule(ctx, svc, constants.SyncTriggerCLI); err != nil {
+			return withExitCode(syncFailureCode(svc, exitSyncError), err)
+		}
+		logger.Info().Msg("One-shot schedule sync completed")
+		return nil
+	}
+
+	// Without the web server no webhook can be received, so no notification channel is set up
+	logger.Info().Msg("Running schedule updates without the web interface")
+	runScheduleLoop(ctx, svc)
+	return nil
+}
+
+// syncFailureCode reports a failure as an authentication problem when the Google token can no
+// longer be refreshed, since only reconnecting Google Calendar can fix it
+func syncFailureCode(svc *services, fallback int) int {
+	if svc.tokenManager.NeedsReauthentication() {
+		return exitAuth
+	}
+	return fallback
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/token"
+)
+
+// tokenPassphraseEnv holds the passphrase of token bundles when no passphrase file is given
+const tokenPassphraseEnv = "NIGHT_ROUTINE_TOKEN_PASSPHRASE"
+
+// runToken moves the Google token and calendar selection between hosts as an encrypted bundle
+func runToken(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return withExitCode(exitUsage, errors.New("usage: night-routine token export|import [flags]"))
+	}
+	switch args[0] {
+	case "export":
+		return runTokenExport(args[1:])
+	case "import":
+		return runTokenImport(args[1:])
+	default:
+		return withExitCode(exitUsage, fmt.Errorf("unknown token command %q (must be export or import)", args[0]))
+	}
+}
+
+// runTokenExport writes the encrypted token bundle to a new file
+func runTokenExport(args []string) error {
+	fs := newFlagSet("token export")
+	output := fs.String("output", "", "bundle file to create (required, never overwritten)")
+	passphraseFile := fs.String("passphrase-file", "", "file holding the passphrase (default: $"+tokenPassphraseEnv+")")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return withExitCode(exitUsage, errors.New("--output is required"))
+	}
+	passphrase, err := readTokenPassphrase(*passphraseFile)
+	if err != nil {
+		return err
+	}
+
+	cfg, tokenStore, tokenStorage, closeDB, err := openTokenStores(false)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	oauthToken, err := tokenStorage.GetToken()
+	if err != nil {
+		return fmt.Errorf("failed to read token: %w", err)
+	}
+	if oauthToken == nil {
+		return withExitCode(exitAuth, fmt.Errorf("no Google token to export, link Google Calendar at %s first", cfg.App.AppUrl))
+	}
+	calendarID, calendarName, err := tokenStore.GetSelectedCalendarWithName()
+	if err != nil {
+		return fmt.Errorf("failed to read calendar selection: %w", err)
+	}
+
+	data, err := token.SealTransferBundle(&token.TransferBundle{
+		Token:        oauthToken,
+		CalendarID:   calendarID,
+		CalendarName: calendarName,
+		ExportedAt:   time.Now().UTC(),
+	}, passphrase)
+	if err != nil {
+		return err
+	}
+
+	// O_EXCL keeps an existing bundle from being silently replaced
+	file, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write bundle file: %w", err)
+	}
+
+	logger := logging.GetLogger("token-transfer")
+	logger.Info().Str("output", *output).Str("calendar_id", calendarID).
+		Msg("Token exported; stop the old host before starting the new one so both do not sync the same calendar")
+	return nil
+}
+
+// runTokenImport restores a token bundle, refusing to replace an existing token without --force
+func runTokenImport(args []string) error {
+	fs := newFlagSet("token import")
+	input := fs.String("input", "", "bundle file to import (required)")
+	passphraseFile := fs.String("passphrase-file", "", "file holding the passphrase (default: $"+tokenPassphraseEnv+")")
+	force := fs.Bool("force", false, "replace the token and calendar selection already stored on this host")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		return withExitCode(exitUsage, errors.New("--input is required"))
+	}
+	passphrase, err := readTokenPassphrase(*passphraseFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*input)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle file: %w", err)
+	}
+	bundle, err := token.OpenTransferBundle(data, passphrase)
+	if err != nil {
+		return err
+	}
+
+	_, tokenStore, tokenStorage, closeDB, err := openTokenStores(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	existing, err := tokenStorage.GetToken()
+	if err != nil {
+		return fmt.Errorf("failed to read current token: %w", err)
+	}
+	if existing != nil && !*force {
+		return errors.New("a Google token is already stored on this host, pass --force to replace it")
+	}
+
+	if err := tokenStorage.SaveToken(bundle.Token); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if bundle.CalendarID != "" {
+		if err := tokenStore.SaveSelectedCalendarWithName(bundle.CalendarID, bundle.CalendarName); err != nil {
+			return fmt.Errorf("failed to save calendar selection: %w", err)
+		}
+	}
+
+	logger := logging.GetLogger("token-transfer")
+	logger.Info().Str("calendar_id", bundle.CalendarID).Time("exported_at", bundle.ExportedAt).
+		Msg("Token imported; webhook channels are set up again when the server starts")
+	return nil
+}
+
+// openTokenStores opens the database and the configured token storage. The returned
+// function closes the database.
+func openTokenStores(migrate bool) (*config.Config, database.TokenStoreInterface, token.Storage, func(), error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	closeDB := func() { db.Close() }
+
+	if migrate {
+		if err := db.MigrateDatabase(); err != nil {
+			closeDB()
+			return nil, nil, nil, nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	tokenStore, err := database.NewTokenStore(db)
+	if err != nil {
+		closeDB()
+		return nil, nil, nil, nil, fmt.Errorf("failed to initialize token store: %w", err)
+	}
+	tokenStorage, err := newTokenStorage(cfg, tokenStore)
+	if err != nil {
+		closeDB()
+		return nil, nil, nil, nil, err
+	}
+	return cfg, tokenStore, tokenStorage, closeDB, nil
+}
+
+// readTokenPassphrase reads the bundle passphrase from a file, or from the environment.
+// A command-line flag is deliberately not offered, as it would show up in the process list.
+func readTokenPassphrase(passphraseFile string) (string, error) {
+	if passphraseFile != "" {
+		data, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		passphrase := strings.TrimRight(string(data), "\r\n")
+		if passphrase == "" {
+			return "", errors.New("passphrase file is empty")
+		}
+		return passphrase, nil
+	}
+	if passphrase := os.Getenv(tokenPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	return "", withExitCode(exitUsage, fmt.Errorf("a passphrase is required: use --passphrase-file or set %s", tokenPassphraseEnv))
+}
@@ -0,0 +1,95 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// absenceRealertAfter is how long absence patterns still found wait before being suggested again
+const absenceRealertAfter = 7 * 24 * time.Hour
+
+// AbsenceMonitor suggests marking a parent unavailable on the weekdays it was overridden on week
+// after week (see fairness.DetectAbsencePatterns), at most once a week while patterns are found. The
+// last suggestion is read from the delivery history so that a restart does not send it again.
+type AbsenceMonitor struct {
+	nights  fairness.OverriddenNightsSource
+	config  fairness.AbsenceConfigSource
+	sender  Sender
+	history DeliveryHistory // nil to rely on the in-memory state only
+	appURL  string
+	now     func() time.Time // injectable for testing; defaults to time.Now
+	logger  zerolog.Logger
+
+	mu             sync.Mutex
+	lastSuggestion time.Time
+}
+
+// NewAbsenceMonitor creates a monitor sending the absence suggestions through sender. appURL is the
+// public URL of the application, linked from the suggestion; it may be empty.
+func NewAbsenceMonitor(nights fairness.OverriddenNightsSource, config fairness.AbsenceConfigSource, sender Sender, history DeliveryHistory, appURL string) *AbsenceMonitor {
+	return &AbsenceMonitor{
+		nights:  nights,
+		config:  config,
+		sender:  sender,
+		history: history,
+		appURL:  strings.TrimRight(appURL, "/"),
+		now:     time.Now,
+		logger:  logging.GetLogger("absence-suggestion"),
+	}
+}
+
+// Check sends the absence suggestion when patterns are found in the overrides of the last four
+// weeks and no suggestion was sent in the past week, reporting whether one was sent
+func (m *AbsenceMonitor) Check(ctx context.Context) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	patterns, err := fairness.DetectAbsencePatterns(ctx, m.nights, m.config, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect absence patterns: %w", err)
+	}
+	if len(patterns) == 0 {
+		m.logger.Debug().Msg("No override pattern found")
+		return false, nil
+	}
+	logger := m.logger.With().Int("patterns", len(patterns)).Logger()
+
+	lastSuggestion := m.lastSuggestion
+	if m.history != nil {
+		lastSent, err := m.history.LastSuccessfulDelivery(notify.EventAbsenceSuggestion.String())
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to read when the absence suggestion was last sent")
+		} else if lastSent.After(lastSuggestion) {
+			lastSuggestion = lastSent
+		}
+	}
+	if !lastSuggestion.IsZero() && now.Sub(lastSuggestion) < absenceRealertAfter {
+		logger.Debug().Time("last_suggestion", lastSuggestion).Msg("Absences already suggested this week")
+		return false, nil
+	}
+
+	data := notify.AbsenceSuggestionData{AppURL: m.appURL}
+	for _, pattern := range patterns {
+		data.Suggestions = append(data.Suggestions, notify.AbsenceSuggestion{
+			Parent:     pattern.Parent,
+			Weekday:    pattern.Weekday.String(),
+			Overridden: pattern.Overridden,
+			Weeks:      pattern.Weeks,
+		})
+	}
+	if err := m.sender.Send(ctx, notify.EventAbsenceSuggestion, data); err != nil {
+		return false, fmt.Errorf("failed to send absence suggestion: %w", err)
+	}
+	m.lastSuggestion = now
+	logger.Info().Msg("Override patterns found, absences suggested")
+	return true, nil
+}
@@ -0,0 +1,95 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticOverriddenNights []fairness.OverriddenNight
+
+func (s staticOverriddenNights) GetOverriddenNights(context.Context, time.Time, time.Time) ([]fairness.OverriddenNight, error) {
+	return s, nil
+}
+
+type staticAbsenceConfig struct {
+	staticParents
+}
+
+func (staticAbsenceConfig) GetAvailability(string) ([]string, error) { return nil, nil }
+
+func (staticAbsenceConfig) GetUnavailabilityRules(string) ([]config.UnavailabilityRule, error) {
+	return nil, nil
+}
+
+type absenceSender struct {
+	data []any
+}
+
+func (s *absenceSender) Send(_ context.Context, event notify.Event, data any) error {
+	if event == notify.EventAbsenceSuggestion {
+		s.data = append(s.data, data)
+	}
+	return nil
+}
+
+// Bob was overridden on the three last Wednesdays before Friday 16 October 2026
+var bobWednesdays = staticOverriddenNights{
+	{Date: time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC), Parent: "Bob"},
+	{Date: time.Date(2026, time.October, 7, 0, 0, 0, 0, time.UTC), Parent: "Bob"},
+	{Date: time.Date(2026, time.October, 14, 0, 0, 0, 0, time.UTC), Parent: "Bob"},
+}
+
+func newTestAbsenceMonitor(nights staticOverriddenNights, history DeliveryHistory) (*AbsenceMonitor, *absenceSender) {
+	sender := &absenceSender{}
+	monitor := NewAbsenceMonitor(nights, staticAbsenceConfig{}, sender, history, "https://night.example.com/")
+	monitor.now = func() time.Time { return imbalanceNow }
+	return monitor, sender
+}
+
+func TestAbsenceMonitor_SuggestsOncePerWeek(t *testing.T) {
+	monitor, sender := newTestAbsenceMonitor(bobWednesdays, nil)
+
+	sent, err := monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.True(t, sent)
+	require.Len(t, sender.data, 1)
+	assert.Equal(t, notify.AbsenceSuggestionData{
+		Suggestions: []notify.AbsenceSuggestion{{Parent: "Bob", Weekday: "Wednesday", Overridden: 3, Weeks: 4}},
+		AppURL:      "https://night.example.com",
+	}, sender.data[0])
+
+	monitor.now = func() time.Time { return imbalanceNow.Add(6 * 24 * time.Hour) }
+	sent, err = monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.False(t, sent, "already suggested this week")
+
+	monitor.now = func() time.Time { return imbalanceNow.Add(7 * 24 * time.Hour) }
+	sent, err = monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.True(t, sent, "suggested again a week later")
+}
+
+func TestAbsenceMonitor_NoPattern(t *testing.T) {
+	monitor, sender := newTestAbsenceMonitor(bobWednesdays[1:], nil)
+
+	sent, err := monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.False(t, sent)
+	assert.Empty(t, sender.data)
+}
+
+func TestAbsenceMonitor_SuggestedBeforeRestart(t *testing.T) {
+	monitor, sender := newTestAbsenceMonitor(bobWednesdays, staticHistory{lastSent: imbalanceNow.Add(-2 * 24 * time.Hour)})
+
+	sent, err := monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.False(t, sent, "the suggestion sent before the restart counts")
+	assert.Empty(t, sender.data)
+}
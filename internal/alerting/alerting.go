@@ -0,0 +1,123 @@
+// Package alerting escalates repeated sync and webhook failures through the notifiers.
+package alerting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// Sources of the failures tracked by the monitor
+const (
+	SourceSync    = "sync"
+	SourceWebhook = "webhook"
+)
+
+// sourceState tracks the consecutive failures of one source
+type sourceState struct {
+	failures  int
+	lastErr   error
+	lastAlert time.Time
+	alerted   bool
+}
+
+// Monitor counts consecutive failures per source and alerts once they reach the threshold.
+// While failures continue, another alert is sent at most once per cooldown. The first success
+// after an alert sends a recovery message.
+type Monitor struct {
+	notifier  notify.Notifier
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	logger    zerolog.Logger
+
+	mu      sync.Mutex
+	sources map[string]*sourceState
+}
+
+// NewMonitor creates a monitor alerting through notifier after threshold consecutive failures
+func NewMonitor(notifier notify.Notifier, threshold int, cooldown time.Duration) *Monitor {
+	return &Monitor{
+		notifier:  notifier,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		logger:    logging.GetLogger("alerting"),
+		sources:   make(map[string]*sourceState),
+	}
+}
+
+// Record records the outcome of an operation of source; a nil err is a success.
+// Alerts are delivered in the background so the caller is never blocked by a slow channel.
+func (m *Monitor) Record(ctx context.Context, source string, err error) {
+	msg, send := m.record(source, err)
+	if !send {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := m.notifier.Notify(ctx, msg); err != nil {
+			m.logger.Error().Err(err).Str("source", source).Msg("Failed to deliver alert")
+			return
+		}
+		m.logger.Info().Str("source", source).Str("subject", msg.Subject).Msg("Alert delivered")
+	}()
+}
+
+// record updates the state of source and returns the message to send, if any
+func (m *Monitor) record(source string, err error) (notify.Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.sources[source]
+	if !ok {
+		state = &sourceState{}
+		m.sources[source] = state
+	}
+
+	if err == nil {
+		wasAlerted, failures := state.alerted, state.failures
+		*state = sourceState{}
+		if !wasAlerted {
+			return notify.Message{}, false
+		}
+		m.logger.Info().Str("source", source).Msg("Source recovered after alert")
+		return m.render(notify.EventFailureRecovered, notify.FailureData{Source: source, Failures: failures})
+	}
+
+	state.failures++
+	state.lastErr = err
+	m.logger.Debug().Str("source", source).Int("consecutive_failures", state.failures).Msg("Recorded failure")
+	if state.failures < m.threshold {
+		return notify.Message{}, false
+	}
+
+	now := m.now()
+	if state.alerted && now.Sub(state.lastAlert) < m.cooldown {
+		return notify.Message{}, false
+	}
+	state.alerted = true
+	state.lastAlert = now
+
+	m.logger.Warn().Str("source", source).Int("consecutive_failures", state.failures).Msg("Failure threshold reached, alerting")
+	return m.render(notify.EventFailureAlert, notify.FailureData{
+		Source:    source,
+		Failures:  state.failures,
+		LastError: state.lastErr.Error(),
+	})
+}
+
+// render renders the message of event, which is not sent when its template fails
+func (m *Monitor) render(event notify.Event, data notify.FailureData) (notify.Message, bool) {
+	msg, err := notify.Render(event, data)
+	if err != nil {
+		m.logger.Error().Err(err).Str("event", event.String()).Msg("Failed to render alert")
+		return notify.Message{}, false
+	}
+	return msg, true
+}
@@ -0,0 +1,143 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNotifier struct {
+	mu       sync.Mutex
+	messages []notify.Message
+	sent     chan struct{}
+}
+
+func newFakeNotifier() *fakeNotifier {
+	return &fakeNotifier{sent: make(chan struct{}, 10)}
+}
+
+func (f *fakeNotifier) Name() string { return "fake" }
+
+func (f *fakeNotifier) Notify(_ context.Context, msg notify.Message) error {
+	f.mu.Lock()
+	f.messages = append(f.messages, msg)
+	f.mu.Unlock()
+	f.sent <- struct{}{}
+	return nil
+}
+
+func TestMonitor_Record(t *testing.T) {
+	failure := errors.New("token revoked")
+
+	type step struct {
+		advance time.Duration
+		err     error
+		subject string // expected alert subject, empty when no alert is expected
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "alerts once the threshold is reached",
+			steps: []step{
+				{err: failure},
+				{err: failure},
+				{err: failure, subject: "Night Routine: sync failing"},
+			},
+		},
+		{
+			name: "success resets the count",
+			steps: []step{
+				{err: failure},
+				{err: failure},
+				{err: nil},
+				{err: failure},
+				{err: failure},
+			},
+		},
+		{
+			name: "cooldown suppresses repeated alerts",
+			steps: []step{
+				{err: failure},
+				{err: failure},
+				{err: failure, subject: "Night Routine: sync failing"},
+				{advance: time.Hour, err: failure},
+				{advance: time.Hour, err: failure},
+				{advance: 5 * time.Hour, err: failure, subject: "Night Routine: sync failing"},
+			},
+		},
+		{
+			name: "recovery is announced after an alert",
+			steps: []step{
+				{err: failure},
+				{err: failure},
+				{err: failure, subject: "Night Routine: sync failing"},
+				{err: nil, subject: "Night Routine: sync recovered"},
+				{err: nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := newFakeNotifier()
+			monitor := NewMonitor(notifier, 3, 6*time.Hour)
+			now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+			monitor.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+				msg, sent := monitor.record(SourceSync, s.err)
+				if s.subject == "" {
+					assert.False(t, sent, "step %d should not alert", i)
+					continue
+				}
+				require.True(t, sent, "step %d should alert", i)
+				assert.Equal(t, s.subject, msg.Subject, "step %d", i)
+			}
+		})
+	}
+}
+
+func TestMonitor_SourcesAreIndependent(t *testing.T) {
+	monitor := NewMonitor(newFakeNotifier(), 2, time.Hour)
+	failure := errors.New("boom")
+
+	_, sent := monitor.record(SourceSync, failure)
+	assert.False(t, sent)
+	_, sent = monitor.record(SourceWebhook, failure)
+	assert.False(t, sent, "webhook failures do not add to sync failures")
+
+	msg, sent := monitor.record(SourceWebhook, failure)
+	require.True(t, sent)
+	assert.Equal(t, "Night Routine: webhook failing", msg.Subject)
+	assert.Contains(t, msg.Body, "2 times")
+	assert.Contains(t, msg.Body, "boom")
+}
+
+func TestMonitor_RecordDelivers(t *testing.T) {
+	notifier := newFakeNotifier()
+	monitor := NewMonitor(notifier, 1, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	monitor.Record(ctx, SourceSync, errors.New("boom"))
+	cancel()
+
+	select {
+	case <-notifier.sent:
+	case <-time.After(time.Second):
+		t.Fatal("alert was not delivered")
+	}
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	require.Len(t, notifier.messages, 1)
+	assert.Equal(t, "Night Routine: sync failing", notifier.messages[0].Subject)
+}
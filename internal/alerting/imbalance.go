@@ -0,0 +1,113 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// imbalanceRealertAfter is how long an imbalance still reaching the threshold waits before being
+// alerted again
+const imbalanceRealertAfter = 7 * 24 * time.Hour
+
+// ParentsSource reads the names of the parents of the roster, implemented by config.ConfigStoreInterface
+type ParentsSource = config.RosterSource
+
+// Sender delivers a notification event, implemented by notify.Service
+type Sender interface {
+	Send(ctx context.Context, event notify.Event, data any) error
+}
+
+// DeliveryHistory tells when an event was last delivered, implemented by database.NotificationDeliveryStore
+type DeliveryHistory interface {
+	LastSuccessfulDelivery(event string) (time.Time, error)
+}
+
+// ImbalanceMonitor alerts when the busiest parent did threshold nights more than the least busy one over the last
+// 30 days, then at most once a week while the imbalance lasts. The last alert is read from the
+// delivery history so that a restart does not alert again.
+type ImbalanceMonitor struct {
+	threshold int
+	stats     fairness.ParentStatsSource
+	parents   ParentsSource
+	sender    Sender
+	history   DeliveryHistory // nil to rely on the in-memory state only
+	appURL    string
+	now       func() time.Time // injectable for testing; defaults to time.Now
+	logger    zerolog.Logger
+
+	mu        sync.Mutex
+	lastAlert time.Time
+}
+
+// NewImbalanceMonitor creates a monitor alerting through sender once the difference of nights
+// reaches threshold. appURL is the public URL of the application, linked from the alert; it may be empty.
+func NewImbalanceMonitor(threshold int, stats fairness.ParentStatsSource, parents ParentsSource, sender Sender, history DeliveryHistory, appURL string) *ImbalanceMonitor {
+	return &ImbalanceMonitor{
+		threshold: threshold,
+		stats:     stats,
+		parents:   parents,
+		sender:    sender,
+		history:   history,
+		appURL:    strings.TrimRight(appURL, "/"),
+		now:       time.Now,
+		logger:    logging.GetLogger("imbalance-alert"),
+	}
+}
+
+// Check sends the imbalance alert when the imbalance of the last 30 days reaches the threshold and
+// no alert was sent in the past week, reporting whether one was sent
+func (m *ImbalanceMonitor) Check(ctx context.Context) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	roster, err := config.Roster(m.parents)
+	if err != nil {
+		return false, fmt.Errorf("failed to get parents: %w", err)
+	}
+	now := m.now()
+	imbalance, err := fairness.RecentImbalance(ctx, m.stats, now, roster...)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the imbalance of the last 30 days: %w", err)
+	}
+	logger := m.logger.With().Str("busier", imbalance.Busier).Int("difference", imbalance.Difference()).Int("threshold", m.threshold).Logger()
+	if !imbalance.Reaches(m.threshold) {
+		logger.Debug().Msg("Nights are balanced enough")
+		return false, nil
+	}
+
+	lastAlert := m.lastAlert
+	if m.history != nil {
+		lastSent, err := m.history.LastSuccessfulDelivery(notify.EventImbalanceAlert.String())
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to read when the imbalance alert was last sent")
+		} else if lastSent.After(lastAlert) {
+			lastAlert = lastSent
+		}
+	}
+	if !lastAlert.IsZero() && now.Sub(lastAlert) < imbalanceRealertAfter {
+		logger.Debug().Time("last_alert", lastAlert).Msg("Imbalance already alerted this week")
+		return false, nil
+	}
+
+	if err := m.sender.Send(ctx, notify.EventImbalanceAlert, notify.ImbalanceAlertData{
+		Busier:      imbalance.Busier,
+		Other:       imbalance.Other,
+		BusierCount: imbalance.BusierCount,
+		OtherCount:  imbalance.OtherCount,
+		AppURL:      m.appURL,
+	}); err != nil {
+		return false, fmt.Errorf("failed to send imbalance alert: %w", err)
+	}
+	m.lastAlert = now
+	logger.Warn().Msg("Imbalance threshold reached, alerted")
+	return true, nil
+}
@@ -0,0 +1,96 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticStats map[string]fairness.Stats
+
+func (s staticStats) GetParentStatsUntil(context.Context, time.Time, ...string) (map[string]fairness.Stats, error) {
+	return s, nil
+}
+
+type staticParents struct{}
+
+func (staticParents) GetParents() (string, string, error) {
+	return "Alice", "Bob", nil
+}
+
+func (staticParents) GetExtraParents() ([]config.ExtraParent, error) {
+	return nil, nil
+}
+
+type recordingSender struct {
+	data []any
+}
+
+func (s *recordingSender) Send(_ context.Context, event notify.Event, data any) error {
+	if event == notify.EventImbalanceAlert {
+		s.data = append(s.data, data)
+	}
+	return nil
+}
+
+type staticHistory struct {
+	lastSent time.Time
+}
+
+func (h staticHistory) LastSuccessfulDelivery(string) (time.Time, error) {
+	return h.lastSent, nil
+}
+
+var imbalanceNow = time.Date(2026, time.October, 16, 20, 0, 0, 0, time.UTC)
+
+func newTestImbalanceMonitor(stats staticStats, history DeliveryHistory) (*ImbalanceMonitor, *recordingSender) {
+	sender := &recordingSender{}
+	monitor := NewImbalanceMonitor(8, stats, staticParents{}, sender, history, "https://night.example.com/")
+	monitor.now = func() time.Time { return imbalanceNow }
+	return monitor, sender
+}
+
+func TestImbalanceMonitor_AlertsOncePerWeek(t *testing.T) {
+	monitor, sender := newTestImbalanceMonitor(staticStats{"Alice": {Last30Days: 11}, "Bob": {Last30Days: 19}}, nil)
+
+	sent, err := monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.True(t, sent)
+	require.Len(t, sender.data, 1)
+	assert.Equal(t, notify.ImbalanceAlertData{Busier: "Bob", Other: "Alice", BusierCount: 19, OtherCount: 11, AppURL: "https://night.example.com"}, sender.data[0])
+
+	monitor.now = func() time.Time { return imbalanceNow.Add(6 * 24 * time.Hour) }
+	sent, err = monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.False(t, sent, "already alerted this week")
+
+	monitor.now = func() time.Time { return imbalanceNow.Add(7 * 24 * time.Hour) }
+	sent, err = monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.True(t, sent, "alerted again a week later")
+}
+
+func TestImbalanceMonitor_UnderThreshold(t *testing.T) {
+	monitor, sender := newTestImbalanceMonitor(staticStats{"Alice": {Last30Days: 12}, "Bob": {Last30Days: 19}}, nil)
+
+	sent, err := monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.False(t, sent)
+	assert.Empty(t, sender.data)
+}
+
+func TestImbalanceMonitor_AlreadySentBeforeRestart(t *testing.T) {
+	history := staticHistory{lastSent: imbalanceNow.Add(-2 * 24 * time.Hour)}
+	monitor, sender := newTestImbalanceMonitor(staticStats{"Alice": {Last30Days: 20}, "Bob": {Last30Days: 10}}, history)
+
+	sent, err := monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.False(t, sent)
+	assert.Empty(t, sender.data)
+}
@@ -0,0 +1,50 @@
+// Package apierror defines the stable machine-readable codes of the JSON API errors. They mirror
+// the ErrCode* codes the HTML forms redirect with, so that clients can tell a rejected request
+// from a missing authentication or a failed sync without parsing the messages.
+package apierror
+
+// Code identifies a kind of API error. Codes are part of the API: existing ones never change,
+// new kinds of errors get new codes.
+type Code string
+
+const (
+	// CodeInvalidRequest is returned for a request whose parameters or body are invalid
+	CodeInvalidRequest Code = "invalid_request"
+	// CodeMethodNotAllowed is returned for an HTTP method the endpoint does not accept
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	// CodeAuthenticationRequired is returned until Google Calendar is linked, or after its token was revoked
+	CodeAuthenticationRequired Code = "authentication_required"
+	// CodeLoginRequired is returned, with the passkey login enabled, for a request needing a session
+	// without one, and for a sign-in whose passkey could not be verified
+	CodeLoginRequired Code = "login_required"
+	// CodeNotFound is returned when the resource of the request does not exist
+	CodeNotFound Code = "not_found"
+	// CodeConflict is returned when the resource changed since it was read; reload it and retry
+	CodeConflict Code = "conflict"
+	// CodeNotOverridden is returned when tagging a night that was not set by hand
+	CodeNotOverridden Code = "not_overridden"
+	// CodeAssignmentTooOld is returned when changing a night older than the past event threshold
+	CodeAssignmentTooOld Code = "assignment_too_old"
+	// CodeCalendarError is returned when Google Calendar rejected or failed a request
+	CodeCalendarError Code = "calendar_error"
+	// CodeSyncFailed is returned when the schedule could not be generated or synced
+	CodeSyncFailed Code = "sync_failed"
+	// CodeInternal is returned for a failure of the server, such as a database error
+	CodeInternal Code = "internal_error"
+)
+
+// String returns the code as sent in the responses
+func (c Code) String() string {
+	return string(c)
+}
+
+// Response is the JSON body of an API error. Message keeps the "error" key the API always used.
+type Response struct {
+	Code    Code   `json:"code"`
+	Message string `json:"error"`
+}
+
+// New returns the body of an API error
+func New(code Code, message string) Response {
+	return Response{Code: code, Message: message}
+}
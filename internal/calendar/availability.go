@@ -0,0 +1,151 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/dates"
+	"github.com/belphemur/night-routine/internal/features"
+	"google.golang.org/api/calendar/v3"
+)
+
+// AvailabilityCalendars are the personal calendars of the parents scanned on each sync for the events
+// making them unavailable. An empty Keyword disables the scan.
+type AvailabilityCalendars struct {
+	ParentA string // Calendar ID of parent A, empty when not scanned
+	ParentB string // Calendar ID of parent B, empty when not scanned
+	Keyword string // Events whose title contains it, ignoring case, make the parent unavailable on their days
+}
+
+// SyncAvailability replaces the days from start to end on which each parent is unavailable with the
+// days of the keyword events of its personal calendar. A parent without calendar is never unavailable
+// from it. The days recorded by the previous sync are kept for a parent whose calendar cannot be read.
+// While the calendar_unavailability feature flag is disabled, the days recorded are cleared instead.
+func (s *Service) SyncAvailability(ctx context.Context, start, end time.Time) error {
+	if s.availability.Keyword == "" {
+		return nil
+	}
+	if !s.features.Enabled(features.CalendarUnavailability) {
+		s.logger.Debug().Msg("Calendar unavailability disabled by its feature flag, clearing the days recorded")
+		return errors.Join(
+			s.scheduler.SetCalendarUnavailability(ctx, "parent_a", start, end, nil),
+			s.scheduler.SetCalendarUnavailability(ctx, "parent_b", start, end, nil),
+		)
+	}
+	if !s.initialized || s.srv == nil {
+		s.logger.Warn().Msg("SyncAvailability called but service is not initialized")
+		return fmt.Errorf("calendar service not initialized - authentication required")
+	}
+
+	var errs []error
+	for _, parent := range []struct{ key, calendarID string }{
+		{"parent_a", s.availability.ParentA},
+		{"parent_b", s.availability.ParentB},
+	} {
+		calendarID := parent.calendarID
+		availabilityLogger := s.logger.With().Str("parent", parent.key).Str("calendar_id", calendarID).Logger()
+
+		var dates []time.Time
+		if calendarID != "" {
+			days, err := s.keywordEventDays(ctx, calendarID, start, end)
+			if err != nil {
+				availabilityLogger.Error().Err(err).Msg("Failed to read the availability of the parent from its calendar")
+				errs = append(errs, fmt.Errorf("failed to read the calendar of %s: %w", parent.key, err))
+				continue
+			}
+			dates = days
+		}
+
+		if err := s.scheduler.SetCalendarUnavailability(ctx, parent.key, start, end, dates); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		availabilityLogger.Debug().Int("unavailable_days", len(dates)).Msg("Synced the availability of the parent from its calendar")
+	}
+	return errors.Join(errs...)
+}
+
+// keywordEventDays returns the days from start to end covered by the events of calendarID whose
+// title contains the availability keyword
+func (s *Service) keywordEventDays(ctx context.Context, calendarID string, start, end time.Time) ([]time.Time, error) {
+	events, err := s.srv.Events.List(calendarID).
+		TimeMin(dates.AddDays(start, -1).Format(time.RFC3339)).
+		TimeMax(dates.AddDays(end, 2).Format(time.RFC3339)).
+		Q(s.availability.Keyword).
+		SingleEvents(true).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	keyword := strings.ToLower(s.availability.Keyword)
+	seen := make(map[string]bool)
+	var dates []time.Time
+	for _, event := range events.Items {
+		if event.Status == "cancelled" || !strings.Contains(strings.ToLower(event.Summary), keyword) {
+			continue
+		}
+		for _, day := range eventDays(event) {
+			if seen[day] || day < start.Format("2006-01-02") || day > end.Format("2006-01-02") {
+				continue
+			}
+			date, err := time.ParseInLocation("2006-01-02", day, time.Local)
+			if err != nil {
+				continue
+			}
+			seen[day] = true
+			dates = append(dates, date)
+		}
+	}
+	return dates, nil
+}
+
+// eventDays returns the days covered by event as YYYY-MM-DD: from the first to the day before the end
+// of an all-day event, from the day it starts to the day it ends for a timed event. A timed event
+// ending at midnight does not cover the day it ends.
+func eventDays(event *calendar.Event) []string {
+	if event == nil || event.Start == nil || event.End == nil {
+		return nil
+	}
+
+	var first, last time.Time
+	if event.Start.Date != "" {
+		startDate, err := time.Parse("2006-01-02", event.Start.Date)
+		if err != nil {
+			return nil
+		}
+		endDate, err := time.Parse("2006-01-02", event.End.Date)
+		if err != nil {
+			return nil
+		}
+		first, last = startDate, endDate.AddDate(0, 0, -1)
+	} else {
+		startTime, err := time.Parse(time.RFC3339, event.Start.DateTime)
+		if err != nil {
+			return nil
+		}
+		endTime, err := time.Parse(time.RFC3339, event.End.DateTime)
+		if err != nil {
+			return nil
+		}
+		first = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, time.UTC)
+		last = time.Date(endTime.Year(), endTime.Month(), endTime.Day(), 0, 0, 0, 0, time.UTC)
+		if endTime.After(startTime) && endTime.Hour() == 0 && endTime.Minute() == 0 && endTime.Second() == 0 {
+			last = last.AddDate(0, 0, -1)
+		}
+	}
+
+	var days []string
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format("2006-01-02"))
+	}
+	if len(days) == 0 {
+		// A zero-length event still covers the day it starts
+		days = append(days, first.Format("2006-01-02"))
+	}
+	return days
+}
@@ -0,0 +1,147 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/features"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gcalendar "google.golang.org/api/calendar/v3"
+)
+
+func TestEventDays(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end gcalendar.EventDateTime
+		want       []string
+	}{
+		{
+			name:  "all-day event",
+			start: gcalendar.EventDateTime{Date: "2026-03-03"},
+			end:   gcalendar.EventDateTime{Date: "2026-03-04"},
+			want:  []string{"2026-03-03"},
+		},
+		{
+			name:  "all-day event over several days",
+			start: gcalendar.EventDateTime{Date: "2026-03-03"},
+			end:   gcalendar.EventDateTime{Date: "2026-03-06"},
+			want:  []string{"2026-03-03", "2026-03-04", "2026-03-05"},
+		},
+		{
+			name:  "timed event",
+			start: gcalendar.EventDateTime{DateTime: "2026-03-03T18:00:00+01:00"},
+			end:   gcalendar.EventDateTime{DateTime: "2026-03-03T23:00:00+01:00"},
+			want:  []string{"2026-03-03"},
+		},
+		{
+			name:  "overnight timed event",
+			start: gcalendar.EventDateTime{DateTime: "2026-03-03T20:00:00+01:00"},
+			end:   gcalendar.EventDateTime{DateTime: "2026-03-04T08:00:00+01:00"},
+			want:  []string{"2026-03-03", "2026-03-04"},
+		},
+		{
+			name:  "timed event ending at midnight",
+			start: gcalendar.EventDateTime{DateTime: "2026-03-03T20:00:00+01:00"},
+			end:   gcalendar.EventDateTime{DateTime: "2026-03-04T00:00:00+01:00"},
+			want:  []string{"2026-03-03"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, eventDays(&gcalendar.Event{Start: &tt.start, End: &tt.end}))
+		})
+	}
+	assert.Nil(t, eventDays(&gcalendar.Event{}), "event without start")
+}
+
+func TestSyncAvailability(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := func(offset int) string { return today.AddDate(0, 0, offset).Format("2006-01-02") }
+
+	service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t)
+	defer cleanup()
+	for _, event := range []*gcalendar.Event{
+		{
+			Id:      "on-call",
+			Summary: "On call - hospital",
+			Start:   &gcalendar.EventDateTime{Date: day(1)},
+			End:     &gcalendar.EventDateTime{Date: day(3)},
+		},
+		{
+			Id:      "cancelled",
+			Summary: "On call",
+			Status:  "cancelled",
+			Start:   &gcalendar.EventDateTime{Date: day(4)},
+			End:     &gcalendar.EventDateTime{Date: day(5)},
+		},
+		{
+			Id:      "dinner",
+			Summary: "Dinner",
+			Start:   &gcalendar.EventDateTime{Date: day(5)},
+			End:     &gcalendar.EventDateTime{Date: day(6)},
+		},
+		{
+			Id:      "far",
+			Summary: "on call",
+			Start:   &gcalendar.EventDateTime{Date: day(30)},
+			End:     &gcalendar.EventDateTime{Date: day(31)},
+		},
+	} {
+		fakeAPI.AddEvent("alice@example.com", event)
+	}
+	end := today.AddDate(0, 0, 7)
+
+	require.NoError(t, service.SyncAvailability(t.Context(), today, end), "disabled without keyword")
+	dates, err := tracker.GetCalendarUnavailability(t.Context(), "parent_a", today, end)
+	require.NoError(t, err)
+	assert.Empty(t, dates)
+
+	service.availability = AvailabilityCalendars{ParentA: "alice@example.com", Keyword: "On Call"}
+	require.NoError(t, service.SyncAvailability(t.Context(), today, end))
+
+	dates, err = tracker.GetCalendarUnavailability(t.Context(), "parent_a", today, end)
+	require.NoError(t, err)
+	var days []string
+	for _, date := range dates {
+		days = append(days, date.Format("2006-01-02"))
+	}
+	assert.Equal(t, []string{day(1), day(2)}, days, "only the keyword events within the range, ignoring case")
+	dates, err = tracker.GetCalendarUnavailability(t.Context(), "parent_b", today, end)
+	require.NoError(t, err)
+	assert.Empty(t, dates, "parent B has no calendar scanned")
+
+	assignments, err := testScheduler.GenerateSchedule(t.Context(), today, end, today)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", assignments[1].Parent)
+	assert.Equal(t, "Bob", assignments[2].Parent)
+
+	// Disabled by its feature flag, the days recorded are cleared
+	service.SetFeatureFlags(features.New(flagStore{string(features.CalendarUnavailability): false}))
+	require.NoError(t, service.SyncAvailability(t.Context(), today, end))
+	dates, err = tracker.GetCalendarUnavailability(t.Context(), "parent_a", today, end)
+	require.NoError(t, err)
+	assert.Empty(t, dates)
+}
+
+// flagStore keeps feature flags in memory
+type flagStore map[string]bool
+
+func (s flagStore) GetFeatureFlag(name string) (bool, bool, error) {
+	enabled, ok := s[name]
+	return enabled, ok, nil
+}
+
+func (s flagStore) ListFeatureFlags() (map[string]bool, error) { return s, nil }
+
+func (s flagStore) SetFeatureFlag(name string, enabled bool, _ time.Time) error {
+	s[name] = enabled
+	return nil
+}
+
+func (s flagStore) DeleteFeatureFlag(name string) error {
+	delete(s, name)
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,37 +15,83 @@ import (
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 
-	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/dates"
 	"github.com/belphemur/night-routine/internal/fairness"
 	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/features"
+	"github.com/belphemur/night-routine/internal/googleclient"
 	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/signals"
 	"github.com/belphemur/night-routine/internal/token"
+	"github.com/belphemur/night-routine/internal/tracing"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 )
 
+var tracer = tracing.Tracer("internal/calendar")
+
 // Service handles Google Calendar operations
 type Service struct {
 	calendarID   string
 	srv          *calendar.Service
+	endpoint     string // Calendar API endpoint, Google when empty
 	oauthConfig  *oauth2.Config
-	appUrl       string
+	branding     Branding
+	availability AvailabilityCalendars
 	publicUrl    string
-	tokenStore   *database.TokenStore
+	tokenStore   database.TokenStoreInterface
 	tokenManager *token.TokenManager
 	scheduler    *scheduler.Scheduler
+	checklists   ChecklistSource
+	comments     CommentSource
+	review       Review
+	nonces       syncNonces      // Nonces of the recent syncs, marking the events they write
+	features     *features.Flags // Gates the experimental subsystems, every flag has its default state when nil
 	initialized  bool
 	logger       zerolog.Logger
 }
 
+// Branding is how the events of this instance are named and told apart from the events of other
+// instances or forks sharing the calendar
+type Branding struct {
+	Emoji      string // Between the caregiver and "Routine" in the event titles
+	Identifier string // Marks the events as managed in their private properties, description and source title
+	SourceURL  string // Source link of the events, also marking them as managed
+}
+
+// ChecklistSource provides the bedtime checklist written in the event description of an assignment
+type ChecklistSource interface {
+	GetChecklist(assignmentID int64) ([]*database.ChecklistItem, error)
+}
+
+// CommentSource provides the comments appended to the event description of an assignment, when enabled
+type CommentSource interface {
+	IsCommentsInEventsEnabled() (bool, error)
+	ListComments(assignmentID int64) ([]*database.Comment, error)
+}
+
+// eventNotes is what the event description carries besides the assignment
+type eventNotes struct {
+	checklist []*database.ChecklistItem
+	comments  []*database.Comment
+}
+
 // New creates a new calendar service. It doesn't require a valid token to initialize.
 // The service will return errors for operations that require authentication until Initialize is called.
-// oauthConfig, appUrl, and publicUrl are static values from file/env configuration.
-func New(oauthConfig *oauth2.Config, appUrl string, publicUrl string, tokenStore *database.TokenStore, scheduler *scheduler.Scheduler, tokenManager *token.TokenManager) *Service {
+// oauthConfig, branding, availability, and publicUrl are static values from file/env configuration.
+// checklists and comments may be nil, the event descriptions then carry no checklist or no comments.
+// review stages the regenerated nights far ahead until they are published, see Review.
+func New(oauthConfig *oauth2.Config, branding Branding, availability AvailabilityCalendars, publicUrl string, tokenStore database.TokenStoreInterface, scheduler *scheduler.Scheduler, tokenManager *token.TokenManager, checklists ChecklistSource, comments CommentSource, review Review) *Service {
 	return &Service{
+		checklists:   checklists,
+		comments:     comments,
+		review:       review,
 		oauthConfig:  oauthConfig,
-		appUrl:       appUrl,
+		branding:     branding,
+		availability: availability,
 		publicUrl:    publicUrl,
 		tokenStore:   tokenStore,
 		tokenManager: tokenManager,
@@ -76,9 +125,9 @@ func (s *Service) Initialize(ctx context.Context) error {
 	}
 	s.logger.Debug().Msg("Valid token obtained")
 
-	// Create authenticated client
-	client := s.oauthConfig.Client(ctx, token)
-	srv, err := calendar.NewService(ctx, option.WithHTTPClient(client))
+	// Create authenticated client on the shared connection pool
+	client := googleclient.New(ctx, s.oauthConfig, token)
+	srv, err := NewAPIService(ctx, client, s.endpoint)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Failed to create Google Calendar service client")
 		return fmt.Errorf("failed to create calendar service: %w", err)
@@ -106,19 +155,66 @@ func (s *Service) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// SetEndpoint makes the service call the Calendar API at endpoint instead of Google, e.g. a
+// fakecalendar server; it applies from the next Initialize
+func (s *Service) SetEndpoint(endpoint string) {
+	s.endpoint = endpoint
+}
+
+// SetFeatureFlags makes the service read the feature flags gating its experimental subsystems
+func (s *Service) SetFeatureFlags(flags *features.Flags) {
+	s.features = flags
+}
+
+// NewAPIService returns a Calendar API client sending its requests through client, to endpoint
+// when set instead of Google
+func NewAPIService(ctx context.Context, client *http.Client, endpoint string) (*calendar.Service, error) {
+	options := []option.ClientOption{option.WithHTTPClient(client)}
+	if endpoint != "" {
+		// The API paths are resolved relative to the endpoint
+		options = append(options, option.WithEndpoint(strings.TrimSuffix(endpoint, "/")+"/"))
+	}
+	return calendar.NewService(ctx, options...)
+}
+
 // IsInitialized returns whether the service has been initialized with a valid token
 func (s *Service) IsInitialized() bool {
 	return s.initialized
 }
 
-// SyncSchedule synchronizes the schedule with Google Calendar
+// Reset drops the authenticated client and calendar selection, returning the service
+// to its uninitialized state. Used after the Google account has been disconnected.
+func (s *Service) Reset() {
+	s.logger.Info().Msg("Resetting calendar service")
+	s.srv = nil
+	s.calendarID = ""
+	s.initialized = false
+}
+
+// SyncSchedule synchronizes the schedule with Google Calendar. In review mode, the regenerated nights
+// beyond the review window keep their event and are staged until published, see Review.
 func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Assignment) error {
+	return s.syncSchedule(ctx, assignments, true)
+}
+
+// syncSchedule synchronizes the assignments with Google Calendar, staging the ones waiting for review
+// when review is true. The synced assignments are no longer staged.
+func (s *Service) syncSchedule(ctx context.Context, assignments []*scheduler.Assignment, review bool) (err error) {
+	ctx, span := tracer.Start(ctx, "calendar.sync", trace.WithAttributes(attribute.Int("assignments.count", len(assignments))))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	if !s.initialized || s.srv == nil {
 		s.logger.Warn().Msg("SyncSchedule called but service is not initialized")
 		return fmt.Errorf("calendar service not initialized - authentication required")
 	}
 	s.logger.Info().Int("assignments_count", len(assignments)).Msg("Starting schedule sync")
 
+	// The events written by this sync carry its nonce, so that the webhook can tell their updates from the user's
+	nonce := s.nonces.issue(time.Now())
+
 	// Get latest token in case it was refreshed
 	token, err := s.tokenManager.GetValidToken(ctx)
 	if err != nil {
@@ -141,6 +237,11 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 		s.calendarID = calendarID
 	}
 
+	// The vacation days have no assignment anymore, their events must go even when nothing else is synced
+	if err := s.removeVacationEvents(ctx); err != nil {
+		return err
+	}
+
 	// If no assignments, nothing to sync
 	if len(assignments) == 0 {
 		s.logger.Info().Msg("No assignments provided, skipping sync")
@@ -162,8 +263,8 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 	s.logger.Debug().Time("first_date", firstDate).Time("last_date", lastDate).Msg("Determined assignment date range")
 
 	// Fetch all events in the date range at once
-	timeMin := firstDate.Add(-24 * time.Hour).Format(time.RFC3339)
-	timeMax := lastDate.Add(24 * time.Hour).Format(time.RFC3339) // Add a day to include last date fully
+	timeMin := dates.AddDays(firstDate, -1).Format(time.RFC3339)
+	timeMax := dates.AddDays(lastDate, 1).Format(time.RFC3339) // Add a day to include last date fully
 	s.logger.Debug().Str("time_min", timeMin).Str("time_max", timeMax).Str("calendar_id", s.calendarID).Msg("Fetching existing events in range")
 
 	events, err := s.srv.Events.List(s.calendarID).
@@ -171,6 +272,7 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 		TimeMax(timeMax).
 		SingleEvents(true).
 		OrderBy("startTime").
+		Context(ctx).
 		Do()
 	if err != nil {
 		s.logger.Error().Err(err).Str("calendar_id", s.calendarID).Msg("Failed to list events for date range")
@@ -178,18 +280,24 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 	}
 	s.logger.Debug().Int("event_count", len(events.Items)).Msg("Fetched existing events")
 
-	// Map events created by our app by assignment ID and date for easy lookup.
+	// The skip dates have no assignment, their events in the synced range must go
+	if events.Items, err = s.removeSkipDateEvents(ctx, events.Items); err != nil {
+		return err
+	}
+
+	// Map events created by our app by assignment ID and date for easy lookup, the dates of each rotation apart.
 	eventsByAssignmentID := make(map[int64][]*calendar.Event)
 	eventsByDate := make(map[string][]*calendar.Event)
 	ourEventCount := 0
 	for _, event := range events.Items {
-		if !eventBelongsToApp(event, s.appUrl) {
+		if !eventBelongsToApp(event, s.branding) {
 			continue
 		}
 
 		ourEventCount++
 		if eventDate := eventStartDate(event); eventDate != "" {
-			eventsByDate[eventDate] = append(eventsByDate[eventDate], event)
+			key := eventDateKey(eventDate, eventChild(event))
+			eventsByDate[key] = append(eventsByDate[key], event)
 		}
 
 		assignmentID, ok, err := eventAssignmentID(event)
@@ -209,6 +317,20 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 		Int("dates_with_events", len(eventsByDate)).
 		Msg("Mapped existing events created by this app")
 
+	if review {
+		if assignments, err = s.stageForReview(assignments, eventsByAssignmentID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	// The excluded nights stay recorded, only their events go
+	if assignments, err = s.removeExcludedEvents(ctx, assignments, eventsByAssignmentID, eventsByDate); err != nil {
+		return err
+	}
+
+	includeComments := s.commentsInEvents()
+	colorIDs := s.parentColorIDs()
+
 	// Track assignments we've already processed to avoid duplicates
 	processedAssignments := make(map[int64]bool)
 	var mu sync.Mutex // Mutex to protect the map
@@ -223,6 +345,13 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 	sem := make(chan struct{}, 2)
 	s.logger.Debug().Int("concurrency_limit", 2).Msg("Starting concurrent assignment processing")
 
+	// The progress of the sync counts each assignment once, as processed below
+	uniqueAssignments := make(map[int64]bool, len(assignments))
+	for _, a := range assignments {
+		uniqueAssignments[a.ID] = true
+	}
+	signals.ReportSyncTotal(ctx, len(uniqueAssignments))
+
 	// Process assignments concurrently
 	for _, assignment := range assignments {
 		// Skip if we've already handled this assignment ID - thread-safe check
@@ -241,10 +370,24 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 		go func(a *scheduler.Assignment) {
 			defer wg.Done()
 
+			// Errors of the assignment are collected for the sync and counted in its progress
+			var assignmentErr error
+			fail := func(err error) {
+				assignmentErr = errors.Join(assignmentErr, err)
+				errChan <- err
+			}
+			defer func() { signals.ReportSyncStep(ctx, assignmentErr) }()
+
 			// Acquire semaphore slot (limits concurrency)
 			sem <- struct{}{}
 			defer func() { <-sem }() // Release semaphore when done
 
+			ctx, span := tracer.Start(ctx, "calendar.sync_assignment", trace.WithAttributes(
+				attribute.Int64("assignment.id", a.ID),
+				attribute.String("assignment.date", a.Date.Format("2006-01-02")),
+			))
+			defer span.End()
+
 			// Create a logger specific to this assignment processing goroutine
 			goroutineLogger := s.logger.With().
 				Int64("assignment_id", a.ID).
@@ -252,32 +395,43 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 				Str("parent", a.Parent).
 				Logger()
 			goroutineLogger.Debug().Msg("Processing assignment")
+			notes := eventNotes{checklist: s.getChecklist(a.ID, goroutineLogger)}
+			if includeComments {
+				notes.comments = s.getComments(a.ID, goroutineLogger)
+			}
 
 			startDateStr := a.Date.Format("2006-01-02")
 			// For all-day events, the end date is the day after the start date.
 			endDateStr := a.Date.AddDate(0, 0, 1).Format("2006-01-02")
 
 			privateData := map[string]string{
-				"updatedAt":     a.UpdatedAt.Format(time.RFC3339),
-				"assignmentId":  fmt.Sprintf("%d", a.ID),
-				"parent":        a.Parent,
-				"caregiverType": a.CaregiverType.String(),
-				"app":           constants.NightRoutineIdentifier,
+				"updatedAt":       a.UpdatedAt.Format(time.RFC3339),
+				"assignmentId":    fmt.Sprintf("%d", a.ID),
+				"parent":          a.Parent,
+				"caregiverType":   a.CaregiverType.String(),
+				"app":             s.branding.Identifier,
+				syncNonceProperty: nonce,
+			}
+			if a.Child != "" {
+				privateData["child"] = a.Child
 			}
+			colorID := ""
 			if a.CaregiverType == fairness.CaregiverTypeBabysitter {
 				privateData["babysitterName"] = a.Parent
+			} else {
+				colorID = colorIDs[a.Parent]
 			}
 
 			// Check if we already have a Google Calendar event ID for this assignment
 			if a.GoogleCalendarEventID != "" {
 				goroutineLogger.Debug().Str("event_id", a.GoogleCalendarEventID).Msg("Assignment has existing event ID, attempting update")
-				event, err := s.srv.Events.Get(s.calendarID, a.GoogleCalendarEventID).Do()
+				event, err := s.srv.Events.Get(s.calendarID, a.GoogleCalendarEventID).Context(ctx).Do()
 				if err == nil {
-					if eventBelongsToApp(event, s.appUrl) {
+					if eventBelongsToApp(event, s.branding) {
 						goroutineLogger.Debug().Str("event_id", event.Id).Msg("Existing managed event found by ID, updating")
-						populateManagedEvent(event, a, privateData, startDateStr, endDateStr, s.appUrl)
+						populateManagedEvent(event, a, colorID, notes, privateData, startDateStr, endDateStr, s.branding)
 
-						_, err = s.srv.Events.Update(s.calendarID, event.Id, event).Do()
+						_, err = s.srv.Events.Update(s.calendarID, event.Id, event).Context(ctx).Do()
 						if err == nil {
 							goroutineLogger.Info().Str("event_id", event.Id).Msg("Successfully updated existing event")
 							return
@@ -297,7 +451,7 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 			var dateEvents []*calendar.Event
 			mu.Lock()
 			assignmentEvents = append(assignmentEvents, eventsByAssignmentID[a.ID]...)
-			dateEvents = append(dateEvents, eventsByDate[startDateStr]...)
+			dateEvents = append(dateEvents, eventsByDate[eventDateKey(startDateStr, a.Child)]...)
 			mu.Unlock()
 
 			reusableEvent, duplicateEvents := selectReusableManagedEvent(assignmentEvents, dateEvents)
@@ -306,12 +460,12 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 					Str("event_id", reusableEvent.Id).
 					Int("duplicate_count", len(duplicateEvents)).
 					Msg("Found existing managed event to relink")
-				populateManagedEvent(reusableEvent, a, privateData, startDateStr, endDateStr, s.appUrl)
+				populateManagedEvent(reusableEvent, a, colorID, notes, privateData, startDateStr, endDateStr, s.branding)
 
-				_, err := s.srv.Events.Update(s.calendarID, reusableEvent.Id, reusableEvent).Do()
+				_, err := s.srv.Events.Update(s.calendarID, reusableEvent.Id, reusableEvent).Context(ctx).Do()
 				if err == nil {
 					if a.GoogleCalendarEventID != reusableEvent.Id {
-						if err := s.scheduler.UpdateGoogleCalendarEventID(a, reusableEvent.Id); err != nil {
+						if err := s.scheduler.UpdateGoogleCalendarEventID(ctx, a, reusableEvent.Id); err != nil {
 							goroutineLogger.Error().Err(err).Str("event_id", reusableEvent.Id).Msg("Failed to relink assignment in DB to existing managed event")
 						} else {
 							goroutineLogger.Info().Str("event_id", reusableEvent.Id).Msg("Relinked assignment in DB to existing managed event")
@@ -320,14 +474,14 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 
 					for _, duplicateEvent := range duplicateEvents {
 						goroutineLogger.Debug().Str("event_id", duplicateEvent.Id).Msg("Deleting duplicate managed event")
-						err := s.srv.Events.Delete(s.calendarID, duplicateEvent.Id).Do()
+						err := s.srv.Events.Delete(s.calendarID, duplicateEvent.Id).Context(ctx).Do()
 						if err != nil {
 							if isGoogleAPINotFound(err) {
 								goroutineLogger.Info().Str("event_id", duplicateEvent.Id).Msg("Duplicate managed event already missing during delete")
 								continue
 							}
 							goroutineLogger.Error().Err(err).Str("event_id", duplicateEvent.Id).Msg("Failed to delete duplicate managed event")
-							errChan <- fmt.Errorf("failed to delete duplicate managed event %s for %v: %w", duplicateEvent.Id, a.Date, err)
+							fail(fmt.Errorf("failed to delete duplicate managed event %s for %v: %w", duplicateEvent.Id, a.Date, err))
 						} else {
 							goroutineLogger.Info().Str("event_id", duplicateEvent.Id).Msg("Successfully deleted duplicate managed event")
 						}
@@ -343,14 +497,14 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 				goroutineLogger.Debug().Int("count", len(duplicateEvents)).Msg("Deleting existing managed events before recreation")
 				for _, existingEvent := range duplicateEvents {
 					goroutineLogger.Debug().Str("event_id", existingEvent.Id).Msg("Deleting event")
-					err := s.srv.Events.Delete(s.calendarID, existingEvent.Id).Do()
+					err := s.srv.Events.Delete(s.calendarID, existingEvent.Id).Context(ctx).Do()
 					if err != nil {
 						if isGoogleAPINotFound(err) {
 							goroutineLogger.Info().Str("event_id", existingEvent.Id).Msg("Managed event already missing during delete, continuing with recreation")
 							continue
 						}
 						goroutineLogger.Error().Err(err).Str("event_id", existingEvent.Id).Msg("Failed to delete existing event")
-						errChan <- fmt.Errorf("failed to delete existing event %s for %v: %w", existingEvent.Id, a.Date, err)
+						fail(fmt.Errorf("failed to delete existing event %s for %v: %w", existingEvent.Id, a.Date, err))
 					} else {
 						goroutineLogger.Info().Str("event_id", existingEvent.Id).Msg("Successfully deleted existing event")
 					}
@@ -369,26 +523,27 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 				Location:     "Home",
 				Transparency: "transparent",
 				Source: &calendar.EventSource{
-					Title: constants.NightRoutineIdentifier,
-					Url:   s.appUrl,
+					Title: s.branding.Identifier,
+					Url:   s.branding.SourceURL,
 				},
 				ExtendedProperties: &calendar.EventExtendedProperties{
 					Private: privateData,
 				},
 			}
-			populateManagedEvent(event, a, privateData, startDateStr, endDateStr, s.appUrl)
+			populateManagedEvent(event, a, colorID, notes, privateData, startDateStr, endDateStr, s.branding)
 
 			// Create the event in Google Calendar
-			createdEvent, err := s.srv.Events.Insert(s.calendarID, event).Do()
+			createdEvent, err := s.srv.Events.Insert(s.calendarID, event).Context(ctx).Do()
 			if err != nil {
 				goroutineLogger.Error().Err(err).Msg("Failed to create new event")
-				errChan <- fmt.Errorf("failed to create event for %v: %w", a.Date, err)
+				tracing.RecordError(span, err)
+				fail(fmt.Errorf("failed to create event for %v: %w", a.Date, err))
 				return
 			}
 			goroutineLogger.Info().Str("event_id", createdEvent.Id).Msg("Successfully created new event")
 
 			// Update the assignment with the Google Calendar event ID
-			if err := s.scheduler.UpdateGoogleCalendarEventID(a, createdEvent.Id); err != nil {
+			if err := s.scheduler.UpdateGoogleCalendarEventID(ctx, a, createdEvent.Id); err != nil {
 				// Log error but continue; this isn't fatal for the sync operation itself
 				goroutineLogger.Error().Err(err).Str("event_id", createdEvent.Id).Msg("Failed to update assignment in DB with Google Calendar event ID")
 				// Don't send to errChan as the calendar event was created
@@ -419,29 +574,309 @@ func (s *Service) SyncSchedule(ctx context.Context, assignments []*scheduler.Ass
 		return joinedErr // Return the joined error
 	}
 
+	// The events of the synced assignments are published, they no longer wait for review
+	if s.review.Staging != nil {
+		ids := make([]int64, 0, len(assignments))
+		for _, a := range assignments {
+			ids = append(ids, a.ID)
+		}
+		if err := s.review.Staging.Unstage(ids); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to unstage synced assignments")
+			return fmt.Errorf("failed to unstage synced assignments: %w", err)
+		}
+	}
+
 	s.logger.Info().Int("assignments_count", len(assignments)).Msg("Schedule sync completed successfully")
 	return nil
 }
 
+// RemoveEvents deletes the managed events of eventIDs, e.g. the ones of the nights of a removed
+// child. Events already gone are skipped.
+func (s *Service) RemoveEvents(ctx context.Context, eventIDs []string) error {
+	if !s.initialized || s.srv == nil {
+		s.logger.Warn().Msg("RemoveEvents called but service is not initialized")
+		return fmt.Errorf("calendar service not initialized - authentication required")
+	}
+
+	var errs []error
+	removed := 0
+	for _, eventID := range eventIDs {
+		if eventID == "" {
+			continue
+		}
+		if err := s.srv.Events.Delete(s.calendarID, eventID).Context(ctx).Do(); err != nil && !isGoogleAPINotFound(err) {
+			s.logger.Error().Err(err).Str("event_id", eventID).Msg("Failed to delete event")
+			errs = append(errs, fmt.Errorf("failed to delete event %s: %w", eventID, err))
+			continue
+		}
+		removed++
+	}
+	s.logger.Info().Int("removed", removed).Msg("Removed events")
+	return errors.Join(errs...)
+}
+
+// RemoveAllEvents deletes every event of the selected calendar managed by this instance, past ones
+// included, and returns how many were deleted. Used when all the data is wiped. The whole calendar is
+// listed, as the events told apart by their source link only have no private property to filter on.
+func (s *Service) RemoveAllEvents(ctx context.Context) (int, error) {
+	if !s.initialized || s.srv == nil {
+		s.logger.Warn().Msg("RemoveAllEvents called but service is not initialized")
+		return 0, fmt.Errorf("calendar service not initialized - authentication required")
+	}
+
+	var eventIDs []string
+	err := s.srv.Events.List(s.calendarID).
+		SingleEvents(true).
+		Pages(ctx, func(page *calendar.Events) error {
+			for _, event := range page.Items {
+				if eventBelongsToApp(event, s.branding) {
+					eventIDs = append(eventIDs, event.Id)
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		s.logger.Error().Err(err).Str("calendar_id", s.calendarID).Msg("Failed to list managed events")
+		return 0, fmt.Errorf("failed to list managed events: %w", err)
+	}
+
+	err = s.RemoveEvents(ctx, eventIDs)
+	return len(eventIDs), err
+}
+
+// removeVacationEvents deletes the managed events of the vacation days from today on. The nights of
+// the vacation which already happened keep their events.
+func (s *Service) removeVacationEvents(ctx context.Context) error {
+	vacation, err := s.scheduler.GetVacation()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get vacation during sync")
+		return err
+	}
+	if !vacation.Enabled {
+		return nil
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from := vacation.Start
+	if from.Before(today) {
+		from = today
+	}
+	if from.After(vacation.End) {
+		return nil
+	}
+	vacationLogger := s.logger.With().Str("from", from.Format("2006-01-02")).Str("to", vacation.End.Format("2006-01-02")).Logger()
+
+	events, err := s.srv.Events.List(s.calendarID).
+		TimeMin(dates.AddDays(from, -1).Format(time.RFC3339)).
+		TimeMax(dates.AddDays(vacation.End, 2).Format(time.RFC3339)).
+		SingleEvents(true).
+		Context(ctx).
+		Do()
+	if err != nil {
+		vacationLogger.Error().Err(err).Msg("Failed to list events of the vacation")
+		return fmt.Errorf("failed to list events of the vacation: %w", err)
+	}
+
+	var errs []error
+	removed := 0
+	for _, event := range events.Items {
+		startDate := eventStartDate(event)
+		if !eventBelongsToApp(event, s.branding) || startDate < from.Format("2006-01-02") || startDate > vacation.End.Format("2006-01-02") {
+			continue
+		}
+		if err := s.srv.Events.Delete(s.calendarID, event.Id).Context(ctx).Do(); err != nil && !isGoogleAPINotFound(err) {
+			vacationLogger.Error().Err(err).Str("event_id", event.Id).Msg("Failed to delete event of the vacation")
+			errs = append(errs, fmt.Errorf("failed to delete event %s of the vacation: %w", event.Id, err))
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		vacationLogger.Info().Int("removed", removed).Msg("Removed the events of the vacation")
+	}
+	return errors.Join(errs...)
+}
+
+// removeSkipDateEvents deletes the managed events among events falling on a skip date from today
+// on, and returns the other events. The nights which already happened keep their events.
+func (s *Service) removeSkipDateEvents(ctx context.Context, events []*calendar.Event) ([]*calendar.Event, error) {
+	skipDates, err := s.scheduler.GetSkipDates()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get skip dates during sync")
+		return nil, err
+	}
+	if len(skipDates) == 0 {
+		return events, nil
+	}
+
+	today := time.Now().Format("2006-01-02")
+	kept := events[:0]
+	var errs []error
+	for _, event := range events {
+		startDate := eventStartDate(event)
+		if !eventBelongsToApp(event, s.branding) || startDate < today {
+			kept = append(kept, event)
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil || !skipDates.Contains(date) {
+			kept = append(kept, event)
+			continue
+		}
+		if err := s.srv.Events.Delete(s.calendarID, event.Id).Context(ctx).Do(); err != nil && !isGoogleAPINotFound(err) {
+			s.logger.Error().Err(err).Str("event_id", event.Id).Str("date", startDate).Msg("Failed to delete event of a skip date")
+			errs = append(errs, fmt.Errorf("failed to delete event %s of skip date %s: %w", event.Id, startDate, err))
+			continue
+		}
+		s.logger.Info().Str("event_id", event.Id).Str("date", startDate).Msg("Removed the event of a skip date")
+	}
+	return kept, errors.Join(errs...)
+}
+
+// removeExcludedEvents deletes the managed events of the assignments kept out of the calendar by the
+// sync exclusions, clears their event ID, and returns the assignments to sync. eventsByAssignmentID
+// and eventsByDate are the managed events of the synced range.
+func (s *Service) removeExcludedEvents(ctx context.Context, assignments []*scheduler.Assignment, eventsByAssignmentID map[int64][]*calendar.Event, eventsByDate map[string][]*calendar.Event) ([]*scheduler.Assignment, error) {
+	exclusions, err := s.scheduler.GetSyncExclusions()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get sync exclusions during sync")
+		return nil, err
+	}
+	if exclusions.IsEmpty() {
+		return assignments, nil
+	}
+
+	toSync := make([]*scheduler.Assignment, 0, len(assignments))
+	var errs []error
+	for _, a := range assignments {
+		if !exclusions.Excludes(a.CaregiverType.String(), a.Tag.String()) {
+			toSync = append(toSync, a)
+			continue
+		}
+		date := a.Date.Format("2006-01-02")
+		eventIDs := make(map[string]bool)
+		if a.GoogleCalendarEventID != "" {
+			eventIDs[a.GoogleCalendarEventID] = true
+		}
+		for _, event := range append(eventsByAssignmentID[a.ID], eventsByDate[eventDateKey(date, a.Child)]...) {
+			eventIDs[event.Id] = true
+		}
+
+		deleted := true
+		for eventID := range eventIDs {
+			if err := s.srv.Events.Delete(s.calendarID, eventID).Context(ctx).Do(); err != nil && !isGoogleAPINotFound(err) {
+				s.logger.Error().Err(err).Str("event_id", eventID).Str("date", date).Msg("Failed to delete event of an excluded night")
+				errs = append(errs, fmt.Errorf("failed to delete event %s of excluded night %s: %w", eventID, date, err))
+				deleted = false
+				continue
+			}
+			s.logger.Info().Str("event_id", eventID).Str("date", date).Msg("Removed the event of an excluded night")
+		}
+		if deleted && a.GoogleCalendarEventID != "" {
+			if err := s.scheduler.UpdateGoogleCalendarEventID(ctx, a, ""); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if excluded := len(assignments) - len(toSync); excluded > 0 {
+		s.logger.Debug().Int("excluded_count", excluded).Strs("exclusions", exclusions.Kinds()).Msg("Nights kept out of the calendar")
+	}
+	return toSync, errors.Join(errs...)
+}
+
 // displayName returns the name to show in calendar events.
 // For all caregiver types, parent_name holds the correct display name.
 func displayName(assignment *scheduler.Assignment) string {
 	return assignment.Parent
 }
 
-func formatEventSummary(assignment *scheduler.Assignment) string {
-	return fmt.Sprintf("[%s] 🌃👶Routine", displayName(assignment))
+// formatEventSummary formats the event title, naming the child of the night outside the main
+// rotation, e.g. "[Alice] 🌃👶Emma Routine"
+func formatEventSummary(assignment *scheduler.Assignment, branding Branding) string {
+	if assignment.Child != "" {
+		return fmt.Sprintf("[%s] %s%s Routine", displayName(assignment), branding.Emoji, assignment.Child)
+	}
+	return fmt.Sprintf("[%s] %sRoutine", displayName(assignment), branding.Emoji)
 }
 
-// formatEventDescription formats the event description string.
-func formatEventDescription(assignment *scheduler.Assignment) string {
+// formatEventDescription formats the event description string with the tag of the night, followed
+// by the checklist and the comments when there are some.
+func formatEventDescription(assignment *scheduler.Assignment, notes eventNotes, branding Branding) string {
 	name := displayName(assignment)
+	var description string
 	if assignment.CaregiverType == fairness.CaregiverTypeBabysitter {
-		return fmt.Sprintf("Night routine handled by babysitter %s. Reason: %s [%s]",
-			name, assignment.DecisionReason.String(), constants.NightRoutineIdentifier)
+		description = fmt.Sprintf("Night routine handled by babysitter %s. Reason: %s [%s]",
+			name, assignment.DecisionReason.String(), branding.Identifier)
+	} else {
+		description = fmt.Sprintf("Night routine duty assigned to %s. Reason: %s [%s]",
+			name, assignment.DecisionReason.String(), branding.Identifier)
 	}
-	return fmt.Sprintf("Night routine duty assigned to %s. Reason: %s [%s]",
-		name, assignment.DecisionReason.String(), constants.NightRoutineIdentifier)
+	if label := assignment.Tag.Label(); label != "" {
+		description += "\nTag: " + label
+	}
+	if len(notes.checklist) == 0 && len(notes.comments) == 0 {
+		return description
+	}
+
+	var b strings.Builder
+	b.WriteString(description)
+	if len(notes.checklist) > 0 {
+		b.WriteString("\n\nChecklist:")
+		for _, item := range notes.checklist {
+			box := "☐"
+			if item.Done() {
+				box = "☑"
+			}
+			fmt.Fprintf(&b, "\n%s %s", box, item.Label)
+		}
+	}
+	if len(notes.comments) > 0 {
+		b.WriteString("\n\nComments:")
+		for _, comment := range notes.comments {
+			fmt.Fprintf(&b, "\n%s (%s): %s", comment.Author, comment.CreatedAt.Format("2006-01-02 15:04"), comment.Text)
+		}
+	}
+	return b.String()
+}
+
+// getChecklist returns the checklist of the assignment, nil when it cannot be read so that the
+// event is still synced
+func (s *Service) getChecklist(assignmentID int64, logger zerolog.Logger) []*database.ChecklistItem {
+	if s.checklists == nil {
+		return nil
+	}
+	checklist, err := s.checklists.GetChecklist(assignmentID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get assignment checklist, syncing the event without it")
+		return nil
+	}
+	return checklist
+}
+
+// commentsInEvents reports whether the comments are appended to the event descriptions, false when
+// it cannot be read so that the events are still synced
+func (s *Service) commentsInEvents() bool {
+	if s.comments == nil {
+		return false
+	}
+	enabled, err := s.comments.IsCommentsInEventsEnabled()
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to read whether comments are shown in events, syncing the events without them")
+		return false
+	}
+	return enabled
+}
+
+// getComments returns the comments of the assignment, nil when they cannot be read so that the
+// event is still synced
+func (s *Service) getComments(assignmentID int64, logger zerolog.Logger) []*database.Comment {
+	comments, err := s.comments.ListComments(assignmentID)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get assignment comments, syncing the event without them")
+		return nil
+	}
+	return comments
 }
 
 // setNoReminders disables all reminders for an event.
@@ -453,9 +888,11 @@ func setNoReminders(event *calendar.Event) {
 	}
 }
 
-func populateManagedEvent(event *calendar.Event, assignment *scheduler.Assignment, privateData map[string]string, startDateStr string, endDateStr string, appURL string) {
-	event.Summary = formatEventSummary(assignment)
-	event.Description = formatEventDescription(assignment)
+// populateManagedEvent fills event from the assignment. colorID is the Google Calendar color of the
+// parent on duty, empty for the default color of the calendar.
+func populateManagedEvent(event *calendar.Event, assignment *scheduler.Assignment, colorID string, notes eventNotes, privateData map[string]string, startDateStr string, endDateStr string, branding Branding) {
+	event.Summary = formatEventSummary(assignment, branding)
+	event.Description = formatEventDescription(assignment, notes, branding)
 	if event.Start == nil {
 		event.Start = &calendar.EventDateTime{}
 	}
@@ -467,25 +904,45 @@ func populateManagedEvent(event *calendar.Event, assignment *scheduler.Assignmen
 	if event.Source == nil {
 		event.Source = &calendar.EventSource{}
 	}
-	event.Source.Title = constants.NightRoutineIdentifier
-	event.Source.Url = appURL
+	event.Source.Title = branding.Identifier
+	event.Source.Url = branding.SourceURL
 	if event.ExtendedProperties == nil {
 		event.ExtendedProperties = &calendar.EventExtendedProperties{}
 	}
 	event.ExtendedProperties.Private = privateData
+	event.ColorId = colorID
+	if colorID == "" && !slices.Contains(event.ForceSendFields, "ColorId") {
+		// An empty color must be sent to reset the color of the event to the one of the calendar
+		event.ForceSendFields = append(event.ForceSendFields, "ColorId")
+	}
 	setNoReminders(event)
 }
 
-func eventBelongsToApp(event *calendar.Event, appURL string) bool {
+// parentColorIDs returns the Google Calendar color ID of each parent, keyed by the name of the
+// parent. Without styles the events keep the color of the calendar.
+func (s *Service) parentColorIDs() map[string]string {
+	styles, err := s.scheduler.GetParentStyles()
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to get parent styles, events use the calendar color")
+		return nil
+	}
+	colorIDs := make(map[string]string, len(styles))
+	for parent, style := range styles {
+		colorIDs[parent] = style.Color.GoogleColorID()
+	}
+	return colorIDs
+}
+
+func eventBelongsToApp(event *calendar.Event, branding Branding) bool {
 	if event == nil {
 		return false
 	}
 	if event.ExtendedProperties != nil && event.ExtendedProperties.Private != nil {
-		if appIdentifier, ok := event.ExtendedProperties.Private["app"]; ok && appIdentifier == constants.NightRoutineIdentifier {
+		if appIdentifier, ok := event.ExtendedProperties.Private["app"]; ok && appIdentifier == branding.Identifier {
 			return true
 		}
 	}
-	return event.Source != nil && event.Source.Url == appURL
+	return event.Source != nil && event.Source.Url == branding.SourceURL
 }
 
 func eventAssignmentID(event *calendar.Event) (int64, bool, error) {
@@ -503,6 +960,23 @@ func eventAssignmentID(event *calendar.Event) (int64, bool, error) {
 	return assignmentID, true, nil
 }
 
+// eventChild returns the child of the rotation of a managed event, empty for the main rotation
+func eventChild(event *calendar.Event) string {
+	if event == nil || event.ExtendedProperties == nil {
+		return ""
+	}
+	return event.ExtendedProperties.Private["child"]
+}
+
+// eventDateKey returns the key of the managed events of date in the rotation of child, so that the
+// events of the rotations sharing a date are never relinked to one another
+func eventDateKey(date, child string) string {
+	if child == "" {
+		return date
+	}
+	return date + "/" + child
+}
+
 func eventStartDate(event *calendar.Event) string {
 	if event == nil || event.Start == nil {
 		return ""
@@ -2,28 +2,34 @@ package calendar
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/http/httptest"
 	"strings"
-	"sync"
 	"testing"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/database"
 	"github.com/belphemur/night-routine/internal/fairness"
 	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/fakecalendar"
+	"github.com/belphemur/night-routine/internal/signals"
 	"github.com/belphemur/night-routine/internal/token"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 	gcalendar "google.golang.org/api/calendar/v3"
-	"google.golang.org/api/option"
 	_ "modernc.org/sqlite"
 )
 
+// testBranding is the default branding of the events, with the application URL of the tests
+var testBranding = Branding{
+	Emoji:      constants.DefaultEventEmoji,
+	Identifier: constants.NightRoutineIdentifier,
+	SourceURL:  "https://app.example",
+}
+
 func TestDisplayName(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -85,11 +91,20 @@ func TestFormatEventSummary(t *testing.T) {
 			},
 			want: "[Dawn] \U0001f303\U0001f476Routine",
 		},
+		{
+			name: "assignment of a child",
+			assignment: &scheduler.Assignment{
+				Parent:        "Alice",
+				CaregiverType: fairness.CaregiverTypeParent,
+				Child:         "Emma",
+			},
+			want: "[Alice] \U0001f303\U0001f476Emma Routine",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.want, formatEventSummary(tt.assignment))
+			assert.Equal(t, tt.want, formatEventSummary(tt.assignment, testBranding))
 		})
 	}
 }
@@ -125,246 +140,179 @@ func TestFormatEventDescription(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			desc := formatEventDescription(tt.assignment)
+			desc := formatEventDescription(tt.assignment, eventNotes{}, testBranding)
 			assert.Contains(t, desc, tt.wantPrefix)
 			assert.Contains(t, desc, tt.wantSuffix)
 		})
 	}
 }
 
-type calendarTestConfigStore struct {
-	parentA string
-	parentB string
-}
-
-func (s *calendarTestConfigStore) GetParents() (string, string, error) {
-	return s.parentA, s.parentB, nil
-}
-
-func (s *calendarTestConfigStore) GetAvailability(parent string) ([]string, error) {
-	return nil, nil
-}
+func TestFormatEventDescription_Checklist(t *testing.T) {
+	assignment := &scheduler.Assignment{
+		Parent:         "Alice",
+		CaregiverType:  fairness.CaregiverTypeParent,
+		DecisionReason: fairness.DecisionReasonAlternating,
+	}
+	completedAt := time.Date(2026, 10, 16, 19, 30, 0, 0, time.UTC)
 
-func (s *calendarTestConfigStore) GetSchedule() (string, int, int, constants.StatsOrder, error) {
-	return "weekly", 7, 5, constants.StatsOrderDesc, nil
-}
+	desc := formatEventDescription(assignment, eventNotes{checklist: []*database.ChecklistItem{
+		{Label: "Bath", CompletedAt: &completedAt},
+		{Label: "Story"},
+	}}, testBranding)
 
-func (s *calendarTestConfigStore) GetOAuthConfig() *oauth2.Config {
-	return nil
+	assert.Equal(t, "Night routine duty assigned to Alice. Reason: Alternating ["+constants.NightRoutineIdentifier+"]\n\n"+
+		"Checklist:\n☑ Bath\n☐ Story", desc)
 }
 
-func setupCalendarTestDB(t *testing.T) (*database.DB, func()) {
-	t.Helper()
-
-	db, err := database.New(database.SQLiteOptions{
-		Path:        ":memory:",
-		Mode:        "memory",
-		Cache:       database.CacheShared,
-		ForeignKeys: true,
-		Journal:     database.JournalMemory,
-		BusyTimeout: 5000,
-	})
-	require.NoError(t, err)
-	require.NoError(t, db.MigrateDatabase())
-
-	return db, func() {
-		require.NoError(t, db.Close())
+func TestFormatEventDescription_Tag(t *testing.T) {
+	assignment := &scheduler.Assignment{
+		Parent:         "Alice",
+		CaregiverType:  fairness.CaregiverTypeParent,
+		DecisionReason: fairness.DecisionReasonOverride,
+		Override:       true,
+		Tag:            fairness.AssignmentTagSickKid,
 	}
-}
-
-type fakeCalendarAPI struct {
-	t      *testing.T
-	mu     sync.Mutex
-	events map[string]*gcalendar.Event
-	nextID int
-}
 
-func newFakeCalendarAPI(t *testing.T, events ...*gcalendar.Event) *fakeCalendarAPI {
-	t.Helper()
+	desc := formatEventDescription(assignment, eventNotes{}, testBranding)
 
-	api := &fakeCalendarAPI{
-		t:      t,
-		events: make(map[string]*gcalendar.Event, len(events)),
-		nextID: 1,
-	}
-	for _, event := range events {
-		cloned := cloneEvent(t, event)
-		api.events[cloned.Id] = cloned
-	}
-	return api
+	assert.Equal(t, "Night routine duty assigned to Alice. Reason: Override ["+constants.NightRoutineIdentifier+"]\nTag: Sick kid", desc)
 }
 
-func (f *fakeCalendarAPI) handle(w http.ResponseWriter, r *http.Request) {
-	f.t.Helper()
-
-	idx := strings.Index(r.URL.Path, "/calendars/")
-	if idx == -1 {
-		http.NotFound(w, r)
-		return
-	}
-
-	parts := strings.Split(strings.Trim(r.URL.Path[idx+len("/calendars/"):], "/"), "/")
-	if len(parts) < 2 || parts[1] != "events" {
-		http.NotFound(w, r)
-		return
+func TestFormatEventDescription_Comments(t *testing.T) {
+	assignment := &scheduler.Assignment{
+		Parent:         "Alice",
+		CaregiverType:  fairness.CaregiverTypeParent,
+		DecisionReason: fairness.DecisionReasonAlternating,
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		if len(parts) == 2 {
-			f.handleList(w)
-			return
-		}
-		if len(parts) == 3 {
-			f.handleGet(w, parts[2])
-			return
-		}
-	case http.MethodPost:
-		if len(parts) == 2 {
-			f.handleInsert(w, r)
-			return
-		}
-	case http.MethodPut:
-		if len(parts) == 3 {
-			f.handleUpdate(w, r, parts[2])
-			return
-		}
-	case http.MethodDelete:
-		if len(parts) == 3 {
-			f.handleDelete(w, parts[2])
-			return
-		}
-	}
+	desc := formatEventDescription(assignment, eventNotes{
+		checklist: []*database.ChecklistItem{{Label: "Bath"}},
+		comments: []*database.Comment{
+			{Author: "Bob", Text: "Fell asleep early", CreatedAt: time.Date(2026, 10, 16, 20, 5, 0, 0, time.UTC)},
+		},
+	}, testBranding)
 
-	http.NotFound(w, r)
+	assert.True(t, strings.HasSuffix(desc, "Checklist:\n☐ Bath\n\nComments:\nBob (2026-10-16 20:05): Fell asleep early"), desc)
 }
 
-func (f *fakeCalendarAPI) handleList(w http.ResponseWriter) {
-	f.mu.Lock()
-	items := make([]*gcalendar.Event, 0, len(f.events))
-	for _, event := range f.events {
-		items = append(items, cloneEvent(f.t, event))
+// TestBranding verifies that the events of an instance with its own branding carry its emoji and
+// identifier, and that the events of another instance are not taken as managed
+func TestBranding(t *testing.T) {
+	fork := Branding{Emoji: "🛁", Identifier: "Bath Time", SourceURL: "https://bath.example"}
+	assignment := &scheduler.Assignment{
+		Parent:         "Alice",
+		CaregiverType:  fairness.CaregiverTypeParent,
+		DecisionReason: fairness.DecisionReasonAlternating,
 	}
-	f.mu.Unlock()
 
-	writeJSONResponse(f.t, w, http.StatusOK, &gcalendar.Events{Items: items})
-}
+	event := &gcalendar.Event{}
+	populateManagedEvent(event, assignment, "9", eventNotes{}, map[string]string{"app": fork.Identifier}, "2026-10-16", "2026-10-17", fork)
 
-func (f *fakeCalendarAPI) handleGet(w http.ResponseWriter, eventID string) {
-	f.mu.Lock()
-	event, ok := f.events[eventID]
-	f.mu.Unlock()
-	if !ok {
-		http.Error(w, `{"error":{"code":404,"message":"not found"}}`, http.StatusNotFound)
-		return
-	}
-
-	writeJSONResponse(f.t, w, http.StatusOK, cloneEvent(f.t, event))
+	assert.Equal(t, "[Alice] 🛁Routine", event.Summary)
+	assert.Equal(t, "Night routine duty assigned to Alice. Reason: Alternating [Bath Time]", event.Description)
+	assert.Equal(t, "Bath Time", event.Source.Title)
+	assert.Equal(t, "https://bath.example", event.Source.Url)
+	assert.Equal(t, "9", event.ColorId)
+	assert.True(t, eventBelongsToApp(event, fork))
+	assert.False(t, eventBelongsToApp(event, testBranding), "events of another instance are not managed")
 }
 
-func (f *fakeCalendarAPI) handleInsert(w http.ResponseWriter, r *http.Request) {
-	var event gcalendar.Event
-	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&event))
-
-	f.mu.Lock()
-	event.Id = fmt.Sprintf("created-%d", f.nextID)
-	f.nextID++
-	stored := cloneEvent(f.t, &event)
-	f.events[stored.Id] = stored
-	f.mu.Unlock()
-
-	writeJSONResponse(f.t, w, http.StatusOK, cloneEvent(f.t, stored))
+type calendarTestConfigStore struct {
+	parentA    string
+	parentB    string
+	vacation   config.Vacation
+	skipDates  config.SkipDates
+	exclusions config.SyncExclusions
 }
 
-func (f *fakeCalendarAPI) handleUpdate(w http.ResponseWriter, r *http.Request, eventID string) {
-	var event gcalendar.Event
-	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&event))
+func (s *calendarTestConfigStore) GetParents() (string, string, error) {
+	return s.parentA, s.parentB, nil
+}
 
-	f.mu.Lock()
-	if _, ok := f.events[eventID]; !ok {
-		f.mu.Unlock()
-		http.Error(w, `{"error":{"code":404,"message":"not found"}}`, http.StatusNotFound)
-		return
-	}
-	event.Id = eventID
-	stored := cloneEvent(f.t, &event)
-	f.events[eventID] = stored
-	f.mu.Unlock()
+func (s *calendarTestConfigStore) GetExtraParents() ([]config.ExtraParent, error) {
+	return nil, nil
+}
 
-	writeJSONResponse(f.t, w, http.StatusOK, cloneEvent(f.t, stored))
+func (s *calendarTestConfigStore) GetAvailability(parent string) ([]string, error) {
+	return nil, nil
 }
 
-func (f *fakeCalendarAPI) handleDelete(w http.ResponseWriter, eventID string) {
-	f.mu.Lock()
-	if _, ok := f.events[eventID]; !ok {
-		f.mu.Unlock()
-		http.Error(w, `{"error":{"code":404,"message":"not found"}}`, http.StatusNotFound)
-		return
-	}
-	delete(f.events, eventID)
-	f.mu.Unlock()
+func (s *calendarTestConfigStore) GetSchedule() (string, int, int, constants.StatsOrder, error) {
+	return "weekly", 7, 5, constants.StatsOrderDesc, nil
+}
 
-	w.WriteHeader(http.StatusNoContent)
+func (s *calendarTestConfigStore) GetUnavailabilityRules(_ string) ([]config.UnavailabilityRule, error) {
+	return nil, nil
 }
 
-func (f *fakeCalendarAPI) event(t *testing.T, eventID string) *gcalendar.Event {
-	t.Helper()
+func (s *calendarTestConfigStore) GetLookAheadWindows() (config.LookAheadWindows, error) {
+	return config.LookAheadWindows{}, nil
+}
 
-	f.mu.Lock()
-	defer f.mu.Unlock()
+func (s *calendarTestConfigStore) GetVacation() (config.Vacation, error) {
+	return s.vacation, nil
+}
 
-	event, ok := f.events[eventID]
-	require.True(t, ok, "event %s should exist", eventID)
-	return cloneEvent(t, event)
+func (s *calendarTestConfigStore) GetSkipDates() (config.SkipDates, error) {
+	return s.skipDates, nil
 }
 
-func (f *fakeCalendarAPI) eventCount() int {
-	f.mu.Lock()
-	defer f.mu.Unlock()
-	return len(f.events)
+func (s *calendarTestConfigStore) GetSyncExclusions() (config.SyncExclusions, error) {
+	return s.exclusions, nil
 }
 
-func (f *fakeCalendarAPI) addEvent(t *testing.T, event *gcalendar.Event) {
-	t.Helper()
+func (s *calendarTestConfigStore) GetMinRestDays() (int, error) {
+	return 0, nil
+}
 
-	f.mu.Lock()
-	defer f.mu.Unlock()
+func (s *calendarTestConfigStore) GetDayWeights() (config.DayWeights, error) {
+	return nil, nil
+}
 
-	cloned := cloneEvent(t, event)
-	f.events[cloned.Id] = cloned
+func (s *calendarTestConfigStore) GetParentStyles() (config.ParentStyle, config.ParentStyle, error) {
+	parentA, parentB := config.DefaultParentStyles()
+	return parentA, parentB, nil
 }
 
-func (f *fakeCalendarAPI) eventExists(eventID string) bool {
-	f.mu.Lock()
-	defer f.mu.Unlock()
-	_, ok := f.events[eventID]
-	return ok
+func (s *calendarTestConfigStore) GetOAuthConfig() *oauth2.Config {
+	return nil
 }
 
-func writeJSONResponse(t *testing.T, w http.ResponseWriter, statusCode int, payload any) {
+func setupCalendarTestDB(t *testing.T) (*database.DB, func()) {
 	t.Helper()
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	require.NoError(t, json.NewEncoder(w).Encode(payload))
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "memory",
+		Cache:       database.CacheShared,
+		ForeignKeys: true,
+		Journal:     database.JournalMemory,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+
+	return db, func() {
+		require.NoError(t, db.Close())
+	}
 }
 
-func cloneEvent(t *testing.T, event *gcalendar.Event) *gcalendar.Event {
+// storedEvent returns the event of the primary calendar of the fake API
+func storedEvent(t *testing.T, api *fakecalendar.Server, eventID string) *gcalendar.Event {
 	t.Helper()
 
-	if event == nil {
-		return nil
-	}
-
-	raw, err := json.Marshal(event)
-	require.NoError(t, err)
+	event, ok := api.Event(fakecalendar.PrimaryCalendarID, eventID)
+	require.True(t, ok, "event %s should exist", eventID)
+	return event
+}
 
-	var cloned gcalendar.Event
-	require.NoError(t, json.Unmarshal(raw, &cloned))
-	return &cloned
+// hasEvent reports whether the primary calendar of the fake API holds the event
+func hasEvent(api *fakecalendar.Server, eventID string) bool {
+	_, ok := api.Event(fakecalendar.PrimaryCalendarID, eventID)
+	return ok
 }
 
-func newSyncTestService(t *testing.T, initialEvents ...*gcalendar.Event) (*Service, *fakeCalendarAPI, *scheduler.Scheduler, *fairness.Tracker, func()) {
+func newSyncTestService(t *testing.T, initialEvents ...*gcalendar.Event) (*Service, *fakecalendar.Server, *scheduler.Scheduler, *fairness.Tracker, func()) {
 	t.Helper()
 
 	db, dbCleanup := setupCalendarTestDB(t)
@@ -386,17 +334,16 @@ func newSyncTestService(t *testing.T, initialEvents ...*gcalendar.Event) (*Servi
 	require.NoError(t, tokenStore.SaveSelectedCalendar("primary"))
 
 	tokenManager := token.NewTokenManager(tokenStore, &oauth2.Config{})
-	fakeAPI := newFakeCalendarAPI(t, initialEvents...)
-	server := httptest.NewServer(http.HandlerFunc(fakeAPI.handle))
+	fakeAPI := fakecalendar.New()
+	for _, event := range initialEvents {
+		fakeAPI.AddEvent(fakecalendar.PrimaryCalendarID, event)
+	}
+	server := httptest.NewServer(fakeAPI)
 
-	apiService, err := gcalendar.NewService(
-		context.Background(),
-		option.WithHTTPClient(server.Client()),
-		option.WithEndpoint(server.URL+"/"),
-	)
+	apiService, err := NewAPIService(context.Background(), server.Client(), server.URL)
 	require.NoError(t, err)
 
-	service := New(&oauth2.Config{}, "https://app.example", "https://public.example", tokenStore, testScheduler, tokenManager)
+	service := New(&oauth2.Config{}, testBranding, AvailabilityCalendars{}, "https://public.example", tokenStore, testScheduler, tokenManager, nil, nil, Review{})
 	service.srv = apiService
 	service.calendarID = "primary"
 	service.initialized = true
@@ -420,23 +367,23 @@ func TestSyncScheduleRelinksExistingManagedEventBySourceURL(t *testing.T) {
 	service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t, existingEvent)
 	defer cleanup()
 
-	assignment, err := tracker.RecordAssignment("Alice", date, false, fairness.DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, "missing-event"))
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "missing-event"))
 
-	assignments, err := testScheduler.GetAssignmentsInRange(date, date)
+	assignments, err := testScheduler.GetAssignmentsInRange(t.Context(), date, date)
 	require.NoError(t, err)
 	require.Len(t, assignments, 1)
 
 	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
 
-	updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+	updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "existing-event", updatedAssignment.GoogleCalendarEventID)
-	assert.Equal(t, 1, fakeAPI.eventCount())
+	assert.Equal(t, 1, fakeAPI.EventCount(fakecalendar.PrimaryCalendarID))
 
-	storedEvent := fakeAPI.event(t, "existing-event")
-	assert.Equal(t, formatEventSummary(assignments[0]), storedEvent.Summary)
+	storedEvent := storedEvent(t, fakeAPI, "existing-event")
+	assert.Equal(t, formatEventSummary(assignments[0], testBranding), storedEvent.Summary)
 	assert.Equal(t, "https://app.example", storedEvent.Source.Url)
 	assert.Equal(t, fmt.Sprintf("%d", assignment.ID), storedEvent.ExtendedProperties.Private["assignmentId"])
 	assert.Equal(t, constants.NightRoutineIdentifier, storedEvent.ExtendedProperties.Private["app"])
@@ -448,38 +395,106 @@ func TestSyncScheduleRecreatesMissingManagedEvent(t *testing.T) {
 	service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t)
 	defer cleanup()
 
-	assignment, err := tracker.RecordAssignment("Bob", date, false, fairness.DecisionReasonAlternating)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Bob", date, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
-	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, "missing-event"))
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "missing-event"))
 
-	assignments, err := testScheduler.GetAssignmentsInRange(date, date)
+	assignments, err := testScheduler.GetAssignmentsInRange(t.Context(), date, date)
 	require.NoError(t, err)
 	require.Len(t, assignments, 1)
 
 	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
 
-	updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+	updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, updatedAssignment.GoogleCalendarEventID)
 	assert.NotEqual(t, "missing-event", updatedAssignment.GoogleCalendarEventID)
-	assert.Equal(t, 1, fakeAPI.eventCount())
+	assert.Equal(t, 1, fakeAPI.EventCount(fakecalendar.PrimaryCalendarID))
 
-	storedEvent := fakeAPI.event(t, updatedAssignment.GoogleCalendarEventID)
-	assert.Equal(t, formatEventSummary(assignments[0]), storedEvent.Summary)
+	storedEvent := storedEvent(t, fakeAPI, updatedAssignment.GoogleCalendarEventID)
+	assert.Equal(t, formatEventSummary(assignments[0], testBranding), storedEvent.Summary)
 	assert.Equal(t, "https://app.example", storedEvent.Source.Url)
 	assert.Equal(t, fmt.Sprintf("%d", assignment.ID), storedEvent.ExtendedProperties.Private["assignmentId"])
 	assert.Equal(t, constants.NightRoutineIdentifier, storedEvent.ExtendedProperties.Private["app"])
 }
 
+// staticChecklists returns the same checklist for every assignment
+type staticChecklists []*database.ChecklistItem
+
+func (c staticChecklists) GetChecklist(int64) ([]*database.ChecklistItem, error) {
+	return c, nil
+}
+
+// staticComments returns the same comments for every assignment
+type staticComments struct {
+	enabled  bool
+	comments []*database.Comment
+}
+
+func (c staticComments) IsCommentsInEventsEnabled() (bool, error) {
+	return c.enabled, nil
+}
+
+func (c staticComments) ListComments(int64) ([]*database.Comment, error) {
+	return c.comments, nil
+}
+
+func TestSyncScheduleWritesCommentsInDescriptionWhenEnabled(t *testing.T) {
+	date := time.Date(2026, 5, 28, 0, 0, 0, 0, time.UTC)
+	comments := []*database.Comment{{Author: "Bob", Text: "Teething", CreatedAt: time.Date(2026, 5, 28, 21, 0, 0, 0, time.UTC)}}
+
+	for _, enabled := range []bool{false, true} {
+		service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t)
+		service.comments = staticComments{enabled: enabled, comments: comments}
+
+		_, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
+		require.NoError(t, err)
+		assignments, err := testScheduler.GetAssignmentsInRange(t.Context(), date, date)
+		require.NoError(t, err)
+
+		require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+
+		updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignments[0].ID)
+		require.NoError(t, err)
+		storedEvent := storedEvent(t, fakeAPI, updatedAssignment.GoogleCalendarEventID)
+		if enabled {
+			assert.True(t, strings.HasSuffix(storedEvent.Description, "Comments:\nBob (2026-05-28 21:00): Teething"), storedEvent.Description)
+		} else {
+			assert.NotContains(t, storedEvent.Description, "Comments:")
+		}
+		cleanup()
+	}
+}
+
+func TestSyncScheduleWritesChecklistInDescription(t *testing.T) {
+	date := time.Date(2026, 5, 28, 0, 0, 0, 0, time.UTC)
+
+	service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t)
+	defer cleanup()
+	service.checklists = staticChecklists{{Label: "Bath"}, {Label: "Story"}}
+
+	_, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	assignments, err := testScheduler.GetAssignmentsInRange(t.Context(), date, date)
+	require.NoError(t, err)
+
+	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+
+	updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignments[0].ID)
+	require.NoError(t, err)
+	storedEvent := storedEvent(t, fakeAPI, updatedAssignment.GoogleCalendarEventID)
+	assert.True(t, strings.HasSuffix(storedEvent.Description, "Checklist:\n☐ Bath\n☐ Story"), storedEvent.Description)
+}
+
 func TestSyncScheduleRelinksManagedEventAndDeletesDuplicates(t *testing.T) {
 	date := time.Date(2026, 5, 27, 0, 0, 0, 0, time.UTC)
 
 	service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t)
 	defer cleanup()
 
-	assignment, err := tracker.RecordAssignment("Alice", date, false, fairness.DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, "missing-event"))
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "missing-event"))
 
 	assignmentEvent := &gcalendar.Event{
 		Id:      "assignment-event",
@@ -501,24 +516,221 @@ func TestSyncScheduleRelinksManagedEventAndDeletesDuplicates(t *testing.T) {
 		End:     &gcalendar.EventDateTime{Date: date.AddDate(0, 0, 1).Format("2006-01-02")},
 		Source:  &gcalendar.EventSource{Title: constants.NightRoutineIdentifier, Url: "https://app.example"},
 	}
-	fakeAPI.addEvent(t, assignmentEvent)
-	fakeAPI.addEvent(t, duplicateEvent)
+	fakeAPI.AddEvent(fakecalendar.PrimaryCalendarID, assignmentEvent)
+	fakeAPI.AddEvent(fakecalendar.PrimaryCalendarID, duplicateEvent)
 
-	assignments, err := testScheduler.GetAssignmentsInRange(date, date)
+	assignments, err := testScheduler.GetAssignmentsInRange(t.Context(), date, date)
 	require.NoError(t, err)
 	require.Len(t, assignments, 1)
 
 	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
 
-	updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+	updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "assignment-event", updatedAssignment.GoogleCalendarEventID)
-	assert.Equal(t, 1, fakeAPI.eventCount())
-	assert.False(t, fakeAPI.eventExists("duplicate-date-event"))
+	assert.Equal(t, 1, fakeAPI.EventCount(fakecalendar.PrimaryCalendarID))
+	assert.False(t, hasEvent(fakeAPI, "duplicate-date-event"))
 
-	storedEvent := fakeAPI.event(t, "assignment-event")
-	assert.Equal(t, formatEventSummary(assignments[0]), storedEvent.Summary)
+	storedEvent := storedEvent(t, fakeAPI, "assignment-event")
+	assert.Equal(t, formatEventSummary(assignments[0], testBranding), storedEvent.Summary)
 	assert.Equal(t, "https://app.example", storedEvent.Source.Url)
 	assert.Equal(t, fmt.Sprintf("%d", assignment.ID), storedEvent.ExtendedProperties.Private["assignmentId"])
 	assert.Equal(t, constants.NightRoutineIdentifier, storedEvent.ExtendedProperties.Private["app"])
 }
+
+func TestSyncScheduleRemovesVacationEvents(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	managedEvent := func(id string, date time.Time) *gcalendar.Event {
+		return &gcalendar.Event{
+			Id:     id,
+			Start:  &gcalendar.EventDateTime{Date: date.Format("2006-01-02")},
+			End:    &gcalendar.EventDateTime{Date: date.AddDate(0, 0, 1).Format("2006-01-02")},
+			Source: &gcalendar.EventSource{Title: constants.NightRoutineIdentifier, Url: "https://app.example"},
+		}
+	}
+	personalEvent := &gcalendar.Event{
+		Id:    "personal",
+		Start: &gcalendar.EventDateTime{Date: today.AddDate(0, 0, 2).Format("2006-01-02")},
+		End:   &gcalendar.EventDateTime{Date: today.AddDate(0, 0, 3).Format("2006-01-02")},
+	}
+
+	service, fakeAPI, _, tracker, cleanup := newSyncTestService(t,
+		managedEvent("yesterday", today.AddDate(0, 0, -1)),
+		managedEvent("during", today.AddDate(0, 0, 2)),
+		managedEvent("after", today.AddDate(0, 0, 6)),
+		personalEvent,
+	)
+	defer cleanup()
+	service.scheduler = scheduler.New(&calendarTestConfigStore{
+		parentA:  "Alice",
+		parentB:  "Bob",
+		vacation: config.Vacation{Enabled: true, Start: today.AddDate(0, 0, -1), End: today.AddDate(0, 0, 5)},
+	}, tracker)
+
+	require.NoError(t, service.SyncSchedule(context.Background(), nil))
+
+	assert.False(t, hasEvent(fakeAPI, "during"), "event of the vacation removed")
+	assert.True(t, hasEvent(fakeAPI, "yesterday"), "nights already done keep their event")
+	assert.True(t, hasEvent(fakeAPI, "after"), "event after the vacation kept")
+	assert.True(t, hasEvent(fakeAPI, "personal"), "events not managed by the app are never touched")
+}
+
+func TestSyncScheduleRemovesSkipDateEvents(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	skipped := today.AddDate(0, 0, 2)
+	skipDate, err := config.ParseSkipDate(skipped.Format("2006-01-02"))
+	require.NoError(t, err)
+
+	service, fakeAPI, _, tracker, cleanup := newSyncTestService(t,
+		&gcalendar.Event{
+			Id:     "skipped",
+			Start:  &gcalendar.EventDateTime{Date: skipped.Format("2006-01-02")},
+			End:    &gcalendar.EventDateTime{Date: skipped.AddDate(0, 0, 1).Format("2006-01-02")},
+			Source: &gcalendar.EventSource{Title: constants.NightRoutineIdentifier, Url: "https://app.example"},
+		},
+		&gcalendar.Event{
+			Id:    "personal",
+			Start: &gcalendar.EventDateTime{Date: skipped.Format("2006-01-02")},
+			End:   &gcalendar.EventDateTime{Date: skipped.AddDate(0, 0, 1).Format("2006-01-02")},
+		},
+	)
+	defer cleanup()
+	service.scheduler = scheduler.New(&calendarTestConfigStore{
+		parentA:   "Alice",
+		parentB:   "Bob",
+		skipDates: config.SkipDates{skipDate},
+	}, tracker)
+
+	assignments, err := service.scheduler.GenerateSchedule(t.Context(), today, today.AddDate(0, 0, 3), now)
+	require.NoError(t, err)
+	require.Len(t, assignments, 3, "no assignment on the skip date")
+
+	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+
+	assert.False(t, hasEvent(fakeAPI, "skipped"), "event of the skip date removed")
+	assert.True(t, hasEvent(fakeAPI, "personal"), "events not managed by the app are never touched")
+}
+
+func TestSyncScheduleRemovesExcludedNightEvents(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	parentNight, babysitterNight, sickNight := today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), today.AddDate(0, 0, 3)
+
+	service, fakeAPI, _, tracker, cleanup := newSyncTestService(t)
+	defer cleanup()
+	configStore := &calendarTestConfigStore{parentA: "Alice", parentB: "Bob"}
+	service.scheduler = scheduler.New(configStore, tracker)
+
+	_, err := tracker.RecordAssignment(t.Context(), "Alice", parentNight, false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Carol", babysitterNight, true)
+	require.NoError(t, err)
+	sick, err := tracker.RecordAssignment(t.Context(), "Bob", sickNight, true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), sick.ID, fairness.AssignmentTagSickKid))
+
+	sync := func() []*scheduler.Assignment {
+		t.Helper()
+		assignments, err := service.scheduler.GetAssignmentsInRange(t.Context(), parentNight, sickNight)
+		require.NoError(t, err)
+		require.Len(t, assignments, 3)
+		require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+		assignments, err = service.scheduler.GetAssignmentsInRange(t.Context(), parentNight, sickNight)
+		require.NoError(t, err)
+		return assignments
+	}
+	assignments := sync()
+	require.Equal(t, 3, fakeAPI.EventCount(fakecalendar.PrimaryCalendarID))
+	babysitterEventID, sickEventID := assignments[1].GoogleCalendarEventID, assignments[2].GoogleCalendarEventID
+
+	configStore.exclusions = config.SyncExclusions{Babysitter: true, Tags: []string{fairness.AssignmentTagSickKid.String()}}
+	assignments = sync()
+
+	assert.Equal(t, 1, fakeAPI.EventCount(fakecalendar.PrimaryCalendarID), "only the parent night keeps its event")
+	assert.NotEmpty(t, assignments[0].GoogleCalendarEventID)
+	assert.False(t, hasEvent(fakeAPI, babysitterEventID), "event of the babysitter night removed")
+	assert.False(t, hasEvent(fakeAPI, sickEventID), "event of the tagged night removed")
+	assert.Empty(t, assignments[1].GoogleCalendarEventID, "excluded nights stay recorded without event")
+	assert.Empty(t, assignments[2].GoogleCalendarEventID)
+
+	configStore.exclusions = config.SyncExclusions{}
+	sync()
+	assert.Equal(t, 3, fakeAPI.EventCount(fakecalendar.PrimaryCalendarID), "the nights get their event back once no longer excluded")
+}
+
+func TestSyncScheduleKeepsChildEventsApart(t *testing.T) {
+	date := time.Date(2026, 5, 25, 0, 0, 0, 0, time.UTC)
+	service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t)
+	defer cleanup()
+
+	_, err := tracker.AddChild(t.Context(), "Emma")
+	require.NoError(t, err)
+	assignments, err := testScheduler.GenerateSchedule(t.Context(), date, date, date)
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+
+	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+	assert.Equal(t, 2, fakeAPI.EventCount(fakecalendar.PrimaryCalendarID), "the nights of both rotations keep their own event")
+
+	childNight, err := tracker.GetAssignmentByID(t.Context(), assignments[1].ID)
+	require.NoError(t, err)
+	event := storedEvent(t, fakeAPI, childNight.GoogleCalendarEventID)
+	assert.Equal(t, "[Alice] \U0001f303\U0001f476Emma Routine", event.Summary)
+	assert.Equal(t, "Emma", event.ExtendedProperties.Private["child"])
+
+	require.NoError(t, service.RemoveEvents(context.Background(), []string{childNight.GoogleCalendarEventID, "already-gone"}))
+	assert.False(t, hasEvent(fakeAPI, childNight.GoogleCalendarEventID))
+	assert.Equal(t, 1, fakeAPI.EventCount(fakecalendar.PrimaryCalendarID))
+}
+
+func TestSyncScheduleReportsProgress(t *testing.T) {
+	date := time.Date(2026, 5, 25, 0, 0, 0, 0, time.UTC)
+	service, _, testScheduler, _, cleanup := newSyncTestService(t)
+	defer cleanup()
+
+	assignments, err := testScheduler.GenerateSchedule(t.Context(), date, date.AddDate(0, 0, 2), date)
+	require.NoError(t, err)
+	require.Len(t, assignments, 3)
+
+	ctx, progress := signals.WithSyncProgress(context.Background(), 1, "manual")
+	require.NoError(t, service.SyncSchedule(ctx, append(assignments, assignments[0])))
+
+	summary := progress.Snapshot()
+	assert.Equal(t, 3, summary.Total, "an assignment listed twice is synced once")
+	assert.Equal(t, 3, summary.Processed)
+	assert.Zero(t, summary.Failed)
+	assert.Empty(t, summary.Errors)
+}
+
+func TestRemoveAllEvents(t *testing.T) {
+	service, fakeAPI, _, _, cleanup := newSyncTestService(t,
+		&gcalendar.Event{
+			Id:     "last-year",
+			Start:  &gcalendar.EventDateTime{Date: "2025-10-16"},
+			End:    &gcalendar.EventDateTime{Date: "2025-10-17"},
+			Source: &gcalendar.EventSource{Title: constants.NightRoutineIdentifier, Url: "https://app.example"},
+		},
+		&gcalendar.Event{
+			Id:                 "tomorrow",
+			Start:              &gcalendar.EventDateTime{Date: "2026-10-17"},
+			End:                &gcalendar.EventDateTime{Date: "2026-10-18"},
+			ExtendedProperties: &gcalendar.EventExtendedProperties{Private: map[string]string{"app": constants.NightRoutineIdentifier}},
+		},
+		&gcalendar.Event{
+			Id:    "personal",
+			Start: &gcalendar.EventDateTime{Date: "2026-10-17"},
+			End:   &gcalendar.EventDateTime{Date: "2026-10-18"},
+		},
+	)
+	defer cleanup()
+
+	removed, err := service.RemoveAllEvents(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.False(t, hasEvent(fakeAPI, "last-year"), "past events are removed too")
+	assert.False(t, hasEvent(fakeAPI, "tomorrow"))
+	assert.True(t, hasEvent(fakeAPI, "personal"), "events not managed by the app are never touched")
+}
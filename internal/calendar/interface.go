@@ -3,6 +3,8 @@ package calendar
 import (
 	"context"
 
+	"google.golang.org/api/calendar/v3"
+
 	"github.com/belphemur/night-routine/internal/fairness/scheduler"
 )
 
@@ -14,9 +16,21 @@ type CalendarService interface {
 	// IsInitialized returns whether the service has been initialized with a valid token
 	IsInitialized() bool
 
+	// Reset returns the service to its uninitialized state
+	Reset()
+
 	// SyncSchedule synchronizes the schedule with Google Calendar
 	SyncSchedule(ctx context.Context, assignments []*scheduler.Assignment) error
 
+	// RemoveEvents deletes managed events by ID, the ones already gone being skipped
+	RemoveEvents(ctx context.Context, eventIDs []string) error
+
+	// RemoveAllEvents deletes every managed event of the selected calendar and returns their number
+	RemoveAllEvents(ctx context.Context) (int, error)
+
+	// IsOwnUpdate reports whether the last update of a managed event was written by a recent sync
+	IsOwnUpdate(event *calendar.Event) bool
+
 	// SetupNotificationChannel sets up a notification channel for calendar changes
 	SetupNotificationChannel(ctx context.Context) error
 
@@ -28,6 +42,12 @@ type CalendarService interface {
 
 	// VerifyNotificationChannel checks if a notification channel is still active with Google Calendar
 	VerifyNotificationChannel(ctx context.Context, channelID, resourceID string) (bool, error)
+
+	// RenewNotificationChannel replaces a notification channel with a new one, created before the old one is stopped
+	RenewNotificationChannel(ctx context.Context, channelID string) error
+
+	// RecreateNotificationChannel stops a notification channel and creates a new one
+	RecreateNotificationChannel(ctx context.Context, channelID string) error
 }
 
 // Ensure Service implements CalendarService
@@ -5,22 +5,23 @@ import (
 	"fmt"
 
 	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/googleclient"
 	"github.com/belphemur/night-routine/internal/signals"
 	"github.com/belphemur/night-routine/internal/token"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/calendar/v3"
-	"google.golang.org/api/option"
 )
 
 // Manager handles calendar-related operations such as listing and selection
 type Manager struct {
-	tokenStore   *database.TokenStore
+	tokenStore   database.TokenStoreInterface
 	tokenManager *token.TokenManager
 	config       *oauth2.Config
+	endpoint     string // Calendar API endpoint, Google when empty
 }
 
 // NewManager creates a new calendar manager
-func NewManager(tokenStore *database.TokenStore, tokenManager *token.TokenManager, oauthConfig *oauth2.Config) *Manager {
+func NewManager(tokenStore database.TokenStoreInterface, tokenManager *token.TokenManager, oauthConfig *oauth2.Config) *Manager {
 	return &Manager{
 		tokenStore:   tokenStore,
 		tokenManager: tokenManager,
@@ -28,6 +29,11 @@ func NewManager(tokenStore *database.TokenStore, tokenManager *token.TokenManage
 	}
 }
 
+// SetEndpoint makes the manager call the Calendar API at endpoint instead of Google
+func (m *Manager) SetEndpoint(endpoint string) {
+	m.endpoint = endpoint
+}
+
 // GetCalendarList fetches available calendars for the authenticated user
 func (m *Manager) GetCalendarList(ctx context.Context) (*calendar.CalendarList, error) {
 	// Get valid token
@@ -36,9 +42,9 @@ func (m *Manager) GetCalendarList(ctx context.Context) (*calendar.CalendarList,
 		return nil, fmt.Errorf("failed to get valid token: %w", err)
 	}
 
-	// Create authenticated client
-	client := m.config.Client(ctx, token)
-	srv, err := calendar.NewService(ctx, option.WithHTTPClient(client))
+	// Create authenticated client on the shared connection pool
+	client := googleclient.New(ctx, m.config, token)
+	srv, err := NewAPIService(ctx, client, m.endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create calendar service: %w", err)
 	}
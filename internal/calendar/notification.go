@@ -2,6 +2,7 @@ package calendar
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,36 +10,19 @@ import (
 	"google.golang.org/api/calendar/v3"
 
 	"github.com/belphemur/night-routine/internal/database"
+	"github.com/rs/zerolog"
 )
 
+// ErrNotificationChannelNotFound is returned when no notification channel has the ID
+var ErrNotificationChannelNotFound = errors.New("notification channel not found")
+
 // SetupNotificationChannel sets up a notification channel for calendar changes
 func (s *Service) SetupNotificationChannel(ctx context.Context) error {
 	s.logger.Info().Msg("Setting up notification channel...")
-	// Get latest token in case it was refreshed
-	token, err := s.tokenManager.GetValidToken(ctx)
-	if err != nil {
-		s.logger.Error().Err(err).Msg("Failed to get valid token for notification setup")
-		return fmt.Errorf("failed to get token: %w", err)
-	}
-	if token == nil {
-		s.logger.Error().Msg("No valid token available for notification setup")
-		return fmt.Errorf("no valid token available")
-	}
-
-	// Get latest calendar ID in case it was changed
-	calendarID, err := s.tokenStore.GetSelectedCalendar()
+	logger, err := s.prepareNotificationChannel(ctx)
 	if err != nil {
-		s.logger.Error().Err(err).Msg("Failed to get selected calendar ID for notification setup")
-		return fmt.Errorf("failed to get calendar ID: %w", err)
-	}
-	if calendarID != "" && calendarID != s.calendarID {
-		s.logger.Info().Str("old_calendar_id", s.calendarID).Str("new_calendar_id", calendarID).Msg("Calendar ID changed, updating service for notification setup")
-		s.calendarID = calendarID
-	} else if calendarID == "" {
-		s.logger.Warn().Msg("No calendar ID selected, cannot set up notification channel")
-		return fmt.Errorf("no calendar ID selected")
+		return err
 	}
-	logger := s.logger.With().Str("calendar_id", s.calendarID).Logger() // Logger with calendar ID context
 
 	// Delete any expired notification channels
 	logger.Debug().Msg("Deleting expired notification channels")
@@ -93,7 +77,100 @@ func (s *Service) SetupNotificationChannel(ctx context.Context) error {
 		}
 	}
 	logger.Info().Msg("No active notification channel found for this calendar, creating a new one")
+	return s.createNotificationChannel(ctx, logger)
+}
+
+// RenewNotificationChannel replaces the channel with a new one watching the selected calendar. The
+// new channel is created before the old one is stopped, so that no change goes unnoticed.
+// Returns ErrNotificationChannelNotFound when no channel has the ID.
+func (s *Service) RenewNotificationChannel(ctx context.Context, channelID string) error {
+	channel, err := s.notificationChannel(channelID)
+	if err != nil {
+		return err
+	}
+	logger, err := s.prepareNotificationChannel(ctx)
+	if err != nil {
+		return err
+	}
+	logger = logger.With().Str("old_channel_id", channel.ID).Logger()
+	logger.Info().Msg("Renewing notification channel")
+
+	if err := s.createNotificationChannel(ctx, logger); err != nil {
+		return err
+	}
+	if err := s.StopNotificationChannel(ctx, channel.ID, channel.ResourceID); err != nil {
+		return fmt.Errorf("renewed notification channel, but failed to stop the old one: %w", err)
+	}
+	return nil
+}
+
+// RecreateNotificationChannel stops the channel and creates a new one watching the selected calendar,
+// for a channel Google no longer delivers on. The channel is removed even when Google fails to stop it.
+// Returns ErrNotificationChannelNotFound when no channel has the ID.
+func (s *Service) RecreateNotificationChannel(ctx context.Context, channelID string) error {
+	channel, err := s.notificationChannel(channelID)
+	if err != nil {
+		return err
+	}
+	logger, err := s.prepareNotificationChannel(ctx)
+	if err != nil {
+		return err
+	}
+	logger = logger.With().Str("old_channel_id", channel.ID).Logger()
+	logger.Info().Msg("Recreating notification channel")
+
+	if err := s.StopNotificationChannel(ctx, channel.ID, channel.ResourceID); err != nil {
+		// The channel is deleted from the database anyway, Google stops delivering on it once expired
+		logger.Warn().Err(err).Msg("Failed to stop the notification channel before recreating it")
+	}
+	return s.createNotificationChannel(ctx, logger)
+}
 
+// notificationChannel returns the stored notification channel with the ID, ErrNotificationChannelNotFound
+// when there is none
+func (s *Service) notificationChannel(channelID string) (*database.NotificationChannel, error) {
+	channel, err := s.tokenStore.GetNotificationChannelByID(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification channel: %w", err)
+	}
+	if channel == nil {
+		return nil, ErrNotificationChannelNotFound
+	}
+	return channel, nil
+}
+
+// prepareNotificationChannel checks that a valid token is available and picks up the calendar selected
+// meanwhile, returning a logger with the calendar ID
+func (s *Service) prepareNotificationChannel(ctx context.Context) (zerolog.Logger, error) {
+	// Get latest token in case it was refreshed
+	token, err := s.tokenManager.GetValidToken(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get valid token for notification setup")
+		return s.logger, fmt.Errorf("failed to get token: %w", err)
+	}
+	if token == nil {
+		s.logger.Error().Msg("No valid token available for notification setup")
+		return s.logger, fmt.Errorf("no valid token available")
+	}
+
+	// Get latest calendar ID in case it was changed
+	calendarID, err := s.tokenStore.GetSelectedCalendar()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get selected calendar ID for notification setup")
+		return s.logger, fmt.Errorf("failed to get calendar ID: %w", err)
+	}
+	if calendarID != "" && calendarID != s.calendarID {
+		s.logger.Info().Str("old_calendar_id", s.calendarID).Str("new_calendar_id", calendarID).Msg("Calendar ID changed, updating service for notification setup")
+		s.calendarID = calendarID
+	} else if calendarID == "" {
+		s.logger.Warn().Msg("No calendar ID selected, cannot set up notification channel")
+		return s.logger, fmt.Errorf("no calendar ID selected")
+	}
+	return s.logger.With().Str("calendar_id", s.calendarID).Logger(), nil // Logger with calendar ID context
+}
+
+// createNotificationChannel asks Google to watch the selected calendar on a new channel and stores it
+func (s *Service) createNotificationChannel(ctx context.Context, logger zerolog.Logger) error {
 	// Create a new notification channel
 	// The channel ID should be unique
 	channelID := fmt.Sprintf("night-routine-%d", time.Now().UnixNano())
@@ -294,24 +371,47 @@ func (s *Service) VerifyNotificationChannel(ctx context.Context, channelID, reso
 
 	// Execute the request
 	_, err = listCall.Do()
+	active, verifyErr := verificationOutcome(err)
+
+	status := database.ChannelVerificationActive
+	switch {
+	case verifyErr != nil:
+		status = database.ChannelVerificationError
+	case !active:
+		status = database.ChannelVerificationInactive
+	}
+	if recordErr := s.tokenStore.RecordNotificationChannelVerification(channelID, status, time.Now()); recordErr != nil {
+		logger.Warn().Err(recordErr).Msg("Failed to record the verification of the channel")
+	}
+	if verifyErr != nil {
+		logger.Warn().Err(verifyErr).Msg("Error when verifying channel")
+		return false, verifyErr
+	}
+	if !active {
+		logger.Info().Msg("Channel verification failed - channel not active with Google Calendar")
+		return false, nil
+	}
 
+	// If we reach here with no error, the channel is likely active
+	logger.Info().Msg("Channel verification passed - channel appears to be active with Google Calendar")
+	return true, nil
+}
+
+// verificationOutcome tells from the error of the verification request whether the channel is active,
+// or an error when its state cannot be determined
+func verificationOutcome(err error) (bool, error) {
 	// If we get a 404 Not Found error with a specific message about the channel,
 	// this indicates the channel is no longer active
 	if err != nil {
-		logger.Warn().Err(err).Msg("Error when verifying channel")
 		// Check error message for indications that the channel doesn't exist
 		errStr := err.Error()
 		if strings.Contains(errStr, "Channel not found") ||
 			strings.Contains(errStr, "Channel ID not found") ||
 			strings.Contains(errStr, "Resource ID not found") {
-			logger.Info().Msg("Channel verification failed - channel not active with Google Calendar")
 			return false, nil
 		}
 		// For other errors, we can't determine the channel state
 		return false, fmt.Errorf("failed to verify channel: %w", err)
 	}
-
-	// If we reach here with no error, the channel is likely active
-	logger.Info().Msg("Channel verification passed - channel appears to be active with Google Calendar")
 	return true, nil
 }
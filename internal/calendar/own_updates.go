@@ -0,0 +1,73 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ownUpdateWindow is how long after a sync starts an update of its events is taken as written by
+// the sync. Google sends the change notification of an update within seconds.
+const ownUpdateWindow = 2 * time.Minute
+
+// syncNonceProperty is the private property marking the events written by a sync with its nonce
+const syncNonceProperty = "syncNonce"
+
+// syncNonces remembers the nonces of the syncs of the last ownUpdateWindow. The zero value is ready
+// to use.
+type syncNonces struct {
+	mu     sync.Mutex
+	issued map[string]time.Time
+}
+
+// issue returns the nonce of a sync starting at now, forgetting the nonces older than ownUpdateWindow
+func (n *syncNonces) issue(now time.Time) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.issued == nil {
+		n.issued = make(map[string]time.Time)
+	}
+	for nonce, issuedAt := range n.issued {
+		if now.Sub(issuedAt) > ownUpdateWindow {
+			delete(n.issued, nonce)
+		}
+	}
+	nonce := rand.Text()
+	n.issued[nonce] = now
+	return nonce
+}
+
+// issuedAt returns when the sync of nonce started, false when the nonce was not issued by this
+// process or was already forgotten
+func (n *syncNonces) issuedAt(nonce string) (time.Time, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	issuedAt, ok := n.issued[nonce]
+	return issuedAt, ok
+}
+
+// IsOwnUpdate reports whether the last update of event was written by a sync of this service: the
+// event carries the nonce of a recent sync, and was not updated later than ownUpdateWindow after
+// that sync started. The webhook ignores these updates, which only reflect the schedule.
+func (s *Service) IsOwnUpdate(event *calendar.Event) bool {
+	if event == nil || event.ExtendedProperties == nil {
+		return false
+	}
+	nonce := event.ExtendedProperties.Private[syncNonceProperty]
+	if nonce == "" {
+		return false
+	}
+	issuedAt, ok := s.nonces.issuedAt(nonce)
+	if !ok {
+		return false
+	}
+	updated, err := time.Parse(time.RFC3339Nano, event.Updated)
+	if err != nil {
+		return false
+	}
+	return updated.Sub(issuedAt) <= ownUpdateWindow
+}
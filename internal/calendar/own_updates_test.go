@@ -0,0 +1,65 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gcalendar "google.golang.org/api/calendar/v3"
+)
+
+func TestSyncNonces(t *testing.T) {
+	var nonces syncNonces
+	start := time.Date(2026, time.October, 16, 20, 0, 0, 0, time.UTC)
+
+	first := nonces.issue(start)
+	second := nonces.issue(start.Add(time.Minute))
+	assert.NotEqual(t, first, second, "every sync gets its own nonce")
+
+	issuedAt, ok := nonces.issuedAt(first)
+	assert.True(t, ok)
+	assert.Equal(t, start, issuedAt)
+
+	nonces.issue(start.Add(ownUpdateWindow + time.Second))
+	_, ok = nonces.issuedAt(first)
+	assert.False(t, ok, "forgotten after the window")
+	_, ok = nonces.issuedAt(second)
+	assert.True(t, ok)
+	_, ok = nonces.issuedAt("unknown")
+	assert.False(t, ok)
+}
+
+func TestIsOwnUpdate(t *testing.T) {
+	date := time.Date(2026, 5, 26, 0, 0, 0, 0, time.UTC)
+
+	service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t)
+	defer cleanup()
+
+	_, err := tracker.RecordAssignment(t.Context(), "Bob", date, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	assignments, err := testScheduler.GetAssignmentsInRange(t.Context(), date, date)
+	require.NoError(t, err)
+
+	syncStart := time.Now()
+	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), date)
+	require.NoError(t, err)
+	event := storedEvent(t, fakeAPI, assignment.GoogleCalendarEventID)
+	require.NotEmpty(t, event.ExtendedProperties.Private[syncNonceProperty], "the events carry the nonce of the sync")
+
+	updatedAt := func(updated time.Time) *gcalendar.Event {
+		copied := *event
+		copied.Updated = updated.UTC().Format(time.RFC3339Nano)
+		return &copied
+	}
+	assert.True(t, service.IsOwnUpdate(updatedAt(syncStart.Add(time.Second))), "written by the sync")
+	assert.False(t, service.IsOwnUpdate(updatedAt(syncStart.Add(ownUpdateWindow+time.Minute))), "edited after the window")
+	assert.False(t, service.IsOwnUpdate(&gcalendar.Event{Updated: syncStart.Format(time.RFC3339)}), "no nonce")
+
+	other := updatedAt(syncStart)
+	other.ExtendedProperties = &gcalendar.EventExtendedProperties{Private: map[string]string{syncNonceProperty: "nonce-of-another-instance"}}
+	assert.False(t, service.IsOwnUpdate(other), "nonce not issued by this service")
+}
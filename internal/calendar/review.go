@@ -0,0 +1,123 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"google.golang.org/api/calendar/v3"
+)
+
+// StagingStore keeps the regenerated assignments whose event waits for review, implemented by
+// database.StagingStore
+type StagingStore interface {
+	Stage(assignmentIDs []int64, at time.Time) error
+	Unstage(assignmentIDs []int64) error
+	ListStaged() ([]*database.StagedAssignment, error)
+}
+
+// Review is the review-before-publish mode. A sync regenerating a night more than AfterDays days
+// out to another caregiver than the one of its event leaves the event as it is and stages the
+// night, until PublishStaged publishes it or the night gets within AfterDays days. Nights set by
+// hand and nights without event are published right away.
+type Review struct {
+	AfterDays int          // Days from today after which the regenerated nights are staged; 0 disables the review
+	Staging   StagingStore // nil disables the review
+}
+
+// enabled reports whether the regenerated nights are staged
+func (r Review) enabled() bool {
+	return r.AfterDays > 0 && r.Staging != nil
+}
+
+// stageForReview stages the assignments waiting for review and returns the ones to sync. events
+// are the managed events of each assignment.
+func (s *Service) stageForReview(assignments []*scheduler.Assignment, events map[int64][]*calendar.Event, now time.Time) ([]*scheduler.Assignment, error) {
+	if !s.review.enabled() {
+		return assignments, nil
+	}
+	cutoff := now.AddDate(0, 0, s.review.AfterDays).Format("2006-01-02")
+
+	toSync := make([]*scheduler.Assignment, 0, len(assignments))
+	var staged []int64
+	for _, a := range assignments {
+		if needsReview(a, events[a.ID], cutoff) {
+			staged = append(staged, a.ID)
+		} else {
+			toSync = append(toSync, a)
+		}
+	}
+	if len(staged) == 0 {
+		return toSync, nil
+	}
+	if err := s.review.Staging.Stage(staged, now); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to stage regenerated assignments")
+		return nil, fmt.Errorf("failed to stage regenerated assignments: %w", err)
+	}
+	s.logger.Info().Int("staged_count", len(staged)).Int("review_after_days", s.review.AfterDays).Msg("Regenerated assignments staged for review")
+	return toSync, nil
+}
+
+// needsReview reports whether the assignment, after cutoff (YYYY-MM-DD), was regenerated to another
+// caregiver than the one of its events
+func needsReview(a *scheduler.Assignment, events []*calendar.Event, cutoff string) bool {
+	if a.Override || a.Date.Format("2006-01-02") <= cutoff {
+		return false
+	}
+	for _, event := range events {
+		if event.ExtendedProperties == nil {
+			continue
+		}
+		private := event.ExtendedProperties.Private
+		if private["parent"] != a.Parent || private["caregiverType"] != a.CaregiverType.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishStaged syncs the events of the assignments staged at or before stagedBefore, and returns
+// their number
+func (s *Service) PublishStaged(ctx context.Context, stagedBefore time.Time) (int, error) {
+	if s.review.Staging == nil {
+		return 0, nil
+	}
+	staged, err := s.review.Staging.ListStaged()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list staged assignments: %w", err)
+	}
+	ids := make(map[int64]bool, len(staged))
+	var first, last time.Time
+	for _, a := range staged {
+		if a.StagedAt.After(stagedBefore) {
+			continue
+		}
+		ids[a.AssignmentID] = true
+		if first.IsZero() {
+			first = a.Date
+		}
+		last = a.Date
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// staged is sorted by date
+	assignments, err := s.scheduler.GetAssignmentsInRange(ctx, first, last)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get staged assignments: %w", err)
+	}
+	toPublish := make([]*scheduler.Assignment, 0, len(ids))
+	for _, a := range assignments {
+		if ids[a.ID] {
+			toPublish = append(toPublish, a)
+		}
+	}
+	s.logger.Info().Int("assignment_count", len(toPublish)).Msg("Publishing staged assignments")
+	if err := s.syncSchedule(ctx, toPublish, false); err != nil {
+		return len(toPublish), err
+	}
+	return len(toPublish), nil
+}
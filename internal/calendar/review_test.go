@@ -0,0 +1,96 @@
+package calendar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStaging keeps the staged assignments in memory
+type memoryStaging map[int64]*database.StagedAssignment
+
+func (m memoryStaging) Stage(ids []int64, at time.Time) error {
+	for _, id := range ids {
+		if m[id] == nil {
+			m[id] = &database.StagedAssignment{AssignmentID: id, StagedAt: at}
+		}
+	}
+	return nil
+}
+
+func (m memoryStaging) Unstage(ids []int64) error {
+	for _, id := range ids {
+		delete(m, id)
+	}
+	return nil
+}
+
+func (m memoryStaging) ListStaged() ([]*database.StagedAssignment, error) {
+	staged := make([]*database.StagedAssignment, 0, len(m))
+	for _, a := range m {
+		staged = append(staged, a)
+	}
+	return staged, nil
+}
+
+func TestSyncScheduleStagesRegeneratedNightsForReview(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	near, far, overridden := today.AddDate(0, 0, 3), today.AddDate(0, 0, 20), today.AddDate(0, 0, 21)
+
+	service, fakeAPI, testScheduler, tracker, cleanup := newSyncTestService(t)
+	defer cleanup()
+	for _, date := range []time.Time{near, far, overridden} {
+		_, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
+		require.NoError(t, err)
+	}
+	assignments, err := testScheduler.GetAssignmentsInRange(t.Context(), near, overridden)
+	require.NoError(t, err)
+	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+
+	staging := memoryStaging{}
+	service.review = Review{AfterDays: 14, Staging: staging}
+
+	// The nights are regenerated to Bob, the last one is set by hand
+	for _, date := range []time.Time{near, far} {
+		_, err := tracker.RecordAssignment(t.Context(), "Bob", date, false, fairness.DecisionReasonAlternating)
+		require.NoError(t, err)
+	}
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", overridden, true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	assignments, err = testScheduler.GetAssignmentsInRange(t.Context(), near, overridden)
+	require.NoError(t, err)
+	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+
+	eventParent := func(date time.Time) string {
+		t.Helper()
+		assignment, err := tracker.GetAssignmentByDate(t.Context(), date)
+		require.NoError(t, err)
+		return storedEvent(t, fakeAPI, assignment.GoogleCalendarEventID).ExtendedProperties.Private["parent"]
+	}
+	assert.Equal(t, "Bob", eventParent(near), "nights within the review window are published")
+	assert.Equal(t, "Alice", eventParent(far), "regenerated nights beyond the review window keep their event")
+	assert.Equal(t, "Bob", eventParent(overridden), "nights set by hand are published")
+	require.Len(t, staging, 1)
+
+	// A staged night stays staged, with the time it was first staged
+	stagedAt := staging[assignments[1].ID].StagedAt
+	require.NoError(t, service.SyncSchedule(context.Background(), assignments))
+	assert.Equal(t, stagedAt, staging[assignments[1].ID].StagedAt)
+
+	staging[assignments[1].ID].Date = far
+	published, err := service.PublishStaged(t.Context(), stagedAt.Add(-time.Second))
+	require.NoError(t, err)
+	assert.Zero(t, published, "only the nights staged before the given time are published")
+
+	published, err = service.PublishStaged(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, "Bob", eventParent(far))
+	assert.Empty(t, staging)
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+	"golang.org/x/oauth2"
+)
+
+// cachedSchedule holds the values returned by GetSchedule
+type cachedSchedule struct {
+	updateFrequency        string
+	lookAheadDays          int
+	pastEventThresholdDays int
+	statsOrder             constants.StatsOrder
+}
+
+// Cache serves the runtime configuration from memory. Values are loaded from the source on
+// first use and kept until Invalidate is called, which must happen on every configuration
+// write (see signals.OnConfigChanged). Errors are never cached.
+type Cache struct {
+	source ConfigStoreInterface
+	logger zerolog.Logger
+
+	mu sync.RWMutex
+	// generation changes on every invalidation, so that a value loaded while the
+	// configuration was being written is not stored over the invalidation
+	generation   uint64
+	parents      *[2]string
+	extraParents *[]ExtraParent
+	availability map[string][]string
+	rules        map[string][]UnavailabilityRule
+	schedule     *cachedSchedule
+	lookAhead    *LookAheadWindows
+	vacation     *Vacation
+	skipDates    *SkipDates
+	exclusions   *SyncExclusions
+	minRestDays  *int
+	dayWeights   *DayWeights
+	styles       *[2]ParentStyle
+}
+
+var _ ConfigStoreInterface = (*Cache)(nil)
+
+// NewCache creates a cache in front of source
+func NewCache(source ConfigStoreInterface) *Cache {
+	return &Cache{
+		source:       source,
+		logger:       logging.GetLogger("config-cache"),
+		availability: make(map[string][]string),
+		rules:        make(map[string][]UnavailabilityRule),
+	}
+}
+
+// Invalidate drops every cached value, the next reads go to the source
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.parents = nil
+	c.extraParents = nil
+	c.availability = make(map[string][]string)
+	c.rules = make(map[string][]UnavailabilityRule)
+	c.schedule = nil
+	c.lookAhead = nil
+	c.vacation = nil
+	c.skipDates = nil
+	c.exclusions = nil
+	c.minRestDays = nil
+	c.dayWeights = nil
+	c.styles = nil
+	c.logger.Debug().Msg("Runtime configuration cache invalidated")
+}
+
+// store runs set under the lock unless the cache was invalidated since generation was read
+func (c *Cache) store(generation uint64, set func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return
+	}
+	set()
+}
+
+// GetParents implements ConfigStoreInterface
+func (c *Cache) GetParents() (parentA, parentB string, err error) {
+	c.mu.RLock()
+	parents, generation := c.parents, c.generation
+	c.mu.RUnlock()
+	if parents != nil {
+		return parents[0], parents[1], nil
+	}
+
+	parentA, parentB, err = c.source.GetParents()
+	if err != nil {
+		return "", "", err
+	}
+	c.store(generation, func() { c.parents = &[2]string{parentA, parentB} })
+	return parentA, parentB, nil
+}
+
+// GetExtraParents implements ConfigStoreInterface. The returned slice is a copy.
+func (c *Cache) GetExtraParents() ([]ExtraParent, error) {
+	c.mu.RLock()
+	extra, generation := c.extraParents, c.generation
+	c.mu.RUnlock()
+	if extra != nil {
+		return slices.Clone(*extra), nil
+	}
+
+	loaded, err := c.source.GetExtraParents()
+	if err != nil {
+		return nil, err
+	}
+	cached := slices.Clone(loaded)
+	c.store(generation, func() { c.extraParents = &cached })
+	return loaded, nil
+}
+
+// GetAvailability implements ConfigStoreInterface. The returned slice is a copy.
+func (c *Cache) GetAvailability(parent string) ([]string, error) {
+	c.mu.RLock()
+	days, ok := c.availability[parent]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok {
+		return slices.Clone(days), nil
+	}
+
+	days, err := c.source.GetAvailability(parent)
+	if err != nil {
+		return nil, err
+	}
+	c.store(generation, func() { c.availability[parent] = slices.Clone(days) })
+	return days, nil
+}
+
+// GetUnavailabilityRules implements ConfigStoreInterface. The returned slice is a copy.
+func (c *Cache) GetUnavailabilityRules(parent string) ([]UnavailabilityRule, error) {
+	c.mu.RLock()
+	rules, ok := c.rules[parent]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok {
+		return slices.Clone(rules), nil
+	}
+
+	rules, err := c.source.GetUnavailabilityRules(parent)
+	if err != nil {
+		return nil, err
+	}
+	c.store(generation, func() { c.rules[parent] = slices.Clone(rules) })
+	return rules, nil
+}
+
+// GetSchedule implements ConfigStoreInterface
+func (c *Cache) GetSchedule() (updateFrequency string, lookAheadDays, pastEventThresholdDays int, statsOrder constants.StatsOrder, err error) {
+	c.mu.RLock()
+	schedule, generation := c.schedule, c.generation
+	c.mu.RUnlock()
+	if schedule != nil {
+		return schedule.updateFrequency, schedule.lookAheadDays, schedule.pastEventThresholdDays, schedule.statsOrder, nil
+	}
+
+	updateFrequency, lookAheadDays, pastEventThresholdDays, statsOrder, err = c.source.GetSchedule()
+	if err != nil {
+		return "", 0, 0, constants.StatsOrderDesc, err
+	}
+	c.store(generation, func() {
+		c.schedule = &cachedSchedule{
+			updateFrequency:        updateFrequency,
+			lookAheadDays:          lookAheadDays,
+			pastEventThresholdDays: pastEventThresholdDays,
+			statsOrder:             statsOrder,
+		}
+	})
+	return updateFrequency, lookAheadDays, pastEventThresholdDays, statsOrder, nil
+}
+
+// GetVacation implements ConfigStoreInterface
+func (c *Cache) GetVacation() (Vacation, error) {
+	c.mu.RLock()
+	vacation, generation := c.vacation, c.generation
+	c.mu.RUnlock()
+	if vacation != nil {
+		return *vacation, nil
+	}
+
+	loaded, err := c.source.GetVacation()
+	if err != nil {
+		return Vacation{}, err
+	}
+	c.store(generation, func() { c.vacation = &loaded })
+	return loaded, nil
+}
+
+// GetSkipDates implements ConfigStoreInterface. The returned slice is a copy.
+func (c *Cache) GetSkipDates() (SkipDates, error) {
+	c.mu.RLock()
+	skipDates, generation := c.skipDates, c.generation
+	c.mu.RUnlock()
+	if skipDates != nil {
+		return slices.Clone(*skipDates), nil
+	}
+
+	loaded, err := c.source.GetSkipDates()
+	if err != nil {
+		return nil, err
+	}
+	cached := slices.Clone(loaded)
+	c.store(generation, func() { c.skipDates = &cached })
+	return loaded, nil
+}
+
+// GetSyncExclusions implements ConfigStoreInterface. The returned tags are a copy.
+func (c *Cache) GetSyncExclusions() (SyncExclusions, error) {
+	c.mu.RLock()
+	exclusions, generation := c.exclusions, c.generation
+	c.mu.RUnlock()
+	if exclusions != nil {
+		return SyncExclusions{Babysitter: exclusions.Babysitter, Tags: slices.Clone(exclusions.Tags)}, nil
+	}
+
+	loaded, err := c.source.GetSyncExclusions()
+	if err != nil {
+		return SyncExclusions{}, err
+	}
+	cached := SyncExclusions{Babysitter: loaded.Babysitter, Tags: slices.Clone(loaded.Tags)}
+	c.store(generation, func() { c.exclusions = &cached })
+	return loaded, nil
+}
+
+// GetLookAheadWindows implements ConfigStoreInterface
+func (c *Cache) GetLookAheadWindows() (LookAheadWindows, error) {
+	c.mu.RLock()
+	lookAhead, generation := c.lookAhead, c.generation
+	c.mu.RUnlock()
+	if lookAhead != nil {
+		return *lookAhead, nil
+	}
+
+	loaded, err := c.source.GetLookAheadWindows()
+	if err != nil {
+		return LookAheadWindows{}, err
+	}
+	c.store(generation, func() { c.lookAhead = &loaded })
+	return loaded, nil
+}
+
+// GetMinRestDays implements ConfigStoreInterface
+func (c *Cache) GetMinRestDays() (int, error) {
+	c.mu.RLock()
+	minRestDays, generation := c.minRestDays, c.generation
+	c.mu.RUnlock()
+	if minRestDays != nil {
+		return *minRestDays, nil
+	}
+
+	loaded, err := c.source.GetMinRestDays()
+	if err != nil {
+		return 0, err
+	}
+	c.store(generation, func() { c.minRestDays = &loaded })
+	return loaded, nil
+}
+
+// GetDayWeights implements ConfigStoreInterface
+func (c *Cache) GetDayWeights() (DayWeights, error) {
+	c.mu.RLock()
+	dayWeights, generation := c.dayWeights, c.generation
+	c.mu.RUnlock()
+	if dayWeights != nil {
+		return *dayWeights, nil
+	}
+
+	loaded, err := c.source.GetDayWeights()
+	if err != nil {
+		return nil, err
+	}
+	c.store(generation, func() { c.dayWeights = &loaded })
+	return loaded, nil
+}
+
+// GetParentStyles implements ConfigStoreInterface
+func (c *Cache) GetParentStyles() (parentA, parentB ParentStyle, err error) {
+	c.mu.RLock()
+	styles, generation := c.styles, c.generation
+	c.mu.RUnlock()
+	if styles != nil {
+		return styles[0], styles[1], nil
+	}
+
+	parentA, parentB, err = c.source.GetParentStyles()
+	if err != nil {
+		return ParentStyle{}, ParentStyle{}, err
+	}
+	c.store(generation, func() { c.styles = &[2]ParentStyle{parentA, parentB} })
+	return parentA, parentB, nil
+}
+
+// GetOAuthConfig implements ConfigStoreInterface. The OAuth configuration is static and
+// always read from the source.
+func (c *Cache) GetOAuthConfig() *oauth2.Config {
+	return c.source.GetOAuthConfig()
+}
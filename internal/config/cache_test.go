@@ -0,0 +1,221 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// countingStore is a ConfigStoreInterface counting the reads reaching it
+type countingStore struct {
+	parentA, parentB string
+	extraParents     []ExtraParent
+	availability     map[string][]string
+	rules            map[string][]UnavailabilityRule
+	updateFrequency  string
+	lookAhead        LookAheadWindows
+	vacation         Vacation
+	skipDates        SkipDates
+	exclusions       SyncExclusions
+	minRestDays      int
+	dayWeights       DayWeights
+	styles           [2]ParentStyle
+	err              error
+	calls            map[string]int
+}
+
+func newCountingStore() *countingStore {
+	return &countingStore{
+		parentA:         "Alice",
+		parentB:         "Bob",
+		extraParents:    []ExtraParent{{Name: "Grandma", Style: DefaultExtraParentStyle(2)}},
+		availability:    map[string][]string{"parent_a": {"Monday"}},
+		rules:           map[string][]UnavailabilityRule{"parent_b": {{Frequency: RuleFrequencyMonthly, Interval: 1, Weekday: time.Monday, Week: 1}}},
+		updateFrequency: "daily",
+		lookAhead:       LookAheadWindows{Scheduled: 60, Webhook: 14},
+		vacation:        Vacation{Enabled: true, Start: time.Date(2026, time.July, 10, 0, 0, 0, 0, time.UTC), End: time.Date(2026, time.July, 20, 0, 0, 0, 0, time.UTC)},
+		skipDates:       SkipDates{{Date: time.Date(2026, time.October, 24, 0, 0, 0, 0, time.UTC)}},
+		exclusions:      SyncExclusions{Babysitter: true, Tags: []string{"sick_kid"}},
+		minRestDays:     2,
+		dayWeights:      DayWeights{time.Friday: 2},
+		styles:          [2]ParentStyle{{Color: constants.ParentColorSage, Avatar: "🦊"}, {Color: constants.ParentColorTomato}},
+		calls:           map[string]int{},
+	}
+}
+
+func (s *countingStore) GetParents() (string, string, error) {
+	s.calls["parents"]++
+	return s.parentA, s.parentB, s.err
+}
+
+func (s *countingStore) GetExtraParents() ([]ExtraParent, error) {
+	s.calls["extra_parents"]++
+	return s.extraParents, s.err
+}
+
+func (s *countingStore) GetAvailability(parent string) ([]string, error) {
+	s.calls["availability"]++
+	return s.availability[parent], s.err
+}
+
+func (s *countingStore) GetUnavailabilityRules(parent string) ([]UnavailabilityRule, error) {
+	s.calls["rules"]++
+	return s.rules[parent], s.err
+}
+
+func (s *countingStore) GetSchedule() (string, int, int, constants.StatsOrder, error) {
+	s.calls["schedule"]++
+	return s.updateFrequency, 30, 5, constants.StatsOrderAsc, s.err
+}
+
+func (s *countingStore) GetLookAheadWindows() (LookAheadWindows, error) {
+	s.calls["look_ahead"]++
+	return s.lookAhead, s.err
+}
+
+func (s *countingStore) GetVacation() (Vacation, error) {
+	s.calls["vacation"]++
+	return s.vacation, s.err
+}
+
+func (s *countingStore) GetSkipDates() (SkipDates, error) {
+	s.calls["skip_dates"]++
+	return s.skipDates, s.err
+}
+
+func (s *countingStore) GetSyncExclusions() (SyncExclusions, error) {
+	s.calls["sync_exclusions"]++
+	return s.exclusions, s.err
+}
+
+func (s *countingStore) GetMinRestDays() (int, error) {
+	s.calls["min_rest_days"]++
+	return s.minRestDays, s.err
+}
+
+func (s *countingStore) GetDayWeights() (DayWeights, error) {
+	s.calls["day_weights"]++
+	return s.dayWeights, s.err
+}
+
+func (s *countingStore) GetParentStyles() (ParentStyle, ParentStyle, error) {
+	s.calls["styles"]++
+	return s.styles[0], s.styles[1], s.err
+}
+
+func (s *countingStore) GetOAuthConfig() *oauth2.Config {
+	return &oauth2.Config{ClientID: "client"}
+}
+
+func TestCache_ServesFromMemory(t *testing.T) {
+	source := newCountingStore()
+	cache := NewCache(source)
+
+	for range 3 {
+		parentA, parentB, err := cache.GetParents()
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", parentA)
+		assert.Equal(t, "Bob", parentB)
+
+		extra, err := cache.GetExtraParents()
+		require.NoError(t, err)
+		assert.Equal(t, source.extraParents, extra)
+
+		days, err := cache.GetAvailability("parent_a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Monday"}, days)
+
+		updateFrequency, lookAheadDays, pastEventThresholdDays, statsOrder, err := cache.GetSchedule()
+		require.NoError(t, err)
+		assert.Equal(t, "daily", updateFrequency)
+		assert.Equal(t, 30, lookAheadDays)
+		assert.Equal(t, 5, pastEventThresholdDays)
+		assert.Equal(t, constants.StatsOrderAsc, statsOrder)
+
+		lookAhead, err := cache.GetLookAheadWindows()
+		require.NoError(t, err)
+		assert.Equal(t, source.lookAhead, lookAhead)
+
+		vacation, err := cache.GetVacation()
+		require.NoError(t, err)
+		assert.Equal(t, source.vacation, vacation)
+
+		rules, err := cache.GetUnavailabilityRules("parent_b")
+		require.NoError(t, err)
+		assert.Equal(t, source.rules["parent_b"], rules)
+
+		skipDates, err := cache.GetSkipDates()
+		require.NoError(t, err)
+		assert.Equal(t, source.skipDates, skipDates)
+
+		exclusions, err := cache.GetSyncExclusions()
+		require.NoError(t, err)
+		assert.Equal(t, source.exclusions, exclusions)
+
+		minRestDays, err := cache.GetMinRestDays()
+		require.NoError(t, err)
+		assert.Equal(t, 2, minRestDays)
+
+		dayWeights, err := cache.GetDayWeights()
+		require.NoError(t, err)
+		assert.Equal(t, source.dayWeights, dayWeights)
+
+		parentAStyle, parentBStyle, err := cache.GetParentStyles()
+		require.NoError(t, err)
+		assert.Equal(t, source.styles, [2]ParentStyle{parentAStyle, parentBStyle})
+	}
+
+	assert.Equal(t, map[string]int{"parents": 1, "extra_parents": 1, "availability": 1, "rules": 1, "schedule": 1, "look_ahead": 1, "vacation": 1, "skip_dates": 1, "sync_exclusions": 1, "min_rest_days": 1, "day_weights": 1, "styles": 1}, source.calls)
+	assert.Equal(t, "client", cache.GetOAuthConfig().ClientID)
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	source := newCountingStore()
+	cache := NewCache(source)
+
+	_, _, err := cache.GetParents()
+	require.NoError(t, err)
+
+	source.parentA = "Carol"
+	parentA, _, err := cache.GetParents()
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", parentA, "stale until invalidated")
+
+	cache.Invalidate()
+	parentA, _, err = cache.GetParents()
+	require.NoError(t, err)
+	assert.Equal(t, "Carol", parentA)
+	assert.Equal(t, 2, source.calls["parents"])
+}
+
+func TestCache_DoesNotCacheErrors(t *testing.T) {
+	source := newCountingStore()
+	source.err = errors.New("database is locked")
+	cache := NewCache(source)
+
+	_, _, _, _, err := cache.GetSchedule()
+	require.Error(t, err)
+
+	source.err = nil
+	updateFrequency, _, _, _, err := cache.GetSchedule()
+	require.NoError(t, err)
+	assert.Equal(t, "daily", updateFrequency)
+	assert.Equal(t, 2, source.calls["schedule"])
+}
+
+func TestCache_AvailabilityIsCopied(t *testing.T) {
+	cache := NewCache(newCountingStore())
+
+	days, err := cache.GetAvailability("parent_a")
+	require.NoError(t, err)
+	days[0] = "Sunday"
+
+	days, err = cache.GetAvailability("parent_a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Monday"}, days)
+}
@@ -3,11 +3,13 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-viper/mapstructure/v2"
 	ktoml "github.com/knadh/koanf/parsers/toml/v2"
@@ -17,6 +19,7 @@ import (
 	koanf "github.com/knadh/koanf/v2"
 
 	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/validation"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
@@ -38,6 +41,11 @@ type Config struct {
 	Schedule     ScheduleConfig     `toml:"schedule"     koanf:"schedule"`
 	Service      ServiceConfig      `toml:"service"      koanf:"service"`
 	App          ApplicationConfig  `toml:"app"          koanf:"app"`
+	Tracing      TracingConfig      `toml:"tracing"      koanf:"tracing"`
+	Notify       NotifyConfig       `toml:"notify"       koanf:"notify"`
+	Hooks        HooksConfig        `toml:"hooks"        koanf:"hooks"`
+	Branding     BrandingConfig     `toml:"branding"     koanf:"branding"`
+	Telemetry    TelemetryConfig    `toml:"telemetry"    koanf:"telemetry"`
 	// Credentials holds the raw OAuth2 client ID and secret loaded from environment variables.
 	Credentials OAuthCredentials `koanf:"oauth"`
 	// OAuth is the fully constructed Google OAuth2 config, built after loading and validation.
@@ -47,21 +55,64 @@ type Config struct {
 
 // ApplicationConfig holds the application server settings.
 type ApplicationConfig struct {
-	Port      int    `toml:"port"       koanf:"port"`       // Port to listen on
-	AppUrl    string `toml:"app_url"    koanf:"app_url"`    // Application URL for internal use (OAuth, etc.)
-	PublicUrl string `toml:"public_url" koanf:"public_url"` // Public URL for external access (webhooks)
+	Port       int    `toml:"port"        koanf:"port"`        // Port to listen on
+	AppUrl     string `toml:"app_url"     koanf:"app_url"`     // Application URL for internal use (OAuth, etc.)
+	PublicUrl  string `toml:"public_url"  koanf:"public_url"`  // Public URL for external access (webhooks)
+	DeviceAuth bool   `toml:"device_auth" koanf:"device_auth"` // Link Google with the device code flow instead of the browser redirect
+	AdminAddr  string `toml:"admin_addr"  koanf:"admin_addr"`  // Address of the pprof/expvar server, e.g. 127.0.0.1:6060; empty disables it
+	// PasskeyLogin asks to sign in with a passkey for the settings, the administration and the changes, once one is registered
+	PasskeyLogin bool `toml:"passkey_login" koanf:"passkey_login"`
+	// CalendarEndpoint is the Google Calendar API endpoint called instead of Google, e.g. a fake API; empty calls Google
+	CalendarEndpoint string `toml:"calendar_endpoint" koanf:"calendar_endpoint"`
+	// DemoMode runs offline on an in-memory fake calendar, connected without Google credentials
+	DemoMode bool `toml:"demo_mode" koanf:"demo_mode"`
+	// WebhookDebounce is how long change notifications are collected before a single processing pass; 0 disables it
+	WebhookDebounce time.Duration `toml:"webhook_debounce" koanf:"webhook_debounce"`
+	// WebhookPollInterval is how often the calendar is polled for changed events when the notifications
+	// do not reach public_url; 0 disables the polling
+	WebhookPollInterval time.Duration `toml:"webhook_poll_interval" koanf:"webhook_poll_interval"`
 }
 
 // ParentsConfig holds the parent names.
 type ParentsConfig struct {
 	ParentA string `toml:"parent_a" koanf:"parent_a"`
 	ParentB string `toml:"parent_b" koanf:"parent_b"`
+	// Names lists the parents taking turns, in place of ParentA and ParentB: the first two are
+	// parent A and parent B, the others are the extra parents, e.g. a grandparent
+	Names []string `toml:"names" koanf:"names"`
+}
+
+// Extra returns the names of the parents taking turns after parent A and parent B
+func (p ParentsConfig) Extra() []string {
+	if len(p.Names) <= 2 {
+		return nil
+	}
+	return p.Names[2:]
+}
+
+// resolveNames sets parent A and parent B from the first names of Names, which may not be used
+// along with them
+func (p *ParentsConfig) resolveNames() error {
+	if len(p.Names) == 0 {
+		return nil
+	}
+	if p.ParentA != "" || p.ParentB != "" {
+		return fmt.Errorf("set either parents.names or parents.parent_a and parents.parent_b")
+	}
+	if err := validation.Roster(p.Names); err != nil {
+		return fmt.Errorf("parents.names: %w", err)
+	}
+	p.ParentA, p.ParentB = p.Names[0], p.Names[1]
+	return nil
 }
 
 // AvailabilityConfig holds the unavailability schedule for each parent.
 type AvailabilityConfig struct {
 	ParentAUnavailable []string `toml:"parent_a_unavailable" koanf:"parent_a_unavailable"`
 	ParentBUnavailable []string `toml:"parent_b_unavailable" koanf:"parent_b_unavailable"`
+	ParentACalendarID  string   `toml:"parent_a_calendar_id" koanf:"parent_a_calendar_id"` // Personal calendar of parent A scanned for the keyword events; empty skips it
+	ParentBCalendarID  string   `toml:"parent_b_calendar_id" koanf:"parent_b_calendar_id"` // Personal calendar of parent B scanned for the keyword events; empty skips it
+	CalendarKeyword    string   `toml:"calendar_keyword"     koanf:"calendar_keyword"`     // Events titled with it make the parent unavailable on their days; empty disables the scan
 }
 
 // ScheduleConfig holds the scheduling parameters.
@@ -69,15 +120,89 @@ type ScheduleConfig struct {
 	UpdateFrequency        string               `toml:"update_frequency"          koanf:"update_frequency"`
 	CalendarID             string               `toml:"calendar_id"               koanf:"calendar_id"`
 	LookAheadDays          int                  `toml:"look_ahead_days"           koanf:"look_ahead_days"`
+	ScheduledLookAheadDays int                  `toml:"scheduled_look_ahead_days" koanf:"scheduled_look_ahead_days"` // Days scheduled ahead by the periodic sync; 0 uses look_ahead_days
+	WebhookLookAheadDays   int                  `toml:"webhook_look_ahead_days"   koanf:"webhook_look_ahead_days"`   // Days recalculated after an event edited in Google Calendar; 0 recalculates up to the last assignment
+	ManualLookAheadDays    int                  `toml:"manual_look_ahead_days"    koanf:"manual_look_ahead_days"`    // Days scheduled ahead by the sync of the web interface; 0 uses look_ahead_days
 	PastEventThresholdDays int                  `toml:"past_event_threshold_days" koanf:"past_event_threshold_days"`
 	StatsOrder             constants.StatsOrder `toml:"stats_order"               koanf:"stats_order"`
+	// ReviewAfterDays stages the regenerated nights more than this many days out until they are
+	// published from the web interface; 0 disables the review
+	ReviewAfterDays int `toml:"review_after_days" koanf:"review_after_days"`
+	// ReviewTimeout publishes the staged nights once they waited this long; 0 waits for the confirmation
+	ReviewTimeout time.Duration `toml:"review_timeout" koanf:"review_timeout"`
+}
+
+// LookAheadWindows returns the look-ahead windows of the sync triggers
+func (s ScheduleConfig) LookAheadWindows() LookAheadWindows {
+	return LookAheadWindows{Scheduled: s.ScheduledLookAheadDays, Webhook: s.WebhookLookAheadDays, Manual: s.ManualLookAheadDays}
 }
 
 // ServiceConfig holds the service configuration.
 type ServiceConfig struct {
-	StateFile           string `toml:"state_file"             koanf:"state_file"`
-	LogLevel            string `toml:"log_level"              koanf:"log_level"`
-	ManualSyncOnStartup bool   `toml:"manual_sync_on_startup" koanf:"manual_sync_on_startup"` // Perform a sync on startup if token exists
+	StateFile           string        `toml:"state_file"             koanf:"state_file"`
+	LogLevel            string        `toml:"log_level"              koanf:"log_level"`
+	ManualSyncOnStartup bool          `toml:"manual_sync_on_startup" koanf:"manual_sync_on_startup"` // Perform a sync on startup if token exists
+	TokenRefreshMargin  time.Duration `toml:"token_refresh_margin"   koanf:"token_refresh_margin"`   // Refresh the OAuth token this long before it expires
+	TokenStorage        string        `toml:"token_storage"          koanf:"token_storage"`          // Where the OAuth token is kept: database or keyring
+	HeartbeatURL        string        `toml:"heartbeat_url"          koanf:"heartbeat_url"`          // Pinged after each successful scheduled sync; empty disables it
+	SlowQueryThreshold  time.Duration `toml:"slow_query_threshold"   koanf:"slow_query_threshold"`   // Log database queries slower than this; 0 disables it
+	StatsCacheTTL       time.Duration `toml:"stats_cache_ttl"        koanf:"stats_cache_ttl"`        // Keep the monthly statistics in memory this long; 0 disables the cache
+	// ConsistencyCheckInterval is how often the look-ahead window is checked for nights without assignment or event; 0 disables it
+	ConsistencyCheckInterval time.Duration `toml:"consistency_check_interval" koanf:"consistency_check_interval"`
+	// QuietHours is the HH:MM-HH:MM window during which the scheduled and webhook syncs are deferred; empty disables it
+	QuietHours string `toml:"quiet_hours" koanf:"quiet_hours"`
+	// MaintenanceWindow is the HH:MM-HH:MM window during which the heavy background jobs run
+	MaintenanceWindow string `toml:"maintenance_window" koanf:"maintenance_window"`
+	// BackupBeforeMigrate snapshots the state file next to it before pending migrations are applied
+	BackupBeforeMigrate bool `toml:"backup_before_migrate" koanf:"backup_before_migrate"`
+	// SkipMigrate leaves the schema to the migrate command; startup fails while migrations are pending
+	SkipMigrate bool `toml:"skip_migrate" koanf:"skip_migrate"`
+}
+
+// TracingConfig holds the OpenTelemetry tracing configuration.
+type TracingConfig struct {
+	Enabled     bool    `toml:"enabled"      koanf:"enabled"`      // Export traces over OTLP/HTTP
+	Endpoint    string  `toml:"endpoint"     koanf:"endpoint"`     // OTLP/HTTP collector URL, e.g. http://localhost:4318
+	ServiceName string  `toml:"service_name" koanf:"service_name"` // service.name resource attribute
+	SampleRatio float64 `toml:"sample_ratio" koanf:"sample_ratio"` // Fraction of traces kept, between 0 and 1
+}
+
+// NotifyConfig holds the outbound notification channels and the failure alerting policy.
+type NotifyConfig struct {
+	SlackWebhookURL  string        `toml:"slack_webhook_url" koanf:"slack_webhook_url"` // Slack incoming webhook URL; empty disables Slack
+	SMTPHost         string        `toml:"smtp_host"         koanf:"smtp_host"`         // SMTP server; empty disables email
+	SMTPPort         int           `toml:"smtp_port"         koanf:"smtp_port"`
+	SMTPUsername     string        `toml:"smtp_username"     koanf:"smtp_username"` // Empty sends without authentication
+	SMTPPassword     string        `toml:"smtp_password"     koanf:"smtp_password"`
+	EmailFrom        string        `toml:"email_from"        koanf:"email_from"`
+	EmailTo          []string      `toml:"email_to"          koanf:"email_to"`
+	FailureThreshold int           `toml:"failure_threshold" koanf:"failure_threshold"` // Consecutive failures before alerting
+	FailureCooldown  time.Duration `toml:"failure_cooldown"  koanf:"failure_cooldown"`  // Minimum time between two alerts for the same failure
+	MonthlyReport    bool          `toml:"monthly_report"    koanf:"monthly_report"`    // Send the report of the past month on the 1st of each month
+	ReminderTime     string        `toml:"reminder_time"     koanf:"reminder_time"`     // HH:MM at which the parent on duty is reminded; empty disables the reminder
+	// ImbalanceThreshold is the difference of nights over the last 30 days from which the family is alerted; 0 disables the alerts
+	ImbalanceThreshold int `toml:"imbalance_threshold" koanf:"imbalance_threshold"`
+}
+
+// HooksConfig holds the user-defined webhooks receiving the schedule changes.
+type HooksConfig struct {
+	URLs   []string `toml:"urls"   koanf:"urls"`   // Receivers of the JSON payloads; empty disables the hooks
+	Secret string   `toml:"secret" koanf:"secret"` // Key of the HMAC-SHA256 signature of each payload
+}
+
+// BrandingConfig holds how the calendar events of this instance are named and marked. Instances or
+// forks sharing a calendar need different identifiers and source URLs to keep their events apart.
+type BrandingConfig struct {
+	EventEmoji      string `toml:"event_emoji"      koanf:"event_emoji"`      // Shown between the caregiver and "Routine" in the event titles; may be empty
+	EventIdentifier string `toml:"event_identifier" koanf:"event_identifier"` // Marks the events of this instance as managed
+	EventSourceURL  string `toml:"event_source_url" koanf:"event_source_url"` // Source link of the events; app_url when empty
+}
+
+// TelemetryConfig holds the opt-in usage telemetry. Nothing leaves the instance unless it is enabled.
+type TelemetryConfig struct {
+	Enabled  bool          `toml:"enabled"  koanf:"enabled"`  // Send the anonymous usage report
+	Endpoint string        `toml:"endpoint" koanf:"endpoint"` // URL receiving the JSON report
+	Interval time.Duration `toml:"interval" koanf:"interval"` // Time between two reports, also the period of the sync error rate
 }
 
 // Load reads the configuration from the given TOML file path, then layers
@@ -95,6 +220,7 @@ type ServiceConfig struct {
 //	NR_APP__PORT=9090
 //	NR_SERVICE__LOG_LEVEL=debug
 //	NR_PARENTS__PARENT_A=Alice
+//	NR_PARENTS__NAMES=Alice,Bob,Grandma
 //	NR_OAUTH__CLIENT_ID=...
 //	NR_AVAILABILITY__PARENT_A_UNAVAILABLE=Monday,Wednesday
 func Load(path string) (*Config, error) {
@@ -103,10 +229,28 @@ func Load(path string) (*Config, error) {
 	// 1. Built-in defaults.
 	defaults := map[string]any{
 		"app.port":                           8888,
+		"app.webhook_debounce":               "5s",
 		"service.log_level":                  "info",
 		"service.manual_sync_on_startup":     true,
-		"schedule.past_event_threshold_days": 5,
+		"service.token_refresh_margin":       "10m",
+		"service.token_storage":              "database",
+		"service.slow_query_threshold":       "500ms",
+		"service.stats_cache_ttl":            "10m",
+		"service.consistency_check_interval": "1h",
+		"service.maintenance_window":         "03:00-05:00",
+		"schedule.update_frequency":          constants.DefaultUpdateFrequency,
+		"schedule.look_ahead_days":           constants.DefaultLookAheadDays,
+		"schedule.past_event_threshold_days": constants.DefaultPastEventThresholdDays,
 		"schedule.stats_order":               string(constants.StatsOrderDesc),
+		"tracing.service_name":               "night-routine",
+		"tracing.sample_ratio":               1.0,
+		"notify.smtp_port":                   587,
+		"notify.failure_threshold":           3,
+		"notify.failure_cooldown":            "6h",
+		"notify.imbalance_threshold":         8,
+		"branding.event_emoji":               constants.DefaultEventEmoji,
+		"branding.event_identifier":          constants.NightRoutineIdentifier,
+		"telemetry.interval":                 "168h",
 	}
 	if err := k.Load(confmap.Provider(defaults, "."), nil); err != nil {
 		return nil, fmt.Errorf("failed to load config defaults: %w", err)
@@ -163,6 +307,7 @@ func Load(path string) (*Config, error) {
 		DecoderConfig: &mapstructure.DecoderConfig{
 			DecodeHook: mapstructure.ComposeDecodeHookFunc(
 				commaSeparatedStringToSliceHook(),
+				mapstructure.StringToTimeDurationHookFunc(),
 			),
 			WeaklyTypedInput: true,
 		},
@@ -181,10 +326,17 @@ func Load(path string) (*Config, error) {
 		cfg.Service.StateFile = filepath.Join(configDir, "..", cfg.Service.StateFile)
 	}
 
+	if err := cfg.Parents.resolveNames(); err != nil {
+		return nil, err
+	}
 	if err := validate(&cfg); err != nil {
 		return nil, err
 	}
 
+	if cfg.Branding.EventSourceURL == "" {
+		cfg.Branding.EventSourceURL = cfg.App.AppUrl
+	}
+
 	cfg.OAuth = &oauth2.Config{
 		ClientID:     cfg.Credentials.ClientID,
 		ClientSecret: cfg.Credentials.ClientSecret,
@@ -225,23 +377,100 @@ func commaSeparatedStringToSliceHook() mapstructure.DecodeHookFuncType {
 
 // validate checks that all required fields are present and valid.
 func validate(cfg *Config) error {
-	if cfg.Parents.ParentA == "" || cfg.Parents.ParentB == "" {
+	// Without parents, the setup wizard of the web interface asks for them on the first run
+	if (cfg.Parents.ParentA == "") != (cfg.Parents.ParentB == "") {
 		return fmt.Errorf("both parent names are required")
 	}
 
-	if cfg.Parents.ParentA == cfg.Parents.ParentB {
-		return fmt.Errorf("parent names must be different")
+	if cfg.Parents.ParentA != "" {
+		if err := validation.Parents(cfg.Parents.ParentA, cfg.Parents.ParentB); err != nil {
+			return err
+		}
+	}
+
+	if err := validation.DaysOfWeek(cfg.Availability.ParentAUnavailable); err != nil {
+		return fmt.Errorf("parent A availability: %w", err)
+	}
+	if err := validation.DaysOfWeek(cfg.Availability.ParentBUnavailable); err != nil {
+		return fmt.Errorf("parent B availability: %w", err)
+	}
+
+	if err := validation.Schedule(cfg.Schedule.UpdateFrequency, cfg.Schedule.LookAheadDays, cfg.Schedule.PastEventThresholdDays, cfg.Schedule.StatsOrder); err != nil {
+		return err
+	}
+	for _, days := range []int{cfg.Schedule.ScheduledLookAheadDays, cfg.Schedule.WebhookLookAheadDays, cfg.Schedule.ManualLookAheadDays} {
+		if err := validation.TriggerLookAheadDays(days); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Schedule.ReviewAfterDays < 0 {
+		return fmt.Errorf("review after days must not be negative, got %d", cfg.Schedule.ReviewAfterDays)
 	}
 
-	switch cfg.Schedule.UpdateFrequency {
-	case "daily", "weekly", "monthly", "disabled":
+	// The staged nights are checked every minute
+	if cfg.Schedule.ReviewTimeout != 0 && cfg.Schedule.ReviewTimeout < time.Minute {
+		return fmt.Errorf("review timeout must be 0 or at least 1m, got %s", cfg.Schedule.ReviewTimeout)
+	}
+
+	// Google access tokens live for one hour; a margin at or above that would refresh continuously.
+	if cfg.Service.TokenRefreshMargin <= 0 || cfg.Service.TokenRefreshMargin >= time.Hour {
+		return fmt.Errorf("token refresh margin must be between 0 and 1h, got %s", cfg.Service.TokenRefreshMargin)
+	}
+
+	switch cfg.Service.TokenStorage {
+	case "database", "keyring":
 		// valid
 	default:
-		return fmt.Errorf("invalid update frequency: %s", cfg.Schedule.UpdateFrequency)
+		return fmt.Errorf("invalid token storage: %s (must be database or keyring)", cfg.Service.TokenStorage)
+	}
+
+	if cfg.Service.SlowQueryThreshold < 0 {
+		return fmt.Errorf("slow query threshold must not be negative, got %s", cfg.Service.SlowQueryThreshold)
 	}
 
-	if cfg.Schedule.LookAheadDays < 1 {
-		return fmt.Errorf("look ahead days must be positive")
+	if cfg.Service.StatsCacheTTL < 0 {
+		return fmt.Errorf("stats cache TTL must not be negative, got %s", cfg.Service.StatsCacheTTL)
+	}
+
+	if _, err := ParseQuietHours(cfg.Service.QuietHours); err != nil {
+		return err
+	}
+
+	// Without window, the pruning and the checkpoints would never run
+	maintenanceWindow, err := ParseQuietHours(cfg.Service.MaintenanceWindow)
+	if err != nil {
+		return fmt.Errorf("maintenance window: %w", err)
+	}
+	if !maintenanceWindow.Enabled() {
+		return fmt.Errorf("maintenance window is required")
+	}
+
+	// Each repair syncs with Google Calendar, checking more often would only spend API quota
+	if cfg.Service.ConsistencyCheckInterval != 0 && cfg.Service.ConsistencyCheckInterval < time.Minute {
+		return fmt.Errorf("consistency check interval must be 0 or at least 1m, got %s", cfg.Service.ConsistencyCheckInterval)
+	}
+
+	// Changes are listed with a two minutes look back when the pass runs, a longer window would miss some
+	if cfg.App.WebhookDebounce < 0 || cfg.App.WebhookDebounce > time.Minute {
+		return fmt.Errorf("webhook debounce must be between 0 and 1m, got %s", cfg.App.WebhookDebounce)
+	}
+
+	// Each poll lists the changed events with the Calendar API, polling more often would only spend API quota
+	if cfg.App.WebhookPollInterval != 0 && (cfg.App.WebhookPollInterval < time.Minute || cfg.App.WebhookPollInterval > 24*time.Hour) {
+		return fmt.Errorf("webhook poll interval must be 0 or between 1m and 24h, got %s", cfg.App.WebhookPollInterval)
+	}
+
+	if cfg.App.AdminAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.App.AdminAddr); err != nil {
+			return fmt.Errorf("invalid admin_addr '%s': %w", cfg.App.AdminAddr, err)
+		}
+	}
+
+	if cfg.Service.HeartbeatURL != "" {
+		if _, err := url.ParseRequestURI(cfg.Service.HeartbeatURL); err != nil {
+			return fmt.Errorf("invalid heartbeat_url '%s': %w", cfg.Service.HeartbeatURL, err)
+		}
 	}
 
 	if cfg.App.AppUrl == "" {
@@ -258,6 +487,89 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("invalid public_url '%s': %w", cfg.App.PublicUrl, err)
 	}
 
+	if cfg.Tracing.Enabled {
+		if cfg.Tracing.Endpoint == "" {
+			return fmt.Errorf("tracing endpoint is required when tracing is enabled")
+		}
+		if _, err := url.ParseRequestURI(cfg.Tracing.Endpoint); err != nil {
+			return fmt.Errorf("invalid tracing endpoint '%s': %w", cfg.Tracing.Endpoint, err)
+		}
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1, got %g", cfg.Tracing.SampleRatio)
+	}
+
+	if cfg.Telemetry.Enabled {
+		if cfg.Telemetry.Endpoint == "" {
+			return fmt.Errorf("telemetry endpoint is required when telemetry is enabled")
+		}
+		if _, err := url.ParseRequestURI(cfg.Telemetry.Endpoint); err != nil {
+			return fmt.Errorf("invalid telemetry endpoint '%s': %w", cfg.Telemetry.Endpoint, err)
+		}
+	}
+	if cfg.Telemetry.Interval < time.Hour {
+		return fmt.Errorf("telemetry interval must be at least 1h, got %s", cfg.Telemetry.Interval)
+	}
+
+	if cfg.Notify.SlackWebhookURL != "" {
+		if _, err := url.ParseRequestURI(cfg.Notify.SlackWebhookURL); err != nil {
+			return fmt.Errorf("invalid slack_webhook_url: %w", err)
+		}
+	}
+	if cfg.Notify.SMTPHost != "" && (cfg.Notify.EmailFrom == "" || len(cfg.Notify.EmailTo) == 0) {
+		return fmt.Errorf("email_from and email_to are required when smtp_host is set")
+	}
+	if cfg.Notify.FailureThreshold < 1 {
+		return fmt.Errorf("failure threshold must be at least 1, got %d", cfg.Notify.FailureThreshold)
+	}
+	if cfg.Notify.FailureCooldown <= 0 {
+		return fmt.Errorf("failure cooldown must be positive, got %s", cfg.Notify.FailureCooldown)
+	}
+	if cfg.Notify.ImbalanceThreshold < 0 {
+		return fmt.Errorf("imbalance threshold must not be negative, got %d", cfg.Notify.ImbalanceThreshold)
+	}
+	if cfg.Notify.ReminderTime != "" {
+		if _, err := time.Parse("15:04", cfg.Notify.ReminderTime); err != nil {
+			return fmt.Errorf("invalid reminder_time %q: expected HH:MM", cfg.Notify.ReminderTime)
+		}
+	}
+
+	for _, hookURL := range cfg.Hooks.URLs {
+		parsed, err := url.ParseRequestURI(hookURL)
+		if err != nil {
+			return fmt.Errorf("invalid hook url '%s': %w", hookURL, err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("invalid hook url '%s': scheme must be http or https", hookURL)
+		}
+	}
+	if len(cfg.Hooks.URLs) > 0 && cfg.Hooks.Secret == "" {
+		return fmt.Errorf("hooks secret is required when hook urls are set")
+	}
+
+	cfg.Branding.EventIdentifier = strings.TrimSpace(cfg.Branding.EventIdentifier)
+	if cfg.Branding.EventIdentifier == "" {
+		return fmt.Errorf("branding event_identifier must not be empty")
+	}
+	if cfg.Branding.EventSourceURL != "" {
+		if _, err := url.ParseRequestURI(cfg.Branding.EventSourceURL); err != nil {
+			return fmt.Errorf("invalid branding event_source_url '%s': %w", cfg.Branding.EventSourceURL, err)
+		}
+	}
+
+	if cfg.App.CalendarEndpoint != "" {
+		if cfg.App.DemoMode {
+			return fmt.Errorf("calendar_endpoint must be empty in demo mode, the demo serves its own calendar")
+		}
+		if _, err := url.ParseRequestURI(cfg.App.CalendarEndpoint); err != nil {
+			return fmt.Errorf("invalid calendar_endpoint '%s': %w", cfg.App.CalendarEndpoint, err)
+		}
+	}
+
+	// The demo never reaches Google
+	if cfg.App.DemoMode {
+		return nil
+	}
 	if cfg.Credentials.ClientID == "" {
 		return fmt.Errorf("OAuth client ID is required (set NR_OAUTH__CLIENT_ID or GOOGLE_OAUTH_CLIENT_ID environment variable)")
 	}
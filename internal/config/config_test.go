@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -58,13 +59,14 @@ parent_a = "Alice"
 parent_b = "Bob"
 
 [availability]
-parent_a_unavailable = ["Mon"]
-parent_b_unavailable = ["Tue"]
+parent_a_unavailable = ["Monday"]
+parent_b_unavailable = ["Tuesday"]
 
 [schedule]
 update_frequency = "daily"
 calendar_id = "primary"
 look_ahead_days = 14
+webhook_look_ahead_days = 7
 
 [service]
 state_file = "data/test.db"
@@ -86,11 +88,12 @@ manual_sync_on_startup = false # Explicitly set to false to test override
 	assert.Equal(t, "https://example.com/public", cfg.App.PublicUrl)
 	assert.Equal(t, "Alice", cfg.Parents.ParentA)
 	assert.Equal(t, "Bob", cfg.Parents.ParentB)
-	assert.Equal(t, []string{"Mon"}, cfg.Availability.ParentAUnavailable)
-	assert.Equal(t, []string{"Tue"}, cfg.Availability.ParentBUnavailable)
+	assert.Equal(t, []string{"Monday"}, cfg.Availability.ParentAUnavailable)
+	assert.Equal(t, []string{"Tuesday"}, cfg.Availability.ParentBUnavailable)
 	assert.Equal(t, "daily", cfg.Schedule.UpdateFrequency)
 	assert.Equal(t, "primary", cfg.Schedule.CalendarID)
 	assert.Equal(t, 14, cfg.Schedule.LookAheadDays)
+	assert.Equal(t, LookAheadWindows{Webhook: 7}, cfg.Schedule.LookAheadWindows())
 	assert.True(t, filepath.IsAbs(cfg.Service.StateFile), "State file path should be absolute")
 	// Check if the cleaned absolute path ends with the expected relative path components
 	expectedSuffix := filepath.Join("data", "test.db")
@@ -170,6 +173,29 @@ state_file = "state.db"
 	assert.Equal(t, "info", cfg.Service.LogLevel)                                                 // Default log level
 	assert.True(t, cfg.Service.ManualSyncOnStartup, "ManualSyncOnStartup should default to true") // Check new default
 	assert.Equal(t, "", cfg.Schedule.CalendarID)                                                  // Default calendar ID is empty
+	assert.Equal(t, 10*time.Minute, cfg.Service.TokenRefreshMargin)                               // Default token refresh margin
+	assert.Equal(t, "database", cfg.Service.TokenStorage)                                         // Default token storage
+	assert.Equal(t, 500*time.Millisecond, cfg.Service.SlowQueryThreshold)                         // Default slow query threshold
+	assert.Equal(t, 5*time.Second, cfg.App.WebhookDebounce)                                       // Default webhook debounce
+	assert.Zero(t, cfg.App.WebhookPollInterval)                                                   // Polling is opt-in
+	assert.Equal(t, 10*time.Minute, cfg.Service.StatsCacheTTL)                                    // Default statistics cache TTL
+	assert.Equal(t, time.Hour, cfg.Service.ConsistencyCheckInterval)                              // Default consistency check interval
+	assert.Equal(t, "03:00-05:00", cfg.Service.MaintenanceWindow)                                 // Default maintenance window
+	assert.False(t, cfg.Tracing.Enabled)                                                          // Tracing is opt-in
+	assert.Equal(t, "night-routine", cfg.Tracing.ServiceName)                                     // Default service name
+	assert.Equal(t, 1.0, cfg.Tracing.SampleRatio)                                                 // Default sample ratio
+	assert.False(t, cfg.Telemetry.Enabled)                                                        // Telemetry is opt-in
+	assert.Equal(t, 7*24*time.Hour, cfg.Telemetry.Interval)                                       // Weekly telemetry report
+	assert.Equal(t, 587, cfg.Notify.SMTPPort)                                                     // Default SMTP port
+	assert.Equal(t, 3, cfg.Notify.FailureThreshold)                                               // Default failure threshold
+	assert.Equal(t, 6*time.Hour, cfg.Notify.FailureCooldown)                                      // Default failure cooldown
+	assert.False(t, cfg.Notify.MonthlyReport)                                                     // Monthly report emails are opt-in
+	assert.Empty(t, cfg.Notify.ReminderTime)                                                      // Duty reminders are opt-in
+	assert.Equal(t, 8, cfg.Notify.ImbalanceThreshold)                                             // Default imbalance threshold
+	assert.Empty(t, cfg.Hooks.URLs)                                                               // Outbound hooks are opt-in
+	assert.Equal(t, "🌃👶", cfg.Branding.EventEmoji)                                                // Default event emoji
+	assert.Equal(t, "Night Routine", cfg.Branding.EventIdentifier)                                // Default event identifier
+	assert.Equal(t, "http://required-app.com", cfg.Branding.EventSourceURL)                       // Event source URL defaults to app_url
 
 	// Check values provided in TOML
 	assert.Equal(t, "http://required-app.com", cfg.App.AppUrl)
@@ -190,6 +216,29 @@ state_file = "state.db"
 	assert.Equal(t, "http://required-app.com/oauth/callback", cfg.OAuth.RedirectURL) // Based on provided AppUrl
 }
 
+func TestLoadConfig_WithoutParents(t *testing.T) {
+	configFile := createTempConfigFile(t, `
+[app]
+app_url = "http://required-app.com"
+public_url = "http://required-public.com"
+
+[service]
+state_file = "state.db"
+`)
+	setEnvVars(t, map[string]string{
+		"GOOGLE_OAUTH_CLIENT_ID":     "test-client-id",
+		"GOOGLE_OAUTH_CLIENT_SECRET": "test-client-secret",
+	})
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err, "the setup wizard asks for the parents")
+	assert.Empty(t, cfg.Parents.ParentA)
+	assert.Empty(t, cfg.Parents.ParentB)
+	assert.Equal(t, "weekly", cfg.Schedule.UpdateFrequency)
+	assert.Equal(t, 30, cfg.Schedule.LookAheadDays)
+	assert.Equal(t, 5, cfg.Schedule.PastEventThresholdDays)
+}
+
 func TestLoadConfig_EnvVarOverrides(t *testing.T) {
 	tomlContent := `
 [app]
@@ -286,6 +335,37 @@ look_ahead_days = 7
 state_file = "s.db"`,
 			expectedErr: "parent names must be different",
 		},
+		{
+			name: "Names Along With Parent A",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+names = ["A", "B"]
+[schedule]
+update_frequency = "weekly"
+look_ahead_days = 7
+[service]
+state_file = "s.db"`,
+			expectedErr: "set either parents.names or parents.parent_a and parents.parent_b",
+		},
+		{
+			name: "Single Name",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+names = ["A"]
+[schedule]
+update_frequency = "weekly"
+look_ahead_days = 7
+[service]
+state_file = "s.db"`,
+			expectedErr: "parents.names: both parent names are required",
+		},
 		{
 			name: "Invalid Frequency",
 			tomlContent: `
@@ -316,7 +396,92 @@ update_frequency = "daily"
 look_ahead_days = 0
 [service]
 state_file = "s.db"`,
-			expectedErr: "look ahead days must be positive",
+			expectedErr: "invalid look ahead days: 0 is not between 1 and 365",
+		},
+		{
+			name: "Too Many Look Ahead Days",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 366
+[service]
+state_file = "s.db"`,
+			expectedErr: "invalid look ahead days: 366 is not between 1 and 365",
+		},
+		{
+			name: "Too Many Webhook Look Ahead Days",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 30
+webhook_look_ahead_days = 400
+[service]
+state_file = "s.db"`,
+			expectedErr: "invalid look ahead days: 400 is neither 0 nor between 1 and 365",
+		},
+		{
+			name: "Invalid Past Event Threshold Days",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 7
+past_event_threshold_days = 31
+[service]
+state_file = "s.db"`,
+			expectedErr: "invalid past event threshold days: 31 is not between 0 and 30",
+		},
+		{
+			name: "Invalid Stats Order",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 7
+stats_order = "random"
+[service]
+state_file = "s.db"`,
+			expectedErr: "invalid stats order: random",
+		},
+		{
+			name: "Invalid Unavailable Day",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[availability]
+parent_b_unavailable = ["Funday"]
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 7
+[service]
+state_file = "s.db"`,
+			expectedErr: "parent B availability: invalid day of week: Funday",
 		},
 		{
 			name: "Missing App URL",
@@ -395,6 +560,429 @@ look_ahead_days = 1
 [service]`,
 			expectedErr: "service.state_file is required",
 		},
+		{
+			name: "Token Refresh Margin Too Large",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+token_refresh_margin = "2h"`,
+			expectedErr: "token refresh margin must be between 0 and 1h",
+		},
+		{
+			name: "Invalid Token Storage",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+token_storage = "vault"`,
+			expectedErr: "invalid token storage: vault",
+		},
+		{
+			name: "Invalid Heartbeat URL",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+heartbeat_url = "not a url"`,
+			expectedErr: "invalid heartbeat_url",
+		},
+		{
+			name: "Invalid Admin Address",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+admin_addr = "6060"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"`,
+			expectedErr: "invalid admin_addr '6060'",
+		},
+		{
+			name: "Webhook Debounce Too Long",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+webhook_debounce = "5m"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"`,
+			expectedErr: "webhook debounce must be between 0 and 1m",
+		},
+		{
+			name: "Webhook Poll Interval Too Short",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+webhook_poll_interval = "10s"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"`,
+			expectedErr: "webhook poll interval must be 0 or between 1m and 24h",
+		},
+		{
+			name: "Invalid Quiet Hours",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+quiet_hours = "22:00"`,
+			expectedErr: "invalid quiet hours",
+		},
+		{
+			name: "Empty Maintenance Window",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+maintenance_window = ""`,
+			expectedErr: "maintenance window is required",
+		},
+		{
+			name: "Consistency Check Interval Too Short",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+consistency_check_interval = "30s"`,
+			expectedErr: "consistency check interval must be 0 or at least 1m",
+		},
+		{
+			name: "Negative Review After Days",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+review_after_days = -1
+[service]
+state_file = "s.db"`,
+			expectedErr: "review after days must not be negative",
+		},
+		{
+			name: "Review Timeout Too Short",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+review_after_days = 14
+review_timeout = "30s"
+[service]
+state_file = "s.db"`,
+			expectedErr: "review timeout must be 0 or at least 1m",
+		},
+		{
+			name: "Tracing Enabled Without Endpoint",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[tracing]
+enabled = true`,
+			expectedErr: "tracing endpoint is required",
+		},
+		{
+			name: "Invalid Tracing Sample Ratio",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[tracing]
+sample_ratio = 1.5`,
+			expectedErr: "tracing sample ratio must be between 0 and 1",
+		},
+		{
+			name: "Telemetry Enabled Without Endpoint",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[telemetry]
+enabled = true`,
+			expectedErr: "telemetry endpoint is required",
+		},
+		{
+			name: "Telemetry Interval Too Short",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[telemetry]
+interval = "5m"`,
+			expectedErr: "telemetry interval must be at least 1h",
+		},
+		{
+			name: "Email Without Recipients",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[notify]
+smtp_host = "smtp.example.com"
+email_from = "night-routine@example.com"`,
+			expectedErr: "email_from and email_to are required when smtp_host is set",
+		},
+		{
+			name: "Invalid Failure Threshold",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[notify]
+failure_threshold = 0`,
+			expectedErr: "failure threshold must be at least 1",
+		},
+		{
+			name: "Invalid Reminder Time",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[notify]
+reminder_time = "6pm"`,
+			expectedErr: `invalid reminder_time "6pm": expected HH:MM`,
+		},
+		{
+			name: "Invalid Hook URL Scheme",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[hooks]
+urls = ["ftp://hooks.example.com"]
+secret = "s3cret"`,
+			expectedErr: "invalid hook url 'ftp://hooks.example.com': scheme must be http or https",
+		},
+		{
+			name: "Hook URLs Without Secret",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[hooks]
+urls = ["https://hooks.example.com/night-routine"]`,
+			expectedErr: "hooks secret is required when hook urls are set",
+		},
+		{
+			name: "Empty Event Identifier",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[branding]
+event_identifier = " "`,
+			expectedErr: "branding event_identifier must not be empty",
+		},
+		{
+			name: "Invalid Event Source URL",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"
+[branding]
+event_source_url = "not a url"`,
+			expectedErr: "invalid branding event_source_url 'not a url'",
+		},
+		{
+			name: "Invalid Calendar Endpoint",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+calendar_endpoint = "not a url"
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"`,
+			expectedErr: "invalid calendar_endpoint 'not a url'",
+		},
+		{
+			name: "Calendar Endpoint In Demo Mode",
+			tomlContent: `
+[app]
+app_url = "http://a.com"
+public_url = "http://p.com"
+calendar_endpoint = "http://localhost:9999/"
+demo_mode = true
+[parents]
+parent_a = "A"
+parent_b = "B"
+[schedule]
+update_frequency = "daily"
+look_ahead_days = 1
+[service]
+state_file = "s.db"`,
+			expectedErr: "calendar_endpoint must be empty in demo mode",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -471,18 +1059,20 @@ log_level = "warn"
 `
 	configFile := createTempConfigFile(t, tomlContent)
 	setEnvVars(t, map[string]string{
-		"NR_APP__PORT":            "7777",
-		"NR_SERVICE__LOG_LEVEL":   "trace",
-		"NR_PARENTS__PARENT_A":    "NRAlice",
-		"NR_PARENTS__PARENT_B":    "NRBob",
-		"NR_OAUTH__CLIENT_ID":     "nr-client-id",
-		"NR_OAUTH__CLIENT_SECRET": "nr-client-secret",
+		"NR_APP__PORT":                     "7777",
+		"NR_SERVICE__LOG_LEVEL":            "trace",
+		"NR_PARENTS__PARENT_A":             "NRAlice",
+		"NR_PARENTS__PARENT_B":             "NRBob",
+		"NR_OAUTH__CLIENT_ID":              "nr-client-id",
+		"NR_OAUTH__CLIENT_SECRET":          "nr-client-secret",
+		"NR_SERVICE__TOKEN_REFRESH_MARGIN": "5m",
 	})
 
 	cfg, err := Load(configFile)
 	require.NoError(t, err)
 
 	assert.Equal(t, 7777, cfg.App.Port, "NR_APP__PORT should override TOML port")
+	assert.Equal(t, 5*time.Minute, cfg.Service.TokenRefreshMargin, "NR_SERVICE__TOKEN_REFRESH_MARGIN should be parsed as a duration")
 	assert.Equal(t, "trace", cfg.Service.LogLevel, "NR_SERVICE__LOG_LEVEL should override TOML log_level")
 	assert.Equal(t, "NRAlice", cfg.Parents.ParentA, "NR_PARENTS__PARENT_A should override TOML parent_a")
 	assert.Equal(t, "NRBob", cfg.Parents.ParentB, "NR_PARENTS__PARENT_B should override TOML parent_b")
@@ -740,3 +1330,53 @@ state_file = "state.db"
 	assert.Equal(t, "http://localhost:8888/oauth/callback", cfg.OAuth.RedirectURL,
 		"trailing slash in app_url must not produce a double-slash redirect URL")
 }
+
+func TestLoadConfig_DemoModeWithoutOAuth(t *testing.T) {
+	tomlContent := `
+[app]
+app_url = "http://localhost:8888"
+public_url = "http://localhost:8888"
+demo_mode = true
+[parents]
+parent_a = "A"
+parent_b = "B"
+[service]
+state_file = "state.db"
+`
+	configFile := createTempConfigFile(t, tomlContent)
+	for _, key := range []string{"GOOGLE_OAUTH_CLIENT_ID", "GOOGLE_OAUTH_CLIENT_SECRET", "NR_OAUTH__CLIENT_ID", "NR_OAUTH__CLIENT_SECRET"} {
+		t.Setenv(key, "")
+	}
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err, "the demo needs no Google credentials")
+	assert.True(t, cfg.App.DemoMode)
+	assert.Empty(t, cfg.App.CalendarEndpoint)
+}
+
+func TestLoadConfig_ParentNames(t *testing.T) {
+	setEnvVars(t, map[string]string{
+		"GOOGLE_OAUTH_CLIENT_ID":     "test-id",
+		"GOOGLE_OAUTH_CLIENT_SECRET": "test-secret",
+	})
+	configFile := createTempConfigFile(t, `
+[app]
+app_url = "http://localhost:8888"
+public_url = "http://localhost:8888"
+[parents]
+names = ["Alice", "Bob", "Grandma"]
+[service]
+state_file = "state.db"
+`)
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", cfg.Parents.ParentA)
+	assert.Equal(t, "Bob", cfg.Parents.ParentB)
+	assert.Equal(t, []string{"Grandma"}, cfg.Parents.Extra())
+
+	t.Setenv("NR_PARENTS__NAMES", "Alice, Bob, Grandma, Grandpa")
+	cfg, err = Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Grandma", "Grandpa"}, cfg.Parents.Extra())
+}
@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+// DayWeights is how much a night counts in the fairness totals of the scheduler, by day of the week:
+// a Friday weighted 2, with a harder bedtime, counts as two nights. Days without weight count 1, so
+// a nil DayWeights counts every night once.
+type DayWeights map[time.Weekday]float64
+
+// Weight returns how much a night on day counts
+func (w DayWeights) Weight(day time.Weekday) float64 {
+	if weight, ok := w[day]; ok {
+		return weight
+	}
+	return 1
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDayWeights_Weight(t *testing.T) {
+	var unset DayWeights
+	assert.Equal(t, 1.0, unset.Weight(time.Friday), "every night counts once without weights")
+
+	weights := DayWeights{time.Friday: 2, time.Sunday: 0.5}
+	assert.Equal(t, 2.0, weights.Weight(time.Friday))
+	assert.Equal(t, 0.5, weights.Weight(time.Sunday))
+	assert.Equal(t, 1.0, weights.Weight(time.Monday))
+}
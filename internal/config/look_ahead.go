@@ -0,0 +1,35 @@
+package config
+
+import "github.com/belphemur/night-routine/internal/constants"
+
+// LookAheadWindows are the days scheduled ahead by the syncs of the periodic ticker, of the webhook
+// and of the web interface. A window of 0 uses the look_ahead_days of the schedule.
+type LookAheadWindows struct {
+	Scheduled int
+	Webhook   int
+	Manual    int
+}
+
+// ForTrigger returns the window of the syncs started by trigger, 0 when they use the look_ahead_days
+// of the schedule
+func (w LookAheadWindows) ForTrigger(trigger constants.SyncTrigger) int {
+	switch trigger {
+	case constants.SyncTriggerScheduled:
+		return w.Scheduled
+	case constants.SyncTriggerWebhook:
+		return w.Webhook
+	case constants.SyncTriggerManual:
+		return w.Manual
+	default:
+		return 0
+	}
+}
+
+// Days returns the days scheduled ahead by the syncs started by trigger, lookAheadDays unless the
+// trigger has its own window
+func (w LookAheadWindows) Days(trigger constants.SyncTrigger, lookAheadDays int) int {
+	if days := w.ForTrigger(trigger); days > 0 {
+		return days
+	}
+	return lookAheadDays
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookAheadWindows_Days(t *testing.T) {
+	windows := LookAheadWindows{Scheduled: 60, Webhook: 14}
+
+	tests := []struct {
+		trigger constants.SyncTrigger
+		want    int
+	}{
+		{trigger: constants.SyncTriggerScheduled, want: 60},
+		{trigger: constants.SyncTriggerWebhook, want: 14},
+		{trigger: constants.SyncTriggerManual, want: 30},
+		{trigger: constants.SyncTriggerStartup, want: 30},
+		{trigger: constants.SyncTriggerSettings, want: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.trigger.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, windows.Days(tt.trigger, 30))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"time"
+)
+
+// ErrInvalidParentContact is returned when a contact of a parent is invalid
+var ErrInvalidParentContact = errors.New("invalid parent contact")
+
+// MaxEscalationDelay is the longest delay before the reminder of a parent escalates
+const MaxEscalationDelay = 12 * time.Hour
+
+var (
+	phonePattern         = regexp.MustCompile(`^\+?[0-9][0-9 ().-]{2,30}$`)
+	slackMemberIDPattern = regexp.MustCompile(`^[UW][A-Z0-9]{2,20}$`)
+)
+
+// EmailSubscription is an email a parent receives at their own address once subscribed to it
+type EmailSubscription string
+
+// Emails the parents can subscribe to
+const (
+	EmailWeeklySummary   EmailSubscription = "weekly_summary"   // Nights of the week ahead, every Monday
+	EmailOverrideNotices EmailSubscription = "override_notices" // Each night changed from Google Calendar, as it is applied
+)
+
+// ParentContact is how the notifications meant for a parent reach them, when their duty reminder
+// escalates to the other parents and which emails they subscribed to. Every field is optional.
+type ParentContact struct {
+	Email         string // Address receiving the notifications meant for the parent instead of the household addresses
+	Phone         string // Given to the other parents when the reminder escalates
+	SlackMemberID string // Slack member mentioned in the notifications meant for the parent, e.g. U024BE7LH
+	// EscalationDelay is how long after the duty reminder the other parents are notified when the
+	// parent did not acknowledge it; 0 never escalates
+	EscalationDelay time.Duration
+	WeeklySummary   bool // Subscribed to EmailWeeklySummary, requires Email
+	OverrideNotices bool // Subscribed to EmailOverrideNotices, requires Email
+}
+
+// Subscribes reports whether the parent receives the emails of subscription at their address
+func (c ParentContact) Subscribes(subscription EmailSubscription) bool {
+	if c.Email == "" {
+		return false
+	}
+	switch subscription {
+	case EmailWeeklySummary:
+		return c.WeeklySummary
+	case EmailOverrideNotices:
+		return c.OverrideNotices
+	default:
+		return false
+	}
+}
+
+// IsZero reports whether no contact is set
+func (c ParentContact) IsZero() bool {
+	return c == ParentContact{}
+}
+
+// Validate checks the format of each field, the escalation delay and that the subscriptions have an email. The errors wrap ErrInvalidParentContact.
+func (c ParentContact) Validate() error {
+	if c.Email != "" {
+		if address, err := mail.ParseAddress(c.Email); err != nil || address.Address != c.Email {
+			return fmt.Errorf("%w: invalid email %q", ErrInvalidParentContact, c.Email)
+		}
+	}
+	if c.Email == "" && (c.WeeklySummary || c.OverrideNotices) {
+		return fmt.Errorf("%w: an email is required to subscribe to emails", ErrInvalidParentContact)
+	}
+	if c.Phone != "" && !phonePattern.MatchString(c.Phone) {
+		return fmt.Errorf("%w: invalid phone %q", ErrInvalidParentContact, c.Phone)
+	}
+	if c.SlackMemberID != "" && !slackMemberIDPattern.MatchString(c.SlackMemberID) {
+		return fmt.Errorf("%w: invalid Slack member ID %q, expected e.g. U024BE7LH", ErrInvalidParentContact, c.SlackMemberID)
+	}
+	if c.EscalationDelay < 0 || c.EscalationDelay > MaxEscalationDelay {
+		return fmt.Errorf("%w: escalation delay must be between 0 and %s", ErrInvalidParentContact, MaxEscalationDelay)
+	}
+	if c.EscalationDelay%time.Minute != 0 {
+		return fmt.Errorf("%w: escalation delay must be a whole number of minutes", ErrInvalidParentContact)
+	}
+	return nil
+}
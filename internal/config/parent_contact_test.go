@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParentContact_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		contact ParentContact
+		valid   bool
+	}{
+		{"empty", ParentContact{}, true},
+		{"every field", ParentContact{Email: "alice@example.com", Phone: "+33 6 12 34 56 78", SlackMemberID: "U024BE7LH", EscalationDelay: 30 * time.Minute}, true},
+		{"email with a display name", ParentContact{Email: "Alice <alice@example.com>"}, false},
+		{"invalid email", ParentContact{Email: "alice"}, false},
+		{"invalid phone", ParentContact{Phone: "call me"}, false},
+		{"invalid Slack member ID", ParentContact{SlackMemberID: "@alice"}, false},
+		{"negative delay", ParentContact{EscalationDelay: -time.Minute}, false},
+		{"delay too long", ParentContact{EscalationDelay: 13 * time.Hour}, false},
+		{"delay with seconds", ParentContact{EscalationDelay: 90 * time.Second}, false},
+		{"subscriptions", ParentContact{Email: "alice@example.com", WeeklySummary: true, OverrideNotices: true}, true},
+		{"subscription without email", ParentContact{WeeklySummary: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.contact.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidParentContact)
+			}
+		})
+	}
+}
+
+func TestParentContact_Subscribes(t *testing.T) {
+	contact := ParentContact{Email: "alice@example.com", WeeklySummary: true}
+	assert.True(t, contact.Subscribes(EmailWeeklySummary))
+	assert.False(t, contact.Subscribes(EmailOverrideNotices))
+	assert.False(t, contact.Subscribes("daily_digest"), "unknown subscription")
+	assert.False(t, ParentContact{WeeklySummary: true}.Subscribes(EmailWeeklySummary), "no email to send to")
+}
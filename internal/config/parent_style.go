@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/belphemur/night-routine/internal/constants"
+)
+
+// ErrInvalidParentStyle is returned when the color or the avatar of a parent is invalid
+var ErrInvalidParentStyle = errors.New("invalid parent style")
+
+// MaxAvatarLength is the maximum number of characters of an avatar, enough for an emoji sequence
+const MaxAvatarLength = 8
+
+// ParentStyle identifies a parent visually on every surface: the web interface, the API and the
+// Google Calendar events
+type ParentStyle struct {
+	Color  constants.ParentColor
+	Avatar string // Emoji or initials shown in place of the first letter of the name, empty for the letter
+}
+
+// DefaultParentStyles returns the styles of the parents before any is saved
+func DefaultParentStyles() (parentA, parentB ParentStyle) {
+	return ParentStyle{Color: constants.DefaultParentAColor}, ParentStyle{Color: constants.DefaultParentBColor}
+}
+
+// Validate checks the color and the length of the avatar. The errors wrap ErrInvalidParentStyle.
+func (s ParentStyle) Validate() error {
+	if !s.Color.IsValid() {
+		return fmt.Errorf("%w: unknown color %q", ErrInvalidParentStyle, s.Color)
+	}
+	if utf8.RuneCountInString(s.Avatar) > MaxAvatarLength {
+		return fmt.Errorf("%w: avatar longer than %d characters", ErrInvalidParentStyle, MaxAvatarLength)
+	}
+	if strings.TrimSpace(s.Avatar) != s.Avatar {
+		return fmt.Errorf("%w: avatar with leading or trailing spaces", ErrInvalidParentStyle)
+	}
+	return nil
+}
+
+// Badge returns the avatar, or the first letter of name in upper case without avatar
+func (s ParentStyle) Badge(name string) string {
+	if s.Avatar != "" {
+		return s.Avatar
+	}
+	first, _ := utf8.DecodeRuneInString(name)
+	if first == utf8.RuneError {
+		return ""
+	}
+	return string(unicode.ToUpper(first))
+}
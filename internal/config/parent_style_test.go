@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParentStyle_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		style ParentStyle
+		valid bool
+	}{
+		{"color only", ParentStyle{Color: constants.ParentColorSage}, true},
+		{"emoji avatar", ParentStyle{Color: constants.ParentColorGrape, Avatar: "🦊"}, true},
+		{"family emoji sequence", ParentStyle{Color: constants.ParentColorGrape, Avatar: "👨‍👩‍👧"}, true},
+		{"initials", ParentStyle{Color: constants.ParentColorBasil, Avatar: "AB"}, true},
+		{"missing color", ParentStyle{Avatar: "A"}, false},
+		{"unknown color", ParentStyle{Color: "pink"}, false},
+		{"avatar too long", ParentStyle{Color: constants.ParentColorSage, Avatar: "Antoinette"}, false},
+		{"avatar with spaces", ParentStyle{Color: constants.ParentColorSage, Avatar: " A"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.style.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidParentStyle)
+			}
+		})
+	}
+}
+
+func TestParentStyle_Badge(t *testing.T) {
+	assert.Equal(t, "🦊", ParentStyle{Avatar: "🦊"}.Badge("alice"))
+	assert.Equal(t, "A", ParentStyle{}.Badge("alice"))
+	assert.Equal(t, "É", ParentStyle{}.Badge("émile"))
+	assert.Empty(t, ParentStyle{}.Badge(""))
+
+	parentA, parentB := DefaultParentStyles()
+	assert.Equal(t, constants.DefaultParentAColor, parentA.Color)
+	assert.Equal(t, constants.DefaultParentBColor, parentB.Color)
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuietHours is returned when quiet hours cannot be parsed
+var ErrInvalidQuietHours = errors.New("invalid quiet hours")
+
+// quietHoursTimeFormat is the format of the bounds of the quiet hours
+const quietHoursTimeFormat = "15:04"
+
+// QuietHours is a daily window, in local time, during which the automatic syncs leave Google Calendar
+// alone, written as 22:00-07:00. The window may span midnight. The zero value is disabled.
+type QuietHours struct {
+	start, end time.Duration // Offsets from midnight, start included and end excluded
+	enabled    bool
+}
+
+// ParseQuietHours parses quiet hours written as HH:MM-HH:MM; an empty value disables them. The errors
+// wrap ErrInvalidQuietHours.
+func ParseQuietHours(value string) (QuietHours, error) {
+	text := strings.TrimSpace(value)
+	if text == "" {
+		return QuietHours{}, nil
+	}
+	startText, endText, ok := strings.Cut(text, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("%w: %q is not written as HH:MM-HH:MM", ErrInvalidQuietHours, text)
+	}
+	start, err := time.Parse(quietHoursTimeFormat, strings.TrimSpace(startText))
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("%w: start %q is not HH:MM", ErrInvalidQuietHours, startText)
+	}
+	end, err := time.Parse(quietHoursTimeFormat, strings.TrimSpace(endText))
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("%w: end %q is not HH:MM", ErrInvalidQuietHours, endText)
+	}
+	if start.Equal(end) {
+		return QuietHours{}, fmt.Errorf("%w: start and end are both %s", ErrInvalidQuietHours, start.Format(quietHoursTimeFormat))
+	}
+	return QuietHours{
+		start:   time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		end:     time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+		enabled: true,
+	}, nil
+}
+
+// Enabled reports whether quiet hours are configured
+func (q QuietHours) Enabled() bool {
+	return q.enabled
+}
+
+// Contains reports whether t falls within the quiet hours, in the location of t
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.enabled {
+		return false
+	}
+	offset := q.offset(t)
+	if q.start < q.end {
+		return offset >= q.start && offset < q.end
+	}
+	// Spanning midnight
+	return offset >= q.start || offset < q.end
+}
+
+// EndAfter returns the end of the quiet hours following t, t itself when t is not within them
+func (q QuietHours) EndAfter(t time.Time) time.Time {
+	if !q.Contains(t) {
+		return t
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if q.offset(t) >= q.end {
+		// Before midnight, the quiet hours end tomorrow
+		midnight = midnight.AddDate(0, 0, 1)
+	}
+	return midnight.Add(q.end)
+}
+
+// StartAfter returns the start of the quiet hours following t, t itself when t is within them
+func (q QuietHours) StartAfter(t time.Time) time.Time {
+	if !q.enabled || q.Contains(t) {
+		return t
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if q.offset(t) >= q.start {
+		// Past today's start, the quiet hours start tomorrow
+		midnight = midnight.AddDate(0, 0, 1)
+	}
+	return midnight.Add(q.start)
+}
+
+// String returns the quiet hours as parsed by ParseQuietHours, empty when disabled
+func (q QuietHours) String() string {
+	if !q.enabled {
+		return ""
+	}
+	return formatOffset(q.start) + "-" + formatOffset(q.end)
+}
+
+// offset returns the time elapsed since the midnight of t
+func (q QuietHours) offset(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// formatOffset formats an offset from midnight as HH:MM
+func formatOffset(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
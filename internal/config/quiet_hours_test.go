@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	quiet, err := ParseQuietHours(" 22:00 - 07:30 ")
+	require.NoError(t, err)
+	assert.True(t, quiet.Enabled())
+	assert.Equal(t, "22:00-07:30", quiet.String())
+
+	quiet, err = ParseQuietHours("")
+	require.NoError(t, err)
+	assert.False(t, quiet.Enabled())
+	assert.False(t, quiet.Contains(time.Now()))
+	assert.Empty(t, quiet.String())
+
+	for _, value := range []string{"22:00", "22:00-25:00", "10pm-7am", "07:00-07:00"} {
+		_, err := ParseQuietHours(value)
+		assert.ErrorIs(t, err, ErrInvalidQuietHours, value)
+	}
+}
+
+func TestQuietHours_Contains(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 10, 16, hour, minute, 0, 0, time.UTC) }
+
+	overnight, err := ParseQuietHours("22:00-07:00")
+	require.NoError(t, err)
+	assert.True(t, overnight.Contains(at(22, 0)))
+	assert.True(t, overnight.Contains(at(3, 0)))
+	assert.False(t, overnight.Contains(at(7, 0)), "the end is excluded")
+	assert.False(t, overnight.Contains(at(12, 0)))
+
+	daytime, err := ParseQuietHours("13:00-14:30")
+	require.NoError(t, err)
+	assert.True(t, daytime.Contains(at(14, 29)))
+	assert.False(t, daytime.Contains(at(22, 0)))
+}
+
+func TestQuietHours_EndAfter(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC) }
+	quiet, err := ParseQuietHours("22:00-07:00")
+	require.NoError(t, err)
+
+	assert.Equal(t, at(17, 7), quiet.EndAfter(at(16, 23)), "ends tomorrow before midnight")
+	assert.Equal(t, at(17, 7), quiet.EndAfter(at(17, 3)), "ends today after midnight")
+	assert.Equal(t, at(16, 12), quiet.EndAfter(at(16, 12)), "not within the quiet hours")
+}
+
+func TestQuietHours_StartAfter(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC) }
+	window, err := ParseQuietHours("03:00-05:00")
+	require.NoError(t, err)
+
+	assert.Equal(t, at(16, 3), window.StartAfter(at(16, 1)), "starts later today")
+	assert.Equal(t, at(17, 3), window.StartAfter(at(16, 12)), "starts tomorrow")
+	assert.Equal(t, at(16, 4), window.StartAfter(at(16, 4)), "within the window")
+
+	overnight, err := ParseQuietHours("22:00-07:00")
+	require.NoError(t, err)
+	assert.Equal(t, at(16, 22), overnight.StartAfter(at(16, 12)))
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/validation"
+)
+
+// ExtraParent is a parent or caregiver taking turns after parent A and parent B, e.g. a grandparent
+type ExtraParent struct {
+	Name  string
+	Style ParentStyle
+}
+
+// ParentKey returns the key of the settings of the parent at index in the roster: "parent_a" and
+// "parent_b" for parent A and parent B, then "parent_c" and on for the extra parents
+func ParentKey(index int) string {
+	return "parent_" + string(rune('a'+index))
+}
+
+// ParentKeyIndex returns the index in the roster of the parent of key, false when key is not the
+// key of one of the validation.MaxParents parents
+func ParentKeyIndex(key string) (int, bool) {
+	letter, ok := strings.CutPrefix(key, "parent_")
+	if !ok || len(letter) != 1 || letter[0] < 'a' || letter[0] >= 'a'+validation.MaxParents {
+		return 0, false
+	}
+	return int(letter[0] - 'a'), true
+}
+
+// IsParentKey reports whether key is the key of the settings of a parent, see ParentKey
+func IsParentKey(key string) bool {
+	_, ok := ParentKeyIndex(key)
+	return ok
+}
+
+// DefaultExtraParentStyle returns the style of the extra parent at index in the roster, 2 for the
+// first one, before any is saved
+func DefaultExtraParentStyle(index int) ParentStyle {
+	colors := constants.DefaultExtraParentColors
+	return ParentStyle{Color: colors[(index-2)%len(colors)]}
+}
+
+// ExtraParentNames returns the names of the extra parents, in order
+func ExtraParentNames(extra []ExtraParent) []string {
+	names := make([]string, 0, len(extra))
+	for _, parent := range extra {
+		names = append(names, parent.Name)
+	}
+	return names
+}
+
+// RosterOf returns the names of the parents taking turns: parent A, parent B, then the extra parents
+func RosterOf(parentA, parentB string, extra []ExtraParent) []string {
+	return append([]string{parentA, parentB}, ExtraParentNames(extra)...)
+}
+
+// RosterSource reads the parents of the roster, implemented by ConfigStoreInterface
+type RosterSource interface {
+	GetParents() (parentA, parentB string, err error)
+	GetExtraParents() ([]ExtraParent, error)
+}
+
+// Roster returns the names of the parents taking turns read from source, see RosterOf
+func Roster(source RosterSource) ([]string, error) {
+	parentA, parentB, err := source.GetParents()
+	if err != nil {
+		return nil, err
+	}
+	extra, err := source.GetExtraParents()
+	if err != nil {
+		return nil, err
+	}
+	return RosterOf(parentA, parentB, extra), nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParentKey(t *testing.T) {
+	for index, key := range map[int]string{0: "parent_a", 1: "parent_b", 2: "parent_c", 5: "parent_f"} {
+		assert.Equal(t, key, ParentKey(index))
+		got, ok := ParentKeyIndex(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, index, got)
+	}
+
+	for _, key := range []string{"parent_g", "parent_", "parent_ab", "parent_A", "babysitter", ""} {
+		assert.False(t, IsParentKey(key), key)
+	}
+}
+
+func TestDefaultExtraParentStyle(t *testing.T) {
+	assert.Equal(t, constants.ParentColorBasil, DefaultExtraParentStyle(2).Color)
+	assert.Equal(t, constants.ParentColorPeacock, DefaultExtraParentStyle(5).Color)
+	assert.Empty(t, DefaultExtraParentStyle(2).Avatar)
+}
+
+func TestRosterOf(t *testing.T) {
+	assert.Equal(t, []string{"Alice", "Bob"}, RosterOf("Alice", "Bob", nil))
+	assert.Equal(t, []string{"Alice", "Bob", "Grandma"}, RosterOf("Alice", "Bob", []ExtraParent{{Name: "Grandma"}}))
+}
@@ -10,8 +10,27 @@ import (
 // This is the single source of truth for all configuration in handlers and services.
 type ConfigStoreInterface interface {
 	GetParents() (parentA, parentB string, err error)
+	// GetExtraParents returns the parents taking turns after parent A and parent B, in the order of
+	// the roster, none when never saved.
+	GetExtraParents() ([]ExtraParent, error)
 	GetAvailability(parent string) ([]string, error)
+	// GetUnavailabilityRules returns the recurring unavailability rules of a parent, on top of its unavailable days.
+	GetUnavailabilityRules(parent string) ([]UnavailabilityRule, error)
 	GetSchedule() (updateFrequency string, lookAheadDays, pastEventThresholdDays int, statsOrder constants.StatsOrder, err error)
+	// GetLookAheadWindows returns the look-ahead windows of the sync triggers, none set when never saved.
+	GetLookAheadWindows() (LookAheadWindows, error)
+	// GetVacation returns the family vacation, disabled when never saved.
+	GetVacation() (Vacation, error)
+	// GetSkipDates returns the days without night routine, single dates or recurring rules.
+	GetSkipDates() (SkipDates, error)
+	// GetSyncExclusions returns the kinds of nights kept out of Google Calendar, none when never saved.
+	GetSyncExclusions() (SyncExclusions, error)
+	// GetMinRestDays returns the nights off a parent gets at least after a block of consecutive nights, 0 when never saved.
+	GetMinRestDays() (int, error)
+	// GetDayWeights returns how much a night counts in the fairness totals by day of the week, none when never saved.
+	GetDayWeights() (DayWeights, error)
+	// GetParentStyles returns the color and avatar of each parent, the defaults when never saved.
+	GetParentStyles() (parentA, parentB ParentStyle, err error)
 	// GetOAuthConfig returns the OAuth2 configuration (static, from environment / file config).
 	GetOAuthConfig() *oauth2.Config
 }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSkipDate is returned when a skip date cannot be parsed
+var ErrInvalidSkipDate = errors.New("invalid skip date")
+
+// skipDateFormat is the format of the single skip dates, compared as local calendar days
+const skipDateFormat = "2006-01-02"
+
+// SkipDate is a day without night routine, e.g. the kid sleeping at the grandparents'. It is either a
+// single date, written as 2026-10-24, or recurring days written as an unavailability rule, such as
+// FREQ=MONTHLY;BYDAY=2SA for every second Saturday of the month.
+type SkipDate struct {
+	Date time.Time           // The single day skipped, zero for recurring days
+	Rule *UnavailabilityRule // The recurring days skipped, nil for a single day
+}
+
+// SkipDates is the list of days without night routine
+type SkipDates []SkipDate
+
+// ParseSkipDate parses a single date or a recurring rule. The errors wrap ErrInvalidSkipDate.
+func ParseSkipDate(value string) (SkipDate, error) {
+	text := strings.TrimSpace(value)
+	if text == "" {
+		return SkipDate{}, fmt.Errorf("%w: empty entry", ErrInvalidSkipDate)
+	}
+	if !strings.Contains(text, "=") {
+		date, err := time.ParseInLocation(skipDateFormat, text, time.Local)
+		if err != nil {
+			return SkipDate{}, fmt.Errorf("%w: %q is not a YYYY-MM-DD date", ErrInvalidSkipDate, text)
+		}
+		return SkipDate{Date: date}, nil
+	}
+
+	rule, err := ParseUnavailabilityRule(text)
+	if err != nil {
+		return SkipDate{}, fmt.Errorf("%w: %w", ErrInvalidSkipDate, err)
+	}
+	return SkipDate{Rule: &rule}, nil
+}
+
+// String returns the skip date as parsed by ParseSkipDate
+func (d SkipDate) String() string {
+	if d.Rule != nil {
+		return d.Rule.String()
+	}
+	return d.Date.Format(skipDateFormat)
+}
+
+// Describe returns the skip date in plain English, as "Saturday 2026-10-24" or "The second Saturday
+// of every month"
+func (d SkipDate) Describe() string {
+	if d.Rule != nil {
+		return d.Rule.Describe()
+	}
+	return d.Date.Format("Monday " + skipDateFormat)
+}
+
+// Matches reports whether the calendar day of date is skipped
+func (d SkipDate) Matches(date time.Time) bool {
+	if d.Rule != nil {
+		return d.Rule.Matches(date)
+	}
+	return date.Format(skipDateFormat) == d.Date.Format(skipDateFormat)
+}
+
+// Contains reports whether the calendar day of date is one of the skipped days
+func (s SkipDates) Contains(date time.Time) bool {
+	for _, skipDate := range s {
+		if skipDate.Matches(date) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSkipDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		want        string // Canonical form, empty when the entry is invalid
+		description string
+	}{
+		{name: "single date", value: " 2026-10-24 ", want: "2026-10-24", description: "Saturday 2026-10-24"},
+		{name: "second saturday", value: "FREQ=MONTHLY;BYDAY=2SA", want: "FREQ=MONTHLY;BYDAY=2SA", description: "The second Saturday of every month"},
+		{name: "empty", value: "  "},
+		{name: "invalid date", value: "24/10/2026"},
+		{name: "invalid rule", value: "FREQ=DAILY;BYDAY=SA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skipDate, err := ParseSkipDate(tt.value)
+			if tt.want == "" {
+				assert.ErrorIs(t, err, ErrInvalidSkipDate)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, skipDate.String())
+			assert.Equal(t, tt.description, skipDate.Describe())
+		})
+	}
+}
+
+func TestSkipDates_Contains(t *testing.T) {
+	secondSaturday, err := ParseSkipDate("FREQ=MONTHLY;BYDAY=2SA")
+	require.NoError(t, err)
+	single, err := ParseSkipDate("2026-10-20")
+	require.NoError(t, err)
+	skipDates := SkipDates{secondSaturday, single}
+
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{name: "single date", date: time.Date(2026, time.October, 20, 21, 0, 0, 0, time.Local), want: true},
+		{name: "second saturday", date: time.Date(2026, time.November, 14, 0, 0, 0, 0, time.Local), want: true},
+		{name: "first saturday", date: time.Date(2026, time.November, 7, 0, 0, 0, 0, time.Local), want: false},
+		{name: "day after single date", date: time.Date(2026, time.October, 21, 0, 0, 0, 0, time.Local), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, skipDates.Contains(tt.date))
+		})
+	}
+	assert.False(t, SkipDates(nil).Contains(time.Now()))
+}
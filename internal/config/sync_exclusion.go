@@ -0,0 +1,59 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// Kinds of nights SyncExclusions lists, as stored
+const (
+	syncExclusionBabysitter = "babysitter"
+	syncExclusionTagPrefix  = "tag:"
+)
+
+// SyncExclusions are the kinds of nights kept out of Google Calendar. The nights are still
+// recorded, counted in the statistics and shown in the web interface; only their calendar event
+// is left out. The zero value syncs every night.
+type SyncExclusions struct {
+	Babysitter bool     // Nights of a babysitter
+	Tags       []string // Tags of the nights set by hand, fairness.AssignmentTag values
+}
+
+// Excludes reports whether a night of caregiverType ("parent" or "babysitter") with tag, empty
+// without one, is kept out of the calendar
+func (e SyncExclusions) Excludes(caregiverType, tag string) bool {
+	if e.Babysitter && caregiverType == syncExclusionBabysitter {
+		return true
+	}
+	return tag != "" && slices.Contains(e.Tags, tag)
+}
+
+// IsEmpty reports whether every night is synced
+func (e SyncExclusions) IsEmpty() bool {
+	return !e.Babysitter && len(e.Tags) == 0
+}
+
+// Kinds returns the excluded kinds as stored: "babysitter", then "tag:<tag>" for each tag
+func (e SyncExclusions) Kinds() []string {
+	var kinds []string
+	if e.Babysitter {
+		kinds = append(kinds, syncExclusionBabysitter)
+	}
+	for _, tag := range e.Tags {
+		kinds = append(kinds, syncExclusionTagPrefix+tag)
+	}
+	return kinds
+}
+
+// ParseSyncExclusions reads the kinds returned by SyncExclusions.Kinds, ignoring unknown ones
+func ParseSyncExclusions(kinds []string) SyncExclusions {
+	var exclusions SyncExclusions
+	for _, kind := range kinds {
+		if kind == syncExclusionBabysitter {
+			exclusions.Babysitter = true
+		} else if tag, ok := strings.CutPrefix(kind, syncExclusionTagPrefix); ok && tag != "" {
+			exclusions.Tags = append(exclusions.Tags, tag)
+		}
+	}
+	return exclusions
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncExclusions_Excludes(t *testing.T) {
+	exclusions := SyncExclusions{Babysitter: true, Tags: []string{"sick_kid"}}
+
+	assert.True(t, exclusions.Excludes("babysitter", ""))
+	assert.True(t, exclusions.Excludes("parent", "sick_kid"))
+	assert.False(t, exclusions.Excludes("parent", "parent_away"))
+	assert.False(t, exclusions.Excludes("parent", ""))
+
+	assert.False(t, SyncExclusions{}.Excludes("babysitter", "sick_kid"), "the zero value syncs every night")
+	assert.True(t, SyncExclusions{}.IsEmpty())
+	assert.False(t, exclusions.IsEmpty())
+}
+
+func TestSyncExclusions_Kinds(t *testing.T) {
+	exclusions := SyncExclusions{Babysitter: true, Tags: []string{"sick_kid", "parent_away"}}
+
+	kinds := exclusions.Kinds()
+	assert.Equal(t, []string{"babysitter", "tag:sick_kid", "tag:parent_away"}, kinds)
+	assert.Equal(t, exclusions, ParseSyncExclusions(kinds))
+	assert.Equal(t, SyncExclusions{Tags: []string{"sick_kid"}}, ParseSyncExclusions([]string{"tag:sick_kid", "tag:", "nobody"}), "unknown kinds are ignored")
+}
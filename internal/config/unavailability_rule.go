@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RuleFrequency is the period an unavailability rule repeats over
+type RuleFrequency string
+
+const (
+	// RuleFrequencyWeekly repeats every Interval weeks
+	RuleFrequencyWeekly RuleFrequency = "WEEKLY"
+	// RuleFrequencyMonthly repeats every Interval months
+	RuleFrequencyMonthly RuleFrequency = "MONTHLY"
+)
+
+// ErrInvalidUnavailabilityRule is returned when an unavailability rule cannot be parsed
+var ErrInvalidUnavailabilityRule = errors.New("invalid unavailability rule")
+
+// ruleDateFormat is the format of the first day of a rule, compared as local calendar days
+const ruleDateFormat = "2006-01-02"
+
+// ruleWeekdays maps the RRULE day codes to the weekdays
+var ruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ruleOrdinals names the weeks of the month of the monthly rules
+var ruleOrdinals = map[int]string{1: "first", 2: "second", 3: "third", 4: "fourth", 5: "fifth", -1: "last"}
+
+// UnavailabilityRule is a recurring day a parent is unavailable, written as a subset of the
+// iCalendar RRULE:
+//
+//	FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16   every other Friday from October 16, 2026
+//	FREQ=MONTHLY;BYDAY=1MO                               the first Monday of every month
+//	FREQ=MONTHLY;BYDAY=-1FR                              the last Friday of every month
+type UnavailabilityRule struct {
+	Frequency RuleFrequency
+	Interval  int // Repeats every Interval weeks or months, counted from Start
+	Weekday   time.Weekday
+	Week      int       // Monthly rules: week of the month of the weekday, 1 to 5, or -1 for the last one
+	Start     time.Time // First day of the rule, zero when it always applied; required when Interval > 1
+}
+
+// ParseUnavailabilityRule parses an unavailability rule, with or without its "RRULE:" prefix. The
+// errors wrap ErrInvalidUnavailabilityRule.
+func ParseUnavailabilityRule(value string) (UnavailabilityRule, error) {
+	rule := UnavailabilityRule{Interval: 1}
+	text := strings.TrimSpace(value)
+	if len(text) >= 6 && strings.EqualFold(text[:6], "RRULE:") {
+		text = text[6:]
+	}
+	if text == "" {
+		return rule, fmt.Errorf("%w: empty rule", ErrInvalidUnavailabilityRule)
+	}
+
+	var byDay string
+	for _, part := range strings.Split(text, ";") {
+		key, val, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return rule, fmt.Errorf("%w: %q is not a KEY=VALUE pair", ErrInvalidUnavailabilityRule, part)
+		}
+		val = strings.ToUpper(strings.TrimSpace(val))
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "FREQ":
+			rule.Frequency = RuleFrequency(val)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 || interval > 52 {
+				return rule, fmt.Errorf("%w: interval %q is not between 1 and 52", ErrInvalidUnavailabilityRule, val)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			byDay = val
+		case "DTSTART":
+			start, err := time.ParseInLocation(ruleDateFormat, val, time.Local)
+			if err != nil {
+				return rule, fmt.Errorf("%w: start %q is not a YYYY-MM-DD date", ErrInvalidUnavailabilityRule, val)
+			}
+			rule.Start = start
+		default:
+			return rule, fmt.Errorf("%w: unsupported key %q", ErrInvalidUnavailabilityRule, key)
+		}
+	}
+
+	if rule.Frequency != RuleFrequencyWeekly && rule.Frequency != RuleFrequencyMonthly {
+		return rule, fmt.Errorf("%w: FREQ must be WEEKLY or MONTHLY", ErrInvalidUnavailabilityRule)
+	}
+	if len(byDay) < 2 {
+		return rule, fmt.Errorf("%w: BYDAY is required", ErrInvalidUnavailabilityRule)
+	}
+	weekday, ok := ruleWeekdays[byDay[len(byDay)-2:]]
+	if !ok {
+		return rule, fmt.Errorf("%w: unknown day %q", ErrInvalidUnavailabilityRule, byDay)
+	}
+	rule.Weekday = weekday
+
+	ordinal := byDay[:len(byDay)-2]
+	switch rule.Frequency {
+	case RuleFrequencyWeekly:
+		if ordinal != "" {
+			return rule, fmt.Errorf("%w: a weekly rule takes a day without week number", ErrInvalidUnavailabilityRule)
+		}
+	case RuleFrequencyMonthly:
+		week, err := strconv.Atoi(ordinal)
+		if _, known := ruleOrdinals[week]; err != nil || !known {
+			return rule, fmt.Errorf("%w: a monthly rule takes a week number 1 to 5 or -1 before the day, as 1MO", ErrInvalidUnavailabilityRule)
+		}
+		rule.Week = week
+	}
+
+	if rule.Interval > 1 && rule.Start.IsZero() {
+		return rule, fmt.Errorf("%w: DTSTART is required with an INTERVAL", ErrInvalidUnavailabilityRule)
+	}
+	return rule, nil
+}
+
+// String returns the rule in its RRULE form, as parsed by ParseUnavailabilityRule
+func (r UnavailabilityRule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	day := strings.ToUpper(r.Weekday.String()[:2])
+	if r.Frequency == RuleFrequencyMonthly {
+		day = strconv.Itoa(r.Week) + day
+	}
+	parts = append(parts, "BYDAY="+day)
+	if !r.Start.IsZero() {
+		parts = append(parts, "DTSTART="+r.Start.Format(ruleDateFormat))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe returns the rule in plain English, as "Every 2 weeks on Friday from 2026-10-16"
+func (r UnavailabilityRule) Describe() string {
+	var description string
+	switch r.Frequency {
+	case RuleFrequencyMonthly:
+		description = fmt.Sprintf("The %s %s of every month", ruleOrdinals[r.Week], r.Weekday)
+		if r.Interval > 1 {
+			description = fmt.Sprintf("The %s %s of every %d months", ruleOrdinals[r.Week], r.Weekday, r.Interval)
+		}
+	default:
+		description = fmt.Sprintf("Every %s", r.Weekday)
+		if r.Interval > 1 {
+			description = fmt.Sprintf("Every %d weeks on %s", r.Interval, r.Weekday)
+		}
+	}
+	if !r.Start.IsZero() {
+		description += " from " + r.Start.Format(ruleDateFormat)
+	}
+	return description
+}
+
+// Matches reports whether the calendar day of date is one of the days of the rule
+func (r UnavailabilityRule) Matches(date time.Time) bool {
+	if date.Weekday() != r.Weekday {
+		return false
+	}
+	day := civilDay(date)
+	var start time.Time
+	if !r.Start.IsZero() {
+		start = civilDay(r.Start)
+		if day.Before(start) {
+			return false
+		}
+	}
+
+	switch r.Frequency {
+	case RuleFrequencyWeekly:
+		if r.Interval <= 1 {
+			return true
+		}
+		// Whole weeks between the Mondays of the weeks of the start and of the day
+		weeks := int(weekStart(day).Sub(weekStart(start)).Hours() / (24 * 7))
+		return weeks%r.Interval == 0
+	case RuleFrequencyMonthly:
+		if r.Week == -1 {
+			if day.AddDate(0, 0, 7).Month() == day.Month() {
+				return false
+			}
+		} else if (day.Day()-1)/7+1 != r.Week {
+			return false
+		}
+		if r.Interval <= 1 {
+			return true
+		}
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		return months%r.Interval == 0
+	}
+	return false
+}
+
+// civilDay returns the calendar day of date at midnight UTC, so that day arithmetic ignores the
+// daylight saving time changes of its location
+func civilDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// weekStart returns the Monday of the week of the civil day
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
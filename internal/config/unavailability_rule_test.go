@@ -0,0 +1,85 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnavailabilityRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		want        string // Canonical form, empty when the rule is invalid
+		description string
+	}{
+		{name: "every other friday", value: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16", description: "Every 2 weeks on Friday from 2026-10-16"},
+		{name: "first monday with prefix and lowercase", value: " rrule:freq=monthly;byday=1mo ", want: "FREQ=MONTHLY;BYDAY=1MO", description: "The first Monday of every month"},
+		{name: "last friday", value: "FREQ=MONTHLY;BYDAY=-1FR", want: "FREQ=MONTHLY;BYDAY=-1FR", description: "The last Friday of every month"},
+		{name: "every week", value: "FREQ=WEEKLY;BYDAY=SU", want: "FREQ=WEEKLY;BYDAY=SU", description: "Every Sunday"},
+		{name: "empty", value: "  "},
+		{name: "daily", value: "FREQ=DAILY;BYDAY=MO"},
+		{name: "missing day", value: "FREQ=WEEKLY"},
+		{name: "unknown day", value: "FREQ=WEEKLY;BYDAY=XX"},
+		{name: "weekly with week number", value: "FREQ=WEEKLY;BYDAY=2MO"},
+		{name: "monthly without week number", value: "FREQ=MONTHLY;BYDAY=MO"},
+		{name: "sixth week", value: "FREQ=MONTHLY;BYDAY=6MO"},
+		{name: "interval without start", value: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR"},
+		{name: "zero interval", value: "FREQ=WEEKLY;INTERVAL=0;BYDAY=FR"},
+		{name: "invalid start", value: "FREQ=WEEKLY;BYDAY=FR;DTSTART=16/10/2026"},
+		{name: "unsupported key", value: "FREQ=WEEKLY;BYDAY=FR;COUNT=3"},
+		{name: "not a pair", value: "FREQ=WEEKLY;FR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseUnavailabilityRule(tt.value)
+			if tt.want == "" {
+				assert.ErrorIs(t, err, ErrInvalidUnavailabilityRule)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule.String())
+			assert.Equal(t, tt.description, rule.Describe())
+		})
+	}
+}
+
+func TestUnavailabilityRule_Matches(t *testing.T) {
+	parse := func(value string) UnavailabilityRule {
+		rule, err := ParseUnavailabilityRule(value)
+		require.NoError(t, err)
+		return rule
+	}
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 21, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name string
+		rule string
+		date time.Time
+		want bool
+	}{
+		{name: "every other friday, start", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16", date: day(2026, time.October, 16), want: true},
+		{name: "every other friday, off week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16", date: day(2026, time.October, 23), want: false},
+		{name: "every other friday, across daylight saving", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16", date: day(2026, time.November, 13), want: true},
+		{name: "every other friday, before start", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16", date: day(2026, time.October, 2), want: false},
+		{name: "every other friday, start mid-week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-14", date: day(2026, time.October, 16), want: true},
+		{name: "every other friday, other day", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16", date: day(2026, time.October, 17), want: false},
+		{name: "first monday", rule: "FREQ=MONTHLY;BYDAY=1MO", date: day(2026, time.November, 2), want: true},
+		{name: "second monday", rule: "FREQ=MONTHLY;BYDAY=1MO", date: day(2026, time.November, 9), want: false},
+		{name: "last friday", rule: "FREQ=MONTHLY;BYDAY=-1FR", date: day(2026, time.October, 30), want: true},
+		{name: "fourth friday not last", rule: "FREQ=MONTHLY;BYDAY=-1FR", date: day(2026, time.October, 23), want: false},
+		{name: "first monday every 3 months", rule: "FREQ=MONTHLY;INTERVAL=3;BYDAY=1MO;DTSTART=2026-10-01", date: day(2027, time.January, 4), want: true},
+		{name: "first monday every 3 months, off month", rule: "FREQ=MONTHLY;INTERVAL=3;BYDAY=1MO;DTSTART=2026-10-01", date: day(2026, time.November, 2), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parse(tt.rule).Matches(tt.date))
+		})
+	}
+}
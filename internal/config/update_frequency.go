@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// UpdateFrequencyDisabled is the update frequency turning off the scheduled syncs
+const UpdateFrequencyDisabled = "disabled"
+
+// UpdateInterval returns the time between two scheduled syncs at the update frequency. It returns
+// false for an unknown frequency, and 0 for UpdateFrequencyDisabled.
+func UpdateInterval(frequency string) (time.Duration, bool) {
+	switch frequency {
+	case "daily":
+		return 24 * time.Hour, true
+	case "weekly":
+		return 7 * 24 * time.Hour, true
+	case "monthly":
+		return 30 * 24 * time.Hour, true // Approximation
+	case UpdateFrequencyDisabled:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
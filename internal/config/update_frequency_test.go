@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateInterval(t *testing.T) {
+	tests := []struct {
+		frequency string
+		want      time.Duration
+		wantOK    bool
+	}{
+		{frequency: "daily", want: 24 * time.Hour, wantOK: true},
+		{frequency: "weekly", want: 7 * 24 * time.Hour, wantOK: true},
+		{frequency: "monthly", want: 30 * 24 * time.Hour, wantOK: true},
+		{frequency: "disabled", want: 0, wantOK: true},
+		{frequency: "hourly", want: 0, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.frequency, func(t *testing.T) {
+			got, ok := UpdateInterval(tt.frequency)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+// vacationDateFormat is the format of the vacation dates, compared as local calendar days
+const vacationDateFormat = "2006-01-02"
+
+// Vacation is a date range during which the whole family is away and no night is scheduled
+type Vacation struct {
+	Enabled bool
+	Start   time.Time // First day of the vacation
+	End     time.Time // Last day of the vacation, included
+}
+
+// Contains reports whether the vacation is enabled and the calendar day of date falls within it
+func (v Vacation) Contains(date time.Time) bool {
+	if !v.Enabled {
+		return false
+	}
+	day := date.Format(vacationDateFormat)
+	return day >= v.Start.Format(vacationDateFormat) && day <= v.End.Format(vacationDateFormat)
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVacation_Contains(t *testing.T) {
+	vacation := Vacation{
+		Enabled: true,
+		Start:   time.Date(2026, time.July, 10, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2026, time.July, 20, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name     string
+		vacation Vacation
+		date     time.Time
+		want     bool
+	}{
+		{name: "first day", vacation: vacation, date: time.Date(2026, time.July, 10, 21, 0, 0, 0, time.UTC), want: true},
+		{name: "last day", vacation: vacation, date: time.Date(2026, time.July, 20, 23, 59, 0, 0, time.UTC), want: true},
+		{name: "day before", vacation: vacation, date: time.Date(2026, time.July, 9, 0, 0, 0, 0, time.UTC), want: false},
+		{name: "day after", vacation: vacation, date: time.Date(2026, time.July, 21, 0, 0, 0, 0, time.UTC), want: false},
+		{name: "disabled", vacation: Vacation{Start: vacation.Start, End: vacation.End}, date: time.Date(2026, time.July, 15, 0, 0, 0, 0, time.UTC), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.vacation.Contains(tt.date))
+		})
+	}
+}
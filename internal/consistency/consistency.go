@@ -0,0 +1,225 @@
+// Package consistency finds the nights of the look-ahead window left without assignment or calendar
+// event by a partially failed sync, and repairs them.
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// GapKind tells what a night of the look-ahead window is missing
+type GapKind string
+
+const (
+	// GapMissingAssignment is a night with night routine but without assignment
+	GapMissingAssignment GapKind = "missing_assignment"
+	// GapMissingEvent is an assignment without Google Calendar event
+	GapMissingEvent GapKind = "missing_event"
+)
+
+// String returns the gap kind name
+func (k GapKind) String() string {
+	return string(k)
+}
+
+// Gap is a night of the look-ahead window that is not fully scheduled
+type Gap struct {
+	Date time.Time
+	Kind GapKind
+}
+
+// AssignmentSource lists the assignments of a date range, implemented by fairness.Tracker
+type AssignmentSource interface {
+	GetAssignmentsInRange(ctx context.Context, start, end time.Time) ([]*fairness.Assignment, error)
+}
+
+// ScheduleSource reads the look-ahead window, the days without night routine and the nights kept out
+// of the calendar, implemented by config.ConfigStoreInterface
+type ScheduleSource interface {
+	GetSchedule() (updateFrequency string, lookAheadDays int, pastEventThresholdDays int, statsOrder constants.StatsOrder, err error)
+	GetVacation() (config.Vacation, error)
+	GetSkipDates() (config.SkipDates, error)
+	GetSyncExclusions() (config.SyncExclusions, error)
+}
+
+// Repairer regenerates and syncs the schedule, implemented in cmd/night-routine
+type Repairer interface {
+	// Ready reports whether the schedule can be synced, false while Google Calendar is disconnected
+	Ready() bool
+	// Repair regenerates the schedule of the look-ahead window and syncs it with the calendar
+	Repair(ctx context.Context) error
+}
+
+// Sender delivers a notification event, implemented by notify.Service
+type Sender interface {
+	Send(ctx context.Context, event notify.Event, data any) error
+}
+
+// Checker looks for gaps in the look-ahead window at a fixed interval and repairs them through a
+// sync. Repaired gaps are logged and reported through the notification channels; nothing is
+// reported when the schedule is complete.
+type Checker struct {
+	interval    time.Duration
+	assignments AssignmentSource
+	schedule    ScheduleSource
+	repairer    Repairer
+	sender      Sender           // nil to report through the logs only
+	now         func() time.Time // injectable for testing; defaults to time.Now
+	logger      zerolog.Logger
+}
+
+// NewChecker creates a checker running every interval. sender may be nil.
+func NewChecker(interval time.Duration, assignments AssignmentSource, schedule ScheduleSource, repairer Repairer, sender Sender) *Checker {
+	return &Checker{
+		interval:    interval,
+		assignments: assignments,
+		schedule:    schedule,
+		repairer:    repairer,
+		sender:      sender,
+		now:         time.Now,
+		logger:      logging.GetLogger("consistency"),
+	}
+}
+
+// Run checks and repairs the schedule every interval until ctx is cancelled. The first check
+// happens after one interval, leaving the startup sync time to complete.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.logger.Info().Dur("interval", c.interval).Msg("Schedule consistency checks enabled")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !c.repairer.Ready() {
+				c.logger.Debug().Msg("Schedule cannot be synced, skipping consistency check")
+				continue
+			}
+			if _, err := c.CheckAndRepair(ctx); err != nil {
+				c.logger.Error().Err(err).Msg("Schedule consistency check failed, retrying on the next check")
+			}
+		}
+	}
+}
+
+// FindGaps returns the nights from today to the end of the look-ahead window that have night routine
+// but no assignment, or an assignment without calendar event, in date order. The nights kept out of
+// the calendar by the sync exclusions have no event and are not gaps.
+func (c *Checker) FindGaps(ctx context.Context) ([]Gap, error) {
+	_, lookAheadDays, _, _, err := c.schedule.GetSchedule()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule configuration: %w", err)
+	}
+	vacation, err := c.schedule.GetVacation()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vacation: %w", err)
+	}
+	skipDates, err := c.schedule.GetSkipDates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skip dates: %w", err)
+	}
+	exclusions, err := c.schedule.GetSyncExclusions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync exclusions: %w", err)
+	}
+
+	now := c.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := today.AddDate(0, 0, lookAheadDays)
+	assignments, err := c.assignments.GetAssignmentsInRange(ctx, today, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	byDate := make(map[string]*fairness.Assignment, len(assignments))
+	for _, a := range assignments {
+		byDate[a.Date.Format(time.DateOnly)] = a
+	}
+
+	var gaps []Gap
+	for day := today; !day.After(end); day = day.AddDate(0, 0, 1) {
+		assignment, ok := byDate[day.Format(time.DateOnly)]
+		switch {
+		case !ok && !vacation.Contains(day) && !skipDates.Contains(day):
+			gaps = append(gaps, Gap{Date: day, Kind: GapMissingAssignment})
+		case ok && assignment.GoogleCalendarEventID == "" && !exclusions.Excludes(assignment.CaregiverType.String(), assignment.Tag.String()):
+			gaps = append(gaps, Gap{Date: day, Kind: GapMissingEvent})
+		}
+	}
+	return gaps, nil
+}
+
+// CheckAndRepair repairs the gaps of the look-ahead window, returning the gaps that were repaired.
+// The gaps still there after the repair are logged, and reported along with the repaired ones; nothing is
+// reported when no gap could be repaired, so that a night failing on every sync does not notify every check.
+func (c *Checker) CheckAndRepair(ctx context.Context) ([]Gap, error) {
+	gaps, err := c.FindGaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(gaps) == 0 {
+		c.logger.Debug().Msg("Schedule is consistent")
+		return nil, nil
+	}
+	c.logger.Warn().Int("gaps", len(gaps)).Strs("dates", gapDates(gaps, "")).Msg("Found gaps in the schedule, repairing")
+
+	if err := c.repairer.Repair(ctx); err != nil {
+		return nil, fmt.Errorf("failed to repair the schedule: %w", err)
+	}
+
+	remaining, err := c.FindGaps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the repaired schedule: %w", err)
+	}
+	stillMissing := make(map[string]bool, len(remaining))
+	for _, gap := range remaining {
+		stillMissing[gap.Date.Format(time.DateOnly)] = true
+	}
+	var repaired []Gap
+	for _, gap := range gaps {
+		if !stillMissing[gap.Date.Format(time.DateOnly)] {
+			repaired = append(repaired, gap)
+		}
+	}
+
+	c.logger.Info().
+		Strs("missing_assignments", gapDates(repaired, GapMissingAssignment)).
+		Strs("missing_events", gapDates(repaired, GapMissingEvent)).
+		Int("remaining", len(remaining)).
+		Msg("Repaired gaps in the schedule")
+	if len(remaining) > 0 {
+		c.logger.Warn().Strs("dates", gapDates(remaining, "")).Msg("Gaps remain in the schedule after repair")
+	}
+
+	if c.sender != nil && len(repaired) > 0 {
+		data := notify.ScheduleRepairedData{
+			MissingAssignments: gapDates(repaired, GapMissingAssignment),
+			MissingEvents:      gapDates(repaired, GapMissingEvent),
+			Remaining:          gapDates(remaining, ""),
+		}
+		if err := c.sender.Send(ctx, notify.EventScheduleRepaired, data); err != nil {
+			c.logger.Error().Err(err).Msg("Failed to report the schedule repair")
+		}
+	}
+	return repaired, nil
+}
+
+// gapDates returns the dates (YYYY-MM-DD) of the gaps of kind, or of every gap when kind is empty
+func gapDates(gaps []Gap, kind GapKind) []string {
+	var dates []string
+	for _, gap := range gaps {
+		if kind == "" || gap.Kind == kind {
+			dates = append(dates, gap.Date.Format(time.DateOnly))
+		}
+	}
+	return dates
+}
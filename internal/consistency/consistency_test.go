@@ -0,0 +1,187 @@
+package consistency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var today = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// fakeSchedule stores the assignments by date and repairs by assigning the dates in repairs
+type fakeSchedule struct {
+	lookAheadDays int
+	vacation      config.Vacation
+	skipDates     config.SkipDates
+	exclusions    config.SyncExclusions
+	assignments   map[string]*fairness.Assignment
+	repairs       map[string]*fairness.Assignment // Applied by Repair
+	repairErr     error
+	repairCount   int
+}
+
+func (f *fakeSchedule) GetSchedule() (string, int, int, constants.StatsOrder, error) {
+	return "daily", f.lookAheadDays, 5, constants.StatsOrderDesc, nil
+}
+
+func (f *fakeSchedule) GetVacation() (config.Vacation, error) {
+	return f.vacation, nil
+}
+
+func (f *fakeSchedule) GetSkipDates() (config.SkipDates, error) {
+	return f.skipDates, nil
+}
+
+func (f *fakeSchedule) GetSyncExclusions() (config.SyncExclusions, error) {
+	return f.exclusions, nil
+}
+
+func (f *fakeSchedule) GetAssignmentsInRange(_ context.Context, start, end time.Time) ([]*fairness.Assignment, error) {
+	var assignments []*fairness.Assignment
+	for _, a := range f.assignments {
+		if !a.Date.Before(start) && !a.Date.After(end) {
+			assignments = append(assignments, a)
+		}
+	}
+	return assignments, nil
+}
+
+func (f *fakeSchedule) Ready() bool {
+	return true
+}
+
+func (f *fakeSchedule) Repair(context.Context) error {
+	f.repairCount++
+	if f.repairErr != nil {
+		return f.repairErr
+	}
+	for date, a := range f.repairs {
+		f.assignments[date] = a
+	}
+	return nil
+}
+
+type recordingSender struct {
+	data []any
+}
+
+func (s *recordingSender) Send(_ context.Context, event notify.Event, data any) error {
+	if event != notify.EventScheduleRepaired {
+		return errors.New("unexpected event")
+	}
+	s.data = append(s.data, data)
+	return nil
+}
+
+// newFakeSchedule returns a schedule of three days after today, fully synced
+func newFakeSchedule() *fakeSchedule {
+	f := &fakeSchedule{lookAheadDays: 3, assignments: map[string]*fairness.Assignment{}, repairs: map[string]*fairness.Assignment{}}
+	for i := 0; i <= 3; i++ {
+		day := today.AddDate(0, 0, i)
+		f.assignments[day.Format(time.DateOnly)] = &fairness.Assignment{ID: int64(i + 1), Date: day, Parent: "Alice", GoogleCalendarEventID: "event"}
+	}
+	return f
+}
+
+func newTestChecker(f *fakeSchedule, sender Sender) *Checker {
+	checker := NewChecker(time.Hour, f, f, f, sender)
+	checker.now = func() time.Time { return today.Add(20 * time.Hour) }
+	return checker
+}
+
+func TestFindGaps(t *testing.T) {
+	f := newFakeSchedule()
+	delete(f.assignments, "2026-10-17")
+	f.assignments["2026-10-18"].GoogleCalendarEventID = ""
+	delete(f.assignments, "2026-10-19")
+	f.skipDates = config.SkipDates{{Date: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)}}
+
+	gaps, err := newTestChecker(f, nil).FindGaps(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Gap{
+		{Date: time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC), Kind: GapMissingAssignment},
+		{Date: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC), Kind: GapMissingEvent},
+	}, gaps, "the skip date is not a gap")
+}
+
+func TestFindGaps_ExcludedNights(t *testing.T) {
+	f := newFakeSchedule()
+	babysitter := f.assignments["2026-10-17"]
+	babysitter.CaregiverType, babysitter.Parent, babysitter.GoogleCalendarEventID = fairness.CaregiverTypeBabysitter, "Carol", ""
+	tagged := f.assignments["2026-10-18"]
+	tagged.Override, tagged.Tag, tagged.GoogleCalendarEventID = true, fairness.AssignmentTagSickKid, ""
+	f.exclusions = config.SyncExclusions{Babysitter: true}
+
+	gaps, err := newTestChecker(f, nil).FindGaps(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Gap{
+		{Date: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC), Kind: GapMissingEvent},
+	}, gaps, "the babysitter night kept out of the calendar is not a gap")
+
+	f.exclusions.Tags = []string{fairness.AssignmentTagSickKid.String()}
+	gaps, err = newTestChecker(f, nil).FindGaps(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, gaps)
+}
+
+func TestCheckAndRepair_Consistent(t *testing.T) {
+	f := newFakeSchedule()
+	sender := &recordingSender{}
+
+	repaired, err := newTestChecker(f, sender).CheckAndRepair(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, repaired)
+	assert.Zero(t, f.repairCount, "nothing to repair")
+	assert.Empty(t, sender.data)
+}
+
+func TestCheckAndRepair_Repairs(t *testing.T) {
+	f := newFakeSchedule()
+	delete(f.assignments, "2026-10-17")
+	f.assignments["2026-10-18"].GoogleCalendarEventID = ""
+	f.assignments["2026-10-19"].GoogleCalendarEventID = ""
+	f.repairs["2026-10-17"] = &fairness.Assignment{ID: 10, Date: today.AddDate(0, 0, 1), Parent: "Bob", GoogleCalendarEventID: "event"}
+	f.repairs["2026-10-18"] = &fairness.Assignment{ID: 3, Date: today.AddDate(0, 0, 2), Parent: "Alice", GoogleCalendarEventID: "event"}
+	sender := &recordingSender{}
+
+	repaired, err := newTestChecker(f, sender).CheckAndRepair(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, repaired, 2)
+	assert.Equal(t, 1, f.repairCount)
+	require.Len(t, sender.data, 1)
+	assert.Equal(t, notify.ScheduleRepairedData{
+		MissingAssignments: []string{"2026-10-17"},
+		MissingEvents:      []string{"2026-10-18"},
+		Remaining:          []string{"2026-10-19"},
+	}, sender.data[0])
+}
+
+func TestCheckAndRepair_NothingRepaired(t *testing.T) {
+	f := newFakeSchedule()
+	f.assignments["2026-10-18"].GoogleCalendarEventID = ""
+	sender := &recordingSender{}
+
+	repaired, err := newTestChecker(f, sender).CheckAndRepair(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, repaired)
+	assert.Empty(t, sender.data, "a gap failing on every sync is not reported again and again")
+}
+
+func TestCheckAndRepair_RepairFails(t *testing.T) {
+	f := newFakeSchedule()
+	delete(f.assignments, "2026-10-17")
+	f.repairErr = errors.New("calendar unavailable")
+	sender := &recordingSender{}
+
+	_, err := newTestChecker(f, sender).CheckAndRepair(context.Background())
+	assert.ErrorIs(t, err, f.repairErr)
+	assert.Empty(t, sender.data)
+}
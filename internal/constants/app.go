@@ -1,5 +1,9 @@
 // Package constants provides shared constants for the night-routine application
 package constants
 
-// NightRoutineIdentifier is the unique identifier used to mark events created by this application
+// NightRoutineIdentifier is the default identifier used to mark events created by this application,
+// set by branding.event_identifier
 const NightRoutineIdentifier = "Night Routine"
+
+// DefaultEventEmoji is the default emoji of the event titles, set by branding.event_emoji
+const DefaultEventEmoji = "🌃👶"
@@ -0,0 +1,102 @@
+// Package constants provides shared constants for the night-routine application
+package constants
+
+import "fmt"
+
+// ParentColor identifies a parent on the web interface, in the API and on the Google Calendar
+// events. The colors are the event colors of Google Calendar, so that the calendar shows the same one.
+type ParentColor string
+
+const (
+	ParentColorLavender  ParentColor = "lavender"
+	ParentColorSage      ParentColor = "sage"
+	ParentColorGrape     ParentColor = "grape"
+	ParentColorFlamingo  ParentColor = "flamingo"
+	ParentColorBanana    ParentColor = "banana"
+	ParentColorTangerine ParentColor = "tangerine"
+	ParentColorPeacock   ParentColor = "peacock"
+	ParentColorGraphite  ParentColor = "graphite"
+	ParentColorBlueberry ParentColor = "blueberry"
+	ParentColorBasil     ParentColor = "basil"
+	ParentColorTomato    ParentColor = "tomato"
+)
+
+// Default colors of the parents, the blue and orange of the home page
+const (
+	DefaultParentAColor = ParentColorBlueberry
+	DefaultParentBColor = ParentColorTangerine
+)
+
+// DefaultExtraParentColors are the default colors of the parents taking turns after parent A and
+// parent B, in the order of the roster
+var DefaultExtraParentColors = []ParentColor{ParentColorBasil, ParentColorGrape, ParentColorFlamingo, ParentColorPeacock}
+
+// parentColorPalette holds the Google Calendar event color ID and the RGB value of each color, in
+// the order of the IDs
+var parentColorPalette = []struct {
+	color   ParentColor
+	colorID string
+	hex     string
+}{
+	{ParentColorLavender, "1", "#7986cb"},
+	{ParentColorSage, "2", "#33b679"},
+	{ParentColorGrape, "3", "#8e24aa"},
+	{ParentColorFlamingo, "4", "#e67c73"},
+	{ParentColorBanana, "5", "#f6bf26"},
+	{ParentColorTangerine, "6", "#f4511e"},
+	{ParentColorPeacock, "7", "#039be5"},
+	{ParentColorGraphite, "8", "#616161"},
+	{ParentColorBlueberry, "9", "#3f51b5"},
+	{ParentColorBasil, "10", "#0b8043"},
+	{ParentColorTomato, "11", "#d50000"},
+}
+
+// IsValid checks if the parent color is one of the palette
+func (c ParentColor) IsValid() bool {
+	return c.GoogleColorID() != ""
+}
+
+// String returns the string representation of the parent color
+func (c ParentColor) String() string {
+	return string(c)
+}
+
+// GoogleColorID returns the event color ID of the color in Google Calendar, empty when invalid
+func (c ParentColor) GoogleColorID() string {
+	for _, entry := range parentColorPalette {
+		if entry.color == c {
+			return entry.colorID
+		}
+	}
+	return ""
+}
+
+// Hex returns the RGB value of the color as #rrggbb, empty when invalid
+func (c ParentColor) Hex() string {
+	for _, entry := range parentColorPalette {
+		if entry.color == c {
+			return entry.hex
+		}
+	}
+	return ""
+}
+
+// ParseParentColor parses a string into a ParentColor type
+// Returns an error if the value is invalid
+func ParseParentColor(s string) (ParentColor, error) {
+	color := ParentColor(s)
+	if !color.IsValid() {
+		return "", fmt.Errorf("invalid parent color: %s", s)
+	}
+	return color, nil
+}
+
+// GetAllParentColors returns all valid parent colors
+// This provides a consistent list for UI components
+func GetAllParentColors() []ParentColor {
+	colors := make([]ParentColor, len(parentColorPalette))
+	for i, entry := range parentColorPalette {
+		colors[i] = entry.color
+	}
+	return colors
+}
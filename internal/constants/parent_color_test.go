@@ -0,0 +1,36 @@
+package constants
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParentColor_Palette(t *testing.T) {
+	colors := GetAllParentColors()
+	require.Len(t, colors, 11)
+	for i, color := range colors {
+		assert.True(t, color.IsValid(), color)
+		assert.Equal(t, strconv.Itoa(i+1), color.GoogleColorID(), "color IDs follow the palette order")
+		assert.Regexp(t, `^#[0-9a-f]{6}$`, color.Hex())
+	}
+
+	assert.Equal(t, "9", DefaultParentAColor.GoogleColorID())
+	assert.Equal(t, "6", DefaultParentBColor.GoogleColorID())
+}
+
+func TestParseParentColor(t *testing.T) {
+	color, err := ParseParentColor("basil")
+	require.NoError(t, err)
+	assert.Equal(t, ParentColorBasil, color)
+	assert.Equal(t, "#0b8043", color.Hex())
+
+	for _, value := range []string{"", "Basil", "#0b8043", "10"} {
+		_, err := ParseParentColor(value)
+		assert.Error(t, err, value)
+		assert.False(t, ParentColor(value).IsValid(), value)
+		assert.Empty(t, ParentColor(value).Hex(), value)
+	}
+}
@@ -0,0 +1,9 @@
+package constants
+
+// Defaults of the schedule settings, used when the configuration file leaves them out and offered by
+// the setup wizard
+const (
+	DefaultUpdateFrequency        = "weekly"
+	DefaultLookAheadDays          = 30
+	DefaultPastEventThresholdDays = 5
+)
@@ -0,0 +1,34 @@
+package constants
+
+// SyncTrigger identifies what started a schedule sync
+type SyncTrigger string
+
+const (
+	// SyncTriggerScheduled is the periodic sync following the update frequency
+	SyncTriggerScheduled SyncTrigger = "scheduled"
+	// SyncTriggerStartup is the sync performed when the server starts
+	SyncTriggerStartup SyncTrigger = "startup"
+	// SyncTriggerManual is a sync requested from the web interface
+	SyncTriggerManual SyncTrigger = "manual"
+	// SyncTriggerSettings is the sync following a settings change
+	SyncTriggerSettings SyncTrigger = "settings"
+	// SyncTriggerCalendarSelected is the sync following the selection of a calendar
+	SyncTriggerCalendarSelected SyncTrigger = "calendar_selected"
+	// SyncTriggerWebhook is the resync following an event edited in Google Calendar
+	SyncTriggerWebhook SyncTrigger = "webhook"
+	// SyncTriggerAssignment is the resync following an assignment edited or unlocked in the web interface
+	SyncTriggerAssignment SyncTrigger = "assignment"
+	// SyncTriggerCLI is a sync run from the command line with 'sync --once'
+	SyncTriggerCLI SyncTrigger = "cli"
+	// SyncTriggerUndo is the resync following the undo of the last schedule change
+	SyncTriggerUndo SyncTrigger = "undo"
+	// SyncTriggerRepair is the resync repairing nights found without assignment or calendar event
+	SyncTriggerRepair SyncTrigger = "repair"
+	// SyncTriggerPublish is the sync publishing the regenerated nights staged for review
+	SyncTriggerPublish SyncTrigger = "publish"
+)
+
+// String returns the string representation of the sync trigger
+func (t SyncTrigger) String() string {
+	return string(t)
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// Acknowledgement is the parent on duty confirming they handle the night of an assignment
+type Acknowledgement struct {
+	AssignmentID   int64
+	Parent         string
+	AcknowledgedAt time.Time
+}
+
+// AcknowledgementStore stores the acknowledgements of the nights (assignment_acknowledgements table).
+// An acknowledgement counts while its parent is still on duty that night: it is ignored once the
+// night is claimed, swapped or given to a babysitter.
+type AcknowledgementStore struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewAcknowledgementStore creates a new acknowledgement store
+func NewAcknowledgementStore(db *DB) (*AcknowledgementStore, error) {
+	logger := logging.GetLogger("acknowledgement-store")
+	return &AcknowledgementStore{db: db, logger: logger}, nil
+}
+
+// Acknowledge records that parent handles the night of the assignment at now and returns the
+// acknowledgement. Acknowledging again keeps the time of the first acknowledgement of parent.
+func (s *AcknowledgementStore) Acknowledge(assignmentID int64, parent string, now time.Time) (*Acknowledgement, error) {
+	s.logger.Debug().Int64("assignment_id", assignmentID).Str("parent", parent).Msg("Acknowledging assignment")
+	if _, err := s.db.ExecContext(context.Background(), `
+	INSERT INTO assignment_acknowledgements (assignment_id, parent_name, acknowledged_at) VALUES (?, ?, ?)
+	ON CONFLICT(assignment_id) DO UPDATE SET parent_name = excluded.parent_name, acknowledged_at = excluded.acknowledged_at
+	WHERE assignment_acknowledgements.parent_name != excluded.parent_name`,
+		assignmentID, parent, now.UTC().Format(time.RFC3339)); err != nil {
+		return nil, fmt.Errorf("failed to acknowledge assignment %d: %w", assignmentID, err)
+	}
+
+	acknowledgement := Acknowledgement{AssignmentID: assignmentID}
+	var acknowledgedAt string
+	if err := s.db.Conn().QueryRow(`
+	SELECT parent_name, acknowledged_at FROM assignment_acknowledgements WHERE assignment_id = ?`, assignmentID).
+		Scan(&acknowledgement.Parent, &acknowledgedAt); err != nil {
+		return nil, fmt.Errorf("failed to read acknowledgement of assignment %d: %w", assignmentID, err)
+	}
+	var err error
+	if acknowledgement.AcknowledgedAt, err = time.Parse(time.RFC3339, acknowledgedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse acknowledgement time: %w", err)
+	}
+	return &acknowledgement, nil
+}
+
+// GetAcknowledgement returns the acknowledgement of the assignment, nil when the night is not
+// acknowledged by the parent on duty
+func (s *AcknowledgementStore) GetAcknowledgement(assignmentID int64) (*Acknowledgement, error) {
+	acknowledgement := Acknowledgement{AssignmentID: assignmentID}
+	var acknowledgedAt string
+	err := s.db.Conn().QueryRow(`
+	SELECT k.parent_name, k.acknowledged_at
+	FROM assignment_acknowledgements k
+	JOIN assignments a ON a.id = k.assignment_id AND a.parent_name = k.parent_name
+	WHERE k.assignment_id = ?`, assignmentID).Scan(&acknowledgement.Parent, &acknowledgedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query acknowledgement of assignment %d: %w", assignmentID, err)
+	}
+	if acknowledgement.AcknowledgedAt, err = time.Parse(time.RFC3339, acknowledgedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse acknowledgement time: %w", err)
+	}
+	return &acknowledgement, nil
+}
+
+// GetAcknowledgements returns when each assignment between start and end, inclusive, was
+// acknowledged by the parent on duty. Nights not acknowledged by their parent are missing from the map.
+func (s *AcknowledgementStore) GetAcknowledgements(start, end time.Time) (map[int64]time.Time, error) {
+	s.logger.Debug().Str("start", start.Format("2006-01-02")).Str("end", end.Format("2006-01-02")).Msg("Fetching acknowledgements")
+	rows, err := s.db.Conn().Query(`
+	SELECT k.assignment_id, k.acknowledged_at
+	FROM assignment_acknowledgements k
+	JOIN assignments a ON a.id = k.assignment_id AND a.parent_name = k.parent_name
+	WHERE a.assignment_date BETWEEN ? AND ?`, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query acknowledgements: %w", err)
+	}
+	defer rows.Close()
+
+	acknowledgements := make(map[int64]time.Time)
+	for rows.Next() {
+		var assignmentID int64
+		var acknowledgedAt string
+		if err := rows.Scan(&assignmentID, &acknowledgedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan acknowledgement: %w", err)
+		}
+		t, err := time.Parse(time.RFC3339, acknowledgedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse acknowledgement time: %w", err)
+		}
+		acknowledgements[assignmentID] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate acknowledgements: %w", err)
+	}
+	return acknowledgements, nil
+}
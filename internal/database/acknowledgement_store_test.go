@@ -0,0 +1,67 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcknowledgementStore(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_acknowledgements.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	insert := func(parent, date string) int64 {
+		result, err := db.Conn().Exec(`INSERT INTO assignments (parent_name, assignment_date, override, decision_reason) VALUES (?, ?, 0, 'Alternating')`, parent, date)
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+		return id
+	}
+	friday := insert("Alice", "2026-10-16")
+	saturday := insert("Bob", "2026-10-17")
+	sunday := insert("Alice", "2026-10-18")
+
+	store, err := NewAcknowledgementStore(db)
+	require.NoError(t, err)
+
+	acknowledgement, err := store.GetAcknowledgement(friday)
+	require.NoError(t, err)
+	assert.Nil(t, acknowledgement)
+
+	now := time.Date(2026, 10, 16, 19, 30, 0, 0, time.UTC)
+	acknowledgement, err = store.Acknowledge(friday, "Alice", now)
+	require.NoError(t, err)
+	assert.Equal(t, &Acknowledgement{AssignmentID: friday, Parent: "Alice", AcknowledgedAt: now}, acknowledgement)
+
+	// Acknowledging again keeps the first time
+	acknowledgement, err = store.Acknowledge(friday, "Alice", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, now, acknowledgement.AcknowledgedAt)
+
+	_, err = store.Acknowledge(saturday, "Bob", now)
+	require.NoError(t, err)
+	_, err = store.Acknowledge(sunday, "Alice", now)
+	require.NoError(t, err)
+
+	// Sunday is given to Bob after Alice acknowledged it
+	_, err = db.Conn().Exec(`UPDATE assignments SET parent_name = 'Bob' WHERE id = ?`, sunday)
+	require.NoError(t, err)
+	acknowledgement, err = store.GetAcknowledgement(sunday)
+	require.NoError(t, err)
+	assert.Nil(t, acknowledgement, "the acknowledgement of a parent no longer on duty does not count")
+
+	acknowledgements, err := store.GetAcknowledgements(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]time.Time{friday: now, saturday: now}, acknowledgements)
+
+	// Bob acknowledging Sunday replaces the acknowledgement of Alice
+	acknowledgement, err = store.Acknowledge(sunday, "Bob", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", acknowledgement.Parent)
+	assert.Equal(t, now.Add(time.Hour), acknowledgement.AcknowledgedAt)
+}
@@ -0,0 +1,357 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// Bounds of a checklist
+const (
+	MaxChecklistItems      = 20
+	MaxChecklistLabelRunes = 80
+)
+
+// ErrInvalidChecklist is returned for a checklist exceeding the bounds
+var ErrInvalidChecklist = errors.New("invalid checklist")
+
+// ErrChecklistItemNotFound is returned when marking an item missing from the checklist
+var ErrChecklistItemNotFound = errors.New("checklist item not found")
+
+// ChecklistItem is an item of the bedtime checklist of an assignment
+type ChecklistItem struct {
+	Label       string
+	CompletedAt *time.Time // nil while the item is not done
+}
+
+// Done reports whether the item was completed
+func (i *ChecklistItem) Done() bool {
+	return i.CompletedAt != nil
+}
+
+// NormalizeChecklist trims the labels and drops the empty ones and the duplicates, keeping the
+// order. It fails with ErrInvalidChecklist when a label or the checklist is too long.
+func NormalizeChecklist(labels []string) ([]string, error) {
+	normalized := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[label] {
+			continue
+		}
+		if utf8.RuneCountInString(label) > MaxChecklistLabelRunes {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidChecklist, label, MaxChecklistLabelRunes)
+		}
+		seen[label] = true
+		normalized = append(normalized, label)
+	}
+	if len(normalized) > MaxChecklistItems {
+		return nil, fmt.Errorf("%w: more than %d items", ErrInvalidChecklist, MaxChecklistItems)
+	}
+	return normalized, nil
+}
+
+// rowsQuerier runs queries on a connection or within a transaction
+type rowsQuerier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// queryChecklistTemplate returns the labels of the checklist template in order
+func queryChecklistTemplate(q rowsQuerier) ([]string, error) {
+	rows, err := q.Query(`SELECT label FROM config_checklist_items ORDER BY position, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checklist template: %w", err)
+	}
+	defer rows.Close()
+
+	labels := []string{}
+	for rows.Next() {
+		var label string
+		if err := rows.Scan(&label); err != nil {
+			return nil, fmt.Errorf("failed to scan checklist template item: %w", err)
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate checklist template: %w", err)
+	}
+	return labels, nil
+}
+
+// queryWeekdayChecklists returns the items added to the checklist template on each weekday, in order
+func queryWeekdayChecklists(q rowsQuerier) (map[time.Weekday][]string, error) {
+	rows, err := q.Query(`SELECT weekday, label FROM config_weekday_checklist_items ORDER BY weekday, position, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query weekday checklists: %w", err)
+	}
+	defer rows.Close()
+
+	checklists := make(map[time.Weekday][]string)
+	for rows.Next() {
+		var weekday time.Weekday
+		var label string
+		if err := rows.Scan(&weekday, &label); err != nil {
+			return nil, fmt.Errorf("failed to scan weekday checklist item: %w", err)
+		}
+		checklists[weekday] = append(checklists[weekday], label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate weekday checklists: %w", err)
+	}
+	return checklists, nil
+}
+
+// queryAssignmentTemplate returns the checklist template followed by the items of the weekday of the
+// assignment, without the items already in the template
+func queryAssignmentTemplate(q rowsQuerier, assignmentID int64) ([]string, error) {
+	labels, err := queryChecklistTemplate(q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.Query(`
+	SELECT w.label
+	FROM config_weekday_checklist_items w
+	JOIN assignments a ON a.id = ? AND w.weekday = CAST(strftime('%w', a.assignment_date) AS INTEGER)
+	ORDER BY w.position, w.id`, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query weekday checklist of assignment: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var label string
+		if err := rows.Scan(&label); err != nil {
+			return nil, fmt.Errorf("failed to scan weekday checklist item: %w", err)
+		}
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate weekday checklist of assignment: %w", err)
+	}
+	return labels, nil
+}
+
+// ChecklistStore stores the bedtime checklist of each assignment and the completion of its items.
+// An assignment follows the checklist template, with the items of its weekday, until its checklist
+// is edited or an item is marked, at which point the template is copied to the assignment.
+type ChecklistStore struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewChecklistStore creates a new checklist store
+func NewChecklistStore(db *DB) (*ChecklistStore, error) {
+	logger := logging.GetLogger("checklist-store")
+	return &ChecklistStore{db: db, logger: logger}, nil
+}
+
+// GetChecklist returns the checklist of the assignment in order, the template with the items of its
+// weekday when it was never edited
+func (s *ChecklistStore) GetChecklist(assignmentID int64) ([]*ChecklistItem, error) {
+	s.logger.Debug().Int64("assignment_id", assignmentID).Msg("Fetching assignment checklist")
+	return s.queryChecklist(s.db.Conn(), assignmentID)
+}
+
+// SaveChecklist replaces the checklist of the assignment; labels are normalized with NormalizeChecklist.
+// Items kept from the previous checklist keep their completion. An empty checklist stays empty.
+func (s *ChecklistStore) SaveChecklist(assignmentID int64, labels []string) error {
+	labels, err := NormalizeChecklist(labels)
+	if err != nil {
+		return err
+	}
+	s.logger.Debug().Int64("assignment_id", assignmentID).Int("item_count", len(labels)).Msg("Saving assignment checklist")
+
+	return s.db.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		if err := markEdited(tx, assignmentID); err != nil {
+			return err
+		}
+		query := `DELETE FROM assignment_checklist_items WHERE assignment_id = ?`
+		args := []any{assignmentID}
+		if len(labels) > 0 {
+			query += ` AND label NOT IN (` + strings.TrimSuffix(strings.Repeat("?,", len(labels)), ",") + `)`
+			for _, label := range labels {
+				args = append(args, label)
+			}
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to remove checklist items: %w", err)
+		}
+		return upsertChecklistItems(tx, assignmentID, labels)
+	})
+}
+
+// SetItemDone marks the item of the assignment checklist labelled label as done or not done,
+// copying the template first when the checklist was never edited. An item already done keeps
+// its completion time.
+func (s *ChecklistStore) SetItemDone(assignmentID int64, label string, done bool) error {
+	s.logger.Debug().Int64("assignment_id", assignmentID).Str("label", label).Bool("done", done).Msg("Marking checklist item")
+
+	return s.db.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		edited, err := isEdited(tx, assignmentID)
+		if err != nil {
+			return err
+		}
+		if !edited {
+			labels, err := queryAssignmentTemplate(tx, assignmentID)
+			if err != nil {
+				return err
+			}
+			if err := markEdited(tx, assignmentID); err != nil {
+				return err
+			}
+			if err := upsertChecklistItems(tx, assignmentID, labels); err != nil {
+				return err
+			}
+		}
+
+		query := `UPDATE assignment_checklist_items SET completed_at = NULL WHERE assignment_id = ? AND label = ?`
+		args := []any{assignmentID, label}
+		if done {
+			query = `UPDATE assignment_checklist_items SET completed_at = COALESCE(completed_at, ?) WHERE assignment_id = ? AND label = ?`
+			args = append([]any{time.Now().UTC().Format(time.RFC3339)}, args...)
+		}
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to mark checklist item: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check marked checklist item: %w", err)
+		}
+		if affected == 0 {
+			return fmt.Errorf("%w: %s", ErrChecklistItemNotFound, label)
+		}
+		return nil
+	})
+}
+
+// GetChecklistCompletions returns when the checklist of each assignment between start and end,
+// inclusive, was fully ticked: the completion time of its last item. Assignments whose checklist
+// is empty, untouched or not fully ticked are missing from the map.
+func (s *ChecklistStore) GetChecklistCompletions(start, end time.Time) (map[int64]time.Time, error) {
+	s.logger.Debug().Str("start", start.Format("2006-01-02")).Str("end", end.Format("2006-01-02")).Msg("Fetching checklist completions")
+	rows, err := s.db.Conn().Query(`
+	SELECT i.assignment_id, MAX(i.completed_at)
+	FROM assignment_checklist_items i
+	JOIN assignments a ON a.id = i.assignment_id
+	WHERE a.assignment_date BETWEEN ? AND ?
+	GROUP BY i.assignment_id
+	HAVING COUNT(*) = COUNT(i.completed_at)`, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checklist completions: %w", err)
+	}
+	defer rows.Close()
+
+	completions := make(map[int64]time.Time)
+	for rows.Next() {
+		var assignmentID int64
+		var completedAt string
+		if err := rows.Scan(&assignmentID, &completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan checklist completion: %w", err)
+		}
+		t, err := time.Parse(time.RFC3339, completedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse checklist completion time: %w", err)
+		}
+		completions[assignmentID] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate checklist completions: %w", err)
+	}
+	return completions, nil
+}
+
+// isEdited reports whether the checklist of the assignment was edited
+func isEdited(q rowsQuerier, assignmentID int64) (bool, error) {
+	rows, err := q.Query(`SELECT 1 FROM assignment_checklists WHERE assignment_id = ?`, assignmentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check assignment checklist: %w", err)
+	}
+	defer rows.Close()
+	edited := rows.Next()
+	return edited, rows.Err()
+}
+
+// markEdited records that the checklist of the assignment no longer follows the template
+func markEdited(tx *sql.Tx, assignmentID int64) error {
+	if _, err := tx.Exec(`
+	INSERT INTO assignment_checklists (assignment_id) VALUES (?)
+	ON CONFLICT(assignment_id) DO UPDATE SET edited_at = CURRENT_TIMESTAMP`, assignmentID); err != nil {
+		return fmt.Errorf("failed to save assignment checklist: %w", err)
+	}
+	return nil
+}
+
+// upsertChecklistItems inserts the missing labels and sets the position of every label
+func upsertChecklistItems(tx *sql.Tx, assignmentID int64, labels []string) error {
+	for position, label := range labels {
+		if _, err := tx.Exec(`
+		INSERT INTO assignment_checklist_items (assignment_id, label, position)
+		VALUES (?, ?, ?)
+		ON CONFLICT(assignment_id, label) DO UPDATE SET position = excluded.position`, assignmentID, label, position); err != nil {
+			return fmt.Errorf("failed to save checklist item %s: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// queryChecklist returns the checklist of the assignment, the template with the items of its weekday
+// when it was never edited
+func (s *ChecklistStore) queryChecklist(q rowsQuerier, assignmentID int64) ([]*ChecklistItem, error) {
+	edited, err := isEdited(q, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if !edited {
+		labels, err := queryAssignmentTemplate(q, assignmentID)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]*ChecklistItem, 0, len(labels))
+		for _, label := range labels {
+			items = append(items, &ChecklistItem{Label: label})
+		}
+		return items, nil
+	}
+
+	rows, err := q.Query(`
+	SELECT label, completed_at
+	FROM assignment_checklist_items
+	WHERE assignment_id = ?
+	ORDER BY position, id`, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assignment checklist: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*ChecklistItem{}
+	for rows.Next() {
+		var item ChecklistItem
+		var completedAt sql.NullString
+		if err := rows.Scan(&item.Label, &completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan checklist item: %w", err)
+		}
+		if completedAt.Valid {
+			t, err := time.Parse(time.RFC3339, completedAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse checklist item completion time: %w", err)
+			}
+			item.CompletedAt = &t
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate assignment checklist: %w", err)
+	}
+	return items, nil
+}
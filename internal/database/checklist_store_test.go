@@ -0,0 +1,168 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestChecklistStore(t *testing.T) (*ChecklistStore, *ConfigStore, int64) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_checklists.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	result, err := db.Conn().Exec(`INSERT INTO assignments (parent_name, assignment_date, override, decision_reason) VALUES ('Alice', '2026-10-16', 0, 'Alternating')`)
+	require.NoError(t, err)
+	assignmentID, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	store, err := NewChecklistStore(db)
+	require.NoError(t, err, "Failed to create checklist store")
+	configStore, err := NewConfigStore(db)
+	require.NoError(t, err, "Failed to create config store")
+	return store, configStore, assignmentID
+}
+
+// checklistLabels returns the labels of items, with a "+" suffix for the done ones
+func checklistLabels(items []*ChecklistItem) []string {
+	labels := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Done() {
+			labels = append(labels, item.Label+"+")
+		} else {
+			labels = append(labels, item.Label)
+		}
+	}
+	return labels
+}
+
+func TestChecklistStore_FollowsTemplateUntilEdited(t *testing.T) {
+	store, configStore, assignmentID := setupTestChecklistStore(t)
+
+	items, err := store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	require.NoError(t, configStore.SaveChecklistTemplate(t.Context(), []string{"Bath", "Bottle", "Story"}))
+	items, err = store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bath", "Bottle", "Story"}, checklistLabels(items))
+
+	require.NoError(t, store.SetItemDone(assignmentID, "Bottle", true))
+	require.NoError(t, configStore.SaveChecklistTemplate(t.Context(), []string{"Teeth"}))
+
+	items, err = store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bath", "Bottle+", "Story"}, checklistLabels(items), "a marked checklist no longer follows the template")
+	assert.NotNil(t, items[1].CompletedAt)
+}
+
+func TestChecklistStore_WeekdayItems(t *testing.T) {
+	store, configStore, assignmentID := setupTestChecklistStore(t)
+	require.NoError(t, configStore.SaveChecklistTemplate(t.Context(), []string{"Bath", "Story"}))
+
+	// The assignment is on Friday 16 October 2026
+	require.NoError(t, configStore.SaveWeekdayChecklists(t.Context(), map[time.Weekday][]string{
+		time.Friday:   {"Nails", "Bath"},
+		time.Saturday: {"Movie"},
+	}))
+	items, err := store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bath", "Story", "Nails"}, checklistLabels(items), "the items of the weekday follow the template, without duplicates")
+
+	require.NoError(t, store.SetItemDone(assignmentID, "Nails", true))
+	require.NoError(t, configStore.SaveWeekdayChecklists(t.Context(), nil))
+	items, err = store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bath", "Story", "Nails+"}, checklistLabels(items), "a marked checklist keeps the items of its weekday")
+}
+
+func TestChecklistStore_SaveChecklist(t *testing.T) {
+	store, configStore, assignmentID := setupTestChecklistStore(t)
+	require.NoError(t, configStore.SaveChecklistTemplate(t.Context(), []string{"Bath", "Bottle"}))
+
+	require.NoError(t, store.SaveChecklist(assignmentID, []string{"Bath", "Bottle", "Story"}))
+	require.NoError(t, store.SetItemDone(assignmentID, "Bath", true))
+	require.NoError(t, store.SaveChecklist(assignmentID, []string{"Story", " Bath ", "Teeth"}))
+
+	items, err := store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Story", "Bath+", "Teeth"}, checklistLabels(items), "kept items keep their completion")
+
+	require.NoError(t, store.SaveChecklist(assignmentID, nil))
+	items, err = store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Empty(t, items, "an emptied checklist does not fall back to the template")
+
+	err = store.SaveChecklist(assignmentID, strings.Split(strings.Repeat("item,", MaxChecklistItems)+"a,b", ","))
+	assert.NoError(t, err, "duplicates do not count against the limit")
+	labels := make([]string, MaxChecklistItems+1)
+	for i := range labels {
+		labels[i] = strings.Repeat("x", i+1)
+	}
+	assert.ErrorIs(t, store.SaveChecklist(assignmentID, labels), ErrInvalidChecklist)
+}
+
+func TestChecklistStore_SetItemDone(t *testing.T) {
+	store, _, assignmentID := setupTestChecklistStore(t)
+	require.NoError(t, store.SaveChecklist(assignmentID, []string{"Bath", "Story"}))
+
+	require.NoError(t, store.SetItemDone(assignmentID, "Story", true))
+	items, err := store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	completedAt := *items[1].CompletedAt
+
+	require.NoError(t, store.SetItemDone(assignmentID, "Story", true))
+	items, err = store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Equal(t, completedAt, *items[1].CompletedAt, "marking a done item again keeps its completion time")
+
+	require.NoError(t, store.SetItemDone(assignmentID, "Story", false))
+	items, err = store.GetChecklist(assignmentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bath", "Story"}, checklistLabels(items))
+
+	assert.ErrorIs(t, store.SetItemDone(assignmentID, "Bottle", true), ErrChecklistItemNotFound)
+}
+
+func TestNormalizeChecklist(t *testing.T) {
+	labels, err := NormalizeChecklist([]string{"  Bath", "", "Bath", "Story ", "  "})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bath", "Story"}, labels)
+
+	_, err = NormalizeChecklist([]string{strings.Repeat("é", MaxChecklistLabelRunes)})
+	assert.NoError(t, err, "the limit counts characters, not bytes")
+	_, err = NormalizeChecklist([]string{strings.Repeat("é", MaxChecklistLabelRunes+1)})
+	assert.ErrorIs(t, err, ErrInvalidChecklist)
+}
+
+func TestChecklistStore_GetChecklistCompletions(t *testing.T) {
+	store, configStore, assignmentID := setupTestChecklistStore(t)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, configStore.SaveChecklistTemplate(t.Context(), []string{"Bath", "Story"}))
+	completions, err := store.GetChecklistCompletions(start, end)
+	require.NoError(t, err)
+	assert.Empty(t, completions, "an untouched checklist is not completed")
+
+	require.NoError(t, store.SetItemDone(assignmentID, "Bath", true))
+	completions, err = store.GetChecklistCompletions(start, end)
+	require.NoError(t, err)
+	assert.Empty(t, completions, "a partly ticked checklist is not completed")
+
+	require.NoError(t, store.SetItemDone(assignmentID, "Story", true))
+	completions, err = store.GetChecklistCompletions(start, end)
+	require.NoError(t, err)
+	require.Contains(t, completions, assignmentID)
+	assert.WithinDuration(t, time.Now(), completions[assignmentID], time.Minute)
+
+	completions, err = store.GetChecklistCompletions(start.AddDate(0, 1, 0), end.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Empty(t, completions, "assignments outside the range are ignored")
+}
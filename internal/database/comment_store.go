@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// Bounds of a comment
+const (
+	MaxCommentAuthorRunes = 50
+	MaxCommentTextRunes   = 1000
+)
+
+// ErrInvalidComment is returned for a comment without author or text, or exceeding the bounds
+var ErrInvalidComment = errors.New("invalid comment")
+
+// ErrCommentNotFound is returned when deleting a comment missing from the assignment
+var ErrCommentNotFound = errors.New("comment not found")
+
+// Comment is a note left on an assignment
+type Comment struct {
+	ID           int64
+	AssignmentID int64
+	Author       string
+	Text         string
+	CreatedAt    time.Time
+}
+
+// CommentStore stores the comments left on the assignments
+type CommentStore struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewCommentStore creates a new comment store
+func NewCommentStore(db *DB) (*CommentStore, error) {
+	logger := logging.GetLogger("comment-store")
+	return &CommentStore{db: db, logger: logger}, nil
+}
+
+// normalizeComment trims the author and the text and checks their bounds
+func normalizeComment(author, text string) (string, string, error) {
+	author = strings.TrimSpace(author)
+	text = strings.TrimSpace(text)
+	switch {
+	case author == "" || text == "":
+		return "", "", fmt.Errorf("%w: author and text are required", ErrInvalidComment)
+	case utf8.RuneCountInString(author) > MaxCommentAuthorRunes:
+		return "", "", fmt.Errorf("%w: author is longer than %d characters", ErrInvalidComment, MaxCommentAuthorRunes)
+	case utf8.RuneCountInString(text) > MaxCommentTextRunes:
+		return "", "", fmt.Errorf("%w: text is longer than %d characters", ErrInvalidComment, MaxCommentTextRunes)
+	}
+	return author, text, nil
+}
+
+// AddComment stores a comment on the assignment and returns it. The author and the text are
+// trimmed; ErrInvalidComment is returned when one is empty or too long.
+func (s *CommentStore) AddComment(assignmentID int64, author, text string) (*Comment, error) {
+	author, text, err := normalizeComment(author, text)
+	if err != nil {
+		return nil, err
+	}
+	comment := &Comment{
+		AssignmentID: assignmentID,
+		Author:       author,
+		Text:         text,
+		CreatedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+	s.logger.Debug().Int64("assignment_id", assignmentID).Str("author", author).Msg("Adding assignment comment")
+
+	result, err := s.db.ExecContext(context.Background(), `
+	INSERT INTO assignment_comments (assignment_id, author, text, created_at)
+	VALUES (?, ?, ?, ?)`, assignmentID, author, text, comment.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+	comment.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment ID: %w", err)
+	}
+	return comment, nil
+}
+
+// ListComments returns the comments of the assignment, oldest first
+func (s *CommentStore) ListComments(assignmentID int64) ([]*Comment, error) {
+	s.logger.Debug().Int64("assignment_id", assignmentID).Msg("Fetching assignment comments")
+	rows, err := s.db.Conn().Query(`
+	SELECT id, assignment_id, author, text, created_at
+	FROM assignment_comments
+	WHERE assignment_id = ?
+	ORDER BY created_at, id`, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*Comment{}
+	for rows.Next() {
+		var comment Comment
+		var createdAt string
+		if err := rows.Scan(&comment.ID, &comment.AssignmentID, &comment.Author, &comment.Text, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comment.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse comment creation time: %w", err)
+		}
+		comments = append(comments, &comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate comments: %w", err)
+	}
+	return comments, nil
+}
+
+// DeleteComment deletes the comment of the assignment, ErrCommentNotFound when there is none
+func (s *CommentStore) DeleteComment(assignmentID, commentID int64) error {
+	s.logger.Debug().Int64("assignment_id", assignmentID).Int64("comment_id", commentID).Msg("Deleting assignment comment")
+	result, err := s.db.ExecContext(context.Background(), `DELETE FROM assignment_comments WHERE id = ? AND assignment_id = ?`, commentID, assignmentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check deleted comment: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %d", ErrCommentNotFound, commentID)
+	}
+	return nil
+}
+
+// IsCommentsInEventsEnabled reports whether the comments are appended to the calendar event descriptions,
+// as set on the settings page
+func (s *CommentStore) IsCommentsInEventsEnabled() (bool, error) {
+	return queryCommentsInEvents(s.db.Conn())
+}
+
+// queryCommentsInEvents reads whether the comments are appended to the calendar events, false when never saved
+func queryCommentsInEvents(q rowsQuerier) (bool, error) {
+	rows, err := q.Query(`SELECT in_calendar_events FROM config_comments WHERE id = 1`)
+	if err != nil {
+		return false, fmt.Errorf("failed to query comment configuration: %w", err)
+	}
+	defer rows.Close()
+
+	enabled := false
+	if rows.Next() {
+		if err := rows.Scan(&enabled); err != nil {
+			return false, fmt.Errorf("failed to scan comment configuration: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to iterate comment configuration: %w", err)
+	}
+	return enabled, nil
+}
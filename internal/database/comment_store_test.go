@@ -0,0 +1,107 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestCommentStore(t *testing.T) (*CommentStore, *ConfigStore, *DB, int64) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_comments.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	result, err := db.Conn().Exec(`INSERT INTO assignments (parent_name, assignment_date, override, decision_reason) VALUES ('Alice', '2026-10-16', 0, 'Alternating')`)
+	require.NoError(t, err)
+	assignmentID, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	store, err := NewCommentStore(db)
+	require.NoError(t, err, "Failed to create comment store")
+	configStore, err := NewConfigStore(db)
+	require.NoError(t, err, "Failed to create config store")
+	return store, configStore, db, assignmentID
+}
+
+func TestCommentStore_AddAndList(t *testing.T) {
+	store, _, _, assignmentID := setupTestCommentStore(t)
+
+	comments, err := store.ListComments(assignmentID)
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+
+	first, err := store.AddComment(assignmentID, " Alice ", " Fell asleep early ")
+	require.NoError(t, err)
+	assert.NotZero(t, first.ID)
+	assert.Equal(t, "Alice", first.Author)
+	assert.Equal(t, "Fell asleep early", first.Text)
+	assert.False(t, first.CreatedAt.IsZero())
+
+	_, err = store.AddComment(assignmentID, "Bob", "Skipped the bath")
+	require.NoError(t, err)
+
+	comments, err = store.ListComments(assignmentID)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, first.ID, comments[0].ID)
+	assert.Equal(t, assignmentID, comments[0].AssignmentID)
+	assert.True(t, first.CreatedAt.Equal(comments[0].CreatedAt))
+	assert.Equal(t, "Skipped the bath", comments[1].Text)
+}
+
+func TestCommentStore_AddInvalid(t *testing.T) {
+	store, _, _, assignmentID := setupTestCommentStore(t)
+
+	tests := []struct {
+		name   string
+		author string
+		text   string
+	}{
+		{"empty author", "  ", "text"},
+		{"empty text", "Alice", ""},
+		{"author too long", strings.Repeat("a", MaxCommentAuthorRunes+1), "text"},
+		{"text too long", "Alice", strings.Repeat("é", MaxCommentTextRunes+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.AddComment(assignmentID, tt.author, tt.text)
+			assert.ErrorIs(t, err, ErrInvalidComment)
+		})
+	}
+}
+
+func TestCommentStore_Delete(t *testing.T) {
+	store, _, db, assignmentID := setupTestCommentStore(t)
+
+	comment, err := store.AddComment(assignmentID, "Alice", "Note")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, store.DeleteComment(assignmentID+1, comment.ID), ErrCommentNotFound, "a comment is only deleted from its assignment")
+	require.NoError(t, store.DeleteComment(assignmentID, comment.ID))
+	assert.ErrorIs(t, store.DeleteComment(assignmentID, comment.ID), ErrCommentNotFound)
+
+	_, err = store.AddComment(assignmentID, "Alice", "Removed with the assignment")
+	require.NoError(t, err)
+	_, err = db.Conn().Exec(`DELETE FROM assignments WHERE id = ?`, assignmentID)
+	require.NoError(t, err)
+	comments, err := store.ListComments(assignmentID)
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+}
+
+func TestCommentStore_IsCommentsInEventsEnabled(t *testing.T) {
+	store, configStore, _, _ := setupTestCommentStore(t)
+
+	enabled, err := store.IsCommentsInEventsEnabled()
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	require.NoError(t, configStore.SaveCommentsInEvents(t.Context(), true))
+	enabled, err = store.IsCommentsInEventsEnabled()
+	require.NoError(t, err)
+	assert.True(t, enabled)
+}
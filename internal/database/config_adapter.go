@@ -1,6 +1,7 @@
 package database
 
 import (
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"golang.org/x/oauth2"
 )
@@ -9,14 +10,14 @@ import (
 // static OAuth2 config (which lives in file/env, not the database) so that
 // handlers only need a single ConfigStoreInterface dependency — no RuntimeConfig.
 type ConfigAdapter struct {
-	store       *ConfigStore
+	store       ConfigStoreInterface
 	oauthConfig *oauth2.Config
 }
 
 // NewConfigAdapter creates a new config adapter.
 // oauthConfig carries the static OAuth2 credentials (from environment variables /
 // file config) that cannot be stored in the database.
-func NewConfigAdapter(store *ConfigStore, oauthConfig *oauth2.Config) *ConfigAdapter {
+func NewConfigAdapter(store ConfigStoreInterface, oauthConfig *oauth2.Config) *ConfigAdapter {
 	return &ConfigAdapter{store: store, oauthConfig: oauthConfig}
 }
 
@@ -25,16 +26,61 @@ func (a *ConfigAdapter) GetParents() (parentA, parentB string, err error) {
 	return a.store.GetParents()
 }
 
+// GetExtraParents implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetExtraParents() ([]config.ExtraParent, error) {
+	return a.store.GetExtraParents()
+}
+
 // GetAvailability implements config.ConfigStoreInterface
 func (a *ConfigAdapter) GetAvailability(parent string) ([]string, error) {
 	return a.store.GetAvailability(parent)
 }
 
+// GetUnavailabilityRules implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetUnavailabilityRules(parent string) ([]config.UnavailabilityRule, error) {
+	return a.store.GetUnavailabilityRules(parent)
+}
+
 // GetSchedule implements config.ConfigStoreInterface
 func (a *ConfigAdapter) GetSchedule() (updateFrequency string, lookAheadDays, pastEventThresholdDays int, statsOrder constants.StatsOrder, err error) {
 	return a.store.GetSchedule()
 }
 
+// GetLookAheadWindows implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetLookAheadWindows() (config.LookAheadWindows, error) {
+	return a.store.GetLookAheadWindows()
+}
+
+// GetVacation implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetVacation() (config.Vacation, error) {
+	return a.store.GetVacation()
+}
+
+// GetSkipDates implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetSkipDates() (config.SkipDates, error) {
+	return a.store.GetSkipDates()
+}
+
+// GetSyncExclusions implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetSyncExclusions() (config.SyncExclusions, error) {
+	return a.store.GetSyncExclusions()
+}
+
+// GetMinRestDays implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetMinRestDays() (int, error) {
+	return a.store.GetMinRestDays()
+}
+
+// GetDayWeights implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetDayWeights() (config.DayWeights, error) {
+	return a.store.GetDayWeights()
+}
+
+// GetParentStyles implements config.ConfigStoreInterface
+func (a *ConfigAdapter) GetParentStyles() (parentA, parentB config.ParentStyle, err error) {
+	return a.store.GetParentStyles()
+}
+
 // GetOAuthConfig implements config.ConfigStoreInterface.
 // Returns the static OAuth2 configuration (client ID, secret, redirect URL, scopes)
 // that was set at application startup from environment variables and the config file.
@@ -41,13 +41,13 @@ func setupTestConfigAdapter(t *testing.T) (*ConfigAdapter, *ConfigStore, func())
 	require.NoError(t, err, "Failed to create config store")
 
 	// Seed test data
-	err = store.SaveParents("AdapterParentA", "AdapterParentB")
+	err = store.SaveParents(t.Context(), "AdapterParentA", "AdapterParentB")
 	require.NoError(t, err)
-	err = store.SaveAvailability("parent_a", []string{"Wednesday", "Friday"})
+	err = store.SaveAvailability(t.Context(), "parent_a", []string{"Wednesday", "Friday"})
 	require.NoError(t, err)
-	err = store.SaveAvailability("parent_b", []string{"Monday", "Thursday"})
+	err = store.SaveAvailability(t.Context(), "parent_b", []string{"Monday", "Thursday"})
 	require.NoError(t, err)
-	err = store.SaveSchedule("monthly", 60, 10, constants.StatsOrderDesc)
+	err = store.SaveSchedule(t.Context(), "monthly", 60, 10, constants.StatsOrderDesc)
 	require.NoError(t, err)
 
 	adapter := NewConfigAdapter(store, nil)
@@ -103,7 +103,7 @@ func TestConfigAdapter_GetAvailability_InvalidParent(t *testing.T) {
 	adapter, _, cleanup := setupTestConfigAdapter(t)
 	defer cleanup()
 
-	_, err := adapter.GetAvailability("parent_c")
+	_, err := adapter.GetAvailability("parent_g")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid parent identifier")
 }
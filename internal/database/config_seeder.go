@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/belphemur/night-routine/internal/config"
@@ -10,12 +11,12 @@ import (
 
 // ConfigSeeder handles seeding configuration from TOML to database
 type ConfigSeeder struct {
-	store  *ConfigStore
+	store  ConfigStoreInterface
 	logger zerolog.Logger
 }
 
 // NewConfigSeeder creates a new config seeder
-func NewConfigSeeder(store *ConfigStore) *ConfigSeeder {
+func NewConfigSeeder(store ConfigStoreInterface) *ConfigSeeder {
 	return &ConfigSeeder{
 		store:  store,
 		logger: logging.GetLogger("config-seeder"),
@@ -27,7 +28,8 @@ func NewConfigSeeder(store *ConfigStore) *ConfigSeeder {
 // - On initial setup: Seeds all config from TOML
 // - On upgrade: Migrates existing TOML config to new DB tables
 // - On normal startup: Skips if DB config already exists
-func (s *ConfigSeeder) SeedFromConfig(cfg *config.Config) error {
+// - Without parents in the file: Skips, the setup wizard of the web interface configures the database
+func (s *ConfigSeeder) SeedFromConfig(ctx context.Context, cfg *config.Config) error {
 	s.logger.Info().Msg("Checking if configuration needs seeding/migration")
 
 	// Check if configuration already exists
@@ -41,20 +43,25 @@ func (s *ConfigSeeder) SeedFromConfig(cfg *config.Config) error {
 		return nil
 	}
 
+	if cfg.Parents.ParentA == "" {
+		s.logger.Info().Msg("No configuration found in database nor parents in the config file, waiting for the setup wizard")
+		return nil
+	}
+
 	s.logger.Info().Msg("No configuration found in database, migrating from TOML config file")
 
 	// Seed parent configuration
-	if err := s.seedParents(cfg); err != nil {
+	if err := s.seedParents(ctx, cfg); err != nil {
 		return fmt.Errorf("failed to seed parent configuration: %w", err)
 	}
 
 	// Seed availability configuration
-	if err := s.seedAvailability(cfg); err != nil {
+	if err := s.seedAvailability(ctx, cfg); err != nil {
 		return fmt.Errorf("failed to seed availability configuration: %w", err)
 	}
 
 	// Seed schedule configuration
-	if err := s.seedSchedule(cfg); err != nil {
+	if err := s.seedSchedule(ctx, cfg); err != nil {
 		return fmt.Errorf("failed to seed schedule configuration: %w", err)
 	}
 
@@ -63,22 +70,33 @@ func (s *ConfigSeeder) SeedFromConfig(cfg *config.Config) error {
 }
 
 // seedParents seeds parent names from config
-func (s *ConfigSeeder) seedParents(cfg *config.Config) error {
+func (s *ConfigSeeder) seedParents(ctx context.Context, cfg *config.Config) error {
 	s.logger.Debug().
 		Str("parent_a", cfg.Parents.ParentA).
 		Str("parent_b", cfg.Parents.ParentB).
 		Msg("Seeding parent configuration")
 
-	if err := s.store.SaveParents(cfg.Parents.ParentA, cfg.Parents.ParentB); err != nil {
+	if err := s.store.SaveParents(ctx, cfg.Parents.ParentA, cfg.Parents.ParentB); err != nil {
 		return err
 	}
 
+	// The extra parents of parents.names take the default colors of their position
+	if extra := cfg.Parents.Extra(); len(extra) > 0 {
+		parents := make([]config.ExtraParent, len(extra))
+		for i, name := range extra {
+			parents[i] = config.ExtraParent{Name: name, Style: config.DefaultExtraParentStyle(i + 2)}
+		}
+		if err := s.store.SaveExtraParents(ctx, parents); err != nil {
+			return err
+		}
+	}
+
 	s.logger.Info().Msg("Parent configuration seeded successfully")
 	return nil
 }
 
 // seedAvailability seeds availability configuration from config
-func (s *ConfigSeeder) seedAvailability(cfg *config.Config) error {
+func (s *ConfigSeeder) seedAvailability(ctx context.Context, cfg *config.Config) error {
 	s.logger.Debug().Msg("Seeding availability configuration")
 
 	// Seed parent A availability
@@ -87,7 +105,7 @@ func (s *ConfigSeeder) seedAvailability(cfg *config.Config) error {
 		Int("unavailable_days", len(cfg.Availability.ParentAUnavailable)).
 		Msg("Seeding parent A availability")
 
-	if err := s.store.SaveAvailability("parent_a", cfg.Availability.ParentAUnavailable); err != nil {
+	if err := s.store.SaveAvailability(ctx, "parent_a", cfg.Availability.ParentAUnavailable); err != nil {
 		return fmt.Errorf("failed to seed parent A availability: %w", err)
 	}
 
@@ -97,7 +115,7 @@ func (s *ConfigSeeder) seedAvailability(cfg *config.Config) error {
 		Int("unavailable_days", len(cfg.Availability.ParentBUnavailable)).
 		Msg("Seeding parent B availability")
 
-	if err := s.store.SaveAvailability("parent_b", cfg.Availability.ParentBUnavailable); err != nil {
+	if err := s.store.SaveAvailability(ctx, "parent_b", cfg.Availability.ParentBUnavailable); err != nil {
 		return fmt.Errorf("failed to seed parent B availability: %w", err)
 	}
 
@@ -106,7 +124,7 @@ func (s *ConfigSeeder) seedAvailability(cfg *config.Config) error {
 }
 
 // seedSchedule seeds schedule configuration from config
-func (s *ConfigSeeder) seedSchedule(cfg *config.Config) error {
+func (s *ConfigSeeder) seedSchedule(ctx context.Context, cfg *config.Config) error {
 	s.logger.Debug().
 		Str("update_frequency", cfg.Schedule.UpdateFrequency).
 		Int("look_ahead_days", cfg.Schedule.LookAheadDays).
@@ -114,7 +132,7 @@ func (s *ConfigSeeder) seedSchedule(cfg *config.Config) error {
 		Str("stats_order", cfg.Schedule.StatsOrder.String()).
 		Msg("Seeding schedule configuration")
 
-	if err := s.store.SaveSchedule(
+	if err := s.store.SaveSchedule(ctx,
 		cfg.Schedule.UpdateFrequency,
 		cfg.Schedule.LookAheadDays,
 		cfg.Schedule.PastEventThresholdDays,
@@ -122,6 +140,9 @@ func (s *ConfigSeeder) seedSchedule(cfg *config.Config) error {
 	); err != nil {
 		return err
 	}
+	if err := s.store.SaveLookAheadWindows(ctx, cfg.Schedule.LookAheadWindows()); err != nil {
+		return err
+	}
 
 	s.logger.Info().Msg("Schedule configuration seeded successfully")
 	return nil
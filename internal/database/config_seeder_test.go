@@ -66,6 +66,7 @@ func createTestConfig() *config.Config {
 		Schedule: config.ScheduleConfig{
 			UpdateFrequency:        "weekly",
 			LookAheadDays:          30,
+			WebhookLookAheadDays:   14,
 			PastEventThresholdDays: 5,
 			StatsOrder:             constants.StatsOrderDesc,
 		},
@@ -84,7 +85,7 @@ func TestConfigSeeder_InitialSeeding(t *testing.T) {
 	assert.False(t, hasConfig, "Database should be empty initially")
 
 	// Seed configuration
-	err = seeder.SeedFromConfig(cfg)
+	err = seeder.SeedFromConfig(t.Context(), cfg)
 	require.NoError(t, err, "Seeding should succeed")
 
 	// Verify configuration was seeded
@@ -114,6 +115,23 @@ func TestConfigSeeder_InitialSeeding(t *testing.T) {
 	assert.Equal(t, 30, lookAhead)
 	assert.Equal(t, 5, threshold)
 	assert.Equal(t, constants.StatsOrderDesc, statsOrder)
+
+	windows, err := store.GetLookAheadWindows()
+	require.NoError(t, err)
+	assert.Equal(t, config.LookAheadWindows{Webhook: 14}, windows)
+}
+
+func TestConfigSeeder_ExtraParents(t *testing.T) {
+	seeder, store, cleanup := setupTestSeeder(t)
+	defer cleanup()
+
+	cfg := createTestConfig()
+	cfg.Parents.Names = []string{"Alice", "Bob", "Grandma"}
+	require.NoError(t, seeder.SeedFromConfig(t.Context(), cfg))
+
+	extra, err := store.GetExtraParents()
+	require.NoError(t, err)
+	assert.Equal(t, []config.ExtraParent{{Name: "Grandma", Style: config.DefaultExtraParentStyle(2)}}, extra)
 }
 
 func TestConfigSeeder_MigrationScenario(t *testing.T) {
@@ -126,7 +144,7 @@ func TestConfigSeeder_MigrationScenario(t *testing.T) {
 	cfg := createTestConfig()
 
 	// First run after upgrade - should migrate TOML to DB
-	err := seeder.SeedFromConfig(cfg)
+	err := seeder.SeedFromConfig(t.Context(), cfg)
 	require.NoError(t, err, "Migration should succeed")
 
 	// Verify configuration was migrated
@@ -143,11 +161,11 @@ func TestConfigSeeder_SkipIfAlreadySeeded(t *testing.T) {
 	cfg := createTestConfig()
 
 	// First seeding
-	err := seeder.SeedFromConfig(cfg)
+	err := seeder.SeedFromConfig(t.Context(), cfg)
 	require.NoError(t, err)
 
 	// Manually update configuration in DB
-	err = store.SaveParents("Charlie", "Diana")
+	err = store.SaveParents(t.Context(), "Charlie", "Diana")
 	require.NoError(t, err)
 
 	// Create new config with different values
@@ -169,7 +187,7 @@ func TestConfigSeeder_SkipIfAlreadySeeded(t *testing.T) {
 	}
 
 	// Attempt to seed again
-	err = seeder.SeedFromConfig(newCfg)
+	err = seeder.SeedFromConfig(t.Context(), newCfg)
 	require.NoError(t, err)
 
 	// Verify DB values were NOT overwritten (Charlie and Diana should remain)
@@ -179,6 +197,19 @@ func TestConfigSeeder_SkipIfAlreadySeeded(t *testing.T) {
 	assert.Equal(t, "Diana", parentB, "DB values should not be overwritten")
 }
 
+func TestConfigSeeder_SkipWithoutParents(t *testing.T) {
+	seeder, store, cleanup := setupTestSeeder(t)
+	defer cleanup()
+
+	cfg := createTestConfig()
+	cfg.Parents = config.ParentsConfig{}
+	require.NoError(t, seeder.SeedFromConfig(t.Context(), cfg))
+
+	hasConfig, err := store.HasConfiguration()
+	require.NoError(t, err)
+	assert.False(t, hasConfig, "left to the setup wizard")
+}
+
 func TestConfigSeeder_EmptyAvailability(t *testing.T) {
 	seeder, store, cleanup := setupTestSeeder(t)
 	defer cleanup()
@@ -201,7 +232,7 @@ func TestConfigSeeder_EmptyAvailability(t *testing.T) {
 	}
 
 	// Seed configuration
-	err := seeder.SeedFromConfig(cfg)
+	err := seeder.SeedFromConfig(t.Context(), cfg)
 	require.NoError(t, err)
 
 	// Verify empty availability lists
@@ -232,7 +263,7 @@ func TestConfigSeeder_AllFrequencyTypes(t *testing.T) {
 			cfg := createTestConfig()
 			cfg.Schedule.UpdateFrequency = tt.frequency
 
-			err := seeder.SeedFromConfig(cfg)
+			err := seeder.SeedFromConfig(t.Context(), cfg)
 			require.NoError(t, err)
 
 			freq, _, _, _, err := store.GetSchedule()
@@ -248,21 +279,21 @@ func TestConfigSeeder_PreservesDatabaseState(t *testing.T) {
 
 	// Seed initial configuration
 	cfg := createTestConfig()
-	err := seeder.SeedFromConfig(cfg)
+	err := seeder.SeedFromConfig(t.Context(), cfg)
 	require.NoError(t, err)
 
 	// User updates configuration via UI (simulated)
-	err = store.SaveParents("UpdatedA", "UpdatedB")
+	err = store.SaveParents(t.Context(), "UpdatedA", "UpdatedB")
 	require.NoError(t, err)
 
-	err = store.SaveAvailability("parent_a", []string{"Saturday", "Sunday"})
+	err = store.SaveAvailability(t.Context(), "parent_a", []string{"Saturday", "Sunday"})
 	require.NoError(t, err)
 
-	err = store.SaveSchedule("daily", 14, 7, constants.StatsOrderAsc)
+	err = store.SaveSchedule(t.Context(), "daily", 14, 7, constants.StatsOrderAsc)
 	require.NoError(t, err)
 
 	// Application restarts and tries to seed again
-	err = seeder.SeedFromConfig(cfg)
+	err = seeder.SeedFromConfig(t.Context(), cfg)
 	require.NoError(t, err)
 
 	// Verify user's updates are preserved
@@ -306,7 +337,7 @@ func TestConfigSeeder_SeedFromConfig_ParentsSeedError(t *testing.T) {
 	}
 
 	// Seed should fail
-	err := seeder.SeedFromConfig(cfg)
+	err := seeder.SeedFromConfig(t.Context(), cfg)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to seed parent configuration")
 }
@@ -334,7 +365,7 @@ func TestConfigSeeder_SeedFromConfig_ScheduleSeedError(t *testing.T) {
 	}
 
 	// Seed should fail on schedule
-	err := seeder.SeedFromConfig(cfg)
+	err := seeder.SeedFromConfig(t.Context(), cfg)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to seed schedule configuration")
 }
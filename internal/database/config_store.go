@@ -1,12 +1,17 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/belphemur/night-routine/internal/validation"
 	"github.com/rs/zerolog"
 )
 
@@ -88,16 +93,20 @@ func (s *ConfigStore) GetParentsFull() (*ConfigParents, error) {
 }
 
 // SaveParents saves or updates parent configuration
-func (s *ConfigStore) SaveParents(parentA, parentB string) error {
-	if parentA == "" || parentB == "" {
-		return fmt.Errorf("parent names cannot be empty")
+func (s *ConfigStore) SaveParents(ctx context.Context, parentA, parentB string) error {
+	if err := validation.Parents(parentA, parentB); err != nil {
+		return err
 	}
-	if parentA == parentB {
-		return fmt.Errorf("parent names must be different")
+	extra, err := s.GetExtraParents()
+	if err != nil {
+		return err
+	}
+	if err := validation.Roster(config.RosterOf(parentA, parentB, extra)); err != nil {
+		return err
 	}
 
 	s.logger.Debug().Str("parent_a", parentA).Str("parent_b", parentB).Msg("Saving parent configuration")
-	_, err := s.db.Exec(`
+	_, err = execWithRetry(ctx, s.db, `
 		INSERT INTO config_parents (id, parent_a, parent_b, updated_at)
 		VALUES (1, ?, ?, CURRENT_TIMESTAMP)
 		ON CONFLICT(id) DO UPDATE SET
@@ -112,12 +121,172 @@ func (s *ConfigStore) SaveParents(parentA, parentB string) error {
 	}
 
 	s.logger.Info().Msg("Parent configuration saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionParents)
+	return nil
+}
+
+// GetParentStyles returns the color and avatar of each parent, the defaults when no parent is saved
+func (s *ConfigStore) GetParentStyles() (parentA, parentB config.ParentStyle, err error) {
+	s.logger.Debug().Msg("Retrieving parent styles")
+	var colorA, colorB string
+	err = s.db.QueryRow(`
+		SELECT parent_a_color, parent_a_avatar, parent_b_color, parent_b_avatar
+		FROM config_parents
+		WHERE id = 1
+	`).Scan(&colorA, &parentA.Avatar, &colorB, &parentB.Avatar)
+	if err == sql.ErrNoRows {
+		parentA, parentB = config.DefaultParentStyles()
+		return parentA, parentB, nil
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to retrieve parent styles")
+		return config.ParentStyle{}, config.ParentStyle{}, fmt.Errorf("failed to retrieve parent styles: %w", err)
+	}
+
+	parentA.Color = constants.ParentColor(colorA)
+	parentB.Color = constants.ParentColor(colorB)
+	return parentA, parentB, nil
+}
+
+// SaveParentStyles saves the color and avatar of each parent, once the parents are saved. It fails
+// with config.ErrInvalidParentStyle for an invalid style.
+func (s *ConfigStore) SaveParentStyles(ctx context.Context, parentA, parentB config.ParentStyle) error {
+	if err := parentA.Validate(); err != nil {
+		return fmt.Errorf("parent A: %w", err)
+	}
+	if err := parentB.Validate(); err != nil {
+		return fmt.Errorf("parent B: %w", err)
+	}
+
+	saveLogger := s.logger.With().Stringer("parent_a_color", parentA.Color).Stringer("parent_b_color", parentB.Color).Logger()
+	saveLogger.Debug().Msg("Saving parent styles")
+	result, err := execWithRetry(ctx, s.db, `
+		UPDATE config_parents
+		SET parent_a_color = ?, parent_a_avatar = ?, parent_b_color = ?, parent_b_avatar = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = 1
+	`, parentA.Color.String(), parentA.Avatar, parentB.Color.String(), parentB.Avatar)
+	if err != nil {
+		saveLogger.Error().Err(err).Msg("Failed to save parent styles")
+		return fmt.Errorf("failed to save parent styles: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return fmt.Errorf("failed to save parent styles: no parent configuration")
+	}
+
+	saveLogger.Info().Msg("Parent styles saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionParents)
+	return nil
+}
+
+// GetExtraParents returns the parents taking turns after parent A and parent B, in the order of the
+// roster, none when never saved
+func (s *ConfigStore) GetExtraParents() ([]config.ExtraParent, error) {
+	s.logger.Debug().Msg("Retrieving extra parents")
+	rows, err := s.db.Query(`
+		SELECT name, color, avatar
+		FROM config_extra_parents
+		ORDER BY position
+	`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query extra parents")
+		return nil, fmt.Errorf("failed to retrieve extra parents: %w", err)
+	}
+	defer rows.Close()
+
+	var parents []config.ExtraParent
+	for rows.Next() {
+		var parent config.ExtraParent
+		var color string
+		if err := rows.Scan(&parent.Name, &color, &parent.Style.Avatar); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to scan extra parent row")
+			return nil, fmt.Errorf("failed to scan extra parent: %w", err)
+		}
+		parent.Style.Color = constants.ParentColor(color)
+		parents = append(parents, parent)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error().Err(err).Msg("Error iterating extra parent rows")
+		return nil, fmt.Errorf("error iterating extra parents: %w", err)
+	}
+
+	s.logger.Debug().Int("count", len(parents)).Msg("Extra parents retrieved")
+	return parents, nil
+}
+
+// SaveExtraParents replaces the parents taking turns after parent A and parent B, once the parents
+// are saved. The settings of a parent follow its position in the roster: those of the positions
+// left empty, availability and links included, are deleted.
+func (s *ConfigStore) SaveExtraParents(ctx context.Context, parents []config.ExtraParent) error {
+	parentA, parentB, err := s.GetParents()
+	if err != nil {
+		return err
+	}
+	if err := validation.Roster(config.RosterOf(parentA, parentB, parents)); err != nil {
+		return err
+	}
+	for _, parent := range parents {
+		if err := parent.Style.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", parent.Name, err)
+		}
+	}
+
+	s.logger.Debug().Int("count", len(parents)).Msg("Saving extra parents")
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceExtraParents(parents)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Int("count", len(parents)).Msg("Extra parents saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionParents)
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionAvailability)
+	return nil
+}
+
+// replaceExtraParents replaces the extra parents and deletes the settings of the positions left
+// empty within a transaction
+func (s *ConfigStore) replaceExtraParents(parents []config.ExtraParent) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM config_extra_parents`); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to delete extra parents")
+		return fmt.Errorf("failed to delete extra parents: %w", err)
+	}
+	for i, parent := range parents {
+		if _, err := tx.Exec(`INSERT INTO config_extra_parents (position, name, color, avatar) VALUES (?, ?, ?, ?)`,
+			i+2, parent.Name, parent.Style.Color.String(), parent.Style.Avatar); err != nil {
+			s.logger.Error().Err(err).Str("name", parent.Name).Msg("Failed to insert extra parent")
+			return fmt.Errorf("failed to insert extra parent %s: %w", parent.Name, err)
+		}
+	}
+
+	for index := len(parents) + 2; index < validation.MaxParents; index++ {
+		key := config.ParentKey(index)
+		for _, table := range []string{"config_availability", "config_unavailability_rules", "calendar_unavailability", "parent_links", "parent_contacts"} {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE parent = ?`, key); err != nil {
+				s.logger.Error().Err(err).Str("parent", key).Str("table", table).Msg("Failed to delete settings of removed parent")
+				return fmt.Errorf("failed to delete %s of %s: %w", table, key, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
 // GetAvailability retrieves unavailable days for a parent
 func (s *ConfigStore) GetAvailability(parent string) ([]string, error) {
-	if parent != "parent_a" && parent != "parent_b" {
+	if !config.IsParentKey(parent) {
 		return nil, fmt.Errorf("invalid parent identifier: %s", parent)
 	}
 
@@ -154,13 +323,30 @@ func (s *ConfigStore) GetAvailability(parent string) ([]string, error) {
 }
 
 // SaveAvailability saves unavailable days for a parent
-func (s *ConfigStore) SaveAvailability(parent string, unavailableDays []string) error {
-	if parent != "parent_a" && parent != "parent_b" {
+func (s *ConfigStore) SaveAvailability(ctx context.Context, parent string, unavailableDays []string) error {
+	if !config.IsParentKey(parent) {
 		return fmt.Errorf("invalid parent identifier: %s", parent)
 	}
+	if err := validation.DaysOfWeek(unavailableDays); err != nil {
+		s.logger.Error().Err(err).Msg("Invalid day of week")
+		return err
+	}
 
 	s.logger.Debug().Str("parent", parent).Int("day_count", len(unavailableDays)).Msg("Saving availability configuration")
 
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceAvailability(parent, unavailableDays)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Str("parent", parent).Msg("Availability configuration saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionAvailability)
+	return nil
+}
+
+// replaceAvailability replaces the unavailable days of parent within a transaction
+func (s *ConfigStore) replaceAvailability(parent string, unavailableDays []string) error {
 	// Start a transaction
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -186,12 +372,7 @@ func (s *ConfigStore) SaveAvailability(parent string, unavailableDays []string)
 	}
 	defer stmt.Close()
 
-	// Validate day values
 	for _, day := range unavailableDays {
-		if !constants.IsValidDayOfWeek(day) {
-			s.logger.Error().Str("day", day).Msg("Invalid day of week")
-			return fmt.Errorf("invalid day of week: %s", day)
-		}
 		if _, err := stmt.Exec(parent, day); err != nil {
 			s.logger.Error().Err(err).Str("day", day).Msg("Failed to insert availability")
 			return fmt.Errorf("failed to insert availability for %s: %w", day, err)
@@ -202,8 +383,99 @@ func (s *ConfigStore) SaveAvailability(parent string, unavailableDays []string)
 		s.logger.Error().Err(err).Msg("Failed to commit transaction")
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	return nil
+}
 
-	s.logger.Info().Str("parent", parent).Msg("Availability configuration saved successfully")
+// GetUnavailabilityRules retrieves the recurring unavailability rules of a parent, in the order they
+// were saved
+func (s *ConfigStore) GetUnavailabilityRules(parent string) ([]config.UnavailabilityRule, error) {
+	if !config.IsParentKey(parent) {
+		return nil, fmt.Errorf("invalid parent identifier: %s", parent)
+	}
+
+	s.logger.Debug().Str("parent", parent).Msg("Retrieving unavailability rules")
+	rows, err := s.db.Query(`
+		SELECT rule
+		FROM config_unavailability_rules
+		WHERE parent = ?
+		ORDER BY id
+	`, parent)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query unavailability rules")
+		return nil, fmt.Errorf("failed to retrieve unavailability rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []config.UnavailabilityRule
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to scan unavailability rule row")
+			return nil, fmt.Errorf("failed to scan unavailability rule: %w", err)
+		}
+		rule, err := config.ParseUnavailabilityRule(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse stored unavailability rule %q: %w", value, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error().Err(err).Msg("Error iterating unavailability rule rows")
+		return nil, fmt.Errorf("error iterating unavailability rules: %w", err)
+	}
+
+	s.logger.Debug().Str("parent", parent).Int("count", len(rules)).Msg("Unavailability rules retrieved")
+	return rules, nil
+}
+
+// SaveUnavailabilityRules replaces the recurring unavailability rules of a parent; a rule listed
+// twice is saved once
+func (s *ConfigStore) SaveUnavailabilityRules(ctx context.Context, parent string, rules []config.UnavailabilityRule) error {
+	if !config.IsParentKey(parent) {
+		return fmt.Errorf("invalid parent identifier: %s", parent)
+	}
+
+	s.logger.Debug().Str("parent", parent).Int("rule_count", len(rules)).Msg("Saving unavailability rules")
+
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceUnavailabilityRules(parent, rules)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Str("parent", parent).Msg("Unavailability rules saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionAvailability)
+	return nil
+}
+
+// replaceUnavailabilityRules replaces the unavailability rules of parent within a transaction
+func (s *ConfigStore) replaceUnavailabilityRules(parent string, rules []config.UnavailabilityRule) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM config_unavailability_rules WHERE parent = ?`, parent); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to delete existing unavailability rules")
+		return fmt.Errorf("failed to delete existing unavailability rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO config_unavailability_rules (parent, rule) VALUES (?, ?)`, parent, rule.String()); err != nil {
+			s.logger.Error().Err(err).Stringer("rule", rule).Msg("Failed to insert unavailability rule")
+			return fmt.Errorf("failed to insert unavailability rule %s: %w", rule, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
@@ -278,19 +550,9 @@ func (s *ConfigStore) GetScheduleFull() (*ConfigSchedule, error) {
 }
 
 // SaveSchedule saves or updates schedule configuration
-func (s *ConfigStore) SaveSchedule(updateFrequency string, lookAheadDays, pastEventThresholdDays int, statsOrder constants.StatsOrder) error {
-	// Validate inputs
-	if updateFrequency != "daily" && updateFrequency != "weekly" && updateFrequency != "monthly" && updateFrequency != "disabled" {
-		return fmt.Errorf("invalid update frequency: %s", updateFrequency)
-	}
-	if lookAheadDays < 1 {
-		return fmt.Errorf("look ahead days must be positive")
-	}
-	if pastEventThresholdDays < 0 {
-		return fmt.Errorf("past event threshold days cannot be negative")
-	}
-	if !statsOrder.IsValid() {
-		return fmt.Errorf("invalid stats order: %s (must be 'desc' or 'asc')", statsOrder)
+func (s *ConfigStore) SaveSchedule(ctx context.Context, updateFrequency string, lookAheadDays, pastEventThresholdDays int, statsOrder constants.StatsOrder) error {
+	if err := validation.Schedule(updateFrequency, lookAheadDays, pastEventThresholdDays, statsOrder); err != nil {
+		return err
 	}
 
 	s.logger.Debug().
@@ -300,7 +562,7 @@ func (s *ConfigStore) SaveSchedule(updateFrequency string, lookAheadDays, pastEv
 		Str("stats_order", statsOrder.String()).
 		Msg("Saving schedule configuration")
 
-	_, err := s.db.Exec(`
+	_, err := execWithRetry(ctx, s.db, `
 		INSERT INTO config_schedule (id, update_frequency, look_ahead_days, past_event_threshold_days, stats_order, updated_at)
 		VALUES (1, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 		ON CONFLICT(id) DO UPDATE SET
@@ -317,6 +579,642 @@ func (s *ConfigStore) SaveSchedule(updateFrequency string, lookAheadDays, pastEv
 	}
 
 	s.logger.Info().Msg("Schedule configuration saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionSchedule)
+	return nil
+}
+
+// GetLookAheadWindows returns the days scheduled ahead by the periodic, webhook and manual syncs,
+// none set when the schedule was never saved
+func (s *ConfigStore) GetLookAheadWindows() (config.LookAheadWindows, error) {
+	s.logger.Debug().Msg("Retrieving look-ahead windows")
+	var windows config.LookAheadWindows
+	err := s.db.QueryRow(`
+		SELECT scheduled_look_ahead_days, webhook_look_ahead_days, manual_look_ahead_days
+		FROM config_schedule
+		WHERE id = 1
+	`).Scan(&windows.Scheduled, &windows.Webhook, &windows.Manual)
+	if err == sql.ErrNoRows {
+		return config.LookAheadWindows{}, nil
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to retrieve look-ahead windows")
+		return config.LookAheadWindows{}, fmt.Errorf("failed to retrieve look-ahead windows: %w", err)
+	}
+	return windows, nil
+}
+
+// SaveLookAheadWindows saves the days scheduled ahead by the periodic, webhook and manual syncs, 0
+// for the look ahead days of the schedule. The schedule must be saved first.
+func (s *ConfigStore) SaveLookAheadWindows(ctx context.Context, windows config.LookAheadWindows) error {
+	for _, days := range []int{windows.Scheduled, windows.Webhook, windows.Manual} {
+		if err := validation.TriggerLookAheadDays(days); err != nil {
+			return err
+		}
+	}
+
+	saveLogger := s.logger.With().Int("scheduled_look_ahead_days", windows.Scheduled).Int("webhook_look_ahead_days", windows.Webhook).Int("manual_look_ahead_days", windows.Manual).Logger()
+	saveLogger.Debug().Msg("Saving look-ahead windows")
+	result, err := execWithRetry(ctx, s.db, `
+		UPDATE config_schedule
+		SET scheduled_look_ahead_days = ?, webhook_look_ahead_days = ?, manual_look_ahead_days = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = 1
+	`, windows.Scheduled, windows.Webhook, windows.Manual)
+	if err != nil {
+		saveLogger.Error().Err(err).Msg("Failed to save look-ahead windows")
+		return fmt.Errorf("failed to save look-ahead windows: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return fmt.Errorf("failed to save look-ahead windows: no schedule configuration")
+	}
+
+	saveLogger.Info().Msg("Look-ahead windows saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionSchedule)
+	return nil
+}
+
+// GetNotifyChannels returns whether each of channels is enabled. Channels never saved are enabled.
+func (s *ConfigStore) GetNotifyChannels(channels []string) (map[string]bool, error) {
+	s.logger.Debug().Strs("channels", channels).Msg("Retrieving notification channel configuration")
+	enabled := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		enabled[channel] = true
+	}
+
+	rows, err := s.db.Query(`SELECT channel, enabled FROM config_notify_channels`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query notification channel configuration")
+		return nil, fmt.Errorf("failed to query notification channel configuration: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var channel string
+		var channelEnabled bool
+		if err := rows.Scan(&channel, &channelEnabled); err != nil {
+			return nil, fmt.Errorf("failed to scan notification channel configuration: %w", err)
+		}
+		if _, ok := enabled[channel]; ok {
+			enabled[channel] = channelEnabled
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification channel configuration: %w", err)
+	}
+	return enabled, nil
+}
+
+// IsNotifyChannelEnabled reports whether notifications are delivered through channel
+func (s *ConfigStore) IsNotifyChannelEnabled(channel string) (bool, error) {
+	enabled, err := s.GetNotifyChannels([]string{channel})
+	if err != nil {
+		return false, err
+	}
+	return enabled[channel], nil
+}
+
+// SaveNotifyChannels saves whether each channel is enabled
+func (s *ConfigStore) SaveNotifyChannels(ctx context.Context, enabled map[string]bool) error {
+	s.logger.Debug().Int("channel_count", len(enabled)).Msg("Saving notification channel configuration")
+
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceNotifyChannels(enabled)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Msg("Notification channel configuration saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionNotify)
+	return nil
+}
+
+// replaceNotifyChannels upserts the state of the channels within a transaction
+func (s *ConfigStore) replaceNotifyChannels(enabled map[string]bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	for channel, channelEnabled := range enabled {
+		if _, err := tx.Exec(`
+			INSERT INTO config_notify_channels (channel, enabled, updated_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(channel) DO UPDATE SET
+				enabled = excluded.enabled,
+				updated_at = CURRENT_TIMESTAMP
+		`, channel, channelEnabled); err != nil {
+			s.logger.Error().Err(err).Str("channel", channel).Msg("Failed to save notification channel")
+			return fmt.Errorf("failed to save notification channel %s: %w", channel, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetChecklistTemplate returns the labels of the bedtime checklist in order, empty when none is configured
+func (s *ConfigStore) GetChecklistTemplate() ([]string, error) {
+	s.logger.Debug().Msg("Fetching checklist template")
+	return queryChecklistTemplate(s.db)
+}
+
+// SaveChecklistTemplate replaces the bedtime checklist; labels are normalized with NormalizeChecklist.
+// Assignments whose checklist was already edited keep their own.
+func (s *ConfigStore) SaveChecklistTemplate(ctx context.Context, labels []string) error {
+	labels, err := NormalizeChecklist(labels)
+	if err != nil {
+		return err
+	}
+	s.logger.Debug().Int("item_count", len(labels)).Msg("Saving checklist template")
+
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceChecklistTemplate(labels)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Int("item_count", len(labels)).Msg("Checklist template saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionChecklist)
+	return nil
+}
+
+// replaceChecklistTemplate replaces the checklist template within a transaction
+func (s *ConfigStore) replaceChecklistTemplate(labels []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM config_checklist_items`); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to clear checklist template")
+		return fmt.Errorf("failed to clear checklist template: %w", err)
+	}
+	for position, label := range labels {
+		if _, err := tx.Exec(`INSERT INTO config_checklist_items (label, position) VALUES (?, ?)`, label, position); err != nil {
+			s.logger.Error().Err(err).Str("label", label).Msg("Failed to save checklist item")
+			return fmt.Errorf("failed to save checklist item %s: %w", label, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetWeekdayChecklists returns the items added to the bedtime checklist on each weekday, in order;
+// weekdays without items are missing from the map
+func (s *ConfigStore) GetWeekdayChecklists() (map[time.Weekday][]string, error) {
+	s.logger.Debug().Msg("Fetching weekday checklists")
+	return queryWeekdayChecklists(s.db)
+}
+
+// SaveWeekdayChecklists replaces the items added to the bedtime checklist on each weekday; the labels
+// of each weekday are normalized with NormalizeChecklist. Weekdays missing from checklists get no
+// items. Assignments whose checklist was already edited keep their own.
+func (s *ConfigStore) SaveWeekdayChecklists(ctx context.Context, checklists map[time.Weekday][]string) error {
+	normalized := make(map[time.Weekday][]string, len(checklists))
+	for weekday, labels := range checklists {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return fmt.Errorf("%w: unknown weekday %d", ErrInvalidChecklist, weekday)
+		}
+		labels, err := NormalizeChecklist(labels)
+		if err != nil {
+			return fmt.Errorf("%s: %w", weekday, err)
+		}
+		normalized[weekday] = labels
+	}
+	s.logger.Debug().Int("weekday_count", len(normalized)).Msg("Saving weekday checklists")
+
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceWeekdayChecklists(normalized)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Int("weekday_count", len(normalized)).Msg("Weekday checklists saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionChecklist)
+	return nil
+}
+
+// replaceWeekdayChecklists replaces the weekday checklists within a transaction
+func (s *ConfigStore) replaceWeekdayChecklists(checklists map[time.Weekday][]string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM config_weekday_checklist_items`); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to clear weekday checklists")
+		return fmt.Errorf("failed to clear weekday checklists: %w", err)
+	}
+	for weekday, labels := range checklists {
+		for position, label := range labels {
+			if _, err := tx.Exec(`INSERT INTO config_weekday_checklist_items (weekday, label, position) VALUES (?, ?, ?)`, int(weekday), label, position); err != nil {
+				s.logger.Error().Err(err).Stringer("weekday", weekday).Str("label", label).Msg("Failed to save weekday checklist item")
+				return fmt.Errorf("failed to save %s checklist item %s: %w", weekday, label, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetCommentsInEvents reports whether the assignment comments are appended to the calendar event
+// descriptions, false when never saved
+func (s *ConfigStore) GetCommentsInEvents() (bool, error) {
+	s.logger.Debug().Msg("Fetching comment configuration")
+	return queryCommentsInEvents(s.db)
+}
+
+// SaveCommentsInEvents saves whether the assignment comments are appended to the calendar event descriptions
+func (s *ConfigStore) SaveCommentsInEvents(ctx context.Context, enabled bool) error {
+	s.logger.Debug().Bool("enabled", enabled).Msg("Saving comment configuration")
+
+	if err := RetryOnBusy(ctx, func() error {
+		_, err := s.db.Exec(`
+			INSERT INTO config_comments (id, in_calendar_events, updated_at)
+			VALUES (1, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(id) DO UPDATE SET
+				in_calendar_events = excluded.in_calendar_events,
+				updated_at = CURRENT_TIMESTAMP
+		`, enabled)
+		return err
+	}); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to save comment configuration")
+		return fmt.Errorf("failed to save comment configuration: %w", err)
+	}
+
+	s.logger.Info().Bool("enabled", enabled).Msg("Comment configuration saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionComments)
+	return nil
+}
+
+// GetMinRestDays returns the nights off a parent gets at least after a block of consecutive nights,
+// 0 (no rest enforced) when never saved
+func (s *ConfigStore) GetMinRestDays() (int, error) {
+	s.logger.Debug().Msg("Fetching rest days configuration")
+
+	var days int
+	err := s.db.QueryRow(`SELECT min_rest_days FROM config_rest_days WHERE id = 1`).Scan(&days)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query rest days configuration")
+		return 0, fmt.Errorf("failed to query rest days configuration: %w", err)
+	}
+	return days, nil
+}
+
+// SaveMinRestDays saves the nights off a parent gets at least after a block of consecutive nights. It
+// fails with validation.ErrInvalidMinRestDays out of 0 to validation.MaxMinRestDays.
+func (s *ConfigStore) SaveMinRestDays(ctx context.Context, days int) error {
+	s.logger.Debug().Int("min_rest_days", days).Msg("Saving rest days configuration")
+
+	if err := validation.MinRestDays(days); err != nil {
+		return err
+	}
+
+	if err := RetryOnBusy(ctx, func() error {
+		_, err := s.db.Exec(`
+			INSERT INTO config_rest_days (id, min_rest_days, updated_at)
+			VALUES (1, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(id) DO UPDATE SET
+				min_rest_days = excluded.min_rest_days,
+				updated_at = CURRENT_TIMESTAMP
+		`, days)
+		return err
+	}); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to save rest days configuration")
+		return fmt.Errorf("failed to save rest days configuration: %w", err)
+	}
+
+	s.logger.Info().Int("min_rest_days", days).Msg("Rest days configuration saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionRestDays)
+	return nil
+}
+
+// GetDayWeights returns how much a night counts in the fairness totals by day of the week, none
+// (every night counts 1) when never saved
+func (s *ConfigStore) GetDayWeights() (config.DayWeights, error) {
+	s.logger.Debug().Msg("Fetching day weights")
+	rows, err := s.db.Query(`SELECT weekday, weight FROM config_day_weights ORDER BY weekday`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query day weights")
+		return nil, fmt.Errorf("failed to retrieve day weights: %w", err)
+	}
+	defer rows.Close()
+
+	var weights config.DayWeights
+	for rows.Next() {
+		var weekday int
+		var weight float64
+		if err := rows.Scan(&weekday, &weight); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to scan day weight row")
+			return nil, fmt.Errorf("failed to scan day weight: %w", err)
+		}
+		if weights == nil {
+			weights = make(config.DayWeights)
+		}
+		weights[time.Weekday(weekday)] = weight
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error().Err(err).Msg("Error iterating day weight rows")
+		return nil, fmt.Errorf("error iterating day weights: %w", err)
+	}
+	return weights, nil
+}
+
+// SaveDayWeights replaces how much a night counts in the fairness totals by day of the week. Days
+// weighted 1 are not stored. It fails with validation.ErrInvalidDayWeight for a weight out of
+// validation.MinDayWeight to validation.MaxDayWeight or out of step.
+func (s *ConfigStore) SaveDayWeights(ctx context.Context, weights config.DayWeights) error {
+	s.logger.Debug().Interface("day_weights", weights).Msg("Saving day weights")
+
+	if err := validation.DayWeights(weights); err != nil {
+		return err
+	}
+
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceDayWeights(weights)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Interface("day_weights", weights).Msg("Day weights saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionDayWeights)
+	return nil
+}
+
+// replaceDayWeights replaces the day weights within a transaction
+func (s *ConfigStore) replaceDayWeights(weights config.DayWeights) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM config_day_weights`); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to delete existing day weights")
+		return fmt.Errorf("failed to delete existing day weights: %w", err)
+	}
+
+	for day, weight := range weights {
+		if weight == 1 {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO config_day_weights (weekday, weight) VALUES (?, ?)`, int(day), weight); err != nil {
+			s.logger.Error().Err(err).Stringer("weekday", day).Msg("Failed to insert day weight")
+			return fmt.Errorf("failed to insert day weight of %s: %w", day, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// vacationDateFormat is the format of the vacation dates in the database
+const vacationDateFormat = "2006-01-02"
+
+// ErrInvalidVacation is returned for an enabled vacation ending before it starts
+var ErrInvalidVacation = errors.New("invalid vacation")
+
+// GetVacation returns the family vacation, disabled when never saved. The dates are local days.
+func (s *ConfigStore) GetVacation() (config.Vacation, error) {
+	s.logger.Debug().Msg("Fetching vacation configuration")
+
+	var enabled bool
+	var startStr, endStr string
+	err := s.db.QueryRow(`SELECT enabled, start_date, end_date FROM config_vacation WHERE id = 1`).Scan(&enabled, &startStr, &endStr)
+	if err == sql.ErrNoRows {
+		return config.Vacation{}, nil
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query vacation configuration")
+		return config.Vacation{}, fmt.Errorf("failed to query vacation configuration: %w", err)
+	}
+
+	start, err := time.ParseInLocation(vacationDateFormat, startStr, time.Local)
+	if err != nil {
+		return config.Vacation{}, fmt.Errorf("failed to parse vacation start %q: %w", startStr, err)
+	}
+	end, err := time.ParseInLocation(vacationDateFormat, endStr, time.Local)
+	if err != nil {
+		return config.Vacation{}, fmt.Errorf("failed to parse vacation end %q: %w", endStr, err)
+	}
+	return config.Vacation{Enabled: enabled, Start: start, End: end}, nil
+}
+
+// SaveVacation saves the family vacation. It fails with ErrInvalidVacation when the vacation is
+// enabled and ends before it starts.
+func (s *ConfigStore) SaveVacation(ctx context.Context, vacation config.Vacation) error {
+	startStr := vacation.Start.Format(vacationDateFormat)
+	endStr := vacation.End.Format(vacationDateFormat)
+	saveLogger := s.logger.With().Bool("enabled", vacation.Enabled).Str("start", startStr).Str("end", endStr).Logger()
+	saveLogger.Debug().Msg("Saving vacation configuration")
+
+	if vacation.Enabled && endStr < startStr {
+		return fmt.Errorf("%w: ends on %s before it starts on %s", ErrInvalidVacation, endStr, startStr)
+	}
+
+	if err := RetryOnBusy(ctx, func() error {
+		_, err := s.db.Exec(`
+			INSERT INTO config_vacation (id, enabled, start_date, end_date, updated_at)
+			VALUES (1, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(id) DO UPDATE SET
+				enabled = excluded.enabled,
+				start_date = excluded.start_date,
+				end_date = excluded.end_date,
+				updated_at = CURRENT_TIMESTAMP
+		`, vacation.Enabled, startStr, endStr)
+		return err
+	}); err != nil {
+		saveLogger.Error().Err(err).Msg("Failed to save vacation configuration")
+		return fmt.Errorf("failed to save vacation configuration: %w", err)
+	}
+
+	saveLogger.Info().Msg("Vacation configuration saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionVacation)
+	return nil
+}
+
+// GetSkipDates returns the days without night routine, in the order they were saved
+func (s *ConfigStore) GetSkipDates() (config.SkipDates, error) {
+	s.logger.Debug().Msg("Retrieving skip dates")
+	rows, err := s.db.Query(`SELECT entry FROM config_skip_dates ORDER BY id`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query skip dates")
+		return nil, fmt.Errorf("failed to retrieve skip dates: %w", err)
+	}
+	defer rows.Close()
+
+	var skipDates config.SkipDates
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to scan skip date row")
+			return nil, fmt.Errorf("failed to scan skip date: %w", err)
+		}
+		skipDate, err := config.ParseSkipDate(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse stored skip date %q: %w", value, err)
+		}
+		skipDates = append(skipDates, skipDate)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error().Err(err).Msg("Error iterating skip date rows")
+		return nil, fmt.Errorf("error iterating skip dates: %w", err)
+	}
+
+	s.logger.Debug().Int("count", len(skipDates)).Msg("Skip dates retrieved")
+	return skipDates, nil
+}
+
+// SaveSkipDates replaces the days without night routine; an entry listed twice is saved once
+func (s *ConfigStore) SaveSkipDates(ctx context.Context, skipDates config.SkipDates) error {
+	s.logger.Debug().Int("count", len(skipDates)).Msg("Saving skip dates")
+
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceSkipDates(skipDates)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Msg("Skip dates saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionSkipDates)
+	return nil
+}
+
+// replaceSkipDates replaces the skip dates within a transaction
+func (s *ConfigStore) replaceSkipDates(skipDates config.SkipDates) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM config_skip_dates`); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to delete existing skip dates")
+		return fmt.Errorf("failed to delete existing skip dates: %w", err)
+	}
+
+	for _, skipDate := range skipDates {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO config_skip_dates (entry) VALUES (?)`, skipDate.String()); err != nil {
+			s.logger.Error().Err(err).Stringer("skip_date", skipDate).Msg("Failed to insert skip date")
+			return fmt.Errorf("failed to insert skip date %s: %w", skipDate, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetSyncExclusions returns the kinds of nights kept out of Google Calendar, none when never saved
+func (s *ConfigStore) GetSyncExclusions() (config.SyncExclusions, error) {
+	s.logger.Debug().Msg("Retrieving sync exclusions")
+	rows, err := s.db.Query(`SELECT kind FROM config_sync_exclusions ORDER BY id`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query sync exclusions")
+		return config.SyncExclusions{}, fmt.Errorf("failed to retrieve sync exclusions: %w", err)
+	}
+	defer rows.Close()
+
+	var kinds []string
+	for rows.Next() {
+		var kind string
+		if err := rows.Scan(&kind); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to scan sync exclusion row")
+			return config.SyncExclusions{}, fmt.Errorf("failed to scan sync exclusion: %w", err)
+		}
+		kinds = append(kinds, kind)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error().Err(err).Msg("Error iterating sync exclusion rows")
+		return config.SyncExclusions{}, fmt.Errorf("error iterating sync exclusions: %w", err)
+	}
+
+	s.logger.Debug().Strs("kinds", kinds).Msg("Sync exclusions retrieved")
+	return config.ParseSyncExclusions(kinds), nil
+}
+
+// SaveSyncExclusions replaces the kinds of nights kept out of Google Calendar; a tag listed twice
+// is saved once
+func (s *ConfigStore) SaveSyncExclusions(ctx context.Context, exclusions config.SyncExclusions) error {
+	kinds := exclusions.Kinds()
+	s.logger.Debug().Strs("kinds", kinds).Msg("Saving sync exclusions")
+
+	if err := RetryOnBusy(ctx, func() error {
+		return s.replaceSyncExclusions(kinds)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info().Strs("kinds", kinds).Msg("Sync exclusions saved successfully")
+	signals.EmitConfigChanged(context.Background(), signals.ConfigSectionSyncExclude)
+	return nil
+}
+
+// replaceSyncExclusions replaces the sync exclusions within a transaction
+func (s *ConfigStore) replaceSyncExclusions(kinds []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback() // Rollback is safe to call even after Commit
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM config_sync_exclusions`); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to delete existing sync exclusions")
+		return fmt.Errorf("failed to delete existing sync exclusions: %w", err)
+	}
+
+	for _, kind := range kinds {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO config_sync_exclusions (kind) VALUES (?)`, kind); err != nil {
+			s.logger.Error().Err(err).Str("kind", kind).Msg("Failed to insert sync exclusion")
+			return fmt.Errorf("failed to insert sync exclusion %s: %w", kind, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
@@ -1,10 +1,17 @@
 package database
 
 import (
+	"context"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/belphemur/night-routine/internal/validation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -54,7 +61,7 @@ func TestConfigStore_SaveAndGetParents(t *testing.T) {
 	defer cleanup()
 
 	// Save parent configuration
-	err := store.SaveParents("Alice", "Bob")
+	err := store.SaveParents(t.Context(), "Alice", "Bob")
 	require.NoError(t, err)
 
 	// Retrieve parent configuration
@@ -64,7 +71,7 @@ func TestConfigStore_SaveAndGetParents(t *testing.T) {
 	assert.Equal(t, "Bob", parentB)
 
 	// Update parent configuration
-	err = store.SaveParents("Charlie", "Diana")
+	err = store.SaveParents(t.Context(), "Charlie", "Diana")
 	require.NoError(t, err)
 
 	// Verify update
@@ -112,7 +119,7 @@ func TestConfigStore_SaveParents_Validation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := store.SaveParents(tt.parentA, tt.parentB)
+			err := store.SaveParents(t.Context(), tt.parentA, tt.parentB)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -128,12 +135,12 @@ func TestConfigStore_SaveAndGetAvailability(t *testing.T) {
 
 	// Save availability for parent A
 	daysA := []string{"Monday", "Wednesday", "Friday"}
-	err := store.SaveAvailability("parent_a", daysA)
+	err := store.SaveAvailability(t.Context(), "parent_a", daysA)
 	require.NoError(t, err)
 
 	// Save availability for parent B
 	daysB := []string{"Tuesday", "Thursday"}
-	err = store.SaveAvailability("parent_b", daysB)
+	err = store.SaveAvailability(t.Context(), "parent_b", daysB)
 	require.NoError(t, err)
 
 	// Retrieve availability for parent A
@@ -148,7 +155,7 @@ func TestConfigStore_SaveAndGetAvailability(t *testing.T) {
 
 	// Update availability for parent A
 	newDaysA := []string{"Saturday"}
-	err = store.SaveAvailability("parent_a", newDaysA)
+	err = store.SaveAvailability(t.Context(), "parent_a", newDaysA)
 	require.NoError(t, err)
 
 	// Verify update
@@ -167,7 +174,7 @@ func TestConfigStore_SaveAvailability_EmptyList(t *testing.T) {
 	defer cleanup()
 
 	// Save empty availability list
-	err := store.SaveAvailability("parent_a", []string{})
+	err := store.SaveAvailability(t.Context(), "parent_a", []string{})
 	require.NoError(t, err)
 
 	// Retrieve and verify empty
@@ -176,11 +183,43 @@ func TestConfigStore_SaveAvailability_EmptyList(t *testing.T) {
 	assert.Empty(t, days)
 }
 
+func TestConfigStore_SaveAndGetUnavailabilityRules(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	rules, err := store.GetUnavailabilityRules("parent_a")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	everyOtherFriday, err := config.ParseUnavailabilityRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16")
+	require.NoError(t, err)
+	firstMonday, err := config.ParseUnavailabilityRule("FREQ=MONTHLY;BYDAY=1MO")
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveUnavailabilityRules(t.Context(), "parent_a", []config.UnavailabilityRule{everyOtherFriday, firstMonday, everyOtherFriday}))
+	rules, err = store.GetUnavailabilityRules("parent_a")
+	require.NoError(t, err)
+	assert.Equal(t, []config.UnavailabilityRule{everyOtherFriday, firstMonday}, rules, "saved once, in order")
+
+	rules, err = store.GetUnavailabilityRules("parent_b")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	require.NoError(t, store.SaveUnavailabilityRules(t.Context(), "parent_a", nil))
+	rules, err = store.GetUnavailabilityRules("parent_a")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	assert.Error(t, store.SaveUnavailabilityRules(t.Context(), "parent_g", nil))
+	_, err = store.GetUnavailabilityRules("parent_g")
+	assert.Error(t, err)
+}
+
 func TestConfigStore_GetAvailability_InvalidParent(t *testing.T) {
 	store, cleanup := setupTestConfigStore(t)
 	defer cleanup()
 
-	_, err := store.GetAvailability("parent_c")
+	_, err := store.GetAvailability("parent_g")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid parent identifier")
 }
@@ -190,7 +229,7 @@ func TestConfigStore_SaveAndGetSchedule(t *testing.T) {
 	defer cleanup()
 
 	// Save schedule configuration
-	err := store.SaveSchedule("weekly", 30, 5, constants.StatsOrderDesc)
+	err := store.SaveSchedule(t.Context(), "weekly", 30, 5, constants.StatsOrderDesc)
 	require.NoError(t, err)
 
 	// Retrieve schedule configuration
@@ -202,7 +241,7 @@ func TestConfigStore_SaveAndGetSchedule(t *testing.T) {
 	assert.Equal(t, constants.StatsOrderDesc, statsOrder)
 
 	// Update schedule configuration
-	err = store.SaveSchedule("daily", 7, 3, constants.StatsOrderAsc)
+	err = store.SaveSchedule(t.Context(), "daily", 7, 3, constants.StatsOrderAsc)
 	require.NoError(t, err)
 
 	// Verify update
@@ -243,7 +282,7 @@ func TestConfigStore_SaveSchedule_Validation(t *testing.T) {
 			threshold:   5,
 			statsOrder:  constants.StatsOrderDesc,
 			wantErr:     true,
-			errContains: "must be positive",
+			errContains: "invalid look ahead days",
 		},
 		{
 			name:        "Negative look ahead",
@@ -252,7 +291,7 @@ func TestConfigStore_SaveSchedule_Validation(t *testing.T) {
 			threshold:   5,
 			statsOrder:  constants.StatsOrderDesc,
 			wantErr:     true,
-			errContains: "must be positive",
+			errContains: "invalid look ahead days",
 		},
 		{
 			name:        "Negative threshold",
@@ -261,7 +300,25 @@ func TestConfigStore_SaveSchedule_Validation(t *testing.T) {
 			threshold:   -1,
 			statsOrder:  constants.StatsOrderDesc,
 			wantErr:     true,
-			errContains: "cannot be negative",
+			errContains: "invalid past event threshold days",
+		},
+		{
+			name:        "Too many look ahead days",
+			frequency:   "weekly",
+			lookAhead:   validation.MaxLookAheadDays + 1,
+			threshold:   5,
+			statsOrder:  constants.StatsOrderDesc,
+			wantErr:     true,
+			errContains: "invalid look ahead days",
+		},
+		{
+			name:        "Too many threshold days",
+			frequency:   "weekly",
+			lookAhead:   30,
+			threshold:   validation.MaxPastEventThresholdDays + 1,
+			statsOrder:  constants.StatsOrderDesc,
+			wantErr:     true,
+			errContains: "invalid past event threshold days",
 		},
 		{
 			name:        "Invalid stats order",
@@ -300,7 +357,7 @@ func TestConfigStore_SaveSchedule_Validation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := store.SaveSchedule(tt.frequency, tt.lookAhead, tt.threshold, tt.statsOrder)
+			err := store.SaveSchedule(t.Context(), tt.frequency, tt.lookAhead, tt.threshold, tt.statsOrder)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errContains != "" {
@@ -323,7 +380,7 @@ func TestConfigStore_HasConfiguration(t *testing.T) {
 	assert.False(t, hasConfig)
 
 	// Save parent configuration
-	err = store.SaveParents("Alice", "Bob")
+	err = store.SaveParents(t.Context(), "Alice", "Bob")
 	require.NoError(t, err)
 
 	// Now should have configuration
@@ -337,7 +394,7 @@ func TestConfigStore_GetParentsFull(t *testing.T) {
 	defer cleanup()
 
 	// Save parent configuration
-	err := store.SaveParents("Alice", "Bob")
+	err := store.SaveParents(t.Context(), "Alice", "Bob")
 	require.NoError(t, err)
 
 	// Get full configuration
@@ -356,7 +413,7 @@ func TestConfigStore_GetScheduleFull(t *testing.T) {
 	defer cleanup()
 
 	// Save schedule configuration
-	err := store.SaveSchedule("weekly", 30, 5, constants.StatsOrderDesc)
+	err := store.SaveSchedule(t.Context(), "weekly", 30, 5, constants.StatsOrderDesc)
 	require.NoError(t, err)
 
 	// Get full configuration
@@ -417,11 +474,11 @@ func TestConfigStore_SaveAvailability_Transaction(t *testing.T) {
 	defer cleanup()
 
 	// Save initial availability
-	err := store.SaveAvailability("parent_a", []string{"Monday", "Wednesday"})
+	err := store.SaveAvailability(t.Context(), "parent_a", []string{"Monday", "Wednesday"})
 	require.NoError(t, err)
 
 	// Update with different days
-	err = store.SaveAvailability("parent_a", []string{"Friday"})
+	err = store.SaveAvailability(t.Context(), "parent_a", []string{"Friday"})
 	require.NoError(t, err)
 
 	// Verify only new days exist
@@ -440,3 +497,352 @@ func TestConfigStore_GetAvailability_QueryError(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, days)
 }
+
+func TestConfigStore_SaveEmitsConfigChanged(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	var mu sync.Mutex
+	var sections []string
+	signals.OnConfigChanged(func(_ context.Context, data signals.ConfigChangedData) {
+		mu.Lock()
+		defer mu.Unlock()
+		sections = append(sections, data.Section)
+	}, "test-config-changed")
+	defer signals.ConfigChanged.RemoveListener("test-config-changed")
+
+	require.NoError(t, store.SaveParents(t.Context(), "Alice", "Bob"))
+	require.NoError(t, store.SaveAvailability(t.Context(), "parent_a", []string{"Monday"}))
+	require.NoError(t, store.SaveSchedule(t.Context(), "daily", 30, 5, constants.StatsOrderDesc))
+	require.NoError(t, store.SaveNotifyChannels(t.Context(), map[string]bool{"slack": false}))
+	require.NoError(t, store.SaveChecklistTemplate(t.Context(), []string{"Bath"}))
+	require.NoError(t, store.SaveCommentsInEvents(t.Context(), true))
+	require.NoError(t, store.SaveVacation(t.Context(), config.Vacation{}))
+	require.NoError(t, store.SaveSkipDates(t.Context(), nil))
+	parentAStyle, parentBStyle := config.DefaultParentStyles()
+	require.NoError(t, store.SaveParentStyles(t.Context(), parentAStyle, parentBStyle))
+	require.NoError(t, store.SaveMinRestDays(t.Context(), 2))
+	require.NoError(t, store.SaveDayWeights(t.Context(), config.DayWeights{time.Friday: 2}))
+	require.NoError(t, store.SaveSyncExclusions(t.Context(), config.SyncExclusions{Babysitter: true}))
+	// Rejected writes change nothing
+	require.Error(t, store.SaveParents(t.Context(), "Alice", "Alice"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{signals.ConfigSectionParents, signals.ConfigSectionAvailability, signals.ConfigSectionSchedule, signals.ConfigSectionNotify, signals.ConfigSectionChecklist, signals.ConfigSectionComments, signals.ConfigSectionVacation, signals.ConfigSectionSkipDates, signals.ConfigSectionParents, signals.ConfigSectionRestDays, signals.ConfigSectionDayWeights, signals.ConfigSectionSyncExclude}, sections)
+}
+
+func TestConfigStore_NotifyChannels(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	enabled, err := store.GetNotifyChannels([]string{"slack", "email"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"slack": true, "email": true}, enabled, "channels are enabled until disabled")
+
+	require.NoError(t, store.SaveNotifyChannels(t.Context(), map[string]bool{"slack": false, "email": true}))
+
+	enabled, err = store.GetNotifyChannels([]string{"slack", "email"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"slack": false, "email": true}, enabled)
+
+	slackEnabled, err := store.IsNotifyChannelEnabled("slack")
+	require.NoError(t, err)
+	assert.False(t, slackEnabled)
+
+	enabled, err = store.GetNotifyChannels([]string{"email"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"email": true}, enabled, "channels no longer configured are left out")
+
+	require.NoError(t, store.SaveNotifyChannels(t.Context(), map[string]bool{"slack": true}))
+	slackEnabled, err = store.IsNotifyChannelEnabled("slack")
+	require.NoError(t, err)
+	assert.True(t, slackEnabled)
+}
+
+func TestConfigStore_ChecklistTemplate(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	labels, err := store.GetChecklistTemplate()
+	require.NoError(t, err)
+	assert.Empty(t, labels, "no checklist until one is configured")
+
+	require.NoError(t, store.SaveChecklistTemplate(t.Context(), []string{" Bath ", "Bottle", "", "Bath", "Story"}))
+	labels, err = store.GetChecklistTemplate()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bath", "Bottle", "Story"}, labels)
+
+	require.NoError(t, store.SaveChecklistTemplate(t.Context(), []string{"Story", "Bath"}))
+	labels, err = store.GetChecklistTemplate()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Story", "Bath"}, labels)
+
+	err = store.SaveChecklistTemplate(t.Context(), []string{strings.Repeat("x", MaxChecklistLabelRunes+1)})
+	assert.ErrorIs(t, err, ErrInvalidChecklist)
+}
+
+func TestConfigStore_WeekdayChecklists(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	checklists, err := store.GetWeekdayChecklists()
+	require.NoError(t, err)
+	assert.Empty(t, checklists, "no weekday items until configured")
+
+	require.NoError(t, store.SaveWeekdayChecklists(t.Context(), map[time.Weekday][]string{
+		time.Friday: {" Bath night ", "", "Bath night", "Pajamas"},
+		time.Sunday: {"Nails"},
+		time.Monday: {},
+	}))
+	checklists, err = store.GetWeekdayChecklists()
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Weekday][]string{time.Sunday: {"Nails"}, time.Friday: {"Bath night", "Pajamas"}}, checklists)
+
+	require.NoError(t, store.SaveWeekdayChecklists(t.Context(), map[time.Weekday][]string{time.Friday: {"Pajamas", "Bath night"}}))
+	checklists, err = store.GetWeekdayChecklists()
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Weekday][]string{time.Friday: {"Pajamas", "Bath night"}}, checklists)
+
+	err = store.SaveWeekdayChecklists(t.Context(), map[time.Weekday][]string{time.Friday: {strings.Repeat("x", MaxChecklistLabelRunes+1)}})
+	assert.ErrorIs(t, err, ErrInvalidChecklist)
+	err = store.SaveWeekdayChecklists(t.Context(), map[time.Weekday][]string{7: {"Bath"}})
+	assert.ErrorIs(t, err, ErrInvalidChecklist)
+}
+
+func TestConfigStore_CommentsInEvents(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	enabled, err := store.GetCommentsInEvents()
+	require.NoError(t, err)
+	assert.False(t, enabled, "comments stay out of the calendar events until enabled")
+
+	require.NoError(t, store.SaveCommentsInEvents(t.Context(), true))
+	enabled, err = store.GetCommentsInEvents()
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	require.NoError(t, store.SaveCommentsInEvents(t.Context(), false))
+	enabled, err = store.GetCommentsInEvents()
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestConfigStore_Vacation(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	vacation, err := store.GetVacation()
+	require.NoError(t, err)
+	assert.False(t, vacation.Enabled, "no vacation until saved")
+
+	saved := config.Vacation{
+		Enabled: true,
+		Start:   time.Date(2026, time.July, 10, 0, 0, 0, 0, time.Local),
+		End:     time.Date(2026, time.July, 20, 0, 0, 0, 0, time.Local),
+	}
+	require.NoError(t, store.SaveVacation(t.Context(), saved))
+	vacation, err = store.GetVacation()
+	require.NoError(t, err)
+	assert.Equal(t, saved, vacation)
+
+	// Disabling keeps the dates for the next time
+	saved.Enabled = false
+	require.NoError(t, store.SaveVacation(t.Context(), saved))
+	vacation, err = store.GetVacation()
+	require.NoError(t, err)
+	assert.Equal(t, saved, vacation)
+
+	err = store.SaveVacation(t.Context(), config.Vacation{Enabled: true, Start: saved.End, End: saved.Start})
+	assert.ErrorIs(t, err, ErrInvalidVacation)
+}
+
+func TestConfigStore_MinRestDays(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	days, err := store.GetMinRestDays()
+	require.NoError(t, err)
+	assert.Zero(t, days, "no rest enforced until saved")
+
+	require.NoError(t, store.SaveMinRestDays(t.Context(), 2))
+	days, err = store.GetMinRestDays()
+	require.NoError(t, err)
+	assert.Equal(t, 2, days)
+
+	assert.ErrorIs(t, store.SaveMinRestDays(t.Context(), -1), validation.ErrInvalidMinRestDays)
+	assert.ErrorIs(t, store.SaveMinRestDays(t.Context(), validation.MaxMinRestDays+1), validation.ErrInvalidMinRestDays)
+	days, err = store.GetMinRestDays()
+	require.NoError(t, err)
+	assert.Equal(t, 2, days, "rejected values change nothing")
+}
+
+func TestConfigStore_DayWeights(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	weights, err := store.GetDayWeights()
+	require.NoError(t, err)
+	assert.Empty(t, weights, "every night counts 1 until saved")
+
+	require.NoError(t, store.SaveDayWeights(t.Context(), config.DayWeights{time.Friday: 1.5, time.Saturday: 2, time.Monday: 1}))
+	weights, err = store.GetDayWeights()
+	require.NoError(t, err)
+	assert.Equal(t, config.DayWeights{time.Friday: 1.5, time.Saturday: 2}, weights, "days weighted 1 are not stored")
+
+	assert.ErrorIs(t, store.SaveDayWeights(t.Context(), config.DayWeights{time.Sunday: 0.25}), validation.ErrInvalidDayWeight)
+	assert.ErrorIs(t, store.SaveDayWeights(t.Context(), config.DayWeights{time.Sunday: validation.MaxDayWeight + 1}), validation.ErrInvalidDayWeight)
+	weights, err = store.GetDayWeights()
+	require.NoError(t, err)
+	assert.Equal(t, config.DayWeights{time.Friday: 1.5, time.Saturday: 2}, weights, "rejected values change nothing")
+
+	require.NoError(t, store.SaveDayWeights(t.Context(), nil))
+	weights, err = store.GetDayWeights()
+	require.NoError(t, err)
+	assert.Empty(t, weights)
+}
+
+func TestConfigStore_LookAheadWindows(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	windows, err := store.GetLookAheadWindows()
+	require.NoError(t, err)
+	assert.Zero(t, windows, "no window until the schedule is saved")
+	require.Error(t, store.SaveLookAheadWindows(t.Context(), config.LookAheadWindows{Webhook: 14}), "no schedule to update")
+
+	require.NoError(t, store.SaveSchedule(t.Context(), "weekly", 30, 5, constants.StatsOrderDesc))
+	windows, err = store.GetLookAheadWindows()
+	require.NoError(t, err)
+	assert.Zero(t, windows, "the migration uses the look ahead days for every trigger")
+
+	saved := config.LookAheadWindows{Scheduled: 60, Webhook: 14}
+	require.NoError(t, store.SaveLookAheadWindows(t.Context(), saved))
+	windows, err = store.GetLookAheadWindows()
+	require.NoError(t, err)
+	assert.Equal(t, saved, windows)
+
+	assert.ErrorIs(t, store.SaveLookAheadWindows(t.Context(), config.LookAheadWindows{Manual: validation.MaxLookAheadDays + 1}), validation.ErrInvalidLookAheadDays)
+	require.NoError(t, store.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc))
+	windows, err = store.GetLookAheadWindows()
+	require.NoError(t, err)
+	assert.Equal(t, saved, windows, "saving the schedule keeps the windows")
+}
+
+func TestConfigStore_SkipDates(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	skipDates, err := store.GetSkipDates()
+	require.NoError(t, err)
+	assert.Empty(t, skipDates, "no skip date until saved")
+
+	single, err := config.ParseSkipDate("2026-10-24")
+	require.NoError(t, err)
+	secondSaturday, err := config.ParseSkipDate("FREQ=MONTHLY;BYDAY=2SA")
+	require.NoError(t, err)
+	require.NoError(t, store.SaveSkipDates(t.Context(), config.SkipDates{single, secondSaturday, single}))
+
+	skipDates, err = store.GetSkipDates()
+	require.NoError(t, err)
+	assert.Equal(t, config.SkipDates{single, secondSaturday}, skipDates, "an entry listed twice is saved once")
+
+	require.NoError(t, store.SaveSkipDates(t.Context(), nil))
+	skipDates, err = store.GetSkipDates()
+	require.NoError(t, err)
+	assert.Empty(t, skipDates)
+}
+
+func TestConfigStore_SyncExclusions(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	exclusions, err := store.GetSyncExclusions()
+	require.NoError(t, err)
+	assert.True(t, exclusions.IsEmpty(), "every night is synced until saved")
+
+	require.NoError(t, store.SaveSyncExclusions(t.Context(), config.SyncExclusions{Babysitter: true, Tags: []string{"sick_kid", "parent_away", "sick_kid"}}))
+	exclusions, err = store.GetSyncExclusions()
+	require.NoError(t, err)
+	assert.Equal(t, config.SyncExclusions{Babysitter: true, Tags: []string{"sick_kid", "parent_away"}}, exclusions, "a tag listed twice is saved once")
+
+	require.NoError(t, store.SaveSyncExclusions(t.Context(), config.SyncExclusions{}))
+	exclusions, err = store.GetSyncExclusions()
+	require.NoError(t, err)
+	assert.True(t, exclusions.IsEmpty())
+}
+
+func TestConfigStore_ParentStyles(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	parentA, parentB, err := store.GetParentStyles()
+	require.NoError(t, err)
+	defaultA, defaultB := config.DefaultParentStyles()
+	assert.Equal(t, defaultA, parentA, "defaults before the parents are saved")
+	assert.Equal(t, defaultB, parentB)
+
+	fox := config.ParentStyle{Color: constants.ParentColorSage, Avatar: "🦊"}
+	initials := config.ParentStyle{Color: constants.ParentColorTomato, Avatar: "BO"}
+	require.Error(t, store.SaveParentStyles(t.Context(), fox, initials), "no parent to style")
+
+	require.NoError(t, store.SaveParents(t.Context(), "Alice", "Bob"))
+	parentA, parentB, err = store.GetParentStyles()
+	require.NoError(t, err)
+	assert.Equal(t, defaultA, parentA, "defaults of the migration")
+	assert.Equal(t, defaultB, parentB)
+
+	require.NoError(t, store.SaveParentStyles(t.Context(), fox, initials))
+	parentA, parentB, err = store.GetParentStyles()
+	require.NoError(t, err)
+	assert.Equal(t, fox, parentA)
+	assert.Equal(t, initials, parentB)
+
+	require.NoError(t, store.SaveParents(t.Context(), "Carol", "Dave"))
+	parentA, _, err = store.GetParentStyles()
+	require.NoError(t, err)
+	assert.Equal(t, fox, parentA, "renaming the parents keeps their styles")
+
+	err = store.SaveParentStyles(t.Context(), config.ParentStyle{Color: "pink"}, initials)
+	assert.ErrorIs(t, err, config.ErrInvalidParentStyle)
+}
+
+func TestConfigStore_ExtraParents(t *testing.T) {
+	store, cleanup := setupTestConfigStore(t)
+	defer cleanup()
+
+	extra, err := store.GetExtraParents()
+	require.NoError(t, err)
+	assert.Empty(t, extra)
+
+	grandma := config.ExtraParent{Name: "Grandma", Style: config.ParentStyle{Color: constants.ParentColorBasil, Avatar: "👵"}}
+	grandpa := config.ExtraParent{Name: "Grandpa", Style: config.DefaultExtraParentStyle(3)}
+	require.Error(t, store.SaveExtraParents(t.Context(), []config.ExtraParent{grandma}), "no parents to add to")
+
+	require.NoError(t, store.SaveParents(t.Context(), "Alice", "Bob"))
+	require.NoError(t, store.SaveExtraParents(t.Context(), []config.ExtraParent{grandma, grandpa}))
+	extra, err = store.GetExtraParents()
+	require.NoError(t, err)
+	assert.Equal(t, []config.ExtraParent{grandma, grandpa}, extra)
+
+	assert.ErrorIs(t, store.SaveExtraParents(t.Context(), []config.ExtraParent{{Name: "Bob", Style: grandpa.Style}}), validation.ErrSameParents)
+	assert.ErrorIs(t, store.SaveParents(t.Context(), "Alice", "Grandma"), validation.ErrSameParents, "a parent renamed like an extra parent")
+	assert.ErrorIs(t, store.SaveExtraParents(t.Context(), []config.ExtraParent{{Name: "Pink", Style: config.ParentStyle{Color: "pink"}}}), config.ErrInvalidParentStyle)
+	tooMany := make([]config.ExtraParent, validation.MaxParents-1)
+	for i := range tooMany {
+		tooMany[i] = config.ExtraParent{Name: string(rune('C' + i)), Style: grandpa.Style}
+	}
+	assert.ErrorIs(t, store.SaveExtraParents(t.Context(), tooMany), validation.ErrTooManyParents)
+
+	// The settings follow the position: removing Grandpa deletes those of parent_d
+	require.NoError(t, store.SaveAvailability(t.Context(), "parent_c", []string{"Monday"}))
+	require.NoError(t, store.SaveAvailability(t.Context(), "parent_d", []string{"Tuesday"}))
+	require.NoError(t, store.SaveExtraParents(t.Context(), []config.ExtraParent{grandma}))
+	days, err := store.GetAvailability("parent_c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Monday"}, days)
+	days, err = store.GetAvailability("parent_d")
+	require.NoError(t, err)
+	assert.Empty(t, days)
+}
@@ -3,18 +3,24 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"embed"
 	"errors" // Import errors package for Join
 	"fmt"
 	"io/fs"
+	"os"
 
+	"github.com/XSAM/otelsql"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
-	_ "modernc.org/sqlite" // Register modernc sqlite driver
+	"modernc.org/sqlite" // Registers the modernc sqlite driver
 
 	"github.com/belphemur/night-routine/internal/database/sqlite3"
 	"github.com/belphemur/night-routine/internal/logging"
 	"github.com/rs/zerolog"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 //go:embed migrations
@@ -37,7 +43,29 @@ func New(opts SQLiteOptions) (*DB, error) {
 	connStr := opts.buildConnectionString()
 	logger := logging.GetLogger("database").With().Str("db_path", opts.Path).Logger() // Use opts.Path for logging
 	logger.Info().Str("connection_string", connStr).Msg("Opening database connection")
-	conn, err := sql.Open("sqlite", connStr)
+	otelOpts := []otelsql.Option{
+		otelsql.WithAttributes(semconv.DBSystemNameSQLite),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			// Only trace queries running inside a traced operation (sync, webhook, request),
+			// otherwise every background query would start a trace of its own
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	}
+	var conn *sql.DB
+	var err error
+	if opts.SlowQueryThreshold > 0 {
+		logger.Debug().Dur("threshold", opts.SlowQueryThreshold).Msg("Logging slow queries")
+		conn = otelsql.OpenDB(&slowQueryConnector{
+			dsn:       connStr,
+			driver:    &sqlite.Driver{},
+			threshold: opts.SlowQueryThreshold,
+			logger:    logger,
+		}, otelOpts...)
+	} else {
+		conn, err = otelsql.Open("sqlite", connStr, otelOpts...)
+	}
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to open database")
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -236,7 +264,15 @@ func (db *DB) beginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 // WithTransaction executes a function within a database transaction
 // If the function returns an error, the transaction is rolled back
 // Otherwise, the transaction is committed
+// A transaction failing on a transient lock is retried as a whole, fn must only act through tx
 func (db *DB) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) error {
+	return RetryOnBusy(ctx, func() error {
+		return db.runTransaction(ctx, fn)
+	})
+}
+
+// runTransaction runs fn within a single transaction attempt
+func (db *DB) runTransaction(ctx context.Context, fn func(*sql.Tx) error) error {
 	tx, err := db.beginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -281,15 +317,121 @@ func (db *DB) Close() error {
 	return nil
 }
 
-// MigrateDatabase performs database migrations
-func (db *DB) MigrateDatabase() error {
-	db.logger.Info().Msg("Starting database migration")
+// Backup writes a consistent snapshot of the database to destPath using VACUUM INTO.
+// The destination must not already exist.
+func (db *DB) Backup(ctx context.Context, destPath string) error {
+	backupLogger := db.logger.With().Str("destination", destPath).Logger()
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup destination %s already exists", destPath)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check backup destination: %w", err)
+	}
+
+	backupLogger.Info().Msg("Starting database backup")
+	if _, err := db.conn.ExecContext(ctx, "VACUUM INTO ?", destPath); err != nil {
+		backupLogger.Error().Err(err).Msg("Failed to back up database")
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	backupLogger.Info().Msg("Database backup completed")
+	return nil
+}
+
+// Size returns the size of the database in bytes, its pages in use and free, without the write-ahead log
+func (db *DB) Size(ctx context.Context) (int64, error) {
+	var size int64
+	if err := db.conn.QueryRowContext(ctx, "SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()").Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to read database size: %w", err)
+	}
+	return size, nil
+}
+
+// Wipe deletes the rows of every table but the schema version, leaving the database as after its
+// migrations, then rewrites the file so that the deleted data does not linger in its free pages or
+// in the write-ahead log. The full-text indexes follow their tables through their triggers.
+func (db *DB) Wipe(ctx context.Context) error {
+	db.logger.Warn().Msg("Wiping every table of the database")
+	err := db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		// The rows referencing each other are all deleted, check the foreign keys at commit only
+		if _, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+			return fmt.Errorf("failed to defer foreign keys: %w", err)
+		}
+		tables, err := wipedTables(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for _, table := range tables {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM "`+table+`"`); err != nil {
+				return fmt.Errorf("failed to wipe table %s: %w", table, err)
+			}
+		}
+		db.logger.Info().Int("tables", len(tables)).Msg("Tables wiped")
+		return nil
+	})
+	if err != nil {
+		db.logger.Error().Err(err).Msg("Failed to wipe the database")
+		return err
+	}
+
+	if _, err := db.conn.ExecContext(ctx, "VACUUM"); err != nil {
+		db.logger.Error().Err(err).Msg("Failed to vacuum the wiped database")
+		return fmt.Errorf("failed to vacuum the database: %w", err)
+	}
+	if err := db.Checkpoint(ctx); err != nil {
+		return err
+	}
+	db.logger.Warn().Msg("Database wiped")
+	return nil
+}
+
+// Checkpoint writes the write-ahead log back into the database file and truncates it
+func (db *DB) Checkpoint(ctx context.Context) error {
+	if _, err := db.conn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		db.logger.Error().Err(err).Msg("Failed to truncate the write-ahead log")
+		return fmt.Errorf("failed to truncate the write-ahead log: %w", err)
+	}
+	return nil
+}
+
+// wipedTables returns the ordinary tables holding data, leaving out the schema version, the
+// internal tables of SQLite and the virtual tables with their shadow tables, and adding the
+// sequences of the AUTOINCREMENT keys
+func wipedTables(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM pragma_table_list WHERE schema = 'main' AND type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_migrations' ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tables: %w", err)
+	}
+
+	var sequences int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_sequence'`).Scan(&sequences); err != nil {
+		return nil, fmt.Errorf("failed to look for the sequences: %w", err)
+	}
+	if sequences > 0 {
+		tables = append(tables, "sqlite_sequence")
+	}
+	return tables, nil
+}
+
+// newMigrator creates a migrator over the embedded migrations
+func (db *DB) newMigrator() (*migrate.Migrate, source.Driver, error) {
 	// Create a new instance of the SQLite driver
 	db.logger.Debug().Msg("Creating migration driver instance")
 	driver, err := sqlite3.WithInstance(db.conn, &sqlite3.Config{})
 	if err != nil {
 		db.logger.Error().Err(err).Msg("Failed to create database driver for migration")
-		return fmt.Errorf("failed to create database driver: %w", err)
+		return nil, nil, fmt.Errorf("failed to create database driver: %w", err)
 	}
 
 	// Extract the sub-filesystem containing only the migrations
@@ -297,7 +439,7 @@ func (db *DB) MigrateDatabase() error {
 	subFS, err := fs.Sub(migrationsFS, "migrations/sqlite")
 	if err != nil {
 		db.logger.Error().Err(err).Msg("Failed to create sub-filesystem for migrations")
-		return fmt.Errorf("failed to create sub-filesystem: %w", err)
+		return nil, nil, fmt.Errorf("failed to create sub-filesystem: %w", err)
 	}
 
 	// Create a new instance of the embed source driver
@@ -305,7 +447,7 @@ func (db *DB) MigrateDatabase() error {
 	sourceInstance, err := iofs.New(subFS, ".")
 	if err != nil {
 		db.logger.Error().Err(err).Msg("Failed to create embedded file source for migration")
-		return fmt.Errorf("failed to create embedded file source: %w", err)
+		return nil, nil, fmt.Errorf("failed to create embedded file source: %w", err)
 	}
 
 	// Create a new instance of the migrator
@@ -313,7 +455,91 @@ func (db *DB) MigrateDatabase() error {
 	m, err := migrate.NewWithInstance("iofs", sourceInstance, "sqlite", driver)
 	if err != nil {
 		db.logger.Error().Err(err).Msg("Failed to create migrator instance")
-		return fmt.Errorf("failed to create migrator: %w", err)
+		return nil, nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	return m, sourceInstance, nil
+}
+
+// MigrationStatus describes the schema version of the database
+type MigrationStatus struct {
+	Current uint   // applied version, 0 when no migration ran
+	Latest  uint   // latest version embedded in the binary
+	Dirty   bool   // a migration failed halfway
+	Waiting []uint // versions of the migrations left to apply, in order
+}
+
+// Pending reports whether migrations remain to be applied
+func (s MigrationStatus) Pending() bool {
+	return s.Current < s.Latest
+}
+
+// GetMigrationStatus reports the applied and latest available schema versions without migrating
+func (db *DB) GetMigrationStatus() (MigrationStatus, error) {
+	m, sourceInstance, err := db.newMigrator()
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+
+	var status MigrationStatus
+	current, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return MigrationStatus{}, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	status.Current = current
+	status.Dirty = dirty
+
+	latest, err := sourceInstance.First()
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	for {
+		if latest > status.Current {
+			status.Waiting = append(status.Waiting, latest)
+		}
+		next, err := sourceInstance.Next(latest)
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return MigrationStatus{}, fmt.Errorf("failed to read migrations: %w", err)
+		}
+		latest = next
+	}
+	status.Latest = latest
+
+	return status, nil
+}
+
+// CheckIntegrity runs SQLite's integrity check and returns the problems it reports
+func (db *DB) CheckIntegrity(ctx context.Context) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check result: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed during integrity check: %w", err)
+	}
+	return problems, nil
+}
+
+// MigrateDatabase performs database migrations
+func (db *DB) MigrateDatabase() error {
+	db.logger.Info().Msg("Starting database migration")
+	m, _, err := db.newMigrator()
+	if err != nil {
+		return err
 	}
 
 	// Get current migration version
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -312,3 +313,137 @@ func TestBeginTxPrivate(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+// TestBackup verifies that a backup is a usable copy of the database
+func TestBackup(t *testing.T) {
+	dir := t.TempDir()
+	db, err := New(NewDefaultOptions(filepath.Join(dir, "source.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	_, err = db.conn.Exec(`
+		INSERT INTO assignments (parent_name, assignment_date, override, decision_reason)
+		VALUES (?, ?, ?, ?)
+	`, "BackupParent", "2024-03-01", false, "test_reason")
+	require.NoError(t, err)
+
+	backupPath := filepath.Join(dir, "backup.db")
+	require.NoError(t, db.Backup(context.Background(), backupPath))
+
+	backup, err := New(NewDefaultOptions(backupPath))
+	require.NoError(t, err)
+	defer backup.Close()
+
+	var parent string
+	require.NoError(t, backup.conn.QueryRow("SELECT parent_name FROM assignments WHERE assignment_date = ?", "2024-03-01").Scan(&parent))
+	assert.Equal(t, "BackupParent", parent)
+
+	// An existing destination is never overwritten
+	assert.Error(t, db.Backup(context.Background(), backupPath))
+}
+
+func TestSize(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "size.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	size, err := db.Size(context.Background())
+	require.NoError(t, err)
+	var pageSize int64
+	require.NoError(t, db.conn.QueryRow("PRAGMA page_size").Scan(&pageSize))
+	assert.Positive(t, size)
+	assert.Zero(t, size%pageSize, "the size is a whole number of pages")
+}
+
+// TestGetMigrationStatus verifies pending migrations are reported before and not after migrating
+func TestGetMigrationStatus(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "status.db")))
+	require.NoError(t, err)
+	defer db.Close()
+
+	status, err := db.GetMigrationStatus()
+	require.NoError(t, err)
+	assert.Zero(t, status.Current)
+	assert.Positive(t, status.Latest)
+	assert.True(t, status.Pending())
+	require.NotEmpty(t, status.Waiting)
+	assert.Equal(t, uint(1), status.Waiting[0])
+	assert.Equal(t, status.Latest, status.Waiting[len(status.Waiting)-1])
+
+	require.NoError(t, db.MigrateDatabase())
+
+	status, err = db.GetMigrationStatus()
+	require.NoError(t, err)
+	assert.Equal(t, status.Latest, status.Current)
+	assert.False(t, status.Dirty)
+	assert.False(t, status.Pending())
+	assert.Empty(t, status.Waiting)
+}
+
+// TestCheckIntegrity verifies a healthy database reports no problem
+func TestCheckIntegrity(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "integrity.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	problems, err := db.CheckIntegrity(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+// TestWipe verifies every table is emptied while the schema stays migrated and usable
+func TestWipe(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "wipe.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	result, err := db.conn.Exec(`
+		INSERT INTO assignments (parent_name, assignment_date, override, decision_reason)
+		VALUES (?, ?, ?, ?)
+	`, "WipedParent", "2024-03-01", false, "test_reason")
+	require.NoError(t, err)
+	assignmentID, err := result.LastInsertId()
+	require.NoError(t, err)
+	comments, err := NewCommentStore(db)
+	require.NoError(t, err)
+	_, err = comments.AddComment(assignmentID, "WipedParent", "Bath skipped, fever")
+	require.NoError(t, err)
+	configStore, err := NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveParents(t.Context(), "WipedParent", "OtherParent"))
+	links, err := NewParentLinkStore(db)
+	require.NoError(t, err)
+	_, err = links.CreateLink("parent_a", time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, db.Wipe(context.Background()))
+
+	tables, err := db.conn.Query(`SELECT name FROM pragma_table_list WHERE schema = 'main' AND type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_migrations'`)
+	require.NoError(t, err)
+	var names []string
+	for tables.Next() {
+		var name string
+		require.NoError(t, tables.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, tables.Err())
+	tables.Close()
+	require.NotEmpty(t, names)
+	for _, name := range names {
+		var count int
+		require.NoError(t, db.conn.QueryRow(`SELECT COUNT(*) FROM "`+name+`"`).Scan(&count))
+		assert.Zero(t, count, name)
+	}
+	var matches int
+	require.NoError(t, db.conn.QueryRow(`SELECT COUNT(*) FROM assignment_comments_fts WHERE assignment_comments_fts MATCH 'fever'`).Scan(&matches))
+	assert.Zero(t, matches, "the full-text index follows its table")
+
+	status, err := db.GetMigrationStatus()
+	require.NoError(t, err)
+	assert.False(t, status.Pending(), "the schema version is kept")
+	require.NoError(t, configStore.SaveParents(t.Context(), "NewParent", "OtherParent"), "the database is usable again")
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/rs/zerolog"
+)
+
+// eventRetention is how long domain events are kept before being purged
+const eventRetention = 90 * 24 * time.Hour
+
+// EventStore persists the domain events of the event bus in SQLite
+type EventStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+var _ signals.EventStore = (*EventStore)(nil)
+
+// NewEventStore creates a new event store
+func NewEventStore(db *DB) (*EventStore, error) {
+	logger := logging.GetLogger("event-store")
+	return &EventStore{db: db.Conn(), logger: logger}, nil
+}
+
+// AppendEvent stores event and returns its identifier
+func (s *EventStore) AppendEvent(ctx context.Context, event signals.Event) (int64, error) {
+	s.logger.Debug().Str("type", string(event.Type)).Msg("Appending domain event")
+	payload := string(event.Payload)
+	if payload == "" {
+		payload = "{}"
+	}
+
+	result, err := execWithRetry(ctx, s.db, `
+	INSERT INTO domain_events (type, payload, occurred_at)
+	VALUES (?, ?, ?)`, string(event.Type), payload, event.OccurredAt.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return 0, fmt.Errorf("failed to append domain event: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get domain event ID: %w", err)
+	}
+	return id, nil
+}
+
+// PruneEvents deletes the events older than the retention period at now and returns how many
+func (s *EventStore) PruneEvents(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := now.UTC().Add(-eventRetention).Format(time.RFC3339Nano)
+	result, err := execWithRetry(ctx, s.db, `DELETE FROM domain_events WHERE occurred_at < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge old domain events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ListEvents returns at most limit events stored after the event afterID, oldest first
+func (s *EventStore) ListEvents(ctx context.Context, afterID int64, limit int) ([]signals.Event, error) {
+	s.logger.Debug().Int64("after_id", afterID).Int("limit", limit).Msg("Listing domain events")
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT id, type, payload, occurred_at
+	FROM domain_events
+	WHERE id > ?
+	ORDER BY id
+	LIMIT ?`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query domain events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []signals.Event{}
+	for rows.Next() {
+		var event signals.Event
+		var eventType, payload, occurredAtStr string
+		if err := rows.Scan(&event.ID, &eventType, &payload, &occurredAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan domain event: %w", err)
+		}
+		event.Type = signals.EventType(eventType)
+		event.Payload = []byte(payload)
+		event.OccurredAt, err = time.Parse(time.RFC3339Nano, occurredAtStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse domain event time: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate domain events: %w", err)
+	}
+	return events, nil
+}
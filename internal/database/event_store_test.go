@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestEventStore(t *testing.T) (*EventStore, *DB) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_events.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewEventStore(db)
+	require.NoError(t, err, "Failed to create event store")
+	return store, db
+}
+
+func TestEventStore_AppendAndList(t *testing.T) {
+	store, _ := setupTestEventStore(t)
+	ctx := context.Background()
+	occurredAt := time.Date(2026, 10, 15, 20, 30, 0, 123, time.UTC)
+
+	firstID, err := store.AppendEvent(ctx, signals.Event{
+		Type:       signals.EventCalendarSelected,
+		Payload:    json.RawMessage(`{"calendar_id":"family@group.calendar.google.com"}`),
+		OccurredAt: occurredAt,
+	})
+	require.NoError(t, err)
+	secondID, err := store.AppendEvent(ctx, signals.Event{Type: signals.EventTokenSetup, OccurredAt: occurredAt})
+	require.NoError(t, err)
+	assert.Greater(t, secondID, firstID)
+
+	events, err := store.ListEvents(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, firstID, events[0].ID)
+	assert.Equal(t, signals.EventCalendarSelected, events[0].Type)
+	assert.JSONEq(t, `{"calendar_id":"family@group.calendar.google.com"}`, string(events[0].Payload))
+	assert.True(t, occurredAt.Equal(events[0].OccurredAt))
+	assert.JSONEq(t, `{}`, string(events[1].Payload), "an empty payload is stored as an empty object")
+
+	events, err = store.ListEvents(ctx, firstID, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, secondID, events[0].ID)
+
+	events, err = store.ListEvents(ctx, 0, 1)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestEventStore_PurgesOldEvents(t *testing.T) {
+	store, db := setupTestEventStore(t)
+	old := time.Now().UTC().Add(-eventRetention - time.Hour).Format(time.RFC3339Nano)
+	_, err := db.Conn().Exec(`INSERT INTO domain_events (type, payload, occurred_at) VALUES ('token_setup', '{}', ?)`, old)
+	require.NoError(t, err)
+
+	_, err = store.AppendEvent(context.Background(), signals.Event{Type: signals.EventTokenSetup, OccurredAt: time.Now()})
+	require.NoError(t, err)
+
+	events, err := store.ListEvents(context.Background(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2, "appending does not purge")
+
+	pruned, err := store.PruneEvents(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	events, err = store.ListEvents(context.Background(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(2), events[0].ID)
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// FeatureFlagStore stores the feature flags set from the admin API (feature_flags table). The known
+// flags and their defaults are defined by the features package; this store only keeps the flags set.
+type FeatureFlagStore struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewFeatureFlagStore creates a new feature flag store
+func NewFeatureFlagStore(db *DB) (*FeatureFlagStore, error) {
+	logger := logging.GetLogger("feature-flag-store")
+	return &FeatureFlagStore{db: db, logger: logger}, nil
+}
+
+// GetFeatureFlag returns whether the flag named name is enabled, false for ok when it was never set
+func (s *FeatureFlagStore) GetFeatureFlag(name string) (bool, bool, error) {
+	var enabled bool
+	err := s.db.Conn().QueryRow(`SELECT enabled FROM feature_flags WHERE name = ?`, name).Scan(&enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("flag", name).Msg("Failed to read feature flag")
+		return false, false, fmt.Errorf("failed to read feature flag %s: %w", name, err)
+	}
+	return enabled, true, nil
+}
+
+// ListFeatureFlags returns the state of every flag set
+func (s *FeatureFlagStore) ListFeatureFlags() (map[string]bool, error) {
+	rows, err := s.db.Conn().Query(`SELECT name, enabled FROM feature_flags`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query feature flags")
+		return nil, fmt.Errorf("failed to retrieve feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var enabled bool
+		if err := rows.Scan(&name, &enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag: %w", err)
+		}
+		flags[name] = enabled
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// SetFeatureFlag enables or disables the flag named name at now
+func (s *FeatureFlagStore) SetFeatureFlag(name string, enabled bool, now time.Time) error {
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `
+	INSERT INTO feature_flags (name, enabled, updated_at) VALUES (?, ?, ?)
+	ON CONFLICT(name) DO UPDATE SET enabled = excluded.enabled, updated_at = excluded.updated_at`,
+		name, enabled, now.UTC().Format(time.RFC3339)); err != nil {
+		s.logger.Error().Err(err).Str("flag", name).Msg("Failed to set feature flag")
+		return fmt.Errorf("failed to set feature flag %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteFeatureFlag forgets the flag named name, if set, giving it its default state back
+func (s *FeatureFlagStore) DeleteFeatureFlag(name string) error {
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `DELETE FROM feature_flags WHERE name = ?`, name); err != nil {
+		s.logger.Error().Err(err).Str("flag", name).Msg("Failed to delete feature flag")
+		return fmt.Errorf("failed to delete feature flag %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagStore(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_feature_flags.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewFeatureFlagStore(db)
+	require.NoError(t, err)
+
+	_, ok, err := store.GetFeatureFlag("ics_feed")
+	require.NoError(t, err)
+	assert.False(t, ok, "a flag never set has no state")
+
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SetFeatureFlag("ics_feed", true, now))
+	require.NoError(t, store.SetFeatureFlag("webhook_polling", true, now))
+	require.NoError(t, store.SetFeatureFlag("webhook_polling", false, now.Add(time.Hour)))
+
+	enabled, ok, err := store.GetFeatureFlag("ics_feed")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, enabled)
+
+	flags, err := store.ListFeatureFlags()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"ics_feed": true, "webhook_polling": false}, flags)
+
+	require.NoError(t, store.DeleteFeatureFlag("ics_feed"))
+	require.NoError(t, store.DeleteFeatureFlag("unknown"), "deleting a flag never set is not an error")
+	_, ok, err = store.GetFeatureFlag("ics_feed")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"golang.org/x/oauth2"
+)
+
+// TokenStoreInterface persists the Google connection: the OAuth token, the selected calendar, the
+// pending OAuth states and the notification channels. TokenStore is the SQLite implementation; an
+// alternative backend implements it to be given to the handlers and the calendar service instead.
+type TokenStoreInterface interface {
+	// SaveToken saves the OAuth token, replacing the previous one
+	SaveToken(token *oauth2.Token) error
+	// GetToken returns the saved OAuth token, nil when there is none
+	GetToken() (*oauth2.Token, error)
+	// ClearToken removes the saved OAuth token
+	ClearToken() error
+
+	// SaveSelectedCalendar saves the selected calendar ID with an empty name
+	SaveSelectedCalendar(calendarID string) error
+	// SaveSelectedCalendarWithName saves the selected calendar ID and name
+	SaveSelectedCalendarWithName(calendarID string, calendarName string) error
+	// GetSelectedCalendar returns the selected calendar ID, empty when none is selected
+	GetSelectedCalendar() (string, error)
+	// GetSelectedCalendarWithName returns the selected calendar ID and name
+	GetSelectedCalendarWithName() (calendarID string, calendarName string, err error)
+	// ClearSelectedCalendar removes the calendar selection
+	ClearSelectedCalendar() error
+
+	// SaveOAuthState stores a pending OAuth state value until expiresAt
+	SaveOAuthState(state string, expiresAt time.Time) error
+	// ConsumeOAuthState deletes a pending OAuth state and reports whether it existed and had not
+	// expired. A state can only be consumed once.
+	ConsumeOAuthState(state string) (bool, error)
+
+	// SaveNotificationChannel saves a notification channel
+	SaveNotificationChannel(channel *NotificationChannel) error
+	// GetNotificationChannelByID returns a notification channel by its ID, nil when unknown
+	GetNotificationChannelByID(id string) (*NotificationChannel, error)
+	// GetActiveNotificationChannels returns the notification channels not expired yet
+	GetActiveNotificationChannels() ([]*NotificationChannel, error)
+	// ListNotificationChannels returns every notification channel, expired ones included, ordered by expiration
+	ListNotificationChannels() ([]*NotificationChannel, error)
+	// RecordNotificationReceived records that Google delivered a notification on the channel at receivedAt
+	RecordNotificationReceived(id string, receivedAt time.Time) error
+	// RecordNotificationChannelVerification records the outcome of the verification of the channel at verifiedAt
+	RecordNotificationChannelVerification(id string, status ChannelVerificationStatus, verifiedAt time.Time) error
+	// DeleteNotificationChannel deletes a notification channel by its ID
+	DeleteNotificationChannel(id string) error
+	// DeleteExpiredNotificationChannels deletes the expired notification channels
+	DeleteExpiredNotificationChannels() error
+}
+
+// Ensure TokenStore implements TokenStoreInterface
+var _ TokenStoreInterface = (*TokenStore)(nil)
+
+// ConfigStoreInterface persists the runtime configuration edited from the web interface and seeded
+// from the configuration file. ConfigStore is the SQLite implementation. Every write validates its
+// values and emits signals.ConfigChanged, so that the config.Cache serving the reads is reloaded.
+type ConfigStoreInterface interface {
+	// GetParents returns the names of the parents, empty when never saved
+	GetParents() (parentA, parentB string, err error)
+	// GetParentsFull returns the parents with their metadata, nil when never saved
+	GetParentsFull() (*ConfigParents, error)
+	// SaveParents saves the names of the parents
+	SaveParents(ctx context.Context, parentA, parentB string) error
+	// GetExtraParents returns the parents taking turns after parent A and parent B, in order
+	GetExtraParents() ([]config.ExtraParent, error)
+	// SaveExtraParents replaces the parents taking turns after parent A and parent B, once the
+	// parents are saved, deleting the settings of the positions left empty
+	SaveExtraParents(ctx context.Context, parents []config.ExtraParent) error
+	// GetParentStyles returns the color and avatar of each parent
+	GetParentStyles() (parentA, parentB config.ParentStyle, err error)
+	// SaveParentStyles saves the color and avatar of each parent, once the parents are saved
+	SaveParentStyles(ctx context.Context, parentA, parentB config.ParentStyle) error
+
+	// GetAvailability returns the unavailable days of parent, a key of config.ParentKey
+	GetAvailability(parent string) ([]string, error)
+	// SaveAvailability replaces the unavailable days of parent
+	SaveAvailability(ctx context.Context, parent string, unavailableDays []string) error
+	// GetUnavailabilityRules returns the recurring unavailability rules of parent, in the order they were saved
+	GetUnavailabilityRules(parent string) ([]config.UnavailabilityRule, error)
+	// SaveUnavailabilityRules replaces the recurring unavailability rules of parent
+	SaveUnavailabilityRules(ctx context.Context, parent string, rules []config.UnavailabilityRule) error
+
+	// GetSchedule returns the schedule settings
+	GetSchedule() (updateFrequency string, lookAheadDays, pastEventThresholdDays int, statsOrder constants.StatsOrder, err error)
+	// GetScheduleFull returns the schedule settings with their metadata, nil when never saved
+	GetScheduleFull() (*ConfigSchedule, error)
+	// SaveSchedule saves the schedule settings
+	SaveSchedule(ctx context.Context, updateFrequency string, lookAheadDays, pastEventThresholdDays int, statsOrder constants.StatsOrder) error
+	// GetLookAheadWindows returns the days scheduled ahead by the periodic, webhook and manual syncs
+	GetLookAheadWindows() (config.LookAheadWindows, error)
+	// SaveLookAheadWindows saves the days scheduled ahead by the periodic, webhook and manual syncs,
+	// once the schedule is saved
+	SaveLookAheadWindows(ctx context.Context, windows config.LookAheadWindows) error
+	// GetMinRestDays returns the nights off a parent gets at least after a block of consecutive nights
+	GetMinRestDays() (int, error)
+	// SaveMinRestDays saves the nights off a parent gets at least after a block of consecutive nights
+	SaveMinRestDays(ctx context.Context, days int) error
+	// GetDayWeights returns how much a night counts in the fairness totals by day of the week
+	GetDayWeights() (config.DayWeights, error)
+	// SaveDayWeights replaces how much a night counts in the fairness totals by day of the week
+	SaveDayWeights(ctx context.Context, weights config.DayWeights) error
+
+	// GetNotifyChannels returns whether each of channels is enabled; channels never saved are enabled
+	GetNotifyChannels(channels []string) (map[string]bool, error)
+	// IsNotifyChannelEnabled reports whether notifications are delivered through channel
+	IsNotifyChannelEnabled(channel string) (bool, error)
+	// SaveNotifyChannels saves whether each channel is enabled
+	SaveNotifyChannels(ctx context.Context, enabled map[string]bool) error
+
+	// GetChecklistTemplate returns the labels of the bedtime checklist in order
+	GetChecklistTemplate() ([]string, error)
+	// SaveChecklistTemplate replaces the bedtime checklist
+	SaveChecklistTemplate(ctx context.Context, labels []string) error
+	// GetWeekdayChecklists returns the items added to the bedtime checklist on each weekday
+	GetWeekdayChecklists() (map[time.Weekday][]string, error)
+	// SaveWeekdayChecklists replaces the items added to the bedtime checklist on each weekday
+	SaveWeekdayChecklists(ctx context.Context, checklists map[time.Weekday][]string) error
+	// GetCommentsInEvents reports whether the assignment comments are written in the calendar events
+	GetCommentsInEvents() (bool, error)
+	// SaveCommentsInEvents saves whether the assignment comments are written in the calendar events
+	SaveCommentsInEvents(ctx context.Context, enabled bool) error
+
+	// GetVacation returns the family vacation, disabled when never saved
+	GetVacation() (config.Vacation, error)
+	// SaveVacation saves the family vacation
+	SaveVacation(ctx context.Context, vacation config.Vacation) error
+	// GetSkipDates returns the days without night routine, in the order they were saved
+	GetSkipDates() (config.SkipDates, error)
+	// SaveSkipDates replaces the days without night routine
+	SaveSkipDates(ctx context.Context, skipDates config.SkipDates) error
+	// GetSyncExclusions returns the kinds of nights kept out of Google Calendar, none when never saved
+	GetSyncExclusions() (config.SyncExclusions, error)
+	// SaveSyncExclusions replaces the kinds of nights kept out of Google Calendar
+	SaveSyncExclusions(ctx context.Context, exclusions config.SyncExclusions) error
+
+	// HasConfiguration reports whether any configuration was saved
+	HasConfiguration() (bool, error)
+}
+
+// Ensure ConfigStore implements ConfigStoreInterface
+var _ ConfigStoreInterface = (*ConfigStore)(nil)
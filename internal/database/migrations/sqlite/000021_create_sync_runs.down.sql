@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sync_runs_started_at;
+DROP TABLE IF EXISTS sync_runs;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS config_notify_channels;
+DROP INDEX IF EXISTS idx_notification_deliveries_delivered_at;
+DROP TABLE IF EXISTS notification_deliveries;
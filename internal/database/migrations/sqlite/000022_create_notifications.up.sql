@@ -0,0 +1,19 @@
+-- One row per notification delivered, or attempted, through a channel
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel TEXT NOT NULL,
+    event TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('success', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    delivered_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_delivered_at ON notification_deliveries(delivered_at);
+
+-- Channels disabled from the settings page; a channel without a row is enabled
+CREATE TABLE IF NOT EXISTS config_notify_channels (
+    channel TEXT PRIMARY KEY,
+    enabled INTEGER NOT NULL DEFAULT 1 CHECK (enabled IN (0, 1)),
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_domain_events_occurred_at;
+DROP TABLE IF EXISTS domain_events;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_assignment_checklist_items_assignment_id;
+DROP TABLE IF EXISTS assignment_checklist_items;
+DROP TABLE IF EXISTS assignment_checklists;
+DROP TABLE IF EXISTS config_checklist_items;
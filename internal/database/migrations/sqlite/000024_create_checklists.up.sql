@@ -0,0 +1,25 @@
+-- Bedtime checklist applied to the assignments whose checklist was never edited
+CREATE TABLE IF NOT EXISTS config_checklist_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    label TEXT NOT NULL UNIQUE,
+    position INTEGER NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Assignments whose checklist was edited, and no longer follows the template, even when emptied
+CREATE TABLE IF NOT EXISTS assignment_checklists (
+    assignment_id INTEGER PRIMARY KEY REFERENCES assignments(id) ON DELETE CASCADE,
+    edited_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Items of the edited checklists, with the completion of each item
+CREATE TABLE IF NOT EXISTS assignment_checklist_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    label TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    completed_at TEXT,
+    UNIQUE (assignment_id, label)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_checklist_items_assignment_id ON assignment_checklist_items(assignment_id);
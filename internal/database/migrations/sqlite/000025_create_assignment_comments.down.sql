@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS config_comments;
+DROP INDEX IF EXISTS idx_assignment_comments_assignment_id;
+DROP TABLE IF EXISTS assignment_comments;
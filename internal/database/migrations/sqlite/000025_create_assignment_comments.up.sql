@@ -0,0 +1,17 @@
+-- Notes left on an assignment, oldest first
+CREATE TABLE IF NOT EXISTS assignment_comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    author TEXT NOT NULL,
+    text TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_comments_assignment_id ON assignment_comments(assignment_id);
+
+-- Whether the comments are appended to the description of the Google Calendar events; a single row
+CREATE TABLE IF NOT EXISTS config_comments (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    in_calendar_events INTEGER NOT NULL DEFAULT 0 CHECK (in_calendar_events IN (0, 1)),
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,8 @@
+-- Family vacation during which no night is scheduled; a single row
+CREATE TABLE IF NOT EXISTS config_vacation (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    enabled INTEGER NOT NULL DEFAULT 0 CHECK (enabled IN (0, 1)),
+    start_date TEXT NOT NULL,
+    end_date TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- Remove version column from the assignments table
+ALTER TABLE assignments DROP COLUMN version;
@@ -0,0 +1,3 @@
+-- Version of the caregiver of an assignment, incremented by every change of it, so that concurrent
+-- edits can detect that the assignment changed since it was read
+ALTER TABLE assignments ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_assignment_changes_assignment_id;
+DROP INDEX IF EXISTS idx_assignment_changes_batch_id;
+DROP TABLE IF EXISTS assignment_changes;
+DROP TABLE IF EXISTS assignment_change_batches;
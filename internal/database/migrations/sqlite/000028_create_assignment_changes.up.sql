@@ -0,0 +1,21 @@
+-- Batches of assignment changes (override, swap, regeneration, unlock), undone together
+CREATE TABLE IF NOT EXISTS assignment_change_batches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    undone_at TEXT
+);
+
+-- State of an assignment before a change of its batch
+CREATE TABLE IF NOT EXISTS assignment_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    batch_id INTEGER NOT NULL REFERENCES assignment_change_batches(id) ON DELETE CASCADE,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    parent_name TEXT NOT NULL,
+    caregiver_type TEXT NOT NULL,
+    override BOOLEAN NOT NULL,
+    decision_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_changes_batch_id ON assignment_changes(batch_id);
+CREATE INDEX IF NOT EXISTS idx_assignment_changes_assignment_id ON assignment_changes(assignment_id);
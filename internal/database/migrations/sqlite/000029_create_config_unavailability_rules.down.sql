@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_config_unavailability_rules_parent;
+DROP TABLE IF EXISTS config_unavailability_rules;
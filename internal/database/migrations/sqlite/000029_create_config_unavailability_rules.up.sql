@@ -0,0 +1,10 @@
+-- Recurring unavailability rules of each parent, in the RRULE form of config.UnavailabilityRule
+CREATE TABLE IF NOT EXISTS config_unavailability_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    parent TEXT NOT NULL CHECK (parent IN ('parent_a', 'parent_b')),
+    rule TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(parent, rule)
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_unavailability_rules_parent ON config_unavailability_rules(parent);
@@ -0,0 +1,2 @@
+-- Why an overridden night was taken by hand, NULL when it was not tagged
+ALTER TABLE assignments ADD COLUMN tag TEXT CHECK (tag IN ('sick_kid', 'parent_away'));
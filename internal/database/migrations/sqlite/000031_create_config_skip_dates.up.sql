@@ -0,0 +1,6 @@
+-- Days without night routine, single dates or recurring rules in the form of config.SkipDate
+CREATE TABLE IF NOT EXISTS config_skip_dates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entry TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
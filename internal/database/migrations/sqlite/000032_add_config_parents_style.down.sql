@@ -0,0 +1,4 @@
+ALTER TABLE config_parents DROP COLUMN parent_b_avatar;
+ALTER TABLE config_parents DROP COLUMN parent_a_avatar;
+ALTER TABLE config_parents DROP COLUMN parent_b_color;
+ALTER TABLE config_parents DROP COLUMN parent_a_color;
@@ -0,0 +1,5 @@
+-- Color (constants.ParentColor) and optional avatar of each parent, shown wherever the parent appears
+ALTER TABLE config_parents ADD COLUMN parent_a_color TEXT NOT NULL DEFAULT 'blueberry';
+ALTER TABLE config_parents ADD COLUMN parent_b_color TEXT NOT NULL DEFAULT 'tangerine';
+ALTER TABLE config_parents ADD COLUMN parent_a_avatar TEXT NOT NULL DEFAULT '';
+ALTER TABLE config_parents ADD COLUMN parent_b_avatar TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+-- Date ranges whose assignments are kept as they are by the schedule regeneration
+CREATE TABLE IF NOT EXISTS locked_ranges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    start_date TEXT NOT NULL,
+    end_date TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,7 @@
+-- Days a parent is unavailable from the events of its personal calendar titled with the availability
+-- keyword, replaced on each sync
+CREATE TABLE IF NOT EXISTS calendar_unavailability (
+    parent TEXT NOT NULL CHECK (parent IN ('parent_a', 'parent_b')),
+    date TEXT NOT NULL,
+    PRIMARY KEY (parent, date)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE notification_channels DROP COLUMN verified_at;
+ALTER TABLE notification_channels DROP COLUMN verification_status;
+ALTER TABLE notification_channels DROP COLUMN last_notification_at;
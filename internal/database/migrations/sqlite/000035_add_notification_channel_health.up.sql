@@ -0,0 +1,4 @@
+-- Health of each notification channel: last notification received and outcome of the last verification with Google
+ALTER TABLE notification_channels ADD COLUMN last_notification_at TEXT;
+ALTER TABLE notification_channels ADD COLUMN verification_status TEXT NOT NULL DEFAULT '';
+ALTER TABLE notification_channels ADD COLUMN verified_at TEXT;
@@ -0,0 +1,6 @@
+-- Nights off a parent gets at least after a block of consecutive nights; a single row
+CREATE TABLE IF NOT EXISTS config_rest_days (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    min_rest_days INTEGER NOT NULL DEFAULT 0 CHECK (min_rest_days >= 0),
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS assignment_monthly_stats_update_trigger;
+DROP TRIGGER IF EXISTS assignment_monthly_stats_delete_trigger;
+DROP TRIGGER IF EXISTS assignment_monthly_stats_insert_trigger;
+DROP TABLE IF EXISTS assignment_monthly_stats;
@@ -0,0 +1,48 @@
+-- Assignment counts per month, caregiver type and caregiver, kept up to date by the triggers below
+-- so that the statistics page does not group the whole assignments table on every request
+CREATE TABLE IF NOT EXISTS assignment_monthly_stats (
+    month TEXT NOT NULL,
+    caregiver_type TEXT NOT NULL,
+    parent_name TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (month, caregiver_type, parent_name)
+);
+
+INSERT INTO assignment_monthly_stats (month, caregiver_type, parent_name, count)
+SELECT strftime('%Y-%m', assignment_date), caregiver_type, parent_name, COUNT(*)
+FROM assignments
+GROUP BY strftime('%Y-%m', assignment_date), caregiver_type, parent_name;
+
+CREATE TRIGGER IF NOT EXISTS assignment_monthly_stats_insert_trigger
+AFTER INSERT ON assignments
+FOR EACH ROW
+BEGIN
+    INSERT INTO assignment_monthly_stats (month, caregiver_type, parent_name, count)
+    VALUES (strftime('%Y-%m', NEW.assignment_date), NEW.caregiver_type, NEW.parent_name, 1)
+    ON CONFLICT(month, caregiver_type, parent_name) DO UPDATE SET count = count + 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS assignment_monthly_stats_delete_trigger
+AFTER DELETE ON assignments
+FOR EACH ROW
+BEGIN
+    UPDATE assignment_monthly_stats SET count = count - 1
+    WHERE month = strftime('%Y-%m', OLD.assignment_date) AND caregiver_type = OLD.caregiver_type AND parent_name = OLD.parent_name;
+    DELETE FROM assignment_monthly_stats
+    WHERE month = strftime('%Y-%m', OLD.assignment_date) AND caregiver_type = OLD.caregiver_type AND parent_name = OLD.parent_name AND count <= 0;
+END;
+
+-- Only a change of caregiver or date moves a count; the updated_at trigger touches neither
+CREATE TRIGGER IF NOT EXISTS assignment_monthly_stats_update_trigger
+AFTER UPDATE OF parent_name, assignment_date, caregiver_type ON assignments
+FOR EACH ROW
+WHEN OLD.parent_name IS NOT NEW.parent_name OR OLD.assignment_date IS NOT NEW.assignment_date OR OLD.caregiver_type IS NOT NEW.caregiver_type
+BEGIN
+    UPDATE assignment_monthly_stats SET count = count - 1
+    WHERE month = strftime('%Y-%m', OLD.assignment_date) AND caregiver_type = OLD.caregiver_type AND parent_name = OLD.parent_name;
+    DELETE FROM assignment_monthly_stats
+    WHERE month = strftime('%Y-%m', OLD.assignment_date) AND caregiver_type = OLD.caregiver_type AND parent_name = OLD.parent_name AND count <= 0;
+    INSERT INTO assignment_monthly_stats (month, caregiver_type, parent_name, count)
+    VALUES (strftime('%Y-%m', NEW.assignment_date), NEW.caregiver_type, NEW.parent_name, 1)
+    ON CONFLICT(month, caregiver_type, parent_name) DO UPDATE SET count = count + 1;
+END;
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS assignment_comments_fts_update;
+DROP TRIGGER IF EXISTS assignment_comments_fts_delete;
+DROP TRIGGER IF EXISTS assignment_comments_fts_insert;
+DROP TABLE IF EXISTS assignment_comments_fts;
+
+DROP INDEX IF EXISTS idx_assignments_tag_date;
+DROP INDEX IF EXISTS idx_assignments_decision_reason_date;
+DROP INDEX IF EXISTS idx_assignments_override_date;
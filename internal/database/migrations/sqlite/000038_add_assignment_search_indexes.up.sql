@@ -0,0 +1,24 @@
+-- Indexes of the assignment search filters, newest nights first
+CREATE INDEX IF NOT EXISTS idx_assignments_override_date ON assignments(override, assignment_date DESC);
+CREATE INDEX IF NOT EXISTS idx_assignments_decision_reason_date ON assignments(decision_reason, assignment_date DESC);
+CREATE INDEX IF NOT EXISTS idx_assignments_tag_date ON assignments(tag, assignment_date DESC) WHERE tag IS NOT NULL;
+
+-- Full-text index of the comments, kept in sync with assignment_comments by the triggers below
+CREATE VIRTUAL TABLE IF NOT EXISTS assignment_comments_fts USING fts5(text, content='assignment_comments', content_rowid='id');
+INSERT INTO assignment_comments_fts(assignment_comments_fts) VALUES ('rebuild');
+
+CREATE TRIGGER IF NOT EXISTS assignment_comments_fts_insert AFTER INSERT ON assignment_comments
+BEGIN
+    INSERT INTO assignment_comments_fts(rowid, text) VALUES (NEW.id, NEW.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS assignment_comments_fts_delete AFTER DELETE ON assignment_comments
+BEGIN
+    INSERT INTO assignment_comments_fts(assignment_comments_fts, rowid, text) VALUES ('delete', OLD.id, OLD.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS assignment_comments_fts_update AFTER UPDATE OF text ON assignment_comments
+BEGIN
+    INSERT INTO assignment_comments_fts(assignment_comments_fts, rowid, text) VALUES ('delete', OLD.id, OLD.text);
+    INSERT INTO assignment_comments_fts(rowid, text) VALUES (NEW.id, NEW.text);
+END;
@@ -0,0 +1,9 @@
+-- Version of the Google Calendar events whose change a webhook pass applied to an assignment, so that
+-- a notification delivered again for the same version is not applied twice
+CREATE TABLE IF NOT EXISTS webhook_applied_events (
+    google_calendar_event_id TEXT PRIMARY KEY,
+    assignment_id INTEGER NOT NULL,
+    etag TEXT NOT NULL,
+    event_updated TEXT NOT NULL,
+    applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+ALTER TABLE config_schedule DROP COLUMN manual_look_ahead_days;
+ALTER TABLE config_schedule DROP COLUMN webhook_look_ahead_days;
+ALTER TABLE config_schedule DROP COLUMN scheduled_look_ahead_days;
@@ -0,0 +1,4 @@
+-- Days scheduled ahead by the periodic, webhook and manual syncs; 0 uses look_ahead_days
+ALTER TABLE config_schedule ADD COLUMN scheduled_look_ahead_days INTEGER NOT NULL DEFAULT 0 CHECK (scheduled_look_ahead_days >= 0);
+ALTER TABLE config_schedule ADD COLUMN webhook_look_ahead_days INTEGER NOT NULL DEFAULT 0 CHECK (webhook_look_ahead_days >= 0);
+ALTER TABLE config_schedule ADD COLUMN manual_look_ahead_days INTEGER NOT NULL DEFAULT 0 CHECK (manual_look_ahead_days >= 0);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS config_weekday_checklist_items;
@@ -0,0 +1,9 @@
+-- Items added to the bedtime checklist template on one weekday, 0 for Sunday to 6 for Saturday
+CREATE TABLE IF NOT EXISTS config_weekday_checklist_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    weekday INTEGER NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    label TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (weekday, label)
+);
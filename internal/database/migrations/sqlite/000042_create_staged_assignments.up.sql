@@ -0,0 +1,5 @@
+-- Regenerated assignments whose calendar event waits for review before being updated
+CREATE TABLE IF NOT EXISTS staged_assignments (
+    assignment_id INTEGER PRIMARY KEY REFERENCES assignments(id) ON DELETE CASCADE,
+    staged_at TEXT NOT NULL
+);
@@ -0,0 +1,6 @@
+-- Kinds of nights kept out of Google Calendar, in the form of config.SyncExclusions.Kinds
+CREATE TABLE IF NOT EXISTS config_sync_exclusions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
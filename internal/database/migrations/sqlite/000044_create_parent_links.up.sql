@@ -0,0 +1,6 @@
+-- Self-service link of each parent, only the SHA-256 of its token is kept
+CREATE TABLE IF NOT EXISTS parent_links (
+    parent TEXT PRIMARY KEY CHECK (parent IN ('parent_a', 'parent_b')),
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS login_sessions;
+DROP TABLE IF EXISTS passkeys;
@@ -0,0 +1,18 @@
+-- Passkeys signing in to the web interface, with the public key in DER SubjectPublicKeyInfo
+CREATE TABLE IF NOT EXISTS passkeys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    credential_id BLOB NOT NULL UNIQUE,
+    public_key BLOB NOT NULL,
+    algorithm INTEGER NOT NULL,
+    sign_count INTEGER NOT NULL DEFAULT 0,
+    name TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME
+);
+
+-- Sessions opened by a passkey, closed with it; only the SHA-256 of their token is kept
+CREATE TABLE IF NOT EXISTS login_sessions (
+    token_hash TEXT PRIMARY KEY,
+    passkey_id INTEGER NOT NULL REFERENCES passkeys(id) ON DELETE CASCADE,
+    expires_at DATETIME NOT NULL
+);
@@ -0,0 +1,51 @@
+-- Revert: the settings of the extra parents are deleted before the two-parent constraints are restored
+DELETE FROM config_availability WHERE parent NOT IN ('parent_a', 'parent_b');
+DELETE FROM config_unavailability_rules WHERE parent NOT IN ('parent_a', 'parent_b');
+DELETE FROM calendar_unavailability WHERE parent NOT IN ('parent_a', 'parent_b');
+DELETE FROM parent_links WHERE parent NOT IN ('parent_a', 'parent_b');
+
+CREATE TABLE config_availability_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    parent TEXT NOT NULL CHECK (parent IN ('parent_a', 'parent_b')),
+    unavailable_day TEXT NOT NULL CHECK (unavailable_day IN ('Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday', 'Sunday')),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(parent, unavailable_day)
+);
+INSERT INTO config_availability_new SELECT * FROM config_availability;
+DROP TABLE config_availability;
+ALTER TABLE config_availability_new RENAME TO config_availability;
+CREATE INDEX IF NOT EXISTS idx_config_availability_parent ON config_availability(parent);
+
+CREATE TABLE config_unavailability_rules_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    parent TEXT NOT NULL CHECK (parent IN ('parent_a', 'parent_b')),
+    rule TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(parent, rule)
+);
+INSERT INTO config_unavailability_rules_new SELECT * FROM config_unavailability_rules;
+DROP TABLE config_unavailability_rules;
+ALTER TABLE config_unavailability_rules_new RENAME TO config_unavailability_rules;
+CREATE INDEX IF NOT EXISTS idx_config_unavailability_rules_parent ON config_unavailability_rules(parent);
+
+CREATE TABLE calendar_unavailability_new (
+    parent TEXT NOT NULL CHECK (parent IN ('parent_a', 'parent_b')),
+    date TEXT NOT NULL,
+    PRIMARY KEY (parent, date)
+);
+INSERT INTO calendar_unavailability_new SELECT * FROM calendar_unavailability;
+DROP TABLE calendar_unavailability;
+ALTER TABLE calendar_unavailability_new RENAME TO calendar_unavailability;
+
+CREATE TABLE parent_links_new (
+    parent TEXT PRIMARY KEY CHECK (parent IN ('parent_a', 'parent_b')),
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL
+);
+INSERT INTO parent_links_new SELECT * FROM parent_links;
+DROP TABLE parent_links;
+ALTER TABLE parent_links_new RENAME TO parent_links;
+
+ALTER TABLE assignment_details DROP COLUMN extra_parents;
+
+DROP TABLE IF EXISTS config_extra_parents;
@@ -0,0 +1,57 @@
+-- Parents and caregivers taking turns after parent A and parent B, e.g. a grandparent. The position
+-- in the roster gives the key of their settings: 2 is parent_c, up to 5 for parent_f.
+CREATE TABLE IF NOT EXISTS config_extra_parents (
+    position INTEGER PRIMARY KEY CHECK (position BETWEEN 2 AND 5),
+    name TEXT NOT NULL UNIQUE,
+    color TEXT NOT NULL,
+    avatar TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Counts of the extra parents when the assignment was calculated, as a JSON array of
+-- {"name", "total", "last_30_days"}
+ALTER TABLE assignment_details ADD COLUMN extra_parents TEXT NOT NULL DEFAULT '[]';
+
+-- SQLite does not support DROP CONSTRAINT, so the tables keyed by parent are recreated to accept
+-- the keys of the extra parents.
+CREATE TABLE config_availability_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    parent TEXT NOT NULL CHECK (parent IN ('parent_a', 'parent_b', 'parent_c', 'parent_d', 'parent_e', 'parent_f')),
+    unavailable_day TEXT NOT NULL CHECK (unavailable_day IN ('Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday', 'Sunday')),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(parent, unavailable_day)
+);
+INSERT INTO config_availability_new SELECT * FROM config_availability;
+DROP TABLE config_availability;
+ALTER TABLE config_availability_new RENAME TO config_availability;
+CREATE INDEX IF NOT EXISTS idx_config_availability_parent ON config_availability(parent);
+
+CREATE TABLE config_unavailability_rules_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    parent TEXT NOT NULL CHECK (parent IN ('parent_a', 'parent_b', 'parent_c', 'parent_d', 'parent_e', 'parent_f')),
+    rule TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(parent, rule)
+);
+INSERT INTO config_unavailability_rules_new SELECT * FROM config_unavailability_rules;
+DROP TABLE config_unavailability_rules;
+ALTER TABLE config_unavailability_rules_new RENAME TO config_unavailability_rules;
+CREATE INDEX IF NOT EXISTS idx_config_unavailability_rules_parent ON config_unavailability_rules(parent);
+
+CREATE TABLE calendar_unavailability_new (
+    parent TEXT NOT NULL CHECK (parent IN ('parent_a', 'parent_b', 'parent_c', 'parent_d', 'parent_e', 'parent_f')),
+    date TEXT NOT NULL,
+    PRIMARY KEY (parent, date)
+);
+INSERT INTO calendar_unavailability_new SELECT * FROM calendar_unavailability;
+DROP TABLE calendar_unavailability;
+ALTER TABLE calendar_unavailability_new RENAME TO calendar_unavailability;
+
+CREATE TABLE parent_links_new (
+    parent TEXT PRIMARY KEY CHECK (parent IN ('parent_a', 'parent_b', 'parent_c', 'parent_d', 'parent_e', 'parent_f')),
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL
+);
+INSERT INTO parent_links_new SELECT * FROM parent_links;
+DROP TABLE parent_links;
+ALTER TABLE parent_links_new RENAME TO parent_links;
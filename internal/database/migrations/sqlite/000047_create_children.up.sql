@@ -0,0 +1,65 @@
+-- Children with a rotation of their own, scheduled alongside the main rotation
+CREATE TABLE IF NOT EXISTS children (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The child of the assignment, 0 for the main rotation: each rotation has one assignment per day
+ALTER TABLE assignments ADD COLUMN child_id INTEGER NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS idx_assignments_date;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_assignments_child_date ON assignments(child_id, assignment_date);
+
+-- The monthly counts are kept per rotation, so that the statistics of a child are its own
+DROP TRIGGER IF EXISTS assignment_monthly_stats_insert_trigger;
+DROP TRIGGER IF EXISTS assignment_monthly_stats_delete_trigger;
+DROP TRIGGER IF EXISTS assignment_monthly_stats_update_trigger;
+DROP TABLE IF EXISTS assignment_monthly_stats;
+
+CREATE TABLE IF NOT EXISTS assignment_monthly_stats (
+    child_id INTEGER NOT NULL DEFAULT 0,
+    month TEXT NOT NULL,
+    caregiver_type TEXT NOT NULL,
+    parent_name TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (child_id, month, caregiver_type, parent_name)
+);
+
+INSERT INTO assignment_monthly_stats (child_id, month, caregiver_type, parent_name, count)
+SELECT child_id, strftime('%Y-%m', assignment_date), caregiver_type, parent_name, COUNT(*)
+FROM assignments
+GROUP BY child_id, strftime('%Y-%m', assignment_date), caregiver_type, parent_name;
+
+CREATE TRIGGER IF NOT EXISTS assignment_monthly_stats_insert_trigger
+AFTER INSERT ON assignments
+FOR EACH ROW
+BEGIN
+    INSERT INTO assignment_monthly_stats (child_id, month, caregiver_type, parent_name, count)
+    VALUES (NEW.child_id, strftime('%Y-%m', NEW.assignment_date), NEW.caregiver_type, NEW.parent_name, 1)
+    ON CONFLICT(child_id, month, caregiver_type, parent_name) DO UPDATE SET count = count + 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS assignment_monthly_stats_delete_trigger
+AFTER DELETE ON assignments
+FOR EACH ROW
+BEGIN
+    UPDATE assignment_monthly_stats SET count = count - 1
+    WHERE child_id = OLD.child_id AND month = strftime('%Y-%m', OLD.assignment_date) AND caregiver_type = OLD.caregiver_type AND parent_name = OLD.parent_name;
+    DELETE FROM assignment_monthly_stats
+    WHERE child_id = OLD.child_id AND month = strftime('%Y-%m', OLD.assignment_date) AND caregiver_type = OLD.caregiver_type AND parent_name = OLD.parent_name AND count <= 0;
+END;
+
+-- Only a change of caregiver or date moves a count; the updated_at trigger touches neither
+CREATE TRIGGER IF NOT EXISTS assignment_monthly_stats_update_trigger
+AFTER UPDATE OF parent_name, assignment_date, caregiver_type ON assignments
+FOR EACH ROW
+WHEN OLD.parent_name IS NOT NEW.parent_name OR OLD.assignment_date IS NOT NEW.assignment_date OR OLD.caregiver_type IS NOT NEW.caregiver_type
+BEGIN
+    UPDATE assignment_monthly_stats SET count = count - 1
+    WHERE child_id = OLD.child_id AND month = strftime('%Y-%m', OLD.assignment_date) AND caregiver_type = OLD.caregiver_type AND parent_name = OLD.parent_name;
+    DELETE FROM assignment_monthly_stats
+    WHERE child_id = OLD.child_id AND month = strftime('%Y-%m', OLD.assignment_date) AND caregiver_type = OLD.caregiver_type AND parent_name = OLD.parent_name AND count <= 0;
+    INSERT INTO assignment_monthly_stats (child_id, month, caregiver_type, parent_name, count)
+    VALUES (NEW.child_id, strftime('%Y-%m', NEW.assignment_date), NEW.caregiver_type, NEW.parent_name, 1)
+    ON CONFLICT(child_id, month, caregiver_type, parent_name) DO UPDATE SET count = count + 1;
+END;
@@ -0,0 +1,3 @@
+ALTER TABLE sync_runs DROP COLUMN events_failed;
+ALTER TABLE sync_runs DROP COLUMN events_processed;
+ALTER TABLE sync_runs DROP COLUMN events_total;
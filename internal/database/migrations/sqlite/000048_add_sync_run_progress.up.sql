@@ -0,0 +1,4 @@
+-- Final progress of the calendar sync of each run: nights to sync, nights processed and nights that failed
+ALTER TABLE sync_runs ADD COLUMN events_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sync_runs ADD COLUMN events_processed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sync_runs ADD COLUMN events_failed INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Feature flags set from the admin API; a flag without row has the default state of its definition
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name TEXT PRIMARY KEY,
+    enabled BOOLEAN NOT NULL,
+    updated_at DATETIME NOT NULL
+);
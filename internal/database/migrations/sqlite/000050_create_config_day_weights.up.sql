@@ -0,0 +1,6 @@
+-- Weight of the nights in the fairness totals by day of the week (0 = Sunday); days without row count 1
+CREATE TABLE IF NOT EXISTS config_day_weights (
+    weekday INTEGER PRIMARY KEY CHECK (weekday BETWEEN 0 AND 6),
+    weight REAL NOT NULL CHECK (weight > 0),
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
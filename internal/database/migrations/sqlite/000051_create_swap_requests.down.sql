@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_swap_requests_created_at;
+DROP TABLE IF EXISTS swap_requests;
@@ -0,0 +1,13 @@
+-- Swaps of two nights proposed by a parent, applied once the other parent accepts them
+CREATE TABLE IF NOT EXISTS swap_requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    proposer TEXT NOT NULL,
+    proposer_date TEXT NOT NULL,  -- Night the proposer gives to the responder, YYYY-MM-DD
+    responder TEXT NOT NULL,
+    responder_date TEXT NOT NULL, -- Night the proposer takes from the responder, YYYY-MM-DD
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    created_at TEXT NOT NULL,
+    resolved_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_swap_requests_created_at ON swap_requests(created_at DESC);
@@ -0,0 +1,9 @@
+-- How the notifications meant for each parent reach them, and when their duty reminder escalates
+CREATE TABLE IF NOT EXISTS parent_contacts (
+    parent TEXT PRIMARY KEY,  -- Key of the parent, parent_a to parent_f
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    slack_member_id TEXT NOT NULL DEFAULT '',
+    escalation_delay_minutes INTEGER NOT NULL DEFAULT 0 CHECK (escalation_delay_minutes >= 0), -- 0 never escalates
+    updated_at TEXT NOT NULL
+);
@@ -0,0 +1,6 @@
+-- The parent on duty confirming they handle the night; it counts while they are still on duty
+CREATE TABLE IF NOT EXISTS assignment_acknowledgements (
+    assignment_id INTEGER PRIMARY KEY REFERENCES assignments(id) ON DELETE CASCADE,
+    parent_name TEXT NOT NULL,    -- Parent who acknowledged the night
+    acknowledged_at TEXT NOT NULL -- RFC 3339
+);
@@ -0,0 +1,2 @@
+ALTER TABLE parent_contacts DROP COLUMN override_notices;
+ALTER TABLE parent_contacts DROP COLUMN weekly_summary;
@@ -0,0 +1,3 @@
+-- Emails each parent subscribed to at their own address: the weekly summary and the notices of the nights changed from Google Calendar
+ALTER TABLE parent_contacts ADD COLUMN weekly_summary INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE parent_contacts ADD COLUMN override_notices INTEGER NOT NULL DEFAULT 0;
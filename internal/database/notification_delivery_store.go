@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// Statuses of a notification delivery
+const (
+	DeliveryStatusSuccess = "success"
+	DeliveryStatusFailed  = "failed"
+)
+
+// deliveryRetention is how long notification deliveries are kept before being purged
+const deliveryRetention = 90 * 24 * time.Hour
+
+// NotificationDelivery is one notification delivered, or attempted, through a channel
+type NotificationDelivery struct {
+	ID          int64
+	Channel     string
+	Event       string
+	Subject     string
+	Status      string
+	Error       string
+	DeliveredAt time.Time
+}
+
+// NotificationDeliveryStore records the notification deliveries in SQLite
+type NotificationDeliveryStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+// NewNotificationDeliveryStore creates a new notification delivery store
+func NewNotificationDeliveryStore(db *DB) (*NotificationDeliveryStore, error) {
+	logger := logging.GetLogger("notification-delivery-store")
+	return &NotificationDeliveryStore{db: db.Conn(), logger: logger}, nil
+}
+
+// RecordDelivery records the outcome of delivering a notification through channel; a nil
+// deliveryErr is a success
+func (s *NotificationDeliveryStore) RecordDelivery(channel, event, subject string, deliveryErr error) error {
+	status, errorMessage := DeliveryStatusSuccess, ""
+	if deliveryErr != nil {
+		status, errorMessage = DeliveryStatusFailed, deliveryErr.Error()
+	}
+	s.logger.Debug().Str("channel", channel).Str("event", event).Str("status", status).Msg("Recording notification delivery")
+
+	now := time.Now().UTC()
+	_, err := execWithRetry(context.Background(), s.db, `
+	INSERT INTO notification_deliveries (channel, event, subject, status, error, delivered_at)
+	VALUES (?, ?, ?, ?, ?, ?)`, channel, event, subject, status, errorMessage, now.Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to record notification delivery: %w", err)
+	}
+	return nil
+}
+
+// PruneDeliveries deletes the deliveries older than the retention period at now and returns how many
+func (s *NotificationDeliveryStore) PruneDeliveries(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := now.UTC().Add(-deliveryRetention).Format(time.RFC3339Nano)
+	result, err := execWithRetry(ctx, s.db, `DELETE FROM notification_deliveries WHERE delivered_at < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge old notification deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ListDeliveries returns the most recent notification deliveries, newest first
+func (s *NotificationDeliveryStore) ListDeliveries(limit int) ([]*NotificationDelivery, error) {
+	s.logger.Debug().Int("limit", limit).Msg("Listing notification deliveries")
+	rows, err := s.db.Query(`
+	SELECT id, channel, event, subject, status, error, delivered_at
+	FROM notification_deliveries
+	ORDER BY delivered_at DESC, id DESC
+	LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*NotificationDelivery{}
+	for rows.Next() {
+		var delivery NotificationDelivery
+		var deliveredAtStr string
+		if err := rows.Scan(&delivery.ID, &delivery.Channel, &delivery.Event, &delivery.Subject, &delivery.Status, &delivery.Error, &deliveredAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		delivery.DeliveredAt, err = time.Parse(time.RFC3339Nano, deliveredAtStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notification delivery time: %w", err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// LastSuccessfulDelivery returns when event was last delivered successfully through any channel,
+// a zero time when it never was within the retention period
+func (s *NotificationDeliveryStore) LastSuccessfulDelivery(event string) (time.Time, error) {
+	var deliveredAtStr sql.NullString
+	err := s.db.QueryRow(`
+	SELECT MAX(delivered_at)
+	FROM notification_deliveries
+	WHERE event = ? AND status = ?`, event, DeliveryStatusSuccess).Scan(&deliveredAtStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query last delivery of %s: %w", event, err)
+	}
+	if !deliveredAtStr.Valid {
+		return time.Time{}, nil
+	}
+	deliveredAt, err := time.Parse(time.RFC3339Nano, deliveredAtStr.String)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse notification delivery time: %w", err)
+	}
+	return deliveredAt, nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestNotificationDeliveryStore(t *testing.T) (*NotificationDeliveryStore, *DB) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_deliveries.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewNotificationDeliveryStore(db)
+	require.NoError(t, err, "Failed to create notification delivery store")
+	return store, db
+}
+
+func TestNotificationDeliveryStore_RecordDelivery(t *testing.T) {
+	store, _ := setupTestNotificationDeliveryStore(t)
+
+	require.NoError(t, store.RecordDelivery("slack", "failure_alert", "Night Routine: sync failing", nil))
+	require.NoError(t, store.RecordDelivery("email", "failure_alert", "Night Routine: sync failing", errors.New("connection refused")))
+
+	deliveries, err := store.ListDeliveries(10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	// Newest first
+	failed, succeeded := deliveries[0], deliveries[1]
+	assert.Equal(t, "email", failed.Channel)
+	assert.Equal(t, DeliveryStatusFailed, failed.Status)
+	assert.Equal(t, "connection refused", failed.Error)
+
+	assert.Equal(t, "slack", succeeded.Channel)
+	assert.Equal(t, "failure_alert", succeeded.Event)
+	assert.Equal(t, "Night Routine: sync failing", succeeded.Subject)
+	assert.Equal(t, DeliveryStatusSuccess, succeeded.Status)
+	assert.Empty(t, succeeded.Error)
+	assert.WithinDuration(t, time.Now(), succeeded.DeliveredAt, time.Minute)
+}
+
+func TestNotificationDeliveryStore_ListDeliveriesLimit(t *testing.T) {
+	store, _ := setupTestNotificationDeliveryStore(t)
+
+	for range 5 {
+		require.NoError(t, store.RecordDelivery("slack", "failure_alert", "subject", nil))
+	}
+
+	deliveries, err := store.ListDeliveries(3)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 3)
+}
+
+func TestNotificationDeliveryStore_PurgesOldDeliveries(t *testing.T) {
+	store, db := setupTestNotificationDeliveryStore(t)
+
+	old := time.Now().Add(-deliveryRetention - time.Hour).UTC().Format(time.RFC3339Nano)
+	_, err := db.Conn().Exec(`INSERT INTO notification_deliveries (channel, event, subject, status, delivered_at) VALUES ('slack', 'failure_alert', 'old', 'success', ?)`, old)
+	require.NoError(t, err)
+
+	require.NoError(t, store.RecordDelivery("email", "failure_recovered", "new", nil))
+
+	pruned, err := store.PruneDeliveries(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	deliveries, err := store.ListDeliveries(10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "new", deliveries[0].Subject)
+}
+
+func TestNotificationDeliveryStore_LastSuccessfulDelivery(t *testing.T) {
+	store, _ := setupTestNotificationDeliveryStore(t)
+
+	last, err := store.LastSuccessfulDelivery("monthly_report")
+	require.NoError(t, err)
+	assert.True(t, last.IsZero(), "never delivered")
+
+	require.NoError(t, store.RecordDelivery("email", "monthly_report", "subject", errors.New("connection refused")))
+	require.NoError(t, store.RecordDelivery("slack", "failure_alert", "subject", nil))
+	last, err = store.LastSuccessfulDelivery("monthly_report")
+	require.NoError(t, err)
+	assert.True(t, last.IsZero(), "failed deliveries and other events are ignored")
+
+	require.NoError(t, store.RecordDelivery("slack", "monthly_report", "subject", nil))
+	last, err = store.LastSuccessfulDelivery("monthly_report")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), last, time.Minute)
+}
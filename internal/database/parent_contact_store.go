@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// ParentContactStore stores how the notifications meant for each parent reach them (parent_contacts
+// table), keyed like the other settings of the parents so that a contact follows a renamed parent
+type ParentContactStore struct {
+	db     *DB
+	roster config.RosterSource
+	logger zerolog.Logger
+}
+
+// NewParentContactStore creates a new parent contact store resolving the names of the parents from roster
+func NewParentContactStore(db *DB, roster config.RosterSource) (*ParentContactStore, error) {
+	logger := logging.GetLogger("parent-contact-store")
+	return &ParentContactStore{db: db, roster: roster, logger: logger}, nil
+}
+
+// GetContacts returns the contact of each parent having one, keyed by config.ParentKey
+func (s *ParentContactStore) GetContacts() (map[string]config.ParentContact, error) {
+	rows, err := s.db.Conn().Query(`SELECT parent, email, phone, slack_member_id, escalation_delay_minutes, weekly_summary, override_notices FROM parent_contacts`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query parent contacts")
+		return nil, fmt.Errorf("failed to retrieve parent contacts: %w", err)
+	}
+	defer rows.Close()
+
+	contacts := make(map[string]config.ParentContact)
+	for rows.Next() {
+		var parent string
+		var contact config.ParentContact
+		var delayMinutes int
+		if err := rows.Scan(&parent, &contact.Email, &contact.Phone, &contact.SlackMemberID, &delayMinutes, &contact.WeeklySummary, &contact.OverrideNotices); err != nil {
+			return nil, fmt.Errorf("failed to scan parent contact: %w", err)
+		}
+		contact.EscalationDelay = time.Duration(delayMinutes) * time.Minute
+		contacts[parent] = contact
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating parent contacts: %w", err)
+	}
+	return contacts, nil
+}
+
+// ContactOf returns the contact of the parent named name, the zero contact when the parent has
+// none or is not in the roster
+func (s *ParentContactStore) ContactOf(name string) (config.ParentContact, error) {
+	roster, err := config.Roster(s.roster)
+	if err != nil {
+		return config.ParentContact{}, fmt.Errorf("failed to get parents: %w", err)
+	}
+	index := slices.Index(roster, name)
+	if index < 0 {
+		return config.ParentContact{}, nil
+	}
+	contacts, err := s.GetContacts()
+	if err != nil {
+		return config.ParentContact{}, err
+	}
+	return contacts[config.ParentKey(index)], nil
+}
+
+// EmailSubscribers returns the names of the parents of the roster subscribed to subscription, in
+// the order of the roster
+func (s *ParentContactStore) EmailSubscribers(subscription config.EmailSubscription) ([]string, error) {
+	roster, err := config.Roster(s.roster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parents: %w", err)
+	}
+	contacts, err := s.GetContacts()
+	if err != nil {
+		return nil, err
+	}
+	var subscribers []string
+	for i, name := range roster {
+		if contacts[config.ParentKey(i)].Subscribes(subscription) {
+			subscribers = append(subscribers, name)
+		}
+	}
+	return subscribers, nil
+}
+
+// SaveContact sets the contact of parent, a key of config.ParentKey, at now. The contact is
+// expected to be valid, see config.ParentContact.Validate.
+func (s *ParentContactStore) SaveContact(parent string, contact config.ParentContact, now time.Time) error {
+	if !config.IsParentKey(parent) {
+		return fmt.Errorf("%w: %q", ErrUnknownParentKey, parent)
+	}
+	s.logger.Debug().Str("parent", parent).Msg("Saving parent contact")
+
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `
+	INSERT INTO parent_contacts (parent, email, phone, slack_member_id, escalation_delay_minutes, weekly_summary, override_notices, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(parent) DO UPDATE SET email = excluded.email, phone = excluded.phone, slack_member_id = excluded.slack_member_id,
+		escalation_delay_minutes = excluded.escalation_delay_minutes, weekly_summary = excluded.weekly_summary,
+		override_notices = excluded.override_notices, updated_at = excluded.updated_at`,
+		parent, contact.Email, contact.Phone, contact.SlackMemberID, int(contact.EscalationDelay/time.Minute),
+		contact.WeeklySummary, contact.OverrideNotices, now.UTC().Format(time.RFC3339)); err != nil {
+		s.logger.Error().Err(err).Str("parent", parent).Msg("Failed to save parent contact")
+		return fmt.Errorf("failed to save contact of %s: %w", parent, err)
+	}
+	s.logger.Info().Str("parent", parent).Msg("Parent contact saved")
+	return nil
+}
+
+// DeleteContact deletes the contact of parent, if any
+func (s *ParentContactStore) DeleteContact(parent string) error {
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `DELETE FROM parent_contacts WHERE parent = ?`, parent); err != nil {
+		s.logger.Error().Err(err).Str("parent", parent).Msg("Failed to delete parent contact")
+		return fmt.Errorf("failed to delete contact of %s: %w", parent, err)
+	}
+	s.logger.Info().Str("parent", parent).Msg("Parent contact deleted")
+	return nil
+}
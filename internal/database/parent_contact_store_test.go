@@ -0,0 +1,69 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticRoster has Alice and Bob taking turns with Carol
+type staticRoster struct{}
+
+func (staticRoster) GetParents() (string, string, error) { return "Alice", "Bob", nil }
+func (staticRoster) GetExtraParents() ([]config.ExtraParent, error) {
+	return []config.ExtraParent{{Name: "Carol"}}, nil
+}
+
+func TestParentContactStore(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_parent_contacts.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewParentContactStore(db, staticRoster{})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	require.ErrorIs(t, store.SaveContact("parent_g", config.ParentContact{Email: "greg@example.com"}, now), ErrUnknownParentKey)
+
+	carol := config.ParentContact{Email: "carol@example.com", Phone: "+33 6 12 34 56 78", SlackMemberID: "U024BE7LH", EscalationDelay: 45 * time.Minute, WeeklySummary: true, OverrideNotices: true}
+	alice := config.ParentContact{Email: "alice@example.com", WeeklySummary: true}
+	require.NoError(t, store.SaveContact("parent_c", carol, now))
+	require.NoError(t, store.SaveContact("parent_a", alice, now))
+
+	contacts, err := store.GetContacts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]config.ParentContact{"parent_a": alice, "parent_c": carol}, contacts)
+
+	subscribers, err := store.EmailSubscribers(config.EmailWeeklySummary)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Carol"}, subscribers, "in the order of the roster")
+	subscribers, err = store.EmailSubscribers(config.EmailOverrideNotices)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Carol"}, subscribers)
+
+	contact, err := store.ContactOf("Carol")
+	require.NoError(t, err)
+	assert.Equal(t, carol, contact)
+	contact, err = store.ContactOf("Bob")
+	require.NoError(t, err)
+	assert.True(t, contact.IsZero(), "no contact saved")
+	contact, err = store.ContactOf("Mallory")
+	require.NoError(t, err)
+	assert.True(t, contact.IsZero(), "not in the roster")
+
+	// Saving again replaces the contact
+	require.NoError(t, store.SaveContact("parent_c", config.ParentContact{Phone: "0612345678"}, now.Add(time.Hour)))
+	contact, err = store.ContactOf("Carol")
+	require.NoError(t, err)
+	assert.Equal(t, config.ParentContact{Phone: "0612345678"}, contact)
+
+	require.NoError(t, store.DeleteContact("parent_c"))
+	contacts, err = store.GetContacts()
+	require.NoError(t, err)
+	assert.Len(t, contacts, 1)
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// ErrUnknownParentKey is returned for a parent that is not a key of config.ParentKey
+var ErrUnknownParentKey = errors.New("unknown parent, expected a key from parent_a to parent_f")
+
+// ParentLinkStore stores the self-service link of each parent (parent_links table). A link carries
+// a random token giving its parent access to their own availability; only the hash of the token is
+// stored, so a link is shown once when created.
+type ParentLinkStore struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewParentLinkStore creates a new parent link store
+func NewParentLinkStore(db *DB) (*ParentLinkStore, error) {
+	logger := logging.GetLogger("parent-link-store")
+	return &ParentLinkStore{db: db, logger: logger}, nil
+}
+
+// CreateLink creates the link of parent, a key of config.ParentKey, at now and returns its token.
+// The previous link of the parent stops working.
+func (s *ParentLinkStore) CreateLink(parent string, now time.Time) (string, error) {
+	if !config.IsParentKey(parent) {
+		return "", fmt.Errorf("%w: %q", ErrUnknownParentKey, parent)
+	}
+	token := rand.Text()
+	s.logger.Debug().Str("parent", parent).Msg("Creating parent link")
+
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `
+	INSERT INTO parent_links (parent, token_hash, created_at) VALUES (?, ?, ?)
+	ON CONFLICT(parent) DO UPDATE SET token_hash = excluded.token_hash, created_at = excluded.created_at`,
+		parent, hashToken(token), now.UTC().Format(time.RFC3339)); err != nil {
+		s.logger.Error().Err(err).Str("parent", parent).Msg("Failed to create parent link")
+		return "", fmt.Errorf("failed to create link of %s: %w", parent, err)
+	}
+	s.logger.Info().Str("parent", parent).Msg("Parent link created")
+	return token, nil
+}
+
+// ParentForToken returns the parent, a key of config.ParentKey, of the link carrying token, false
+// when no link carries it
+func (s *ParentLinkStore) ParentForToken(token string) (string, bool, error) {
+	if token == "" {
+		return "", false, nil
+	}
+	var parent string
+	err := s.db.Conn().QueryRow(`SELECT parent FROM parent_links WHERE token_hash = ?`, hashToken(token)).Scan(&parent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to look up parent link")
+		return "", false, fmt.Errorf("failed to look up parent link: %w", err)
+	}
+	return parent, true, nil
+}
+
+// ListLinks returns the creation time of the link of each parent having one
+func (s *ParentLinkStore) ListLinks() (map[string]time.Time, error) {
+	rows, err := s.db.Conn().Query(`SELECT parent, created_at FROM parent_links`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query parent links")
+		return nil, fmt.Errorf("failed to retrieve parent links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make(map[string]time.Time)
+	for rows.Next() {
+		var parent, createdAt string
+		if err := rows.Scan(&parent, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan parent link: %w", err)
+		}
+		if links[parent], err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse parent link time: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating parent links: %w", err)
+	}
+	return links, nil
+}
+
+// RevokeLink deletes the link of parent, if any
+func (s *ParentLinkStore) RevokeLink(parent string) error {
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `DELETE FROM parent_links WHERE parent = ?`, parent); err != nil {
+		s.logger.Error().Err(err).Str("parent", parent).Msg("Failed to revoke parent link")
+		return fmt.Errorf("failed to revoke link of %s: %w", parent, err)
+	}
+	s.logger.Info().Str("parent", parent).Msg("Parent link revoked")
+	return nil
+}
+
+// hashToken returns the stored form of a link or session token, the hex SHA-256
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
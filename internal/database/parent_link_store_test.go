@@ -0,0 +1,59 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParentLinkStore(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_parent_links.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewParentLinkStore(db)
+	require.NoError(t, err)
+
+	_, err = store.CreateLink("parent_g", time.Now())
+	require.ErrorIs(t, err, ErrUnknownParentKey)
+
+	createdAt := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	first, err := store.CreateLink("parent_a", createdAt)
+	require.NoError(t, err)
+	parentB, err := store.CreateLink("parent_b", createdAt)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, parentB)
+
+	parent, ok, err := store.ParentForToken(first)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "parent_a", parent)
+
+	// A new link replaces the previous one of the parent
+	second, err := store.CreateLink("parent_a", createdAt.Add(time.Hour))
+	require.NoError(t, err)
+	_, ok, err = store.ParentForToken(first)
+	require.NoError(t, err)
+	assert.False(t, ok, "the previous link stops working")
+	parent, ok, err = store.ParentForToken(second)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "parent_a", parent)
+
+	links, err := store.ListLinks()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"parent_a": createdAt.Add(time.Hour), "parent_b": createdAt}, links)
+
+	require.NoError(t, store.RevokeLink("parent_b"))
+	_, ok, err = store.ParentForToken(parentB)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = store.ParentForToken("")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// ErrPasskeyNotFound is returned when deleting a passkey that does not exist
+var ErrPasskeyNotFound = errors.New("passkey not found")
+
+// Passkey is a passkey signing in to the web interface
+type Passkey struct {
+	ID           int64
+	CredentialID []byte // Credential ID chosen by the authenticator
+	PublicKey    []byte // DER SubjectPublicKeyInfo
+	Algorithm    int    // COSE identifier of the signature algorithm
+	SignCount    uint32 // Last signature counter, 0 for authenticators without counter
+	Name         string // Given when registered, e.g. "Alice's phone"
+	CreatedAt    time.Time
+	LastUsedAt   *time.Time // nil until the first sign-in
+}
+
+// PasskeyStore stores the passkeys of the passkey login (passkeys table) and the sessions they
+// open (login_sessions table). Only the hash of a session token is stored; deleting a passkey
+// closes its sessions.
+type PasskeyStore struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewPasskeyStore creates a new passkey store
+func NewPasskeyStore(db *DB) (*PasskeyStore, error) {
+	logger := logging.GetLogger("passkey-store")
+	return &PasskeyStore{db: db, logger: logger}, nil
+}
+
+// AddPasskey stores a new passkey and sets its ID
+func (s *PasskeyStore) AddPasskey(passkey *Passkey) error {
+	result, err := execWithRetry(context.Background(), s.db.Conn(), `
+	INSERT INTO passkeys (credential_id, public_key, algorithm, sign_count, name, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`,
+		passkey.CredentialID, passkey.PublicKey, passkey.Algorithm, passkey.SignCount, passkey.Name,
+		passkey.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		s.logger.Error().Err(err).Str("name", passkey.Name).Msg("Failed to add passkey")
+		return fmt.Errorf("failed to add passkey: %w", err)
+	}
+	if passkey.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to get passkey ID: %w", err)
+	}
+	s.logger.Info().Int64("passkey_id", passkey.ID).Str("name", passkey.Name).Msg("Passkey added")
+	return nil
+}
+
+// ListPasskeys returns the passkeys, oldest first
+func (s *PasskeyStore) ListPasskeys() ([]*Passkey, error) {
+	rows, err := s.db.Conn().Query(`
+	SELECT id, credential_id, public_key, algorithm, sign_count, name, created_at, last_used_at
+	FROM passkeys ORDER BY id`)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query passkeys")
+		return nil, fmt.Errorf("failed to retrieve passkeys: %w", err)
+	}
+	defer rows.Close()
+
+	passkeys := []*Passkey{}
+	for rows.Next() {
+		passkey, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, passkey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating passkeys: %w", err)
+	}
+	return passkeys, nil
+}
+
+// CountPasskeys returns the number of passkeys; the login is only required once there is one
+func (s *PasskeyStore) CountPasskeys() (int, error) {
+	var count int
+	if err := s.db.Conn().QueryRow(`SELECT COUNT(*) FROM passkeys`).Scan(&count); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to count passkeys")
+		return 0, fmt.Errorf("failed to count passkeys: %w", err)
+	}
+	return count, nil
+}
+
+// GetPasskey returns the passkey of credentialID, nil when there is none
+func (s *PasskeyStore) GetPasskey(credentialID []byte) (*Passkey, error) {
+	passkey, err := scanPasskey(s.db.Conn().QueryRow(`
+	SELECT id, credential_id, public_key, algorithm, sign_count, name, created_at, last_used_at
+	FROM passkeys WHERE credential_id = ?`, credentialID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get passkey")
+		return nil, err
+	}
+	return passkey, nil
+}
+
+// RecordUse saves the signature counter of a sign-in with the passkey at usedAt
+func (s *PasskeyStore) RecordUse(id int64, signCount uint32, usedAt time.Time) error {
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `UPDATE passkeys SET sign_count = ?, last_used_at = ? WHERE id = ?`,
+		signCount, usedAt.UTC().Format(time.RFC3339), id); err != nil {
+		s.logger.Error().Err(err).Int64("passkey_id", id).Msg("Failed to record passkey use")
+		return fmt.Errorf("failed to record use of passkey %d: %w", id, err)
+	}
+	return nil
+}
+
+// DeletePasskey deletes a passkey and closes its sessions
+func (s *PasskeyStore) DeletePasskey(id int64) error {
+	result, err := execWithRetry(context.Background(), s.db.Conn(), `DELETE FROM passkeys WHERE id = ?`, id)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("passkey_id", id).Msg("Failed to delete passkey")
+		return fmt.Errorf("failed to delete passkey %d: %w", id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check deleted passkey: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %d", ErrPasskeyNotFound, id)
+	}
+	s.logger.Info().Int64("passkey_id", id).Msg("Passkey deleted")
+	return nil
+}
+
+// CreateSession opens a session of the passkey at now, valid for ttl, and returns its token. The
+// expired sessions are deleted.
+func (s *PasskeyStore) CreateSession(passkeyID int64, now time.Time, ttl time.Duration) (string, error) {
+	token := rand.Text()
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `DELETE FROM login_sessions WHERE expires_at <= ?`,
+		now.UTC().Format(time.RFC3339)); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to delete expired sessions")
+	}
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `INSERT INTO login_sessions (token_hash, passkey_id, expires_at) VALUES (?, ?, ?)`,
+		hashToken(token), passkeyID, now.Add(ttl).UTC().Format(time.RFC3339)); err != nil {
+		s.logger.Error().Err(err).Int64("passkey_id", passkeyID).Msg("Failed to create session")
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return token, nil
+}
+
+// SessionValid reports whether token is the token of a session not expired at now
+func (s *PasskeyStore) SessionValid(token string, now time.Time) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	var count int
+	if err := s.db.Conn().QueryRow(`SELECT COUNT(*) FROM login_sessions WHERE token_hash = ? AND expires_at > ?`,
+		hashToken(token), now.UTC().Format(time.RFC3339)).Scan(&count); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to look up session")
+		return false, fmt.Errorf("failed to look up session: %w", err)
+	}
+	return count > 0, nil
+}
+
+// DeleteSession closes the session of token, if any
+func (s *PasskeyStore) DeleteSession(token string) error {
+	if _, err := execWithRetry(context.Background(), s.db.Conn(), `DELETE FROM login_sessions WHERE token_hash = ?`, hashToken(token)); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to delete session")
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// scanPasskey scans a passkeys row
+func scanPasskey(row interface{ Scan(dest ...any) error }) (*Passkey, error) {
+	var passkey Passkey
+	var createdAt string
+	var lastUsedAt sql.NullString
+	if err := row.Scan(&passkey.ID, &passkey.CredentialID, &passkey.PublicKey, &passkey.Algorithm, &passkey.SignCount,
+		&passkey.Name, &createdAt, &lastUsedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan passkey: %w", err)
+	}
+	var err error
+	if passkey.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse passkey creation time: %w", err)
+	}
+	if lastUsedAt.Valid {
+		usedAt, err := time.Parse(time.RFC3339, lastUsedAt.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse passkey use time: %w", err)
+		}
+		passkey.LastUsedAt = &usedAt
+	}
+	return &passkey, nil
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasskeyStore(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_passkeys.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewPasskeyStore(db)
+	require.NoError(t, err)
+
+	count, err := store.CountPasskeys()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	passkey := &Passkey{CredentialID: []byte("credential-1"), PublicKey: []byte("key"), Algorithm: -7, SignCount: 1, Name: "Alice's phone", CreatedAt: now}
+	require.NoError(t, store.AddPasskey(passkey))
+	assert.NotZero(t, passkey.ID)
+	require.Error(t, store.AddPasskey(&Passkey{CredentialID: []byte("credential-1"), PublicKey: []byte("key"), Name: "Copy", CreatedAt: now}),
+		"a credential is registered once")
+
+	got, err := store.GetPasskey([]byte("credential-1"))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, passkey, got)
+	missing, err := store.GetPasskey([]byte("unknown"))
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	usedAt := now.Add(time.Hour)
+	require.NoError(t, store.RecordUse(passkey.ID, 5, usedAt))
+	passkeys, err := store.ListPasskeys()
+	require.NoError(t, err)
+	require.Len(t, passkeys, 1)
+	assert.Equal(t, uint32(5), passkeys[0].SignCount)
+	require.NotNil(t, passkeys[0].LastUsedAt)
+	assert.Equal(t, usedAt, *passkeys[0].LastUsedAt)
+
+	// Sessions expire, and close with their passkey
+	token, err := store.CreateSession(passkey.ID, now, time.Hour)
+	require.NoError(t, err)
+	valid, err := store.SessionValid(token, now.Add(59*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = store.SessionValid(token, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, valid, "expired session")
+	valid, err = store.SessionValid("unknown", now)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	loggedOut, err := store.CreateSession(passkey.ID, now, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteSession(loggedOut))
+	valid, err = store.SessionValid(loggedOut, now)
+	require.NoError(t, err)
+	assert.False(t, valid, "deleted session")
+
+	require.NoError(t, store.DeletePasskey(passkey.ID))
+	valid, err = store.SessionValid(token, now)
+	require.NoError(t, err)
+	assert.False(t, valid, "session of a deleted passkey")
+	require.ErrorIs(t, store.DeletePasskey(passkey.ID), ErrPasskeyNotFound)
+	count, err = store.CountPasskeys()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"expvar"
+	"math/rand/v2"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"modernc.org/sqlite"
+	sqlitelib "modernc.org/sqlite/lib"
+)
+
+// busyRetries counts the writes retried after a transient lock error, exposed on /debug/vars
+var busyRetries = expvar.NewInt("database_busy_retries")
+
+// busyRetryPolicy bounds the retries of a write failing on a transient lock
+type busyRetryPolicy struct {
+	attempts     int           // Total number of attempts, including the first one
+	initialDelay time.Duration // Delay before the first retry, doubled on every retry
+	maxDelay     time.Duration // Upper bound of the delay between two attempts
+}
+
+// defaultBusyRetryPolicy retries for about a second in total, on top of the busy timeout
+var defaultBusyRetryPolicy = busyRetryPolicy{
+	attempts:     5,
+	initialDelay: 25 * time.Millisecond,
+	maxDelay:     500 * time.Millisecond,
+}
+
+// IsBusy reports whether err is a transient SQLITE_BUSY or SQLITE_LOCKED error, extended codes included.
+// SQLite returns them without waiting for the busy timeout when a read transaction cannot be
+// upgraded to a write one, so that another writer can go first.
+func IsBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code() & 0xff {
+	case sqlitelib.SQLITE_BUSY, sqlitelib.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
+
+// RetryOnBusy runs fn, running it again with an exponential backoff while it fails with a transient
+// lock error. fn must be safe to run more than once. The last error is returned unchanged once the
+// attempts are exhausted or ctx is done.
+func RetryOnBusy(ctx context.Context, fn func() error) error {
+	return defaultBusyRetryPolicy.run(ctx, fn)
+}
+
+// run implements RetryOnBusy for the policy
+func (p busyRetryPolicy) run(ctx context.Context, fn func() error) error {
+	delay := p.initialDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsBusy(err) {
+			return err
+		}
+		logger := logging.GetLogger("database")
+		if attempt >= p.attempts {
+			logger.Warn().Err(err).Int("attempts", attempt).Msg("Database still locked, giving up")
+			return err
+		}
+
+		// Jitter keeps concurrent writers from retrying in lockstep
+		wait := delay/2 + rand.N(delay/2+1)
+		busyRetries.Add(1)
+		logger.Debug().Err(err).Int("attempt", attempt).Dur("wait", wait).Msg("Database locked, retrying")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, p.maxDelay)
+	}
+}
+
+// execWithRetry runs a write statement on conn, retrying it while the database is locked
+func execWithRetry(ctx context.Context, conn *sql.DB, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := RetryOnBusy(ctx, func() error {
+		var err error
+		result, err = conn.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// ExecContext runs a write statement, retrying it while the database is locked
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return execWithRetry(ctx, db.conn, query, args...)
+}
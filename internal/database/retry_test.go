@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedDatabase returns a database whose write lock is held by another connection until release is called.
+// The connections do not wait on the lock, writes fail with SQLITE_BUSY right away.
+func lockedDatabase(t *testing.T) (conn *sql.DB, release func()) {
+	t.Helper()
+	dsn := "file:" + filepath.Join(t.TempDir(), "locked.db") + "?_pragma=busy_timeout(0)&_pragma=journal_mode(WAL)"
+
+	holder, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { holder.Close() })
+	_, err = holder.Exec(`CREATE TABLE items (name TEXT)`)
+	require.NoError(t, err)
+
+	lock, err := holder.Conn(context.Background())
+	require.NoError(t, err)
+	_, err = lock.ExecContext(context.Background(), `BEGIN IMMEDIATE`)
+	require.NoError(t, err)
+
+	conn, err = sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, func() {
+		_, err := lock.ExecContext(context.Background(), `COMMIT`)
+		require.NoError(t, err)
+		require.NoError(t, lock.Close())
+	}
+}
+
+// fastRetries is a policy short enough for the tests
+var fastRetries = busyRetryPolicy{attempts: 3, initialDelay: time.Millisecond, maxDelay: 2 * time.Millisecond}
+
+func TestIsBusy(t *testing.T) {
+	conn, release := lockedDatabase(t)
+	defer release()
+
+	_, busyErr := conn.Exec(`INSERT INTO items (name) VALUES ('a')`)
+	require.Error(t, busyErr)
+
+	assert.True(t, IsBusy(busyErr))
+	assert.True(t, IsBusy(fmt.Errorf("failed to save: %w", busyErr)), "wrapped errors are unwrapped")
+	assert.False(t, IsBusy(errors.New("database is locked")), "only driver errors are recognized")
+	assert.False(t, IsBusy(nil))
+
+	_, syntaxErr := conn.Exec(`INSERT INTO`)
+	assert.False(t, IsBusy(syntaxErr))
+}
+
+func TestExecWithRetry_WaitsForLock(t *testing.T) {
+	conn, release := lockedDatabase(t)
+	time.AfterFunc(50*time.Millisecond, release)
+
+	before := busyRetries.Value()
+	_, err := execWithRetry(context.Background(), conn, `INSERT INTO items (name) VALUES (?)`, "a")
+	require.NoError(t, err)
+	assert.Greater(t, busyRetries.Value(), before)
+
+	var count int
+	require.NoError(t, conn.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestBusyRetryPolicy(t *testing.T) {
+	conn, release := lockedDatabase(t)
+	defer release()
+	_, busyErr := conn.Exec(`INSERT INTO items (name) VALUES ('a')`)
+	require.True(t, IsBusy(busyErr))
+	otherErr := errors.New("constraint failed")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		errs      []error // Error returned by each call, nil once exhausted
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "success", ctx: context.Background(), wantCalls: 1},
+		{name: "recovers from lock", ctx: context.Background(), errs: []error{busyErr, busyErr}, wantCalls: 3},
+		{name: "gives up", ctx: context.Background(), errs: []error{busyErr, busyErr, busyErr, busyErr}, wantErr: busyErr, wantCalls: 3},
+		{name: "other errors are not retried", ctx: context.Background(), errs: []error{otherErr}, wantErr: otherErr, wantCalls: 1},
+		{name: "stops when canceled", ctx: canceled, errs: []error{busyErr, busyErr}, wantErr: busyErr, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := fastRetries.run(tt.ctx, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestWithTransaction_RetriesOnBusy(t *testing.T) {
+	conn, release := lockedDatabase(t)
+	db := &DB{conn: conn}
+	time.AfterFunc(50*time.Millisecond, release)
+
+	attempts := 0
+	err := db.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		attempts++
+		_, err := tx.Exec(`INSERT INTO items (name) VALUES ('a')`)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Greater(t, attempts, 1, "the whole transaction is run again")
+
+	var count int
+	require.NoError(t, conn.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count))
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"expvar"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// slowQueries counts the queries that exceeded the slow query threshold, exposed on /debug/vars
+var slowQueries = expvar.NewInt("database_slow_queries")
+
+// slowQueryConnector opens connections logging the statements slower than threshold.
+// Queries are timed until their first row, which includes the time spent waiting on locks.
+type slowQueryConnector struct {
+	dsn       string
+	driver    driver.Driver
+	threshold time.Duration
+	logger    zerolog.Logger
+}
+
+var _ driver.Connector = (*slowQueryConnector)(nil)
+
+// Connect opens a connection of the wrapped driver
+func (c *slowQueryConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &slowQueryConn{Conn: conn, connector: c}, nil
+}
+
+// Driver returns the wrapped driver
+func (c *slowQueryConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// observe logs the statement and counts it when it took longer than the threshold
+func (c *slowQueryConnector) observe(method, query string, args []driver.NamedValue, start time.Time) {
+	elapsed := time.Since(start)
+	if elapsed < c.threshold {
+		return
+	}
+	slowQueries.Add(1)
+	c.logger.Warn().
+		Str("method", method).
+		Str("query", compactQuery(query)).
+		Strs("args", redactArgs(args)).
+		Dur("duration", elapsed).
+		Dur("threshold", c.threshold).
+		Msg("Slow query")
+}
+
+// slowQueryConn times the statements run on a connection
+type slowQueryConn struct {
+	driver.Conn
+	connector *slowQueryConnector
+}
+
+var (
+	_ driver.ExecerContext      = (*slowQueryConn)(nil)
+	_ driver.QueryerContext     = (*slowQueryConn)(nil)
+	_ driver.ConnPrepareContext = (*slowQueryConn)(nil)
+	_ driver.ConnBeginTx        = (*slowQueryConn)(nil)
+	_ driver.Pinger             = (*slowQueryConn)(nil)
+	_ driver.SessionResetter    = (*slowQueryConn)(nil)
+	_ driver.Validator          = (*slowQueryConn)(nil)
+)
+
+// ExecContext runs and times a statement
+func (c *slowQueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer c.connector.observe("exec", query, args, time.Now())
+	return execer.ExecContext(ctx, query, args)
+}
+
+// QueryContext runs and times a query
+func (c *slowQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer c.connector.observe("query", query, args, time.Now())
+	return queryer.QueryContext(ctx, query, args)
+}
+
+// PrepareContext prepares a statement timed on every execution
+func (c *slowQueryConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &slowQueryStmt{Stmt: stmt, query: query, connector: c.connector}, nil
+}
+
+// Prepare prepares a statement timed on every execution
+func (c *slowQueryConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// BeginTx starts a transaction
+func (c *slowQueryConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() // Fallback for drivers without BeginTx
+}
+
+// Ping checks the connection
+func (c *slowQueryConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the connection before it is reused
+func (c *slowQueryConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the connection can be reused
+func (c *slowQueryConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// slowQueryStmt times the executions of a prepared statement
+type slowQueryStmt struct {
+	driver.Stmt
+	query     string
+	connector *slowQueryConnector
+}
+
+var (
+	_ driver.StmtExecContext  = (*slowQueryStmt)(nil)
+	_ driver.StmtQueryContext = (*slowQueryStmt)(nil)
+)
+
+// ExecContext runs and times the statement
+func (s *slowQueryStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer s.connector.observe("exec", s.query, args, time.Now())
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values) // Fallback for drivers without ExecContext
+}
+
+// QueryContext runs and times the statement
+func (s *slowQueryStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	defer s.connector.observe("query", s.query, args, time.Now())
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values) // Fallback for drivers without QueryContext
+}
+
+// namedValuesToValues converts positional arguments for the legacy driver interfaces
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("named argument %s is not supported", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// compactQuery collapses the whitespace of a query so that it fits on one log line
+func compactQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// redactArgs describes the query arguments without revealing text, which may hold tokens or names.
+// Numbers, booleans, times and NULL are kept since they help to find the rows involved.
+func redactArgs(args []driver.NamedValue) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.Value.(type) {
+		case nil:
+			redacted[i] = "NULL"
+		case string:
+			redacted[i] = fmt.Sprintf("<redacted string, %d bytes>", len(v))
+		case []byte:
+			redacted[i] = fmt.Sprintf("<redacted blob, %d bytes>", len(v))
+		case time.Time:
+			redacted[i] = v.Format(time.RFC3339Nano)
+		default:
+			redacted[i] = fmt.Sprint(v)
+		}
+	}
+	return redacted
+}
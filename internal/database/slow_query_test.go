@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql/driver"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowQueryLogging_CountsSlowQueries(t *testing.T) {
+	opts := NewDefaultOptions(filepath.Join(t.TempDir(), "slow.db"))
+	// Every statement is slower than a nanosecond
+	opts.SlowQueryThreshold = time.Nanosecond
+
+	db, err := New(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "migrations run through the wrapped driver")
+
+	before := slowQueries.Value()
+
+	_, err = db.Conn().Exec(`INSERT INTO sync_runs (trigger, status, started_at) VALUES (?, ?, ?)`, "manual", SyncRunStatusRunning, time.Now().UTC().Format(time.RFC3339Nano))
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, db.Conn().QueryRow(`SELECT COUNT(*) FROM sync_runs WHERE trigger = ?`, "manual").Scan(&count))
+	assert.Equal(t, 1, count)
+
+	stmt, err := db.Conn().Prepare(`SELECT COUNT(*) FROM sync_runs`)
+	require.NoError(t, err)
+	defer stmt.Close()
+	require.NoError(t, stmt.QueryRow().Scan(&count))
+
+	tx, err := db.Conn().Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`DELETE FROM sync_runs`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	assert.GreaterOrEqual(t, slowQueries.Value()-before, int64(4))
+}
+
+func TestSlowQueryLogging_DisabledByDefault(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "fast.db")))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	before := slowQueries.Value()
+	_, err = db.Conn().Exec(`DELETE FROM sync_runs`)
+	require.NoError(t, err)
+
+	assert.Equal(t, before, slowQueries.Value())
+}
+
+func TestRedactArgs(t *testing.T) {
+	at := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	args := []driver.NamedValue{
+		{Ordinal: 1, Value: "ya29.secret-access-token"},
+		{Ordinal: 2, Value: []byte{1, 2, 3}},
+		{Ordinal: 3, Value: int64(42)},
+		{Ordinal: 4, Value: true},
+		{Ordinal: 5, Value: at},
+		{Ordinal: 6, Value: nil},
+	}
+
+	assert.Equal(t, []string{
+		"<redacted string, 24 bytes>",
+		"<redacted blob, 3 bytes>",
+		"42",
+		"true",
+		"2026-03-01T20:00:00Z",
+		"NULL",
+	}, redactArgs(args))
+}
+
+func TestCompactQuery(t *testing.T) {
+	query := `
+	SELECT id, date
+	FROM assignments
+	WHERE date >= ?`
+
+	assert.Equal(t, "SELECT id, date FROM assignments WHERE date >= ?", compactQuery(query))
+}
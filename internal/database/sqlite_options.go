@@ -1,5 +1,7 @@
 package database
 
+import "time"
+
 // SynchronousMode represents the available synchronous settings for SQLite
 type SynchronousMode string
 
@@ -74,6 +76,9 @@ type SQLiteOptions struct {
 	AuthPass  string // _auth_pass
 	AuthCrypt string // _auth_crypt: SHA1, SSHA1, SHA256, etc.
 	AuthSalt  string // _auth_salt
+
+	// Diagnostics
+	SlowQueryThreshold time.Duration // Log statements slower than this; zero disables slow query logging
 }
 
 // NewDefaultOptions creates SQLiteOptions with recommended defaults
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// StagedAssignment is a regenerated assignment whose calendar event waits for review
+type StagedAssignment struct {
+	AssignmentID int64
+	Date         time.Time
+	Parent       string // Caregiver of the assignment, not yet published
+	StagedAt     time.Time
+}
+
+// StagingStore stores the regenerated assignments whose calendar event waits for review before
+// being updated (staged_assignments table)
+type StagingStore struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewStagingStore creates a new staging store
+func NewStagingStore(db *DB) (*StagingStore, error) {
+	logger := logging.GetLogger("staging-store")
+	return &StagingStore{db: db, logger: logger}, nil
+}
+
+// Stage marks the assignments as waiting for review since at. Assignments already staged keep the
+// time they were first staged.
+func (s *StagingStore) Stage(assignmentIDs []int64, at time.Time) error {
+	if len(assignmentIDs) == 0 {
+		return nil
+	}
+	s.logger.Debug().Int("assignment_count", len(assignmentIDs)).Msg("Staging assignments")
+
+	return s.db.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		for _, id := range assignmentIDs {
+			if _, err := tx.Exec(`
+			INSERT INTO staged_assignments (assignment_id, staged_at) VALUES (?, ?)
+			ON CONFLICT(assignment_id) DO NOTHING`, id, at.UTC().Format(time.RFC3339)); err != nil {
+				return fmt.Errorf("failed to stage assignment %d: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Unstage removes the assignments from the ones waiting for review, once their event is published
+func (s *StagingStore) Unstage(assignmentIDs []int64) error {
+	if len(assignmentIDs) == 0 {
+		return nil
+	}
+	s.logger.Debug().Int("assignment_count", len(assignmentIDs)).Msg("Unstaging assignments")
+
+	args := make([]any, 0, len(assignmentIDs))
+	for _, id := range assignmentIDs {
+		args = append(args, id)
+	}
+	query := `DELETE FROM staged_assignments WHERE assignment_id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(assignmentIDs)), ",") + `)`
+	if _, err := s.db.ExecContext(context.Background(), query, args...); err != nil {
+		return fmt.Errorf("failed to unstage assignments: %w", err)
+	}
+	return nil
+}
+
+// ListStaged returns the assignments waiting for review, ordered by date
+func (s *StagingStore) ListStaged() ([]*StagedAssignment, error) {
+	s.logger.Debug().Msg("Fetching staged assignments")
+	rows, err := s.db.Conn().Query(`
+	SELECT s.assignment_id, a.assignment_date, a.parent_name, s.staged_at
+	FROM staged_assignments s
+	JOIN assignments a ON a.id = s.assignment_id
+	ORDER BY a.assignment_date`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staged assignments: %w", err)
+	}
+	defer rows.Close()
+
+	staged := []*StagedAssignment{}
+	for rows.Next() {
+		var assignment StagedAssignment
+		var date, stagedAt string
+		if err := rows.Scan(&assignment.AssignmentID, &date, &assignment.Parent, &stagedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan staged assignment: %w", err)
+		}
+		if assignment.Date, err = time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			return nil, fmt.Errorf("failed to parse staged assignment date: %w", err)
+		}
+		if assignment.StagedAt, err = time.Parse(time.RFC3339, stagedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse staged assignment time: %w", err)
+		}
+		staged = append(staged, &assignment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate staged assignments: %w", err)
+	}
+	return staged, nil
+}
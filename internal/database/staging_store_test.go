@@ -0,0 +1,56 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagingStore(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_staging.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	var ids []int64
+	for _, row := range [][2]string{{"Bob", "2026-11-20"}, {"Alice", "2026-11-13"}, {"Alice", "2026-10-17"}} {
+		result, err := db.Conn().Exec(`INSERT INTO assignments (parent_name, assignment_date, override, decision_reason) VALUES (?, ?, 0, 'Alternating')`, row[0], row[1])
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	store, err := NewStagingStore(db)
+	require.NoError(t, err)
+
+	staged, err := store.ListStaged()
+	require.NoError(t, err)
+	assert.Empty(t, staged)
+
+	stagedAt := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Stage(ids[:2], stagedAt))
+	require.NoError(t, store.Stage(ids[:1], stagedAt.Add(time.Hour)))
+
+	staged, err = store.ListStaged()
+	require.NoError(t, err)
+	assert.Equal(t, []*StagedAssignment{
+		{AssignmentID: ids[1], Date: time.Date(2026, 11, 13, 0, 0, 0, 0, time.Local), Parent: "Alice", StagedAt: stagedAt},
+		{AssignmentID: ids[0], Date: time.Date(2026, 11, 20, 0, 0, 0, 0, time.Local), Parent: "Bob", StagedAt: stagedAt},
+	}, staged, "ordered by date, staging again keeps the first time")
+
+	require.NoError(t, store.Unstage([]int64{ids[1], ids[2]}))
+	staged, err = store.ListStaged()
+	require.NoError(t, err)
+	require.Len(t, staged, 1)
+	assert.Equal(t, ids[0], staged[0].AssignmentID)
+
+	_, err = db.Conn().Exec(`DELETE FROM assignments WHERE id = ?`, ids[0])
+	require.NoError(t, err)
+	staged, err = store.ListStaged()
+	require.NoError(t, err)
+	assert.Empty(t, staged, "deleted assignments are no longer staged")
+}
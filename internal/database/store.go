@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -11,14 +12,31 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// ChannelVerificationStatus is the outcome of the last verification of a notification channel with Google
+type ChannelVerificationStatus string
+
+const (
+	// ChannelVerificationNone is a channel never verified
+	ChannelVerificationNone ChannelVerificationStatus = ""
+	// ChannelVerificationActive is a channel Google confirmed as active
+	ChannelVerificationActive ChannelVerificationStatus = "active"
+	// ChannelVerificationInactive is a channel Google no longer knows
+	ChannelVerificationInactive ChannelVerificationStatus = "inactive"
+	// ChannelVerificationError is a channel whose verification failed without telling its state
+	ChannelVerificationError ChannelVerificationStatus = "error"
+)
+
 // NotificationChannel represents a Google Calendar notification channel
 type NotificationChannel struct {
-	ID         string
-	ResourceID string
-	CalendarID string
-	Expiration time.Time
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID                 string
+	ResourceID         string
+	CalendarID         string
+	Expiration         time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	LastNotificationAt *time.Time // Nil until Google delivers a notification on the channel
+	VerificationStatus ChannelVerificationStatus
+	VerifiedAt         *time.Time // Nil until the channel is verified
 }
 
 // TokenStore handles OAuth token storage in SQLite
@@ -43,7 +61,7 @@ func (s *TokenStore) SaveToken(token *oauth2.Token) error {
 	}
 
 	s.logger.Debug().Msg("Executing query to save token")
-	_, err = s.db.Exec(`
+	_, err = execWithRetry(context.Background(), s.db, `
 	INSERT OR REPLACE INTO oauth_tokens (id, token_data)
 	VALUES (1, ?)`, tokenJSON)
 	if err != nil {
@@ -85,7 +103,7 @@ func (s *TokenStore) GetToken() (*oauth2.Token, error) {
 // ClearToken removes the saved OAuth token
 func (s *TokenStore) ClearToken() error {
 	s.logger.Debug().Msg("Clearing OAuth token") // Changed to Debug
-	_, err := s.db.Exec(`DELETE FROM oauth_tokens WHERE id = 1`)
+	_, err := execWithRetry(context.Background(), s.db, `DELETE FROM oauth_tokens WHERE id = 1`)
 	if err != nil {
 		s.logger.Debug().Err(err).Msg("Failed to execute clear token query") // Changed to Debug
 		return fmt.Errorf("failed to clear token: %w", err)
@@ -104,7 +122,7 @@ func (s *TokenStore) SaveSelectedCalendar(calendarID string) error {
 func (s *TokenStore) SaveSelectedCalendarWithName(calendarID string, calendarName string) error {
 	saveLogger := s.logger.With().Str("calendar_id", calendarID).Str("calendar_name", calendarName).Logger()
 	saveLogger.Debug().Msg("Saving selected calendar ID and name")
-	_, err := s.db.Exec(`
+	_, err := execWithRetry(context.Background(), s.db, `
 	INSERT OR REPLACE INTO calendar_settings (id, calendar_id, calendar_name)
 	VALUES (1, ?, ?)`, calendarID, calendarName)
 	if err != nil {
@@ -152,6 +170,56 @@ func (s *TokenStore) GetSelectedCalendarWithName() (calendarID string, calendarN
 	return calendarID, calendarName, nil
 }
 
+// ClearSelectedCalendar removes the saved calendar selection
+func (s *TokenStore) ClearSelectedCalendar() error {
+	s.logger.Debug().Msg("Clearing selected calendar")
+	_, err := execWithRetry(context.Background(), s.db, `DELETE FROM calendar_settings WHERE id = 1`)
+	if err != nil {
+		s.logger.Debug().Err(err).Msg("Failed to execute clear selected calendar query")
+		return fmt.Errorf("failed to clear selected calendar: %w", err)
+	}
+	s.logger.Debug().Msg("Selected calendar cleared successfully")
+	return nil
+}
+
+// SaveOAuthState stores a pending OAuth state value until expiresAt.
+// Expired states left behind by abandoned flows are purged at the same time.
+func (s *TokenStore) SaveOAuthState(state string, expiresAt time.Time) error {
+	s.logger.Debug().Time("expires_at", expiresAt).Msg("Saving OAuth state")
+	if _, err := execWithRetry(context.Background(), s.db, `DELETE FROM oauth_states WHERE expires_at <= ?`, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		s.logger.Debug().Err(err).Msg("Failed to purge expired OAuth states")
+		return fmt.Errorf("failed to purge expired OAuth states: %w", err)
+	}
+	_, err := execWithRetry(context.Background(), s.db, `
+	INSERT INTO oauth_states (state, expires_at)
+	VALUES (?, ?)`, state, expiresAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		s.logger.Debug().Err(err).Msg("Failed to execute save OAuth state query")
+		return fmt.Errorf("failed to save OAuth state: %w", err)
+	}
+	s.logger.Debug().Msg("OAuth state saved successfully")
+	return nil
+}
+
+// ConsumeOAuthState deletes a pending OAuth state and reports whether it existed and had
+// not expired. A state can only be consumed once.
+func (s *TokenStore) ConsumeOAuthState(state string) (bool, error) {
+	s.logger.Debug().Msg("Consuming OAuth state")
+	result, err := execWithRetry(context.Background(), s.db, `
+	DELETE FROM oauth_states WHERE state = ? AND expires_at > ?`,
+		state, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		s.logger.Debug().Err(err).Msg("Failed to execute consume OAuth state query")
+		return false, fmt.Errorf("failed to consume OAuth state: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	s.logger.Debug().Bool("valid", rowsAffected == 1).Msg("OAuth state consumed")
+	return rowsAffected == 1, nil
+}
+
 // SaveNotificationChannel saves a notification channel
 func (s *TokenStore) SaveNotificationChannel(channel *NotificationChannel) error {
 	saveLogger := s.logger.With().
@@ -161,7 +229,7 @@ func (s *TokenStore) SaveNotificationChannel(channel *NotificationChannel) error
 		Time("expiration", channel.Expiration).
 		Logger()
 	saveLogger.Debug().Msg("Saving notification channel") // Changed to Debug
-	_, err := s.db.Exec(`
+	_, err := execWithRetry(context.Background(), s.db, `
 	INSERT OR REPLACE INTO notification_channels (id, resource_id, calendar_id, expiration)
 	VALUES (?, ?, ?, ?)`,
 		channel.ID, channel.ResourceID, channel.CalendarID, channel.Expiration.Format(time.RFC3339))
@@ -173,42 +241,33 @@ func (s *TokenStore) SaveNotificationChannel(channel *NotificationChannel) error
 	return nil
 }
 
-// GetNotificationChannelByID retrieves a notification channel by its ID
-func (s *TokenStore) GetNotificationChannelByID(id string) (*NotificationChannel, error) {
-	getLogger := s.logger.With().Str("channel_id", id).Logger()
-	getLogger.Debug().Msg("Retrieving notification channel by ID")
-	if id == "" {
-		getLogger.Debug().Msg("Empty channel ID provided") // Changed to Debug
-		return nil, nil
-	}
+// notificationChannelColumns are the columns scanned by scanNotificationChannel
+const notificationChannelColumns = `id, resource_id, calendar_id, expiration, created_at, updated_at,
+	last_notification_at, verification_status, verified_at`
 
+// scanNotificationChannel scans a row of notificationChannelColumns
+func (s *TokenStore) scanNotificationChannel(row interface{ Scan(...any) error }) (*NotificationChannel, error) {
 	var channel NotificationChannel
 	var expirationStr, createdAtStr, updatedAtStr string
-
-	err := s.db.QueryRow(`
-	SELECT id, resource_id, calendar_id, expiration, created_at, updated_at
-	FROM notification_channels
-	WHERE id = ?`, id).Scan(
+	var lastNotificationStr, verifiedAtStr sql.NullString
+	if err := row.Scan(
 		&channel.ID,
 		&channel.ResourceID,
 		&channel.CalendarID,
 		&expirationStr,
 		&createdAtStr,
 		&updatedAtStr,
-	)
-
-	if err == sql.ErrNoRows {
-		getLogger.Debug().Msg("Notification channel not found") // Changed to Debug
-		return nil, nil
-	}
-	if err != nil {
-		getLogger.Debug().Err(err).Msg("Failed to retrieve notification channel") // Changed to Debug
-		return nil, fmt.Errorf("failed to retrieve notification channel: %w", err)
+		&lastNotificationStr,
+		&channel.VerificationStatus,
+		&verifiedAtStr,
+	); err != nil {
+		return nil, err
 	}
+	channelLogger := s.logger.With().Str("channel_id", channel.ID).Logger()
 
 	expiration, err := time.Parse(time.RFC3339, expirationStr)
 	if err != nil {
-		getLogger.Debug().Err(err).Str("expiration_string", expirationStr).Msg("Failed to parse expiration date") // Changed to Debug
+		channelLogger.Debug().Err(err).Str("expiration_string", expirationStr).Msg("Failed to parse expiration date for channel")
 		return nil, fmt.Errorf("failed to parse expiration date: %w", err)
 	}
 	channel.Expiration = expiration
@@ -217,88 +276,130 @@ func (s *TokenStore) GetNotificationChannelByID(id string) (*NotificationChannel
 	if err == nil {
 		channel.CreatedAt = createdAt
 	} else {
-		getLogger.Debug().Err(err).Str("timestamp_string", createdAtStr).Msg("Failed to parse created_at timestamp") // Changed to Debug
+		channelLogger.Debug().Err(err).Str("timestamp_string", createdAtStr).Msg("Failed to parse created_at timestamp")
 	}
 
 	updatedAt, err := time.Parse("2006-01-02 15:04:05", updatedAtStr)
 	if err == nil {
 		channel.UpdatedAt = updatedAt
 	} else {
-		getLogger.Debug().Err(err).Str("timestamp_string", updatedAtStr).Msg("Failed to parse updated_at timestamp") // Changed to Debug
+		channelLogger.Debug().Err(err).Str("timestamp_string", updatedAtStr).Msg("Failed to parse updated_at timestamp")
 	}
 
-	getLogger.Debug().Msg("Notification channel retrieved successfully")
+	if lastNotificationStr.Valid {
+		if lastNotification, err := time.Parse(time.RFC3339, lastNotificationStr.String); err == nil {
+			channel.LastNotificationAt = &lastNotification
+		}
+	}
+	if verifiedAtStr.Valid {
+		if verifiedAt, err := time.Parse(time.RFC3339, verifiedAtStr.String); err == nil {
+			channel.VerifiedAt = &verifiedAt
+		}
+	}
 	return &channel, nil
 }
 
+// GetNotificationChannelByID retrieves a notification channel by its ID
+func (s *TokenStore) GetNotificationChannelByID(id string) (*NotificationChannel, error) {
+	getLogger := s.logger.With().Str("channel_id", id).Logger()
+	getLogger.Debug().Msg("Retrieving notification channel by ID")
+	if id == "" {
+		getLogger.Debug().Msg("Empty channel ID provided") // Changed to Debug
+		return nil, nil
+	}
+
+	channel, err := s.scanNotificationChannel(s.db.QueryRow(`
+	SELECT `+notificationChannelColumns+`
+	FROM notification_channels
+	WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		getLogger.Debug().Msg("Notification channel not found") // Changed to Debug
+		return nil, nil
+	}
+	if err != nil {
+		getLogger.Debug().Err(err).Msg("Failed to retrieve notification channel") // Changed to Debug
+		return nil, fmt.Errorf("failed to retrieve notification channel: %w", err)
+	}
+
+	getLogger.Debug().Msg("Notification channel retrieved successfully")
+	return channel, nil
+}
+
 // GetActiveNotificationChannels retrieves all active notification channels
 func (s *TokenStore) GetActiveNotificationChannels() ([]*NotificationChannel, error) {
 	s.logger.Debug().Msg("Retrieving active notification channels")
-	rows, err := s.db.Query(`
-	SELECT id, resource_id, calendar_id, expiration, created_at, updated_at
+	return s.queryNotificationChannels(`
+	SELECT ` + notificationChannelColumns + `
 	FROM notification_channels
 	WHERE expiration > datetime('now')
 	ORDER BY expiration ASC`)
+}
+
+// ListNotificationChannels retrieves every notification channel, expired ones included, ordered by
+// expiration
+func (s *TokenStore) ListNotificationChannels() ([]*NotificationChannel, error) {
+	s.logger.Debug().Msg("Retrieving all notification channels")
+	return s.queryNotificationChannels(`
+	SELECT ` + notificationChannelColumns + `
+	FROM notification_channels
+	ORDER BY expiration ASC`)
+}
+
+// queryNotificationChannels runs a query selecting notificationChannelColumns
+func (s *TokenStore) queryNotificationChannels(query string) ([]*NotificationChannel, error) {
+	rows, err := s.db.Query(query)
 	if err != nil {
-		s.logger.Debug().Err(err).Msg("Failed to query active notification channels") // Changed to Debug
+		s.logger.Debug().Err(err).Msg("Failed to query notification channels")
 		return nil, fmt.Errorf("failed to query notification channels: %w", err)
 	}
 	defer rows.Close()
 
 	var channels []*NotificationChannel
 	for rows.Next() {
-		var channel NotificationChannel
-		var expirationStr, createdAtStr, updatedAtStr string
-
-		if err := rows.Scan(
-			&channel.ID,
-			&channel.ResourceID,
-			&channel.CalendarID,
-			&expirationStr,
-			&createdAtStr,
-			&updatedAtStr,
-		); err != nil {
-			s.logger.Debug().Err(err).Msg("Failed to scan notification channel row") // Changed to Debug
-			return nil, fmt.Errorf("failed to scan notification channel: %w", err)
-		}
-
-		expiration, err := time.Parse(time.RFC3339, expirationStr)
+		channel, err := s.scanNotificationChannel(rows)
 		if err != nil {
-			s.logger.Debug().Err(err).Str("expiration_string", expirationStr).Str("channel_id", channel.ID).Msg("Failed to parse expiration date for channel") // Changed to Debug
-			return nil, fmt.Errorf("failed to parse expiration date: %w", err)
-		}
-		channel.Expiration = expiration
-
-		createdAt, err := time.Parse("2006-01-02 15:04:05", createdAtStr)
-		if err == nil {
-			channel.CreatedAt = createdAt
-		} else {
-			s.logger.Debug().Err(err).Str("timestamp_string", createdAtStr).Str("channel_id", channel.ID).Msg("Failed to parse created_at timestamp") // Changed to Debug
-		}
-
-		updatedAt, err := time.Parse("2006-01-02 15:04:05", updatedAtStr)
-		if err == nil {
-			channel.UpdatedAt = updatedAt
-		} else {
-			s.logger.Debug().Err(err).Str("timestamp_string", updatedAtStr).Str("channel_id", channel.ID).Msg("Failed to parse updated_at timestamp") // Changed to Debug
+			s.logger.Debug().Err(err).Msg("Failed to scan notification channel row")
+			return nil, fmt.Errorf("failed to scan notification channel: %w", err)
 		}
-
-		channels = append(channels, &channel)
+		channels = append(channels, channel)
 	}
 	if err := rows.Err(); err != nil {
-		s.logger.Debug().Err(err).Msg("Error iterating active notification channel rows") // Changed to Debug
+		s.logger.Debug().Err(err).Msg("Error iterating notification channel rows")
 		return nil, fmt.Errorf("failed during row iteration: %w", err)
 	}
 
-	s.logger.Debug().Int("count", len(channels)).Msg("Active notification channels retrieved successfully")
+	s.logger.Debug().Int("count", len(channels)).Msg("Notification channels retrieved successfully")
 	return channels, nil
 }
 
+// RecordNotificationReceived records that Google delivered a notification on the channel at receivedAt
+func (s *TokenStore) RecordNotificationReceived(id string, receivedAt time.Time) error {
+	_, err := execWithRetry(context.Background(), s.db, `UPDATE notification_channels SET last_notification_at = ? WHERE id = ?`,
+		receivedAt.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		s.logger.Debug().Err(err).Str("channel_id", id).Msg("Failed to record notification received")
+		return fmt.Errorf("failed to record notification received: %w", err)
+	}
+	return nil
+}
+
+// RecordNotificationChannelVerification records the outcome of the verification of the channel with
+// Google at verifiedAt
+func (s *TokenStore) RecordNotificationChannelVerification(id string, status ChannelVerificationStatus, verifiedAt time.Time) error {
+	_, err := execWithRetry(context.Background(), s.db, `UPDATE notification_channels SET verification_status = ?, verified_at = ? WHERE id = ?`,
+		string(status), verifiedAt.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		s.logger.Debug().Err(err).Str("channel_id", id).Msg("Failed to record notification channel verification")
+		return fmt.Errorf("failed to record notification channel verification: %w", err)
+	}
+	return nil
+}
+
 // DeleteNotificationChannel deletes a notification channel by its ID
 func (s *TokenStore) DeleteNotificationChannel(id string) error {
 	deleteLogger := s.logger.With().Str("channel_id", id).Logger()
 	deleteLogger.Debug().Msg("Deleting notification channel") // Changed to Debug
-	result, err := s.db.Exec(`DELETE FROM notification_channels WHERE id = ?`, id)
+	result, err := execWithRetry(context.Background(), s.db, `DELETE FROM notification_channels WHERE id = ?`, id)
 	if err != nil {
 		deleteLogger.Debug().Err(err).Msg("Failed to execute delete notification channel query") // Changed to Debug
 		return fmt.Errorf("failed to delete notification channel: %w", err)
@@ -311,7 +412,7 @@ func (s *TokenStore) DeleteNotificationChannel(id string) error {
 // DeleteExpiredNotificationChannels deletes all expired notification channels
 func (s *TokenStore) DeleteExpiredNotificationChannels() error {
 	s.logger.Debug().Msg("Deleting expired notification channels") // Changed to Debug
-	result, err := s.db.Exec(`DELETE FROM notification_channels WHERE expiration <= datetime('now')`)
+	result, err := execWithRetry(context.Background(), s.db, `DELETE FROM notification_channels WHERE expiration <= datetime('now')`)
 	if err != nil {
 		s.logger.Debug().Err(err).Msg("Failed to execute delete expired notification channels query") // Changed to Debug
 		return fmt.Errorf("failed to delete expired notification channels: %w", err)
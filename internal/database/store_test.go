@@ -0,0 +1,59 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestTokenStore(t *testing.T) *TokenStore {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_tokens.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewTokenStore(db)
+	require.NoError(t, err, "Failed to create token store")
+	return store
+}
+
+func TestTokenStore_NotificationChannelHealth(t *testing.T) {
+	store := setupTestTokenStore(t)
+
+	expiration := time.Now().Add(20 * 24 * time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, store.SaveNotificationChannel(&NotificationChannel{ID: "active", ResourceID: "resource", CalendarID: "family", Expiration: expiration}))
+	require.NoError(t, store.SaveNotificationChannel(&NotificationChannel{ID: "expired", ResourceID: "old", CalendarID: "family", Expiration: time.Now().Add(-48 * time.Hour)}))
+
+	channel, err := store.GetNotificationChannelByID("active")
+	require.NoError(t, err)
+	assert.Nil(t, channel.LastNotificationAt)
+	assert.Nil(t, channel.VerifiedAt)
+	assert.Equal(t, ChannelVerificationNone, channel.VerificationStatus)
+
+	received := time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC)
+	verified := received.Add(time.Hour)
+	require.NoError(t, store.RecordNotificationReceived("active", received))
+	require.NoError(t, store.RecordNotificationChannelVerification("active", ChannelVerificationActive, verified))
+
+	channel, err = store.GetNotificationChannelByID("active")
+	require.NoError(t, err)
+	require.NotNil(t, channel.LastNotificationAt)
+	assert.True(t, received.Equal(*channel.LastNotificationAt))
+	require.NotNil(t, channel.VerifiedAt)
+	assert.True(t, verified.Equal(*channel.VerifiedAt))
+	assert.Equal(t, ChannelVerificationActive, channel.VerificationStatus)
+	assert.True(t, expiration.Equal(channel.Expiration))
+
+	active, err := store.GetActiveNotificationChannels()
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "active", active[0].ID)
+
+	all, err := store.ListNotificationChannels()
+	require.NoError(t, err)
+	require.Len(t, all, 2, "expired channels listed too")
+	assert.Equal(t, "expired", all[0].ID, "ordered by expiration")
+}
@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// Statuses of a swap request
+const (
+	SwapRequestStatusPending   = "pending"
+	SwapRequestStatusAccepted  = "accepted"
+	SwapRequestStatusDeclined  = "declined"
+	SwapRequestStatusCancelled = "cancelled"
+)
+
+// ErrSwapRequestNotFound is returned for a swap request that does not exist
+var ErrSwapRequestNotFound = errors.New("swap request not found")
+
+// ErrSwapRequestResolved is returned when answering a swap request that is no longer pending
+var ErrSwapRequestResolved = errors.New("swap request already resolved")
+
+// SwapRequest is a swap of two nights proposed by a parent: once the responder accepts it, the
+// responder takes ProposerDate and the proposer takes ResponderDate
+type SwapRequest struct {
+	ID            int64
+	Proposer      string
+	ProposerDate  time.Time
+	Responder     string
+	ResponderDate time.Time
+	Status        string
+	CreatedAt     time.Time
+	ResolvedAt    time.Time // Zero while pending
+}
+
+// SwapRequestStore stores the swap requests (swap_requests table)
+type SwapRequestStore struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewSwapRequestStore creates a new swap request store
+func NewSwapRequestStore(db *DB) (*SwapRequestStore, error) {
+	logger := logging.GetLogger("swap-request-store")
+	return &SwapRequestStore{db: db, logger: logger}, nil
+}
+
+// CreateSwapRequest stores a pending swap request proposed at now and returns it
+func (s *SwapRequestStore) CreateSwapRequest(proposer string, proposerDate time.Time, responder string, responderDate time.Time, now time.Time) (*SwapRequest, error) {
+	request := &SwapRequest{
+		Proposer:      proposer,
+		ProposerDate:  dateOnly(proposerDate),
+		Responder:     responder,
+		ResponderDate: dateOnly(responderDate),
+		Status:        SwapRequestStatusPending,
+		CreatedAt:     now.UTC().Truncate(time.Second),
+	}
+	s.logger.Debug().Str("proposer", proposer).Str("responder", responder).Msg("Creating swap request")
+
+	result, err := s.db.ExecContext(context.Background(), `
+	INSERT INTO swap_requests (proposer, proposer_date, responder, responder_date, status, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`, proposer, request.ProposerDate.Format(time.DateOnly), responder, request.ResponderDate.Format(time.DateOnly),
+		request.Status, request.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create swap request: %w", err)
+	}
+	request.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swap request ID: %w", err)
+	}
+	return request, nil
+}
+
+// GetSwapRequest returns the swap request id, ErrSwapRequestNotFound when there is none
+func (s *SwapRequestStore) GetSwapRequest(id int64) (*SwapRequest, error) {
+	request, err := scanSwapRequest(s.db.Conn().QueryRow(`
+	SELECT id, proposer, proposer_date, responder, responder_date, status, created_at, resolved_at
+	FROM swap_requests WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrSwapRequestNotFound, id)
+	}
+	return request, err
+}
+
+// ListSwapRequests returns the most recent swap requests, newest first
+func (s *SwapRequestStore) ListSwapRequests(limit int) ([]*SwapRequest, error) {
+	rows, err := s.db.Conn().Query(`
+	SELECT id, proposer, proposer_date, responder, responder_date, status, created_at, resolved_at
+	FROM swap_requests ORDER BY created_at DESC, id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query swap requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []*SwapRequest{}
+	for rows.Next() {
+		request, err := scanSwapRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate swap requests: %w", err)
+	}
+	return requests, nil
+}
+
+// ResolveSwapRequest sets the status of the pending swap request id at now. Returns
+// ErrSwapRequestNotFound when it does not exist and ErrSwapRequestResolved when it is no longer pending.
+func (s *SwapRequestStore) ResolveSwapRequest(id int64, status string, now time.Time) error {
+	s.logger.Debug().Int64("swap_request_id", id).Str("status", status).Msg("Resolving swap request")
+	result, err := s.db.ExecContext(context.Background(), `
+	UPDATE swap_requests SET status = ?, resolved_at = ?
+	WHERE id = ? AND status = ?`, status, now.UTC().Format(time.RFC3339), id, SwapRequestStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to resolve swap request: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check resolved swap request: %w", err)
+	}
+	if affected == 0 {
+		if _, err := s.GetSwapRequest(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %d", ErrSwapRequestResolved, id)
+	}
+	return nil
+}
+
+// scanSwapRequest scans a row of swap_requests
+func scanSwapRequest(scanner interface{ Scan(dest ...any) error }) (*SwapRequest, error) {
+	var request SwapRequest
+	var proposerDate, responderDate, createdAt string
+	var resolvedAt sql.NullString
+	if err := scanner.Scan(&request.ID, &request.Proposer, &proposerDate, &request.Responder, &responderDate,
+		&request.Status, &createdAt, &resolvedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan swap request: %w", err)
+	}
+	var err error
+	if request.ProposerDate, err = time.Parse(time.DateOnly, proposerDate); err != nil {
+		return nil, fmt.Errorf("failed to parse swap request date: %w", err)
+	}
+	if request.ResponderDate, err = time.Parse(time.DateOnly, responderDate); err != nil {
+		return nil, fmt.Errorf("failed to parse swap request date: %w", err)
+	}
+	if request.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse swap request creation time: %w", err)
+	}
+	if resolvedAt.Valid {
+		if request.ResolvedAt, err = time.Parse(time.RFC3339, resolvedAt.String); err != nil {
+			return nil, fmt.Errorf("failed to parse swap request resolution time: %w", err)
+		}
+	}
+	return &request, nil
+}
+
+// dateOnly returns the day of t at midnight UTC, as a date is read back from the database
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
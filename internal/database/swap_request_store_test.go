@@ -0,0 +1,51 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapRequestStore(t *testing.T) {
+	db, err := New(NewDefaultOptions(filepath.Join(t.TempDir(), "test_swap_requests.db")))
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewSwapRequestStore(db)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	friday := time.Date(2026, 10, 23, 0, 0, 0, 0, time.Local)
+	tuesday := time.Date(2026, 10, 27, 0, 0, 0, 0, time.Local)
+	created, err := store.CreateSwapRequest("Alice", friday, "Bob", tuesday, now)
+	require.NoError(t, err)
+	second, err := store.CreateSwapRequest("Bob", tuesday, "Alice", friday, now.Add(time.Minute))
+	require.NoError(t, err)
+
+	request, err := store.GetSwapRequest(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, request)
+	assert.Equal(t, "2026-10-23", request.ProposerDate.Format(time.DateOnly))
+	assert.Equal(t, SwapRequestStatusPending, request.Status)
+	assert.True(t, request.ResolvedAt.IsZero())
+
+	requests, err := store.ListSwapRequests(10)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, second.ID, requests[0].ID, "newest first")
+
+	require.NoError(t, store.ResolveSwapRequest(created.ID, SwapRequestStatusAccepted, now.Add(time.Hour)))
+	request, err = store.GetSwapRequest(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SwapRequestStatusAccepted, request.Status)
+	assert.Equal(t, now.Add(time.Hour), request.ResolvedAt)
+
+	require.ErrorIs(t, store.ResolveSwapRequest(created.ID, SwapRequestStatusDeclined, now), ErrSwapRequestResolved)
+	require.ErrorIs(t, store.ResolveSwapRequest(42, SwapRequestStatusDeclined, now), ErrSwapRequestNotFound)
+	_, err = store.GetSwapRequest(42)
+	require.ErrorIs(t, err, ErrSwapRequestNotFound)
+}
@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/rs/zerolog"
+)
+
+// Statuses of a sync run
+const (
+	SyncRunStatusRunning = "running"
+	SyncRunStatusSuccess = "success"
+	SyncRunStatusFailed  = "failed"
+)
+
+// syncRunRetention is how long sync runs are kept before being purged
+const syncRunRetention = 90 * 24 * time.Hour
+
+// SyncRun is one recorded schedule sync
+type SyncRun struct {
+	ID               int64
+	Trigger          constants.SyncTrigger
+	Status           string
+	StartedAt        time.Time
+	FinishedAt       *time.Time // nil while the run is in progress
+	AssignmentsCount int
+	Error            string
+	// Final progress of the calendar sync, zero while the run is in progress
+	EventsTotal     int
+	EventsProcessed int
+	EventsFailed    int
+}
+
+// Duration returns how long the run took, or zero while it is in progress
+func (r *SyncRun) Duration() time.Duration {
+	if r.FinishedAt == nil {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// SyncRunStore records the history of schedule syncs in SQLite
+type SyncRunStore struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+// NewSyncRunStore creates a new sync run store
+func NewSyncRunStore(db *DB) (*SyncRunStore, error) {
+	logger := logging.GetLogger("sync-run-store")
+	return &SyncRunStore{db: db.Conn(), logger: logger}, nil
+}
+
+// StartRun records the start of a sync and returns the ID of the run
+func (s *SyncRunStore) StartRun(trigger constants.SyncTrigger) (int64, error) {
+	s.logger.Debug().Str("trigger", trigger.String()).Msg("Recording sync run start")
+	result, err := execWithRetry(context.Background(), s.db, `
+	INSERT INTO sync_runs (trigger, status, started_at)
+	VALUES (?, ?, ?)`, trigger.String(), SyncRunStatusRunning, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record sync run start: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sync run ID: %w", err)
+	}
+	return id, nil
+}
+
+// FinishRun records the outcome of a sync with the final progress of its calendar sync
+func (s *SyncRunStore) FinishRun(id int64, assignmentsCount int, progress signals.SyncProgressData, runErr error) error {
+	status, errorMessage := runStatus(runErr), ""
+	if runErr != nil {
+		errorMessage = runErr.Error()
+	}
+	s.logger.Debug().Int64("sync_run_id", id).Str("status", status).Msg("Recording sync run outcome")
+
+	now := time.Now().UTC()
+	_, err := execWithRetry(context.Background(), s.db, `
+	UPDATE sync_runs SET status = ?, finished_at = ?, assignments_count = ?, error = ?,
+		events_total = ?, events_processed = ?, events_failed = ?
+	WHERE id = ?`, status, now.Format(time.RFC3339Nano), assignmentsCount, errorMessage,
+		progress.Total, progress.Processed, progress.Failed, id)
+	if err != nil {
+		return fmt.Errorf("failed to record sync run outcome: %w", err)
+	}
+	return nil
+}
+
+// PruneRuns deletes the runs started before the retention period at now and returns how many
+func (s *SyncRunStore) PruneRuns(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := now.UTC().Add(-syncRunRetention).Format(time.RFC3339Nano)
+	result, err := execWithRetry(ctx, s.db, `DELETE FROM sync_runs WHERE started_at < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge old sync runs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// SyncRunning reports whether a sync started after since is still running. Runs started before
+// since are ignored, so that a run left running by a crash does not block forever.
+func (s *SyncRunStore) SyncRunning(since time.Time) (bool, error) {
+	var running bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM sync_runs WHERE status = ? AND started_at >= ?)`,
+		SyncRunStatusRunning, since.UTC().Format(time.RFC3339Nano)).Scan(&running)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for a running sync: %w", err)
+	}
+	return running, nil
+}
+
+// RecordRun records run as a sync started by trigger and emits the SyncCompleted signal once it
+// ends. run returns the number of assignments it synced; the calendar sync reports its progress
+// through the context given to run, emitted as SyncProgress and persisted with the outcome.
+// Failing to record never fails the sync: the error returned is the one of run. A nil store runs
+// the sync without recording it.
+func (s *SyncRunStore) RecordRun(ctx context.Context, trigger constants.SyncTrigger, run func(ctx context.Context) (int, error)) error {
+	if s == nil {
+		runCtx, progress := signals.WithSyncProgress(ctx, 0, trigger.String())
+		assignmentsCount, err := run(runCtx)
+		progress.Finish(ctx, runStatus(err))
+		signals.EmitSyncCompleted(ctx, trigger.String(), assignmentsCount, err)
+		return err
+	}
+
+	id, startErr := s.StartRun(trigger)
+	if startErr != nil {
+		s.logger.Warn().Err(startErr).Str("trigger", trigger.String()).Msg("Failed to record sync run start")
+	}
+
+	runCtx, progress := signals.WithSyncProgress(ctx, id, trigger.String())
+	assignmentsCount, runErr := run(runCtx)
+	summary := progress.Finish(ctx, runStatus(runErr))
+
+	if startErr == nil {
+		if err := s.FinishRun(id, assignmentsCount, summary, runErr); err != nil {
+			s.logger.Warn().Err(err).Int64("sync_run_id", id).Msg("Failed to record sync run outcome")
+		}
+	}
+	signals.EmitSyncCompleted(ctx, trigger.String(), assignmentsCount, runErr)
+	return runErr
+}
+
+// runStatus returns the status of a run ended with err
+func runStatus(err error) string {
+	if err != nil {
+		return SyncRunStatusFailed
+	}
+	return SyncRunStatusSuccess
+}
+
+// ListRuns returns the most recent sync runs, newest first
+func (s *SyncRunStore) ListRuns(limit int) ([]*SyncRun, error) {
+	s.logger.Debug().Int("limit", limit).Msg("Listing sync runs")
+	rows, err := s.db.Query(`
+	SELECT id, trigger, status, started_at, finished_at, assignments_count, error, events_total, events_processed, events_failed
+	FROM sync_runs
+	ORDER BY started_at DESC, id DESC
+	LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*SyncRun{}
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sync runs: %w", err)
+	}
+	return runs, nil
+}
+
+// CountRuns returns how many syncs started since since, and how many of them failed. Runs still in
+// progress are counted in neither.
+func (s *SyncRunStore) CountRuns(since time.Time) (total int, failed int, err error) {
+	err = s.db.QueryRow(`
+	SELECT COUNT(*), COUNT(CASE WHEN status = ? THEN 1 END)
+	FROM sync_runs
+	WHERE started_at >= ? AND status != ?`,
+		SyncRunStatusFailed, since.UTC().Format(time.RFC3339Nano), SyncRunStatusRunning).Scan(&total, &failed)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count sync runs: %w", err)
+	}
+	return total, failed, nil
+}
+
+// LastSuccessfulRun returns the most recent successful sync started by trigger, nil when there is none
+func (s *SyncRunStore) LastSuccessfulRun(trigger constants.SyncTrigger) (*SyncRun, error) {
+	s.logger.Debug().Str("trigger", trigger.String()).Msg("Getting last successful sync run")
+	run, err := scanSyncRun(s.db.QueryRow(`
+	SELECT id, trigger, status, started_at, finished_at, assignments_count, error, events_total, events_processed, events_failed
+	FROM sync_runs
+	WHERE trigger = ? AND status = ?
+	ORDER BY started_at DESC, id DESC
+	LIMIT 1`, trigger.String(), SyncRunStatusSuccess))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return run, err
+}
+
+// scanSyncRun scans a row of the sync_runs columns, sql.ErrNoRows returned as is
+func scanSyncRun(row interface{ Scan(dest ...any) error }) (*SyncRun, error) {
+	var run SyncRun
+	var trigger, startedAtStr string
+	var finishedAtStr sql.NullString
+	if err := row.Scan(&run.ID, &trigger, &run.Status, &startedAtStr, &finishedAtStr, &run.AssignmentsCount, &run.Error,
+		&run.EventsTotal, &run.EventsProcessed, &run.EventsFailed); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan sync run: %w", err)
+	}
+	run.Trigger = constants.SyncTrigger(trigger)
+
+	var err error
+	run.StartedAt, err = time.Parse(time.RFC3339Nano, startedAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sync run start: %w", err)
+	}
+	if finishedAtStr.Valid {
+		finishedAt, err := time.Parse(time.RFC3339Nano, finishedAtStr.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sync run end: %w", err)
+		}
+		run.FinishedAt = &finishedAt
+	}
+	return &run, nil
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestSyncRunStore(t *testing.T) (*SyncRunStore, *DB) {
+	opts := SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "test_sync_runs.db"),
+		Mode:        "rwc",
+		Cache:       CachePrivate,
+		Journal:     JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+		Synchronous: SynchronousNormal,
+	}
+
+	db, err := New(opts)
+	require.NoError(t, err, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase(), "Failed to run migrations")
+
+	store, err := NewSyncRunStore(db)
+	require.NoError(t, err, "Failed to create sync run store")
+	return store, db
+}
+
+func TestSyncRunStore_RecordRun(t *testing.T) {
+	store, _ := setupTestSyncRunStore(t)
+
+	err := store.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 7, nil })
+	require.NoError(t, err)
+
+	syncErr := errors.New("failed to list events")
+	err = store.RecordRun(context.Background(), constants.SyncTriggerManual, func(context.Context) (int, error) { return 3, syncErr })
+	assert.Equal(t, syncErr, err, "the sync error is returned unchanged")
+
+	runs, err := store.ListRuns(10)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+
+	// Newest first
+	failed, succeeded := runs[0], runs[1]
+	assert.Equal(t, constants.SyncTriggerManual, failed.Trigger)
+	assert.Equal(t, SyncRunStatusFailed, failed.Status)
+	assert.Equal(t, 3, failed.AssignmentsCount)
+	assert.Equal(t, "failed to list events", failed.Error)
+	require.NotNil(t, failed.FinishedAt)
+
+	assert.Equal(t, constants.SyncTriggerScheduled, succeeded.Trigger)
+	assert.Equal(t, SyncRunStatusSuccess, succeeded.Status)
+	assert.Equal(t, 7, succeeded.AssignmentsCount)
+	assert.Empty(t, succeeded.Error)
+	require.NotNil(t, succeeded.FinishedAt)
+	assert.GreaterOrEqual(t, succeeded.Duration(), time.Duration(0))
+}
+
+func TestSyncRunStore_RecordRunProgress(t *testing.T) {
+	store, _ := setupTestSyncRunStore(t)
+
+	err := store.RecordRun(context.Background(), constants.SyncTriggerManual, func(ctx context.Context) (int, error) {
+		signals.ReportSyncTotal(ctx, 3)
+		signals.ReportSyncStep(ctx, nil)
+		signals.ReportSyncStep(ctx, errors.New("quota exceeded"))
+		signals.ReportSyncStep(ctx, nil)
+		return 3, nil
+	})
+	require.NoError(t, err)
+
+	runs, err := store.ListRuns(1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 3, runs[0].EventsTotal)
+	assert.Equal(t, 3, runs[0].EventsProcessed)
+	assert.Equal(t, 1, runs[0].EventsFailed)
+}
+
+func TestSyncRunStore_RunningRun(t *testing.T) {
+	store, _ := setupTestSyncRunStore(t)
+
+	_, err := store.StartRun(constants.SyncTriggerWebhook)
+	require.NoError(t, err)
+
+	runs, err := store.ListRuns(10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, SyncRunStatusRunning, runs[0].Status)
+	assert.Nil(t, runs[0].FinishedAt)
+	assert.Zero(t, runs[0].Duration())
+}
+
+func TestSyncRunStore_ListRunsLimit(t *testing.T) {
+	store, _ := setupTestSyncRunStore(t)
+
+	for range 5 {
+		require.NoError(t, store.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 1, nil }))
+	}
+
+	runs, err := store.ListRuns(3)
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+}
+
+func TestSyncRunStore_LastSuccessfulRun(t *testing.T) {
+	store, _ := setupTestSyncRunStore(t)
+
+	run, err := store.LastSuccessfulRun(constants.SyncTriggerScheduled)
+	require.NoError(t, err)
+	assert.Nil(t, run, "no run recorded yet")
+
+	require.NoError(t, store.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 4, nil }))
+	require.Error(t, store.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 0, errors.New("boom") }))
+	require.NoError(t, store.RecordRun(context.Background(), constants.SyncTriggerManual, func(context.Context) (int, error) { return 2, nil }))
+
+	run, err = store.LastSuccessfulRun(constants.SyncTriggerScheduled)
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, constants.SyncTriggerScheduled, run.Trigger, "runs of the other triggers are ignored")
+	assert.Equal(t, 4, run.AssignmentsCount, "failed runs are ignored")
+	assert.NotNil(t, run.FinishedAt)
+}
+
+func TestSyncRunStore_CountRuns(t *testing.T) {
+	store, db := setupTestSyncRunStore(t)
+
+	lastWeek := time.Now().Add(-8 * 24 * time.Hour).UTC().Format(time.RFC3339Nano)
+	_, err := db.Conn().Exec(`INSERT INTO sync_runs (trigger, status, started_at, finished_at) VALUES ('scheduled', 'failed', ?, ?)`, lastWeek, lastWeek)
+	require.NoError(t, err)
+	require.NoError(t, store.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 4, nil }))
+	require.Error(t, store.RecordRun(context.Background(), constants.SyncTriggerManual, func(context.Context) (int, error) { return 0, errors.New("boom") }))
+	_, err = store.StartRun(constants.SyncTriggerWebhook)
+	require.NoError(t, err)
+
+	total, failed, err := store.CountRuns(time.Now().Add(-7 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, total, "older and running runs are not counted")
+	assert.Equal(t, 1, failed)
+}
+
+func TestSyncRunStore_PurgesOldRuns(t *testing.T) {
+	store, db := setupTestSyncRunStore(t)
+
+	old := time.Now().Add(-syncRunRetention - time.Hour).UTC().Format(time.RFC3339Nano)
+	_, err := db.Conn().Exec(`INSERT INTO sync_runs (trigger, status, started_at, finished_at) VALUES ('scheduled', 'success', ?, ?)`, old, old)
+	require.NoError(t, err)
+
+	require.NoError(t, store.RecordRun(context.Background(), constants.SyncTriggerManual, func(context.Context) (int, error) { return 0, nil }))
+
+	pruned, err := store.PruneRuns(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	runs, err := store.ListRuns(10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, constants.SyncTriggerManual, runs[0].Trigger)
+}
+
+func TestSyncRunStore_SyncRunning(t *testing.T) {
+	store, db := setupTestSyncRunStore(t)
+	now := time.Now()
+
+	running, err := store.SyncRunning(now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, running)
+
+	stale := now.Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+	_, err = db.Conn().Exec(`INSERT INTO sync_runs (trigger, status, started_at) VALUES ('scheduled', 'running', ?)`, stale)
+	require.NoError(t, err)
+	running, err = store.SyncRunning(now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, running, "a run left running before since is ignored")
+
+	id, err := store.StartRun(constants.SyncTriggerManual)
+	require.NoError(t, err)
+	running, err = store.SyncRunning(now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, running)
+
+	require.NoError(t, store.FinishRun(id, 0, signals.SyncProgressData{}, nil))
+	running, err = store.SyncRunning(now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, running)
+}
+
+func TestSyncRunStore_NilStoreRunsWithoutRecording(t *testing.T) {
+	var store *SyncRunStore
+	called := false
+	err := store.RecordRun(context.Background(), constants.SyncTriggerManual, func(ctx context.Context) (int, error) {
+		called = true
+		return 0, nil
+	})
+	require.NoError(t, err)
+	assert.True(t, called)
+}
@@ -0,0 +1,40 @@
+// Package dates does the calendar-day arithmetic of the schedule. A day is a date of the calendar,
+// not 24 hours: around a daylight saving time change a day lasts 23 or 25 hours, so adding or
+// truncating 24 hours lands on the wrong day. Truncate(24h) also rounds to midnight UTC, which is
+// another day than the local one for part of the day outside UTC.
+package dates
+
+import "time"
+
+// Day returns midnight of the calendar day of t, in the location of t. Where midnight is skipped
+// by a clock change, the first instant of the day is returned.
+func Day(t time.Time) time.Time {
+	return midnight(t.Year(), t.Month(), t.Day(), t.Location())
+}
+
+// AddDays returns midnight of the calendar day days after the one of t, in the location of t.
+// days may be negative.
+func AddDays(t time.Time, days int) time.Time {
+	return midnight(t.Year(), t.Month(), t.Day()+days, t.Location())
+}
+
+// DaysBetween returns the number of calendar days from the day of from to the day of to, negative
+// when to is before from. Each date is read in its own location, so that a date parsed from the
+// database in UTC compares with a local one by its year, month and day.
+func DaysBetween(from, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay) / (24 * time.Hour))
+}
+
+// midnight returns the first instant of the day in location, day normalized as by time.Date.
+// time.Date may resolve a midnight skipped by a clock change to the evening before, the first
+// instant is then the clock change.
+func midnight(year int, month time.Month, day int, location *time.Location) time.Time {
+	year, month, day = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Date()
+	t := time.Date(year, month, day, 0, 0, 0, 0, location)
+	if t.Day() != day {
+		_, t = t.ZoneBounds()
+	}
+	return t
+}
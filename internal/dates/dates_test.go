@@ -0,0 +1,128 @@
+package dates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return location
+}
+
+func TestDay(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+
+	// 20:00 in New York is already the next day in UTC
+	evening := time.Date(2026, 10, 16, 20, 0, 0, 0, newYork)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, newYork), Day(evening))
+	assert.Equal(t, 16, Day(evening).Day(), "the local day is kept")
+	assert.Equal(t, 20, evening.Truncate(24*time.Hour).In(newYork).Hour(), "Truncate rounds to midnight UTC")
+
+	// Midnight does not exist on the day Santiago moves its clocks forward
+	santiago := loadLocation(t, "America/Santiago")
+	day := Day(time.Date(2026, 9, 6, 12, 0, 0, 0, santiago))
+	assert.Equal(t, 6, day.Day())
+	assert.Equal(t, 1, day.Hour(), "the day starts with the clock change")
+	assert.Equal(t, 6, AddDays(time.Date(2026, 9, 5, 12, 0, 0, 0, santiago), 1).Day())
+}
+
+func TestAddDays(t *testing.T) {
+	paris := loadLocation(t, "Europe/Paris")
+
+	tests := []struct {
+		name string
+		from time.Time
+		days int
+		want time.Time
+	}{
+		{
+			name: "over the spring clock change",
+			from: time.Date(2026, 3, 28, 0, 0, 0, 0, paris),
+			days: 2,
+			want: time.Date(2026, 3, 30, 0, 0, 0, 0, paris),
+		},
+		{
+			name: "over the autumn clock change",
+			from: time.Date(2026, 10, 24, 0, 0, 0, 0, paris),
+			days: 1,
+			want: time.Date(2026, 10, 25, 0, 0, 0, 0, paris),
+		},
+		{
+			name: "backwards over the autumn clock change",
+			from: time.Date(2026, 10, 26, 21, 30, 0, 0, paris),
+			days: -5,
+			want: time.Date(2026, 10, 21, 0, 0, 0, 0, paris),
+		},
+		{
+			name: "over the end of the month",
+			from: time.Date(2026, 2, 27, 12, 0, 0, 0, time.UTC),
+			days: 3,
+			want: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AddDays(tt.from, tt.days)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+
+	// Adding 24 hours over the autumn clock change stays on the same day
+	assert.Equal(t, 25, time.Date(2026, 10, 25, 0, 0, 0, 0, paris).Add(24*time.Hour).Day())
+	assert.Equal(t, 26, AddDays(time.Date(2026, 10, 25, 0, 0, 0, 0, paris), 1).Day())
+}
+
+func TestDaysBetween(t *testing.T) {
+	paris := loadLocation(t, "Europe/Paris")
+	newYork := loadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{
+			name: "same day",
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, paris),
+			to:   time.Date(2026, 10, 16, 23, 59, 0, 0, paris),
+			want: 0,
+		},
+		{
+			name: "over the spring clock change",
+			from: time.Date(2026, 3, 28, 0, 0, 0, 0, paris),
+			to:   time.Date(2026, 3, 30, 0, 0, 0, 0, paris),
+			want: 2,
+		},
+		{
+			name: "over the autumn clock change",
+			from: time.Date(2026, 10, 25, 0, 0, 0, 0, paris),
+			to:   time.Date(2026, 10, 26, 0, 0, 0, 0, paris),
+			want: 1,
+		},
+		{
+			name: "backwards",
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, paris),
+			to:   time.Date(2026, 10, 11, 0, 0, 0, 0, paris),
+			want: -5,
+		},
+		{
+			name: "database date in UTC against a local evening",
+			from: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2026, 10, 16, 21, 0, 0, 0, newYork),
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DaysBetween(tt.from, tt.to))
+		})
+	}
+}
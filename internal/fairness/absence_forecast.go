@@ -0,0 +1,162 @@
+package fairness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+)
+
+const (
+	// absencePatternWeeks is how many past occurrences of a weekday are looked at for a pattern
+	absencePatternWeeks = 4
+	// absencePatternMinOverrides is how many of them must have been overridden away from the parent
+	absencePatternMinOverrides = 3
+)
+
+// OverriddenNight is a night set by hand to another caregiver than the parent it was assigned to
+type OverriddenNight struct {
+	Date   time.Time
+	Parent string // Parent replaced by the override
+}
+
+// OverriddenNightsSource reads the overridden nights, implemented by Tracker
+type OverriddenNightsSource interface {
+	GetOverriddenNights(ctx context.Context, start, end time.Time) ([]OverriddenNight, error)
+}
+
+// AbsenceConfigSource reads the parents and their unavailability, implemented by config.ConfigStoreInterface
+type AbsenceConfigSource interface {
+	config.RosterSource
+	GetAvailability(parent string) ([]string, error)
+	GetUnavailabilityRules(parent string) ([]config.UnavailabilityRule, error)
+}
+
+// AbsencePattern is a weekday a parent was repeatedly overridden on, suggesting that the parent is
+// unavailable that day
+type AbsencePattern struct {
+	Parent     string
+	ParentKey  string // Key of the availability settings of the parent, see config.ParentKey
+	Weekday    time.Weekday
+	Overridden int // Occurrences of the weekday the parent was overridden on
+	Weeks      int // Occurrences of the weekday looked at
+}
+
+// GetOverriddenNights returns the nights from start (inclusive) to end (exclusive) a parent was
+// overridden on, oldest first, read from the change journal. Only the overrides still in place count:
+// undone ones, and nights handed back to the scheduler or set back to the replaced parent, are left out.
+func (t *Tracker) GetOverriddenNights(ctx context.Context, start, end time.Time) ([]OverriddenNight, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	rows, err := t.db.Conn().QueryContext(ctx, `
+	SELECT DISTINCT a.assignment_date, c.parent_name
+	FROM assignment_changes c
+	JOIN assignment_change_batches b ON b.id = c.batch_id
+	JOIN assignments a ON a.id = c.assignment_id
+	WHERE b.kind = ? AND b.undone_at IS NULL
+		AND c.caregiver_type = ?
+		AND a.override = 1
+		AND NOT (a.caregiver_type = ? AND a.parent_name = c.parent_name)
+		AND a.child_id = ? AND a.assignment_date >= ? AND a.assignment_date < ?
+	ORDER BY a.assignment_date, c.parent_name`,
+		ChangeKindOverride.String(), CaregiverTypeParent.String(), CaregiverTypeParent.String(),
+		t.childID, start.Format(dateFormat), end.Format(dateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overridden nights: %w", err)
+	}
+	defer rows.Close()
+
+	var nights []OverriddenNight
+	for rows.Next() {
+		var night OverriddenNight
+		var date string
+		if err := rows.Scan(&date, &night.Parent); err != nil {
+			return nil, fmt.Errorf("failed to scan overridden night: %w", err)
+		}
+		if night.Date, err = time.Parse(dateFormat, date); err != nil {
+			return nil, fmt.Errorf("failed to parse overridden night date: %w", err)
+		}
+		nights = append(nights, night)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list overridden nights: %w", err)
+	}
+	return nights, nil
+}
+
+// DetectAbsencePatterns returns the weekdays a parent was overridden on at least 3 of the last 4
+// times, the day of now included, in roster order and from Sunday. The weekdays the parent is already
+// unavailable on, as an unavailable day or an every-week rule, are left out.
+func DetectAbsencePatterns(ctx context.Context, nights OverriddenNightsSource, cfg AbsenceConfigSource, now time.Time) ([]AbsencePattern, error) {
+	roster, err := config.Roster(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parents: %w", err)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	overridden, err := nights.GetOverriddenNights(ctx, today.AddDate(0, 0, 1-7*absencePatternWeeks), today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]*[7]int, len(roster))
+	for _, night := range overridden {
+		if counts[night.Parent] == nil {
+			counts[night.Parent] = new([7]int)
+		}
+		counts[night.Parent][night.Date.Weekday()]++
+	}
+
+	var patterns []AbsencePattern
+	for i, name := range roster {
+		weekdays := counts[name]
+		if weekdays == nil {
+			continue
+		}
+		key := config.ParentKey(i)
+		for weekday, count := range weekdays {
+			if count < absencePatternMinOverrides {
+				continue
+			}
+			unavailable, err := unavailableEvery(cfg, key, time.Weekday(weekday))
+			if err != nil {
+				return nil, err
+			}
+			if unavailable {
+				continue
+			}
+			patterns = append(patterns, AbsencePattern{
+				Parent:     name,
+				ParentKey:  key,
+				Weekday:    time.Weekday(weekday),
+				Overridden: count,
+				Weeks:      absencePatternWeeks,
+			})
+		}
+	}
+	return patterns, nil
+}
+
+// unavailableEvery reports whether the parent of key is already unavailable on every weekday
+func unavailableEvery(cfg AbsenceConfigSource, key string, weekday time.Weekday) (bool, error) {
+	days, err := cfg.GetAvailability(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s availability: %w", key, err)
+	}
+	for _, day := range days {
+		if day == weekday.String() {
+			return true, nil
+		}
+	}
+	rules, err := cfg.GetUnavailabilityRules(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s unavailability rules: %w", key, err)
+	}
+	for _, rule := range rules {
+		if rule.Frequency == config.RuleFrequencyWeekly && rule.Interval <= 1 && rule.Weekday == weekday {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,166 @@
+package fairness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOverriddenNights(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	overridden, err := tracker.RecordAssignment(t.Context(), "Bob", day1, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	toBabysitter, err := tracker.RecordAssignment(t.Context(), "Bob", day1.AddDate(0, 0, 1), false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	undone, err := tracker.RecordAssignment(t.Context(), "Bob", day1.AddDate(0, 0, 2), false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	unlocked, err := tracker.RecordAssignment(t.Context(), "Alice", day1.AddDate(0, 0, 3), false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	regenerated, err := tracker.RecordAssignment(t.Context(), "Alice", day1.AddDate(0, 0, 4), false, DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), undone.ID, "Alice", true, undone.Version))
+	_, err = tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+
+	// A regeneration joining the batch of an override is not an override itself
+	batchCtx := tracker.BeginBatch(t.Context(), ChangeKindOverride)
+	require.NoError(t, tracker.UpdateAssignmentParent(batchCtx, overridden.ID, "Alice", true, overridden.Version))
+	_, err = tracker.RecordAssignment(tracker.BeginBatch(batchCtx, ChangeKindRegeneration), "Bob", regenerated.Date, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentToBabysitter(t.Context(), toBabysitter.ID, "Grandma", true, toBabysitter.Version))
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), unlocked.ID, "Bob", true, unlocked.Version))
+	require.NoError(t, tracker.UnlockAssignment(t.Context(), unlocked.ID))
+
+	nights, err := tracker.GetOverriddenNights(t.Context(), day1, day1.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	assert.Equal(t, []OverriddenNight{
+		{Date: day1, Parent: "Bob"},
+		{Date: day1.AddDate(0, 0, 1), Parent: "Bob"},
+	}, nights)
+
+	nights, err = tracker.GetOverriddenNights(t.Context(), day1.AddDate(0, 0, 1), day1.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	assert.Len(t, nights, 1, "the start is inclusive")
+}
+
+type fakeOverriddenNights []OverriddenNight
+
+func (f fakeOverriddenNights) GetOverriddenNights(_ context.Context, start, end time.Time) ([]OverriddenNight, error) {
+	var nights []OverriddenNight
+	for _, night := range f {
+		if !night.Date.Before(start) && night.Date.Before(end) {
+			nights = append(nights, night)
+		}
+	}
+	return nights, nil
+}
+
+type fakeAbsenceConfig struct {
+	availability map[string][]string
+	rules        map[string][]config.UnavailabilityRule
+	extra        []config.ExtraParent
+}
+
+func (f fakeAbsenceConfig) GetParents() (string, string, error) { return "Alice", "Bob", nil }
+
+func (f fakeAbsenceConfig) GetExtraParents() ([]config.ExtraParent, error) { return f.extra, nil }
+
+func (f fakeAbsenceConfig) GetAvailability(parent string) ([]string, error) {
+	return f.availability[parent], nil
+}
+
+func (f fakeAbsenceConfig) GetUnavailabilityRules(parent string) ([]config.UnavailabilityRule, error) {
+	return f.rules[parent], nil
+}
+
+func TestDetectAbsencePatterns(t *testing.T) {
+	// Friday 16 October 2026; the last four Wednesdays are the 23 and 30 September, 7 and 14 October
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	wednesdays := []time.Time{
+		time.Date(2026, 9, 23, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 7, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+	}
+	nights := fakeOverriddenNights{
+		{Date: wednesdays[1], Parent: "Bob"},
+		{Date: wednesdays[2], Parent: "Bob"},
+		{Date: wednesdays[3], Parent: "Bob"},
+		// Two Fridays only, tonight included
+		{Date: time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC), Parent: "Alice"},
+		{Date: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), Parent: "Alice"},
+		// Older than the last four weeks
+		{Date: time.Date(2026, 9, 16, 0, 0, 0, 0, time.UTC), Parent: "Alice"},
+	}
+
+	tests := []struct {
+		name   string
+		nights fakeOverriddenNights
+		config fakeAbsenceConfig
+		want   []AbsencePattern
+	}{
+		{
+			name:   "Three of the last four Wednesdays",
+			nights: nights,
+			want:   []AbsencePattern{{Parent: "Bob", ParentKey: "parent_b", Weekday: time.Wednesday, Overridden: 3, Weeks: 4}},
+		},
+		{
+			name:   "Friday overridden three times, tonight included",
+			nights: append(fakeOverriddenNights{{Date: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Parent: "Alice"}}, nights...),
+			want: []AbsencePattern{
+				{Parent: "Alice", ParentKey: "parent_a", Weekday: time.Friday, Overridden: 3, Weeks: 4},
+				{Parent: "Bob", ParentKey: "parent_b", Weekday: time.Wednesday, Overridden: 3, Weeks: 4},
+			},
+		},
+		{
+			name:   "Already an unavailable day",
+			nights: nights,
+			config: fakeAbsenceConfig{availability: map[string][]string{"parent_b": {"Wednesday"}}},
+		},
+		{
+			name:   "Already an every-week rule",
+			nights: nights,
+			config: fakeAbsenceConfig{rules: map[string][]config.UnavailabilityRule{"parent_b": {{Frequency: config.RuleFrequencyWeekly, Interval: 1, Weekday: time.Wednesday}}}},
+		},
+		{
+			name:   "An every-other-week rule does not cover the weekday",
+			nights: nights,
+			config: fakeAbsenceConfig{rules: map[string][]config.UnavailabilityRule{"parent_b": {{Frequency: config.RuleFrequencyWeekly, Interval: 2, Weekday: time.Wednesday, Start: wednesdays[1]}}}},
+			want:   []AbsencePattern{{Parent: "Bob", ParentKey: "parent_b", Weekday: time.Wednesday, Overridden: 3, Weeks: 4}},
+		},
+		{
+			name: "An extra parent",
+			nights: append(fakeOverriddenNights{
+				{Date: wednesdays[0], Parent: "Grandma"},
+				{Date: wednesdays[2], Parent: "Grandma"},
+				{Date: wednesdays[3], Parent: "Grandma"},
+			}, nights...),
+			config: fakeAbsenceConfig{extra: []config.ExtraParent{{Name: "Grandma"}}},
+			want: []AbsencePattern{
+				{Parent: "Bob", ParentKey: "parent_b", Weekday: time.Wednesday, Overridden: 3, Weeks: 4},
+				{Parent: "Grandma", ParentKey: "parent_c", Weekday: time.Wednesday, Overridden: 3, Weeks: 4},
+			},
+		},
+		{
+			name:   "No override",
+			nights: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns, err := DetectAbsencePatterns(t.Context(), tt.nights, tt.config, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, patterns)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package fairness
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventVersion is a version of a Google Calendar event, from its etag and its updated time
+type EventVersion struct {
+	ETag    string
+	Updated time.Time
+}
+
+// Covers reports whether the change of version was already applied when v was: both are the same
+// version, or version was not updated after v
+func (v EventVersion) Covers(version EventVersion) bool {
+	if v.ETag != "" && v.ETag == version.ETag {
+		return true
+	}
+	return !v.Updated.IsZero() && !version.Updated.IsZero() && !version.Updated.After(v.Updated)
+}
+
+// GetAppliedEventVersion returns the version of the Google Calendar event whose change was last
+// applied to an assignment by RecordAppliedEventVersion, nil when none was
+func (t *Tracker) GetAppliedEventVersion(ctx context.Context, eventID string) (*EventVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	var version EventVersion
+	var updatedStr string
+	err := t.db.Conn().QueryRowContext(ctx, `
+	SELECT etag, event_updated FROM webhook_applied_events WHERE google_calendar_event_id = ?`, eventID).Scan(&version.ETag, &updatedStr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied version of event %s: %w", eventID, err)
+	}
+	if version.Updated, err = time.Parse(time.RFC3339Nano, updatedStr); err != nil {
+		return nil, fmt.Errorf("failed to parse applied version of event %s: %w", eventID, err)
+	}
+	return &version, nil
+}
+
+// RecordAppliedEventVersion records that the change of version of the Google Calendar event was
+// applied to the assignment, replacing the version recorded before
+func (t *Tracker) RecordAppliedEventVersion(ctx context.Context, eventID string, assignmentID int64, version EventVersion) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	_, err := t.db.ExecContext(ctx, `
+	INSERT INTO webhook_applied_events (google_calendar_event_id, assignment_id, etag, event_updated, applied_at)
+	VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	ON CONFLICT(google_calendar_event_id) DO UPDATE SET
+		assignment_id = excluded.assignment_id,
+		etag = excluded.etag,
+		event_updated = excluded.event_updated,
+		applied_at = CURRENT_TIMESTAMP`,
+		eventID, assignmentID, version.ETag, version.Updated.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		t.logger.Error().Err(err).Str("event_id", eventID).Int64("assignment_id", assignmentID).Msg("Failed to record applied event version")
+		return fmt.Errorf("failed to record applied version of event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// DeleteOrphanedAppliedEvents deletes the applied versions recorded for assignments that no longer
+// exist and returns how many
+func (t *Tracker) DeleteOrphanedAppliedEvents(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	result, err := t.db.ExecContext(ctx, `
+	DELETE FROM webhook_applied_events WHERE assignment_id NOT IN (SELECT id FROM assignments)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete orphaned applied event versions: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,75 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventVersionCovers(t *testing.T) {
+	updated := time.Date(2026, time.October, 16, 20, 0, 0, 0, time.UTC)
+	applied := EventVersion{ETag: `"3"`, Updated: updated}
+
+	assert.True(t, applied.Covers(EventVersion{ETag: `"3"`, Updated: updated}), "same version")
+	assert.True(t, applied.Covers(EventVersion{ETag: `"3"`}), "same etag without updated time")
+	assert.True(t, applied.Covers(EventVersion{ETag: `"2"`, Updated: updated.Add(-time.Minute)}), "older version")
+	assert.False(t, applied.Covers(EventVersion{ETag: `"4"`, Updated: updated.Add(time.Second)}), "newer version")
+	assert.False(t, applied.Covers(EventVersion{ETag: `"4"`}), "other etag without updated time")
+	assert.False(t, EventVersion{}.Covers(EventVersion{}), "nothing to compare")
+}
+
+func TestAppliedEventVersion(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	version, err := tracker.GetAppliedEventVersion(t.Context(), "event-1")
+	require.NoError(t, err)
+	assert.Nil(t, version, "nothing applied yet")
+
+	first := EventVersion{ETag: `"1"`, Updated: time.Date(2026, time.October, 16, 20, 0, 0, 123000000, time.UTC)}
+	require.NoError(t, tracker.RecordAppliedEventVersion(t.Context(), "event-1", 7, first))
+	version, err = tracker.GetAppliedEventVersion(t.Context(), "event-1")
+	require.NoError(t, err)
+	require.NotNil(t, version)
+	assert.Equal(t, first, *version)
+
+	second := EventVersion{ETag: `"2"`, Updated: first.Updated.Add(time.Minute)}
+	require.NoError(t, tracker.RecordAppliedEventVersion(t.Context(), "event-1", 7, second))
+	version, err = tracker.GetAppliedEventVersion(t.Context(), "event-1")
+	require.NoError(t, err)
+	assert.Equal(t, second, *version, "the last applied version replaces the previous one")
+
+	version, err = tracker.GetAppliedEventVersion(t.Context(), "event-2")
+	require.NoError(t, err)
+	assert.Nil(t, version)
+}
+
+func TestDeleteOrphanedAppliedEvents(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC), false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	version := EventVersion{ETag: `"1"`, Updated: time.Date(2026, time.October, 16, 20, 0, 0, 0, time.UTC)}
+	require.NoError(t, tracker.RecordAppliedEventVersion(t.Context(), "event-1", assignment.ID, version))
+	require.NoError(t, tracker.RecordAppliedEventVersion(t.Context(), "event-2", assignment.ID+1, version))
+
+	deleted, err := tracker.DeleteOrphanedAppliedEvents(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	applied, err := tracker.GetAppliedEventVersion(t.Context(), "event-1")
+	require.NoError(t, err)
+	assert.NotNil(t, applied, "the version of an existing assignment is kept")
+	applied, err = tracker.GetAppliedEventVersion(t.Context(), "event-2")
+	require.NoError(t, err)
+	assert.Nil(t, applied)
+}
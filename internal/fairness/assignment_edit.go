@@ -0,0 +1,114 @@
+package fairness
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/signals"
+)
+
+// ErrAssignmentNotScheduled is returned when editing a night without assignment
+var ErrAssignmentNotScheduled = errors.New("no assignment on this date")
+
+// AssignmentEdit is a change made by hand to the night of Date, applied with others by EditAssignments
+type AssignmentEdit struct {
+	Date    time.Time
+	Parent  string // Parent taking the night as an override; empty unlocks the night
+	Version int64  // Version of the assignment the edit is based on; the current one when 0
+}
+
+// EditedAssignment is the assignment of an edit once applied
+type EditedAssignment struct {
+	Assignment *Assignment
+	Changed    bool // False when the night already was as asked
+}
+
+// targetState returns the caregiver of a once the edit is applied: parent as an override, or the
+// same caregiver handed back to the scheduler when unlocking
+func (edit AssignmentEdit) targetState(a *Assignment) caregiverState {
+	if edit.Parent == "" {
+		return caregiverState{parent: a.Parent, caregiverType: CaregiverTypeParent}
+	}
+	return caregiverState{parent: edit.Parent, caregiverType: CaregiverTypeParent, override: true, decisionReason: DecisionReasonOverride}
+}
+
+// EditAssignments applies edits in a single transaction: either all of them or none. Returns
+// ErrAssignmentNotScheduled when a night has no assignment and ErrAssignmentConflict when one is no
+// longer at the version of its edit. A night already as asked is left untouched; an unlocked night
+// loses its tag. The changes are journaled as one batch of ChangeKindEdit.
+func (t *Tracker) EditAssignments(ctx context.Context, edits []AssignmentEdit) ([]EditedAssignment, error) {
+	editLogger := t.logger.With().Int("edit_count", len(edits)).Logger()
+	editLogger.Debug().Msg("Editing assignments")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	var previous, changed []*Assignment
+	var edited []EditedAssignment
+	err := t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		previous, changed, edited = nil, nil, make([]EditedAssignment, 0, len(edits))
+		for _, edit := range edits {
+			date := edit.Date.Format(dateFormat)
+			a, err := t.scanAssignment(tx.QueryRowContext(ctx, selectAssignmentByDateSQL, t.childID, date))
+			if err != nil {
+				return fmt.Errorf("failed to get assignment of %s: %w", date, err)
+			}
+			if a == nil {
+				return fmt.Errorf("%s: %w", date, ErrAssignmentNotScheduled)
+			}
+			if edit.Version != 0 && a.Version != edit.Version {
+				return fmt.Errorf("assignment %d of %s is no longer at version %d: %w", a.ID, date, edit.Version, ErrAssignmentConflict)
+			}
+			target := edit.targetState(a)
+			if stateOf(a) == target {
+				edited = append(edited, EditedAssignment{Assignment: a})
+				continue
+			}
+
+			var decisionReason sql.NullString
+			if target.decisionReason != "" {
+				decisionReason = sql.NullString{String: target.decisionReason.String(), Valid: true}
+			}
+			query := `UPDATE assignments SET parent_name = ?, caregiver_type = ?, override = ?, decision_reason = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP`
+			if !target.override {
+				query += ", tag = NULL"
+			}
+			if _, err := tx.ExecContext(ctx, query+" WHERE id = ?",
+				target.parent, target.caregiverType.String(), target.override, decisionReason, a.ID); err != nil {
+				return fmt.Errorf("failed to update assignment of %s: %w", date, err)
+			}
+			updated, err := t.scanAssignment(tx.QueryRowContext(ctx, selectAssignmentByDateSQL, t.childID, date))
+			if err != nil {
+				return fmt.Errorf("failed to read back assignment of %s: %w", date, err)
+			}
+			previous, changed = append(previous, a), append(changed, updated)
+			edited = append(edited, EditedAssignment{Assignment: updated, Changed: true})
+		}
+		return nil
+	})
+	if err != nil {
+		editLogger.Warn().Err(err).Msg("Failed to edit assignments, none was changed")
+		return nil, fmt.Errorf("failed to edit assignments: %w", err)
+	}
+	if len(previous) == 0 {
+		editLogger.Debug().Msg("Every night already was as asked")
+		return edited, nil
+	}
+
+	t.changed(time.Time{})
+	// The edits are undone together, with the regeneration the caller may follow them with
+	ctx = t.BeginBatch(ctx, ChangeKindEdit)
+	for i, after := range changed {
+		t.journalChange(ctx, ChangeKindEdit, previous[i], stateOf(after))
+		if after.Override {
+			signals.EmitAssignmentOverridden(context.Background(), after.ID, after.Parent, after.CaregiverType.String())
+		} else {
+			signals.EmitAssignmentUnlocked(context.Background(), after.ID)
+		}
+	}
+	editLogger.Info().Int("changed_count", len(previous)).Msg("Assignments edited")
+	return edited, nil
+}
@@ -0,0 +1,98 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditAssignments(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day1.AddDate(0, 0, 2)
+	first, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	second, err := tracker.RecordAssignment(t.Context(), "Bob", day2, true, DecisionReasonOverride)
+	require.NoError(t, err)
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), second.ID, AssignmentTagSickKid))
+	third, err := tracker.RecordAssignment(t.Context(), "Alice", day3, true, DecisionReasonOverride)
+	require.NoError(t, err)
+
+	edited, err := tracker.EditAssignments(t.Context(), []AssignmentEdit{
+		{Date: day1, Parent: "Bob", Version: first.Version},
+		{Date: day2},
+		{Date: day3, Parent: "Alice"},
+	})
+	require.NoError(t, err)
+	require.Len(t, edited, 3)
+
+	assert.True(t, edited[0].Changed)
+	assert.Equal(t, "Bob", edited[0].Assignment.Parent)
+	assert.True(t, edited[0].Assignment.Override)
+	assert.Equal(t, DecisionReasonOverride, edited[0].Assignment.DecisionReason)
+	assert.Greater(t, edited[0].Assignment.Version, first.Version)
+
+	assert.True(t, edited[1].Changed)
+	assert.False(t, edited[1].Assignment.Override, "the night is unlocked")
+	assert.Equal(t, AssignmentTagNone, edited[1].Assignment.Tag, "an unlocked night loses its tag")
+
+	assert.False(t, edited[2].Changed, "the night already was an override of Alice")
+	assert.Equal(t, third.Version, edited[2].Assignment.Version)
+
+	// Both changes are undone together
+	batch, err := tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, ChangeKindEdit, batch.Kind)
+	require.Len(t, batch.Assignments, 2)
+	restored, err := tracker.GetAssignmentByID(t.Context(), first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", restored.Parent)
+	assert.False(t, restored.Override)
+	restored, err = tracker.GetAssignmentByID(t.Context(), second.ID)
+	require.NoError(t, err)
+	assert.True(t, restored.Override)
+}
+
+func TestEditAssignments_AllOrNone(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	first, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	second, err := tracker.RecordAssignment(t.Context(), "Bob", day2, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		edits []AssignmentEdit
+		err   error
+	}{
+		{"Night not scheduled", []AssignmentEdit{{Date: day1, Parent: "Bob"}, {Date: day2.AddDate(0, 0, 1), Parent: "Alice"}}, ErrAssignmentNotScheduled},
+		{"Stale version", []AssignmentEdit{{Date: day1, Parent: "Bob"}, {Date: day2, Parent: "Alice", Version: second.Version + 1}}, ErrAssignmentConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tracker.EditAssignments(t.Context(), tt.edits)
+			require.ErrorIs(t, err, tt.err)
+
+			unchanged, err := tracker.GetAssignmentByID(t.Context(), first.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Alice", unchanged.Parent, "none of the edits is applied")
+			assert.Equal(t, first.Version, unchanged.Version)
+		})
+	}
+
+	_, err = tracker.UndoLastBatch(t.Context())
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
@@ -0,0 +1,113 @@
+package fairness
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AssignmentSearch filters the assignments returned by SearchAssignments; the zero value of a
+// field does not filter
+type AssignmentSearch struct {
+	Caregiver      string         // Name of the parent or babysitter who had the night
+	Override       *bool          // Whether the night was set by hand
+	DecisionReason DecisionReason // Why the caregiver was chosen
+	Tag            AssignmentTag  // Why an overridden night was taken
+	Notes          string         // Words the comments of the night contain, the last one possibly partial
+	From           time.Time      // First day searched
+	To             time.Time      // Last day searched
+	Limit          int            // Most assignments returned, all when 0
+}
+
+// notesMatchQuery turns free text into an FTS5 query matching the comments containing every word,
+// each word quoted so that the text cannot use the FTS5 syntax. Empty when the text has no word.
+func notesMatchQuery(notes string) string {
+	words := strings.Fields(notes)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	if len(words) > 0 {
+		words[len(words)-1] += "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// SearchAssignments returns the assignments matching every filter of search, newest first. The
+// filters use the indexes of the assignments and the full-text index of the comments.
+func (t *Tracker) SearchAssignments(ctx context.Context, search AssignmentSearch) ([]*Assignment, error) {
+	// The assignments of the other rotations are never matched
+	conditions := []string{"child_id = ?"}
+	args := []any{t.childID}
+	if search.Caregiver != "" {
+		conditions = append(conditions, "parent_name = ?")
+		args = append(args, search.Caregiver)
+	}
+	if search.Override != nil {
+		conditions = append(conditions, "override = ?")
+		args = append(args, *search.Override)
+	}
+	if search.DecisionReason != "" {
+		conditions = append(conditions, "decision_reason = ?")
+		args = append(args, search.DecisionReason.String())
+	}
+	if search.Tag != AssignmentTagNone {
+		conditions = append(conditions, "tag = ?")
+		args = append(args, search.Tag.String())
+	}
+	if match := notesMatchQuery(search.Notes); match != "" {
+		conditions = append(conditions, `id IN (
+		SELECT c.assignment_id FROM assignment_comments_fts f
+		JOIN assignment_comments c ON c.id = f.rowid
+		WHERE assignment_comments_fts MATCH ?)`)
+		args = append(args, match)
+	}
+	if !search.From.IsZero() {
+		conditions = append(conditions, "assignment_date >= ?")
+		args = append(args, search.From.Format(dateFormat))
+	}
+	if !search.To.IsZero() {
+		conditions = append(conditions, "assignment_date <= ?")
+		args = append(args, search.To.Format(dateFormat))
+	}
+
+	query := `
+	SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, version, tag, created_at, updated_at
+	FROM assignments`
+	if len(conditions) > 0 {
+		query += "\n\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	query += "\n\tORDER BY assignment_date DESC"
+	if search.Limit > 0 {
+		query += "\n\tLIMIT ?"
+		args = append(args, search.Limit)
+	}
+
+	searchLogger := t.logger.With().Int("filters", len(conditions)).Int("limit", search.Limit).Logger()
+	searchLogger.Debug().Msg("Searching assignments")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	rows, err := t.db.Conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		searchLogger.Error().Err(err).Msg("Failed to search assignments")
+		return nil, fmt.Errorf("failed to search assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*Assignment
+	for rows.Next() {
+		a, err := t.scanAssignment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed during row iteration: %w", err)
+	}
+
+	searchLogger.Debug().Int("count", len(assignments)).Msg("Assignments searched")
+	return assignments, nil
+}
@@ -0,0 +1,71 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSearchAssignments verifies each filter of the assignment search and their combination
+func TestSearchAssignments(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+	comments, err := database.NewCommentStore(db)
+	require.NoError(t, err)
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	first, err := tracker.RecordAssignment(t.Context(), "Alice", day(1), false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	second, err := tracker.RecordAssignment(t.Context(), "Bob", day(2), true, DecisionReasonOverride)
+	require.NoError(t, err)
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), second.ID, AssignmentTagSickKid))
+	third, err := tracker.RecordAssignment(t.Context(), "Alice", day(3), true, DecisionReasonOverride)
+	require.NoError(t, err)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day(4), true)
+	require.NoError(t, err)
+
+	_, err = comments.AddComment(second.ID, "Bob", "Fever all night, called the doctor")
+	require.NoError(t, err)
+	_, err = comments.AddComment(first.ID, "Alice", "Quiet night")
+	require.NoError(t, err)
+	removed, err := comments.AddComment(third.ID, "Alice", "Doctor visit tomorrow")
+	require.NoError(t, err)
+	require.NoError(t, comments.DeleteComment(third.ID, removed.ID))
+
+	overridden := true
+	tests := []struct {
+		name   string
+		search AssignmentSearch
+		dates  []int
+	}{
+		{"no filter", AssignmentSearch{}, []int{4, 3, 2, 1}},
+		{"limit", AssignmentSearch{Limit: 2}, []int{4, 3}},
+		{"caregiver", AssignmentSearch{Caregiver: "Alice"}, []int{3, 1}},
+		{"override", AssignmentSearch{Override: &overridden}, []int{4, 3, 2}},
+		{"decision reason", AssignmentSearch{DecisionReason: DecisionReasonTotalCount}, []int{1}},
+		{"tag", AssignmentSearch{Tag: AssignmentTagSickKid}, []int{2}},
+		{"notes", AssignmentSearch{Notes: "doctor"}, []int{2}},
+		{"notes with every word", AssignmentSearch{Notes: "fever quiet"}, nil},
+		{"notes partial last word", AssignmentSearch{Notes: "qui"}, []int{1}},
+		{"notes quoted", AssignmentSearch{Notes: `"night" OR`}, nil},
+		{"range", AssignmentSearch{From: day(2), To: day(3)}, []int{3, 2}},
+		{"combined", AssignmentSearch{Caregiver: "Alice", Override: &overridden}, []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignments, err := tracker.SearchAssignments(t.Context(), tt.search)
+			require.NoError(t, err)
+			var dates []int
+			for _, a := range assignments {
+				dates = append(dates, a.Date.Day())
+			}
+			assert.Equal(t, tt.dates, dates)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package fairness
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AssignmentTag tells why an overridden night was taken by hand, to show the extra load in the statistics
+type AssignmentTag string
+
+const (
+	// AssignmentTagNone marks a night without tag
+	AssignmentTagNone AssignmentTag = ""
+	// AssignmentTagSickKid marks a night taken over because a kid was sick
+	AssignmentTagSickKid AssignmentTag = "sick_kid"
+	// AssignmentTagParentAway marks a night taken over because the other parent was away
+	AssignmentTagParentAway AssignmentTag = "parent_away"
+)
+
+// AssignmentTags lists the tags a night can have, in display order
+var AssignmentTags = []AssignmentTag{AssignmentTagSickKid, AssignmentTagParentAway}
+
+// ErrInvalidAssignmentTag is returned for a tag which is not one of AssignmentTags
+var ErrInvalidAssignmentTag = errors.New("invalid assignment tag")
+
+// ErrAssignmentNotOverridden is returned when tagging a night that was not overridden
+var ErrAssignmentNotOverridden = errors.New("assignment is not overridden")
+
+// String returns the string representation of the tag
+func (t AssignmentTag) String() string {
+	return string(t)
+}
+
+// Label returns the tag as shown to the parents, empty without tag
+func (t AssignmentTag) Label() string {
+	switch t {
+	case AssignmentTagSickKid:
+		return "Sick kid"
+	case AssignmentTagParentAway:
+		return "Parent away"
+	default:
+		return ""
+	}
+}
+
+// ParseAssignmentTag parses a tag, the empty string being AssignmentTagNone
+func ParseAssignmentTag(value string) (AssignmentTag, error) {
+	switch tag := AssignmentTag(value); tag {
+	case AssignmentTagNone, AssignmentTagSickKid, AssignmentTagParentAway:
+		return tag, nil
+	default:
+		return AssignmentTagNone, fmt.Errorf("%w: %q", ErrInvalidAssignmentTag, value)
+	}
+}
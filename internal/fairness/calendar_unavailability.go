@@ -0,0 +1,83 @@
+package fairness
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ReplaceCalendarUnavailability replaces the days from start to end, both included, on which parent
+// ("parent_a" or "parent_b") is unavailable from its personal calendar. Dates outside the range are
+// ignored, the days before and after the range are kept.
+func (t *Tracker) ReplaceCalendarUnavailability(ctx context.Context, parent string, start, end time.Time, dates []time.Time) error {
+	startStr, endStr := start.Format(dateFormat), end.Format(dateFormat)
+	replaceLogger := t.logger.With().
+		Str("parent", parent).
+		Str("start_date", startStr).
+		Str("end_date", endStr).
+		Int("date_count", len(dates)).
+		Logger()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	err := t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM calendar_unavailability WHERE parent = ? AND date BETWEEN ? AND ?`,
+			parent, startStr, endStr); err != nil {
+			return fmt.Errorf("failed to clear calendar unavailability: %w", err)
+		}
+		for _, date := range dates {
+			dateStr := date.Format(dateFormat)
+			if dateStr < startStr || dateStr > endStr {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO calendar_unavailability (parent, date) VALUES (?, ?) ON CONFLICT(parent, date) DO NOTHING`,
+				parent, dateStr); err != nil {
+				return fmt.Errorf("failed to record calendar unavailability on %s: %w", dateStr, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		replaceLogger.Error().Err(err).Msg("Failed to replace calendar unavailability")
+		return err
+	}
+
+	replaceLogger.Debug().Msg("Calendar unavailability replaced")
+	return nil
+}
+
+// GetCalendarUnavailability returns the days from start to end, both included, on which parent
+// ("parent_a" or "parent_b") is unavailable from its personal calendar, in order
+func (t *Tracker) GetCalendarUnavailability(ctx context.Context, parent string, start, end time.Time) ([]time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	rows, err := t.db.Conn().QueryContext(ctx, `
+		SELECT date FROM calendar_unavailability
+		WHERE parent = ? AND date BETWEEN ? AND ?
+		ORDER BY date`,
+		parent, start.Format(dateFormat), end.Format(dateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar unavailability: %w", err)
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	for rows.Next() {
+		var dateStr string
+		if err := rows.Scan(&dateStr); err != nil {
+			return nil, fmt.Errorf("failed to scan calendar unavailability: %w", err)
+		}
+		date, err := time.Parse(dateFormat, dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse calendar unavailability %q: %w", dateStr, err)
+		}
+		dates = append(dates, date)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate calendar unavailability: %w", err)
+	}
+	return dates, nil
+}
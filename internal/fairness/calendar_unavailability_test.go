@@ -0,0 +1,38 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarUnavailability(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, tracker.ReplaceCalendarUnavailability(t.Context(), "parent_a", day(1), day(10), []time.Time{day(3), day(5), day(5), day(12)}))
+	require.NoError(t, tracker.ReplaceCalendarUnavailability(t.Context(), "parent_b", day(1), day(10), []time.Time{day(4)}))
+
+	dates, err := tracker.GetCalendarUnavailability(t.Context(), "parent_a", day(1), day(31))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{day(3), day(5)}, dates, "days outside the replaced range ignored")
+
+	// A later sync over part of the range replaces only that part
+	require.NoError(t, tracker.ReplaceCalendarUnavailability(t.Context(), "parent_a", day(4), day(10), []time.Time{day(8)}))
+	dates, err = tracker.GetCalendarUnavailability(t.Context(), "parent_a", day(1), day(31))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{day(3), day(8)}, dates)
+
+	dates, err = tracker.GetCalendarUnavailability(t.Context(), "parent_b", day(5), day(31))
+	require.NoError(t, err)
+	assert.Empty(t, dates)
+
+	assert.Error(t, tracker.ReplaceCalendarUnavailability(t.Context(), "parent_g", day(1), day(10), []time.Time{day(2)}), "unknown parent")
+}
@@ -0,0 +1,318 @@
+package fairness
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChangeKind names the mutation a batch of assignment changes comes from
+type ChangeKind string
+
+const (
+	// ChangeKindOverride is an assignment set by hand, with the regeneration it triggers
+	ChangeKindOverride ChangeKind = "override"
+	// ChangeKindSwap is a swap of two assignments made outside a regeneration
+	ChangeKindSwap ChangeKind = "swap"
+	// ChangeKindRegeneration is a regeneration of the schedule
+	ChangeKindRegeneration ChangeKind = "regeneration"
+	// ChangeKindUnlock is an override handed back to the scheduler, with the regeneration it triggers
+	ChangeKindUnlock ChangeKind = "unlock"
+	// ChangeKindEdit is several nights set or unlocked by hand together, with the regeneration they trigger
+	ChangeKindEdit ChangeKind = "edit"
+)
+
+// String returns the string representation of the change kind
+func (k ChangeKind) String() string {
+	return string(k)
+}
+
+// ErrNothingToUndo is returned by UndoLastBatch when no batch of changes is left to undo
+var ErrNothingToUndo = errors.New("no assignment change to undo")
+
+// changeRetention is how long the batches of changes can be undone before being purged
+const changeRetention = 90 * 24 * time.Hour
+
+// ChangeBatch is a group of assignment changes undone together
+type ChangeBatch struct {
+	ID        int64
+	Kind      ChangeKind
+	CreatedAt time.Time
+	// Assignments are the assignments restored by UndoLastBatch, by date
+	Assignments []*Assignment
+}
+
+// AssignmentChange is a journaled change of an assignment, with the caregiver it had before
+type AssignmentChange struct {
+	Kind           ChangeKind
+	ChangedAt      time.Time
+	Undone         bool // The batch of the change was undone
+	Parent         string
+	CaregiverType  CaregiverType
+	Override       bool
+	DecisionReason DecisionReason
+}
+
+// batchKey is the context key of the batch of changes begun by BeginBatch
+type batchKey struct{}
+
+// openBatch is the batch the changes made with the context of BeginBatch are journaled in
+type openBatch struct {
+	kind ChangeKind
+	mu   sync.Mutex // Guards id
+	id   int64      // Zero until the first journaled change
+}
+
+// caregiverState is the part of an assignment restored by an undo
+type caregiverState struct {
+	parent         string
+	caregiverType  CaregiverType
+	override       bool
+	decisionReason DecisionReason
+}
+
+// stateOf returns the caregiver state of a
+func stateOf(a *Assignment) caregiverState {
+	return caregiverState{
+		parent:         a.Parent,
+		caregiverType:  a.CaregiverType,
+		override:       a.Override,
+		decisionReason: a.DecisionReason,
+	}
+}
+
+// BeginBatch returns a context grouping the assignment changes made with it into one batch of kind,
+// undone together by UndoLastBatch. A batch begun with a context already carrying one joins it, so
+// that an override and the regeneration it triggers are undone together. Changes made with a context
+// carrying no batch are a batch each; concurrent requests never share a batch.
+func (t *Tracker) BeginBatch(ctx context.Context, kind ChangeKind) context.Context {
+	if _, ok := ctx.Value(batchKey{}).(*openBatch); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, batchKey{}, &openBatch{kind: kind})
+}
+
+// journalChange records before, the assignment as it was before a write of kind, when the write
+// changed its caregiver to after. A failing journal is logged, it does not fail the write.
+func (t *Tracker) journalChange(ctx context.Context, kind ChangeKind, before *Assignment, after caregiverState) {
+	if before == nil || stateOf(before) == after {
+		return
+	}
+	journalLogger := t.logger.With().Int64("assignment_id", before.ID).Str("kind", kind.String()).Logger()
+
+	// The write is done: journal it even when the caller gave up meanwhile
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultQueryTimeout)
+	defer cancel()
+
+	batchID, err := t.batchID(ctx, kind)
+	if err != nil {
+		journalLogger.Error().Err(err).Msg("Failed to create batch of changes, the change cannot be undone")
+		return
+	}
+
+	var decisionReason sql.NullString
+	if before.DecisionReason != "" {
+		decisionReason = sql.NullString{String: before.DecisionReason.String(), Valid: true}
+	}
+	_, err = t.db.ExecContext(ctx, `
+	INSERT INTO assignment_changes (batch_id, assignment_id, parent_name, caregiver_type, override, decision_reason)
+	VALUES (?, ?, ?, ?, ?, ?)`, batchID, before.ID, before.Parent, before.CaregiverType.String(), before.Override, decisionReason)
+	if err != nil {
+		journalLogger.Error().Err(err).Msg("Failed to journal change, it cannot be undone")
+		return
+	}
+	journalLogger.Debug().Int64("batch_id", batchID).Msg("Change journaled")
+}
+
+// batchID returns the batch of the next journaled change, created on the first change of the batch
+// carried by ctx, or for this change alone when ctx carries none. Batches older than the retention
+// are purged when a batch is created.
+func (t *Tracker) batchID(ctx context.Context, kind ChangeKind) (int64, error) {
+	open, _ := ctx.Value(batchKey{}).(*openBatch)
+	if open != nil {
+		open.mu.Lock()
+		defer open.mu.Unlock()
+		if open.id != 0 {
+			return open.id, nil
+		}
+		kind = open.kind
+	}
+
+	now := time.Now().UTC()
+	result, err := t.db.ExecContext(ctx, `INSERT INTO assignment_change_batches (kind, created_at) VALUES (?, ?)`,
+		kind.String(), now.Format(time.RFC3339Nano))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert batch of changes: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get batch of changes ID: %w", err)
+	}
+	if open != nil {
+		open.id = id
+	}
+
+	cutoff := now.Add(-changeRetention).Format(time.RFC3339Nano)
+	if _, err := t.db.ExecContext(ctx, `DELETE FROM assignment_change_batches WHERE created_at < ?`, cutoff); err != nil {
+		t.logger.Warn().Err(err).Msg("Failed to purge old batches of changes")
+	}
+	return id, nil
+}
+
+// UndoLastBatch restores the assignments changed by the most recent batch not undone yet to their
+// state before the batch, and marks it undone; a following call undoes the batch before it.
+// Assignments first scheduled by the batch are kept, they had no previous state; the assignments no
+// longer overridden lose their tag. Returns ErrNothingToUndo when no batch is left.
+func (t *Tracker) UndoLastBatch(ctx context.Context) (*ChangeBatch, error) {
+	undoLogger := t.logger.With().Str("operation", "undo").Logger()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	var batch ChangeBatch
+	var restoredIDs []int64
+	err := t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		restoredIDs = nil
+
+		var kind, createdAt string
+		err := tx.QueryRowContext(ctx, `
+		SELECT id, kind, created_at FROM assignment_change_batches
+		WHERE undone_at IS NULL
+		ORDER BY id DESC
+		LIMIT 1`).Scan(&batch.ID, &kind, &createdAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNothingToUndo
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get last batch of changes: %w", err)
+		}
+		batch.Kind = ChangeKind(kind)
+		if batch.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return fmt.Errorf("failed to parse batch of changes time: %w", err)
+		}
+
+		// Newest first, so that the state before the first change of an assignment is the one kept
+		rows, err := tx.QueryContext(ctx, `
+		SELECT assignment_id, parent_name, caregiver_type, override, decision_reason
+		FROM assignment_changes
+		WHERE batch_id = ?
+		ORDER BY id DESC`, batch.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list changes of batch %d: %w", batch.ID, err)
+		}
+		type change struct {
+			assignmentID   int64
+			parent         string
+			caregiverType  string
+			override       bool
+			decisionReason sql.NullString
+		}
+		var changes []change
+		for rows.Next() {
+			var c change
+			if err := rows.Scan(&c.assignmentID, &c.parent, &c.caregiverType, &c.override, &c.decisionReason); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan change: %w", err)
+			}
+			changes = append(changes, c)
+		}
+		if err := rows.Close(); err != nil {
+			return fmt.Errorf("failed to list changes of batch %d: %w", batch.ID, err)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to list changes of batch %d: %w", batch.ID, err)
+		}
+
+		seen := make(map[int64]bool)
+		for _, c := range changes {
+			if _, err := tx.ExecContext(ctx, `
+			UPDATE assignments
+			SET parent_name = ?, caregiver_type = ?, override = ?, decision_reason = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP,
+			    tag = CASE WHEN ? THEN tag END
+			WHERE id = ?`, c.parent, c.caregiverType, c.override, c.decisionReason, c.override, c.assignmentID); err != nil {
+				return fmt.Errorf("failed to restore assignment %d: %w", c.assignmentID, err)
+			}
+			if !seen[c.assignmentID] {
+				seen[c.assignmentID] = true
+				restoredIDs = append(restoredIDs, c.assignmentID)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE assignment_change_batches SET undone_at = ? WHERE id = ?`,
+			time.Now().UTC().Format(time.RFC3339Nano), batch.ID); err != nil {
+			return fmt.Errorf("failed to mark batch %d undone: %w", batch.ID, err)
+		}
+		return nil
+	})
+	if errors.Is(err, ErrNothingToUndo) {
+		return nil, err
+	}
+	if err != nil {
+		undoLogger.Error().Err(err).Msg("Failed to undo last batch of changes")
+		return nil, fmt.Errorf("failed to undo last batch of changes: %w", err)
+	}
+	t.changed(time.Time{})
+
+	for _, id := range restoredIDs {
+		assignment, err := t.GetAssignmentByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get restored assignment %d: %w", id, err)
+		}
+		if assignment != nil {
+			batch.Assignments = append(batch.Assignments, assignment)
+		}
+	}
+	sort.Slice(batch.Assignments, func(i, j int) bool {
+		return batch.Assignments[i].Date.Before(batch.Assignments[j].Date)
+	})
+
+	undoLogger.Info().
+		Int64("batch_id", batch.ID).
+		Str("kind", batch.Kind.String()).
+		Int("assignments", len(batch.Assignments)).
+		Msg("Batch of changes undone")
+	return &batch, nil
+}
+
+// GetAssignmentChanges returns the journaled changes of an assignment, oldest first, each with the
+// caregiver before the change. Changes older than the retention of the journal are not returned.
+func (t *Tracker) GetAssignmentChanges(ctx context.Context, assignmentID int64) ([]AssignmentChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	rows, err := t.db.Conn().QueryContext(ctx, `
+	SELECT b.kind, b.created_at, b.undone_at IS NOT NULL, c.parent_name, c.caregiver_type, c.override, c.decision_reason
+	FROM assignment_changes c
+	JOIN assignment_change_batches b ON b.id = c.batch_id
+	WHERE c.assignment_id = ?
+	ORDER BY c.id`, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes of assignment %d: %w", assignmentID, err)
+	}
+	defer rows.Close()
+
+	var changes []AssignmentChange
+	for rows.Next() {
+		var change AssignmentChange
+		var kind, changedAt, caregiverType string
+		var decisionReason sql.NullString
+		if err := rows.Scan(&kind, &changedAt, &change.Undone, &change.Parent, &caregiverType, &change.Override, &decisionReason); err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+		if change.ChangedAt, err = time.Parse(time.RFC3339Nano, changedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse change time: %w", err)
+		}
+		change.Kind = ChangeKind(kind)
+		change.CaregiverType = CaregiverType(caregiverType)
+		change.DecisionReason = DecisionReason(decisionReason.String)
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list changes of assignment %d: %w", assignmentID, err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,201 @@
+package fairness
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndoLastBatch_RestoresOverrideWithItsRegeneration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	first, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	second, err := tracker.RecordAssignment(t.Context(), "Bob", day2, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	// An override and the regeneration it triggers form one batch
+	batchCtx := tracker.BeginBatch(t.Context(), ChangeKindOverride)
+	require.NoError(t, tracker.UpdateAssignmentParent(batchCtx, first.ID, "Bob", true, first.Version))
+	regenerationCtx := tracker.BeginBatch(batchCtx, ChangeKindRegeneration)
+	_, err = tracker.RecordAssignment(regenerationCtx, "Alice", day2, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	// Rewriting an assignment unchanged is not journaled
+	_, err = tracker.RecordAssignment(regenerationCtx, "Alice", day2, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	batch, err := tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, ChangeKindOverride, batch.Kind)
+	require.Len(t, batch.Assignments, 2)
+	assert.Equal(t, first.ID, batch.Assignments[0].ID, "restored assignments are sorted by date")
+	assert.Equal(t, second.ID, batch.Assignments[1].ID)
+
+	restored, err := tracker.GetAssignmentByID(t.Context(), first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", restored.Parent)
+	assert.False(t, restored.Override)
+	assert.Equal(t, DecisionReasonAlternating, restored.DecisionReason)
+	restored, err = tracker.GetAssignmentByID(t.Context(), second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", restored.Parent)
+	assert.Equal(t, DecisionReasonAlternating, restored.DecisionReason)
+
+	// The creations of the assignments had no previous state, there is nothing left to undo
+	_, err = tracker.UndoLastBatch(t.Context())
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
+
+func TestBeginBatch_ConcurrentBatches(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	assignments := make([]*Assignment, 4)
+	for i := range assignments {
+		assignments[i], err = tracker.RecordAssignment(t.Context(), "Alice", day1.AddDate(0, 0, i), false, DecisionReasonAlternating)
+		require.NoError(t, err)
+	}
+
+	// Two requests changing nights at the same time each get a batch of their own
+	kinds := []ChangeKind{ChangeKindOverride, ChangeKindEdit}
+	var wg sync.WaitGroup
+	for i, kind := range kinds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batchCtx := tracker.BeginBatch(t.Context(), kind)
+			for _, a := range []*Assignment{assignments[i], assignments[i+2]} {
+				assert.NoError(t, tracker.UpdateAssignmentParent(batchCtx, a.ID, "Bob", true, a.Version))
+			}
+		}()
+	}
+	wg.Wait()
+
+	undone := map[ChangeKind][]int64{}
+	for range kinds {
+		batch, err := tracker.UndoLastBatch(t.Context())
+		require.NoError(t, err)
+		for _, a := range batch.Assignments {
+			undone[batch.Kind] = append(undone[batch.Kind], a.ID)
+		}
+	}
+	assert.Equal(t, map[ChangeKind][]int64{
+		ChangeKindOverride: {assignments[0].ID, assignments[2].ID},
+		ChangeKindEdit:     {assignments[1].ID, assignments[3].ID},
+	}, undone)
+
+	_, err = tracker.UndoLastBatch(t.Context())
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
+
+func TestUndoLastBatch_UndoesBatchesNewestFirst(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	date := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	// Outside any batch, every change is a batch of its own
+	require.NoError(t, tracker.UpdateAssignmentToBabysitter(t.Context(), assignment.ID, "Grandma", true, assignment.Version))
+	require.NoError(t, tracker.UnlockAssignment(t.Context(), assignment.ID))
+
+	batch, err := tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, ChangeKindUnlock, batch.Kind)
+	require.Len(t, batch.Assignments, 1)
+	assert.Equal(t, "Grandma", batch.Assignments[0].Parent)
+	assert.Equal(t, CaregiverTypeBabysitter, batch.Assignments[0].CaregiverType)
+	assert.True(t, batch.Assignments[0].Override)
+
+	batch, err = tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, ChangeKindOverride, batch.Kind)
+	require.Len(t, batch.Assignments, 1)
+	assert.Equal(t, "Alice", batch.Assignments[0].Parent)
+	assert.Equal(t, CaregiverTypeParent, batch.Assignments[0].CaregiverType)
+	assert.False(t, batch.Assignments[0].Override)
+	assert.Greater(t, batch.Assignments[0].Version, assignment.Version, "an undo is a change of the caregiver")
+
+	_, err = tracker.UndoLastBatch(t.Context())
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
+
+func TestUndoLastBatch_Swap(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	dateA := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	dateB := dateA.AddDate(0, 0, 1)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", dateA, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", dateB, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	_, _, err = tracker.SwapAssignments(t.Context(), "Bob", dateA, "Alice", dateB, DecisionReasonDoubleConsecutiveSwap)
+	require.NoError(t, err)
+
+	batch, err := tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, ChangeKindSwap, batch.Kind)
+	require.Len(t, batch.Assignments, 2)
+	assert.Equal(t, "Alice", batch.Assignments[0].Parent)
+	assert.Equal(t, "Bob", batch.Assignments[1].Parent)
+}
+
+func TestUndoLastBatch_NothingToUndo(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	_, err = tracker.UndoLastBatch(t.Context())
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
+
+func TestGetAssignmentChanges(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", day, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	changes, err := tracker.GetAssignmentChanges(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "the creation of an assignment is not a change")
+
+	require.NoError(t, tracker.UpdateAssignmentParent(tracker.BeginBatch(t.Context(), ChangeKindOverride), assignment.ID, "Bob", true, assignment.Version))
+	_, err = tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentToBabysitter(t.Context(), assignment.ID, "Grandma", true, assignment.Version+2))
+
+	changes, err = tracker.GetAssignmentChanges(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, ChangeKindOverride, changes[0].Kind)
+	assert.True(t, changes[0].Undone)
+	assert.Equal(t, "Alice", changes[0].Parent)
+	assert.Equal(t, CaregiverTypeParent, changes[0].CaregiverType)
+	assert.False(t, changes[0].Override)
+	assert.Equal(t, DecisionReasonAlternating, changes[0].DecisionReason)
+	assert.False(t, changes[1].Undone)
+	assert.Equal(t, "Alice", changes[1].Parent, "the undo restored Alice")
+	assert.WithinDuration(t, time.Now(), changes[1].ChangedAt, time.Minute)
+}
@@ -0,0 +1,157 @@
+package fairness
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrChildNotFound is returned by DeleteChild when no child has the ID
+var ErrChildNotFound = errors.New("child not found")
+
+// ErrChildExists is returned by AddChild when a child already has the name
+var ErrChildExists = errors.New("a child already has this name")
+
+// Child is a child with a rotation of its own, scheduled alongside the main rotation: its nights,
+// its fairness counters and its calendar events are independent of the other rotations
+type Child struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+// ForChild returns a tracker of the rotation of child, sharing the database and the revision of t
+func (t *Tracker) ForChild(child Child) TrackerInterface {
+	return &Tracker{
+		db:       t.db,
+		logger:   t.logger.With().Int64("child_id", child.ID).Str("child", child.Name).Logger(),
+		childID:  child.ID,
+		revision: t.revision,
+	}
+}
+
+// GetChildren returns the children with a rotation of their own, by name
+func (t *Tracker) GetChildren(ctx context.Context) ([]Child, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	rows, err := t.db.Conn().QueryContext(ctx, `SELECT id, name, created_at FROM children ORDER BY name`)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("Failed to list children")
+		return nil, fmt.Errorf("failed to list children: %w", err)
+	}
+	defer rows.Close()
+
+	var children []Child
+	for rows.Next() {
+		var child Child
+		if err := rows.Scan(&child.ID, &child.Name, &child.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan child: %w", err)
+		}
+		children = append(children, child)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed during children iteration: %w", err)
+	}
+	return children, nil
+}
+
+// AddChild adds a child, whose rotation starts with the next schedule generation. Returns
+// ErrChildExists when a child already has the name.
+func (t *Tracker) AddChild(ctx context.Context, name string) (*Child, error) {
+	addLogger := t.logger.With().Str("child", name).Logger()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	var child Child
+	err := t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM children WHERE name = ?)`, name).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check child name: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("%q: %w", name, ErrChildExists)
+		}
+		result, err := tx.ExecContext(ctx, `INSERT INTO children (name) VALUES (?)`, name)
+		if err != nil {
+			return fmt.Errorf("failed to add child: %w", err)
+		}
+		if child.ID, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("failed to get child ID: %w", err)
+		}
+		return tx.QueryRowContext(ctx, `SELECT name, created_at FROM children WHERE id = ?`, child.ID).Scan(&child.Name, &child.CreatedAt)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrChildExists) {
+			addLogger.Error().Err(err).Msg("Failed to add child")
+		}
+		return nil, err
+	}
+
+	addLogger.Info().Int64("child_id", child.ID).Msg("Child added")
+	return &child, nil
+}
+
+// DeleteChild removes a child with every assignment of its rotation, and returns the removed
+// assignments so that the caller can delete their calendar events. Returns ErrChildNotFound when no
+// child has the ID.
+func (t *Tracker) DeleteChild(ctx context.Context, id int64) ([]*Assignment, error) {
+	deleteLogger := t.logger.With().Int64("child_id", id).Logger()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	var removed []*Assignment
+	err := t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		removed = nil
+		rows, err := tx.QueryContext(ctx, `
+		SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, version, tag, created_at, updated_at
+		FROM assignments
+		WHERE child_id = ?
+		ORDER BY assignment_date`, id)
+		if err != nil {
+			return fmt.Errorf("failed to list assignments of child: %w", err)
+		}
+		for rows.Next() {
+			a, err := t.scanAssignment(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			removed = append(removed, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed during assignments iteration: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM children WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete child: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		} else if n == 0 {
+			return ErrChildNotFound
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM assignments WHERE child_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete assignments of child: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrChildNotFound) {
+			deleteLogger.Error().Err(err).Msg("Failed to delete child")
+		}
+		return nil, err
+	}
+
+	if len(removed) > 0 {
+		t.changed(time.Time{})
+	}
+	deleteLogger.Info().Int("removed_assignments", len(removed)).Msg("Child deleted")
+	return removed, nil
+}
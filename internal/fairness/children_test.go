@@ -0,0 +1,76 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildren(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	emma, err := tracker.AddChild(t.Context(), "Emma")
+	require.NoError(t, err)
+	assert.Equal(t, "Emma", emma.Name)
+	_, err = tracker.AddChild(t.Context(), "Emma")
+	assert.ErrorIs(t, err, ErrChildExists)
+	_, err = tracker.AddChild(t.Context(), "Alex")
+	require.NoError(t, err)
+
+	children, err := tracker.GetChildren(t.Context())
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	assert.Equal(t, "Alex", children[0].Name, "children are ordered by name")
+	assert.Equal(t, "Emma", children[1].Name)
+
+	// Both rotations have their own night on the same date
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	emmaTracker := tracker.ForChild(*emma)
+	main, err := tracker.RecordAssignment(t.Context(), "Alice", day, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	childNight, err := emmaTracker.RecordAssignment(t.Context(), "Bob", day, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	assert.NotEqual(t, main.ID, childNight.ID)
+
+	a, err := tracker.GetAssignmentByDate(t.Context(), day)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", a.Parent)
+	a, err = emmaTracker.GetAssignmentByDate(t.Context(), day)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", a.Parent)
+
+	// The fairness counters are the ones of the rotation
+	stats, err := tracker.GetParentStatsUntil(t.Context(), day.AddDate(0, 0, 1), "Alice", "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats["Alice"].TotalAssignments)
+	assert.Equal(t, 0, stats["Bob"].TotalAssignments)
+	stats, err = emmaTracker.GetParentStatsUntil(t.Context(), day.AddDate(0, 0, 1), "Alice", "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats["Alice"].TotalAssignments)
+	assert.Equal(t, 1, stats["Bob"].TotalAssignments)
+
+	monthly, err := emmaTracker.GetParentMonthlyStatsForLastNMonths(t.Context(), day.AddDate(0, 1, 0), 2)
+	require.NoError(t, err)
+	require.Len(t, monthly, 1)
+	assert.Equal(t, MonthlyStatRow{ParentName: "Bob", MonthYear: "2026-10", Count: 1}, monthly[0])
+
+	// Removing the child removes its rotation only
+	removed, err := tracker.DeleteChild(t.Context(), emma.ID)
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	assert.Equal(t, childNight.ID, removed[0].ID)
+	a, err = tracker.GetAssignmentByDate(t.Context(), day)
+	require.NoError(t, err)
+	assert.Equal(t, main.ID, a.ID)
+	monthly, err = emmaTracker.GetParentMonthlyStatsForLastNMonths(t.Context(), day.AddDate(0, 1, 0), 2)
+	require.NoError(t, err)
+	assert.Empty(t, monthly)
+
+	_, err = tracker.DeleteChild(t.Context(), emma.ID)
+	assert.ErrorIs(t, err, ErrChildNotFound)
+}
@@ -1,5 +1,10 @@
 package fairness
 
+import (
+	"errors"
+	"fmt"
+)
+
 // DecisionReason represents the reason for a parent assignment decision
 type DecisionReason string
 
@@ -19,9 +24,41 @@ const (
 	// DecisionReasonDoubleConsecutiveSwap represents that assignments were swapped to avoid
 	// both parents having back-to-back consecutive nights (e.g. AA BB → AB AB).
 	DecisionReasonDoubleConsecutiveSwap DecisionReason = "Double Consecutive Swap"
+	// DecisionReasonRestDays represents that a parent was assigned because the parent selected by the
+	// fairness rules did not get the minimum nights off since its last block of consecutive nights
+	DecisionReasonRestDays DecisionReason = "Rest Days"
+	// DecisionReasonSwap represents that two parents exchanged their nights through a swap request
+	// proposed by one and confirmed by the other
+	DecisionReasonSwap DecisionReason = "Swap"
 )
 
 // String returns the string representation of the DecisionReason
 func (d DecisionReason) String() string {
 	return string(d)
 }
+
+// DecisionReasons lists the decision reasons an assignment can have
+var DecisionReasons = []DecisionReason{
+	DecisionReasonUnavailability,
+	DecisionReasonTotalCount,
+	DecisionReasonRecentCount,
+	DecisionReasonConsecutiveLimit,
+	DecisionReasonAlternating,
+	DecisionReasonOverride,
+	DecisionReasonDoubleConsecutiveSwap,
+	DecisionReasonRestDays,
+	DecisionReasonSwap,
+}
+
+// ErrInvalidDecisionReason is returned for a decision reason which is not one of DecisionReasons
+var ErrInvalidDecisionReason = errors.New("invalid decision reason")
+
+// ParseDecisionReason parses one of DecisionReasons, as returned by String
+func ParseDecisionReason(value string) (DecisionReason, error) {
+	for _, reason := range DecisionReasons {
+		if string(reason) == value {
+			return reason, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidDecisionReason, value)
+}
@@ -32,7 +32,7 @@ func TestDecisionReasonTracking(t *testing.T) {
 	// Record assignments with different decision reasons
 	for i, tc := range testCases {
 		testDate := date.AddDate(0, 0, i) // Use a different date for each test case
-		assignment, err := tracker.RecordAssignment(tc.parent, testDate, false, tc.decisionReason)
+		assignment, err := tracker.RecordAssignment(t.Context(), tc.parent, testDate, false, tc.decisionReason)
 		assert.NoError(t, err)
 		assert.Equal(t, tc.parent, assignment.Parent)
 		assert.Equal(t, tc.decisionReason, assignment.DecisionReason)
@@ -41,7 +41,7 @@ func TestDecisionReasonTracking(t *testing.T) {
 	// Test retrieving assignments and verifying decision reasons
 	for i, tc := range testCases {
 		testDate := date.AddDate(0, 0, i)
-		assignment, err := tracker.GetAssignmentByDate(testDate)
+		assignment, err := tracker.GetAssignmentByDate(t.Context(), testDate)
 		assert.NoError(t, err)
 		assert.NotNil(t, assignment)
 		assert.Equal(t, tc.parent, assignment.Parent)
@@ -60,12 +60,12 @@ func TestDecisionReasonWithOverride(t *testing.T) {
 	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	// Create initial assignment with a decision reason
-	assignment, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
 	assert.Equal(t, DecisionReasonTotalCount, assignment.DecisionReason)
 
 	// Override the assignment with a different decision reason
-	updatedAssignment, err := tracker.RecordAssignment("Bob", date, true, DecisionReasonOverride)
+	updatedAssignment, err := tracker.RecordAssignment(t.Context(), "Bob", date, true, DecisionReasonOverride)
 	assert.NoError(t, err)
 	assert.Equal(t, "Bob", updatedAssignment.Parent)
 	assert.Equal(t, DecisionReasonOverride, updatedAssignment.DecisionReason)
@@ -91,14 +91,14 @@ func TestDecisionReasonInRange(t *testing.T) {
 		if i%2 == 1 {
 			parent = "Bob"
 		}
-		_, err := tracker.RecordAssignment(parent, date, false, reason)
+		_, err := tracker.RecordAssignment(t.Context(), parent, date, false, reason)
 		assert.NoError(t, err)
 	}
 
 	// Get assignments in range
 	rangeStart := startDate
 	rangeEnd := startDate.AddDate(0, 0, len(decisionReasons)-1)
-	assignments, err := tracker.GetAssignmentsInRange(rangeStart, rangeEnd)
+	assignments, err := tracker.GetAssignmentsInRange(t.Context(), rangeStart, rangeEnd)
 	assert.NoError(t, err)
 	assert.Len(t, assignments, len(decisionReasons))
 
@@ -120,32 +120,43 @@ func TestDecisionReasonWithGoogleCalendarID(t *testing.T) {
 	eventID := "google_event_123"
 
 	// Create assignment with decision reason
-	assignment, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
 
 	// Set Google Calendar event ID separately
-	err = tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, eventID)
+	err = tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, eventID)
 	assert.NoError(t, err)
 
 	// Get updated assignment
-	assignment, err = tracker.GetAssignmentByID(assignment.ID)
+	assignment, err = tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, eventID, assignment.GoogleCalendarEventID)
 	assert.Equal(t, DecisionReasonTotalCount, assignment.DecisionReason)
 
 	// Get assignment by Google Calendar event ID
-	retrievedAssignment, err := tracker.GetAssignmentByGoogleCalendarEventID(eventID)
+	retrievedAssignment, err := tracker.GetAssignmentByGoogleCalendarEventID(t.Context(), eventID)
 	assert.NoError(t, err)
 	assert.NotNil(t, retrievedAssignment)
 	assert.Equal(t, DecisionReasonTotalCount, retrievedAssignment.DecisionReason)
 
 	// Update Google Calendar event ID and verify decision reason is preserved
 	newEventID := "google_event_456"
-	err = tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, newEventID)
+	err = tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, newEventID)
 	assert.NoError(t, err)
 
-	updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+	updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, newEventID, updatedAssignment.GoogleCalendarEventID)
 	assert.Equal(t, DecisionReasonTotalCount, updatedAssignment.DecisionReason)
 }
+
+// TestParseDecisionReason verifies that every decision reason parses back from its string
+func TestParseDecisionReason(t *testing.T) {
+	for _, reason := range DecisionReasons {
+		parsed, err := ParseDecisionReason(reason.String())
+		assert.NoError(t, err)
+		assert.Equal(t, reason, parsed)
+	}
+	_, err := ParseDecisionReason("Coin Toss")
+	assert.ErrorIs(t, err, ErrInvalidDecisionReason)
+}
@@ -0,0 +1,168 @@
+package fairness
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Highlights are the streaks, weekend nights, checklist completions, acknowledgements and monthly
+// MVPs of the parents over a period, shown on the statistics page
+type Highlights struct {
+	Parents     []ParentHighlights // Sorted by parent name
+	MonthlyMVPs []MonthlyMVP       // Oldest month first, months without parent nights are skipped
+}
+
+// ParentHighlights are the highlights of one parent
+type ParentHighlights struct {
+	Parent           string
+	Nights           int
+	LongestStreak    int // Most nights in a row
+	WeekendNights    int // Friday and Saturday nights
+	ChecklistsOnTime int // Nights whose checklist was fully ticked before the end of the day
+	Acknowledged     int // Nights the parent acknowledged
+	// TaggedNights counts the tagged nights by tag, in the order of AssignmentTags, without the
+	// tags of no night: the extra load from sickness or from the other parent being away
+	TaggedNights []TaggedNights
+}
+
+// TaggedNights is the number of nights of a parent with a tag
+type TaggedNights struct {
+	Tag    AssignmentTag
+	Nights int
+}
+
+// OnTimePercent returns the rounded percentage of the nights whose checklist was completed on time,
+// 0 without nights
+func (p ParentHighlights) OnTimePercent() int {
+	if p.Nights == 0 {
+		return 0
+	}
+	return int(math.Round(100 * float64(p.ChecklistsOnTime) / float64(p.Nights)))
+}
+
+// MonthlyMVP is the parent who did the most nights of a month, the on-time checklists breaking
+// ties. Parents still tied share the title.
+type MonthlyMVP struct {
+	Month            string // YYYY-MM
+	Parents          []string
+	Nights           int
+	ChecklistsOnTime int
+}
+
+// isWeekendNight reports whether date is a Friday or a Saturday, the nights before a day off
+func isWeekendNight(date time.Time) bool {
+	return date.Weekday() == time.Friday || date.Weekday() == time.Saturday
+}
+
+// ComputeHighlights computes the highlights of the parents from their assignments. completions maps
+// an assignment ID to the time its checklist was fully ticked; a checklist is on time when ticked
+// before midnight ending the night in loc. acknowledgements holds the IDs of the assignments the
+// parent on duty acknowledged. Babysitter nights count for nobody and break the streaks, their tags
+// included.
+func ComputeHighlights(assignments []*Assignment, completions, acknowledgements map[int64]time.Time, loc *time.Location) Highlights {
+	sorted := slices.Clone(assignments)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	parents := make(map[string]*ParentHighlights)
+	tagged := make(map[string]map[AssignmentTag]int)
+	type monthKey struct{ month, parent string }
+	monthly := make(map[monthKey]*MonthlyMVP)
+	var months []string
+
+	var previous *Assignment
+	streak := 0
+	for _, a := range sorted {
+		if a.CaregiverType != CaregiverTypeParent {
+			previous = a
+			continue
+		}
+		p, ok := parents[a.Parent]
+		if !ok {
+			p = &ParentHighlights{Parent: a.Parent}
+			parents[a.Parent] = p
+		}
+		p.Nights++
+		if a.Tag != AssignmentTagNone {
+			if tagged[a.Parent] == nil {
+				tagged[a.Parent] = make(map[AssignmentTag]int)
+			}
+			tagged[a.Parent][a.Tag]++
+		}
+		if isWeekendNight(a.Date) {
+			p.WeekendNights++
+		}
+		if _, ok := acknowledgements[a.ID]; ok {
+			p.Acknowledged++
+		}
+
+		if previous != nil && previous.CaregiverType == CaregiverTypeParent && previous.Parent == a.Parent &&
+			previous.Date.AddDate(0, 0, 1).Format(dateFormat) == a.Date.Format(dateFormat) {
+			streak++
+		} else {
+			streak = 1
+		}
+		p.LongestStreak = max(p.LongestStreak, streak)
+		previous = a
+
+		onTime := false
+		if completedAt, ok := completions[a.ID]; ok {
+			deadline := time.Date(a.Date.Year(), a.Date.Month(), a.Date.Day()+1, 0, 0, 0, 0, loc)
+			onTime = completedAt.Before(deadline)
+		}
+		if onTime {
+			p.ChecklistsOnTime++
+		}
+
+		key := monthKey{month: a.Date.Format("2006-01"), parent: a.Parent}
+		m, ok := monthly[key]
+		if !ok {
+			m = &MonthlyMVP{Month: key.month, Parents: []string{a.Parent}}
+			monthly[key] = m
+			if len(months) == 0 || months[len(months)-1] != key.month {
+				months = append(months, key.month)
+			}
+		}
+		m.Nights++
+		if onTime {
+			m.ChecklistsOnTime++
+		}
+	}
+
+	var highlights Highlights
+	for _, p := range parents {
+		for _, tag := range AssignmentTags {
+			if nights := tagged[p.Parent][tag]; nights > 0 {
+				p.TaggedNights = append(p.TaggedNights, TaggedNights{Tag: tag, Nights: nights})
+			}
+		}
+		highlights.Parents = append(highlights.Parents, *p)
+	}
+	sort.Slice(highlights.Parents, func(i, j int) bool { return highlights.Parents[i].Parent < highlights.Parents[j].Parent })
+
+	names := make([]string, 0, len(parents))
+	for name := range parents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, month := range months {
+		var mvp *MonthlyMVP
+		for _, name := range names {
+			candidate, ok := monthly[monthKey{month: month, parent: name}]
+			if !ok {
+				continue
+			}
+			switch {
+			case mvp == nil || candidate.Nights > mvp.Nights ||
+				(candidate.Nights == mvp.Nights && candidate.ChecklistsOnTime > mvp.ChecklistsOnTime):
+				winner := *candidate
+				mvp = &winner
+			case candidate.Nights == mvp.Nights && candidate.ChecklistsOnTime == mvp.ChecklistsOnTime:
+				mvp.Parents = append(mvp.Parents, name)
+			}
+		}
+		highlights.MonthlyMVPs = append(highlights.MonthlyMVPs, *mvp)
+	}
+	return highlights
+}
@@ -0,0 +1,122 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// night returns a parent assignment on the day of October 2026
+func night(id int64, parent string, day int) *Assignment {
+	return &Assignment{ID: id, Parent: parent, CaregiverType: CaregiverTypeParent, Date: time.Date(2026, 10, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestComputeHighlights_Streaks(t *testing.T) {
+	// Thursday 1st to Wednesday 14th of October 2026
+	assignments := []*Assignment{
+		night(1, "Alice", 1), night(2, "Alice", 2), night(3, "Alice", 3),
+		night(4, "Bob", 4),
+		night(5, "Alice", 5),
+		{ID: 6, Parent: "Dawn", CaregiverType: CaregiverTypeBabysitter, Date: time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)},
+		night(7, "Alice", 7),
+		night(9, "Bob", 9), night(10, "Bob", 10),
+		// A missing night breaks the streak
+		night(12, "Bob", 12),
+	}
+
+	highlights := ComputeHighlights(assignments, nil, nil, time.UTC)
+
+	assert.Equal(t, []ParentHighlights{
+		{Parent: "Alice", Nights: 5, LongestStreak: 3, WeekendNights: 2},
+		{Parent: "Bob", Nights: 4, LongestStreak: 2, WeekendNights: 2},
+	}, highlights.Parents)
+}
+
+func TestComputeHighlights_ChecklistsOnTime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("Europe/Paris time zone not available")
+	}
+	assignments := []*Assignment{night(1, "Alice", 1), night(2, "Alice", 2), night(3, "Alice", 3)}
+	completions := map[int64]time.Time{
+		// 23:30 in Paris, on time
+		1: time.Date(2026, 10, 1, 21, 30, 0, 0, time.UTC),
+		// 00:30 the next day in Paris, late
+		2: time.Date(2026, 10, 2, 22, 30, 0, 0, time.UTC),
+	}
+
+	highlights := ComputeHighlights(assignments, completions, nil, paris)
+
+	assert.Equal(t, 1, highlights.Parents[0].ChecklistsOnTime)
+	assert.Equal(t, 33, highlights.Parents[0].OnTimePercent())
+	assert.Zero(t, ParentHighlights{}.OnTimePercent())
+}
+
+func TestComputeHighlights_Acknowledged(t *testing.T) {
+	assignments := []*Assignment{
+		night(1, "Alice", 1), night(2, "Alice", 2),
+		{ID: 3, Parent: "Dawn", CaregiverType: CaregiverTypeBabysitter, Date: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC)},
+	}
+	acknowledgedAt := time.Date(2026, 10, 1, 19, 0, 0, 0, time.UTC)
+
+	highlights := ComputeHighlights(assignments, nil, map[int64]time.Time{1: acknowledgedAt, 3: acknowledgedAt}, time.UTC)
+
+	assert.Equal(t, []ParentHighlights{{Parent: "Alice", Nights: 2, LongestStreak: 2, WeekendNights: 1, Acknowledged: 1}}, highlights.Parents,
+		"babysitter nights are not acknowledged")
+}
+
+func TestComputeHighlights_TaggedNights(t *testing.T) {
+	tagged := func(a *Assignment, tag AssignmentTag) *Assignment {
+		a.Override = true
+		a.Tag = tag
+		return a
+	}
+	assignments := []*Assignment{
+		tagged(night(1, "Alice", 1), AssignmentTagParentAway),
+		tagged(night(2, "Alice", 2), AssignmentTagSickKid),
+		tagged(night(3, "Alice", 3), AssignmentTagSickKid),
+		night(4, "Bob", 4),
+		{ID: 5, Parent: "Dawn", CaregiverType: CaregiverTypeBabysitter, Override: true, Tag: AssignmentTagSickKid, Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)},
+	}
+
+	highlights := ComputeHighlights(assignments, nil, nil, time.UTC)
+
+	assert.Equal(t, []TaggedNights{
+		{Tag: AssignmentTagSickKid, Nights: 2},
+		{Tag: AssignmentTagParentAway, Nights: 1},
+	}, highlights.Parents[0].TaggedNights)
+	assert.Empty(t, highlights.Parents[1].TaggedNights)
+}
+
+func TestComputeHighlights_MonthlyMVPs(t *testing.T) {
+	september := func(id int64, parent string, day int) *Assignment {
+		a := night(id, parent, day)
+		a.Date = a.Date.AddDate(0, -1, 0)
+		return a
+	}
+	assignments := []*Assignment{
+		// October: Alice and Bob tied on nights, Bob completed a checklist on time
+		night(10, "Alice", 1), night(11, "Bob", 2), night(12, "Alice", 3), night(13, "Bob", 4),
+		// September: Bob did the most nights
+		september(1, "Alice", 1), september(2, "Bob", 2), september(3, "Bob", 3),
+		// August: a perfect tie is shared
+		{ID: 20, Parent: "Alice", CaregiverType: CaregiverTypeParent, Date: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 21, Parent: "Bob", CaregiverType: CaregiverTypeParent, Date: time.Date(2026, 8, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	completions := map[int64]time.Time{13: time.Date(2026, 10, 4, 20, 0, 0, 0, time.UTC)}
+
+	highlights := ComputeHighlights(assignments, completions, nil, time.UTC)
+
+	assert.Equal(t, []MonthlyMVP{
+		{Month: "2026-08", Parents: []string{"Alice", "Bob"}, Nights: 1},
+		{Month: "2026-09", Parents: []string{"Bob"}, Nights: 2},
+		{Month: "2026-10", Parents: []string{"Bob"}, Nights: 2, ChecklistsOnTime: 1},
+	}, highlights.MonthlyMVPs)
+}
+
+func TestComputeHighlights_Empty(t *testing.T) {
+	highlights := ComputeHighlights(nil, nil, nil, time.UTC)
+	assert.Empty(t, highlights.Parents)
+	assert.Empty(t, highlights.MonthlyMVPs)
+}
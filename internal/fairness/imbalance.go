@@ -0,0 +1,51 @@
+package fairness
+
+import (
+	"context"
+	"time"
+)
+
+// Imbalance compares the nights of the busiest parent and of the least busy one over the 30 days
+// ending today. Babysitter nights count for every parent and never create an imbalance.
+type Imbalance struct {
+	Busier      string // Parent with the most nights, the first of the roster when even
+	Other       string // Parent with the fewest nights, the last of the roster when even
+	BusierCount int
+	OtherCount  int
+}
+
+// Difference returns how many more nights the busier parent did
+func (i Imbalance) Difference() int {
+	return i.BusierCount - i.OtherCount
+}
+
+// Reaches reports whether the difference reaches threshold; a threshold of 0 or less is never reached
+func (i Imbalance) Reaches(threshold int) bool {
+	return threshold > 0 && i.Difference() >= threshold
+}
+
+// ParentStatsSource reads the statistics of the parents, implemented by Tracker
+type ParentStatsSource interface {
+	GetParentStatsUntil(ctx context.Context, until time.Time, parentNames ...string) (map[string]Stats, error)
+}
+
+// RecentImbalance returns the imbalance of the nights of the parents of the roster, parent A first,
+// over the 30 days ending on the day of now, that day included
+func RecentImbalance(ctx context.Context, tracker ParentStatsSource, now time.Time, parents ...string) (Imbalance, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	stats, err := tracker.GetParentStatsUntil(ctx, today.AddDate(0, 0, 1), parents...)
+	if err != nil {
+		return Imbalance{}, err
+	}
+	var imbalance Imbalance
+	for i, parent := range parents {
+		count := stats[parent].Last30Days
+		if i == 0 || count > imbalance.BusierCount {
+			imbalance.Busier, imbalance.BusierCount = parent, count
+		}
+		if i == 0 || count <= imbalance.OtherCount {
+			imbalance.Other, imbalance.OtherCount = parent, count
+		}
+	}
+	return imbalance, nil
+}
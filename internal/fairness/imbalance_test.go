@@ -0,0 +1,63 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentImbalance(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 10; i++ {
+		parent := "Bob"
+		if i < 3 {
+			parent = "Alice"
+		}
+		_, err := tracker.RecordAssignment(t.Context(), parent, now.AddDate(0, 0, -i), false, DecisionReasonTotalCount)
+		require.NoError(t, err)
+	}
+	// Out of the 30 days window
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", now.AddDate(0, 0, -30), false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", now.AddDate(0, 0, 1), false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	// Counts for both parents
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", now.AddDate(0, 0, -12), true)
+	require.NoError(t, err)
+
+	imbalance, err := RecentImbalance(t.Context(), tracker, now, "Alice", "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, Imbalance{Busier: "Bob", Other: "Alice", BusierCount: 8, OtherCount: 4}, imbalance)
+	assert.Equal(t, 4, imbalance.Difference())
+	assert.True(t, imbalance.Reaches(4))
+	assert.False(t, imbalance.Reaches(5))
+	assert.False(t, imbalance.Reaches(0), "a threshold of 0 disables the alert")
+}
+
+func TestRecentImbalance_ExtraParents(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+
+	for i, parent := range []string{"Alice", "Bob", "Bob", "Grandma", "Bob", "Alice"} {
+		_, err := tracker.RecordAssignment(t.Context(), parent, now.AddDate(0, 0, -i), false, DecisionReasonTotalCount)
+		require.NoError(t, err)
+	}
+
+	imbalance, err := RecentImbalance(t.Context(), tracker, now, "Alice", "Bob", "Grandma")
+	require.NoError(t, err)
+	assert.Equal(t, Imbalance{Busier: "Bob", Other: "Grandma", BusierCount: 3, OtherCount: 1}, imbalance)
+
+	imbalance, err = RecentImbalance(t.Context(), tracker, now.AddDate(0, 0, 40), "Alice", "Bob", "Grandma")
+	require.NoError(t, err)
+	assert.Equal(t, Imbalance{Busier: "Alice", Other: "Grandma"}, imbalance, "the first and last of the roster when even")
+}
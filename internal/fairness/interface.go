@@ -1,69 +1,153 @@
 package fairness
 
-import "time"
+import (
+	"context"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+)
 
 // TrackerInterface defines the operations for tracking fairness
 type TrackerInterface interface {
 	// RecordAssignment records a new assignment with all details
-	RecordAssignment(parent string, date time.Time, override bool, decisionReason DecisionReason) (*Assignment, error)
+	RecordAssignment(ctx context.Context, parent string, date time.Time, override bool, decisionReason DecisionReason) (*Assignment, error)
 
 	// RecordBabysitterAssignment records a named babysitter assignment for a date.
-	RecordBabysitterAssignment(name string, date time.Time, override bool) (*Assignment, error)
+	RecordBabysitterAssignment(ctx context.Context, name string, date time.Time, override bool) (*Assignment, error)
 
 	// GetLastAssignmentsUntil returns the last n assignments of all caregiver types up to a specific date.
 	// Used to detect babysitter nights and gaps that break consecutive-assignment chains.
 	// Parent-only entries can be derived from this list by filtering on CaregiverType.
-	GetLastAssignmentsUntil(n int, until time.Time) ([]*Assignment, error)
+	GetLastAssignmentsUntil(ctx context.Context, n int, until time.Time) ([]*Assignment, error)
 
 	// GetParentStatsUntil returns statistics for each parent up to a specific date.
 	// parentNames ensures that both configured parents appear in the result map
 	// even if they have zero parent assignments so far, so that babysitter shift
 	// counts are applied to both.
-	GetParentStatsUntil(until time.Time, parentNames ...string) (map[string]Stats, error)
+	GetParentStatsUntil(ctx context.Context, until time.Time, parentNames ...string) (map[string]Stats, error)
+
+	// GetWeightedParentStatsUntil returns the statistics of GetParentStatsUntil, the weighted
+	// counts weighing each night with the weight of its day of the week.
+	GetWeightedParentStatsUntil(ctx context.Context, until time.Time, weights config.DayWeights, parentNames ...string) (map[string]Stats, error)
 
 	// GetAssignmentByID retrieves an assignment by its ID
-	GetAssignmentByID(id int64) (*Assignment, error)
+	GetAssignmentByID(ctx context.Context, id int64) (*Assignment, error)
 
 	// GetAssignmentByDate retrieves an assignment for a specific date
-	GetAssignmentByDate(date time.Time) (*Assignment, error)
+	GetAssignmentByDate(ctx context.Context, date time.Time) (*Assignment, error)
 
 	// UpdateAssignmentGoogleCalendarEventID updates an assignment with Google Calendar event ID
-	UpdateAssignmentGoogleCalendarEventID(id int64, googleCalendarEventID string) error
+	UpdateAssignmentGoogleCalendarEventID(ctx context.Context, id int64, googleCalendarEventID string) error
 
 	// GetAssignmentByGoogleCalendarEventID retrieves an assignment by its Google Calendar event ID
-	GetAssignmentByGoogleCalendarEventID(eventID string) (*Assignment, error)
+	GetAssignmentByGoogleCalendarEventID(ctx context.Context, eventID string) (*Assignment, error)
 
 	// GetAssignmentsInRange retrieves all assignments in a date range
-	GetAssignmentsInRange(start, end time.Time) ([]*Assignment, error)
+	GetAssignmentsInRange(ctx context.Context, start, end time.Time) ([]*Assignment, error)
 
-	// UpdateAssignmentParent updates the parent for an assignment and sets the override flag
-	UpdateAssignmentParent(id int64, parent string, override bool) error
+	// UpdateAssignmentParent updates the parent for an assignment and sets the override flag.
+	// Returns ErrAssignmentConflict when the assignment is no longer at version.
+	UpdateAssignmentParent(ctx context.Context, id int64, parent string, override bool, version int64) error
 
 	// UpdateAssignmentToBabysitter sets an assignment to a named babysitter.
-	UpdateAssignmentToBabysitter(id int64, babysitterName string, override bool) error
+	// Returns ErrAssignmentConflict when the assignment is no longer at version.
+	UpdateAssignmentToBabysitter(ctx context.Context, id int64, babysitterName string, override bool, version int64) error
+
+	UnlockAssignment(ctx context.Context, id int64) error
 
-	UnlockAssignment(id int64) error
+	// SetAssignmentTag tags an overridden assignment, AssignmentTagNone removing the tag.
+	// Returns ErrAssignmentNotOverridden when the assignment is not an override.
+	SetAssignmentTag(ctx context.Context, id int64, tag AssignmentTag) error
+
+	// DeleteAssignment removes an assignment, used for the nights of a vacation
+	DeleteAssignment(ctx context.Context, id int64) error
 
 	// GetLastAssignmentDate returns the date of the last assignment in the database
-	GetLastAssignmentDate() (time.Time, error)
+	GetLastAssignmentDate(ctx context.Context) (time.Time, error)
+
+	// GetAssignmentsVersion returns a version of the assignments that changes on every write
+	GetAssignmentsVersion(ctx context.Context) (AssignmentsVersion, error)
 
 	// GetParentMonthlyStatsForLastNMonths fetches and aggregates assignment counts per parent per month for the last n months,
 	// relative to the given referenceTime.
-	GetParentMonthlyStatsForLastNMonths(referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error)
+	GetParentMonthlyStatsForLastNMonths(ctx context.Context, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error)
 
 	// GetBabysitterMonthlyStatsForLastNMonths fetches babysitter assignment counts per babysitter per month.
-	GetBabysitterMonthlyStatsForLastNMonths(referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error)
+	GetBabysitterMonthlyStatsForLastNMonths(ctx context.Context, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error)
 
-	// SaveAssignmentDetails stores the fairness algorithm calculation details for an assignment
-	SaveAssignmentDetails(assignmentID int64, calculationDate time.Time, parentAName string, statsA Stats, parentBName string, statsB Stats) error
+	// SaveAssignmentDetails stores the fairness algorithm calculation details for an assignment,
+	// extra holding the stats of the extra parents of the roster
+	SaveAssignmentDetails(ctx context.Context, assignmentID int64, calculationDate time.Time, parentAName string, statsA Stats, parentBName string, statsB Stats, extra ...NamedStats) error
 
 	// GetAssignmentDetails retrieves the fairness algorithm calculation details for an assignment
-	GetAssignmentDetails(assignmentID int64) (*AssignmentDetails, error)
+	GetAssignmentDetails(ctx context.Context, assignmentID int64) (*AssignmentDetails, error)
 
 	// SwapAssignments atomically swaps two assignments' parents within a single
 	// database transaction. Both assignments are upserted with the new parent
 	// and the given decision reason. Returns the updated assignment records.
-	SwapAssignments(parentA string, dateA time.Time, parentB string, dateB time.Time, reason DecisionReason) (updatedA *Assignment, updatedB *Assignment, err error)
+	SwapAssignments(ctx context.Context, parentA string, dateA time.Time, parentB string, dateB time.Time, reason DecisionReason) (updatedA *Assignment, updatedB *Assignment, err error)
+
+	// EditAssignments applies edits, overrides and unlocks, in a single transaction: either all of
+	// them or none. Returns ErrAssignmentNotScheduled for a night without assignment and
+	// ErrAssignmentConflict for one no longer at the version of its edit.
+	EditAssignments(ctx context.Context, edits []AssignmentEdit) ([]EditedAssignment, error)
+
+	// SwapNights exchanges the nights of two parents in a single transaction, both becoming
+	// overrides with DecisionReasonSwap. Returns ErrAssignmentConflict when a night is no longer
+	// held by the parent of the swap.
+	SwapNights(ctx context.Context, swap NightSwap) (*Assignment, *Assignment, error)
+
+	// BeginBatch returns a context grouping the changes made with it into one batch of kind, undone
+	// together. A batch begun with a context already carrying one joins it.
+	BeginBatch(ctx context.Context, kind ChangeKind) context.Context
+
+	// GetAssignmentChanges returns the journaled changes of an assignment, oldest first, each with
+	// the caregiver before the change
+	GetAssignmentChanges(ctx context.Context, assignmentID int64) ([]AssignmentChange, error)
+
+	// UndoLastBatch restores the assignments changed by the most recent batch not undone yet.
+	// Returns ErrNothingToUndo when no batch is left.
+	UndoLastBatch(ctx context.Context) (*ChangeBatch, error)
+
+	// LockRange locks the days from start to end, both included, so that the regeneration keeps
+	// their assignments. Returns ErrInvalidLockedRange when end is before start.
+	LockRange(ctx context.Context, start, end time.Time) (*LockedRange, error)
+
+	// UnlockRange removes a locked range. Returns ErrLockedRangeNotFound when no locked range has the ID.
+	UnlockRange(ctx context.Context, id int64) error
+
+	// GetLockedRanges returns the locked ranges, ordered by start
+	GetLockedRanges(ctx context.Context) (LockedRanges, error)
+
+	// ReplaceCalendarUnavailability replaces the days from start to end on which parent ("parent_a"
+	// or "parent_b") is unavailable from the keyword events of its personal calendar
+	ReplaceCalendarUnavailability(ctx context.Context, parent string, start, end time.Time, dates []time.Time) error
+
+	// GetCalendarUnavailability returns the days from start to end on which parent is unavailable
+	// from its personal calendar, in order
+	GetCalendarUnavailability(ctx context.Context, parent string, start, end time.Time) ([]time.Time, error)
+
+	// GetAppliedEventVersion returns the version of the Google Calendar event whose change was last
+	// applied to an assignment, nil when none was
+	GetAppliedEventVersion(ctx context.Context, eventID string) (*EventVersion, error)
+
+	// RecordAppliedEventVersion records that the change of version of the Google Calendar event was
+	// applied to the assignment
+	RecordAppliedEventVersion(ctx context.Context, eventID string, assignmentID int64, version EventVersion) error
+
+	// GetChildren returns the children with a rotation of their own, by name
+	GetChildren(ctx context.Context) ([]Child, error)
+
+	// AddChild adds a child with a rotation of its own. Returns ErrChildExists when a child already has the name.
+	AddChild(ctx context.Context, name string) (*Child, error)
+
+	// DeleteChild removes a child with the assignments of its rotation, returned so that their calendar
+	// events can be deleted. Returns ErrChildNotFound when no child has the ID.
+	DeleteChild(ctx context.Context, id int64) ([]*Assignment, error)
+
+	// ForChild returns the tracker of the rotation of child: the assignments, statistics and
+	// regeneration it works on are the ones of the child
+	ForChild(child Child) TrackerInterface
 }
 
 // Ensure Tracker implements the TrackerInterface
@@ -0,0 +1,132 @@
+package fairness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidLockedRange is returned by LockRange when the range ends before it starts
+var ErrInvalidLockedRange = errors.New("locked range ends before it starts")
+
+// ErrLockedRangeNotFound is returned by UnlockRange when no locked range has the ID
+var ErrLockedRangeNotFound = errors.New("locked range not found")
+
+// LockedRange is a range of days, first and last included, whose assignments the schedule
+// regeneration keeps as they are
+type LockedRange struct {
+	ID    int64
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether the day of date is within the range
+func (r LockedRange) Contains(date time.Time) bool {
+	day := date.Format(dateFormat)
+	return day >= r.Start.Format(dateFormat) && day <= r.End.Format(dateFormat)
+}
+
+// LockedRanges are the locked ranges, ordered by start
+type LockedRanges []LockedRange
+
+// Contains reports whether the day of date is within one of the ranges
+func (r LockedRanges) Contains(date time.Time) bool {
+	for _, lockedRange := range r {
+		if lockedRange.Contains(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// LockRange locks the days from start to end, both included. Returns ErrInvalidLockedRange when
+// end is before start.
+func (t *Tracker) LockRange(ctx context.Context, start, end time.Time) (*LockedRange, error) {
+	startStr, endStr := start.Format(dateFormat), end.Format(dateFormat)
+	lockLogger := t.logger.With().Str("start_date", startStr).Str("end_date", endStr).Logger()
+	if endStr < startStr {
+		return nil, ErrInvalidLockedRange
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	result, err := t.db.ExecContext(ctx, `INSERT INTO locked_ranges (start_date, end_date) VALUES (?, ?)`, startStr, endStr)
+	if err != nil {
+		lockLogger.Error().Err(err).Msg("Failed to lock range")
+		return nil, fmt.Errorf("failed to lock range: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get locked range ID: %w", err)
+	}
+
+	// The assignments are shown as locked: a new version of the home page
+	t.revision.Add(1)
+	lockLogger.Info().Int64("locked_range_id", id).Msg("Range locked")
+	return &LockedRange{ID: id, Start: startOfDay(start), End: startOfDay(end)}, nil
+}
+
+// UnlockRange removes a locked range, handing its assignments back to the regeneration. Returns
+// ErrLockedRangeNotFound when no locked range has the ID.
+func (t *Tracker) UnlockRange(ctx context.Context, id int64) error {
+	unlockLogger := t.logger.With().Int64("locked_range_id", id).Logger()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	result, err := t.db.ExecContext(ctx, `DELETE FROM locked_ranges WHERE id = ?`, id)
+	if err != nil {
+		unlockLogger.Error().Err(err).Msg("Failed to unlock range")
+		return fmt.Errorf("failed to unlock range: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check unlocked range: %w", err)
+	}
+	if rows == 0 {
+		return ErrLockedRangeNotFound
+	}
+
+	t.revision.Add(1)
+	unlockLogger.Info().Msg("Range unlocked")
+	return nil
+}
+
+// GetLockedRanges returns the locked ranges, ordered by start
+func (t *Tracker) GetLockedRanges(ctx context.Context) (LockedRanges, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	rows, err := t.db.Conn().QueryContext(ctx, `SELECT id, start_date, end_date FROM locked_ranges ORDER BY start_date, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query locked ranges: %w", err)
+	}
+	defer rows.Close()
+
+	var ranges LockedRanges
+	for rows.Next() {
+		var lockedRange LockedRange
+		var startStr, endStr string
+		if err := rows.Scan(&lockedRange.ID, &startStr, &endStr); err != nil {
+			return nil, fmt.Errorf("failed to scan locked range: %w", err)
+		}
+		if lockedRange.Start, err = time.Parse(dateFormat, startStr); err != nil {
+			return nil, fmt.Errorf("failed to parse start of locked range %d: %w", lockedRange.ID, err)
+		}
+		if lockedRange.End, err = time.Parse(dateFormat, endStr); err != nil {
+			return nil, fmt.Errorf("failed to parse end of locked range %d: %w", lockedRange.ID, err)
+		}
+		ranges = append(ranges, lockedRange)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate locked ranges: %w", err)
+	}
+	return ranges, nil
+}
+
+// startOfDay returns the day of date as parsed from the database, midnight UTC
+func startOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
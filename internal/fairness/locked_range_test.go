@@ -0,0 +1,45 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockedRanges(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	july := time.Date(2026, time.July, 4, 0, 0, 0, 0, time.UTC)
+	august := time.Date(2026, time.August, 31, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2026, time.December, 19, 0, 0, 0, 0, time.UTC)
+
+	_, err = tracker.LockRange(t.Context(), august, july)
+	assert.ErrorIs(t, err, ErrInvalidLockedRange)
+
+	winter, err := tracker.LockRange(t.Context(), december, december.AddDate(0, 0, 15))
+	require.NoError(t, err)
+	summer, err := tracker.LockRange(t.Context(), july, august)
+	require.NoError(t, err)
+	assert.Equal(t, LockedRange{ID: summer.ID, Start: july, End: august}, *summer)
+
+	ranges, err := tracker.GetLockedRanges(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, LockedRanges{*summer, *winter}, ranges, "ordered by start")
+
+	assert.True(t, ranges.Contains(july), "first day included")
+	assert.True(t, ranges.Contains(time.Date(2026, time.August, 31, 21, 30, 0, 0, time.UTC)), "last day included")
+	assert.True(t, ranges.Contains(time.Date(2027, time.January, 3, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, ranges.Contains(time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)))
+
+	require.NoError(t, tracker.UnlockRange(t.Context(), summer.ID))
+	assert.ErrorIs(t, tracker.UnlockRange(t.Context(), summer.ID), ErrLockedRangeNotFound)
+	ranges, err = tracker.GetLockedRanges(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, LockedRanges{*winter}, ranges)
+}
@@ -0,0 +1,93 @@
+package fairness
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/signals"
+)
+
+// ErrInvalidSwap is returned when the nights of a swap cannot be exchanged: the same night twice,
+// or the same parent on both sides
+var ErrInvalidSwap = errors.New("invalid swap")
+
+// NightSwap exchanges the night of Date, held by Parent, with the night of OtherDate, held by
+// OtherParent: once applied, OtherParent has Date and Parent has OtherDate
+type NightSwap struct {
+	Date        time.Time
+	Parent      string
+	OtherDate   time.Time
+	OtherParent string
+}
+
+// SwapNights applies swap in a single transaction, both nights becoming overrides with
+// DecisionReasonSwap and losing their tag. Returns ErrInvalidSwap for a swap of a night with itself
+// or between the same parent, ErrAssignmentNotScheduled when a night has no assignment and
+// ErrAssignmentConflict when a night is no longer held by the parent of the swap as a parent night.
+// Both changes are journaled as one batch of ChangeKindSwap. The assignments are returned in the
+// order of the dates of swap.
+func (t *Tracker) SwapNights(ctx context.Context, swap NightSwap) (*Assignment, *Assignment, error) {
+	date, otherDate := swap.Date.Format(dateFormat), swap.OtherDate.Format(dateFormat)
+	swapLogger := t.logger.With().
+		Str("date", date).Str("parent", swap.Parent).
+		Str("other_date", otherDate).Str("other_parent", swap.OtherParent).
+		Logger()
+	swapLogger.Debug().Msg("Swapping nights")
+
+	if date == otherDate || swap.Parent == swap.OtherParent {
+		return nil, nil, fmt.Errorf("%w: %s of %s with %s of %s", ErrInvalidSwap, date, swap.Parent, otherDate, swap.OtherParent)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	sides := []struct {
+		date, holder, taker string
+	}{
+		{date, swap.Parent, swap.OtherParent},
+		{otherDate, swap.OtherParent, swap.Parent},
+	}
+	var previous, swapped [2]*Assignment
+	err := t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		for i, side := range sides {
+			a, err := t.scanAssignment(tx.QueryRowContext(ctx, selectAssignmentByDateSQL, t.childID, side.date))
+			if err != nil {
+				return fmt.Errorf("failed to get assignment of %s: %w", side.date, err)
+			}
+			if a == nil {
+				return fmt.Errorf("%s: %w", side.date, ErrAssignmentNotScheduled)
+			}
+			if a.Parent != side.holder || a.CaregiverType != CaregiverTypeParent {
+				return fmt.Errorf("night of %s is no longer held by %s: %w", side.date, side.holder, ErrAssignmentConflict)
+			}
+			if _, err := tx.ExecContext(ctx, `
+			UPDATE assignments SET parent_name = ?, override = 1, decision_reason = ?, tag = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?`, side.taker, DecisionReasonSwap.String(), a.ID); err != nil {
+				return fmt.Errorf("failed to update assignment of %s: %w", side.date, err)
+			}
+			updated, err := t.scanAssignment(tx.QueryRowContext(ctx, selectAssignmentByDateSQL, t.childID, side.date))
+			if err != nil {
+				return fmt.Errorf("failed to read back assignment of %s: %w", side.date, err)
+			}
+			previous[i], swapped[i] = a, updated
+		}
+		return nil
+	})
+	if err != nil {
+		swapLogger.Warn().Err(err).Msg("Failed to swap nights, none was changed")
+		return nil, nil, fmt.Errorf("failed to swap nights: %w", err)
+	}
+
+	t.changed(time.Time{})
+	// Both sides of the swap are undone together
+	ctx = t.BeginBatch(ctx, ChangeKindSwap)
+	for i, after := range swapped {
+		t.journalChange(ctx, ChangeKindSwap, previous[i], stateOf(after))
+		signals.EmitAssignmentOverridden(context.Background(), after.ID, after.Parent, after.CaregiverType.String())
+	}
+	swapLogger.Info().Int64("assignment_id", swapped[0].ID).Int64("other_assignment_id", swapped[1].ID).Msg("Nights swapped")
+	return swapped[0], swapped[1], nil
+}
@@ -0,0 +1,81 @@
+package fairness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapNights(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 3)
+	first, err := tracker.RecordAssignment(t.Context(), "Alice", day1, true, DecisionReasonOverride)
+	require.NoError(t, err)
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), first.ID, AssignmentTagSickKid))
+	second, err := tracker.RecordAssignment(t.Context(), "Bob", day2, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	swappedA, swappedB, err := tracker.SwapNights(t.Context(), NightSwap{Date: day1, Parent: "Alice", OtherDate: day2, OtherParent: "Bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", swappedA.Parent)
+	assert.Equal(t, "Alice", swappedB.Parent)
+	for _, swapped := range []*Assignment{swappedA, swappedB} {
+		assert.True(t, swapped.Override, "a swapped night is kept by the regeneration")
+		assert.Equal(t, DecisionReasonSwap, swapped.DecisionReason)
+		assert.Equal(t, AssignmentTagNone, swapped.Tag)
+	}
+	assert.Greater(t, swappedB.Version, second.Version)
+
+	// Both sides are undone together
+	batch, err := tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, ChangeKindSwap, batch.Kind)
+	assert.Len(t, batch.Assignments, 2)
+	restored, err := tracker.GetAssignmentByID(t.Context(), second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", restored.Parent)
+	assert.False(t, restored.Override)
+}
+
+func TestSwapNights_AllOrNone(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	first, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", day2, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		swap NightSwap
+		err  error
+	}{
+		{"Same night", NightSwap{Date: day1, Parent: "Alice", OtherDate: day1, OtherParent: "Bob"}, ErrInvalidSwap},
+		{"Same parent", NightSwap{Date: day1, Parent: "Alice", OtherDate: day2, OtherParent: "Alice"}, ErrInvalidSwap},
+		{"Night not scheduled", NightSwap{Date: day1, Parent: "Alice", OtherDate: day2.AddDate(0, 0, 1), OtherParent: "Bob"}, ErrAssignmentNotScheduled},
+		{"Night changed since the proposal", NightSwap{Date: day1, Parent: "Alice", OtherDate: day2, OtherParent: "Carol"}, ErrAssignmentConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tracker.SwapNights(t.Context(), tt.swap)
+			require.ErrorIs(t, err, tt.err)
+
+			unchanged, err := tracker.GetAssignmentByID(t.Context(), first.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Alice", unchanged.Parent, "neither night is swapped")
+			assert.Equal(t, first.Version, unchanged.Version)
+		})
+	}
+}
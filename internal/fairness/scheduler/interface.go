@@ -1,28 +1,31 @@
 package scheduler
 
 import (
+	"context"
 	"time"
 )
 
 // SchedulerInterface defines the interface for the night routine scheduler
 type SchedulerInterface interface {
 	// GenerateSchedule creates a schedule for the specified date range
-	GenerateSchedule(start, end time.Time, currentTime time.Time) ([]*Assignment, error)
+	GenerateSchedule(ctx context.Context, start, end time.Time, currentTime time.Time) ([]*Assignment, error)
 
 	// GetAssignmentsInRange retrieves existing assignments in a date range without generating new ones
-	GetAssignmentsInRange(start, end time.Time) ([]*Assignment, error)
+	GetAssignmentsInRange(ctx context.Context, start, end time.Time) ([]*Assignment, error)
 
 	// UpdateGoogleCalendarEventID updates the assignment with the Google Calendar event ID
-	UpdateGoogleCalendarEventID(assignment *Assignment, eventID string) error
+	UpdateGoogleCalendarEventID(ctx context.Context, assignment *Assignment, eventID string) error
 
 	// GetAssignmentByGoogleCalendarEventID finds an assignment by its Google Calendar event ID
-	GetAssignmentByGoogleCalendarEventID(eventID string) (*Assignment, error)
+	GetAssignmentByGoogleCalendarEventID(ctx context.Context, eventID string) (*Assignment, error)
 
-	// UpdateAssignmentParent updates the parent for an assignment and sets the override flag
-	UpdateAssignmentParent(id int64, parent string, override bool) error
+	// UpdateAssignmentParent updates the parent for an assignment and sets the override flag,
+	// while the assignment is still at version
+	UpdateAssignmentParent(ctx context.Context, id int64, parent string, override bool, version int64) error
 
-	// UpdateAssignmentToBabysitter updates the assignment to a babysitter and sets the override flag.
-	UpdateAssignmentToBabysitter(id int64, babysitterName string, override bool) error
+	// UpdateAssignmentToBabysitter updates the assignment to a babysitter and sets the override flag,
+	// while the assignment is still at version
+	UpdateAssignmentToBabysitter(ctx context.Context, id int64, babysitterName string, override bool, version int64) error
 }
 
 // Ensure Scheduler implements SchedulerInterface
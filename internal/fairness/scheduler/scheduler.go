@@ -1,11 +1,13 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"time"
 
 	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/dates"
 	"github.com/belphemur/night-routine/internal/fairness"
 	"github.com/belphemur/night-routine/internal/logging"
 	"github.com/rs/zerolog"
@@ -18,6 +20,7 @@ const (
 	ParentTypeA ParentType = iota
 	ParentTypeB
 	ParentTypeBabysitter
+	ParentTypeOther // An extra parent of the roster, after parent A and parent B
 )
 
 // String returns the string representation of the ParentType
@@ -29,6 +32,8 @@ func (p ParentType) String() string {
 		return "ParentB"
 	case ParentTypeBabysitter:
 		return "Babysitter"
+	case ParentTypeOther:
+		return "OtherParent"
 	default:
 		return "Unknown"
 	}
@@ -44,16 +49,56 @@ type Assignment struct {
 	Override              bool
 	GoogleCalendarEventID string
 	DecisionReason        fairness.DecisionReason
+	Version               int64 // Precondition of the updates, see fairness.ErrAssignmentConflict
+	Tag                   fairness.AssignmentTag
 	UpdatedAt             time.Time
+	Child                 string // Child whose rotation the night belongs to, empty for the main rotation
+}
+
+// parentAvailability holds when a parent of the roster cannot take a night
+type parentAvailability struct {
+	days     []string                    // Unavailable days of the week
+	rules    []config.UnavailabilityRule // Recurring unavailability rules
+	busyDays map[string]bool             // Days unavailable from the personal calendar
 }
 
 // scheduleConfig holds configuration resolved once per GenerateSchedule call
 // to avoid repeated config store queries for every day in the range.
 type scheduleConfig struct {
-	parentA            string
-	parentB            string
-	parentAUnavailable []string
-	parentBUnavailable []string
+	parents      []string                      // Roster: parent A, parent B, then the extra parents
+	availability map[string]parentAvailability // Keyed by parent name
+	minRestDays  int                           // Nights off a parent gets at least after a block of consecutive nights
+	dayWeights   config.DayWeights             // Weight of the nights in the fairness totals by day of the week
+	vacation     config.Vacation
+	skipDates    config.SkipDates
+}
+
+// isDayOff reports whether date gets no night routine, being a day of the family vacation or a
+// skip date
+func (c *scheduleConfig) isDayOff(date time.Time) bool {
+	return c.vacation.Contains(date) || c.skipDates.Contains(date)
+}
+
+// isUnavailable reports whether parent is unavailable on date, from its unavailable days of the
+// week, one of its recurring unavailability rules or its personal calendar
+func (c *scheduleConfig) isUnavailable(parent string, date time.Time) bool {
+	availability := c.availability[parent]
+	if contains(availability.days, date.Format("Monday")) || availability.busyDays[date.Format("2006-01-02")] {
+		return true
+	}
+	return slices.ContainsFunc(availability.rules, func(rule config.UnavailabilityRule) bool {
+		return rule.Matches(date)
+	})
+}
+
+// parentA returns the name of parent A, the first parent of the roster
+func (c *scheduleConfig) parentA() string {
+	return c.parents[0]
+}
+
+// parentB returns the name of parent B, the second parent of the roster
+func (c *scheduleConfig) parentB() string {
+	return c.parents[1]
 }
 
 // Scheduler handles the night routine scheduling logic
@@ -61,6 +106,7 @@ type Scheduler struct {
 	configStore config.ConfigStoreInterface
 	tracker     fairness.TrackerInterface
 	logger      zerolog.Logger
+	child       string // Child whose rotation is scheduled, empty for the main rotation
 }
 
 // New creates a new Scheduler instance
@@ -77,33 +123,117 @@ func (s *Scheduler) getParents() (parentA, parentB string, err error) {
 	return s.configStore.GetParents()
 }
 
-// resolveScheduleConfig fetches parents and availability once from the config
-// store so that the per-day assignment loop does not repeat those queries.
-func (s *Scheduler) resolveScheduleConfig() (*scheduleConfig, error) {
-	parentA, parentB, err := s.configStore.GetParents()
+// resolveScheduleConfig fetches the roster and the availability of each parent once from the
+// config store, and the days from start to end the parents are unavailable from their calendars,
+// so that the per-day assignment loop does not repeat those queries.
+func (s *Scheduler) resolveScheduleConfig(ctx context.Context, start, end time.Time) (*scheduleConfig, error) {
+	parents, err := config.Roster(s.configStore)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parent names: %w", err)
 	}
-	parentADays, err := s.configStore.GetAvailability("parent_a")
+	availability := make(map[string]parentAvailability, len(parents))
+	for i, parent := range parents {
+		key := config.ParentKey(i)
+		days, err := s.configStore.GetAvailability(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s availability: %w", key, err)
+		}
+		rules, err := s.configStore.GetUnavailabilityRules(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s unavailability rules: %w", key, err)
+		}
+		busyDays, err := s.getCalendarUnavailability(ctx, key, start, end)
+		if err != nil {
+			return nil, err
+		}
+		availability[parent] = parentAvailability{days: days, rules: rules, busyDays: busyDays}
+	}
+	vacation, err := s.configStore.GetVacation()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vacation: %w", err)
+	}
+	skipDates, err := s.configStore.GetSkipDates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skip dates: %w", err)
+	}
+	minRestDays, err := s.configStore.GetMinRestDays()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get parent_a availability: %w", err)
+		return nil, fmt.Errorf("failed to get minimum rest days: %w", err)
 	}
-	parentBDays, err := s.configStore.GetAvailability("parent_b")
+	dayWeights, err := s.configStore.GetDayWeights()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get parent_b availability: %w", err)
+		return nil, fmt.Errorf("failed to get day weights: %w", err)
 	}
 	return &scheduleConfig{
-		parentA:            parentA,
-		parentB:            parentB,
-		parentAUnavailable: parentADays,
-		parentBUnavailable: parentBDays,
+		parents:      parents,
+		availability: availability,
+		minRestDays:  minRestDays,
+		dayWeights:   dayWeights,
+		vacation:     vacation,
+		skipDates:    skipDates,
 	}, nil
 }
 
+// getCalendarUnavailability returns the days from start to end the parent of key (see config.ParentKey) is
+// unavailable from its personal calendar, as a set of YYYY-MM-DD
+func (s *Scheduler) getCalendarUnavailability(ctx context.Context, parent string, start, end time.Time) (map[string]bool, error) {
+	dates, err := s.tracker.GetCalendarUnavailability(ctx, parent, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s calendar unavailability: %w", parent, err)
+	}
+	days := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		days[date.Format("2006-01-02")] = true
+	}
+	return days, nil
+}
+
 // GenerateSchedule creates a schedule for the specified date range, considering a current time.
-// Assignments that are overridden or occurred before/on currentTime are considered fixed.
+// Assignments that are overridden, within a locked range or occurred before/on currentTime are considered fixed.
 // When an override exists on or after the current day, all non-override days after that override are recalculated.
-func (s *Scheduler) GenerateSchedule(start, end time.Time, currentTime time.Time) ([]*Assignment, error) {
+// Days of the family vacation and skip dates get no assignment: the assignments recorded on them
+// from the current day on are deleted, locked ones included, earlier ones already happened and are kept.
+// The rotation of each child is generated the same way after the main rotation, its assignments
+// following the ones of the main rotation in the schedule.
+// The changes are a regeneration batch, undone together, unless they join a batch already open.
+func (s *Scheduler) GenerateSchedule(ctx context.Context, start, end time.Time, currentTime time.Time) ([]*Assignment, error) {
+	ctx = s.tracker.BeginBatch(ctx, fairness.ChangeKindRegeneration)
+
+	schedule, err := s.generateRotation(ctx, start, end, currentTime)
+	if err != nil {
+		return nil, err
+	}
+	if s.child != "" {
+		return schedule, nil
+	}
+
+	children, err := s.tracker.GetChildren(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get children")
+		return nil, fmt.Errorf("failed to get children: %w", err)
+	}
+	for _, child := range children {
+		childSchedule, err := s.forChild(child).generateRotation(ctx, start, end, currentTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate the schedule of %s: %w", child.Name, err)
+		}
+		schedule = append(schedule, childSchedule...)
+	}
+	return schedule, nil
+}
+
+// forChild returns a scheduler of the rotation of child
+func (s *Scheduler) forChild(child fairness.Child) *Scheduler {
+	return &Scheduler{
+		configStore: s.configStore,
+		tracker:     s.tracker.ForChild(child),
+		logger:      s.logger.With().Str("child", child.Name).Logger(),
+		child:       child.Name,
+	}
+}
+
+// generateRotation generates the schedule of the rotation of s, see GenerateSchedule
+func (s *Scheduler) generateRotation(ctx context.Context, start, end time.Time, currentTime time.Time) ([]*Assignment, error) {
 	genLogger := s.logger.With().
 		Time("start_date", start).
 		Time("end_date", end).
@@ -113,30 +243,55 @@ func (s *Scheduler) GenerateSchedule(start, end time.Time, currentTime time.Time
 
 	// Resolve config once for the entire schedule generation to avoid
 	// repeated config store queries for every day in the range.
-	cfg, err := s.resolveScheduleConfig()
+	cfg, err := s.resolveScheduleConfig(ctx, start, end)
 	if err != nil {
 		genLogger.Error().Err(err).Msg("Failed to resolve schedule config")
 		return nil, fmt.Errorf("failed to resolve schedule config: %w", err)
 	}
-	parentA := cfg.parentA
+	parentA, parentB := cfg.parentA(), cfg.parentB()
 
 	var schedule []*Assignment
-	current := start
+	current := dates.Day(start)
 
 	// Get all existing assignments in the date range
 	genLogger.Debug().Msg("Fetching all existing assignments in range")
-	existingAssignments, err := s.tracker.GetAssignmentsInRange(start, end)
+	existingAssignments, err := s.tracker.GetAssignmentsInRange(ctx, start, end)
 	if err != nil {
 		genLogger.Error().Err(err).Msg("Failed to get existing assignments")
 		return nil, fmt.Errorf("failed to get existing assignments: %w", err)
 	}
 	genLogger.Debug().Int("count", len(existingAssignments)).Msg("Fetched existing assignments")
 
+	lockedRanges, err := s.tracker.GetLockedRanges(ctx)
+	if err != nil {
+		genLogger.Error().Err(err).Msg("Failed to get locked ranges")
+		return nil, fmt.Errorf("failed to get locked ranges: %w", err)
+	}
+
 	// Use the local date string of currentTime for "today" comparisons.
 	// time.Truncate(24h) truncates to UTC midnight which is wrong for servers in non-UTC
 	// timezones: a server in UTC-4 at 20:00 local = 00:00 UTC next day, making
 	// Truncate identify tomorrow as "today".  Date strings (formatted in the time's
 	// own location) are always consistent with the DB which stores local date strings.
+	currentDayStr := currentTime.Format("2006-01-02")
+
+	// Clear the vacation days and skip dates that did not happen yet, so that neither their
+	// events nor their overrides outlive them
+	if cfg.vacation.Enabled || len(cfg.skipDates) > 0 {
+		kept := existingAssignments[:0]
+		for _, a := range existingAssignments {
+			if !cfg.isDayOff(a.Date) || a.Date.Format("2006-01-02") < currentDayStr {
+				kept = append(kept, a)
+				continue
+			}
+			genLogger.Info().Int64("assignment_id", a.ID).Str("date", a.Date.Format("2006-01-02")).Msg("Deleting assignment of a day without night routine")
+			if err := s.tracker.DeleteAssignment(ctx, a.ID); err != nil {
+				genLogger.Error().Err(err).Int64("assignment_id", a.ID).Msg("Failed to delete assignment of a day without night routine")
+				return nil, fmt.Errorf("failed to delete assignment of %s without night routine: %w", a.Date.Format("2006-01-02"), err)
+			}
+		}
+		existingAssignments = kept
+	}
 
 	// First pass: find the earliest override in the range.
 	// Days after this override that are on or after currentDay need recalculation.
@@ -159,18 +314,18 @@ func (s *Scheduler) GenerateSchedule(start, end time.Time, currentTime time.Time
 	// Fixed assignments are:
 	// 1. Assignments strictly before today AND strictly before the start date (truly past)
 	// 2. Override assignments (always fixed - user explicitly set them)
+	// 3. Assignments within a locked range (always fixed - the user settled them)
 	// NOT fixed (will be recalculated):
 	// - Non-override assignments at the start date (the caller explicitly requested recalculation from here)
 	// - Non-override assignments on or after currentDay that are after an override
 	startDayStr := start.Format("2006-01-02")
-	currentDayStr := currentTime.Format("2006-01-02")
 	assignmentFixedInTime := make(map[string]*fairness.Assignment)
 	fixedCount := 0
 	for _, a := range existingAssignments {
 		assignmentDayStr := a.Date.Format("2006-01-02")
 
-		// Overrides are always fixed
-		if a.Override {
+		// Overrides and locked assignments are always fixed
+		if a.Override || lockedRanges.Contains(a.Date) {
 			assignmentFixedInTime[assignmentDayStr] = a
 			fixedCount++
 			continue
@@ -204,28 +359,41 @@ func (s *Scheduler) GenerateSchedule(start, end time.Time, currentTime time.Time
 		}
 		// Future assignments (not override, not past, not today): recalculate
 	}
-	genLogger.Debug().Int("fixed_count", fixedCount).Msg("Mapped fixed assignments (overridden or past)")
+	genLogger.Debug().Int("fixed_count", fixedCount).Msg("Mapped fixed assignments (overridden, locked or past)")
 
 	// Process each day in the range
 	genLogger.Debug().Msg("Processing days in range")
 	dcTracker := newDoubleConsecutiveTracker(genLogger)
-	for !current.After(end) {
+	for dates.DaysBetween(current, end) >= 0 {
+		// Stop between days once the caller gave up; the days already recorded are kept
+		if err := ctx.Err(); err != nil {
+			genLogger.Warn().Err(err).Str("date", current.Format("2006-01-02")).Msg("Schedule generation cancelled")
+			return nil, fmt.Errorf("schedule generation cancelled: %w", err)
+		}
 		dateStr := current.Format("2006-01-02")
 		dayLogger := genLogger.With().Str("date", dateStr).Logger()
 
-		// Check if there's a fixed assignment (overridden, past, or before override) for this date
+		// Check if there's a fixed assignment (overridden, locked, past, or before override) for this date
 		if fixedAssignment, ok := assignmentFixedInTime[dateStr]; ok {
 			dayLogger.Info().Int64("assignment_id", fixedAssignment.ID).Str("parent", fixedAssignment.Parent).Str("reason", string(fixedAssignment.DecisionReason)).Bool("override", fixedAssignment.Override).Msg("Using fixed assignment")
-			assignment := convertTrackerAssignment(fixedAssignment, parentA)
+			assignment := convertTrackerAssignment(fixedAssignment, parentA, parentB)
 			schedule = append(schedule, assignment)
-			// Fixed assignments are immutable (past/override) and cannot
+			// Fixed assignments are immutable (past/override/locked) and cannot
 			// participate in swaps — reset the consecutive tracker so no
 			// pattern detection spans across a fixed boundary.
 			dcTracker.reset()
+		} else if cfg.vacation.Contains(current) {
+			// Nobody is home: no assignment, and the fairness counters stay as they were
+			dayLogger.Debug().Msg("Vacation day, no assignment")
+			dcTracker.reset()
+		} else if cfg.skipDates.Contains(current) {
+			// Same as a vacation day: the kid sleeps elsewhere
+			dayLogger.Debug().Msg("Skip date, no assignment")
+			dcTracker.reset()
 		} else {
 			dayLogger.Debug().Msg("No fixed assignment found for this date, assigning parent")
 			// No fixed assignment, determine assignment based on fairness rules
-			assignment, err := s.assignForDate(current, cfg)
+			assignment, err := s.assignForDate(ctx, current, cfg)
 			if err != nil {
 				dayLogger.Error().Err(err).Msg("Failed to assign parent for date")
 				// Wrap error with date context
@@ -234,42 +402,41 @@ func (s *Scheduler) GenerateSchedule(start, end time.Time, currentTime time.Time
 			dayLogger.Info().Int64("assignment_id", assignment.ID).Str("parent", assignment.Parent).Msg("Assigned parent for date")
 			schedule = append(schedule, assignment)
 			// Detect and swap double consecutive patterns inline.
-			if err := dcTracker.observe(schedule, len(schedule)-1, cfg, s.tracker); err != nil {
+			if err := dcTracker.observe(ctx, schedule, len(schedule)-1, cfg, s.tracker); err != nil {
 				dayLogger.Error().Err(err).Msg("Failed to swap double consecutive assignments")
 				return nil, fmt.Errorf("failed to swap double consecutive for date %v: %w", current.Format("2006-01-02"), err)
 			}
 		}
 
-		current = current.AddDate(0, 0, 1)
+		current = dates.AddDays(current, 1)
 	}
 
+	for _, a := range schedule {
+		a.Child = s.child
+	}
 	genLogger.Info().Int("total_assignments", len(schedule)).Msg("Schedule generation complete")
 
 	return schedule, nil
 }
 
 // isSwappable returns true when an assignment can participate in double-consecutive
-// smoothing. Overrides, unavailability, and babysitter assignments are excluded
-// because they represent user intent or hard constraints that must not be moved.
+// smoothing. Overrides, swapped nights, unavailability, rest days and babysitter assignments are
+// excluded because they represent user intent or hard constraints that must not be moved.
 func isSwappable(a *Assignment) bool {
 	if a.CaregiverType == fairness.CaregiverTypeBabysitter {
 		return false
 	}
 	switch a.DecisionReason {
-	case fairness.DecisionReasonOverride, fairness.DecisionReasonUnavailability:
+	case fairness.DecisionReasonOverride, fairness.DecisionReasonSwap, fairness.DecisionReasonUnavailability, fairness.DecisionReasonRestDays:
 		return false
 	}
 	return true
 }
 
 // isParentAvailableOnDate checks whether a parent can be assigned on the given date
-// based on day-of-week unavailability constraints from the schedule config.
+// based on the day-of-week and recurring unavailability constraints from the schedule config.
 func isParentAvailableOnDate(parent string, date time.Time, cfg *scheduleConfig) bool {
-	dayOfWeek := date.Format("Monday")
-	if parent == cfg.parentA {
-		return !contains(cfg.parentAUnavailable, dayOfWeek)
-	}
-	return !contains(cfg.parentBUnavailable, dayOfWeek)
+	return !cfg.isUnavailable(parent, date)
 }
 
 // consecutiveRun describes a contiguous run of the same parent in the schedule
@@ -313,6 +480,7 @@ func (d *doubleConsecutiveTracker) reset() {
 //
 // Returns an error if the DB upserts fail during a swap.
 func (d *doubleConsecutiveTracker) observe(
+	ctx context.Context,
 	schedule []*Assignment,
 	i int,
 	cfg *scheduleConfig,
@@ -387,6 +555,7 @@ func (d *doubleConsecutiveTracker) observe(
 	// Atomically swap both assignments in a single transaction.
 	// In-memory state is only updated after the transaction commits.
 	updatedA, updatedB, err := tracker.SwapAssignments(
+		ctx,
 		parentForA, schedule[swapA].Date,
 		parentForB, schedule[swapB].Date,
 		fairness.DecisionReasonDoubleConsecutiveSwap,
@@ -401,13 +570,13 @@ func (d *doubleConsecutiveTracker) observe(
 	schedule[swapA].ID = updatedA.ID
 	schedule[swapA].Parent = updatedA.Parent
 	schedule[swapA].DecisionReason = updatedA.DecisionReason
-	schedule[swapA].ParentType = resolveParentType(updatedA, cfg.parentA)
+	schedule[swapA].ParentType = resolveParentType(updatedA, cfg.parentA(), cfg.parentB())
 	schedule[swapA].UpdatedAt = updatedA.UpdatedAt
 
 	schedule[swapB].ID = updatedB.ID
 	schedule[swapB].Parent = updatedB.Parent
 	schedule[swapB].DecisionReason = updatedB.DecisionReason
-	schedule[swapB].ParentType = resolveParentType(updatedB, cfg.parentA)
+	schedule[swapB].ParentType = resolveParentType(updatedB, cfg.parentA(), cfg.parentB())
 	schedule[swapB].UpdatedAt = updatedB.UpdatedAt
 
 	// Reset tracking after a successful swap.
@@ -417,20 +586,17 @@ func (d *doubleConsecutiveTracker) observe(
 
 // assignForDate determines who should do the night routine on a specific date and records it.
 // It uses the pre-resolved scheduleConfig to avoid repeated config store queries.
-func (s *Scheduler) assignForDate(date time.Time, cfg *scheduleConfig) (*Assignment, error) {
+func (s *Scheduler) assignForDate(ctx context.Context, date time.Time, cfg *scheduleConfig) (*Assignment, error) {
 	assignLogger := s.logger.With().Str("date", date.Format("2006-01-02")).Logger()
 	assignLogger.Debug().Msg("Assigning parent for date")
 
-	parentAName := cfg.parentA
-	parentBName := cfg.parentB
-
 	// Fetch the last 7 assignments of all caregiver types. This single list is
 	// used for everything: parent-only entries are derived via parentOnly() for
 	// streaks and lastParent; the full list detects babysitter gaps and recent
 	// unavailability. Fetching 7 ensures enough parent entries even when
 	// babysitter nights are interspersed.
 	assignLogger.Debug().Msg("Fetching last assignments")
-	lastAssignments, err := s.tracker.GetLastAssignmentsUntil(7, date)
+	lastAssignments, err := s.tracker.GetLastAssignmentsUntil(ctx, 7, date)
 	if err != nil {
 		assignLogger.Error().Err(err).Msg("Failed to get last assignments")
 		return nil, fmt.Errorf("failed to get last assignments: %w", err)
@@ -439,7 +605,7 @@ func (s *Scheduler) assignForDate(date time.Time, cfg *scheduleConfig) (*Assignm
 
 	// Get parent stats for balanced distribution up to the given date
 	assignLogger.Debug().Msg("Fetching parent stats")
-	stats, err := s.tracker.GetParentStatsUntil(date, parentAName, parentBName)
+	stats, err := s.tracker.GetWeightedParentStatsUntil(ctx, date, cfg.dayWeights, cfg.parents...)
 	if err != nil {
 		assignLogger.Error().Err(err).Msg("Failed to get parent stats")
 		return nil, fmt.Errorf("failed to get parent stats: %w", err)
@@ -457,7 +623,7 @@ func (s *Scheduler) assignForDate(date time.Time, cfg *scheduleConfig) (*Assignm
 
 	// Record the assignment in the database
 	assignLogger.Debug().Msg("Recording assignment in tracker")
-	trackerAssignment, err := s.tracker.RecordAssignment(parent, date, false, decisionReason)
+	trackerAssignment, err := s.tracker.RecordAssignment(ctx, parent, date, false, decisionReason)
 	if err != nil {
 		assignLogger.Error().Err(err).Msg("Failed to record assignment")
 		return nil, fmt.Errorf("failed to record assignment: %w", err)
@@ -467,10 +633,13 @@ func (s *Scheduler) assignForDate(date time.Time, cfg *scheduleConfig) (*Assignm
 	// Save assignment details for non-override decisions
 	if trackerAssignment.CaregiverType != fairness.CaregiverTypeBabysitter && decisionReason != fairness.DecisionReasonOverride {
 		assignLogger.Debug().Msg("Saving assignment details")
-		statsA := stats[parentAName]
-		statsB := stats[parentBName]
+		parentAName, parentBName := cfg.parentA(), cfg.parentB()
+		extra := make([]fairness.NamedStats, 0, len(cfg.parents)-2)
+		for _, name := range cfg.parents[2:] {
+			extra = append(extra, fairness.NamedStats{Name: name, Stats: stats[name]})
+		}
 
-		err = s.tracker.SaveAssignmentDetails(trackerAssignment.ID, date, parentAName, statsA, parentBName, statsB)
+		err = s.tracker.SaveAssignmentDetails(ctx, trackerAssignment.ID, date, parentAName, stats[parentAName], parentBName, stats[parentBName], extra...)
 		if err != nil {
 			// Log error but don't fail the assignment
 			assignLogger.Error().Err(err).Msg("Failed to save assignment details")
@@ -479,11 +648,11 @@ func (s *Scheduler) assignForDate(date time.Time, cfg *scheduleConfig) (*Assignm
 		}
 	}
 
-	return convertTrackerAssignment(trackerAssignment, parentAName), nil
+	return convertTrackerAssignment(trackerAssignment, cfg.parentA(), cfg.parentB()), nil
 }
 
 // UpdateGoogleCalendarEventID updates the assignment with the Google Calendar event ID
-func (s *Scheduler) UpdateGoogleCalendarEventID(assignment *Assignment, eventID string) error {
+func (s *Scheduler) UpdateGoogleCalendarEventID(ctx context.Context, assignment *Assignment, eventID string) error {
 	updateLogger := s.logger.With().
 		Int64("assignment_id", assignment.ID).
 		Str("date", assignment.Date.Format("2006-01-02")).
@@ -492,7 +661,7 @@ func (s *Scheduler) UpdateGoogleCalendarEventID(assignment *Assignment, eventID
 		Logger()
 	updateLogger.Info().Msg("Updating assignment with Google Calendar Event ID")
 
-	err := s.tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, eventID)
+	err := s.tracker.UpdateAssignmentGoogleCalendarEventID(ctx, assignment.ID, eventID)
 	if err != nil {
 		updateLogger.Error().Err(err).Msg("Failed to update assignment event ID in tracker")
 		return fmt.Errorf("failed to update assignment with Google Calendar event ID: %w", err)
@@ -505,11 +674,11 @@ func (s *Scheduler) UpdateGoogleCalendarEventID(assignment *Assignment, eventID
 }
 
 // GetAssignmentByGoogleCalendarEventID finds an assignment by its Google Calendar event ID
-func (s *Scheduler) GetAssignmentByGoogleCalendarEventID(eventID string) (*Assignment, error) {
+func (s *Scheduler) GetAssignmentByGoogleCalendarEventID(ctx context.Context, eventID string) (*Assignment, error) {
 	getLogger := s.logger.With().Str("event_id", eventID).Logger()
 	getLogger.Debug().Msg("Getting assignment by Google Calendar Event ID")
 
-	assignment, err := s.tracker.GetAssignmentByGoogleCalendarEventID(eventID)
+	assignment, err := s.tracker.GetAssignmentByGoogleCalendarEventID(ctx, eventID)
 	if err != nil {
 		getLogger.Error().Err(err).Msg("Failed to get assignment by event ID from tracker")
 		return nil, fmt.Errorf("failed to get assignment by Google Calendar event ID: %w", err)
@@ -521,25 +690,27 @@ func (s *Scheduler) GetAssignmentByGoogleCalendarEventID(eventID string) (*Assig
 	}
 
 	getLogger.Info().Int64("assignment_id", assignment.ID).Msg("Found assignment by event ID")
-	parentA, _, err := s.getParents()
+	parentA, parentB, err := s.getParents()
 	if err != nil {
 		getLogger.Error().Err(err).Msg("Failed to get parent names")
 		return nil, fmt.Errorf("failed to get parent names: %w", err)
 	}
-	return convertTrackerAssignment(assignment, parentA), nil
+	return convertTrackerAssignment(assignment, parentA, parentB), nil
 }
 
 // UpdateAssignmentParent updates the parent for an assignment and sets the override flag
-// When override is true, it also sets the decision reason to Override
-func (s *Scheduler) UpdateAssignmentParent(id int64, parent string, override bool) error {
+// When override is true, it also sets the decision reason to Override. The update only applies while
+// the assignment is still at version, otherwise fairness.ErrAssignmentConflict is returned.
+func (s *Scheduler) UpdateAssignmentParent(ctx context.Context, id int64, parent string, override bool, version int64) error {
 	updateLogger := s.logger.With().
 		Int64("assignment_id", id).
 		Str("new_parent", parent).
 		Bool("override", override).
+		Int64("version", version).
 		Logger()
 	updateLogger.Info().Msg("Updating assignment parent")
 
-	err := s.tracker.UpdateAssignmentParent(id, parent, override)
+	err := s.tracker.UpdateAssignmentParent(ctx, id, parent, override, version)
 	if err != nil {
 		updateLogger.Error().Err(err).Msg("Failed to update assignment parent in tracker")
 		return fmt.Errorf("failed to update assignment parent: %w", err)
@@ -549,16 +720,18 @@ func (s *Scheduler) UpdateAssignmentParent(id int64, parent string, override boo
 	return nil
 }
 
-// UpdateAssignmentToBabysitter updates an assignment to a babysitter and sets override state.
-func (s *Scheduler) UpdateAssignmentToBabysitter(id int64, babysitterName string, override bool) error {
+// UpdateAssignmentToBabysitter updates an assignment to a babysitter and sets override state, while the
+// assignment is still at version.
+func (s *Scheduler) UpdateAssignmentToBabysitter(ctx context.Context, id int64, babysitterName string, override bool, version int64) error {
 	updateLogger := s.logger.With().
 		Int64("assignment_id", id).
 		Str("babysitter_name", babysitterName).
 		Bool("override", override).
+		Int64("version", version).
 		Logger()
 	updateLogger.Info().Msg("Updating assignment to babysitter")
 
-	err := s.tracker.UpdateAssignmentToBabysitter(id, babysitterName, override)
+	err := s.tracker.UpdateAssignmentToBabysitter(ctx, id, babysitterName, override, version)
 	if err != nil {
 		updateLogger.Error().Err(err).Msg("Failed to update assignment to babysitter in tracker")
 		return fmt.Errorf("failed to update assignment to babysitter: %w", err)
@@ -568,57 +741,122 @@ func (s *Scheduler) UpdateAssignmentToBabysitter(id int64, babysitterName string
 	return nil
 }
 
+// GetVacation returns the family vacation, during which no night is scheduled
+func (s *Scheduler) GetVacation() (config.Vacation, error) {
+	vacation, err := s.configStore.GetVacation()
+	if err != nil {
+		return config.Vacation{}, fmt.Errorf("failed to get vacation: %w", err)
+	}
+	return vacation, nil
+}
+
+// GetSkipDates returns the days without night routine, on which no night is scheduled
+func (s *Scheduler) GetSkipDates() (config.SkipDates, error) {
+	skipDates, err := s.configStore.GetSkipDates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skip dates: %w", err)
+	}
+	return skipDates, nil
+}
+
+// GetSyncExclusions returns the kinds of nights kept out of Google Calendar
+func (s *Scheduler) GetSyncExclusions() (config.SyncExclusions, error) {
+	exclusions, err := s.configStore.GetSyncExclusions()
+	if err != nil {
+		return config.SyncExclusions{}, fmt.Errorf("failed to get sync exclusions: %w", err)
+	}
+	return exclusions, nil
+}
+
+// SetCalendarUnavailability replaces the days from start to end the parent of key (see config.ParentKey) is
+// unavailable from its personal calendar, used by the next generations
+func (s *Scheduler) SetCalendarUnavailability(ctx context.Context, parent string, start, end time.Time, dates []time.Time) error {
+	if err := s.tracker.ReplaceCalendarUnavailability(ctx, parent, start, end, dates); err != nil {
+		return fmt.Errorf("failed to set %s calendar unavailability: %w", parent, err)
+	}
+	return nil
+}
+
+// GetParentStyles returns the color and the avatar of each parent of the roster, keyed by the name
+// of the parent
+func (s *Scheduler) GetParentStyles() (map[string]config.ParentStyle, error) {
+	parentA, parentB, err := s.getParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent names: %w", err)
+	}
+	styleA, styleB, err := s.configStore.GetParentStyles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent styles: %w", err)
+	}
+	extra, err := s.configStore.GetExtraParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extra parents: %w", err)
+	}
+	styles := map[string]config.ParentStyle{parentA: styleA, parentB: styleB}
+	for _, parent := range extra {
+		styles[parent.Name] = parent.Style
+	}
+	return styles, nil
+}
+
 // GetAssignmentsInRange retrieves existing assignments in a date range without generating new ones.
-func (s *Scheduler) GetAssignmentsInRange(start, end time.Time) ([]*Assignment, error) {
-	raw, err := s.tracker.GetAssignmentsInRange(start, end)
+func (s *Scheduler) GetAssignmentsInRange(ctx context.Context, start, end time.Time) ([]*Assignment, error) {
+	raw, err := s.tracker.GetAssignmentsInRange(ctx, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assignments in range: %w", err)
 	}
-	parentA, _, err := s.getParents()
+	parentA, parentB, err := s.getParents()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parent names: %w", err)
 	}
-	return mapTrackerAssignments(raw, parentA), nil
+	return mapTrackerAssignments(raw, parentA, parentB), nil
 }
 
 // convertTrackerAssignment converts a fairness.Assignment to a scheduler Assignment.
 // This is the single source of truth for tracker→scheduler mapping; all call-sites
 // must use this helper to avoid field-drift when new fields are added.
-func convertTrackerAssignment(a *fairness.Assignment, parentAName string) *Assignment {
+func convertTrackerAssignment(a *fairness.Assignment, parentAName, parentBName string) *Assignment {
 	return &Assignment{
 		ID:                    a.ID,
 		Date:                  a.Date,
 		Parent:                a.Parent,
-		ParentType:            resolveParentType(a, parentAName),
+		ParentType:            resolveParentType(a, parentAName, parentBName),
 		CaregiverType:         a.CaregiverType,
 		Override:              a.Override,
 		GoogleCalendarEventID: a.GoogleCalendarEventID,
 		DecisionReason:        a.DecisionReason,
+		Version:               a.Version,
+		Tag:                   a.Tag,
 		UpdatedAt:             a.UpdatedAt,
 	}
 }
 
 // mapTrackerAssignments converts a slice of fairness.Assignment to scheduler Assignments.
-func mapTrackerAssignments(assignments []*fairness.Assignment, parentAName string) []*Assignment {
+func mapTrackerAssignments(assignments []*fairness.Assignment, parentAName, parentBName string) []*Assignment {
 	result := make([]*Assignment, len(assignments))
 	for i, a := range assignments {
-		result[i] = convertTrackerAssignment(a, parentAName)
+		result[i] = convertTrackerAssignment(a, parentAName, parentBName)
 	}
 	return result
 }
 
-func resolveParentType(a *fairness.Assignment, parentAName string) ParentType {
-	if a.CaregiverType == fairness.CaregiverTypeBabysitter {
+// resolveParentType returns who took the night of a: parent A, parent B, a babysitter, or another
+// parent of the roster
+func resolveParentType(a *fairness.Assignment, parentAName, parentBName string) ParentType {
+	switch {
+	case a.CaregiverType == fairness.CaregiverTypeBabysitter:
 		return ParentTypeBabysitter
-	}
-	if a.Parent == parentAName {
+	case a.Parent == parentAName:
 		return ParentTypeA
+	case a.Parent == parentBName:
+		return ParentTypeB
+	default:
+		return ParentTypeOther
 	}
-	return ParentTypeB
 }
 
 // determineParentForDate determines who should do the night routine on a specific date.
-// It uses the pre-resolved scheduleConfig for parent names and availability.
+// It uses the pre-resolved scheduleConfig for the roster and availability.
 // lastAssignments contains all caregiver types (parent + babysitter); parent-only
 // entries are derived internally via parentOnly() when needed for streaks/stats.
 func (s *Scheduler) determineParentForDate(date time.Time, lastAssignments []*fairness.Assignment, stats map[string]fairness.Stats, cfg *scheduleConfig) (string, fairness.DecisionReason, error) {
@@ -626,36 +864,32 @@ func (s *Scheduler) determineParentForDate(date time.Time, lastAssignments []*fa
 	determineLogger.Debug().Msg("Determining parent for date considering unavailability")
 	dayOfWeek := date.Format("Monday")
 
-	parentA := cfg.parentA
-	parentB := cfg.parentB
-
-	parentAUnavailable := contains(cfg.parentAUnavailable, dayOfWeek)
-	parentBUnavailable := contains(cfg.parentBUnavailable, dayOfWeek)
+	var available []string
+	for _, parent := range cfg.parents {
+		if !cfg.isUnavailable(parent, date) {
+			available = append(available, parent)
+		}
+	}
 	determineLogger.Debug().
 		Str("day_of_week", dayOfWeek).
-		Bool("parent_a_unavailable", parentAUnavailable).
-		Bool("parent_b_unavailable", parentBUnavailable).
+		Strs("available_parents", available).
 		Msg("Checked parent unavailability")
 
-	if parentAUnavailable && parentBUnavailable {
-		err := fmt.Errorf("both parents unavailable on %s", dayOfWeek)
+	if len(available) == 0 {
+		err := fmt.Errorf("all parents unavailable on %s %s", dayOfWeek, date.Format("2006-01-02"))
 		determineLogger.Error().Err(err).Msg("Cannot assign parent")
 		return "", "", err
 	}
 
-	// If one parent is unavailable, assign to the other
-	if parentAUnavailable {
-		determineLogger.Info().Str("assigned_parent", parentB).Msg("Parent A unavailable, assigning Parent B")
-		return parentB, fairness.DecisionReasonUnavailability, nil
-	}
-	if parentBUnavailable {
-		determineLogger.Info().Str("assigned_parent", parentA).Msg("Parent B unavailable, assigning Parent A")
-		return parentA, fairness.DecisionReasonUnavailability, nil
+	// If a single parent is available, assign to that one
+	if len(available) == 1 {
+		determineLogger.Info().Str("assigned_parent", available[0]).Msg("Other parents unavailable, assigning the only available parent")
+		return available[0], fairness.DecisionReasonUnavailability, nil
 	}
 
 	// Determine next parent based on fairness rules
-	determineLogger.Debug().Msg("Both parents available, determining next parent based on fairness")
-	parent, reason := s.determineNextParent(date, parentA, parentB, lastAssignments, stats)
+	determineLogger.Debug().Msg("Several parents available, determining next parent based on fairness")
+	parent, reason := s.determineNextParent(date, cfg.parents, available, lastAssignments, stats, cfg.minRestDays)
 	determineLogger.Info().Str("assigned_parent", parent).Str("reason", string(reason)).Msg("Determined next parent based on fairness rules")
 	return parent, reason, nil
 }
@@ -679,29 +913,94 @@ func parentOnly(assignments []*fairness.Assignment) []*fairness.Assignment {
 	return parents
 }
 
-// otherParentOf returns the other parent given the current parent.
-func otherParentOf(current, parentA, parentB string) string {
-	if current == parentA {
-		return parentB
+// nextInRotation returns the first of candidates following current in the order of the roster,
+// wrapping around; with two parents, the other parent. A current outside the roster starts from
+// the first parent.
+func nextInRotation(current string, roster, candidates []string) string {
+	start := slices.Index(roster, current) + 1
+	for i := range roster {
+		if parent := roster[(start+i)%len(roster)]; parent != current && contains(candidates, parent) {
+			return parent
+		}
 	}
-	return parentA
+	return current
 }
 
-// determineNextParent applies fairness rules to select the next parent.
+// fewestBy returns the candidates with the lowest count, in the order of candidates
+func fewestBy(candidates []string, count func(parent string) float64) []string {
+	var fewest []string
+	for _, parent := range candidates {
+		switch {
+		case len(fewest) == 0 || count(parent) < count(fewest[0]):
+			fewest = []string{parent}
+		case count(parent) == count(fewest[0]):
+			fewest = append(fewest, parent)
+		}
+	}
+	return fewest
+}
+
+// determineNextParent selects the next parent among candidates, the available parents in roster
+// order, with the fairness rules of applyFairnessRules, then enforces the rest days: when the
+// selected parent got fewer than minRestDays nights off since its last block of consecutive nights,
+// the next candidate in rotation that is not resting is assigned instead (RestDays), unless they
+// are all resting. A minRestDays of 0 enforces no rest.
+func (s *Scheduler) determineNextParent(date time.Time, roster, candidates []string, lastAssignments []*fairness.Assignment, stats map[string]fairness.Stats, minRestDays int) (string, fairness.DecisionReason) {
+	parent, reason := s.applyFairnessRules(date, roster, candidates, lastAssignments, stats)
+	if minRestDays <= 0 || !isResting(parent, date, lastAssignments, minRestDays) {
+		return parent, reason
+	}
+
+	rested := slices.DeleteFunc(slices.Clone(candidates), func(other string) bool {
+		return isResting(other, date, lastAssignments, minRestDays)
+	})
+	if len(rested) == 0 {
+		return parent, reason
+	}
+	other := nextInRotation(parent, roster, rested)
+	s.logger.Info().
+		Str("date", date.Format("2006-01-02")).
+		Str("resting_parent", parent).
+		Str("assigned_parent", other).
+		Int("min_rest_days", minRestDays).
+		Msg("Forcing switch so that the parent gets its rest days")
+	return other, fairness.DecisionReasonRestDays
+}
+
+// isResting reports whether parent ended a block of consecutive nights fewer than minRestDays nights
+// before date. A parent whose last night is the night before date is still in its block, a parent
+// without previous night has nothing to rest from. Babysitter nights count as nights off.
+func isResting(parent string, date time.Time, lastAssignments []*fairness.Assignment, minRestDays int) bool {
+	for _, a := range parentOnly(lastAssignments) {
+		if a.Parent != parent {
+			continue
+		}
+		nightsOff := dates.DaysBetween(a.Date, date) - 1
+		return nightsOff > 0 && nightsOff < minRestDays
+	}
+	return false
+}
+
+// applyFairnessRules applies fairness rules to select the next parent among candidates, the
+// available parents in roster order. Each rule narrows the candidates down until one is left.
 //
 // Decision cascade (first match wins):
-//  1. No prior parent assignments → parent with fewer (or equal) total assignments (TotalCount)
-//  2. TotalCount — parent with fewer total assignments.
-//  3. ConsecutiveLimit — when totals are tied and the same parent has 2+
-//     consecutive assignments, force a switch.
-//  4. RecentCount — parent with fewer last-30-day assignments.
-//  5. Alternating — default: alternate from the last parent.
+//  1. No prior parent assignments → first parent with the fewest total assignments (TotalCount)
+//  2. TotalCount — the only parent with the fewest total assignments.
+//  3. ConsecutiveLimit — when the last parent is tied for the fewest and has 2+
+//     consecutive assignments, force a switch to the next tied parent in rotation.
+//  4. RecentCount — the only tied parent with the fewest last-30-day assignments.
+//  5. Alternating — default: the next tied parent in rotation after the last parent.
+//
+// With two parents, the rotation alternates between them.
+// The totals and last-30-day counts are weighted by the day weights of the settings, a weighted
+// night counting for more than one.
 //
 // lastAssignments contains all caregiver types (parent + babysitter) in reverse
 // chronological order. Parent-only entries are derived via parentOnly() for
 // streak counting and lastParent detection; babysitter nights are excluded from
 // these calculations but preserved in the full list for context.
-func (s *Scheduler) determineNextParent(date time.Time, parentA, parentB string, lastAssignments []*fairness.Assignment, stats map[string]fairness.Stats) (string, fairness.DecisionReason) {
+func (s *Scheduler) applyFairnessRules(date time.Time, roster, candidates []string, lastAssignments []*fairness.Assignment, stats map[string]fairness.Stats) (string, fairness.DecisionReason) {
 	fairnessLogger := s.logger.With().Interface("stats", stats).Logger()
 	fairnessLogger.Debug().Msg("Applying fairness rules to determine next parent")
 
@@ -709,37 +1008,24 @@ func (s *Scheduler) determineNextParent(date time.Time, parentA, parentB string,
 	parents := parentOnly(lastAssignments)
 
 	// ── 1. No prior parent assignments ───────────────────────────────────
+	fewestTotal := fewestBy(candidates, func(parent string) float64 { return stats[parent].WeightedTotal })
 	if len(parents) == 0 {
 		fairnessLogger.Info().Msg("No previous assignments, assigning based on total counts")
-		if stats[parentA].TotalAssignments <= stats[parentB].TotalAssignments {
-			fairnessLogger.Debug().Str("assigned_parent", parentA).Msg("Assigning Parent A (fewer/equal total)")
-			return parentA, fairness.DecisionReasonTotalCount
-		}
-		fairnessLogger.Debug().Str("assigned_parent", parentB).Msg("Assigning Parent B (fewer total)")
-		return parentB, fairness.DecisionReasonTotalCount
+		fairnessLogger.Debug().Str("assigned_parent", fewestTotal[0]).Msg("Assigning first parent with the fewest total")
+		return fewestTotal[0], fairness.DecisionReasonTotalCount
 	}
 
 	lastParent := parents[0].Parent
-	other := otherParentOf(lastParent, parentA, parentB)
-
-	statsA := stats[parentA]
-	statsB := stats[parentB]
 
 	// ── 2. TotalCount ───────────────────────────────────────────────────
 	fairnessLogger.Debug().
-		Int("parent_a_total", statsA.TotalAssignments).
-		Int("parent_b_total", statsB.TotalAssignments).
+		Strs("fewest_total", fewestTotal).
 		Str("last_parent", lastParent).
 		Msg("Comparing total assignments")
 
-	if statsA.TotalAssignments != statsB.TotalAssignments {
-		fewerParent := parentA
-		if statsB.TotalAssignments < statsA.TotalAssignments {
-			fewerParent = parentB
-		}
-
-		fairnessLogger.Debug().Str("assigned_parent", fewerParent).Msg("Assigning parent with fewer total")
-		return fewerParent, fairness.DecisionReasonTotalCount
+	if len(fewestTotal) == 1 {
+		fairnessLogger.Debug().Str("assigned_parent", fewestTotal[0]).Msg("Assigning parent with fewer total")
+		return fewestTotal[0], fairness.DecisionReasonTotalCount
 	}
 
 	// ── 3. ConsecutiveLimit (totals tied, 2+ streak) ─────────────────────
@@ -749,29 +1035,26 @@ func (s *Scheduler) determineNextParent(date time.Time, parentA, parentB string,
 	}
 	fairnessLogger.Debug().Str("last_parent", lastParent).Int("consecutive_count", consecutiveCount).Msg("Checking consecutive assignments")
 
-	if consecutiveCount >= 2 {
+	if consecutiveCount >= 2 && contains(fewestTotal, lastParent) {
+		other := nextInRotation(lastParent, roster, fewestTotal)
 		fairnessLogger.Info().Msg("Forcing switch due to consecutive assignments limit")
 		fairnessLogger.Debug().Str("assigned_parent", other).Msg("Assigning other parent (forced switch)")
 		return other, fairness.DecisionReasonConsecutiveLimit
 	}
 
 	// ── 4. RecentCount ──────────────────────────────────────────────────
+	fewestRecent := fewestBy(fewestTotal, func(parent string) float64 { return stats[parent].WeightedLast30 })
 	fairnessLogger.Debug().
-		Int("parent_a_last30", statsA.Last30Days).
-		Int("parent_b_last30", statsB.Last30Days).
+		Strs("fewest_recent", fewestRecent).
 		Msg("Total assignments equal, comparing last 30 days")
 
-	if statsA.Last30Days != statsB.Last30Days {
-		fewerRecentParent := parentA
-		if statsB.Last30Days < statsA.Last30Days {
-			fewerRecentParent = parentB
-		}
-
-		fairnessLogger.Debug().Str("assigned_parent", fewerRecentParent).Msg("Assigning parent with fewer recent")
-		return fewerRecentParent, fairness.DecisionReasonRecentCount
+	if len(fewestRecent) == 1 {
+		fairnessLogger.Debug().Str("assigned_parent", fewestRecent[0]).Msg("Assigning parent with fewer recent")
+		return fewestRecent[0], fairness.DecisionReasonRecentCount
 	}
 
 	// ── 5. Alternating ───────────────────────────────────────────────────
+	other := nextInRotation(lastParent, roster, fewestRecent)
 	fairnessLogger.Info().Msg("All fairness factors equal or within limits, defaulting to alternating")
 	fairnessLogger.Debug().Str("assigned_parent", other).Msg("Assigning other parent (alternating)")
 	return other, fairness.DecisionReasonAlternating
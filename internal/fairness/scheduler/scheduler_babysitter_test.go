@@ -34,18 +34,18 @@ func TestBabysitterOverrideIsFixed(t *testing.T) {
 	day3 := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)
 
 	// Generate initial: day1=Alice, day2=Bob, day3=Alice
-	initial, err := sched.GenerateSchedule(day1, day3, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day3, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 3)
 
 	// Set day2 (future) to babysitter
-	day2Assignment, err := tracker.GetAssignmentByDate(day2)
+	day2Assignment, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day2Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day2Assignment.ID, "Dawn", true, day2Assignment.Version)
 	assert.NoError(t, err)
 
 	// Regenerate from day1 — day2 must remain babysitter "Dawn" (fixed override)
-	recalc, err := sched.GenerateSchedule(day1, day3, day1)
+	recalc, err := sched.GenerateSchedule(t.Context(), day1, day3, day1)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 3)
 
@@ -78,7 +78,7 @@ func TestBabysitterShiftCountsForBothParentsTotalCount(t *testing.T) {
 	day3 := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)
 	day4 := time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC)
 
-	initial, err := sched.GenerateSchedule(day1, day4, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day4, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 4)
 	assert.Equal(t, "Alice", initial[0].Parent)
@@ -87,14 +87,14 @@ func TestBabysitterShiftCountsForBothParentsTotalCount(t *testing.T) {
 	assert.Equal(t, "Bob", initial[3].Parent)
 
 	// Convert day2 (Bob) to babysitter → parent stats: Alice=1(day1)+1(shift)=2, Bob=0+1(shift)=1
-	day2Assignment, err := tracker.GetAssignmentByDate(day2)
+	day2Assignment, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day2Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day2Assignment.ID, "Dawn", true, day2Assignment.Version)
 	assert.NoError(t, err)
 
 	// Regenerate: day3-day4 recalculate. Stats before day3: Alice=1+1shift=2, Bob=0+1shift=1
 	// day3 → Bob (TotalCount, fewer assignments)
-	recalc, err := sched.GenerateSchedule(day1, day4, day3)
+	recalc, err := sched.GenerateSchedule(t.Context(), day1, day4, day3)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 4)
 
@@ -122,11 +122,11 @@ func TestBabysitterShiftCountsForBothParentsRecentCount(t *testing.T) {
 	ancient1 := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
 	ancient2 := time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
 	ancient3 := time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)
-	_, err = tracker.RecordAssignment("Alice", ancient1, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", ancient1, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", ancient2, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", ancient2, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Alice", ancient3, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", ancient3, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
 
 	// Recent: rDay1=Bob, rDay2=Bob (within 30 days of rDay3)
@@ -134,21 +134,21 @@ func TestBabysitterShiftCountsForBothParentsRecentCount(t *testing.T) {
 	rDay2 := time.Date(2026, 4, 7, 0, 0, 0, 0, time.UTC)
 	rDay3 := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)
 
-	_, err = tracker.RecordAssignment("Bob", rDay1, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", rDay1, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", rDay2, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", rDay2, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
 
 	// Convert rDay2 (Bob) to babysitter.
 	// Parent stats: Alice total=2+1shift=3, Bob total=2(ancient2+rDay1)+1shift=3 → tied.
 	// Last30 at rDay3: Alice=0+1shift=1, Bob=1(rDay1)+1shift=2 → Bob has more recent → Alice wins RecentCount.
-	rDay2Assignment, err := tracker.GetAssignmentByDate(rDay2)
+	rDay2Assignment, err := tracker.GetAssignmentByDate(t.Context(), rDay2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(rDay2Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), rDay2Assignment.ID, "Dawn", true, rDay2Assignment.Version)
 	assert.NoError(t, err)
 
 	// Generate for rDay3 only
-	recalc, err := sched.GenerateSchedule(rDay3, rDay3, rDay3)
+	recalc, err := sched.GenerateSchedule(t.Context(), rDay3, rDay3, rDay3)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 1)
 
@@ -177,9 +177,9 @@ func TestConsecutiveLimitWithBabysitterGap(t *testing.T) {
 	// Alice=2, Bob=2 parent + 1 babysitter shift each; parent-only last 2 = Bob, Bob → ConsecutiveLimit → Alice
 	old1 := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC) // Within last 30 days of day4
 	old2 := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
-	_, err = tracker.RecordAssignment("Alice", old1, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", old1, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Alice", old2, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", old2, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
 
 	day1 := time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC) // Bob
@@ -187,11 +187,11 @@ func TestConsecutiveLimitWithBabysitterGap(t *testing.T) {
 	day3 := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC) // Bob (override)
 	day4 := time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC) // recalculate
 
-	_, err = tracker.RecordAssignment("Bob", day1, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", day1, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", day2, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day2, true)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", day3, true, fairness.DecisionReasonOverride)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", day3, true, fairness.DecisionReasonOverride)
 	assert.NoError(t, err)
 
 	// Stats at day4: Alice=2+1shift=3, Bob=2+1shift=3 → tied total
@@ -199,7 +199,7 @@ func TestConsecutiveLimitWithBabysitterGap(t *testing.T) {
 	// Parent-only last assignments: [Bob(day3), Bob(day1), Alice(old2), Alice(old1)]
 	// Consecutive: Bob, Bob → count=2 ≥ 2 → ConsecutiveLimit → Alice
 
-	recalc, err := sched.GenerateSchedule(day4, day4, day4)
+	recalc, err := sched.GenerateSchedule(t.Context(), day4, day4, day4)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 1)
 
@@ -229,17 +229,17 @@ func TestBabysitterPoisonsAlternatingLogic(t *testing.T) {
 	day3 := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
 	day4 := time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
 
-	initial, err := sched.GenerateSchedule(day1, day4, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day4, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 4)
 
-	day3Assignment, err := tracker.GetAssignmentByDate(day3)
+	day3Assignment, err := tracker.GetAssignmentByDate(t.Context(), day3)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day3Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day3Assignment.ID, "Dawn", true, day3Assignment.Version)
 	assert.NoError(t, err)
 
 	// Stats at day4: Alice=1(day1), Bob=1(day2) → tied. Alternating from Bob → Alice.
-	recalc, err := sched.GenerateSchedule(day1, day4, day4)
+	recalc, err := sched.GenerateSchedule(t.Context(), day1, day4, day4)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 4)
 
@@ -272,14 +272,14 @@ func TestBabysitterAlternatingFromParentA(t *testing.T) {
 	day3 := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)
 	day4 := time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC)
 
-	_, err = tracker.RecordAssignment("Bob", day1, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", day1, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Alice", day2, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", day2, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", day3, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day3, true)
 	assert.NoError(t, err)
 
-	recalc, err := sched.GenerateSchedule(day4, day4, day4)
+	recalc, err := sched.GenerateSchedule(t.Context(), day4, day4, day4)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 1)
 
@@ -307,28 +307,28 @@ func TestUnlockBabysitterRecalculatesStartDate(t *testing.T) {
 	day1 := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
 	day2 := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
 
-	initial, err := sched.GenerateSchedule(day1, day2, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day2, day1)
 	assert.NoError(t, err)
 	assert.Equal(t, "Alice", initial[0].Parent)
 	assert.Equal(t, "Bob", initial[1].Parent)
 
 	// Set day2 to babysitter then unlock
-	day2Assignment, err := tracker.GetAssignmentByDate(day2)
+	day2Assignment, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day2Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day2Assignment.ID, "Dawn", true, day2Assignment.Version)
 	assert.NoError(t, err)
-	err = tracker.UnlockAssignment(day2Assignment.ID)
+	err = tracker.UnlockAssignment(t.Context(), day2Assignment.ID)
 	assert.NoError(t, err)
 
 	// Verify DB state after unlock
-	unlocked, err := tracker.GetAssignmentByID(day2Assignment.ID)
+	unlocked, err := tracker.GetAssignmentByID(t.Context(), day2Assignment.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, "Dawn", unlocked.Parent, "parent_name retains babysitter name after unlock")
 	assert.Equal(t, fairness.CaregiverTypeParent, unlocked.CaregiverType)
 	assert.False(t, unlocked.Override)
 
 	// Regenerate from day2 — day2 should recalculate to a real parent
-	recalc, err := sched.GenerateSchedule(day2, day2, day2)
+	recalc, err := sched.GenerateSchedule(t.Context(), day2, day2, day2)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 1)
 	assert.Equal(t, "Bob", recalc[0].Parent, "day2 should be Bob (Alice=1, Bob=0 → TotalCount)")
@@ -352,20 +352,20 @@ func TestUnlockBabysitterRecalculatesMultipleDays(t *testing.T) {
 	day4 := time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC)
 
 	// Initial: Alice, Bob, Alice, Bob
-	initial, err := sched.GenerateSchedule(day1, day4, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day4, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 4)
 
 	// Set day2 to babysitter, then unlock
-	day2Assignment, err := tracker.GetAssignmentByDate(day2)
+	day2Assignment, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day2Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day2Assignment.ID, "Dawn", true, day2Assignment.Version)
 	assert.NoError(t, err)
-	err = tracker.UnlockAssignment(day2Assignment.ID)
+	err = tracker.UnlockAssignment(t.Context(), day2Assignment.ID)
 	assert.NoError(t, err)
 
 	// Regenerate from day2 onward
-	recalc, err := sched.GenerateSchedule(day2, day4, day2)
+	recalc, err := sched.GenerateSchedule(t.Context(), day2, day4, day2)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 3)
 
@@ -398,18 +398,18 @@ func TestBabysitterOnPastDayRecalculatesFutureAssignments(t *testing.T) {
 	day3 := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
 	day4 := time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
 
-	initial, err := sched.GenerateSchedule(day1, day4, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day4, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 4)
 
 	// Set day2 (yesterday) to babysitter
-	day2Assignment, err := tracker.GetAssignmentByDate(day2)
+	day2Assignment, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day2Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day2Assignment.ID, "Dawn", true, day2Assignment.Version)
 	assert.NoError(t, err)
 
 	// Regenerate with currentTime=day3: day1 fixed, day2 babysitter fixed
-	recalc, err := sched.GenerateSchedule(day1, day4, day3)
+	recalc, err := sched.GenerateSchedule(t.Context(), day1, day4, day3)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 4)
 
@@ -442,24 +442,24 @@ func TestMultipleBabysitterDays(t *testing.T) {
 	day4 := time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC)  // Bob
 	day5 := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC) // recalculate
 
-	initial, err := sched.GenerateSchedule(day1, day5, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day5, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 5)
 
 	// Convert day2 and day3 to babysitters
-	day2Assignment, err := tracker.GetAssignmentByDate(day2)
+	day2Assignment, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day2Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day2Assignment.ID, "Dawn", true, day2Assignment.Version)
 	assert.NoError(t, err)
 
-	day3Assignment, err := tracker.GetAssignmentByDate(day3)
+	day3Assignment, err := tracker.GetAssignmentByDate(t.Context(), day3)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day3Assignment.ID, "Eve", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day3Assignment.ID, "Eve", true, day3Assignment.Version)
 	assert.NoError(t, err)
 
 	// Regenerate from day4 onward
 	// Stats at day4: Alice=1(day1), Bob=0 (day2 now babysitter) → Bob TotalCount
-	recalc, err := sched.GenerateSchedule(day1, day5, day4)
+	recalc, err := sched.GenerateSchedule(t.Context(), day1, day5, day4)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 5)
 
@@ -489,18 +489,18 @@ func TestConsecutiveBabysitterDays(t *testing.T) {
 	day5 := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC) // recalculate
 
 	// Record day1 and set day2-day4 as consecutive babysitter days
-	_, err = tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", day2, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day2, true)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", day3, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day3, true)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", day4, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day4, true)
 	assert.NoError(t, err)
 
 	// Generate day5: only parent assignment is Alice(day1)
 	// Stats: Alice=1, Bob=0 → Bob TotalCount
-	recalc, err := sched.GenerateSchedule(day5, day5, day5)
+	recalc, err := sched.GenerateSchedule(t.Context(), day5, day5, day5)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 1)
 
@@ -528,11 +528,11 @@ func TestBabysitterOnFirstDayOfSchedule(t *testing.T) {
 	day2 := time.Date(2026, 4, 7, 0, 0, 0, 0, time.UTC) // recalculate
 	day3 := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC) // recalculate
 
-	_, err = tracker.RecordBabysitterAssignment("Dawn", day1, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day1, true)
 	assert.NoError(t, err)
 
 	// Generate day1-day3 with currentTime=day2 (day1 is past + override, fixed)
-	recalc, err := sched.GenerateSchedule(day1, day3, day2)
+	recalc, err := sched.GenerateSchedule(t.Context(), day1, day3, day2)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 3)
 
@@ -559,18 +559,18 @@ func TestBabysitterOnLastDayOfSchedule(t *testing.T) {
 	_ = time.Date(2026, 4, 7, 0, 0, 0, 0, time.UTC)     // day2
 	day3 := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC) // babysitter
 
-	initial, err := sched.GenerateSchedule(day1, day3, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day3, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 3)
 
 	// Set last day to babysitter
-	day3Assignment, err := tracker.GetAssignmentByDate(day3)
+	day3Assignment, err := tracker.GetAssignmentByDate(t.Context(), day3)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day3Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day3Assignment.ID, "Dawn", true, day3Assignment.Version)
 	assert.NoError(t, err)
 
 	// Regenerate — day3 stays as babysitter
-	recalc, err := sched.GenerateSchedule(day1, day3, day1)
+	recalc, err := sched.GenerateSchedule(t.Context(), day1, day3, day1)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 3)
 
@@ -598,26 +598,26 @@ func TestBabysitterReplacedByParentOverride(t *testing.T) {
 	day3 := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)
 
 	// day1=Alice, day2=babysitter, day3=recalculate
-	_, err = tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", day2, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day2, true)
 	assert.NoError(t, err)
 
 	// Replace babysitter with parent override: day2=Bob(override)
-	day2Assignment, err := tracker.GetAssignmentByDate(day2)
+	day2Assignment, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentParent(day2Assignment.ID, "Bob", true)
+	err = tracker.UpdateAssignmentParent(t.Context(), day2Assignment.ID, "Bob", true, day2Assignment.Version)
 	assert.NoError(t, err)
 
 	// Verify day2 is now a parent assignment
-	updated, err := tracker.GetAssignmentByID(day2Assignment.ID)
+	updated, err := tracker.GetAssignmentByID(t.Context(), day2Assignment.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, "Bob", updated.Parent)
 	assert.Equal(t, fairness.CaregiverTypeParent, updated.CaregiverType)
 	assert.True(t, updated.Override)
 
 	// Generate day3: Alice=1, Bob=1 → tied → alternate from Bob → Alice
-	recalc, err := sched.GenerateSchedule(day3, day3, day3)
+	recalc, err := sched.GenerateSchedule(t.Context(), day3, day3, day3)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 1)
 
@@ -645,10 +645,10 @@ func TestBabysitterWithUnavailableParent(t *testing.T) {
 	thu := time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC)  // Thursday
 	fri := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC) // Friday
 
-	_, err = tracker.RecordBabysitterAssignment("Dawn", wed, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", wed, true)
 	assert.NoError(t, err)
 
-	recalc, err := sched.GenerateSchedule(wed, fri, thu)
+	recalc, err := sched.GenerateSchedule(t.Context(), wed, fri, thu)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 3)
 
@@ -681,17 +681,17 @@ func TestMultipleDifferentBabysitters(t *testing.T) {
 	day4 := time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC)  // babysitter Frank
 	day5 := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC) // recalculate
 
-	_, err = tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", day2, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day2, true)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Eve", day3, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Eve", day3, true)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Frank", day4, true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Frank", day4, true)
 	assert.NoError(t, err)
 
 	// day5: only Alice(day1) in parent stats → Bob (TotalCount)
-	recalc, err := sched.GenerateSchedule(day5, day5, day5)
+	recalc, err := sched.GenerateSchedule(t.Context(), day5, day5, day5)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 1)
 
@@ -725,7 +725,7 @@ func TestFullWeekWithMidWeekBabysitter(t *testing.T) {
 	sun := time.Date(2026, 4, 12, 0, 0, 0, 0, time.UTC)
 
 	// Generate full week: Mon=Alice, Tue=Bob, Wed=Alice, Thu=Bob, Fri=Alice, Sat=Bob, Sun=Alice
-	initial, err := sched.GenerateSchedule(mon, sun, mon)
+	initial, err := sched.GenerateSchedule(t.Context(), mon, sun, mon)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 7)
 	assert.Equal(t, "Alice", initial[0].Parent) // Mon
@@ -737,15 +737,15 @@ func TestFullWeekWithMidWeekBabysitter(t *testing.T) {
 	assert.Equal(t, "Alice", initial[6].Parent) // Sun
 
 	// Set Wednesday to babysitter (mid-week)
-	wedAssignment, err := tracker.GetAssignmentByDate(wed)
+	wedAssignment, err := tracker.GetAssignmentByDate(t.Context(), wed)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(wedAssignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), wedAssignment.ID, "Dawn", true, wedAssignment.Version)
 	assert.NoError(t, err)
 
 	// Regenerate with currentTime=Thursday
 	// Fixed: Mon=Alice(past), Tue=Bob(past), Wed=Dawn(override)
 	// Thu onward: recalculate (after override)
-	recalc, err := sched.GenerateSchedule(mon, sun, thu)
+	recalc, err := sched.GenerateSchedule(t.Context(), mon, sun, thu)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 7)
 
@@ -801,7 +801,7 @@ func TestBabysitterOnPastDayRecalculatesPastDaysBetweenOverrideAndToday(t *testi
 	day5 := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)  // future
 
 	// Generate initial schedule: Alice, Bob, Alice, Bob, Alice
-	initial, err := sched.GenerateSchedule(day1, day5, day1)
+	initial, err := sched.GenerateSchedule(t.Context(), day1, day5, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initial, 5)
 	assert.Equal(t, "Alice", initial[0].Parent)
@@ -811,15 +811,15 @@ func TestBabysitterOnPastDayRecalculatesPastDaysBetweenOverrideAndToday(t *testi
 	assert.Equal(t, "Alice", initial[4].Parent)
 
 	// Set day2 (past) to babysitter
-	day2Assignment, err := tracker.GetAssignmentByDate(day2)
+	day2Assignment, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentToBabysitter(day2Assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), day2Assignment.ID, "Dawn", true, day2Assignment.Version)
 	assert.NoError(t, err)
 
 	// Regenerate from day2 (the babysitter date) with currentTime = day4 (today).
 	// This matches how the handler calls it: recalculateSchedule(assignment.Date)
 	// with time.Now() as currentTime.
-	recalc, err := sched.GenerateSchedule(day2, day5, day4)
+	recalc, err := sched.GenerateSchedule(t.Context(), day2, day5, day4)
 	assert.NoError(t, err)
 	assert.Len(t, recalc, 4) // day2..day5
 
@@ -866,17 +866,17 @@ func TestBabysitterShiftKeepsFairness(t *testing.T) {
 		sched := New(store, tracker)
 
 		// Alice=2 (dayPre + dayA), Bob=1 (dayB), babysitter on dayC.
-		_, err = tracker.RecordAssignment("Alice", dayPre, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Alice", dayPre, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Alice", dayA, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Alice", dayA, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Bob", dayB, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Bob", dayB, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordBabysitterAssignment("Sitter", dayC, false)
+		_, err = tracker.RecordBabysitterAssignment(t.Context(), "Sitter", dayC, false)
 		assert.NoError(t, err)
 
 		// Stats at dayD: Alice=2+1shift=3, Bob=1+1shift=2. Bob has fewer → TotalCount → Bob.
-		schedule, err := sched.GenerateSchedule(dayD, dayD, dayD)
+		schedule, err := sched.GenerateSchedule(t.Context(), dayD, dayD, dayD)
 		assert.NoError(t, err)
 		assert.Len(t, schedule, 1)
 
@@ -893,17 +893,17 @@ func TestBabysitterShiftKeepsFairness(t *testing.T) {
 		sched := New(store, tracker)
 
 		// Same imbalance but parent on dayC instead of babysitter.
-		_, err = tracker.RecordAssignment("Alice", dayPre, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Alice", dayPre, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Alice", dayA, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Alice", dayA, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Alice", dayB, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Alice", dayB, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Bob", dayC, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Bob", dayC, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
 
 		// Alice=3, Bob=1 (no babysitter, no shift). TotalCount → Bob.
-		schedule, err := sched.GenerateSchedule(dayD, dayD, dayD)
+		schedule, err := sched.GenerateSchedule(t.Context(), dayD, dayD, dayD)
 		assert.NoError(t, err)
 		assert.Len(t, schedule, 1)
 
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCalendarUnavailability verifies that the days a parent is unavailable from its personal
+// calendar go to the other parent, and are scheduled as usual once the calendar no longer has them
+func TestCalendarUnavailability(t *testing.T) {
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	onCall := []time.Time{
+		time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, scheduler.SetCalendarUnavailability(t.Context(), "parent_a", start, end, onCall))
+
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, end, start)
+	require.NoError(t, err)
+	require.Len(t, schedule, 14)
+	for _, assignment := range schedule[1:4] {
+		assert.Equal(t, "Bob", assignment.Parent, "Alice is on call on %s", assignment.Date.Format("2006-01-02"))
+		assert.Equal(t, fairness.DecisionReasonUnavailability, assignment.DecisionReason)
+	}
+
+	require.NoError(t, scheduler.SetCalendarUnavailability(t.Context(), "parent_a", start, end, nil))
+	schedule, err = scheduler.GenerateSchedule(t.Context(), start, end, start)
+	require.NoError(t, err)
+	for _, assignment := range schedule[1:4] {
+		assert.NotEqual(t, fairness.DecisionReasonUnavailability, assignment.DecisionReason, "%s recalculated", assignment.Date.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChildrenRotations verifies that each child gets a rotation of its own after the main one,
+// an override of a child only moving the nights of that child
+func TestChildrenRotations(t *testing.T) {
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	emma, err := tracker.AddChild(t.Context(), "Emma")
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, start.AddDate(0, 0, 3), start)
+	require.NoError(t, err)
+	require.Len(t, schedule, 8)
+	assert.Equal(t, []string{"Alice", "Bob", "Alice", "Bob", "Alice", "Bob", "Alice", "Bob"}, scheduledParents(schedule))
+	for i, a := range schedule {
+		if i < 4 {
+			assert.Empty(t, a.Child, "the main rotation comes first")
+		} else {
+			assert.Equal(t, "Emma", a.Child)
+		}
+	}
+
+	// Emma's first night goes to Bob: her rotation is recalculated, the main one is not
+	emmaTracker := tracker.ForChild(*emma)
+	_, err = emmaTracker.RecordAssignment(t.Context(), "Bob", start, true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	schedule, err = scheduler.GenerateSchedule(t.Context(), start, start.AddDate(0, 0, 3), start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Bob", "Alice", "Bob", "Bob", "Alice", "Bob", "Alice"}, scheduledParents(schedule))
+	assert.True(t, schedule[4].Override)
+
+	// The regeneration of every rotation is undone as one batch
+	batch, err := tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, fairness.ChangeKindRegeneration, batch.Kind)
+	assert.Len(t, batch.Assignments, 3)
+}
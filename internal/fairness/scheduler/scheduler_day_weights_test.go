@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDayWeightsBalanceTotals verifies that a weighted night counts for its weight in the totals
+// the next parent is picked by
+func TestDayWeightsBalanceTotals(t *testing.T) {
+	friday := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	monday := friday.AddDate(0, 0, 3)
+
+	tests := []struct {
+		name    string
+		weights config.DayWeights
+		want    string
+	}{
+		{name: "Every night counts 1", weights: nil, want: "Alice"},
+		{name: "The Friday counts 3", weights: config.DayWeights{time.Friday: 3}, want: "Bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+			store.dayWeights = tt.weights
+			db, cleanup := setupTestDB(t)
+			defer cleanup()
+
+			tracker, err := fairness.New(db)
+			require.NoError(t, err)
+			// Alice did the Friday, Bob the Saturday and the Sunday
+			_, err = tracker.RecordAssignment(t.Context(), "Alice", friday, false, fairness.DecisionReasonTotalCount)
+			require.NoError(t, err)
+			_, err = tracker.RecordAssignment(t.Context(), "Bob", friday.AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
+			require.NoError(t, err)
+			_, err = tracker.RecordAssignment(t.Context(), "Bob", friday.AddDate(0, 0, 2), false, fairness.DecisionReasonTotalCount)
+			require.NoError(t, err)
+
+			schedule, err := New(store, tracker).GenerateSchedule(t.Context(), monday, monday, monday)
+			require.NoError(t, err)
+			require.Len(t, schedule, 1)
+			assert.Equal(t, tt.want, schedule[0].Parent)
+			assert.Equal(t, fairness.DecisionReasonTotalCount, schedule[0].DecisionReason)
+		})
+	}
+}
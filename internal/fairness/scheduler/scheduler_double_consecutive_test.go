@@ -25,12 +25,7 @@ func makeAssignment(id int64, parent string, date time.Time, reason fairness.Dec
 }
 
 func noUnavailabilityCfg() *scheduleConfig {
-	return &scheduleConfig{
-		parentA:            "Alice",
-		parentB:            "Bob",
-		parentAUnavailable: []string{},
-		parentBUnavailable: []string{},
-	}
+	return &scheduleConfig{parents: []string{"Alice", "Bob"}}
 }
 
 func testTracker(t *testing.T) fairness.TrackerInterface {
@@ -54,13 +49,13 @@ func TestObserveDetectsDoubleConsecutive(t *testing.T) {
 	day4 := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
 
 	// Pre-record all 4 assignments in the DB so upsert works.
-	a1, err := tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	a1, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a2, err := tracker.RecordAssignment("Alice", day2, false, fairness.DecisionReasonTotalCount)
+	a2, err := tracker.RecordAssignment(t.Context(), "Alice", day2, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a3, err := tracker.RecordAssignment("Bob", day3, false, fairness.DecisionReasonConsecutiveLimit)
+	a3, err := tracker.RecordAssignment(t.Context(), "Bob", day3, false, fairness.DecisionReasonConsecutiveLimit)
 	require.NoError(t, err)
-	a4, err := tracker.RecordAssignment("Bob", day4, false, fairness.DecisionReasonAlternating)
+	a4, err := tracker.RecordAssignment(t.Context(), "Bob", day4, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	schedule := []*Assignment{
@@ -74,7 +69,7 @@ func TestObserveDetectsDoubleConsecutive(t *testing.T) {
 
 	// Feed assignments one by one.
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	// After swap: Alice, Bob, Alice, Bob  (boundary positions 1 and 2 swapped).
@@ -86,12 +81,12 @@ func TestObserveDetectsDoubleConsecutive(t *testing.T) {
 	assert.Equal(t, "Bob", schedule[3].Parent, "day4 unchanged")
 
 	// Verify the DB was updated via the upsert.
-	dbA2, err := tracker.GetAssignmentByDate(day2)
+	dbA2, err := tracker.GetAssignmentByDate(t.Context(), day2)
 	require.NoError(t, err)
 	assert.Equal(t, "Bob", dbA2.Parent)
 	assert.Equal(t, fairness.DecisionReasonDoubleConsecutiveSwap, dbA2.DecisionReason)
 
-	dbA3, err := tracker.GetAssignmentByDate(day3)
+	dbA3, err := tracker.GetAssignmentByDate(t.Context(), day3)
 	require.NoError(t, err)
 	assert.Equal(t, "Alice", dbA3.Parent)
 	assert.Equal(t, fairness.DecisionReasonDoubleConsecutiveSwap, dbA3.DecisionReason)
@@ -107,13 +102,13 @@ func TestObserveReversedPattern(t *testing.T) {
 	day3 := time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)
 	day4 := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
 
-	a1, err := tracker.RecordAssignment("Bob", day1, false, fairness.DecisionReasonTotalCount)
+	a1, err := tracker.RecordAssignment(t.Context(), "Bob", day1, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a2, err := tracker.RecordAssignment("Bob", day2, false, fairness.DecisionReasonTotalCount)
+	a2, err := tracker.RecordAssignment(t.Context(), "Bob", day2, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a3, err := tracker.RecordAssignment("Alice", day3, false, fairness.DecisionReasonConsecutiveLimit)
+	a3, err := tracker.RecordAssignment(t.Context(), "Alice", day3, false, fairness.DecisionReasonConsecutiveLimit)
 	require.NoError(t, err)
-	a4, err := tracker.RecordAssignment("Alice", day4, false, fairness.DecisionReasonAlternating)
+	a4, err := tracker.RecordAssignment(t.Context(), "Alice", day4, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	schedule := []*Assignment{
@@ -125,7 +120,7 @@ func TestObserveReversedPattern(t *testing.T) {
 
 	dc := newDoubleConsecutiveTracker(logging.GetLogger("test"))
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	assert.Equal(t, "Bob", schedule[0].Parent)
@@ -144,11 +139,11 @@ func TestObserveNoSwapForSingleConsecutive(t *testing.T) {
 	day2 := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
 	day3 := time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)
 
-	a1, err := tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	a1, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a2, err := tracker.RecordAssignment("Bob", day2, false, fairness.DecisionReasonAlternating)
+	a2, err := tracker.RecordAssignment(t.Context(), "Bob", day2, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
-	a3, err := tracker.RecordAssignment("Bob", day3, false, fairness.DecisionReasonAlternating)
+	a3, err := tracker.RecordAssignment(t.Context(), "Bob", day3, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	schedule := []*Assignment{
@@ -159,7 +154,7 @@ func TestObserveNoSwapForSingleConsecutive(t *testing.T) {
 
 	dc := newDoubleConsecutiveTracker(logging.GetLogger("test"))
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	// Verify no swap occurred — parents remain unchanged.
@@ -180,15 +175,15 @@ func TestObserveBabysitterBreaksTracking(t *testing.T) {
 	day4 := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
 	day5 := time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)
 
-	a1, err := tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	a1, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a2, err := tracker.RecordAssignment("Alice", day2, false, fairness.DecisionReasonTotalCount)
+	a2, err := tracker.RecordAssignment(t.Context(), "Alice", day2, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a3, err := tracker.RecordBabysitterAssignment("Nanny", day3, true)
+	a3, err := tracker.RecordBabysitterAssignment(t.Context(), "Nanny", day3, true)
 	require.NoError(t, err)
-	a4, err := tracker.RecordAssignment("Bob", day4, false, fairness.DecisionReasonAlternating)
+	a4, err := tracker.RecordAssignment(t.Context(), "Bob", day4, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
-	a5, err := tracker.RecordAssignment("Bob", day5, false, fairness.DecisionReasonAlternating)
+	a5, err := tracker.RecordAssignment(t.Context(), "Bob", day5, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	schedule := []*Assignment{
@@ -201,7 +196,7 @@ func TestObserveBabysitterBreaksTracking(t *testing.T) {
 
 	dc := newDoubleConsecutiveTracker(logging.GetLogger("test"))
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	// Verify no swap occurred — babysitter broke tracking.
@@ -223,15 +218,15 @@ func TestObserveOverrideBreaksTracking(t *testing.T) {
 	day4 := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
 	day5 := time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)
 
-	a1, err := tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	a1, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a2, err := tracker.RecordAssignment("Alice", day2, false, fairness.DecisionReasonTotalCount)
+	a2, err := tracker.RecordAssignment(t.Context(), "Alice", day2, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a3, err := tracker.RecordAssignment("Alice", day3, true, fairness.DecisionReasonOverride)
+	a3, err := tracker.RecordAssignment(t.Context(), "Alice", day3, true, fairness.DecisionReasonOverride)
 	require.NoError(t, err)
-	a4, err := tracker.RecordAssignment("Bob", day4, false, fairness.DecisionReasonAlternating)
+	a4, err := tracker.RecordAssignment(t.Context(), "Bob", day4, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
-	a5, err := tracker.RecordAssignment("Bob", day5, false, fairness.DecisionReasonAlternating)
+	a5, err := tracker.RecordAssignment(t.Context(), "Bob", day5, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	schedule := []*Assignment{
@@ -244,7 +239,7 @@ func TestObserveOverrideBreaksTracking(t *testing.T) {
 
 	dc := newDoubleConsecutiveTracker(logging.GetLogger("test"))
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	// Verify no swap occurred — override broke tracking.
@@ -266,15 +261,15 @@ func TestObserveUnavailabilityBreaksTracking(t *testing.T) {
 	day4 := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
 	day5 := time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)
 
-	a1, err := tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	a1, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a2, err := tracker.RecordAssignment("Alice", day2, false, fairness.DecisionReasonTotalCount)
+	a2, err := tracker.RecordAssignment(t.Context(), "Alice", day2, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a3, err := tracker.RecordAssignment("Bob", day3, false, fairness.DecisionReasonUnavailability)
+	a3, err := tracker.RecordAssignment(t.Context(), "Bob", day3, false, fairness.DecisionReasonUnavailability)
 	require.NoError(t, err)
-	a4, err := tracker.RecordAssignment("Bob", day4, false, fairness.DecisionReasonAlternating)
+	a4, err := tracker.RecordAssignment(t.Context(), "Bob", day4, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
-	a5, err := tracker.RecordAssignment("Bob", day5, false, fairness.DecisionReasonAlternating)
+	a5, err := tracker.RecordAssignment(t.Context(), "Bob", day5, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	schedule := []*Assignment{
@@ -287,7 +282,7 @@ func TestObserveUnavailabilityBreaksTracking(t *testing.T) {
 
 	dc := newDoubleConsecutiveTracker(logging.GetLogger("test"))
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	// Verify no swap occurred — unavailability broke tracking.
@@ -303,10 +298,8 @@ func TestObserveRespectsAvailabilityConstraints(t *testing.T) {
 	tracker := testTracker(t)
 	// Alice is unavailable on Thursdays.
 	cfg := &scheduleConfig{
-		parentA:            "Alice",
-		parentB:            "Bob",
-		parentAUnavailable: []string{"Thursday"},
-		parentBUnavailable: []string{},
+		parents:      []string{"Alice", "Bob"},
+		availability: map[string]parentAvailability{"Alice": {days: []string{"Thursday"}}},
 	}
 
 	// Build AA BB where position 2 (day3) is a Thursday.
@@ -316,13 +309,13 @@ func TestObserveRespectsAvailabilityConstraints(t *testing.T) {
 	day3 := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)  // Thursday
 	day4 := time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)  // Friday
 
-	a1, err := tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	a1, err := tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a2, err := tracker.RecordAssignment("Alice", day2, false, fairness.DecisionReasonTotalCount)
+	a2, err := tracker.RecordAssignment(t.Context(), "Alice", day2, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	a3, err := tracker.RecordAssignment("Bob", day3, false, fairness.DecisionReasonConsecutiveLimit)
+	a3, err := tracker.RecordAssignment(t.Context(), "Bob", day3, false, fairness.DecisionReasonConsecutiveLimit)
 	require.NoError(t, err)
-	a4, err := tracker.RecordAssignment("Bob", day4, false, fairness.DecisionReasonAlternating)
+	a4, err := tracker.RecordAssignment(t.Context(), "Bob", day4, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	schedule := []*Assignment{
@@ -334,7 +327,7 @@ func TestObserveRespectsAvailabilityConstraints(t *testing.T) {
 
 	dc := newDoubleConsecutiveTracker(logging.GetLogger("test"))
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	// Assignments should be unchanged.
@@ -358,7 +351,7 @@ func TestObserveMultiplePatterns(t *testing.T) {
 	parents := []string{"Alice", "Alice", "Bob", "Bob", "Alice", "Alice", "Bob", "Bob"}
 	for i, p := range parents {
 		var err error
-		assignments[i], err = tracker.RecordAssignment(p, days[i], false, fairness.DecisionReasonTotalCount)
+		assignments[i], err = tracker.RecordAssignment(t.Context(), p, days[i], false, fairness.DecisionReasonTotalCount)
 		require.NoError(t, err)
 	}
 
@@ -370,7 +363,7 @@ func TestObserveMultiplePatterns(t *testing.T) {
 
 	dc := newDoubleConsecutiveTracker(logging.GetLogger("test"))
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	// After two swaps: AB AB AB AB.
@@ -398,7 +391,7 @@ func TestObserveLongerRuns(t *testing.T) {
 	assignments := make([]*fairness.Assignment, 6)
 	for i, p := range parents {
 		var err error
-		assignments[i], err = tracker.RecordAssignment(p, days[i], false, fairness.DecisionReasonTotalCount)
+		assignments[i], err = tracker.RecordAssignment(t.Context(), p, days[i], false, fairness.DecisionReasonTotalCount)
 		require.NoError(t, err)
 	}
 
@@ -410,7 +403,7 @@ func TestObserveLongerRuns(t *testing.T) {
 
 	dc := newDoubleConsecutiveTracker(logging.GetLogger("test"))
 	for i := range schedule {
-		require.NoError(t, dc.observe(schedule, i, cfg, tracker))
+		require.NoError(t, dc.observe(t.Context(), schedule, i, cfg, tracker))
 	}
 
 	// Boundary swap: [2]=Alice→Bob, [3]=Bob→Alice
@@ -455,18 +448,18 @@ func TestGenerateScheduleNoDoubleConsecutiveInOutput(t *testing.T) {
 			// Seed prior assignments to create the desired imbalance.
 			seedDay := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
 			for i := range tt.seedAlice {
-				_, err = tracker.RecordAssignment("Alice", seedDay.AddDate(0, 0, i), false, fairness.DecisionReasonAlternating)
+				_, err = tracker.RecordAssignment(t.Context(), "Alice", seedDay.AddDate(0, 0, i), false, fairness.DecisionReasonAlternating)
 				require.NoError(t, err)
 			}
 			for i := range tt.seedBob {
-				_, err = tracker.RecordAssignment("Bob", seedDay.AddDate(0, 0, tt.seedAlice+i), false, fairness.DecisionReasonAlternating)
+				_, err = tracker.RecordAssignment(t.Context(), "Bob", seedDay.AddDate(0, 0, tt.seedAlice+i), false, fairness.DecisionReasonAlternating)
 				require.NoError(t, err)
 			}
 
 			start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
 			end := start.AddDate(0, 0, tt.days-1)
 
-			schedule, err := sched.GenerateSchedule(start, end, start)
+			schedule, err := sched.GenerateSchedule(t.Context(), start, end, start)
 			require.NoError(t, err)
 			assert.Len(t, schedule, tt.days)
 
@@ -506,20 +499,20 @@ func TestGenerateScheduleFixedAssignmentsNotSwapped(t *testing.T) {
 	day3 := time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)
 	day4 := time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC)
 
-	_, err = tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	_, err = tracker.RecordAssignment("Alice", day2, false, fairness.DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", day2, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", day3, false, fairness.DecisionReasonConsecutiveLimit)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", day3, false, fairness.DecisionReasonConsecutiveLimit)
 	require.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", day4, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", day4, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	// Set currentTime to day5 so day1-day4 are all past (fixed).
 	day5 := time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)
 	day7 := time.Date(2026, 4, 7, 0, 0, 0, 0, time.UTC)
 
-	schedule, err := sched.GenerateSchedule(day1, day7, day5)
+	schedule, err := sched.GenerateSchedule(t.Context(), day1, day7, day5)
 	require.NoError(t, err)
 
 	// Past assignments (day1-day4) should retain their original reasons.
@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scheduledParents(schedule []*Assignment) []string {
+	var parents []string
+	for _, assignment := range schedule {
+		parents = append(parents, assignment.Parent)
+	}
+	return parents
+}
+
+// TestExtraParentsRotate verifies that the nights rotate across the whole roster, the extra parents
+// being recorded with their stats in the assignment details
+func TestExtraParentsRotate(t *testing.T) {
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	store.extra = []config.ExtraParent{{Name: "Grandma"}}
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, start.AddDate(0, 0, 5), start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Bob", "Grandma", "Alice", "Bob", "Grandma"}, scheduledParents(schedule))
+	assert.Equal(t, ParentTypeOther, schedule[2].ParentType)
+	assert.Equal(t, "OtherParent", schedule[2].ParentType.String())
+
+	details, err := tracker.GetAssignmentDetails(t.Context(), schedule[5].ID)
+	require.NoError(t, err)
+	require.NotNil(t, details)
+	assert.Equal(t, []fairness.NamedStats{{Name: "Grandma", Stats: fairness.Stats{TotalAssignments: 1, Last30Days: 1}}}, details.ExtraParents)
+}
+
+// TestExtraParentsUnavailability verifies that an unavailable parent is left out of the rotation,
+// and that the only available parent of the roster takes the night
+func TestExtraParentsUnavailability(t *testing.T) {
+	// Monday 5 January 2026 to Sunday 11 January
+	store := newTestConfigStore("Alice", "Bob", []string{"Tuesday"}, []string{"Tuesday"})
+	store.extra = []config.ExtraParent{{Name: "Grandma"}}
+	store.extraUnavailable = map[string][]string{"parent_c": {"Monday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}}
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, start.AddDate(0, 0, 3), start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Grandma", "Bob", "Alice"}, scheduledParents(schedule))
+	assert.Equal(t, fairness.DecisionReasonUnavailability, schedule[1].DecisionReason)
+
+	store.extraUnavailable = map[string][]string{"parent_c": {"Tuesday"}}
+	_, err = scheduler.GenerateSchedule(t.Context(), start, start.AddDate(0, 0, 3), start)
+	assert.ErrorContains(t, err, "all parents unavailable on Tuesday 2026-01-06")
+}
+
+// TestApplyFairnessRulesRoster covers the fairness rules across more than two parents
+func TestApplyFairnessRulesRoster(t *testing.T) {
+	scheduler := New(newTestConfigStore("Alice", "Bob", nil, nil), nil)
+	roster := []string{"Alice", "Bob", "Carol"}
+	tonight := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	night := func(parent string, daysAgo int) *fairness.Assignment {
+		return &fairness.Assignment{Parent: parent, Date: tonight.AddDate(0, 0, -daysAgo), CaregiverType: fairness.CaregiverTypeParent}
+	}
+
+	tests := []struct {
+		name       string
+		candidates []string
+		last       []*fairness.Assignment
+		stats      map[string]fairness.Stats
+		want       string
+		reason     fairness.DecisionReason
+	}{
+		{
+			name:       "Fewest total",
+			candidates: roster,
+			last:       []*fairness.Assignment{night("Alice", 1)},
+			stats:      map[string]fairness.Stats{"Alice": {TotalAssignments: 3, WeightedTotal: 3}, "Bob": {TotalAssignments: 3, WeightedTotal: 3}, "Carol": {TotalAssignments: 2, WeightedTotal: 2}},
+			want:       "Carol",
+			reason:     fairness.DecisionReasonTotalCount,
+		},
+		{
+			name:       "Streak of the last parent",
+			candidates: roster,
+			last:       []*fairness.Assignment{night("Bob", 1), night("Bob", 2)},
+			stats:      map[string]fairness.Stats{"Alice": {TotalAssignments: 3, WeightedTotal: 3}, "Bob": {TotalAssignments: 3, WeightedTotal: 3}, "Carol": {TotalAssignments: 3, WeightedTotal: 3}},
+			want:       "Carol",
+			reason:     fairness.DecisionReasonConsecutiveLimit,
+		},
+		{
+			name:       "Fewest recent among the tied parents",
+			candidates: roster,
+			last:       []*fairness.Assignment{night("Carol", 1)},
+			stats:      map[string]fairness.Stats{"Alice": {TotalAssignments: 3, Last30Days: 3, WeightedTotal: 3, WeightedLast30: 3}, "Bob": {TotalAssignments: 3, Last30Days: 2, WeightedTotal: 3, WeightedLast30: 2}, "Carol": {TotalAssignments: 4, WeightedTotal: 4}},
+			want:       "Bob",
+			reason:     fairness.DecisionReasonRecentCount,
+		},
+		{
+			name:       "Rotation wraps around the roster",
+			candidates: roster,
+			last:       []*fairness.Assignment{night("Carol", 1)},
+			stats:      map[string]fairness.Stats{},
+			want:       "Alice",
+			reason:     fairness.DecisionReasonAlternating,
+		},
+		{
+			name:       "Rotation skips the unavailable parents",
+			candidates: []string{"Alice", "Carol"},
+			last:       []*fairness.Assignment{night("Alice", 1)},
+			stats:      map[string]fairness.Stats{},
+			want:       "Carol",
+			reason:     fairness.DecisionReasonAlternating,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, reason := scheduler.applyFairnessRules(tonight, roster, tt.candidates, tt.last, tt.stats)
+			assert.Equal(t, tt.want, parent)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
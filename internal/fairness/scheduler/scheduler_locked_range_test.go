@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockedRangeKeepsAssignments verifies that the assignments within a locked range are kept by a
+// regeneration triggered by an override before them, and recalculated again once unlocked
+func TestLockedRangeKeepsAssignments(t *testing.T) {
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	wed := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	thu := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	fri := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	sun := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	_, err = scheduler.GenerateSchedule(t.Context(), wed, sun, wed)
+	require.NoError(t, err)
+	before, err := tracker.GetAssignmentsInRange(t.Context(), thu, fri)
+	require.NoError(t, err)
+	require.Len(t, before, 2)
+
+	lockedRange, err := tracker.LockRange(t.Context(), thu, fri)
+	require.NoError(t, err)
+
+	// Give Wednesday to the parent of Thursday, which would otherwise move Thursday to the other one
+	wedAssignment, err := tracker.GetAssignmentByDate(t.Context(), wed)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), wedAssignment.ID, before[0].Parent, true, wedAssignment.Version))
+
+	schedule, err := scheduler.GenerateSchedule(t.Context(), wed, sun, wed)
+	require.NoError(t, err)
+	require.Len(t, schedule, 5)
+	for i, a := range before {
+		assert.Equal(t, a.ID, schedule[i+1].ID)
+		assert.Equal(t, a.Parent, schedule[i+1].Parent, "locked assignment of %s kept", a.Date.Format(time.DateOnly))
+		assert.Equal(t, a.Version, schedule[i+1].Version, "locked assignment of %s not rewritten", a.Date.Format(time.DateOnly))
+	}
+
+	require.NoError(t, tracker.UnlockRange(t.Context(), lockedRange.ID))
+	schedule, err = scheduler.GenerateSchedule(t.Context(), wed, sun, wed)
+	require.NoError(t, err)
+	assert.NotEqual(t, before[0].Parent, schedule[1].Parent, "Thursday recalculated once unlocked")
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMinRestDaysForcesSwitch verifies that a parent gets at least the minimum nights off after its
+// block of consecutive nights, the switches being recorded with the rest days reason
+func TestMinRestDaysForcesSwitch(t *testing.T) {
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	store.minRestDays = 2
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, start.AddDate(0, 0, 7), start)
+	require.NoError(t, err)
+	require.Len(t, schedule, 8)
+
+	var parents []string
+	for _, assignment := range schedule {
+		parents = append(parents, assignment.Parent)
+	}
+	assert.Equal(t, []string{"Alice", "Bob", "Bob", "Alice", "Alice", "Bob", "Bob", "Alice"}, parents)
+	assert.Equal(t, fairness.DecisionReasonRestDays, schedule[2].DecisionReason, "Alice had a single night off")
+	assert.Equal(t, fairness.DecisionReasonRestDays, schedule[4].DecisionReason, "Bob had a single night off")
+}
+
+// TestMinRestDaysDisabled verifies that without rest days the parents keep alternating
+func TestMinRestDaysDisabled(t *testing.T) {
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, start.AddDate(0, 0, 3), start)
+	require.NoError(t, err)
+
+	for _, assignment := range schedule {
+		assert.NotEqual(t, fairness.DecisionReasonRestDays, assignment.DecisionReason)
+	}
+	assert.Equal(t, "Alice", schedule[0].Parent)
+	assert.Equal(t, "Bob", schedule[1].Parent)
+	assert.Equal(t, "Alice", schedule[2].Parent)
+}
+
+// TestIsResting covers the nights off counted from the last night of a parent
+func TestIsResting(t *testing.T) {
+	tonight := time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
+	night := func(parent string, daysAgo int) *fairness.Assignment {
+		return &fairness.Assignment{Parent: parent, Date: tonight.AddDate(0, 0, -daysAgo), CaregiverType: fairness.CaregiverTypeParent}
+	}
+	babysitter := &fairness.Assignment{Parent: "Nanny", Date: tonight.AddDate(0, 0, -1), CaregiverType: fairness.CaregiverTypeBabysitter}
+
+	last := []*fairness.Assignment{night("Bob", 1), night("Alice", 2), night("Alice", 3)}
+	assert.True(t, isResting("Alice", tonight, last, 2), "one night off out of two")
+	assert.False(t, isResting("Alice", tonight, last, 1), "one night off is enough")
+	assert.False(t, isResting("Bob", tonight, last, 2), "Bob is in the middle of a block")
+	assert.False(t, isResting("Carol", tonight, last, 2), "no previous night")
+
+	last = []*fairness.Assignment{babysitter, night("Alice", 2)}
+	assert.True(t, isResting("Alice", tonight, last, 2), "the babysitter night is a night off")
+	assert.False(t, isResting("Alice", tonight, last, 1))
+}
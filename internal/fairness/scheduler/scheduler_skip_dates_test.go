@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSkipDates verifies that single and recurring skip dates get no assignment, that the
+// assignments already recorded on them from the current day on are deleted, overrides included, and
+// that past ones are kept.
+func TestSkipDates(t *testing.T) {
+	secondSaturday, err := config.ParseSkipDate("FREQ=MONTHLY;BYDAY=2SA")
+	require.NoError(t, err)
+	thursday, err := config.ParseSkipDate("2026-01-15")
+	require.NoError(t, err)
+
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	_, err = scheduler.GenerateSchedule(t.Context(), start, end, start)
+	require.NoError(t, err)
+	override, err := tracker.GetAssignmentByDate(t.Context(), time.Date(2026, 2, 14, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), override.ID, "Bob", true, override.Version))
+
+	store.skipDates = config.SkipDates{secondSaturday, thursday}
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, end, today)
+	require.NoError(t, err)
+
+	byDate := make(map[string]*Assignment, len(schedule))
+	for _, assignment := range schedule {
+		byDate[assignment.Date.Format("2006-01-02")] = assignment
+	}
+	assert.Contains(t, byDate, "2026-01-10", "the second Saturday of January already happened")
+	for _, day := range []string{"2026-01-15", "2026-02-14"} {
+		assert.NotContains(t, byDate, day)
+		date, err := time.Parse("2006-01-02", day)
+		require.NoError(t, err)
+		assignment, err := tracker.GetAssignmentByDate(t.Context(), date)
+		require.NoError(t, err)
+		assert.Nil(t, assignment, "assignment of %s deleted", day)
+	}
+	assert.Len(t, schedule, 59-2)
+}
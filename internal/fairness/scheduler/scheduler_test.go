@@ -1,11 +1,13 @@
 package scheduler
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/belphemur/night-routine/internal/fairness"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // createTestConfigStore creates a testConfigStore for testing
@@ -28,7 +30,7 @@ func TestGenerateSchedule(t *testing.T) {
 	end := time.Date(2023, 1, 7, 0, 0, 0, 0, time.UTC)   // Saturday
 
 	// Use the end date as the "current time" for the test
-	schedule, err := scheduler.GenerateSchedule(start, end, end)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, end, end)
 	assert.NoError(t, err)
 	assert.Len(t, schedule, 7)
 
@@ -39,6 +41,54 @@ func TestGenerateSchedule(t *testing.T) {
 	assert.Equal(t, "Alice", schedule[4].Parent)
 }
 
+// TestGenerateSchedule_Cancelled verifies that the generation stops once the caller's context is cancelled
+func TestGenerateSchedule_Cancelled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(createTestConfigStore(), tracker)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 7, 0, 0, 0, 0, time.UTC)
+	schedule, err := scheduler.GenerateSchedule(ctx, start, end, end)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, schedule)
+
+	assignments, err := tracker.GetAssignmentsInRange(t.Context(), start, end)
+	require.NoError(t, err)
+	assert.Empty(t, assignments, "no day was assigned")
+}
+
+// TestGenerateSchedule_ClockChange verifies that every calendar day gets exactly one night when
+// the range spans a daylight saving time change and ends earlier in the day than it starts
+func TestGenerateSchedule_ClockChange(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(createTestConfigStore(), tracker)
+
+	// The clocks move back on the night of October 25, 2026
+	start := time.Date(2026, 10, 23, 21, 0, 0, 0, paris)
+	end := time.Date(2026, 10, 27, 8, 0, 0, 0, paris)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, end, start)
+	require.NoError(t, err)
+
+	days := make([]string, 0, len(schedule))
+	for _, a := range schedule {
+		days = append(days, a.Date.Format("2006-01-02"))
+	}
+	assert.Equal(t, []string{"2026-10-23", "2026-10-24", "2026-10-25", "2026-10-26", "2026-10-27"}, days)
+}
+
 // TestGetAssignmentsInRange verifies that GetAssignmentsInRange is a read-only
 // operation: it returns previously-generated assignments with correct field
 // mapping (ParentType, CaregiverType, DecisionReason, Override) but does not
@@ -55,12 +105,12 @@ func TestGetAssignmentsInRange(t *testing.T) {
 	// Generate assignments for 3 days (Sun–Tue).
 	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
-	generated, err := sched.GenerateSchedule(start, end, end)
+	generated, err := sched.GenerateSchedule(t.Context(), start, end, end)
 	assert.NoError(t, err)
 	assert.Len(t, generated, 3)
 
 	// Reading back the same range must return exactly the same assignments.
-	read, err := sched.GetAssignmentsInRange(start, end)
+	read, err := sched.GetAssignmentsInRange(t.Context(), start, end)
 	assert.NoError(t, err)
 	assert.Len(t, read, 3)
 	for i, a := range read {
@@ -75,7 +125,7 @@ func TestGetAssignmentsInRange(t *testing.T) {
 	// Querying a range with no assignments returns an empty slice, not new ones.
 	future := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
 	futureEnd := time.Date(2023, 6, 7, 0, 0, 0, 0, time.UTC)
-	empty, err := sched.GetAssignmentsInRange(future, futureEnd)
+	empty, err := sched.GetAssignmentsInRange(t.Context(), future, futureEnd)
 	assert.NoError(t, err)
 	assert.Empty(t, empty, "GetAssignmentsInRange must not create assignments for empty ranges")
 }
@@ -98,15 +148,15 @@ func TestGenerateScheduleWithPriorAssignments(t *testing.T) {
 	dayAfter := time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)  // Thursday - Bob unavailable
 
 	// Add some prior assignments (Alice did the day before, Bob did yesterday)
-	_, err = tracker.RecordAssignment("Alice", dayBefore, false, "")
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", dayBefore, false, "")
 	assert.NoError(t, err)
 	// On Monday, Alice is unavailable, so Bob would be assigned
-	_, err = tracker.RecordAssignment("Bob", yesterday, false, fairness.DecisionReasonUnavailability)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", yesterday, false, fairness.DecisionReasonUnavailability)
 	assert.NoError(t, err)
 
 	// Test period: 3 days starting from today (Tuesday)
 	// Use the end date (dayAfter) as the "current time" for the test
-	schedule, err := scheduler.GenerateSchedule(today, dayAfter, dayAfter)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), today, dayAfter, dayAfter)
 	assert.NoError(t, err)
 	assert.Len(t, schedule, 3)
 
@@ -136,8 +186,8 @@ func TestDetermineAssignmentForDate(t *testing.T) {
 
 	// Get empty stats and assignments for testing
 	stats := make(map[string]fairness.Stats)
-	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
-	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
+	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
+	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
 
 	var lastAssignments []*fairness.Assignment
 
@@ -173,24 +223,24 @@ func TestAssignForDate(t *testing.T) {
 	cfg := testScheduleConfig(store)
 
 	// Monday: Alice is unavailable, so Bob should be assigned
-	assignment, err := scheduler.assignForDate(monday, cfg)
+	assignment, err := scheduler.assignForDate(t.Context(), monday, cfg)
 	assert.NoError(t, err)
 	assert.Equal(t, "Bob", assignment.Parent)
 
 	// Verify the assignment was recorded
-	recordedAssignments, err := tracker.GetLastAssignmentsUntil(1, time.Now())
+	recordedAssignments, err := tracker.GetLastAssignmentsUntil(t.Context(), 1, time.Now())
 	assert.NoError(t, err)
 	assert.Len(t, recordedAssignments, 1)
 	assert.Equal(t, "Bob", recordedAssignments[0].Parent)
 	assert.Equal(t, monday.Format("2006-01-02"), recordedAssignments[0].Date.Format("2006-01-02"))
 
 	// Thursday: Bob is unavailable, so Alice should be assigned
-	assignment, err = scheduler.assignForDate(thursday, cfg)
+	assignment, err = scheduler.assignForDate(t.Context(), thursday, cfg)
 	assert.NoError(t, err)
 	assert.Equal(t, "Alice", assignment.Parent)
 
 	// Verify the assignment was recorded
-	recordedAssignments, err = tracker.GetLastAssignmentsUntil(2, time.Now())
+	recordedAssignments, err = tracker.GetLastAssignmentsUntil(t.Context(), 2, time.Now())
 	assert.NoError(t, err)
 	assert.Len(t, recordedAssignments, 2)
 	// The most recent assignment should be first
@@ -207,15 +257,16 @@ func TestDetermineNextParent(t *testing.T) {
 	tracker, err := fairness.New(db)
 	assert.NoError(t, err)
 	scheduler := New(store, tracker)
+	roster := []string{"Alice", "Bob"}
 
 	// Test with no prior assignments
 	stats := make(map[string]fairness.Stats)
-	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
-	stats["Bob"] = fairness.Stats{TotalAssignments: 12, Last30Days: 5}
+	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
+	stats["Bob"] = fairness.Stats{TotalAssignments: 12, Last30Days: 5, WeightedTotal: 12, WeightedLast30: 5}
 
 	// Alice should be chosen because she has fewer total assignments
 	scheduleDate := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
-	parent, reason := scheduler.determineNextParent(scheduleDate, "Alice", "Bob", []*fairness.Assignment{}, stats)
+	parent, reason := scheduler.determineNextParent(scheduleDate, roster, roster, []*fairness.Assignment{}, stats, 0)
 	assert.Equal(t, "Alice", parent)
 	assert.Equal(t, fairness.DecisionReasonTotalCount, reason)
 
@@ -231,30 +282,30 @@ func TestDetermineNextParent(t *testing.T) {
 	}
 
 	// Bob chosen: Alice has fewer total, Alice == last parent → TotalCount still picks Alice (no avoidance).
-	parent, reason = scheduler.determineNextParent(scheduleDate, "Alice", "Bob", lastAssignments, stats)
+	parent, reason = scheduler.determineNextParent(scheduleDate, roster, roster, lastAssignments, stats, 0)
 	assert.Equal(t, "Alice", parent)
 	assert.Equal(t, fairness.DecisionReasonTotalCount, reason)
 
 	// Test with recent count imbalance — fewer-recent parent equals last parent,
 	// TotalCount is tied so RecentCount picks the parent with fewer recent.
-	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 7}
-	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
+	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 7, WeightedTotal: 10, WeightedLast30: 7}
+	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
 
 	singleAssignment := []*fairness.Assignment{
 		{Parent: "Bob", Date: yesterday, CaregiverType: fairness.CaregiverTypeParent},
 	}
 
 	// Bob chosen: totals tied, Bob has fewer recent → RecentCount → Bob.
-	parent, reason = scheduler.determineNextParent(scheduleDate, "Alice", "Bob", singleAssignment, stats)
+	parent, reason = scheduler.determineNextParent(scheduleDate, roster, roster, singleAssignment, stats, 0)
 	assert.Equal(t, "Bob", parent)
 	assert.Equal(t, fairness.DecisionReasonRecentCount, reason)
 
 	// Test with significant monthly imbalance — RecentCount picks fewer-recent parent
-	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 9}
-	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
+	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 9, WeightedTotal: 10, WeightedLast30: 9}
+	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
 
 	// Bob chosen: totals tied, Bob has fewer recent → RecentCount → Bob.
-	parent, reason = scheduler.determineNextParent(scheduleDate, "Alice", "Bob", singleAssignment, stats)
+	parent, reason = scheduler.determineNextParent(scheduleDate, roster, roster, singleAssignment, stats, 0)
 	assert.Equal(t, "Bob", parent)
 	assert.Equal(t, fairness.DecisionReasonRecentCount, reason)
 }
@@ -273,8 +324,8 @@ func TestBothParentsUnavailable(t *testing.T) {
 	wednesday := time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC) // Wednesday
 
 	stats := make(map[string]fairness.Stats)
-	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
-	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
+	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
+	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
 
 	cfg := testScheduleConfig(store)
 
@@ -292,11 +343,12 @@ func TestAlternatingAssignments(t *testing.T) {
 	tracker, err := fairness.New(db)
 	assert.NoError(t, err)
 	scheduler := New(store, tracker)
+	roster := []string{"Alice", "Bob"}
 
 	// Create balanced stats
 	stats := make(map[string]fairness.Stats)
-	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
-	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5}
+	stats["Alice"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
+	stats["Bob"] = fairness.Stats{TotalAssignments: 10, Last30Days: 5, WeightedTotal: 10, WeightedLast30: 5}
 
 	scheduleDate := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
 	yesterday := scheduleDate.AddDate(0, 0, -1)
@@ -307,7 +359,7 @@ func TestAlternatingAssignments(t *testing.T) {
 	}
 
 	// Next should be Bob
-	parent, reason := scheduler.determineNextParent(scheduleDate, "Alice", "Bob", lastAssignments, stats)
+	parent, reason := scheduler.determineNextParent(scheduleDate, roster, roster, lastAssignments, stats, 0)
 	assert.Equal(t, "Bob", parent)
 	assert.Equal(t, fairness.DecisionReasonAlternating, reason)
 
@@ -317,7 +369,7 @@ func TestAlternatingAssignments(t *testing.T) {
 	}
 
 	// Next should be Alice
-	parent, reason = scheduler.determineNextParent(scheduleDate, "Alice", "Bob", lastAssignments, stats)
+	parent, reason = scheduler.determineNextParent(scheduleDate, roster, roster, lastAssignments, stats, 0)
 	assert.Equal(t, "Alice", parent)
 	assert.Equal(t, fairness.DecisionReasonAlternating, reason)
 }
@@ -341,19 +393,19 @@ func TestGenerateScheduleWithCurrentTimeFiltering(t *testing.T) {
 	currentTime := day2 // Set current time to day2
 
 	// Record initial assignments
-	_, err = tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonAlternating) // Past, not overridden -> Fixed
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonAlternating) // Past, not overridden -> Fixed
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", day2, false, fairness.DecisionReasonAlternating) // Present, not overridden -> Fixed
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", day2, false, fairness.DecisionReasonAlternating) // Present, not overridden -> Fixed
 	assert.NoError(t, err)
 	// Record a future assignment that should be ignored unless overridden
-	initialDay3Assignment, err := tracker.RecordAssignment("Alice", day3, false, fairness.DecisionReasonAlternating)
+	initialDay3Assignment, err := tracker.RecordAssignment(t.Context(), "Alice", day3, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
 	// Now override the future assignment by updating the existing record
-	err = tracker.UpdateAssignmentParent(initialDay3Assignment.ID, "Bob", true) // Future, but overridden -> Fixed
+	err = tracker.UpdateAssignmentParent(t.Context(), initialDay3Assignment.ID, "Bob", true, initialDay3Assignment.Version) // Future, but overridden -> Fixed
 	assert.NoError(t, err)
 
 	// Generate schedule for day1 to day3, with currentTime being day2
-	schedule, err := scheduler.GenerateSchedule(day1, day3, currentTime)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), day1, day3, currentTime)
 	assert.NoError(t, err)
 	assert.Len(t, schedule, 3)
 
@@ -374,7 +426,7 @@ func TestGenerateScheduleWithCurrentTimeFiltering(t *testing.T) {
 	assert.Equal(t, day3.Format("2006-01-02"), schedule[2].Date.Format("2006-01-02"))
 	// The reason should reflect the override status when fetched
 	// Let's fetch the assignment directly to check the reason stored vs generated
-	finalDay3Assignment, err := tracker.GetAssignmentByID(initialDay3Assignment.ID)
+	finalDay3Assignment, err := tracker.GetAssignmentByID(t.Context(), initialDay3Assignment.ID)
 	assert.NoError(t, err)
 	assert.True(t, finalDay3Assignment.Override) // Ensure override flag is set
 	// The generated schedule should reflect the reason of the *fixed* assignment
@@ -407,7 +459,7 @@ func TestOverrideRecalculatesFollowingDays(t *testing.T) {
 
 	// Step 1: Generate initial schedule (before any override)
 	// Current time is Wednesday, generating schedule for Wed-Sun
-	initialSchedule, err := scheduler.GenerateSchedule(wed, sun, wed)
+	initialSchedule, err := scheduler.GenerateSchedule(t.Context(), wed, sun, wed)
 	assert.NoError(t, err)
 	assert.Len(t, initialSchedule, 5)
 
@@ -421,16 +473,16 @@ func TestOverrideRecalculatesFollowingDays(t *testing.T) {
 
 	// Step 2: User overrides Saturday to Alice (instead of Bob)
 	// This creates consecutive assignments: Fri=Alice, Sat=Alice (override)
-	satAssignment, err := tracker.GetAssignmentByDate(sat)
+	satAssignment, err := tracker.GetAssignmentByDate(t.Context(), sat)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentParent(satAssignment.ID, "Alice", true)
+	err = tracker.UpdateAssignmentParent(t.Context(), satAssignment.ID, "Alice", true, satAssignment.Version)
 	assert.NoError(t, err)
 
 	// Step 3: Regenerate schedule with current time = Saturday (the override day)
 	// Sunday should be recalculated to Bob
 	// Stats after override: Alice=3 (Wed, Fri, Sat), Bob=1 (Thu)
 	// Bob has fewer total assignments, so Bob is chosen
-	newSchedule, err := scheduler.GenerateSchedule(wed, sun, sat)
+	newSchedule, err := scheduler.GenerateSchedule(t.Context(), wed, sun, sat)
 	assert.NoError(t, err)
 	assert.Len(t, newSchedule, 5)
 
@@ -475,7 +527,7 @@ func TestOverrideOnPastDayRecalculatesFollowingDays(t *testing.T) {
 	day4 := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC) // Sunday (today = currentDay)
 
 	// Step 1: Generate initial schedule from day1 to day4, with current time at day1
-	initialSchedule, err := scheduler.GenerateSchedule(day1, day4, day1)
+	initialSchedule, err := scheduler.GenerateSchedule(t.Context(), day1, day4, day1)
 	assert.NoError(t, err)
 	assert.Len(t, initialSchedule, 4)
 
@@ -487,15 +539,15 @@ func TestOverrideOnPastDayRecalculatesFollowingDays(t *testing.T) {
 
 	// Step 2: Override day3 (Saturday) to Bob (same as day2)
 	// Now we have: day2=Bob, day3=Bob (override) - two consecutive Bob days
-	day3Assignment, err := tracker.GetAssignmentByDate(day3)
+	day3Assignment, err := tracker.GetAssignmentByDate(t.Context(), day3)
 	assert.NoError(t, err)
-	err = tracker.UpdateAssignmentParent(day3Assignment.ID, "Bob", true)
+	err = tracker.UpdateAssignmentParent(t.Context(), day3Assignment.ID, "Bob", true, day3Assignment.Version)
 	assert.NoError(t, err)
 
 	// Step 3: Regenerate with current time = day4 (today)
 	// The override is on day3 (yesterday), day4 (today) should be recalculated
 	// Alice has fewer total assignments (1) than Bob (2), so Alice is chosen
-	newSchedule, err := scheduler.GenerateSchedule(day1, day4, day4)
+	newSchedule, err := scheduler.GenerateSchedule(t.Context(), day1, day4, day4)
 	assert.NoError(t, err)
 	assert.Len(t, newSchedule, 4)
 
@@ -529,11 +581,11 @@ func TestTotalCountCorrectsImbalanceAtMonthBoundary(t *testing.T) {
 	jan30 := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
 	jan31 := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
 
-	_, err = tracker.RecordAssignment("Alice", jan29, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", jan29, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Alice", jan30, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", jan30, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", jan31, false, fairness.DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", jan31, false, fairness.DecisionReasonAlternating)
 	assert.NoError(t, err)
 
 	// State: Alice=2, Bob=1. Last = Bob (Jan 31).
@@ -541,7 +593,7 @@ func TestTotalCountCorrectsImbalanceAtMonthBoundary(t *testing.T) {
 	feb1 := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
 	feb3 := time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)
 
-	schedule, err := sched.GenerateSchedule(feb1, feb3, feb1)
+	schedule, err := sched.GenerateSchedule(t.Context(), feb1, feb3, feb1)
 	assert.NoError(t, err)
 	assert.Len(t, schedule, 3)
 
@@ -578,17 +630,17 @@ func TestTotalCountWithImbalance(t *testing.T) {
 		sched := New(store, tracker)
 
 		// Record: Alice=2, Bob=1 (Alice has more). Last assignment = Alice.
-		_, err = tracker.RecordAssignment("Alice", day1, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Alice", day1, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Bob", day2, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Bob", day2, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Alice", day3, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Alice", day3, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
 
 		// State: Alice=2, Bob=1. Last = Alice(day3).
 		// day4: TotalCount wants Bob (fewer) → Bob.
 		// day5: Alice=2, Bob=2 → tied. Alternate from Bob → Alice.
-		schedule, err := sched.GenerateSchedule(day4, day5, day4)
+		schedule, err := sched.GenerateSchedule(t.Context(), day4, day5, day4)
 		assert.NoError(t, err)
 		assert.Len(t, schedule, 2)
 
@@ -606,16 +658,16 @@ func TestTotalCountWithImbalance(t *testing.T) {
 		sched := New(store, tracker)
 
 		// Record: Alice=1, Bob=2. Last = Alice (day3).
-		_, err = tracker.RecordAssignment("Bob", day1, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Bob", day1, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Bob", day2, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Bob", day2, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Alice", day3, false, fairness.DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Alice", day3, false, fairness.DecisionReasonAlternating)
 		assert.NoError(t, err)
 
 		// State: Alice=1, Bob=2. Last = Alice(day3).
 		// day4: TotalCount wants Alice (fewer). Alice == last → consecutive allowed.
-		schedule, err := sched.GenerateSchedule(day4, day5, day4)
+		schedule, err := sched.GenerateSchedule(t.Context(), day4, day5, day4)
 		assert.NoError(t, err)
 		assert.Len(t, schedule, 2)
 
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnavailabilityRules verifies that the days of a parent's recurring unavailability rules go to
+// the other parent, and only those days
+func TestUnavailabilityRules(t *testing.T) {
+	everyOtherFriday, err := config.ParseUnavailabilityRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-01-02")
+	require.NoError(t, err)
+	firstMonday, err := config.ParseUnavailabilityRule("FREQ=MONTHLY;BYDAY=1MO")
+	require.NoError(t, err)
+
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	store.parentARules = []config.UnavailabilityRule{everyOtherFriday}
+	store.parentBRules = []config.UnavailabilityRule{firstMonday}
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)
+	schedule, err := scheduler.GenerateSchedule(t.Context(), start, end, start)
+	require.NoError(t, err)
+
+	byDate := make(map[string]*Assignment, len(schedule))
+	for _, assignment := range schedule {
+		byDate[assignment.Date.Format("2006-01-02")] = assignment
+	}
+
+	for _, day := range []string{"2026-01-02", "2026-01-16", "2026-01-30", "2026-02-13", "2026-02-27"} {
+		require.Contains(t, byDate, day)
+		assert.Equal(t, "Bob", byDate[day].Parent, "Alice is off every other Friday, %s", day)
+		assert.Equal(t, fairness.DecisionReasonUnavailability, byDate[day].DecisionReason, day)
+	}
+	for _, day := range []string{"2026-01-05", "2026-02-02"} {
+		require.Contains(t, byDate, day)
+		assert.Equal(t, "Alice", byDate[day].Parent, "Bob is off the first Monday of the month, %s", day)
+		assert.Equal(t, fairness.DecisionReasonUnavailability, byDate[day].DecisionReason, day)
+	}
+	for _, day := range []string{"2026-01-09", "2026-01-12", "2026-02-06"} {
+		require.Contains(t, byDate, day)
+		assert.NotEqual(t, fairness.DecisionReasonUnavailability, byDate[day].DecisionReason, "%s is outside the rules", day)
+	}
+}
+
+// TestUnavailabilityRules_BothParentsUnavailable verifies that a day on which both parents are
+// unavailable, from a rule and a day of the week, fails the schedule
+func TestUnavailabilityRules_BothParentsUnavailable(t *testing.T) {
+	firstMonday, err := config.ParseUnavailabilityRule("FREQ=MONTHLY;BYDAY=1MO")
+	require.NoError(t, err)
+
+	store := newTestConfigStore("Alice", "Bob", []string{"Monday"}, []string{})
+	store.parentBRules = []config.UnavailabilityRule{firstMonday}
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	monday := time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
+	_, err = scheduler.GenerateSchedule(t.Context(), monday, monday, monday)
+	assert.ErrorContains(t, err, "all parents unavailable on Monday 2026-02-02")
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVacationClearsUpcomingDays verifies that the vacation days from the current day on get no
+// assignment, that the assignments already recorded on them are deleted, overrides included, and
+// that the schedule resumes after the vacation from the fairness counters left before it.
+func TestVacationClearsUpcomingDays(t *testing.T) {
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	wed := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	thu := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	fri := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	sun := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	_, err = scheduler.GenerateSchedule(t.Context(), wed, sun, wed)
+	require.NoError(t, err)
+	friAssignment, err := tracker.GetAssignmentByDate(t.Context(), fri)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), friAssignment.ID, "Bob", true, friAssignment.Version))
+
+	store.vacation = config.Vacation{Enabled: true, Start: thu, End: fri}
+	schedule, err := scheduler.GenerateSchedule(t.Context(), wed, sun, wed)
+	require.NoError(t, err)
+
+	require.Len(t, schedule, 3, "no assignment on Thursday and Friday")
+	assert.Equal(t, wed, schedule[0].Date)
+	assert.Equal(t, "Alice", schedule[0].Parent, "Wed is today and kept")
+	assert.Equal(t, "Bob", schedule[1].Parent, "the schedule resumes from Wednesday")
+	assert.Equal(t, "Alice", schedule[2].Parent)
+
+	for _, day := range []time.Time{thu, fri} {
+		assignment, err := tracker.GetAssignmentByDate(t.Context(), day)
+		require.NoError(t, err)
+		assert.Nil(t, assignment, "assignment of %s deleted", day.Format("2006-01-02"))
+	}
+
+	stats, err := tracker.GetParentStatsUntil(t.Context(), sun.AddDate(0, 0, 1), "Alice", "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats["Alice"].TotalAssignments)
+	assert.Equal(t, 1, stats["Bob"].TotalAssignments, "vacation nights count for nobody")
+}
+
+// TestVacationKeepsPastDays verifies that nights of a vacation which already happened are kept
+func TestVacationKeepsPastDays(t *testing.T) {
+	store := newTestConfigStore("Alice", "Bob", []string{}, []string{})
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	scheduler := New(store, tracker)
+
+	wed := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	fri := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	sun := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	_, err = scheduler.GenerateSchedule(t.Context(), wed, sun, wed)
+	require.NoError(t, err)
+
+	store.vacation = config.Vacation{Enabled: true, Start: wed, End: sun}
+	schedule, err := scheduler.GenerateSchedule(t.Context(), wed, sun, fri)
+	require.NoError(t, err)
+
+	for _, a := range schedule {
+		assert.True(t, a.Date.Before(fri), "no night from today on, got %s", a.Date.Format("2006-01-02"))
+	}
+	assignments, err := tracker.GetAssignmentsInRange(t.Context(), wed, sun)
+	require.NoError(t, err)
+	require.Len(t, assignments, 2, "Wednesday and Thursday already happened")
+	assert.Equal(t, wed, assignments[0].Date)
+	assert.Equal(t, fri.AddDate(0, 0, -1), assignments[1].Date)
+}
@@ -123,7 +123,7 @@ func TestAssignForDateLongPeriods(t *testing.T) {
 			for day := 0; day < tc.days; day++ {
 				date := startDate.AddDate(0, 0, day)
 
-				assignment, err := scheduler.assignForDate(date, cfg)
+				assignment, err := scheduler.assignForDate(t.Context(), date, cfg)
 				assert.NoError(t, err)
 
 				// Count the assignment
@@ -209,7 +209,7 @@ func TestBalanceOverLongPeriods(t *testing.T) {
 			for day := 0; day < tc.days; day++ {
 				date := startDate.AddDate(0, 0, day)
 
-				assignment, err := scheduler.assignForDate(date, cfg)
+				assignment, err := scheduler.assignForDate(t.Context(), date, cfg)
 				assert.NoError(t, err)
 				actualAssignments[assignment.Parent]++
 			}
@@ -253,7 +253,7 @@ func TestUnavailabilityImbalanceCorrectedByTotalCount(t *testing.T) {
 	var results []dayResult
 	for day := range 14 {
 		date := startDate.AddDate(0, 0, day)
-		a, err := scheduler.assignForDate(date, cfg)
+		a, err := scheduler.assignForDate(t.Context(), date, cfg)
 		assert.NoError(t, err)
 		results = append(results, dayResult{Parent: a.Parent, DecisionReason: a.DecisionReason})
 	}
@@ -342,7 +342,7 @@ func TestAssignForDateWithSpecificDays(t *testing.T) {
 			cfg := testScheduleConfig(store)
 
 			// Assign for the specific date
-			assignment, err := scheduler.assignForDate(tc.date, cfg)
+			assignment, err := scheduler.assignForDate(t.Context(), tc.date, cfg)
 			assert.NoError(t, err)
 
 			// Verify the assignment matches the expected parent
@@ -3,6 +3,7 @@ package scheduler
 import (
 	"testing"
 
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/database"
 	"github.com/stretchr/testify/assert"
@@ -16,23 +17,77 @@ type testConfigStore struct {
 	parentB            string
 	parentAUnavailable []string
 	parentBUnavailable []string
+	parentARules       []config.UnavailabilityRule
+	parentBRules       []config.UnavailabilityRule
+	extra              []config.ExtraParent
+	extraUnavailable   map[string][]string // Keyed by parent key, e.g. "parent_c"
+	vacation           config.Vacation
+	skipDates          config.SkipDates
+	minRestDays        int
+	dayWeights         config.DayWeights
 }
 
 func (s *testConfigStore) GetParents() (string, string, error) {
 	return s.parentA, s.parentB, nil
 }
 
+func (s *testConfigStore) GetExtraParents() ([]config.ExtraParent, error) {
+	return s.extra, nil
+}
+
 func (s *testConfigStore) GetAvailability(parent string) ([]string, error) {
-	if parent == "parent_a" {
+	switch parent {
+	case "parent_a":
 		return s.parentAUnavailable, nil
+	case "parent_b":
+		return s.parentBUnavailable, nil
+	}
+	return s.extraUnavailable[parent], nil
+}
+
+func (s *testConfigStore) GetUnavailabilityRules(parent string) ([]config.UnavailabilityRule, error) {
+	switch parent {
+	case "parent_a":
+		return s.parentARules, nil
+	case "parent_b":
+		return s.parentBRules, nil
 	}
-	return s.parentBUnavailable, nil
+	return nil, nil
 }
 
 func (s *testConfigStore) GetSchedule() (string, int, int, constants.StatsOrder, error) {
 	return "weekly", 7, 5, constants.StatsOrderDesc, nil
 }
 
+func (s *testConfigStore) GetLookAheadWindows() (config.LookAheadWindows, error) {
+	return config.LookAheadWindows{}, nil
+}
+
+func (s *testConfigStore) GetVacation() (config.Vacation, error) {
+	return s.vacation, nil
+}
+
+func (s *testConfigStore) GetSkipDates() (config.SkipDates, error) {
+	return s.skipDates, nil
+}
+
+func (s *testConfigStore) GetSyncExclusions() (config.SyncExclusions, error) {
+	return config.SyncExclusions{}, nil
+}
+
+func (s *testConfigStore) GetMinRestDays() (int, error) {
+	return s.minRestDays, nil
+}
+
+func (s *testConfigStore) GetDayWeights() (config.DayWeights, error) {
+	return s.dayWeights, nil
+}
+
+func (s *testConfigStore) GetParentStyles() (config.ParentStyle, config.ParentStyle, error) {
+	parentA, parentB := config.DefaultParentStyles()
+	return parentA, parentB, nil
+}
+
 func (s *testConfigStore) GetOAuthConfig() *oauth2.Config {
 	return nil
 }
@@ -50,11 +105,18 @@ func newTestConfigStore(parentA, parentB string, parentAUnavailable, parentBUnav
 // testScheduleConfig builds a scheduleConfig from a testConfigStore for tests
 // that call assignForDate or determineParentForDate directly.
 func testScheduleConfig(store *testConfigStore) *scheduleConfig {
+	parents := config.RosterOf(store.parentA, store.parentB, store.extra)
+	availability := make(map[string]parentAvailability, len(parents))
+	for i, parent := range parents {
+		days, _ := store.GetAvailability(config.ParentKey(i))
+		rules, _ := store.GetUnavailabilityRules(config.ParentKey(i))
+		availability[parent] = parentAvailability{days: days, rules: rules}
+	}
 	return &scheduleConfig{
-		parentA:            store.parentA,
-		parentB:            store.parentB,
-		parentAUnavailable: store.parentAUnavailable,
-		parentBUnavailable: store.parentBUnavailable,
+		parents:      parents,
+		availability: availability,
+		minRestDays:  store.minRestDays,
+		dayWeights:   store.dayWeights,
 	}
 }
 
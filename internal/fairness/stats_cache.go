@@ -0,0 +1,162 @@
+package fairness
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// MonthlyStatsProvider serves the monthly assignment counts shown on the statistics page
+type MonthlyStatsProvider interface {
+	GetParentMonthlyStatsForLastNMonths(ctx context.Context, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error)
+	GetBabysitterMonthlyStatsForLastNMonths(ctx context.Context, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error)
+}
+
+var _ MonthlyStatsProvider = (*Tracker)(nil)
+
+// statsKey identifies the counts of one month of a caregiver type
+type statsKey struct {
+	caregiverType CaregiverType
+	through       string // Last day counted, YYYY-MM-DD: the end of the month, or the reference day for the current month
+}
+
+// month returns the YYYY-MM month of the key
+func (k statsKey) month() string {
+	return k.through[:len("2006-01")]
+}
+
+// statsEntry holds the counts of one month
+type statsEntry struct {
+	rows    []MonthlyStatRow
+	expires time.Time
+}
+
+// StatsCache serves the monthly statistics from memory, one entry per month. Entries expire
+// after the TTL and the months of written assignments are dropped by Invalidate, so a schedule
+// sync writing future nights keeps the past months cached. Errors are never cached.
+type StatsCache struct {
+	source MonthlyStatsProvider
+	ttl    time.Duration
+	now    func() time.Time
+	logger zerolog.Logger
+
+	mu sync.Mutex
+	// generation changes on every invalidation, so that counts loaded while an
+	// assignment was being written are not stored over the invalidation
+	generation uint64
+	months     map[statsKey]statsEntry
+}
+
+var _ MonthlyStatsProvider = (*StatsCache)(nil)
+
+// NewStatsCache creates a cache in front of source keeping the monthly counts for ttl
+func NewStatsCache(source MonthlyStatsProvider, ttl time.Duration) *StatsCache {
+	return &StatsCache{
+		source: source,
+		ttl:    ttl,
+		now:    time.Now,
+		logger: logging.GetLogger("stats-cache"),
+		months: make(map[statsKey]statsEntry),
+	}
+}
+
+// Invalidate drops the cached counts of the month of date, or every month for a zero date
+func (c *StatsCache) Invalidate(date time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+
+	if date.IsZero() {
+		clear(c.months)
+		c.logger.Debug().Msg("Statistics cache invalidated")
+		return
+	}
+	month := date.Format("2006-01")
+	for key := range c.months {
+		if key.month() == month {
+			delete(c.months, key)
+		}
+	}
+	c.logger.Debug().Str("month", month).Msg("Statistics cache invalidated for month")
+}
+
+// GetParentMonthlyStatsForLastNMonths implements MonthlyStatsProvider
+func (c *StatsCache) GetParentMonthlyStatsForLastNMonths(ctx context.Context, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error) {
+	return c.get(ctx, CaregiverTypeParent, referenceTime, nMonths, c.source.GetParentMonthlyStatsForLastNMonths)
+}
+
+// GetBabysitterMonthlyStatsForLastNMonths implements MonthlyStatsProvider
+func (c *StatsCache) GetBabysitterMonthlyStatsForLastNMonths(ctx context.Context, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error) {
+	return c.get(ctx, CaregiverTypeBabysitter, referenceTime, nMonths, c.source.GetBabysitterMonthlyStatsForLastNMonths)
+}
+
+// get serves the counts from the cache when every month is present, otherwise loads the whole range
+func (c *StatsCache) get(ctx context.Context, caregiverType CaregiverType, referenceTime time.Time, nMonths int, load func(context.Context, time.Time, int) ([]MonthlyStatRow, error)) ([]MonthlyStatRow, error) {
+	keys := monthKeys(caregiverType, referenceTime, nMonths)
+
+	c.mu.Lock()
+	now := c.now()
+	generation := c.generation
+	var rows []MonthlyStatRow
+	hit := true
+	for _, key := range keys {
+		entry, ok := c.months[key]
+		if !ok || now.After(entry.expires) {
+			hit = false
+			break
+		}
+		rows = append(rows, entry.rows...)
+	}
+	c.mu.Unlock()
+	if hit {
+		c.logger.Debug().Str("caregiver_type", caregiverType.String()).Int("n_months", nMonths).Msg("Statistics served from cache")
+		return rows, nil
+	}
+
+	rows, err := load(ctx, referenceTime, nMonths)
+	if err != nil {
+		return nil, err
+	}
+	c.store(generation, keys, rows)
+	return rows, nil
+}
+
+// store caches rows split per month unless the cache was invalidated since generation was read
+func (c *StatsCache) store(generation uint64, keys []statsKey, rows []MonthlyStatRow) {
+	byMonth := make(map[string][]MonthlyStatRow, len(keys))
+	for _, row := range rows {
+		byMonth[row.MonthYear] = append(byMonth[row.MonthYear], row)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return
+	}
+	now := c.now()
+	for key, entry := range c.months {
+		if now.After(entry.expires) {
+			delete(c.months, key)
+		}
+	}
+	expires := now.Add(c.ttl)
+	for _, key := range keys {
+		c.months[key] = statsEntry{rows: byMonth[key.month()], expires: expires}
+	}
+}
+
+// monthKeys returns the keys of the nMonths months ending with the month of referenceTime, oldest first.
+// The months are counted like GetParentMonthlyStatsForLastNMonths: whole months, and the current one up to
+// the reference day.
+func monthKeys(caregiverType CaregiverType, referenceTime time.Time, nMonths int) []statsKey {
+	startOfCurrentMonth := time.Date(referenceTime.Year(), referenceTime.Month(), 1, 0, 0, 0, 0, referenceTime.Location())
+	keys := make([]statsKey, 0, nMonths)
+	for i := nMonths - 1; i > 0; i-- {
+		lastDay := startOfCurrentMonth.AddDate(0, -i+1, -1)
+		keys = append(keys, statsKey{caregiverType: caregiverType, through: lastDay.Format(dateFormat)})
+	}
+	return append(keys, statsKey{caregiverType: caregiverType, through: referenceTime.Format(dateFormat)})
+}
@@ -0,0 +1,199 @@
+package fairness
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStatsSource counts the loads and returns fixed rows
+type fakeStatsSource struct {
+	rows   []MonthlyStatRow
+	err    error
+	loads  int
+	onLoad func()
+}
+
+func (f *fakeStatsSource) load() ([]MonthlyStatRow, error) {
+	f.loads++
+	if f.onLoad != nil {
+		f.onLoad()
+	}
+	return f.rows, f.err
+}
+
+func (f *fakeStatsSource) GetParentMonthlyStatsForLastNMonths(context.Context, time.Time, int) ([]MonthlyStatRow, error) {
+	return f.load()
+}
+
+func (f *fakeStatsSource) GetBabysitterMonthlyStatsForLastNMonths(context.Context, time.Time, int) ([]MonthlyStatRow, error) {
+	return f.load()
+}
+
+func newTestStatsCache(source MonthlyStatsProvider, now *time.Time) *StatsCache {
+	cache := NewStatsCache(source, 10*time.Minute)
+	cache.now = func() time.Time { return *now }
+	return cache
+}
+
+func TestStatsCache_ServesFromCache(t *testing.T) {
+	source := &fakeStatsSource{rows: []MonthlyStatRow{
+		{MonthYear: "2026-08", ParentName: "Alice", Count: 15},
+		{MonthYear: "2026-08", ParentName: "Bob", Count: 16},
+		{MonthYear: "2026-10", ParentName: "Alice", Count: 8},
+	}}
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	cache := newTestStatsCache(source, &now)
+
+	first, err := cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 3)
+	require.NoError(t, err)
+	second, err := cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, source.loads)
+	assert.Equal(t, source.rows, first)
+	assert.Equal(t, source.rows, second, "months are reassembled in order")
+
+	_, err = cache.GetBabysitterMonthlyStatsForLastNMonths(t.Context(), now, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.loads, "caregiver types are cached separately")
+}
+
+func TestStatsCache_Invalidate(t *testing.T) {
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		date       time.Time
+		wantReload bool
+	}{
+		{name: "month in range", date: time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC), wantReload: true},
+		{name: "current month", date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), wantReload: true},
+		{name: "future month", date: time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC), wantReload: false},
+		{name: "unknown date", date: time.Time{}, wantReload: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeStatsSource{}
+			cache := newTestStatsCache(source, &now)
+
+			_, err := cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 3)
+			require.NoError(t, err)
+			cache.Invalidate(tt.date)
+			_, err = cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 3)
+			require.NoError(t, err)
+
+			if tt.wantReload {
+				assert.Equal(t, 2, source.loads)
+			} else {
+				assert.Equal(t, 1, source.loads)
+			}
+		})
+	}
+}
+
+func TestStatsCache_Expires(t *testing.T) {
+	source := &fakeStatsSource{}
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	cache := newTestStatsCache(source, &now)
+
+	_, err := cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	now = now.Add(5 * time.Minute)
+	_, err = cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	assert.Equal(t, 1, source.loads)
+
+	now = now.Add(6 * time.Minute)
+	_, err = cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.loads, "expired months are reloaded")
+}
+
+func TestStatsCache_NewDayReloadsCurrentMonth(t *testing.T) {
+	source := &fakeStatsSource{}
+	now := time.Date(2026, 10, 16, 23, 55, 0, 0, time.UTC)
+	cache := newTestStatsCache(source, &now)
+
+	_, err := cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	now = now.Add(10*time.Minute - time.Second)
+	_, err = cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.loads, "the current month is counted up to the reference day")
+}
+
+func TestStatsCache_ErrorsAreNotCached(t *testing.T) {
+	source := &fakeStatsSource{err: errors.New("database is locked")}
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	cache := newTestStatsCache(source, &now)
+
+	_, err := cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.Error(t, err)
+	source.err = nil
+	_, err = cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	_, err = cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.loads)
+}
+
+func TestStatsCache_InvalidationDuringLoad(t *testing.T) {
+	source := &fakeStatsSource{}
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	cache := newTestStatsCache(source, &now)
+	// An assignment written while the counts are read makes them stale
+	source.onLoad = func() { cache.Invalidate(now) }
+
+	_, err := cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	source.onLoad = nil
+	_, err = cache.GetParentMonthlyStatsForLastNMonths(t.Context(), now, 12)
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.loads)
+}
+
+func TestMonthKeys(t *testing.T) {
+	reference := time.Date(2026, 3, 31, 20, 0, 0, 0, time.UTC)
+	keys := monthKeys(CaregiverTypeParent, reference, 3)
+
+	var through []string
+	for _, key := range keys {
+		through = append(through, key.through)
+	}
+	assert.Equal(t, []string{"2026-01-31", "2026-02-28", "2026-03-31"}, through)
+}
+
+func TestTracker_WritesEmitAssignmentsChanged(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var dates []time.Time
+	signals.OnAssignmentsChanged(func(_ context.Context, data signals.AssignmentsChangedData) {
+		mu.Lock()
+		defer mu.Unlock()
+		dates = append(dates, data.Date)
+	}, "test-assignments-changed")
+	defer signals.AssignmentsChanged.RemoveListener("test-assignments-changed")
+
+	date := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentToBabysitter(t.Context(), assignment.ID, "Grandma", true, assignment.Version))
+	// Setting the event ID does not change any count
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "event"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []time.Time{date, {}}, dates)
+}
@@ -3,11 +3,17 @@ package fairness
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/database"
 	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/signals"
 	"github.com/rs/zerolog"
 )
 
@@ -18,22 +24,37 @@ const (
 	defaultQueryTimeout = 30 * time.Second
 )
 
+// ErrAssignmentConflict is returned by the versioned assignment updates when the assignment was
+// changed, e.g. by a webhook override or a schedule regeneration, since the caller read it
+var ErrAssignmentConflict = errors.New("assignment was modified concurrently")
+
 // Tracker maintains the state of night routine assignments
 type Tracker struct {
 	db     *database.DB
 	logger zerolog.Logger
+	// childID is the child whose rotation the assignments belong to, 0 for the main rotation
+	childID int64
+	// revision counts the writes made through this tracker and its children, see AssignmentsVersion
+	revision *atomic.Uint64
 }
 
 // New creates a new Tracker instance
 func New(db *database.DB) (*Tracker, error) {
 	return &Tracker{
-		db:     db,
-		logger: logging.GetLogger("fairness-tracker"),
+		db:       db,
+		logger:   logging.GetLogger("fairness-tracker"),
+		revision: new(atomic.Uint64),
 	}, nil
 }
 
+// changed records a write to the assignment of date, a zero date when it is not known
+func (t *Tracker) changed(date time.Time) {
+	t.revision.Add(1)
+	signals.EmitAssignmentsChanged(context.Background(), date)
+}
+
 // RecordAssignment records a new assignment with all details
-func (t *Tracker) RecordAssignment(parent string, date time.Time, override bool, decisionReason DecisionReason) (*Assignment, error) {
+func (t *Tracker) RecordAssignment(ctx context.Context, parent string, date time.Time, override bool, decisionReason DecisionReason) (*Assignment, error) {
 	recordLogger := t.logger.With().
 		Str("date", date.Format(dateFormat)).
 		Str("parent", parent).
@@ -43,21 +64,28 @@ func (t *Tracker) RecordAssignment(parent string, date time.Time, override bool,
 	recordLogger.Debug().Msg("Recording assignment details")
 
 	// Use proper UPSERT syntax with ON CONFLICT clause
-	// This works because we have a unique index on assignment_date
+	// This works because we have a unique index on the child and the assignment_date
 	recordLogger.Debug().Msg("Using UPSERT with ON CONFLICT to create or update assignment")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
-	_, err := t.db.Conn().ExecContext(ctx, `
-	INSERT INTO assignments (parent_name, assignment_date, override, decision_reason, caregiver_type)
-	VALUES (?, ?, ?, ?, ?)
-	ON CONFLICT(assignment_date) DO UPDATE SET 
+	previous, err := t.GetAssignmentByDate(ctx, date)
+	if err != nil {
+		recordLogger.Error().Err(err).Msg("Failed to get the existing assignment")
+		return nil, err
+	}
+
+	_, err = t.db.ExecContext(ctx, `
+	INSERT INTO assignments (parent_name, assignment_date, override, decision_reason, caregiver_type, child_id)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(child_id, assignment_date) DO UPDATE SET
 		parent_name = excluded.parent_name,
 		override = excluded.override,
 		decision_reason = excluded.decision_reason,
-		caregiver_type = excluded.caregiver_type
-		`, parent, date.Format(dateFormat), override, decisionReason.String(), CaregiverTypeParent.String())
+		caregiver_type = excluded.caregiver_type,
+		version = version + 1
+		`, parent, date.Format(dateFormat), override, decisionReason.String(), CaregiverTypeParent.String(), t.childID)
 
 	if err != nil {
 		if err == context.DeadlineExceeded {
@@ -67,19 +95,24 @@ func (t *Tracker) RecordAssignment(parent string, date time.Time, override bool,
 		recordLogger.Error().Err(err).Msg("Failed to upsert assignment")
 		return nil, fmt.Errorf("failed to record assignment: %w", err)
 	}
+	t.changed(date)
 
 	// Get the full assignment record
-	assignment, err := t.GetAssignmentByDate(date)
+	assignment, err := t.GetAssignmentByDate(ctx, date)
 	if err != nil {
 		recordLogger.Debug().Err(err).Msg("Failed to get the upserted assignment")
 		return nil, fmt.Errorf("failed to get assignment by date: %w", err)
 	}
+	if previous == nil {
+		signals.EmitAssignmentCreated(context.Background(), assignment.ID, date, parent, CaregiverTypeParent.String())
+	}
+	t.journalChange(ctx, ChangeKindRegeneration, previous, caregiverState{parent, CaregiverTypeParent, override, decisionReason})
 	recordLogger.Debug().Int64("assignment_id", assignment.ID).Msg("Assignment upserted successfully")
 	return assignment, nil
 }
 
 // RecordBabysitterAssignment records a babysitter assignment for a given day.
-func (t *Tracker) RecordBabysitterAssignment(name string, date time.Time, override bool) (*Assignment, error) {
+func (t *Tracker) RecordBabysitterAssignment(ctx context.Context, name string, date time.Time, override bool) (*Assignment, error) {
 	recordLogger := t.logger.With().
 		Str("date", date.Format(dateFormat)).
 		Str("babysitter", name).
@@ -87,18 +120,25 @@ func (t *Tracker) RecordBabysitterAssignment(name string, date time.Time, overri
 		Logger()
 	recordLogger.Debug().Msg("Recording babysitter assignment details")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
-	_, err := t.db.Conn().ExecContext(ctx, `
-	INSERT INTO assignments (parent_name, assignment_date, override, decision_reason, caregiver_type)
-	VALUES (?, ?, ?, ?, ?)
-	ON CONFLICT(assignment_date) DO UPDATE SET
+	previous, err := t.GetAssignmentByDate(ctx, date)
+	if err != nil {
+		recordLogger.Error().Err(err).Msg("Failed to get the existing assignment")
+		return nil, err
+	}
+
+	_, err = t.db.ExecContext(ctx, `
+	INSERT INTO assignments (parent_name, assignment_date, override, decision_reason, caregiver_type, child_id)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(child_id, assignment_date) DO UPDATE SET
 		parent_name = excluded.parent_name,
 		override = excluded.override,
 		decision_reason = excluded.decision_reason,
-		caregiver_type = excluded.caregiver_type
-	`, name, date.Format(dateFormat), override, DecisionReasonOverride.String(), CaregiverTypeBabysitter.String())
+		caregiver_type = excluded.caregiver_type,
+		version = version + 1
+	`, name, date.Format(dateFormat), override, DecisionReasonOverride.String(), CaregiverTypeBabysitter.String(), t.childID)
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			recordLogger.Error().Err(err).Msg("Database upsert for babysitter assignment timed out")
@@ -107,29 +147,35 @@ func (t *Tracker) RecordBabysitterAssignment(name string, date time.Time, overri
 		recordLogger.Error().Err(err).Msg("Failed to upsert babysitter assignment")
 		return nil, fmt.Errorf("failed to record babysitter assignment: %w", err)
 	}
+	t.changed(date)
 
-	assignment, err := t.GetAssignmentByDate(date)
+	assignment, err := t.GetAssignmentByDate(ctx, date)
 	if err != nil {
 		recordLogger.Debug().Err(err).Msg("Failed to get the upserted babysitter assignment")
 		return nil, fmt.Errorf("failed to get assignment by date: %w", err)
 	}
+	if previous == nil {
+		signals.EmitAssignmentCreated(context.Background(), assignment.ID, date, name, CaregiverTypeBabysitter.String())
+	}
+	t.journalChange(ctx, ChangeKindRegeneration, previous, caregiverState{name, CaregiverTypeBabysitter, override, DecisionReasonOverride})
 	recordLogger.Debug().Int64("assignment_id", assignment.ID).Msg("Babysitter assignment upserted successfully")
 	return assignment, nil
 }
 
 const upsertAssignmentSQL = `
-	INSERT INTO assignments (parent_name, assignment_date, override, decision_reason, caregiver_type)
-	VALUES (?, ?, ?, ?, ?)
-	ON CONFLICT(assignment_date) DO UPDATE SET
+	INSERT INTO assignments (parent_name, assignment_date, override, decision_reason, caregiver_type, child_id)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(child_id, assignment_date) DO UPDATE SET
 		parent_name = excluded.parent_name,
 		override = excluded.override,
 		decision_reason = excluded.decision_reason,
-		caregiver_type = excluded.caregiver_type`
+		caregiver_type = excluded.caregiver_type,
+		version = version + 1`
 
 const selectAssignmentByDateSQL = `
-	SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, created_at, updated_at
+	SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, version, tag, created_at, updated_at
 	FROM assignments
-	WHERE assignment_date = ?
+	WHERE child_id = ? AND assignment_date = ?
 	ORDER BY id DESC
 	LIMIT 1`
 
@@ -137,7 +183,7 @@ const selectAssignmentByDateSQL = `
 // database transaction. Both are upserted with the new parent and the given
 // decision reason. The in-memory Assignment records are returned only after
 // the transaction commits successfully.
-func (t *Tracker) SwapAssignments(parentA string, dateA time.Time, parentB string, dateB time.Time, reason DecisionReason) (*Assignment, *Assignment, error) {
+func (t *Tracker) SwapAssignments(ctx context.Context, parentA string, dateA time.Time, parentB string, dateB time.Time, reason DecisionReason) (*Assignment, *Assignment, error) {
 	swapLogger := t.logger.With().
 		Str("parentA", parentA).Str("dateA", dateA.Format(dateFormat)).
 		Str("parentB", parentB).Str("dateB", dateB.Format(dateFormat)).
@@ -145,36 +191,45 @@ func (t *Tracker) SwapAssignments(parentA string, dateA time.Time, parentB strin
 		Logger()
 	swapLogger.Debug().Msg("Swapping assignments atomically")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
+	previousA, err := t.GetAssignmentByDate(ctx, dateA)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get assignment A (%s): %w", dateA.Format(dateFormat), err)
+	}
+	previousB, err := t.GetAssignmentByDate(ctx, dateB)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get assignment B (%s): %w", dateB.Format(dateFormat), err)
+	}
+
 	var updatedA, updatedB *Assignment
 
-	err := t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+	err = t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
 		// Upsert assignment A.
 		if _, err := tx.ExecContext(ctx, upsertAssignmentSQL,
-			parentA, dateA.Format(dateFormat), false, reason.String(), CaregiverTypeParent.String(),
+			parentA, dateA.Format(dateFormat), false, reason.String(), CaregiverTypeParent.String(), t.childID,
 		); err != nil {
 			return fmt.Errorf("failed to upsert assignment A (%s): %w", dateA.Format(dateFormat), err)
 		}
 
 		// Upsert assignment B.
 		if _, err := tx.ExecContext(ctx, upsertAssignmentSQL,
-			parentB, dateB.Format(dateFormat), false, reason.String(), CaregiverTypeParent.String(),
+			parentB, dateB.Format(dateFormat), false, reason.String(), CaregiverTypeParent.String(), t.childID,
 		); err != nil {
 			return fmt.Errorf("failed to upsert assignment B (%s): %w", dateB.Format(dateFormat), err)
 		}
 
 		// Read back both rows inside the same transaction so the returned
 		// data is guaranteed consistent with the writes.
-		rowA := tx.QueryRowContext(ctx, selectAssignmentByDateSQL, dateA.Format(dateFormat))
+		rowA := tx.QueryRowContext(ctx, selectAssignmentByDateSQL, t.childID, dateA.Format(dateFormat))
 		var scanErr error
 		updatedA, scanErr = t.scanAssignment(rowA)
 		if scanErr != nil {
 			return fmt.Errorf("failed to read back assignment A (%s): %w", dateA.Format(dateFormat), scanErr)
 		}
 
-		rowB := tx.QueryRowContext(ctx, selectAssignmentByDateSQL, dateB.Format(dateFormat))
+		rowB := tx.QueryRowContext(ctx, selectAssignmentByDateSQL, t.childID, dateB.Format(dateFormat))
 		updatedB, scanErr = t.scanAssignment(rowB)
 		if scanErr != nil {
 			return fmt.Errorf("failed to read back assignment B (%s): %w", dateB.Format(dateFormat), scanErr)
@@ -187,6 +242,12 @@ func (t *Tracker) SwapAssignments(parentA string, dateA time.Time, parentB strin
 		swapLogger.Error().Err(err).Msg("Failed to swap assignments")
 		return nil, nil, fmt.Errorf("failed to swap assignments: %w", err)
 	}
+	t.changed(dateA)
+	t.changed(dateB)
+	// Both sides of the swap are undone together
+	batchCtx := t.BeginBatch(ctx, ChangeKindSwap)
+	t.journalChange(batchCtx, ChangeKindSwap, previousA, caregiverState{parentA, CaregiverTypeParent, false, reason})
+	t.journalChange(batchCtx, ChangeKindSwap, previousB, caregiverState{parentB, CaregiverTypeParent, false, reason})
 
 	swapLogger.Debug().
 		Int64("assignment_a_id", updatedA.ID).
@@ -207,6 +268,7 @@ func (t *Tracker) scanAssignment(scanner interface {
 	var googleEventID sql.NullString
 	var decisionReason sql.NullString
 	var caregiverType sql.NullString
+	var tag sql.NullString
 
 	err := scanner.Scan(
 		&a.ID,
@@ -216,6 +278,8 @@ func (t *Tracker) scanAssignment(scanner interface {
 		&googleEventID,
 		&decisionReason,
 		&caregiverType,
+		&a.Version,
+		&tag,
 		&createdAt,
 		&updatedAt,
 	)
@@ -234,6 +298,10 @@ func (t *Tracker) scanAssignment(scanner interface {
 		a.DecisionReason = DecisionReason(decisionReason.String)
 	}
 
+	if tag.Valid {
+		a.Tag = AssignmentTag(tag.String)
+	}
+
 	if caregiverType.Valid {
 		a.CaregiverType = CaregiverType(caregiverType.String)
 	} else {
@@ -253,15 +321,15 @@ func (t *Tracker) scanAssignment(scanner interface {
 }
 
 // GetAssignmentByID retrieves an assignment by its ID
-func (t *Tracker) GetAssignmentByID(id int64) (*Assignment, error) {
+func (t *Tracker) GetAssignmentByID(ctx context.Context, id int64) (*Assignment, error) {
 	queryLogger := t.logger.With().Int64("assignment_id", id).Logger()
 	queryLogger.Debug().Msg("Getting assignment by ID")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
 	row := t.db.Conn().QueryRowContext(ctx, `
-		SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, created_at, updated_at
+		SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, version, tag, created_at, updated_at
 		FROM assignments
 		WHERE id = ?
 	`, id)
@@ -283,17 +351,17 @@ func (t *Tracker) GetAssignmentByID(id int64) (*Assignment, error) {
 }
 
 // UpdateAssignmentGoogleCalendarEventID updates an assignment with its Google Calendar event ID
-func (t *Tracker) UpdateAssignmentGoogleCalendarEventID(id int64, googleCalendarEventID string) error {
+func (t *Tracker) UpdateAssignmentGoogleCalendarEventID(ctx context.Context, id int64, googleCalendarEventID string) error {
 	updateLogger := t.logger.With().
 		Int64("assignment_id", id).
 		Str("google_calendar_event_id", googleCalendarEventID).
 		Logger()
 	updateLogger.Debug().Msg("Updating assignment Google Calendar Event ID")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
-	_, err := t.db.Conn().ExecContext(ctx, `
+	_, err := t.db.ExecContext(ctx, `
 	UPDATE assignments
 	SET google_calendar_event_id = ?, updated_at = CURRENT_TIMESTAMP
 	WHERE id = ?
@@ -312,19 +380,26 @@ func (t *Tracker) UpdateAssignmentGoogleCalendarEventID(id int64, googleCalendar
 	return nil
 }
 
-// UpdateAssignmentParent updates the parent for an assignment and sets the override flag
-func (t *Tracker) UpdateAssignmentParent(id int64, parent string, override bool) error {
+// UpdateAssignmentParent updates the parent for an assignment and sets the override flag. The update
+// only applies while the assignment is still at version, otherwise ErrAssignmentConflict is returned.
+func (t *Tracker) UpdateAssignmentParent(ctx context.Context, id int64, parent string, override bool, version int64) error {
 	updateLogger := t.logger.With().
 		Int64("assignment_id", id).
 		Str("new_parent", parent).
 		Bool("override", override).
+		Int64("version", version).
 		Logger()
 	updateLogger.Debug().Msg("Updating assignment parent")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	previous, err := t.GetAssignmentByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get assignment: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
-	query := `UPDATE assignments SET parent_name = ?, override = ?, caregiver_type = ?, updated_at = CURRENT_TIMESTAMP`
+	query := `UPDATE assignments SET parent_name = ?, override = ?, caregiver_type = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP`
 	args := []any{parent, override}
 	args = append(args, CaregiverTypeParent.String())
 
@@ -334,11 +409,11 @@ func (t *Tracker) UpdateAssignmentParent(id int64, parent string, override bool)
 		args = append(args, DecisionReasonOverride)
 	}
 
-	query += " WHERE id = ?"
-	args = append(args, id)
+	query += " WHERE id = ? AND version = ?"
+	args = append(args, id, version)
 
 	// Execute the query
-	_, err := t.db.Conn().ExecContext(ctx, query, args...)
+	result, err := t.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			updateLogger.Error().Err(err).Msg("Database update timed out")
@@ -347,34 +422,50 @@ func (t *Tracker) UpdateAssignmentParent(id int64, parent string, override bool)
 		updateLogger.Error().Err(err).Msg("Failed to execute update query")
 		return fmt.Errorf("failed to update assignment: %w", err)
 	}
+	if err := checkVersionedUpdate(result, id, version); err != nil {
+		updateLogger.Warn().Err(err).Msg("Assignment changed since it was read")
+		return err
+	}
 
+	t.changed(time.Time{})
+	t.journalChange(ctx, ChangeKindOverride, previous, overriddenState(previous, parent, CaregiverTypeParent, override))
+	if override {
+		signals.EmitAssignmentOverridden(context.Background(), id, parent, CaregiverTypeParent.String())
+	}
 	updateLogger.Debug().Msg("Assignment parent/override updated in DB")
 	return nil
 }
 
-// UpdateAssignmentToBabysitter sets an assignment to a named babysitter and marks it as override.
-func (t *Tracker) UpdateAssignmentToBabysitter(id int64, babysitterName string, override bool) error {
+// UpdateAssignmentToBabysitter sets an assignment to a named babysitter and marks it as override. Like
+// UpdateAssignmentParent, the update only applies while the assignment is still at version.
+func (t *Tracker) UpdateAssignmentToBabysitter(ctx context.Context, id int64, babysitterName string, override bool, version int64) error {
 	updateLogger := t.logger.With().
 		Int64("assignment_id", id).
 		Str("babysitter_name", babysitterName).
 		Bool("override", override).
+		Int64("version", version).
 		Logger()
 	updateLogger.Debug().Msg("Updating assignment to babysitter")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	previous, err := t.GetAssignmentByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get assignment: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
 	// parent_name stores the display name shown in the UI and calendar for all caregiver types.
-	query := `UPDATE assignments SET parent_name = ?, caregiver_type = ?, override = ?, updated_at = CURRENT_TIMESTAMP`
+	query := `UPDATE assignments SET parent_name = ?, caregiver_type = ?, override = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP`
 	args := []any{babysitterName, CaregiverTypeBabysitter.String(), override}
 	if override {
 		query += ", decision_reason = ?"
 		args = append(args, DecisionReasonOverride)
 	}
-	query += " WHERE id = ?"
-	args = append(args, id)
+	query += " WHERE id = ? AND version = ?"
+	args = append(args, id, version)
 
-	_, err := t.db.Conn().ExecContext(ctx, query, args...)
+	result, err := t.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			updateLogger.Error().Err(err).Msg("Database update timed out")
@@ -383,27 +474,110 @@ func (t *Tracker) UpdateAssignmentToBabysitter(id int64, babysitterName string,
 		updateLogger.Error().Err(err).Msg("Failed to execute babysitter update query")
 		return fmt.Errorf("failed to update assignment to babysitter: %w", err)
 	}
+	if err := checkVersionedUpdate(result, id, version); err != nil {
+		updateLogger.Warn().Err(err).Msg("Assignment changed since it was read")
+		return err
+	}
 
+	t.changed(time.Time{})
+	t.journalChange(ctx, ChangeKindOverride, previous, overriddenState(previous, babysitterName, CaregiverTypeBabysitter, override))
+	if override {
+		signals.EmitAssignmentOverridden(context.Background(), id, babysitterName, CaregiverTypeBabysitter.String())
+	}
 	updateLogger.Debug().Msg("Assignment babysitter update saved in DB")
 	return nil
 }
 
+// overriddenState returns the caregiver state of previous once updated to caregiver; the decision
+// reason only changes with an override
+func overriddenState(previous *Assignment, caregiver string, caregiverType CaregiverType, override bool) caregiverState {
+	state := caregiverState{parent: caregiver, caregiverType: caregiverType, override: override, decisionReason: DecisionReasonOverride}
+	if !override && previous != nil {
+		state.decisionReason = previous.DecisionReason
+	}
+	return state
+}
+
+// checkVersionedUpdate returns ErrAssignmentConflict when the update of assignment id at version
+// matched no row, because the assignment was changed or deleted since it was read
+func checkVersionedUpdate(result sql.Result, id, version int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("assignment %d is no longer at version %d: %w", id, version, ErrAssignmentConflict)
+	}
+	return nil
+}
+
+// SetAssignmentTag sets the tag of an overridden assignment, AssignmentTagNone removing it. It
+// returns ErrAssignmentNotOverridden when the assignment is not an override, and an error when it
+// does not exist. The tag is not a change of the caregiver, the version is kept.
+func (t *Tracker) SetAssignmentTag(ctx context.Context, id int64, tag AssignmentTag) error {
+	updateLogger := t.logger.With().Int64("assignment_id", id).Str("tag", tag.String()).Logger()
+	updateLogger.Debug().Msg("Setting assignment tag")
+
+	if _, err := ParseAssignmentTag(tag.String()); err != nil {
+		return err
+	}
+	var value sql.NullString
+	if tag != AssignmentTagNone {
+		value = sql.NullString{String: tag.String(), Valid: true}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	var override bool
+	err := t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, `SELECT override FROM assignments WHERE id = ?`, id).Scan(&override); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("assignment %d not found", id)
+			}
+			return fmt.Errorf("failed to get assignment: %w", err)
+		}
+		if !override {
+			return fmt.Errorf("cannot tag assignment %d: %w", id, ErrAssignmentNotOverridden)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE assignments SET tag = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, value, id); err != nil {
+			return fmt.Errorf("failed to set assignment tag: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		updateLogger.Warn().Err(err).Msg("Failed to set assignment tag")
+		return err
+	}
+
+	t.changed(time.Time{})
+	updateLogger.Info().Msg("Assignment tag set")
+	return nil
+}
+
 // UnlockAssignment removes the override flag from an assignment
-func (t *Tracker) UnlockAssignment(id int64) error {
+func (t *Tracker) UnlockAssignment(ctx context.Context, id int64) error {
 	updateLogger := t.logger.With().Int64("assignment_id", id).Logger()
 	updateLogger.Debug().Msg("Unlocking assignment (removing override)")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	previous, err := t.GetAssignmentByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get assignment: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
-	return t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+	err = t.db.WithTransaction(ctx, func(tx *sql.Tx) error {
 		// Set override to false and clear any babysitter marker so the assignment
 		// is treated as a parent assignment again.
 		result, err := tx.ExecContext(ctx, `
 		UPDATE assignments
 		SET override = 0,
 		    decision_reason = NULL,
+		    tag = NULL,
 		    caregiver_type = ?,
+		    version = version + 1,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 		`, CaregiverTypeParent, id)
@@ -430,12 +604,41 @@ func (t *Tracker) UnlockAssignment(id int64) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	// Unlocking turns a babysitter night back into a parent one
+	t.changed(time.Time{})
+	if previous != nil {
+		t.journalChange(ctx, ChangeKindUnlock, previous, caregiverState{parent: previous.Parent, caregiverType: CaregiverTypeParent})
+	}
+	signals.EmitAssignmentUnlocked(context.Background(), id)
+	return nil
+}
+
+// DeleteAssignment removes an assignment with its details, checklist and comments. Deleting a
+// missing assignment is not an error.
+func (t *Tracker) DeleteAssignment(ctx context.Context, id int64) error {
+	deleteLogger := t.logger.With().Int64("assignment_id", id).Logger()
+	deleteLogger.Debug().Msg("Deleting assignment")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	if _, err := t.db.ExecContext(ctx, `DELETE FROM assignments WHERE id = ?`, id); err != nil {
+		deleteLogger.Error().Err(err).Msg("Failed to execute delete query")
+		return fmt.Errorf("failed to delete assignment: %w", err)
+	}
+
+	deleteLogger.Debug().Msg("Assignment deleted")
+	t.changed(time.Time{})
+	return nil
 }
 
 // GetLastAssignmentsUntil returns the last n assignments of all caregiver types up to a specific date.
 // Babysitter assignments are included so the caller can detect gaps in parent assignments
 // caused by babysitter nights. Parent-only entries can be derived by filtering on CaregiverType.
-func (t *Tracker) GetLastAssignmentsUntil(n int, until time.Time) ([]*Assignment, error) {
+func (t *Tracker) GetLastAssignmentsUntil(ctx context.Context, n int, until time.Time) ([]*Assignment, error) {
 	queryLogger := t.logger.With().
 		Int("limit", n).
 		Str("until_date", until.Format(dateFormat)).
@@ -443,16 +646,16 @@ func (t *Tracker) GetLastAssignmentsUntil(n int, until time.Time) ([]*Assignment
 	queryLogger.Debug().Msg("Fetching last assignments (all caregiver types)")
 	untilStr := until.Format(dateFormat)
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
 	rows, err := t.db.Conn().QueryContext(ctx, `
-SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, created_at, updated_at
+SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, version, tag, created_at, updated_at
 FROM assignments
-WHERE assignment_date < ?
+WHERE child_id = ? AND assignment_date < ?
 ORDER BY assignment_date DESC
 LIMIT ?
-`, untilStr, n)
+`, t.childID, untilStr, n)
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			queryLogger.Error().Err(err).Msg("Database query for last assignments timed out")
@@ -482,21 +685,21 @@ LIMIT ?
 }
 
 // GetAssignmentByDate retrieves an assignment for a specific date
-func (t *Tracker) GetAssignmentByDate(date time.Time) (*Assignment, error) {
+func (t *Tracker) GetAssignmentByDate(ctx context.Context, date time.Time) (*Assignment, error) {
 	queryLogger := t.logger.With().Str("date", date.Format(dateFormat)).Logger()
 	queryLogger.Debug().Msg("Getting assignment by date")
 	dateStr := date.Format(dateFormat)
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
 	row := t.db.Conn().QueryRowContext(ctx, `
-		SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, created_at, updated_at
+		SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, version, tag, created_at, updated_at
 		FROM assignments
-		WHERE assignment_date = ?
+		WHERE child_id = ? AND assignment_date = ?
 		ORDER BY id DESC
 		LIMIT 1
-	`, dateStr)
+	`, t.childID, dateStr)
 
 	a, err := t.scanAssignment(row)
 	if err != nil {
@@ -515,7 +718,7 @@ func (t *Tracker) GetAssignmentByDate(date time.Time) (*Assignment, error) {
 }
 
 // GetAssignmentByGoogleCalendarEventID retrieves an assignment by its Google Calendar event ID
-func (t *Tracker) GetAssignmentByGoogleCalendarEventID(eventID string) (*Assignment, error) {
+func (t *Tracker) GetAssignmentByGoogleCalendarEventID(ctx context.Context, eventID string) (*Assignment, error) {
 	queryLogger := t.logger.With().Str("event_id", eventID).Logger()
 	queryLogger.Debug().Msg("Getting assignment by Google Calendar Event ID")
 	if eventID == "" {
@@ -523,11 +726,11 @@ func (t *Tracker) GetAssignmentByGoogleCalendarEventID(eventID string) (*Assignm
 		return nil, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
 	row := t.db.Conn().QueryRowContext(ctx, `
-		SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, created_at, updated_at
+		SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, version, tag, created_at, updated_at
 		FROM assignments
 		WHERE google_calendar_event_id = ?
 	`, eventID)
@@ -556,7 +759,25 @@ func (t *Tracker) GetAssignmentByGoogleCalendarEventID(eventID string) (*Assignm
 }
 
 // GetAssignmentsInRange retrieves all assignments in a date range
-func (t *Tracker) GetAssignmentsInRange(start, end time.Time) ([]*Assignment, error) {
+func (t *Tracker) GetAssignmentsInRange(ctx context.Context, start, end time.Time) ([]*Assignment, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	var assignments []*Assignment
+	err := t.ForEachAssignmentInRange(ctx, start, end, func(a *Assignment) error {
+		assignments = append(assignments, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// ForEachAssignmentInRange calls fn for every assignment of the date range, oldest first, while
+// reading them from the database, so that a large range is never held in memory. An error returned
+// by fn stops the iteration and is returned as is.
+func (t *Tracker) ForEachAssignmentInRange(ctx context.Context, start, end time.Time, fn func(*Assignment) error) error {
 	queryLogger := t.logger.With().
 		Str("start_date", start.Format(dateFormat)).
 		Str("end_date", end.Format(dateFormat)).
@@ -565,70 +786,90 @@ func (t *Tracker) GetAssignmentsInRange(start, end time.Time) ([]*Assignment, er
 	startStr := start.Format(dateFormat)
 	endStr := end.Format(dateFormat)
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
-	defer cancel()
-
 	rows, err := t.db.Conn().QueryContext(ctx, `
-	SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, created_at, updated_at
+	SELECT id, parent_name, assignment_date, override, google_calendar_event_id, decision_reason, caregiver_type, version, tag, created_at, updated_at
 	FROM assignments
-	WHERE assignment_date >= ? AND assignment_date <= ?
+	WHERE child_id = ? AND assignment_date >= ? AND assignment_date <= ?
 	ORDER BY assignment_date ASC
-	`, startStr, endStr)
+	`, t.childID, startStr, endStr)
 
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			queryLogger.Error().Err(err).Msg("Database query for assignments in range timed out")
-			return nil, fmt.Errorf("database query timed out: %w", err)
+			return fmt.Errorf("database query timed out: %w", err)
 		}
 		queryLogger.Error().Err(err).Msg("Failed to query assignments in range")
-		return nil, fmt.Errorf("failed to query assignments in range: %w", err)
+		return fmt.Errorf("failed to query assignments in range: %w", err)
 	}
 	defer rows.Close()
 
-	var assignments []*Assignment
+	count := 0
 	for rows.Next() {
 		a, err := t.scanAssignment(rows)
 		if err != nil {
 			queryLogger.Debug().Err(err).Msg("Failed to scan assignment row")
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+			return fmt.Errorf("failed to scan row: %w", err)
 		}
-		assignments = append(assignments, a)
+		if err := fn(a); err != nil {
+			return err
+		}
+		count++
 	}
 	if err := rows.Err(); err != nil {
 		queryLogger.Debug().Err(err).Msg("Error iterating assignment rows")
-		return nil, fmt.Errorf("failed during row iteration: %w", err)
+		return fmt.Errorf("failed during row iteration: %w", err)
 	}
 
-	queryLogger.Debug().Int("count", len(assignments)).Msg("Fetched assignments in range successfully")
-	return assignments, nil
+	queryLogger.Debug().Int("count", count).Msg("Fetched assignments in range successfully")
+	return nil
 }
 
-// GetParentStatsUntil returns statistics for each parent up to a specific date.
-// Babysitter assignments are counted as +1 for both parents (they represent a
-// "shift" — the night still happened but was handled by a babysitter, so both
+// GetParentStatsUntil returns statistics for each parent up to a specific date, every night
+// weighing 1. See GetWeightedParentStatsUntil.
+func (t *Tracker) GetParentStatsUntil(ctx context.Context, until time.Time, parentNames ...string) (map[string]Stats, error) {
+	return t.GetWeightedParentStatsUntil(ctx, until, nil, parentNames...)
+}
+
+// GetWeightedParentStatsUntil returns statistics for each parent up to a specific date, the
+// weighted counts weighing each night with weights.
+// Babysitter assignments are counted as +1 for every parent (they represent a
+// "shift" — the night still happened but was handled by a babysitter, so all
 // parents advance equally and no imbalance is created).
 // parentNames seeds the result map so that parents with zero parent assignments
 // still receive the babysitter shift increment.
-func (t *Tracker) GetParentStatsUntil(until time.Time, parentNames ...string) (map[string]Stats, error) {
+func (t *Tracker) GetWeightedParentStatsUntil(ctx context.Context, until time.Time, weights config.DayWeights, parentNames ...string) (map[string]Stats, error) {
 	queryLogger := t.logger.With().Str("until_date", until.Format(dateFormat)).Logger()
 	queryLogger.Debug().Msg("Fetching parent statistics")
 	untilStr := until.Format(dateFormat)
 	thirtyDaysBeforeUntil := until.AddDate(0, 0, -30).Format(dateFormat)
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
+	weight, weightArgs := dayWeightExpr(weights)
+	// statsArgs lists the arguments of the stats queries of a caregiver type, the weight expression
+	// being used for the total and the last 30 days
+	statsArgs := func(caregiverType CaregiverType) []any {
+		args := []any{thirtyDaysBeforeUntil, untilStr}
+		args = append(args, weightArgs...)
+		args = append(args, thirtyDaysBeforeUntil, untilStr)
+		args = append(args, weightArgs...)
+		return append(args, t.childID, untilStr, caregiverType.String())
+	}
+
 	// 1. Parent-only stats
 	rows, err := t.db.Conn().QueryContext(ctx, `
 	SELECT
 	parent_name,
 	COUNT(*) as total_assignments,
-	SUM(CASE WHEN assignment_date >= ? AND assignment_date < ? THEN 1 ELSE 0 END) as last_30_days
+	SUM(CASE WHEN assignment_date >= ? AND assignment_date < ? THEN 1 ELSE 0 END) as last_30_days,
+	SUM(`+weight+`) as weighted_total,
+	SUM(CASE WHEN assignment_date >= ? AND assignment_date < ? THEN `+weight+` ELSE 0 END) as weighted_last_30
 	FROM assignments
-	WHERE assignment_date < ?
+	WHERE child_id = ? AND assignment_date < ?
 	AND caregiver_type = ?
 	GROUP BY parent_name
-	`, thirtyDaysBeforeUntil, untilStr, untilStr, CaregiverTypeParent.String())
+	`, statsArgs(CaregiverTypeParent)...)
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			queryLogger.Error().Err(err).Msg("Database query for parent stats timed out")
@@ -647,7 +888,7 @@ func (t *Tracker) GetParentStatsUntil(until time.Time, parentNames ...string) (m
 	for rows.Next() {
 		var parentName string
 		var s Stats
-		if err := rows.Scan(&parentName, &s.TotalAssignments, &s.Last30Days); err != nil {
+		if err := rows.Scan(&parentName, &s.TotalAssignments, &s.Last30Days, &s.WeightedTotal, &s.WeightedLast30); err != nil {
 			queryLogger.Debug().Err(err).Msg("Failed to scan parent stats row")
 			return nil, fmt.Errorf("failed to scan stats: %w", err)
 		}
@@ -661,14 +902,18 @@ func (t *Tracker) GetParentStatsUntil(until time.Time, parentNames ...string) (m
 	// 2. Babysitter shift count: each babysitter night counts as +1 for both parents
 	var babysitterShiftTotal int
 	var babysitterShiftLast30 int
+	var babysitterWeightedTotal float64
+	var babysitterWeightedLast30 float64
 	err = t.db.Conn().QueryRowContext(ctx, `
 	SELECT
 	COUNT(*) as total,
-	COALESCE(SUM(CASE WHEN assignment_date >= ? AND assignment_date < ? THEN 1 ELSE 0 END), 0) as last_30
+	COALESCE(SUM(CASE WHEN assignment_date >= ? AND assignment_date < ? THEN 1 ELSE 0 END), 0) as last_30,
+	COALESCE(SUM(`+weight+`), 0) as weighted_total,
+	COALESCE(SUM(CASE WHEN assignment_date >= ? AND assignment_date < ? THEN `+weight+` ELSE 0 END), 0) as weighted_last_30
 	FROM assignments
-	WHERE assignment_date < ?
+	WHERE child_id = ? AND assignment_date < ?
 	AND caregiver_type = ?
-	`, thirtyDaysBeforeUntil, untilStr, untilStr, CaregiverTypeBabysitter.String()).Scan(&babysitterShiftTotal, &babysitterShiftLast30)
+	`, statsArgs(CaregiverTypeBabysitter)...).Scan(&babysitterShiftTotal, &babysitterShiftLast30, &babysitterWeightedTotal, &babysitterWeightedLast30)
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			queryLogger.Error().Err(err).Msg("Database query for babysitter shift count timed out")
@@ -686,6 +931,8 @@ func (t *Tracker) GetParentStatsUntil(until time.Time, parentNames ...string) (m
 		for parentName, s := range stats {
 			s.TotalAssignments += babysitterShiftTotal
 			s.Last30Days += babysitterShiftLast30
+			s.WeightedTotal += babysitterWeightedTotal
+			s.WeightedLast30 += babysitterWeightedLast30
 			stats[parentName] = s
 		}
 	}
@@ -694,20 +941,71 @@ func (t *Tracker) GetParentStatsUntil(until time.Time, parentNames ...string) (m
 	return stats, nil
 }
 
-// GetLastAssignmentDate returns the date of the last assignment in the database
-func (t *Tracker) GetLastAssignmentDate() (time.Time, error) {
+// dayWeightExpr returns the SQL expression of the weight of an assignment by the day of the week of
+// its date, with its arguments. Days without weight weigh 1.
+func dayWeightExpr(weights config.DayWeights) (string, []any) {
+	var expr strings.Builder
+	var args []any
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weight, ok := weights[day]
+		if !ok || weight == 1 {
+			continue
+		}
+		if expr.Len() == 0 {
+			expr.WriteString("CASE CAST(strftime('%w', assignment_date) AS INTEGER)")
+		}
+		expr.WriteString(" WHEN ? THEN ?")
+		args = append(args, int(day), weight)
+	}
+	if expr.Len() == 0 {
+		return "1.0", nil
+	}
+	expr.WriteString(" ELSE 1.0 END")
+	return expr.String(), args
+}
+
+// GetAssignmentsVersion returns the current version of the assignments
+func (t *Tracker) GetAssignmentsVersion(ctx context.Context) (AssignmentsVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	// Read the revision first: a write racing with the query then yields a new version next time
+	version := AssignmentsVersion{Revision: t.revision.Load()}
+	var lastUpdate string
+	err := t.db.Conn().QueryRowContext(ctx, `
+	SELECT COUNT(*), COALESCE(MAX(id), 0), COALESCE(MAX(COALESCE(updated_at, created_at)), '')
+	FROM assignments
+	`).Scan(&version.Count, &version.MaxID, &lastUpdate)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("Failed to query assignments version")
+		return AssignmentsVersion{}, fmt.Errorf("failed to get assignments version: %w", err)
+	}
+
+	if lastUpdate != "" {
+		// CURRENT_TIMESTAMP is stored in UTC with a second precision
+		version.LastUpdate, err = time.Parse(time.DateTime, lastUpdate)
+		if err != nil {
+			return AssignmentsVersion{}, fmt.Errorf("failed to parse last assignment update %q: %w", lastUpdate, err)
+		}
+	}
+	return version, nil
+}
+
+// GetLastAssignmentDate returns the date of the last assignment of the rotation in the database
+func (t *Tracker) GetLastAssignmentDate(ctx context.Context) (time.Time, error) {
 	t.logger.Debug().Msg("Fetching last assignment date")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
 	var dateStr string
 	err := t.db.Conn().QueryRowContext(ctx, `
 	SELECT assignment_date
 	FROM assignments
+	WHERE child_id = ?
 	ORDER BY assignment_date DESC
 	LIMIT 1
-	`).Scan(&dateStr)
+	`, t.childID).Scan(&dateStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			t.logger.Debug().Msg("No assignments found in database")
@@ -740,101 +1038,65 @@ func (t *Tracker) GetLastAssignmentDate() (time.Time, error) {
 
 // GetParentMonthlyStatsForLastNMonths fetches and aggregates assignment counts per parent per month for the last n months,
 // relative to the given referenceTime.
-func (t *Tracker) GetParentMonthlyStatsForLastNMonths(referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error) {
-	queryLogger := t.logger.With().
-		Time("reference_time", referenceTime).
-		Int("n_months", nMonths).
-		Logger()
-	queryLogger.Debug().Msg("Fetching parent monthly stats")
-
-	// Calculate the start date: first day of the month, nMonths ago
-	// e.g., if ref is Nov 15, 2023, and n=3, we want Aug, Sep, Oct, Nov (partial)
-	// Actually "last n months" usually implies including current month.
-	// Let's go back n months from the first of the current month.
-	startOfCurrentMonth := time.Date(referenceTime.Year(), referenceTime.Month(), 1, 0, 0, 0, 0, referenceTime.Location())
-	startDate := startOfCurrentMonth.AddDate(0, -nMonths+1, 0)
-
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
-	defer cancel()
-
-	// SQLite query to group by month and parent
-	// strftime('%Y-%m', assignment_date) gives YYYY-MM
-	query := `
-		SELECT 
-			strftime('%Y-%m', assignment_date) as month_str,
-			parent_name,
-			COUNT(*) as count
-		FROM assignments
-		WHERE assignment_date >= ? AND assignment_date <= ?
-		AND caregiver_type = ?
-		GROUP BY month_str, parent_name
-		ORDER BY month_str ASC, parent_name ASC
-	`
-	// Query up to the provided referenceTime
-	rows, err := t.db.Conn().QueryContext(ctx, query, startDate.Format(dateFormat), referenceTime.Format(dateFormat), CaregiverTypeParent.String())
-	if err != nil {
-		if err == context.DeadlineExceeded {
-			queryLogger.Error().Err(err).Msg("Database query for parent monthly stats timed out")
-			return nil, fmt.Errorf("database query timed out: %w", err)
-		}
-		queryLogger.Error().Err(err).Msg("Failed to query parent monthly stats")
-		return nil, fmt.Errorf("failed to query stats: %w", err)
-	}
-	defer rows.Close()
-
-	var stats []MonthlyStatRow
-	for rows.Next() {
-		var row MonthlyStatRow
-		if err := rows.Scan(&row.MonthYear, &row.ParentName, &row.Count); err != nil {
-			queryLogger.Debug().Err(err).Msg("Failed to scan parent monthly stats row")
-			return nil, fmt.Errorf("failed to scan stats: %w", err)
-		}
-		stats = append(stats, row)
-	}
-	if err := rows.Err(); err != nil {
-		queryLogger.Debug().Err(err).Msg("Error iterating parent monthly stats rows")
-		return nil, fmt.Errorf("failed during row iteration: %w", err)
-	}
-
-	queryLogger.Debug().Int("count", len(stats)).Msg("Fetched parent monthly stats successfully")
-	return stats, nil
+func (t *Tracker) GetParentMonthlyStatsForLastNMonths(ctx context.Context, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error) {
+	return t.getMonthlyStats(ctx, CaregiverTypeParent, referenceTime, nMonths)
 }
 
 // GetBabysitterMonthlyStatsForLastNMonths fetches and aggregates babysitter assignment counts per babysitter per month,
 // relative to the given referenceTime.
-func (t *Tracker) GetBabysitterMonthlyStatsForLastNMonths(referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error) {
+func (t *Tracker) GetBabysitterMonthlyStatsForLastNMonths(ctx context.Context, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error) {
+	return t.getMonthlyStats(ctx, CaregiverTypeBabysitter, referenceTime, nMonths)
+}
+
+// getMonthlyStats returns the assignment counts of caregiverType per caregiver per month, from the first
+// day of the month nMonths-1 months before referenceTime to referenceTime, ordered by month and name.
+// The months before the one of referenceTime are read from assignment_monthly_stats, maintained by
+// triggers on every assignment write, so that only the current month is counted from the assignments.
+func (t *Tracker) getMonthlyStats(ctx context.Context, caregiverType CaregiverType, referenceTime time.Time, nMonths int) ([]MonthlyStatRow, error) {
 	queryLogger := t.logger.With().
+		Str("caregiver_type", caregiverType.String()).
 		Time("reference_time", referenceTime).
 		Int("n_months", nMonths).
 		Logger()
-	queryLogger.Debug().Msg("Fetching babysitter monthly stats")
+	queryLogger.Debug().Msg("Fetching monthly stats")
 
+	// Go back n-1 months from the first of the current month, the current month being counted up
+	// to referenceTime: with a reference on Nov 15 and n=3, Sep, Oct and Nov 1-15
 	startOfCurrentMonth := time.Date(referenceTime.Year(), referenceTime.Month(), 1, 0, 0, 0, 0, referenceTime.Location())
 	startDate := startOfCurrentMonth.AddDate(0, -nMonths+1, 0)
+	currentMonthStart := startOfCurrentMonth
+	if startDate.After(currentMonthStart) {
+		currentMonthStart = startDate
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
 	query := `
+		SELECT month AS month_str, parent_name, count
+		FROM assignment_monthly_stats
+		WHERE child_id = ? AND caregiver_type = ? AND month >= ? AND month < ? AND count > 0
+		UNION ALL
 		SELECT
-			strftime('%Y-%m', assignment_date) as month_str,
-			parent_name as babysitter_label,
-			COUNT(*) as count
+			strftime('%Y-%m', assignment_date) AS month_str,
+			parent_name,
+			COUNT(*) AS count
 		FROM assignments
-		WHERE assignment_date >= ? AND assignment_date <= ?
+		WHERE child_id = ? AND assignment_date >= ? AND assignment_date <= ?
 		AND caregiver_type = ?
-		GROUP BY month_str, babysitter_label
-		ORDER BY month_str ASC, babysitter_label ASC
+		GROUP BY month_str, parent_name
+		ORDER BY month_str ASC, parent_name ASC
 	`
-
-	rows, err := t.db.Conn().QueryContext(ctx, query, startDate.Format(dateFormat), referenceTime.Format(dateFormat), CaregiverTypeBabysitter.String())
+	rows, err := t.db.Conn().QueryContext(ctx, query,
+		t.childID, caregiverType.String(), startDate.Format("2006-01"), startOfCurrentMonth.Format("2006-01"),
+		t.childID, currentMonthStart.Format(dateFormat), referenceTime.Format(dateFormat), caregiverType.String())
 	if err != nil {
 		if err == context.DeadlineExceeded {
-			queryLogger.Error().Err(err).Msg("Database query for babysitter monthly stats timed out")
+			queryLogger.Error().Err(err).Msg("Database query for monthly stats timed out")
 			return nil, fmt.Errorf("database query timed out: %w", err)
 		}
-		queryLogger.Error().Err(err).Msg("Failed to query babysitter monthly stats")
-		return nil, fmt.Errorf("failed to query babysitter stats: %w", err)
+		queryLogger.Error().Err(err).Msg("Failed to query monthly stats")
+		return nil, fmt.Errorf("failed to query %s stats: %w", caregiverType, err)
 	}
 	defer rows.Close()
 
@@ -842,38 +1104,49 @@ func (t *Tracker) GetBabysitterMonthlyStatsForLastNMonths(referenceTime time.Tim
 	for rows.Next() {
 		var row MonthlyStatRow
 		if err := rows.Scan(&row.MonthYear, &row.ParentName, &row.Count); err != nil {
-			queryLogger.Debug().Err(err).Msg("Failed to scan babysitter monthly stats row")
-			return nil, fmt.Errorf("failed to scan babysitter stats: %w", err)
+			queryLogger.Debug().Err(err).Msg("Failed to scan monthly stats row")
+			return nil, fmt.Errorf("failed to scan %s stats: %w", caregiverType, err)
 		}
 		stats = append(stats, row)
 	}
 	if err := rows.Err(); err != nil {
-		queryLogger.Debug().Err(err).Msg("Error iterating babysitter monthly stats rows")
+		queryLogger.Debug().Err(err).Msg("Error iterating monthly stats rows")
 		return nil, fmt.Errorf("failed during row iteration: %w", err)
 	}
 
-	queryLogger.Debug().Int("count", len(stats)).Msg("Fetched babysitter monthly stats successfully")
+	queryLogger.Debug().Int("count", len(stats)).Msg("Fetched monthly stats successfully")
 	return stats, nil
 }
 
-// SaveAssignmentDetails stores the fairness algorithm calculation details for an assignment
+// SaveAssignmentDetails stores the fairness algorithm calculation details for an assignment, extra
+// holding the stats of the parents of the roster after parent A and parent B.
 // Uses UPSERT to handle both new inserts and updates when recalculating schedules
-func (t *Tracker) SaveAssignmentDetails(assignmentID int64, calculationDate time.Time, parentAName string, statsA Stats, parentBName string, statsB Stats) error {
+func (t *Tracker) SaveAssignmentDetails(ctx context.Context, assignmentID int64, calculationDate time.Time, parentAName string, statsA Stats, parentBName string, statsB Stats, extra ...NamedStats) error {
 	saveLogger := t.logger.With().
 		Int64("assignment_id", assignmentID).
 		Str("calculation_date", calculationDate.Format(dateFormat)).
 		Logger()
 	saveLogger.Debug().Msg("Saving assignment details")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	extraDetails := make([]extraParentDetails, 0, len(extra))
+	for _, parent := range extra {
+		extraDetails = append(extraDetails, extraParentDetails{Name: parent.Name, Total: parent.Stats.TotalAssignments, Last30Days: parent.Stats.Last30Days})
+	}
+	extraJSON, err := json.Marshal(extraDetails)
+	if err != nil {
+		return fmt.Errorf("failed to encode extra parents details: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
-	_, err := t.db.Conn().ExecContext(ctx, `
+	_, err = t.db.ExecContext(ctx, `
 		INSERT INTO assignment_details (
 			assignment_id, calculation_date,
 			parent_a_name, parent_a_total_count, parent_a_last_30_days,
-			parent_b_name, parent_b_total_count, parent_b_last_30_days
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			parent_b_name, parent_b_total_count, parent_b_last_30_days,
+			extra_parents
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(assignment_id) DO UPDATE SET
 			calculation_date = excluded.calculation_date,
 			parent_a_name = excluded.parent_a_name,
@@ -881,10 +1154,12 @@ func (t *Tracker) SaveAssignmentDetails(assignmentID int64, calculationDate time
 			parent_a_last_30_days = excluded.parent_a_last_30_days,
 			parent_b_name = excluded.parent_b_name,
 			parent_b_total_count = excluded.parent_b_total_count,
-			parent_b_last_30_days = excluded.parent_b_last_30_days
+			parent_b_last_30_days = excluded.parent_b_last_30_days,
+			extra_parents = excluded.extra_parents
 	`, assignmentID, calculationDate.Format(dateFormat),
 		parentAName, statsA.TotalAssignments, statsA.Last30Days,
-		parentBName, statsB.TotalAssignments, statsB.Last30Days)
+		parentBName, statsB.TotalAssignments, statsB.Last30Days,
+		string(extraJSON))
 
 	if err != nil {
 		if err == context.DeadlineExceeded {
@@ -900,22 +1175,23 @@ func (t *Tracker) SaveAssignmentDetails(assignmentID int64, calculationDate time
 }
 
 // GetAssignmentDetails retrieves the fairness algorithm calculation details for an assignment
-func (t *Tracker) GetAssignmentDetails(assignmentID int64) (*AssignmentDetails, error) {
+func (t *Tracker) GetAssignmentDetails(ctx context.Context, assignmentID int64) (*AssignmentDetails, error) {
 	queryLogger := t.logger.With().Int64("assignment_id", assignmentID).Logger()
 	queryLogger.Debug().Msg("Getting assignment details")
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
 	defer cancel()
 
 	var details AssignmentDetails
 	var calculationDateStr string
 	var createdAt time.Time
+	var extraJSON string
 
 	err := t.db.Conn().QueryRowContext(ctx, `
 		SELECT id, assignment_id, calculation_date,
 			parent_a_name, parent_a_total_count, parent_a_last_30_days,
 			parent_b_name, parent_b_total_count, parent_b_last_30_days,
-			created_at
+			extra_parents, created_at
 		FROM assignment_details
 		WHERE assignment_id = ?
 	`, assignmentID).Scan(
@@ -928,6 +1204,7 @@ func (t *Tracker) GetAssignmentDetails(assignmentID int64) (*AssignmentDetails,
 		&details.ParentBName,
 		&details.ParentBTotalCount,
 		&details.ParentBLast30Days,
+		&extraJSON,
 		&createdAt,
 	)
 
@@ -952,6 +1229,15 @@ func (t *Tracker) GetAssignmentDetails(assignmentID int64) (*AssignmentDetails,
 	details.CalculationDate = calculationDate
 	details.CreatedAt = createdAt
 
+	var extraDetails []extraParentDetails
+	if err := json.Unmarshal([]byte(extraJSON), &extraDetails); err != nil {
+		queryLogger.Error().Err(err).Msg("Failed to decode extra parents details")
+		return nil, fmt.Errorf("failed to decode extra parents details: %w", err)
+	}
+	for _, parent := range extraDetails {
+		details.ExtraParents = append(details.ExtraParents, NamedStats{Name: parent.Name, Stats: Stats{TotalAssignments: parent.Total, Last30Days: parent.Last30Days}})
+	}
+
 	queryLogger.Debug().Msg("Assignment details retrieved successfully")
 	return &details, nil
 }
@@ -965,14 +1251,42 @@ type Assignment struct {
 	Override              bool
 	GoogleCalendarEventID string
 	DecisionReason        DecisionReason
+	Version               int64         // Incremented by every change of the caregiver, see ErrAssignmentConflict
+	Tag                   AssignmentTag // Why an overridden night was taken by hand, cleared with the override
 	CreatedAt             time.Time
 	UpdatedAt             time.Time
 }
 
+// AssignmentsVersion identifies the state of the assignments, for HTTP caching validators.
+// LastUpdate only has a second precision, Revision catches the writes made within the same second.
+type AssignmentsVersion struct {
+	Count      int
+	MaxID      int64
+	LastUpdate time.Time // Latest creation or update of an assignment, zero without assignments
+	Revision   uint64    // Writes made through this tracker since it was created
+}
+
 // Stats represents statistics for a parent
 type Stats struct {
 	TotalAssignments int
 	Last30Days       int
+	// WeightedTotal and WeightedLast30 count each night with the weight of its day of the week,
+	// equal to the counts when no day is weighted
+	WeightedTotal  float64
+	WeightedLast30 float64
+}
+
+// NamedStats holds the statistics of a parent of the roster
+type NamedStats struct {
+	Name  string
+	Stats Stats
+}
+
+// extraParentDetails is the JSON form of the stats of an extra parent in assignment_details
+type extraParentDetails struct {
+	Name       string `json:"name"`
+	Total      int    `json:"total"`
+	Last30Days int    `json:"last_30_days"`
 }
 
 // MonthlyStatRow holds a raw row from the monthly statistics query.
@@ -993,5 +1307,6 @@ type AssignmentDetails struct {
 	ParentBName       string
 	ParentBTotalCount int
 	ParentBLast30Days int
+	ExtraParents      []NamedStats // Parents of the roster after parent A and parent B, in roster order
 	CreatedAt         time.Time
 }
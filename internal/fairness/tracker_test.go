@@ -1,11 +1,17 @@
 package fairness
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/signals"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	_ "modernc.org/sqlite" // Register modernc sqlite driver
 )
 
@@ -44,7 +50,7 @@ func TestRecordAssignment(t *testing.T) {
 
 	// Test recording a new assignment
 	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
-	assignment, err := tracker.RecordAssignment("Alice", date, false, "Total Count")
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, "Total Count")
 	assert.NoError(t, err)
 	assert.NotNil(t, assignment)
 	assert.Equal(t, "Alice", assignment.Parent)
@@ -52,13 +58,36 @@ func TestRecordAssignment(t *testing.T) {
 	assert.False(t, assignment.Override)
 
 	// Test recording another assignment for the same date (should update)
-	assignment2, err := tracker.RecordAssignment("Bob", date, false, "Alternating")
+	assignment2, err := tracker.RecordAssignment(t.Context(), "Bob", date, false, "Alternating")
 	assert.NoError(t, err)
 	assert.NotNil(t, assignment2)
 	assert.Equal(t, "Bob", assignment2.Parent)
 	assert.Equal(t, assignment.ID, assignment2.ID) // Should be the same assignment (updated)
 }
 
+// TestTracker_CancelledContext verifies that the queries stop once the caller's context is cancelled
+func TestTracker_CancelledContext(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = tracker.RecordAssignment(ctx, "Alice", date, false, DecisionReasonTotalCount)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = tracker.GetAssignmentsInRange(ctx, date, date.AddDate(0, 0, 7))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), date)
+	require.NoError(t, err)
+	assert.Nil(t, assignment, "nothing was recorded")
+}
+
 // TestGetParentStatsUntil tests the GetParentStatsUntil method
 func TestGetParentStatsUntil(t *testing.T) {
 	db, cleanup := setupTestDB(t)
@@ -82,12 +111,12 @@ func TestGetParentStatsUntil(t *testing.T) {
 	}
 
 	for _, a := range assignments {
-		_, err := tracker.RecordAssignment(a.parent, a.date, false, "Total Count")
+		_, err := tracker.RecordAssignment(t.Context(), a.parent, a.date, false, "Total Count")
 		assert.NoError(t, err)
 	}
 
 	// Get stats until now
-	stats, err := tracker.GetParentStatsUntil(now)
+	stats, err := tracker.GetParentStatsUntil(t.Context(), now)
 	assert.NoError(t, err)
 
 	// Check Alice's stats
@@ -101,6 +130,38 @@ func TestGetParentStatsUntil(t *testing.T) {
 	assert.Equal(t, 1, bobStats.Last30Days)
 }
 
+// TestGetWeightedParentStatsUntil verifies that each night counts with the weight of its day of the
+// week in the weighted counts, babysitter shifts included, the plain counts staying unchanged
+func TestGetWeightedParentStatsUntil(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	until := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)                                                             // Friday
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", until.AddDate(0, 0, -7), false, DecisionReasonTotalCount) // Friday
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", until.AddDate(0, 0, -11), false, DecisionReasonTotalCount) // Monday
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", until.AddDate(0, 0, -6), false, DecisionReasonTotalCount) // Saturday
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", until.AddDate(0, 0, -41), false, DecisionReasonTotalCount) // Saturday, old
+	require.NoError(t, err)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", until.AddDate(0, 0, -14), true) // Friday
+	require.NoError(t, err)
+
+	weights := config.DayWeights{time.Friday: 2, time.Saturday: 1.5}
+	stats, err := tracker.GetWeightedParentStatsUntil(t.Context(), until, weights, "Alice", "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, Stats{TotalAssignments: 3, Last30Days: 3, WeightedTotal: 5, WeightedLast30: 5}, stats["Alice"])
+	assert.Equal(t, Stats{TotalAssignments: 3, Last30Days: 2, WeightedTotal: 5, WeightedLast30: 3.5}, stats["Bob"])
+
+	stats, err = tracker.GetParentStatsUntil(t.Context(), until, "Alice", "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, Stats{TotalAssignments: 3, Last30Days: 2, WeightedTotal: 3, WeightedLast30: 2}, stats["Bob"], "without weights every night counts 1")
+}
+
 // TestGetAssignmentByDate tests the GetAssignmentByDate method
 func TestGetAssignmentByDate(t *testing.T) {
 	db, cleanup := setupTestDB(t)
@@ -112,16 +173,16 @@ func TestGetAssignmentByDate(t *testing.T) {
 	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	// Test getting non-existent assignment
-	assignment, err := tracker.GetAssignmentByDate(date)
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), date)
 	assert.NoError(t, err)
 	assert.Nil(t, assignment)
 
 	// Create an assignment
-	created, err := tracker.RecordAssignment("Alice", date, false, "Total Count")
+	created, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, "Total Count")
 	assert.NoError(t, err)
 
 	// Get the assignment
-	assignment, err = tracker.GetAssignmentByDate(date)
+	assignment, err = tracker.GetAssignmentByDate(t.Context(), date)
 	assert.NoError(t, err)
 	assert.NotNil(t, assignment)
 	assert.Equal(t, created.ID, assignment.ID)
@@ -139,24 +200,24 @@ func TestAssignmentWithOverride(t *testing.T) {
 	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	// Create initial assignment
-	assignment, err := tracker.RecordAssignment("Alice", date, false, "Total Count")
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, "Total Count")
 	assert.NoError(t, err)
 	assert.False(t, assignment.Override)
 	assert.Equal(t, DecisionReason("Total Count"), assignment.DecisionReason)
 
 	// Override the assignment
-	err = tracker.UpdateAssignmentParent(assignment.ID, "Bob", true)
+	err = tracker.UpdateAssignmentParent(t.Context(), assignment.ID, "Bob", true, assignment.Version)
 	assert.NoError(t, err)
 
 	// Verify the override
-	updated, err := tracker.GetAssignmentByDate(date)
+	updated, err := tracker.GetAssignmentByDate(t.Context(), date)
 	assert.NoError(t, err)
 	assert.True(t, updated.Override)
 	assert.Equal(t, "Bob", updated.Parent)
 	assert.Equal(t, DecisionReasonOverride, updated.DecisionReason, "Decision reason should be set to Override when overriding")
 
 	// With our simplified method, overrides can be changed
-	assignment, err = tracker.RecordAssignment("Alice", date, false, "Total Count")
+	assignment, err = tracker.RecordAssignment(t.Context(), "Alice", date, false, "Total Count")
 	assert.NoError(t, err)
 	assert.Equal(t, "Alice", assignment.Parent) // Should now be Alice (overrides can be changed)
 	assert.False(t, assignment.Override)        // Override flag is updated
@@ -175,38 +236,38 @@ func TestUpdateAssignmentParentWithOverride(t *testing.T) {
 
 	// Create initial assignment with a specific decision reason
 	initialReason := DecisionReason("Alternating")
-	assignment, err := tracker.RecordAssignment("Alice", date, false, initialReason)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, initialReason)
 	assert.NoError(t, err)
 	assert.Equal(t, initialReason, assignment.DecisionReason)
 
 	// Test case 1: Update with override=true
-	err = tracker.UpdateAssignmentParent(assignment.ID, "Bob", true)
+	err = tracker.UpdateAssignmentParent(t.Context(), assignment.ID, "Bob", true, assignment.Version)
 	assert.NoError(t, err)
 
 	// Verify decision reason is set to Override
-	updated, err := tracker.GetAssignmentByDate(date)
+	updated, err := tracker.GetAssignmentByDate(t.Context(), date)
 	assert.NoError(t, err)
 	assert.Equal(t, "Bob", updated.Parent)
 	assert.True(t, updated.Override)
 	assert.Equal(t, DecisionReasonOverride, updated.DecisionReason, "Decision reason should be set to Override when override=true")
 
 	// Test case 2: Update with override=false
-	err = tracker.UpdateAssignmentParent(updated.ID, "Charlie", false)
+	err = tracker.UpdateAssignmentParent(t.Context(), updated.ID, "Charlie", false, updated.Version)
 	assert.NoError(t, err)
 
 	// Verify decision reason is not changed when override=false
-	updated2, err := tracker.GetAssignmentByDate(date)
+	updated2, err := tracker.GetAssignmentByDate(t.Context(), date)
 	assert.NoError(t, err)
 	assert.Equal(t, "Charlie", updated2.Parent)
 	assert.False(t, updated2.Override)
 	assert.Equal(t, DecisionReasonOverride, updated2.DecisionReason, "Decision reason should not be changed when override=false")
 
 	// Test case 3: Set override=true again with a different parent
-	err = tracker.UpdateAssignmentParent(updated2.ID, "David", true)
+	err = tracker.UpdateAssignmentParent(t.Context(), updated2.ID, "David", true, updated2.Version)
 	assert.NoError(t, err)
 
 	// Verify decision reason is set to Override again
-	updated3, err := tracker.GetAssignmentByDate(date)
+	updated3, err := tracker.GetAssignmentByDate(t.Context(), date)
 	assert.NoError(t, err)
 	assert.Equal(t, "David", updated3.Parent)
 	assert.True(t, updated3.Override)
@@ -233,20 +294,53 @@ func TestGetAssignmentsInRange(t *testing.T) {
 	}
 
 	for _, a := range assignments {
-		_, err := tracker.RecordAssignment(a.parent, a.date, false, "Alternating")
+		_, err := tracker.RecordAssignment(t.Context(), a.parent, a.date, false, "Alternating")
 		assert.NoError(t, err)
 	}
 
 	// Test getting assignments in range
 	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
-	rangeAssignments, err := tracker.GetAssignmentsInRange(start, end)
+	rangeAssignments, err := tracker.GetAssignmentsInRange(t.Context(), start, end)
 	assert.NoError(t, err)
 	assert.Len(t, rangeAssignments, 2)
 	assert.Equal(t, "Bob", rangeAssignments[0].Parent)
 	assert.Equal(t, "Alice", rangeAssignments[1].Parent)
 }
 
+// TestForEachAssignmentInRange tests that the assignments are streamed in order and that an error stops the iteration
+func TestForEachAssignmentInRange(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	assert.NoError(t, err)
+
+	for i, parent := range []string{"Alice", "Bob", "Alice"} {
+		_, err := tracker.RecordAssignment(t.Context(), parent, time.Date(2025, 1, 3-i, 0, 0, 0, 0, time.UTC), false, DecisionReasonAlternating)
+		assert.NoError(t, err)
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	var dates []string
+	err = tracker.ForEachAssignmentInRange(context.Background(), start, end, func(a *Assignment) error {
+		dates = append(dates, a.Date.Format(dateFormat))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2025-01-01", "2025-01-02", "2025-01-03"}, dates, "oldest first")
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = tracker.ForEachAssignmentInRange(context.Background(), start, end, func(a *Assignment) error {
+		calls++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
 // TestGoogleCalendarIntegration tests the Google Calendar related methods
 func TestGoogleCalendarIntegration(t *testing.T) {
 	db, cleanup := setupTestDB(t)
@@ -259,31 +353,31 @@ func TestGoogleCalendarIntegration(t *testing.T) {
 	eventID := "google_event_123"
 
 	// Create assignment
-	assignment, err := tracker.RecordAssignment("Alice", date, false, "Override")
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, "Override")
 	assert.NoError(t, err)
 
 	// Update with Google Calendar event ID
-	err = tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, eventID)
+	err = tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, eventID)
 	assert.NoError(t, err)
 
 	// Retrieve updated assignment
-	assignment, err = tracker.GetAssignmentByID(assignment.ID)
+	assignment, err = tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, eventID, assignment.GoogleCalendarEventID)
 
 	// Get assignment by event ID
-	found, err := tracker.GetAssignmentByGoogleCalendarEventID(eventID)
+	found, err := tracker.GetAssignmentByGoogleCalendarEventID(t.Context(), eventID)
 	assert.NoError(t, err)
 	assert.NotNil(t, found)
 	assert.Equal(t, assignment.ID, found.ID)
 
 	// Update event ID
 	newEventID := "google_event_456"
-	err = tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, newEventID)
+	err = tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, newEventID)
 	assert.NoError(t, err)
 
 	// Verify update
-	updated, err := tracker.GetAssignmentByID(assignment.ID)
+	updated, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, newEventID, updated.GoogleCalendarEventID)
 }
@@ -307,7 +401,7 @@ func TestGetParentMonthlyStatsForLastNMonths(t *testing.T) {
 	}
 
 	t.Run("No assignments", func(t *testing.T) {
-		stats, err := tracker.GetParentMonthlyStatsForLastNMonths(testReferenceTime, 12)
+		stats, err := tracker.GetParentMonthlyStatsForLastNMonths(t.Context(), testReferenceTime, 12)
 		assert.NoError(t, err)
 		assert.Empty(t, stats)
 	})
@@ -317,25 +411,25 @@ func TestGetParentMonthlyStatsForLastNMonths(t *testing.T) {
 	// - 2 assignments 1 month ago (current month - 1)
 	// - 1 assignment 3 months ago
 	// - 1 assignment 13 months ago (should be excluded for 12 month lookback)
-	_, err = tracker.RecordAssignment("Parent A", monthsAgo(1).AddDate(0, 0, -1), false, "Test") // e.g., April 2025
+	_, err = tracker.RecordAssignment(t.Context(), "Parent A", monthsAgo(1).AddDate(0, 0, -1), false, "Test") // e.g., April 2025
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Parent A", monthsAgo(1).AddDate(0, 0, -2), false, "Test") // e.g., April 2025
+	_, err = tracker.RecordAssignment(t.Context(), "Parent A", monthsAgo(1).AddDate(0, 0, -2), false, "Test") // e.g., April 2025
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Parent A", monthsAgo(3), false, "Test") // e.g., February 2025
+	_, err = tracker.RecordAssignment(t.Context(), "Parent A", monthsAgo(3), false, "Test") // e.g., February 2025
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Parent A", monthsAgo(13), false, "Test") // e.g., April 2024 (too old)
+	_, err = tracker.RecordAssignment(t.Context(), "Parent A", monthsAgo(13), false, "Test") // e.g., April 2024 (too old)
 	assert.NoError(t, err)
 
 	// Parent B:
 	// - 1 assignment this month (current month)
 	// - 3 assignments 11 months ago (just within 12 month lookback)
-	_, err = tracker.RecordAssignment("Parent B", daysAgo(5), false, "Test") // e.g., May 2025
+	_, err = tracker.RecordAssignment(t.Context(), "Parent B", daysAgo(5), false, "Test") // e.g., May 2025
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Parent B", monthsAgo(11).AddDate(0, 0, -1), false, "Test") // e.g., June 2024
+	_, err = tracker.RecordAssignment(t.Context(), "Parent B", monthsAgo(11).AddDate(0, 0, -1), false, "Test") // e.g., June 2024
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Parent B", monthsAgo(11).AddDate(0, 0, -2), false, "Test") // e.g., June 2024
+	_, err = tracker.RecordAssignment(t.Context(), "Parent B", monthsAgo(11).AddDate(0, 0, -2), false, "Test") // e.g., June 2024
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Parent B", monthsAgo(11).AddDate(0, 0, -3), false, "Test") // e.g., June 2024
+	_, err = tracker.RecordAssignment(t.Context(), "Parent B", monthsAgo(11).AddDate(0, 0, -3), false, "Test") // e.g., June 2024
 	assert.NoError(t, err)
 
 	// Parent C:
@@ -348,14 +442,14 @@ func TestGetParentMonthlyStatsForLastNMonths(t *testing.T) {
 	//   If nMonths = 12, startDateRange = now - 11 months.
 	//   If now = May 15, 2025, startDateRange = June 15, 2024. firstDayOfRange = June 1, 2024.
 	//   So, data from May 2024 should be excluded.
-	_, err = tracker.RecordAssignment("Parent C", monthsAgo(12), false, "Test") // e.g., May 2024 (should be included if logic is inclusive of 12th month)
+	_, err = tracker.RecordAssignment(t.Context(), "Parent C", monthsAgo(12), false, "Test") // e.g., May 2024 (should be included if logic is inclusive of 12th month)
 	assert.NoError(t, err)
 	// Let's add one for Parent C that *is* included (11 months ago)
-	_, err = tracker.RecordAssignment("Parent C", monthsAgo(11).AddDate(0, 0, -5), false, "Test") // e.g. June 2024
+	_, err = tracker.RecordAssignment(t.Context(), "Parent C", monthsAgo(11).AddDate(0, 0, -5), false, "Test") // e.g. June 2024
 	assert.NoError(t, err)
 
 	t.Run("With assignments within 12 months", func(t *testing.T) {
-		stats, err := tracker.GetParentMonthlyStatsForLastNMonths(testReferenceTime, 12)
+		stats, err := tracker.GetParentMonthlyStatsForLastNMonths(t.Context(), testReferenceTime, 12)
 		assert.NoError(t, err)
 		// Expected:
 		// Parent A: monthsAgo(1) -> 2, monthsAgo(3) -> 1
@@ -404,7 +498,7 @@ func TestGetParentMonthlyStatsForLastNMonths(t *testing.T) {
 	})
 
 	t.Run("Lookback for 1 month", func(t *testing.T) {
-		stats, err := tracker.GetParentMonthlyStatsForLastNMonths(testReferenceTime, 1)
+		stats, err := tracker.GetParentMonthlyStatsForLastNMonths(t.Context(), testReferenceTime, 1)
 		assert.NoError(t, err)
 
 		resultsMap := make(map[string]map[string]int)
@@ -429,7 +523,7 @@ func TestGetParentMonthlyStatsForLastNMonths(t *testing.T) {
 
 	t.Run("Lookback for 2 months", func(t *testing.T) {
 		// This should include current month and (current month - 1)
-		stats, err := tracker.GetParentMonthlyStatsForLastNMonths(testReferenceTime, 2)
+		stats, err := tracker.GetParentMonthlyStatsForLastNMonths(t.Context(), testReferenceTime, 2)
 		assert.NoError(t, err)
 
 		resultsMap := make(map[string]map[string]int)
@@ -463,7 +557,7 @@ func TestSaveAndGetAssignmentDetails(t *testing.T) {
 
 	// Create an assignment first
 	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
-	assignment, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
 	assert.NotNil(t, assignment)
 
@@ -478,11 +572,11 @@ func TestSaveAndGetAssignmentDetails(t *testing.T) {
 	}
 
 	// Save assignment details
-	err = tracker.SaveAssignmentDetails(assignment.ID, date, "Alice", statsA, "Bob", statsB)
+	err = tracker.SaveAssignmentDetails(t.Context(), assignment.ID, date, "Alice", statsA, "Bob", statsB)
 	assert.NoError(t, err)
 
 	// Retrieve assignment details
-	details, err := tracker.GetAssignmentDetails(assignment.ID)
+	details, err := tracker.GetAssignmentDetails(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, details)
 
@@ -497,6 +591,30 @@ func TestSaveAndGetAssignmentDetails(t *testing.T) {
 	assert.Equal(t, 4, details.ParentBLast30Days)
 }
 
+func TestSaveAndGetAssignmentDetails_ExtraParents(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Grandma", date, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	extra := []NamedStats{{Name: "Grandma", Stats: Stats{TotalAssignments: 2, Last30Days: 1}}, {Name: "Sam", Stats: Stats{TotalAssignments: 4}}}
+	require.NoError(t, tracker.SaveAssignmentDetails(t.Context(), assignment.ID, date, "Alice", Stats{}, "Bob", Stats{}, extra...))
+	details, err := tracker.GetAssignmentDetails(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, extra, details.ExtraParents)
+
+	// Recalculated once the extra parents are removed
+	require.NoError(t, tracker.SaveAssignmentDetails(t.Context(), assignment.ID, date, "Alice", Stats{}, "Bob", Stats{}))
+	details, err = tracker.GetAssignmentDetails(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Empty(t, details.ExtraParents)
+}
+
 // TestGetAssignmentDetailsNotFound tests retrieving non-existent assignment details
 func TestGetAssignmentDetailsNotFound(t *testing.T) {
 	db, cleanup := setupTestDB(t)
@@ -506,7 +624,7 @@ func TestGetAssignmentDetailsNotFound(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Try to get details for non-existent assignment
-	details, err := tracker.GetAssignmentDetails(99999)
+	details, err := tracker.GetAssignmentDetails(t.Context(), 99999)
 	assert.NoError(t, err)
 	assert.Nil(t, details)
 }
@@ -521,17 +639,17 @@ func TestAssignmentDetailsCascadeDelete(t *testing.T) {
 
 	// Create an assignment
 	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
-	assignment, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
 
 	// Save assignment details
 	statsA := Stats{TotalAssignments: 5, Last30Days: 3}
 	statsB := Stats{TotalAssignments: 7, Last30Days: 4}
-	err = tracker.SaveAssignmentDetails(assignment.ID, date, "Alice", statsA, "Bob", statsB)
+	err = tracker.SaveAssignmentDetails(t.Context(), assignment.ID, date, "Alice", statsA, "Bob", statsB)
 	assert.NoError(t, err)
 
 	// Verify details exist
-	details, err := tracker.GetAssignmentDetails(assignment.ID)
+	details, err := tracker.GetAssignmentDetails(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, details)
 
@@ -540,7 +658,7 @@ func TestAssignmentDetailsCascadeDelete(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify details are also deleted (cascade)
-	details, err = tracker.GetAssignmentDetails(assignment.ID)
+	details, err = tracker.GetAssignmentDetails(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.Nil(t, details)
 }
@@ -553,13 +671,13 @@ func TestUpdateAssignmentToBabysitter(t *testing.T) {
 	assert.NoError(t, err)
 
 	date := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
-	assignment, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonAlternating)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonAlternating)
 	assert.NoError(t, err)
 
-	err = tracker.UpdateAssignmentToBabysitter(assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), assignment.ID, "Dawn", true, assignment.Version)
 	assert.NoError(t, err)
 
-	updated, err := tracker.GetAssignmentByID(assignment.ID)
+	updated, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, updated)
 	assert.Equal(t, CaregiverTypeBabysitter, updated.CaregiverType)
@@ -567,15 +685,54 @@ func TestUpdateAssignmentToBabysitter(t *testing.T) {
 	assert.True(t, updated.Override)
 	assert.Equal(t, DecisionReasonOverride, updated.DecisionReason)
 
-	err = tracker.UpdateAssignmentParent(assignment.ID, "Bob", true)
+	err = tracker.UpdateAssignmentParent(t.Context(), assignment.ID, "Bob", true, updated.Version)
 	assert.NoError(t, err)
 
-	updated, err = tracker.GetAssignmentByID(assignment.ID)
+	updated, err = tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, CaregiverTypeParent, updated.CaregiverType)
 	assert.Equal(t, "Bob", updated.Parent)
 }
 
+func TestUpdateAssignment_VersionConflict(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	date := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+	read, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), read.Version)
+
+	// A regeneration rewrites the assignment after it was read
+	regenerated, err := tracker.RecordAssignment(t.Context(), "Bob", date, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), regenerated.Version)
+
+	err = tracker.UpdateAssignmentParent(t.Context(), read.ID, "Alice", true, read.Version)
+	assert.ErrorIs(t, err, ErrAssignmentConflict)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), read.ID, "Dawn", true, read.Version)
+	assert.ErrorIs(t, err, ErrAssignmentConflict)
+
+	current, err := tracker.GetAssignmentByID(t.Context(), read.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", current.Parent, "The losing edit must not overwrite the assignment")
+	assert.False(t, current.Override)
+
+	// The calendar event id is not a change of the caregiver
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), read.ID, "event-1"))
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), read.ID, "Alice", true, regenerated.Version))
+	current, err = tracker.GetAssignmentByID(t.Context(), read.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", current.Parent)
+	assert.Equal(t, int64(3), current.Version)
+
+	err = tracker.UpdateAssignmentParent(t.Context(), read.ID+100, "Alice", true, 1)
+	assert.ErrorIs(t, err, ErrAssignmentConflict, "A deleted assignment conflicts too")
+}
+
 func TestGetParentStatsUntil_BabysitterShiftCountsForBothParents(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -585,14 +742,14 @@ func TestGetParentStatsUntil_BabysitterShiftCountsForBothParents(t *testing.T) {
 
 	until := time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)
 
-	_, err = tracker.RecordAssignment("Alice", until.AddDate(0, 0, -10), false, DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", until.AddDate(0, 0, -10), false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", until.AddDate(0, 0, -8), false, DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", until.AddDate(0, 0, -8), false, DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", until.AddDate(0, 0, -5), true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", until.AddDate(0, 0, -5), true)
 	assert.NoError(t, err)
 
-	stats, err := tracker.GetParentStatsUntil(until, "Alice", "Bob")
+	stats, err := tracker.GetParentStatsUntil(t.Context(), until, "Alice", "Bob")
 	assert.NoError(t, err)
 	// Babysitter shift adds +1 to both parents: Alice=1+1=2, Bob=1+1=2
 	assert.Equal(t, 2, stats["Alice"].TotalAssignments)
@@ -616,13 +773,13 @@ func TestGetParentStatsUntil_BabysitterShiftAppliedToZeroAssignmentParent(t *tes
 	until := time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)
 
 	// Only Alice has parent assignments; Bob has none.
-	_, err = tracker.RecordAssignment("Alice", until.AddDate(0, 0, -10), false, DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", until.AddDate(0, 0, -10), false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", until.AddDate(0, 0, -5), true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", until.AddDate(0, 0, -5), true)
 	assert.NoError(t, err)
 
 	// Pass both parent names so Bob is seeded even with 0 parent assignments.
-	stats, err := tracker.GetParentStatsUntil(until, "Alice", "Bob")
+	stats, err := tracker.GetParentStatsUntil(t.Context(), until, "Alice", "Bob")
 	assert.NoError(t, err)
 
 	// Alice = 1 parent + 1 shift = 2
@@ -642,16 +799,16 @@ func TestUnlockAssignment_ClearsBabysitterState(t *testing.T) {
 	assert.NoError(t, err)
 
 	date := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
-	assignment, err := tracker.RecordAssignment("Alice", date, true, DecisionReasonOverride)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, true, DecisionReasonOverride)
 	assert.NoError(t, err)
 
-	err = tracker.UpdateAssignmentToBabysitter(assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), assignment.ID, "Dawn", true, assignment.Version)
 	assert.NoError(t, err)
 
-	err = tracker.UnlockAssignment(assignment.ID)
+	err = tracker.UnlockAssignment(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 
-	updated, err := tracker.GetAssignmentByID(assignment.ID)
+	updated, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, updated)
 	assert.False(t, updated.Override)
@@ -672,17 +829,17 @@ func TestSaveAssignmentDetailsUpsert(t *testing.T) {
 
 	// Create an assignment
 	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
-	assignment, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
 
 	// Save initial assignment details
 	statsA := Stats{TotalAssignments: 5, Last30Days: 3}
 	statsB := Stats{TotalAssignments: 7, Last30Days: 4}
-	err = tracker.SaveAssignmentDetails(assignment.ID, date, "Alice", statsA, "Bob", statsB)
+	err = tracker.SaveAssignmentDetails(t.Context(), assignment.ID, date, "Alice", statsA, "Bob", statsB)
 	assert.NoError(t, err)
 
 	// Retrieve and verify initial details
-	details, err := tracker.GetAssignmentDetails(assignment.ID)
+	details, err := tracker.GetAssignmentDetails(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, details)
 	assert.Equal(t, 5, details.ParentATotalCount)
@@ -691,11 +848,11 @@ func TestSaveAssignmentDetailsUpsert(t *testing.T) {
 	// Update the details with new stats (simulating schedule recalculation)
 	statsA2 := Stats{TotalAssignments: 10, Last30Days: 6}
 	statsB2 := Stats{TotalAssignments: 12, Last30Days: 8}
-	err = tracker.SaveAssignmentDetails(assignment.ID, date, "Alice", statsA2, "Bob", statsB2)
+	err = tracker.SaveAssignmentDetails(t.Context(), assignment.ID, date, "Alice", statsA2, "Bob", statsB2)
 	assert.NoError(t, err)
 
 	// Retrieve and verify updated details
-	updatedDetails, err := tracker.GetAssignmentDetails(assignment.ID)
+	updatedDetails, err := tracker.GetAssignmentDetails(t.Context(), assignment.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, updatedDetails)
 	assert.Equal(t, assignment.ID, updatedDetails.AssignmentID)
@@ -720,7 +877,7 @@ func TestRecordBabysitterAssignment(t *testing.T) {
 
 	t.Run("Insert new babysitter assignment", func(t *testing.T) {
 		date := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
-		assignment, err := tracker.RecordBabysitterAssignment("Dawn", date, true)
+		assignment, err := tracker.RecordBabysitterAssignment(t.Context(), "Dawn", date, true)
 		assert.NoError(t, err)
 		assert.NotNil(t, assignment)
 		assert.Equal(t, "Dawn", assignment.Parent)
@@ -733,12 +890,12 @@ func TestRecordBabysitterAssignment(t *testing.T) {
 	t.Run("Upsert overwrites existing parent assignment", func(t *testing.T) {
 		date := time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)
 		// First record a parent
-		original, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonTotalCount)
+		original, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
 		assert.NoError(t, err)
 		assert.Equal(t, CaregiverTypeParent, original.CaregiverType)
 
 		// Now record babysitter on same date
-		updated, err := tracker.RecordBabysitterAssignment("Dawn", date, true)
+		updated, err := tracker.RecordBabysitterAssignment(t.Context(), "Dawn", date, true)
 		assert.NoError(t, err)
 		assert.Equal(t, original.ID, updated.ID, "should be the same row via upsert")
 		assert.Equal(t, CaregiverTypeBabysitter, updated.CaregiverType)
@@ -754,18 +911,18 @@ func TestGetLastAssignmentDate(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("No assignments returns zero time", func(t *testing.T) {
-		date, err := tracker.GetLastAssignmentDate()
+		date, err := tracker.GetLastAssignmentDate(t.Context())
 		assert.NoError(t, err)
 		assert.True(t, date.IsZero())
 	})
 
 	t.Run("Returns latest assignment date", func(t *testing.T) {
-		_, err := tracker.RecordAssignment("Alice", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), false, DecisionReasonTotalCount)
+		_, err := tracker.RecordAssignment(t.Context(), "Alice", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), false, DecisionReasonTotalCount)
 		assert.NoError(t, err)
-		_, err = tracker.RecordAssignment("Bob", time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), false, DecisionReasonAlternating)
+		_, err = tracker.RecordAssignment(t.Context(), "Bob", time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), false, DecisionReasonAlternating)
 		assert.NoError(t, err)
 
-		date, err := tracker.GetLastAssignmentDate()
+		date, err := tracker.GetLastAssignmentDate(t.Context())
 		assert.NoError(t, err)
 		assert.Equal(t, "2025-03-15", date.Format("2006-01-02"))
 	})
@@ -785,21 +942,21 @@ func TestGetBabysitterMonthlyStatsForLastNMonths(t *testing.T) {
 	}
 
 	// Parent assignments (should NOT appear in babysitter stats)
-	_, err = tracker.RecordAssignment("Alice", monthsAgo(0), false, DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", monthsAgo(0), false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", monthsAgo(1), false, DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", monthsAgo(1), false, DecisionReasonAlternating)
 	assert.NoError(t, err)
 
 	// Babysitter assignments
-	_, err = tracker.RecordBabysitterAssignment("Dawn", monthsAgo(0).AddDate(0, 0, 1), true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", monthsAgo(0).AddDate(0, 0, 1), true)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", monthsAgo(0).AddDate(0, 0, 2), true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", monthsAgo(0).AddDate(0, 0, 2), true)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Eve", monthsAgo(2), true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Eve", monthsAgo(2), true)
 	assert.NoError(t, err)
 
 	t.Run("Returns only babysitter stats", func(t *testing.T) {
-		stats, err := tracker.GetBabysitterMonthlyStatsForLastNMonths(refTime, 12)
+		stats, err := tracker.GetBabysitterMonthlyStatsForLastNMonths(t.Context(), refTime, 12)
 		assert.NoError(t, err)
 
 		resultsMap := make(map[string]map[string]int)
@@ -819,7 +976,7 @@ func TestGetBabysitterMonthlyStatsForLastNMonths(t *testing.T) {
 	})
 
 	t.Run("Lookback for 1 month excludes older entries", func(t *testing.T) {
-		stats, err := tracker.GetBabysitterMonthlyStatsForLastNMonths(refTime, 1)
+		stats, err := tracker.GetBabysitterMonthlyStatsForLastNMonths(t.Context(), refTime, 1)
 		assert.NoError(t, err)
 
 		for _, s := range stats {
@@ -830,7 +987,7 @@ func TestGetBabysitterMonthlyStatsForLastNMonths(t *testing.T) {
 	t.Run("No babysitter assignments returns empty", func(t *testing.T) {
 		// Query a narrow range that only contains parent assignments
 		parentOnlyRef := time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC)
-		stats, err := tracker.GetBabysitterMonthlyStatsForLastNMonths(parentOnlyRef, 1)
+		stats, err := tracker.GetBabysitterMonthlyStatsForLastNMonths(t.Context(), parentOnlyRef, 1)
 		assert.NoError(t, err)
 		assert.Empty(t, stats)
 	})
@@ -843,11 +1000,77 @@ func TestUnlockAssignment_NotFound(t *testing.T) {
 	tracker, err := New(db)
 	assert.NoError(t, err)
 
-	err = tracker.UnlockAssignment(99999)
+	err = tracker.UnlockAssignment(t.Context(), 99999)
 	assert.Error(t, err, "unlocking a nonexistent assignment should fail")
 	assert.Contains(t, err.Error(), "assignment not found")
 }
 
+func TestTracker_EmitsAssignmentCreatedAndUnlocked(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var created []signals.AssignmentCreatedData
+	var unlocked []int64
+	signals.OnAssignmentCreated(func(_ context.Context, data signals.AssignmentCreatedData) {
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, data)
+	}, "test-assignment-created")
+	defer signals.AssignmentCreated.RemoveListener("test-assignment-created")
+	signals.OnAssignmentUnlocked(func(_ context.Context, data signals.AssignmentUnlockedData) {
+		mu.Lock()
+		defer mu.Unlock()
+		unlocked = append(unlocked, data.AssignmentID)
+	}, "test-assignment-unlocked")
+	defer signals.AssignmentUnlocked.RemoveListener("test-assignment-unlocked")
+
+	date := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	// Updating the assignment of the same date is not a creation
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", date, true, DecisionReasonOverride)
+	require.NoError(t, err)
+	babysitter, err := tracker.RecordBabysitterAssignment(t.Context(), "Grandma", date.AddDate(0, 0, 1), true)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UnlockAssignment(t.Context(), assignment.ID))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []signals.AssignmentCreatedData{
+		{AssignmentID: assignment.ID, Date: "2026-10-20", Caregiver: "Alice", CaregiverType: "parent"},
+		{AssignmentID: babysitter.ID, Date: "2026-10-21", Caregiver: "Grandma", CaregiverType: "babysitter"},
+	}, created)
+	assert.Equal(t, []int64{assignment.ID}, unlocked)
+}
+
+func TestDeleteAssignment(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	versionBefore, err := tracker.GetAssignmentsVersion(t.Context())
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.DeleteAssignment(t.Context(), assignment.ID))
+
+	deleted, err := tracker.GetAssignmentByDate(t.Context(), date)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+	versionAfter, err := tracker.GetAssignmentsVersion(t.Context())
+	require.NoError(t, err)
+	assert.NotEqual(t, versionBefore, versionAfter)
+
+	assert.NoError(t, tracker.DeleteAssignment(t.Context(), assignment.ID), "deleting a missing assignment is not an error")
+}
+
 // TestGetLastAssignmentsUntil verifies that GetLastAssignmentsUntil returns all
 // caregiver types (parents and babysitters) in reverse chronological order.
 func TestGetLastAssignmentsUntil(t *testing.T) {
@@ -862,15 +1085,15 @@ func TestGetLastAssignmentsUntil(t *testing.T) {
 	day3 := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
 	until := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
 
-	_, err = tracker.RecordAssignment("Alice", day1, false, DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", day1, false, DecisionReasonAlternating)
 	assert.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Sitter", day2, false)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Sitter", day2, false)
 	assert.NoError(t, err)
-	_, err = tracker.RecordAssignment("Bob", day3, false, DecisionReasonAlternating)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", day3, false, DecisionReasonAlternating)
 	assert.NoError(t, err)
 
 	// GetLastAssignmentsUntil should return all three (parent + babysitter + parent).
-	all, err := tracker.GetLastAssignmentsUntil(5, until)
+	all, err := tracker.GetLastAssignmentsUntil(t.Context(), 5, until)
 	assert.NoError(t, err)
 	assert.Len(t, all, 3, "should include parent and babysitter assignments")
 	assert.Equal(t, "Bob", all[0].Parent)
@@ -879,3 +1102,146 @@ func TestGetLastAssignmentsUntil(t *testing.T) {
 	assert.Equal(t, "Alice", all[2].Parent)
 	assert.Equal(t, CaregiverTypeParent, all[2].CaregiverType)
 }
+
+func TestGetAssignmentsVersion(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	empty, err := tracker.GetAssignmentsVersion(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, AssignmentsVersion{}, empty)
+
+	date := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	recorded, err := tracker.GetAssignmentsVersion(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, recorded.Count)
+	assert.Equal(t, assignment.ID, recorded.MaxID)
+	assert.False(t, recorded.LastUpdate.IsZero())
+	assert.Equal(t, uint64(1), recorded.Revision)
+
+	// Updates within the same second keep updated_at, the revision still changes
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), assignment.ID, "Bob", true, assignment.Version))
+	updated, err := tracker.GetAssignmentsVersion(t.Context())
+	require.NoError(t, err)
+	assert.NotEqual(t, recorded, updated)
+
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "event"))
+	unchanged, err := tracker.GetAssignmentsVersion(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, updated.Revision, unchanged.Revision, "setting the event ID does not change what is shown")
+}
+
+func TestSetAssignmentTag(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	date := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	assert.Equal(t, AssignmentTagNone, assignment.Tag)
+
+	err = tracker.SetAssignmentTag(t.Context(), assignment.ID, AssignmentTagSickKid)
+	assert.ErrorIs(t, err, ErrAssignmentNotOverridden, "only overrides are tagged")
+	assert.Error(t, tracker.SetAssignmentTag(t.Context(), assignment.ID+100, AssignmentTagSickKid), "missing assignment")
+
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), assignment.ID, "Bob", true, assignment.Version))
+	assert.ErrorIs(t, tracker.SetAssignmentTag(t.Context(), assignment.ID, AssignmentTag("flu")), ErrInvalidAssignmentTag)
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), assignment.ID, AssignmentTagSickKid))
+	tagged, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AssignmentTagSickKid, tagged.Tag)
+	assert.Equal(t, assignment.Version+1, tagged.Version, "the tag is not a change of the caregiver")
+
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), assignment.ID, AssignmentTagNone))
+	untagged, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AssignmentTagNone, untagged.Tag)
+
+	// The tag goes with the override, by an unlock or by an undo
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), assignment.ID, AssignmentTagParentAway))
+	require.NoError(t, tracker.UnlockAssignment(t.Context(), assignment.ID))
+	unlocked, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, AssignmentTagNone, unlocked.Tag)
+
+	_, err = tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	restored, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.True(t, restored.Override)
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), assignment.ID, AssignmentTagSickKid))
+	_, err = tracker.UndoLastBatch(t.Context())
+	require.NoError(t, err)
+	restored, err = tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.Override)
+	assert.Equal(t, AssignmentTagNone, restored.Tag)
+}
+
+// TestMonthlyStatsFollowAssignmentWrites verifies that the materialized monthly counts of the past
+// months follow the assignments inserted, moved to another caregiver and deleted
+func TestMonthlyStatsFollowAssignmentWrites(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	tracker, err := New(db)
+	require.NoError(t, err)
+
+	refTime := time.Date(2025, time.May, 15, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2025, time.April, 10, 0, 0, 0, 0, time.UTC)
+
+	first, err := tracker.RecordAssignment(t.Context(), "Alice", march, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", march.AddDate(0, 0, 1), false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	second, err := tracker.RecordAssignment(t.Context(), "Bob", april, false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", refTime.AddDate(0, 0, -1), false, DecisionReasonAlternating)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", refTime.AddDate(0, 0, 1), false, DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	stats, err := tracker.GetParentMonthlyStatsForLastNMonths(t.Context(), refTime, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []MonthlyStatRow{
+		{ParentName: "Alice", MonthYear: "2025-03", Count: 2},
+		{ParentName: "Bob", MonthYear: "2025-04", Count: 1},
+		{ParentName: "Bob", MonthYear: "2025-05", Count: 1},
+	}, stats, "the current month is counted up to the reference day")
+
+	// Recording the same day again changes nothing
+	first, err = tracker.RecordAssignment(t.Context(), "Alice", march, false, DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), first.ID, "Bob", true, first.Version))
+	require.NoError(t, tracker.UpdateAssignmentToBabysitter(t.Context(), second.ID, "Dawn", true, second.Version))
+
+	stats, err = tracker.GetParentMonthlyStatsForLastNMonths(t.Context(), refTime, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []MonthlyStatRow{
+		{ParentName: "Alice", MonthYear: "2025-03", Count: 1},
+		{ParentName: "Bob", MonthYear: "2025-03", Count: 1},
+		{ParentName: "Bob", MonthYear: "2025-05", Count: 1},
+	}, stats)
+	babysitterStats, err := tracker.GetBabysitterMonthlyStatsForLastNMonths(t.Context(), refTime, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []MonthlyStatRow{{ParentName: "Dawn", MonthYear: "2025-04", Count: 1}}, babysitterStats)
+
+	require.NoError(t, tracker.DeleteAssignment(t.Context(), first.ID))
+	stats, err = tracker.GetParentMonthlyStatsForLastNMonths(t.Context(), refTime, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []MonthlyStatRow{
+		{ParentName: "Alice", MonthYear: "2025-03", Count: 1},
+		{ParentName: "Bob", MonthYear: "2025-05", Count: 1},
+	}, stats)
+
+	var materialized int
+	require.NoError(t, db.Conn().QueryRow(`SELECT COUNT(*) FROM assignment_monthly_stats WHERE count <= 0`).Scan(&materialized))
+	assert.Zero(t, materialized, "emptied counts are dropped")
+}
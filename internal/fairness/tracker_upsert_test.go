@@ -19,7 +19,7 @@ func TestUpsertBehavior(t *testing.T) {
 	date := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
 
 	// Step 1: Create initial assignment
-	assignment1, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonTotalCount)
+	assignment1, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
 	assert.NotNil(t, assignment1)
 	assert.Equal(t, "Alice", assignment1.Parent)
@@ -28,7 +28,7 @@ func TestUpsertBehavior(t *testing.T) {
 	initialID := assignment1.ID
 
 	// Step 2: Update the same date with a different parent (should update existing record)
-	assignment2, err := tracker.RecordAssignment("Bob", date, false, DecisionReasonRecentCount)
+	assignment2, err := tracker.RecordAssignment(t.Context(), "Bob", date, false, DecisionReasonRecentCount)
 	assert.NoError(t, err)
 	assert.NotNil(t, assignment2)
 	assert.Equal(t, initialID, assignment2.ID, "ID should remain the same on conflict") // Confirm same record
@@ -37,7 +37,7 @@ func TestUpsertBehavior(t *testing.T) {
 	assert.False(t, assignment2.Override)
 
 	// Step 3: Update with override flag set to true
-	assignment3, err := tracker.RecordAssignment("Charlie", date, true, DecisionReasonOverride)
+	assignment3, err := tracker.RecordAssignment(t.Context(), "Charlie", date, true, DecisionReasonOverride)
 	assert.NoError(t, err)
 	assert.NotNil(t, assignment3)
 	assert.Equal(t, initialID, assignment3.ID, "ID should remain the same on conflict")
@@ -46,7 +46,7 @@ func TestUpsertBehavior(t *testing.T) {
 	assert.True(t, assignment3.Override, "Override flag should be updated")
 
 	// Step 4: Update back to false for override
-	assignment4, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonConsecutiveLimit)
+	assignment4, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonConsecutiveLimit)
 	assert.NoError(t, err)
 	assert.NotNil(t, assignment4)
 	assert.Equal(t, initialID, assignment4.ID, "ID should remain the same on conflict")
@@ -73,7 +73,7 @@ func TestConflictPreservesMetadata(t *testing.T) {
 	date := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
 
 	// Create initial assignment
-	assignment1, err := tracker.RecordAssignment("Alice", date, false, DecisionReasonTotalCount)
+	assignment1, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, DecisionReasonTotalCount)
 	assert.NoError(t, err)
 	initialCreatedAt := assignment1.CreatedAt
 
@@ -81,7 +81,7 @@ func TestConflictPreservesMetadata(t *testing.T) {
 	time.Sleep(1 * time.Second)
 
 	// Update the same assignment
-	assignment2, err := tracker.RecordAssignment("Bob", date, true, DecisionReasonOverride)
+	assignment2, err := tracker.RecordAssignment(t.Context(), "Bob", date, true, DecisionReasonOverride)
 	assert.NoError(t, err)
 
 	// Check that created_at is preserved but updated_at is changed
@@ -0,0 +1,467 @@
+// Package fakecalendar is an in-memory fake of the subset of the Google Calendar API used by the
+// service: the events of a calendar, the calendar list and the notification channels. Pointing the
+// calendar clients at it runs full syncs in the integration tests and in the offline demo mode.
+package fakecalendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// PrimaryCalendarID is the calendar every server starts with
+const PrimaryCalendarID = "primary"
+
+// defaultChannelTTL is how long a channel lives without ttl parameter, as with Google
+const defaultChannelTTL = 7 * 24 * time.Hour
+
+// apiPrefix is the path of the Calendar API on Google; the routes are served with and without it
+const apiPrefix = "/calendar/v3"
+
+// Server serves the fake Calendar API. Its calendars are created on first use; deleted events are
+// removed instead of being kept as cancelled.
+type Server struct {
+	mu        sync.Mutex
+	calendars map[string]*fakeCalendar
+	channels  map[string]*calendar.Channel
+	nextID    int
+	mux       *http.ServeMux
+	now       func() time.Time
+}
+
+// fakeCalendar is a calendar of the server
+type fakeCalendar struct {
+	summary string
+	events  map[string]*calendar.Event
+}
+
+// New creates a server holding an empty primary calendar
+func New() *Server {
+	s := &Server{
+		calendars: make(map[string]*fakeCalendar),
+		channels:  make(map[string]*calendar.Channel),
+		nextID:    1,
+		mux:       http.NewServeMux(),
+		now:       time.Now,
+	}
+	s.AddCalendar(PrimaryCalendarID, "Primary")
+
+	routes := map[string]http.HandlerFunc{
+		"GET /users/me/calendarList":                      s.handleCalendarList,
+		"GET /calendars/{calendarID}/events":              s.handleList,
+		"POST /calendars/{calendarID}/events":             s.handleInsert,
+		"POST /calendars/{calendarID}/events/watch":       s.handleWatch,
+		"GET /calendars/{calendarID}/events/{eventID}":    s.handleGet,
+		"PUT /calendars/{calendarID}/events/{eventID}":    s.handleUpdate,
+		"PATCH /calendars/{calendarID}/events/{eventID}":  s.handlePatch,
+		"DELETE /calendars/{calendarID}/events/{eventID}": s.handleDelete,
+		"POST /channels/stop":                             s.handleStop,
+	}
+	for pattern, handler := range routes {
+		method, path, _ := strings.Cut(pattern, " ")
+		s.mux.HandleFunc(pattern, handler)
+		s.mux.HandleFunc(method+" "+apiPrefix+path, handler)
+	}
+	return s
+}
+
+// ServeHTTP serves the Calendar API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// AddCalendar adds a calendar listed with summary, renaming it when it exists
+func (s *Server) AddCalendar(calendarID, summary string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calendar(calendarID).summary = summary
+}
+
+// AddEvent stores a copy of event in the calendar, as if written by the user, and returns it. An
+// event without ID gets one, an event without update time is updated now.
+func (s *Server) AddEvent(calendarID string, event *calendar.Event) *calendar.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.store(s.calendar(calendarID), cloneEvent(event))
+	if event.Updated != "" {
+		stored.Updated = event.Updated
+	}
+	return cloneEvent(stored)
+}
+
+// Event returns a copy of the event of the calendar, false when there is none
+func (s *Server) Event(calendarID, eventID string) (*calendar.Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event, ok := s.calendar(calendarID).events[eventID]
+	return cloneEvent(event), ok
+}
+
+// Events returns copies of the events of the calendar, by start
+func (s *Server) Events(calendarID string) []*calendar.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedEvents(s.calendar(calendarID), "")
+}
+
+// EventCount returns the number of events of the calendar
+func (s *Server) EventCount(calendarID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.calendar(calendarID).events)
+}
+
+// Channels returns the open notification channels, by ID
+func (s *Server) Channels() []*calendar.Channel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	channels := make([]*calendar.Channel, 0, len(s.channels))
+	for _, channel := range s.channels {
+		copied := *channel
+		channels = append(channels, &copied)
+	}
+	slices.SortFunc(channels, func(a, b *calendar.Channel) int { return strings.Compare(a.Id, b.Id) })
+	return channels
+}
+
+// calendar returns the calendar of calendarID, creating it on first use. The lock must be held.
+func (s *Server) calendar(calendarID string) *fakeCalendar {
+	cal, ok := s.calendars[calendarID]
+	if !ok {
+		cal = &fakeCalendar{summary: calendarID, events: make(map[string]*calendar.Event)}
+		s.calendars[calendarID] = cal
+	}
+	return cal
+}
+
+// store saves event in cal, setting its ID, status and timestamps. The lock must be held.
+func (s *Server) store(cal *fakeCalendar, event *calendar.Event) *calendar.Event {
+	now := s.now().UTC().Format(time.RFC3339Nano)
+	if event.Id == "" {
+		event.Id = fmt.Sprintf("event%d", s.nextID)
+		s.nextID++
+	}
+	if event.Status == "" {
+		event.Status = "confirmed"
+	}
+	if event.Created == "" {
+		event.Created = now
+	}
+	event.Updated = now
+	cal.events[event.Id] = event
+	return event
+}
+
+// sortedEvents returns copies of the events of cal, by start or by update with orderBy "updated".
+// The lock must be held.
+func (s *Server) sortedEvents(cal *fakeCalendar, orderBy string) []*calendar.Event {
+	events := make([]*calendar.Event, 0, len(cal.events))
+	for _, event := range cal.events {
+		events = append(events, cloneEvent(event))
+	}
+	slices.SortFunc(events, func(a, b *calendar.Event) int {
+		if orderBy == "updated" {
+			if c := strings.Compare(a.Updated, b.Updated); c != 0 {
+				return c
+			}
+		} else if c := eventStart(a).Compare(eventStart(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return events
+}
+
+func (s *Server) handleCalendarList(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	list := &calendar.CalendarList{Kind: "calendar#calendarList"}
+	for id, cal := range s.calendars {
+		list.Items = append(list.Items, &calendar.CalendarListEntry{
+			Id:         id,
+			Summary:    cal.summary,
+			AccessRole: "owner",
+			Primary:    id == PrimaryCalendarID,
+		})
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(list.Items, func(a, b *calendar.CalendarListEntry) int { return strings.Compare(a.Id, b.Id) })
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleList lists the events with the filters of the service: timeMin, timeMax, updatedMin, q,
+// privateExtendedProperty, showDeleted, orderBy and maxResults. There is a single page.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var filters []func(*calendar.Event) bool
+	for _, bound := range []struct {
+		param string
+		keep  func(event *calendar.Event, at time.Time) bool
+	}{
+		{"timeMin", func(event *calendar.Event, at time.Time) bool { return eventEnd(event).After(at) }},
+		{"timeMax", func(event *calendar.Event, at time.Time) bool { return eventStart(event).Before(at) }},
+		{"updatedMin", func(event *calendar.Event, at time.Time) bool {
+			updated, err := time.Parse(time.RFC3339Nano, event.Updated)
+			return err == nil && !updated.Before(at)
+		}},
+	} {
+		value := query.Get(bound.param)
+		if value == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid", fmt.Sprintf("Invalid %s: %s", bound.param, value))
+			return
+		}
+		keep := bound.keep
+		filters = append(filters, func(event *calendar.Event) bool { return keep(event, at) })
+	}
+	if q := strings.ToLower(query.Get("q")); q != "" {
+		filters = append(filters, func(event *calendar.Event) bool {
+			return strings.Contains(strings.ToLower(event.Summary+"\n"+event.Description+"\n"+event.Location), q)
+		})
+	}
+	for _, property := range query["privateExtendedProperty"] {
+		key, value, _ := strings.Cut(property, "=")
+		filters = append(filters, func(event *calendar.Event) bool {
+			return event.ExtendedProperties != nil && event.ExtendedProperties.Private[key] == value
+		})
+	}
+	if query.Get("showDeleted") != "true" {
+		filters = append(filters, func(event *calendar.Event) bool { return event.Status != "cancelled" })
+	}
+
+	s.mu.Lock()
+	all := s.sortedEvents(s.calendar(r.PathValue("calendarID")), query.Get("orderBy"))
+	s.mu.Unlock()
+
+	items := make([]*calendar.Event, 0, len(all))
+	for _, event := range all {
+		if !slices.ContainsFunc(filters, func(keep func(*calendar.Event) bool) bool { return !keep(event) }) {
+			items = append(items, event)
+		}
+	}
+	if maxResults, err := strconv.Atoi(query.Get("maxResults")); err == nil && maxResults > 0 && len(items) > maxResults {
+		items = items[:maxResults]
+	}
+	writeJSON(w, http.StatusOK, &calendar.Events{Kind: "calendar#events", Items: items})
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	event, ok := s.Event(r.PathValue("calendarID"), r.PathValue("eventID"))
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, event)
+}
+
+func (s *Server) handleInsert(w http.ResponseWriter, r *http.Request) {
+	var event calendar.Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		writeError(w, http.StatusBadRequest, "parseError", "Invalid event: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	cal := s.calendar(r.PathValue("calendarID"))
+	if _, exists := cal.events[event.Id]; exists {
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, "duplicate", "The requested identifier already exists.")
+		return
+	}
+	event.Created = ""
+	stored := cloneEvent(s.store(cal, &event))
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, stored)
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var event calendar.Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		writeError(w, http.StatusBadRequest, "parseError", "Invalid event: "+err.Error())
+		return
+	}
+	s.replace(w, r, func(existing *calendar.Event) *calendar.Event {
+		event.Created = existing.Created
+		return &event
+	})
+}
+
+// handlePatch merges the fields of the body into the event, as Google does for the fields sent
+func (s *Server) handlePatch(w http.ResponseWriter, r *http.Request) {
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, "parseError", "Invalid event: "+err.Error())
+		return
+	}
+	s.replace(w, r, func(existing *calendar.Event) *calendar.Event {
+		var fields map[string]json.RawMessage
+		raw, _ := json.Marshal(existing)
+		_ = json.Unmarshal(raw, &fields)
+		for name, value := range patch {
+			fields[name] = value
+		}
+		var patched calendar.Event
+		raw, _ = json.Marshal(fields)
+		if err := json.Unmarshal(raw, &patched); err != nil {
+			return nil
+		}
+		return &patched
+	})
+}
+
+// replace stores the event returned by change from the existing event of the request
+func (s *Server) replace(w http.ResponseWriter, r *http.Request, change func(existing *calendar.Event) *calendar.Event) {
+	eventID := r.PathValue("eventID")
+
+	s.mu.Lock()
+	cal := s.calendar(r.PathValue("calendarID"))
+	existing, ok := cal.events[eventID]
+	if !ok {
+		s.mu.Unlock()
+		writeNotFound(w)
+		return
+	}
+	event := change(existing)
+	if event == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "invalid", "Invalid event")
+		return
+	}
+	event.Id = eventID
+	stored := cloneEvent(s.store(cal, event))
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, stored)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	cal := s.calendar(r.PathValue("calendarID"))
+	eventID := r.PathValue("eventID")
+	_, ok := cal.events[eventID]
+	delete(cal.events, eventID)
+	s.mu.Unlock()
+
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleWatch opens a channel on the calendar. No notification is ever pushed to its address.
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	var channel calendar.Channel
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil || channel.Id == "" {
+		writeError(w, http.StatusBadRequest, "required", "Required: channel id")
+		return
+	}
+	ttl := defaultChannelTTL
+	if seconds, err := strconv.Atoi(channel.Params["ttl"]); err == nil && seconds > 0 {
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	s.mu.Lock()
+	channel.Kind = "api#channel"
+	channel.ResourceId = fmt.Sprintf("resource-%s", r.PathValue("calendarID"))
+	channel.ResourceUri = r.URL.String()
+	channel.Expiration = s.now().Add(ttl).UnixMilli()
+	s.channels[channel.Id] = &channel
+	created := channel
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, &created)
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	var channel calendar.Channel
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+		writeError(w, http.StatusBadRequest, "parseError", "Invalid channel: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	_, ok := s.channels[channel.Id]
+	delete(s.channels, channel.Id)
+	s.mu.Unlock()
+
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// eventStart returns the start of the event; an all-day event starts at midnight UTC
+func eventStart(event *calendar.Event) time.Time {
+	return eventTime(event.Start)
+}
+
+// eventEnd returns the end of the event, its start when it has none
+func eventEnd(event *calendar.Event) time.Time {
+	if event.End == nil {
+		return eventStart(event)
+	}
+	return eventTime(event.End)
+}
+
+func eventTime(at *calendar.EventDateTime) time.Time {
+	if at == nil {
+		return time.Time{}
+	}
+	if at.DateTime != "" {
+		parsed, _ := time.Parse(time.RFC3339, at.DateTime)
+		return parsed
+	}
+	parsed, _ := time.Parse(time.DateOnly, at.Date)
+	return parsed
+}
+
+// cloneEvent returns a deep copy of event, nil for nil
+func cloneEvent(event *calendar.Event) *calendar.Event {
+	if event == nil {
+		return nil
+	}
+	raw, err := json.Marshal(event)
+	if err != nil {
+		panic(fmt.Sprintf("fakecalendar: failed to copy event: %v", err))
+	}
+	var cloned calendar.Event
+	if err := json.Unmarshal(raw, &cloned); err != nil {
+		panic(fmt.Sprintf("fakecalendar: failed to copy event: %v", err))
+	}
+	return &cloned
+}
+
+// writeJSON writes payload as the JSON answer of the request
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// writeError writes an error in the format of the Google APIs, parsed into a googleapi.Error
+func writeError(w http.ResponseWriter, status int, reason, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"code":    status,
+			"message": message,
+			"errors":  []map[string]string{{"domain": "global", "reason": reason, "message": message}},
+		},
+	})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "notFound", "Not Found")
+}
@@ -0,0 +1,147 @@
+package fakecalendar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// newTestService returns a Calendar API client of a new fake server
+func newTestService(t *testing.T) (*Server, *calendar.Service) {
+	fake := New()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	srv, err := calendar.NewService(t.Context(), option.WithHTTPClient(server.Client()), option.WithEndpoint(server.URL+"/"))
+	require.NoError(t, err)
+	return fake, srv
+}
+
+func allDay(date string) *calendar.EventDateTime {
+	return &calendar.EventDateTime{Date: date}
+}
+
+func requireStatus(t *testing.T, err error, status int) {
+	var apiErr *googleapi.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, status, apiErr.Code)
+}
+
+func TestServer_Events(t *testing.T) {
+	fake, srv := newTestService(t)
+
+	created, err := srv.Events.Insert(PrimaryCalendarID, &calendar.Event{
+		Summary: "[Alice] Routine",
+		Start:   allDay("2026-10-16"),
+		End:     allDay("2026-10-17"),
+	}).Do()
+	require.NoError(t, err)
+	require.NotEmpty(t, created.Id)
+	assert.Equal(t, "confirmed", created.Status)
+	assert.NotEmpty(t, created.Updated)
+	assert.Equal(t, 1, fake.EventCount(PrimaryCalendarID))
+
+	got, err := srv.Events.Get(PrimaryCalendarID, created.Id).Do()
+	require.NoError(t, err)
+	assert.Equal(t, "[Alice] Routine", got.Summary)
+
+	got.Summary = "[Bob] Routine"
+	_, err = srv.Events.Update(PrimaryCalendarID, got.Id, got).Do()
+	require.NoError(t, err)
+	_, err = srv.Events.Patch(PrimaryCalendarID, got.Id, &calendar.Event{Description: "Patched"}).Do()
+	require.NoError(t, err)
+	stored, ok := fake.Event(PrimaryCalendarID, created.Id)
+	require.True(t, ok)
+	assert.Equal(t, "[Bob] Routine", stored.Summary, "a patch keeps the fields it does not send")
+	assert.Equal(t, "Patched", stored.Description)
+
+	_, err = srv.Events.Insert(PrimaryCalendarID, &calendar.Event{Id: created.Id}).Do()
+	requireStatus(t, err, http.StatusConflict)
+
+	require.NoError(t, srv.Events.Delete(PrimaryCalendarID, created.Id).Do())
+	_, ok = fake.Event(PrimaryCalendarID, created.Id)
+	assert.False(t, ok)
+	requireStatus(t, srv.Events.Delete(PrimaryCalendarID, created.Id).Do(), http.StatusNotFound)
+	_, err = srv.Events.Get(PrimaryCalendarID, created.Id).Do()
+	requireStatus(t, err, http.StatusNotFound)
+	_, err = srv.Events.Update(PrimaryCalendarID, created.Id, got).Do()
+	requireStatus(t, err, http.StatusNotFound)
+}
+
+func TestServer_ListFilters(t *testing.T) {
+	fake, srv := newTestService(t)
+	fake.now = func() time.Time { return time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC) }
+	managed := &calendar.EventExtendedProperties{Private: map[string]string{"app": "Night Routine"}}
+	fake.AddEvent(PrimaryCalendarID, &calendar.Event{Id: "first", Summary: "On call", Start: allDay("2026-10-16"), End: allDay("2026-10-17"), ExtendedProperties: managed})
+	fake.AddEvent(PrimaryCalendarID, &calendar.Event{Id: "second", Summary: "Dinner", Start: allDay("2026-10-18"), End: allDay("2026-10-19"),
+		Updated: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)})
+	fake.AddEvent(PrimaryCalendarID, &calendar.Event{Id: "timed", Summary: "Meeting",
+		Start: &calendar.EventDateTime{DateTime: "2026-10-17T09:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2026-10-17T10:00:00Z"}})
+	fake.AddEvent(PrimaryCalendarID, &calendar.Event{Id: "cancelled", Status: "cancelled", Start: allDay("2026-10-17"), End: allDay("2026-10-18")})
+	fake.AddEvent("alice@example.com", &calendar.Event{Id: "personal", Start: allDay("2026-10-16"), End: allDay("2026-10-17")})
+
+	ids := func(call *calendar.EventsListCall) []string {
+		events, err := call.Do()
+		require.NoError(t, err)
+		var ids []string
+		for _, event := range events.Items {
+			ids = append(ids, event.Id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"first", "timed", "second"}, ids(srv.Events.List(PrimaryCalendarID).OrderBy("startTime")))
+	assert.Equal(t, []string{"first", "cancelled", "timed", "second"}, ids(srv.Events.List(PrimaryCalendarID).ShowDeleted(true)))
+	assert.Equal(t, []string{"timed"}, ids(srv.Events.List(PrimaryCalendarID).TimeMin("2026-10-17T00:00:00Z").TimeMax("2026-10-18T00:00:00Z")))
+	assert.Equal(t, []string{"first"}, ids(srv.Events.List(PrimaryCalendarID).PrivateExtendedProperty("app=Night Routine")))
+	assert.Equal(t, []string{"first"}, ids(srv.Events.List(PrimaryCalendarID).Q("on CALL")))
+	assert.Equal(t, []string{"first", "timed"}, ids(srv.Events.List(PrimaryCalendarID).UpdatedMin("2026-10-02T00:00:00Z")))
+	assert.Equal(t, []string{"second", "first"}, ids(srv.Events.List(PrimaryCalendarID).OrderBy("updated").MaxResults(2)))
+	assert.Equal(t, []string{"personal"}, ids(srv.Events.List("alice@example.com")))
+
+	_, err := srv.Events.List(PrimaryCalendarID).TimeMin("yesterday").Do()
+	requireStatus(t, err, http.StatusBadRequest)
+
+	calendars, err := srv.CalendarList.List().Do()
+	require.NoError(t, err)
+	require.Len(t, calendars.Items, 2)
+	assert.Equal(t, "alice@example.com", calendars.Items[0].Id)
+	assert.True(t, calendars.Items[1].Primary)
+}
+
+func TestServer_Channels(t *testing.T) {
+	fake, srv := newTestService(t)
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	fake.now = func() time.Time { return now }
+
+	created, err := srv.Events.Watch(PrimaryCalendarID, &calendar.Channel{
+		Id:      "night-routine-1",
+		Type:    "web_hook",
+		Address: "https://night.example.com/api/webhook/calendar",
+		Params:  map[string]string{"ttl": "3600"},
+	}).Do()
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ResourceId)
+	assert.Equal(t, now.Add(time.Hour).UnixMilli(), created.Expiration)
+	require.Len(t, fake.Channels(), 1)
+
+	require.NoError(t, srv.Channels.Stop(&calendar.Channel{Id: created.Id, ResourceId: created.ResourceId}).Do())
+	assert.Empty(t, fake.Channels())
+	requireStatus(t, srv.Channels.Stop(&calendar.Channel{Id: created.Id}).Do(), http.StatusNotFound)
+}
+
+func TestServer_GooglePaths(t *testing.T) {
+	fake := New()
+	fake.AddEvent(PrimaryCalendarID, &calendar.Event{Id: "event", Start: allDay("2026-10-16")})
+
+	w := httptest.NewRecorder()
+	fake.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/calendar/v3/calendars/primary/events/event", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
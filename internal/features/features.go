@@ -0,0 +1,145 @@
+// Package features gates the experimental subsystems at runtime, so that they can ship disabled and
+// be enabled per instance from the admin API without a restart.
+package features
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// Flag names a subsystem gated at runtime
+type Flag string
+
+// The gated subsystems
+const (
+	ICSFeed                Flag = "ics_feed"                // iCalendar feed at /calendar.ics and its URL on the self-service page
+	WebhookPolling         Flag = "webhook_polling"         // Polling of the calendar when the notifications cannot reach public_url
+	CalendarUnavailability Flag = "calendar_unavailability" // Parents made unavailable by the keyword events of their calendar
+	ImbalanceAlert         Flag = "imbalance_alert"         // Notification when the last 30 days are unbalanced
+	AbsenceSuggestions     Flag = "absence_suggestions"     // Notification suggesting the weekdays a parent is always overridden on
+)
+
+// Definition describes a flag and its state when it was never set
+type Definition struct {
+	Flag        Flag
+	Description string
+	Default     bool
+}
+
+// Definitions are the known flags. A new subsystem ships disabled with Default false, and is made
+// enabled by default once stable.
+var Definitions = []Definition{
+	{ICSFeed, "iCalendar feed of the schedule, authenticated by the self-service links", true},
+	{WebhookPolling, "Poll the calendar for changed events when the notifications cannot reach public_url", true},
+	{CalendarUnavailability, "Make a parent unavailable on the days of the keyword events of their calendar", true},
+	{ImbalanceAlert, "Alert when the nights of the last 30 days reach notify.imbalance_threshold", true},
+	{AbsenceSuggestions, "Suggest marking unavailable the weekdays a parent is overridden on week after week", true},
+}
+
+// ErrUnknownFlag is returned for a name that is not a known flag
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Lookup returns the definition of the flag named name
+func Lookup(name string) (Definition, bool) {
+	for _, definition := range Definitions {
+		if string(definition.Flag) == name {
+			return definition, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Store persists the flags set from the admin API, implemented by database.FeatureFlagStore
+type Store interface {
+	GetFeatureFlag(name string) (enabled bool, ok bool, err error)
+	ListFeatureFlags() (map[string]bool, error)
+	SetFeatureFlag(name string, enabled bool, now time.Time) error
+	DeleteFeatureFlag(name string) error
+}
+
+// State is a flag with its current state
+type State struct {
+	Definition
+	Enabled bool
+	Set     bool // False while the flag has its default state
+}
+
+// Flags reads and sets the flags. A nil *Flags gives every flag its default state.
+type Flags struct {
+	store  Store
+	logger zerolog.Logger
+}
+
+// New creates the flags stored in store
+func New(store Store) *Flags {
+	return &Flags{store: store, logger: logging.GetLogger("features")}
+}
+
+// Enabled tells whether flag is enabled. It is read from the store at every call, so that a change
+// applies to the next use of the subsystem; a flag that cannot be read has its default state.
+func (f *Flags) Enabled(flag Flag) bool {
+	definition, known := Lookup(string(flag))
+	if f == nil {
+		return definition.Default
+	}
+	flagLogger := f.logger.With().Str("flag", string(flag)).Logger()
+	if !known {
+		flagLogger.Warn().Msg("Unknown feature flag, disabled")
+		return false
+	}
+	enabled, ok, err := f.store.GetFeatureFlag(string(flag))
+	if err != nil {
+		flagLogger.Warn().Err(err).Bool("default", definition.Default).Msg("Failed to read the feature flag, using its default")
+		return definition.Default
+	}
+	if !ok {
+		return definition.Default
+	}
+	return enabled
+}
+
+// List returns the state of every known flag, in the order of Definitions. The flags stored for a
+// subsystem that no longer exists are left out.
+func (f *Flags) List() ([]State, error) {
+	stored, err := f.store.ListFeatureFlags()
+	if err != nil {
+		return nil, err
+	}
+	states := make([]State, 0, len(Definitions))
+	for _, definition := range Definitions {
+		enabled, set := stored[string(definition.Flag)]
+		if !set {
+			enabled = definition.Default
+		}
+		states = append(states, State{Definition: definition, Enabled: enabled, Set: set})
+	}
+	return states, nil
+}
+
+// Set enables or disables the flag named name from now on
+func (f *Flags) Set(name string, enabled bool, now time.Time) error {
+	if _, ok := Lookup(name); !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownFlag, name)
+	}
+	if err := f.store.SetFeatureFlag(name, enabled, now); err != nil {
+		return err
+	}
+	f.logger.Info().Str("flag", name).Bool("enabled", enabled).Msg("Feature flag set")
+	return nil
+}
+
+// Reset gives the flag named name its default state back
+func (f *Flags) Reset(name string) error {
+	if _, ok := Lookup(name); !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownFlag, name)
+	}
+	if err := f.store.DeleteFeatureFlag(name); err != nil {
+		return err
+	}
+	f.logger.Info().Str("flag", name).Msg("Feature flag reset to its default")
+	return nil
+}
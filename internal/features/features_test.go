@@ -0,0 +1,74 @@
+package features
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore keeps the flags in memory, failing every read when err is set
+type memoryStore struct {
+	flags map[string]bool
+	err   error
+}
+
+func (s *memoryStore) GetFeatureFlag(name string) (bool, bool, error) {
+	enabled, ok := s.flags[name]
+	return enabled, ok, s.err
+}
+
+func (s *memoryStore) ListFeatureFlags() (map[string]bool, error) {
+	return s.flags, s.err
+}
+
+func (s *memoryStore) SetFeatureFlag(name string, enabled bool, _ time.Time) error {
+	s.flags[name] = enabled
+	return nil
+}
+
+func (s *memoryStore) DeleteFeatureFlag(name string) error {
+	delete(s.flags, name)
+	return nil
+}
+
+func TestFlags_Enabled(t *testing.T) {
+	var unset *Flags
+	assert.True(t, unset.Enabled(ICSFeed), "a nil Flags gives the default state")
+	assert.True(t, unset.Enabled(WebhookPolling))
+
+	store := &memoryStore{flags: map[string]bool{}}
+	flags := New(store)
+	assert.True(t, flags.Enabled(ICSFeed))
+	assert.False(t, flags.Enabled(Flag("caldav")), "an unknown flag is disabled")
+
+	require.NoError(t, flags.Set(string(ICSFeed), false, time.Now()))
+	require.NoError(t, flags.Set(string(WebhookPolling), false, time.Now()))
+	assert.False(t, flags.Enabled(ICSFeed))
+	assert.False(t, flags.Enabled(WebhookPolling))
+
+	require.NoError(t, flags.Reset(string(WebhookPolling)))
+	assert.True(t, flags.Enabled(WebhookPolling), "a reset flag has its default state back")
+
+	store.err = errors.New("database is locked")
+	assert.True(t, flags.Enabled(ICSFeed), "a flag that cannot be read has its default state")
+
+	require.ErrorIs(t, flags.Set("caldav", true, time.Now()), ErrUnknownFlag)
+	require.ErrorIs(t, flags.Reset("caldav"), ErrUnknownFlag)
+}
+
+func TestFlags_List(t *testing.T) {
+	flags := New(&memoryStore{flags: map[string]bool{"ics_feed": true, "removed_subsystem": true}})
+
+	states, err := flags.List()
+	require.NoError(t, err)
+	require.Len(t, states, len(Definitions), "the flags of removed subsystems are left out")
+	assert.Equal(t, ICSFeed, states[0].Flag)
+	assert.True(t, states[0].Enabled)
+	assert.True(t, states[0].Set)
+	assert.Equal(t, WebhookPolling, states[1].Flag)
+	assert.True(t, states[1].Enabled)
+	assert.False(t, states[1].Set)
+}
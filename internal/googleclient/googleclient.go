@@ -0,0 +1,67 @@
+// Package googleclient provides the HTTP client shared by every call to the Google APIs.
+// A single pooled transport keeps the TLS connections to Google alive between operations
+// instead of opening new ones for every calendar service or token refresh.
+package googleclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// dialTimeout bounds establishing a TCP connection
+	dialTimeout = 10 * time.Second
+	// keepAlive is the interval of the TCP keep-alive probes
+	keepAlive = 30 * time.Second
+	// tlsHandshakeTimeout bounds the TLS handshake
+	tlsHandshakeTimeout = 10 * time.Second
+	// responseHeaderTimeout bounds the wait for the response headers once the request is sent
+	responseHeaderTimeout = 30 * time.Second
+	// idleConnTimeout is how long an idle connection is kept in the pool
+	idleConnTimeout = 90 * time.Second
+	// maxIdleConnsPerHost is the number of idle connections kept per Google host
+	maxIdleConnsPerHost = 10
+	// requestTimeout bounds a whole request, including reading the response body
+	requestTimeout = 60 * time.Second
+)
+
+// HTTPClient returns the client shared by the Google API calls. Its transport is pooled and traced.
+var HTTPClient = sync.OnceValue(func() *http.Client {
+	return &http.Client{
+		Transport: otelhttp.NewTransport(newTransport()),
+		Timeout:   requestTimeout,
+	}
+})
+
+// newTransport returns the default transport, keeping the proxy settings and HTTP/2, with tuned pooling and timeouts
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: keepAlive,
+	}).DialContext
+	transport.TLSHandshakeTimeout = tlsHandshakeTimeout
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+	transport.IdleConnTimeout = idleConnTimeout
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	return transport
+}
+
+// Context returns ctx carrying the shared client, which the oauth2 package then uses
+// for token exchanges and refreshes and as the base transport of the clients it builds
+func Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, HTTPClient())
+}
+
+// New returns a client authenticated with token on top of the shared transport
+func New(ctx context.Context, config *oauth2.Config, token *oauth2.Token) *http.Client {
+	client := config.Client(Context(ctx), token)
+	client.Timeout = requestTimeout
+	return client
+}
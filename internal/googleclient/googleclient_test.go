@@ -0,0 +1,63 @@
+package googleclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestHTTPClient_IsShared(t *testing.T) {
+	assert.Same(t, HTTPClient(), HTTPClient())
+	assert.Equal(t, requestTimeout, HTTPClient().Timeout)
+}
+
+func TestNewTransport(t *testing.T) {
+	transport := newTransport()
+	assert.Equal(t, tlsHandshakeTimeout, transport.TLSHandshakeTimeout)
+	assert.Equal(t, responseHeaderTimeout, transport.ResponseHeaderTimeout)
+	assert.Equal(t, idleConnTimeout, transport.IdleConnTimeout)
+	assert.Equal(t, maxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.Proxy, "proxy settings of the environment are kept")
+}
+
+func TestNew_UsesSharedTransport(t *testing.T) {
+	config := &oauth2.Config{}
+	client := New(context.Background(), config, &oauth2.Token{AccessToken: "access"})
+
+	transport, ok := client.Transport.(*oauth2.Transport)
+	require.True(t, ok)
+	assert.Same(t, HTTPClient().Transport, transport.Base)
+	assert.Equal(t, requestTimeout, client.Timeout)
+}
+
+func TestNew_ReusesConnections(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	// Two clients, as built by two operations, share the pool
+	for range 2 {
+		client := New(context.Background(), &oauth2.Config{}, &oauth2.Token{AccessToken: "access"})
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, int32(1), connections.Load())
+}
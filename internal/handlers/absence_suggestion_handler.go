@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/rs/zerolog"
+)
+
+// errNotSuggested is returned by applySuggestion when the weekday is not a current suggestion of the parent
+var errNotSuggested = errors.New("not a current absence suggestion")
+
+// AvailabilityStore saves the unavailable days of the parents, implemented by database.ConfigStore
+type AvailabilityStore interface {
+	GetAvailability(parent string) ([]string, error)
+	SaveAvailability(ctx context.Context, parent string, unavailableDays []string) error
+}
+
+// AbsenceSuggestionHandler lists the weekdays the parents are overridden on week after week and
+// marks them unavailable in one call
+type AbsenceSuggestionHandler struct {
+	*BaseHandler
+	nights          fairness.OverriddenNightsSource
+	availability    AvailabilityStore
+	Scheduler       Scheduler.SchedulerInterface
+	CalendarService calendar.CalendarService
+	ConfigStore     config.ConfigStoreInterface
+	now             func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewAbsenceSuggestionHandler creates a new handler of the absence suggestions, saving the applied
+// ones to availability
+func NewAbsenceSuggestionHandler(baseHandler *BaseHandler, nights fairness.OverriddenNightsSource, availability AvailabilityStore, sched Scheduler.SchedulerInterface, calSvc calendar.CalendarService, configStore config.ConfigStoreInterface) *AbsenceSuggestionHandler {
+	return &AbsenceSuggestionHandler{
+		BaseHandler:     baseHandler,
+		nights:          nights,
+		availability:    availability,
+		Scheduler:       sched,
+		CalendarService: calSvc,
+		ConfigStore:     configStore,
+		now:             time.Now,
+	}
+}
+
+// RegisterRoutes registers the absence suggestion routes
+func (h *AbsenceSuggestionHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/absence-suggestions", h.handleListSuggestions, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/v1/absence-suggestions/apply", h.handleApplySuggestion, http.MethodPost)
+}
+
+// AbsenceSuggestionResponse is a weekday a parent was overridden on week after week
+type AbsenceSuggestionResponse struct {
+	Parent     string `json:"parent"`
+	Weekday    string `json:"weekday"`    // e.g. "Wednesday"
+	Overridden int    `json:"overridden"` // Occurrences of the weekday the parent was overridden on
+	Weeks      int    `json:"weeks"`      // Occurrences of the weekday looked at
+}
+
+// ApplyAbsenceSuggestionRequest is the JSON body applying a suggestion
+type ApplyAbsenceSuggestionRequest struct {
+	Parent  string `json:"parent"`
+	Weekday string `json:"weekday"`
+}
+
+// ApplyAbsenceSuggestionResponse is the JSON response of an applied suggestion
+type ApplyAbsenceSuggestionResponse struct {
+	Parent          string   `json:"parent"`
+	Weekday         string   `json:"weekday"`
+	UnavailableDays []string `json:"unavailable_days"` // Unavailable days of the parent after the change
+}
+
+// handleListSuggestions returns the current absence suggestions on GET
+func (h *AbsenceSuggestionHandler) handleListSuggestions(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleListSuggestions").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	patterns, err := fairness.DetectAbsencePatterns(r.Context(), h.nights, h.ConfigStore, h.now())
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to detect absence patterns")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve absence suggestions", handlerLogger)
+		return
+	}
+	response := make([]AbsenceSuggestionResponse, 0, len(patterns))
+	for _, pattern := range patterns {
+		response = append(response, AbsenceSuggestionResponse{
+			Parent:     pattern.Parent,
+			Weekday:    pattern.Weekday.String(),
+			Overridden: pattern.Overridden,
+			Weeks:      pattern.Weeks,
+		})
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// handleApplySuggestion marks the weekday of a current suggestion unavailable for its parent on POST,
+// then recalculates and syncs the schedule from today
+func (h *AbsenceSuggestionHandler) handleApplySuggestion(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleApplySuggestion").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var req ApplyAbsenceSuggestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Parent == "" || req.Weekday == "" {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, parent and weekday are required", handlerLogger)
+		return
+	}
+
+	response, err := h.applySuggestion(r.Context(), handlerLogger, req)
+	switch {
+	case errors.Is(err, errNotSuggested):
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "No absence suggestion for this parent and weekday", handlerLogger)
+		return
+	case err != nil:
+		handlerLogger.Error().Err(err).Str("parent", req.Parent).Str("weekday", req.Weekday).Msg("Failed to apply absence suggestion")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to apply absence suggestion", handlerLogger)
+		return
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// applySuggestion adds the weekday of the request to the unavailable days of its parent when it is
+// a current suggestion, returning errNotSuggested otherwise. A failing sync is logged, the next one
+// catches up.
+func (h *AbsenceSuggestionHandler) applySuggestion(ctx context.Context, logger zerolog.Logger, req ApplyAbsenceSuggestionRequest) (ApplyAbsenceSuggestionResponse, error) {
+	now := h.now()
+	patterns, err := fairness.DetectAbsencePatterns(ctx, h.nights, h.ConfigStore, now)
+	if err != nil {
+		return ApplyAbsenceSuggestionResponse{}, fmt.Errorf("failed to detect absence patterns: %w", err)
+	}
+	index := slices.IndexFunc(patterns, func(pattern fairness.AbsencePattern) bool {
+		return pattern.Parent == req.Parent && strings.EqualFold(pattern.Weekday.String(), req.Weekday)
+	})
+	if index < 0 {
+		return ApplyAbsenceSuggestionResponse{}, errNotSuggested
+	}
+	pattern := patterns[index]
+	logger = logger.With().Str("parent", pattern.Parent).Stringer("weekday", pattern.Weekday).Logger()
+
+	days, err := h.availability.GetAvailability(pattern.ParentKey)
+	if err != nil {
+		return ApplyAbsenceSuggestionResponse{}, fmt.Errorf("failed to get %s availability: %w", pattern.ParentKey, err)
+	}
+	days = append(days, pattern.Weekday.String())
+	if err := h.availability.SaveAvailability(ctx, pattern.ParentKey, days); err != nil {
+		return ApplyAbsenceSuggestionResponse{}, fmt.Errorf("failed to save %s availability: %w", pattern.ParentKey, err)
+	}
+	logger.Info().Strs("unavailable_days", days).Msg("Absence suggestion applied")
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if err := recalculateScheduleAndSync(ctx, h.logger, h.SyncRuns, constants.SyncTriggerSettings, h.Tracker, h.Scheduler, h.CalendarService, h.ConfigStore, today); err != nil {
+		logger.Error().Err(err).Msg("Failed to recalculate schedule after applying absence suggestion")
+	}
+
+	return ApplyAbsenceSuggestionResponse{
+		Parent:          pattern.Parent,
+		Weekday:         pattern.Weekday.String(),
+		UnavailableDays: days,
+	}, nil
+}
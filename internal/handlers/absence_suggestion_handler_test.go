@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// memoryAvailabilityStore keeps the unavailable days in memory
+type memoryAvailabilityStore map[string][]string
+
+func (s memoryAvailabilityStore) GetAvailability(parent string) ([]string, error) {
+	return s[parent], nil
+}
+
+func (s memoryAvailabilityStore) SaveAvailability(_ context.Context, parent string, unavailableDays []string) error {
+	s[parent] = unavailableDays
+	return nil
+}
+
+// Friday 16 October 2026, ParentB was overridden on the three last Wednesdays
+var absenceTestNow = time.Date(2026, 10, 16, 20, 0, 0, 0, time.Local)
+
+func setupTestAbsenceSuggestionHandler(t *testing.T, authenticated bool) (*AbsenceSuggestionHandler, memoryAvailabilityStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	for _, day := range []int{30, 37, 44} {
+		date := time.Date(2026, 9, day, 0, 0, 0, 0, time.Local)
+		assignment, err := tracker.RecordAssignment(t.Context(), "ParentB", date, false, fairness.DecisionReasonAlternating)
+		require.NoError(t, err)
+		require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), assignment.ID, "ParentA", true, assignment.Version))
+	}
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	noopCfgStore := &noopConfigStore{}
+	availability := memoryAvailabilityStore{}
+	handler := NewAbsenceSuggestionHandler(baseHandler, tracker, availability, Scheduler.New(noopCfgStore, tracker), &noopCalendarService{}, noopCfgStore)
+	handler.now = func() time.Time { return absenceTestNow }
+	return handler, availability
+}
+
+func TestAbsenceSuggestionHandler_List(t *testing.T) {
+	handler, _ := setupTestAbsenceSuggestionHandler(t, true)
+
+	w := httptest.NewRecorder()
+	handler.handleListSuggestions(w, httptest.NewRequest(http.MethodGet, "/api/v1/absence-suggestions", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response []AbsenceSuggestionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []AbsenceSuggestionResponse{{Parent: "ParentB", Weekday: "Wednesday", Overridden: 3, Weeks: 4}}, response)
+}
+
+func TestAbsenceSuggestionHandler_Apply(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticated bool
+		body          string
+		wantStatus    int
+		wantDays      []string
+	}{
+		{"Applies the suggestion", true, `{"parent":"ParentB","weekday":"wednesday"}`, http.StatusOK, []string{"Wednesday"}},
+		{"Weekday not suggested", true, `{"parent":"ParentB","weekday":"Thursday"}`, http.StatusNotFound, nil},
+		{"Parent not suggested", true, `{"parent":"ParentA","weekday":"Wednesday"}`, http.StatusNotFound, nil},
+		{"Missing weekday", true, `{"parent":"ParentB"}`, http.StatusBadRequest, nil},
+		{"Unauthenticated", false, `{"parent":"ParentB","weekday":"Wednesday"}`, http.StatusUnauthorized, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, availability := setupTestAbsenceSuggestionHandler(t, tt.authenticated)
+
+			w := httptest.NewRecorder()
+			handler.handleApplySuggestion(w, httptest.NewRequest(http.MethodPost, "/api/v1/absence-suggestions/apply", strings.NewReader(tt.body)))
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Equal(t, tt.wantDays, availability["parent_b"])
+
+			if tt.wantStatus == http.StatusOK {
+				var response ApplyAbsenceSuggestionResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, ApplyAbsenceSuggestionResponse{Parent: "ParentB", Weekday: "Wednesday", UnavailableDays: []string{"Wednesday"}}, response)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+// AcknowledgementHandler lets the parent on duty acknowledge their night
+type AcknowledgementHandler struct {
+	*BaseHandler
+	acknowledgements *database.AcknowledgementStore
+	links            ParentLinks      // Self-service links whose token authenticates the automations
+	now              func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewAcknowledgementHandler creates a new handler acknowledging the nights. Besides the web
+// interface, automations acknowledge with the token of a self-service link of links as bearer token.
+func NewAcknowledgementHandler(baseHandler *BaseHandler, acknowledgements *database.AcknowledgementStore, links ParentLinks) *AcknowledgementHandler {
+	return &AcknowledgementHandler{BaseHandler: baseHandler, acknowledgements: acknowledgements, links: links, now: time.Now}
+}
+
+// RegisterRoutes registers the acknowledgement routes
+func (h *AcknowledgementHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/assignments/{id}/ack", h.handleAcknowledge, http.MethodPost)
+}
+
+// AcknowledgementResponse is the JSON response of an acknowledgement
+type AcknowledgementResponse struct {
+	AssignmentID   int64     `json:"assignment_id"`
+	Date           string    `json:"date"`
+	Parent         string    `json:"parent"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"` // Time of the first acknowledgement of the parent
+}
+
+// handleAcknowledge records that the parent on duty handles the night of the assignment of the
+// path. The application has no user accounts, so the acknowledgement goes to the parent on duty;
+// acknowledging again answers the first acknowledgement. Past nights and babysitter nights cannot be
+// acknowledged.
+func (h *AcknowledgementHandler) handleAcknowledge(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleAcknowledge").Logger()
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !checkBearerLink(w, r, h.links, handlerLogger) {
+		return
+	}
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	assignmentID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || assignmentID <= 0 {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid assignment ID", handlerLogger)
+		return
+	}
+	handlerLogger = handlerLogger.With().Int64("assignment_id", assignmentID).Logger()
+
+	assignment, err := h.Tracker.GetAssignmentByID(r.Context(), assignmentID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get assignment")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment", handlerLogger)
+		return
+	}
+	if assignment == nil {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Assignment not found", handlerLogger)
+		return
+	}
+	if assignment.CaregiverType != fairness.CaregiverTypeParent {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Only the night of a parent can be acknowledged", handlerLogger)
+		return
+	}
+	now := h.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if assignment.Date.Format(time.DateOnly) < today.Format(time.DateOnly) {
+		writeError(w, http.StatusBadRequest, apierror.CodeAssignmentTooOld, "Past nights cannot be acknowledged", handlerLogger)
+		return
+	}
+
+	acknowledgement, err := h.acknowledgements.Acknowledge(assignment.ID, assignment.Parent, now)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to acknowledge assignment")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to acknowledge the night", handlerLogger)
+		return
+	}
+	handlerLogger.Info().Str("parent", assignment.Parent).Msg("Night acknowledged")
+
+	writeJSON(w, http.StatusOK, AcknowledgementResponse{
+		AssignmentID:   assignment.ID,
+		Date:           assignment.Date.Format(time.DateOnly),
+		Parent:         acknowledgement.Parent,
+		AcknowledgedAt: acknowledgement.AcknowledgedAt,
+	}, handlerLogger)
+}
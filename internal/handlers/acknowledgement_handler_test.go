@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestAcknowledgementHandler(t *testing.T, authenticated bool) (*AcknowledgementHandler, *fairness.Tracker, *database.AcknowledgementStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	acknowledgements, err := database.NewAcknowledgementStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+	return NewAcknowledgementHandler(baseHandler, acknowledgements, fakeParentLinks{"link-token": "parent_a"}), tracker, acknowledgements
+}
+
+// fakeParentLinks is a ParentLinks knowing the parent key of its tokens
+type fakeParentLinks map[string]string
+
+func (f fakeParentLinks) CreateLink(string, time.Time) (string, error) {
+	return "", errors.New("links cannot be created")
+}
+
+func (f fakeParentLinks) ParentForToken(token string) (string, bool, error) {
+	parent, ok := f[token]
+	return parent, ok, nil
+}
+
+func (f fakeParentLinks) ListLinks() (map[string]time.Time, error) {
+	return map[string]time.Time{}, nil
+}
+
+func (f fakeParentLinks) RevokeLink(string) error {
+	return nil
+}
+
+func acknowledge(handler *AcknowledgementHandler, id string) *httptest.ResponseRecorder {
+	return acknowledgeWithToken(handler, id, "")
+}
+
+// acknowledgeWithToken acknowledges as an automation, with bearer as bearer token when not empty
+func acknowledgeWithToken(handler *AcknowledgementHandler, id, bearer string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/assignments/"+id+"/ack", nil)
+	req.SetPathValue("id", id)
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	w := httptest.NewRecorder()
+	handler.handleAcknowledge(w, req)
+	return w
+}
+
+func TestAcknowledgementHandler_AcknowledgeTonight(t *testing.T) {
+	handler, tracker, acknowledgements := setupTestAcknowledgementHandler(t, true)
+	now := time.Date(2026, 10, 16, 19, 30, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+	tonight, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	w := acknowledge(handler, fmt.Sprint(tonight.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response AcknowledgementResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, AcknowledgementResponse{AssignmentID: tonight.ID, Date: "2026-10-16", Parent: "ParentA", AcknowledgedAt: now}, response)
+
+	// Acknowledging again answers the first acknowledgement
+	handler.now = func() time.Time { return now.Add(time.Hour) }
+	w = acknowledge(handler, fmt.Sprint(tonight.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, now, response.AcknowledgedAt)
+
+	acknowledgement, err := acknowledgements.GetAcknowledgement(tonight.ID)
+	require.NoError(t, err)
+	require.NotNil(t, acknowledgement)
+	assert.Equal(t, "ParentA", acknowledgement.Parent)
+}
+
+func TestAcknowledgementHandler_LinkToken(t *testing.T) {
+	handler, tracker, _ := setupTestAcknowledgementHandler(t, true)
+	handler.now = func() time.Time { return time.Date(2026, 10, 16, 19, 30, 0, 0, time.UTC) }
+	tonight, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	w := acknowledgeWithToken(handler, fmt.Sprint(tonight.ID), "unknown")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), string(apierror.CodeAuthenticationRequired))
+
+	w = acknowledgeWithToken(handler, fmt.Sprint(tonight.ID), "link-token")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestAcknowledgementHandler_Errors(t *testing.T) {
+	now := time.Date(2026, 10, 16, 19, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		authenticated bool
+		id            string // Night of the assignment to acknowledge, past, babysitter or tonight, or a raw ID
+		wantStatus    int
+		wantCode      apierror.Code
+	}{
+		{name: "unauthenticated", id: "tonight", wantStatus: http.StatusUnauthorized, wantCode: apierror.CodeAuthenticationRequired},
+		{name: "invalid id", authenticated: true, id: "abc", wantStatus: http.StatusBadRequest, wantCode: apierror.CodeInvalidRequest},
+		{name: "unknown assignment", authenticated: true, id: "42", wantStatus: http.StatusNotFound, wantCode: apierror.CodeNotFound},
+		{name: "past night", authenticated: true, id: "past", wantStatus: http.StatusBadRequest, wantCode: apierror.CodeAssignmentTooOld},
+		{name: "babysitter night", authenticated: true, id: "babysitter", wantStatus: http.StatusBadRequest, wantCode: apierror.CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, tracker, acknowledgements := setupTestAcknowledgementHandler(t, tt.authenticated)
+			handler.now = func() time.Time { return now }
+			ids := make(map[string]int64)
+			for name, offset := range map[string]int{"past": -1, "tonight": 0, "babysitter": 1} {
+				assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Date(2026, 10, 16+offset, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonAlternating)
+				require.NoError(t, err)
+				ids[name] = assignment.ID
+				if name == "babysitter" {
+					require.NoError(t, tracker.UpdateAssignmentToBabysitter(t.Context(), assignment.ID, "Dawn", true, assignment.Version))
+				}
+			}
+
+			id := tt.id
+			if assignmentID, ok := ids[id]; ok {
+				id = fmt.Sprint(assignmentID)
+			}
+			w := acknowledge(handler, id)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), string(tt.wantCode))
+
+			for _, assignmentID := range ids {
+				acknowledgement, err := acknowledgements.GetAcknowledgement(assignmentID)
+				require.NoError(t, err)
+				assert.Nil(t, acknowledgement)
+			}
+		})
+	}
+}
@@ -3,13 +3,18 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/apierror"
 	"github.com/belphemur/night-routine/internal/calendar"
 	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/dates"
 	"github.com/belphemur/night-routine/internal/fairness"
 	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
 )
@@ -36,8 +41,9 @@ func NewAssignmentDetailsHandler(baseHandler *BaseHandler, tracker fairness.Trac
 
 // RegisterRoutes registers assignment details related routes
 func (h *AssignmentDetailsHandler) RegisterRoutes() {
-	http.HandleFunc("/api/assignment-details", h.handleGetAssignmentDetails)
-	http.HandleFunc("/api/assignment-babysitter", h.handleSetAssignmentBabysitter)
+	handleMethods(http.DefaultServeMux, "/api/assignment-details", h.handleGetAssignmentDetails, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/assignment-babysitter", h.handleSetAssignmentBabysitter, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/assignment-tag", h.handleSetAssignmentTag, http.MethodPost)
 }
 
 // AssignmentDetailsResponse represents the JSON response for assignment details
@@ -53,6 +59,10 @@ type AssignmentDetailsResponse struct {
 	ParentBName       string `json:"parent_b_name"`
 	ParentBTotalCount int    `json:"parent_b_total_count"`
 	ParentBLast30Days int    `json:"parent_b_last_30_days"`
+	// Counts of the parents of the roster after parent A and parent B, omitted without any
+	ExtraParents []ParentCountsResponse `json:"extra_parents,omitempty"`
+	Version      int64                  `json:"version"` // Precondition to send back with a change of the assignment
+	Tag          string                 `json:"tag,omitempty"`
 }
 
 // handleGetAssignmentDetails handles GET requests for assignment details
@@ -62,17 +72,14 @@ func (h *AssignmentDetailsHandler) handleGetAssignmentDetails(w http.ResponseWri
 
 	if r.Method != http.MethodGet {
 		handlerLogger.Warn().Msg("Invalid method for get assignment details request")
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
 		return
 	}
 
 	if !h.CheckAuthentication(r.Context(), handlerLogger) {
 		handlerLogger.Warn().Msg("Unauthenticated access attempt to assignment details")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode unauthorized response")
-		}
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
 		return
 	}
 
@@ -80,10 +87,7 @@ func (h *AssignmentDetailsHandler) handleGetAssignmentDetails(w http.ResponseWri
 	if assignmentIDStr == "" {
 		handlerLogger.Warn().Msg("No assignment_id provided")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Missing assignment_id parameter"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode bad request response")
-		}
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Missing assignment_id parameter", handlerLogger)
 		return
 	}
 
@@ -91,10 +95,7 @@ func (h *AssignmentDetailsHandler) handleGetAssignmentDetails(w http.ResponseWri
 	if err != nil {
 		handlerLogger.Error().Err(err).Str("assignment_id_str", assignmentIDStr).Msg("Invalid assignment ID format")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Invalid assignment_id format"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode bad request response")
-		}
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid assignment_id format", handlerLogger)
 		return
 	}
 
@@ -102,36 +103,27 @@ func (h *AssignmentDetailsHandler) handleGetAssignmentDetails(w http.ResponseWri
 	handlerLogger.Debug().Msg("Fetching assignment and details")
 
 	// First get the assignment to retrieve the decision reason
-	assignment, err := h.Tracker.GetAssignmentByID(assignmentID)
+	assignment, err := h.Tracker.GetAssignmentByID(r.Context(), assignmentID)
 	if err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to get assignment")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Failed to retrieve assignment"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode error response")
-		}
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment", handlerLogger)
 		return
 	}
 
 	if assignment == nil {
 		handlerLogger.Debug().Msg("Assignment not found")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Assignment details not found"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode not found response")
-		}
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Assignment details not found", handlerLogger)
 		return
 	}
 
 	// Then get the assignment details
-	details, err := h.Tracker.GetAssignmentDetails(assignmentID)
+	details, err := h.Tracker.GetAssignmentDetails(r.Context(), assignmentID)
 	if err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to get assignment details")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Failed to retrieve assignment details"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode error response")
-		}
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment details", handlerLogger)
 		return
 	}
 
@@ -142,6 +134,8 @@ func (h *AssignmentDetailsHandler) handleGetAssignmentDetails(w http.ResponseWri
 				DecisionReason: assignment.DecisionReason.String(),
 				CaregiverType:  assignment.CaregiverType.String(),
 				ParentName:     assignment.Parent,
+				Version:        assignment.Version,
+				Tag:            assignment.Tag.String(),
 			}
 
 			w.Header().Set("Content-Type", "application/json")
@@ -156,10 +150,7 @@ func (h *AssignmentDetailsHandler) handleGetAssignmentDetails(w http.ResponseWri
 
 		handlerLogger.Debug().Msg("No details found for assignment")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Assignment details not found"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode not found response")
-		}
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Assignment details not found", handlerLogger)
 		return
 	}
 
@@ -174,6 +165,9 @@ func (h *AssignmentDetailsHandler) handleGetAssignmentDetails(w http.ResponseWri
 		ParentBName:       details.ParentBName,
 		ParentBTotalCount: details.ParentBTotalCount,
 		ParentBLast30Days: details.ParentBLast30Days,
+		ExtraParents:      newParentCounts(details),
+		Version:           assignment.Version,
+		Tag:               assignment.Tag.String(),
 	}
 	if assignment.CaregiverType == fairness.CaregiverTypeBabysitter {
 		response.ParentName = assignment.Parent
@@ -191,6 +185,11 @@ func (h *AssignmentDetailsHandler) handleGetAssignmentDetails(w http.ResponseWri
 type setBabysitterRequest struct {
 	AssignmentID   int64  `json:"assignment_id"`
 	BabysitterName string `json:"babysitter_name"`
+	// Version of the assignment the change is based on, the one returned with its details. When
+	// omitted, the change is based on the assignment as read by the handler.
+	Version int64 `json:"version,omitempty"`
+	// Tag of the night, one of fairness.AssignmentTags; the night is not tagged when omitted
+	Tag string `json:"tag,omitempty"`
 }
 
 func (h *AssignmentDetailsHandler) handleSetAssignmentBabysitter(w http.ResponseWriter, r *http.Request) {
@@ -199,17 +198,14 @@ func (h *AssignmentDetailsHandler) handleSetAssignmentBabysitter(w http.Response
 
 	if r.Method != http.MethodPost {
 		handlerLogger.Warn().Msg("Invalid method for set assignment babysitter request")
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
 		return
 	}
 
 	if !h.CheckAuthentication(r.Context(), handlerLogger) {
 		handlerLogger.Warn().Msg("Unauthenticated access attempt to set babysitter")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode unauthorized response")
-		}
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
 		return
 	}
 
@@ -217,10 +213,7 @@ func (h *AssignmentDetailsHandler) handleSetAssignmentBabysitter(w http.Response
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to decode set babysitter payload")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		if encErr := json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"}); encErr != nil {
-			handlerLogger.Error().Err(encErr).Msg("Failed to encode bad request response")
-		}
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body", handlerLogger)
 		return
 	}
 
@@ -228,10 +221,14 @@ func (h *AssignmentDetailsHandler) handleSetAssignmentBabysitter(w http.Response
 	if req.AssignmentID <= 0 || req.BabysitterName == "" {
 		handlerLogger.Warn().Int64("assignment_id", req.AssignmentID).Msg("Invalid assignment id or babysitter name")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "assignment_id and babysitter_name are required"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode validation error response")
-		}
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "assignment_id and babysitter_name are required", handlerLogger)
+		return
+	}
+
+	tag, err := fairness.ParseAssignmentTag(req.Tag)
+	if err != nil {
+		handlerLogger.Warn().Err(err).Msg("Invalid assignment tag")
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "tag must be sick_kid or parent_away", handlerLogger)
 		return
 	}
 
@@ -239,31 +236,22 @@ func (h *AssignmentDetailsHandler) handleSetAssignmentBabysitter(w http.Response
 	if len(req.BabysitterName) > maxBabysitterNameLen {
 		handlerLogger.Warn().Int("name_len", len(req.BabysitterName)).Msg("Babysitter name exceeds maximum length")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "babysitter_name exceeds maximum length"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode validation error response")
-		}
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "babysitter_name exceeds maximum length", handlerLogger)
 		return
 	}
 
-	assignment, err := h.Tracker.GetAssignmentByID(req.AssignmentID)
+	assignment, err := h.Tracker.GetAssignmentByID(r.Context(), req.AssignmentID)
 	if err != nil {
 		handlerLogger.Error().Err(err).Int64("assignment_id", req.AssignmentID).Msg("Failed to get assignment")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		if encErr := json.NewEncoder(w).Encode(map[string]string{"error": "Failed to retrieve assignment"}); encErr != nil {
-			handlerLogger.Error().Err(encErr).Msg("Failed to encode server error response")
-		}
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment", handlerLogger)
 		return
 	}
 
 	if assignment == nil {
 		handlerLogger.Warn().Int64("assignment_id", req.AssignmentID).Msg("Assignment not found")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Assignment not found"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode not found response")
-		}
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Assignment not found", handlerLogger)
 		return
 	}
 
@@ -273,43 +261,50 @@ func (h *AssignmentDetailsHandler) handleSetAssignmentBabysitter(w http.Response
 	if schedErr != nil {
 		handlerLogger.Error().Err(schedErr).Msg("Failed to get schedule configuration for threshold check")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		if encErr := json.NewEncoder(w).Encode(map[string]string{"error": "Failed to validate assignment date"}); encErr != nil {
-			handlerLogger.Error().Err(encErr).Msg("Failed to encode server error response")
-		}
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to validate assignment date", handlerLogger)
 		return
 	}
 
-	now := time.Now()
-	thresholdDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -thresholdDays)
-	y, m, d := assignment.Date.Date()
-	assignmentDate := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
-
-	if assignmentDate.Before(thresholdDate) {
+	thresholdDate := dates.AddDays(time.Now(), -thresholdDays)
+	if dates.DaysBetween(assignment.Date, thresholdDate) > 0 {
 		handlerLogger.Warn().
 			Int("threshold_days", thresholdDays).
-			Str("assignment_date", assignmentDate.Format("2006-01-02")).
+			Str("assignment_date", assignment.Date.Format("2006-01-02")).
 			Msg("Rejecting babysitter assignment for past assignment outside threshold")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "Assignment is too far in the past to modify"}); err != nil {
-			handlerLogger.Error().Err(err).Msg("Failed to encode threshold error response")
-		}
+		writeError(w, http.StatusBadRequest, apierror.CodeAssignmentTooOld, "Assignment is too far in the past to modify", handlerLogger)
 		return
 	}
 
-	if err := h.Tracker.UpdateAssignmentToBabysitter(req.AssignmentID, req.BabysitterName, true); err != nil {
+	version := assignment.Version
+	if req.Version != 0 {
+		version = req.Version
+	}
+	// The override and the recalculation following it are undone together
+	ctx := h.Tracker.BeginBatch(r.Context(), fairness.ChangeKindOverride)
+	err = h.Tracker.UpdateAssignmentToBabysitter(ctx, req.AssignmentID, req.BabysitterName, true, version)
+	if errors.Is(err, fairness.ErrAssignmentConflict) {
+		handlerLogger.Warn().Err(err).Int64("assignment_id", req.AssignmentID).Msg("Assignment changed before setting babysitter")
+		w.Header().Set("Content-Type", "application/json")
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "Assignment was changed in the meantime, reload it and try again", handlerLogger)
+		return
+	}
+	if err != nil {
 		handlerLogger.Error().Err(err).Int64("assignment_id", req.AssignmentID).Msg("Failed to update assignment to babysitter")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		if encErr := json.NewEncoder(w).Encode(map[string]string{"error": "Failed to set babysitter"}); encErr != nil {
-			handlerLogger.Error().Err(encErr).Msg("Failed to encode server error response")
-		}
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to set babysitter", handlerLogger)
 		return
 	}
 
+	// The tag only describes the night, the babysitter is set without it when it fails
+	if tag != fairness.AssignmentTagNone {
+		if err := h.Tracker.SetAssignmentTag(ctx, req.AssignmentID, tag); err != nil {
+			handlerLogger.Error().Err(err).Int64("assignment_id", req.AssignmentID).Msg("Failed to tag assignment")
+		}
+	}
+
 	// Keep calendar and future assignments coherent after introducing a babysitter override.
-	if err := h.recalculateSchedule(r.Context(), assignment.Date); err != nil {
+	if err := h.recalculateSchedule(ctx, assignment.Date); err != nil {
 		handlerLogger.Error().Err(err).Int64("assignment_id", req.AssignmentID).Msg("Failed to recalculate schedule after setting babysitter")
 	}
 
@@ -324,6 +319,8 @@ func (h *AssignmentDetailsHandler) recalculateSchedule(ctx context.Context, from
 	return recalculateScheduleAndSync(
 		ctx,
 		h.logger,
+		h.SyncRuns,
+		constants.SyncTriggerAssignment,
 		h.Tracker,
 		h.Scheduler,
 		h.CalendarService,
@@ -331,3 +328,89 @@ func (h *AssignmentDetailsHandler) recalculateSchedule(ctx context.Context, from
 		fromDate,
 	)
 }
+
+type setTagRequest struct {
+	AssignmentID int64 `json:"assignment_id"`
+	// Tag of the night, one of fairness.AssignmentTags; empty to remove it
+	Tag string `json:"tag"`
+}
+
+// handleSetAssignmentTag tags an overridden night as sick kid or parent away, or removes its tag, and
+// syncs its calendar event
+func (h *AssignmentDetailsHandler) handleSetAssignmentTag(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleSetAssignmentTag").Logger()
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var req setTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AssignmentID <= 0 {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, assignment_id is required", handlerLogger)
+		return
+	}
+	tag, err := fairness.ParseAssignmentTag(req.Tag)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "tag must be sick_kid, parent_away or empty", handlerLogger)
+		return
+	}
+	handlerLogger = handlerLogger.With().Int64("assignment_id", req.AssignmentID).Str("tag", tag.String()).Logger()
+
+	assignment, err := h.Tracker.GetAssignmentByID(r.Context(), req.AssignmentID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get assignment")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment", handlerLogger)
+		return
+	}
+	if assignment == nil {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Assignment not found", handlerLogger)
+		return
+	}
+
+	err = h.Tracker.SetAssignmentTag(r.Context(), assignment.ID, tag)
+	if errors.Is(err, fairness.ErrAssignmentNotOverridden) {
+		writeError(w, http.StatusBadRequest, apierror.CodeNotOverridden, "Only overridden nights can be tagged", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to tag assignment")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to tag assignment", handlerLogger)
+		return
+	}
+	handlerLogger.Info().Msg("Assignment tagged")
+
+	// The tag is recorded, a failing sync is retried by the next one
+	if err := h.SyncRuns.RecordRun(r.Context(), constants.SyncTriggerAssignment, func(ctx context.Context) (int, error) {
+		return h.syncAssignmentEvent(ctx, assignment)
+	}); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to sync the tagged assignment")
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "tag": tag.String()}, handlerLogger)
+}
+
+// syncAssignmentEvent syncs the calendar event of assignment when it has one, and returns the number
+// of synced events
+func (h *AssignmentDetailsHandler) syncAssignmentEvent(ctx context.Context, assignment *fairness.Assignment) (int, error) {
+	assignments, err := h.Scheduler.GetAssignmentsInRange(ctx, assignment.Date, assignment.Date)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get assignment %d: %w", assignment.ID, err)
+	}
+	for _, a := range assignments {
+		if a.ID != assignment.ID || a.GoogleCalendarEventID == "" {
+			continue
+		}
+		if err := h.CalendarService.SyncSchedule(ctx, []*Scheduler.Assignment{a}); err != nil {
+			return 1, fmt.Errorf("failed to sync schedule: %w", err)
+		}
+		return 1, nil
+	}
+	return 0, nil
+}
@@ -81,18 +81,18 @@ func setupTestAssignmentDetailsHandler(t *testing.T, authenticated bool) (*Assig
 	// Create config adapter — single source of truth for all config reads
 	cfgStore, err := database.NewConfigStore(db)
 	require.NoError(t, err)
-	err = cfgStore.SaveParents("Alice", "Bob")
+	err = cfgStore.SaveParents(t.Context(), "Alice", "Bob")
 	require.NoError(t, err)
-	err = cfgStore.SaveAvailability("parent_a", []string{})
+	err = cfgStore.SaveAvailability(t.Context(), "parent_a", []string{})
 	require.NoError(t, err)
-	err = cfgStore.SaveAvailability("parent_b", []string{})
+	err = cfgStore.SaveAvailability(t.Context(), "parent_b", []string{})
 	require.NoError(t, err)
-	err = cfgStore.SaveSchedule("daily", 7, 5, constants.StatsOrderDesc)
+	err = cfgStore.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc)
 	require.NoError(t, err)
 	configAdapter := database.NewConfigAdapter(cfgStore, oauthCfg)
 
 	// Create base handler
-	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, "test-version", "test-logo-version")
+	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, nil)
 	require.NoError(t, err)
 
 	// Create assignment details handler with scheduler and no-op external integrations.
@@ -113,12 +113,12 @@ func TestHandleGetAssignmentDetails_Success(t *testing.T) {
 
 	// Create an assignment and save details
 	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
-	assignment, err := tracker.RecordAssignment("Alice", date, false, fairness.DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
 
 	statsA := fairness.Stats{TotalAssignments: 5, Last30Days: 3}
 	statsB := fairness.Stats{TotalAssignments: 7, Last30Days: 4}
-	err = tracker.SaveAssignmentDetails(assignment.ID, date, "Alice", statsA, "Bob", statsB)
+	err = tracker.SaveAssignmentDetails(t.Context(), assignment.ID, date, "Alice", statsA, "Bob", statsB)
 	require.NoError(t, err)
 
 	// Create request
@@ -152,9 +152,9 @@ func TestHandleGetAssignmentDetails_BabysitterAssignment(t *testing.T) {
 	defer cleanup()
 
 	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
-	assignment, err := tracker.RecordAssignment("Alice", date, false, fairness.DecisionReasonAlternating)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
-	require.NoError(t, tracker.UpdateAssignmentToBabysitter(assignment.ID, "Dawn", true))
+	require.NoError(t, tracker.UpdateAssignmentToBabysitter(t.Context(), assignment.ID, "Dawn", true, assignment.Version))
 
 	req := httptest.NewRequest(http.MethodGet, "/api/assignment-details?assignment_id="+strconv.FormatInt(assignment.ID, 10), nil)
 	w := httptest.NewRecorder()
@@ -274,7 +274,7 @@ func TestHandleSetAssignmentBabysitter_Success(t *testing.T) {
 	defer cleanup()
 
 	date := testCurrentDate()
-	assignment, err := tracker.RecordAssignment("Alice", date, false, fairness.DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
 
 	payload := []byte(`{"assignment_id":` + strconv.FormatInt(assignment.ID, 10) + `,"babysitter_name":"Dawn"}`)
@@ -284,12 +284,35 @@ func TestHandleSetAssignmentBabysitter_Success(t *testing.T) {
 	handler.handleSetAssignmentBabysitter(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	updated, err := tracker.GetAssignmentByID(assignment.ID)
+	updated, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	require.NoError(t, err)
 	assert.Equal(t, fairness.CaregiverTypeBabysitter, updated.CaregiverType)
 	assert.Equal(t, "Dawn", updated.Parent)
 }
 
+func TestHandleSetAssignmentBabysitter_StaleVersionConflicts(t *testing.T) {
+	handler, tracker, _, cleanup := setupTestAssignmentDetailsHandler(t, true)
+	defer cleanup()
+
+	date := testCurrentDate()
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	// A webhook override lands between the details read and the babysitter change
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), assignment.ID, "Bob", true, assignment.Version))
+
+	payload := []byte(`{"assignment_id":` + strconv.FormatInt(assignment.ID, 10) + `,"babysitter_name":"Dawn","version":` + strconv.FormatInt(assignment.Version, 10) + `}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/assignment-babysitter", bytes.NewReader(payload))
+	w := httptest.NewRecorder()
+
+	handler.handleSetAssignmentBabysitter(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	current, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", current.Parent)
+	assert.Equal(t, fairness.CaregiverTypeParent, current.CaregiverType)
+}
+
 func TestHandleSetAssignmentBabysitter_InvalidPayload(t *testing.T) {
 	handler, _, _, cleanup := setupTestAssignmentDetailsHandler(t, true)
 	defer cleanup()
@@ -309,7 +332,7 @@ func TestHandleSetAssignmentBabysitter_TriggersScheduleRecalculation(t *testing.
 	handler.CalendarService = recordingSvc
 
 	date := testCurrentDate()
-	assignment, err := tracker.RecordAssignment("Alice", date, false, fairness.DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
 
 	payload := []byte(`{"assignment_id":` + strconv.FormatInt(assignment.ID, 10) + `,"babysitter_name":"Dawn"}`)
@@ -404,7 +427,7 @@ func TestHandleSetAssignmentBabysitter_PastThreshold(t *testing.T) {
 
 	// Create an assignment far in the past (beyond the configured 5-day threshold)
 	oldDate := testCurrentDate().AddDate(0, 0, -30)
-	assignment, err := tracker.RecordAssignment("Alice", oldDate, false, fairness.DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", oldDate, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
 
 	payload := []byte(`{"assignment_id":` + strconv.FormatInt(assignment.ID, 10) + `,"babysitter_name":"Dawn"}`)
@@ -419,3 +442,95 @@ func TestHandleSetAssignmentBabysitter_PastThreshold(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Contains(t, resp["error"], "too far in the past")
 }
+
+func TestHandleSetAssignmentBabysitter_Tag(t *testing.T) {
+	handler, tracker, _, cleanup := setupTestAssignmentDetailsHandler(t, true)
+	defer cleanup()
+
+	date := testCurrentDate()
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	payload := []byte(`{"assignment_id":` + strconv.FormatInt(assignment.ID, 10) + `,"babysitter_name":"Dawn","tag":"parent_away"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/assignment-babysitter", bytes.NewReader(payload))
+	w := httptest.NewRecorder()
+
+	handler.handleSetAssignmentBabysitter(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	updated, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fairness.AssignmentTagParentAway, updated.Tag)
+
+	payload = []byte(`{"assignment_id":` + strconv.FormatInt(assignment.ID, 10) + `,"babysitter_name":"Dawn","tag":"holiday"}`)
+	req = httptest.NewRequest(http.MethodPost, "/api/assignment-babysitter", bytes.NewReader(payload))
+	w = httptest.NewRecorder()
+
+	handler.handleSetAssignmentBabysitter(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func setAssignmentTag(handler *AssignmentDetailsHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/assignment-tag", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	handler.handleSetAssignmentTag(w, req)
+	return w
+}
+
+func TestHandleSetAssignmentTag_Success(t *testing.T) {
+	handler, tracker, _, cleanup := setupTestAssignmentDetailsHandler(t, true)
+	defer cleanup()
+
+	recordingSvc := &recordingCalendarService{}
+	handler.CalendarService = recordingSvc
+
+	date := testCurrentDate()
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", date, true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "event-1"))
+	id := strconv.FormatInt(assignment.ID, 10)
+
+	w := setAssignmentTag(handler, `{"assignment_id":`+id+`,"tag":"sick_kid"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	tagged, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fairness.AssignmentTagSickKid, tagged.Tag)
+	assert.Equal(t, assignment.Version, tagged.Version, "tagging does not change the caregiver")
+	assert.Equal(t, 1, recordingSvc.syncCalls, "the event shows the tag")
+
+	w = setAssignmentTag(handler, `{"assignment_id":`+id+`,"tag":""}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	untagged, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fairness.AssignmentTagNone, untagged.Tag)
+}
+
+func TestHandleSetAssignmentTag_Errors(t *testing.T) {
+	handler, tracker, _, cleanup := setupTestAssignmentDetailsHandler(t, true)
+	defer cleanup()
+
+	scheduled, err := tracker.RecordAssignment(t.Context(), "Alice", testCurrentDate(), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	id := strconv.FormatInt(scheduled.ID, 10)
+
+	assert.Equal(t, http.StatusBadRequest, setAssignmentTag(handler, `bad json`).Code)
+	assert.Equal(t, http.StatusBadRequest, setAssignmentTag(handler, `{"assignment_id":`+id+`,"tag":"holiday"}`).Code)
+	assert.Equal(t, http.StatusNotFound, setAssignmentTag(handler, `{"assignment_id":99999,"tag":"sick_kid"}`).Code)
+
+	w := setAssignmentTag(handler, `{"assignment_id":`+id+`,"tag":"sick_kid"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Only overridden nights can be tagged")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/assignment-tag", nil)
+	w = httptest.NewRecorder()
+	handler.handleSetAssignmentTag(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestHandleSetAssignmentTag_Unauthenticated(t *testing.T) {
+	handler, _, _, cleanup := setupTestAssignmentDetailsHandler(t, false)
+	defer cleanup()
+
+	w := setAssignmentTag(handler, `{"assignment_id":1,"tag":"sick_kid"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+)
+
+// maxAssignmentEdits is the number of nights a request of AssignmentEditHandler changes at most,
+// two months of the calendar
+const maxAssignmentEdits = 62
+
+// AssignmentEditHandler sets or unlocks several nights at once, e.g. the nights selected on the calendar
+type AssignmentEditHandler struct {
+	*BaseHandler
+	Tracker         fairness.TrackerInterface
+	Scheduler       Scheduler.SchedulerInterface
+	CalendarService calendar.CalendarService
+	ConfigStore     config.ConfigStoreInterface
+}
+
+// NewAssignmentEditHandler creates a new handler editing several nights at once
+func NewAssignmentEditHandler(baseHandler *BaseHandler, tracker fairness.TrackerInterface, sched Scheduler.SchedulerInterface, calSvc calendar.CalendarService, configStore config.ConfigStoreInterface) *AssignmentEditHandler {
+	return &AssignmentEditHandler{
+		BaseHandler:     baseHandler,
+		Tracker:         tracker,
+		Scheduler:       sched,
+		CalendarService: calSvc,
+		ConfigStore:     configStore,
+	}
+}
+
+// RegisterRoutes registers the assignment edit routes
+func (h *AssignmentEditHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/assignments/batch", h.handleEditAssignments, http.MethodPost)
+}
+
+// AssignmentEditRequest changes the night of a date: to a parent as an override, or unlocked
+type AssignmentEditRequest struct {
+	Date    string `json:"date"`              // YYYY-MM-DD
+	Parent  string `json:"parent,omitempty"`  // Parent taking the night, one of the configured parents
+	Unlock  bool   `json:"unlock,omitempty"`  // Hands the night back to the scheduler instead
+	Version int64  `json:"version,omitempty"` // Version of the assignment the edit is based on; the current one when omitted
+}
+
+// AssignmentEditsRequest is the JSON body of an edit of several nights
+type AssignmentEditsRequest struct {
+	Edits []AssignmentEditRequest `json:"edits"`
+}
+
+// EditedAssignmentResponse is a night of an edit once the schedule is recalculated
+type EditedAssignmentResponse struct {
+	AssignmentID  int64  `json:"assignment_id"`
+	Date          string `json:"date"`
+	Caregiver     string `json:"caregiver"`
+	CaregiverType string `json:"caregiver_type"`
+	Override      bool   `json:"override"`
+	Version       int64  `json:"version"`
+	Changed       bool   `json:"changed"` // False when the night already was as asked
+}
+
+// AssignmentEditsResponse is the JSON response of an edit of several nights
+type AssignmentEditsResponse struct {
+	Assignments []EditedAssignmentResponse `json:"assignments"` // In the order of the edits
+	Synced      bool                       `json:"synced"`      // False when the calendar could not be synced, the next sync catches up
+}
+
+// handleEditAssignments applies the edits of the request body on POST, all of them or none, then
+// recalculates the schedule from the first changed night and syncs it once
+func (h *AssignmentEditHandler) handleEditAssignments(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleEditAssignments").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var req AssignmentEditsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, edits are required", handlerLogger)
+		return
+	}
+	roster, err := config.Roster(h.ConfigStore)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parents")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get parents", handlerLogger)
+		return
+	}
+	edits, err := parseAssignmentEdits(req.Edits, roster)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+		return
+	}
+
+	// The edits and the recalculation following them are undone together
+	ctx := h.Tracker.BeginBatch(r.Context(), fairness.ChangeKindEdit)
+	edited, err := h.Tracker.EditAssignments(ctx, edits)
+	switch {
+	case errors.Is(err, fairness.ErrAssignmentNotScheduled):
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "A night of the edits is not scheduled, none was changed", handlerLogger)
+		return
+	case errors.Is(err, fairness.ErrAssignmentConflict):
+		handlerLogger.Warn().Err(err).Msg("Nights changed before the edit")
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "A night was changed in the meantime, none was changed; reload them and try again", handlerLogger)
+		return
+	case err != nil:
+		handlerLogger.Error().Err(err).Msg("Failed to edit assignments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to edit the nights", handlerLogger)
+		return
+	}
+
+	response := AssignmentEditsResponse{Assignments: make([]EditedAssignmentResponse, 0, len(edited)), Synced: true}
+	var from time.Time
+	for _, e := range edited {
+		if e.Changed && (from.IsZero() || e.Assignment.Date.Before(from)) {
+			from = e.Assignment.Date
+		}
+	}
+	if !from.IsZero() {
+		// The nights are edited, a failing sync is retried by the next one
+		if err := recalculateScheduleAndSync(ctx, h.logger, h.SyncRuns, constants.SyncTriggerAssignment, h.Tracker, h.Scheduler, h.CalendarService, h.ConfigStore, from); err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to recalculate schedule after editing assignments")
+			response.Synced = false
+		}
+	}
+
+	for _, e := range edited {
+		// The unlocked nights are given a caregiver by the recalculation
+		a, err := h.Tracker.GetAssignmentByID(ctx, e.Assignment.ID)
+		if err != nil || a == nil {
+			handlerLogger.Warn().Err(err).Int64("assignment_id", e.Assignment.ID).Msg("Failed to read back edited assignment")
+			a = e.Assignment
+		}
+		response.Assignments = append(response.Assignments, EditedAssignmentResponse{
+			AssignmentID:  a.ID,
+			Date:          a.Date.Format(time.DateOnly),
+			Caregiver:     a.Parent,
+			CaregiverType: a.CaregiverType.String(),
+			Override:      a.Override,
+			Version:       a.Version,
+			Changed:       e.Changed,
+		})
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// parseAssignmentEdits checks the edits of a request and converts them: between 1 and
+// maxAssignmentEdits nights, each once, either set to one of the parents of roster or unlocked
+func parseAssignmentEdits(requests []AssignmentEditRequest, roster []string) ([]fairness.AssignmentEdit, error) {
+	if len(requests) == 0 || len(requests) > maxAssignmentEdits {
+		return nil, fmt.Errorf("between 1 and %d edits are required, got %d", maxAssignmentEdits, len(requests))
+	}
+
+	edits := make([]fairness.AssignmentEdit, 0, len(requests))
+	seen := make(map[string]bool, len(requests))
+	for _, req := range requests {
+		date, err := time.ParseInLocation(time.DateOnly, req.Date, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", req.Date)
+		}
+		if seen[req.Date] {
+			return nil, fmt.Errorf("%s is edited more than once", req.Date)
+		}
+		seen[req.Date] = true
+		if req.Unlock == (req.Parent != "") {
+			return nil, fmt.Errorf("%s: either parent or unlock is required", req.Date)
+		}
+		if req.Parent != "" && !slices.Contains(roster, req.Parent) {
+			return nil, fmt.Errorf("%s: parent must be one of the configured parents", req.Date)
+		}
+		edits = append(edits, fairness.AssignmentEdit{Date: date, Parent: req.Parent, Version: req.Version})
+	}
+	return edits, nil
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestAssignmentEditHandler(t *testing.T, authenticated bool) (*AssignmentEditHandler, *fairness.Tracker) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	noopCfgStore := &noopConfigStore{}
+	handler := NewAssignmentEditHandler(baseHandler, tracker, Scheduler.New(noopCfgStore, tracker), &noopCalendarService{}, noopCfgStore)
+	return handler, tracker
+}
+
+func editAssignments(handler *AssignmentEditHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/assignments/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.handleEditAssignments(w, req)
+	return w
+}
+
+func TestAssignmentEditHandler_Success(t *testing.T) {
+	handler, tracker := setupTestAssignmentEditHandler(t, true)
+	today := time.Now()
+	day1 := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	day2 := day1.AddDate(0, 0, 1)
+	first, err := tracker.RecordAssignment(t.Context(), "ParentA", day1, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	second, err := tracker.RecordAssignment(t.Context(), "ParentA", day2, true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+
+	w := editAssignments(handler, `{"edits":[{"date":"`+day1.Format(time.DateOnly)+`","parent":"ParentB"},{"date":"`+day2.Format(time.DateOnly)+`","unlock":true}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response AssignmentEditsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Synced)
+	require.Len(t, response.Assignments, 2)
+	assert.Equal(t, first.ID, response.Assignments[0].AssignmentID)
+	assert.Equal(t, "ParentB", response.Assignments[0].Caregiver)
+	assert.True(t, response.Assignments[0].Override)
+	assert.True(t, response.Assignments[0].Changed)
+	assert.Equal(t, second.ID, response.Assignments[1].AssignmentID)
+	assert.False(t, response.Assignments[1].Override)
+	assert.True(t, response.Assignments[1].Changed)
+
+	// The unlocked night is recalculated after the override before it
+	recalculated, err := tracker.GetAssignmentByID(t.Context(), second.ID)
+	require.NoError(t, err)
+	assert.False(t, recalculated.Override)
+	assert.Equal(t, recalculated.Parent, response.Assignments[1].Caregiver)
+}
+
+func TestAssignmentEditHandler_Errors(t *testing.T) {
+	handler, tracker := setupTestAssignmentEditHandler(t, true)
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", day, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   apierror.Code
+	}{
+		{"No edit", `{"edits":[]}`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Not JSON", `edits`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Invalid date", `{"edits":[{"date":"16/10/2026","parent":"ParentB"}]}`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Same date twice", `{"edits":[{"date":"2026-10-16","parent":"ParentB"},{"date":"2026-10-16","unlock":true}]}`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Parent and unlock", `{"edits":[{"date":"2026-10-16","parent":"ParentB","unlock":true}]}`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Unknown parent", `{"edits":[{"date":"2026-10-16","parent":"Mallory"}]}`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Night not scheduled", `{"edits":[{"date":"2026-10-16","parent":"ParentB"},{"date":"2026-10-17","parent":"ParentB"}]}`, http.StatusNotFound, apierror.CodeNotFound},
+		{"Stale version", `{"edits":[{"date":"2026-10-16","parent":"ParentB","version":99}]}`, http.StatusConflict, apierror.CodeConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := editAssignments(handler, tt.body)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			var response apierror.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+
+	unchanged, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ParentA", unchanged.Parent, "rejected edits change nothing")
+	assert.Equal(t, assignment.Version, unchanged.Version)
+}
+
+func TestAssignmentEditHandler_Unauthenticated(t *testing.T) {
+	handler, _ := setupTestAssignmentEditHandler(t, false)
+
+	w := editAssignments(handler, `{"edits":[{"date":"2026-10-16","parent":"ParentB"}]}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+// Bounds of the number of assignments returned by the search API
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 500
+)
+
+// AssignmentSearcher searches the assignments, implemented by fairness.Tracker
+type AssignmentSearcher interface {
+	SearchAssignments(ctx context.Context, search fairness.AssignmentSearch) ([]*fairness.Assignment, error)
+}
+
+// AssignmentSearchHandler searches the history of the assignments, for auditing it
+type AssignmentSearchHandler struct {
+	*BaseHandler
+	assignments AssignmentSearcher
+}
+
+// NewAssignmentSearchHandler creates a new assignment search handler
+func NewAssignmentSearchHandler(baseHandler *BaseHandler, assignments AssignmentSearcher) *AssignmentSearchHandler {
+	return &AssignmentSearchHandler{BaseHandler: baseHandler, assignments: assignments}
+}
+
+// RegisterRoutes registers the assignment search routes
+func (h *AssignmentSearchHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/assignments/search", h.handleSearch, http.MethodGet)
+}
+
+// SearchAssignmentResponse is an assignment found by the search API
+type SearchAssignmentResponse struct {
+	UpcomingAssignmentResponse
+	Date string `json:"date"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+// SearchResponse is the response of the assignment search API
+type SearchResponse struct {
+	Assignments []SearchAssignmentResponse `json:"assignments"` // Newest first
+}
+
+// parseAssignmentSearch reads the filters of the query parameters, returning the message of the
+// first invalid one
+func parseAssignmentSearch(r *http.Request) (fairness.AssignmentSearch, string) {
+	query := r.URL.Query()
+	search := fairness.AssignmentSearch{
+		Caregiver: query.Get("caregiver"),
+		Notes:     query.Get("q"),
+		Limit:     defaultSearchLimit,
+	}
+
+	if overrideStr := query.Get("override"); overrideStr != "" {
+		override, err := strconv.ParseBool(overrideStr)
+		if err != nil {
+			return search, "override must be true or false"
+		}
+		search.Override = &override
+	}
+	if reasonStr := query.Get("decision_reason"); reasonStr != "" {
+		reason, err := fairness.ParseDecisionReason(reasonStr)
+		if err != nil {
+			return search, "decision_reason is not a known decision reason"
+		}
+		search.DecisionReason = reason
+	}
+	if tagStr := query.Get("tag"); tagStr != "" {
+		tag, err := fairness.ParseAssignmentTag(tagStr)
+		if err != nil {
+			return search, "tag must be sick_kid or parent_away"
+		}
+		search.Tag = tag
+	}
+
+	var err error
+	if search.From, err = parseExportDate(query.Get("from"), time.Time{}); err != nil {
+		return search, "from must be formatted as YYYY-MM-DD"
+	}
+	if search.To, err = parseExportDate(query.Get("to"), time.Time{}); err != nil {
+		return search, "to must be formatted as YYYY-MM-DD"
+	}
+	if !search.From.IsZero() && !search.To.IsZero() && search.To.Before(search.From) {
+		return search, "to must not be before from"
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			return search, "limit must be a number between 1 and 500"
+		}
+		search.Limit = limit
+	}
+	return search, ""
+}
+
+// handleSearch returns the assignments matching every filter of the query parameters, newest
+// first: caregiver, override, decision_reason, tag, q (words of the comments), from, to and limit
+// (default 50, at most 500).
+func (h *AssignmentSearchHandler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleSearch").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to assignment search")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	search, invalid := parseAssignmentSearch(r)
+	if invalid != "" {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, invalid, handlerLogger)
+		return
+	}
+
+	assignments, err := h.assignments.SearchAssignments(r.Context(), search)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to search assignments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to search assignments", handlerLogger)
+		return
+	}
+
+	response := SearchResponse{Assignments: make([]SearchAssignmentResponse, 0, len(assignments))}
+	styles := h.parentStyles(handlerLogger)
+	for _, a := range assignments {
+		found := SearchAssignmentResponse{
+			UpcomingAssignmentResponse: UpcomingAssignmentResponse{
+				ID:             a.ID,
+				Caregiver:      a.Parent,
+				CaregiverType:  a.CaregiverType.String(),
+				Override:       a.Override,
+				DecisionReason: a.DecisionReason.String(),
+				Synced:         a.GoogleCalendarEventID != "",
+			},
+			Date: a.Date.Format(exportDateFormat),
+			Tag:  a.Tag.String(),
+		}
+		if style, ok := styles[a.Parent]; ok && a.CaregiverType == fairness.CaregiverTypeParent {
+			found.Color = style.Color.Hex()
+			found.Avatar = style.Badge(a.Parent)
+		}
+		response.Assignments = append(response.Assignments, found)
+	}
+	handlerLogger.Debug().Int("count", len(response.Assignments)).Msg("Assignments searched")
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
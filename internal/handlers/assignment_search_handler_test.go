@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignmentSearchHandler(t *testing.T) {
+	env := setupTestDayHandler(t, true)
+	handler := NewAssignmentSearchHandler(env.handler.BaseHandler, env.tracker)
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	_, err := env.tracker.RecordAssignment(t.Context(), "Alice", day(1), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	sick, err := env.tracker.RecordAssignment(t.Context(), "Bob", day(2), true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	require.NoError(t, env.tracker.SetAssignmentTag(t.Context(), sick.ID, fairness.AssignmentTagSickKid))
+	_, err = env.comments.AddComment(sick.ID, "Bob", "Fever, called the doctor")
+	require.NoError(t, err)
+	_, err = env.tracker.RecordBabysitterAssignment(t.Context(), "Dawn", day(3), true)
+	require.NoError(t, err)
+
+	search := func(query string) (int, SearchResponse) {
+		w := httptest.NewRecorder()
+		handler.handleSearch(w, httptest.NewRequest(http.MethodGet, "/api/v1/assignments/search?"+query, nil))
+		var response SearchResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		}
+		return w.Code, response
+	}
+
+	code, response := search("")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Assignments, 3)
+	assert.Equal(t, "2026-03-03", response.Assignments[0].Date, "newest first")
+	assert.Empty(t, response.Assignments[0].Color, "no color for a babysitter")
+
+	code, response = search("override=true&tag=sick_kid&q=doctor")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Assignments, 1)
+	assert.Equal(t, SearchAssignmentResponse{
+		UpcomingAssignmentResponse: UpcomingAssignmentResponse{
+			ID: sick.ID, Caregiver: "Bob", CaregiverType: "parent", Override: true, DecisionReason: "Override",
+			Color: "#f4511e", Avatar: "B",
+		},
+		Date: "2026-03-02",
+		Tag:  "sick_kid",
+	}, response.Assignments[0])
+
+	code, response = search("caregiver=Alice&decision_reason=Total+Count&from=2026-03-01&to=2026-03-31&limit=1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Assignments, 1)
+	assert.Equal(t, "2026-03-01", response.Assignments[0].Date)
+
+	code, response = search("caregiver=Carol")
+	require.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, response.Assignments, "an empty list rather than null")
+	assert.Empty(t, response.Assignments)
+
+	for _, query := range []string{"override=maybe", "decision_reason=Coin", "tag=tired", "from=March", "from=2026-03-02&to=2026-03-01", "limit=0", "limit=501"} {
+		code, _ := search(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+
+	w := httptest.NewRecorder()
+	handler.handleSearch(w, httptest.NewRequest(http.MethodPost, "/api/v1/assignments/search", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestAssignmentSearchHandler_Unauthenticated(t *testing.T) {
+	env := setupTestDayHandler(t, false)
+	handler := NewAssignmentSearchHandler(env.handler.BaseHandler, env.tracker)
+
+	w := httptest.NewRecorder()
+	handler.handleSearch(w, httptest.NewRequest(http.MethodGet, "/api/v1/assignments/search", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
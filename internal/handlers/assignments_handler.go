@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+)
+
+// Bounds of the date range returned by the assignments API
+const (
+	defaultAssignmentsDays = 31
+	maxAssignmentsDays     = 366
+)
+
+// AssignmentsHandler reads, overrides and regenerates the assignments as JSON, for dashboards and
+// scripts
+type AssignmentsHandler struct {
+	*BaseHandler
+	Tracker         fairness.TrackerInterface
+	Scheduler       Scheduler.SchedulerInterface
+	CalendarService calendar.CalendarService
+	ConfigStore     config.ConfigStoreInterface
+	now             func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewAssignmentsHandler creates a new assignments API handler
+func NewAssignmentsHandler(baseHandler *BaseHandler, tracker fairness.TrackerInterface, sched Scheduler.SchedulerInterface, calSvc calendar.CalendarService, configStore config.ConfigStoreInterface) *AssignmentsHandler {
+	return &AssignmentsHandler{
+		BaseHandler:     baseHandler,
+		Tracker:         tracker,
+		Scheduler:       sched,
+		CalendarService: calSvc,
+		ConfigStore:     configStore,
+		now:             time.Now,
+	}
+}
+
+// RegisterRoutes registers the assignments API routes
+func (h *AssignmentsHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/assignments", h.handleAssignments, http.MethodGet, http.MethodPatch)
+	handleMethods(http.DefaultServeMux, "/api/v1/assignments/regenerate", h.handleRegenerate, http.MethodPost)
+}
+
+// AssignmentResponse is an assignment of the assignments API
+type AssignmentResponse struct {
+	ID             int64     `json:"id"`
+	Date           string    `json:"date"`
+	Caregiver      string    `json:"caregiver"`
+	CaregiverType  string    `json:"caregiver_type"`
+	Override       bool      `json:"override"`
+	DecisionReason string    `json:"decision_reason"`
+	Tag            string    `json:"tag,omitempty"`
+	Child          string    `json:"child,omitempty"` // Child whose rotation the night belongs to, absent for the main rotation
+	Version        int64     `json:"version"`         // Precondition to send back with an override
+	Synced         bool      `json:"synced"`          // The assignment has an event in Google Calendar
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// AssignmentsResponse is the response of the assignments API
+type AssignmentsResponse struct {
+	From        string               `json:"from"`
+	To          string               `json:"to"`
+	Assignments []AssignmentResponse `json:"assignments"`      // Oldest first
+	Synced      *bool                `json:"synced,omitempty"` // After a change: false when the calendar could not be synced, the next sync catches up
+}
+
+// RegenerateRequest is the optional JSON body of a regeneration of the schedule
+type RegenerateRequest struct {
+	From string `json:"from,omitempty"` // YYYY-MM-DD, not before today; today when omitted
+}
+
+// newAssignmentResponse converts an assignment of the tracker
+func newAssignmentResponse(a *fairness.Assignment) AssignmentResponse {
+	return AssignmentResponse{
+		ID:             a.ID,
+		Date:           a.Date.Format(time.DateOnly),
+		Caregiver:      a.Parent,
+		CaregiverType:  a.CaregiverType.String(),
+		Override:       a.Override,
+		DecisionReason: a.DecisionReason.String(),
+		Tag:            a.Tag.String(),
+		Version:        a.Version,
+		Synced:         a.GoogleCalendarEventID != "",
+		UpdatedAt:      a.UpdatedAt,
+	}
+}
+
+// handleAssignments lists the assignments of a date range on GET and overrides the night of a date
+// on PATCH
+func (h *AssignmentsHandler) handleAssignments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.handleListAssignments(w, r)
+	case http.MethodPatch:
+		h.handleOverrideAssignment(w, r)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PATCH")
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", h.logger)
+	}
+}
+
+// handleListAssignments returns the assignments of the main rotation from the from query parameter
+// to the to one, both YYYY-MM-DD and included. The range defaults to the 31 days from today and
+// spans at most 366 days.
+func (h *AssignmentsHandler) handleListAssignments(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleListAssignments").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to the assignments")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	now := h.now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	query := r.URL.Query()
+	if fromStr := query.Get("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, fromStr, time.Local)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "from must be formatted as YYYY-MM-DD", handlerLogger)
+			return
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 0, defaultAssignmentsDays-1)
+	if toStr := query.Get("to"); toStr != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, toStr, time.Local)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "to must be formatted as YYYY-MM-DD", handlerLogger)
+			return
+		}
+		to = parsed
+	}
+	if to.Before(from) || !to.Before(from.AddDate(0, 0, maxAssignmentsDays)) {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, fmt.Sprintf("to must not be before from, and the range spans at most %d days", maxAssignmentsDays), handlerLogger)
+		return
+	}
+
+	assignments, err := h.Tracker.GetAssignmentsInRange(r.Context(), from, to)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get assignments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignments", handlerLogger)
+		return
+	}
+
+	response := AssignmentsResponse{
+		From:        from.Format(time.DateOnly),
+		To:          to.Format(time.DateOnly),
+		Assignments: make([]AssignmentResponse, 0, len(assignments)),
+	}
+	for _, a := range assignments {
+		response.Assignments = append(response.Assignments, newAssignmentResponse(a))
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// handleOverrideAssignment sets the night of the request body to a parent as an override, or
+// unlocks it, then recalculates the schedule from it and syncs it. The response holds the night once
+// recalculated.
+func (h *AssignmentsHandler) handleOverrideAssignment(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleOverrideAssignment").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to override an assignment")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var req AssignmentEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, date and parent are required", handlerLogger)
+		return
+	}
+	roster, err := config.Roster(h.ConfigStore)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parents")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get parents", handlerLogger)
+		return
+	}
+	edits, err := parseAssignmentEdits([]AssignmentEditRequest{req}, roster)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+		return
+	}
+	handlerLogger = handlerLogger.With().Str("date", req.Date).Logger()
+
+	// The override and the recalculation following it are undone together
+	kind := fairness.ChangeKindOverride
+	if req.Unlock {
+		kind = fairness.ChangeKindUnlock
+	}
+	ctx := h.Tracker.BeginBatch(r.Context(), kind)
+	edited, err := h.Tracker.EditAssignments(ctx, edits)
+	switch {
+	case errors.Is(err, fairness.ErrAssignmentNotScheduled):
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "The night is not scheduled", handlerLogger)
+		return
+	case errors.Is(err, fairness.ErrAssignmentConflict):
+		handlerLogger.Warn().Err(err).Msg("Night changed before the override")
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "The night was changed in the meantime; reload it and try again", handlerLogger)
+		return
+	case err != nil:
+		handlerLogger.Error().Err(err).Msg("Failed to override assignment")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to change the night", handlerLogger)
+		return
+	}
+
+	synced := true
+	assignment := edited[0].Assignment
+	if edited[0].Changed {
+		// The night is changed, a failing sync is retried by the next one
+		if err := recalculateScheduleAndSync(ctx, h.logger, h.SyncRuns, constants.SyncTriggerAssignment, h.Tracker, h.Scheduler, h.CalendarService, h.ConfigStore, assignment.Date); err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to recalculate schedule after overriding assignment")
+			synced = false
+		}
+		// An unlocked night is given a caregiver by the recalculation
+		if a, err := h.Tracker.GetAssignmentByID(ctx, assignment.ID); err != nil || a == nil {
+			handlerLogger.Warn().Err(err).Int64("assignment_id", assignment.ID).Msg("Failed to read back overridden assignment")
+		} else {
+			assignment = a
+		}
+	}
+
+	writeJSON(w, http.StatusOK, AssignmentsResponse{
+		From:        req.Date,
+		To:          req.Date,
+		Assignments: []AssignmentResponse{newAssignmentResponse(assignment)},
+		Synced:      &synced,
+	}, handlerLogger)
+}
+
+// handleRegenerate regenerates the schedule on POST from the date of the optional request body
+// over the look-ahead days of the manual syncs, overrides and locked ranges being kept, then syncs
+// every regenerated night
+func (h *AssignmentsHandler) handleRegenerate(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleRegenerate").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to regenerate the schedule")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var req RegenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body", handlerLogger)
+		return
+	}
+	now := h.now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if req.From != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, req.From, time.Local)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "from must be formatted as YYYY-MM-DD", handlerLogger)
+			return
+		}
+		if parsed.Before(from) {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "from must not be before today, the past nights are kept", handlerLogger)
+			return
+		}
+		from = parsed
+	}
+
+	_, lookAheadDays, _, _, err := h.ConfigStore.GetSchedule()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get schedule configuration")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get the schedule settings", handlerLogger)
+		return
+	}
+	windows, err := h.ConfigStore.GetLookAheadWindows()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get look-ahead windows")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get the schedule settings", handlerLogger)
+		return
+	}
+	to := from.AddDate(0, 0, windows.Days(constants.SyncTriggerManual, lookAheadDays))
+
+	// Every night from the start date on is recalculated, as by a manual sync
+	assignments, err := h.Scheduler.GenerateSchedule(r.Context(), from, to, from)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to regenerate schedule")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to regenerate the schedule", handlerLogger)
+		return
+	}
+	handlerLogger.Info().Int("assignments_generated", len(assignments)).Msg("Schedule regenerated")
+
+	synced := true
+	if err := h.SyncRuns.RecordRun(r.Context(), constants.SyncTriggerManual, func(ctx context.Context) (int, error) {
+		if !h.CalendarService.IsInitialized() {
+			return 0, fmt.Errorf("calendar service not initialized - authentication required")
+		}
+		if err := h.CalendarService.SyncSchedule(ctx, assignments); err != nil {
+			return len(assignments), fmt.Errorf("failed to sync schedule: %w", err)
+		}
+		return len(assignments), nil
+	}); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to sync regenerated schedule")
+		synced = false
+	}
+
+	response := AssignmentsResponse{
+		From:        from.Format(time.DateOnly),
+		To:          to.Format(time.DateOnly),
+		Assignments: make([]AssignmentResponse, 0, len(assignments)),
+		Synced:      &synced,
+	}
+	for _, a := range assignments {
+		response.Assignments = append(response.Assignments, AssignmentResponse{
+			ID:             a.ID,
+			Date:           a.Date.Format(time.DateOnly),
+			Caregiver:      a.Parent,
+			CaregiverType:  a.CaregiverType.String(),
+			Override:       a.Override,
+			DecisionReason: a.DecisionReason.String(),
+			Tag:            a.Tag.String(),
+			Child:          a.Child,
+			Version:        a.Version,
+			Synced:         a.GoogleCalendarEventID != "",
+			UpdatedAt:      a.UpdatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// assignmentsTestNow is the current time of the assignments handler in tests
+var assignmentsTestNow = time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+
+func setupTestAssignmentsHandler(t *testing.T, authenticated bool) (*AssignmentsHandler, *fairness.Tracker) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	noopCfgStore := &noopConfigStore{}
+	handler := NewAssignmentsHandler(baseHandler, tracker, Scheduler.New(noopCfgStore, tracker), &noopCalendarService{}, noopCfgStore)
+	handler.now = func() time.Time { return assignmentsTestNow }
+	return handler, tracker
+}
+
+func serveAssignments(handler *AssignmentsHandler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	if strings.HasSuffix(target, "/regenerate") {
+		handler.handleRegenerate(w, req)
+	} else {
+		handler.handleAssignments(w, req)
+	}
+	return w
+}
+
+func TestAssignmentsHandler_List(t *testing.T) {
+	handler, tracker := setupTestAssignmentsHandler(t, true)
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+	_, err := tracker.RecordAssignment(t.Context(), "ParentA", day, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "ParentB", day.AddDate(0, 0, 40), true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+
+	w := serveAssignments(handler, http.MethodGet, "/api/v1/assignments", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response AssignmentsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "2026-10-16", response.From)
+	assert.Equal(t, "2026-11-15", response.To)
+	assert.Nil(t, response.Synced)
+	require.Len(t, response.Assignments, 1, "the night after the default range is left out")
+	assert.Equal(t, "2026-10-17", response.Assignments[0].Date)
+	assert.Equal(t, "ParentA", response.Assignments[0].Caregiver)
+	assert.Equal(t, "Alternating", response.Assignments[0].DecisionReason)
+
+	w = serveAssignments(handler, http.MethodGet, "/api/v1/assignments?from=2026-11-01&to=2026-12-31", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Assignments, 1)
+	assert.Equal(t, "ParentB", response.Assignments[0].Caregiver)
+	assert.True(t, response.Assignments[0].Override)
+
+	for _, target := range []string{"/api/v1/assignments?from=16-10-2026", "/api/v1/assignments?to=tomorrow", "/api/v1/assignments?from=2026-10-16&to=2026-10-15", "/api/v1/assignments?from=2026-01-01&to=2027-01-02"} {
+		w := serveAssignments(handler, http.MethodGet, target, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
+
+func TestAssignmentsHandler_Override(t *testing.T) {
+	handler, tracker := setupTestAssignmentsHandler(t, true)
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", day, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	w := serveAssignments(handler, http.MethodPatch, "/api/v1/assignments", `{"date":"2026-10-17","parent":"ParentB"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response AssignmentsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Synced)
+	assert.True(t, *response.Synced)
+	require.Len(t, response.Assignments, 1)
+	assert.Equal(t, assignment.ID, response.Assignments[0].ID)
+	assert.Equal(t, "ParentB", response.Assignments[0].Caregiver)
+	assert.True(t, response.Assignments[0].Override)
+	assert.Equal(t, "Override", response.Assignments[0].DecisionReason)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   apierror.Code
+	}{
+		{"Not JSON", `parent`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"No parent", `{"date":"2026-10-17"}`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Unknown parent", `{"date":"2026-10-17","parent":"Mallory"}`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Night not scheduled", `{"date":"2026-10-18","parent":"ParentB"}`, http.StatusNotFound, apierror.CodeNotFound},
+		{"Stale version", `{"date":"2026-10-17","parent":"ParentA","version":1}`, http.StatusConflict, apierror.CodeConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveAssignments(handler, http.MethodPatch, "/api/v1/assignments", tt.body)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			var response apierror.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+}
+
+func TestAssignmentsHandler_Regenerate(t *testing.T) {
+	handler, tracker := setupTestAssignmentsHandler(t, true)
+
+	w := serveAssignments(handler, http.MethodPost, "/api/v1/assignments/regenerate", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response AssignmentsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "2026-10-16", response.From)
+	require.NotNil(t, response.Synced)
+	assert.True(t, *response.Synced)
+	require.NotEmpty(t, response.Assignments)
+	assert.Equal(t, "2026-10-16", response.Assignments[0].Date)
+
+	recorded, err := tracker.GetAssignmentByDate(t.Context(), time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	require.NotNil(t, recorded)
+	assert.Equal(t, recorded.ID, response.Assignments[0].ID)
+
+	w = serveAssignments(handler, http.MethodPost, "/api/v1/assignments/regenerate", `{"from":"2026-10-20"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "2026-10-20", response.Assignments[0].Date)
+
+	for _, body := range []string{`{"from":"2026-10-15"}`, `{"from":"20/10/2026"}`, `from`} {
+		w := serveAssignments(handler, http.MethodPost, "/api/v1/assignments/regenerate", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestAssignmentsHandler_Unauthenticated(t *testing.T) {
+	handler, _ := setupTestAssignmentsHandler(t, false)
+
+	assert.Equal(t, http.StatusUnauthorized, serveAssignments(handler, http.MethodGet, "/api/v1/assignments", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveAssignments(handler, http.MethodPatch, "/api/v1/assignments", `{"date":"2026-10-17","parent":"ParentB"}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, serveAssignments(handler, http.MethodPost, "/api/v1/assignments/regenerate", "").Code)
+}
@@ -3,17 +3,22 @@ package handlers
 //go:generate pnpm run build:css
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
+	"path"
+	"sync"
 	"time"
 
 	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/database"
 	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/features"
 	"github.com/belphemur/night-routine/internal/logging"
 	"github.com/belphemur/night-routine/internal/token"
 	"github.com/rs/zerolog"
@@ -24,24 +29,53 @@ var templateFS embed.FS
 
 // BaseHandler contains common handler functionality
 type BaseHandler struct {
-	tmpl         *template.Template
-	TokenStore   *database.TokenStore
+	// pages holds every page template parsed with the layout, keyed by file name
+	pages        map[string]*template.Template
+	TokenStore   database.TokenStoreInterface
 	TokenManager *token.TokenManager
 	// ConfigStore is the single source of truth for all application configuration.
 	// It reads schedule/parent/availability settings live from the database and
 	// returns static values (OAuth) from the file/env config — no RuntimeConfig needed.
 	ConfigStore config.ConfigStoreInterface
 	Tracker     fairness.TrackerInterface
-	cssVersion  string
-	logoVersion string
-	logger      zerolog.Logger
+	// SyncRuns records the history of schedule syncs; nil disables recording
+	SyncRuns *database.SyncRunStore
+	// Build describes the running binary, shown in the page footer and by /api/version
+	Build BuildInfo
+	// Features gates the experimental subsystems; nil gives every flag its default state
+	Features *features.Flags
+	logger   zerolog.Logger
 }
 
+// renderBuffers are reused across renders, a page is written only once fully rendered
+var renderBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
 // NewBaseHandler creates a common base handler with shared components
-func NewBaseHandler(configStore config.ConfigStoreInterface, tokenStore *database.TokenStore, tokenManager *token.TokenManager, tracker fairness.TrackerInterface, cssVersion, logoVersion string) (*BaseHandler, error) {
+func NewBaseHandler(configStore config.ConfigStoreInterface, tokenStore database.TokenStoreInterface, tokenManager *token.TokenManager, tracker fairness.TrackerInterface, syncRuns *database.SyncRunStore) (*BaseHandler, error) {
 	logger := logging.GetLogger("base-handler")
 	logger.Debug().Msg("Parsing templates")
 
+	pages, err := parsePages()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to parse templates")
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	logger.Debug().Int("pages", len(pages)).Msg("Templates parsed successfully")
+
+	return &BaseHandler{
+		pages:        pages,
+		TokenStore:   tokenStore,
+		TokenManager: tokenManager,
+		ConfigStore:  configStore,
+		Tracker:      tracker,
+		SyncRuns:     syncRuns,
+		logger:       logger,
+	}, nil
+}
+
+// parsePages parses the layout once and every page template into its own copy of it,
+// so that a broken template fails the startup instead of a request
+func parsePages() (map[string]*template.Template, error) {
 	// Define custom template functions
 	funcMap := template.FuncMap{
 		"add": func(a, b int) int {
@@ -51,52 +85,61 @@ func NewBaseHandler(configStore config.ConfigStoreInterface, tokenStore *databas
 			a, _ := json.Marshal(v)
 			return template.JS(a)
 		},
+		"asset": assetURL,
 	}
 
-	// Parse only layout.html initially
-	tmpl, err := template.New("").Funcs(funcMap).ParseFS(templateFS, "templates/layout.html")
+	layout, err := template.New("").Funcs(funcMap).ParseFS(templateFS, "templates/layout.html")
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to parse templates")
-		return nil, fmt.Errorf("failed to parse templates: %w", err)
+		return nil, err
 	}
-	logger.Debug().Msg("Templates parsed successfully")
 
-	return &BaseHandler{
-		tmpl:         tmpl, // Updated field name
-		TokenStore:   tokenStore,
-		TokenManager: tokenManager,
-		ConfigStore:  configStore,
-		Tracker:      tracker,
-		cssVersion:   cssVersion,
-		logoVersion:  logoVersion,
-		logger:       logger,
-	}, nil
+	files, err := fs.Glob(templateFS, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		name := path.Base(file)
+		if name == "layout.html" {
+			continue
+		}
+		page, err := layout.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := page.ParseFS(templateFS, file); err != nil {
+			return nil, err
+		}
+		pages[name] = page
+	}
+	return pages, nil
 }
 
 // RenderTemplate renders a template with the given data
 func (h *BaseHandler) RenderTemplate(w http.ResponseWriter, name string, data any) {
 	h.logger.Debug().Str("template_name", name).Msg("Executing template")
 
-	// Clone the base template (which contains layout.html)
-	tmpl, err := h.tmpl.Clone()
-	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to clone template")
+	tmpl, ok := h.pages[name]
+	if !ok {
+		h.logger.Error().Str("template", name).Msg("Unknown page template")
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	// Parse the specific page template into the clone
-	_, err = tmpl.ParseFS(templateFS, "templates/"+name)
-	if err != nil {
-		h.logger.Error().Err(err).Str("template", name).Msg("Failed to parse page template")
+	buf := renderBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer renderBuffers.Put(buf)
+
+	// Render fully before writing, so that a failing template still gets a clean error response
+	if err := tmpl.ExecuteTemplate(buf, "layout.html", data); err != nil {
+		h.logger.Error().Err(err).Str("template", name).Msg("Failed to execute template")
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := tmpl.ExecuteTemplate(w, "layout.html", data); err != nil {
-		h.logger.Error().Err(err).Str("template", name).Msg("Failed to execute template")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	if _, err := buf.WriteTo(w); err != nil {
+		h.logger.Debug().Err(err).Str("template", name).Msg("Failed to write page")
 	}
 }
 
@@ -132,8 +175,7 @@ type BasePageData struct {
 	CurrentYear     int
 	CurrentPath     string
 	IsAuthenticated bool
-	CSSETag         string
-	LogoETag        string
+	Version         string // Version of the running binary, shown in the footer
 }
 
 // NewBasePageData creates a new BasePageData with common fields populated
@@ -142,7 +184,31 @@ func (h *BaseHandler) NewBasePageData(r *http.Request, isAuthenticated bool) Bas
 		CurrentYear:     time.Now().Year(),
 		CurrentPath:     r.URL.Path,
 		IsAuthenticated: isAuthenticated,
-		CSSETag:         h.cssVersion,
-		LogoETag:        h.logoVersion,
+		Version:         h.Build.Version,
+	}
+}
+
+// parentStyles returns the color and the avatar of each parent of the roster, keyed by the name of
+// the parent. Errors are logged and return nil, the caregivers are then shown without style.
+func (h *BaseHandler) parentStyles(logger zerolog.Logger) map[string]config.ParentStyle {
+	parentA, parentB, err := h.ConfigStore.GetParents()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get parents for their styles")
+		return nil
+	}
+	styleA, styleB, err := h.ConfigStore.GetParentStyles()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get parent styles")
+		return nil
+	}
+	extra, err := h.ConfigStore.GetExtraParents()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get extra parents for their styles")
+		return nil
+	}
+	styles := map[string]config.ParentStyle{parentA: styleA, parentB: styleB}
+	for _, parent := range extra {
+		styles[parent.Name] = parent.Style
 	}
+	return styles
 }
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePages(t *testing.T) {
+	pages, err := parsePages()
+	require.NoError(t, err)
+
+	for _, name := range []string{"home.html", "settings.html", "statistics.html", "calendars.html", "device_auth.html"} {
+		page, ok := pages[name]
+		require.True(t, ok, "page %s is parsed", name)
+		assert.NotNil(t, page.Lookup("layout.html"), "page %s includes the layout", name)
+		assert.NotNil(t, page.Lookup("content"), "page %s defines its content", name)
+	}
+	assert.NotContains(t, pages, "layout.html")
+}
+
+func TestRenderTemplate(t *testing.T) {
+	pages, err := parsePages()
+	require.NoError(t, err)
+	handler := &BaseHandler{pages: pages}
+
+	t.Run("renders the page with versioned assets", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.RenderTemplate(w, "home.html", HomePageData{})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		logoURL, err := assetURL("images/logo.png")
+		require.NoError(t, err)
+		assert.Contains(t, w.Body.String(), logoURL)
+	})
+
+	t.Run("unknown page", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.RenderTemplate(w, "missing.html", nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("execution error writes no partial page", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		// The layout reads fields missing from a string
+		handler.RenderTemplate(w, "home.html", "not page data")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "<html")
+	})
+}
+
+func TestAssetURL(t *testing.T) {
+	cssURL, err := assetURL("css/tailwind.css")
+	require.NoError(t, err)
+	assert.Regexp(t, `^/static/css/tailwind\.css\?v=[0-9a-f]{16}$`, cssURL)
+
+	faviconURL, err := assetURL("images/favicon.png")
+	require.NoError(t, err)
+	assert.Regexp(t, `^/static/images/favicon\.png\?v=[0-9a-f]{16}$`, faviconURL)
+
+	_, err = assetURL("images/missing.png")
+	assert.Error(t, err)
+}
@@ -24,7 +24,7 @@ func NewCalendarHandler(baseHandler *BaseHandler, calendarManager *calendar.Mana
 
 // RegisterRoutes registers calendar related routes
 func (h *CalendarHandler) RegisterRoutes() {
-	http.HandleFunc("/calendars", h.handleCalendarList)
+	handleMethods(http.DefaultServeMux, "/calendars", h.handleCalendarList, http.MethodGet, http.MethodPost)
 }
 
 // CalendarPageData contains data for the calendar selection page
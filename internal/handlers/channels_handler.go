@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/rs/zerolog"
+)
+
+// Actions on a notification channel, the last segment of its API path
+const (
+	channelActionVerify   = "verify"
+	channelActionRenew    = "renew"
+	channelActionRecreate = "recreate"
+)
+
+// ChannelsHandler shows the health of the Google Calendar notification channels and verifies,
+// renews or recreates them on demand
+type ChannelsHandler struct {
+	*BaseHandler
+	CalendarService calendar.CalendarService
+}
+
+// NewChannelsHandler creates a new notification channels handler
+func NewChannelsHandler(baseHandler *BaseHandler, calSvc calendar.CalendarService) *ChannelsHandler {
+	return &ChannelsHandler{
+		BaseHandler:     baseHandler,
+		CalendarService: calSvc,
+	}
+}
+
+// RegisterRoutes registers the notification channels routes
+func (h *ChannelsHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/admin/channels", h.handleChannelsPage, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/admin/channels", h.handleListChannels, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/admin/channels/{id}/{action}", h.handleChannelAction, http.MethodPost)
+}
+
+// ChannelResponse is a notification channel with its health
+type ChannelResponse struct {
+	ID                 string     `json:"id"`
+	ResourceID         string     `json:"resource_id"`
+	CalendarID         string     `json:"calendar_id"`
+	CreatedAt          time.Time  `json:"created_at"`
+	Expiration         time.Time  `json:"expiration"`
+	Expired            bool       `json:"expired"`
+	LastNotificationAt *time.Time `json:"last_notification_at"` // Null until Google delivers a notification
+	VerificationStatus string     `json:"verification_status"`  // active, inactive, error or unverified
+	VerifiedAt         *time.Time `json:"verified_at"`          // Null until verified
+}
+
+// ChannelsResponse is the response of the notification channels API
+type ChannelsResponse struct {
+	Channels []ChannelResponse `json:"channels"`
+}
+
+// ChannelsPageData contains data for the notification channels page template
+type ChannelsPageData struct {
+	BasePageData
+	Channels     []ChannelResponse
+	ErrorMessage string
+}
+
+// newChannelResponse converts a stored notification channel for the API and the page
+func newChannelResponse(channel *database.NotificationChannel, now time.Time) ChannelResponse {
+	status := string(channel.VerificationStatus)
+	if channel.VerificationStatus == database.ChannelVerificationNone {
+		status = "unverified"
+	}
+	return ChannelResponse{
+		ID:                 channel.ID,
+		ResourceID:         channel.ResourceID,
+		CalendarID:         channel.CalendarID,
+		CreatedAt:          channel.CreatedAt,
+		Expiration:         channel.Expiration,
+		Expired:            !channel.Expiration.After(now),
+		LastNotificationAt: channel.LastNotificationAt,
+		VerificationStatus: status,
+		VerifiedAt:         channel.VerifiedAt,
+	}
+}
+
+// listChannels returns every notification channel with its health, expired ones included
+func (h *ChannelsHandler) listChannels() ([]ChannelResponse, error) {
+	channels, err := h.TokenStore.ListNotificationChannels()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	response := make([]ChannelResponse, 0, len(channels))
+	for _, channel := range channels {
+		response = append(response, newChannelResponse(channel, now))
+	}
+	return response, nil
+}
+
+// handleChannelsPage shows the notification channels with their health and the buttons acting on them
+func (h *ChannelsHandler) handleChannelsPage(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleChannelsPage").Logger()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	isAuthenticated := h.CheckAuthentication(r.Context(), handlerLogger)
+	data := ChannelsPageData{BasePageData: h.NewBasePageData(r, isAuthenticated)}
+	if isAuthenticated {
+		channels, err := h.listChannels()
+		if err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to list notification channels")
+			data.ErrorMessage = GetErrorMessage(ErrCodeFailedLoadChannels)
+		}
+		data.Channels = channels
+	}
+
+	h.RenderTemplate(w, "channels.html", data)
+}
+
+// handleListChannels returns every notification channel with its health, ordered by expiration
+func (h *ChannelsHandler) handleListChannels(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleListChannels").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	h.writeChannels(w, handlerLogger)
+}
+
+// handleChannelAction verifies, renews or recreates a notification channel, and answers with every
+// channel as listed afterwards
+func (h *ChannelsHandler) handleChannelAction(w http.ResponseWriter, r *http.Request) {
+	channelID, action := r.PathValue("id"), r.PathValue("action")
+	handlerLogger := h.logger.With().Str("handler", "handleChannelAction").Str("channel_id", channelID).Str("action", action).Logger()
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	if action != channelActionVerify && action != channelActionRenew && action != channelActionRecreate {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Unknown action, use verify, renew or recreate", handlerLogger)
+		return
+	}
+
+	channel, err := h.TokenStore.GetNotificationChannelByID(channelID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get notification channel")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the notification channel", handlerLogger)
+		return
+	}
+	if channel == nil {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Notification channel not found", handlerLogger)
+		return
+	}
+
+	if !h.CalendarService.IsInitialized() {
+		if err := h.CalendarService.Initialize(r.Context()); err != nil {
+			handlerLogger.Warn().Err(err).Msg("Calendar service not initialized")
+			writeError(w, http.StatusServiceUnavailable, apierror.CodeAuthenticationRequired, GetErrorMessage(ErrCodeAuthRequired), handlerLogger)
+			return
+		}
+	}
+
+	switch action {
+	case channelActionVerify:
+		_, err = h.CalendarService.VerifyNotificationChannel(r.Context(), channel.ID, channel.ResourceID)
+	case channelActionRenew:
+		err = h.CalendarService.RenewNotificationChannel(r.Context(), channel.ID)
+	case channelActionRecreate:
+		err = h.CalendarService.RecreateNotificationChannel(r.Context(), channel.ID)
+	}
+	if errors.Is(err, calendar.ErrNotificationChannelNotFound) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Notification channel not found", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to act on the notification channel")
+		writeError(w, http.StatusBadGateway, apierror.CodeCalendarError, "Failed to "+action+" the notification channel", handlerLogger)
+		return
+	}
+
+	handlerLogger.Info().Msg("Acted on the notification channel")
+	h.writeChannels(w, handlerLogger)
+}
+
+// writeChannels answers with every notification channel
+func (h *ChannelsHandler) writeChannels(w http.ResponseWriter, logger zerolog.Logger) {
+	channels, err := h.listChannels()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list notification channels")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve notification channels", logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, ChannelsResponse{Channels: channels}, logger)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelsHandler(t *testing.T) {
+	env := setupTestDayHandler(t, true)
+	calSvc := new(MockCalendarService)
+	handler := NewChannelsHandler(env.handler.BaseHandler, calSvc)
+
+	require.NoError(t, handler.TokenStore.SaveNotificationChannel(&database.NotificationChannel{
+		ID: "channel-1", ResourceID: "resource-1", CalendarID: "family@example.com", Expiration: time.Now().Add(72 * time.Hour),
+	}))
+	require.NoError(t, handler.TokenStore.RecordNotificationReceived("channel-1", time.Now()))
+
+	act := func(id, action string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/channels/"+id+"/"+action, nil)
+		req.SetPathValue("id", id)
+		req.SetPathValue("action", action)
+		w := httptest.NewRecorder()
+		handler.handleChannelAction(w, req)
+		return w
+	}
+
+	w := httptest.NewRecorder()
+	handler.handleListChannels(w, httptest.NewRequest(http.MethodGet, "/api/admin/channels", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list ChannelsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Channels, 1)
+	assert.Equal(t, "family@example.com", list.Channels[0].CalendarID)
+	assert.False(t, list.Channels[0].Expired)
+	assert.NotNil(t, list.Channels[0].LastNotificationAt)
+	assert.Equal(t, "unverified", list.Channels[0].VerificationStatus)
+
+	w = httptest.NewRecorder()
+	handler.handleChannelsPage(w, httptest.NewRequest(http.MethodGet, "/admin/channels", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "family@example.com")
+
+	calSvc.On("IsInitialized").Return(true)
+	calSvc.On("VerifyNotificationChannel", mock.Anything, "channel-1", "resource-1").Return(true, nil).Once()
+	calSvc.On("RenewNotificationChannel", mock.Anything, "channel-1").Return(nil).Once()
+	calSvc.On("RecreateNotificationChannel", mock.Anything, "channel-1").Return(errors.New("google unavailable")).Once()
+
+	assert.Equal(t, http.StatusOK, act("channel-1", "verify").Code)
+	assert.Equal(t, http.StatusOK, act("channel-1", "renew").Code)
+	w = act("channel-1", "recreate")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to recreate the notification channel")
+	assert.Equal(t, http.StatusNotFound, act("channel-1", "delete").Code, "unknown action")
+	assert.Equal(t, http.StatusNotFound, act("channel-2", "verify").Code, "unknown channel")
+	calSvc.AssertExpectations(t)
+}
+
+func TestChannelsHandler_Unauthenticated(t *testing.T) {
+	handler := NewChannelsHandler(setupTestDayHandler(t, false).handler.BaseHandler, new(MockCalendarService))
+
+	w := httptest.NewRecorder()
+	handler.handleListChannels(w, httptest.NewRequest(http.MethodGet, "/api/admin/channels", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/channels/channel-1/verify", nil)
+	req.SetPathValue("id", "channel-1")
+	req.SetPathValue("action", "verify")
+	w = httptest.NewRecorder()
+	handler.handleChannelAction(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.handleListChannels(w, httptest.NewRequest(http.MethodPost, "/api/admin/channels", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
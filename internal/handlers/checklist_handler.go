@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/rs/zerolog"
+)
+
+// ChecklistHandler serves the bedtime checklist of the assignments
+type ChecklistHandler struct {
+	*BaseHandler
+	checklists *database.ChecklistStore
+}
+
+// NewChecklistHandler creates a new checklist handler
+func NewChecklistHandler(baseHandler *BaseHandler, checklists *database.ChecklistStore) *ChecklistHandler {
+	return &ChecklistHandler{BaseHandler: baseHandler, checklists: checklists}
+}
+
+// RegisterRoutes registers the checklist routes
+func (h *ChecklistHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/assignment-checklist", h.handleChecklist, http.MethodGet, http.MethodPut, http.MethodPost)
+}
+
+// ChecklistItemResponse is an item of the checklist in the API response
+type ChecklistItemResponse struct {
+	Label       string     `json:"label"`
+	Done        bool       `json:"done"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ChecklistResponse is the checklist of an assignment
+type ChecklistResponse struct {
+	AssignmentID int64                   `json:"assignment_id"`
+	Items        []ChecklistItemResponse `json:"items"`
+}
+
+// saveChecklistRequest replaces the items of the checklist
+type saveChecklistRequest struct {
+	AssignmentID int64    `json:"assignment_id"`
+	Items        []string `json:"items"`
+}
+
+// markChecklistItemRequest marks an item of the checklist as done or not done
+type markChecklistItemRequest struct {
+	AssignmentID int64  `json:"assignment_id"`
+	Label        string `json:"label"`
+	Done         bool   `json:"done"`
+}
+
+// handleChecklist returns the checklist of an assignment on GET, replaces its items on PUT and
+// marks one of its items on POST. Every method answers with the resulting checklist.
+func (h *ChecklistHandler) handleChecklist(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleChecklist").Str("method", r.Method).Logger()
+	handlerLogger.Debug().Msg("Handling checklist request")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to checklist")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var assignmentID int64
+	switch r.Method {
+	case http.MethodGet:
+		var err error
+		assignmentID, err = strconv.ParseInt(r.URL.Query().Get("assignment_id"), 10, 64)
+		if err != nil || assignmentID <= 0 {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Missing or invalid assignment_id parameter", handlerLogger)
+			return
+		}
+		if !h.assignmentExists(r.Context(), w, assignmentID, handlerLogger) {
+			return
+		}
+
+	case http.MethodPut:
+		var req saveChecklistRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AssignmentID <= 0 {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, assignment_id and items are required", handlerLogger)
+			return
+		}
+		assignmentID = req.AssignmentID
+		if !h.assignmentExists(r.Context(), w, assignmentID, handlerLogger) {
+			return
+		}
+		if err := h.checklists.SaveChecklist(assignmentID, req.Items); err != nil {
+			if errors.Is(err, database.ErrInvalidChecklist) {
+				writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+				return
+			}
+			handlerLogger.Error().Err(err).Int64("assignment_id", assignmentID).Msg("Failed to save checklist")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to save checklist", handlerLogger)
+			return
+		}
+		handlerLogger.Info().Int64("assignment_id", assignmentID).Int("item_count", len(req.Items)).Msg("Checklist saved")
+
+	case http.MethodPost:
+		var req markChecklistItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AssignmentID <= 0 || req.Label == "" {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, assignment_id and label are required", handlerLogger)
+			return
+		}
+		assignmentID = req.AssignmentID
+		if !h.assignmentExists(r.Context(), w, assignmentID, handlerLogger) {
+			return
+		}
+		if err := h.checklists.SetItemDone(assignmentID, req.Label, req.Done); err != nil {
+			if errors.Is(err, database.ErrChecklistItemNotFound) {
+				writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Checklist item not found", handlerLogger)
+				return
+			}
+			handlerLogger.Error().Err(err).Int64("assignment_id", assignmentID).Msg("Failed to mark checklist item")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to mark checklist item", handlerLogger)
+			return
+		}
+		handlerLogger.Info().Int64("assignment_id", assignmentID).Str("label", req.Label).Bool("done", req.Done).Msg("Checklist item marked")
+	}
+
+	items, err := h.checklists.GetChecklist(assignmentID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Int64("assignment_id", assignmentID).Msg("Failed to get checklist")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve checklist", handlerLogger)
+		return
+	}
+
+	response := ChecklistResponse{AssignmentID: assignmentID, Items: make([]ChecklistItemResponse, 0, len(items))}
+	for _, item := range items {
+		response.Items = append(response.Items, ChecklistItemResponse{
+			Label:       item.Label,
+			Done:        item.Done(),
+			CompletedAt: item.CompletedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// assignmentExists writes a not found or server error response when the assignment cannot be found
+func (h *BaseHandler) assignmentExists(ctx context.Context, w http.ResponseWriter, assignmentID int64, logger zerolog.Logger) bool {
+	assignment, err := h.Tracker.GetAssignmentByID(ctx, assignmentID)
+	if err != nil {
+		logger.Error().Err(err).Int64("assignment_id", assignmentID).Msg("Failed to get assignment")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment", logger)
+		return false
+	}
+	if assignment == nil {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Assignment not found", logger)
+		return false
+	}
+	return true
+}
+
+// writeJSON writes payload as a JSON response with the status code
+func writeJSON(w http.ResponseWriter, status int, payload any, logger zerolog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		logger.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
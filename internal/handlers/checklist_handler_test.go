@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestChecklistHandler(t *testing.T, authenticated bool) (*ChecklistHandler, *fairness.Assignment, *database.ConfigStore, func()) {
+	detailsHandler, tracker, db, cleanup := setupTestAssignmentDetailsHandler(t, authenticated)
+
+	cfgStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, cfgStore.SaveChecklistTemplate(t.Context(), []string{"Bath", "Story"}))
+
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	checklists, err := database.NewChecklistStore(db)
+	require.NoError(t, err)
+
+	handler := NewChecklistHandler(detailsHandler.BaseHandler, checklists)
+	return handler, assignment, cfgStore, cleanup
+}
+
+func decodeChecklistResponse(t *testing.T, w *httptest.ResponseRecorder) ChecklistResponse {
+	t.Helper()
+	var response ChecklistResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	return response
+}
+
+func checklistLabels(response ChecklistResponse) []string {
+	labels := make([]string, 0, len(response.Items))
+	for _, item := range response.Items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func TestChecklistHandler_GetFollowsTemplate(t *testing.T) {
+	handler, assignment, _, cleanup := setupTestChecklistHandler(t, true)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/assignment-checklist?assignment_id="+strconv.FormatInt(assignment.ID, 10), nil)
+	w := httptest.NewRecorder()
+	handler.handleChecklist(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	response := decodeChecklistResponse(t, w)
+	assert.Equal(t, assignment.ID, response.AssignmentID)
+	assert.Equal(t, []string{"Bath", "Story"}, checklistLabels(response))
+	for _, item := range response.Items {
+		assert.False(t, item.Done)
+		assert.Nil(t, item.CompletedAt)
+	}
+}
+
+func TestChecklistHandler_PutReplacesItems(t *testing.T) {
+	handler, assignment, cfgStore, cleanup := setupTestChecklistHandler(t, true)
+	defer cleanup()
+
+	body, err := json.Marshal(map[string]any{"assignment_id": assignment.ID, "items": []string{"Story", "Teeth"}})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	handler.handleChecklist(w, httptest.NewRequest(http.MethodPut, "/api/assignment-checklist", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Story", "Teeth"}, checklistLabels(decodeChecklistResponse(t, w)))
+
+	// The edited checklist no longer follows the template
+	require.NoError(t, cfgStore.SaveChecklistTemplate(t.Context(), []string{"Pajamas"}))
+	w = httptest.NewRecorder()
+	handler.handleChecklist(w, httptest.NewRequest(http.MethodGet, "/api/assignment-checklist?assignment_id="+strconv.FormatInt(assignment.ID, 10), nil))
+	assert.Equal(t, []string{"Story", "Teeth"}, checklistLabels(decodeChecklistResponse(t, w)))
+}
+
+func TestChecklistHandler_PostMarksItem(t *testing.T) {
+	handler, assignment, _, cleanup := setupTestChecklistHandler(t, true)
+	defer cleanup()
+
+	body, err := json.Marshal(markChecklistItemRequest{AssignmentID: assignment.ID, Label: "Bath", Done: true})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	handler.handleChecklist(w, httptest.NewRequest(http.MethodPost, "/api/assignment-checklist", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	response := decodeChecklistResponse(t, w)
+	require.Len(t, response.Items, 2)
+	assert.True(t, response.Items[0].Done)
+	assert.NotNil(t, response.Items[0].CompletedAt)
+	assert.False(t, response.Items[1].Done)
+
+	body, err = json.Marshal(markChecklistItemRequest{AssignmentID: assignment.ID, Label: "Bath", Done: false})
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	handler.handleChecklist(w, httptest.NewRequest(http.MethodPost, "/api/assignment-checklist", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	response = decodeChecklistResponse(t, w)
+	assert.False(t, response.Items[0].Done)
+	assert.Nil(t, response.Items[0].CompletedAt)
+}
+
+func TestChecklistHandler_Errors(t *testing.T) {
+	handler, assignment, _, cleanup := setupTestChecklistHandler(t, true)
+	defer cleanup()
+
+	tooLong := string(bytes.Repeat([]byte("a"), database.MaxChecklistLabelRunes+1))
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           any
+		expectedStatus int
+	}{
+		{"missing assignment id", http.MethodGet, "/api/assignment-checklist", nil, http.StatusBadRequest},
+		{"unknown assignment", http.MethodGet, "/api/assignment-checklist?assignment_id=9999", nil, http.StatusNotFound},
+		{"label too long", http.MethodPut, "/api/assignment-checklist", saveChecklistRequest{AssignmentID: assignment.ID, Items: []string{tooLong}}, http.StatusBadRequest},
+		{"unknown item", http.MethodPost, "/api/assignment-checklist", markChecklistItemRequest{AssignmentID: assignment.ID, Label: "Dishes", Done: true}, http.StatusNotFound},
+		{"missing label", http.MethodPost, "/api/assignment-checklist", markChecklistItemRequest{AssignmentID: assignment.ID, Done: true}, http.StatusBadRequest},
+		{"method not allowed", http.MethodDelete, "/api/assignment-checklist", nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			if tt.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tt.body))
+			}
+			w := httptest.NewRecorder()
+			handler.handleChecklist(w, httptest.NewRequest(tt.method, tt.url, &body))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestChecklistHandler_Unauthenticated(t *testing.T) {
+	handler, assignment, _, cleanup := setupTestChecklistHandler(t, false)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/assignment-checklist?assignment_id="+strconv.FormatInt(assignment.ID, 10), nil)
+	w := httptest.NewRecorder()
+	handler.handleChecklist(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/dates"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+)
+
+// maxChildNameLength is the longest name of a child, in characters, shown in the event titles
+const maxChildNameLength = 50
+
+// ChildrenHandler manages the children with a rotation of their own, scheduled alongside the main one
+type ChildrenHandler struct {
+	*BaseHandler
+	Scheduler       Scheduler.SchedulerInterface
+	CalendarService calendar.CalendarService
+	ConfigStore     config.ConfigStoreInterface
+}
+
+// NewChildrenHandler creates a new children handler
+func NewChildrenHandler(baseHandler *BaseHandler, sched Scheduler.SchedulerInterface, calSvc calendar.CalendarService, configStore config.ConfigStoreInterface) *ChildrenHandler {
+	return &ChildrenHandler{
+		BaseHandler:     baseHandler,
+		Scheduler:       sched,
+		CalendarService: calSvc,
+		ConfigStore:     configStore,
+	}
+}
+
+// RegisterRoutes registers the children routes
+func (h *ChildrenHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/children", h.handleChildren, http.MethodGet, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/children/{id}", h.handleDeleteChild, http.MethodDelete)
+}
+
+// ChildResponse is a child in the API responses
+type ChildResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// AddChildResponse is the response of the addition of a child
+type AddChildResponse struct {
+	Child  ChildResponse `json:"child"`
+	Synced bool          `json:"synced"` // False when the rotation could not be scheduled and synced yet, the next sync catches up
+}
+
+// DeleteChildResponse is the response of the removal of a child
+type DeleteChildResponse struct {
+	RemovedNights int  `json:"removed_nights"`
+	Synced        bool `json:"synced"` // False when calendar events of the removed nights are left
+}
+
+// addChildRequest adds a child named Name
+type addChildRequest struct {
+	Name string `json:"name"`
+}
+
+// handleChildren lists the children on GET and adds one on POST, scheduling its rotation from today
+func (h *ChildrenHandler) handleChildren(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleChildren").Str("method", r.Method).Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to children")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		children, err := h.Tracker.GetChildren(r.Context())
+		if err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to get children")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve children", handlerLogger)
+			return
+		}
+		response := make([]ChildResponse, 0, len(children))
+		for _, child := range children {
+			response = append(response, ChildResponse{ID: child.ID, Name: child.Name})
+		}
+		writeJSON(w, http.StatusOK, response, handlerLogger)
+		return
+	}
+
+	var req addChildRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, name is required", handlerLogger)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxChildNameLength {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, fmt.Sprintf("name must be 1 to %d characters", maxChildNameLength), handlerLogger)
+		return
+	}
+
+	child, err := h.Tracker.AddChild(r.Context(), name)
+	if errors.Is(err, fairness.ErrChildExists) {
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "A child already has this name", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to add child")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to add child", handlerLogger)
+		return
+	}
+
+	response := AddChildResponse{Child: ChildResponse{ID: child.ID, Name: child.Name}, Synced: true}
+	// The child is added, a failing sync is retried by the next one
+	if err := h.SyncRuns.RecordRun(r.Context(), constants.SyncTriggerSettings, func(ctx context.Context) (int, error) {
+		return h.scheduleAndSync(ctx)
+	}); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to schedule the rotation of the new child")
+		response.Synced = false
+	}
+	writeJSON(w, http.StatusCreated, response, handlerLogger)
+}
+
+// scheduleAndSync generates the schedule over the look-ahead days from today and syncs every night
+// of it: the nights of a new rotation have no event yet, unlike the ones a recalculation resyncs
+func (h *ChildrenHandler) scheduleAndSync(ctx context.Context) (int, error) {
+	_, lookAheadDays, _, _, err := h.ConfigStore.GetSchedule()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schedule configuration: %w", err)
+	}
+	today := dates.Day(time.Now())
+	assignments, err := h.Scheduler.GenerateSchedule(ctx, today, dates.AddDays(today, lookAheadDays), time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate schedule: %w", err)
+	}
+	if !h.CalendarService.IsInitialized() {
+		return 0, fmt.Errorf("calendar service not initialized - authentication required")
+	}
+	if err := h.CalendarService.SyncSchedule(ctx, assignments); err != nil {
+		return len(assignments), fmt.Errorf("failed to sync schedule: %w", err)
+	}
+	return len(assignments), nil
+}
+
+// handleDeleteChild removes a child on DELETE, with the nights of its rotation and their calendar events
+func (h *ChildrenHandler) handleDeleteChild(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleDeleteChild").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to remove a child")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid child ID", handlerLogger)
+		return
+	}
+
+	removed, err := h.Tracker.DeleteChild(r.Context(), id)
+	if errors.Is(err, fairness.ErrChildNotFound) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Child not found", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Int64("child_id", id).Msg("Failed to remove child")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to remove child", handlerLogger)
+		return
+	}
+
+	response := DeleteChildResponse{RemovedNights: len(removed), Synced: true}
+	eventIDs := make([]string, 0, len(removed))
+	for _, a := range removed {
+		if a.GoogleCalendarEventID != "" {
+			eventIDs = append(eventIDs, a.GoogleCalendarEventID)
+		}
+	}
+	if len(eventIDs) > 0 {
+		if !h.CalendarService.IsInitialized() {
+			handlerLogger.Warn().Int("event_count", len(eventIDs)).Msg("Calendar not connected, the events of the removed child are left")
+			response.Synced = false
+		} else if err := h.CalendarService.RemoveEvents(r.Context(), eventIDs); err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to remove the events of the removed child")
+			response.Synced = false
+		}
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
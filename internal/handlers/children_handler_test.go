@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestChildrenHandler(t *testing.T, authenticated bool) (*ChildrenHandler, *fairness.Tracker) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	noopCfgStore := &noopConfigStore{}
+	handler := NewChildrenHandler(baseHandler, Scheduler.New(noopCfgStore, tracker), &noopCalendarService{}, noopCfgStore)
+	return handler, tracker
+}
+
+func TestChildrenHandler(t *testing.T) {
+	handler, tracker := setupTestChildrenHandler(t, true)
+
+	add := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.handleChildren(w, httptest.NewRequest(http.MethodPost, "/api/v1/children", strings.NewReader(body)))
+		return w
+	}
+	remove := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/children/"+id, nil)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.handleDeleteChild(w, req)
+		return w
+	}
+	list := func() []ChildResponse {
+		w := httptest.NewRecorder()
+		handler.handleChildren(w, httptest.NewRequest(http.MethodGet, "/api/v1/children", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var children []ChildResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &children))
+		return children
+	}
+
+	assert.Empty(t, list())
+	assert.Equal(t, http.StatusBadRequest, add(`{"name":"  "}`).Code, "name is required")
+	assert.Equal(t, http.StatusBadRequest, add(`{"name":"`+strings.Repeat("a", maxChildNameLength+1)+`"}`).Code, "name too long")
+
+	w := add(`{"name":" Emma "}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var added AddChildResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &added))
+	assert.Equal(t, "Emma", added.Child.Name)
+	assert.True(t, added.Synced)
+	assert.Equal(t, []ChildResponse{added.Child}, list())
+	assert.Equal(t, http.StatusConflict, add(`{"name":"Emma"}`).Code)
+
+	// The rotation of the child is scheduled right away, apart from the main one
+	emmaNights, err := tracker.ForChild(fairness.Child{ID: added.Child.ID, Name: "Emma"}).GetLastAssignmentDate(t.Context())
+	require.NoError(t, err)
+	assert.False(t, emmaNights.IsZero())
+
+	id := strconv.FormatInt(added.Child.ID, 10)
+	assert.Equal(t, http.StatusBadRequest, remove("abc").Code)
+	w = remove(id)
+	require.Equal(t, http.StatusOK, w.Code)
+	var removed DeleteChildResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &removed))
+	assert.Positive(t, removed.RemovedNights)
+	assert.Equal(t, http.StatusNotFound, remove(id).Code)
+	assert.Empty(t, list())
+}
+
+func TestChildrenHandler_Unauthenticated(t *testing.T) {
+	handler, _ := setupTestChildrenHandler(t, false)
+
+	w := httptest.NewRecorder()
+	handler.handleChildren(w, httptest.NewRequest(http.MethodPost, "/api/v1/children", strings.NewReader(`{"name":"Emma"}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/children/1", nil)
+	req.SetPathValue("id", "1")
+	w = httptest.NewRecorder()
+	handler.handleDeleteChild(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// Errors of claimTonight mapped to client errors
+var (
+	errUnknownParent       = errors.New("parent is not one of the configured parents")
+	errTonightNotScheduled = errors.New("tonight is not scheduled")
+)
+
+// Notifier delivers a notification event, implemented by notify.Service
+type Notifier interface {
+	Send(ctx context.Context, event notify.Event, data any) error
+}
+
+// ClaimHandler lets a parent take tonight in one tap
+type ClaimHandler struct {
+	*BaseHandler
+	Tracker         fairness.TrackerInterface
+	Scheduler       Scheduler.SchedulerInterface
+	CalendarService calendar.CalendarService
+	ConfigStore     config.ConfigStoreInterface
+	notifier        Notifier
+	now             func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewClaimHandler creates a new handler claiming tonight, telling the other parent through notifier
+func NewClaimHandler(baseHandler *BaseHandler, tracker fairness.TrackerInterface, sched Scheduler.SchedulerInterface, calSvc calendar.CalendarService, configStore config.ConfigStoreInterface, notifier Notifier) *ClaimHandler {
+	return &ClaimHandler{
+		BaseHandler:     baseHandler,
+		Tracker:         tracker,
+		Scheduler:       sched,
+		CalendarService: calSvc,
+		ConfigStore:     configStore,
+		notifier:        notifier,
+		now:             time.Now,
+	}
+}
+
+// RegisterRoutes registers the claim routes
+func (h *ClaimHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/assignments/tonight/claim", h.handleClaimTonight, http.MethodPost)
+}
+
+// ClaimTonightRequest is the JSON body of a claim of tonight
+type ClaimTonightRequest struct {
+	Parent  string `json:"parent"`            // Parent taking tonight, one of the configured parents
+	Version int64  `json:"version,omitempty"` // Version of tonight's assignment the claim is based on; the current one when omitted
+}
+
+// ClaimTonightResponse is the JSON response of a claim of tonight
+type ClaimTonightResponse struct {
+	AssignmentID      int64  `json:"assignment_id"`
+	Date              string `json:"date"`
+	Parent            string `json:"parent"`
+	PreviousCaregiver string `json:"previous_caregiver"`
+	Changed           bool   `json:"changed"` // False when the parent was already on duty
+}
+
+// handleClaimTonight assigns tonight to the parent of the request body as an override, syncs the
+// calendar and notifies the other parent. The application has no user accounts, so the caller names
+// themselves; the name must be one of the configured parents.
+func (h *ClaimHandler) handleClaimTonight(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleClaimTonight").Logger()
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var req ClaimTonightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Parent == "" {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, parent is required", handlerLogger)
+		return
+	}
+
+	now := h.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	response, err := h.claimTonight(r.Context(), handlerLogger, today, req.Parent, req.Version)
+	switch {
+	case errors.Is(err, errUnknownParent):
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "parent must be one of the configured parents", handlerLogger)
+		return
+	case errors.Is(err, errTonightNotScheduled):
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Tonight is not scheduled", handlerLogger)
+		return
+	case errors.Is(err, fairness.ErrAssignmentConflict):
+		handlerLogger.Warn().Err(err).Str("parent", req.Parent).Msg("Tonight changed before the claim")
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "Tonight was changed in the meantime, reload it and try again", handlerLogger)
+		return
+	case err != nil:
+		handlerLogger.Error().Err(err).Str("parent", req.Parent).Msg("Failed to claim tonight")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to claim tonight", handlerLogger)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// otherParent returns the parent following parent in the roster, wrapping around: the other
+// parent when there are two
+func (h *ClaimHandler) otherParent(parent string) (string, error) {
+	roster, err := config.Roster(h.ConfigStore)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parents: %w", err)
+	}
+	index := slices.Index(roster, parent)
+	if index < 0 {
+		return "", errUnknownParent
+	}
+	return roster[(index+1)%len(roster)], nil
+}
+
+// claimTonight assigns the night of today to parent as an override, recalculates and syncs the
+// schedule, and notifies the other parent. Nothing changes when parent is already on duty. A non-zero
+// version is the version of the assignment the claim is based on; fairness.ErrAssignmentConflict is
+// returned when the assignment changed since.
+func (h *ClaimHandler) claimTonight(ctx context.Context, logger zerolog.Logger, today time.Time, parent string, version int64) (ClaimTonightResponse, error) {
+	otherParent, err := h.otherParent(parent)
+	if err != nil {
+		return ClaimTonightResponse{}, err
+	}
+	logger = logger.With().Str("parent", parent).Str("date", today.Format(time.DateOnly)).Logger()
+
+	assignment, err := h.Tracker.GetAssignmentByDate(ctx, today)
+	if err != nil {
+		return ClaimTonightResponse{}, fmt.Errorf("failed to get tonight's assignment: %w", err)
+	}
+	if assignment == nil {
+		return ClaimTonightResponse{}, errTonightNotScheduled
+	}
+
+	response := ClaimTonightResponse{
+		AssignmentID:      assignment.ID,
+		Date:              today.Format(time.DateOnly),
+		Parent:            parent,
+		PreviousCaregiver: assignment.Parent,
+	}
+	if assignment.Parent == parent && assignment.CaregiverType == fairness.CaregiverTypeParent {
+		logger.Debug().Msg("Parent already on duty tonight")
+		return response, nil
+	}
+	if assignment.CaregiverType == fairness.CaregiverTypeParent {
+		// With more than two parents, the parent taken off tonight is the one told
+		otherParent = assignment.Parent
+	}
+
+	if version == 0 {
+		version = assignment.Version
+	}
+	// The override and the recalculation following it are undone together
+	ctx = h.Tracker.BeginBatch(ctx, fairness.ChangeKindOverride)
+	if err := h.Tracker.UpdateAssignmentParent(ctx, assignment.ID, parent, true, version); err != nil {
+		return ClaimTonightResponse{}, fmt.Errorf("failed to update assignment %d: %w", assignment.ID, err)
+	}
+	response.Changed = true
+	logger.Info().Int64("assignment_id", assignment.ID).Str("previous_caregiver", assignment.Parent).Msg("Tonight claimed")
+
+	// The override is recorded, a failing sync is retried by the next one
+	if err := recalculateScheduleAndSync(ctx, h.logger, h.SyncRuns, constants.SyncTriggerAssignment, h.Tracker, h.Scheduler, h.CalendarService, h.ConfigStore, today); err != nil {
+		logger.Error().Err(err).Msg("Failed to recalculate schedule after claiming tonight")
+	}
+
+	if h.notifier != nil {
+		if err := h.notifier.Send(ctx, notify.EventTonightClaimed, notify.TonightClaimedData{
+			Parent:            parent,
+			OtherParent:       otherParent,
+			Date:              today.Format("Monday 2 January"),
+			PreviousCaregiver: assignment.Parent,
+		}); err != nil {
+			logger.Warn().Err(err).Msg("Failed to notify the other parent")
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// recordingNotifier records the events sent through it
+type recordingNotifier struct {
+	events []notify.Event
+	data   []any
+	err    error
+}
+
+func (n *recordingNotifier) Send(_ context.Context, event notify.Event, data any) error {
+	n.events = append(n.events, event)
+	n.data = append(n.data, data)
+	return n.err
+}
+
+func setupTestClaimHandler(t *testing.T, authenticated bool) (*ClaimHandler, *fairness.Tracker, *recordingNotifier) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	noopCfgStore := &noopConfigStore{}
+	notifier := &recordingNotifier{}
+	handler := NewClaimHandler(baseHandler, tracker, Scheduler.New(noopCfgStore, tracker), &noopCalendarService{}, noopCfgStore, notifier)
+	return handler, tracker, notifier
+}
+
+func claimTonight(handler *ClaimHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/assignments/tonight/claim", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.handleClaimTonight(w, req)
+	return w
+}
+
+func TestClaimHandler_Success(t *testing.T) {
+	handler, tracker, notifier := setupTestClaimHandler(t, true)
+	now := time.Now()
+	handler.now = func() time.Time { return now }
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentB", today, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	w := claimTonight(handler, `{"parent":"ParentA"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response ClaimTonightResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, ClaimTonightResponse{
+		AssignmentID:      assignment.ID,
+		Date:              today.Format(time.DateOnly),
+		Parent:            "ParentA",
+		PreviousCaregiver: "ParentB",
+		Changed:           true,
+	}, response)
+
+	updated, err := tracker.GetAssignmentByDate(t.Context(), today)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, "ParentA", updated.Parent)
+	assert.True(t, updated.Override)
+	assert.Equal(t, fairness.DecisionReasonOverride, updated.DecisionReason)
+
+	require.Equal(t, []notify.Event{notify.EventTonightClaimed}, notifier.events)
+	assert.Equal(t, notify.TonightClaimedData{
+		Parent:            "ParentA",
+		OtherParent:       "ParentB",
+		Date:              today.Format("Monday 2 January"),
+		PreviousCaregiver: "ParentB",
+	}, notifier.data[0])
+}
+
+func TestClaimHandler_AlreadyOnDuty(t *testing.T) {
+	handler, tracker, notifier := setupTestClaimHandler(t, true)
+
+	_, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Now(), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	w := claimTonight(handler, `{"parent":"ParentA"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response ClaimTonightResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Changed)
+	assert.Empty(t, notifier.events, "nothing changed, nobody is notified")
+
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.False(t, assignment.Override, "the assignment is not turned into an override")
+}
+
+func TestClaimHandler_FromBabysitter(t *testing.T) {
+	handler, tracker, notifier := setupTestClaimHandler(t, true)
+
+	_, err := tracker.RecordBabysitterAssignment(t.Context(), "Grandma", time.Now(), true)
+	require.NoError(t, err)
+
+	w := claimTonight(handler, `{"parent":"ParentA"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "ParentA", assignment.Parent)
+	assert.Equal(t, fairness.CaregiverTypeParent, assignment.CaregiverType)
+	require.Len(t, notifier.data, 1)
+	assert.Equal(t, "Grandma", notifier.data[0].(notify.TonightClaimedData).PreviousCaregiver)
+}
+
+func TestClaimHandler_NotifierFailureStillClaims(t *testing.T) {
+	handler, tracker, notifier := setupTestClaimHandler(t, true)
+	notifier.err = errors.New("slack down")
+
+	_, err := tracker.RecordAssignment(t.Context(), "ParentB", time.Now(), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	w := claimTonight(handler, `{"parent":"ParentA"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "ParentA", assignment.Parent)
+}
+
+func TestClaimHandler_StaleVersionConflicts(t *testing.T) {
+	handler, tracker, notifier := setupTestClaimHandler(t, true)
+
+	read, err := tracker.RecordAssignment(t.Context(), "ParentB", time.Now(), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	// A regeneration changes tonight after the client read it
+	_, err = tracker.RecordAssignment(t.Context(), "ParentB", time.Now(), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	w := claimTonight(handler, fmt.Sprintf(`{"parent":"ParentA","version":%d}`, read.Version))
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Empty(t, notifier.events)
+
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "ParentB", assignment.Parent)
+	assert.False(t, assignment.Override)
+}
+
+func TestClaimHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticated bool
+		method        string
+		body          string
+		scheduled     bool
+		wantStatus    int
+	}{
+		{name: "wrong method", authenticated: true, method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "unauthenticated", method: http.MethodPost, body: `{"parent":"ParentA"}`, scheduled: true, wantStatus: http.StatusUnauthorized},
+		{name: "invalid body", authenticated: true, method: http.MethodPost, body: `{`, scheduled: true, wantStatus: http.StatusBadRequest},
+		{name: "missing parent", authenticated: true, method: http.MethodPost, body: `{}`, scheduled: true, wantStatus: http.StatusBadRequest},
+		{name: "unknown parent", authenticated: true, method: http.MethodPost, body: `{"parent":"Mallory"}`, scheduled: true, wantStatus: http.StatusBadRequest},
+		{name: "tonight not scheduled", authenticated: true, method: http.MethodPost, body: `{"parent":"ParentA"}`, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, tracker, notifier := setupTestClaimHandler(t, tt.authenticated)
+			if tt.scheduled {
+				_, err := tracker.RecordAssignment(t.Context(), "ParentB", time.Now(), false, fairness.DecisionReasonAlternating)
+				require.NoError(t, err)
+			}
+
+			req := httptest.NewRequest(tt.method, "/api/assignments/tonight/claim", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.handleClaimTonight(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusMethodNotAllowed {
+				assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+			}
+			assert.Empty(t, notifier.events)
+		})
+	}
+}
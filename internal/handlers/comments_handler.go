@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+)
+
+// CommentsHandler serves the comments left on the assignments
+type CommentsHandler struct {
+	*BaseHandler
+	comments *database.CommentStore
+}
+
+// NewCommentsHandler creates a new comments handler
+func NewCommentsHandler(baseHandler *BaseHandler, comments *database.CommentStore) *CommentsHandler {
+	return &CommentsHandler{BaseHandler: baseHandler, comments: comments}
+}
+
+// RegisterRoutes registers the comment routes
+func (h *CommentsHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/assignment-comments", h.handleComments, http.MethodGet, http.MethodPost, http.MethodDelete)
+}
+
+// CommentResponse is a comment in the API response
+type CommentResponse struct {
+	ID        int64     `json:"id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentsResponse is the comments of an assignment, oldest first
+type CommentsResponse struct {
+	AssignmentID int64             `json:"assignment_id"`
+	Comments     []CommentResponse `json:"comments"`
+}
+
+// addCommentRequest adds a comment to an assignment
+type addCommentRequest struct {
+	AssignmentID int64  `json:"assignment_id"`
+	Author       string `json:"author"`
+	Text         string `json:"text"`
+}
+
+// handleComments returns the comments of an assignment on GET, adds one on POST and deletes one on
+// DELETE. Every method answers with the resulting comments.
+func (h *CommentsHandler) handleComments(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleComments").Str("method", r.Method).Logger()
+	handlerLogger.Debug().Msg("Handling comments request")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to comments")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	status := http.StatusOK
+	var assignmentID int64
+	switch r.Method {
+	case http.MethodGet, http.MethodDelete:
+		var err error
+		assignmentID, err = strconv.ParseInt(r.URL.Query().Get("assignment_id"), 10, 64)
+		if err != nil || assignmentID <= 0 {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Missing or invalid assignment_id parameter", handlerLogger)
+			return
+		}
+		if !h.assignmentExists(r.Context(), w, assignmentID, handlerLogger) {
+			return
+		}
+		if r.Method == http.MethodGet {
+			break
+		}
+
+		commentID, err := strconv.ParseInt(r.URL.Query().Get("comment_id"), 10, 64)
+		if err != nil || commentID <= 0 {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Missing or invalid comment_id parameter", handlerLogger)
+			return
+		}
+		if err := h.comments.DeleteComment(assignmentID, commentID); err != nil {
+			if errors.Is(err, database.ErrCommentNotFound) {
+				writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Comment not found", handlerLogger)
+				return
+			}
+			handlerLogger.Error().Err(err).Int64("assignment_id", assignmentID).Int64("comment_id", commentID).Msg("Failed to delete comment")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to delete comment", handlerLogger)
+			return
+		}
+		handlerLogger.Info().Int64("assignment_id", assignmentID).Int64("comment_id", commentID).Msg("Comment deleted")
+
+	case http.MethodPost:
+		var req addCommentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AssignmentID <= 0 {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, assignment_id, author and text are required", handlerLogger)
+			return
+		}
+		assignmentID = req.AssignmentID
+		if !h.assignmentExists(r.Context(), w, assignmentID, handlerLogger) {
+			return
+		}
+		comment, err := h.comments.AddComment(assignmentID, req.Author, req.Text)
+		if err != nil {
+			if errors.Is(err, database.ErrInvalidComment) {
+				writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+				return
+			}
+			handlerLogger.Error().Err(err).Int64("assignment_id", assignmentID).Msg("Failed to add comment")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to add comment", handlerLogger)
+			return
+		}
+		handlerLogger.Info().Int64("assignment_id", assignmentID).Int64("comment_id", comment.ID).Str("author", comment.Author).Msg("Comment added")
+		status = http.StatusCreated
+	}
+
+	comments, err := h.comments.ListComments(assignmentID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Int64("assignment_id", assignmentID).Msg("Failed to list comments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve comments", handlerLogger)
+		return
+	}
+
+	response := CommentsResponse{AssignmentID: assignmentID, Comments: make([]CommentResponse, 0, len(comments))}
+	for _, comment := range comments {
+		response.Comments = append(response.Comments, CommentResponse{
+			ID:        comment.ID,
+			Author:    comment.Author,
+			Text:      comment.Text,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+	writeJSON(w, status, response, handlerLogger)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestCommentsHandler(t *testing.T, authenticated bool) (*CommentsHandler, *fairness.Assignment, func()) {
+	detailsHandler, tracker, db, cleanup := setupTestAssignmentDetailsHandler(t, authenticated)
+
+	assignment, err := tracker.RecordAssignment(t.Context(), "Alice", time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	comments, err := database.NewCommentStore(db)
+	require.NoError(t, err)
+
+	handler := NewCommentsHandler(detailsHandler.BaseHandler, comments)
+	return handler, assignment, cleanup
+}
+
+func postComment(t *testing.T, handler *CommentsHandler, req addCommentRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	handler.handleComments(w, httptest.NewRequest(http.MethodPost, "/api/assignment-comments", bytes.NewReader(body)))
+	return w
+}
+
+func decodeCommentsResponse(t *testing.T, w *httptest.ResponseRecorder) CommentsResponse {
+	t.Helper()
+	var response CommentsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	return response
+}
+
+func TestCommentsHandler_AddListDelete(t *testing.T) {
+	handler, assignment, cleanup := setupTestCommentsHandler(t, true)
+	defer cleanup()
+
+	w := postComment(t, handler, addCommentRequest{AssignmentID: assignment.ID, Author: "Alice", Text: "Fell asleep early"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	response := decodeCommentsResponse(t, w)
+	require.Len(t, response.Comments, 1)
+	assert.Equal(t, "Alice", response.Comments[0].Author)
+	assert.Equal(t, "Fell asleep early", response.Comments[0].Text)
+	assert.False(t, response.Comments[0].CreatedAt.IsZero())
+
+	w = postComment(t, handler, addCommentRequest{AssignmentID: assignment.ID, Author: "Bob", Text: "Teething"})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.handleComments(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/assignment-comments?assignment_id=%d", assignment.ID), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	response = decodeCommentsResponse(t, w)
+	assert.Equal(t, assignment.ID, response.AssignmentID)
+	require.Len(t, response.Comments, 2)
+
+	w = httptest.NewRecorder()
+	handler.handleComments(w, httptest.NewRequest(http.MethodDelete,
+		fmt.Sprintf("/api/assignment-comments?assignment_id=%d&comment_id=%d", assignment.ID, response.Comments[0].ID), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	response = decodeCommentsResponse(t, w)
+	require.Len(t, response.Comments, 1)
+	assert.Equal(t, "Bob", response.Comments[0].Author)
+}
+
+func TestCommentsHandler_Errors(t *testing.T) {
+	handler, assignment, cleanup := setupTestCommentsHandler(t, true)
+	defer cleanup()
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+	}{
+		{"missing assignment id", http.MethodGet, "/api/assignment-comments", "", http.StatusBadRequest},
+		{"unknown assignment", http.MethodGet, "/api/assignment-comments?assignment_id=9999", "", http.StatusNotFound},
+		{"invalid body", http.MethodPost, "/api/assignment-comments", "{", http.StatusBadRequest},
+		{"missing text", http.MethodPost, "/api/assignment-comments", fmt.Sprintf(`{"assignment_id":%d,"author":"Alice"}`, assignment.ID), http.StatusBadRequest},
+		{"text too long", http.MethodPost, "/api/assignment-comments",
+			fmt.Sprintf(`{"assignment_id":%d,"author":"Alice","text":%q}`, assignment.ID, strings.Repeat("a", database.MaxCommentTextRunes+1)), http.StatusBadRequest},
+		{"missing comment id", http.MethodDelete, fmt.Sprintf("/api/assignment-comments?assignment_id=%d", assignment.ID), "", http.StatusBadRequest},
+		{"unknown comment", http.MethodDelete, fmt.Sprintf("/api/assignment-comments?assignment_id=%d&comment_id=42", assignment.ID), "", http.StatusNotFound},
+		{"method not allowed", http.MethodPut, "/api/assignment-comments", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleComments(w, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestCommentsHandler_Unauthenticated(t *testing.T) {
+	handler, assignment, cleanup := setupTestCommentsHandler(t, false)
+	defer cleanup()
+
+	w := postComment(t, handler, addCommentRequest{AssignmentID: assignment.ID, Author: "Alice", Text: "Note"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// templatesVersion hashes the embedded templates and the assets they link to, so that an
+// upgrade changing them changes the ETags of the rendered pages
+var templatesVersion = sync.OnceValue(func() string {
+	hash := sha256.New()
+	if assets, err := loadStaticAssets(); err == nil {
+		for _, name := range staticAssetNames {
+			hash.Write([]byte(assets[name].etag))
+		}
+	}
+	_ = fs.WalkDir(templateFS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := templateFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		hash.Write([]byte(path))
+		hash.Write(content)
+		return nil
+	})
+	return hex.EncodeToString(hash.Sum(nil))
+})
+
+// weakETag returns a weak ETag identifying the given parts. Rendered pages are only
+// semantically equivalent for the same parts, hence the weak validator.
+func weakETag(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+}
+
+// etagMatches reports whether the If-None-Match header lists etag, using the weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range parseETags(ifNoneMatch) {
+		if strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified sets the validators of a response and answers 304 Not Modified when the
+// client already holds this version. It returns true when the response was written.
+// If-None-Match takes precedence over If-Modified-Since; a zero lastModified is not sent.
+// The response must be revalidated on every use, it is never served stale from the cache.
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	notModified := false
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		notModified = etagMatches(ifNoneMatch, etag)
+	} else if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		notModified = err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	if !notModified {
+		return false
+	}
+
+	// A 304 carries no body, nor the headers describing it
+	w.Header().Del("Content-Type")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeakETag(t *testing.T) {
+	etag := weakETag("a", "b")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, etag, weakETag("a", "b"))
+	assert.NotEqual(t, etag, weakETag("ab", ""), "parts are delimited")
+}
+
+func TestEtagMatches(t *testing.T) {
+	etag := `W/"abc"`
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "same weak tag", ifNoneMatch: `W/"abc"`, want: true},
+		{name: "strong form of the tag", ifNoneMatch: `"abc"`, want: true},
+		{name: "in a list", ifNoneMatch: `"old", W/"abc"`, want: true},
+		{name: "wildcard", ifNoneMatch: "*", want: true},
+		{name: "other tag", ifNoneMatch: `W/"def"`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, etagMatches(tt.ifNoneMatch, etag))
+		})
+	}
+}
+
+func TestWriteNotModified(t *testing.T) {
+	etag := `W/"abc"`
+	lastModified := time.Date(2026, 10, 16, 12, 30, 15, 500, time.UTC)
+
+	tests := []struct {
+		name         string
+		method       string
+		headers      map[string]string
+		lastModified time.Time
+		want         bool
+	}{
+		{name: "no validators", method: http.MethodGet, lastModified: lastModified, want: false},
+		{name: "matching ETag", method: http.MethodGet, headers: map[string]string{"If-None-Match": etag}, lastModified: lastModified, want: true},
+		{name: "matching ETag on HEAD", method: http.MethodHead, headers: map[string]string{"If-None-Match": etag}, lastModified: lastModified, want: true},
+		{name: "matching ETag on POST", method: http.MethodPost, headers: map[string]string{"If-None-Match": etag}, lastModified: lastModified, want: false},
+		{name: "stale ETag", method: http.MethodGet, headers: map[string]string{"If-None-Match": `W/"old"`}, lastModified: lastModified, want: false},
+		{
+			name:         "ETag takes precedence over date",
+			method:       http.MethodGet,
+			headers:      map[string]string{"If-None-Match": `W/"old"`, "If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			lastModified: lastModified,
+			want:         false,
+		},
+		{name: "not modified since", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, lastModified: lastModified, want: true},
+		{name: "modified since", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": lastModified.Add(-time.Minute).Format(http.TimeFormat)}, lastModified: lastModified, want: false},
+		{name: "date without last modification", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, want: false},
+		{name: "invalid date", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": "yesterday"}, lastModified: lastModified, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+
+			got := writeNotModified(w, r, etag, tt.lastModified)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+			if tt.lastModified.IsZero() {
+				assert.Empty(t, w.Header().Get("Last-Modified"))
+			} else {
+				assert.Equal(t, "Fri, 16 Oct 2026 12:30:15 GMT", w.Header().Get("Last-Modified"))
+			}
+			if tt.want {
+				assert.Equal(t, http.StatusNotModified, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/rs/zerolog"
+)
+
+// DayHandler returns everything known about one night, for the detail drawer of the web interface
+type DayHandler struct {
+	*BaseHandler
+	checklists *database.ChecklistStore
+	comments   *database.CommentStore
+}
+
+// NewDayHandler creates a new day detail handler
+func NewDayHandler(baseHandler *BaseHandler, checklists *database.ChecklistStore, comments *database.CommentStore) *DayHandler {
+	return &DayHandler{BaseHandler: baseHandler, checklists: checklists, comments: comments}
+}
+
+// RegisterRoutes registers the day detail routes
+func (h *DayHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/days/{date}", h.handleDay, http.MethodGet)
+}
+
+// DayAssignmentResponse is the assignment of the day in the day detail API response
+type DayAssignmentResponse struct {
+	UpcomingAssignmentResponse
+	Tag     string `json:"tag,omitempty"`
+	Version int64  `json:"version"` // Precondition to send back with a change of the assignment
+}
+
+// DayExplanationResponse explains why the caregiver of the day was chosen
+type DayExplanationResponse struct {
+	Summary           string `json:"summary"`
+	CalculationDate   string `json:"calculation_date,omitempty"`
+	ParentAName       string `json:"parent_a_name,omitempty"`
+	ParentATotalCount int    `json:"parent_a_total_count"`
+	ParentALast30Days int    `json:"parent_a_last_30_days"`
+	ParentBName       string `json:"parent_b_name,omitempty"`
+	ParentBTotalCount int    `json:"parent_b_total_count"`
+	ParentBLast30Days int    `json:"parent_b_last_30_days"`
+	// Counts of the parents of the roster after parent A and parent B, omitted without any
+	ExtraParents []ParentCountsResponse `json:"extra_parents,omitempty"`
+}
+
+// ParentCountsResponse holds the counts of an extra parent of the roster when a night was decided
+type ParentCountsResponse struct {
+	Name       string `json:"name"`
+	TotalCount int    `json:"total_count"`
+	Last30Days int    `json:"last_30_days"`
+}
+
+// newParentCounts returns the counts of the extra parents of details, nil without any
+func newParentCounts(details *fairness.AssignmentDetails) []ParentCountsResponse {
+	var counts []ParentCountsResponse
+	for _, parent := range details.ExtraParents {
+		counts = append(counts, ParentCountsResponse{Name: parent.Name, TotalCount: parent.Stats.TotalAssignments, Last30Days: parent.Stats.Last30Days})
+	}
+	return counts
+}
+
+// compareCounts returns the count of caregiver and the lowest count of the other parents of details
+func compareCounts(details *fairness.AssignmentDetails, caregiver string, count func(fairness.Stats) int) (own, other int) {
+	parents := append([]fairness.NamedStats{
+		{Name: details.ParentAName, Stats: fairness.Stats{TotalAssignments: details.ParentATotalCount, Last30Days: details.ParentALast30Days}},
+		{Name: details.ParentBName, Stats: fairness.Stats{TotalAssignments: details.ParentBTotalCount, Last30Days: details.ParentBLast30Days}},
+	}, details.ExtraParents...)
+	first := true
+	for _, parent := range parents {
+		switch {
+		case parent.Name == caregiver:
+			own = count(parent.Stats)
+		case first || count(parent.Stats) < other:
+			other, first = count(parent.Stats), false
+		}
+	}
+	return own, other
+}
+
+// DayChangeResponse is a change of the assignment, with the caregiver it replaced
+type DayChangeResponse struct {
+	Kind              string    `json:"kind"`
+	ChangedAt         time.Time `json:"changed_at"`
+	Undone            bool      `json:"undone"`
+	PreviousCaregiver string    `json:"previous_caregiver"`
+	PreviousType      string    `json:"previous_caregiver_type"`
+	PreviousOverride  bool      `json:"previous_override"`
+	PreviousReason    string    `json:"previous_decision_reason,omitempty"`
+}
+
+// DaySyncResponse is the Google Calendar state of the day
+type DaySyncResponse struct {
+	Synced   bool             `json:"synced"`
+	EventID  string           `json:"event_id,omitempty"`
+	LastSync *SyncRunResponse `json:"last_sync"` // null before the first sync
+}
+
+// DayResponse is the response of the day detail API
+type DayResponse struct {
+	Date        string                  `json:"date"`
+	Weekday     string                  `json:"weekday"`
+	Unscheduled string                  `json:"unscheduled,omitempty"` // "vacation" or "skip_date" for a day without night routine
+	Locked      bool                    `json:"locked"`                // Within a locked range, kept by the regeneration
+	Assignment  *DayAssignmentResponse  `json:"assignment"`            // null when the day has no assignment
+	Explanation *DayExplanationResponse `json:"explanation"`
+	History     []DayChangeResponse     `json:"history"`
+	Comments    []CommentResponse       `json:"comments"`
+	Checklist   []ChecklistItemResponse `json:"checklist"`
+	Sync        DaySyncResponse         `json:"sync"`
+}
+
+// handleDay returns the assignment of a date with its decision explanation, change history,
+// comments, checklist and sync status. The date is YYYY-MM-DD.
+func (h *DayHandler) handleDay(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleDay").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to day details")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", r.PathValue("date"), time.Local)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "date must be formatted as YYYY-MM-DD", handlerLogger)
+		return
+	}
+	handlerLogger = handlerLogger.With().Str("date", date.Format("2006-01-02")).Logger()
+
+	assignment, err := h.Tracker.GetAssignmentByDate(r.Context(), date)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get assignment of the day")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment", handlerLogger)
+		return
+	}
+
+	response := DayResponse{
+		Date:        date.Format("2006-01-02"),
+		Weekday:     date.Weekday().String(),
+		Unscheduled: h.unscheduledReason(date, handlerLogger),
+		Locked:      h.isLocked(r.Context(), date, handlerLogger),
+		History:     []DayChangeResponse{},
+		Comments:    []CommentResponse{},
+		Checklist:   []ChecklistItemResponse{},
+	}
+	if h.SyncRuns != nil {
+		runs, err := h.SyncRuns.ListRuns(1)
+		if err != nil {
+			handlerLogger.Warn().Err(err).Msg("Failed to get the latest sync run")
+		} else if len(runs) > 0 {
+			lastSync := newSyncRunResponse(runs[0])
+			response.Sync.LastSync = &lastSync
+		}
+	}
+	if assignment == nil {
+		writeJSON(w, http.StatusOK, response, handlerLogger)
+		return
+	}
+
+	response.Assignment = &DayAssignmentResponse{
+		UpcomingAssignmentResponse: UpcomingAssignmentResponse{
+			ID:             assignment.ID,
+			Caregiver:      assignment.Parent,
+			CaregiverType:  assignment.CaregiverType.String(),
+			Override:       assignment.Override,
+			DecisionReason: assignment.DecisionReason.String(),
+			Synced:         assignment.GoogleCalendarEventID != "",
+		},
+		Tag:     assignment.Tag.String(),
+		Version: assignment.Version,
+	}
+	if style, ok := h.parentStyles(handlerLogger)[assignment.Parent]; ok && assignment.CaregiverType == fairness.CaregiverTypeParent {
+		response.Assignment.Color = style.Color.Hex()
+		response.Assignment.Avatar = style.Badge(assignment.Parent)
+	}
+	response.Sync.Synced = assignment.GoogleCalendarEventID != ""
+	response.Sync.EventID = assignment.GoogleCalendarEventID
+
+	details, err := h.Tracker.GetAssignmentDetails(r.Context(), assignment.ID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Int64("assignment_id", assignment.ID).Msg("Failed to get assignment details")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment details", handlerLogger)
+		return
+	}
+	response.Explanation = newDayExplanation(assignment, details)
+
+	changes, err := h.Tracker.GetAssignmentChanges(r.Context(), assignment.ID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Int64("assignment_id", assignment.ID).Msg("Failed to get assignment changes")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve assignment history", handlerLogger)
+		return
+	}
+	for _, change := range changes {
+		response.History = append(response.History, DayChangeResponse{
+			Kind:              change.Kind.String(),
+			ChangedAt:         change.ChangedAt,
+			Undone:            change.Undone,
+			PreviousCaregiver: change.Parent,
+			PreviousType:      change.CaregiverType.String(),
+			PreviousOverride:  change.Override,
+			PreviousReason:    change.DecisionReason.String(),
+		})
+	}
+
+	comments, err := h.comments.ListComments(assignment.ID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Int64("assignment_id", assignment.ID).Msg("Failed to list comments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve comments", handlerLogger)
+		return
+	}
+	for _, comment := range comments {
+		response.Comments = append(response.Comments, CommentResponse{
+			ID:        comment.ID,
+			Author:    comment.Author,
+			Text:      comment.Text,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	items, err := h.checklists.GetChecklist(assignment.ID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Int64("assignment_id", assignment.ID).Msg("Failed to get checklist")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve checklist", handlerLogger)
+		return
+	}
+	for _, item := range items {
+		response.Checklist = append(response.Checklist, ChecklistItemResponse{
+			Label:       item.Label,
+			Done:        item.Done(),
+			CompletedAt: item.CompletedAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// unscheduledReason returns "vacation" or "skip_date" when no night routine is scheduled on date.
+// Errors are logged and leave the reason empty.
+func (h *DayHandler) unscheduledReason(date time.Time, logger zerolog.Logger) string {
+	vacation, err := h.ConfigStore.GetVacation()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get vacation")
+	} else if vacation.Contains(date) {
+		return "vacation"
+	}
+	skipDates, err := h.ConfigStore.GetSkipDates()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get skip dates")
+	} else if skipDates.Contains(date) {
+		return "skip_date"
+	}
+	return ""
+}
+
+// isLocked reports whether date is within a locked range; a failure to read them is logged and
+// reported as not locked
+func (h *DayHandler) isLocked(ctx context.Context, date time.Time, logger zerolog.Logger) bool {
+	ranges, err := h.Tracker.GetLockedRanges(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get locked ranges")
+		return false
+	}
+	return ranges.Contains(date)
+}
+
+// newDayExplanation explains the decision of assignment in plain English, with the fairness
+// snapshot taken when it was decided; details is nil for the nights decided without one.
+func newDayExplanation(assignment *fairness.Assignment, details *fairness.AssignmentDetails) *DayExplanationResponse {
+	explanation := &DayExplanationResponse{}
+	if details != nil {
+		explanation.CalculationDate = details.CalculationDate.Format("2006-01-02")
+		explanation.ParentAName = details.ParentAName
+		explanation.ParentATotalCount = details.ParentATotalCount
+		explanation.ParentALast30Days = details.ParentALast30Days
+		explanation.ParentBName = details.ParentBName
+		explanation.ParentBTotalCount = details.ParentBTotalCount
+		explanation.ParentBLast30Days = details.ParentBLast30Days
+		explanation.ExtraParents = newParentCounts(details)
+	}
+	otherParent := "the other parent was"
+	if details != nil && len(details.ExtraParents) > 0 {
+		otherParent = "the other parents were"
+	}
+
+	caregiver := assignment.Parent
+	switch {
+	case assignment.CaregiverType == fairness.CaregiverTypeBabysitter:
+		explanation.Summary = fmt.Sprintf("%s babysits, set by hand.", caregiver)
+	case assignment.DecisionReason == fairness.DecisionReasonOverride:
+		explanation.Summary = fmt.Sprintf("%s was set by hand and is locked.", caregiver)
+	case assignment.DecisionReason == fairness.DecisionReasonSwap:
+		explanation.Summary = fmt.Sprintf("%s took this night in a swap agreed by both parents, and it is locked.", caregiver)
+	case assignment.DecisionReason == fairness.DecisionReasonUnavailability:
+		explanation.Summary = fmt.Sprintf("%s was assigned because %s unavailable.", caregiver, otherParent)
+	case assignment.DecisionReason == fairness.DecisionReasonTotalCount && details != nil:
+		own, other := compareCounts(details, caregiver, func(stats fairness.Stats) int { return stats.TotalAssignments })
+		explanation.Summary = fmt.Sprintf("%s had fewer nights in total (%d against %d).", caregiver, own, other)
+	case assignment.DecisionReason == fairness.DecisionReasonRecentCount && details != nil:
+		own, other := compareCounts(details, caregiver, func(stats fairness.Stats) int { return stats.Last30Days })
+		explanation.Summary = fmt.Sprintf("%s had fewer nights in the last 30 days (%d against %d).", caregiver, own, other)
+	case assignment.DecisionReason == fairness.DecisionReasonConsecutiveLimit:
+		explanation.Summary = fmt.Sprintf("%s was assigned so that the other parent does not get too many nights in a row.", caregiver)
+	case assignment.DecisionReason == fairness.DecisionReasonAlternating:
+		explanation.Summary = fmt.Sprintf("%s was assigned to alternate with the previous night, the counts being even.", caregiver)
+	case assignment.DecisionReason == fairness.DecisionReasonRestDays:
+		explanation.Summary = fmt.Sprintf("%s was assigned so that the other parent gets the minimum nights off after a block of nights.", caregiver)
+	case assignment.DecisionReason == fairness.DecisionReasonDoubleConsecutiveSwap:
+		explanation.Summary = fmt.Sprintf("%s was swapped in so that neither parent has two nights in a row.", caregiver)
+	default:
+		explanation.Summary = fmt.Sprintf("%s was assigned (%s).", caregiver, assignment.DecisionReason)
+	}
+	return explanation
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+type dayTestEnv struct {
+	handler     *DayHandler
+	tracker     *fairness.Tracker
+	configStore *database.ConfigStore
+	checklists  *database.ChecklistStore
+	comments    *database.CommentStore
+}
+
+func setupTestDayHandler(t *testing.T, authenticated bool) dayTestEnv {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "day.db"),
+		Mode:        "rwc",
+		Cache:       database.CachePrivate,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveParents(t.Context(), "Alice", "Bob"))
+	checklists, err := database.NewChecklistStore(db)
+	require.NoError(t, err)
+	comments, err := database.NewCommentStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(configStore, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	return dayTestEnv{
+		handler:     NewDayHandler(baseHandler, checklists, comments),
+		tracker:     tracker,
+		configStore: configStore,
+		checklists:  checklists,
+		comments:    comments,
+	}
+}
+
+func getDay(t *testing.T, handler *DayHandler, date string) (*httptest.ResponseRecorder, DayResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/days/"+date, nil)
+	req.SetPathValue("date", date)
+	w := httptest.NewRecorder()
+	handler.handleDay(w, req)
+
+	var resp DayResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, resp
+}
+
+func TestDayHandler_FullContext(t *testing.T) {
+	env := setupTestDayHandler(t, true)
+	date := time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+
+	assignment, err := env.tracker.RecordAssignment(t.Context(), "Alice", date, false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, env.tracker.SaveAssignmentDetails(t.Context(), assignment.ID, date, "Alice", fairness.Stats{TotalAssignments: 3, Last30Days: 2}, "Bob", fairness.Stats{TotalAssignments: 5, Last30Days: 3}))
+	require.NoError(t, env.tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "event-1"))
+	require.NoError(t, env.tracker.UpdateAssignmentParent(env.tracker.BeginBatch(t.Context(), fairness.ChangeKindOverride), assignment.ID, "Bob", true, assignment.Version))
+	require.NoError(t, env.tracker.SetAssignmentTag(t.Context(), assignment.ID, fairness.AssignmentTagSickKid))
+	_, err = env.comments.AddComment(assignment.ID, "Alice", "Thanks!")
+	require.NoError(t, err)
+	require.NoError(t, env.checklists.SaveChecklist(assignment.ID, []string{"Bath", "Story"}))
+	require.NoError(t, env.checklists.SetItemDone(assignment.ID, "Bath", true))
+
+	w, resp := getDay(t, env.handler, "2026-10-16")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Friday", resp.Weekday)
+	assert.Empty(t, resp.Unscheduled)
+
+	require.NotNil(t, resp.Assignment)
+	assert.Equal(t, assignment.ID, resp.Assignment.ID)
+	assert.Equal(t, "Bob", resp.Assignment.Caregiver)
+	assert.True(t, resp.Assignment.Override)
+	assert.Equal(t, "sick_kid", resp.Assignment.Tag)
+	assert.Equal(t, "#f4511e", resp.Assignment.Color)
+	assert.Equal(t, "B", resp.Assignment.Avatar)
+
+	require.NotNil(t, resp.Explanation)
+	assert.Equal(t, "Bob was set by hand and is locked.", resp.Explanation.Summary)
+	assert.Equal(t, "2026-10-16", resp.Explanation.CalculationDate)
+	assert.Equal(t, 5, resp.Explanation.ParentBTotalCount)
+
+	require.Len(t, resp.History, 1)
+	assert.Equal(t, "override", resp.History[0].Kind)
+	assert.Equal(t, "Alice", resp.History[0].PreviousCaregiver)
+	assert.Equal(t, "Total Count", resp.History[0].PreviousReason)
+
+	require.Len(t, resp.Comments, 1)
+	assert.Equal(t, "Thanks!", resp.Comments[0].Text)
+	require.Len(t, resp.Checklist, 2)
+	assert.True(t, resp.Checklist[0].Done)
+	assert.False(t, resp.Checklist[1].Done)
+
+	assert.True(t, resp.Sync.Synced)
+	assert.Equal(t, "event-1", resp.Sync.EventID)
+	assert.Nil(t, resp.Sync.LastSync)
+}
+
+func TestDayHandler_Unscheduled(t *testing.T) {
+	env := setupTestDayHandler(t, true)
+	require.NoError(t, env.configStore.SaveSkipDates(t.Context(), config.SkipDates{{Date: time.Date(2026, 12, 24, 0, 0, 0, 0, time.Local)}}))
+
+	w, resp := getDay(t, env.handler, "2026-12-24")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "skip_date", resp.Unscheduled)
+	assert.Nil(t, resp.Assignment)
+	assert.Nil(t, resp.Explanation)
+	assert.Empty(t, resp.History)
+	assert.False(t, resp.Sync.Synced)
+
+	w, resp = getDay(t, env.handler, "2026-12-25")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, resp.Unscheduled, "a day not scheduled yet")
+}
+
+func TestDayHandler_Errors(t *testing.T) {
+	env := setupTestDayHandler(t, true)
+
+	w, _ := getDay(t, env.handler, "16-10-2026")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	env.handler.handleDay(w, httptest.NewRequest(http.MethodPost, "/api/v1/days/2026-10-16", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w, _ = getDay(t, setupTestDayHandler(t, false).handler, "2026-10-16")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestNewDayExplanation(t *testing.T) {
+	details := &fairness.AssignmentDetails{ParentAName: "Alice", ParentATotalCount: 4, ParentALast30Days: 1, ParentBName: "Bob", ParentBTotalCount: 6, ParentBLast30Days: 3}
+	withGrandma := *details
+	withGrandma.ExtraParents = []fairness.NamedStats{{Name: "Grandma", Stats: fairness.Stats{TotalAssignments: 2, Last30Days: 2}}}
+
+	tests := []struct {
+		assignment *fairness.Assignment
+		details    *fairness.AssignmentDetails
+		summary    string
+	}{
+		{&fairness.Assignment{Parent: "Alice", CaregiverType: fairness.CaregiverTypeParent, DecisionReason: fairness.DecisionReasonTotalCount}, details, "Alice had fewer nights in total (4 against 6)."},
+		{&fairness.Assignment{Parent: "Bob", CaregiverType: fairness.CaregiverTypeParent, DecisionReason: fairness.DecisionReasonRecentCount}, details, "Bob had fewer nights in the last 30 days (3 against 1)."},
+		{&fairness.Assignment{Parent: "Alice", CaregiverType: fairness.CaregiverTypeParent, DecisionReason: fairness.DecisionReasonTotalCount}, nil, "Alice was assigned (Total Count)."},
+		{&fairness.Assignment{Parent: "Grandma", CaregiverType: fairness.CaregiverTypeBabysitter, DecisionReason: fairness.DecisionReasonOverride}, nil, "Grandma babysits, set by hand."},
+		{&fairness.Assignment{Parent: "Bob", CaregiverType: fairness.CaregiverTypeParent, DecisionReason: fairness.DecisionReasonUnavailability}, details, "Bob was assigned because the other parent was unavailable."},
+		{&fairness.Assignment{Parent: "Grandma", CaregiverType: fairness.CaregiverTypeParent, DecisionReason: fairness.DecisionReasonTotalCount}, &withGrandma, "Grandma had fewer nights in total (2 against 4)."},
+		{&fairness.Assignment{Parent: "Alice", CaregiverType: fairness.CaregiverTypeParent, DecisionReason: fairness.DecisionReasonRecentCount}, &withGrandma, "Alice had fewer nights in the last 30 days (1 against 2)."},
+		{&fairness.Assignment{Parent: "Alice", CaregiverType: fairness.CaregiverTypeParent, DecisionReason: fairness.DecisionReasonUnavailability}, &withGrandma, "Alice was assigned because the other parents were unavailable."},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.summary, newDayExplanation(tt.assignment, tt.details).Summary)
+	}
+	assert.Equal(t, []ParentCountsResponse{{Name: "Grandma", TotalCount: 2, Last30Days: 2}}, newDayExplanation(tests[5].assignment, &withGrandma).ExtraParents)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/rs/zerolog"
+	"github.com/skip2/go-qrcode"
+)
+
+// qrCodeSize is the width and height in pixels of the QR codes of the devices page
+const qrCodeSize = 256
+
+// googleCalendarSubscribeURL adds a calendar shared with the Google account of the phone
+const googleCalendarSubscribeURL = "https://calendar.google.com/calendar/r?cid="
+
+// DeviceLink is a link opened on a phone by scanning its QR code
+type DeviceLink struct {
+	Title       string
+	Description string
+	URL         string
+	// QRCode is the PNG image of the QR code of URL, as a data URI
+	QRCode template.URL
+}
+
+// DevicesPageData contains data for the devices page template
+type DevicesPageData struct {
+	BasePageData
+	Links        []DeviceLink
+	ErrorMessage string
+}
+
+// DevicesHandler renders the "Connect devices" page: the links a phone subscribes to, with their QR
+// codes generated server-side so that nothing has to be typed.
+type DevicesHandler struct {
+	*BaseHandler
+	appURL string
+}
+
+// NewDevicesHandler creates a new devices page handler linking to the web interface at appURL
+func NewDevicesHandler(baseHandler *BaseHandler, appURL string) *DevicesHandler {
+	return &DevicesHandler{
+		BaseHandler: baseHandler,
+		appURL:      appURL,
+	}
+}
+
+// RegisterRoutes registers devices page related routes
+func (h *DevicesHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/devices", h.handleDevicesPage, http.MethodGet)
+}
+
+// handleDevicesPage shows the QR code of each link available: the web interface, and the Google
+// calendar of the events once selected
+func (h *DevicesHandler) handleDevicesPage(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleDevicesPage").Logger()
+	handlerLogger.Info().Msg("Handling devices page request")
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	isAuthenticated := h.CheckAuthentication(r.Context(), handlerLogger)
+	data := DevicesPageData{BasePageData: h.NewBasePageData(r, isAuthenticated)}
+
+	links := []DeviceLink{{
+		Title:       "Night Routine",
+		Description: "Open the schedule on the phone, and add it to the home screen",
+		URL:         h.appURL,
+	}}
+	if isAuthenticated {
+		if link, ok := h.calendarLink(handlerLogger); ok {
+			links = append(links, link)
+		}
+	}
+
+	for _, link := range links {
+		qrCode, err := qrCodeDataURI(link.URL)
+		if err != nil {
+			handlerLogger.Error().Err(err).Str("link", link.Title).Msg("Failed to generate QR code")
+			data.ErrorMessage = GetErrorMessage(ErrCodeFailedGenerateQRCode)
+			continue
+		}
+		link.QRCode = qrCode
+		data.Links = append(data.Links, link)
+	}
+
+	handlerLogger.Debug().Int("links", len(data.Links)).Msg("Rendering devices page")
+	h.RenderTemplate(w, "devices.html", data)
+}
+
+// calendarLink returns the link adding the selected Google calendar to the phone, false when no
+// calendar is selected
+func (h *DevicesHandler) calendarLink(logger zerolog.Logger) (DeviceLink, bool) {
+	calendarID, calendarName, err := h.TokenStore.GetSelectedCalendarWithName()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get selected calendar")
+		return DeviceLink{}, false
+	}
+	if calendarID == "" {
+		return DeviceLink{}, false
+	}
+	if calendarName == "" {
+		calendarName = calendarID
+	}
+	return DeviceLink{
+		Title:       "Google Calendar",
+		Description: fmt.Sprintf("Subscribe to %s with the Google account the calendar is shared with", calendarName),
+		URL:         googleCalendarSubscribeURL + url.QueryEscape(calendarID),
+	}, true
+}
+
+// qrCodeDataURI encodes content as the data URI of a PNG QR code
+func qrCodeDataURI(content string) (template.URL, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	// Trusted as a URL by the templates, the data URI only holds the PNG generated above
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)), nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestDevicesHandler(t *testing.T) (*DevicesHandler, *database.TokenStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "devices.db"),
+		Mode:        "rwc",
+		Cache:       database.CachePrivate,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	tokenManager := token.NewTokenManager(tokenStore, oauthCfg)
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, tokenManager, tracker, nil)
+	require.NoError(t, err)
+
+	return NewDevicesHandler(baseHandler, "https://night-routine.example.com"), tokenStore
+}
+
+// qrCodes returns the decoded PNG images of the QR codes of the page
+func qrCodes(t *testing.T, body string) [][]byte {
+	var images [][]byte
+	for _, match := range regexp.MustCompile(`src="data:image/png;base64,([^"]+)"`).FindAllStringSubmatch(body, -1) {
+		image, err := base64.StdEncoding.DecodeString(html.UnescapeString(match[1]))
+		require.NoError(t, err)
+		images = append(images, image)
+	}
+	return images
+}
+
+func TestDevicesHandler_NotAuthenticated(t *testing.T) {
+	handler, _ := setupTestDevicesHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.handleDevicesPage(w, httptest.NewRequest(http.MethodGet, "/devices", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `href="https://night-routine.example.com"`)
+	assert.NotContains(t, body, googleCalendarSubscribeURL)
+	images := qrCodes(t, body)
+	require.Len(t, images, 1)
+	assert.True(t, strings.HasPrefix(string(images[0]), "\x89PNG"), "QR code is a PNG image")
+}
+
+func TestDevicesHandler_CalendarLink(t *testing.T) {
+	handler, tokenStore := setupTestDevicesHandler(t)
+	require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	require.NoError(t, tokenStore.SaveSelectedCalendarWithName("family@group.calendar.google.com", "Family"))
+
+	w := httptest.NewRecorder()
+	handler.handleDevicesPage(w, httptest.NewRequest(http.MethodGet, "/devices", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, googleCalendarSubscribeURL+"family%40group.calendar.google.com")
+	assert.Contains(t, body, "Subscribe to Family")
+	assert.Len(t, qrCodes(t, body), 2)
+}
+
+func TestDevicesHandler_MethodNotAllowed(t *testing.T) {
+	handler, _ := setupTestDevicesHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.handleDevicesPage(w, httptest.NewRequest(http.MethodPost, "/devices", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -1,15 +1,42 @@
 package handlers
 
+import (
+	"errors"
+	"net/http"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/validation"
+	"github.com/rs/zerolog"
+)
+
 // Error Codes
 const (
 	ErrCodeInvalidFormData           = "invalid_form_data"
 	ErrCodeInvalidDayOfWeek          = "invalid_day_of_week"
+	ErrCodeInvalidUnavailabilityRule = "invalid_unavailability_rule"
 	ErrCodeInvalidLookAheadDays      = "invalid_look_ahead_days"
 	ErrCodeInvalidPastEventThreshold = "invalid_past_event_threshold"
 	ErrCodeInvalidStatsOrder         = "invalid_stats_order"
+	ErrCodeInvalidMinRestDays        = "invalid_min_rest_days"
+	ErrCodeInvalidDayWeight          = "invalid_day_weight"
 	ErrCodeFailedSaveParent          = "failed_save_parent"
 	ErrCodeFailedSaveAvailability    = "failed_save_availability"
 	ErrCodeFailedSaveSchedule        = "failed_save_schedule"
+	ErrCodeFailedSaveNotifications   = "failed_save_notifications"
+	ErrCodeInvalidChecklist          = "invalid_checklist"
+	ErrCodeFailedSaveChecklist       = "failed_save_checklist"
+	ErrCodeFailedSaveComments        = "failed_save_comments"
+	ErrCodeInvalidVacation           = "invalid_vacation"
+	ErrCodeFailedSaveVacation        = "failed_save_vacation"
+	ErrCodeInvalidSkipDate           = "invalid_skip_date"
+	ErrCodeInvalidParents            = "invalid_parents"
+	ErrCodeInvalidParentStyle        = "invalid_parent_style"
+	ErrCodeInvalidUpdateFrequency    = "invalid_update_frequency"
+	ErrCodeFailedSaveSetup           = "failed_save_setup"
+	ErrCodeFailedGenerateQRCode      = "failed_generate_qr_code"
+	ErrCodeFailedSaveSkipDates       = "failed_save_skip_dates"
+	ErrCodeFailedSaveSyncExclusions  = "failed_save_sync_exclusions"
+	ErrCodeFailedLoadChannels        = "failed_load_channels"
 	ErrCodeSyncFailed                = "sync_failed"
 	ErrCodeAuthRequired              = "authentication_required"
 	ErrCodeCalendarSelectionRequired = "calendar_selection_required"
@@ -22,6 +49,7 @@ const (
 	ErrCodeInvalidAssignmentID       = "invalid_assignment_id"
 	ErrCodeUnlockFailed              = "unlock_failed"
 	ErrCodeNotOverridden             = "not_overridden"
+	ErrCodeDisconnectFailed          = "disconnect_failed"
 )
 
 // Success Codes
@@ -30,18 +58,38 @@ const (
 	SuccessCodeSettingsUpdatedSyncFailed = "settings_updated_sync_failed"
 	SuccessCodeSyncComplete              = "sync_complete"
 	SuccessCodeAssignmentUnlocked        = "assignment_unlocked"
+	SuccessCodeDisconnected              = "disconnected"
+	SuccessCodeAvailabilityUpdated       = "availability_updated"
 )
 
 // ErrorMessages maps error codes to user-friendly messages
 var ErrorMessages = map[string]string{
 	ErrCodeInvalidFormData:           "Invalid form data.",
 	ErrCodeInvalidDayOfWeek:          "Invalid day of week.",
+	ErrCodeInvalidUnavailabilityRule: "Invalid recurring unavailability rule, write one rule per line as FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16 or FREQ=MONTHLY;BYDAY=1MO.",
 	ErrCodeInvalidLookAheadDays:      "Look ahead days must be between 1 and 365.",
 	ErrCodeInvalidPastEventThreshold: "Past event threshold must be between 0 and 30.",
 	ErrCodeInvalidStatsOrder:         "Invalid statistics order. Must be 'desc' or 'asc'.",
+	ErrCodeInvalidMinRestDays:        "Minimum rest days must be between 0 and 7.",
+	ErrCodeInvalidDayWeight:          "Day weights must be between 0.5 and 5, in steps of 0.5.",
 	ErrCodeFailedSaveParent:          "Failed to save parent names.",
 	ErrCodeFailedSaveAvailability:    "Failed to save availability.",
 	ErrCodeFailedSaveSchedule:        "Failed to save schedule settings.",
+	ErrCodeFailedSaveNotifications:   "Failed to save notification settings.",
+	ErrCodeInvalidChecklist:          "The checklist is limited to 20 items of at most 80 characters.",
+	ErrCodeFailedSaveChecklist:       "Failed to save the bedtime checklist.",
+	ErrCodeFailedSaveComments:        "Failed to save the comment settings.",
+	ErrCodeInvalidVacation:           "The vacation needs a first and a last day, the last one not before the first.",
+	ErrCodeFailedSaveVacation:        "Failed to save the vacation.",
+	ErrCodeInvalidSkipDate:           "Invalid day without routine, write one per line as a date such as 2026-10-24 or a rule such as FREQ=MONTHLY;BYDAY=2SA.",
+	ErrCodeFailedSaveSkipDates:       "Failed to save the days without routine.",
+	ErrCodeFailedSaveSyncExclusions:  "Failed to save the nights kept out of the calendar.",
+	ErrCodeInvalidParents:            "Every parent needs a name, the names must be different, and at most 6 parents take turns.",
+	ErrCodeInvalidParentStyle:        "Invalid parent color or avatar, the avatar is an emoji or at most 8 characters.",
+	ErrCodeInvalidUpdateFrequency:    "Invalid update frequency. Must be daily, weekly, monthly or disabled.",
+	ErrCodeFailedSaveSetup:           "Failed to save the configuration. Please try again.",
+	ErrCodeFailedGenerateQRCode:      "Failed to generate a QR code, some links are missing.",
+	ErrCodeFailedLoadChannels:        "Failed to load the notification channels.",
 	ErrCodeSyncFailed:                "Failed to sync schedule. Please try again.",
 	ErrCodeAuthRequired:              "Authentication required. Please connect your Google Calendar first.",
 	ErrCodeCalendarSelectionRequired: "Please select a calendar first.",
@@ -54,6 +102,7 @@ var ErrorMessages = map[string]string{
 	ErrCodeInvalidAssignmentID:       "Invalid assignment ID.",
 	ErrCodeUnlockFailed:              "Failed to unlock assignment. Please try again.",
 	ErrCodeNotOverridden:             "Cannot unlock an assignment that hasn't been manually overridden.",
+	ErrCodeDisconnectFailed:          "Failed to disconnect Google Calendar. Please try again.",
 }
 
 // SuccessMessages maps success codes to user-friendly messages
@@ -62,6 +111,8 @@ var SuccessMessages = map[string]string{
 	SuccessCodeSettingsUpdatedSyncFailed: "Settings updated but sync failed. Please sync manually.",
 	SuccessCodeSyncComplete:              "Schedule successfully synced with Google Calendar.",
 	SuccessCodeAssignmentUnlocked:        "Assignment unlocked successfully.",
+	SuccessCodeDisconnected:              "Google Calendar disconnected.",
+	SuccessCodeAvailabilityUpdated:       "Your availability was saved and the schedule recalculated.",
 }
 
 // GetErrorMessage returns the message for a given error code
@@ -79,3 +130,33 @@ func GetSuccessMessage(code string) string {
 	}
 	return ""
 }
+
+// validationErrorCode returns the error code of a setting rejected by the validation package,
+// ErrCodeInvalidFormData for any other error
+func validationErrorCode(err error) string {
+	switch {
+	case errors.Is(err, validation.ErrMissingParents), errors.Is(err, validation.ErrSameParents), errors.Is(err, validation.ErrTooManyParents):
+		return ErrCodeInvalidParents
+	case errors.Is(err, validation.ErrInvalidDayOfWeek):
+		return ErrCodeInvalidDayOfWeek
+	case errors.Is(err, validation.ErrInvalidUpdateFrequency):
+		return ErrCodeInvalidUpdateFrequency
+	case errors.Is(err, validation.ErrInvalidLookAheadDays):
+		return ErrCodeInvalidLookAheadDays
+	case errors.Is(err, validation.ErrInvalidPastEventThresholdDays):
+		return ErrCodeInvalidPastEventThreshold
+	case errors.Is(err, validation.ErrInvalidStatsOrder):
+		return ErrCodeInvalidStatsOrder
+	case errors.Is(err, validation.ErrInvalidMinRestDays):
+		return ErrCodeInvalidMinRestDays
+	case errors.Is(err, validation.ErrInvalidDayWeight):
+		return ErrCodeInvalidDayWeight
+	default:
+		return ErrCodeInvalidFormData
+	}
+}
+
+// writeError writes an API error as JSON, with its stable code next to the message
+func writeError(w http.ResponseWriter, status int, code apierror.Code, message string, logger zerolog.Logger) {
+	writeJSON(w, status, apierror.New(code, message), logger)
+}
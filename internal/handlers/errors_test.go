@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAPIErrorCodes verifies that the API errors carry the stable code of their kind next to the message
+func TestAPIErrorCodes(t *testing.T) {
+	authenticated := setupTestDayHandler(t, true).handler.BaseHandler
+	unauthenticated := setupTestDayHandler(t, false).handler.BaseHandler
+
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		method         string
+		url            string
+		expectedStatus int
+		expectedCode   apierror.Code
+	}{
+		{"validation", NewLockHandler(authenticated).handleLocks, http.MethodPost, "/api/v1/locks", http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"authentication", NewLockHandler(unauthenticated).handleLocks, http.MethodGet, "/api/v1/locks", http.StatusUnauthorized, apierror.CodeAuthenticationRequired},
+		{"method", NewUpcomingHandler(authenticated).handleUpcoming, http.MethodDelete, "/api/v1/upcoming", http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed},
+		{"not found", NewUndoHandler(authenticated, authenticated.Tracker, nil, nil).handleUndo, http.MethodPost, "/api/admin/undo", http.StatusNotFound, apierror.CodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			var body apierror.Response
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.expectedCode, body.Code)
+			assert.NotEmpty(t, body.Message)
+		})
+	}
+}
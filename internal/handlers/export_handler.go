@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+// exportDateFormat is the format of the export query parameters and of the exported dates
+const exportDateFormat = "2006-01-02"
+
+// exportCSVHeader is the first row of the CSV export
+var exportCSVHeader = []string{"date", "parent", "caregiver_type", "override", "decision_reason"}
+
+// AssignmentStreamer reads the assignments of a date range one at a time, implemented by fairness.Tracker
+type AssignmentStreamer interface {
+	ForEachAssignmentInRange(ctx context.Context, start, end time.Time, fn func(*fairness.Assignment) error) error
+}
+
+// ExportHandler exports the assignments for spreadsheet analysis
+type ExportHandler struct {
+	*BaseHandler
+	assignments AssignmentStreamer
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(baseHandler *BaseHandler, assignments AssignmentStreamer) *ExportHandler {
+	return &ExportHandler{BaseHandler: baseHandler, assignments: assignments}
+}
+
+// RegisterRoutes registers the export routes
+func (h *ExportHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/export.csv", h.handleExportCSV, http.MethodGet)
+}
+
+// handleExportCSV streams the assignments between the optional from and to query parameters
+// (YYYY-MM-DD, both included, default every assignment) as CSV, oldest first. The rows are written
+// while they are read from the database, so the export never holds the whole history in memory.
+func (h *ExportHandler) handleExportCSV(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleExportCSV").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	start, err := parseExportDate(r.URL.Query().Get("from"), time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "from must be formatted as YYYY-MM-DD", handlerLogger)
+		return
+	}
+	end, err := parseExportDate(r.URL.Query().Get("to"), time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "to must be formatted as YYYY-MM-DD", handlerLogger)
+		return
+	}
+	if end.Before(start) {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "to must not be before from", handlerLogger)
+		return
+	}
+
+	csvWriter := csv.NewWriter(w)
+	started := false
+	// The headers are only sent with the first row, so that a failing query still gets an error response
+	startCSV := func() error {
+		started = true
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="night-routine-assignments.csv"`)
+		return csvWriter.Write(exportCSVHeader)
+	}
+
+	rows := 0
+	err = h.assignments.ForEachAssignmentInRange(r.Context(), start, end, func(a *fairness.Assignment) error {
+		if !started {
+			if err := startCSV(); err != nil {
+				return err
+			}
+		}
+		rows++
+		return csvWriter.Write([]string{
+			a.Date.Format(exportDateFormat),
+			a.Parent,
+			a.CaregiverType.String(),
+			strconv.FormatBool(a.Override),
+			a.DecisionReason.String(),
+		})
+	})
+	if err == nil && !started {
+		err = startCSV()
+	}
+	if err != nil {
+		if !started {
+			handlerLogger.Error().Err(err).Msg("Failed to read assignments for export")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to export assignments", handlerLogger)
+			return
+		}
+		// Part of the CSV may already be sent: abort the connection rather than end a truncated file cleanly
+		handlerLogger.Error().Err(err).Int("rows", rows).Msg("Failed to stream assignments export")
+		panic(http.ErrAbortHandler)
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		handlerLogger.Error().Err(err).Int("rows", rows).Msg("Failed to write assignments export")
+		return
+	}
+	handlerLogger.Debug().Int("rows", rows).Msg("Assignments exported")
+}
+
+// parseExportDate parses a date query parameter, returning fallback when it is empty
+func parseExportDate(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.Parse(exportDateFormat, value)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestExportHandler(t *testing.T) (*ExportHandler, func()) {
+	detailsHandler, tracker, _, cleanup := setupTestAssignmentDetailsHandler(t, true)
+
+	_, err := tracker.RecordAssignment(t.Context(), "Alice", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Grandma, Jr.", time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), true)
+	require.NoError(t, err)
+
+	return NewExportHandler(detailsHandler.BaseHandler, tracker), cleanup
+}
+
+func TestExportHandler_CSV(t *testing.T) {
+	handler, cleanup := setupTestExportHandler(t)
+	defer cleanup()
+
+	tests := []struct {
+		name     string
+		query    string
+		wantBody string
+	}{
+		{
+			name:  "every assignment",
+			query: "",
+			wantBody: "date,parent,caregiver_type,override,decision_reason\n" +
+				"2025-01-01,Alice,parent,false,Total Count\n" +
+				"2025-01-02,Bob,parent,true,Override\n" +
+				"2025-01-03,\"Grandma, Jr.\",babysitter,true,Override\n",
+		},
+		{
+			name:  "date range",
+			query: "?from=2025-01-02&to=2025-01-02",
+			wantBody: "date,parent,caregiver_type,override,decision_reason\n" +
+				"2025-01-02,Bob,parent,true,Override\n",
+		},
+		{
+			name:     "empty range keeps the header",
+			query:    "?from=2026-01-01",
+			wantBody: "date,parent,caregiver_type,override,decision_reason\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleExportCSV(w, httptest.NewRequest(http.MethodGet, "/api/v1/export.csv"+tt.query, nil))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="night-routine-assignments.csv"`, w.Header().Get("Content-Disposition"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestExportHandler_Errors(t *testing.T) {
+	handler, cleanup := setupTestExportHandler(t)
+	defer cleanup()
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		wantStatus int
+	}{
+		{name: "invalid from", method: http.MethodGet, query: "?from=01/02/2025", wantStatus: http.StatusBadRequest},
+		{name: "invalid to", method: http.MethodGet, query: "?to=2025-13-01", wantStatus: http.StatusBadRequest},
+		{name: "to before from", method: http.MethodGet, query: "?from=2025-02-01&to=2025-01-01", wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, query: "", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleExportCSV(w, httptest.NewRequest(tt.method, "/api/v1/export.csv"+tt.query, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+// failingStreamer returns err after handing out rows assignments
+type failingStreamer struct {
+	rows int
+	err  error
+}
+
+func (s failingStreamer) ForEachAssignmentInRange(_ context.Context, _, _ time.Time, fn func(*fairness.Assignment) error) error {
+	for i := range s.rows {
+		a := &fairness.Assignment{Parent: "Alice", Date: time.Date(2025, 1, i+1, 0, 0, 0, 0, time.UTC), CaregiverType: fairness.CaregiverTypeParent}
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func TestExportHandler_ReadFailure(t *testing.T) {
+	handler, cleanup := setupTestExportHandler(t)
+	defer cleanup()
+
+	t.Run("before the first row", func(t *testing.T) {
+		handler.assignments = failingStreamer{err: errors.New("database is locked")}
+		w := httptest.NewRecorder()
+		handler.handleExportCSV(w, httptest.NewRequest(http.MethodGet, "/api/v1/export.csv", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("while streaming", func(t *testing.T) {
+		handler.assignments = failingStreamer{rows: 2, err: errors.New("database is locked")}
+		w := httptest.NewRecorder()
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.handleExportCSV(w, httptest.NewRequest(http.MethodGet, "/api/v1/export.csv", nil))
+		}, "a truncated export aborts the connection")
+	})
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/features"
+	"github.com/rs/zerolog"
+)
+
+// FeatureFlagsHandler lists the feature flags gating the experimental subsystems and sets them,
+// taking effect without a restart
+type FeatureFlagsHandler struct {
+	*BaseHandler
+	flags *features.Flags
+	now   func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewFeatureFlagsHandler creates a new feature flags handler
+func NewFeatureFlagsHandler(baseHandler *BaseHandler, flags *features.Flags) *FeatureFlagsHandler {
+	return &FeatureFlagsHandler{
+		BaseHandler: baseHandler,
+		flags:       flags,
+		now:         time.Now,
+	}
+}
+
+// RegisterRoutes registers the feature flags routes
+func (h *FeatureFlagsHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/admin/features", h.handleListFeatureFlags, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/admin/features/{name}", h.handleFeatureFlag, http.MethodPut, http.MethodDelete)
+}
+
+// FeatureFlagResponse is a feature flag with its state
+type FeatureFlagResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Default     bool   `json:"default"`
+	Set         bool   `json:"set"` // False while the flag has its default state
+}
+
+// FeatureFlagsResponse is the response of the feature flags API
+type FeatureFlagsResponse struct {
+	Flags []FeatureFlagResponse `json:"flags"`
+}
+
+// SetFeatureFlagRequest is the JSON body setting a feature flag
+type SetFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// handleListFeatureFlags returns the state of every known flag
+func (h *FeatureFlagsHandler) handleListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleListFeatureFlags").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+	h.writeFeatureFlags(w, handlerLogger)
+}
+
+// handleFeatureFlag enables or disables a flag on PUT, and gives it its default state back on
+// DELETE. Both answer the state of every flag.
+func (h *FeatureFlagsHandler) handleFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	handlerLogger := h.logger.With().Str("handler", "handleFeatureFlag").Str("method", r.Method).Str("flag", name).Logger()
+
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodPut+", "+http.MethodDelete)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var err error
+	if r.Method == http.MethodDelete {
+		err = h.flags.Reset(name)
+	} else {
+		var req SetFeatureFlagRequest
+		if decodeErr := json.NewDecoder(r.Body).Decode(&req); decodeErr != nil || req.Enabled == nil {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "The body must be a JSON object with enabled", handlerLogger)
+			return
+		}
+		err = h.flags.Set(name, *req.Enabled, h.now())
+	}
+	if errors.Is(err, features.ErrUnknownFlag) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Unknown feature flag", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save the feature flag")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to save the feature flag", handlerLogger)
+		return
+	}
+	h.writeFeatureFlags(w, handlerLogger)
+}
+
+// writeFeatureFlags answers the state of every known flag
+func (h *FeatureFlagsHandler) writeFeatureFlags(w http.ResponseWriter, handlerLogger zerolog.Logger) {
+	states, err := h.flags.List()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list the feature flags")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to list the feature flags", handlerLogger)
+		return
+	}
+	response := FeatureFlagsResponse{Flags: make([]FeatureFlagResponse, 0, len(states))}
+	for _, state := range states {
+		response.Flags = append(response.Flags, FeatureFlagResponse{
+			Name:        string(state.Flag),
+			Description: state.Description,
+			Enabled:     state.Enabled,
+			Default:     state.Default,
+			Set:         state.Set,
+		})
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
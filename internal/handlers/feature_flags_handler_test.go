@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/features"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestFeatureFlagsHandler(t *testing.T, authenticated bool) (*FeatureFlagsHandler, *features.Flags) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	flagStore, err := database.NewFeatureFlagStore(db)
+	require.NoError(t, err)
+	flags := features.New(flagStore)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+	return NewFeatureFlagsHandler(baseHandler, flags), flags
+}
+
+func serveFeatureFlag(handler *FeatureFlagsHandler, method, name, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/admin/features/"+name, strings.NewReader(body))
+	req.SetPathValue("name", name)
+	w := httptest.NewRecorder()
+	handler.handleFeatureFlag(w, req)
+	return w
+}
+
+func TestFeatureFlagsHandler(t *testing.T) {
+	handler, flags := setupTestFeatureFlagsHandler(t, true)
+
+	w := httptest.NewRecorder()
+	handler.handleListFeatureFlags(w, httptest.NewRequest(http.MethodGet, "/api/admin/features", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response FeatureFlagsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Flags, len(features.Definitions))
+	assert.Equal(t, "ics_feed", response.Flags[0].Name)
+	assert.True(t, response.Flags[0].Enabled)
+	assert.False(t, response.Flags[0].Set)
+
+	w = serveFeatureFlag(handler, http.MethodPut, "ics_feed", `{"enabled":false}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Flags[0].Enabled)
+	assert.True(t, response.Flags[0].Set)
+	assert.False(t, flags.Enabled(features.ICSFeed), "the flag applies without a restart")
+
+	w = serveFeatureFlag(handler, http.MethodDelete, "ics_feed", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, flags.Enabled(features.ICSFeed))
+
+	tests := []struct {
+		name   string
+		method string
+		flag   string
+		body   string
+		status int
+		code   apierror.Code
+	}{
+		{"Unknown flag", http.MethodPut, "caldav", `{"enabled":true}`, http.StatusNotFound, apierror.CodeNotFound},
+		{"Reset unknown flag", http.MethodDelete, "caldav", "", http.StatusNotFound, apierror.CodeNotFound},
+		{"No enabled", http.MethodPut, "ics_feed", `{}`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"Not JSON", http.MethodPut, "ics_feed", `true`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveFeatureFlag(handler, tt.method, tt.flag, tt.body)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			var response apierror.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+}
+
+func TestFeatureFlagsHandler_Unauthenticated(t *testing.T) {
+	handler, _ := setupTestFeatureFlagsHandler(t, false)
+
+	w := httptest.NewRecorder()
+	handler.handleListFeatureFlags(w, httptest.NewRequest(http.MethodGet, "/api/admin/features", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, serveFeatureFlag(handler, http.MethodPut, "ics_feed", `{"enabled":true}`).Code)
+}
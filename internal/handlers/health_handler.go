@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+)
+
+// Readiness statuses reported by /readyz
+const (
+	ReadinessStatusOK          = "ok"
+	ReadinessStatusDegraded    = "degraded"
+	ReadinessStatusUnavailable = "unavailable"
+)
+
+// Readiness check results
+const (
+	CheckResultOK                       = "ok"
+	CheckResultError                    = "error"
+	CheckResultMissing                  = "missing"
+	CheckResultReauthenticationRequired = "reauthentication_required"
+)
+
+// readinessTimeout bounds the database ping of a readiness probe
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler serves liveness and readiness probes
+type HealthHandler struct {
+	*BaseHandler
+	DB *database.DB
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(baseHandler *BaseHandler, db *database.DB) *HealthHandler {
+	return &HealthHandler{
+		BaseHandler: baseHandler,
+		DB:          db,
+	}
+}
+
+// RegisterRoutes registers health related routes
+func (h *HealthHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/healthz", h.handleLiveness, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/readyz", h.handleReadiness, http.MethodGet)
+}
+
+// ReadinessResponse represents the JSON response of the readiness probe
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// handleLiveness reports that the process is serving requests
+func (h *HealthHandler) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("ok")); err != nil {
+		h.logger.Debug().Err(err).Msg("Failed to write liveness response")
+	}
+}
+
+// handleReadiness reports whether the service can do its job. A database failure makes the
+// service unavailable (503); a Google token needing re-authentication only degrades it, since
+// the web interface keeps working and is where the user reconnects.
+func (h *HealthHandler) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleReadiness").Logger()
+
+	resp := ReadinessResponse{
+		Status: ReadinessStatusOK,
+		Checks: map[string]string{},
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	if err := h.DB.Conn().PingContext(ctx); err != nil {
+		handlerLogger.Warn().Err(err).Msg("Readiness database check failed")
+		resp.Checks["database"] = CheckResultError
+		resp.Status = ReadinessStatusUnavailable
+	} else {
+		resp.Checks["database"] = CheckResultOK
+	}
+
+	hasToken, err := h.TokenManager.HasToken()
+	switch {
+	case err != nil:
+		handlerLogger.Warn().Err(err).Msg("Readiness token check failed")
+		resp.Checks["google_token"] = CheckResultError
+		if resp.Status == ReadinessStatusOK {
+			resp.Status = ReadinessStatusDegraded
+		}
+	case !hasToken:
+		resp.Checks["google_token"] = CheckResultMissing
+	case h.TokenManager.NeedsReauthentication():
+		resp.Checks["google_token"] = CheckResultReauthenticationRequired
+		if resp.Status == ReadinessStatusOK {
+			resp.Status = ReadinessStatusDegraded
+		}
+	default:
+		resp.Checks["google_token"] = CheckResultOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status == ReadinessStatusUnavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
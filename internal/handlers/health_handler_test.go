@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestHealthHandler(t *testing.T, oauthCfg *oauth2.Config) (*HealthHandler, *database.DB, *database.TokenStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	tokenManager := token.NewTokenManager(tokenStore, oauthCfg)
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, tokenManager, tracker, nil)
+	require.NoError(t, err)
+
+	return NewHealthHandler(baseHandler, db), db, tokenStore
+}
+
+func getReadiness(t *testing.T, handler *HealthHandler) (int, ReadinessResponse) {
+	w := httptest.NewRecorder()
+	handler.handleReadiness(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var resp ReadinessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func TestHealthHandler_Liveness(t *testing.T) {
+	handler, _, _ := setupTestHealthHandler(t, &oauth2.Config{})
+
+	w := httptest.NewRecorder()
+	handler.handleLiveness(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+}
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	t.Run("ok without token", func(t *testing.T) {
+		handler, _, _ := setupTestHealthHandler(t, &oauth2.Config{})
+
+		code, resp := getReadiness(t, handler)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, ReadinessStatusOK, resp.Status)
+		assert.Equal(t, CheckResultOK, resp.Checks["database"])
+		assert.Equal(t, CheckResultMissing, resp.Checks["google_token"])
+	})
+
+	t.Run("ok with valid token", func(t *testing.T) {
+		handler, _, tokenStore := setupTestHealthHandler(t, &oauth2.Config{})
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)}))
+
+		code, resp := getReadiness(t, handler)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, ReadinessStatusOK, resp.Status)
+		assert.Equal(t, CheckResultOK, resp.Checks["google_token"])
+	})
+
+	t.Run("degraded when re-authentication is required", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+		}))
+		defer server.Close()
+
+		handler, _, tokenStore := setupTestHealthHandler(t, &oauth2.Config{
+			Endpoint: oauth2.Endpoint{TokenURL: server.URL, AuthStyle: oauth2.AuthStyleInParams},
+		})
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "access", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Minute)}))
+		_, err := handler.TokenManager.GetValidToken(context.Background())
+		require.Error(t, err)
+
+		code, resp := getReadiness(t, handler)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, ReadinessStatusDegraded, resp.Status)
+		assert.Equal(t, CheckResultReauthenticationRequired, resp.Checks["google_token"])
+	})
+
+	t.Run("unavailable when database is down", func(t *testing.T) {
+		handler, db, _ := setupTestHealthHandler(t, &oauth2.Config{})
+		require.NoError(t, db.Close())
+
+		code, resp := getReadiness(t, handler)
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, ReadinessStatusUnavailable, resp.Status)
+		assert.Equal(t, CheckResultError, resp.Checks["database"])
+	})
+}
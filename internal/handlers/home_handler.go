@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
 	scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
 	"github.com/belphemur/night-routine/internal/viewhelpers"
 	"github.com/rs/zerolog"
@@ -14,19 +19,25 @@ import (
 type HomeHandler struct {
 	*BaseHandler
 	Scheduler scheduler.SchedulerInterface
+	// ImbalanceThreshold is the difference of nights over the last 30 days from which a banner
+	// suggests reviewing the settings; 0 hides the banner
+	ImbalanceThreshold int
 }
 
 // NewHomeHandler creates a new home page handler
-func NewHomeHandler(baseHandler *BaseHandler, sched scheduler.SchedulerInterface) *HomeHandler {
+func NewHomeHandler(baseHandler *BaseHandler, sched scheduler.SchedulerInterface, imbalanceThreshold int) *HomeHandler {
 	return &HomeHandler{
-		BaseHandler: baseHandler,
-		Scheduler:   sched,
+		BaseHandler:        baseHandler,
+		Scheduler:          sched,
+		ImbalanceThreshold: imbalanceThreshold,
 	}
 }
 
 // RegisterRoutes registers home page related routes
 func (h *HomeHandler) RegisterRoutes() {
-	http.HandleFunc("/", h.handleHome)
+	// Only the root path is the home page, the other paths matching no route are answered 404
+	handleMethods(http.DefaultServeMux, "/{$}", h.handleHome, http.MethodGet)
+	handleAPINotFound(http.DefaultServeMux)
 }
 
 // CalendarDayJSON represents a calendar day in JSON format for client-side use
@@ -39,6 +50,10 @@ type CalendarDayJSON struct {
 	CaregiverType    string `json:"caregiverType,omitempty"`
 	AssignmentReason string `json:"assignmentReason,omitempty"`
 	IsOverridden     bool   `json:"isOverridden"`
+	IsLocked         bool   `json:"isLocked"`
+	Tag              string `json:"tag,omitempty"`
+	Color            string `json:"color,omitempty"`
+	Badge            string `json:"badge,omitempty"`
 	CSSClasses       string `json:"cssClasses"`
 }
 
@@ -49,6 +64,19 @@ type MobileCalendarData struct {
 	EndDate   string            `json:"endDate"`
 }
 
+// homeSyncRunsLimit is the number of sync runs shown in the history section of the home page
+const homeSyncRunsLimit = 10
+
+// SyncRunRow is a sync run formatted for the history section of the home page
+type SyncRunRow struct {
+	Trigger          string
+	Status           string
+	StartedAt        string
+	Duration         string
+	AssignmentsCount int
+	Error            string
+}
+
 // HomePageData contains data for the home page template
 type HomePageData struct {
 	BasePageData
@@ -56,9 +84,24 @@ type HomePageData struct {
 	CalendarName   string
 	ErrorMessage   string
 	SuccessMessage string
-	CurrentMonth   string
-	CalendarWeeks  [][]viewhelpers.CalendarDay
-	CalendarData   MobileCalendarData // Flattened calendar data for mobile view with boundaries
+	// ReauthRequired is set when Google rejected the stored token and the user must reconnect
+	ReauthRequired bool
+	// DemoMode is set when the schedule syncs to the in-memory calendar of [app] demo_mode
+	DemoMode      bool
+	CurrentMonth  string
+	CalendarWeeks [][]viewhelpers.CalendarDay
+	CalendarData  MobileCalendarData  // Flattened calendar data for mobile view with boundaries
+	SyncRuns      []SyncRunRow        // Most recent sync runs, newest first
+	Parents       []string            // Parents offered by the take tonight button
+	ParentLegend  []ParentLegendEntry // Color and badge of each parent, for the calendar legend
+	Imbalance     *fairness.Imbalance // Set when the nights of the last 30 days reach the imbalance threshold
+}
+
+// ParentLegendEntry identifies a parent in the legend of the calendar
+type ParentLegendEntry struct {
+	Name  string
+	Color string // #rrggbb
+	Badge string
 }
 
 // handleHome shows the main page with auth status and potentially the calendar
@@ -76,10 +119,18 @@ func (h *HomeHandler) handleHome(w http.ResponseWriter, r *http.Request) {
 		CalendarName:   calendarName,
 		ErrorMessage:   errorMessage,
 		SuccessMessage: successMessage,
+		ReauthRequired: h.TokenManager.NeedsReauthentication(),
+		DemoMode:       h.Build.DemoMode(),
+	}
+
+	// Pollers get a cheap 304 while neither the assignments nor the rest of the page changed
+	if etag, lastModified, ok := h.homeValidators(r.Context(), data, handlerLogger); ok && writeNotModified(w, r, etag, lastModified) {
+		handlerLogger.Debug().Str("etag", etag).Msg("Home page not modified")
+		return
 	}
 
 	if isAuthenticated {
-		calendarMonth, calendarWeeks, calendarErr := h.generateCalendarData(handlerLogger)
+		calendarMonth, calendarWeeks, calendarErr := h.generateCalendarData(r.Context(), handlerLogger)
 		if calendarErr != nil {
 			// Use the existing error message mechanism if calendar generation fails
 			data.ErrorMessage = "Error generating assignment calendar. Please try again later."
@@ -88,12 +139,130 @@ func (h *HomeHandler) handleHome(w http.ResponseWriter, r *http.Request) {
 			data.CalendarWeeks = calendarWeeks
 			data.CalendarData = h.flattenCalendarData(calendarWeeks)
 		}
+
+		if roster, err := config.Roster(h.ConfigStore); err != nil {
+			handlerLogger.Warn().Err(err).Msg("Failed to get parents, hiding the take tonight button")
+		} else {
+			data.Parents = roster
+			data.Imbalance = h.getImbalance(r.Context(), roster, handlerLogger)
+			if styles := h.parentStyles(handlerLogger); styles != nil {
+				for _, parent := range roster {
+					style := styles[parent]
+					data.ParentLegend = append(data.ParentLegend, ParentLegendEntry{Name: parent, Color: style.Color.Hex(), Badge: style.Badge(parent)})
+				}
+			}
+		}
 	}
 
+	data.SyncRuns = h.getSyncRunRows(handlerLogger)
+
 	handlerLogger.Debug().Msg("Rendering home template")
 	h.RenderTemplate(w, "home.html", data)
 }
 
+// getImbalance returns the imbalance of the last 30 days when it reaches the threshold, nil otherwise.
+// Errors are logged and hide the banner.
+func (h *HomeHandler) getImbalance(ctx context.Context, roster []string, logger zerolog.Logger) *fairness.Imbalance {
+	if h.ImbalanceThreshold == 0 {
+		return nil
+	}
+	imbalance, err := fairness.RecentImbalance(ctx, h.Tracker, time.Now(), roster...)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get the imbalance of the last 30 days, hiding the banner")
+		return nil
+	}
+	if !imbalance.Reaches(h.ImbalanceThreshold) {
+		return nil
+	}
+	return &imbalance
+}
+
+// homeValidators returns the ETag and last modification of the home page from everything it shows:
+// the assignments version, the latest sync run, the parents and their styles, the calendar, the day and the messages.
+// ok is false when one of them cannot be read, the page is then served without validators.
+func (h *HomeHandler) homeValidators(ctx context.Context, data HomePageData, logger zerolog.Logger) (etag string, lastModified time.Time, ok bool) {
+	version, err := h.Tracker.GetAssignmentsVersion(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get assignments version, serving the home page without ETag")
+		return "", time.Time{}, false
+	}
+	roster, err := config.Roster(h.ConfigStore)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get parents, serving the home page without ETag")
+		return "", time.Time{}, false
+	}
+	styles := h.parentStyles(logger)
+	if styles == nil {
+		logger.Warn().Msg("Failed to get parent styles, serving the home page without ETag")
+		return "", time.Time{}, false
+	}
+	parents := make([]string, 0, 3*len(roster))
+	for _, parent := range roster {
+		parents = append(parents, parent, styles[parent].Color.String(), styles[parent].Avatar)
+	}
+
+	lastModified = version.LastUpdate
+	syncRun := ""
+	if h.SyncRuns != nil {
+		runs, err := h.SyncRuns.ListRuns(1)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to get the latest sync run, serving the home page without ETag")
+			return "", time.Time{}, false
+		}
+		if len(runs) > 0 {
+			run := runs[0]
+			syncRun = fmt.Sprintf("%d:%s", run.ID, run.Status)
+			runChange := run.StartedAt
+			if run.FinishedAt != nil {
+				runChange = *run.FinishedAt
+			}
+			if runChange.After(lastModified) {
+				lastModified = runChange
+			}
+		}
+	}
+
+	etag = weakETag(
+		templatesVersion(), data.CurrentPath,
+		time.Now().Format("2006-01-02"),
+		strconv.FormatBool(data.IsAuthenticated), strconv.FormatBool(data.ReauthRequired), strconv.FormatBool(data.DemoMode),
+		data.CalendarID, data.CalendarName, data.ErrorMessage, data.SuccessMessage,
+		strings.Join(parents, "\x00"),
+		fmt.Sprintf("%d:%d:%d:%d", version.Count, version.MaxID, version.LastUpdate.Unix(), version.Revision),
+		syncRun,
+	)
+	return etag, lastModified, true
+}
+
+// getSyncRunRows returns the most recent sync runs for the history section.
+// Errors are logged and hide the section rather than failing the page.
+func (h *HomeHandler) getSyncRunRows(logger zerolog.Logger) []SyncRunRow {
+	if h.SyncRuns == nil {
+		return nil
+	}
+	runs, err := h.SyncRuns.ListRuns(homeSyncRunsLimit)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to list sync runs")
+		return nil
+	}
+
+	rows := make([]SyncRunRow, 0, len(runs))
+	for _, run := range runs {
+		row := SyncRunRow{
+			Trigger:          strings.ReplaceAll(run.Trigger.String(), "_", " "),
+			Status:           run.Status,
+			StartedAt:        run.StartedAt.Local().Format("Jan 2, 15:04"),
+			AssignmentsCount: run.AssignmentsCount,
+			Error:            run.Error,
+		}
+		if run.FinishedAt != nil {
+			row.Duration = run.Duration().Round(100 * time.Millisecond).String()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
 // flattenCalendarData converts CalendarWeeks to a MobileCalendarData struct for mobile view
 func (h *HomeHandler) flattenCalendarData(weeks [][]viewhelpers.CalendarDay) MobileCalendarData {
 	var days []CalendarDayJSON
@@ -111,6 +280,7 @@ func (h *HomeHandler) flattenCalendarData(weeks [][]viewhelpers.CalendarDay) Mob
 				DateStr:        day.Date.Format("2006-01-02"),
 				DayOfMonth:     day.DayOfMonth,
 				IsCurrentMonth: day.IsCurrentMonth,
+				IsLocked:       day.Locked,
 			}
 
 			// Build base CSS classes shared by all days
@@ -127,6 +297,9 @@ func (h *HomeHandler) flattenCalendarData(weeks [][]viewhelpers.CalendarDay) Mob
 				dayJSON.CaregiverType = day.Assignment.CaregiverType
 				dayJSON.AssignmentReason = day.Assignment.DecisionReason
 				dayJSON.IsOverridden = day.Assignment.DecisionReason == "Override"
+				dayJSON.Tag = day.Assignment.Tag
+				dayJSON.Color = day.Assignment.Color
+				dayJSON.Badge = day.Assignment.Badge
 
 				// Add assignment-specific classes
 				classes := append(baseClasses, "cursor-pointer", "transition-all", "duration-200")
@@ -138,6 +311,8 @@ func (h *HomeHandler) flattenCalendarData(weeks [][]viewhelpers.CalendarDay) Mob
 					classes = append(classes, "bg-linear-to-br", "from-amber-50", "to-orange-100", "text-orange-900", "border-orange-200", "hover:from-amber-100", "hover:to-orange-200")
 				case "Babysitter":
 					classes = append(classes, "bg-linear-to-br", "from-slate-100", "to-zinc-200", "text-slate-900", "border-slate-300", "hover:from-slate-200", "hover:to-zinc-300")
+				case "OtherParent":
+					classes = append(classes, "bg-linear-to-br", "from-emerald-50", "to-green-50", "text-slate-900", "border-emerald-200")
 				}
 
 				if dayJSON.IsOverridden {
@@ -192,13 +367,13 @@ func (h *HomeHandler) processMessages(r *http.Request, logger zerolog.Logger) (e
 }
 
 // generateCalendarData calculates the date range, reads existing assignments, and structures them for the template.
-func (h *HomeHandler) generateCalendarData(logger zerolog.Logger) (monthName string, weeks [][]viewhelpers.CalendarDay, err error) {
+func (h *HomeHandler) generateCalendarData(ctx context.Context, logger zerolog.Logger) (monthName string, weeks [][]viewhelpers.CalendarDay, err error) {
 	logger.Debug().Msg("Generating calendar view data")
 	refTime := time.Now()
 	startDate, endDate := viewhelpers.CalculateCalendarRange(refTime)
 	logger.Debug().Time("start_date", startDate).Time("end_date", endDate).Msg("Calculated calendar range")
 
-	assignments, err := h.Scheduler.GetAssignmentsInRange(startDate, endDate)
+	assignments, err := h.Scheduler.GetAssignmentsInRange(ctx, startDate, endDate)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to read assignments for calendar view")
 		return "", nil, err
@@ -206,6 +381,15 @@ func (h *HomeHandler) generateCalendarData(logger zerolog.Logger) (monthName str
 
 	logger.Debug().Int("assignment_count", len(assignments)).Msg("Successfully read assignments")
 
+	styles := h.parentStyles(logger)
+	if styles == nil {
+		logger.Warn().Msg("Failed to get parent styles, using the default ones")
+		styles = make(map[string]config.ParentStyle)
+		if parentA, parentB, err := h.ConfigStore.GetParents(); err == nil {
+			styles[parentA], styles[parentB] = config.DefaultParentStyles()
+		}
+	}
+
 	// Convert scheduler-internal assignments to presentation DTOs at the boundary.
 	displayAssignments := make([]*viewhelpers.DisplayAssignment, len(assignments))
 	for i, a := range assignments {
@@ -216,10 +400,26 @@ func (h *HomeHandler) generateCalendarData(logger zerolog.Logger) (monthName str
 			ParentType:     a.ParentType.String(),
 			CaregiverType:  a.CaregiverType.String(),
 			DecisionReason: string(a.DecisionReason),
+			Tag:            a.Tag.String(),
+		}
+		if style, ok := styles[a.Parent]; ok && a.ParentType != scheduler.ParentTypeBabysitter {
+			displayAssignments[i].Color = style.Color.Hex()
+			displayAssignments[i].Badge = style.Badge(a.Parent)
 		}
 	}
 
 	monthName, weeks = viewhelpers.StructureAssignmentsForTemplate(startDate, endDate, displayAssignments)
+
+	// The locked days are only marked, a failure to read them does not hide the calendar
+	lockedRanges, err := h.Tracker.GetLockedRanges(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get locked ranges for calendar view")
+	}
+	for _, week := range weeks {
+		for i := range week {
+			week[i].Locked = lockedRanges.Contains(week[i].Date)
+		}
+	}
 	logger.Debug().Str("month_name", monthName).Int("week_count", len(weeks)).Msg("Structured calendar data for template")
 	return monthName, weeks, nil
 }
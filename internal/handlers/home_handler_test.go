@@ -1,12 +1,25 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/belphemur/night-routine/internal/token"
 	"github.com/belphemur/night-routine/internal/viewhelpers"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func TestHomeHandler_flattenCalendarData(t *testing.T) {
@@ -69,6 +82,8 @@ func TestHomeHandler_flattenCalendarData(t *testing.T) {
 						Parent:         "Alice",
 						ParentType:     "ParentA",
 						DecisionReason: "TotalCount",
+						Color:          "#3f51b5",
+						Badge:          "A",
 					},
 				},
 			},
@@ -84,6 +99,8 @@ func TestHomeHandler_flattenCalendarData(t *testing.T) {
 		assert.Equal(t, int64(1), day.AssignmentID)
 		assert.Equal(t, "Alice", day.AssignmentParent)
 		assert.Equal(t, "TotalCount", day.AssignmentReason)
+		assert.Equal(t, "#3f51b5", day.Color)
+		assert.Equal(t, "A", day.Badge)
 		assert.False(t, day.IsOverridden)
 		assert.Contains(t, day.CSSClasses, "from-blue-50")
 		assert.Contains(t, day.CSSClasses, "to-indigo-100")
@@ -154,6 +171,24 @@ func TestHomeHandler_flattenCalendarData(t *testing.T) {
 		assert.Contains(t, day.CSSClasses, "overridden")
 	})
 
+	t.Run("locked day", func(t *testing.T) {
+		date := time.Date(2025, 11, 27, 0, 0, 0, 0, time.UTC)
+		weeks := [][]viewhelpers.CalendarDay{
+			{
+				{
+					Date:           date,
+					DayOfMonth:     27,
+					IsCurrentMonth: true,
+					Locked:         true,
+				},
+			},
+		}
+
+		result := handler.flattenCalendarData(weeks)
+		require.Len(t, result.Days, 1)
+		assert.True(t, result.Days[0].IsLocked)
+	})
+
 	t.Run("day not in current month", func(t *testing.T) {
 		date := time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)
 		weeks := [][]viewhelpers.CalendarDay{
@@ -275,3 +310,135 @@ func TestHomeHandler_flattenCalendarData(t *testing.T) {
 		assert.Contains(t, classes, "hover:shadow-lg")
 	})
 }
+
+func TestHomeHandler_getSyncRunRows(t *testing.T) {
+	t.Run("no store", func(t *testing.T) {
+		handler := &HomeHandler{BaseHandler: &BaseHandler{}}
+		assert.Nil(t, handler.getSyncRunRows(zerolog.Nop()))
+	})
+
+	t.Run("recorded runs", func(t *testing.T) {
+		syncRunsHandler, syncRuns := setupTestSyncRunsHandler(t)
+		handler := NewHomeHandler(syncRunsHandler.BaseHandler, nil, 0)
+
+		require.NoError(t, syncRuns.RecordRun(context.Background(), constants.SyncTriggerCalendarSelected, func(context.Context) (int, error) { return 5, nil }))
+		require.Error(t, syncRuns.RecordRun(context.Background(), constants.SyncTriggerManual, func(context.Context) (int, error) { return 0, errors.New("quota exceeded") }))
+
+		rows := handler.getSyncRunRows(zerolog.Nop())
+		require.Len(t, rows, 2)
+
+		assert.Equal(t, "manual", rows[0].Trigger)
+		assert.Equal(t, database.SyncRunStatusFailed, rows[0].Status)
+		assert.Equal(t, "quota exceeded", rows[0].Error)
+
+		assert.Equal(t, "calendar selected", rows[1].Trigger)
+		assert.Equal(t, database.SyncRunStatusSuccess, rows[1].Status)
+		assert.Equal(t, 5, rows[1].AssignmentsCount)
+		assert.NotEmpty(t, rows[1].Duration)
+		assert.NotEmpty(t, rows[1].StartedAt)
+	})
+}
+
+func TestHomeHandler_ConditionalRequests(t *testing.T) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "home.db"),
+		Mode:        "rwc",
+		Cache:       database.CachePrivate,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveParents(t.Context(), "Alice", "Bob"))
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	syncRuns, err := database.NewSyncRunStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(configStore, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, syncRuns)
+	require.NoError(t, err)
+	handler := NewHomeHandler(baseHandler, nil, 8)
+
+	get := func(target string, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for name, value := range headers {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler.handleHome(w, r)
+		return w
+	}
+
+	first := get("/", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Empty(t, first.Header().Get("Last-Modified"), "nothing was written yet")
+
+	notModified := get("/", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	withMessage := get("/?error=unknown", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, withMessage.Code, "messages are part of the page")
+
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", time.Now(), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	afterAssignment := get("/", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, afterAssignment.Code, "a written assignment changes the ETag")
+	etag = afterAssignment.Header().Get("ETag")
+	lastModified := afterAssignment.Header().Get("Last-Modified")
+	assert.NotEmpty(t, lastModified)
+
+	assert.Equal(t, http.StatusNotModified, get("/", map[string]string{"If-Modified-Since": lastModified}).Code)
+
+	runID, err := syncRuns.StartRun(constants.SyncTriggerManual)
+	require.NoError(t, err)
+	require.NoError(t, syncRuns.FinishRun(runID, 0, signals.SyncProgressData{}, nil))
+	afterSync := get("/", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, afterSync.Code, "a sync run changes the ETag")
+	etag = afterSync.Header().Get("ETag")
+
+	require.NoError(t, configStore.SaveParentStyles(t.Context(), config.ParentStyle{Color: constants.ParentColorBasil, Avatar: "🦊"}, config.ParentStyle{Color: constants.ParentColorTomato}))
+	afterStyle := get("/", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, afterStyle.Code, "a parent style changes the ETag")
+	etag = afterStyle.Header().Get("ETag")
+
+	_, err = tracker.LockRange(t.Context(), time.Now(), time.Now().AddDate(0, 0, 3))
+	require.NoError(t, err)
+	afterLock := get("/", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, afterLock.Code, "a locked range changes the ETag")
+	assert.Contains(t, afterLock.Body.String(), "🔒 Locked")
+	assert.NotContains(t, afterLock.Body.String(), "Demo mode")
+
+	baseHandler.Build.Features = []string{"demo_mode"}
+	demo := get("/", nil)
+	assert.Equal(t, http.StatusOK, demo.Code)
+	assert.Contains(t, demo.Body.String(), "Demo mode")
+}
+
+func TestHomeHandler_getImbalance(t *testing.T) {
+	env := setupTestDayHandler(t, true)
+	today := time.Now()
+	for i := 0; i < 9; i++ {
+		_, err := env.tracker.RecordAssignment(t.Context(), "Bob", today.AddDate(0, 0, -i), false, fairness.DecisionReasonTotalCount)
+		require.NoError(t, err)
+	}
+	_, err := env.tracker.RecordAssignment(t.Context(), "Alice", today.AddDate(0, 0, -10), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	imbalance := NewHomeHandler(env.handler.BaseHandler, nil, 8).getImbalance(t.Context(), []string{"Alice", "Bob"}, zerolog.Nop())
+	require.NotNil(t, imbalance)
+	assert.Equal(t, fairness.Imbalance{Busier: "Bob", Other: "Alice", BusierCount: 9, OtherCount: 1}, *imbalance)
+
+	assert.Nil(t, NewHomeHandler(env.handler.BaseHandler, nil, 9).getImbalance(t.Context(), []string{"Alice", "Bob"}, zerolog.Nop()), "under the threshold")
+	assert.Nil(t, NewHomeHandler(env.handler.BaseHandler, nil, 0).getImbalance(t.Context(), []string{"Alice", "Bob"}, zerolog.Nop()), "banner disabled")
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/features"
+)
+
+const (
+	// icsDateFormat is the format of the all-day dates of the feed
+	icsDateFormat = "20060102"
+	// icsTimestampFormat is the format of the UTC timestamps of the feed
+	icsTimestampFormat = "20060102T150405Z"
+	// icsPastDays is how many days before today the feed starts, so that the recent nights stay visible
+	icsPastDays = 30
+	// icsLineLimit is the length in octets after which the lines of the feed are folded (RFC 5545 §3.1)
+	icsLineLimit = 75
+)
+
+// ICSHandler serves the schedule as an iCalendar feed, for the calendar applications subscribing to
+// a URL rather than using Google Calendar. Subscriptions cannot sign in, so the feed is authenticated
+// by the self-service link token of a parent: revoking the link also revokes the feed.
+type ICSHandler struct {
+	*BaseHandler
+	links       ParentLinks
+	assignments AssignmentStreamer
+	emoji       string
+	identifier  string
+	now         func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewICSHandler creates a new iCalendar feed handler, titling the events with emoji and making their
+// UID unique with identifier, as the Google Calendar events
+func NewICSHandler(baseHandler *BaseHandler, links ParentLinks, assignments AssignmentStreamer, emoji, identifier string) *ICSHandler {
+	return &ICSHandler{
+		BaseHandler: baseHandler,
+		links:       links,
+		assignments: assignments,
+		emoji:       emoji,
+		identifier:  identifier,
+		now:         time.Now,
+	}
+}
+
+// RegisterRoutes registers the iCalendar feed route
+func (h *ICSHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/calendar.ics", h.handleICS, http.MethodGet)
+}
+
+// handleICS renders the nights of the main rotation from icsPastDays ago onward as an iCalendar
+// feed. The token query parameter must be the token of a self-service link. The feed is not found
+// while the ics_feed feature flag is disabled.
+func (h *ICSHandler) handleICS(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleICS").Logger()
+
+	// The token is the credential, it must not leak through the caches or the links followed
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.Features.Enabled(features.ICSFeed) {
+		http.NotFound(w, r)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	parentKey, ok, err := h.links.ParentForToken(token)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to look up parent link")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		handlerLogger.Warn().Msg("Unknown token for the iCalendar feed")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	handlerLogger = handlerLogger.With().Str("parent_key", parentKey).Logger()
+
+	now := h.now()
+	start := time.Date(now.Year(), now.Month(), now.Day()-icsPastDays, 0, 0, 0, 0, now.Location())
+	end := time.Date(9999, time.December, 31, 0, 0, 0, 0, now.Location())
+
+	var body strings.Builder
+	writer := &icsWriter{buf: &body}
+	writer.line("BEGIN:VCALENDAR")
+	writer.line("VERSION:2.0")
+	writer.line("PRODID:-//Night Routine//Night Routine Scheduler//EN")
+	writer.line("CALSCALE:GREGORIAN")
+	writer.line("METHOD:PUBLISH")
+	writer.property("X-WR-CALNAME", "Night Routine")
+	writer.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	writer.line("X-PUBLISHED-TTL:PT1H")
+
+	events := 0
+	err = h.assignments.ForEachAssignmentInRange(r.Context(), start, end, func(a *fairness.Assignment) error {
+		events++
+		h.writeEvent(writer, a)
+		return nil
+	})
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to read assignments for the iCalendar feed")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writer.line("END:VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="night-routine.ics"`)
+	if _, err := io.WriteString(w, body.String()); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to write the iCalendar feed")
+		return
+	}
+	handlerLogger.Debug().Int("events", events).Msg("iCalendar feed served")
+}
+
+// writeEvent writes the all-day event of a night, titled as its Google Calendar event
+func (h *ICSHandler) writeEvent(writer *icsWriter, a *fairness.Assignment) {
+	day := time.Date(a.Date.Year(), a.Date.Month(), a.Date.Day(), 0, 0, 0, 0, time.UTC)
+	stamp := a.UpdatedAt
+	if stamp.IsZero() {
+		stamp = a.CreatedAt
+	}
+
+	var description string
+	if a.CaregiverType == fairness.CaregiverTypeBabysitter {
+		description = fmt.Sprintf("Night routine handled by babysitter %s. Reason: %s", a.Parent, a.DecisionReason.String())
+	} else {
+		description = fmt.Sprintf("Night routine duty assigned to %s. Reason: %s", a.Parent, a.DecisionReason.String())
+	}
+	if label := a.Tag.Label(); label != "" {
+		description += "\nTag: " + label
+	}
+
+	writer.line("BEGIN:VEVENT")
+	writer.property("UID", "assignment-"+strconv.FormatInt(a.ID, 10)+"@"+h.identifier)
+	writer.line("DTSTAMP:" + stamp.UTC().Format(icsTimestampFormat))
+	writer.line("DTSTART;VALUE=DATE:" + day.Format(icsDateFormat))
+	writer.line("DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format(icsDateFormat))
+	writer.line("SEQUENCE:" + strconv.FormatInt(a.Version, 10))
+	writer.property("SUMMARY", fmt.Sprintf("[%s] %sRoutine", a.Parent, h.emoji))
+	writer.property("DESCRIPTION", description)
+	writer.line("TRANSP:TRANSPARENT")
+	writer.line("END:VEVENT")
+}
+
+// icsWriter writes the content lines of an iCalendar object, ended by CRLF and folded at icsLineLimit octets
+type icsWriter struct {
+	buf *strings.Builder
+}
+
+// property writes a property with a text value, escaped as RFC 5545 §3.3.11 requires
+func (w *icsWriter) property(name, value string) {
+	w.line(name + ":" + escapeICSText(value))
+}
+
+// line writes a content line, folding it without splitting a UTF-8 character
+func (w *icsWriter) line(content string) {
+	limit := icsLineLimit
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		w.buf.WriteString(content[:cut])
+		w.buf.WriteString("\r\n ")
+		content = content[cut:]
+		// The leading space of the continuation lines counts in their length
+		limit = icsLineLimit - 1
+	}
+	w.buf.WriteString(content)
+	w.buf.WriteString("\r\n")
+}
+
+// icsTextEscaper escapes the characters with a meaning in the text values
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escapeICSText escapes a text value of the feed
+func escapeICSText(value string) string {
+	return icsTextEscaper.Replace(value)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/features"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestICSHandler(t *testing.T) (*ICSHandler, *features.Flags, *fairness.Tracker, string) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	links, err := database.NewParentLinkStore(db)
+	require.NoError(t, err)
+	linkToken, err := links.CreateLink("parent_a", time.Now())
+	require.NoError(t, err)
+	flagStore, err := database.NewFeatureFlagStore(db)
+	require.NoError(t, err)
+	flags := features.New(flagStore)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+	baseHandler.Features = flags
+
+	handler := NewICSHandler(baseHandler, links, tracker, "🌃👶", "night-routine")
+	handler.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local) }
+	return handler, flags, tracker, linkToken
+}
+
+func TestICSHandler_Feed(t *testing.T) {
+	handler, _, tracker, linkToken := setupTestICSHandler(t)
+	old, err := tracker.RecordAssignment(t.Context(), "ParentB", time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	night, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local), true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.handleICS(w, httptest.NewRequest(http.MethodGet, "/calendar.ics?token="+linkToken, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 1, strings.Count(body, "BEGIN:VEVENT"), "the nights before the last 30 days are left out")
+	assert.NotContains(t, body, "assignment-"+strconv.FormatInt(old.ID, 10)+"@")
+	assert.Contains(t, body, "UID:assignment-"+strconv.FormatInt(night.ID, 10)+"@night-routine\r\n")
+	assert.Contains(t, body, "DTSTART;VALUE=DATE:20261017\r\nDTEND;VALUE=DATE:20261018\r\n")
+	assert.Contains(t, body, "SUMMARY:[ParentA] 🌃👶Routine\r\n")
+	assert.Contains(t, body, "DESCRIPTION:Night routine duty assigned to ParentA. Reason: Override\r\n")
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), icsLineLimit, line)
+	}
+}
+
+func TestICSHandler_Token(t *testing.T) {
+	handler, flags, _, linkToken := setupTestICSHandler(t)
+
+	for _, target := range []string{"/calendar.ics", "/calendar.ics?token=unknown"} {
+		w := httptest.NewRecorder()
+		handler.handleICS(w, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, target)
+	}
+
+	// The feed is turned off by the admin
+	require.NoError(t, flags.Set(string(features.ICSFeed), false, time.Now()))
+	w := httptest.NewRecorder()
+	handler.handleICS(w, httptest.NewRequest(http.MethodGet, "/calendar.ics?token="+linkToken, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestICSWriter_FoldAndEscape(t *testing.T) {
+	var b strings.Builder
+	writer := &icsWriter{buf: &b}
+	writer.property("DESCRIPTION", "a; b, c\\d\nnext "+strings.Repeat("é", 60))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], `DESCRIPTION:a\; b\, c\\d\nnext `))
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), icsLineLimit)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "), "continuation lines start with a space")
+		}
+	}
+	unfolded := strings.ReplaceAll(strings.TrimSuffix(b.String(), "\r\n"), "\r\n ", "")
+	assert.True(t, strings.HasSuffix(unfolded, strings.Repeat("é", 60)), "no character is split by the folding")
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+// LockHandler locks ranges of days whose assignments the schedule regeneration keeps as they are
+type LockHandler struct {
+	*BaseHandler
+}
+
+// NewLockHandler creates a new locked ranges handler
+func NewLockHandler(baseHandler *BaseHandler) *LockHandler {
+	return &LockHandler{BaseHandler: baseHandler}
+}
+
+// RegisterRoutes registers the locked ranges routes
+func (h *LockHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/locks", h.handleLocks, http.MethodGet, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/locks/{id}", h.handleUnlockRange, http.MethodDelete)
+}
+
+// LockedRangeResponse is a locked range in the API responses
+type LockedRangeResponse struct {
+	ID    int64  `json:"id"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// lockRangeRequest locks the days from start to end, both included, as YYYY-MM-DD
+type lockRangeRequest struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// newLockedRangeResponse converts a locked range for the API responses
+func newLockedRangeResponse(lockedRange fairness.LockedRange) LockedRangeResponse {
+	return LockedRangeResponse{
+		ID:    lockedRange.ID,
+		Start: lockedRange.Start.Format(time.DateOnly),
+		End:   lockedRange.End.Format(time.DateOnly),
+	}
+}
+
+// handleLocks lists the locked ranges on GET and locks a range on POST. Locking changes no
+// assignment, it keeps the existing ones from the next regenerations.
+func (h *LockHandler) handleLocks(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleLocks").Str("method", r.Method).Logger()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to locked ranges")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		ranges, err := h.Tracker.GetLockedRanges(r.Context())
+		if err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to get locked ranges")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve locked ranges", handlerLogger)
+			return
+		}
+		response := make([]LockedRangeResponse, 0, len(ranges))
+		for _, lockedRange := range ranges {
+			response = append(response, newLockedRangeResponse(lockedRange))
+		}
+		writeJSON(w, http.StatusOK, response, handlerLogger)
+		return
+	}
+
+	var req lockRangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, start and end are required", handlerLogger)
+		return
+	}
+	start, startErr := time.ParseInLocation(time.DateOnly, req.Start, time.Local)
+	end, endErr := time.ParseInLocation(time.DateOnly, req.End, time.Local)
+	if startErr != nil || endErr != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "start and end must be formatted as YYYY-MM-DD", handlerLogger)
+		return
+	}
+
+	lockedRange, err := h.Tracker.LockRange(r.Context(), start, end)
+	if errors.Is(err, fairness.ErrInvalidLockedRange) {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "end must not be before start", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to lock range")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to lock range", handlerLogger)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newLockedRangeResponse(*lockedRange), handlerLogger)
+}
+
+// handleUnlockRange removes a locked range on DELETE. Its assignments are recalculated by the next
+// regeneration, not right away.
+func (h *LockHandler) handleUnlockRange(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleUnlockRange").Logger()
+
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodDelete)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to unlock a range")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid locked range ID", handlerLogger)
+		return
+	}
+
+	err = h.Tracker.UnlockRange(r.Context(), id)
+	if errors.Is(err, fairness.ErrLockedRangeNotFound) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Locked range not found", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Int64("locked_range_id", id).Msg("Failed to unlock range")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to unlock range", handlerLogger)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
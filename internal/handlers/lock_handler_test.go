@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockHandler(t *testing.T) {
+	env := setupTestDayHandler(t, true)
+	handler := NewLockHandler(env.handler.BaseHandler)
+
+	lock := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.handleLocks(w, httptest.NewRequest(http.MethodPost, "/api/v1/locks", strings.NewReader(body)))
+		return w
+	}
+	unlock := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/locks/"+id, nil)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.handleUnlockRange(w, req)
+		return w
+	}
+	list := func() []LockedRangeResponse {
+		w := httptest.NewRecorder()
+		handler.handleLocks(w, httptest.NewRequest(http.MethodGet, "/api/v1/locks", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var ranges []LockedRangeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ranges))
+		return ranges
+	}
+
+	assert.Empty(t, list())
+	assert.Equal(t, http.StatusBadRequest, lock(`{"start":"2026-07-04"}`).Code, "end is required")
+	assert.Equal(t, http.StatusBadRequest, lock(`{"start":"2026-08-31","end":"2026-07-04"}`).Code, "end before start")
+
+	w := lock(`{"start":"2026-07-04","end":"2026-08-31"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created LockedRangeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "2026-07-04", created.Start)
+	assert.Equal(t, "2026-08-31", created.End)
+	assert.Equal(t, []LockedRangeResponse{created}, list())
+
+	_, day := getDay(t, env.handler, "2026-08-15")
+	assert.True(t, day.Locked, "the day detail shows the lock")
+	_, day = getDay(t, env.handler, "2026-09-01")
+	assert.False(t, day.Locked)
+
+	assert.Equal(t, http.StatusBadRequest, unlock("abc").Code)
+	assert.Equal(t, http.StatusNoContent, unlock(strconv.FormatInt(created.ID, 10)).Code)
+	assert.Equal(t, http.StatusNotFound, unlock(strconv.FormatInt(created.ID, 10)).Code)
+	assert.Empty(t, list())
+}
+
+func TestLockHandler_Unauthenticated(t *testing.T) {
+	handler := NewLockHandler(setupTestDayHandler(t, false).handler.BaseHandler)
+
+	w := httptest.NewRecorder()
+	handler.handleLocks(w, httptest.NewRequest(http.MethodGet, "/api/v1/locks", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.handleLocks(w, httptest.NewRequest(http.MethodPut, "/api/v1/locks", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
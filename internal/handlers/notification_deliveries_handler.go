@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+)
+
+// Bounds of the number of notification deliveries returned by the API
+const (
+	defaultDeliveriesLimit = 20
+	maxDeliveriesLimit     = 200
+)
+
+// NotificationDeliveriesHandler exposes the log of notification deliveries
+type NotificationDeliveriesHandler struct {
+	*BaseHandler
+	deliveries *database.NotificationDeliveryStore
+}
+
+// NewNotificationDeliveriesHandler creates a new notification deliveries handler
+func NewNotificationDeliveriesHandler(baseHandler *BaseHandler, deliveries *database.NotificationDeliveryStore) *NotificationDeliveriesHandler {
+	return &NotificationDeliveriesHandler{BaseHandler: baseHandler, deliveries: deliveries}
+}
+
+// RegisterRoutes registers the notification delivery routes
+func (h *NotificationDeliveriesHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/notification-deliveries", h.handleListDeliveries, http.MethodGet)
+}
+
+// NotificationDeliveryResponse is one notification delivery in the API response
+type NotificationDeliveryResponse struct {
+	ID          int64     `json:"id"`
+	Channel     string    `json:"channel"`
+	Event       string    `json:"event"`
+	Subject     string    `json:"subject"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// NotificationDeliveriesResponse is the response of the notification deliveries API
+type NotificationDeliveriesResponse struct {
+	Deliveries []NotificationDeliveryResponse `json:"deliveries"`
+}
+
+// handleListDeliveries returns the most recent notification deliveries, newest first.
+// The optional limit query parameter caps the number of deliveries (default 20, at most 200).
+func (h *NotificationDeliveriesHandler) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleListDeliveries").Logger()
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	limit := defaultDeliveriesLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > maxDeliveriesLimit {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "limit must be a number between 1 and 200", handlerLogger)
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.deliveries.ListDeliveries(limit)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list notification deliveries")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve notification deliveries", handlerLogger)
+		return
+	}
+
+	response := NotificationDeliveriesResponse{Deliveries: make([]NotificationDeliveryResponse, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, NotificationDeliveryResponse{
+			ID:          delivery.ID,
+			Channel:     delivery.Channel,
+			Event:       delivery.Event,
+			Subject:     delivery.Subject,
+			Status:      delivery.Status,
+			Error:       delivery.Error,
+			DeliveredAt: delivery.DeliveredAt,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
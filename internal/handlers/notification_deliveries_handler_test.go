@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestNotificationDeliveriesHandler(t *testing.T) (*NotificationDeliveriesHandler, *database.NotificationDeliveryStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "deliveries.db"),
+		Mode:        "rwc",
+		Cache:       database.CachePrivate,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	deliveries, err := database.NewNotificationDeliveryStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	tokenManager := token.NewTokenManager(tokenStore, oauthCfg)
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, tokenManager, tracker, nil)
+	require.NoError(t, err)
+
+	return NewNotificationDeliveriesHandler(baseHandler, deliveries), deliveries
+}
+
+func TestNotificationDeliveriesHandler_List(t *testing.T) {
+	handler, deliveries := setupTestNotificationDeliveriesHandler(t)
+	require.NoError(t, deliveries.RecordDelivery("slack", "failure_alert", "Night Routine: sync failing", nil))
+	require.NoError(t, deliveries.RecordDelivery("email", "failure_alert", "Night Routine: sync failing", errors.New("connection refused")))
+
+	w := httptest.NewRecorder()
+	handler.handleListDeliveries(w, httptest.NewRequest(http.MethodGet, "/api/v1/notification-deliveries", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var resp NotificationDeliveriesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Deliveries, 2)
+
+	assert.Equal(t, "email", resp.Deliveries[0].Channel)
+	assert.Equal(t, database.DeliveryStatusFailed, resp.Deliveries[0].Status)
+	assert.Equal(t, "connection refused", resp.Deliveries[0].Error)
+
+	assert.Equal(t, "slack", resp.Deliveries[1].Channel)
+	assert.Equal(t, "failure_alert", resp.Deliveries[1].Event)
+	assert.Equal(t, database.DeliveryStatusSuccess, resp.Deliveries[1].Status)
+	assert.Empty(t, resp.Deliveries[1].Error)
+}
+
+func TestNotificationDeliveriesHandler_Limit(t *testing.T) {
+	handler, deliveries := setupTestNotificationDeliveriesHandler(t)
+	for range 3 {
+		require.NoError(t, deliveries.RecordDelivery("slack", "failure_alert", "subject", nil))
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedCode       int
+		expectedDeliveries int
+	}{
+		{name: "default", query: "", expectedCode: http.StatusOK, expectedDeliveries: 3},
+		{name: "limit applied", query: "?limit=2", expectedCode: http.StatusOK, expectedDeliveries: 2},
+		{name: "not a number", query: "?limit=abc", expectedCode: http.StatusBadRequest},
+		{name: "too large", query: "?limit=201", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleListDeliveries(w, httptest.NewRequest(http.MethodGet, "/api/v1/notification-deliveries"+tt.query, nil))
+
+			require.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var resp NotificationDeliveriesResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Len(t, resp.Deliveries, tt.expectedDeliveries)
+		})
+	}
+}
+
+func TestNotificationDeliveriesHandler_MethodNotAllowed(t *testing.T) {
+	handler, _ := setupTestNotificationDeliveriesHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.handleListDeliveries(w, httptest.NewRequest(http.MethodPost, "/api/v1/notification-deliveries", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
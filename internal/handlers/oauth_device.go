@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/googleclient"
+	"golang.org/x/oauth2"
+)
+
+// Device flow statuses reported by /auth/device/status
+const (
+	DeviceFlowStatusPending  = "pending"
+	DeviceFlowStatusComplete = "complete"
+	DeviceFlowStatusExpired  = "expired"
+	DeviceFlowStatusFailed   = "failed"
+)
+
+// deviceFlow tracks the device authorization currently awaiting user approval
+type deviceFlow struct {
+	mu       sync.Mutex
+	response *oauth2.DeviceAuthResponse
+	status   string
+	cancel   context.CancelFunc // Stops the polling of the pending flow, nil when none polls
+}
+
+// DeviceAuthPageData contains data for the device authorization page
+type DeviceAuthPageData struct {
+	BasePageData
+	UserCode        string
+	VerificationURL string
+	ExpiresAt       time.Time
+}
+
+// DeviceFlowStatusResponse represents the JSON response of the device flow status endpoint
+type DeviceFlowStatusResponse struct {
+	Status string `json:"status"`
+}
+
+// handleDeviceAuth starts the OAuth device authorization flow, or shows the one already
+// pending, and displays the code to enter on another device
+func (h *OAuthHandler) handleDeviceAuth(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleDeviceAuth").Logger()
+
+	h.deviceFlow.mu.Lock()
+	defer h.deviceFlow.mu.Unlock()
+
+	da := h.deviceFlow.response
+	if da == nil || h.deviceFlow.status != DeviceFlowStatusPending {
+		handlerLogger.Info().Msg("Starting OAuth device authorization flow")
+		var err error
+		da, err = h.OAuthConfig.DeviceAuth(googleclient.Context(r.Context()), oauth2.AccessTypeOffline)
+		if err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to start device authorization")
+			http.Error(w, "Failed to start device authorization", http.StatusInternalServerError)
+			return
+		}
+		h.deviceFlow.response = da
+		h.deviceFlow.status = DeviceFlowStatusPending
+		if h.deviceFlow.cancel != nil {
+			h.deviceFlow.cancel()
+		}
+		var ctx context.Context
+		ctx, h.deviceFlow.cancel = context.WithCancel(context.Background())
+
+		// Logged as well so a fully headless setup can be linked from the console
+		handlerLogger.Info().
+			Str("verification_url", da.VerificationURI).
+			Str("user_code", da.UserCode).
+			Time("expires_at", da.Expiry).
+			Msg("Enter the code at the verification URL to link Google Calendar")
+
+		go h.pollDeviceToken(ctx, da)
+	}
+
+	h.RenderTemplate(w, "device_auth.html", DeviceAuthPageData{
+		BasePageData:    h.NewBasePageData(r, false),
+		UserCode:        da.UserCode,
+		VerificationURL: da.VerificationURI,
+		ExpiresAt:       da.Expiry,
+	})
+}
+
+// pollDeviceToken waits for the user to approve the device code and stores the token, until ctx is
+// cancelled by Close or by a new flow. It runs detached from the request that started the flow.
+func (h *OAuthHandler) pollDeviceToken(ctx context.Context, da *oauth2.DeviceAuthResponse) {
+	pollLogger := h.logger.With().Str("component", "device-auth-poll").Logger()
+
+	status := DeviceFlowStatusComplete
+	token, err := h.OAuthConfig.DeviceAccessToken(googleclient.Context(ctx), da)
+	if err == nil {
+		err = h.TokenManager.SaveToken(ctx, token)
+	}
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.Is(err, context.Canceled) {
+			status = DeviceFlowStatusFailed
+			pollLogger.Warn().Msg("Device authorization stopped before it was approved")
+		} else if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "expired_token") {
+			status = DeviceFlowStatusExpired
+			pollLogger.Warn().Msg("Device code expired before it was approved")
+		} else {
+			status = DeviceFlowStatusFailed
+			pollLogger.Error().Err(err).Msg("Device authorization failed")
+		}
+	} else {
+		pollLogger.Info().Msg("Device authorization completed, token saved")
+	}
+
+	h.deviceFlow.mu.Lock()
+	defer h.deviceFlow.mu.Unlock()
+	if h.deviceFlow.response == da {
+		h.deviceFlow.status = status
+	}
+}
+
+// Close stops polling the pending device authorization. Call it when the server shuts down.
+func (h *OAuthHandler) Close() {
+	h.deviceFlow.mu.Lock()
+	defer h.deviceFlow.mu.Unlock()
+	if h.deviceFlow.cancel != nil {
+		h.deviceFlow.cancel()
+		h.deviceFlow.cancel = nil
+	}
+}
+
+// handleDeviceStatus reports the state of the pending device authorization
+func (h *OAuthHandler) handleDeviceStatus(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleDeviceStatus").Logger()
+
+	h.deviceFlow.mu.Lock()
+	status := h.deviceFlow.status
+	h.deviceFlow.mu.Unlock()
+	if status == "" {
+		status = DeviceFlowStatusExpired
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(DeviceFlowStatusResponse{Status: status}); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
@@ -1,19 +1,37 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/googleclient"
 	"golang.org/x/oauth2"
 )
 
+// oauthStateTTL is how long a generated OAuth state stays valid for the callback
+const oauthStateTTL = 10 * time.Minute
+
+// oauthStateCookieName is the cookie binding the OAuth state to the browser that started the flow
+const oauthStateCookieName = "night_routine_oauth_state"
+
 // OAuthHandler manages OAuth2 authentication and token storage
 type OAuthHandler struct {
-	*BaseHandler // Embed BaseHandler
-	OAuthConfig  *oauth2.Config
+	*BaseHandler    // Embed BaseHandler
+	OAuthConfig     *oauth2.Config
+	CalendarService calendar.CalendarService
+	// DeviceAuth links Google with the device code flow, for servers the browser cannot reach
+	DeviceAuth bool
+	deviceFlow deviceFlow
 }
 
 // NewOAuthHandler creates a new OAuth handler using the BaseHandler
-func NewOAuthHandler(baseHandler *BaseHandler) (*OAuthHandler, error) {
+func NewOAuthHandler(baseHandler *BaseHandler, calendarService calendar.CalendarService, deviceAuth bool) (*OAuthHandler, error) {
 	// Logger is inherited from BaseHandler
 	baseHandler.logger.Debug().Msg("Initializing OAuth handler")
 
@@ -21,15 +39,19 @@ func NewOAuthHandler(baseHandler *BaseHandler) (*OAuthHandler, error) {
 	oauthConfig := baseHandler.ConfigStore.GetOAuthConfig()
 
 	return &OAuthHandler{
-		BaseHandler: baseHandler,
-		OAuthConfig: oauthConfig,
+		BaseHandler:     baseHandler,
+		OAuthConfig:     oauthConfig,
+		CalendarService: calendarService,
+		DeviceAuth:      deviceAuth,
 	}, nil
 }
 
 // RegisterRoutes registers the OAuth routes
 func (h *OAuthHandler) RegisterRoutes() {
-	http.HandleFunc("/auth", h.handleAuth)
-	http.HandleFunc("/oauth/callback", h.handleCallback)
+	handleMethods(http.DefaultServeMux, "/auth", h.handleAuth, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/oauth/callback", h.handleCallback, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/auth/disconnect", h.handleDisconnect, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/auth/device/status", h.handleDeviceStatus, http.MethodGet)
 }
 
 // handleAuth initiates the OAuth flow
@@ -37,8 +59,28 @@ func (h *OAuthHandler) handleAuth(w http.ResponseWriter, r *http.Request) {
 	// Use logger from embedded BaseHandler
 	handlerLogger := h.logger.With().Str("handler", "handleAuth").Logger()
 	handlerLogger.Info().Msg("Initiating OAuth flow")
-	// Consider adding state generation and validation for security
-	state := "pseudo-random-state" // Replace with actual random state generation
+
+	if h.DeviceAuth {
+		h.handleDeviceAuth(w, r)
+		return
+	}
+
+	// The state is persisted so the callback can be validated even across restarts
+	state, err := generateOAuthState()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to generate OAuth state")
+		http.Error(w, "Failed to start authentication", http.StatusInternalServerError)
+		return
+	}
+	if err := h.TokenStore.SaveOAuthState(state, time.Now().Add(oauthStateTTL)); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save OAuth state")
+		http.Error(w, "Failed to start authentication", http.StatusInternalServerError)
+		return
+	}
+	// Only the browser that started the flow may complete it, so that a callback URL carrying the
+	// code of someone else cannot link their Google account (login CSRF)
+	h.setStateCookie(w, state, int(oauthStateTTL.Seconds()))
+
 	// Use OAuthConfig from the struct
 	url := h.OAuthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce) // Force approval prompt
 	handlerLogger.Debug().Str("redirect_url", url).Msg("Redirecting user to Google for authentication")
@@ -51,13 +93,29 @@ func (h *OAuthHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
 	handlerLogger := h.logger.With().Str("handler", "handleCallback").Logger()
 	handlerLogger.Info().Msg("Handling OAuth callback")
 
-	// Add state validation here
-	// state := r.URL.Query().Get("state")
-	// if state != "pseudo-random-state" { // Compare with stored state
-	// 	handlerLogger.Error().Str("received_state", state).Msg("Invalid OAuth state")
-	// 	http.Error(w, "Invalid state", http.StatusBadRequest)
-	// 	return
-	// }
+	// The state must be the one given to this browser, checked before consuming it so that a
+	// callback from another browser does not use up the state of the flow in progress
+	state := r.URL.Query().Get("state")
+	cookie, err := r.Cookie(oauthStateCookieName)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		handlerLogger.Warn().Msg("OAuth state does not match the browser that started the flow")
+		http.Error(w, "Invalid state", http.StatusBadRequest)
+		return
+	}
+	h.setStateCookie(w, "", -1)
+
+	// Each state is single use: consuming it rejects replays as well as forged callbacks
+	valid, err := h.TokenStore.ConsumeOAuthState(state)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to validate OAuth state")
+		http.Error(w, "Failed to validate state", http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		handlerLogger.Warn().Msg("Invalid or expired OAuth state")
+		http.Error(w, "Invalid state", http.StatusBadRequest)
+		return
+	}
 
 	code := r.URL.Query().Get("code")
 	if code == "" {
@@ -69,7 +127,7 @@ func (h *OAuthHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
 
 	handlerLogger.Debug().Msg("Exchanging authorization code for token")
 	// Use OAuthConfig from the struct
-	token, err := h.OAuthConfig.Exchange(r.Context(), code)
+	token, err := h.OAuthConfig.Exchange(googleclient.Context(r.Context()), code)
 	if err != nil {
 		handlerLogger.Error().Err(err).Msg("Token exchange failed")
 		http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
@@ -90,3 +148,72 @@ func (h *OAuthHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
 	handlerLogger.Debug().Msg("Redirecting to calendar selection page")
 	http.Redirect(w, r, "/calendars", http.StatusSeeOther) // Use SeeOther for POST-redirect-GET
 }
+
+// handleDisconnect unlinks the Google account: it stops the notification channels,
+// revokes the token at Google, and clears the stored token and calendar selection.
+func (h *OAuthHandler) handleDisconnect(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleDisconnect").Logger()
+	handlerLogger.Info().Msg("Handling Google Calendar disconnect")
+
+	if r.Method != http.MethodPost {
+		handlerLogger.Warn().Str("method", r.Method).Msg("Invalid method for disconnect")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Channels must be stopped while the token is still valid
+	if h.CalendarService.IsInitialized() {
+		handlerLogger.Debug().Msg("Stopping notification channels")
+		if err := h.CalendarService.StopAllNotificationChannels(ctx); err != nil {
+			handlerLogger.Warn().Err(err).Msg("Failed to stop notification channels, continuing with disconnect")
+		}
+	}
+
+	// A failed revocation should not keep the token stored locally
+	handlerLogger.Debug().Msg("Revoking token at Google")
+	if err := h.TokenManager.RevokeToken(ctx); err != nil {
+		handlerLogger.Warn().Err(err).Msg("Failed to revoke token at Google, continuing with disconnect")
+	}
+
+	if err := h.TokenStore.ClearSelectedCalendar(); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to clear selected calendar")
+		http.Redirect(w, r, "/?error="+ErrCodeDisconnectFailed, http.StatusSeeOther)
+		return
+	}
+	h.CalendarService.Reset()
+
+	// ClearToken emits the token setup signal with success=false
+	if err := h.TokenManager.ClearToken(ctx); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to clear token")
+		http.Redirect(w, r, "/?error="+ErrCodeDisconnectFailed, http.StatusSeeOther)
+		return
+	}
+
+	handlerLogger.Info().Msg("Google Calendar disconnected")
+	http.Redirect(w, r, "/?success="+SuccessCodeDisconnected, http.StatusSeeOther)
+}
+
+// setStateCookie sets the OAuth state cookie for maxAge seconds, a negative maxAge deletes it. The
+// cookie is only sent over HTTPS when the callback is served over it.
+func (h *OAuthHandler) setStateCookie(w http.ResponseWriter, state string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookieName,
+		Value:    state,
+		Path:     "/oauth",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.OAuthConfig.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// generateOAuthState returns a random, URL-safe OAuth state value
+func generateOAuthState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestOAuthHandler(t *testing.T, calSvc *MockCalendarService) (*OAuthHandler, *database.TokenStore, func()) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	tokenManager := token.NewTokenManager(tokenStore, oauthCfg)
+	configAdapter := database.NewConfigAdapter(nil, oauthCfg)
+
+	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, nil)
+	require.NoError(t, err)
+
+	handler, err := NewOAuthHandler(baseHandler, calSvc, false)
+	require.NoError(t, err)
+
+	return handler, tokenStore, func() { db.Close() }
+}
+
+func TestOAuthHandler_HandleDisconnect_InvalidMethod(t *testing.T) {
+	calSvc := new(MockCalendarService)
+	handler, _, cleanup := setupTestOAuthHandler(t, calSvc)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/disconnect", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleDisconnect(w, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	calSvc.AssertNotCalled(t, "Reset")
+}
+
+func TestOAuthHandler_HandleDisconnect(t *testing.T) {
+	tests := []struct {
+		name        string
+		initialized bool
+	}{
+		{name: "initialized service stops channels", initialized: true},
+		{name: "uninitialized service skips channels", initialized: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calSvc := new(MockCalendarService)
+			calSvc.On("IsInitialized").Return(tt.initialized)
+			calSvc.On("Reset").Return()
+			if tt.initialized {
+				calSvc.On("StopAllNotificationChannels", mock.Anything).Return(nil)
+			}
+
+			handler, tokenStore, cleanup := setupTestOAuthHandler(t, calSvc)
+			defer cleanup()
+
+			// A token without credentials keeps the revocation from reaching Google
+			require.NoError(t, tokenStore.SaveToken(&oauth2.Token{TokenType: "Bearer"}))
+			require.NoError(t, tokenStore.SaveSelectedCalendarWithName("cal-id", "Family"))
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/disconnect", nil)
+			w := httptest.NewRecorder()
+
+			handler.handleDisconnect(w, req)
+
+			assert.Equal(t, http.StatusSeeOther, w.Code)
+			assert.Equal(t, "/?success="+SuccessCodeDisconnected, w.Header().Get("Location"))
+
+			storedToken, err := tokenStore.GetToken()
+			require.NoError(t, err)
+			assert.Nil(t, storedToken)
+
+			calendarID, err := tokenStore.GetSelectedCalendar()
+			require.NoError(t, err)
+			assert.Empty(t, calendarID)
+
+			calSvc.AssertExpectations(t)
+			if !tt.initialized {
+				calSvc.AssertNotCalled(t, "StopAllNotificationChannels", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestOAuthHandler_HandleAuth_PersistsState(t *testing.T) {
+	handler, tokenStore, cleanup := setupTestOAuthHandler(t, new(MockCalendarService))
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleAuth(w, req)
+
+	require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	state := location.Query().Get("state")
+	require.NotEmpty(t, state)
+
+	valid, err := tokenStore.ConsumeOAuthState(state)
+	require.NoError(t, err)
+	assert.True(t, valid, "state from the redirect should be stored")
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, oauthStateCookieName, cookies[0].Name)
+	assert.Equal(t, state, cookies[0].Value, "the state is bound to the browser")
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+}
+
+func TestOAuthHandler_HandleCallback_StateValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  func(t *testing.T, tokenStore *database.TokenStore) string
+		cookie string // State cookie of the browser, the state itself when empty
+	}{
+		{
+			name:  "missing state",
+			state: func(t *testing.T, _ *database.TokenStore) string { return "" },
+		},
+		{
+			name:  "unknown state",
+			state: func(t *testing.T, _ *database.TokenStore) string { return "forged-state" },
+		},
+		{
+			name: "expired state",
+			state: func(t *testing.T, tokenStore *database.TokenStore) string {
+				require.NoError(t, tokenStore.SaveOAuthState("expired-state", time.Now().Add(-time.Minute)))
+				return "expired-state"
+			},
+		},
+		{
+			name: "already consumed state",
+			state: func(t *testing.T, tokenStore *database.TokenStore) string {
+				require.NoError(t, tokenStore.SaveOAuthState("used-state", time.Now().Add(time.Minute)))
+				valid, err := tokenStore.ConsumeOAuthState("used-state")
+				require.NoError(t, err)
+				require.True(t, valid)
+				return "used-state"
+			},
+		},
+		{
+			name: "state of another browser",
+			state: func(t *testing.T, tokenStore *database.TokenStore) string {
+				require.NoError(t, tokenStore.SaveOAuthState("attacker-state", time.Now().Add(time.Minute)))
+				return "attacker-state"
+			},
+			cookie: "victim-state",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, tokenStore, cleanup := setupTestOAuthHandler(t, new(MockCalendarService))
+			defer cleanup()
+
+			state := tt.state(t, tokenStore)
+			req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=test-code&state="+url.QueryEscape(state), nil)
+			cookie := tt.cookie
+			if cookie == "" {
+				cookie = state
+			}
+			req.AddCookie(&http.Cookie{Name: oauthStateCookieName, Value: cookie})
+			w := httptest.NewRecorder()
+
+			handler.handleCallback(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "Invalid state")
+		})
+	}
+}
+
+func TestOAuthHandler_HandleCallback_RequiresStateCookie(t *testing.T) {
+	handler, tokenStore, cleanup := setupTestOAuthHandler(t, new(MockCalendarService))
+	defer cleanup()
+	require.NoError(t, tokenStore.SaveOAuthState("pending-state", time.Now().Add(time.Minute)))
+
+	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=attacker-code&state=pending-state", nil)
+	w := httptest.NewRecorder()
+	handler.handleCallback(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	valid, err := tokenStore.ConsumeOAuthState("pending-state")
+	require.NoError(t, err)
+	assert.True(t, valid, "a callback from another browser does not use up the state")
+}
+
+func TestOAuthHandler_DeviceFlow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device":
+			fmt.Fprint(w, `{"device_code":"device-code","user_code":"ABCD-EFGH","verification_url":"https://example.com/device","expires_in":60,"interval":1}`)
+		case "/token":
+			fmt.Fprint(w, `{"access_token":"device-access","refresh_token":"device-refresh","token_type":"Bearer","expires_in":3600}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler, tokenStore, cleanup := setupTestOAuthHandler(t, new(MockCalendarService))
+	defer cleanup()
+	handler.DeviceAuth = true
+	handler.OAuthConfig = &oauth2.Config{
+		ClientID: "test-client",
+		Endpoint: oauth2.Endpoint{
+			DeviceAuthURL: server.URL + "/device",
+			TokenURL:      server.URL + "/token",
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+	}
+
+	getStatus := func() string {
+		w := httptest.NewRecorder()
+		handler.handleDeviceStatus(w, httptest.NewRequest(http.MethodGet, "/auth/device/status", nil))
+		var resp DeviceFlowStatusResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Status
+	}
+
+	assert.Equal(t, DeviceFlowStatusExpired, getStatus(), "no flow started yet")
+
+	w := httptest.NewRecorder()
+	handler.handleAuth(w, httptest.NewRequest(http.MethodGet, "/auth", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ABCD-EFGH")
+	assert.Contains(t, w.Body.String(), "https://example.com/device")
+
+	assert.Eventually(t, func() bool { return getStatus() == DeviceFlowStatusComplete }, 5*time.Second, 100*time.Millisecond)
+
+	stored, err := tokenStore.GetToken()
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, "device-refresh", stored.RefreshToken)
+}
+
+func TestOAuthHandler_DeviceFlow_StopsPollingOnClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device":
+			fmt.Fprint(w, `{"device_code":"device-code","user_code":"ABCD-EFGH","verification_url":"https://example.com/device","expires_in":60,"interval":1}`)
+		case "/token":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"authorization_pending"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler, tokenStore, cleanup := setupTestOAuthHandler(t, new(MockCalendarService))
+	defer cleanup()
+	handler.DeviceAuth = true
+	handler.OAuthConfig = &oauth2.Config{
+		ClientID: "test-client",
+		Endpoint: oauth2.Endpoint{
+			DeviceAuthURL: server.URL + "/device",
+			TokenURL:      server.URL + "/token",
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+	}
+
+	getStatus := func() string {
+		w := httptest.NewRecorder()
+		handler.handleDeviceStatus(w, httptest.NewRequest(http.MethodGet, "/auth/device/status", nil))
+		var resp DeviceFlowStatusResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Status
+	}
+
+	w := httptest.NewRecorder()
+	handler.handleAuth(w, httptest.NewRequest(http.MethodGet, "/auth", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, DeviceFlowStatusPending, getStatus())
+
+	handler.Close()
+	assert.Eventually(t, func() bool { return getStatus() == DeviceFlowStatusFailed }, 5*time.Second, 50*time.Millisecond, "the polling stops with the server")
+
+	stored, err := tokenStore.GetToken()
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/config"
+)
+
+// ParentContacts stores how the notifications meant for each parent reach them, implemented by
+// database.ParentContactStore
+type ParentContacts interface {
+	GetContacts() (map[string]config.ParentContact, error)
+	SaveContact(parent string, contact config.ParentContact, now time.Time) error
+	DeleteContact(parent string) error
+}
+
+// ParentContactsHandler manages the contact directory of the parents: the email address, phone
+// and Slack member of each parent, the delay after which their duty reminder escalates and the
+// emails they subscribe to
+type ParentContactsHandler struct {
+	*BaseHandler
+	contacts    ParentContacts
+	ConfigStore config.ConfigStoreInterface
+	now         func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewParentContactsHandler creates a new parent contacts handler
+func NewParentContactsHandler(baseHandler *BaseHandler, contacts ParentContacts, configStore config.ConfigStoreInterface) *ParentContactsHandler {
+	return &ParentContactsHandler{
+		BaseHandler: baseHandler,
+		contacts:    contacts,
+		ConfigStore: configStore,
+		now:         time.Now,
+	}
+}
+
+// RegisterRoutes registers the parent contacts routes
+func (h *ParentContactsHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/parent-contacts", h.handleListContacts, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/v1/parent-contacts/{parent}", h.handleContact, http.MethodPut, http.MethodDelete)
+}
+
+// ParentContactRequest is the JSON body setting the contact of a parent; every field is optional
+type ParentContactRequest struct {
+	Email                  string `json:"email"`
+	Phone                  string `json:"phone"`
+	SlackMemberID          string `json:"slack_member_id"`          // e.g. U024BE7LH
+	EscalationDelayMinutes int    `json:"escalation_delay_minutes"` // 0 never escalates
+	WeeklySummary          bool   `json:"weekly_summary"`           // Emails the nights of the week every Monday, requires email
+	OverrideNotices        bool   `json:"override_notices"`         // Emails the nights changed from Google Calendar, requires email
+}
+
+// ParentContactResponse is the contact of a parent
+type ParentContactResponse struct {
+	Parent string `json:"parent"` // "parent_a", "parent_b", then "parent_c" and on for the other parents
+	Name   string `json:"name"`   // Name of the parent
+	ParentContactRequest
+}
+
+// newParentContactResponse converts the contact of the parent of key named name
+func newParentContactResponse(key, name string, contact config.ParentContact) ParentContactResponse {
+	return ParentContactResponse{
+		Parent: key,
+		Name:   name,
+		ParentContactRequest: ParentContactRequest{
+			Email:                  contact.Email,
+			Phone:                  contact.Phone,
+			SlackMemberID:          contact.SlackMemberID,
+			EscalationDelayMinutes: int(contact.EscalationDelay / time.Minute),
+			WeeklySummary:          contact.WeeklySummary,
+			OverrideNotices:        contact.OverrideNotices,
+		},
+	}
+}
+
+// handleListContacts lists the contact of each parent taking turns, empty for a parent without one
+func (h *ParentContactsHandler) handleListContacts(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleListContacts").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+	roster, err := config.Roster(h.ConfigStore)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parents")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the parents", handlerLogger)
+		return
+	}
+	contacts, err := h.contacts.GetContacts()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list parent contacts")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the contacts", handlerLogger)
+		return
+	}
+	response := make([]ParentContactResponse, 0, len(roster))
+	for i, name := range roster {
+		key := config.ParentKey(i)
+		response = append(response, newParentContactResponse(key, name, contacts[key]))
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// handleContact sets the contact of a parent on PUT and deletes it on DELETE
+func (h *ParentContactsHandler) handleContact(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("parent")
+	handlerLogger := h.logger.With().Str("handler", "handleContact").Str("method", r.Method).Str("parent", key).Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+	roster, err := config.Roster(h.ConfigStore)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parents")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the parents", handlerLogger)
+		return
+	}
+	index, ok := config.ParentKeyIndex(key)
+	if !ok || index >= len(roster) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Unknown parent, expected the key of a parent taking turns, e.g. parent_a", handlerLogger)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := h.contacts.DeleteContact(key); err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to delete parent contact")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to delete the contact", handlerLogger)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var req ParentContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body", handlerLogger)
+		return
+	}
+	contact := config.ParentContact{
+		Email:           req.Email,
+		Phone:           req.Phone,
+		SlackMemberID:   req.SlackMemberID,
+		EscalationDelay: time.Duration(req.EscalationDelayMinutes) * time.Minute,
+		WeeklySummary:   req.WeeklySummary,
+		OverrideNotices: req.OverrideNotices,
+	}
+	if err := contact.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+		return
+	}
+	if err := h.contacts.SaveContact(key, contact, h.now()); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save parent contact")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to save the contact", handlerLogger)
+		return
+	}
+	writeJSON(w, http.StatusOK, newParentContactResponse(key, roster[index], contact), handlerLogger)
+}
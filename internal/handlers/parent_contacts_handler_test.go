@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestParentContactsHandler(t *testing.T, authenticated bool) (*ParentContactsHandler, *database.ParentContactStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	roster := &noopConfigStore{}
+	contacts, err := database.NewParentContactStore(db, roster)
+	require.NoError(t, err)
+	return NewParentContactsHandler(baseHandler, contacts, roster), contacts
+}
+
+func serveParentContact(h *ParentContactsHandler, method, parent, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/parent-contacts/"+parent, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	if parent == "" {
+		h.handleListContacts(w, req)
+		return w
+	}
+	req.SetPathValue("parent", parent)
+	h.handleContact(w, req)
+	return w
+}
+
+func TestParentContactsHandler_SaveListDelete(t *testing.T) {
+	handler, contacts := setupTestParentContactsHandler(t, true)
+
+	w := serveParentContact(handler, http.MethodPut, "parent_b", `{"email":"bob@example.com","phone":"+33 6 12 34 56 78","slack_member_id":"U024BE7LH","escalation_delay_minutes":30,"weekly_summary":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var saved ParentContactResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &saved))
+	assert.Equal(t, "ParentB", saved.Name)
+	assert.Equal(t, 30, saved.EscalationDelayMinutes)
+	assert.True(t, saved.WeeklySummary)
+	assert.False(t, saved.OverrideNotices)
+
+	contact, err := contacts.ContactOf("ParentB")
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", contact.Email)
+	assert.True(t, contact.Subscribes(config.EmailWeeklySummary))
+
+	w = serveParentContact(handler, http.MethodGet, "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []ParentContactResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 2, "every parent taking turns, with or without contact")
+	assert.Equal(t, ParentContactResponse{Parent: "parent_a", Name: "ParentA"}, listed[0])
+	assert.Equal(t, saved, listed[1])
+
+	w = serveParentContact(handler, http.MethodDelete, "parent_b", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	contact, err = contacts.ContactOf("ParentB")
+	require.NoError(t, err)
+	assert.True(t, contact.IsZero())
+}
+
+func TestParentContactsHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticated bool
+		method        string
+		parent        string
+		body          string
+		wantStatus    int
+	}{
+		{name: "unauthenticated list", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "unauthenticated save", method: http.MethodPut, parent: "parent_a", body: `{}`, wantStatus: http.StatusUnauthorized},
+		{name: "unknown parent", authenticated: true, method: http.MethodPut, parent: "parent_z", body: `{}`, wantStatus: http.StatusNotFound},
+		{name: "parent not taking turns", authenticated: true, method: http.MethodPut, parent: "parent_c", body: `{}`, wantStatus: http.StatusNotFound},
+		{name: "invalid body", authenticated: true, method: http.MethodPut, parent: "parent_a", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "invalid email", authenticated: true, method: http.MethodPut, parent: "parent_a", body: `{"email":"alice"}`, wantStatus: http.StatusBadRequest},
+		{name: "subscription without email", authenticated: true, method: http.MethodPut, parent: "parent_a", body: `{"override_notices":true}`, wantStatus: http.StatusBadRequest},
+		{name: "delay too long", authenticated: true, method: http.MethodPut, parent: "parent_a", body: `{"escalation_delay_minutes":1440}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, contacts := setupTestParentContactsHandler(t, tt.authenticated)
+			w := serveParentContact(handler, tt.method, tt.parent, tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+
+			saved, err := contacts.GetContacts()
+			require.NoError(t, err)
+			assert.Empty(t, saved)
+		})
+	}
+}
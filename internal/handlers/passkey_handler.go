@@ -0,0 +1,661 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/passkey"
+	"github.com/rs/zerolog"
+)
+
+// Limits of the passkey login
+const (
+	sessionCookieName        = "night_routine_session"
+	sessionTTL               = 30 * 24 * time.Hour
+	passkeyChallengeTTL      = 5 * time.Minute
+	passkeyMaxChallenges     = 1000 // Challenges waiting for an answer, beyond which new ones are refused
+	passkeyRequestsPerMinute = 20   // Sign-in requests per client address, two per attempt
+	passkeyNameMaxLength     = 60
+)
+
+// loginPublicPaths are reachable without signing in whatever the method: signing in and out, and
+// the Google Calendar webhook
+var loginPublicPaths = []string{
+	"/login",
+	"/auth/logout",
+	"/api/v1/passkeys/login",
+	"/api/v1/passkeys/login/options",
+	"/api/webhook/calendar",
+}
+
+// tokenEndpointPaths are the endpoints called by voice assistants and automations, which cannot sign
+// in with a passkey: a request carrying the self-service link token of a parent as bearer token skips
+// the session, and the handler checks the token. The ack path is matched with {id} as any segment.
+var tokenEndpointPaths = []string{
+	"/api/v1/voice",
+	"/api/assignments/{id}/ack",
+}
+
+// loginGuardedPrefixes are the pages and read endpoints of the settings and administration that
+// need a session; every request changing something needs one as well
+var loginGuardedPrefixes = []string{
+	"/settings",
+	"/calendars",
+	"/auth",
+	"/oauth/",
+	"/admin/",
+	"/api/admin/",
+	"/api/v1/settings",
+	"/api/v1/parent-links",
+	"/api/v1/passkeys",
+}
+
+// Passkeys stores the passkeys and the sessions they open, implemented by database.PasskeyStore
+type Passkeys interface {
+	AddPasskey(p *database.Passkey) error
+	ListPasskeys() ([]*database.Passkey, error)
+	CountPasskeys() (int, error)
+	GetPasskey(credentialID []byte) (*database.Passkey, error)
+	RecordUse(id int64, signCount uint32, usedAt time.Time) error
+	DeletePasskey(id int64) error
+	CreateSession(passkeyID int64, now time.Time, ttl time.Duration) (string, error)
+	SessionValid(token string, now time.Time) (bool, error)
+	DeleteSession(token string) error
+}
+
+// PasskeyHandler signs in to the web interface with passkeys, for households without an identity
+// provider. Once a passkey is registered, RequireLogin asks for a session for the settings, the
+// administration and every request changing something; until then the web interface stays open
+// so that the first passkey can be registered from the settings page.
+type PasskeyHandler struct {
+	*BaseHandler
+	passkeys     Passkeys
+	rp           passkey.RelyingParty
+	enabled      bool
+	secureCookie bool // The cookie is only sent over HTTPS when the web interface is served over it
+	limiter      *rateLimiter
+	mu           sync.Mutex
+	challenges   map[string]time.Time // Issued challenges and their expiry
+	now          func() time.Time     // injectable for testing; defaults to time.Now
+}
+
+// NewPasskeyHandler creates a new passkey login handler for the web interface at appURL; the login
+// is only required when enabled
+func NewPasskeyHandler(baseHandler *BaseHandler, passkeys Passkeys, appURL string, enabled bool) (*PasskeyHandler, error) {
+	rp, err := passkey.NewRelyingParty(appURL, "Night Routine")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create passkey relying party: %w", err)
+	}
+	return &PasskeyHandler{
+		BaseHandler:  baseHandler,
+		passkeys:     passkeys,
+		rp:           rp,
+		enabled:      enabled,
+		secureCookie: strings.HasPrefix(rp.Origin, "https://"),
+		limiter:      newRateLimiter(passkeyRequestsPerMinute, time.Minute),
+		challenges:   make(map[string]time.Time),
+		now:          time.Now,
+	}, nil
+}
+
+// RegisterRoutes registers the passkey routes, none when the passkey login is disabled
+func (h *PasskeyHandler) RegisterRoutes() {
+	if !h.enabled {
+		return
+	}
+	handleMethods(http.DefaultServeMux, "/login", h.handleLoginPage, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/auth/logout", h.handleLogout, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/passkeys/login/options", h.handleLoginOptions, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/passkeys/login", h.handleLogin, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/passkeys", h.handlePasskeys, http.MethodGet, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/passkeys/register/options", h.handleRegisterOptions, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/passkeys/{id}", h.handleDeletePasskey, http.MethodDelete)
+}
+
+// LoginPageData contains data for the login page template
+type LoginPageData struct {
+	BasePageData
+	Next string // Local path opened once signed in
+}
+
+// PasskeyResponse is a registered passkey
+type PasskeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // Missing until the first sign-in
+}
+
+// LoginOptionsResponse is the request of navigator.credentials.get, binary values base64url encoded
+type LoginOptionsResponse struct {
+	Challenge        string   `json:"challenge"`
+	RPID             string   `json:"rp_id"`
+	AllowCredentials []string `json:"allow_credentials"` // IDs of the registered passkeys
+	Timeout          int64    `json:"timeout"`           // Milliseconds
+}
+
+// RegisterOptionsResponse is the request of navigator.credentials.create, binary values base64url encoded
+type RegisterOptionsResponse struct {
+	Challenge          string   `json:"challenge"`
+	RPID               string   `json:"rp_id"`
+	RPName             string   `json:"rp_name"`
+	UserID             string   `json:"user_id"` // Random, each passkey is its own account on the authenticator
+	Algorithms         []int    `json:"algorithms"`
+	ExcludeCredentials []string `json:"exclude_credentials"` // IDs of the registered passkeys
+	Timeout            int64    `json:"timeout"`             // Milliseconds
+}
+
+// LoginRequest is the JSON body of a sign-in, the response of navigator.credentials.get, binary
+// values base64url encoded
+type LoginRequest struct {
+	CredentialID      string `json:"credential_id"`
+	ClientDataJSON    string `json:"client_data_json"`
+	AuthenticatorData string `json:"authenticator_data"`
+	Signature         string `json:"signature"`
+}
+
+// RegisterPasskeyRequest is the JSON body registering a passkey, the response of
+// navigator.credentials.create, binary values base64url encoded
+type RegisterPasskeyRequest struct {
+	Name              string `json:"name"`
+	ClientDataJSON    string `json:"client_data_json"`
+	AuthenticatorData string `json:"authenticator_data"` // response.getAuthenticatorData()
+	PublicKey         string `json:"public_key"`         // response.getPublicKey()
+	Algorithm         int    `json:"algorithm"`          // response.getPublicKeyAlgorithm()
+}
+
+// RequireLogin asks for a session for the requests loginRequired reports, once a passkey is
+// registered: the API answers 401 login_required and the pages redirect to the login page. A
+// failing check refuses the request.
+func (h *PasskeyHandler) RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.enabled || !loginRequired(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		logger := h.logger.With().Str("middleware", "RequireLogin").Str("path", r.URL.Path).Logger()
+		signedIn, err := h.signedIn(r)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to check the session")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if signedIn {
+			next.ServeHTTP(w, r)
+			return
+		}
+		logger.Debug().Str("method", r.Method).Msg("Request refused without session")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/"):
+			writeError(w, http.StatusUnauthorized, apierror.CodeLoginRequired, "Sign in with a passkey", logger)
+		case r.Method == http.MethodGet || r.Method == http.MethodHead:
+			http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+		default:
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+		}
+	})
+}
+
+// loginRequired reports whether r needs a session: the settings and administration, and every
+// request changing something, except loginPublicPaths, the self-service pages having their own
+// token and the tokenEndpointPaths called with a bearer token
+func loginRequired(r *http.Request) bool {
+	for _, path := range loginPublicPaths {
+		if r.URL.Path == path {
+			return false
+		}
+	}
+	if strings.HasPrefix(r.URL.Path, selfServicePath) {
+		return false
+	}
+	if _, ok := bearerToken(r); ok && isTokenEndpoint(r.URL.Path) {
+		return false
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return true
+	}
+	for _, prefix := range loginGuardedPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTokenEndpoint reports whether path is one of tokenEndpointPaths
+func isTokenEndpoint(path string) bool {
+	segments := strings.Split(path, "/")
+	for _, pattern := range tokenEndpointPaths {
+		patternSegments := strings.Split(pattern, "/")
+		if len(patternSegments) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, segment := range patternSegments {
+			if segment != segments[i] && (segment != "{id}" || segments[i] == "") {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken returns the token of the Authorization header of r, if any
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// checkBearerLink checks the bearer token of a request to one of tokenEndpointPaths against the
+// self-service links, answering 401 when it is unknown. A request without bearer token passed the
+// session check of RequireLogin and is accepted.
+func checkBearerLink(w http.ResponseWriter, r *http.Request, links ParentLinks, logger zerolog.Logger) bool {
+	token, ok := bearerToken(r)
+	if !ok {
+		return true
+	}
+	parentKey, ok, err := links.ParentForToken(token)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to look up parent link")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to check the token", logger)
+		return false
+	}
+	if !ok {
+		logger.Warn().Msg("Unknown bearer token")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unknown token, use the token of a self-service link", logger)
+		return false
+	}
+	logger.Debug().Str("parent_key", parentKey).Msg("Request authenticated by a self-service link")
+	return true
+}
+
+// signedIn reports whether r carries a valid session, or no passkey is registered yet
+func (h *PasskeyHandler) signedIn(r *http.Request) (bool, error) {
+	count, err := h.passkeys.CountPasskeys()
+	if err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return true, nil
+	}
+	return h.hasSession(r)
+}
+
+// hasSession reports whether r carries a valid session cookie
+func (h *PasskeyHandler) hasSession(r *http.Request) (bool, error) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return false, nil
+	}
+	return h.passkeys.SessionValid(cookie.Value, h.now())
+}
+
+// handleLoginPage shows the login page, or opens the next page when signed in or without passkey
+func (h *PasskeyHandler) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleLoginPage").Logger()
+
+	next := localPath(r.URL.Query().Get("next"))
+	signedIn, err := h.signedIn(r)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to check the session")
+	}
+	if signedIn {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+	h.RenderTemplate(w, "login.html", LoginPageData{
+		BasePageData: h.NewBasePageData(r, false),
+		Next:         next,
+	})
+}
+
+// handleLogout closes the session of the request and opens the login page
+func (h *PasskeyHandler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleLogout").Logger()
+
+	if cookie, err := r.Cookie(sessionCookieName); err == nil {
+		if err := h.passkeys.DeleteSession(cookie.Value); err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to close the session")
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+	handlerLogger.Info().Msg("Signed out")
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// handleLoginOptions returns a challenge to sign with one of the registered passkeys
+func (h *PasskeyHandler) handleLoginOptions(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleLoginOptions").Logger()
+
+	if !h.allow(w, r, handlerLogger) {
+		return
+	}
+	passkeys, err := h.passkeys.ListPasskeys()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list passkeys")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the passkeys", handlerLogger)
+		return
+	}
+	challenge, ok := h.issueChallenge(w, handlerLogger)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, LoginOptionsResponse{
+		Challenge:        challenge,
+		RPID:             h.rp.ID,
+		AllowCredentials: credentialIDs(passkeys),
+		Timeout:          passkeyChallengeTTL.Milliseconds(),
+	}, handlerLogger)
+}
+
+// handleLogin checks the signature of a passkey and opens a session
+func (h *PasskeyHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleLogin").Logger()
+
+	if !h.allow(w, r, handlerLogger) {
+		return
+	}
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body", handlerLogger)
+		return
+	}
+	var credentialID []byte
+	var assertion passkey.Assertion
+	if err := decodeBase64URL(
+		base64URLField{"credential_id", req.CredentialID, &credentialID},
+		base64URLField{"client_data_json", req.ClientDataJSON, &assertion.ClientDataJSON},
+		base64URLField{"authenticator_data", req.AuthenticatorData, &assertion.AuthenticatorData},
+		base64URLField{"signature", req.Signature, &assertion.Signature},
+	); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+		return
+	}
+
+	registered, err := h.passkeys.GetPasskey(credentialID)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get passkey")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the passkey", handlerLogger)
+		return
+	}
+	if registered == nil {
+		handlerLogger.Warn().Msg("Sign-in with an unknown passkey")
+		writeError(w, http.StatusUnauthorized, apierror.CodeLoginRequired, "Unknown passkey", handlerLogger)
+		return
+	}
+	signCount, err := h.rp.VerifyAssertion(assertion, registered.PublicKey, registered.Algorithm, registered.SignCount, h.consumeChallenge)
+	if err != nil {
+		handlerLogger.Warn().Err(err).Int64("passkey_id", registered.ID).Msg("Passkey sign-in refused")
+		writeError(w, http.StatusUnauthorized, apierror.CodeLoginRequired, "The passkey could not be verified", handlerLogger)
+		return
+	}
+	now := h.now()
+	if err := h.passkeys.RecordUse(registered.ID, signCount, now); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to record passkey use")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to sign in", handlerLogger)
+		return
+	}
+	if !h.openSession(w, registered.ID, handlerLogger) {
+		return
+	}
+	handlerLogger.Info().Int64("passkey_id", registered.ID).Str("name", registered.Name).Msg("Signed in with passkey")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePasskeys lists the passkeys on GET and registers one on POST. The browser registering the
+// first passkey is signed in with it.
+func (h *PasskeyHandler) handlePasskeys(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handlePasskeys").Str("method", r.Method).Logger()
+
+	if r.Method == http.MethodPost {
+		h.registerPasskey(w, r, handlerLogger)
+		return
+	}
+	passkeys, err := h.passkeys.ListPasskeys()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list passkeys")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the passkeys", handlerLogger)
+		return
+	}
+	response := make([]PasskeyResponse, 0, len(passkeys))
+	for _, p := range passkeys {
+		response = append(response, newPasskeyResponse(p))
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// handleRegisterOptions returns a challenge to create a passkey with
+func (h *PasskeyHandler) handleRegisterOptions(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleRegisterOptions").Logger()
+
+	passkeys, err := h.passkeys.ListPasskeys()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list passkeys")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the passkeys", handlerLogger)
+		return
+	}
+	challenge, ok := h.issueChallenge(w, handlerLogger)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, RegisterOptionsResponse{
+		Challenge:          challenge,
+		RPID:               h.rp.ID,
+		RPName:             h.rp.Name,
+		UserID:             base64.RawURLEncoding.EncodeToString([]byte(rand.Text())),
+		Algorithms:         []int{passkey.AlgorithmES256, passkey.AlgorithmEdDSA, passkey.AlgorithmRS256},
+		ExcludeCredentials: credentialIDs(passkeys),
+		Timeout:            passkeyChallengeTTL.Milliseconds(),
+	}, handlerLogger)
+}
+
+// registerPasskey checks the response of the browser creating a passkey and stores it
+func (h *PasskeyHandler) registerPasskey(w http.ResponseWriter, r *http.Request, logger zerolog.Logger) {
+	var req RegisterPasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body", logger)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > passkeyNameMaxLength {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, fmt.Sprintf("name is required, at most %d characters", passkeyNameMaxLength), logger)
+		return
+	}
+	reg := passkey.Registration{Algorithm: req.Algorithm}
+	if err := decodeBase64URL(
+		base64URLField{"client_data_json", req.ClientDataJSON, &reg.ClientDataJSON},
+		base64URLField{"authenticator_data", req.AuthenticatorData, &reg.AuthenticatorData},
+		base64URLField{"public_key", req.PublicKey, &reg.PublicKey},
+	); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), logger)
+		return
+	}
+	credential, err := h.rp.VerifyRegistration(reg, h.consumeChallenge)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Passkey registration refused")
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "The passkey could not be verified: "+err.Error(), logger)
+		return
+	}
+
+	signedIn, err := h.hasSession(r)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to check the session")
+	}
+	created := &database.Passkey{
+		CredentialID: credential.ID,
+		PublicKey:    credential.PublicKey,
+		Algorithm:    credential.Algorithm,
+		SignCount:    credential.SignCount,
+		Name:         req.Name,
+		CreatedAt:    h.now(),
+	}
+	if err := h.passkeys.AddPasskey(created); err != nil {
+		logger.Error().Err(err).Msg("Failed to add passkey")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to save the passkey", logger)
+		return
+	}
+	// Registering the first passkey turns the login on: keep its browser signed in
+	if !signedIn && !h.openSession(w, created.ID, logger) {
+		return
+	}
+	writeJSON(w, http.StatusCreated, newPasskeyResponse(created), logger)
+}
+
+// handleDeletePasskey deletes a passkey and closes its sessions. Deleting the last one turns the
+// login off.
+func (h *PasskeyHandler) handleDeletePasskey(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleDeletePasskey").Logger()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid passkey ID", handlerLogger)
+		return
+	}
+	if err := h.passkeys.DeletePasskey(id); err != nil {
+		if errors.Is(err, database.ErrPasskeyNotFound) {
+			writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Passkey not found", handlerLogger)
+			return
+		}
+		handlerLogger.Error().Err(err).Int64("passkey_id", id).Msg("Failed to delete passkey")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to delete the passkey", handlerLogger)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allow applies the rate limit of the sign-in attempts of the client of r
+func (h *PasskeyHandler) allow(w http.ResponseWriter, r *http.Request, logger zerolog.Logger) bool {
+	allowed, retryAfter := h.limiter.allow(clientAddress(r), h.now())
+	if !allowed {
+		logger.Warn().Str("client", clientAddress(r)).Msg("Passkey sign-in rate limited")
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+	}
+	return allowed
+}
+
+// openSession opens a session of the passkey and sets its cookie
+func (h *PasskeyHandler) openSession(w http.ResponseWriter, passkeyID int64, logger zerolog.Logger) bool {
+	token, err := h.passkeys.CreateSession(passkeyID, h.now(), sessionTTL)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to open session")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to sign in", logger)
+		return false
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(sessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return true
+}
+
+// issueChallenge issues a challenge valid for passkeyChallengeTTL, refusing it once
+// passkeyMaxChallenges are waiting for an answer
+func (h *PasskeyHandler) issueChallenge(w http.ResponseWriter, logger zerolog.Logger) (string, bool) {
+	challenge, err := passkey.NewChallenge()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to generate challenge")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to generate the challenge", logger)
+		return "", false
+	}
+	now := h.now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for issued, expiry := range h.challenges {
+		if !now.Before(expiry) {
+			delete(h.challenges, issued)
+		}
+	}
+	if len(h.challenges) >= passkeyMaxChallenges {
+		logger.Warn().Int("challenges", len(h.challenges)).Msg("Too many pending passkey challenges")
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return "", false
+	}
+	h.challenges[challenge] = now.Add(passkeyChallengeTTL)
+	return challenge, true
+}
+
+// consumeChallenge reports whether challenge was issued and has not expired, and forgets it
+func (h *PasskeyHandler) consumeChallenge(challenge string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	expiry, ok := h.challenges[challenge]
+	delete(h.challenges, challenge)
+	return ok && h.now().Before(expiry)
+}
+
+// newPasskeyResponse returns the JSON form of a passkey, without its key
+func newPasskeyResponse(p *database.Passkey) PasskeyResponse {
+	return PasskeyResponse{ID: p.ID, Name: p.Name, CreatedAt: p.CreatedAt, LastUsedAt: p.LastUsedAt}
+}
+
+// credentialIDs returns the base64url credential IDs of the passkeys
+func credentialIDs(passkeys []*database.Passkey) []string {
+	ids := make([]string, 0, len(passkeys))
+	for _, p := range passkeys {
+		ids = append(ids, base64.RawURLEncoding.EncodeToString(p.CredentialID))
+	}
+	return ids
+}
+
+// base64URLField is a base64url value of a request body and the field it decodes into
+type base64URLField struct {
+	name  string
+	value string
+	dest  *[]byte
+}
+
+// decodeBase64URL decodes the values, base64url with or without padding, into their field; each
+// one is required
+func decodeBase64URL(fields ...base64URLField) error {
+	for _, field := range fields {
+		decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(field.value, "="))
+		if err != nil || len(decoded) == 0 {
+			return fmt.Errorf("%s is required, base64url encoded", field.name)
+		}
+		*field.dest = decoded
+	}
+	return nil
+}
+
+// localPath returns next when it is a path of this site, "/" otherwise, so that the login page
+// does not redirect elsewhere
+func localPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/passkey"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+const passkeyTestAppURL = "http://localhost:8888"
+
+// softAuthenticator is a software authenticator holding one ES256 passkey of the test app URL
+type softAuthenticator struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &softAuthenticator{key: key, id: []byte("credential-" + rand.Text())}
+}
+
+func (a *softAuthenticator) authenticatorData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte("localhost"))
+	data := append([]byte{}, rpIDHash[:]...)
+	flags := byte(0x01) // User present
+	if attested {
+		flags |= 0x40
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.id)))
+		data = append(data, a.id...)
+	}
+	return data
+}
+
+func clientDataJSON(t *testing.T, ceremony, challenge string) []byte {
+	raw, err := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": passkeyTestAppURL})
+	require.NoError(t, err)
+	return raw
+}
+
+func (a *softAuthenticator) register(t *testing.T, name, challenge string) RegisterPasskeyRequest {
+	publicKey, err := x509.MarshalPKIXPublicKey(&a.key.PublicKey)
+	require.NoError(t, err)
+	return RegisterPasskeyRequest{
+		Name:              name,
+		ClientDataJSON:    base64.RawURLEncoding.EncodeToString(clientDataJSON(t, "webauthn.create", challenge)),
+		AuthenticatorData: base64.RawURLEncoding.EncodeToString(a.authenticatorData(true)),
+		PublicKey:         base64.RawURLEncoding.EncodeToString(publicKey),
+		Algorithm:         passkey.AlgorithmES256,
+	}
+}
+
+func (a *softAuthenticator) sign(t *testing.T, challenge string) LoginRequest {
+	a.signCount++
+	clientData := clientDataJSON(t, "webauthn.get", challenge)
+	authData := a.authenticatorData(false)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+	return LoginRequest{
+		CredentialID:      base64.RawURLEncoding.EncodeToString(a.id),
+		ClientDataJSON:    base64.RawURLEncoding.EncodeToString(clientData),
+		AuthenticatorData: base64.RawURLEncoding.EncodeToString(authData),
+		Signature:         base64.RawURLEncoding.EncodeToString(signature),
+	}
+}
+
+func setupTestPasskeyHandler(t *testing.T, enabled bool) (*PasskeyHandler, *database.PasskeyStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	store, err := database.NewPasskeyStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+	handler, err := NewPasskeyHandler(baseHandler, store, passkeyTestAppURL, enabled)
+	require.NoError(t, err)
+	return handler, store
+}
+
+// postJSON calls a passkey endpoint with body as JSON and the cookies given
+func postJSON(t *testing.T, handle http.HandlerFunc, path string, body any, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(string(raw)))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	handle(w, req)
+	return w
+}
+
+func challengeOf(t *testing.T, w *httptest.ResponseRecorder) string {
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var options struct {
+		Challenge string `json:"challenge"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &options))
+	return options.Challenge
+}
+
+func sessionCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == sessionCookieName {
+			return cookie
+		}
+	}
+	require.Fail(t, "no session cookie")
+	return nil
+}
+
+func TestLoginRequired(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		bearer string
+		want   bool
+	}{
+		{http.MethodGet, "/", "", false},
+		{http.MethodGet, "/statistics", "", false},
+		{http.MethodGet, "/api/v1/upcoming", "", false},
+		{http.MethodGet, "/settings", "", true},
+		{http.MethodGet, "/admin/channels", "", true},
+		{http.MethodGet, "/api/v1/passkeys", "", true},
+		{http.MethodPost, "/api/sync", "", true},
+		{http.MethodPost, "/api/assignments/tonight/claim", "", true},
+		{http.MethodPost, "/settings/update", "", true},
+		{http.MethodPost, "/api/v1/passkeys/login", "", false},
+		{http.MethodPost, "/api/v1/passkeys/login/options", "", false},
+		{http.MethodPost, "/api/webhook/calendar", "", false},
+		{http.MethodPost, "/me/token", "", false},
+		{http.MethodGet, "/login", "", false},
+		{http.MethodPost, "/api/v1/voice", "", true},
+		{http.MethodPost, "/api/v1/voice", "link-token", false},
+		{http.MethodPost, "/api/assignments/42/ack", "", true},
+		{http.MethodPost, "/api/assignments/42/ack", "link-token", false},
+		{http.MethodPost, "/api/assignments/tonight/claim", "link-token", true},
+		{http.MethodPost, "/api/assignments//ack", "link-token", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path+" "+tt.bearer, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			assert.Equal(t, tt.want, loginRequired(req))
+		})
+	}
+}
+
+func TestPasskeyHandler_RegisterAndLogin(t *testing.T) {
+	handler, store := setupTestPasskeyHandler(t, true)
+	authenticator := newSoftAuthenticator(t)
+
+	// The first passkey signs its browser in
+	challenge := challengeOf(t, postJSON(t, handler.handleRegisterOptions, "/api/v1/passkeys/register/options", nil))
+	w := postJSON(t, handler.handlePasskeys, "/api/v1/passkeys", authenticator.register(t, "Alice's phone", challenge))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	firstSession := sessionCookie(t, w)
+	assert.True(t, firstSession.HttpOnly)
+	var created PasskeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "Alice's phone", created.Name)
+
+	// A challenge is used once
+	w = postJSON(t, handler.handlePasskeys, "/api/v1/passkeys", newSoftAuthenticator(t).register(t, "Replay", challenge), firstSession)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	challenge = challengeOf(t, postJSON(t, handler.handleLoginOptions, "/api/v1/passkeys/login/options", nil))
+	w = postJSON(t, handler.handleLogin, "/api/v1/passkeys/login", authenticator.sign(t, challenge))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	session := sessionCookie(t, w)
+	valid, err := store.SessionValid(session.Value, handler.now())
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	passkeys, err := store.ListPasskeys()
+	require.NoError(t, err)
+	require.Len(t, passkeys, 1)
+	assert.Equal(t, uint32(1), passkeys[0].SignCount)
+	assert.NotNil(t, passkeys[0].LastUsedAt)
+
+	// An unknown passkey does not sign in
+	challenge = challengeOf(t, postJSON(t, handler.handleLoginOptions, "/api/v1/passkeys/login/options", nil))
+	w = postJSON(t, handler.handleLogin, "/api/v1/passkeys/login", newSoftAuthenticator(t).sign(t, challenge))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), apierror.CodeLoginRequired.String())
+}
+
+func TestPasskeyHandler_RequireLogin(t *testing.T) {
+	handler, store := setupTestPasskeyHandler(t, true)
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	guarded := handler.RequireLogin(next)
+	serve := func(method, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		guarded.ServeHTTP(w, req)
+		return w
+	}
+
+	// Without passkey, nothing is guarded
+	assert.Equal(t, http.StatusTeapot, serve(http.MethodGet, "/settings").Code)
+
+	authenticator := newSoftAuthenticator(t)
+	challenge := challengeOf(t, postJSON(t, handler.handleRegisterOptions, "/api/v1/passkeys/register/options", nil))
+	w := postJSON(t, handler.handlePasskeys, "/api/v1/passkeys", authenticator.register(t, "Alice's phone", challenge))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	session := sessionCookie(t, w)
+
+	w = serve(http.MethodGet, "/settings?tab=sync")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/login?next=%2Fsettings%3Ftab%3Dsync", w.Header().Get("Location"))
+	w = serve(http.MethodPost, "/api/sync")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), apierror.CodeLoginRequired.String())
+	assert.Equal(t, http.StatusTeapot, serve(http.MethodGet, "/").Code, "reading the schedule stays open")
+	assert.Equal(t, http.StatusTeapot, serve(http.MethodPost, "/api/sync", session).Code)
+	assert.Equal(t, http.StatusTeapot, serve(http.MethodGet, "/settings", session).Code)
+
+	// Deleting the passkey closes its session and turns the login off
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/passkeys/1", nil)
+	req.SetPathValue("id", "1")
+	w = httptest.NewRecorder()
+	handler.handleDeletePasskey(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	valid, err := store.SessionValid(session.Value, handler.now())
+	require.NoError(t, err)
+	assert.False(t, valid)
+	assert.Equal(t, http.StatusTeapot, serve(http.MethodGet, "/settings").Code)
+}
+
+func TestPasskeyHandler_Disabled(t *testing.T) {
+	handler, store := setupTestPasskeyHandler(t, false)
+	require.NoError(t, store.AddPasskey(&database.Passkey{CredentialID: []byte("id"), PublicKey: []byte("key"), Name: "Phone", CreatedAt: handler.now()}))
+
+	w := httptest.NewRecorder()
+	handler.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusTeapot) })).
+		ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sync", nil))
+	assert.Equal(t, http.StatusTeapot, w.Code)
+}
+
+func TestLocalPath(t *testing.T) {
+	assert.Equal(t, "/settings?tab=1", localPath("/settings?tab=1"))
+	assert.Equal(t, "/", localPath("https://evil.example.com"))
+	assert.Equal(t, "/", localPath("//evil.example.com"))
+	assert.Equal(t, "/", localPath(""))
+}
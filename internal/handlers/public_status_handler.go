@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/rs/zerolog"
+)
+
+// Limits of the public status page, reachable without authentication through the public URL
+const (
+	publicStatusRequestsPerMinute = 30
+	publicStatusMaxAge            = time.Minute // Shared caches may serve the page that long
+)
+
+// PublicStatusHandler serves the public status page: whether the service is up, the time of the
+// last sync and of the next scheduled one, and nothing about the family or the Google account
+type PublicStatusHandler struct {
+	*BaseHandler
+	DB      *database.DB
+	limiter *rateLimiter
+	now     func() time.Time
+}
+
+// NewPublicStatusHandler creates a new public status page handler
+func NewPublicStatusHandler(baseHandler *BaseHandler, db *database.DB) *PublicStatusHandler {
+	return &PublicStatusHandler{
+		BaseHandler: baseHandler,
+		DB:          db,
+		limiter:     newRateLimiter(publicStatusRequestsPerMinute, time.Minute),
+		now:         time.Now,
+	}
+}
+
+// RegisterRoutes registers the public status page route
+func (h *PublicStatusHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/status", h.handlePublicStatus, http.MethodGet)
+}
+
+// PublicStatusPageData contains the data of the public status page. The version of the footer is
+// left out.
+type PublicStatusPageData struct {
+	BasePageData
+	Status         string     // ReadinessStatusOK, ReadinessStatusDegraded or ReadinessStatusUnavailable
+	LastSync       *time.Time // End of the last sync, its start while it runs; nil before the first one
+	LastSyncFailed bool
+	NextSync       *time.Time // Next scheduled sync; nil when unknown or paused
+	SyncPaused     bool       // The scheduled syncs are disabled
+}
+
+// handlePublicStatus renders the public status page, limited per client address. The page can be
+// cached by shared caches and is revalidated with its ETag.
+func (h *PublicStatusHandler) handlePublicStatus(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handlePublicStatus").Logger()
+
+	now := h.now()
+	if allowed, retryAfter := h.limiter.allow(clientAddress(r), now); !allowed {
+		handlerLogger.Debug().Str("client", clientAddress(r)).Msg("Public status page rate limited")
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	data := PublicStatusPageData{
+		BasePageData: h.NewBasePageData(r, false),
+		Status:       ReadinessStatusOK,
+	}
+	data.Version = ""
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	if err := h.DB.Conn().PingContext(ctx); err != nil {
+		handlerLogger.Warn().Err(err).Msg("Public status database check failed")
+		data.Status = ReadinessStatusUnavailable
+	}
+	h.fillSyncs(&data, now, handlerLogger)
+	if data.LastSyncFailed && data.Status == ReadinessStatusOK {
+		data.Status = ReadinessStatusDegraded
+	}
+
+	etag := weakETag(templatesVersion(), data.Status, formatOptionalTime(data.LastSync), strconv.FormatBool(data.LastSyncFailed), formatOptionalTime(data.NextSync), strconv.FormatBool(data.SyncPaused))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(publicStatusMaxAge.Seconds())))
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.RenderTemplate(w, "public_status.html", data)
+}
+
+// fillSyncs sets the last sync and the next scheduled sync of data. The scheduled syncs run on the
+// first minute tick once the update interval elapsed since the last successful one.
+func (h *PublicStatusHandler) fillSyncs(data *PublicStatusPageData, now time.Time, logger zerolog.Logger) {
+	if h.SyncRuns == nil {
+		return
+	}
+	runs, err := h.SyncRuns.ListRuns(1)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Public status last sync check failed")
+	} else if len(runs) > 0 {
+		run := runs[0]
+		data.LastSync = &run.StartedAt
+		if run.FinishedAt != nil {
+			data.LastSync = run.FinishedAt
+		}
+		data.LastSyncFailed = run.Status == database.SyncRunStatusFailed
+	}
+
+	updateFrequency, _, _, _, err := h.ConfigStore.GetSchedule()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Public status scheduler check failed")
+		return
+	}
+	if updateFrequency == config.UpdateFrequencyDisabled {
+		data.SyncPaused = true
+		return
+	}
+	interval, ok := config.UpdateInterval(updateFrequency)
+	if !ok {
+		return
+	}
+	next := now.Truncate(time.Minute).Add(time.Minute)
+	lastScheduled, err := h.SyncRuns.LastSuccessfulRun(constants.SyncTriggerScheduled)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Public status next sync check failed")
+		return
+	}
+	if lastScheduled != nil && lastScheduled.FinishedAt != nil {
+		if due := lastScheduled.FinishedAt.Add(interval); due.After(next) {
+			next = due
+		}
+	}
+	data.NextSync = &next
+}
+
+// formatOptionalTime formats t in RFC 3339, empty when nil
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
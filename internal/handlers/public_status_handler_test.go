@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestPublicStatusHandler(t *testing.T) (*PublicStatusHandler, *statusTestEnv) {
+	env := setupTestStatusHandler(t)
+	return NewPublicStatusHandler(env.handler.BaseHandler, env.db), env
+}
+
+func getPublicStatus(handler *PublicStatusHandler, remoteAddr string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/status", nil)
+	r.RemoteAddr = remoteAddr
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	handler.handlePublicStatus(w, r)
+	return w
+}
+
+func TestPublicStatusHandler_FreshInstall(t *testing.T) {
+	handler, env := setupTestPublicStatusHandler(t)
+	require.NoError(t, env.tokenStore.SaveSelectedCalendarWithName("family@group.calendar.google.com", "Family"))
+	now := time.Date(2026, 10, 16, 20, 30, 15, 0, time.Local)
+	handler.now = func() time.Time { return now }
+
+	w := getPublicStatus(handler, "192.0.2.1:1234", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	body := w.Body.String()
+	assert.Contains(t, body, "Operational")
+	assert.Contains(t, body, "Not yet")
+	assert.Contains(t, body, "Fri, Oct 16 2026 20:31", "the next sync runs on the next tick")
+	assert.NotContains(t, body, "family@group.calendar.google.com")
+}
+
+func TestPublicStatusHandler_NextScheduledSync(t *testing.T) {
+	handler, env := setupTestPublicStatusHandler(t)
+	require.NoError(t, env.syncRuns.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 12, nil }))
+	runs, err := env.syncRuns.ListRuns(1)
+	require.NoError(t, err)
+	next := runs[0].FinishedAt.Add(24 * time.Hour)
+
+	w := getPublicStatus(handler, "192.0.2.1:1234", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), next.Local().Format("Mon, Jan 2 2006 15:04 MST"), "daily syncs run a day after the last one")
+}
+
+func TestPublicStatusHandler_DegradedAndPaused(t *testing.T) {
+	handler, env := setupTestPublicStatusHandler(t)
+	require.Error(t, env.syncRuns.RecordRun(context.Background(), constants.SyncTriggerWebhook, func(context.Context) (int, error) { return 0, errors.New("quota exceeded") }))
+	require.NoError(t, env.configStore.SaveSchedule(t.Context(), "disabled", 30, 5, constants.StatsOrderDesc))
+
+	w := getPublicStatus(handler, "192.0.2.1:1234", nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Degraded")
+	assert.Contains(t, body, "(failed)")
+	assert.Contains(t, body, "Paused")
+	assert.NotContains(t, body, "quota exceeded", "sync errors are not exposed")
+}
+
+func TestPublicStatusHandler_NotModified(t *testing.T) {
+	handler, _ := setupTestPublicStatusHandler(t)
+	now := time.Date(2026, 10, 16, 20, 30, 0, 0, time.Local)
+	handler.now = func() time.Time { return now }
+
+	first := getPublicStatus(handler, "192.0.2.1:1234", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+
+	w := getPublicStatus(handler, "192.0.2.1:1234", http.Header{"If-None-Match": {first.Header().Get("ETag")}})
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestPublicStatusHandler_RateLimited(t *testing.T) {
+	handler, _ := setupTestPublicStatusHandler(t)
+	now := time.Date(2026, 10, 16, 20, 30, 0, 0, time.Local)
+	handler.now = func() time.Time { return now }
+
+	for range publicStatusRequestsPerMinute {
+		require.Equal(t, http.StatusOK, getPublicStatus(handler, "192.0.2.1:1234", nil).Code)
+	}
+	now = now.Add(20 * time.Second)
+	w := getPublicStatus(handler, "192.0.2.1:5678", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "40", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, getPublicStatus(handler, "192.0.2.2:1234", nil).Code, "other clients keep their own limit")
+
+	now = now.Add(40 * time.Second)
+	assert.Equal(t, http.StatusOK, getPublicStatus(handler, "192.0.2.1:1234", nil).Code, "the limit resets with the window")
+}
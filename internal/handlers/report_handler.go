@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/report"
+)
+
+// ReportHandler serves the monthly reports
+type ReportHandler struct {
+	*BaseHandler
+	generator *report.Generator
+	now       func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewReportHandler creates a new monthly report handler
+func NewReportHandler(baseHandler *BaseHandler, generator *report.Generator) *ReportHandler {
+	return &ReportHandler{BaseHandler: baseHandler, generator: generator, now: time.Now}
+}
+
+// RegisterRoutes registers the monthly report routes
+func (h *ReportHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/statistics/report", h.handleMonthlyReport, http.MethodGet)
+}
+
+// handleMonthlyReport returns the report of the month query parameter (YYYY-MM, default the past month)
+// as a standalone HTML page, which the browser prints to PDF. With download=1 the page is sent as an
+// attachment instead of being displayed.
+func (h *ReportHandler) handleMonthlyReport(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleMonthlyReport").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := h.now()
+	month := report.StartOfMonth(now).AddDate(0, -1, 0)
+	if monthStr := r.URL.Query().Get("month"); monthStr != "" {
+		parsed, err := report.ParseMonth(monthStr, now.Location())
+		if err != nil {
+			http.Error(w, "month must be formatted as YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		month = parsed
+	}
+
+	monthlyReport, err := h.generator.Generate(r.Context(), month)
+	if err != nil {
+		handlerLogger.Error().Err(err).Str("month", month.Format("2006-01")).Msg("Failed to generate monthly report")
+		http.Error(w, "Failed to generate the report", http.StatusInternalServerError)
+		return
+	}
+
+	// Rendered to a buffer first so that a template error still returns a clean error response
+	var buf bytes.Buffer
+	if err := report.RenderHTML(&buf, monthlyReport); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to render monthly report")
+		http.Error(w, "Failed to render the report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.URL.Query().Get("download") == "1" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="night-routine-report-%s.html"`, monthlyReport.MonthKey()))
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to write monthly report")
+	}
+}
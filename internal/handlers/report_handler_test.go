@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestReportHandler(t *testing.T) (*ReportHandler, *fairness.Tracker, func()) {
+	detailsHandler, tracker, _, cleanup := setupTestAssignmentDetailsHandler(t, true)
+	handler := NewReportHandler(detailsHandler.BaseHandler, report.NewGenerator(tracker))
+	handler.now = func() time.Time { return time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC) }
+	return handler, tracker, cleanup
+}
+
+func TestReportHandler_MonthlyReport(t *testing.T) {
+	handler, tracker, cleanup := setupTestReportHandler(t)
+	defer cleanup()
+
+	_, err := tracker.RecordAssignment(t.Context(), "Alice", time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", time.Date(2026, time.September, 2, 0, 0, 0, 0, time.UTC), true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", time.Date(2026, time.August, 10, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+
+	t.Run("defaults to the past month", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.handleMonthlyReport(w, httptest.NewRequest(http.MethodGet, "/statistics/report", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+		body := w.Body.String()
+		assert.Contains(t, body, "Night Routine - September 2026")
+		assert.Contains(t, body, "Wed 2026-09-02")
+		assert.Contains(t, body, "2026-08")
+	})
+
+	t.Run("download of a given month", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.handleMonthlyReport(w, httptest.NewRequest(http.MethodGet, "/statistics/report?month=2026-08&download=1", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="night-routine-report-2026-08.html"`, w.Header().Get("Content-Disposition"))
+		assert.Contains(t, w.Body.String(), "Night Routine - August 2026")
+		assert.Contains(t, w.Body.String(), "No night was set by hand this month.")
+	})
+}
+
+func TestReportHandler_Errors(t *testing.T) {
+	handler, _, cleanup := setupTestReportHandler(t)
+	defer cleanup()
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{name: "invalid month", method: http.MethodGet, target: "/statistics/report?month=2026-13", wantStatus: http.StatusBadRequest},
+		{name: "malformed month", method: http.MethodGet, target: "/statistics/report?month=september", wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, target: "/statistics/report", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleMonthlyReport(w, httptest.NewRequest(tt.method, tt.target, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
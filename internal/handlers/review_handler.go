@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+)
+
+// StagedLister lists the regenerated nights waiting for review, implemented by database.StagingStore
+type StagedLister interface {
+	ListStaged() ([]*database.StagedAssignment, error)
+}
+
+// StagedPublisher publishes the staged nights to the calendar, implemented by calendar.Service
+type StagedPublisher interface {
+	PublishStaged(ctx context.Context, stagedBefore time.Time) (int, error)
+}
+
+// ReviewHandler lists the regenerated nights staged for review and publishes them on confirmation
+type ReviewHandler struct {
+	*BaseHandler
+	staging   StagedLister
+	publisher StagedPublisher
+	timeout   time.Duration // Staged nights are published automatically after it; 0 waits for the confirmation
+	now       func() time.Time
+}
+
+// NewReviewHandler creates a new handler of the nights staged for review
+func NewReviewHandler(baseHandler *BaseHandler, staging StagedLister, publisher StagedPublisher, timeout time.Duration) *ReviewHandler {
+	return &ReviewHandler{
+		BaseHandler: baseHandler,
+		staging:     staging,
+		publisher:   publisher,
+		timeout:     timeout,
+		now:         time.Now,
+	}
+}
+
+// RegisterRoutes registers the review routes
+func (h *ReviewHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/staged-assignments", h.handleListStaged, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/v1/staged-assignments/publish", h.handlePublish, http.MethodPost)
+}
+
+// StagedAssignmentResponse is a regenerated night whose calendar event waits for review
+type StagedAssignmentResponse struct {
+	AssignmentID int64      `json:"assignment_id"`
+	Date         string     `json:"date"`   // YYYY-MM-DD
+	Parent       string     `json:"parent"` // Caregiver once published
+	StagedAt     time.Time  `json:"staged_at"`
+	PublishAt    *time.Time `json:"publish_at,omitempty"` // Automatic publication, missing without review timeout
+}
+
+// PublishStagedResponse is the JSON response of a publication
+type PublishStagedResponse struct {
+	Published int `json:"published"` // Nights whose calendar event was updated
+}
+
+// handleListStaged returns the nights staged for review, ordered by date
+func (h *ReviewHandler) handleListStaged(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleListStaged").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	staged, err := h.staging.ListStaged()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list staged assignments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve staged assignments", handlerLogger)
+		return
+	}
+	response := make([]StagedAssignmentResponse, 0, len(staged))
+	for _, a := range staged {
+		item := StagedAssignmentResponse{
+			AssignmentID: a.AssignmentID,
+			Date:         a.Date.Format("2006-01-02"),
+			Parent:       a.Parent,
+			StagedAt:     a.StagedAt,
+		}
+		if h.timeout > 0 {
+			publishAt := a.StagedAt.Add(h.timeout)
+			item.PublishAt = &publishAt
+		}
+		response = append(response, item)
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// handlePublish updates the calendar events of every staged night on POST
+func (h *ReviewHandler) handlePublish(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handlePublish").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var published int
+	if err := h.SyncRuns.RecordRun(r.Context(), constants.SyncTriggerPublish, func(ctx context.Context) (int, error) {
+		var err error
+		published, err = h.publisher.PublishStaged(ctx, h.now())
+		return published, err
+	}); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to publish staged assignments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeSyncFailed, "Failed to publish the staged nights", handlerLogger)
+		return
+	}
+	handlerLogger.Info().Int("published", published).Msg("Staged assignments published")
+	writeJSON(w, http.StatusOK, PublishStagedResponse{Published: published}, handlerLogger)
+}
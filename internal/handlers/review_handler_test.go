@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// staticStaging lists the same staged assignments
+type staticStaging []*database.StagedAssignment
+
+func (s staticStaging) ListStaged() ([]*database.StagedAssignment, error) {
+	return s, nil
+}
+
+// recordingPublisher records the time of the publications
+type recordingPublisher struct {
+	published    int
+	err          error
+	stagedBefore time.Time
+}
+
+func (p *recordingPublisher) PublishStaged(_ context.Context, stagedBefore time.Time) (int, error) {
+	p.stagedBefore = stagedBefore
+	return p.published, p.err
+}
+
+func setupTestReviewHandler(t *testing.T, authenticated bool, publisher *recordingPublisher, timeout time.Duration) *ReviewHandler {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), nil, nil)
+	require.NoError(t, err)
+
+	staging := staticStaging{{
+		AssignmentID: 42,
+		Date:         time.Date(2026, 11, 20, 0, 0, 0, 0, time.Local),
+		Parent:       "Bob",
+		StagedAt:     time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC),
+	}}
+	handler := NewReviewHandler(baseHandler, staging, publisher, timeout)
+	handler.now = func() time.Time { return absenceTestNow }
+	return handler
+}
+
+func TestReviewHandler_ListStaged(t *testing.T) {
+	publishAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		authenticated bool
+		timeout       time.Duration
+		wantStatus    int
+		want          []StagedAssignmentResponse
+	}{
+		{"Without timeout", true, 0, http.StatusOK, []StagedAssignmentResponse{{AssignmentID: 42, Date: "2026-11-20", Parent: "Bob", StagedAt: time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)}}},
+		{"With timeout", true, 48 * time.Hour, http.StatusOK, []StagedAssignmentResponse{{AssignmentID: 42, Date: "2026-11-20", Parent: "Bob", StagedAt: time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC), PublishAt: &publishAt}}},
+		{"Unauthenticated", false, 0, http.StatusUnauthorized, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestReviewHandler(t, tt.authenticated, &recordingPublisher{}, tt.timeout)
+
+			w := httptest.NewRecorder()
+			handler.handleListStaged(w, httptest.NewRequest(http.MethodGet, "/api/v1/staged-assignments", nil))
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response []StagedAssignmentResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.want, response)
+		})
+	}
+}
+
+func TestReviewHandler_Publish(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticated bool
+		publisher     *recordingPublisher
+		wantStatus    int
+		wantCode      apierror.Code
+	}{
+		{"Publishes the staged nights", true, &recordingPublisher{published: 1}, http.StatusOK, ""},
+		{"Sync failure", true, &recordingPublisher{err: errors.New("calendar service not initialized")}, http.StatusInternalServerError, apierror.CodeSyncFailed},
+		{"Unauthenticated", false, &recordingPublisher{}, http.StatusUnauthorized, apierror.CodeAuthenticationRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := setupTestReviewHandler(t, tt.authenticated, tt.publisher, 0)
+
+			w := httptest.NewRecorder()
+			handler.handlePublish(w, httptest.NewRequest(http.MethodPost, "/api/v1/staged-assignments/publish", nil))
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+
+			if tt.wantStatus != http.StatusOK {
+				var response apierror.Response
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantCode, response.Code)
+				return
+			}
+			var response PublishStagedResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, PublishStagedResponse{Published: 1}, response)
+			assert.Equal(t, absenceTestNow, tt.publisher.stagedBefore, "every night staged until now is published")
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/logging"
+)
+
+// apiPathPrefix prefixes the paths of the JSON endpoints
+const apiPathPrefix = "/api/"
+
+// handleMethods registers handler on mux for the methods of the path, GET answering HEAD as well.
+// The other methods of the path are answered 405 Method Not Allowed with the Allow header, with a
+// JSON error under /api/ and a plain text one elsewhere.
+func handleMethods(mux *http.ServeMux, path string, handler http.HandlerFunc, methods ...string) {
+	allowed := make([]string, 0, len(methods)+1)
+	for _, method := range methods {
+		mux.HandleFunc(method+" "+path, handler)
+		allowed = append(allowed, method)
+		if method == http.MethodGet {
+			allowed = append(allowed, http.MethodHead)
+		}
+	}
+	allow := strings.Join(allowed, ", ")
+
+	logger := logging.GetLogger("routes")
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		logger.Warn().Str("method", r.Method).Str("path", r.URL.Path).Msg("Method not allowed")
+		w.Header().Set("Allow", allow)
+		if strings.HasPrefix(path, apiPathPrefix) {
+			writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", logger)
+			return
+		}
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	})
+}
+
+// handleAPINotFound answers the paths under /api/ matching no endpoint with a JSON 404
+func handleAPINotFound(mux *http.ServeMux) {
+	logger := logging.GetLogger("routes")
+	mux.HandleFunc(apiPathPrefix, func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "No endpoint at "+r.URL.Path, logger)
+	})
+}
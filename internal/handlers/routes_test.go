@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleMethods(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	handleMethods(mux, "/{$}", ok, http.MethodGet)
+	handleMethods(mux, "/settings/update", ok, http.MethodPost)
+	handleMethods(mux, "/api/v1/settings", ok, http.MethodGet, http.MethodPut)
+	handleMethods(mux, "/api/v1/locks/{id}", ok, http.MethodDelete)
+	handleAPINotFound(mux)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantAllow  string
+		wantCode   apierror.Code
+	}{
+		{"Registered method", http.MethodPost, "/settings/update", http.StatusNoContent, "", ""},
+		{"HEAD of a GET route", http.MethodHead, "/", http.StatusNoContent, "", ""},
+		{"Second method", http.MethodPut, "/api/v1/settings", http.StatusNoContent, "", ""},
+		{"Wildcard", http.MethodDelete, "/api/v1/locks/3", http.StatusNoContent, "", ""},
+		{"Form route with GET", http.MethodGet, "/settings/update", http.StatusMethodNotAllowed, "POST", ""},
+		{"Home page with POST", http.MethodPost, "/", http.StatusMethodNotAllowed, "GET, HEAD", ""},
+		{"API route with DELETE", http.MethodDelete, "/api/v1/settings", http.StatusMethodNotAllowed, "GET, HEAD, PUT", apierror.CodeMethodNotAllowed},
+		{"Wildcard with GET", http.MethodGet, "/api/v1/locks/3", http.StatusMethodNotAllowed, "DELETE", apierror.CodeMethodNotAllowed},
+		{"Unknown page", http.MethodGet, "/unknown", http.StatusNotFound, "", ""},
+		{"Unknown endpoint", http.MethodGet, "/api/unknown", http.StatusNotFound, "", apierror.CodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantAllow, w.Header().Get("Allow"))
+			if tt.wantCode != "" {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), `"code":"`+string(tt.wantCode)+`"`)
+			}
+		})
+	}
+}
@@ -7,30 +7,64 @@ import (
 
 	"github.com/belphemur/night-routine/internal/calendar"
 	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
 	"github.com/belphemur/night-routine/internal/fairness"
 	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
 	"github.com/rs/zerolog"
 )
 
 // recalculateScheduleAndSync regenerates assignments from fromDate and syncs
-// assignments that already have Google Calendar event IDs.
+// assignments that already have Google Calendar event IDs. The run is recorded
+// in the sync history under trigger.
 func recalculateScheduleAndSync(
 	ctx context.Context,
 	logger zerolog.Logger,
+	syncRuns *database.SyncRunStore,
+	trigger constants.SyncTrigger,
 	tracker fairness.TrackerInterface,
 	scheduler Scheduler.SchedulerInterface,
 	calendarService calendar.CalendarService,
 	configStore config.ConfigStoreInterface,
 	fromDate time.Time,
 ) error {
-	recalcLogger := logger.With().Str("from_date", fromDate.Format("2006-01-02")).Logger()
+	return syncRuns.RecordRun(ctx, trigger, func(ctx context.Context) (int, error) {
+		return recalculateSchedule(ctx, logger, trigger, tracker, scheduler, calendarService, configStore, fromDate)
+	})
+}
+
+// recalculateSchedule performs the recalculation of recalculateScheduleAndSync and returns the
+// number of assignments synced. When trigger has its own look-ahead window, only the days of the
+// window are recalculated; the days after it are left to the next periodic sync.
+func recalculateSchedule(
+	ctx context.Context,
+	logger zerolog.Logger,
+	trigger constants.SyncTrigger,
+	tracker fairness.TrackerInterface,
+	scheduler Scheduler.SchedulerInterface,
+	calendarService calendar.CalendarService,
+	configStore config.ConfigStoreInterface,
+	fromDate time.Time,
+) (int, error) {
+	recalcLogger := logger.With().Str("from_date", fromDate.Format("2006-01-02")).Stringer("trigger", trigger).Logger()
 	recalcLogger.Info().Msg("Recalculating schedule")
 
+	windows, err := configStore.GetLookAheadWindows()
+	if err != nil {
+		recalcLogger.Error().Err(err).Msg("Failed to get look-ahead windows")
+		return 0, fmt.Errorf("failed to get look-ahead windows: %w", err)
+	}
+	if window := windows.ForTrigger(trigger); window > 0 {
+		endDate := fromDate.AddDate(0, 0, window)
+		recalcLogger.Debug().Int("look_ahead_days", window).Time("end_date", endDate).Msg("Using the look-ahead window of the trigger as recalculation end date")
+		return generateAndSyncWithEventIDs(ctx, recalcLogger, scheduler, calendarService, fromDate, endDate)
+	}
+
 	recalcLogger.Debug().Msg("Fetching last assignment date from tracker")
-	lastAssignmentDate, err := tracker.GetLastAssignmentDate()
+	lastAssignmentDate, err := tracker.GetLastAssignmentDate(ctx)
 	if err != nil {
 		recalcLogger.Error().Err(err).Msg("Failed to get last assignment date")
-		return fmt.Errorf("failed to get last assignment date: %w", err)
+		return 0, fmt.Errorf("failed to get last assignment date: %w", err)
 	}
 	recalcLogger.Debug().Time("last_assignment_date", lastAssignmentDate).Msg("Retrieved last assignment date")
 
@@ -40,7 +74,7 @@ func recalculateScheduleAndSync(
 		_, lookAheadDays, _, _, err := configStore.GetSchedule()
 		if err != nil {
 			recalcLogger.Error().Err(err).Msg("Failed to get schedule configuration")
-			return fmt.Errorf("failed to get schedule configuration: %w", err)
+			return 0, fmt.Errorf("failed to get schedule configuration: %w", err)
 		}
 		endDate = fromDate.AddDate(0, 0, lookAheadDays)
 		recalcLogger.Debug().Int("look_ahead_days", lookAheadDays).Time("end_date", endDate).Msg("Calculated end date from look-ahead settings")
@@ -48,11 +82,23 @@ func recalculateScheduleAndSync(
 		recalcLogger.Debug().Time("end_date", endDate).Msg("Using last assignment date as recalculation end date")
 	}
 
+	return generateAndSyncWithEventIDs(ctx, recalcLogger, scheduler, calendarService, fromDate, endDate)
+}
+
+// generateAndSyncWithEventIDs regenerates the assignments from fromDate to endDate and syncs the ones
+// that already have Google Calendar event IDs
+func generateAndSyncWithEventIDs(
+	ctx context.Context,
+	recalcLogger zerolog.Logger,
+	scheduler Scheduler.SchedulerInterface,
+	calendarService calendar.CalendarService,
+	fromDate, endDate time.Time,
+) (int, error) {
 	recalcLogger.Debug().Time("start_date", fromDate).Time("end_date", endDate).Msg("Generating schedule for recalculation window")
-	assignments, err := scheduler.GenerateSchedule(fromDate, endDate, time.Now())
+	assignments, err := scheduler.GenerateSchedule(ctx, fromDate, endDate, time.Now())
 	if err != nil {
 		recalcLogger.Error().Err(err).Msg("Failed to generate schedule during recalculation")
-		return fmt.Errorf("failed to generate schedule: %w", err)
+		return 0, fmt.Errorf("failed to generate schedule: %w", err)
 	}
 	recalcLogger.Info().Int("assignments_generated", len(assignments)).Msg("Generated schedule during recalculation")
 
@@ -67,9 +113,9 @@ func recalculateScheduleAndSync(
 	recalcLogger.Debug().Msg("Syncing recalculated assignments with calendar")
 	if err := calendarService.SyncSchedule(ctx, withEventIDs); err != nil {
 		recalcLogger.Error().Err(err).Msg("Failed to sync recalculated assignments")
-		return fmt.Errorf("failed to sync schedule: %w", err)
+		return len(withEventIDs), fmt.Errorf("failed to sync schedule: %w", err)
 	}
 
 	recalcLogger.Info().Msg("Schedule recalculation and sync completed")
-	return nil
+	return len(withEventIDs), nil
 }
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/features"
+	"github.com/rs/zerolog"
+)
+
+// selfServicePath is the path of the self-service page, followed by the token of the link
+const selfServicePath = "/me/"
+
+// ParentLinks stores the self-service link of each parent, implemented by database.ParentLinkStore
+type ParentLinks interface {
+	CreateLink(parent string, now time.Time) (string, error)
+	ParentForToken(token string) (string, bool, error)
+	ListLinks() (map[string]time.Time, error)
+	RevokeLink(parent string) error
+}
+
+// SelfServiceHandler serves the page where a parent, opening their own link, manages their
+// unavailable days, recurring unavailability, color and avatar, and the API creating the links.
+// The application has no user accounts: the random token of the link is the credential, and only
+// gives access to the settings of its parent.
+type SelfServiceHandler struct {
+	*BaseHandler
+	links           ParentLinks
+	configStore     database.ConfigStoreInterface
+	Scheduler       Scheduler.SchedulerInterface
+	CalendarService calendar.CalendarService
+	appURL          string
+	now             func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewSelfServiceHandler creates a new self-service handler; the links point to the web interface at appURL
+func NewSelfServiceHandler(baseHandler *BaseHandler, links ParentLinks, configStore database.ConfigStoreInterface, sched Scheduler.SchedulerInterface, calSvc calendar.CalendarService, appURL string) *SelfServiceHandler {
+	return &SelfServiceHandler{
+		BaseHandler:     baseHandler,
+		links:           links,
+		configStore:     configStore,
+		Scheduler:       sched,
+		CalendarService: calSvc,
+		appURL:          appURL,
+		now:             time.Now,
+	}
+}
+
+// RegisterRoutes registers the self-service routes
+func (h *SelfServiceHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, selfServicePath+"{token}", h.handleSelfService, http.MethodGet, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/parent-links", h.handleParentLinks, http.MethodGet, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/parent-links/{parent}", h.handleRevokeParentLink, http.MethodDelete)
+}
+
+// SelfServicePageData contains data for the self-service page template
+type SelfServicePageData struct {
+	BasePageData
+	Token           string // Token of the link, the form posts back to it
+	FeedURL         string // iCalendar feed of the schedule, authenticated by the token; empty while disabled
+	Parent          string // Name of the parent
+	Days            []SelfServiceDay
+	Rules           UnavailabilityRulesSetting
+	Style           config.ParentStyle
+	AllParentColors []constants.ParentColor
+	ErrorMessage    string
+	SuccessMessage  string
+}
+
+// SelfServiceDay is a day of the week on the self-service page
+type SelfServiceDay struct {
+	Name        string // e.g. "Monday"
+	Unavailable bool
+}
+
+// ParentLinkResponse is the self-service link of a parent
+type ParentLinkResponse struct {
+	Parent    string     `json:"parent"`               // "parent_a", "parent_b", then "parent_c" and on for the other parents
+	Name      string     `json:"name"`                 // Name of the parent
+	CreatedAt *time.Time `json:"created_at,omitempty"` // Missing when the parent has no link
+	URL       string     `json:"url,omitempty"`        // Only returned when the link is created
+}
+
+// CreateParentLinkRequest is the JSON body creating the link of a parent
+type CreateParentLinkRequest struct {
+	Parent string `json:"parent"` // "parent_a", "parent_b", then "parent_c" and on for the other parents
+}
+
+// handleSelfService shows the self-service page of the parent of the link on GET and saves it on POST.
+// An unknown token is not found, whatever the method.
+func (h *SelfServiceHandler) handleSelfService(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleSelfService").Str("method", r.Method).Logger()
+
+	// The token is the credential, it must not leak through the caches or the links followed
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	token := r.PathValue("token")
+	parentKey, ok, err := h.links.ParentForToken(token)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to look up parent link")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		handlerLogger.Warn().Msg("Unknown self-service link")
+		http.NotFound(w, r)
+		return
+	}
+	handlerLogger = handlerLogger.With().Str("parent_key", parentKey).Logger()
+
+	if r.Method == http.MethodPost {
+		h.saveSelfService(w, r, token, parentKey, handlerLogger)
+		return
+	}
+
+	roster, err := h.loadRoster()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parents")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	index, _ := config.ParentKeyIndex(parentKey)
+	if index >= len(roster) {
+		handlerLogger.Warn().Msg("Self-service link of a parent no longer taking turns")
+		http.NotFound(w, r)
+		return
+	}
+	unavailable, err := h.configStore.GetAvailability(parentKey)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get availability")
+	}
+	rules, err := h.configStore.GetUnavailabilityRules(parentKey)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get unavailability rules")
+	}
+
+	data := SelfServicePageData{
+		BasePageData:    h.NewBasePageData(r, false),
+		Token:           token,
+		Parent:          roster[index].Name,
+		Rules:           newUnavailabilityRulesSetting(rules),
+		Style:           roster[index].Style,
+		AllParentColors: constants.GetAllParentColors(),
+		SuccessMessage:  GetSuccessMessage(r.URL.Query().Get("success")),
+	}
+	for _, day := range getAllDaysOfWeek() {
+		data.Days = append(data.Days, SelfServiceDay{Name: day, Unavailable: slices.Contains(unavailable, day)})
+	}
+	if h.Features.Enabled(features.ICSFeed) {
+		data.FeedURL = strings.TrimSuffix(h.appURL, "/") + "/calendar.ics?token=" + url.QueryEscape(token)
+	}
+	if code := r.URL.Query().Get("error"); code != "" {
+		data.ErrorMessage = GetErrorMessage(code)
+	}
+	h.RenderTemplate(w, "self_service.html", data)
+}
+
+// saveSelfService saves the availability, recurring unavailability and style of the parent of the
+// link, then recalculates and syncs the schedule from today. Nothing of the other parents changes.
+func (h *SelfServiceHandler) saveSelfService(w http.ResponseWriter, r *http.Request, token, parentKey string, logger zerolog.Logger) {
+	page := selfServicePath + url.PathEscape(token)
+	if err := r.ParseForm(); err != nil {
+		logger.Error().Err(err).Msg("Failed to parse form")
+		http.Redirect(w, r, page+"?error="+ErrCodeInvalidFormData, http.StatusSeeOther)
+		return
+	}
+
+	rules, err := parseUnavailabilityRules(r.FormValue("unavailability_rules"))
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid unavailability rule")
+		http.Redirect(w, r, page+"?error="+ErrCodeInvalidUnavailabilityRule, http.StatusSeeOther)
+		return
+	}
+	roster, err := h.loadRoster()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get parents")
+		http.Redirect(w, r, page+"?error="+ErrCodeFailedSaveParent, http.StatusSeeOther)
+		return
+	}
+	index, _ := config.ParentKeyIndex(parentKey)
+	if index >= len(roster) {
+		logger.Warn().Msg("Self-service link of a parent no longer taking turns")
+		http.NotFound(w, r)
+		return
+	}
+	style, err := parseParentStyle(r.Form, "self", roster[index].Style)
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid parent style")
+		http.Redirect(w, r, page+"?error="+ErrCodeInvalidParentStyle, http.StatusSeeOther)
+		return
+	}
+	roster[index].Style = style
+
+	if err := h.configStore.SaveAvailability(r.Context(), parentKey, r.Form["unavailable"]); err != nil {
+		logger.Error().Err(err).Msg("Failed to save availability")
+		http.Redirect(w, r, page+"?error="+validationErrorCode(err), http.StatusSeeOther)
+		return
+	}
+	if err := h.configStore.SaveUnavailabilityRules(r.Context(), parentKey, rules); err != nil {
+		logger.Error().Err(err).Msg("Failed to save unavailability rules")
+		http.Redirect(w, r, page+"?error="+ErrCodeFailedSaveAvailability, http.StatusSeeOther)
+		return
+	}
+	if err := h.saveRosterStyles(r.Context(), roster, index); err != nil {
+		logger.Error().Err(err).Msg("Failed to save parent styles")
+		http.Redirect(w, r, page+"?error="+ErrCodeFailedSaveParent, http.StatusSeeOther)
+		return
+	}
+	logger.Info().Strs("unavailable_days", r.Form["unavailable"]).Int("rule_count", len(rules)).Msg("Self-service availability saved")
+
+	// Until Google Calendar is connected, the first sync schedules with the saved availability
+	if !h.CalendarService.IsInitialized() {
+		http.Redirect(w, r, page+"?success="+SuccessCodeAvailabilityUpdated, http.StatusSeeOther)
+		return
+	}
+	now := h.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if err := recalculateScheduleAndSync(r.Context(), logger, h.SyncRuns, constants.SyncTriggerSettings, h.Tracker, h.Scheduler, h.CalendarService, h.ConfigStore, today); err != nil {
+		logger.Error().Err(err).Msg("Failed to recalculate schedule after self-service update")
+		http.Redirect(w, r, page+"?success="+SuccessCodeSettingsUpdatedSyncFailed, http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, page+"?success="+SuccessCodeAvailabilityUpdated, http.StatusSeeOther)
+}
+
+// handleParentLinks lists the parents with the creation time of their link on GET, and creates the
+// link of a parent on POST, replacing the previous one. The URL of a link is only returned when it
+// is created.
+func (h *SelfServiceHandler) handleParentLinks(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleParentLinks").Str("method", r.Method).Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+	roster, err := h.loadRoster()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parents")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the parents", handlerLogger)
+		return
+	}
+	names := make(map[string]string, len(roster))
+	for i, parent := range roster {
+		names[config.ParentKey(i)] = parent.Name
+	}
+
+	if r.Method == http.MethodPost {
+		var req CreateParentLinkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || names[req.Parent] == "" {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, parent must be the key of a parent taking turns, e.g. parent_a", handlerLogger)
+			return
+		}
+		now := h.now()
+		token, err := h.links.CreateLink(req.Parent, now)
+		if err != nil {
+			handlerLogger.Error().Err(err).Str("parent", req.Parent).Msg("Failed to create parent link")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create the link", handlerLogger)
+			return
+		}
+		writeJSON(w, http.StatusCreated, ParentLinkResponse{
+			Parent:    req.Parent,
+			Name:      names[req.Parent],
+			CreatedAt: &now,
+			URL:       strings.TrimSuffix(h.appURL, "/") + selfServicePath + token,
+		}, handlerLogger)
+		return
+	}
+
+	links, err := h.links.ListLinks()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list parent links")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve the links", handlerLogger)
+		return
+	}
+	response := make([]ParentLinkResponse, 0, len(names))
+	for i := range roster {
+		parent := config.ParentKey(i)
+		link := ParentLinkResponse{Parent: parent, Name: names[parent]}
+		if createdAt, ok := links[parent]; ok {
+			link.CreatedAt = &createdAt
+		}
+		response = append(response, link)
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// handleRevokeParentLink deletes the link of a parent on DELETE, which stops working at once
+func (h *SelfServiceHandler) handleRevokeParentLink(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleRevokeParentLink").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+	parent := r.PathValue("parent")
+	if !config.IsParentKey(parent) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Unknown parent, expected the key of a parent, e.g. parent_a", handlerLogger)
+		return
+	}
+	if err := h.links.RevokeLink(parent); err != nil {
+		handlerLogger.Error().Err(err).Str("parent", parent).Msg("Failed to revoke parent link")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to revoke the link", handlerLogger)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadRoster returns the parents taking turns with their style, in the order of their keys
+func (h *SelfServiceHandler) loadRoster() ([]config.ExtraParent, error) {
+	parentA, parentB, err := h.configStore.GetParents()
+	if err != nil {
+		return nil, err
+	}
+	styleA, styleB, err := h.configStore.GetParentStyles()
+	if err != nil {
+		return nil, err
+	}
+	extra, err := h.configStore.GetExtraParents()
+	if err != nil {
+		return nil, err
+	}
+	return append([]config.ExtraParent{{Name: parentA, Style: styleA}, {Name: parentB, Style: styleB}}, extra...), nil
+}
+
+// saveRosterStyles saves the style of the parent at index in roster: parent A and parent B with
+// their styles, the other parents with the extra parents
+func (h *SelfServiceHandler) saveRosterStyles(ctx context.Context, roster []config.ExtraParent, index int) error {
+	if index < 2 {
+		return h.configStore.SaveParentStyles(ctx, roster[0].Style, roster[1].Style)
+	}
+	return h.configStore.SaveExtraParents(ctx, roster[2:])
+}
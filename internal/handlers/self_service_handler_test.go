@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestSelfServiceHandler(t *testing.T, authenticated bool) (*SelfServiceHandler, *database.ConfigStore, *database.ParentLinkStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveParents(t.Context(), "TestParentA", "TestParentB"))
+	require.NoError(t, configStore.SaveAvailability(t.Context(), "parent_b", []string{"Friday"}))
+	require.NoError(t, configStore.SaveSchedule(t.Context(), "weekly", 30, 5, constants.StatsOrderDesc))
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	links, err := database.NewParentLinkStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	configAdapter := database.NewConfigAdapter(configStore, oauthCfg)
+	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	handler := NewSelfServiceHandler(baseHandler, links, configStore, Scheduler.New(configAdapter, tracker), &noopCalendarService{}, "https://night.example.com/")
+	return handler, configStore, links
+}
+
+func selfServiceRequest(method, token string, form url.Values) *http.Request {
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, selfServicePath+token, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, selfServicePath+token, nil)
+	}
+	req.SetPathValue("token", token)
+	return req
+}
+
+func TestSelfServiceHandler_Page(t *testing.T) {
+	handler, _, links := setupTestSelfServiceHandler(t, false)
+	token, err := links.CreateLink("parent_b", time.Now())
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.handleSelfService(w, selfServiceRequest(http.MethodGet, token, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), "Hi TestParentB")
+	assert.Contains(t, w.Body.String(), `value="Friday" checked`)
+
+	w = httptest.NewRecorder()
+	handler.handleSelfService(w, selfServiceRequest(http.MethodGet, "unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = httptest.NewRecorder()
+	handler.handleSelfService(w, selfServiceRequest(http.MethodPost, "unknown", url.Values{"unavailable": {"Monday"}}))
+	assert.Equal(t, http.StatusNotFound, w.Code, "an unknown link cannot save")
+}
+
+func TestSelfServiceHandler_Save(t *testing.T) {
+	handler, configStore, links := setupTestSelfServiceHandler(t, false)
+	token, err := links.CreateLink("parent_a", time.Now())
+	require.NoError(t, err)
+	_, styleB, err := configStore.GetParentStyles()
+	require.NoError(t, err)
+
+	form := url.Values{
+		"unavailable":          {"Monday", "Tuesday"},
+		"unavailability_rules": {"FREQ=MONTHLY;BYDAY=1WE"},
+		"self_color":           {string(constants.ParentColorGrape)},
+		"self_avatar":          {"🦉"},
+	}
+	w := httptest.NewRecorder()
+	handler.handleSelfService(w, selfServiceRequest(http.MethodPost, token, form))
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, selfServicePath+token+"?success="+SuccessCodeAvailabilityUpdated, w.Header().Get("Location"))
+
+	unavailableA, err := configStore.GetAvailability("parent_a")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Monday", "Tuesday"}, unavailableA)
+	rules, err := configStore.GetUnavailabilityRules("parent_a")
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+	gotA, gotB, err := configStore.GetParentStyles()
+	require.NoError(t, err)
+	assert.Equal(t, config.ParentStyle{Color: constants.ParentColorGrape, Avatar: "🦉"}, gotA)
+
+	// The other parent keeps their settings
+	unavailableB, err := configStore.GetAvailability("parent_b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Friday"}, unavailableB)
+	assert.Equal(t, styleB, gotB)
+
+	// An invalid rule saves nothing
+	form.Set("unavailability_rules", "FREQ=NEVER")
+	form["unavailable"] = []string{"Sunday"}
+	w = httptest.NewRecorder()
+	handler.handleSelfService(w, selfServiceRequest(http.MethodPost, token, form))
+	assert.Equal(t, selfServicePath+token+"?error="+ErrCodeInvalidUnavailabilityRule, w.Header().Get("Location"))
+	unavailableA, err = configStore.GetAvailability("parent_a")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Monday", "Tuesday"}, unavailableA)
+}
+
+func TestSelfServiceHandler_ExtraParent(t *testing.T) {
+	handler, configStore, _ := setupTestSelfServiceHandler(t, true)
+	require.NoError(t, configStore.SaveExtraParents(t.Context(), []config.ExtraParent{{Name: "Grandma", Style: config.DefaultExtraParentStyle(2)}}))
+
+	w := httptest.NewRecorder()
+	handler.handleParentLinks(w, httptest.NewRequest(http.MethodPost, "/api/v1/parent-links", strings.NewReader(`{"parent":"parent_c"}`)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created ParentLinkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "Grandma", created.Name)
+	token := strings.TrimPrefix(created.URL, "https://night.example.com/me/")
+
+	w = httptest.NewRecorder()
+	handler.handleSelfService(w, selfServiceRequest(http.MethodGet, token, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Hi Grandma")
+
+	form := url.Values{
+		"unavailable": {"Sunday"},
+		"self_color":  {string(constants.ParentColorGrape)},
+		"self_avatar": {"👵"},
+	}
+	w = httptest.NewRecorder()
+	handler.handleSelfService(w, selfServiceRequest(http.MethodPost, token, form))
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, selfServicePath+token+"?success="+SuccessCodeAvailabilityUpdated, w.Header().Get("Location"))
+	unavailable, err := configStore.GetAvailability("parent_c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Sunday"}, unavailable)
+	extra, err := configStore.GetExtraParents()
+	require.NoError(t, err)
+	require.Len(t, extra, 1)
+	assert.Equal(t, config.ParentStyle{Color: constants.ParentColorGrape, Avatar: "👵"}, extra[0].Style)
+
+	w = httptest.NewRecorder()
+	handler.handleParentLinks(w, httptest.NewRequest(http.MethodGet, "/api/v1/parent-links", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []ParentLinkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 3)
+	assert.Equal(t, "parent_c", listed[2].Parent)
+	assert.NotNil(t, listed[2].CreatedAt)
+
+	// Once the parent no longer takes turns, the link stops working
+	require.NoError(t, configStore.SaveExtraParents(t.Context(), nil))
+	w = httptest.NewRecorder()
+	handler.handleSelfService(w, selfServiceRequest(http.MethodGet, token, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSelfServiceHandler_ParentLinks(t *testing.T) {
+	handler, _, links := setupTestSelfServiceHandler(t, true)
+
+	w := httptest.NewRecorder()
+	handler.handleParentLinks(w, httptest.NewRequest(http.MethodPost, "/api/v1/parent-links", strings.NewReader(`{"parent":"parent_c"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.handleParentLinks(w, httptest.NewRequest(http.MethodPost, "/api/v1/parent-links", strings.NewReader(`{"parent":"parent_a"}`)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created ParentLinkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "TestParentA", created.Name)
+	require.True(t, strings.HasPrefix(created.URL, "https://night.example.com/me/"), created.URL)
+	parent, ok, err := links.ParentForToken(strings.TrimPrefix(created.URL, "https://night.example.com/me/"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "parent_a", parent)
+
+	w = httptest.NewRecorder()
+	handler.handleParentLinks(w, httptest.NewRequest(http.MethodGet, "/api/v1/parent-links", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []ParentLinkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 2)
+	assert.NotNil(t, listed[0].CreatedAt)
+	assert.Empty(t, listed[0].URL, "the URL is only returned when the link is created")
+	assert.Nil(t, listed[1].CreatedAt)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/parent-links/parent_a", nil)
+	req.SetPathValue("parent", "parent_a")
+	w = httptest.NewRecorder()
+	handler.handleRevokeParentLink(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	got, err := links.ListLinks()
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestSelfServiceHandler_ParentLinksUnauthenticated(t *testing.T) {
+	handler, _, _ := setupTestSelfServiceHandler(t, false)
+
+	w := httptest.NewRecorder()
+	handler.handleParentLinks(w, httptest.NewRequest(http.MethodPost, "/api/v1/parent-links", strings.NewReader(`{"parent":"parent_a"}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
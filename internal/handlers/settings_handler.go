@@ -2,44 +2,56 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/apierror"
 	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
 	"github.com/belphemur/night-routine/internal/fairness/scheduler"
 	"github.com/belphemur/night-routine/internal/token"
+	"github.com/belphemur/night-routine/internal/validation"
 	"github.com/rs/zerolog"
 )
 
 // SettingsHandler manages settings page functionality
 type SettingsHandler struct {
 	*BaseHandler
-	configStore     *database.ConfigStore
+	configStore     database.ConfigStoreInterface
 	scheduler       *scheduler.Scheduler
 	tokenManager    *token.TokenManager
 	calendarService *calendar.Service
+	// notifyChannels are the notification channels configured in the file, which can be toggled here
+	notifyChannels []string
 }
 
 // NewSettingsHandler creates a new settings page handler
-func NewSettingsHandler(baseHandler *BaseHandler, configStore *database.ConfigStore, sched *scheduler.Scheduler, tokenMgr *token.TokenManager, calSvc *calendar.Service) *SettingsHandler {
+func NewSettingsHandler(baseHandler *BaseHandler, configStore database.ConfigStoreInterface, sched *scheduler.Scheduler, tokenMgr *token.TokenManager, calSvc *calendar.Service, notifyChannels []string) *SettingsHandler {
 	return &SettingsHandler{
 		BaseHandler:     baseHandler,
 		configStore:     configStore,
 		scheduler:       sched,
 		tokenManager:    tokenMgr,
 		calendarService: calSvc,
+		notifyChannels:  notifyChannels,
 	}
 }
 
 // RegisterRoutes registers settings related routes
 func (h *SettingsHandler) RegisterRoutes() {
-	http.HandleFunc("/settings", h.handleSettings)
-	http.HandleFunc("/settings/update", h.handleUpdateSettings)
+	handleMethods(http.DefaultServeMux, "/settings", h.handleSettings, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/settings/update", h.handleUpdateSettings, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/settings", h.handleSettingsAPI, http.MethodGet, http.MethodPut)
 }
 
 // SettingsPageData contains data for the settings page template
@@ -47,15 +59,77 @@ type SettingsPageData struct {
 	BasePageData
 	ParentA                string
 	ParentB                string
+	ExtraParents           string // Parents taking turns after parent A and parent B, one per line
 	ParentAUnavailable     []string
 	ParentBUnavailable     []string
+	ParentARules           UnavailabilityRulesSetting
+	ParentBRules           UnavailabilityRulesSetting
+	ParentAStyle           config.ParentStyle
+	ParentBStyle           config.ParentStyle
+	AllParentColors        []constants.ParentColor
 	UpdateFrequency        string
 	LookAheadDays          int
+	LookAheadWindows       config.LookAheadWindows // Look-ahead days of the periodic, webhook and manual syncs, 0 for LookAheadDays
 	PastEventThresholdDays int
 	StatsOrder             constants.StatsOrder
+	MinRestDays            int // Nights off a parent gets at least after a block of consecutive nights
+	MaxMinRestDays         int
+	DayWeights             []DayWeightSetting // Weight of the nights in the fairness totals on each weekday, Monday first
+	MinDayWeight           float64
+	MaxDayWeight           float64
+	DayWeightStep          float64
 	ErrorMessage           string
 	SuccessMessage         string
 	AllDaysOfWeek          []string
+	NotifyChannels         []NotifyChannelSetting
+	Checklist              string                    // Items of the bedtime checklist, one per line
+	WeekdayChecklists      []WeekdayChecklistSetting // Items added to the checklist on each weekday, Monday first
+	CommentsInEvents       bool                      // Whether the comments are written in the calendar events
+	SyncExcludeBabysitter  bool                      // Whether the babysitter nights are kept out of the calendar
+	SyncExcludeTags        []SyncExcludeTagSetting   // Tags whose nights can be kept out of the calendar
+	VacationEnabled        bool
+	VacationStart          string // First day of the vacation as YYYY-MM-DD, empty when never set
+	VacationEnd            string // Last day of the vacation as YYYY-MM-DD, empty when never set
+	SkipDates              SkipDatesSetting
+}
+
+// SkipDatesSetting is the list of days without night routine on the settings page
+type SkipDatesSetting struct {
+	Text         string   // Dates and rules, one per line
+	Descriptions []string // Dates and rules in plain English
+}
+
+// WeekdayChecklistSetting is the items added to the bedtime checklist on one weekday on the settings page
+type WeekdayChecklistSetting struct {
+	Weekday string // e.g. "Friday"
+	Field   string // Name of the form field, e.g. "checklist_friday"
+	Text    string // Items, one per line
+}
+
+// DayWeightSetting is the weight of the nights of one weekday on the settings page
+type DayWeightSetting struct {
+	Weekday string // e.g. "Friday"
+	Field   string // Name of the form field, e.g. "day_weight_friday"
+	Weight  string // Empty when the nights count 1
+}
+
+// UnavailabilityRulesSetting is the recurring unavailability of a parent on the settings page
+type UnavailabilityRulesSetting struct {
+	Text         string   // Rules in their RRULE form, one per line
+	Descriptions []string // Rules in plain English
+}
+
+// SyncExcludeTagSetting is a tag whose nights can be kept out of the calendar on the settings page
+type SyncExcludeTagSetting struct {
+	Value    string // e.g. "sick_kid"
+	Label    string // e.g. "Sick kid"
+	Excluded bool
+}
+
+// NotifyChannelSetting is a configured notification channel on the settings page
+type NotifyChannelSetting struct {
+	Name    string
+	Enabled bool
 }
 
 // handleSettings shows the settings page
@@ -77,6 +151,11 @@ func (h *SettingsHandler) handleSettings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	extraParents, err := h.configStore.GetExtraParents()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get extra parents")
+	}
+
 	parentAUnavailable, err := h.configStore.GetAvailability("parent_a")
 	if err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to get parent A availability")
@@ -89,6 +168,22 @@ func (h *SettingsHandler) handleSettings(w http.ResponseWriter, r *http.Request)
 		parentBUnavailable = []string{}
 	}
 
+	parentARules, err := h.configStore.GetUnavailabilityRules("parent_a")
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parent A unavailability rules")
+	}
+
+	parentBRules, err := h.configStore.GetUnavailabilityRules("parent_b")
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parent B unavailability rules")
+	}
+
+	parentAStyle, parentBStyle, err := h.configStore.GetParentStyles()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parent styles")
+		parentAStyle, parentBStyle = config.DefaultParentStyles()
+	}
+
 	updateFrequency, lookAheadDays, pastEventThresholdDays, statsOrder, err := h.configStore.GetSchedule()
 	if err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to get schedule configuration")
@@ -100,6 +195,57 @@ func (h *SettingsHandler) handleSettings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	checklist, err := h.configStore.GetChecklistTemplate()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get checklist template")
+	}
+
+	weekdayChecklists, err := h.configStore.GetWeekdayChecklists()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get weekday checklists")
+	}
+
+	commentsInEvents, err := h.configStore.GetCommentsInEvents()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get comment configuration")
+	}
+
+	syncExclusions, err := h.configStore.GetSyncExclusions()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get sync exclusions")
+	}
+
+	minRestDays, err := h.configStore.GetMinRestDays()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get rest days configuration")
+	}
+
+	dayWeights, err := h.configStore.GetDayWeights()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get day weights")
+	}
+
+	lookAheadWindows, err := h.configStore.GetLookAheadWindows()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get look-ahead windows")
+	}
+
+	vacation, err := h.configStore.GetVacation()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get vacation configuration")
+	}
+
+	skipDates, err := h.configStore.GetSkipDates()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get skip dates")
+	}
+
+	notifyChannels, err := h.getNotifyChannelSettings()
+	if err != nil {
+		// The section is hidden rather than failing the page
+		handlerLogger.Error().Err(err).Msg("Failed to get notification channel configuration")
+	}
+
 	// Process messages
 	errorMessage := GetErrorMessage(r.URL.Query().Get("error"))
 	successMessage := GetSuccessMessage(r.URL.Query().Get("success"))
@@ -113,15 +259,38 @@ func (h *SettingsHandler) handleSettings(w http.ResponseWriter, r *http.Request)
 		BasePageData:           h.NewBasePageData(r, true), // Always authenticated for settings
 		ParentA:                parentA,
 		ParentB:                parentB,
+		ExtraParents:           strings.Join(config.ExtraParentNames(extraParents), "\n"),
 		ParentAUnavailable:     parentAUnavailable,
 		ParentBUnavailable:     parentBUnavailable,
+		ParentARules:           newUnavailabilityRulesSetting(parentARules),
+		ParentBRules:           newUnavailabilityRulesSetting(parentBRules),
+		ParentAStyle:           parentAStyle,
+		ParentBStyle:           parentBStyle,
+		AllParentColors:        constants.GetAllParentColors(),
 		UpdateFrequency:        updateFrequency,
 		LookAheadDays:          lookAheadDays,
+		LookAheadWindows:       lookAheadWindows,
 		PastEventThresholdDays: pastEventThresholdDays,
 		StatsOrder:             statsOrder,
+		MinRestDays:            minRestDays,
+		MaxMinRestDays:         validation.MaxMinRestDays,
+		DayWeights:             newDayWeightSettings(dayWeights),
+		MinDayWeight:           validation.MinDayWeight,
+		MaxDayWeight:           validation.MaxDayWeight,
+		DayWeightStep:          validation.DayWeightStep,
 		ErrorMessage:           errorMessage,
 		SuccessMessage:         successMessage,
 		AllDaysOfWeek:          getAllDaysOfWeek(),
+		NotifyChannels:         notifyChannels,
+		Checklist:              strings.Join(checklist, "\n"),
+		WeekdayChecklists:      newWeekdayChecklistSettings(weekdayChecklists),
+		CommentsInEvents:       commentsInEvents,
+		SyncExcludeBabysitter:  syncExclusions.Babysitter,
+		SyncExcludeTags:        newSyncExcludeTagSettings(syncExclusions),
+		VacationEnabled:        vacation.Enabled,
+		VacationStart:          formatVacationDate(vacation.Start),
+		VacationEnd:            formatVacationDate(vacation.End),
+		SkipDates:              newSkipDatesSetting(skipDates),
 	}
 
 	handlerLogger.Debug().Msg("Rendering settings template")
@@ -136,7 +305,9 @@ func (h *SettingsHandler) handleUpdateSettings(w http.ResponseWriter, r *http.Re
 	// No authentication check - settings are always accessible
 
 	if r.Method != http.MethodPost {
-		http.Redirect(w, r, "/settings", http.StatusSeeOther)
+		handlerLogger.Warn().Msg("Invalid method for settings update")
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -151,24 +322,54 @@ func (h *SettingsHandler) handleUpdateSettings(w http.ResponseWriter, r *http.Re
 	parentA := strings.TrimSpace(r.FormValue("parent_a"))
 	parentB := strings.TrimSpace(r.FormValue("parent_b"))
 
+	// Extract the extra parents, one per line; a form without the field keeps them
+	var extraNames []string
+	if _, ok := r.Form["extra_parents"]; ok {
+		extraNames = parseExtraParentNames(r.FormValue("extra_parents"))
+	}
+	extraParents, err := h.extraParentsFor(extraNames)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get extra parents")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveParent, http.StatusSeeOther)
+		return
+	}
+
 	// Extract availability (checkboxes)
 	parentAUnavailable := r.Form["parent_a_unavailable"]
 	parentBUnavailable := r.Form["parent_b_unavailable"]
 
-	// Validate unavailable days
-	for _, day := range parentAUnavailable {
-		if !constants.IsValidDayOfWeek(day) {
-			handlerLogger.Error().Str("invalid_day", day).Msg("Invalid day in parent A availability")
-			http.Redirect(w, r, "/settings?error="+ErrCodeInvalidDayOfWeek, http.StatusSeeOther)
-			return
-		}
+	// Extract the recurring unavailability rules, one per line
+	parentARules, err := parseUnavailabilityRules(r.FormValue("parent_a_unavailability_rules"))
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Invalid rule in parent A unavailability rules")
+		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidUnavailabilityRule, http.StatusSeeOther)
+		return
 	}
-	for _, day := range parentBUnavailable {
-		if !constants.IsValidDayOfWeek(day) {
-			handlerLogger.Error().Str("invalid_day", day).Msg("Invalid day in parent B availability")
-			http.Redirect(w, r, "/settings?error="+ErrCodeInvalidDayOfWeek, http.StatusSeeOther)
-			return
-		}
+	parentBRules, err := parseUnavailabilityRules(r.FormValue("parent_b_unavailability_rules"))
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Invalid rule in parent B unavailability rules")
+		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidUnavailabilityRule, http.StatusSeeOther)
+		return
+	}
+
+	// Extract the color and the avatar of each parent
+	currentAStyle, currentBStyle, err := h.configStore.GetParentStyles()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parent styles")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveParent, http.StatusSeeOther)
+		return
+	}
+	parentAStyle, err := parseParentStyle(r.Form, "parent_a", currentAStyle)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Invalid parent A style")
+		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidParentStyle, http.StatusSeeOther)
+		return
+	}
+	parentBStyle, err := parseParentStyle(r.Form, "parent_b", currentBStyle)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Invalid parent B style")
+		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidParentStyle, http.StatusSeeOther)
+		return
 	}
 
 	// Extract schedule settings
@@ -177,65 +378,245 @@ func (h *SettingsHandler) handleUpdateSettings(w http.ResponseWriter, r *http.Re
 	pastEventThresholdDaysStr := r.FormValue("past_event_threshold_days")
 	statsOrderStr := r.FormValue("stats_order")
 
-	// Validate and convert numeric values with upper bounds
+	// Convert the numeric values, their bounds are checked with the other settings
 	lookAheadDays, err := strconv.Atoi(lookAheadDaysStr)
-	if err != nil || lookAheadDays < 1 || lookAheadDays > 365 {
+	if err != nil {
 		handlerLogger.Error().Err(err).Str("value", lookAheadDaysStr).Msg("Invalid look ahead days")
 		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidLookAheadDays, http.StatusSeeOther)
 		return
 	}
 
 	pastEventThresholdDays, err := strconv.Atoi(pastEventThresholdDaysStr)
-	if err != nil || pastEventThresholdDays < 0 || pastEventThresholdDays > 30 {
+	if err != nil {
 		handlerLogger.Error().Err(err).Str("value", pastEventThresholdDaysStr).Msg("Invalid past event threshold days")
 		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidPastEventThreshold, http.StatusSeeOther)
 		return
 	}
 
-	// Parse and validate stats order
-	statsOrder, err := constants.ParseStatsOrder(statsOrderStr)
+	// Parse the minimum rest days; an empty field enforces no rest
+	minRestDays := 0
+	if minRestDaysStr := r.FormValue("min_rest_days"); minRestDaysStr != "" {
+		minRestDays, err = strconv.Atoi(minRestDaysStr)
+		if err != nil {
+			handlerLogger.Error().Err(err).Str("value", minRestDaysStr).Msg("Invalid minimum rest days")
+			http.Redirect(w, r, "/settings?error="+ErrCodeInvalidMinRestDays, http.StatusSeeOther)
+			return
+		}
+	}
+
+	// Parse the weight of the nights of each weekday; an empty field counts them 1
+	dayWeights, err := parseDayWeights(r.Form)
 	if err != nil {
-		handlerLogger.Error().Err(err).Str("value", statsOrderStr).Msg("Invalid stats order")
-		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidStatsOrder, http.StatusSeeOther)
+		handlerLogger.Error().Err(err).Msg("Invalid day weight")
+		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidDayWeight, http.StatusSeeOther)
+		return
+	}
+
+	// Parse the look-ahead days of each sync; an empty field uses the look ahead days
+	var lookAheadWindows config.LookAheadWindows
+	for field, days := range map[string]*int{
+		"scheduled_look_ahead_days": &lookAheadWindows.Scheduled,
+		"webhook_look_ahead_days":   &lookAheadWindows.Webhook,
+		"manual_look_ahead_days":    &lookAheadWindows.Manual,
+	} {
+		if value := r.FormValue(field); value != "" {
+			if *days, err = strconv.Atoi(value); err != nil {
+				handlerLogger.Error().Err(err).Str("field", field).Str("value", value).Msg("Invalid look ahead days")
+				http.Redirect(w, r, "/settings?error="+ErrCodeInvalidLookAheadDays, http.StatusSeeOther)
+				return
+			}
+		}
+	}
+
+	settings := validation.Settings{
+		ParentA:                parentA,
+		ParentB:                parentB,
+		ExtraParents:           config.ExtraParentNames(extraParents),
+		ParentAUnavailable:     parentAUnavailable,
+		ParentBUnavailable:     parentBUnavailable,
+		UpdateFrequency:        updateFrequency,
+		LookAheadDays:          lookAheadDays,
+		PastEventThresholdDays: pastEventThresholdDays,
+		StatsOrder:             constants.StatsOrder(statsOrderStr),
+		MinRestDays:            minRestDays,
+		DayWeights:             dayWeights,
+		ScheduledLookAheadDays: lookAheadWindows.Scheduled,
+		WebhookLookAheadDays:   lookAheadWindows.Webhook,
+		ManualLookAheadDays:    lookAheadWindows.Manual,
+	}
+	if err := settings.Validate(); err != nil {
+		handlerLogger.Error().Err(err).Msg("Invalid settings")
+		http.Redirect(w, r, "/settings?error="+validationErrorCode(err), http.StatusSeeOther)
+		return
+	}
+
+	// Parse the vacation; an unchecked box is not submitted and keeps the dates for the next time
+	vacation, err := parseVacation(r.FormValue("vacation_enabled") == "on", r.FormValue("vacation_start"), r.FormValue("vacation_end"))
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Invalid vacation")
+		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidVacation, http.StatusSeeOther)
+		return
+	}
+
+	// Parse the days without night routine, one date or rule per line
+	skipDates, err := parseSkipDates(r.FormValue("skip_dates"))
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Invalid skip date")
+		http.Redirect(w, r, "/settings?error="+ErrCodeInvalidSkipDate, http.StatusSeeOther)
 		return
 	}
 
 	handlerLogger.Info().
 		Str("parent_a", parentA).
 		Str("parent_b", parentB).
+		Int("extra_parents", len(extraParents)).
 		Str("update_frequency", updateFrequency).
 		Int("look_ahead_days", lookAheadDays).
 		Int("past_event_threshold_days", pastEventThresholdDays).
-		Str("stats_order", statsOrder.String()).
+		Str("stats_order", settings.StatsOrder.String()).
+		Int("min_rest_days", minRestDays).
 		Msg("Updating configuration")
 
 	// Save parent configuration
-	if err := h.configStore.SaveParents(parentA, parentB); err != nil {
+	if err := h.configStore.SaveParents(r.Context(), parentA, parentB); err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to save parent configuration")
 		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveParent, http.StatusSeeOther)
 		return
 	}
 
+	if err := h.configStore.SaveExtraParents(r.Context(), extraParents); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save extra parents")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveParent, http.StatusSeeOther)
+		return
+	}
+
+	if err := h.configStore.SaveParentStyles(r.Context(), parentAStyle, parentBStyle); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save parent styles")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveParent, http.StatusSeeOther)
+		return
+	}
+
 	// Save availability configuration
-	if err := h.configStore.SaveAvailability("parent_a", parentAUnavailable); err != nil {
+	if err := h.configStore.SaveAvailability(r.Context(), "parent_a", parentAUnavailable); err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to save parent A availability")
 		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveAvailability, http.StatusSeeOther)
 		return
 	}
 
-	if err := h.configStore.SaveAvailability("parent_b", parentBUnavailable); err != nil {
+	if err := h.configStore.SaveAvailability(r.Context(), "parent_b", parentBUnavailable); err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to save parent B availability")
 		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveAvailability, http.StatusSeeOther)
 		return
 	}
 
+	if err := h.configStore.SaveUnavailabilityRules(r.Context(), "parent_a", parentARules); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save parent A unavailability rules")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveAvailability, http.StatusSeeOther)
+		return
+	}
+
+	if err := h.configStore.SaveUnavailabilityRules(r.Context(), "parent_b", parentBRules); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save parent B unavailability rules")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveAvailability, http.StatusSeeOther)
+		return
+	}
+
 	// Save schedule configuration
-	if err := h.configStore.SaveSchedule(updateFrequency, lookAheadDays, pastEventThresholdDays, statsOrder); err != nil {
+	if err := h.configStore.SaveSchedule(r.Context(), updateFrequency, lookAheadDays, pastEventThresholdDays, settings.StatsOrder); err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to save schedule configuration")
 		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveSchedule, http.StatusSeeOther)
 		return
 	}
 
+	if err := h.configStore.SaveLookAheadWindows(r.Context(), lookAheadWindows); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save look-ahead windows")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveSchedule, http.StatusSeeOther)
+		return
+	}
+
+	if err := h.configStore.SaveMinRestDays(r.Context(), minRestDays); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save rest days configuration")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveSchedule, http.StatusSeeOther)
+		return
+	}
+
+	if err := h.configStore.SaveDayWeights(r.Context(), dayWeights); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save day weights")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveSchedule, http.StatusSeeOther)
+		return
+	}
+
+	// Save which notification channels are enabled; unchecked boxes are not submitted
+	if len(h.notifyChannels) > 0 {
+		enabledChannels := make(map[string]bool, len(h.notifyChannels))
+		for _, channel := range h.notifyChannels {
+			enabledChannels[channel] = slices.Contains(r.Form["notify_channels"], channel)
+		}
+		if err := h.configStore.SaveNotifyChannels(r.Context(), enabledChannels); err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to save notification channel configuration")
+			http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveNotifications, http.StatusSeeOther)
+			return
+		}
+	}
+
+	// Save the bedtime checklist, one item per line
+	if err := h.configStore.SaveChecklistTemplate(r.Context(), strings.Split(r.FormValue("checklist"), "\n")); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save checklist template")
+		errCode := ErrCodeFailedSaveChecklist
+		if errors.Is(err, database.ErrInvalidChecklist) {
+			errCode = ErrCodeInvalidChecklist
+		}
+		http.Redirect(w, r, "/settings?error="+errCode, http.StatusSeeOther)
+		return
+	}
+
+	// Save the items added to the checklist on each weekday, one item per line
+	if err := h.configStore.SaveWeekdayChecklists(r.Context(), parseWeekdayChecklists(r.Form)); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save weekday checklists")
+		errCode := ErrCodeFailedSaveChecklist
+		if errors.Is(err, database.ErrInvalidChecklist) {
+			errCode = ErrCodeInvalidChecklist
+		}
+		http.Redirect(w, r, "/settings?error="+errCode, http.StatusSeeOther)
+		return
+	}
+
+	// Save whether the comments are written in the calendar events; an unchecked box is not submitted
+	if err := h.configStore.SaveCommentsInEvents(r.Context(), r.FormValue("comments_in_events") == "on"); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save comment configuration")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveComments, http.StatusSeeOther)
+		return
+	}
+
+	// Save the nights kept out of the calendar; unchecked boxes are not submitted
+	syncExclusions := config.SyncExclusions{Babysitter: r.FormValue("sync_exclude_babysitter") == "on"}
+	for _, tag := range fairness.AssignmentTags {
+		if slices.Contains(r.Form["sync_exclude_tags"], tag.String()) {
+			syncExclusions.Tags = append(syncExclusions.Tags, tag.String())
+		}
+	}
+	if err := h.configStore.SaveSyncExclusions(r.Context(), syncExclusions); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save sync exclusions")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveSyncExclusions, http.StatusSeeOther)
+		return
+	}
+
+	if err := h.configStore.SaveVacation(r.Context(), vacation); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save vacation configuration")
+		errCode := ErrCodeFailedSaveVacation
+		if errors.Is(err, database.ErrInvalidVacation) {
+			errCode = ErrCodeInvalidVacation
+		}
+		http.Redirect(w, r, "/settings?error="+errCode, http.StatusSeeOther)
+		return
+	}
+
+	if err := h.configStore.SaveSkipDates(r.Context(), skipDates); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save skip dates")
+		http.Redirect(w, r, "/settings?error="+ErrCodeFailedSaveSkipDates, http.StatusSeeOther)
+		return
+	}
+
 	handlerLogger.Info().Msg("Configuration updated successfully")
 
 	// Trigger automatic sync after settings update
@@ -248,6 +629,261 @@ func (h *SettingsHandler) handleUpdateSettings(w http.ResponseWriter, r *http.Re
 	http.Redirect(w, r, "/settings?success="+SuccessCodeSettingsUpdated, http.StatusSeeOther)
 }
 
+// SettingsResponse is the body of the settings API, read by GET and written by PUT
+type SettingsResponse struct {
+	ParentA string `json:"parent_a"`
+	ParentB string `json:"parent_b"`
+	// Parents taking turns after parent A and parent B; omitted on PUT, they are kept
+	ExtraParents           []string `json:"extra_parents"`
+	ParentAUnavailable     []string `json:"parent_a_unavailable"`
+	ParentBUnavailable     []string `json:"parent_b_unavailable"`
+	UpdateFrequency        string   `json:"update_frequency"`
+	LookAheadDays          int      `json:"look_ahead_days"`
+	PastEventThresholdDays int      `json:"past_event_threshold_days"`
+	StatsOrder             string   `json:"stats_order"`
+	MinRestDays            int      `json:"min_rest_days"`
+	// Weight of the nights in the fairness totals by weekday, e.g. {"Friday": 2}, days without
+	// weight counting 1; omitted on PUT, they are kept
+	DayWeights map[string]float64 `json:"day_weights"`
+	// Look-ahead days of the periodic, webhook and manual syncs, 0 for look_ahead_days
+	ScheduledLookAheadDays int `json:"scheduled_look_ahead_days"`
+	WebhookLookAheadDays   int `json:"webhook_look_ahead_days"`
+	ManualLookAheadDays    int `json:"manual_look_ahead_days"`
+}
+
+// SettingsUpdateResponse is the response of the settings API to PUT
+type SettingsUpdateResponse struct {
+	Settings SettingsResponse `json:"settings"`
+	Synced   bool             `json:"synced"` // Whether the schedule was synced with the new settings
+}
+
+// handleSettingsAPI returns the parents, availability, schedule, rest days and day weights on GET and replaces
+// them on PUT. PUT checks them with the same validation as the settings page, then syncs the
+// schedule like it.
+func (h *SettingsHandler) handleSettingsAPI(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleSettingsAPI").Str("method", r.Method).Logger()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		w.Header().Set("Allow", "GET, PUT")
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		handlerLogger.Warn().Msg("Unauthenticated access attempt to settings API")
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		settings, err := h.loadSettings()
+		if err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to load settings")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve settings", handlerLogger)
+			return
+		}
+		writeJSON(w, http.StatusOK, settings, handlerLogger)
+		return
+	}
+
+	var req SettingsResponse
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body", handlerLogger)
+		return
+	}
+	var extraNames []string
+	if req.ExtraParents != nil {
+		extraNames = make([]string, 0, len(req.ExtraParents))
+		for _, name := range req.ExtraParents {
+			extraNames = append(extraNames, strings.TrimSpace(name))
+		}
+	}
+	extraParents, err := h.extraParentsFor(extraNames)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get extra parents")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve settings", handlerLogger)
+		return
+	}
+	dayWeights, err := h.dayWeightsFor(req.DayWeights)
+	if err != nil {
+		if errors.Is(err, validation.ErrInvalidDayWeight) {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+			return
+		}
+		handlerLogger.Error().Err(err).Msg("Failed to get day weights")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve settings", handlerLogger)
+		return
+	}
+	settings := validation.Settings{
+		ParentA:                strings.TrimSpace(req.ParentA),
+		ParentB:                strings.TrimSpace(req.ParentB),
+		ExtraParents:           config.ExtraParentNames(extraParents),
+		ParentAUnavailable:     req.ParentAUnavailable,
+		ParentBUnavailable:     req.ParentBUnavailable,
+		UpdateFrequency:        req.UpdateFrequency,
+		LookAheadDays:          req.LookAheadDays,
+		PastEventThresholdDays: req.PastEventThresholdDays,
+		StatsOrder:             constants.StatsOrder(req.StatsOrder),
+		MinRestDays:            req.MinRestDays,
+		DayWeights:             dayWeights,
+		ScheduledLookAheadDays: req.ScheduledLookAheadDays,
+		WebhookLookAheadDays:   req.WebhookLookAheadDays,
+		ManualLookAheadDays:    req.ManualLookAheadDays,
+	}
+	if err := settings.Validate(); err != nil {
+		handlerLogger.Warn().Err(err).Msg("Invalid settings")
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+		return
+	}
+
+	if err := h.saveSettings(r.Context(), settings, extraParents); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to save settings")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to save settings", handlerLogger)
+		return
+	}
+	handlerLogger.Info().Msg("Configuration updated through the API")
+
+	response := SettingsUpdateResponse{Synced: true}
+	if err := h.triggerSync(r.Context(), handlerLogger); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to trigger automatic sync after settings update")
+		response.Synced = false
+	}
+	saved, err := h.loadSettings()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to load settings")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve settings", handlerLogger)
+		return
+	}
+	response.Settings = saved
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// loadSettings reads the settings of the settings API from the database
+func (h *SettingsHandler) loadSettings() (SettingsResponse, error) {
+	var settings SettingsResponse
+	var err error
+	if settings.ParentA, settings.ParentB, err = h.configStore.GetParents(); err != nil {
+		return settings, fmt.Errorf("failed to get parents: %w", err)
+	}
+	extraParents, err := h.configStore.GetExtraParents()
+	if err != nil {
+		return settings, fmt.Errorf("failed to get extra parents: %w", err)
+	}
+	settings.ExtraParents = config.ExtraParentNames(extraParents)
+	if settings.ParentAUnavailable, err = h.configStore.GetAvailability("parent_a"); err != nil {
+		return settings, fmt.Errorf("failed to get parent A availability: %w", err)
+	}
+	if settings.ParentBUnavailable, err = h.configStore.GetAvailability("parent_b"); err != nil {
+		return settings, fmt.Errorf("failed to get parent B availability: %w", err)
+	}
+	var statsOrder constants.StatsOrder
+	if settings.UpdateFrequency, settings.LookAheadDays, settings.PastEventThresholdDays, statsOrder, err = h.configStore.GetSchedule(); err != nil {
+		return settings, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	settings.StatsOrder = statsOrder.String()
+	if settings.MinRestDays, err = h.configStore.GetMinRestDays(); err != nil {
+		return settings, fmt.Errorf("failed to get rest days: %w", err)
+	}
+	dayWeights, err := h.configStore.GetDayWeights()
+	if err != nil {
+		return settings, fmt.Errorf("failed to get day weights: %w", err)
+	}
+	settings.DayWeights = make(map[string]float64, len(dayWeights))
+	for day, weight := range dayWeights {
+		settings.DayWeights[day.String()] = weight
+	}
+	windows, err := h.configStore.GetLookAheadWindows()
+	if err != nil {
+		return settings, fmt.Errorf("failed to get look-ahead windows: %w", err)
+	}
+	settings.ScheduledLookAheadDays, settings.WebhookLookAheadDays, settings.ManualLookAheadDays = windows.Scheduled, windows.Webhook, windows.Manual
+	if settings.ParentAUnavailable == nil {
+		settings.ParentAUnavailable = []string{}
+	}
+	if settings.ParentBUnavailable == nil {
+		settings.ParentBUnavailable = []string{}
+	}
+	return settings, nil
+}
+
+// saveSettings saves validated settings of the settings API
+func (h *SettingsHandler) saveSettings(ctx context.Context, settings validation.Settings, extraParents []config.ExtraParent) error {
+	if err := h.configStore.SaveParents(ctx, settings.ParentA, settings.ParentB); err != nil {
+		return fmt.Errorf("failed to save parents: %w", err)
+	}
+	if err := h.configStore.SaveExtraParents(ctx, extraParents); err != nil {
+		return fmt.Errorf("failed to save extra parents: %w", err)
+	}
+	if err := h.configStore.SaveAvailability(ctx, "parent_a", settings.ParentAUnavailable); err != nil {
+		return fmt.Errorf("failed to save parent A availability: %w", err)
+	}
+	if err := h.configStore.SaveAvailability(ctx, "parent_b", settings.ParentBUnavailable); err != nil {
+		return fmt.Errorf("failed to save parent B availability: %w", err)
+	}
+	if err := h.configStore.SaveSchedule(ctx, settings.UpdateFrequency, settings.LookAheadDays, settings.PastEventThresholdDays, settings.StatsOrder); err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+	windows := config.LookAheadWindows{Scheduled: settings.ScheduledLookAheadDays, Webhook: settings.WebhookLookAheadDays, Manual: settings.ManualLookAheadDays}
+	if err := h.configStore.SaveLookAheadWindows(ctx, windows); err != nil {
+		return fmt.Errorf("failed to save look-ahead windows: %w", err)
+	}
+	if err := h.configStore.SaveMinRestDays(ctx, settings.MinRestDays); err != nil {
+		return fmt.Errorf("failed to save rest days: %w", err)
+	}
+	if err := h.configStore.SaveDayWeights(ctx, settings.DayWeights); err != nil {
+		return fmt.Errorf("failed to save day weights: %w", err)
+	}
+	return nil
+}
+
+// dayWeightsFor returns the day weights of the settings API, keyed by weekday name. Nil weights
+// keep the day weights as they are.
+func (h *SettingsHandler) dayWeightsFor(named map[string]float64) (config.DayWeights, error) {
+	if named == nil {
+		return h.configStore.GetDayWeights()
+	}
+	weights := make(config.DayWeights, len(named))
+	for name, weight := range named {
+		index := slices.Index(constants.GetAllDaysOfWeek(), name)
+		if index < 0 {
+			return nil, fmt.Errorf("%w: unknown day %q", validation.ErrInvalidDayWeight, name)
+		}
+		weights[(time.Monday+time.Weekday(index))%7] = weight
+	}
+	return weights, nil
+}
+
+// extraParentsFor returns the extra parents of names, in order: a parent already in the roster
+// keeps its style, a new one gets the default style of its position. Nil names keep the extra
+// parents as they are.
+func (h *SettingsHandler) extraParentsFor(names []string) ([]config.ExtraParent, error) {
+	current, err := h.configStore.GetExtraParents()
+	if err != nil || names == nil {
+		return current, err
+	}
+	parents := make([]config.ExtraParent, 0, len(names))
+	for i, name := range names {
+		parent := config.ExtraParent{Name: name, Style: config.DefaultExtraParentStyle(i + 2)}
+		if index := slices.IndexFunc(current, func(extra config.ExtraParent) bool { return extra.Name == name }); index >= 0 {
+			parent.Style = current[index].Style
+		}
+		parents = append(parents, parent)
+	}
+	return parents, nil
+}
+
+// parseExtraParentNames returns the names of the extra parents of the settings page, one per line,
+// blank lines skipped
+func parseExtraParentNames(text string) []string {
+	names := []string{}
+	for line := range strings.Lines(text) {
+		if name := strings.TrimSpace(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // triggerSync triggers an automatic schedule sync
 func (h *SettingsHandler) triggerSync(ctx context.Context, logger zerolog.Logger) error {
 	logger.Info().Msg("Triggering automatic sync after settings update")
@@ -295,31 +931,240 @@ func (h *SettingsHandler) triggerSync(ctx context.Context, logger zerolog.Logger
 	}
 
 	// Generate and sync schedule
-	logger.Info().Msg("Generating schedule for automatic sync")
-	now := time.Now()
+	err = h.SyncRuns.RecordRun(ctx, constants.SyncTriggerSettings, func(ctx context.Context) (int, error) {
+		logger.Info().Msg("Generating schedule for automatic sync")
+		now := time.Now()
+
+		// Fetch lookAheadDays from database to use the latest settings
+		_, lookAheadDays, _, _, err := h.configStore.GetSchedule()
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to fetch lookAheadDays from database")
+			return 0, fmt.Errorf("failed to fetch lookAheadDays: %w", err)
+		}
+		end := now.AddDate(0, 0, lookAheadDays)
+
+		assignments, err := h.scheduler.GenerateSchedule(ctx, now, end, time.Now())
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to generate schedule")
+			return 0, fmt.Errorf("failed to generate schedule: %w", err)
+		}
 
-	// Fetch lookAheadDays from database to use the latest settings
-	_, lookAheadDays, _, _, err := h.configStore.GetSchedule()
+		logger.Info().Int("assignments", len(assignments)).Msg("Syncing schedule with calendar")
+		if err := h.calendarService.SyncSchedule(ctx, assignments); err != nil {
+			logger.Error().Err(err).Msg("Failed to sync schedule with calendar")
+			return len(assignments), fmt.Errorf("failed to sync calendar: %w", err)
+		}
+		return len(assignments), nil
+	})
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to fetch lookAheadDays from database")
-		return fmt.Errorf("failed to fetch lookAheadDays: %w", err)
+		return err
 	}
-	end := now.AddDate(0, 0, lookAheadDays)
 
-	assignments, err := h.scheduler.GenerateSchedule(now, end, time.Now())
+	logger.Info().Msg("Automatic sync completed successfully")
+	return nil
+}
+
+// getNotifyChannelSettings returns the configured notification channels with their state
+func (h *SettingsHandler) getNotifyChannelSettings() ([]NotifyChannelSetting, error) {
+	if len(h.notifyChannels) == 0 {
+		return nil, nil
+	}
+	enabled, err := h.configStore.GetNotifyChannels(h.notifyChannels)
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to generate schedule")
-		return fmt.Errorf("failed to generate schedule: %w", err)
+		return nil, err
+	}
+	settings := make([]NotifyChannelSetting, 0, len(h.notifyChannels))
+	for _, channel := range h.notifyChannels {
+		settings = append(settings, NotifyChannelSetting{Name: channel, Enabled: enabled[channel]})
 	}
+	return settings, nil
+}
 
-	logger.Info().Int("assignments", len(assignments)).Msg("Syncing schedule with calendar")
-	if err := h.calendarService.SyncSchedule(ctx, assignments); err != nil {
-		logger.Error().Err(err).Msg("Failed to sync schedule with calendar")
-		return fmt.Errorf("failed to sync calendar: %w", err)
+// parseVacation reads the vacation of the settings form. Both days are required when the vacation is
+// enabled; a disabled vacation accepts empty days.
+func parseVacation(enabled bool, startStr, endStr string) (config.Vacation, error) {
+	vacation := config.Vacation{Enabled: enabled}
+	if enabled && (startStr == "" || endStr == "") {
+		return vacation, errors.New("vacation enabled without first or last day")
+	}
+	var err error
+	if startStr != "" {
+		if vacation.Start, err = time.ParseInLocation("2006-01-02", startStr, time.Local); err != nil {
+			return vacation, fmt.Errorf("invalid vacation start %q: %w", startStr, err)
+		}
 	}
+	if endStr != "" {
+		if vacation.End, err = time.ParseInLocation("2006-01-02", endStr, time.Local); err != nil {
+			return vacation, fmt.Errorf("invalid vacation end %q: %w", endStr, err)
+		}
+	}
+	return vacation, nil
+}
 
-	logger.Info().Msg("Automatic sync completed successfully")
-	return nil
+// parseUnavailabilityRules parses the recurring unavailability rules of a parent, one per line;
+// blank lines are skipped
+func parseUnavailabilityRules(text string) ([]config.UnavailabilityRule, error) {
+	var rules []config.UnavailabilityRule
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		rule, err := config.ParseUnavailabilityRule(line)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// newUnavailabilityRulesSetting shows the unavailability rules of a parent on the settings page
+func newUnavailabilityRulesSetting(rules []config.UnavailabilityRule) UnavailabilityRulesSetting {
+	setting := UnavailabilityRulesSetting{}
+	lines := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		lines = append(lines, rule.String())
+		setting.Descriptions = append(setting.Descriptions, rule.Describe())
+	}
+	setting.Text = strings.Join(lines, "\n")
+	return setting
+}
+
+// parseSkipDates parses the days without night routine, one date or rule per line; blank lines are
+// skipped
+func parseSkipDates(text string) (config.SkipDates, error) {
+	var skipDates config.SkipDates
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		skipDate, err := config.ParseSkipDate(line)
+		if err != nil {
+			return nil, err
+		}
+		skipDates = append(skipDates, skipDate)
+	}
+	return skipDates, nil
+}
+
+// newSyncExcludeTagSettings lists every tag on the settings page, in the order of fairness.AssignmentTags
+func newSyncExcludeTagSettings(exclusions config.SyncExclusions) []SyncExcludeTagSetting {
+	settings := make([]SyncExcludeTagSetting, 0, len(fairness.AssignmentTags))
+	for _, tag := range fairness.AssignmentTags {
+		settings = append(settings, SyncExcludeTagSetting{
+			Value:    tag.String(),
+			Label:    tag.Label(),
+			Excluded: slices.Contains(exclusions.Tags, tag.String()),
+		})
+	}
+	return settings
+}
+
+// newSkipDatesSetting shows the days without night routine on the settings page
+func newSkipDatesSetting(skipDates config.SkipDates) SkipDatesSetting {
+	setting := SkipDatesSetting{}
+	lines := make([]string, 0, len(skipDates))
+	for _, skipDate := range skipDates {
+		lines = append(lines, skipDate.String())
+		setting.Descriptions = append(setting.Descriptions, skipDate.Describe())
+	}
+	setting.Text = strings.Join(lines, "\n")
+	return setting
+}
+
+// weekdayChecklistField is the name of the form field of the checklist items of weekday
+func weekdayChecklistField(weekday time.Weekday) string {
+	return "checklist_" + strings.ToLower(weekday.String())
+}
+
+// newWeekdayChecklistSettings shows the items added to the checklist on each weekday on the settings
+// page, from Monday to Sunday
+func newWeekdayChecklistSettings(checklists map[time.Weekday][]string) []WeekdayChecklistSetting {
+	settings := make([]WeekdayChecklistSetting, 0, 7)
+	for i := range 7 {
+		weekday := (time.Monday + time.Weekday(i)) % 7
+		settings = append(settings, WeekdayChecklistSetting{
+			Weekday: weekday.String(),
+			Field:   weekdayChecklistField(weekday),
+			Text:    strings.Join(checklists[weekday], "\n"),
+		})
+	}
+	return settings
+}
+
+// parseWeekdayChecklists reads the items added to the checklist on each weekday from the form, one
+// item per line
+func parseWeekdayChecklists(form url.Values) map[time.Weekday][]string {
+	checklists := make(map[time.Weekday][]string, 7)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if text := form.Get(weekdayChecklistField(weekday)); text != "" {
+			checklists[weekday] = strings.Split(text, "\n")
+		}
+	}
+	return checklists
+}
+
+// dayWeightField is the name of the form field of the weight of the nights of weekday
+func dayWeightField(weekday time.Weekday) string {
+	return "day_weight_" + strings.ToLower(weekday.String())
+}
+
+// newDayWeightSettings shows the weight of the nights of each weekday on the settings page, from
+// Monday to Sunday
+func newDayWeightSettings(weights config.DayWeights) []DayWeightSetting {
+	settings := make([]DayWeightSetting, 0, 7)
+	for i := range 7 {
+		weekday := (time.Monday + time.Weekday(i)) % 7
+		setting := DayWeightSetting{Weekday: weekday.String(), Field: dayWeightField(weekday)}
+		if weight := weights.Weight(weekday); weight != 1 {
+			setting.Weight = strconv.FormatFloat(weight, 'g', -1, 64)
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
+
+// parseDayWeights reads the weight of the nights of each weekday from the form; an empty field
+// counts them 1. The bounds are checked with the other settings.
+func parseDayWeights(form url.Values) (config.DayWeights, error) {
+	weights := make(config.DayWeights)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		value := strings.TrimSpace(form.Get(dayWeightField(weekday)))
+		if value == "" {
+			continue
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q for %s is not a number", validation.ErrInvalidDayWeight, value, weekday)
+		}
+		weights[weekday] = weight
+	}
+	return weights, nil
+}
+
+// parseParentStyle reads the color and the avatar of parent from the form. A form without the color
+// keeps current, as the setup wizard does not ask for it.
+func parseParentStyle(form url.Values, parent string, current config.ParentStyle) (config.ParentStyle, error) {
+	if !form.Has(parent + "_color") {
+		return current, nil
+	}
+	color, err := constants.ParseParentColor(form.Get(parent + "_color"))
+	if err != nil {
+		return config.ParentStyle{}, err
+	}
+	style := config.ParentStyle{Color: color, Avatar: strings.TrimSpace(form.Get(parent + "_avatar"))}
+	if err := style.Validate(); err != nil {
+		return config.ParentStyle{}, err
+	}
+	return style, nil
+}
+
+// formatVacationDate formats a vacation day for a date input, empty for a day never set
+func formatVacationDate(date time.Time) string {
+	if date.Year() <= 1 {
+		return ""
+	}
+	return date.Format("2006-01-02")
 }
 
 // getAllDaysOfWeek returns all days of the week for the UI
@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/database"
 	"github.com/belphemur/night-routine/internal/fairness"
@@ -38,13 +43,13 @@ func setupTestSettingsHandler(t *testing.T) (*SettingsHandler, *database.ConfigS
 	require.NoError(t, err)
 
 	// Seed initial data
-	err = configStore.SaveParents("TestParentA", "TestParentB")
+	err = configStore.SaveParents(t.Context(), "TestParentA", "TestParentB")
 	require.NoError(t, err)
-	err = configStore.SaveAvailability("parent_a", []string{"Monday"})
+	err = configStore.SaveAvailability(t.Context(), "parent_a", []string{"Monday"})
 	require.NoError(t, err)
-	err = configStore.SaveAvailability("parent_b", []string{"Friday"})
+	err = configStore.SaveAvailability(t.Context(), "parent_b", []string{"Friday"})
 	require.NoError(t, err)
-	err = configStore.SaveSchedule("weekly", 30, 5, constants.StatsOrderDesc)
+	err = configStore.SaveSchedule(t.Context(), "weekly", 30, 5, constants.StatsOrderDesc)
 	require.NoError(t, err)
 
 	// Create token store
@@ -74,11 +79,11 @@ func setupTestSettingsHandler(t *testing.T) (*SettingsHandler, *database.ConfigS
 	configAdapter := database.NewConfigAdapter(configStore, oauthCfg)
 
 	// Create base handler
-	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, "test-version", "test-logo-version")
+	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, nil)
 	require.NoError(t, err)
 
 	// Create settings handler (pass nil for optional sync dependencies in tests)
-	handler := NewSettingsHandler(baseHandler, configStore, nil, tokenManager, nil)
+	handler := NewSettingsHandler(baseHandler, configStore, nil, tokenManager, nil, nil)
 
 	cleanup := func() {
 		db.Close()
@@ -144,6 +149,8 @@ func TestSettingsHandler_HandleUpdateSettings_Success(t *testing.T) {
 	formData.Set("look_ahead_days", "14")
 	formData.Set("past_event_threshold_days", "3")
 	formData.Set("stats_order", "asc")
+	formData.Set("scheduled_look_ahead_days", "60")
+	formData.Set("webhook_look_ahead_days", "")
 
 	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -166,6 +173,50 @@ func TestSettingsHandler_HandleUpdateSettings_Success(t *testing.T) {
 	assert.Equal(t, 14, lookAhead)
 	assert.Equal(t, 3, threshold)
 	assert.Equal(t, constants.StatsOrderAsc, statsOrder)
+
+	windows, err := configStore.GetLookAheadWindows()
+	require.NoError(t, err)
+	assert.Equal(t, config.LookAheadWindows{Scheduled: 60}, windows, "an empty field uses the look ahead days")
+}
+
+func TestSettingsHandler_HandleUpdateSettings_ParentStyles(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	post := func(avatar string) *httptest.ResponseRecorder {
+		formData := url.Values{}
+		formData.Set("parent_a", "NewParentA")
+		formData.Set("parent_b", "NewParentB")
+		formData.Set("parent_a_color", "basil")
+		formData.Set("parent_a_avatar", avatar)
+		formData.Set("parent_b_color", "flamingo")
+		formData.Set("update_frequency", "daily")
+		formData.Set("look_ahead_days", "14")
+		formData.Set("past_event_threshold_days", "3")
+		formData.Set("stats_order", "asc")
+
+		req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.handleUpdateSettings(w, req)
+		return w
+	}
+
+	w := post(" 🦊 ")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), "/settings?success=")
+	styleA, styleB, err := configStore.GetParentStyles()
+	require.NoError(t, err)
+	assert.Equal(t, config.ParentStyle{Color: constants.ParentColorBasil, Avatar: "🦊"}, styleA)
+	assert.Equal(t, config.ParentStyle{Color: constants.ParentColorFlamingo}, styleB)
+
+	w = post("Antoinette")
+	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidParentStyle)
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	assert.Contains(t, w.Body.String(), `<option value="basil" selected>`)
+	assert.Contains(t, w.Body.String(), `value="🦊"`)
 }
 
 func TestSettingsHandler_HandleUpdateSettings_NotPost(t *testing.T) {
@@ -177,8 +228,8 @@ func TestSettingsHandler_HandleUpdateSettings_NotPost(t *testing.T) {
 
 	handler.handleUpdateSettings(w, req)
 
-	assert.Equal(t, http.StatusSeeOther, w.Code)
-	assert.Equal(t, "/settings", w.Header().Get("Location"))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
 }
 
 func TestSettingsHandler_HandleUpdateSettings_InvalidFormData(t *testing.T) {
@@ -237,7 +288,7 @@ func TestSettingsHandler_HandleUpdateSettings_InvalidThresholdDays(t *testing.T)
 	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidPastEventThreshold)
 }
 
-func TestSettingsHandler_HandleUpdateSettings_ParentsSaveFails(t *testing.T) {
+func TestSettingsHandler_HandleUpdateSettings_SameParents(t *testing.T) {
 	handler, _, _, cleanup := setupTestSettingsHandler(t)
 	defer cleanup()
 
@@ -256,10 +307,10 @@ func TestSettingsHandler_HandleUpdateSettings_ParentsSaveFails(t *testing.T) {
 	handler.handleUpdateSettings(w, req)
 
 	assert.Equal(t, http.StatusSeeOther, w.Code)
-	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeFailedSaveParent)
+	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidParents)
 }
 
-func TestSettingsHandler_HandleUpdateSettings_ScheduleSaveFails(t *testing.T) {
+func TestSettingsHandler_HandleUpdateSettings_InvalidUpdateFrequency(t *testing.T) {
 	handler, _, _, cleanup := setupTestSettingsHandler(t)
 	defer cleanup()
 
@@ -278,7 +329,7 @@ func TestSettingsHandler_HandleUpdateSettings_ScheduleSaveFails(t *testing.T) {
 	handler.handleUpdateSettings(w, req)
 
 	assert.Equal(t, http.StatusSeeOther, w.Code)
-	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeFailedSaveSchedule)
+	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidUpdateFrequency)
 }
 
 func TestSettingsHandler_GetAllDaysOfWeek(t *testing.T) {
@@ -320,10 +371,10 @@ func TestSettingsHandler_CheckAuthentication_NoToken(t *testing.T) {
 	// Create config adapter — single source of truth for all config reads
 	configAdapter := database.NewConfigAdapter(configStore, oauthCfg)
 
-	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, "test-version", "test-logo-version")
+	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, nil)
 	require.NoError(t, err)
 
-	handler := NewSettingsHandler(baseHandler, configStore, nil, tokenManager, nil)
+	handler := NewSettingsHandler(baseHandler, configStore, nil, tokenManager, nil, nil)
 
 	// Test unauthenticated access to settings
 	req := httptest.NewRequest(http.MethodGet, "/settings", nil)
@@ -358,9 +409,9 @@ func TestSettingsHandler_HandleUpdateSettings_Unauthenticated(t *testing.T) {
 	require.NoError(t, err)
 
 	// Seed initial data
-	err = configStore.SaveParents("OldA", "OldB")
+	err = configStore.SaveParents(t.Context(), "OldA", "OldB")
 	require.NoError(t, err)
-	err = configStore.SaveSchedule("weekly", 30, 5, constants.StatsOrderDesc)
+	err = configStore.SaveSchedule(t.Context(), "weekly", 30, 5, constants.StatsOrderDesc)
 	require.NoError(t, err)
 
 	tokenStore, err := database.NewTokenStore(db)
@@ -375,10 +426,10 @@ func TestSettingsHandler_HandleUpdateSettings_Unauthenticated(t *testing.T) {
 	// Create config adapter — single source of truth for all config reads
 	configAdapter := database.NewConfigAdapter(configStore, oauthCfg)
 
-	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, "test-version", "test-logo-version")
+	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, nil)
 	require.NoError(t, err)
 
-	handler := NewSettingsHandler(baseHandler, configStore, nil, tokenManager, nil)
+	handler := NewSettingsHandler(baseHandler, configStore, nil, tokenManager, nil, nil)
 
 	formData := url.Values{}
 	formData.Set("parent_a", "TestA")
@@ -468,3 +519,624 @@ func TestSettingsHandler_HandleUpdateSettings_ThresholdDaysOutOfBounds(t *testin
 	assert.Equal(t, http.StatusSeeOther, w.Code)
 	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidPastEventThreshold)
 }
+
+func TestSettingsHandler_NotifyChannels(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+	handler.notifyChannels = []string{"slack", "email"}
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Add("notify_channels", "email")
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), "/settings?success=")
+
+	channels, err := configStore.GetNotifyChannels(handler.notifyChannels)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"slack": false, "email": true}, channels)
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `value="slack"`)
+	assert.Contains(t, w.Body.String(), `value="email"`)
+}
+
+func TestSettingsHandler_NoNotifyChannels(t *testing.T) {
+	handler, _, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `name="notify_channels"`)
+}
+
+func TestSettingsHandler_Checklist(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Set("checklist", "Bath\r\n\r\nStory\n")
+	formData.Set("checklist_friday", "Bath night\r\nStory")
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), "/settings?success=")
+
+	labels, err := configStore.GetChecklistTemplate()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bath", "Story"}, labels)
+
+	weekdayChecklists, err := configStore.GetWeekdayChecklists()
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Weekday][]string{time.Friday: {"Bath night", "Story"}}, weekdayChecklists)
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Bath\nStory</textarea>")
+	assert.Contains(t, w.Body.String(), "Bath night\nStory</textarea>")
+}
+
+func TestSettingsHandler_InvalidChecklist(t *testing.T) {
+	handler, _, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Set("checklist", strings.Repeat("a", 81))
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidChecklist)
+}
+
+func TestSettingsHandler_CommentsInEvents(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Set("comments_in_events", "on")
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	enabled, err := configStore.GetCommentsInEvents()
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `name="comments_in_events"\s+checked`, w.Body.String())
+
+	// An unchecked box is not submitted and disables the comments
+	formData.Del("comments_in_events")
+	req = httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	enabled, err = configStore.GetCommentsInEvents()
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestSettingsHandler_SyncExclusions(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Set("sync_exclude_babysitter", "on")
+	formData["sync_exclude_tags"] = []string{"sick_kid", "unknown"}
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	exclusions, err := configStore.GetSyncExclusions()
+	require.NoError(t, err)
+	assert.Equal(t, config.SyncExclusions{Babysitter: true, Tags: []string{"sick_kid"}}, exclusions, "unknown tags are ignored")
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `name="sync_exclude_babysitter"\s+checked`, w.Body.String())
+	assert.Regexp(t, `value="sick_kid"\s+checked`, w.Body.String())
+	assert.NotRegexp(t, `value="parent_away"\s+checked`, w.Body.String())
+
+	// Unchecked boxes are not submitted and sync every night again
+	formData.Del("sync_exclude_babysitter")
+	formData.Del("sync_exclude_tags")
+	req = httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	exclusions, err = configStore.GetSyncExclusions()
+	require.NoError(t, err)
+	assert.True(t, exclusions.IsEmpty())
+}
+
+func TestSettingsHandler_Vacation(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Set("vacation_enabled", "on")
+	formData.Set("vacation_start", "2026-07-10")
+	formData.Set("vacation_end", "2026-07-20")
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.NotContains(t, w.Header().Get("Location"), "error=")
+	vacation, err := configStore.GetVacation()
+	require.NoError(t, err)
+	assert.True(t, vacation.Enabled)
+	assert.Equal(t, "2026-07-10", vacation.Start.Format("2006-01-02"))
+	assert.Equal(t, "2026-07-20", vacation.End.Format("2006-01-02"))
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `name="vacation_enabled"\s+checked`, w.Body.String())
+	assert.Contains(t, w.Body.String(), `value="2026-07-10"`)
+}
+
+func TestSettingsHandler_InvalidVacation(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+	}{
+		{name: "missing last day", start: "2026-07-10"},
+		{name: "ends before it starts", start: "2026-07-20", end: "2026-07-10"},
+		{name: "not a date", start: "10/07/2026", end: "2026-07-20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+			defer cleanup()
+
+			formData := url.Values{}
+			formData.Set("parent_a", "TestParentA")
+			formData.Set("parent_b", "TestParentB")
+			formData.Set("update_frequency", "weekly")
+			formData.Set("look_ahead_days", "30")
+			formData.Set("past_event_threshold_days", "5")
+			formData.Set("stats_order", "desc")
+			formData.Set("vacation_enabled", "on")
+			formData.Set("vacation_start", tt.start)
+			formData.Set("vacation_end", tt.end)
+
+			req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			handler.handleUpdateSettings(w, req)
+
+			require.Equal(t, http.StatusSeeOther, w.Code)
+			assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidVacation)
+			vacation, err := configStore.GetVacation()
+			require.NoError(t, err)
+			assert.False(t, vacation.Enabled)
+		})
+	}
+}
+
+func TestSettingsHandler_MinRestDays(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	update := func(minRestDays string) *httptest.ResponseRecorder {
+		formData := url.Values{}
+		formData.Set("parent_a", "TestParentA")
+		formData.Set("parent_b", "TestParentB")
+		formData.Set("update_frequency", "weekly")
+		formData.Set("look_ahead_days", "30")
+		formData.Set("past_event_threshold_days", "5")
+		formData.Set("stats_order", "desc")
+		formData.Set("min_rest_days", minRestDays)
+
+		req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.handleUpdateSettings(w, req)
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		return w
+	}
+
+	assert.NotContains(t, update("2").Header().Get("Location"), "error=")
+	days, err := configStore.GetMinRestDays()
+	require.NoError(t, err)
+	assert.Equal(t, 2, days)
+
+	w := httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `name="min_rest_days" value="2"`, w.Body.String())
+
+	for _, invalid := range []string{"-1", "8", "two"} {
+		assert.Contains(t, update(invalid).Header().Get("Location"), "error="+ErrCodeInvalidMinRestDays, invalid)
+	}
+	days, err = configStore.GetMinRestDays()
+	require.NoError(t, err)
+	assert.Equal(t, 2, days, "invalid values change nothing")
+
+	assert.NotContains(t, update("").Header().Get("Location"), "error=")
+	days, err = configStore.GetMinRestDays()
+	require.NoError(t, err)
+	assert.Zero(t, days, "an empty field enforces no rest")
+}
+
+func TestSettingsHandler_DayWeights(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	update := func(weights map[string]string) *httptest.ResponseRecorder {
+		formData := url.Values{}
+		formData.Set("parent_a", "TestParentA")
+		formData.Set("parent_b", "TestParentB")
+		formData.Set("update_frequency", "weekly")
+		formData.Set("look_ahead_days", "30")
+		formData.Set("past_event_threshold_days", "5")
+		formData.Set("stats_order", "desc")
+		for field, weight := range weights {
+			formData.Set(field, weight)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.handleUpdateSettings(w, req)
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		return w
+	}
+
+	assert.NotContains(t, update(map[string]string{"day_weight_friday": "2", "day_weight_saturday": "1.5", "day_weight_monday": ""}).Header().Get("Location"), "error=")
+	weights, err := configStore.GetDayWeights()
+	require.NoError(t, err)
+	assert.Equal(t, config.DayWeights{time.Friday: 2, time.Saturday: 1.5}, weights)
+
+	w := httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `name="day_weight_friday" value="2"`, w.Body.String())
+	assert.Regexp(t, `name="day_weight_saturday" value="1.5"`, w.Body.String())
+	assert.Regexp(t, `name="day_weight_monday" value=""`, w.Body.String())
+
+	for _, invalid := range []string{"0", "0.75", "6", "heavy"} {
+		assert.Contains(t, update(map[string]string{"day_weight_friday": invalid}).Header().Get("Location"), "error="+ErrCodeInvalidDayWeight, invalid)
+	}
+	weights, err = configStore.GetDayWeights()
+	require.NoError(t, err)
+	assert.Equal(t, config.DayWeights{time.Friday: 2, time.Saturday: 1.5}, weights, "invalid values change nothing")
+
+	assert.NotContains(t, update(nil).Header().Get("Location"), "error=")
+	weights, err = configStore.GetDayWeights()
+	require.NoError(t, err)
+	assert.Empty(t, weights, "empty fields count the nights once")
+}
+
+func TestSettingsHandler_UnavailabilityRules(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Set("parent_a_unavailability_rules", "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16\r\n\r\nfreq=monthly;byday=-1fr\r\n")
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.NotContains(t, w.Header().Get("Location"), "error=")
+	rules, err := configStore.GetUnavailabilityRules("parent_a")
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=2026-10-16", rules[0].String())
+	assert.Equal(t, "FREQ=MONTHLY;BYDAY=-1FR", rules[1].String())
+	rules, err = configStore.GetUnavailabilityRules("parent_b")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "FREQ=MONTHLY;BYDAY=-1FR")
+	assert.Contains(t, w.Body.String(), "Every 2 weeks on Friday from 2026-10-16")
+	assert.Contains(t, w.Body.String(), "The last Friday of every month")
+
+	formData.Set("parent_b_unavailability_rules", "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR")
+	req = httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidUnavailabilityRule)
+	rules, err = configStore.GetUnavailabilityRules("parent_a")
+	require.NoError(t, err)
+	assert.Len(t, rules, 2, "nothing saved on an invalid rule")
+}
+
+func TestSettingsHandler_SkipDates(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Set("skip_dates", "2026-10-24\r\n\r\nfreq=monthly;byday=2sa\r\n")
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.NotContains(t, w.Header().Get("Location"), "error=")
+	skipDates, err := configStore.GetSkipDates()
+	require.NoError(t, err)
+	require.Len(t, skipDates, 2)
+	assert.Equal(t, "2026-10-24", skipDates[0].String())
+	assert.Equal(t, "FREQ=MONTHLY;BYDAY=2SA", skipDates[1].String())
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Saturday 2026-10-24")
+	assert.Contains(t, w.Body.String(), "The second Saturday of every month")
+
+	formData.Set("skip_dates", "24/10/2026")
+	req = httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	handler.handleUpdateSettings(w, req)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidSkipDate)
+	skipDates, err = configStore.GetSkipDates()
+	require.NoError(t, err)
+	assert.Len(t, skipDates, 2, "nothing saved on an invalid entry")
+}
+
+func TestSettingsHandler_ExtraParents(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	formData := url.Values{}
+	formData.Set("parent_a", "TestParentA")
+	formData.Set("parent_b", "TestParentB")
+	formData.Set("update_frequency", "weekly")
+	formData.Set("look_ahead_days", "30")
+	formData.Set("past_event_threshold_days", "5")
+	formData.Set("stats_order", "desc")
+	formData.Set("extra_parents", " Grandma\r\n\r\nGrandpa\r\n")
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/settings/update", strings.NewReader(formData.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.handleUpdateSettings(w, req)
+		return w
+	}
+
+	w := post()
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.NotContains(t, w.Header().Get("Location"), "error=")
+	extra, err := configStore.GetExtraParents()
+	require.NoError(t, err)
+	require.Len(t, extra, 2)
+	assert.Equal(t, "Grandma", extra[0].Name)
+	assert.Equal(t, config.DefaultExtraParentStyle(2), extra[0].Style)
+	assert.Equal(t, "Grandpa", extra[1].Name)
+	assert.Equal(t, config.DefaultExtraParentStyle(3), extra[1].Style)
+
+	w = httptest.NewRecorder()
+	handler.handleSettings(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Grandma\nGrandpa</textarea>")
+
+	// A parent staying in the roster keeps its style, even moved to another position
+	extra[1].Style = config.ParentStyle{Color: constants.GetAllParentColors()[0], Avatar: "🧓"}
+	require.NoError(t, configStore.SaveExtraParents(t.Context(), extra))
+	formData.Set("extra_parents", "Grandpa")
+	require.Equal(t, http.StatusSeeOther, post().Code)
+	extra, err = configStore.GetExtraParents()
+	require.NoError(t, err)
+	require.Len(t, extra, 1)
+	assert.Equal(t, "Grandpa", extra[0].Name)
+	assert.Equal(t, "🧓", extra[0].Style.Avatar)
+
+	formData.Set("extra_parents", "TestParentA")
+	w = post()
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), "error="+ErrCodeInvalidParents)
+	extra, err = configStore.GetExtraParents()
+	require.NoError(t, err)
+	assert.Len(t, extra, 1, "nothing saved on a duplicate name")
+}
+
+func TestSettingsHandler_SettingsAPI(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	handler.handleSettingsAPI(w, httptest.NewRequest(http.MethodGet, "/api/v1/settings", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var settings SettingsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &settings))
+	assert.Equal(t, SettingsResponse{
+		ParentA:                "TestParentA",
+		ParentB:                "TestParentB",
+		ExtraParents:           []string{},
+		ParentAUnavailable:     []string{"Monday"},
+		ParentBUnavailable:     []string{"Friday"},
+		UpdateFrequency:        "weekly",
+		LookAheadDays:          30,
+		PastEventThresholdDays: 5,
+		StatsOrder:             "desc",
+		MinRestDays:            0,
+		DayWeights:             map[string]float64{},
+	}, settings)
+
+	settings.ParentB = " Charlie "
+	settings.ParentAUnavailable = []string{}
+	settings.LookAheadDays = 60
+	settings.StatsOrder = "asc"
+	settings.MinRestDays = 1
+	settings.WebhookLookAheadDays = 14
+	settings.ExtraParents = []string{" Grandma "}
+	settings.DayWeights = map[string]float64{"Friday": 2}
+	body, err := json.Marshal(settings)
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	handler.handleSettingsAPI(w, httptest.NewRequest(http.MethodPut, "/api/v1/settings", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated SettingsUpdateResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "Charlie", updated.Settings.ParentB)
+	assert.Equal(t, []string{"Grandma"}, updated.Settings.ExtraParents)
+	assert.Empty(t, updated.Settings.ParentAUnavailable)
+	assert.Equal(t, 60, updated.Settings.LookAheadDays)
+	assert.Equal(t, "asc", updated.Settings.StatsOrder)
+	assert.Equal(t, 14, updated.Settings.WebhookLookAheadDays)
+	assert.Equal(t, map[string]float64{"Friday": 2}, updated.Settings.DayWeights)
+	assert.Zero(t, updated.Settings.ScheduledLookAheadDays, "the periodic sync uses the look ahead days")
+	assert.False(t, updated.Synced, "no calendar is selected to sync")
+
+	days, err := configStore.GetMinRestDays()
+	require.NoError(t, err)
+	assert.Equal(t, 1, days)
+
+	// Without the extra parents and the day weights, the update keeps them
+	body = []byte(`{"parent_a":"TestParentA","parent_b":"Charlie","update_frequency":"weekly","look_ahead_days":60,"past_event_threshold_days":5,"stats_order":"asc"}`)
+	w = httptest.NewRecorder()
+	handler.handleSettingsAPI(w, httptest.NewRequest(http.MethodPut, "/api/v1/settings", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	extra, err := configStore.GetExtraParents()
+	require.NoError(t, err)
+	require.Len(t, extra, 1)
+	assert.Equal(t, "Grandma", extra[0].Name)
+	weights, err := configStore.GetDayWeights()
+	require.NoError(t, err)
+	assert.Equal(t, config.DayWeights{time.Friday: 2}, weights)
+}
+
+func TestSettingsHandler_SettingsAPIValidation(t *testing.T) {
+	handler, configStore, _, cleanup := setupTestSettingsHandler(t)
+	defer cleanup()
+
+	valid := `"parent_a":"A","parent_b":"B","update_frequency":"daily","look_ahead_days":7,"past_event_threshold_days":5,"stats_order":"desc"`
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"Same parents", `{"parent_a":"A","parent_b":"A","update_frequency":"daily","look_ahead_days":7,"stats_order":"desc"}`, "parent names must be different"},
+		{"Invalid day", `{` + valid + `,"parent_b_unavailable":["Mon"]}`, "invalid day of week: Mon"},
+		{"Invalid frequency", `{"parent_a":"A","parent_b":"B","update_frequency":"hourly","look_ahead_days":7,"stats_order":"desc"}`, "invalid update frequency: hourly"},
+		{"Look ahead out of bounds", `{"parent_a":"A","parent_b":"B","update_frequency":"daily","look_ahead_days":400,"stats_order":"desc"}`, "invalid look ahead days"},
+		{"Rest days out of bounds", `{` + valid + `,"min_rest_days":8}`, "invalid minimum rest days"},
+		{"Day weight out of bounds", `{` + valid + `,"day_weights":{"Friday":7}}`, "invalid day weight: 7 for Friday"},
+		{"Day weight of an unknown day", `{` + valid + `,"day_weights":{"Fri":2}}`, `invalid day weight: unknown day "Fri"`},
+		{"Manual look ahead out of bounds", `{` + valid + `,"manual_look_ahead_days":-3}`, "invalid look ahead days: -3 is neither 0 nor between 1 and 365"},
+		{"Extra parent named like a parent", `{` + valid + `,"extra_parents":["B"]}`, "parent names must be different"},
+		{"Too many parents", `{` + valid + `,"extra_parents":["C","D","E","F","G"]}`, "too many parents: 7, at most 6"},
+		{"Not JSON", `parent_a=A`, "Invalid request body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleSettingsAPI(w, httptest.NewRequest(http.MethodPut, "/api/v1/settings", strings.NewReader(tt.body)))
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var response apierror.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, apierror.CodeInvalidRequest, response.Code)
+			assert.Contains(t, response.Message, tt.message)
+		})
+	}
+
+	parentA, parentB, err := configStore.GetParents()
+	require.NoError(t, err)
+	assert.Equal(t, "TestParentA", parentA, "rejected settings change nothing")
+	assert.Equal(t, "TestParentB", parentB)
+
+	w := httptest.NewRecorder()
+	handler.handleSettingsAPI(w, httptest.NewRequest(http.MethodPost, "/api/v1/settings", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, PUT", w.Header().Get("Allow"))
+}
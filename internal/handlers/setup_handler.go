@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/validation"
+	"github.com/rs/zerolog"
+)
+
+// Steps of the first-run setup wizard, in order. The first three are filled in the wizard and saved
+// together; the last two send the user to the Google authentication and the calendar selection.
+const (
+	SetupStepParents      = "parents"
+	SetupStepAvailability = "availability"
+	SetupStepSchedule     = "schedule"
+	SetupStepConnect      = "connect"
+	SetupStepCalendar     = "calendar"
+)
+
+// setupStep is a step of the setup wizard
+type setupStep struct {
+	name  string
+	title string
+}
+
+// setupSteps lists the steps of the setup wizard, in order
+var setupSteps = []setupStep{
+	{SetupStepParents, "Parents"},
+	{SetupStepAvailability, "Availability"},
+	{SetupStepSchedule, "Schedule"},
+	{SetupStepConnect, "Google Calendar"},
+	{SetupStepCalendar, "Calendar"},
+}
+
+// setupGatedPages are the pages sent to the setup wizard until the database is configured
+var setupGatedPages = []string{"/", "/settings", "/statistics"}
+
+// SetupHandler guides the first run through the configuration: parents, availability, schedule,
+// Google authentication and calendar selection. It replaces seeding the database from the parents
+// of the configuration file, which become optional.
+type SetupHandler struct {
+	*BaseHandler
+	configStore database.ConfigStoreInterface
+	// configured is set once the database holds a configuration, which is never removed
+	configured atomic.Bool
+}
+
+// NewSetupHandler creates a new setup wizard handler
+func NewSetupHandler(baseHandler *BaseHandler, configStore database.ConfigStoreInterface) *SetupHandler {
+	return &SetupHandler{
+		BaseHandler: baseHandler,
+		configStore: configStore,
+	}
+}
+
+// RegisterRoutes registers setup wizard related routes
+func (h *SetupHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/setup", h.handleSetup, http.MethodGet, http.MethodPost)
+}
+
+// SetupStepView is a step of the progress bar of the setup wizard
+type SetupStepView struct {
+	Number  int
+	Title   string
+	Done    bool
+	Current bool
+}
+
+// SetupDayView is a day of the week with the parents unavailable on it
+type SetupDayView struct {
+	Name               string
+	ParentAUnavailable bool
+	ParentBUnavailable bool
+}
+
+// SetupPageData contains data for the setup wizard template. The values of the steps already filled
+// are carried over as hidden fields until they are saved with the schedule.
+type SetupPageData struct {
+	BasePageData
+	Step                   string
+	Steps                  []SetupStepView
+	ErrorMessage           string
+	ParentA                string
+	ParentB                string
+	ParentAUnavailable     []string
+	ParentBUnavailable     []string
+	Days                   []SetupDayView
+	UpdateFrequency        string
+	LookAheadDays          int
+	PastEventThresholdDays int
+	StatsOrder             constants.StatsOrder
+}
+
+// RequireSetup sends the requests of the pages to the setup wizard while the database holds no
+// configuration; the other routes are served as usual.
+func (h *SetupHandler) RequireSetup(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && slices.Contains(setupGatedPages, r.URL.Path) && !h.isConfigured(h.logger) {
+			http.Redirect(w, r, "/setup", http.StatusSeeOther)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isConfigured reports whether the database holds a configuration. A failing check is logged and
+// reported as configured, so that the application stays reachable.
+func (h *SetupHandler) isConfigured(logger zerolog.Logger) bool {
+	if h.configured.Load() {
+		return true
+	}
+	configured, err := h.configStore.HasConfiguration()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to check configuration existence")
+		return true
+	}
+	if configured {
+		h.configured.Store(true)
+	}
+	return configured
+}
+
+// handleSetup shows the current step of the setup wizard, or processes the submitted one
+func (h *SetupHandler) handleSetup(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleSetup").Logger()
+	handlerLogger.Info().Str("method", r.Method).Msg("Handling setup request")
+
+	if !h.isConfigured(handlerLogger) {
+		if r.Method == http.MethodPost {
+			h.handleSetupForm(w, r, handlerLogger)
+			return
+		}
+		h.renderSetup(w, r, SetupPageData{
+			Step:                   SetupStepParents,
+			UpdateFrequency:        constants.DefaultUpdateFrequency,
+			LookAheadDays:          constants.DefaultLookAheadDays,
+			PastEventThresholdDays: constants.DefaultPastEventThresholdDays,
+			StatsOrder:             constants.StatsOrderDesc,
+		})
+		return
+	}
+
+	// The configuration is saved, the wizard only guides through the Google connection
+	if r.Method != http.MethodGet {
+		http.Redirect(w, r, "/setup", http.StatusSeeOther)
+		return
+	}
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		h.renderSetup(w, r, SetupPageData{Step: SetupStepConnect})
+		return
+	}
+	calendarID, err := h.TokenStore.GetSelectedCalendar()
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get selected calendar")
+	}
+	if calendarID == "" {
+		h.renderSetup(w, r, SetupPageData{Step: SetupStepCalendar})
+		return
+	}
+	handlerLogger.Debug().Msg("Setup complete, redirecting to home page")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// handleSetupForm validates the submitted step and shows the next one, or the previous one when the
+// user goes back. The schedule step saves the whole configuration.
+func (h *SetupHandler) handleSetupForm(w http.ResponseWriter, r *http.Request, logger zerolog.Logger) {
+	if err := r.ParseForm(); err != nil {
+		logger.Error().Err(err).Msg("Failed to parse form")
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	data, errCodes := parseSetupForm(r)
+	current := max(setupStepIndex(r.FormValue("step")), 0)
+	logger = logger.With().Str("step", setupSteps[current].name).Logger()
+
+	if r.FormValue("back") != "" {
+		data.Step = setupSteps[max(current-1, 0)].name
+		h.renderSetup(w, r, data)
+		return
+	}
+
+	// The first invalid step up to the submitted one is shown again with its error
+	for _, step := range setupSteps[:current+1] {
+		if errCode, ok := errCodes[step.name]; ok {
+			logger.Warn().Str("error_code", errCode).Str("invalid_step", step.name).Msg("Invalid setup step")
+			data.Step = step.name
+			data.ErrorMessage = GetErrorMessage(errCode)
+			h.renderSetup(w, r, data)
+			return
+		}
+	}
+
+	if setupSteps[current].name != SetupStepSchedule {
+		data.Step = setupSteps[current+1].name
+		h.renderSetup(w, r, data)
+		return
+	}
+
+	if err := h.saveSetup(r.Context(), data); err != nil {
+		logger.Error().Err(err).Msg("Failed to save setup configuration")
+		data.Step = SetupStepSchedule
+		data.ErrorMessage = GetErrorMessage(ErrCodeFailedSaveSetup)
+		h.renderSetup(w, r, data)
+		return
+	}
+	h.configured.Store(true)
+	logger.Info().Str("parent_a", data.ParentA).Str("parent_b", data.ParentB).Msg("Setup configuration saved")
+	http.Redirect(w, r, "/setup", http.StatusSeeOther)
+}
+
+// setupStepIndex returns the position of step in setupSteps, -1 for an unknown step
+func setupStepIndex(step string) int {
+	return slices.IndexFunc(setupSteps, func(s setupStep) bool { return s.name == step })
+}
+
+// parseSetupForm reads every field of the wizard, with the error code of the first invalid field of
+// each step. The schedule fields left empty keep their defaults.
+func parseSetupForm(r *http.Request) (SetupPageData, map[string]string) {
+	data := SetupPageData{
+		ParentA:                strings.TrimSpace(r.FormValue("parent_a")),
+		ParentB:                strings.TrimSpace(r.FormValue("parent_b")),
+		ParentAUnavailable:     r.Form["parent_a_unavailable"],
+		ParentBUnavailable:     r.Form["parent_b_unavailable"],
+		UpdateFrequency:        constants.DefaultUpdateFrequency,
+		LookAheadDays:          constants.DefaultLookAheadDays,
+		PastEventThresholdDays: constants.DefaultPastEventThresholdDays,
+		StatsOrder:             constants.StatsOrderDesc,
+	}
+	errCodes := make(map[string]string)
+	fail := func(step, errCode string) {
+		if _, ok := errCodes[step]; !ok {
+			errCodes[step] = errCode
+		}
+	}
+
+	if validation.Parents(data.ParentA, data.ParentB) != nil {
+		fail(SetupStepParents, ErrCodeInvalidParents)
+	}
+	if validation.DaysOfWeek(slices.Concat(data.ParentAUnavailable, data.ParentBUnavailable)) != nil {
+		fail(SetupStepAvailability, ErrCodeInvalidDayOfWeek)
+	}
+
+	if value := r.FormValue("update_frequency"); value != "" {
+		data.UpdateFrequency = value
+		if validation.UpdateFrequency(value) != nil {
+			fail(SetupStepSchedule, ErrCodeInvalidUpdateFrequency)
+		}
+	}
+	if value := r.FormValue("look_ahead_days"); value != "" {
+		lookAheadDays, err := strconv.Atoi(value)
+		if err != nil || validation.LookAheadDays(lookAheadDays) != nil {
+			fail(SetupStepSchedule, ErrCodeInvalidLookAheadDays)
+		} else {
+			data.LookAheadDays = lookAheadDays
+		}
+	}
+	if value := r.FormValue("past_event_threshold_days"); value != "" {
+		pastEventThresholdDays, err := strconv.Atoi(value)
+		if err != nil || validation.PastEventThresholdDays(pastEventThresholdDays) != nil {
+			fail(SetupStepSchedule, ErrCodeInvalidPastEventThreshold)
+		} else {
+			data.PastEventThresholdDays = pastEventThresholdDays
+		}
+	}
+	if value := r.FormValue("stats_order"); value != "" {
+		if statsOrder := constants.StatsOrder(value); validation.StatsOrder(statsOrder) != nil {
+			fail(SetupStepSchedule, ErrCodeInvalidStatsOrder)
+		} else {
+			data.StatsOrder = statsOrder
+		}
+	}
+	return data, errCodes
+}
+
+// saveSetup saves the configuration of the wizard. The parents are saved last, as they mark the
+// database as configured.
+func (h *SetupHandler) saveSetup(ctx context.Context, data SetupPageData) error {
+	if err := h.configStore.SaveAvailability(ctx, "parent_a", data.ParentAUnavailable); err != nil {
+		return fmt.Errorf("failed to save parent A availability: %w", err)
+	}
+	if err := h.configStore.SaveAvailability(ctx, "parent_b", data.ParentBUnavailable); err != nil {
+		return fmt.Errorf("failed to save parent B availability: %w", err)
+	}
+	if err := h.configStore.SaveSchedule(ctx, data.UpdateFrequency, data.LookAheadDays, data.PastEventThresholdDays, data.StatsOrder); err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+	if err := h.configStore.SaveParents(ctx, data.ParentA, data.ParentB); err != nil {
+		return fmt.Errorf("failed to save parents: %w", err)
+	}
+	return nil
+}
+
+// renderSetup renders the step of data with the progress of the wizard
+func (h *SetupHandler) renderSetup(w http.ResponseWriter, r *http.Request, data SetupPageData) {
+	data.BasePageData = h.NewBasePageData(r, data.Step == SetupStepCalendar)
+	current := setupStepIndex(data.Step)
+	for i, step := range setupSteps {
+		data.Steps = append(data.Steps, SetupStepView{Number: i + 1, Title: step.title, Done: i < current, Current: i == current})
+	}
+	for _, day := range constants.GetAllDaysOfWeek() {
+		data.Days = append(data.Days, SetupDayView{
+			Name:               day,
+			ParentAUnavailable: slices.Contains(data.ParentAUnavailable, day),
+			ParentBUnavailable: slices.Contains(data.ParentBUnavailable, day),
+		})
+	}
+	h.RenderTemplate(w, "setup.html", data)
+}
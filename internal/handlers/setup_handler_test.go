@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestSetupHandler(t *testing.T) (*SetupHandler, *database.ConfigStore, *database.TokenStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "setup.db"),
+		Mode:        "rwc",
+		Cache:       database.CachePrivate,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	tokenManager := token.NewTokenManager(tokenStore, oauthCfg)
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(configStore, oauthCfg), tokenStore, tokenManager, tracker, nil)
+	require.NoError(t, err)
+
+	return NewSetupHandler(baseHandler, configStore), configStore, tokenStore
+}
+
+// postSetup submits the form values of a step of the wizard
+func postSetup(handler *SetupHandler, values url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/setup", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.handleSetup(w, req)
+	return w
+}
+
+func TestSetupHandler_RequireSetup(t *testing.T) {
+	handler, configStore, _ := setupTestSetupHandler(t)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	gated := handler.RequireSetup(next)
+
+	for _, path := range []string{"/", "/settings", "/statistics"} {
+		w := httptest.NewRecorder()
+		gated.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusSeeOther, w.Code, path)
+		assert.Equal(t, "/setup", w.Header().Get("Location"), path)
+	}
+	for _, path := range []string{"/setup", "/auth", "/health"} {
+		w := httptest.NewRecorder()
+		gated.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+
+	require.NoError(t, configStore.SaveParents(t.Context(), "Alice", "Bob"))
+	w := httptest.NewRecorder()
+	gated.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "configured database serves the pages")
+}
+
+func TestSetupHandler_Steps(t *testing.T) {
+	handler, configStore, _ := setupTestSetupHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.handleSetup(w, httptest.NewRequest(http.MethodGet, "/setup", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `name="parent_a"`)
+	assert.Contains(t, w.Body.String(), `value="parents"`)
+
+	// Invalid parents stay on the first step
+	w = postSetup(handler, url.Values{"step": {SetupStepParents}, "parent_a": {"Alice"}, "parent_b": {"Alice"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), GetErrorMessage(ErrCodeInvalidParents))
+	assert.Contains(t, w.Body.String(), `value="parents"`)
+
+	values := url.Values{
+		"step":                      {SetupStepParents},
+		"parent_a":                  {"Alice"},
+		"parent_b":                  {"Bob"},
+		"update_frequency":          {constants.DefaultUpdateFrequency},
+		"look_ahead_days":           {"30"},
+		"past_event_threshold_days": {"5"},
+		"stats_order":               {"desc"},
+	}
+	w = postSetup(handler, values)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `value="availability"`)
+	assert.Contains(t, w.Body.String(), "Alice - Unavailable Days")
+	assert.Contains(t, w.Body.String(), `<input type="hidden" name="parent_a" value="Alice">`)
+
+	values.Set("step", SetupStepAvailability)
+	values["parent_a_unavailable"] = []string{"Wednesday"}
+	values["parent_b_unavailable"] = []string{"Someday"}
+	w = postSetup(handler, values)
+	assert.Contains(t, w.Body.String(), GetErrorMessage(ErrCodeInvalidDayOfWeek))
+
+	values["parent_b_unavailable"] = []string{"Friday"}
+	w = postSetup(handler, values)
+	assert.Contains(t, w.Body.String(), `value="schedule"`)
+	assert.Contains(t, w.Body.String(), `<input type="hidden" name="parent_a_unavailable" value="Wednesday">`)
+
+	// Going back keeps the values filled in
+	values.Set("step", SetupStepSchedule)
+	values.Set("back", "1")
+	w = postSetup(handler, values)
+	assert.Contains(t, w.Body.String(), `value="availability"`)
+	assert.Regexp(t, `value="Wednesday" checked`, w.Body.String())
+	values.Del("back")
+
+	values.Set("look_ahead_days", "0")
+	w = postSetup(handler, values)
+	assert.Contains(t, w.Body.String(), GetErrorMessage(ErrCodeInvalidLookAheadDays))
+	configured, err := configStore.HasConfiguration()
+	require.NoError(t, err)
+	assert.False(t, configured, "nothing saved before the last step is valid")
+
+	values.Set("look_ahead_days", "14")
+	w = postSetup(handler, values)
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/setup", w.Header().Get("Location"))
+
+	parentA, parentB, err := configStore.GetParents()
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", parentA)
+	assert.Equal(t, "Bob", parentB)
+	unavailable, err := configStore.GetAvailability("parent_a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Wednesday"}, unavailable)
+	unavailable, err = configStore.GetAvailability("parent_b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Friday"}, unavailable)
+	_, lookAheadDays, _, _, err := configStore.GetSchedule()
+	require.NoError(t, err)
+	assert.Equal(t, 14, lookAheadDays)
+}
+
+func TestSetupHandler_ConnectAndCalendar(t *testing.T) {
+	handler, configStore, tokenStore := setupTestSetupHandler(t)
+	require.NoError(t, configStore.SaveParents(t.Context(), "Alice", "Bob"))
+
+	// The saved configuration cannot be submitted again
+	w := postSetup(handler, url.Values{"step": {SetupStepSchedule}, "parent_a": {"Eve"}, "parent_b": {"Mallory"}})
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	parentA, _, err := configStore.GetParents()
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", parentA)
+
+	w = httptest.NewRecorder()
+	handler.handleSetup(w, httptest.NewRequest(http.MethodGet, "/setup", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `href="/auth"`)
+
+	require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	w = httptest.NewRecorder()
+	handler.handleSetup(w, httptest.NewRequest(http.MethodGet, "/setup", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `href="/calendars"`)
+
+	require.NoError(t, tokenStore.SaveSelectedCalendar("family@group.calendar.google.com"))
+	w = httptest.NewRecorder()
+	handler.handleSetup(w, httptest.NewRequest(http.MethodGet, "/setup", nil))
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/", w.Header().Get("Location"))
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// staticAsset is an embedded file served under /static/
+type staticAsset struct {
+	content []byte
+	etag    string // Quoted SHA-256 of the content
+}
+
+// version returns a short hash of the content for cache-busting URLs
+func (a *staticAsset) version() string {
+	return strings.Trim(a.etag, `"`)[:16]
+}
+
+// staticAssetNames lists the embedded assets, relative to assets/
+var staticAssetNames = []string{"css/tailwind.css", "images/favicon.png", "images/logo.png"}
+
+// loadStaticAssets reads and hashes the embedded assets on first use. The result is shared
+// by the static handler serving them and the templates linking to them.
+var loadStaticAssets = sync.OnceValues(func() (map[string]*staticAsset, error) {
+	assets := make(map[string]*staticAsset, len(staticAssetNames))
+	for _, name := range staticAssetNames {
+		content, err := assetsFS.ReadFile("assets/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s: %w", name, err)
+		}
+		hash := sha256.Sum256(content)
+		assets[name] = &staticAsset{
+			content: content,
+			etag:    fmt.Sprintf("\"%s\"", hex.EncodeToString(hash[:])),
+		}
+	}
+	return assets, nil
+})
+
+// assetURL returns the URL of an embedded asset with its content hash, so browsers fetch it
+// again as soon as it changes. It is available to the templates as the asset function.
+func assetURL(name string) (string, error) {
+	assets, err := loadStaticAssets()
+	if err != nil {
+		return "", err
+	}
+	asset, ok := assets[name]
+	if !ok {
+		return "", fmt.Errorf("unknown asset %s", name)
+	}
+	return "/static/" + name + "?v=" + asset.version(), nil
+}
@@ -1,10 +1,7 @@
 package handlers
 
 import (
-	"crypto/sha256"
 	"embed"
-	"encoding/hex"
-	"fmt"
 	"net/http"
 	"slices"
 	"strings"
@@ -31,59 +28,32 @@ type StaticHandler struct {
 func NewStaticHandler() (*StaticHandler, error) {
 	logger := logging.GetLogger("static-handler")
 
-	// Pre-load and cache CSS file with ETag
-	css, err := assetsFS.ReadFile("assets/css/tailwind.css")
+	// The assets are read and hashed once, then shared with the templates linking to them
+	assets, err := loadStaticAssets()
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to read Tailwind CSS for ETag calculation")
-		return nil, fmt.Errorf("failed to read CSS file: %w", err)
+		logger.Error().Err(err).Msg("Failed to load static assets")
+		return nil, err
 	}
-
-	// Calculate SHA-256 hash for CSS ETag
-	cssHash := sha256.Sum256(css)
-	cssETag := fmt.Sprintf("\"%s\"", hex.EncodeToString(cssHash[:]))
-	logger.Debug().Str("etag", cssETag).Int("content_size", len(css)).Msg("Cached CSS file with ETag")
-
-	// Pre-load and cache Favicon with ETag
-	favicon, err := assetsFS.ReadFile("assets/images/favicon.png")
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to read Favicon for ETag calculation")
-		return nil, fmt.Errorf("failed to read Favicon file: %w", err)
-	}
-
-	// Calculate SHA-256 hash for Favicon ETag
-	faviconHash := sha256.Sum256(favicon)
-	faviconETag := fmt.Sprintf("\"%s\"", hex.EncodeToString(faviconHash[:]))
-	logger.Debug().Str("etag", faviconETag).Int("content_size", len(favicon)).Msg("Cached Favicon file with ETag")
-
-	// Pre-load and cache Logo with ETag
-	logo, err := assetsFS.ReadFile("assets/images/logo.png")
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to read Logo for ETag calculation")
-		return nil, fmt.Errorf("failed to read Logo file: %w", err)
-	}
-
-	// Calculate SHA-256 hash for Logo ETag
-	logoHash := sha256.Sum256(logo)
-	logoETag := fmt.Sprintf("\"%s\"", hex.EncodeToString(logoHash[:]))
-	logger.Debug().Str("etag", logoETag).Int("content_size", len(logo)).Msg("Cached Logo file with ETag")
+	css, favicon, logo := assets["css/tailwind.css"], assets["images/favicon.png"], assets["images/logo.png"]
+	logger.Debug().Str("css_etag", css.etag).Str("favicon_etag", favicon.etag).Str("logo_etag", logo.etag).Msg("Static assets loaded")
 
 	return &StaticHandler{
 		logger:         logger,
-		cssETag:        cssETag,
-		cssContent:     css,
-		faviconETag:    faviconETag,
-		faviconContent: favicon,
-		logoETag:       logoETag,
-		logoContent:    logo,
+		cssETag:        css.etag,
+		cssContent:     css.content,
+		faviconETag:    favicon.etag,
+		faviconContent: favicon.content,
+		logoETag:       logo.etag,
+		logoContent:    logo.content,
 	}, nil
 }
 
 // RegisterRoutes registers static asset routes
 func (h *StaticHandler) RegisterRoutes() {
-	http.HandleFunc("/static/css/tailwind.css", h.serveTailwindCSS)
-	http.HandleFunc("/favicon.ico", h.serveFavicon)               // Standard browser location
-	http.HandleFunc("/static/images/favicon.png", h.serveFavicon) // Explicit path
-	http.HandleFunc("/static/images/logo.png", h.serveLogo)       // Logo path
+	handleMethods(http.DefaultServeMux, "/static/css/tailwind.css", h.serveTailwindCSS, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/favicon.ico", h.serveFavicon, http.MethodGet)               // Standard browser location
+	handleMethods(http.DefaultServeMux, "/static/images/favicon.png", h.serveFavicon, http.MethodGet) // Explicit path
+	handleMethods(http.DefaultServeMux, "/static/images/logo.png", h.serveLogo, http.MethodGet)       // Logo path
 }
 
 // serveTailwindCSS serves the embedded Tailwind CSS file with ETag support
@@ -145,18 +115,3 @@ func parseETags(header string) []string {
 	}
 	return etags
 }
-
-// GetCSSETag returns the ETag for the CSS file, stripping quotes
-func (h *StaticHandler) GetCSSETag() string {
-	return strings.Trim(h.cssETag, "\"")
-}
-
-// GetFaviconETag returns the ETag for the favicon, stripping quotes
-func (h *StaticHandler) GetFaviconETag() string {
-	return strings.Trim(h.faviconETag, "\"")
-}
-
-// GetLogoETag returns the ETag for the logo, stripping quotes
-func (h *StaticHandler) GetLogoETag() string {
-	return strings.Trim(h.logoETag, "\"")
-}
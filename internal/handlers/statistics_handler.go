@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"sort"
+	"strconv"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/report"
 )
 
 // ParentStatsForTemplate holds processed monthly statistics for a single parent,
@@ -22,28 +29,203 @@ type StatisticsPageData struct {
 	ErrorMessage    string
 	ParentsStats    []ParentStatsForTemplate
 	BabysitterStats []ParentStatsForTemplate
-	MonthHeaders    []string // Sorted list of "YYYY-MM" for table columns, e.g., ["2023-06", "2023-07"]
+	MonthHeaders    []string             // Sorted list of "YYYY-MM" for table columns, e.g., ["2023-06", "2023-07"]
+	Highlights      *fairness.Highlights // nil when they could not be computed
+	ReportMonth     string               // Month preselected for the monthly report, YYYY-MM
+	AsOf            string               // Past day the statistics are shown as of, YYYY-MM-DD; empty for today
 }
 
+// errInvalidAsOf is returned for an as_of parameter that is not a day up to today
+var errInvalidAsOf = errors.New("as_of must be a date (YYYY-MM-DD) not after today")
+
+// parseAsOf returns the moment the statistics are computed for: now without as_of, otherwise the
+// given day in the location of now, which cannot be after today
+func parseAsOf(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return now, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return time.Time{}, errInvalidAsOf
+	}
+	if day.After(now) {
+		return time.Time{}, errInvalidAsOf
+	}
+	return day, nil
+}
+
+// ChecklistCompletionProvider tells when the checklists of the assignments were fully ticked
+type ChecklistCompletionProvider interface {
+	GetChecklistCompletions(start, end time.Time) (map[int64]time.Time, error)
+}
+
+// AcknowledgementProvider tells which nights were acknowledged by the parent on duty
+type AcknowledgementProvider interface {
+	GetAcknowledgements(start, end time.Time) (map[int64]time.Time, error)
+}
+
+// Bounds of the number of months covered by the highlights API
+const (
+	defaultHighlightsMonths = 12
+	maxHighlightsMonths     = 24
+)
+
 // StatisticsHandler manages statistics page functionality.
 type StatisticsHandler struct {
 	*BaseHandler
-	configStore *database.ConfigStore
-	now         func() time.Time // injectable for testing; defaults to time.Now
+	configStore      database.ConfigStoreInterface
+	stats            fairness.MonthlyStatsProvider
+	checklists       ChecklistCompletionProvider // nil when checklists are not tracked
+	acknowledgements AcknowledgementProvider     // nil when acknowledgements are not tracked
+	now              func() time.Time            // injectable for testing; defaults to time.Now
 }
 
-// NewStatisticsHandler creates a new statistics page handler.
-func NewStatisticsHandler(baseHandler *BaseHandler, configStore *database.ConfigStore) *StatisticsHandler {
+// NewStatisticsHandler creates a new statistics page handler reading the monthly counts from stats,
+// usually a fairness.StatsCache in front of the tracker. The on-time checklists and the acknowledged
+// nights of the highlights come from checklists and acknowledgements, which may be nil.
+func NewStatisticsHandler(baseHandler *BaseHandler, configStore database.ConfigStoreInterface, stats fairness.MonthlyStatsProvider, checklists ChecklistCompletionProvider, acknowledgements AcknowledgementProvider) *StatisticsHandler {
 	return &StatisticsHandler{
-		BaseHandler: baseHandler,
-		configStore: configStore,
-		now:         time.Now,
+		BaseHandler:      baseHandler,
+		configStore:      configStore,
+		stats:            stats,
+		checklists:       checklists,
+		acknowledgements: acknowledgements,
+		now:              time.Now,
 	}
 }
 
 // RegisterRoutes registers statistics page related routes.
 func (h *StatisticsHandler) RegisterRoutes() {
-	http.HandleFunc("/statistics", h.handleStatisticsPage)
+	handleMethods(http.DefaultServeMux, "/statistics", h.handleStatisticsPage, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/statistics/highlights", h.handleHighlights, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/statistics/fairness", h.handleFairness, http.MethodGet)
+}
+
+// computeHighlights computes the highlights of the nights from the first day of the month nMonths-1
+// months before now until today
+func (h *StatisticsHandler) computeHighlights(ctx context.Context, now time.Time, nMonths int) (*fairness.Highlights, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -nMonths+1, 0)
+
+	assignments, err := h.Tracker.GetAssignmentsInRange(ctx, start, today)
+	if err != nil {
+		return nil, err
+	}
+	var completions map[int64]time.Time
+	if h.checklists != nil {
+		completions, err = h.checklists.GetChecklistCompletions(start, today)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var acknowledgements map[int64]time.Time
+	if h.acknowledgements != nil {
+		acknowledgements, err = h.acknowledgements.GetAcknowledgements(start, today)
+		if err != nil {
+			return nil, err
+		}
+	}
+	highlights := fairness.ComputeHighlights(assignments, completions, acknowledgements, now.Location())
+	return &highlights, nil
+}
+
+// ParentHighlightsResponse is the highlights of a parent in the API response
+type ParentHighlightsResponse struct {
+	Parent                  string `json:"parent"`
+	Color                   string `json:"color,omitempty"`  // Color of the parent as #rrggbb
+	Avatar                  string `json:"avatar,omitempty"` // Avatar or initial of the parent
+	Nights                  int    `json:"nights"`
+	LongestStreak           int    `json:"longest_streak"`
+	WeekendNights           int    `json:"weekend_nights"`
+	ChecklistsOnTime        int    `json:"checklists_on_time"`
+	ChecklistsOnTimePercent int    `json:"checklists_on_time_percent"`
+	AcknowledgedNights      int    `json:"acknowledged_nights"`
+	// TaggedNights counts the tagged nights by tag, without the tags of no night
+	TaggedNights map[string]int `json:"tagged_nights"`
+}
+
+// MonthlyMVPResponse is the MVP of a month in the API response
+type MonthlyMVPResponse struct {
+	Month            string   `json:"month"`
+	Parents          []string `json:"parents"`
+	Nights           int      `json:"nights"`
+	ChecklistsOnTime int      `json:"checklists_on_time"`
+}
+
+// HighlightsResponse is the response of the highlights API
+type HighlightsResponse struct {
+	Months      int                        `json:"months"`
+	AsOf        string                     `json:"as_of"` // Last day covered, YYYY-MM-DD
+	Parents     []ParentHighlightsResponse `json:"parents"`
+	MonthlyMVPs []MonthlyMVPResponse       `json:"monthly_mvps"`
+}
+
+// handleHighlights returns the streaks, weekend nights, on-time checklists, acknowledged nights and monthly MVPs.
+// The optional months query parameter sets the number of months covered (default 12, at most 24),
+// the optional as_of one the past day they end with.
+func (h *StatisticsHandler) handleHighlights(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleHighlights").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	months := defaultHighlightsMonths
+	if monthsStr := r.URL.Query().Get("months"); monthsStr != "" {
+		parsed, err := strconv.Atoi(monthsStr)
+		if err != nil || parsed < 1 || parsed > maxHighlightsMonths {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "months must be a number between 1 and 24", handlerLogger)
+			return
+		}
+		months = parsed
+	}
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"), h.now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+		return
+	}
+
+	highlights, err := h.computeHighlights(r.Context(), asOf, months)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to compute highlights")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to compute highlights", handlerLogger)
+		return
+	}
+
+	response := HighlightsResponse{
+		Months:      months,
+		AsOf:        asOf.Format("2006-01-02"),
+		Parents:     make([]ParentHighlightsResponse, 0, len(highlights.Parents)),
+		MonthlyMVPs: make([]MonthlyMVPResponse, 0, len(highlights.MonthlyMVPs)),
+	}
+	styles := h.parentStyles(handlerLogger)
+	for _, p := range highlights.Parents {
+		taggedNights := make(map[string]int, len(p.TaggedNights))
+		for _, tagged := range p.TaggedNights {
+			taggedNights[tagged.Tag.String()] = tagged.Nights
+		}
+		parent := ParentHighlightsResponse{
+			Parent:                  p.Parent,
+			Nights:                  p.Nights,
+			LongestStreak:           p.LongestStreak,
+			WeekendNights:           p.WeekendNights,
+			ChecklistsOnTime:        p.ChecklistsOnTime,
+			ChecklistsOnTimePercent: p.OnTimePercent(),
+			AcknowledgedNights:      p.Acknowledged,
+			TaggedNights:            taggedNights,
+		}
+		if style, ok := styles[p.Parent]; ok {
+			parent.Color = style.Color.Hex()
+			parent.Avatar = style.Badge(p.Parent)
+		}
+		response.Parents = append(response.Parents, parent)
+	}
+	for _, mvp := range highlights.MonthlyMVPs {
+		response.MonthlyMVPs = append(response.MonthlyMVPs, MonthlyMVPResponse(mvp))
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
 }
 
 // handleStatisticsPage shows the statistics page.
@@ -54,7 +236,18 @@ func (h *StatisticsHandler) handleStatisticsPage(w http.ResponseWriter, r *http.
 	data := StatisticsPageData{
 		BasePageData: h.NewBasePageData(r, true), // Assuming authenticated
 	}
-	nowForStats := h.now() // Use a consistent "now" for this request processing
+	now := h.now()
+	nowForStats, err := parseAsOf(r.URL.Query().Get("as_of"), now) // Use a consistent "now" for this request processing
+	if err != nil {
+		handlerLogger.Warn().Err(err).Str("as_of", r.URL.Query().Get("as_of")).Msg("Invalid as_of date")
+		data.ErrorMessage = "Invalid date: " + err.Error()
+		h.RenderTemplate(w, "statistics.html", data)
+		return
+	}
+	if r.URL.Query().Get("as_of") != "" {
+		data.AsOf = nowForStats.Format("2006-01-02")
+	}
+	data.ReportMonth = report.StartOfMonth(nowForStats).AddDate(0, -1, 0).Format("2006-01")
 
 	// Get the stats order from configuration (we only need statsOrder, ignore other schedule values)
 	_, _, _, statsOrder, err := h.configStore.GetSchedule()
@@ -63,7 +256,7 @@ func (h *StatisticsHandler) handleStatisticsPage(w http.ResponseWriter, r *http.
 		statsOrder = constants.StatsOrderDesc
 	}
 
-	rawStats, err := h.Tracker.GetParentMonthlyStatsForLastNMonths(nowForStats, 12)
+	rawStats, err := h.stats.GetParentMonthlyStatsForLastNMonths(r.Context(), nowForStats, 12)
 	if err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to get parent monthly stats from tracker")
 		data.ErrorMessage = "Could not retrieve statistics data. Please try again later."
@@ -71,7 +264,7 @@ func (h *StatisticsHandler) handleStatisticsPage(w http.ResponseWriter, r *http.
 		return
 	}
 
-	rawBabysitterStats, err := h.Tracker.GetBabysitterMonthlyStatsForLastNMonths(nowForStats, 12)
+	rawBabysitterStats, err := h.stats.GetBabysitterMonthlyStatsForLastNMonths(r.Context(), nowForStats, 12)
 	if err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to get babysitter monthly stats from tracker")
 		data.ErrorMessage = "Could not retrieve statistics data. Please try again later."
@@ -79,6 +272,13 @@ func (h *StatisticsHandler) handleStatisticsPage(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// The highlights are an extra, the page is still shown without them
+	if highlights, err := h.computeHighlights(r.Context(), nowForStats, 12); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to compute highlights")
+	} else if len(highlights.Parents) > 0 {
+		data.Highlights = highlights
+	}
+
 	if len(rawStats) == 0 && len(rawBabysitterStats) == 0 {
 		// No data from the database, so show "No statistics data available"
 		// data.ParentsStats is already nil, data.MonthHeaders is empty.
@@ -226,3 +426,98 @@ func (h *StatisticsHandler) handleStatisticsPage(w http.ResponseWriter, r *http.
 		Msg("Processed statistics data for template")
 	h.RenderTemplate(w, "statistics.html", data)
 }
+
+// ParentFairnessResponse is the fairness counters of a parent in the fairness API response
+type ParentFairnessResponse struct {
+	Parent           string `json:"parent"`
+	Color            string `json:"color,omitempty"`  // Color of the parent as #rrggbb
+	Avatar           string `json:"avatar,omitempty"` // Avatar or initial of the parent
+	TotalAssignments int    `json:"total_assignments"`
+	Last30Days       int    `json:"last_30_days"`
+}
+
+// FairnessNightResponse is one of the last nights in the fairness API response
+type FairnessNightResponse struct {
+	Date           string `json:"date"`
+	Caregiver      string `json:"caregiver"`
+	CaregiverType  string `json:"caregiver_type"`
+	DecisionReason string `json:"decision_reason"`
+}
+
+// FairnessResponse is the response of the fairness API: the counters the scheduler would have
+// compared after the night of AsOf, and the last nights up to it
+type FairnessResponse struct {
+	AsOf       string                   `json:"as_of"`
+	Parents    []ParentFairnessResponse `json:"parents"`
+	LastNights []FairnessNightResponse  `json:"last_nights"` // Newest first
+}
+
+// fairnessLastNights is the number of nights listed by the fairness API, as many as the scheduler looks at
+const fairnessLastNights = 7
+
+// handleFairness returns the fairness counters of the parents as of the end of a day, today or the
+// day of the optional as_of query parameter. Babysitter nights count for every parent.
+func (h *StatisticsHandler) handleFairness(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleFairness").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	asOf, err := parseAsOf(r.URL.Query().Get("as_of"), h.now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+		return
+	}
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, asOf.Location())
+	until := day.AddDate(0, 0, 1)
+
+	roster, err := config.Roster(h.ConfigStore)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parents")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get parents", handlerLogger)
+		return
+	}
+	stats, err := h.Tracker.GetParentStatsUntil(r.Context(), until, roster...)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get parent stats")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get fairness statistics", handlerLogger)
+		return
+	}
+	lastNights, err := h.Tracker.GetLastAssignmentsUntil(r.Context(), fairnessLastNights, until)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get last assignments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get fairness statistics", handlerLogger)
+		return
+	}
+
+	response := FairnessResponse{
+		AsOf:       day.Format("2006-01-02"),
+		Parents:    make([]ParentFairnessResponse, 0, len(roster)),
+		LastNights: make([]FairnessNightResponse, 0, len(lastNights)),
+	}
+	styles := h.parentStyles(handlerLogger)
+	for _, name := range roster {
+		parent := ParentFairnessResponse{
+			Parent:           name,
+			TotalAssignments: stats[name].TotalAssignments,
+			Last30Days:       stats[name].Last30Days,
+		}
+		if style, ok := styles[name]; ok {
+			parent.Color = style.Color.Hex()
+			parent.Avatar = style.Badge(name)
+		}
+		response.Parents = append(response.Parents, parent)
+	}
+	for _, a := range lastNights {
+		response.LastNights = append(response.LastNights, FairnessNightResponse{
+			Date:           a.Date.Format("2006-01-02"),
+			Caregiver:      a.Parent,
+			CaregiverType:  a.CaregiverType.String(),
+			DecisionReason: a.DecisionReason.String(),
+		})
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -38,9 +39,9 @@ func setupTestStatisticsHandler(t *testing.T, statsOrder constants.StatsOrder) (
 	require.NoError(t, err)
 
 	// Seed initial data with specified stats order
-	err = configStore.SaveParents("TestParentA", "TestParentB")
+	err = configStore.SaveParents(t.Context(), "TestParentA", "TestParentB")
 	require.NoError(t, err)
-	err = configStore.SaveSchedule("weekly", 30, 5, statsOrder)
+	err = configStore.SaveSchedule(t.Context(), "weekly", 30, 5, statsOrder)
 	require.NoError(t, err)
 
 	// Create token store
@@ -70,11 +71,11 @@ func setupTestStatisticsHandler(t *testing.T, statsOrder constants.StatsOrder) (
 	configAdapter := database.NewConfigAdapter(configStore, oauthCfg)
 
 	// Create base handler
-	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, "test-version", "test-logo-version")
+	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, nil)
 	require.NoError(t, err)
 
 	// Create statistics handler
-	handler := NewStatisticsHandler(baseHandler, configStore)
+	handler := NewStatisticsHandler(baseHandler, configStore, tracker, nil, nil)
 
 	cleanup := func() {
 		db.Close()
@@ -105,7 +106,7 @@ func TestStatisticsHandler_StatsOrderDescending(t *testing.T) {
 	}
 
 	for _, date := range dates {
-		_, err := tracker.RecordAssignment("TestParentA", date, false, fairness.DecisionReasonTotalCount)
+		_, err := tracker.RecordAssignment(t.Context(), "TestParentA", date, false, fairness.DecisionReasonTotalCount)
 		require.NoError(t, err)
 	}
 
@@ -157,7 +158,7 @@ func TestStatisticsHandler_StatsOrderAscending(t *testing.T) {
 	}
 
 	for _, date := range dates {
-		_, err := tracker.RecordAssignment("TestParentA", date, false, fairness.DecisionReasonTotalCount)
+		_, err := tracker.RecordAssignment(t.Context(), "TestParentA", date, false, fairness.DecisionReasonTotalCount)
 		require.NoError(t, err)
 	}
 
@@ -217,7 +218,7 @@ func TestStatisticsHandler_DefaultsToDescendingOnError(t *testing.T) {
 	handler.now = func() time.Time { return fixedNow }
 
 	// Add a single assignment
-	_, err := tracker.RecordAssignment("TestParentA", fixedNow, false, fairness.DecisionReasonTotalCount)
+	_, err := tracker.RecordAssignment(t.Context(), "TestParentA", fixedNow, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
 
 	// Make request
@@ -238,11 +239,13 @@ func TestStatisticsHandler_MultipleParents(t *testing.T) {
 	// Use fixed dates in the same month to ensure both parents appear
 	// Use dates in the past to ensure they are counted
 	baseDate := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC) // October 2025
+	// Pin the current date so that October 2025 stays within the last 12 months
+	handler.now = func() time.Time { return baseDate.AddDate(0, 1, 0) }
 
 	// Create assignments for both parents on different days in the same month
-	_, err := tracker.RecordAssignment("TestParentA", baseDate, false, fairness.DecisionReasonTotalCount)
+	_, err := tracker.RecordAssignment(t.Context(), "TestParentA", baseDate, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	_, err = tracker.RecordAssignment("TestParentB", baseDate.AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
+	_, err = tracker.RecordAssignment(t.Context(), "TestParentB", baseDate.AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
 
 	// Make request
@@ -263,9 +266,10 @@ func TestStatisticsHandler_IncludesBabysitterSection(t *testing.T) {
 	defer cleanup()
 
 	baseDate := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
-	_, err := tracker.RecordAssignment("TestParentA", baseDate, false, fairness.DecisionReasonTotalCount)
+	handler.now = func() time.Time { return baseDate.AddDate(0, 1, 0) }
+	_, err := tracker.RecordAssignment(t.Context(), "TestParentA", baseDate, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
-	_, err = tracker.RecordBabysitterAssignment("Dawn", baseDate.AddDate(0, 0, 1), true)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Dawn", baseDate.AddDate(0, 0, 1), true)
 	require.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/statistics", nil)
@@ -277,3 +281,163 @@ func TestStatisticsHandler_IncludesBabysitterSection(t *testing.T) {
 	assert.Contains(t, body, "Babysitter Days")
 	assert.Contains(t, body, "Dawn")
 }
+
+// fakeChecklistCompletions returns fixed completion times
+type fakeChecklistCompletions map[int64]time.Time
+
+func (f fakeChecklistCompletions) GetChecklistCompletions(time.Time, time.Time) (map[int64]time.Time, error) {
+	return f, nil
+}
+
+// fakeAcknowledgements returns fixed acknowledgement times
+type fakeAcknowledgements map[int64]time.Time
+
+func (f fakeAcknowledgements) GetAcknowledgements(time.Time, time.Time) (map[int64]time.Time, error) {
+	return f, nil
+}
+
+func TestStatisticsHandler_Highlights(t *testing.T) {
+	handler, _, _, tracker, cleanup := setupTestStatisticsHandler(t, constants.StatsOrderDesc)
+	defer cleanup()
+
+	// Friday 2nd to Sunday 4th of October 2026
+	friday := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC) }
+	first, err := tracker.RecordAssignment(t.Context(), "TestParentA", friday, false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "TestParentA", friday.AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	sunday, err := tracker.RecordAssignment(t.Context(), "TestParentB", friday.AddDate(0, 0, 2), true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	require.NoError(t, tracker.SetAssignmentTag(t.Context(), sunday.ID, fairness.AssignmentTagSickKid))
+	// Future nights are not counted
+	_, err = tracker.RecordAssignment(t.Context(), "TestParentB", time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	handler.checklists = fakeChecklistCompletions{first.ID: friday.Add(20 * time.Hour)}
+	handler.acknowledgements = fakeAcknowledgements{sunday.ID: friday.AddDate(0, 0, 2).Add(19 * time.Hour)}
+
+	w := httptest.NewRecorder()
+	handler.handleHighlights(w, httptest.NewRequest(http.MethodGet, "/api/statistics/highlights", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var response HighlightsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, 12, response.Months)
+	assert.Equal(t, []ParentHighlightsResponse{
+		{Parent: "TestParentA", Color: "#3f51b5", Avatar: "T", Nights: 2, LongestStreak: 2, WeekendNights: 2, ChecklistsOnTime: 1, ChecklistsOnTimePercent: 50, TaggedNights: map[string]int{}},
+		{Parent: "TestParentB", Color: "#f4511e", Avatar: "T", Nights: 1, LongestStreak: 1, AcknowledgedNights: 1, TaggedNights: map[string]int{"sick_kid": 1}},
+	}, response.Parents)
+	assert.Equal(t, []MonthlyMVPResponse{{Month: "2026-10", Parents: []string{"TestParentA"}, Nights: 2, ChecklistsOnTime: 1}}, response.MonthlyMVPs)
+
+	w = httptest.NewRecorder()
+	handler.handleStatisticsPage(w, httptest.NewRequest(http.MethodGet, "/statistics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Highlights")
+	assert.Contains(t, body, "1 / 2 (50%)")
+	assert.Contains(t, body, "Sick kid")
+	assert.Contains(t, body, "Nights acknowledged")
+	assert.Contains(t, body, "Monthly MVP")
+}
+
+func TestStatisticsHandler_HighlightsErrors(t *testing.T) {
+	handler, _, _, _, cleanup := setupTestStatisticsHandler(t, constants.StatsOrderDesc)
+	defer cleanup()
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+	}{
+		{"months too large", http.MethodGet, "/api/statistics/highlights?months=25", http.StatusBadRequest},
+		{"months not a number", http.MethodGet, "/api/statistics/highlights?months=all", http.StatusBadRequest},
+		{"method not allowed", http.MethodPost, "/api/statistics/highlights", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleHighlights(w, httptest.NewRequest(tt.method, tt.url, nil))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestStatisticsHandler_AsOf(t *testing.T) {
+	handler, _, _, tracker, cleanup := setupTestStatisticsHandler(t, constants.StatsOrderDesc)
+	defer cleanup()
+
+	handler.now = func() time.Time { return time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC) }
+	for i, parent := range []string{"TestParentA", "TestParentB", "TestParentA"} {
+		_, err := tracker.RecordAssignment(t.Context(), parent, time.Date(2026, 8, 30+i, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonAlternating)
+		require.NoError(t, err)
+	}
+	_, err := tracker.RecordAssignment(t.Context(), "TestParentB", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.handleFairness(w, httptest.NewRequest(http.MethodGet, "/api/statistics/fairness?as_of=2026-08-31", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var fairnessResponse FairnessResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&fairnessResponse))
+	assert.Equal(t, "2026-08-31", fairnessResponse.AsOf)
+	assert.Equal(t, []ParentFairnessResponse{
+		{Parent: "TestParentA", Color: "#3f51b5", Avatar: "T", TotalAssignments: 1, Last30Days: 1},
+		{Parent: "TestParentB", Color: "#f4511e", Avatar: "T", TotalAssignments: 1, Last30Days: 1},
+	}, fairnessResponse.Parents, "the night of as_of is counted")
+	assert.Equal(t, []FairnessNightResponse{
+		{Date: "2026-08-31", Caregiver: "TestParentB", CaregiverType: "parent", DecisionReason: "Alternating"},
+		{Date: "2026-08-30", Caregiver: "TestParentA", CaregiverType: "parent", DecisionReason: "Alternating"},
+	}, fairnessResponse.LastNights)
+
+	w = httptest.NewRecorder()
+	handler.handleFairness(w, httptest.NewRequest(http.MethodGet, "/api/statistics/fairness", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	fairnessResponse = FairnessResponse{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&fairnessResponse))
+	assert.Equal(t, "2026-10-20", fairnessResponse.AsOf)
+	assert.Equal(t, 2, fairnessResponse.Parents[1].TotalAssignments)
+	assert.Len(t, fairnessResponse.LastNights, 4)
+
+	w = httptest.NewRecorder()
+	handler.handleHighlights(w, httptest.NewRequest(http.MethodGet, "/api/statistics/highlights?months=1&as_of=2026-09-15", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var highlights HighlightsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&highlights))
+	assert.Equal(t, "2026-09-15", highlights.AsOf)
+	require.Len(t, highlights.Parents, 1, "only September is covered")
+	assert.Equal(t, "TestParentA", highlights.Parents[0].Parent)
+
+	w = httptest.NewRecorder()
+	handler.handleStatisticsPage(w, httptest.NewRequest(http.MethodGet, "/statistics?as_of=2026-09-15", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "as of 2026-09-15")
+	assert.Contains(t, body, "2026-08")
+	assert.NotContains(t, body, "2026-10</th>", "October is after as_of")
+}
+
+func TestStatisticsHandler_AsOfErrors(t *testing.T) {
+	handler, _, _, _, cleanup := setupTestStatisticsHandler(t, constants.StatsOrderDesc)
+	defer cleanup()
+	handler.now = func() time.Time { return time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC) }
+
+	for _, asOf := range []string{"2026-10-21", "yesterday"} {
+		w := httptest.NewRecorder()
+		handler.handleFairness(w, httptest.NewRequest(http.MethodGet, "/api/statistics/fairness?as_of="+asOf, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, asOf)
+
+		w = httptest.NewRecorder()
+		handler.handleHighlights(w, httptest.NewRequest(http.MethodGet, "/api/statistics/highlights?as_of="+asOf, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, asOf)
+
+		w = httptest.NewRecorder()
+		handler.handleStatisticsPage(w, httptest.NewRequest(http.MethodGet, "/statistics?as_of="+asOf, nil))
+		assert.Contains(t, w.Body.String(), "Invalid date", asOf)
+	}
+
+	w := httptest.NewRecorder()
+	handler.handleFairness(w, httptest.NewRequest(http.MethodPost, "/api/statistics/fairness", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
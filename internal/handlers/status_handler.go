@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/rs/zerolog"
+)
+
+// Component states reported by /api/status, on top of the readiness check results
+const (
+	CheckResultFailed  = "failed"
+	CheckResultRunning = "running"
+	CheckResultPaused  = "paused"
+)
+
+// StatusHandler reports the state of every subsystem for dashboards
+type StatusHandler struct {
+	*BaseHandler
+	DB *database.DB
+}
+
+// NewStatusHandler creates a new status handler
+func NewStatusHandler(baseHandler *BaseHandler, db *database.DB) *StatusHandler {
+	return &StatusHandler{
+		BaseHandler: baseHandler,
+		DB:          db,
+	}
+}
+
+// RegisterRoutes registers the status route
+func (h *StatusHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/status", h.handleStatus, http.MethodGet)
+}
+
+// ComponentStatus is the state of a subsystem without further details
+type ComponentStatus struct {
+	Status string `json:"status"`
+}
+
+// TokenStatus is the state of the Google token
+type TokenStatus struct {
+	Status    string     `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Expiry of the current access token
+}
+
+// CalendarStatus is the state of the selected Google Calendar
+type CalendarStatus struct {
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// ChannelStatus is the state of the Google Calendar notification channel
+type ChannelStatus struct {
+	Status    string     `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// LastSyncStatus is the outcome of the most recent schedule sync
+type LastSyncStatus struct {
+	Status           string     `json:"status"`
+	Trigger          string     `json:"trigger,omitempty"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	AssignmentsCount int        `json:"assignments_count"`
+	Error            string     `json:"error,omitempty"`
+}
+
+// SchedulerStatus is the state of the automatic schedule updates
+type SchedulerStatus struct {
+	Status          string `json:"status"`
+	UpdateFrequency string `json:"update_frequency,omitempty"`
+}
+
+// StatusResponse is the JSON response of /api/status. Status sums up the components
+// with the same values as /readyz.
+type StatusResponse struct {
+	Status              string          `json:"status"`
+	Database            ComponentStatus `json:"database"`
+	GoogleToken         TokenStatus     `json:"google_token"`
+	Calendar            CalendarStatus  `json:"calendar"`
+	NotificationChannel ChannelStatus   `json:"notification_channel"`
+	LastSync            LastSyncStatus  `json:"last_sync"`
+	Scheduler           SchedulerStatus `json:"scheduler"`
+}
+
+// handleStatus reports the state of each subsystem. A database failure makes the service
+// unavailable; a rejected token or a failed last sync degrades it. Unlike /readyz, the
+// response is always 200 so that dashboards can display the details.
+func (h *StatusHandler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleStatus").Logger()
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	resp := StatusResponse{Status: ReadinessStatusOK}
+	degrade := func() {
+		if resp.Status == ReadinessStatusOK {
+			resp.Status = ReadinessStatusDegraded
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	if err := h.DB.Conn().PingContext(ctx); err != nil {
+		handlerLogger.Warn().Err(err).Msg("Status database check failed")
+		resp.Database.Status = CheckResultError
+		resp.Status = ReadinessStatusUnavailable
+	} else {
+		resp.Database.Status = CheckResultOK
+	}
+
+	resp.GoogleToken = h.tokenStatus(handlerLogger)
+	if resp.GoogleToken.Status == CheckResultError || resp.GoogleToken.Status == CheckResultReauthenticationRequired {
+		degrade()
+	}
+
+	resp.Calendar = h.calendarStatus(handlerLogger)
+	resp.NotificationChannel = h.channelStatus(handlerLogger)
+
+	resp.LastSync = h.lastSyncStatus(handlerLogger)
+	if resp.LastSync.Status == CheckResultFailed {
+		degrade()
+	}
+
+	resp.Scheduler = h.schedulerStatus(handlerLogger)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
+
+// tokenStatus reports whether a usable Google token is stored
+func (h *StatusHandler) tokenStatus(logger zerolog.Logger) TokenStatus {
+	token, err := h.TokenManager.GetToken()
+	switch {
+	case err != nil:
+		logger.Warn().Err(err).Msg("Status token check failed")
+		return TokenStatus{Status: CheckResultError}
+	case token == nil:
+		return TokenStatus{Status: CheckResultMissing}
+	}
+
+	status := TokenStatus{Status: CheckResultOK}
+	if h.TokenManager.NeedsReauthentication() {
+		status.Status = CheckResultReauthenticationRequired
+	}
+	if !token.Expiry.IsZero() {
+		expiry := token.Expiry.UTC()
+		status.ExpiresAt = &expiry
+	}
+	return status
+}
+
+// calendarStatus reports the selected Google Calendar
+func (h *StatusHandler) calendarStatus(logger zerolog.Logger) CalendarStatus {
+	calendarID, calendarName, err := h.TokenStore.GetSelectedCalendarWithName()
+	switch {
+	case err != nil:
+		logger.Warn().Err(err).Msg("Status calendar check failed")
+		return CalendarStatus{Status: CheckResultError}
+	case calendarID == "":
+		return CalendarStatus{Status: CheckResultMissing}
+	}
+	return CalendarStatus{Status: CheckResultOK, ID: calendarID, Name: calendarName}
+}
+
+// channelStatus reports the notification channel expiring last. Without one, changes made in
+// Google Calendar are only picked up by the next scheduled sync.
+func (h *StatusHandler) channelStatus(logger zerolog.Logger) ChannelStatus {
+	channels, err := h.TokenStore.GetActiveNotificationChannels()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Status notification channel check failed")
+		return ChannelStatus{Status: CheckResultError}
+	}
+	if len(channels) == 0 {
+		return ChannelStatus{Status: CheckResultMissing}
+	}
+	// Channels are ordered by expiration, the last one is renewed last
+	expiry := channels[len(channels)-1].Expiration.UTC()
+	return ChannelStatus{Status: CheckResultOK, ExpiresAt: &expiry}
+}
+
+// lastSyncStatus reports the outcome of the most recent sync
+func (h *StatusHandler) lastSyncStatus(logger zerolog.Logger) LastSyncStatus {
+	if h.SyncRuns == nil {
+		return LastSyncStatus{Status: CheckResultMissing}
+	}
+	runs, err := h.SyncRuns.ListRuns(1)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Status last sync check failed")
+		return LastSyncStatus{Status: CheckResultError}
+	}
+	if len(runs) == 0 {
+		return LastSyncStatus{Status: CheckResultMissing}
+	}
+
+	run := runs[0]
+	status := LastSyncStatus{
+		Status:           CheckResultOK,
+		Trigger:          run.Trigger.String(),
+		StartedAt:        &run.StartedAt,
+		FinishedAt:       run.FinishedAt,
+		AssignmentsCount: run.AssignmentsCount,
+		Error:            run.Error,
+	}
+	switch run.Status {
+	case database.SyncRunStatusFailed:
+		status.Status = CheckResultFailed
+	case database.SyncRunStatusRunning:
+		status.Status = CheckResultRunning
+	}
+	return status
+}
+
+// schedulerStatus reports whether the schedule is updated automatically
+func (h *StatusHandler) schedulerStatus(logger zerolog.Logger) SchedulerStatus {
+	updateFrequency, _, _, _, err := h.ConfigStore.GetSchedule()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Status scheduler check failed")
+		return SchedulerStatus{Status: CheckResultError}
+	}
+	status := SchedulerStatus{Status: CheckResultOK, UpdateFrequency: updateFrequency}
+	if updateFrequency == "disabled" {
+		status.Status = CheckResultPaused
+	}
+	return status
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+type statusTestEnv struct {
+	handler     *StatusHandler
+	db          *database.DB
+	tokenStore  *database.TokenStore
+	configStore *database.ConfigStore
+	syncRuns    *database.SyncRunStore
+}
+
+func setupTestStatusHandler(t *testing.T) *statusTestEnv {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "status.db"),
+		Mode:        "rwc",
+		Cache:       database.CachePrivate,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveSchedule(t.Context(), "daily", 30, 5, constants.StatsOrderDesc))
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	syncRuns, err := database.NewSyncRunStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	tokenManager := token.NewTokenManager(tokenStore, oauthCfg)
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(configStore, oauthCfg), tokenStore, tokenManager, tracker, syncRuns)
+	require.NoError(t, err)
+
+	return &statusTestEnv{
+		handler:     NewStatusHandler(baseHandler, db),
+		db:          db,
+		tokenStore:  tokenStore,
+		configStore: configStore,
+		syncRuns:    syncRuns,
+	}
+}
+
+func getStatus(t *testing.T, handler *StatusHandler) StatusResponse {
+	w := httptest.NewRecorder()
+	handler.handleStatus(w, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var resp StatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestStatusHandler_FreshInstall(t *testing.T) {
+	env := setupTestStatusHandler(t)
+
+	resp := getStatus(t, env.handler)
+
+	assert.Equal(t, ReadinessStatusOK, resp.Status)
+	assert.Equal(t, CheckResultOK, resp.Database.Status)
+	assert.Equal(t, CheckResultMissing, resp.GoogleToken.Status)
+	assert.Equal(t, CheckResultMissing, resp.Calendar.Status)
+	assert.Equal(t, CheckResultMissing, resp.NotificationChannel.Status)
+	assert.Equal(t, CheckResultMissing, resp.LastSync.Status)
+	assert.Equal(t, CheckResultOK, resp.Scheduler.Status)
+	assert.Equal(t, "daily", resp.Scheduler.UpdateFrequency)
+}
+
+func TestStatusHandler_Configured(t *testing.T) {
+	env := setupTestStatusHandler(t)
+	tokenExpiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, env.tokenStore.SaveToken(&oauth2.Token{AccessToken: "access", Expiry: tokenExpiry}))
+	require.NoError(t, env.tokenStore.SaveSelectedCalendarWithName("family@group.calendar.google.com", "Family"))
+	channelExpiry := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, env.tokenStore.SaveNotificationChannel(&database.NotificationChannel{
+		ID:         "channel-1",
+		ResourceID: "resource-1",
+		CalendarID: "family@group.calendar.google.com",
+		Expiration: channelExpiry,
+	}))
+	require.NoError(t, env.syncRuns.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 12, nil }))
+
+	resp := getStatus(t, env.handler)
+
+	assert.Equal(t, ReadinessStatusOK, resp.Status)
+	assert.Equal(t, CheckResultOK, resp.GoogleToken.Status)
+	require.NotNil(t, resp.GoogleToken.ExpiresAt)
+	assert.True(t, tokenExpiry.Equal(*resp.GoogleToken.ExpiresAt))
+
+	assert.Equal(t, CalendarStatus{Status: CheckResultOK, ID: "family@group.calendar.google.com", Name: "Family"}, resp.Calendar)
+
+	assert.Equal(t, CheckResultOK, resp.NotificationChannel.Status)
+	require.NotNil(t, resp.NotificationChannel.ExpiresAt)
+	assert.True(t, channelExpiry.Equal(*resp.NotificationChannel.ExpiresAt))
+
+	assert.Equal(t, CheckResultOK, resp.LastSync.Status)
+	assert.Equal(t, "scheduled", resp.LastSync.Trigger)
+	assert.Equal(t, 12, resp.LastSync.AssignmentsCount)
+	assert.NotNil(t, resp.LastSync.FinishedAt)
+}
+
+func TestStatusHandler_Degraded(t *testing.T) {
+	env := setupTestStatusHandler(t)
+	require.Error(t, env.syncRuns.RecordRun(context.Background(), constants.SyncTriggerWebhook, func(context.Context) (int, error) { return 0, errors.New("quota exceeded") }))
+	require.NoError(t, env.configStore.SaveSchedule(t.Context(), "disabled", 30, 5, constants.StatsOrderDesc))
+
+	resp := getStatus(t, env.handler)
+
+	assert.Equal(t, ReadinessStatusDegraded, resp.Status)
+	assert.Equal(t, CheckResultFailed, resp.LastSync.Status)
+	assert.Equal(t, "quota exceeded", resp.LastSync.Error)
+	assert.Equal(t, CheckResultPaused, resp.Scheduler.Status)
+}
+
+func TestStatusHandler_DatabaseDown(t *testing.T) {
+	env := setupTestStatusHandler(t)
+	require.NoError(t, env.db.Close())
+
+	resp := getStatus(t, env.handler)
+
+	assert.Equal(t, ReadinessStatusUnavailable, resp.Status)
+	assert.Equal(t, CheckResultError, resp.Database.Status)
+}
+
+func TestStatusHandler_MethodNotAllowed(t *testing.T) {
+	env := setupTestStatusHandler(t)
+
+	w := httptest.NewRecorder()
+	env.handler.handleStatus(w, httptest.NewRequest(http.MethodPost, "/api/status", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// swapRequestsListLimit is the number of swap requests listed, newest first
+const swapRequestsListLimit = 50
+
+// swapNotificationDateFormat is the format of the nights in the swap notifications
+const swapNotificationDateFormat = "Monday 2 January"
+
+// Errors of proposeSwap mapped to client errors
+var (
+	errSwapNightNotHeld = errors.New("the night is not held by the parent")
+	errSwapPastNight    = errors.New("a night of the swap is past")
+)
+
+// SwapRequests stores the swap requests, implemented by database.SwapRequestStore
+type SwapRequests interface {
+	CreateSwapRequest(proposer string, proposerDate time.Time, responder string, responderDate time.Time, now time.Time) (*database.SwapRequest, error)
+	GetSwapRequest(id int64) (*database.SwapRequest, error)
+	ListSwapRequests(limit int) ([]*database.SwapRequest, error)
+	ResolveSwapRequest(id int64, status string, now time.Time) error
+}
+
+// SwapHandler lets a parent propose to exchange one of their nights with a night of another
+// parent; the swap is applied once the other parent accepts it. The application has no user
+// accounts, so the caller names themselves, like when claiming tonight.
+type SwapHandler struct {
+	*BaseHandler
+	Tracker         fairness.TrackerInterface
+	Scheduler       Scheduler.SchedulerInterface
+	CalendarService calendar.CalendarService
+	ConfigStore     config.ConfigStoreInterface
+	requests        SwapRequests
+	notifier        Notifier // nil to notify nobody
+	appURL          string
+	now             func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewSwapHandler creates a new swap handler telling the parents through notifier; the
+// notifications link the web interface at appURL
+func NewSwapHandler(baseHandler *BaseHandler, tracker fairness.TrackerInterface, sched Scheduler.SchedulerInterface, calSvc calendar.CalendarService, configStore config.ConfigStoreInterface, requests SwapRequests, notifier Notifier, appURL string) *SwapHandler {
+	return &SwapHandler{
+		BaseHandler:     baseHandler,
+		Tracker:         tracker,
+		Scheduler:       sched,
+		CalendarService: calSvc,
+		ConfigStore:     configStore,
+		requests:        requests,
+		notifier:        notifier,
+		appURL:          strings.TrimRight(appURL, "/"),
+		now:             time.Now,
+	}
+}
+
+// RegisterRoutes registers the swap request routes
+func (h *SwapHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/swap-requests", h.handleSwapRequests, http.MethodGet, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/v1/swap-requests/{id}/{action}", h.handleSwapRequestAction, http.MethodPost)
+}
+
+// ProposeSwapRequest is the JSON body proposing a swap
+type ProposeSwapRequest struct {
+	Parent    string `json:"parent"`     // Parent proposing the swap, one of the configured parents
+	Date      string `json:"date"`       // Night of the parent given away, YYYY-MM-DD
+	OtherDate string `json:"other_date"` // Night of another parent taken in exchange, YYYY-MM-DD
+}
+
+// AnswerSwapRequest is the JSON body answering a swap request
+type AnswerSwapRequest struct {
+	Parent string `json:"parent"` // The responder to accept or decline, the proposer to cancel
+}
+
+// SwapRequestResponse is a swap request
+type SwapRequestResponse struct {
+	ID            int64  `json:"id"`
+	Proposer      string `json:"proposer"`
+	ProposerDate  string `json:"proposer_date"` // Night the proposer gives to the responder
+	Responder     string `json:"responder"`
+	ResponderDate string `json:"responder_date"` // Night the proposer takes from the responder
+	Status        string `json:"status"`         // pending, accepted, declined or cancelled
+	CreatedAt     string `json:"created_at"`
+	ResolvedAt    string `json:"resolved_at,omitempty"`
+}
+
+// SwapRequestsResponse is the response of the swap request list
+type SwapRequestsResponse struct {
+	Requests []SwapRequestResponse `json:"requests"`
+}
+
+// SwapAnswerResponse is the response answering a swap request
+type SwapAnswerResponse struct {
+	SwapRequestResponse
+	Synced bool `json:"synced"` // False when the calendar could not be synced after an accepted swap, the next sync catches up
+}
+
+// newSwapRequestResponse converts a stored swap request
+func newSwapRequestResponse(request *database.SwapRequest) SwapRequestResponse {
+	response := SwapRequestResponse{
+		ID:            request.ID,
+		Proposer:      request.Proposer,
+		ProposerDate:  request.ProposerDate.Format(time.DateOnly),
+		Responder:     request.Responder,
+		ResponderDate: request.ResponderDate.Format(time.DateOnly),
+		Status:        request.Status,
+		CreatedAt:     request.CreatedAt.Format(time.RFC3339),
+	}
+	if !request.ResolvedAt.IsZero() {
+		response.ResolvedAt = request.ResolvedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// handleSwapRequests lists the recent swap requests on GET and proposes a swap on POST
+func (h *SwapHandler) handleSwapRequests(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleSwapRequests").Str("method", r.Method).Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		requests, err := h.requests.ListSwapRequests(swapRequestsListLimit)
+		if err != nil {
+			handlerLogger.Error().Err(err).Msg("Failed to list swap requests")
+			writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to list the swap requests", handlerLogger)
+			return
+		}
+		response := SwapRequestsResponse{Requests: make([]SwapRequestResponse, 0, len(requests))}
+		for _, request := range requests {
+			response.Requests = append(response.Requests, newSwapRequestResponse(request))
+		}
+		writeJSON(w, http.StatusOK, response, handlerLogger)
+		return
+	}
+
+	var req ProposeSwapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Parent == "" {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, parent is required", handlerLogger)
+		return
+	}
+	date, err := time.ParseInLocation(time.DateOnly, req.Date, time.Local)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid date, expected YYYY-MM-DD", handlerLogger)
+		return
+	}
+	otherDate, err := time.ParseInLocation(time.DateOnly, req.OtherDate, time.Local)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid other_date, expected YYYY-MM-DD", handlerLogger)
+		return
+	}
+	if date.Equal(otherDate) {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "date and other_date must be different nights", handlerLogger)
+		return
+	}
+
+	request, err := h.proposeSwap(r.Context(), handlerLogger, req.Parent, date, otherDate)
+	switch {
+	case errors.Is(err, errUnknownParent):
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "parent must be one of the configured parents", handlerLogger)
+		return
+	case errors.Is(err, errSwapPastNight):
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Both nights must be today or later", handlerLogger)
+		return
+	case errors.Is(err, fairness.ErrAssignmentNotScheduled):
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "A night of the swap is not scheduled", handlerLogger)
+		return
+	case errors.Is(err, errSwapNightNotHeld):
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error(), handlerLogger)
+		return
+	case err != nil:
+		handlerLogger.Error().Err(err).Msg("Failed to propose swap")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to propose the swap", handlerLogger)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newSwapRequestResponse(request), handlerLogger)
+}
+
+// proposeSwap stores a swap of the night of date, held by parent, with the night of otherDate,
+// held by another parent, and asks that parent to confirm it
+func (h *SwapHandler) proposeSwap(ctx context.Context, logger zerolog.Logger, parent string, date, otherDate time.Time) (*database.SwapRequest, error) {
+	roster, err := config.Roster(h.ConfigStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parents: %w", err)
+	}
+	if !slices.Contains(roster, parent) {
+		return nil, errUnknownParent
+	}
+	now := h.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if date.Before(today) || otherDate.Before(today) {
+		return nil, errSwapPastNight
+	}
+
+	own, err := h.nightOf(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	if own.CaregiverType != fairness.CaregiverTypeParent || own.Parent != parent {
+		return nil, fmt.Errorf("%w: %s is not a night of %s", errSwapNightNotHeld, date.Format(time.DateOnly), parent)
+	}
+	other, err := h.nightOf(ctx, otherDate)
+	if err != nil {
+		return nil, err
+	}
+	if other.CaregiverType != fairness.CaregiverTypeParent || other.Parent == parent {
+		return nil, fmt.Errorf("%w: %s is not a night of another parent", errSwapNightNotHeld, otherDate.Format(time.DateOnly))
+	}
+
+	request, err := h.requests.CreateSwapRequest(parent, date, other.Parent, otherDate, now)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info().Int64("swap_request_id", request.ID).Str("proposer", parent).Str("responder", other.Parent).Msg("Swap proposed")
+	h.notify(ctx, logger, notify.EventSwapProposed, request, false)
+	return request, nil
+}
+
+// nightOf returns the assignment of date, fairness.ErrAssignmentNotScheduled when there is none
+func (h *SwapHandler) nightOf(ctx context.Context, date time.Time) (*fairness.Assignment, error) {
+	assignment, err := h.Tracker.GetAssignmentByDate(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment of %s: %w", date.Format(time.DateOnly), err)
+	}
+	if assignment == nil {
+		return nil, fmt.Errorf("%s: %w", date.Format(time.DateOnly), fairness.ErrAssignmentNotScheduled)
+	}
+	return assignment, nil
+}
+
+// handleSwapRequestAction answers a pending swap request: the responder accepts or declines it,
+// the proposer cancels it. Accepting swaps both nights at once, then recalculates and syncs the
+// schedule from the first of them.
+func (h *SwapHandler) handleSwapRequestAction(w http.ResponseWriter, r *http.Request) {
+	action := r.PathValue("action")
+	handlerLogger := h.logger.With().Str("handler", "handleSwapRequestAction").Str("action", action).Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid swap request ID", handlerLogger)
+		return
+	}
+	handlerLogger = handlerLogger.With().Int64("swap_request_id", id).Logger()
+	if action != "accept" && action != "decline" && action != "cancel" {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Unknown action, expected accept, decline or cancel", handlerLogger)
+		return
+	}
+	var req AnswerSwapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Parent == "" {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, parent is required", handlerLogger)
+		return
+	}
+
+	request, err := h.requests.GetSwapRequest(id)
+	if errors.Is(err, database.ErrSwapRequestNotFound) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Swap request not found", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get swap request")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get the swap request", handlerLogger)
+		return
+	}
+	if request.Status != database.SwapRequestStatusPending {
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "The swap request was already "+request.Status, handlerLogger)
+		return
+	}
+	if action == "cancel" && req.Parent != request.Proposer {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Only "+request.Proposer+" can cancel this swap", handlerLogger)
+		return
+	}
+	if action != "cancel" && req.Parent != request.Responder {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Only "+request.Responder+" can "+action+" this swap", handlerLogger)
+		return
+	}
+
+	response := SwapAnswerResponse{Synced: true}
+	switch action {
+	case "accept":
+		response.Synced, err = h.acceptSwap(r.Context(), handlerLogger, request)
+	case "decline":
+		err = h.resolve(r.Context(), handlerLogger, request, database.SwapRequestStatusDeclined)
+	default:
+		err = h.resolve(r.Context(), handlerLogger, request, database.SwapRequestStatusCancelled)
+	}
+	switch {
+	case errors.Is(err, errSwapPastNight):
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "A night of the swap is past", handlerLogger)
+		return
+	case errors.Is(err, fairness.ErrAssignmentConflict), errors.Is(err, fairness.ErrAssignmentNotScheduled):
+		handlerLogger.Warn().Err(err).Msg("Nights changed since the swap was proposed")
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "A night of the swap changed since it was proposed, neither was swapped", handlerLogger)
+		return
+	case errors.Is(err, database.ErrSwapRequestResolved):
+		writeError(w, http.StatusConflict, apierror.CodeConflict, "The swap request was already answered", handlerLogger)
+		return
+	case err != nil:
+		handlerLogger.Error().Err(err).Msg("Failed to answer swap request")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to answer the swap request", handlerLogger)
+		return
+	}
+
+	if request, err = h.requests.GetSwapRequest(id); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to read back swap request")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get the swap request", handlerLogger)
+		return
+	}
+	response.SwapRequestResponse = newSwapRequestResponse(request)
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// acceptSwap swaps the nights of request, marks it accepted and tells the proposer, then
+// recalculates and syncs the schedule from the first night. It returns whether the sync succeeded.
+func (h *SwapHandler) acceptSwap(ctx context.Context, logger zerolog.Logger, request *database.SwapRequest) (bool, error) {
+	proposerDate := time.Date(request.ProposerDate.Year(), request.ProposerDate.Month(), request.ProposerDate.Day(), 0, 0, 0, 0, time.Local)
+	responderDate := time.Date(request.ResponderDate.Year(), request.ResponderDate.Month(), request.ResponderDate.Day(), 0, 0, 0, 0, time.Local)
+	now := h.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if proposerDate.Before(today) || responderDate.Before(today) {
+		return false, errSwapPastNight
+	}
+
+	// The swap and the recalculation following it are undone together
+	ctx = h.Tracker.BeginBatch(ctx, fairness.ChangeKindSwap)
+	if _, _, err := h.Tracker.SwapNights(ctx, fairness.NightSwap{
+		Date:        proposerDate,
+		Parent:      request.Proposer,
+		OtherDate:   responderDate,
+		OtherParent: request.Responder,
+	}); err != nil {
+		return false, err
+	}
+	if err := h.resolve(ctx, logger, request, database.SwapRequestStatusAccepted); err != nil {
+		return false, err
+	}
+
+	from := proposerDate
+	if responderDate.Before(from) {
+		from = responderDate
+	}
+	// The nights are swapped, a failing sync is retried by the next one
+	if err := recalculateScheduleAndSync(ctx, h.logger, h.SyncRuns, constants.SyncTriggerAssignment, h.Tracker, h.Scheduler, h.CalendarService, h.ConfigStore, from); err != nil {
+		logger.Error().Err(err).Msg("Failed to recalculate schedule after swapping nights")
+		return false, nil
+	}
+	return true, nil
+}
+
+// resolve sets the status of request and tells the proposer when the responder answered it
+func (h *SwapHandler) resolve(ctx context.Context, logger zerolog.Logger, request *database.SwapRequest, status string) error {
+	if err := h.requests.ResolveSwapRequest(request.ID, status, h.now()); err != nil {
+		return err
+	}
+	logger.Info().Str("status", status).Msg("Swap request answered")
+	if status != database.SwapRequestStatusCancelled {
+		h.notify(ctx, logger, notify.EventSwapAnswered, request, status == database.SwapRequestStatusAccepted)
+	}
+	return nil
+}
+
+// notify sends event about request; a failure is only logged
+func (h *SwapHandler) notify(ctx context.Context, logger zerolog.Logger, event notify.Event, request *database.SwapRequest, accepted bool) {
+	if h.notifier == nil {
+		return
+	}
+	if err := h.notifier.Send(ctx, event, notify.SwapData{
+		Proposer:      request.Proposer,
+		ProposerDate:  request.ProposerDate.Format(swapNotificationDateFormat),
+		Responder:     request.Responder,
+		ResponderDate: request.ResponderDate.Format(swapNotificationDateFormat),
+		Accepted:      accepted,
+		AppURL:        h.appURL,
+	}); err != nil {
+		logger.Warn().Err(err).Str("event", event.String()).Msg("Failed to notify the swap")
+	}
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// swapTestEnv holds a swap handler on an in-memory database with ParentA on the night in one day
+// and ParentB on the night in two days
+type swapTestEnv struct {
+	handler  *SwapHandler
+	tracker  *fairness.Tracker
+	requests *database.SwapRequestStore
+	notifier *recordingNotifier
+	first    time.Time // Night of ParentA
+	second   time.Time // Night of ParentB
+}
+
+func setupTestSwapHandler(t *testing.T, authenticated bool) *swapTestEnv {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	requests, err := database.NewSwapRequestStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	noopCfgStore := &noopConfigStore{}
+	notifier := &recordingNotifier{}
+	handler := NewSwapHandler(baseHandler, tracker, Scheduler.New(noopCfgStore, tracker), &noopCalendarService{}, noopCfgStore, requests, notifier, "https://night.example.com/")
+	now := time.Now()
+	handler.now = func() time.Time { return now }
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	env := &swapTestEnv{handler: handler, tracker: tracker, requests: requests, notifier: notifier, first: today.AddDate(0, 0, 1), second: today.AddDate(0, 0, 2)}
+	_, err = tracker.RecordAssignment(t.Context(), "ParentA", env.first, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "ParentB", env.second, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	return env
+}
+
+func (env *swapTestEnv) propose(body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/swap-requests", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	env.handler.handleSwapRequests(w, req)
+	return w
+}
+
+func (env *swapTestEnv) answer(id, action, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/swap-requests/"+id+"/"+action, strings.NewReader(body))
+	req.SetPathValue("id", id)
+	req.SetPathValue("action", action)
+	w := httptest.NewRecorder()
+	env.handler.handleSwapRequestAction(w, req)
+	return w
+}
+
+// proposeFirstForSecond has ParentA propose to give their night for the night of ParentB
+func (env *swapTestEnv) proposeFirstForSecond(t *testing.T) SwapRequestResponse {
+	w := env.propose(fmt.Sprintf(`{"parent":"ParentA","date":%q,"other_date":%q}`, env.first.Format(time.DateOnly), env.second.Format(time.DateOnly)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var response SwapRequestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func TestSwapHandler_ProposeAndAccept(t *testing.T) {
+	env := setupTestSwapHandler(t, true)
+
+	proposed := env.proposeFirstForSecond(t)
+	assert.Equal(t, "ParentA", proposed.Proposer)
+	assert.Equal(t, "ParentB", proposed.Responder)
+	assert.Equal(t, env.second.Format(time.DateOnly), proposed.ResponderDate)
+	assert.Equal(t, database.SwapRequestStatusPending, proposed.Status)
+	require.Equal(t, []notify.Event{notify.EventSwapProposed}, env.notifier.events)
+	assert.Equal(t, notify.SwapData{
+		Proposer:      "ParentA",
+		ProposerDate:  env.first.Format("Monday 2 January"),
+		Responder:     "ParentB",
+		ResponderDate: env.second.Format("Monday 2 January"),
+		AppURL:        "https://night.example.com",
+	}, env.notifier.data[0])
+
+	unchanged, err := env.tracker.GetAssignmentByDate(t.Context(), env.first)
+	require.NoError(t, err)
+	assert.Equal(t, "ParentA", unchanged.Parent, "nothing is swapped before the answer")
+
+	w := env.answer(fmt.Sprint(proposed.ID), "accept", `{"parent":"ParentB"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response SwapAnswerResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, database.SwapRequestStatusAccepted, response.Status)
+	assert.NotEmpty(t, response.ResolvedAt)
+	assert.True(t, response.Synced)
+
+	for date, parent := range map[time.Time]string{env.first: "ParentB", env.second: "ParentA"} {
+		swapped, err := env.tracker.GetAssignmentByDate(t.Context(), date)
+		require.NoError(t, err)
+		assert.Equal(t, parent, swapped.Parent)
+		assert.True(t, swapped.Override)
+		assert.Equal(t, fairness.DecisionReasonSwap, swapped.DecisionReason)
+	}
+	require.Equal(t, []notify.Event{notify.EventSwapProposed, notify.EventSwapAnswered}, env.notifier.events)
+	assert.True(t, env.notifier.data[1].(notify.SwapData).Accepted)
+
+	// The request is answered once
+	w = env.answer(fmt.Sprint(proposed.ID), "decline", `{"parent":"ParentB"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestSwapHandler_DeclineAndCancel(t *testing.T) {
+	env := setupTestSwapHandler(t, true)
+
+	declined := env.proposeFirstForSecond(t)
+	w := env.answer(fmt.Sprint(declined.ID), "decline", `{"parent":"ParentB"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, []notify.Event{notify.EventSwapProposed, notify.EventSwapAnswered}, env.notifier.events)
+	assert.False(t, env.notifier.data[1].(notify.SwapData).Accepted)
+
+	cancelled := env.proposeFirstForSecond(t)
+	w = env.answer(fmt.Sprint(cancelled.ID), "cancel", `{"parent":"ParentA"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, env.notifier.events, 3, "a cancelled swap notifies nobody")
+
+	requests, err := env.requests.ListSwapRequests(10)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, database.SwapRequestStatusCancelled, requests[0].Status)
+	assert.Equal(t, database.SwapRequestStatusDeclined, requests[1].Status)
+
+	night, err := env.tracker.GetAssignmentByDate(t.Context(), env.first)
+	require.NoError(t, err)
+	assert.Equal(t, "ParentA", night.Parent, "a declined swap changes nothing")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/swap-requests", nil)
+	list := httptest.NewRecorder()
+	env.handler.handleSwapRequests(list, req)
+	require.Equal(t, http.StatusOK, list.Code)
+	var response SwapRequestsResponse
+	require.NoError(t, json.Unmarshal(list.Body.Bytes(), &response))
+	require.Len(t, response.Requests, 2)
+	assert.Equal(t, cancelled.ID, response.Requests[0].ID, "newest first")
+}
+
+func TestSwapHandler_AcceptAfterNightChanged(t *testing.T) {
+	env := setupTestSwapHandler(t, true)
+	proposed := env.proposeFirstForSecond(t)
+
+	// ParentB's night was given to ParentA in the meantime
+	_, err := env.tracker.RecordAssignment(t.Context(), "ParentA", env.second, true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+
+	w := env.answer(fmt.Sprint(proposed.ID), "accept", `{"parent":"ParentB"}`)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	request, err := env.requests.GetSwapRequest(proposed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, database.SwapRequestStatusPending, request.Status, "a failed swap leaves the request pending")
+	night, err := env.tracker.GetAssignmentByDate(t.Context(), env.first)
+	require.NoError(t, err)
+	assert.Equal(t, "ParentA", night.Parent, "neither night is swapped")
+}
+
+func TestSwapHandler_ProposeErrors(t *testing.T) {
+	now := time.Now()
+	day := func(offset int) string { return now.AddDate(0, 0, offset).Format(time.DateOnly) }
+	tests := []struct {
+		name          string
+		authenticated bool
+		body          string
+		wantStatus    int
+	}{
+		{name: "unauthenticated", body: fmt.Sprintf(`{"parent":"ParentA","date":%q,"other_date":%q}`, day(1), day(2)), wantStatus: http.StatusUnauthorized},
+		{name: "invalid body", authenticated: true, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "missing parent", authenticated: true, body: fmt.Sprintf(`{"date":%q,"other_date":%q}`, day(1), day(2)), wantStatus: http.StatusBadRequest},
+		{name: "invalid date", authenticated: true, body: fmt.Sprintf(`{"parent":"ParentA","date":"tomorrow","other_date":%q}`, day(2)), wantStatus: http.StatusBadRequest},
+		{name: "same night", authenticated: true, body: fmt.Sprintf(`{"parent":"ParentA","date":%q,"other_date":%q}`, day(1), day(1)), wantStatus: http.StatusBadRequest},
+		{name: "unknown parent", authenticated: true, body: fmt.Sprintf(`{"parent":"Mallory","date":%q,"other_date":%q}`, day(1), day(2)), wantStatus: http.StatusBadRequest},
+		{name: "past night", authenticated: true, body: fmt.Sprintf(`{"parent":"ParentA","date":%q,"other_date":%q}`, day(-1), day(2)), wantStatus: http.StatusBadRequest},
+		{name: "night of another parent", authenticated: true, body: fmt.Sprintf(`{"parent":"ParentB","date":%q,"other_date":%q}`, day(1), day(2)), wantStatus: http.StatusBadRequest},
+		{name: "night not scheduled", authenticated: true, body: fmt.Sprintf(`{"parent":"ParentA","date":%q,"other_date":%q}`, day(1), day(5)), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupTestSwapHandler(t, tt.authenticated)
+			env.handler.now = func() time.Time { return now }
+
+			w := env.propose(tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Empty(t, env.notifier.events)
+			requests, err := env.requests.ListSwapRequests(10)
+			require.NoError(t, err)
+			assert.Empty(t, requests)
+		})
+	}
+}
+
+func TestSwapHandler_AnswerErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		action     string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid id", id: "abc", action: "accept", body: `{"parent":"ParentB"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown action", action: "approve", body: `{"parent":"ParentB"}`, wantStatus: http.StatusNotFound},
+		{name: "missing parent", action: "accept", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown request", id: "42", action: "accept", body: `{"parent":"ParentB"}`, wantStatus: http.StatusNotFound},
+		{name: "proposer accepting", action: "accept", body: `{"parent":"ParentA"}`, wantStatus: http.StatusBadRequest},
+		{name: "responder cancelling", action: "cancel", body: `{"parent":"ParentB"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupTestSwapHandler(t, true)
+			proposed := env.proposeFirstForSecond(t)
+			id := tt.id
+			if id == "" {
+				id = fmt.Sprint(proposed.ID)
+			}
+
+			w := env.answer(id, tt.action, tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			request, err := env.requests.GetSwapRequest(proposed.ID)
+			require.NoError(t, err)
+			assert.Equal(t, database.SwapRequestStatusPending, request.Status)
+			assert.Len(t, env.notifier.events, 1, "only the proposal is notified")
+		})
+	}
+}
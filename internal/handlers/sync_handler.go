@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/apierror"
 	"github.com/belphemur/night-routine/internal/calendar"
 	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/fairness/scheduler"
 	"github.com/belphemur/night-routine/internal/token"
 )
@@ -38,8 +40,8 @@ func NewSyncHandler(baseHandler *BaseHandler, scheduler *scheduler.Scheduler, to
 
 // RegisterRoutes registers sync related routes
 func (h *SyncHandler) RegisterRoutes() {
-	http.HandleFunc("/sync", h.handleManualSync)
-	http.HandleFunc("/api/sync", h.handleAPISync)
+	handleMethods(http.DefaultServeMux, "/sync", h.handleManualSync, http.MethodPost)
+	handleMethods(http.DefaultServeMux, "/api/sync", h.handleAPISync, http.MethodPost)
 }
 
 // SyncRequest represents the JSON request body for sync
@@ -50,9 +52,10 @@ type SyncRequest struct {
 
 // SyncResponse represents the JSON response for sync
 type SyncResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success bool          `json:"success"`
+	Message string        `json:"message,omitempty"`
+	Code    apierror.Code `json:"code,omitempty"` // Stable code of the error
+	Error   string        `json:"error,omitempty"`
 }
 
 // handleAPISync handles AJAX sync requests
@@ -68,6 +71,7 @@ func (h *SyncHandler) handleAPISync(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		if err := json.NewEncoder(w).Encode(SyncResponse{
 			Success: false,
+			Code:    apierror.CodeMethodNotAllowed,
 			Error:   "Method not allowed",
 		}); err != nil {
 			handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
@@ -82,6 +86,7 @@ func (h *SyncHandler) handleAPISync(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		if err := json.NewEncoder(w).Encode(SyncResponse{
 			Success: false,
+			Code:    apierror.CodeInvalidRequest,
 			Error:   "Invalid request body",
 		}); err != nil {
 			handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
@@ -100,6 +105,7 @@ func (h *SyncHandler) handleAPISync(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusBadRequest)
 			if err := json.NewEncoder(w).Encode(SyncResponse{
 				Success: false,
+				Code:    apierror.CodeInvalidRequest,
 				Error:   "Invalid start date format. Expected YYYY-MM-DD",
 			}); err != nil {
 				handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
@@ -120,6 +126,7 @@ func (h *SyncHandler) handleAPISync(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusUnauthorized)
 		if err := json.NewEncoder(w).Encode(SyncResponse{
 			Success: false,
+			Code:    apierror.CodeAuthenticationRequired,
 			Error:   "Sync prerequisites are not met. Please verify your authentication and calendar settings.",
 		}); err != nil {
 			handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
@@ -134,6 +141,7 @@ func (h *SyncHandler) handleAPISync(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		if err := json.NewEncoder(w).Encode(SyncResponse{
 			Success: false,
+			Code:    apierror.CodeSyncFailed,
 			Error:   "Sync failed. Please try again.",
 		}); err != nil {
 			handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
@@ -270,8 +278,17 @@ func (h *SyncHandler) updateSchedule(ctx context.Context) error {
 	return h.updateScheduleWithDate(ctx, time.Now())
 }
 
-// updateScheduleWithDate generates and syncs a new schedule starting from the specified date
+// updateScheduleWithDate generates and syncs a new schedule starting from the specified date,
+// recording the run in the sync history
 func (h *SyncHandler) updateScheduleWithDate(ctx context.Context, startDate time.Time) error {
+	return h.SyncRuns.RecordRun(ctx, constants.SyncTriggerManual, func(ctx context.Context) (int, error) {
+		return h.generateAndSync(ctx, startDate)
+	})
+}
+
+// generateAndSync generates and syncs a new schedule starting from the specified date.
+// It returns the number of assignments synced.
+func (h *SyncHandler) generateAndSync(ctx context.Context, startDate time.Time) (int, error) {
 	updateLogger := h.logger.With().Str("operation", "updateSchedule").Logger()
 	updateLogger.Info().Time("start_date", startDate).Msg("Starting schedule generation and sync")
 
@@ -281,13 +298,24 @@ func (h *SyncHandler) updateScheduleWithDate(ctx context.Context, startDate time
 	_, lookAheadDays, _, _, err := h.ConfigStore.GetSchedule()
 	if err != nil {
 		updateLogger.Error().Err(err).Msg("Failed to get schedule configuration")
-		return fmt.Errorf("failed to get schedule configuration: %w", err)
+		return 0, fmt.Errorf("failed to get schedule configuration: %w", err)
+	}
+	windows, err := h.ConfigStore.GetLookAheadWindows()
+	if err != nil {
+		updateLogger.Error().Err(err).Msg("Failed to get look-ahead windows")
+		return 0, fmt.Errorf("failed to get look-ahead windows: %w", err)
 	}
+	lookAheadDays = windows.Days(constants.SyncTriggerManual, lookAheadDays)
 
 	// Calculate date range
 	end := startDate.AddDate(0, 0, lookAheadDays)
 	updateLogger.Debug().Time("start_date", startDate).Time("end_date", end).Int("lookahead_days", lookAheadDays).Msg("Calculated date range")
 
+	// The keyword events of the personal calendars of the parents make them unavailable
+	if err := h.CalendarService.SyncAvailability(ctx, startDate, end); err != nil {
+		updateLogger.Warn().Err(err).Msg("Failed to sync the availability of the parents from their calendars")
+	}
+
 	// Generate schedule.
 	// We intentionally use startDate as both the schedule start and the currentTime:
 	//   - startDate represents the user's "today" (in UTC) when they trigger a manual sync.
@@ -296,11 +324,11 @@ func (h *SyncHandler) updateScheduleWithDate(ctx context.Context, startDate time
 	// By passing startDate as currentTime, all assignments from startDate onward are treated
 	// as current/future and can be recalculated if needed.
 	updateLogger.Debug().Msg("Generating schedule")
-	assignments, err := h.Scheduler.GenerateSchedule(startDate, end, startDate)
+	assignments, err := h.Scheduler.GenerateSchedule(ctx, startDate, end, startDate)
 	if err != nil {
 		updateLogger.Error().Err(err).Msg("Failed to generate schedule")
 		// Wrap error for context
-		return fmt.Errorf("failed to generate schedule: %w", err)
+		return 0, fmt.Errorf("failed to generate schedule: %w", err)
 	}
 	updateLogger.Info().Int("assignments_generated", len(assignments)).Msg("Schedule generated successfully")
 
@@ -309,12 +337,12 @@ func (h *SyncHandler) updateScheduleWithDate(ctx context.Context, startDate time
 	if err := h.CalendarService.SyncSchedule(ctx, assignments); err != nil {
 		updateLogger.Error().Err(err).Msg("Failed to sync schedule with calendar")
 		// Wrap error for context
-		return fmt.Errorf("failed to sync calendar: %w", err)
+		return len(assignments), fmt.Errorf("failed to sync calendar: %w", err)
 	}
 
 	updateLogger.Info().
 		Int("days", lookAheadDays).
 		Int("assignments", len(assignments)).
 		Msg("Schedule update and sync completed successfully")
-	return nil
+	return len(assignments), nil
 }
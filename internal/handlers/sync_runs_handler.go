@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/signals"
+)
+
+// Bounds of the number of sync runs returned by the API
+const (
+	defaultSyncRunsLimit = 20
+	maxSyncRunsLimit     = 200
+)
+
+// Streaming of the sync progress
+const (
+	syncProgressBuffer    = 64               // Progress events queued for a slow client, the newer ones dropped beyond
+	syncProgressKeepAlive = 30 * time.Second // Interval of the comments keeping idle streams open through proxies
+)
+
+// syncProgressStreams numbers the progress streams, naming their SyncProgress listeners
+var syncProgressStreams atomic.Int64
+
+// SyncRunsHandler exposes the history of schedule syncs
+type SyncRunsHandler struct {
+	*BaseHandler
+}
+
+// NewSyncRunsHandler creates a new sync runs handler
+func NewSyncRunsHandler(baseHandler *BaseHandler) *SyncRunsHandler {
+	return &SyncRunsHandler{BaseHandler: baseHandler}
+}
+
+// RegisterRoutes registers the sync history routes
+func (h *SyncRunsHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/sync-runs", h.handleListSyncRuns, http.MethodGet)
+	handleMethods(http.DefaultServeMux, "/api/v1/sync-runs/progress", h.handleSyncProgress, http.MethodGet)
+}
+
+// SyncRunResponse is one sync run in the API response
+type SyncRunResponse struct {
+	ID               int64      `json:"id"`
+	Trigger          string     `json:"trigger"`
+	Status           string     `json:"status"`
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at"`
+	DurationMs       int64      `json:"duration_ms"`
+	AssignmentsCount int        `json:"assignments_count"`
+	Error            string     `json:"error,omitempty"`
+	EventsTotal      int        `json:"events_total"`
+	EventsProcessed  int        `json:"events_processed"`
+	EventsFailed     int        `json:"events_failed"`
+}
+
+// SyncRunsResponse is the response of the sync history API
+type SyncRunsResponse struct {
+	Runs []SyncRunResponse `json:"runs"`
+}
+
+// newSyncRunResponse converts a stored sync run to its API representation
+func newSyncRunResponse(run *database.SyncRun) SyncRunResponse {
+	return SyncRunResponse{
+		ID:               run.ID,
+		Trigger:          run.Trigger.String(),
+		Status:           run.Status,
+		StartedAt:        run.StartedAt,
+		FinishedAt:       run.FinishedAt,
+		DurationMs:       run.Duration().Milliseconds(),
+		AssignmentsCount: run.AssignmentsCount,
+		Error:            run.Error,
+		EventsTotal:      run.EventsTotal,
+		EventsProcessed:  run.EventsProcessed,
+		EventsFailed:     run.EventsFailed,
+	}
+}
+
+// handleListSyncRuns returns the most recent sync runs, newest first.
+// The optional limit query parameter caps the number of runs (default 20, at most 200).
+func (h *SyncRunsHandler) handleListSyncRuns(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleListSyncRuns").Logger()
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	limit := defaultSyncRunsLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > maxSyncRunsLimit {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "limit must be a number between 1 and 200", handlerLogger)
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := h.SyncRuns.ListRuns(limit)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to list sync runs")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve sync runs", handlerLogger)
+		return
+	}
+
+	response := SyncRunsResponse{Runs: make([]SyncRunResponse, 0, len(runs))}
+	for _, run := range runs {
+		response.Runs = append(response.Runs, newSyncRunResponse(run))
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
+
+// handleSyncProgress streams the progress of the running syncs as server-sent events, a progress
+// event per assignment processed and a last one once the sync ends with its status, until the
+// client disconnects
+func (h *SyncRunsHandler) handleSyncProgress(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleSyncProgress").Logger()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Streaming not supported", handlerLogger)
+		return
+	}
+
+	// The listener never blocks the sync: the events a slow client cannot take are dropped
+	events := make(chan signals.SyncProgressData, syncProgressBuffer)
+	key := fmt.Sprintf("sync-progress-stream-%d", syncProgressStreams.Add(1))
+	signals.OnSyncProgress(func(_ context.Context, data signals.SyncProgressData) {
+		select {
+		case events <- data:
+		default:
+		}
+	}, key)
+	defer signals.SyncProgress.RemoveListener(key)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(syncProgressKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case data := <-events:
+			payload, err := json.Marshal(data)
+			if err != nil {
+				handlerLogger.Error().Err(err).Msg("Failed to encode sync progress")
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: progress\ndata: %s\n\n", payload); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
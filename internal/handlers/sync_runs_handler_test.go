@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestSyncRunsHandler(t *testing.T) (*SyncRunsHandler, *database.SyncRunStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        filepath.Join(t.TempDir(), "sync_runs.db"),
+		Mode:        "rwc",
+		Cache:       database.CachePrivate,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	syncRuns, err := database.NewSyncRunStore(db)
+	require.NoError(t, err)
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	tokenManager := token.NewTokenManager(tokenStore, oauthCfg)
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(configStore, oauthCfg), tokenStore, tokenManager, tracker, syncRuns)
+	require.NoError(t, err)
+
+	return NewSyncRunsHandler(baseHandler), syncRuns
+}
+
+func TestSyncRunsHandler_List(t *testing.T) {
+	handler, syncRuns := setupTestSyncRunsHandler(t)
+	require.NoError(t, syncRuns.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 14, nil }))
+	require.Error(t, syncRuns.RecordRun(context.Background(), constants.SyncTriggerWebhook, func(context.Context) (int, error) { return 0, errors.New("token revoked") }))
+
+	w := httptest.NewRecorder()
+	handler.handleListSyncRuns(w, httptest.NewRequest(http.MethodGet, "/api/v1/sync-runs", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var resp SyncRunsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Runs, 2)
+
+	assert.Equal(t, "webhook", resp.Runs[0].Trigger)
+	assert.Equal(t, database.SyncRunStatusFailed, resp.Runs[0].Status)
+	assert.Equal(t, "token revoked", resp.Runs[0].Error)
+
+	assert.Equal(t, "scheduled", resp.Runs[1].Trigger)
+	assert.Equal(t, database.SyncRunStatusSuccess, resp.Runs[1].Status)
+	assert.Equal(t, 14, resp.Runs[1].AssignmentsCount)
+	assert.NotNil(t, resp.Runs[1].FinishedAt)
+}
+
+func TestSyncRunsHandler_Empty(t *testing.T) {
+	handler, _ := setupTestSyncRunsHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.handleListSyncRuns(w, httptest.NewRequest(http.MethodGet, "/api/v1/sync-runs", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"runs":[]}`, w.Body.String())
+}
+
+func TestSyncRunsHandler_Limit(t *testing.T) {
+	handler, syncRuns := setupTestSyncRunsHandler(t)
+	for range 3 {
+		require.NoError(t, syncRuns.RecordRun(context.Background(), constants.SyncTriggerManual, func(context.Context) (int, error) { return 1, nil }))
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedRuns int
+	}{
+		{name: "limit applied", query: "?limit=2", expectedCode: http.StatusOK, expectedRuns: 2},
+		{name: "not a number", query: "?limit=abc", expectedCode: http.StatusBadRequest},
+		{name: "zero", query: "?limit=0", expectedCode: http.StatusBadRequest},
+		{name: "too large", query: "?limit=1000", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleListSyncRuns(w, httptest.NewRequest(http.MethodGet, "/api/v1/sync-runs"+tt.query, nil))
+
+			require.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var resp SyncRunsResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Len(t, resp.Runs, tt.expectedRuns)
+		})
+	}
+}
+
+func TestSyncRunsHandler_MethodNotAllowed(t *testing.T) {
+	handler, _ := setupTestSyncRunsHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.handleListSyncRuns(w, httptest.NewRequest(http.MethodPost, "/api/v1/sync-runs", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestSyncRunsHandler_Progress(t *testing.T) {
+	handler, syncRuns := setupTestSyncRunsHandler(t)
+	server := httptest.NewServer(http.HandlerFunc(handler.handleSyncProgress))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The stream listens once its headers are sent
+	require.NoError(t, syncRuns.RecordRun(context.Background(), constants.SyncTriggerManual, func(ctx context.Context) (int, error) {
+		signals.ReportSyncTotal(ctx, 2)
+		signals.ReportSyncStep(ctx, nil)
+		signals.ReportSyncStep(ctx, nil)
+		return 2, nil
+	}))
+
+	var events []signals.SyncProgressData
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < 4 && scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event signals.SyncProgressData
+		require.NoError(t, json.Unmarshal([]byte(data), &event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 4)
+	assert.Equal(t, signals.SyncStatusRunning, events[0].Status)
+	assert.Equal(t, 2, events[0].Total)
+	assert.Equal(t, 1, events[1].Processed)
+	last := events[3]
+	assert.Equal(t, "manual", last.Trigger)
+	assert.Equal(t, database.SyncRunStatusSuccess, last.Status)
+	assert.Equal(t, 2, last.Processed)
+	assert.NotZero(t, last.RunID)
+}
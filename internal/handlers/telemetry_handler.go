@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/telemetry"
+)
+
+// TelemetryCollector builds the anonymous usage report, implemented by telemetry.Collector
+type TelemetryCollector interface {
+	Collect(ctx context.Context) (telemetry.Report, error)
+}
+
+// TelemetryHandler previews the usage report, sent only once the telemetry is enabled in the
+// configuration
+type TelemetryHandler struct {
+	*BaseHandler
+	collector TelemetryCollector
+	settings  config.TelemetryConfig
+}
+
+// NewTelemetryHandler creates a new telemetry handler
+func NewTelemetryHandler(baseHandler *BaseHandler, collector TelemetryCollector, settings config.TelemetryConfig) *TelemetryHandler {
+	return &TelemetryHandler{
+		BaseHandler: baseHandler,
+		collector:   collector,
+		settings:    settings,
+	}
+}
+
+// RegisterRoutes registers the telemetry route
+func (h *TelemetryHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/admin/telemetry", h.handleTelemetry, http.MethodGet)
+}
+
+// TelemetryResponse is the response of the telemetry API
+type TelemetryResponse struct {
+	Enabled  bool             `json:"enabled"`
+	Endpoint string           `json:"endpoint"` // Empty while no endpoint is configured
+	Interval string           `json:"interval"`
+	Report   telemetry.Report `json:"report"` // Exactly what is sent on the next report
+}
+
+// handleTelemetry returns whether the telemetry is enabled and the report it sends, built now. The
+// report is built while disabled too, so it can be checked before opting in.
+func (h *TelemetryHandler) handleTelemetry(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleTelemetry").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	report, err := h.collector.Collect(r.Context())
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to build the telemetry report")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to build the telemetry report", handlerLogger)
+		return
+	}
+	writeJSON(w, http.StatusOK, TelemetryResponse{
+		Enabled:  h.settings.Enabled,
+		Endpoint: h.settings.Endpoint,
+		Interval: h.settings.Interval.String(),
+		Report:   report,
+	}, handlerLogger)
+}
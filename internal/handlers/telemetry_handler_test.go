@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/telemetry"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// stubTelemetryCollector returns a fixed report, or err
+type stubTelemetryCollector struct {
+	report telemetry.Report
+	err    error
+}
+
+func (c stubTelemetryCollector) Collect(context.Context) (telemetry.Report, error) {
+	return c.report, c.err
+}
+
+func newTestTelemetryHandler(t *testing.T, authenticated bool, collector TelemetryCollector) *TelemetryHandler {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+	return NewTelemetryHandler(baseHandler, collector, config.TelemetryConfig{Interval: 7 * 24 * time.Hour})
+}
+
+func TestTelemetryHandler(t *testing.T) {
+	report := telemetry.Report{Version: "1.2.3", DatabaseSize: "<1MB", Features: []string{}, FeatureFlags: []string{"webhook_polling"}, PeriodDays: 7, Syncs: 10, SyncErrorRate: 0.1}
+	handler := newTestTelemetryHandler(t, true, stubTelemetryCollector{report: report})
+
+	w := httptest.NewRecorder()
+	handler.handleTelemetry(w, httptest.NewRequest(http.MethodGet, "/api/admin/telemetry", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response TelemetryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Enabled, "the report is previewed while disabled")
+	assert.Equal(t, "168h0m0s", response.Interval)
+	assert.Equal(t, report, response.Report)
+
+	handler = newTestTelemetryHandler(t, true, stubTelemetryCollector{err: errors.New("database is locked")})
+	w = httptest.NewRecorder()
+	handler.handleTelemetry(w, httptest.NewRequest(http.MethodGet, "/api/admin/telemetry", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestTelemetryHandler_Unauthenticated(t *testing.T) {
+	handler := newTestTelemetryHandler(t, false, stubTelemetryCollector{})
+
+	w := httptest.NewRecorder()
+	handler.handleTelemetry(w, httptest.NewRequest(http.MethodGet, "/api/admin/telemetry", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
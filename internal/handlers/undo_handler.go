@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+)
+
+// UndoHandler reverts the last change of the schedule
+type UndoHandler struct {
+	*BaseHandler
+	Tracker         fairness.TrackerInterface
+	Scheduler       Scheduler.SchedulerInterface
+	CalendarService calendar.CalendarService
+}
+
+// NewUndoHandler creates a new handler undoing the last schedule change
+func NewUndoHandler(baseHandler *BaseHandler, tracker fairness.TrackerInterface, sched Scheduler.SchedulerInterface, calSvc calendar.CalendarService) *UndoHandler {
+	return &UndoHandler{
+		BaseHandler:     baseHandler,
+		Tracker:         tracker,
+		Scheduler:       sched,
+		CalendarService: calSvc,
+	}
+}
+
+// RegisterRoutes registers the undo routes
+func (h *UndoHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/admin/undo", h.handleUndo, http.MethodPost)
+}
+
+// UndoneAssignmentResponse is an assignment restored by the undo
+type UndoneAssignmentResponse struct {
+	AssignmentID  int64  `json:"assignment_id"`
+	Date          string `json:"date"`
+	Caregiver     string `json:"caregiver"`
+	CaregiverType string `json:"caregiver_type"`
+	Override      bool   `json:"override"`
+}
+
+// UndoResponse is the JSON response of an undo
+type UndoResponse struct {
+	BatchID     int64                      `json:"batch_id"`
+	Kind        string                     `json:"kind"` // override, swap, regeneration, unlock or edit
+	ChangedAt   string                     `json:"changed_at"`
+	Assignments []UndoneAssignmentResponse `json:"assignments"`
+	Synced      bool                       `json:"synced"` // False when the calendar could not be synced, the next sync catches up
+}
+
+// handleUndo reverts the most recent batch of assignment changes, such as an override with the
+// regeneration it triggered, and resyncs the events of the restored assignments. Calling it again
+// reverts the batch before.
+func (h *UndoHandler) handleUndo(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleUndo").Logger()
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	batch, err := h.Tracker.UndoLastBatch(r.Context())
+	if errors.Is(err, fairness.ErrNothingToUndo) {
+		writeError(w, http.StatusNotFound, apierror.CodeNotFound, "Nothing to undo", handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to undo the last schedule change")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to undo the last schedule change", handlerLogger)
+		return
+	}
+	handlerLogger = handlerLogger.With().Int64("batch_id", batch.ID).Str("kind", batch.Kind.String()).Logger()
+	handlerLogger.Info().Int("assignments", len(batch.Assignments)).Msg("Last schedule change undone")
+
+	response := UndoResponse{
+		BatchID:     batch.ID,
+		Kind:        batch.Kind.String(),
+		ChangedAt:   batch.CreatedAt.Format(time.RFC3339),
+		Assignments: make([]UndoneAssignmentResponse, 0, len(batch.Assignments)),
+		Synced:      true,
+	}
+	for _, a := range batch.Assignments {
+		response.Assignments = append(response.Assignments, UndoneAssignmentResponse{
+			AssignmentID:  a.ID,
+			Date:          a.Date.Format(time.DateOnly),
+			Caregiver:     a.Parent,
+			CaregiverType: a.CaregiverType.String(),
+			Override:      a.Override,
+		})
+	}
+
+	// The assignments are restored, a failing sync is retried by the next one
+	if err := h.SyncRuns.RecordRun(r.Context(), constants.SyncTriggerUndo, func(ctx context.Context) (int, error) {
+		return h.syncRestored(ctx, batch.Assignments)
+	}); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to sync the restored assignments")
+		response.Synced = false
+	}
+
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
+
+// syncRestored syncs the events of the restored assignments that have one, and returns their number
+func (h *UndoHandler) syncRestored(ctx context.Context, restored []*fairness.Assignment) (int, error) {
+	if len(restored) == 0 {
+		return 0, nil
+	}
+	// restored is sorted by date
+	assignments, err := h.Scheduler.GetAssignmentsInRange(ctx, restored[0].Date, restored[len(restored)-1].Date)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get restored assignments: %w", err)
+	}
+	restoredIDs := make(map[int64]bool, len(restored))
+	for _, a := range restored {
+		restoredIDs[a.ID] = true
+	}
+
+	var withEventIDs []*Scheduler.Assignment
+	for _, a := range assignments {
+		if restoredIDs[a.ID] && a.GoogleCalendarEventID != "" {
+			withEventIDs = append(withEventIDs, a)
+		}
+	}
+	if len(withEventIDs) == 0 {
+		return 0, nil
+	}
+	if err := h.CalendarService.SyncSchedule(ctx, withEventIDs); err != nil {
+		return len(withEventIDs), fmt.Errorf("failed to sync schedule: %w", err)
+	}
+	return len(withEventIDs), nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// syncedAssignmentsRecorder records the assignments synced with the calendar
+type syncedAssignmentsRecorder struct {
+	noopCalendarService
+	synced []*Scheduler.Assignment
+}
+
+func (r *syncedAssignmentsRecorder) SyncSchedule(_ context.Context, assignments []*Scheduler.Assignment) error {
+	r.synced = append(r.synced, assignments...)
+	return nil
+}
+
+func setupTestUndoHandler(t *testing.T, authenticated bool) (*UndoHandler, *fairness.Tracker, *syncedAssignmentsRecorder) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	if authenticated {
+		require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	}
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+
+	calSvc := &syncedAssignmentsRecorder{}
+	handler := NewUndoHandler(baseHandler, tracker, Scheduler.New(&noopConfigStore{}, tracker), calSvc)
+	return handler, tracker, calSvc
+}
+
+func undo(handler *UndoHandler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/undo", nil)
+	w := httptest.NewRecorder()
+	handler.handleUndo(w, req)
+	return w
+}
+
+func TestUndoHandler_RevertsLastOverride(t *testing.T) {
+	handler, tracker, calSvc := setupTestUndoHandler(t, true)
+
+	date := testCurrentDate()
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", date, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "event-1"))
+	require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), assignment.ID, "ParentB", true, assignment.Version))
+
+	w := undo(handler)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response UndoResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "override", response.Kind)
+	assert.True(t, response.Synced)
+	assert.Equal(t, []UndoneAssignmentResponse{{
+		AssignmentID:  assignment.ID,
+		Date:          date.Format(time.DateOnly),
+		Caregiver:     "ParentA",
+		CaregiverType: "parent",
+		Override:      false,
+	}}, response.Assignments)
+
+	restored, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ParentA", restored.Parent)
+	assert.False(t, restored.Override)
+
+	require.Len(t, calSvc.synced, 1, "the event of the restored assignment is resynced")
+	assert.Equal(t, "ParentA", calSvc.synced[0].Parent)
+
+	w = undo(handler)
+	assert.Equal(t, http.StatusNotFound, w.Code, "the creation of the assignment cannot be undone")
+}
+
+func TestUndoHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		authenticated bool
+		expected      int
+	}{
+		{name: "wrong method", method: http.MethodGet, authenticated: true, expected: http.StatusMethodNotAllowed},
+		{name: "unauthenticated", method: http.MethodPost, authenticated: false, expected: http.StatusUnauthorized},
+		{name: "nothing to undo", method: http.MethodPost, authenticated: true, expected: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, _ := setupTestUndoHandler(t, tt.authenticated)
+			req := httptest.NewRequest(tt.method, "/api/admin/undo", nil)
+			w := httptest.NewRecorder()
+			handler.handleUndo(w, req)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
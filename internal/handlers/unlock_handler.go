@@ -8,6 +8,7 @@ import (
 
 	"github.com/belphemur/night-routine/internal/calendar"
 	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/fairness"
 	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
 )
@@ -35,7 +36,7 @@ func NewUnlockHandler(baseHandler *BaseHandler, tracker fairness.TrackerInterfac
 
 // RegisterRoutes registers unlock related routes
 func (h *UnlockHandler) RegisterRoutes() {
-	http.HandleFunc("/unlock", h.handleUnlock)
+	handleMethods(http.DefaultServeMux, "/unlock", h.handleUnlock, http.MethodPost)
 }
 
 // handleUnlock handles the request to unlock an overridden assignment
@@ -78,7 +79,7 @@ func (h *UnlockHandler) handleUnlock(w http.ResponseWriter, r *http.Request) {
 	handlerLogger = handlerLogger.With().Int64("assignment_id", assignmentID).Logger()
 	handlerLogger.Debug().Msg("Attempting to unlock assignment")
 
-	assignment, err := h.Tracker.GetAssignmentByID(assignmentID)
+	assignment, err := h.Tracker.GetAssignmentByID(r.Context(), assignmentID)
 	if err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to get assignment")
 		http.Redirect(w, r, "/?error="+ErrCodeUnlockFailed, http.StatusSeeOther)
@@ -98,7 +99,10 @@ func (h *UnlockHandler) handleUnlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.Tracker.UnlockAssignment(assignmentID); err != nil {
+	// The unlock and the recalculation following it are undone together
+	ctx := h.Tracker.BeginBatch(r.Context(), fairness.ChangeKindUnlock)
+
+	if err := h.Tracker.UnlockAssignment(ctx, assignmentID); err != nil {
 		handlerLogger.Error().Err(err).Msg("Failed to unlock assignment")
 		http.Redirect(w, r, "/?error="+ErrCodeUnlockFailed, http.StatusSeeOther)
 		return
@@ -107,7 +111,7 @@ func (h *UnlockHandler) handleUnlock(w http.ResponseWriter, r *http.Request) {
 	handlerLogger.Info().Msg("Assignment unlocked successfully, triggering schedule recalculation")
 
 	// Recalculate and sync the schedule so the calendar reflects the removal of the override.
-	if err := h.recalculateSchedule(r.Context(), assignment.Date); err != nil {
+	if err := h.recalculateSchedule(ctx, assignment.Date); err != nil {
 		// Log but don't fail the redirect — the DB is already correct.
 		handlerLogger.Error().Err(err).Msg("Failed to recalculate schedule after unlock")
 	}
@@ -120,6 +124,8 @@ func (h *UnlockHandler) recalculateSchedule(ctx context.Context, fromDate time.T
 	return recalculateScheduleAndSync(
 		ctx,
 		h.logger,
+		h.SyncRuns,
+		constants.SyncTriggerAssignment,
 		h.Tracker,
 		h.Scheduler,
 		h.CalendarService,
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/database"
 	"github.com/belphemur/night-routine/internal/fairness"
@@ -18,6 +19,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
+	gcalendar "google.golang.org/api/calendar/v3"
 )
 
 // noopCalendarService is a minimal CalendarService stub that does nothing.
@@ -25,10 +27,14 @@ type noopCalendarService struct{}
 
 func (n *noopCalendarService) Initialize(_ context.Context) error               { return nil }
 func (n *noopCalendarService) IsInitialized() bool                              { return true }
+func (n *noopCalendarService) Reset()                                           {}
 func (n *noopCalendarService) SetupNotificationChannel(_ context.Context) error { return nil }
 func (n *noopCalendarService) SyncSchedule(_ context.Context, _ []*Scheduler.Assignment) error {
 	return nil
 }
+func (n *noopCalendarService) RemoveEvents(_ context.Context, _ []string) error { return nil }
+func (n *noopCalendarService) RemoveAllEvents(_ context.Context) (int, error)   { return 0, nil }
+func (n *noopCalendarService) IsOwnUpdate(_ *gcalendar.Event) bool              { return false }
 func (n *noopCalendarService) StopNotificationChannel(_ context.Context, _, _ string) error {
 	return nil
 }
@@ -36,17 +42,38 @@ func (n *noopCalendarService) StopAllNotificationChannels(_ context.Context) err
 func (n *noopCalendarService) VerifyNotificationChannel(_ context.Context, _, _ string) (bool, error) {
 	return true, nil
 }
+func (n *noopCalendarService) RenewNotificationChannel(_ context.Context, _ string) error { return nil }
+func (n *noopCalendarService) RecreateNotificationChannel(_ context.Context, _ string) error {
+	return nil
+}
 
 // noopConfigStore is a minimal ConfigStoreInterface stub that returns safe defaults.
 type noopConfigStore struct{}
 
-func (n *noopConfigStore) GetParents() (string, string, error) { return "ParentA", "ParentB", nil }
+func (n *noopConfigStore) GetParents() (string, string, error)            { return "ParentA", "ParentB", nil }
+func (n *noopConfigStore) GetExtraParents() ([]config.ExtraParent, error) { return nil, nil }
 func (n *noopConfigStore) GetAvailability(_ string) ([]string, error) {
 	return []string{}, nil
 }
 func (n *noopConfigStore) GetSchedule() (string, int, int, constants.StatsOrder, error) {
 	return "daily", 30, 7, constants.StatsOrderDesc, nil
 }
+func (n *noopConfigStore) GetUnavailabilityRules(_ string) ([]config.UnavailabilityRule, error) {
+	return nil, nil
+}
+func (n *noopConfigStore) GetLookAheadWindows() (config.LookAheadWindows, error) {
+	return config.LookAheadWindows{}, nil
+}
+func (n *noopConfigStore) GetVacation() (config.Vacation, error)   { return config.Vacation{}, nil }
+func (n *noopConfigStore) GetSkipDates() (config.SkipDates, error) { return nil, nil }
+func (n *noopConfigStore) GetSyncExclusions() (config.SyncExclusions, error) {
+	return config.SyncExclusions{}, nil
+}
+func (n *noopConfigStore) GetMinRestDays() (int, error)              { return 0, nil }
+func (n *noopConfigStore) GetDayWeights() (config.DayWeights, error) { return nil, nil }
+func (n *noopConfigStore) GetParentStyles() (config.ParentStyle, config.ParentStyle, error) {
+	return config.ParentStyle{}, config.ParentStyle{}, nil
+}
 func (n *noopConfigStore) GetOAuthConfig() *oauth2.Config { return &oauth2.Config{} }
 
 func setupTestUnlockHandler(t *testing.T, authenticated bool) (*UnlockHandler, *fairness.Tracker, *database.DB, func()) {
@@ -97,7 +124,7 @@ func setupTestUnlockHandler(t *testing.T, authenticated bool) (*UnlockHandler, *
 	configAdapter := database.NewConfigAdapter(nil, oauthCfg)
 
 	// Create base handler
-	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, "test-version", "test-logo-version")
+	baseHandler, err := NewBaseHandler(configAdapter, tokenStore, tokenManager, tracker, nil)
 	require.NoError(t, err)
 
 	// Create unlock handler with a real lightweight scheduler backed by noopConfigStore.
@@ -190,7 +217,7 @@ func TestUnlockHandler_HandleUnlock_NotOverridden(t *testing.T) {
 	defer cleanup()
 
 	// Create a non-overridden assignment
-	assignment, err := tracker.RecordAssignment("ParentA", time.Now(), false, fairness.DecisionReasonAlternating)
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Now(), false, fairness.DecisionReasonAlternating)
 	require.NoError(t, err)
 
 	formData := url.Values{}
@@ -211,7 +238,7 @@ func TestUnlockHandler_HandleUnlock_Success(t *testing.T) {
 	defer cleanup()
 
 	// Create an overridden assignment
-	assignment, err := tracker.RecordAssignment("ParentA", time.Now(), true, fairness.DecisionReasonOverride)
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Now(), true, fairness.DecisionReasonOverride)
 	require.NoError(t, err)
 
 	formData := url.Values{}
@@ -227,7 +254,7 @@ func TestUnlockHandler_HandleUnlock_Success(t *testing.T) {
 	assert.Contains(t, w.Header().Get("Location"), "success="+SuccessCodeAssignmentUnlocked)
 
 	// Verify it is no longer overridden
-	updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+	updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	require.NoError(t, err)
 	assert.False(t, updatedAssignment.Override)
 }
@@ -237,10 +264,10 @@ func TestUnlockHandler_HandleUnlock_BabysitterOverrideSuccess(t *testing.T) {
 	defer cleanup()
 
 	// Create an overridden assignment and convert it to babysitter to mirror UI flow.
-	assignment, err := tracker.RecordAssignment("ParentA", time.Now(), true, fairness.DecisionReasonOverride)
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Now(), true, fairness.DecisionReasonOverride)
 	require.NoError(t, err)
 
-	err = tracker.UpdateAssignmentToBabysitter(assignment.ID, "Dawn", true)
+	err = tracker.UpdateAssignmentToBabysitter(t.Context(), assignment.ID, "Dawn", true, assignment.Version)
 	require.NoError(t, err)
 
 	formData := url.Values{}
@@ -255,7 +282,7 @@ func TestUnlockHandler_HandleUnlock_BabysitterOverrideSuccess(t *testing.T) {
 	assert.Equal(t, http.StatusSeeOther, w.Code)
 	assert.Contains(t, w.Header().Get("Location"), "success="+SuccessCodeAssignmentUnlocked)
 
-	updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+	updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 	require.NoError(t, err)
 	// UnlockAssignment clears override, babysitter metadata, and restores parent caregiver type.
 	// The subsequent schedule recalculation overwrites parent_name with a real parent.
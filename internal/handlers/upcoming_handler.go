@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+// Bounds of the number of days returned by the upcoming API
+const (
+	defaultUpcomingDays = 7
+	maxUpcomingDays     = 31
+)
+
+// UpcomingHandler returns the assignments of the next days for dashboards
+type UpcomingHandler struct {
+	*BaseHandler
+	now func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewUpcomingHandler creates a new upcoming assignments handler
+func NewUpcomingHandler(baseHandler *BaseHandler) *UpcomingHandler {
+	return &UpcomingHandler{BaseHandler: baseHandler, now: time.Now}
+}
+
+// RegisterRoutes registers the upcoming assignments routes
+func (h *UpcomingHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/upcoming", h.handleUpcoming, http.MethodGet)
+}
+
+// UpcomingAssignmentResponse is the assignment of a day in the upcoming API response
+type UpcomingAssignmentResponse struct {
+	ID             int64  `json:"id"`
+	Caregiver      string `json:"caregiver"`
+	CaregiverType  string `json:"caregiver_type"`
+	Override       bool   `json:"override"`
+	DecisionReason string `json:"decision_reason"`
+	Synced         bool   `json:"synced"`           // The assignment has an event in Google Calendar
+	Color          string `json:"color,omitempty"`  // Color of the parent as #rrggbb, absent for a babysitter
+	Avatar         string `json:"avatar,omitempty"` // Avatar or initial of the parent, absent for a babysitter
+}
+
+// UpcomingDayResponse is a day of the upcoming API response
+type UpcomingDayResponse struct {
+	Date       string                      `json:"date"`
+	Weekday    string                      `json:"weekday"`
+	Assignment *UpcomingAssignmentResponse `json:"assignment"` // null when the day is not scheduled yet
+}
+
+// UpcomingResponse is the response of the upcoming API
+type UpcomingResponse struct {
+	From     string                `json:"from"`
+	To       string                `json:"to"`
+	Days     []UpcomingDayResponse `json:"days"`
+	LastSync *SyncRunResponse      `json:"last_sync"` // null before the first sync
+}
+
+// handleUpcoming returns every day from today with its assignment, override and sync status, and the
+// latest sync run, in one payload for the home page and external dashboards. The optional days query
+// parameter sets the number of days, today included (default 7, at most 31).
+func (h *UpcomingHandler) handleUpcoming(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleUpcoming").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	days := defaultUpcomingDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > maxUpcomingDays {
+			writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "days must be a number between 1 and 31", handlerLogger)
+			return
+		}
+		days = parsed
+	}
+
+	now := h.now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	to := from.AddDate(0, 0, days-1)
+
+	assignments, err := h.Tracker.GetAssignmentsInRange(r.Context(), from, to)
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to get upcoming assignments")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve upcoming assignments", handlerLogger)
+		return
+	}
+	styles := h.parentStyles(handlerLogger)
+	byDate := make(map[string]*UpcomingAssignmentResponse, len(assignments))
+	for _, a := range assignments {
+		assignment := &UpcomingAssignmentResponse{
+			ID:             a.ID,
+			Caregiver:      a.Parent,
+			CaregiverType:  a.CaregiverType.String(),
+			Override:       a.Override,
+			DecisionReason: a.DecisionReason.String(),
+			Synced:         a.GoogleCalendarEventID != "",
+		}
+		if style, ok := styles[a.Parent]; ok && a.CaregiverType == fairness.CaregiverTypeParent {
+			assignment.Color = style.Color.Hex()
+			assignment.Avatar = style.Badge(a.Parent)
+		}
+		byDate[a.Date.Format("2006-01-02")] = assignment
+	}
+
+	response := UpcomingResponse{
+		From: from.Format("2006-01-02"),
+		To:   to.Format("2006-01-02"),
+		Days: make([]UpcomingDayResponse, 0, days),
+	}
+	for day := range days {
+		date := from.AddDate(0, 0, day)
+		response.Days = append(response.Days, UpcomingDayResponse{
+			Date:       date.Format("2006-01-02"),
+			Weekday:    date.Weekday().String(),
+			Assignment: byDate[date.Format("2006-01-02")],
+		})
+	}
+
+	// The sync status is an extra, the days are still returned without it
+	if h.SyncRuns != nil {
+		runs, err := h.SyncRuns.ListRuns(1)
+		if err != nil {
+			handlerLogger.Warn().Err(err).Msg("Failed to get the latest sync run")
+		} else if len(runs) > 0 {
+			lastSync := newSyncRunResponse(runs[0])
+			response.LastSync = &lastSync
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpcomingHandler(t *testing.T) {
+	syncRunsHandler, syncRuns := setupTestSyncRunsHandler(t)
+	handler := NewUpcomingHandler(syncRunsHandler.BaseHandler)
+	handler.now = func() time.Time { return time.Date(2026, time.October, 16, 21, 30, 0, 0, time.UTC) }
+	tracker := handler.Tracker
+	configStore := new(MockConfigStore)
+	configStore.On("GetParents").Return("Alice", "Bob", nil)
+	configStore.On("GetParentStyles").Return(config.ParentStyle{Color: constants.ParentColorSage, Avatar: "🦊"}, config.ParentStyle{Color: constants.ParentColorGrape}, nil)
+	handler.ConfigStore = configStore
+
+	today, err := tracker.RecordAssignment(t.Context(), "Alice", time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), today.ID, "event-1"))
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC), true, fairness.DecisionReasonOverride)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Bob", time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "Alice", time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	t.Run("without sync run", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.handleUpcoming(w, httptest.NewRequest(http.MethodGet, "/api/v1/upcoming?days=3", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp UpcomingResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "2026-10-16", resp.From)
+		assert.Equal(t, "2026-10-18", resp.To)
+		assert.Nil(t, resp.LastSync)
+		require.Len(t, resp.Days, 3)
+
+		assert.Equal(t, UpcomingDayResponse{Date: "2026-10-16", Weekday: "Friday", Assignment: &UpcomingAssignmentResponse{
+			ID: today.ID, Caregiver: "Alice", CaregiverType: "parent", DecisionReason: "Total Count", Synced: true,
+			Color: "#33b679", Avatar: "🦊",
+		}}, resp.Days[0])
+		require.NotNil(t, resp.Days[1].Assignment)
+		assert.Equal(t, "Bob", resp.Days[1].Assignment.Caregiver)
+		assert.Equal(t, "#8e24aa", resp.Days[1].Assignment.Color)
+		assert.Equal(t, "B", resp.Days[1].Assignment.Avatar)
+		assert.True(t, resp.Days[1].Assignment.Override)
+		assert.False(t, resp.Days[1].Assignment.Synced)
+		assert.Equal(t, UpcomingDayResponse{Date: "2026-10-18", Weekday: "Sunday"}, resp.Days[2], "not scheduled yet")
+	})
+
+	t.Run("defaults to a week with the last sync", func(t *testing.T) {
+		require.NoError(t, syncRuns.RecordRun(context.Background(), constants.SyncTriggerScheduled, func(context.Context) (int, error) { return 30, nil }))
+
+		w := httptest.NewRecorder()
+		handler.handleUpcoming(w, httptest.NewRequest(http.MethodGet, "/api/v1/upcoming", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp UpcomingResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Days, 7)
+		assert.Equal(t, "2026-10-22", resp.To)
+		require.NotNil(t, resp.Days[3].Assignment)
+		assert.Equal(t, "Alice", resp.Days[3].Assignment.Caregiver)
+		require.NotNil(t, resp.LastSync)
+		assert.Equal(t, "scheduled", resp.LastSync.Trigger)
+		assert.Equal(t, 30, resp.LastSync.AssignmentsCount)
+	})
+}
+
+func TestUpcomingHandler_Errors(t *testing.T) {
+	syncRunsHandler, _ := setupTestSyncRunsHandler(t)
+	handler := NewUpcomingHandler(syncRunsHandler.BaseHandler)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{name: "zero days", method: http.MethodGet, target: "/api/v1/upcoming?days=0", wantStatus: http.StatusBadRequest},
+		{name: "too many days", method: http.MethodGet, target: "/api/v1/upcoming?days=32", wantStatus: http.StatusBadRequest},
+		{name: "not a number", method: http.MethodGet, target: "/api/v1/upcoming?days=week", wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, target: "/api/v1/upcoming", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleUpcoming(w, httptest.NewRequest(tt.method, tt.target, nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+	"slices"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+)
+
+// BuildInfo describes the running binary and the optional parts its configuration enables
+type BuildInfo struct {
+	Version  string            // Release version, "dev" for local builds
+	Commit   string            // Git commit the binary was built from
+	Date     string            // Build date
+	Features []string          // Optional features enabled by the configuration, sorted
+	Backends map[string]string // Implementation of each pluggable part, e.g. "token_storage": "keyring"
+}
+
+// DemoMode reports whether the schedule syncs to the in-memory calendar of the demo mode
+func (b BuildInfo) DemoMode() bool {
+	return slices.Contains(b.Features, "demo_mode")
+}
+
+// VersionHandler exposes the build information for bug reports
+type VersionHandler struct {
+	*BaseHandler
+}
+
+// NewVersionHandler creates a new version handler, reporting the build of the base handler
+func NewVersionHandler(baseHandler *BaseHandler) *VersionHandler {
+	return &VersionHandler{BaseHandler: baseHandler}
+}
+
+// RegisterRoutes registers the version route
+func (h *VersionHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/version", h.handleVersion, http.MethodGet)
+}
+
+// VersionResponse is the response of the version API
+type VersionResponse struct {
+	Version   string            `json:"version"`
+	Commit    string            `json:"commit"`
+	BuildDate string            `json:"build_date"`
+	GoVersion string            `json:"go_version"`
+	Platform  string            `json:"platform"` // GOOS/GOARCH
+	Features  []string          `json:"features"`
+	Backends  map[string]string `json:"backends"`
+}
+
+// handleVersion returns the version, commit and build date of the binary, the Go version it was
+// built with, and its enabled features and backends. It needs no authentication, like the health
+// checks, so that it can be read when the login is what is broken.
+func (h *VersionHandler) handleVersion(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleVersion").Logger()
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	response := VersionResponse{
+		Version:   h.Build.Version,
+		Commit:    h.Build.Commit,
+		BuildDate: h.Build.Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Features:  h.Build.Features,
+		Backends:  h.Build.Backends,
+	}
+	if response.Features == nil {
+		response.Features = []string{}
+	}
+	if response.Backends == nil {
+		response.Backends = map[string]string{}
+	}
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionHandler(t *testing.T) {
+	env := setupTestDayHandler(t, false)
+	base := env.handler.BaseHandler
+	base.Build = BuildInfo{
+		Version:  "1.2.3",
+		Commit:   "abc1234",
+		Date:     "2026-10-01T12:00:00Z",
+		Features: []string{"hooks", "tracing"},
+		Backends: map[string]string{"database": "sqlite"},
+	}
+	handler := NewVersionHandler(base)
+
+	w := httptest.NewRecorder()
+	handler.handleVersion(w, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+
+	require.Equal(t, http.StatusOK, w.Code, "no authentication needed")
+	var response VersionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, VersionResponse{
+		Version:   "1.2.3",
+		Commit:    "abc1234",
+		BuildDate: "2026-10-01T12:00:00Z",
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Features:  []string{"hooks", "tracing"},
+		Backends:  map[string]string{"database": "sqlite"},
+	}, response)
+	assert.Equal(t, "1.2.3", base.NewBasePageData(httptest.NewRequest(http.MethodGet, "/", nil), false).Version, "shown in the footer")
+
+	w = httptest.NewRecorder()
+	handler.handleVersion(w, httptest.NewRequest(http.MethodPost, "/api/version", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/rs/zerolog"
+)
+
+// Intents understood by the voice assistant endpoint
+const (
+	VoiceIntentWhoTonight  = "who_tonight"
+	VoiceIntentWhoTomorrow = "who_tomorrow"
+	VoiceIntentSwapTonight = "swap_tonight"
+)
+
+// voiceUnavailableSpeech is answered when the schedule cannot be read or changed
+const voiceUnavailableSpeech = "Sorry, the night routine schedule is unavailable right now."
+
+// VoiceHandler answers voice assistant intents. Alexa skills and Google Assistant actions map their
+// utterances to an intent and read the speech of the response aloud.
+type VoiceHandler struct {
+	*BaseHandler
+	claims *ClaimHandler
+	links  ParentLinks      // Self-service links whose token authenticates the assistants
+	now    func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewVoiceHandler creates a new voice assistant handler, swapping tonight through claims. The
+// assistants cannot sign in with a passkey: they send the token of a self-service link of links as
+// bearer token.
+func NewVoiceHandler(baseHandler *BaseHandler, claims *ClaimHandler, links ParentLinks) *VoiceHandler {
+	return &VoiceHandler{BaseHandler: baseHandler, claims: claims, links: links, now: time.Now}
+}
+
+// RegisterRoutes registers the voice assistant routes
+func (h *VoiceHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/v1/voice", h.handleVoice, http.MethodPost)
+}
+
+// VoiceRequest is the JSON body of a voice assistant request
+type VoiceRequest struct {
+	Intent string `json:"intent"`
+}
+
+// VoiceResponse is the JSON response of a voice assistant request
+type VoiceResponse struct {
+	Intent string `json:"intent"`
+	Speech string `json:"speech"` // Sentence read aloud by the assistant
+}
+
+// handleVoice answers the intent of the request body: who does the night routine tonight or
+// tomorrow, or swapping tonight to the other parent. Errors of the schedule are answered with a
+// spoken apology rather than an error status, so that the assistant always has something to say.
+func (h *VoiceHandler) handleVoice(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleVoice").Logger()
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed", handlerLogger)
+		return
+	}
+
+	if !checkBearerLink(w, r, h.links, handlerLogger) {
+		return
+	}
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var req VoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body, intent is required", handlerLogger)
+		return
+	}
+	handlerLogger = handlerLogger.With().Str("intent", req.Intent).Logger()
+
+	now := h.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var speech string
+	var err error
+	switch req.Intent {
+	case VoiceIntentWhoTonight:
+		speech, err = h.whoSpeech(r.Context(), today, "tonight")
+	case VoiceIntentWhoTomorrow:
+		speech, err = h.whoSpeech(r.Context(), today.AddDate(0, 0, 1), "tomorrow")
+	case VoiceIntentSwapTonight:
+		speech, err = h.swapTonightSpeech(r.Context(), handlerLogger, today)
+	default:
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, fmt.Sprintf("intent must be one of %s, %s or %s", VoiceIntentWhoTonight, VoiceIntentWhoTomorrow, VoiceIntentSwapTonight), handlerLogger)
+		return
+	}
+	if err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to answer voice intent")
+		speech = voiceUnavailableSpeech
+	}
+
+	writeJSON(w, http.StatusOK, VoiceResponse{Intent: req.Intent, Speech: speech}, handlerLogger)
+}
+
+// whoSpeech tells who does the night routine of date, said as when
+func (h *VoiceHandler) whoSpeech(ctx context.Context, date time.Time, when string) (string, error) {
+	assignment, err := h.Tracker.GetAssignmentByDate(ctx, date)
+	if err != nil {
+		return "", fmt.Errorf("failed to get assignment of %s: %w", date.Format(time.DateOnly), err)
+	}
+	if assignment == nil {
+		return fmt.Sprintf("Nobody is scheduled for the night routine %s yet.", when), nil
+	}
+	if assignment.CaregiverType == fairness.CaregiverTypeBabysitter {
+		return fmt.Sprintf("%s, the babysitter, does the night routine %s.", assignment.Parent, when), nil
+	}
+	return fmt.Sprintf("%s does the night routine %s.", assignment.Parent, when), nil
+}
+
+// swapTonightSpeech gives tonight to the parent who is not on duty, and tells the result
+func (h *VoiceHandler) swapTonightSpeech(ctx context.Context, logger zerolog.Logger, today time.Time) (string, error) {
+	assignment, err := h.Tracker.GetAssignmentByDate(ctx, today)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tonight's assignment: %w", err)
+	}
+	if assignment == nil {
+		return "Nobody is scheduled tonight, there is nothing to swap.", nil
+	}
+	if assignment.CaregiverType == fairness.CaregiverTypeBabysitter {
+		return fmt.Sprintf("%s, the babysitter, has tonight, there is nothing to swap.", assignment.Parent), nil
+	}
+
+	otherParent, err := h.claims.otherParent(assignment.Parent)
+	if errors.Is(err, errUnknownParent) {
+		return fmt.Sprintf("%s is no longer a configured parent, tonight cannot be swapped.", assignment.Parent), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	_, err = h.claims.claimTonight(ctx, logger, today, otherParent, assignment.Version)
+	if errors.Is(err, fairness.ErrAssignmentConflict) {
+		return "Tonight was just changed by someone else, ask me who has tonight and try again.", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Done, %s now does the night routine tonight instead of %s.", otherParent, assignment.Parent), nil
+}
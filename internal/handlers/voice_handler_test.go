@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestVoiceHandler(t *testing.T, authenticated bool) (*VoiceHandler, *fairness.Tracker, *recordingNotifier) {
+	claims, tracker, notifier := setupTestClaimHandler(t, authenticated)
+	return NewVoiceHandler(claims.BaseHandler, claims, fakeParentLinks{"link-token": "parent_a"}), tracker, notifier
+}
+
+func askVoice(t *testing.T, handler *VoiceHandler, intent string) VoiceResponse {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/voice", strings.NewReader(`{"intent":"`+intent+`"}`))
+	w := httptest.NewRecorder()
+	handler.handleVoice(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response VoiceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, intent, response.Intent)
+	return response
+}
+
+func TestVoiceHandler_Who(t *testing.T) {
+	handler, tracker, _ := setupTestVoiceHandler(t, true)
+	now := time.Now()
+
+	_, err := tracker.RecordAssignment(t.Context(), "ParentA", now, false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+	_, err = tracker.RecordBabysitterAssignment(t.Context(), "Grandma", now.AddDate(0, 0, 1), true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ParentA does the night routine tonight.", askVoice(t, handler, VoiceIntentWhoTonight).Speech)
+	assert.Equal(t, "Grandma, the babysitter, does the night routine tomorrow.", askVoice(t, handler, VoiceIntentWhoTomorrow).Speech)
+}
+
+func TestVoiceHandler_WhoNotScheduled(t *testing.T) {
+	handler, _, _ := setupTestVoiceHandler(t, true)
+
+	assert.Equal(t, "Nobody is scheduled for the night routine tomorrow yet.", askVoice(t, handler, VoiceIntentWhoTomorrow).Speech)
+}
+
+func TestVoiceHandler_SwapTonight(t *testing.T) {
+	handler, tracker, notifier := setupTestVoiceHandler(t, true)
+
+	_, err := tracker.RecordAssignment(t.Context(), "ParentA", time.Now(), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	response := askVoice(t, handler, VoiceIntentSwapTonight)
+	assert.Equal(t, "Done, ParentB now does the night routine tonight instead of ParentA.", response.Speech)
+
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "ParentB", assignment.Parent)
+	assert.True(t, assignment.Override)
+	assert.Equal(t, []notify.Event{notify.EventTonightClaimed}, notifier.events)
+}
+
+func TestVoiceHandler_SwapTonightNothingToSwap(t *testing.T) {
+	handler, tracker, notifier := setupTestVoiceHandler(t, true)
+
+	assert.Equal(t, "Nobody is scheduled tonight, there is nothing to swap.", askVoice(t, handler, VoiceIntentSwapTonight).Speech)
+
+	_, err := tracker.RecordBabysitterAssignment(t.Context(), "Grandma", time.Now(), true)
+	require.NoError(t, err)
+	assert.Equal(t, "Grandma, the babysitter, has tonight, there is nothing to swap.", askVoice(t, handler, VoiceIntentSwapTonight).Speech)
+
+	assignment, err := tracker.GetAssignmentByDate(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, fairness.CaregiverTypeBabysitter, assignment.CaregiverType)
+	assert.Empty(t, notifier.events)
+}
+
+func TestVoiceHandler_LinkToken(t *testing.T) {
+	handler, _, _ := setupTestVoiceHandler(t, true)
+
+	for bearer, wantStatus := range map[string]int{"unknown": http.StatusUnauthorized, "link-token": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/voice", strings.NewReader(`{"intent":"who_tonight"}`))
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		handler.handleVoice(w, req)
+		assert.Equal(t, wantStatus, w.Code, bearer)
+	}
+}
+
+func TestVoiceHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticated bool
+		method        string
+		body          string
+		wantStatus    int
+	}{
+		{name: "wrong method", authenticated: true, method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "unauthenticated", method: http.MethodPost, body: `{"intent":"who_tonight"}`, wantStatus: http.StatusUnauthorized},
+		{name: "invalid body", authenticated: true, method: http.MethodPost, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "unknown intent", authenticated: true, method: http.MethodPost, body: `{"intent":"order_pizza"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, _ := setupTestVoiceHandler(t, tt.authenticated)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/voice", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.handleVoice(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// pendingPass is a processing pass waiting for its coalescing window to elapse
+type pendingPass struct {
+	ctx   context.Context
+	timer *time.Timer
+}
+
+// webhookCoalescer merges the change notifications of a calendar into a single processing pass.
+// The window starts with the first notification and the ones received before it elapses are
+// absorbed by the pending pass. Passes of the same calendar never run concurrently.
+type webhookCoalescer struct {
+	window  time.Duration
+	process func(ctx context.Context, calendarID string)
+	logger  zerolog.Logger
+
+	mu      sync.Mutex
+	pending map[string]*pendingPass
+	locks   map[string]*sync.Mutex
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// newWebhookCoalescer creates a coalescer running process once per window and calendar
+func newWebhookCoalescer(window time.Duration, process func(ctx context.Context, calendarID string)) *webhookCoalescer {
+	return &webhookCoalescer{
+		window:  window,
+		process: process,
+		logger:  logging.GetLogger("webhook-coalescer"),
+		pending: make(map[string]*pendingPass),
+		locks:   make(map[string]*sync.Mutex),
+	}
+}
+
+// Submit schedules a pass for calendarID and reports whether a new pass was scheduled;
+// false means the notification was merged into the pending one. The pass runs with a
+// context detached from the cancellation of ctx, since the request is answered first.
+func (c *webhookCoalescer) Submit(ctx context.Context, calendarID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		c.logger.Warn().Str("calendar_id", calendarID).Msg("Coalescer closed, dropping notification")
+		return false
+	}
+	if _, ok := c.pending[calendarID]; ok {
+		c.logger.Debug().Str("calendar_id", calendarID).Msg("Notification merged into pending pass")
+		return false
+	}
+
+	pass := &pendingPass{ctx: context.WithoutCancel(ctx)}
+	c.wg.Add(1)
+	pass.timer = time.AfterFunc(c.window, func() { c.run(calendarID, pass) })
+	c.pending[calendarID] = pass
+	c.logger.Debug().Str("calendar_id", calendarID).Dur("window", c.window).Msg("Processing pass scheduled")
+	return true
+}
+
+// run executes a pass once no other pass of the same calendar is running
+func (c *webhookCoalescer) run(calendarID string, pass *pendingPass) {
+	defer c.wg.Done()
+
+	c.mu.Lock()
+	// A notification received from now on needs a new pass: this one may already have listed the events
+	if c.pending[calendarID] == pass {
+		delete(c.pending, calendarID)
+	}
+	lock, ok := c.locks[calendarID]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[calendarID] = lock
+	}
+	c.mu.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+	c.process(pass.ctx, calendarID)
+}
+
+// Close runs the pending passes without waiting for their window and waits for every pass to finish.
+// Notifications submitted afterwards are dropped.
+func (c *webhookCoalescer) Close() {
+	c.mu.Lock()
+	c.closed = true
+	for calendarID, pass := range c.pending {
+		if pass.timer.Stop() {
+			go c.run(calendarID, pass)
+		}
+	}
+	c.mu.Unlock()
+
+	c.wg.Wait()
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// passRecorder records the processing passes run by a coalescer
+type passRecorder struct {
+	mu     sync.Mutex
+	passes []string
+	done   chan string
+}
+
+func newPassRecorder() *passRecorder {
+	return &passRecorder{done: make(chan string, 10)}
+}
+
+func (r *passRecorder) process(_ context.Context, calendarID string) {
+	r.mu.Lock()
+	r.passes = append(r.passes, calendarID)
+	r.mu.Unlock()
+	r.done <- calendarID
+}
+
+func (r *passRecorder) wait(t *testing.T) string {
+	t.Helper()
+	select {
+	case calendarID := <-r.done:
+		return calendarID
+	case <-time.After(time.Second):
+		t.Fatal("processing pass did not run")
+		return ""
+	}
+}
+
+func (r *passRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.passes)
+}
+
+func TestWebhookCoalescer_MergesBurst(t *testing.T) {
+	recorder := newPassRecorder()
+	coalescer := newWebhookCoalescer(50*time.Millisecond, recorder.process)
+
+	assert.True(t, coalescer.Submit(context.Background(), "cal"), "first notification schedules a pass")
+	for range 4 {
+		assert.False(t, coalescer.Submit(context.Background(), "cal"), "burst is merged into the pending pass")
+	}
+
+	assert.Equal(t, "cal", recorder.wait(t))
+	coalescer.Close()
+	assert.Equal(t, 1, recorder.count())
+}
+
+func TestWebhookCoalescer_NewPassAfterWindow(t *testing.T) {
+	recorder := newPassRecorder()
+	coalescer := newWebhookCoalescer(10*time.Millisecond, recorder.process)
+
+	require.True(t, coalescer.Submit(context.Background(), "cal"))
+	recorder.wait(t)
+	assert.True(t, coalescer.Submit(context.Background(), "cal"), "notification after the pass schedules another one")
+	recorder.wait(t)
+
+	coalescer.Close()
+	assert.Equal(t, 2, recorder.count())
+}
+
+func TestWebhookCoalescer_CalendarsAreIndependent(t *testing.T) {
+	recorder := newPassRecorder()
+	coalescer := newWebhookCoalescer(10*time.Millisecond, recorder.process)
+
+	assert.True(t, coalescer.Submit(context.Background(), "cal-a"))
+	assert.True(t, coalescer.Submit(context.Background(), "cal-b"))
+
+	got := []string{recorder.wait(t), recorder.wait(t)}
+	assert.ElementsMatch(t, []string{"cal-a", "cal-b"}, got)
+	coalescer.Close()
+}
+
+func TestWebhookCoalescer_CloseFlushesPending(t *testing.T) {
+	recorder := newPassRecorder()
+	coalescer := newWebhookCoalescer(time.Hour, recorder.process)
+
+	require.True(t, coalescer.Submit(context.Background(), "cal"))
+	coalescer.Close()
+	assert.Equal(t, 1, recorder.count(), "pending pass runs on close without waiting for its window")
+
+	assert.False(t, coalescer.Submit(context.Background(), "cal"), "notifications are dropped once closed")
+	assert.Equal(t, 1, recorder.count())
+}
+
+func TestWebhookCoalescer_DetachesRequestContext(t *testing.T) {
+	var passErr error
+	done := make(chan struct{})
+	coalescer := newWebhookCoalescer(10*time.Millisecond, func(ctx context.Context, _ string) {
+		passErr = ctx.Err()
+		close(done)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	coalescer.Submit(ctx, "cal")
+	cancel() // the request is answered before the pass runs
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("processing pass did not run")
+	}
+	coalescer.Close()
+	assert.NoError(t, passErr)
+}
@@ -4,21 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	gcalendar "google.golang.org/api/calendar/v3"
-	"google.golang.org/api/option"
 
 	"github.com/belphemur/night-routine/internal/calendar"
 	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/dates"
 	"github.com/belphemur/night-routine/internal/fairness"
 	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/googleclient"
 	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/belphemur/night-routine/internal/signals"
 	"github.com/belphemur/night-routine/internal/token"
+	"github.com/belphemur/night-routine/internal/tracing"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // WebhookHandler handles incoming webhook notifications
@@ -31,28 +41,155 @@ type WebhookHandler struct {
 	// so that settings changes (e.g. PastEventThresholdDays, LookAheadDays) take
 	// effect immediately without requiring an application restart.
 	ConfigStore config.ConfigStoreInterface
-	logger      zerolog.Logger
+	// EventIdentifier marks the events managed by this instance, constants.NightRoutineIdentifier when empty
+	EventIdentifier string
+	// QuietHours defers the recalculation and sync following the changes received within them
+	QuietHours config.QuietHours
+	// CalendarEndpoint is the Calendar API endpoint listing the changed events, Google when empty
+	CalendarEndpoint string
+	// OverrideNotices emails the overrides applied from Google Calendar to the subscribed parents, nil sends none
+	OverrideNotices SubscriptionMailer
+	// AppURL is the public URL of the application linked by the override notices, may be empty
+	AppURL string
+	// coalescer merges bursts of change notifications, nil processes each notification inline
+	coalescer *webhookCoalescer
+	logger    zerolog.Logger
+
+	deferMu      sync.Mutex
+	deferredFrom time.Time   // Earliest date to recalculate at the end of the quiet hours
+	deferTimer   *time.Timer // Runs the deferred recalculation, nil when none is pending
+
+	// processMu serializes the processing of the notifications and of the polls
+	processMu sync.Mutex
+	polledAt  time.Time // Time up to which the polls listed the changed events, zero before the first one
 }
 
-// NewWebhookHandler creates a new webhook handler
-func NewWebhookHandler(baseHandler *BaseHandler, calendarService calendar.CalendarService, scheduler Scheduler.SchedulerInterface, tokenManager *token.TokenManager, configStore config.ConfigStoreInterface) *WebhookHandler {
-	return &WebhookHandler{
+// SubscriptionMailer emails an event to the parents subscribed to it, implemented by notify.Service
+type SubscriptionMailer interface {
+	EmailSubscribers(ctx context.Context, subscription config.EmailSubscription, event notify.Event, data any) error
+}
+
+// NewWebhookHandler creates a new webhook handler. Change notifications of a calendar received
+// within debounce are processed in a single pass; 0 processes each notification as it arrives.
+// Only the events marked with eventIdentifier are processed. The overrides received within quietHours
+// are recorded at once, their recalculation and sync wait for the end of the quiet hours.
+func NewWebhookHandler(baseHandler *BaseHandler, calendarService calendar.CalendarService, scheduler Scheduler.SchedulerInterface, tokenManager *token.TokenManager, configStore config.ConfigStoreInterface, eventIdentifier string, debounce time.Duration, quietHours config.QuietHours) *WebhookHandler {
+	h := &WebhookHandler{
 		BaseHandler:     baseHandler,
 		CalendarService: calendarService,
 		Scheduler:       scheduler,
 		TokenManager:    tokenManager,
 		ConfigStore:     configStore,
+		EventIdentifier: eventIdentifier,
+		QuietHours:      quietHours,
 		logger:          logging.GetLogger("webhook"),
 	}
+	if debounce > 0 {
+		h.coalescer = newWebhookCoalescer(debounce, func(ctx context.Context, calendarID string) {
+			if err := h.processNotification(ctx, calendarID); err != nil {
+				h.logger.Error().Err(err).Str("calendar_id", calendarID).Msg("Error processing coalesced event changes")
+				return
+			}
+			h.logger.Info().Str("calendar_id", calendarID).Msg("Coalesced event changes processed successfully")
+		})
+	}
+	return h
+}
+
+// eventIdentifier returns the identifier marking the events managed by this instance
+func (h *WebhookHandler) eventIdentifier() string {
+	if h.EventIdentifier == "" {
+		return constants.NightRoutineIdentifier
+	}
+	return h.EventIdentifier
+}
+
+// Close processes the notifications still waiting for their coalescing window and drops the
+// recalculation deferred by the quiet hours, left to the next sync. Call it once the HTTP server
+// stopped accepting requests.
+func (h *WebhookHandler) Close() {
+	if h.coalescer != nil {
+		h.coalescer.Close()
+	}
+	h.deferMu.Lock()
+	defer h.deferMu.Unlock()
+	if h.deferTimer != nil {
+		h.deferTimer.Stop()
+		h.deferTimer = nil
+		h.logger.Warn().Str("from_date", h.deferredFrom.Format("2006-01-02")).Msg("Dropping the recalculation deferred by the quiet hours")
+	}
 }
 
 // RegisterRoutes registers webhook related routes
 func (h *WebhookHandler) RegisterRoutes() {
-	http.HandleFunc("/api/webhook/calendar", h.handleCalendarWebhook)
+	handleMethods(http.DefaultServeMux, "/api/webhook/calendar", h.handleCalendarWebhook, http.MethodPost)
 }
 
-// handleCalendarWebhook processes incoming calendar notifications
+// Limits of the change notifications, received from the internet at public_url
+const (
+	// maxWebhookBodyBytes is the most read of a notification body; Google Calendar sends none
+	maxWebhookBodyBytes = 1024
+	// maxWebhookHeaderLength is the longest channel or resource ID accepted
+	maxWebhookHeaderLength = 256
+)
+
+// webhookResourceStates are the X-Goog-Resource-State values of the Google Calendar notifications
+var webhookResourceStates = map[string]bool{"sync": true, "exists": true, "not_exists": true}
+
+// validateWebhookHeaders checks the headers of a change notification: the channel and resource IDs
+// are required printable ASCII of bounded length, the resource state one Google Calendar sends, and
+// the message number, when present, a positive number.
+func validateWebhookHeaders(header http.Header) error {
+	for _, name := range []string{"X-Goog-Channel-ID", "X-Goog-Resource-ID"} {
+		values := header.Values(name)
+		if len(values) != 1 {
+			return fmt.Errorf("%s must be set once, got %d values", name, len(values))
+		}
+		if !isWebhookToken(values[0]) {
+			return fmt.Errorf("%s must be 1 to %d printable ASCII characters", name, maxWebhookHeaderLength)
+		}
+	}
+	if state := header.Get("X-Goog-Resource-State"); !webhookResourceStates[state] {
+		return fmt.Errorf("unexpected X-Goog-Resource-State %q", state)
+	}
+	if number := header.Get("X-Goog-Message-Number"); number != "" {
+		if n, err := strconv.ParseUint(number, 10, 64); err != nil || n == 0 {
+			return fmt.Errorf("X-Goog-Message-Number must be a positive number, got %q", number)
+		}
+	}
+	return nil
+}
+
+// isWebhookToken reports whether value is 1 to maxWebhookHeaderLength printable ASCII characters
+// without spaces
+func isWebhookToken(value string) bool {
+	if value == "" || len(value) > maxWebhookHeaderLength {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '!' || value[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// handleCalendarWebhook processes incoming calendar notifications. The endpoint is reachable from
+// the internet, so anything but a bodiless POST with valid Google headers is rejected before the
+// notification channel is looked up.
 func (h *WebhookHandler) handleCalendarWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := validateWebhookHeaders(r.Header); err != nil {
+		h.logger.Warn().Err(err).Str("remote_addr", r.RemoteAddr).Msg("Rejected calendar webhook notification with invalid headers")
+		http.Error(w, "Invalid notification headers", http.StatusBadRequest)
+		return
+	}
+
 	// Add request context to logger
 	requestLogger := h.logger.With().
 		Str("method", r.Method).
@@ -61,9 +198,31 @@ func (h *WebhookHandler) handleCalendarWebhook(w http.ResponseWriter, r *http.Re
 		Str("resource_id", r.Header.Get("X-Goog-Resource-ID")).
 		Str("resource_state", r.Header.Get("X-Goog-Resource-State")).
 		Logger()
+
+	if r.ContentLength > maxWebhookBodyBytes {
+		requestLogger.Warn().Int64("content_length", r.ContentLength).Msg("Rejected calendar webhook notification with a body too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	bodyBytes, err := io.Copy(io.Discard, http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		requestLogger.Warn().Msg("Rejected calendar webhook notification with a body too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		requestLogger.Warn().Err(err).Msg("Failed to read calendar webhook notification body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if bodyBytes > 0 {
+		requestLogger.Warn().Int64("body_bytes", bodyBytes).Msg("Rejected calendar webhook notification with a body")
+		http.Error(w, "Unexpected request body", http.StatusBadRequest)
+		return
+	}
 	requestLogger.Info().Msg("Received calendar webhook notification")
 
-	// Validate the request
 	channelID := r.Header.Get("X-Goog-Channel-ID")
 	resourceID := r.Header.Get("X-Goog-Resource-ID")
 	resourceState := r.Header.Get("X-Goog-Resource-State")
@@ -89,6 +248,9 @@ func (h *WebhookHandler) handleCalendarWebhook(w http.ResponseWriter, r *http.Re
 		return
 	}
 	requestLogger.Debug().Msg("Notification channel validated")
+	if err := h.TokenStore.RecordNotificationReceived(channelID, time.Now()); err != nil {
+		requestLogger.Warn().Err(err).Msg("Failed to record the notification received on the channel")
+	}
 
 	// Check if the channel is close to expiration (within 7 days)
 	if time.Until(channel.Expiration) < 7*24*time.Hour {
@@ -111,8 +273,19 @@ func (h *WebhookHandler) handleCalendarWebhook(w http.ResponseWriter, r *http.Re
 	}
 
 	// This is an actual change notification
+	if h.coalescer != nil {
+		if h.coalescer.Submit(r.Context(), channel.CalendarID) {
+			requestLogger.Info().Msg("Event change notification queued for processing")
+		} else {
+			requestLogger.Info().Msg("Event change notification merged into the pending processing pass")
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	requestLogger.Info().Msg("Processing event change notification")
-	if err := h.processEventChanges(r.Context(), channel.CalendarID); err != nil {
+	err = h.processNotification(r.Context(), channel.CalendarID)
+	if err != nil {
 		requestLogger.Error().Err(err).Msg("Error processing event changes")
 		http.Error(w, "Failed to process event changes", http.StatusInternalServerError)
 		return
@@ -122,59 +295,88 @@ func (h *WebhookHandler) handleCalendarWebhook(w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusOK)
 }
 
+// processNotification processes the changes of calendarID and emits the WebhookProcessed signal
+func (h *WebhookHandler) processNotification(ctx context.Context, calendarID string) error {
+	h.processMu.Lock()
+	err := h.processEventChanges(ctx, calendarID)
+	h.processMu.Unlock()
+	signals.EmitWebhookProcessed(ctx, calendarID, err)
+	return err
+}
+
 // processEventChanges fetches recent changes and updates assignments
-func (h *WebhookHandler) processEventChanges(ctx context.Context, calendarID string) error {
+func (h *WebhookHandler) processEventChanges(ctx context.Context, calendarID string) (err error) {
+	ctx, span := tracing.Tracer("internal/handlers").Start(ctx, "webhook.process_changes", trace.WithAttributes(attribute.String("calendar.id", calendarID)))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	procLogger := h.logger.With().Str("calendar_id", calendarID).Logger()
 	procLogger.Info().Msg("Processing event changes")
 
+	// Get events that were recently updated
+	// Look back slightly further to avoid race conditions with notification delivery
+	events, err := h.listChangedEvents(ctx, calendarID, time.Now().Add(-2*time.Minute), procLogger)
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		procLogger.Info().Msg("No recently updated events found")
+		return nil
+	}
+
+	return h.processEvents(ctx, events, procLogger)
+}
+
+// listChangedEvents lists the events of calendarID updated since updatedMin, every page of them
+func (h *WebhookHandler) listChangedEvents(ctx context.Context, calendarID string, updatedMin time.Time, procLogger zerolog.Logger) ([]*gcalendar.Event, error) {
 	// Get a valid token using TokenManager
 	token, err := h.TokenManager.GetValidToken(ctx)
 	if err != nil {
 		procLogger.Error().Err(err).Msg("Failed to get valid token for processing changes")
-		return fmt.Errorf("failed to get valid token: %w", err)
+		return nil, fmt.Errorf("failed to get valid token: %w", err)
 	}
 	procLogger.Debug().Msg("Valid token obtained")
 
-	// Create a calendar client using the OAuth config from the config store
-	client := h.ConfigStore.GetOAuthConfig().Client(ctx, token)
-	calendarSvc, err := gcalendar.NewService(ctx, option.WithHTTPClient(client))
+	// Create a calendar client using the OAuth config from the config store, on the shared connection pool
+	client := googleclient.New(ctx, h.ConfigStore.GetOAuthConfig(), token)
+	calendarSvc, err := calendar.NewAPIService(ctx, client, h.CalendarEndpoint)
 	if err != nil {
 		procLogger.Error().Err(err).Msg("Failed to create Google Calendar service client")
-		return fmt.Errorf("failed to create calendar service: %w", err)
+		return nil, fmt.Errorf("failed to create calendar service: %w", err)
 	}
 	procLogger.Debug().Msg("Google Calendar service client created")
 
-	// Get events that were recently updated
-	// Look back slightly further to avoid race conditions with notification delivery
-	timeMin := time.Now().Add(-2 * time.Minute).Format(time.RFC3339)
+	timeMin := updatedMin.Format(time.RFC3339)
 	procLogger.Debug().Str("updated_min", timeMin).Msg("Fetching recently updated events")
-	events, err := calendarSvc.Events.List(calendarID).
+	var events []*gcalendar.Event
+	err = calendarSvc.Events.List(calendarID).
 		UpdatedMin(timeMin).
 		SingleEvents(true).
 		OrderBy("updated").
-		Do()
+		Pages(ctx, func(page *gcalendar.Events) error {
+			events = append(events, page.Items...)
+			return nil
+		})
 	if err != nil {
 		procLogger.Error().Err(err).Msg("Failed to list updated events from Google Calendar")
-		return fmt.Errorf("failed to list updated events: %w", err)
+		return nil, fmt.Errorf("failed to list updated events: %w", err)
 	}
-	procLogger.Info().Int("event_count", len(events.Items)).Msg("Fetched updated events")
-
-	if len(events.Items) == 0 {
-		procLogger.Info().Msg("No recently updated events found")
-		return nil
-	}
-
-	return h.processEvents(ctx, events.Items, procLogger)
+	procLogger.Info().Int("event_count", len(events)).Msg("Fetched updated events")
+	return events, nil
 }
 
 // processEvents processes a batch of calendar events and updates assignments accordingly
 func (h *WebhookHandler) processEvents(ctx context.Context, events []*gcalendar.Event, procLogger zerolog.Logger) error {
 	var processingErrors []error
-	parentA, parentB, err := h.ConfigStore.GetParents()
+	// recalculateFrom is the earliest date of the overridden assignments, nil when none changed
+	var recalculateFrom *time.Time
+	roster, err := config.Roster(h.ConfigStore)
 	if err != nil {
 		procLogger.Warn().Err(err).Msg("Failed to get parent names from config store, falling back to summary-only parsing")
-		parentA = ""
-		parentB = ""
+		roster = nil
 	}
 
 	// Read the past-event threshold live from the database so that UI setting
@@ -186,6 +388,9 @@ func (h *WebhookHandler) processEvents(ctx context.Context, events []*gcalendar.
 	}
 	procLogger.Debug().Int("threshold_days", thresholdDays).Msg("Using past event threshold from live config")
 
+	// The overrides of the notification and the recalculation following them are undone together
+	batchCtx := h.Tracker.BeginBatch(ctx, fairness.ChangeKindOverride)
+
 	for _, event := range events {
 		eventLogger := procLogger.With().Str("event_id", event.Id).Logger()
 		eventLogger.Debug().Msg("Processing event")
@@ -200,13 +405,33 @@ func (h *WebhookHandler) processEvents(ctx context.Context, events []*gcalendar.
 			continue
 		}
 
-		if val, ok := event.ExtendedProperties.Private["app"]; !ok || val != constants.NightRoutineIdentifier {
+		if val, ok := event.ExtendedProperties.Private["app"]; !ok || val != h.eventIdentifier() {
 			eventLogger.Debug().Msg("Event is not managed by Night Routine app, skipping")
 			continue
 		}
 		eventLogger.Debug().Msg("Event identified as managed by Night Routine")
 
-		assignee, ok := parseManagedEventAssignee(event.Summary, parentA, parentB)
+		// The syncs update the events they write; these updates reflect the schedule and are no override
+		if h.CalendarService != nil && h.CalendarService.IsOwnUpdate(event) {
+			eventLogger.Debug().Msg("Event update written by a sync of the app, skipping")
+			continue
+		}
+
+		// A notification delivered again lists the versions already applied, applying them again
+		// would override the recalculated nights and start another sync
+		version := eventVersion(event)
+		applied, err := h.Tracker.GetAppliedEventVersion(batchCtx, event.Id)
+		if err != nil {
+			eventLogger.Error().Err(err).Msg("Error reading the applied version of the event")
+			processingErrors = append(processingErrors, err)
+			continue
+		}
+		if applied != nil && applied.Covers(version) {
+			eventLogger.Debug().Str("etag", version.ETag).Msg("Event version already applied, skipping")
+			continue
+		}
+
+		assignee, ok := parseManagedEventAssignee(event.Summary, roster)
 		if !ok {
 			eventLogger.Warn().Str("summary", event.Summary).Msg("Could not parse managed assignee from event summary, skipping")
 			continue
@@ -218,7 +443,7 @@ func (h *WebhookHandler) processEvents(ctx context.Context, events []*gcalendar.
 		eventLogger.Debug().Msg("Extracted managed assignee from event summary")
 
 		// Find the assignment by Google Calendar event ID
-		assignment, err := h.Scheduler.GetAssignmentByGoogleCalendarEventID(event.Id)
+		assignment, err := h.Scheduler.GetAssignmentByGoogleCalendarEventID(batchCtx, event.Id)
 		if err != nil {
 			eventLogger.Error().Err(err).Msg("Error finding assignment by event ID")
 			processingErrors = append(processingErrors, err) // Collect error
@@ -264,21 +489,15 @@ func (h *WebhookHandler) processEvents(ctx context.Context, events []*gcalendar.
 			}
 		}
 
-		// Check if the assignment is within the configurable past event threshold
-		now := time.Now()
-		thresholdDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -thresholdDays)
-
-		// Ensure assignment date is compared in the same timezone/location
-		// We use the Year/Month/Day from the assignment date to construct a new date in the local timezone
-		// This avoids issues where DB returns UTC time which shifts the day when converted to Local
-		y, m, d := assignment.Date.Date()
-		assignmentDate := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
-
-		if assignmentDate.Before(thresholdDate) {
+		// Check if the assignment is within the configurable past event threshold. The days are
+		// compared by calendar date: the database date is read by its year, month and day, and
+		// the threshold does not move around a clock change.
+		thresholdDate := dates.AddDays(time.Now(), -thresholdDays)
+		if dates.DaysBetween(assignment.Date, thresholdDate) > 0 {
 			eventLogger.Warn().
 				Int("threshold_days", thresholdDays).
 				Str("threshold_date", thresholdDate.Format("2006-01-02")).
-				Str("assignment_date", assignmentDate.Format("2006-01-02")).
+				Str("assignment_date", assignment.Date.Format("2006-01-02")).
 				Msg("Rejecting override attempt for past assignment outside threshold")
 			continue
 		}
@@ -288,14 +507,14 @@ func (h *WebhookHandler) processEvents(ctx context.Context, events []*gcalendar.
 
 		if assignee.CaregiverType == fairness.CaregiverTypeBabysitter {
 			eventLogger.Info().Msg("Updating assignment to babysitter due to event change (override)")
-			if err := h.Scheduler.UpdateAssignmentToBabysitter(assignment.ID, assignee.Name, true); err != nil {
+			if err := h.Scheduler.UpdateAssignmentToBabysitter(batchCtx, assignment.ID, assignee.Name, true, assignment.Version); err != nil {
 				eventLogger.Error().Err(err).Msg("Error updating assignment to babysitter in database")
 				processingErrors = append(processingErrors, err)
 				continue
 			}
 		} else {
 			eventLogger.Info().Msg("Updating assignment parent due to event change (override)")
-			if err := h.Scheduler.UpdateAssignmentParent(assignment.ID, assignee.Name, true); err != nil {
+			if err := h.Scheduler.UpdateAssignmentParent(batchCtx, assignment.ID, assignee.Name, true, assignment.Version); err != nil {
 				eventLogger.Error().Err(err).Msg("Error updating assignment parent in database")
 				processingErrors = append(processingErrors, err)
 				continue
@@ -303,15 +522,28 @@ func (h *WebhookHandler) processEvents(ctx context.Context, events []*gcalendar.
 		}
 
 		eventLogger.Info().Msg("Successfully updated assignment in database")
+		if err := h.Tracker.RecordAppliedEventVersion(batchCtx, event.Id, assignment.ID, version); err != nil {
+			eventLogger.Error().Err(err).Msg("Error recording the applied version of the event")
+			processingErrors = append(processingErrors, err)
+		}
+		h.noticeOverride(batchCtx, eventLogger, assignment, assignee.Name, assignee.CaregiverType)
+		if recalculateFrom == nil || assignment.Date.Before(*recalculateFrom) {
+			recalculateFrom = &assignment.Date
+		}
+	}
 
-		// Recalculate the schedule for future days starting from the modified assignment's date
-		eventLogger.Info().Msg("Recalculating schedule due to override")
-		if err := h.recalculateSchedule(ctx, assignment.Date); err != nil {
-			eventLogger.Error().Err(err).Msg("Error recalculating schedule after override")
+	// Recalculate the schedule once for the whole batch, from the earliest modified assignment's date
+	if recalculateFrom != nil && h.QuietHours.Contains(time.Now()) {
+		h.deferRecalculation(ctx, *recalculateFrom)
+	} else if recalculateFrom != nil {
+		recalcLogger := procLogger.With().Str("from_date", recalculateFrom.Format("2006-01-02")).Logger()
+		recalcLogger.Info().Msg("Recalculating schedule due to overrides")
+		if err := h.recalculateSchedule(batchCtx, *recalculateFrom); err != nil {
+			recalcLogger.Error().Err(err).Msg("Error recalculating schedule after overrides")
 			processingErrors = append(processingErrors, err) // Collect error
-			continue
+		} else {
+			recalcLogger.Info().Msg("Successfully recalculated schedule")
 		}
-		eventLogger.Info().Msg("Successfully recalculated schedule")
 	}
 
 	// Join multiple errors if they occurred
@@ -325,11 +557,30 @@ func (h *WebhookHandler) processEvents(ctx context.Context, events []*gcalendar.
 	return nil // Success - transaction will be committed
 }
 
+// noticeOverride emails the night of assignment given to caregiver from Google Calendar to the
+// parents subscribed to the override notices; a failure is only logged
+func (h *WebhookHandler) noticeOverride(ctx context.Context, logger zerolog.Logger, assignment *Scheduler.Assignment, caregiver string, caregiverType fairness.CaregiverType) {
+	if h.OverrideNotices == nil {
+		return
+	}
+	if err := h.OverrideNotices.EmailSubscribers(ctx, config.EmailOverrideNotices, notify.EventCalendarOverride, notify.CalendarOverrideData{
+		Date:              assignment.Date.Format(swapNotificationDateFormat),
+		Caregiver:         caregiver,
+		Babysitter:        caregiverType == fairness.CaregiverTypeBabysitter,
+		PreviousCaregiver: assignment.Parent,
+		AppURL:            strings.TrimRight(h.AppURL, "/"),
+	}); err != nil {
+		logger.Warn().Err(err).Msg("Failed to email the override notice")
+	}
+}
+
 // recalculateSchedule regenerates the schedule from the given date
 func (h *WebhookHandler) recalculateSchedule(ctx context.Context, fromDate time.Time) error {
 	return recalculateScheduleAndSync(
 		ctx,
 		h.logger,
+		h.SyncRuns,
+		constants.SyncTriggerWebhook,
 		h.Tracker,
 		h.Scheduler,
 		h.CalendarService,
@@ -338,12 +589,67 @@ func (h *WebhookHandler) recalculateSchedule(ctx context.Context, fromDate time.
 	)
 }
 
+// deferRecalculation recalculates the schedule from fromDate at the end of the quiet hours, along
+// with the recalculations already deferred
+func (h *WebhookHandler) deferRecalculation(ctx context.Context, fromDate time.Time) {
+	h.deferMu.Lock()
+	defer h.deferMu.Unlock()
+
+	if h.deferredFrom.IsZero() || fromDate.Before(h.deferredFrom) {
+		h.deferredFrom = fromDate
+	}
+	logger := h.logger.With().Str("from_date", h.deferredFrom.Format("2006-01-02")).Str("quiet_hours", h.QuietHours.String()).Logger()
+	if h.deferTimer != nil {
+		logger.Info().Msg("Quiet hours, recalculation merged into the deferred one")
+		return
+	}
+
+	now := time.Now()
+	end := h.QuietHours.EndAfter(now)
+	ctx = context.WithoutCancel(ctx)
+	h.deferTimer = time.AfterFunc(end.Sub(now), func() { h.runDeferredRecalculation(ctx) })
+	logger.Info().Time("deferred_until", end).Msg("Quiet hours, recalculation deferred")
+}
+
+// runDeferredRecalculation runs the recalculation deferred by the quiet hours
+func (h *WebhookHandler) runDeferredRecalculation(ctx context.Context) {
+	h.deferMu.Lock()
+	fromDate := h.deferredFrom
+	h.deferredFrom = time.Time{}
+	h.deferTimer = nil
+	h.deferMu.Unlock()
+	if fromDate.IsZero() {
+		return
+	}
+
+	logger := h.logger.With().Str("from_date", fromDate.Format("2006-01-02")).Logger()
+	logger.Info().Msg("Quiet hours over, recalculating schedule due to overrides")
+	if err := h.recalculateSchedule(ctx, fromDate); err != nil {
+		logger.Error().Err(err).Msg("Error recalculating schedule after the quiet hours")
+		return
+	}
+	logger.Info().Msg("Successfully recalculated schedule after the quiet hours")
+}
+
+// eventVersion returns the version of a Google Calendar event; the updated time is zero when
+// Google did not send a valid one
+func eventVersion(event *gcalendar.Event) fairness.EventVersion {
+	version := fairness.EventVersion{ETag: event.Etag}
+	if updated, err := time.Parse(time.RFC3339Nano, event.Updated); err == nil {
+		version.Updated = updated
+	}
+	return version
+}
+
 type parsedManagedAssignee struct {
 	Name          string
 	CaregiverType fairness.CaregiverType
 }
 
-func parseManagedEventAssignee(summary, parentA, parentB string) (parsedManagedAssignee, bool) {
+// parseManagedEventAssignee reads the caregiver of a managed event from its summary: a name of the
+// roster in brackets is a parent, any other name a babysitter. Without roster every bracketed name
+// is taken for a parent.
+func parseManagedEventAssignee(summary string, roster []string) (parsedManagedAssignee, bool) {
 	trimmedSummary := strings.TrimSpace(summary)
 	if trimmedSummary == "" {
 		return parsedManagedAssignee{}, false
@@ -357,11 +663,7 @@ func parseManagedEventAssignee(summary, parentA, parentB string) (parsedManagedA
 				return parsedManagedAssignee{}, false
 			}
 
-			if parentA == "" && parentB == "" {
-				return parsedManagedAssignee{Name: name, CaregiverType: fairness.CaregiverTypeParent}, true
-			}
-
-			if name == parentA || name == parentB {
+			if len(roster) == 0 || slices.Contains(roster, name) {
 				return parsedManagedAssignee{Name: name, CaregiverType: fairness.CaregiverTypeParent}, true
 			}
 
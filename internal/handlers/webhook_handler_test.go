@@ -4,17 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	gcalendar "google.golang.org/api/calendar/v3"
 
+	"github.com/belphemur/night-routine/internal/config"
 	"github.com/belphemur/night-routine/internal/constants"
 	"github.com/belphemur/night-routine/internal/database"
 	"github.com/belphemur/night-routine/internal/fairness"
 	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
 	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -26,67 +31,77 @@ type MockTracker struct {
 	mock.Mock
 }
 
-func (m *MockTracker) GetLastAssignmentDate() (time.Time, error) {
+func (m *MockTracker) GetLastAssignmentDate(_ context.Context) (time.Time, error) {
 	args := m.Called()
 	return args.Get(0).(time.Time), args.Error(1)
 }
 
-func (m *MockTracker) RecordAssignment(parent string, date time.Time, override bool, decisionReason fairness.DecisionReason) (*fairness.Assignment, error) {
+func (m *MockTracker) GetAssignmentsVersion(_ context.Context) (fairness.AssignmentsVersion, error) {
+	args := m.Called()
+	return args.Get(0).(fairness.AssignmentsVersion), args.Error(1)
+}
+
+func (m *MockTracker) RecordAssignment(_ context.Context, parent string, date time.Time, override bool, decisionReason fairness.DecisionReason) (*fairness.Assignment, error) {
 	args := m.Called(parent, date, override, decisionReason)
 	return args.Get(0).(*fairness.Assignment), args.Error(1)
 }
 
-func (m *MockTracker) RecordBabysitterAssignment(name string, date time.Time, override bool) (*fairness.Assignment, error) {
+func (m *MockTracker) RecordBabysitterAssignment(_ context.Context, name string, date time.Time, override bool) (*fairness.Assignment, error) {
 	args := m.Called(name, date, override)
 	return args.Get(0).(*fairness.Assignment), args.Error(1)
 }
 
-func (m *MockTracker) GetLastAssignmentsUntil(n int, until time.Time) ([]*fairness.Assignment, error) {
+func (m *MockTracker) GetLastAssignmentsUntil(_ context.Context, n int, until time.Time) ([]*fairness.Assignment, error) {
 	args := m.Called(n, until)
 	return args.Get(0).([]*fairness.Assignment), args.Error(1)
 }
 
-func (m *MockTracker) GetParentStatsUntil(until time.Time, parentNames ...string) (map[string]fairness.Stats, error) {
+func (m *MockTracker) GetParentStatsUntil(_ context.Context, until time.Time, parentNames ...string) (map[string]fairness.Stats, error) {
 	args := m.Called(until, parentNames)
 	return args.Get(0).(map[string]fairness.Stats), args.Error(1)
 }
 
-func (m *MockTracker) GetAssignmentByID(id int64) (*fairness.Assignment, error) {
+func (m *MockTracker) GetWeightedParentStatsUntil(_ context.Context, until time.Time, weights config.DayWeights, parentNames ...string) (map[string]fairness.Stats, error) {
+	args := m.Called(until, weights, parentNames)
+	return args.Get(0).(map[string]fairness.Stats), args.Error(1)
+}
+
+func (m *MockTracker) GetAssignmentByID(_ context.Context, id int64) (*fairness.Assignment, error) {
 	args := m.Called(id)
 	return args.Get(0).(*fairness.Assignment), args.Error(1)
 }
 
-func (m *MockTracker) GetAssignmentByDate(date time.Time) (*fairness.Assignment, error) {
+func (m *MockTracker) GetAssignmentByDate(_ context.Context, date time.Time) (*fairness.Assignment, error) {
 	args := m.Called(date)
 	return args.Get(0).(*fairness.Assignment), args.Error(1)
 }
 
-func (m *MockTracker) UpdateAssignmentGoogleCalendarEventID(id int64, googleCalendarEventID string) error {
+func (m *MockTracker) UpdateAssignmentGoogleCalendarEventID(_ context.Context, id int64, googleCalendarEventID string) error {
 	args := m.Called(id, googleCalendarEventID)
 	return args.Error(0)
 }
 
-func (m *MockTracker) GetAssignmentByGoogleCalendarEventID(eventID string) (*fairness.Assignment, error) {
+func (m *MockTracker) GetAssignmentByGoogleCalendarEventID(_ context.Context, eventID string) (*fairness.Assignment, error) {
 	args := m.Called(eventID)
 	return args.Get(0).(*fairness.Assignment), args.Error(1)
 }
 
-func (m *MockTracker) GetAssignmentsInRange(start, end time.Time) ([]*fairness.Assignment, error) {
+func (m *MockTracker) GetAssignmentsInRange(_ context.Context, start, end time.Time) ([]*fairness.Assignment, error) {
 	args := m.Called(start, end)
 	return args.Get(0).([]*fairness.Assignment), args.Error(1)
 }
 
-func (m *MockTracker) UpdateAssignmentParent(id int64, parent string, override bool) error {
-	args := m.Called(id, parent, override)
+func (m *MockTracker) UpdateAssignmentParent(_ context.Context, id int64, parent string, override bool, version int64) error {
+	args := m.Called(id, parent, override, version)
 	return args.Error(0)
 }
 
-func (m *MockTracker) UpdateAssignmentToBabysitter(id int64, babysitterName string, override bool) error {
-	args := m.Called(id, babysitterName, override)
+func (m *MockTracker) UpdateAssignmentToBabysitter(_ context.Context, id int64, babysitterName string, override bool, version int64) error {
+	args := m.Called(id, babysitterName, override, version)
 	return args.Error(0)
 }
 
-func (m *MockTracker) GetParentMonthlyStatsForLastNMonths(referenceTime time.Time, nMonths int) ([]fairness.MonthlyStatRow, error) {
+func (m *MockTracker) GetParentMonthlyStatsForLastNMonths(_ context.Context, referenceTime time.Time, nMonths int) ([]fairness.MonthlyStatRow, error) {
 	args := m.Called(referenceTime, nMonths)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -94,7 +109,7 @@ func (m *MockTracker) GetParentMonthlyStatsForLastNMonths(referenceTime time.Tim
 	return args.Get(0).([]fairness.MonthlyStatRow), args.Error(1)
 }
 
-func (m *MockTracker) GetBabysitterMonthlyStatsForLastNMonths(referenceTime time.Time, nMonths int) ([]fairness.MonthlyStatRow, error) {
+func (m *MockTracker) GetBabysitterMonthlyStatsForLastNMonths(_ context.Context, referenceTime time.Time, nMonths int) ([]fairness.MonthlyStatRow, error) {
 	args := m.Called(referenceTime, nMonths)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -102,17 +117,27 @@ func (m *MockTracker) GetBabysitterMonthlyStatsForLastNMonths(referenceTime time
 	return args.Get(0).([]fairness.MonthlyStatRow), args.Error(1)
 }
 
-func (m *MockTracker) UnlockAssignment(id int64) error {
+func (m *MockTracker) SetAssignmentTag(_ context.Context, id int64, tag fairness.AssignmentTag) error {
+	args := m.Called(id, tag)
+	return args.Error(0)
+}
+
+func (m *MockTracker) UnlockAssignment(_ context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTracker) DeleteAssignment(_ context.Context, id int64) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockTracker) SaveAssignmentDetails(assignmentID int64, calculationDate time.Time, parentAName string, statsA fairness.Stats, parentBName string, statsB fairness.Stats) error {
+func (m *MockTracker) SaveAssignmentDetails(_ context.Context, assignmentID int64, calculationDate time.Time, parentAName string, statsA fairness.Stats, parentBName string, statsB fairness.Stats, _ ...fairness.NamedStats) error {
 	args := m.Called(assignmentID, calculationDate, parentAName, statsA, parentBName, statsB)
 	return args.Error(0)
 }
 
-func (m *MockTracker) GetAssignmentDetails(assignmentID int64) (*fairness.AssignmentDetails, error) {
+func (m *MockTracker) GetAssignmentDetails(_ context.Context, assignmentID int64) (*fairness.AssignmentDetails, error) {
 	args := m.Called(assignmentID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -120,7 +145,7 @@ func (m *MockTracker) GetAssignmentDetails(assignmentID int64) (*fairness.Assign
 	return args.Get(0).(*fairness.AssignmentDetails), args.Error(1)
 }
 
-func (m *MockTracker) SwapAssignments(parentA string, dateA time.Time, parentB string, dateB time.Time, reason fairness.DecisionReason) (*fairness.Assignment, *fairness.Assignment, error) {
+func (m *MockTracker) SwapAssignments(_ context.Context, parentA string, dateA time.Time, parentB string, dateB time.Time, reason fairness.DecisionReason) (*fairness.Assignment, *fairness.Assignment, error) {
 	args := m.Called(parentA, dateA, parentB, dateB, reason)
 	var a, b *fairness.Assignment
 	if args.Get(0) != nil {
@@ -132,9 +157,108 @@ func (m *MockTracker) SwapAssignments(parentA string, dateA time.Time, parentB s
 	return a, b, args.Error(2)
 }
 
+func (m *MockTracker) GetAppliedEventVersion(_ context.Context, eventID string) (*fairness.EventVersion, error) {
+	args := m.Called(eventID)
+	version, _ := args.Get(0).(*fairness.EventVersion)
+	return version, args.Error(1)
+}
+
+func (m *MockTracker) RecordAppliedEventVersion(_ context.Context, eventID string, assignmentID int64, version fairness.EventVersion) error {
+	args := m.Called(eventID, assignmentID, version)
+	return args.Error(0)
+}
+
+func (m *MockTracker) EditAssignments(_ context.Context, edits []fairness.AssignmentEdit) ([]fairness.EditedAssignment, error) {
+	args := m.Called(edits)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]fairness.EditedAssignment), args.Error(1)
+}
+
+func (m *MockTracker) SwapNights(_ context.Context, swap fairness.NightSwap) (*fairness.Assignment, *fairness.Assignment, error) {
+	args := m.Called(swap)
+	a, _ := args.Get(0).(*fairness.Assignment)
+	b, _ := args.Get(1).(*fairness.Assignment)
+	return a, b, args.Error(2)
+}
+
+// BeginBatch is not recorded, batches only group the changes for an undo
+func (m *MockTracker) BeginBatch(ctx context.Context, kind fairness.ChangeKind) context.Context {
+	return ctx
+}
+
+func (m *MockTracker) UndoLastBatch(_ context.Context) (*fairness.ChangeBatch, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*fairness.ChangeBatch), args.Error(1)
+}
+
+func (m *MockTracker) GetAssignmentChanges(_ context.Context, assignmentID int64) ([]fairness.AssignmentChange, error) {
+	args := m.Called(assignmentID)
+	changes, _ := args.Get(0).([]fairness.AssignmentChange)
+	return changes, args.Error(1)
+}
+
+func (m *MockTracker) LockRange(_ context.Context, start, end time.Time) (*fairness.LockedRange, error) {
+	args := m.Called(start, end)
+	lockedRange, _ := args.Get(0).(*fairness.LockedRange)
+	return lockedRange, args.Error(1)
+}
+
+func (m *MockTracker) UnlockRange(_ context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// GetLockedRanges is not recorded, no range is locked
+func (m *MockTracker) GetLockedRanges(_ context.Context) (fairness.LockedRanges, error) {
+	return nil, nil
+}
+
+// ReplaceCalendarUnavailability is not recorded
+func (m *MockTracker) ReplaceCalendarUnavailability(_ context.Context, _ string, _, _ time.Time, _ []time.Time) error {
+	return nil
+}
+
+// GetCalendarUnavailability is not recorded, no parent is unavailable from its calendar
+func (m *MockTracker) GetCalendarUnavailability(_ context.Context, _ string, _, _ time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+// GetChildren is not recorded, there is only the main rotation
+func (m *MockTracker) GetChildren(_ context.Context) ([]fairness.Child, error) {
+	return nil, nil
+}
+
+func (m *MockTracker) AddChild(_ context.Context, name string) (*fairness.Child, error) {
+	args := m.Called(name)
+	child, _ := args.Get(0).(*fairness.Child)
+	return child, args.Error(1)
+}
+
+func (m *MockTracker) DeleteChild(_ context.Context, id int64) ([]*fairness.Assignment, error) {
+	args := m.Called(id)
+	removed, _ := args.Get(0).([]*fairness.Assignment)
+	return removed, args.Error(1)
+}
+
+// ForChild returns the mock itself, the rotations share its expectations
+func (m *MockTracker) ForChild(_ fairness.Child) fairness.TrackerInterface {
+	return m
+}
+
 // MockCalendarService is a mock implementation of the calendar.CalendarService interface
 type MockCalendarService struct {
 	mock.Mock
+	OwnUpdates map[string]bool // IDs of the events whose update IsOwnUpdate reports as written by a sync
+}
+
+// IsOwnUpdate is not recorded, it reports the events of OwnUpdates
+func (m *MockCalendarService) IsOwnUpdate(event *gcalendar.Event) bool {
+	return m.OwnUpdates[event.Id]
 }
 
 func (m *MockCalendarService) Initialize(ctx context.Context) error {
@@ -147,11 +271,25 @@ func (m *MockCalendarService) IsInitialized() bool {
 	return args.Bool(0)
 }
 
+func (m *MockCalendarService) Reset() {
+	m.Called()
+}
+
 func (m *MockCalendarService) SetupNotificationChannel(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
 
+func (m *MockCalendarService) RemoveEvents(ctx context.Context, eventIDs []string) error {
+	args := m.Called(ctx, eventIDs)
+	return args.Error(0)
+}
+
+func (m *MockCalendarService) RemoveAllEvents(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
 // SyncSchedule mocks the SyncSchedule method of the CalendarService interface
 func (m *MockCalendarService) SyncSchedule(ctx context.Context, assignments []*Scheduler.Assignment) error {
 	args := m.Called(ctx, mock.Anything)
@@ -173,13 +311,23 @@ func (m *MockCalendarService) VerifyNotificationChannel(ctx context.Context, cha
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockCalendarService) RenewNotificationChannel(ctx context.Context, channelID string) error {
+	args := m.Called(ctx, channelID)
+	return args.Error(0)
+}
+
+func (m *MockCalendarService) RecreateNotificationChannel(ctx context.Context, channelID string) error {
+	args := m.Called(ctx, channelID)
+	return args.Error(0)
+}
+
 // MockScheduler is a mock implementation of the Scheduler.SchedulerInterface
 type MockScheduler struct {
 	mock.Mock
 }
 
 // GenerateSchedule mocks the GenerateSchedule method of the SchedulerInterface
-func (m *MockScheduler) GenerateSchedule(fromDate, endDate time.Time, currentTime time.Time) ([]*Scheduler.Assignment, error) {
+func (m *MockScheduler) GenerateSchedule(_ context.Context, fromDate, endDate time.Time, currentTime time.Time) ([]*Scheduler.Assignment, error) {
 	// Note: We use mock.Anything for currentTime in expectations as it's often time.Now()
 	args := m.Called(fromDate, endDate, currentTime)
 	// Ensure the returned slice is correctly typed
@@ -190,27 +338,27 @@ func (m *MockScheduler) GenerateSchedule(fromDate, endDate time.Time, currentTim
 	return nil, args.Error(1)
 }
 
-func (m *MockScheduler) UpdateAssignmentParent(id int64, parent string, override bool) error {
-	args := m.Called(id, parent, override)
+func (m *MockScheduler) UpdateAssignmentParent(_ context.Context, id int64, parent string, override bool, version int64) error {
+	args := m.Called(id, parent, override, version)
 	return args.Error(0)
 }
 
-func (m *MockScheduler) UpdateAssignmentToBabysitter(id int64, babysitterName string, override bool) error {
-	args := m.Called(id, babysitterName, override)
+func (m *MockScheduler) UpdateAssignmentToBabysitter(_ context.Context, id int64, babysitterName string, override bool, version int64) error {
+	args := m.Called(id, babysitterName, override, version)
 	return args.Error(0)
 }
 
-func (m *MockScheduler) GetAssignmentByGoogleCalendarEventID(eventID string) (*Scheduler.Assignment, error) {
+func (m *MockScheduler) GetAssignmentByGoogleCalendarEventID(_ context.Context, eventID string) (*Scheduler.Assignment, error) {
 	args := m.Called(eventID)
 	return args.Get(0).(*Scheduler.Assignment), args.Error(1)
 }
 
-func (m *MockScheduler) UpdateGoogleCalendarEventID(assignment *Scheduler.Assignment, eventID string) error {
+func (m *MockScheduler) UpdateGoogleCalendarEventID(_ context.Context, assignment *Scheduler.Assignment, eventID string) error {
 	args := m.Called(assignment, eventID)
 	return args.Error(0)
 }
 
-func (m *MockScheduler) GetAssignmentsInRange(start, end time.Time) ([]*Scheduler.Assignment, error) {
+func (m *MockScheduler) GetAssignmentsInRange(_ context.Context, start, end time.Time) ([]*Scheduler.Assignment, error) {
 	args := m.Called(start, end)
 	if assignments, ok := args.Get(0).([]*Scheduler.Assignment); ok {
 		return assignments, args.Error(1)
@@ -239,6 +387,10 @@ func (m *MockConfigStore) GetParents() (string, string, error) {
 	return args.String(0), args.String(1), args.Error(2)
 }
 
+func (m *MockConfigStore) GetExtraParents() ([]config.ExtraParent, error) {
+	return nil, nil
+}
+
 func (m *MockConfigStore) GetAvailability(parent string) ([]string, error) {
 	args := m.Called(parent)
 	if args.Get(0) == nil {
@@ -252,6 +404,47 @@ func (m *MockConfigStore) GetSchedule() (string, int, int, constants.StatsOrder,
 	return args.String(0), args.Int(1), args.Int(2), args.Get(3).(constants.StatsOrder), args.Error(4)
 }
 
+func (m *MockConfigStore) GetUnavailabilityRules(parent string) ([]config.UnavailabilityRule, error) {
+	args := m.Called(parent)
+	return args.Get(0).([]config.UnavailabilityRule), args.Error(1)
+}
+
+func (m *MockConfigStore) GetLookAheadWindows() (config.LookAheadWindows, error) {
+	args := m.Called()
+	return args.Get(0).(config.LookAheadWindows), args.Error(1)
+}
+
+func (m *MockConfigStore) GetVacation() (config.Vacation, error) {
+	args := m.Called()
+	return args.Get(0).(config.Vacation), args.Error(1)
+}
+
+func (m *MockConfigStore) GetSkipDates() (config.SkipDates, error) {
+	args := m.Called()
+	return args.Get(0).(config.SkipDates), args.Error(1)
+}
+
+func (m *MockConfigStore) GetSyncExclusions() (config.SyncExclusions, error) {
+	args := m.Called()
+	return args.Get(0).(config.SyncExclusions), args.Error(1)
+}
+
+func (m *MockConfigStore) GetMinRestDays() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockConfigStore) GetDayWeights() (config.DayWeights, error) {
+	args := m.Called()
+	weights, _ := args.Get(0).(config.DayWeights)
+	return weights, args.Error(1)
+}
+
+func (m *MockConfigStore) GetParentStyles() (config.ParentStyle, config.ParentStyle, error) {
+	args := m.Called()
+	return args.Get(0).(config.ParentStyle), args.Get(1).(config.ParentStyle), args.Error(2)
+}
+
 func (m *MockConfigStore) GetOAuthConfig() *oauth2.Config {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -278,6 +471,7 @@ func TestWebhookHandler_RecalculateSchedule(t *testing.T) {
 		name                string
 		setupMocks          func(*MockTracker, *MockScheduler, *MockCalendarService)
 		configLookAheadDays int
+		lookAheadWindows    config.LookAheadWindows
 		expectedError       string
 	}{
 		{
@@ -297,7 +491,7 @@ func TestWebhookHandler_RecalculateSchedule(t *testing.T) {
 				scheduler.On("GenerateSchedule", fromDate, lastDate, mock.AnythingOfType("time.Time")).Return(schedulerAssignments, nil)
 
 				// Set up expectations for calendar service
-				calService.On("SyncSchedule", ctx, mock.Anything).Return(nil)
+				calService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
 			},
 			configLookAheadDays: 7,
 			expectedError:       "",
@@ -319,7 +513,7 @@ func TestWebhookHandler_RecalculateSchedule(t *testing.T) {
 				scheduler.On("GenerateSchedule", fromDate, lookAheadEndDate, mock.AnythingOfType("time.Time")).Return(schedulerAssignments, nil)
 
 				// Set up expectations for calendar service
-				calService.On("SyncSchedule", ctx, mock.Anything).Return(nil)
+				calService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
 			},
 			configLookAheadDays: 7,
 			expectedError:       "",
@@ -363,11 +557,22 @@ func TestWebhookHandler_RecalculateSchedule(t *testing.T) {
 				scheduler.On("GenerateSchedule", fromDate, lastDate, mock.AnythingOfType("time.Time")).Return(schedulerAssignments, nil)
 
 				// Set up expectations for calendar service with error
-				calService.On("SyncSchedule", ctx, mock.Anything).Return(errors.New("sync error"))
+				calService.On("SyncSchedule", mock.Anything, mock.Anything).Return(errors.New("sync error"))
 			},
 			configLookAheadDays: 7,
 			expectedError:       "failed to sync schedule: sync error",
 		},
+		{
+			name: "Webhook window bounds the recalculation",
+			setupMocks: func(tracker *MockTracker, scheduler *MockScheduler, calService *MockCalendarService) {
+				// The assignments up to the last one are left to the next periodic sync
+				scheduler.On("GenerateSchedule", fromDate, fromDate.AddDate(0, 0, 3), mock.AnythingOfType("time.Time")).Return([]*Scheduler.Assignment{{GoogleCalendarEventID: "event1"}}, nil)
+				calService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
+			},
+			configLookAheadDays: 30,
+			lookAheadWindows:    config.LookAheadWindows{Webhook: 3, Manual: 60},
+			expectedError:       "",
+		},
 		{
 			name: "Success with filtered assignments",
 			setupMocks: func(tracker *MockTracker, scheduler *MockScheduler, calService *MockCalendarService) {
@@ -388,7 +593,7 @@ func TestWebhookHandler_RecalculateSchedule(t *testing.T) {
 				scheduler.On("GenerateSchedule", fromDate, lastDate, mock.AnythingOfType("time.Time")).Return(schedulerAssignments, nil)
 
 				// Set up expectations for calendar service
-				calService.On("SyncSchedule", ctx, mock.Anything).Return(nil)
+				calService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
 			},
 			configLookAheadDays: 7,
 			expectedError:       "",
@@ -410,6 +615,7 @@ func TestWebhookHandler_RecalculateSchedule(t *testing.T) {
 			// date is zero or before fromDate (test cases that return a real last date
 			// take a different branch and never call GetSchedule).
 			mockConfigStore.On("GetSchedule").Maybe().Return("daily", tt.configLookAheadDays, defaultTestThresholdDays, constants.StatsOrderDesc, nil)
+			mockConfigStore.On("GetLookAheadWindows").Return(tt.lookAheadWindows, nil)
 
 			// Create handler with mocked dependencies
 			handler := &WebhookHandler{
@@ -459,13 +665,13 @@ func TestProcessEventsIntegration(t *testing.T) {
 	// Create config store with default schedule settings
 	configStore, err := database.NewConfigStore(db)
 	require.NoError(t, err)
-	err = configStore.SaveSchedule("daily", 7, 5, constants.StatsOrderDesc)
+	err = configStore.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc)
 	require.NoError(t, err)
-	err = configStore.SaveParents("ParentA", "ParentB")
+	err = configStore.SaveParents(t.Context(), "ParentA", "ParentB")
 	require.NoError(t, err)
-	err = configStore.SaveAvailability("parent_a", []string{})
+	err = configStore.SaveAvailability(t.Context(), "parent_a", []string{})
 	require.NoError(t, err)
-	err = configStore.SaveAvailability("parent_b", []string{})
+	err = configStore.SaveAvailability(t.Context(), "parent_b", []string{})
 	require.NoError(t, err)
 
 	// Create real tracker and scheduler
@@ -510,11 +716,11 @@ func TestProcessEventsIntegration(t *testing.T) {
 		}
 
 		// First, create assignment that this event will reference
-		assignment1, err := tracker.RecordAssignment("OriginalParent1", time.Now().AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
+		assignment1, err := tracker.RecordAssignment(t.Context(), "OriginalParent1", time.Now().AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
 		require.NoError(t, err)
 
 		// Update assignment with Google Calendar event ID
-		err = tracker.UpdateAssignmentGoogleCalendarEventID(assignment1.ID, "event1")
+		err = tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment1.ID, "event1")
 		require.NoError(t, err)
 
 		// Count assignments before transaction
@@ -792,12 +998,12 @@ func TestProcessEventsWithinTransaction_PastEventThreshold(t *testing.T) {
 			assignmentDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -tt.assignmentDaysAgo)
 
 			// Create assignment in the database
-			assignment, err := tracker.RecordAssignment("OriginalParent", assignmentDate, false, fairness.DecisionReasonTotalCount)
+			assignment, err := tracker.RecordAssignment(t.Context(), "OriginalParent", assignmentDate, false, fairness.DecisionReasonTotalCount)
 			require.NoError(t, err)
 
 			// Update assignment with Google Calendar event ID
 			eventID := "test_event_" + tt.name
-			err = tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, eventID)
+			err = tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, eventID)
 			require.NoError(t, err)
 
 			// Create real scheduler — uses mockConfigStore for parent/availability lookups
@@ -805,6 +1011,13 @@ func TestProcessEventsWithinTransaction_PastEventThreshold(t *testing.T) {
 			mockConfigStore.On("GetSchedule").Return("daily", 7, tt.thresholdDays, constants.StatsOrderDesc, nil)
 			mockConfigStore.On("GetParents").Return("OriginalParent", "NewParent", nil)
 			mockConfigStore.On("GetAvailability", mock.Anything).Maybe().Return([]string{}, nil)
+			mockConfigStore.On("GetUnavailabilityRules", mock.Anything).Maybe().Return([]config.UnavailabilityRule{}, nil)
+			mockConfigStore.On("GetVacation").Maybe().Return(config.Vacation{}, nil)
+			mockConfigStore.On("GetSkipDates").Maybe().Return(config.SkipDates(nil), nil)
+			mockConfigStore.On("GetSyncExclusions").Maybe().Return(config.SyncExclusions{}, nil)
+			mockConfigStore.On("GetMinRestDays").Maybe().Return(0, nil)
+			mockConfigStore.On("GetDayWeights").Maybe().Return(nil, nil)
+			mockConfigStore.On("GetLookAheadWindows").Maybe().Return(config.LookAheadWindows{}, nil)
 			scheduler := Scheduler.New(mockConfigStore, tracker)
 
 			// Create mock calendar service
@@ -847,7 +1060,7 @@ func TestProcessEventsWithinTransaction_PastEventThreshold(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Verify the assignment was updated or not based on threshold
-			updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+			updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 			require.NoError(t, err)
 
 			if tt.expectedProcessed {
@@ -884,13 +1097,13 @@ func TestWebhookHandler_DynamicConfigReading(t *testing.T) {
 	// Set up the live config store with an initial threshold of 3 days
 	configStore, err := database.NewConfigStore(db)
 	require.NoError(t, err)
-	err = configStore.SaveSchedule("daily", 7, 3, constants.StatsOrderDesc)
+	err = configStore.SaveSchedule(t.Context(), "daily", 7, 3, constants.StatsOrderDesc)
 	require.NoError(t, err)
-	err = configStore.SaveParents("ParentA", "ParentB")
+	err = configStore.SaveParents(t.Context(), "ParentA", "ParentB")
 	require.NoError(t, err)
-	err = configStore.SaveAvailability("parent_a", []string{})
+	err = configStore.SaveAvailability(t.Context(), "parent_a", []string{})
 	require.NoError(t, err)
-	err = configStore.SaveAvailability("parent_b", []string{})
+	err = configStore.SaveAvailability(t.Context(), "parent_b", []string{})
 	require.NoError(t, err)
 
 	tracker, err := fairness.New(db)
@@ -920,10 +1133,10 @@ func TestWebhookHandler_DynamicConfigReading(t *testing.T) {
 
 	// Create an assignment 5 days ago — outside the initial 3-day threshold
 	assignmentDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -5)
-	assignment, err := tracker.RecordAssignment("OriginalParent", assignmentDate, false, fairness.DecisionReasonTotalCount)
+	assignment, err := tracker.RecordAssignment(t.Context(), "OriginalParent", assignmentDate, false, fairness.DecisionReasonTotalCount)
 	require.NoError(t, err)
 	eventID := "dynamic_test_event"
-	err = tracker.UpdateAssignmentGoogleCalendarEventID(assignment.ID, eventID)
+	err = tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, eventID)
 	require.NoError(t, err)
 
 	events := []*gcalendar.Event{
@@ -943,7 +1156,7 @@ func TestWebhookHandler_DynamicConfigReading(t *testing.T) {
 		err = handler.processEvents(ctx, events, handler.logger)
 		require.NoError(t, err)
 
-		updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+		updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 		require.NoError(t, err)
 		assert.Equal(t, "OriginalParent", updatedAssignment.Parent, "should not update: assignment is outside the 3-day threshold")
 		assert.False(t, updatedAssignment.Override)
@@ -951,16 +1164,484 @@ func TestWebhookHandler_DynamicConfigReading(t *testing.T) {
 
 	// Simulate the user updating "Past Event Threshold (Days)" to 7 via the settings UI.
 	// The handler is NOT restarted — it must pick up the new value dynamically.
-	err = configStore.SaveSchedule("daily", 7, 7, constants.StatsOrderDesc)
+	err = configStore.SaveSchedule(t.Context(), "daily", 7, 7, constants.StatsOrderDesc)
 	require.NoError(t, err)
 
 	t.Run("Accepts same event after threshold is updated to 7 days (no restart needed)", func(t *testing.T) {
 		err = handler.processEvents(ctx, events, handler.logger)
 		require.NoError(t, err)
 
-		updatedAssignment, err := tracker.GetAssignmentByID(assignment.ID)
+		updatedAssignment, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
 		require.NoError(t, err)
 		assert.Equal(t, "NewParent", updatedAssignment.Parent, "should update: assignment is now within the 7-day threshold")
 		assert.True(t, updatedAssignment.Override, "override flag should be set after parent change")
 	})
 }
+
+// TestProcessEvents_RecalculatesOncePerBatch verifies that several overrides received together
+// lead to a single recalculation and sync
+func TestProcessEvents_RecalculatesOncePerBatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_webhook_batch.db")
+
+	db, err := database.New(database.NewDefaultOptions(dbPath))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc))
+	require.NoError(t, configStore.SaveParents(t.Context(), "ParentA", "ParentB"))
+	require.NoError(t, configStore.SaveAvailability(t.Context(), "parent_a", []string{}))
+	require.NoError(t, configStore.SaveAvailability(t.Context(), "parent_b", []string{}))
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	configAdapter := database.NewConfigAdapter(configStore, nil)
+
+	mockCalService := &MockCalendarService{}
+	mockCalService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
+
+	handler := &WebhookHandler{
+		BaseHandler: &BaseHandler{
+			Tracker:     tracker,
+			ConfigStore: configAdapter,
+		},
+		Scheduler:       Scheduler.New(configAdapter, tracker),
+		CalendarService: mockCalService,
+		ConfigStore:     configAdapter,
+		logger:          logging.GetLogger("webhook-test"),
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var events []*gcalendar.Event
+	for i, parent := range []string{"ParentA", "ParentB"} {
+		assignment, err := tracker.RecordAssignment(t.Context(), parent, today.AddDate(0, 0, i+1), false, fairness.DecisionReasonTotalCount)
+		require.NoError(t, err)
+		eventID := "batch_event_" + parent
+		require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, eventID))
+
+		other := "ParentB"
+		if parent == "ParentB" {
+			other = "ParentA"
+		}
+		events = append(events, &gcalendar.Event{
+			Id:      eventID,
+			Status:  "confirmed",
+			Summary: "[" + other + "] 🌃👶Routine",
+			ExtendedProperties: &gcalendar.EventExtendedProperties{
+				Private: map[string]string{
+					"app": constants.NightRoutineIdentifier,
+				},
+			},
+		})
+	}
+
+	require.NoError(t, handler.processEvents(context.Background(), events, handler.logger))
+	mockCalService.AssertNumberOfCalls(t, "SyncSchedule", 1)
+}
+
+type recordingSubscriptionMailer struct {
+	subscriptions []config.EmailSubscription
+	events        []notify.Event
+	data          []any
+}
+
+func (m *recordingSubscriptionMailer) EmailSubscribers(_ context.Context, subscription config.EmailSubscription, event notify.Event, data any) error {
+	m.subscriptions = append(m.subscriptions, subscription)
+	m.events = append(m.events, event)
+	m.data = append(m.data, data)
+	return errors.New("smtp unreachable")
+}
+
+// TestProcessEvents_EmailsOverrideNotices verifies that an override from Google Calendar is emailed
+// to the subscribed parents, and that a failing email does not fail the processing
+func TestProcessEvents_EmailsOverrideNotices(t *testing.T) {
+	db, err := database.New(database.NewDefaultOptions(filepath.Join(t.TempDir(), "test_webhook_notices.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc))
+	require.NoError(t, configStore.SaveParents(t.Context(), "ParentA", "ParentB"))
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	configAdapter := database.NewConfigAdapter(configStore, nil)
+
+	mockCalService := &MockCalendarService{}
+	mockCalService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
+
+	mailer := &recordingSubscriptionMailer{}
+	handler := &WebhookHandler{
+		BaseHandler: &BaseHandler{
+			Tracker:     tracker,
+			ConfigStore: configAdapter,
+		},
+		Scheduler:       Scheduler.New(configAdapter, tracker),
+		CalendarService: mockCalService,
+		ConfigStore:     configAdapter,
+		OverrideNotices: mailer,
+		AppURL:          "https://night-routine.example.com/",
+		logger:          logging.GetLogger("webhook-test"),
+	}
+
+	now := time.Now()
+	night := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", night, false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "notice_event"))
+	events := []*gcalendar.Event{{
+		Id:                 "notice_event",
+		Status:             "confirmed",
+		Summary:            "[ParentB] 🌃👶Routine",
+		ExtendedProperties: &gcalendar.EventExtendedProperties{Private: map[string]string{"app": constants.NightRoutineIdentifier}},
+	}}
+
+	require.NoError(t, handler.processEvents(context.Background(), events, handler.logger))
+	require.Len(t, mailer.events, 1)
+	assert.Equal(t, config.EmailOverrideNotices, mailer.subscriptions[0])
+	assert.Equal(t, notify.EventCalendarOverride, mailer.events[0])
+	assert.Equal(t, notify.CalendarOverrideData{
+		Date:              night.Format("Monday 2 January"),
+		Caregiver:         "ParentB",
+		PreviousCaregiver: "ParentA",
+		AppURL:            "https://night-routine.example.com",
+	}, mailer.data[0])
+
+	// The same version delivered again is not emailed again
+	require.NoError(t, handler.processEvents(context.Background(), events, handler.logger))
+	assert.Len(t, mailer.events, 1)
+}
+
+// TestProcessEvents_DeferredByQuietHours verifies that an override received within the quiet hours is
+// recorded at once while its recalculation and sync wait for the end of the quiet hours
+func TestProcessEvents_DeferredByQuietHours(t *testing.T) {
+	db, err := database.New(database.NewDefaultOptions(filepath.Join(t.TempDir(), "test_webhook_quiet.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc))
+	require.NoError(t, configStore.SaveParents(t.Context(), "ParentA", "ParentB"))
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	configAdapter := database.NewConfigAdapter(configStore, nil)
+
+	mockCalService := &MockCalendarService{}
+	mockCalService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
+
+	// Quiet hours around the current time
+	now := time.Now()
+	quietHours, err := config.ParseQuietHours(now.Add(-time.Hour).Format("15:04") + "-" + now.Add(time.Hour).Format("15:04"))
+	require.NoError(t, err)
+
+	handler := &WebhookHandler{
+		BaseHandler: &BaseHandler{
+			Tracker:     tracker,
+			ConfigStore: configAdapter,
+		},
+		Scheduler:       Scheduler.New(configAdapter, tracker),
+		CalendarService: mockCalService,
+		ConfigStore:     configAdapter,
+		QuietHours:      quietHours,
+		logger:          logging.GetLogger("webhook-test"),
+	}
+	defer handler.Close()
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", today.AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "quiet_event"))
+	events := []*gcalendar.Event{{
+		Id:                 "quiet_event",
+		Status:             "confirmed",
+		Summary:            "[ParentB] 🌃👶Routine",
+		ExtendedProperties: &gcalendar.EventExtendedProperties{Private: map[string]string{"app": constants.NightRoutineIdentifier}},
+	}}
+
+	require.NoError(t, handler.processEvents(context.Background(), events, handler.logger))
+	updated, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ParentB", updated.Parent, "the override is recorded at once")
+	assert.True(t, updated.Override)
+	mockCalService.AssertNotCalled(t, "SyncSchedule", mock.Anything, mock.Anything)
+
+	handler.deferMu.Lock()
+	assert.Equal(t, assignment.Date, handler.deferredFrom)
+	assert.NotNil(t, handler.deferTimer)
+	handler.deferTimer.Stop()
+	handler.deferMu.Unlock()
+
+	handler.runDeferredRecalculation(context.Background())
+	mockCalService.AssertNumberOfCalls(t, "SyncSchedule", 1)
+}
+
+// TestProcessEvents_SkipsAppliedEventVersions verifies that a notification delivered again with an
+// event version already applied does not override the night again, while a newer version does
+func TestProcessEvents_SkipsAppliedEventVersions(t *testing.T) {
+	db, err := database.New(database.NewDefaultOptions(filepath.Join(t.TempDir(), "test_webhook_versions.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc))
+	require.NoError(t, configStore.SaveParents(t.Context(), "ParentA", "ParentB"))
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	configAdapter := database.NewConfigAdapter(configStore, nil)
+
+	mockCalService := &MockCalendarService{}
+	mockCalService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
+
+	handler := &WebhookHandler{
+		BaseHandler: &BaseHandler{
+			Tracker:     tracker,
+			ConfigStore: configAdapter,
+		},
+		Scheduler:       Scheduler.New(configAdapter, tracker),
+		CalendarService: mockCalService,
+		ConfigStore:     configAdapter,
+		logger:          logging.GetLogger("webhook-test"),
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", today.AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "versioned_event"))
+	event := func(etag string, updated time.Time) []*gcalendar.Event {
+		return []*gcalendar.Event{{
+			Id:                 "versioned_event",
+			Etag:               etag,
+			Updated:            updated.UTC().Format(time.RFC3339Nano),
+			Status:             "confirmed",
+			Summary:            "[ParentB] 🌃👶Routine",
+			ExtendedProperties: &gcalendar.EventExtendedProperties{Private: map[string]string{"app": constants.NightRoutineIdentifier}},
+		}}
+	}
+	parentOfNight := func() string {
+		night, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+		require.NoError(t, err)
+		return night.Parent
+	}
+	giveNightBackToParentA := func() {
+		night, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+		require.NoError(t, err)
+		require.NoError(t, tracker.UpdateAssignmentParent(t.Context(), night.ID, "ParentA", false, night.Version))
+	}
+
+	first := now.Add(-time.Minute)
+	require.NoError(t, handler.processEvents(t.Context(), event(`"1"`, first), handler.logger))
+	assert.Equal(t, "ParentB", parentOfNight())
+	mockCalService.AssertNumberOfCalls(t, "SyncSchedule", 1)
+
+	// The night changes again before Google delivers the same version once more
+	giveNightBackToParentA()
+	require.NoError(t, handler.processEvents(t.Context(), event(`"1"`, first), handler.logger))
+	assert.Equal(t, "ParentA", parentOfNight(), "an applied version is not applied again")
+	mockCalService.AssertNumberOfCalls(t, "SyncSchedule", 1)
+
+	require.NoError(t, handler.processEvents(t.Context(), event(`"2"`, now), handler.logger))
+	assert.Equal(t, "ParentB", parentOfNight(), "a newer version is applied")
+	mockCalService.AssertNumberOfCalls(t, "SyncSchedule", 2)
+}
+
+func TestProcessEvents_SkipsOwnUpdates(t *testing.T) {
+	db, err := database.New(database.NewDefaultOptions(filepath.Join(t.TempDir(), "test_webhook_own_updates.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc))
+	require.NoError(t, configStore.SaveParents(t.Context(), "ParentA", "ParentB"))
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	configAdapter := database.NewConfigAdapter(configStore, nil)
+
+	// The summary of own_event was just written by a sync, and has not caught up with the schedule yet
+	mockCalService := &MockCalendarService{OwnUpdates: map[string]bool{"own_event": true}}
+	mockCalService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
+
+	handler := &WebhookHandler{
+		BaseHandler: &BaseHandler{
+			Tracker:     tracker,
+			ConfigStore: configAdapter,
+		},
+		Scheduler:       Scheduler.New(configAdapter, tracker),
+		CalendarService: mockCalService,
+		ConfigStore:     configAdapter,
+		logger:          logging.GetLogger("webhook-test"),
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", today.AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, "own_event"))
+
+	events := []*gcalendar.Event{{
+		Id:                 "own_event",
+		Updated:            now.UTC().Format(time.RFC3339Nano),
+		Status:             "confirmed",
+		Summary:            "[ParentB] 🌃👶Routine",
+		ExtendedProperties: &gcalendar.EventExtendedProperties{Private: map[string]string{"app": constants.NightRoutineIdentifier}},
+	}}
+	require.NoError(t, handler.processEvents(t.Context(), events, handler.logger))
+
+	night, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ParentA", night.Parent, "an update written by a sync is not an override")
+	assert.False(t, night.Override)
+	mockCalService.AssertNotCalled(t, "SyncSchedule", mock.Anything, mock.Anything)
+}
+
+// notificationRequest returns a Google Calendar change notification of channel-1 and resource-1
+func notificationRequest(method, state, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/webhook/calendar", strings.NewReader(body))
+	req.Header.Set("X-Goog-Channel-ID", "channel-1")
+	req.Header.Set("X-Goog-Resource-ID", "resource-1")
+	req.Header.Set("X-Goog-Resource-State", state)
+	req.Header.Set("X-Goog-Message-Number", "1")
+	return req
+}
+
+// TestHandleCalendarWebhook_RejectsInvalidRequests verifies that requests which are not bodiless
+// POSTs with valid Google headers are rejected before the notification channel is looked up
+func TestHandleCalendarWebhook_RejectsInvalidRequests(t *testing.T) {
+	// No token store: a request reaching the channel lookup would panic
+	handler := &WebhookHandler{BaseHandler: &BaseHandler{}, logger: logging.GetLogger("webhook-test")}
+
+	tests := []struct {
+		name     string
+		request  func() *http.Request
+		wantCode int
+	}{
+		{
+			name:     "wrong method",
+			request:  func() *http.Request { return notificationRequest(http.MethodGet, "exists", "") },
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name: "missing channel ID",
+			request: func() *http.Request {
+				req := notificationRequest(http.MethodPost, "exists", "")
+				req.Header.Del("X-Goog-Channel-ID")
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "repeated resource ID",
+			request: func() *http.Request {
+				req := notificationRequest(http.MethodPost, "exists", "")
+				req.Header.Add("X-Goog-Resource-ID", "resource-2")
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "channel ID too long",
+			request: func() *http.Request {
+				req := notificationRequest(http.MethodPost, "exists", "")
+				req.Header.Set("X-Goog-Channel-ID", strings.Repeat("a", maxWebhookHeaderLength+1))
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "channel ID with spaces",
+			request: func() *http.Request {
+				req := notificationRequest(http.MethodPost, "exists", "")
+				req.Header.Set("X-Goog-Channel-ID", "channel 1")
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown resource state",
+			request:  func() *http.Request { return notificationRequest(http.MethodPost, "deleted", "") },
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid message number",
+			request: func() *http.Request {
+				req := notificationRequest(http.MethodPost, "exists", "")
+				req.Header.Set("X-Goog-Message-Number", "-1")
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unexpected body",
+			request:  func() *http.Request { return notificationRequest(http.MethodPost, "exists", `{"kind":"event"}`) },
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "body too large",
+			request: func() *http.Request {
+				return notificationRequest(http.MethodPost, "exists", strings.Repeat("a", maxWebhookBodyBytes+1))
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "body too large without length",
+			request: func() *http.Request {
+				req := notificationRequest(http.MethodPost, "exists", strings.Repeat("a", maxWebhookBodyBytes+1))
+				req.ContentLength = -1
+				return req
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.handleCalendarWebhook(w, tt.request())
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+// TestHandleCalendarWebhook_SyncNotification verifies that a valid notification reaches the channel
+// check, and is acknowledged for a known channel
+func TestHandleCalendarWebhook_SyncNotification(t *testing.T) {
+	db, err := database.New(database.NewDefaultOptions(filepath.Join(t.TempDir(), "webhook.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	require.NoError(t, tokenStore.SaveNotificationChannel(&database.NotificationChannel{
+		ID:         "channel-1",
+		ResourceID: "resource-1",
+		CalendarID: "primary",
+		Expiration: time.Now().Add(30 * 24 * time.Hour),
+	}))
+
+	handler := &WebhookHandler{BaseHandler: &BaseHandler{TokenStore: tokenStore}, logger: logging.GetLogger("webhook-test")}
+
+	w := httptest.NewRecorder()
+	handler.handleCalendarWebhook(w, notificationRequest(http.MethodPost, "sync", ""))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req := notificationRequest(http.MethodPost, "sync", "")
+	req.Header.Set("X-Goog-Resource-ID", "resource-2")
+	w = httptest.NewRecorder()
+	handler.handleCalendarWebhook(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "the resource ID must match the channel")
+}
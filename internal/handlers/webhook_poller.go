@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/features"
+)
+
+// pollMargin is how far before the previous poll the changed events are listed again, for the clock
+// skew with Google; the event versions already applied are skipped
+const pollMargin = 2 * time.Minute
+
+// StartPolling lists the events of the selected calendar changed since the previous poll every
+// interval until ctx is cancelled, so that the overrides made in Google Calendar are still detected
+// when the change notifications cannot reach public_url. A poll is skipped while a notification
+// channel of the calendar delivers notifications: the changes reach the webhook then.
+func (h *WebhookHandler) StartPolling(ctx context.Context, interval time.Duration) {
+	pollLogger := h.logger.With().Dur("interval", interval).Logger()
+	pollLogger.Info().Msg("Polling the calendar for changed events as fallback of the notifications")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				pollLogger.Info().Msg("Stopped polling the calendar")
+				return
+			case <-ticker.C:
+				if err := h.poll(ctx, interval); err != nil {
+					pollLogger.Error().Err(err).Msg("Failed to poll the calendar for changed events")
+				}
+			}
+		}
+	}()
+}
+
+// poll processes the events of the selected calendar changed since the previous poll, the first
+// one looking interval back. A failed poll lists the same changes again the next time. Nothing is
+// polled while the webhook_polling feature flag is disabled.
+func (h *WebhookHandler) poll(ctx context.Context, interval time.Duration) error {
+	if !h.Features.Enabled(features.WebhookPolling) {
+		h.logger.Debug().Msg("Polling disabled by its feature flag")
+		return nil
+	}
+	calendarID, err := h.TokenStore.GetSelectedCalendar()
+	if err != nil {
+		return fmt.Errorf("failed to get selected calendar: %w", err)
+	}
+	if calendarID == "" {
+		h.logger.Debug().Msg("No calendar selected, nothing to poll")
+		return nil
+	}
+	pollLogger := h.logger.With().Str("calendar_id", calendarID).Logger()
+
+	h.processMu.Lock()
+	defer h.processMu.Unlock()
+
+	now := time.Now()
+	since := h.polledAt
+	if since.IsZero() {
+		since = now.Add(-interval)
+	}
+	notifiedAt, err := h.lastNotification(calendarID)
+	if err != nil {
+		pollLogger.Warn().Err(err).Msg("Failed to get the notification channels, polling anyway")
+	} else if notifiedAt.After(since) {
+		pollLogger.Debug().Time("last_notification", notifiedAt).Msg("The notification channel delivers the changes, skipping the poll")
+		h.polledAt = notifiedAt
+		return nil
+	}
+
+	pollLogger.Debug().Time("since", since).Msg("Polling the calendar for changed events")
+	events, err := h.listChangedEvents(ctx, calendarID, since.Add(-pollMargin), pollLogger)
+	if err != nil {
+		return err
+	}
+	if len(events) > 0 {
+		if err := h.processEvents(ctx, events, pollLogger); err != nil {
+			return err
+		}
+	}
+	h.polledAt = now
+	return nil
+}
+
+// lastNotification returns when an active notification channel of calendarID last delivered a
+// notification, zero when none did
+func (h *WebhookHandler) lastNotification(calendarID string) (time.Time, error) {
+	channels, err := h.TokenStore.GetActiveNotificationChannels()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for _, channel := range channels {
+		if channel.CalendarID == calendarID && channel.LastNotificationAt != nil && channel.LastNotificationAt.After(last) {
+			last = *channel.LastNotificationAt
+		}
+	}
+	return last, nil
+}
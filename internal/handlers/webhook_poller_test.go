@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	Scheduler "github.com/belphemur/night-routine/internal/fairness/scheduler"
+	"github.com/belphemur/night-routine/internal/fakecalendar"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	gcalendar "google.golang.org/api/calendar/v3"
+)
+
+// TestWebhookHandler_Poll verifies that a poll applies the overrides made in the calendar since the
+// previous one, and that it is skipped while the notification channel delivers the changes
+func TestWebhookHandler_Poll(t *testing.T) {
+	db, err := database.New(database.NewDefaultOptions(filepath.Join(t.TempDir(), "test_webhook_poll.db")))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.MigrateDatabase())
+
+	configStore, err := database.NewConfigStore(db)
+	require.NoError(t, err)
+	require.NoError(t, configStore.SaveSchedule(t.Context(), "daily", 7, 5, constants.StatsOrderDesc))
+	require.NoError(t, configStore.SaveParents(t.Context(), "ParentA", "ParentB"))
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", TokenType: "Bearer"}))
+	require.NoError(t, tokenStore.SaveSelectedCalendar(fakecalendar.PrimaryCalendarID))
+
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	oauthCfg := &oauth2.Config{}
+	configAdapter := database.NewConfigAdapter(configStore, oauthCfg)
+	mockCalService := &MockCalendarService{}
+	mockCalService.On("SyncSchedule", mock.Anything, mock.Anything).Return(nil)
+
+	fake := fakecalendar.New()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	handler := &WebhookHandler{
+		BaseHandler: &BaseHandler{
+			Tracker:     tracker,
+			TokenStore:  tokenStore,
+			ConfigStore: configAdapter,
+		},
+		Scheduler:        Scheduler.New(configAdapter, tracker),
+		CalendarService:  mockCalService,
+		TokenManager:     token.NewTokenManager(tokenStore, oauthCfg),
+		ConfigStore:      configAdapter,
+		CalendarEndpoint: server.URL + "/",
+		logger:           logging.GetLogger("webhook-test"),
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	assignment, err := tracker.RecordAssignment(t.Context(), "ParentA", today.AddDate(0, 0, 1), false, fairness.DecisionReasonTotalCount)
+	require.NoError(t, err)
+	event := fake.AddEvent(fakecalendar.PrimaryCalendarID, &gcalendar.Event{
+		Summary:            "[ParentB] 🌃👶Routine",
+		ExtendedProperties: &gcalendar.EventExtendedProperties{Private: map[string]string{"app": constants.NightRoutineIdentifier}},
+	})
+	require.NoError(t, tracker.UpdateAssignmentGoogleCalendarEventID(t.Context(), assignment.ID, event.Id))
+
+	require.NoError(t, handler.poll(t.Context(), time.Hour))
+	night, err := tracker.GetAssignmentByID(t.Context(), assignment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ParentB", night.Parent, "the event edited in the calendar is an override")
+	assert.True(t, night.Override)
+	assert.False(t, handler.polledAt.IsZero())
+
+	// A notification delivered since the previous poll: the channel works, nothing is listed
+	polledAt := handler.polledAt
+	require.NoError(t, tokenStore.SaveNotificationChannel(&database.NotificationChannel{
+		ID:         "channel-1",
+		ResourceID: "resource-1",
+		CalendarID: fakecalendar.PrimaryCalendarID,
+		Expiration: now.Add(24 * time.Hour),
+	}))
+	notifiedAt := polledAt.Add(2 * time.Second)
+	require.NoError(t, tokenStore.RecordNotificationReceived("channel-1", notifiedAt))
+	server.Close()
+	require.NoError(t, handler.poll(t.Context(), time.Hour), "the calendar is not called")
+	assert.WithinDuration(t, notifiedAt, handler.polledAt, time.Second)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/calendar"
+	"github.com/belphemur/night-routine/internal/signals"
+)
+
+// wipeConfirmation must be sent as confirm to wipe the data, so that it is never wiped by mistake
+const wipeConfirmation = "wipe everything"
+
+// DataWiper deletes all the data of the application, implemented by database.DB
+type DataWiper interface {
+	Wipe(ctx context.Context) error
+}
+
+// WipeHandler deletes everything the application stored, in Google Calendar and in its database,
+// for the households decommissioning it
+type WipeHandler struct {
+	*BaseHandler
+	CalendarService calendar.CalendarService
+	data            DataWiper
+}
+
+// NewWipeHandler creates a new handler wiping all the data
+func NewWipeHandler(baseHandler *BaseHandler, calSvc calendar.CalendarService, data DataWiper) *WipeHandler {
+	return &WipeHandler{
+		BaseHandler:     baseHandler,
+		CalendarService: calSvc,
+		data:            data,
+	}
+}
+
+// RegisterRoutes registers the wipe routes
+func (h *WipeHandler) RegisterRoutes() {
+	handleMethods(http.DefaultServeMux, "/api/admin/wipe", h.handleWipe, http.MethodPost)
+}
+
+// WipeRequest is the JSON body of a wipe
+type WipeRequest struct {
+	Confirm string `json:"confirm"` // Must be "wipe everything"
+}
+
+// WipeResponse is the JSON response of a wipe
+type WipeResponse struct {
+	EventsDeleted int      `json:"events_deleted"`
+	Warnings      []string `json:"warnings"` // Steps in Google that failed; the database is wiped anyway
+}
+
+// handleWipe deletes the events of the application from the selected calendar, stops the
+// notification channels, revokes the Google token, then empties every table of the database. The
+// steps in Google are done while the token is valid and do not stop the wipe when they fail: they
+// are reported as warnings to be finished by hand. The wipe always requires Google Calendar to be
+// authenticated like the other admin APIs: a disconnected instance must be connected again before
+// it can be wiped, so that no client reaching the server can empty its database.
+func (h *WipeHandler) handleWipe(w http.ResponseWriter, r *http.Request) {
+	handlerLogger := h.logger.With().Str("handler", "handleWipe").Logger()
+
+	if !h.CheckAuthentication(r.Context(), handlerLogger) {
+		writeError(w, http.StatusUnauthorized, apierror.CodeAuthenticationRequired, "Unauthorized", handlerLogger)
+		return
+	}
+
+	var req WipeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Confirm != wipeConfirmation {
+		writeError(w, http.StatusBadRequest, apierror.CodeInvalidRequest, `confirm must be "`+wipeConfirmation+`"`, handlerLogger)
+		return
+	}
+
+	// The wipe goes on when the client goes away, a half-wiped instance helps no one
+	ctx := context.WithoutCancel(r.Context())
+	response := WipeResponse{Warnings: []string{}}
+	warn := func(err error, message string) {
+		handlerLogger.Warn().Err(err).Msg(message + ", continuing with the wipe")
+		response.Warnings = append(response.Warnings, message+": "+err.Error())
+	}
+
+	if !h.CalendarService.IsInitialized() {
+		if err := h.CalendarService.Initialize(ctx); err != nil {
+			warn(err, "Failed to initialize the calendar service")
+		}
+	}
+	if h.CalendarService.IsInitialized() {
+		deleted, err := h.CalendarService.RemoveAllEvents(ctx)
+		response.EventsDeleted = deleted
+		if err != nil {
+			warn(err, "Failed to delete the events")
+		}
+		if err := h.CalendarService.StopAllNotificationChannels(ctx); err != nil {
+			warn(err, "Failed to stop the notification channels")
+		}
+	}
+	if err := h.TokenManager.RevokeToken(ctx); err != nil {
+		warn(err, "Failed to revoke the token at Google")
+	}
+	h.CalendarService.Reset()
+	// The token may be kept out of the database, in the OS keyring
+	if err := h.TokenManager.ClearToken(ctx); err != nil {
+		warn(err, "Failed to clear the token")
+	}
+
+	if err := h.data.Wipe(ctx); err != nil {
+		handlerLogger.Error().Err(err).Msg("Failed to wipe the database")
+		writeError(w, http.StatusInternalServerError, apierror.CodeInternal, "Failed to wipe the database", handlerLogger)
+		return
+	}
+	// Drop what the caches still hold of the wiped data
+	signals.EmitConfigChanged(ctx, signals.ConfigSectionAll)
+	signals.EmitAssignmentsChanged(ctx, time.Time{})
+
+	handlerLogger.Warn().Int("events_deleted", response.EventsDeleted).Int("warnings", len(response.Warnings)).Msg("All the data wiped")
+	writeJSON(w, http.StatusOK, response, handlerLogger)
+}
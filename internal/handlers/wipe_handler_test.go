@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/apierror"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestWipeHandler(t *testing.T, calSvc *MockCalendarService) (*WipeHandler, *database.TokenStore, *fairness.Tracker) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.MigrateDatabase())
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+	tracker, err := fairness.New(db)
+	require.NoError(t, err)
+	_, err = tracker.RecordAssignment(t.Context(), "ParentA", time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local), false, fairness.DecisionReasonAlternating)
+	require.NoError(t, err)
+
+	oauthCfg := &oauth2.Config{}
+	baseHandler, err := NewBaseHandler(database.NewConfigAdapter(nil, oauthCfg), tokenStore, token.NewTokenManager(tokenStore, oauthCfg), tracker, nil)
+	require.NoError(t, err)
+	return NewWipeHandler(baseHandler, calSvc, db), tokenStore, tracker
+}
+
+func serveWipe(handler *WipeHandler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.handleWipe(w, httptest.NewRequest(http.MethodPost, "/api/admin/wipe", strings.NewReader(body)))
+	return w
+}
+
+func TestWipeHandler_Guards(t *testing.T) {
+	calSvc := new(MockCalendarService)
+	handler, tokenStore, tracker := setupTestWipeHandler(t, calSvc)
+
+	// Disconnected from Google Calendar, the wipe is refused rather than left open to anyone
+	w := serveWipe(handler, `{"confirm":"wipe everything"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Connected, Google Calendar must be authenticated: a token without credentials is not
+	require.NoError(t, tokenStore.SaveToken(&oauth2.Token{TokenType: "Bearer"}))
+	w = serveWipe(handler, `{"confirm":"wipe everything"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "test-access-token", RefreshToken: "test-refresh-token", TokenType: "Bearer"}))
+	for _, body := range []string{``, `{}`, `{"confirm":"yes"}`, `wipe everything`} {
+		w := serveWipe(handler, body)
+		require.Equal(t, http.StatusBadRequest, w.Code, body)
+		var response apierror.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, apierror.CodeInvalidRequest, response.Code)
+	}
+
+	assignments, err := tracker.GetAssignmentsInRange(t.Context(), time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2026, 12, 31, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Len(t, assignments, 1, "nothing is wiped")
+	calSvc.AssertNotCalled(t, "Reset")
+}
@@ -0,0 +1,45 @@
+// Package heartbeat pings a dead man's switch, such as healthchecks.io, after successful syncs.
+// The external monitor alerts when the pings stop, catching a service that silently died.
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// pingTimeout bounds a single ping
+const pingTimeout = 10 * time.Second
+
+// Pinger pings a heartbeat URL
+type Pinger struct {
+	url    string
+	client *http.Client
+}
+
+// NewPinger creates a pinger for the given URL
+func NewPinger(url string) *Pinger {
+	return &Pinger{
+		url:    url,
+		client: &http.Client{Timeout: pingTimeout},
+	}
+}
+
+// Ping sends a GET request to the heartbeat URL. Any 2xx answer is a success.
+func (p *Pinger) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create heartbeat request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to ping heartbeat URL: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("heartbeat URL answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package hooks posts the schedule changes to user-defined webhooks, signed with HMAC-SHA256, so that
+// automation services such as Zapier, IFTTT or n8n can react to them.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/rs/zerolog"
+)
+
+// deliveryTimeout bounds a single delivery
+const deliveryTimeout = 10 * time.Second
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Night-Routine-Event"
+	HeaderDelivery  = "X-Night-Routine-Delivery"
+	HeaderSignature = "X-Night-Routine-Signature"
+)
+
+// Events are the domain events delivered to the hooks
+var Events = []signals.EventType{
+	signals.EventAssignmentCreated,
+	signals.EventAssignmentOverridden,
+	signals.EventAssignmentUnlocked,
+	signals.EventSyncCompleted,
+}
+
+// Payload is the JSON body posted to the hooks
+type Payload struct {
+	ID         int64           `json:"id"` // Identifier of the event, the same for every retry
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// EventSource delivers the domain events, implemented by signals.Bus
+type EventSource interface {
+	Subscribe(name string, handler signals.SubscriberFunc, types ...signals.EventType)
+}
+
+// Hook posts events to one URL
+type Hook struct {
+	url    string
+	secret []byte
+	client *http.Client
+	logger zerolog.Logger
+}
+
+// NewHook creates a hook posting to url, signing the payloads with secret
+func NewHook(url, secret string) *Hook {
+	return &Hook{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: deliveryTimeout},
+		logger: logging.GetLogger("hooks").With().Str("url", url).Logger(),
+	}
+}
+
+// Register subscribes a hook per URL to the schedule events of source. Each hook has its own queue,
+// so a failing receiver is retried without delaying the others.
+func Register(source EventSource, urls []string, secret string) {
+	for i, url := range urls {
+		hook := NewHook(url, secret)
+		source.Subscribe(fmt.Sprintf("hook-%d", i), hook.Deliver, Events...)
+	}
+}
+
+// Deliver posts event to the hook. Any 2xx answer is a success, anything else is returned as an
+// error for the bus to retry.
+func (h *Hook) Deliver(ctx context.Context, event signals.Event) error {
+	body, err := json.Marshal(Payload{
+		ID:         event.ID,
+		Event:      string(event.Type),
+		OccurredAt: event.OccurredAt,
+		Data:       event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "night-routine")
+	req.Header.Set(HeaderEvent, string(event.Type))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(h.secret, body))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post hook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hook answered %s", resp.Status)
+	}
+	h.logger.Debug().Str("event", string(event.Type)).Int64("event_id", event.ID).Msg("Hook delivered")
+	return nil
+}
+
+// Sign returns the signature header value of body: "sha256=" followed by the hex HMAC-SHA256 of
+// body keyed with secret. Receivers recompute it over the raw body to authenticate a delivery.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,85 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook_Deliver(t *testing.T) {
+	var gotHeaders http.Header
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	occurredAt := time.Date(2026, time.October, 16, 20, 0, 0, 0, time.UTC)
+	err := NewHook(server.URL, "s3cret").Deliver(context.Background(), signals.Event{
+		ID:         42,
+		Type:       signals.EventAssignmentOverridden,
+		Payload:    json.RawMessage(`{"assignment_id":7,"caregiver":"Alice","caregiver_type":"parent"}`),
+		OccurredAt: occurredAt,
+	})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"id":42,"event":"assignment_overridden","occurred_at":"2026-10-16T20:00:00Z",`+
+		`"data":{"assignment_id":7,"caregiver":"Alice","caregiver_type":"parent"}}`, string(gotBody))
+	assert.Equal(t, "application/json", gotHeaders.Get("Content-Type"))
+	assert.Equal(t, "assignment_overridden", gotHeaders.Get(HeaderEvent))
+	assert.Equal(t, "42", gotHeaders.Get(HeaderDelivery))
+	assert.Equal(t, Sign([]byte("s3cret"), gotBody), gotHeaders.Get(HeaderSignature))
+}
+
+func TestHook_DeliverFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewHook(server.URL, "s3cret").Deliver(context.Background(), signals.Event{ID: 1, Type: signals.EventSyncCompleted, Payload: json.RawMessage(`{}`)})
+	assert.ErrorContains(t, err, "hook answered 503 Service Unavailable")
+}
+
+func TestSign(t *testing.T) {
+	// printf '{"id":1}' | openssl dgst -sha256 -hmac key
+	assert.Equal(t, "sha256=c95c6a7c2c7c761e984c68cf64b4bca93f07242900aafdbb328d3bb75ab0dcb0", Sign([]byte("key"), []byte(`{"id":1}`)))
+	assert.NotEqual(t, Sign([]byte("key"), []byte(`{"id":1}`)), Sign([]byte("other"), []byte(`{"id":1}`)))
+}
+
+// recordingSource records the subscriptions
+type recordingSource struct {
+	names []string
+	types [][]signals.EventType
+}
+
+func (s *recordingSource) Subscribe(name string, _ signals.SubscriberFunc, types ...signals.EventType) {
+	s.names = append(s.names, name)
+	s.types = append(s.types, types)
+}
+
+func TestRegister(t *testing.T) {
+	source := &recordingSource{}
+	Register(source, []string{"https://a.example.com", "https://b.example.com"}, "s3cret")
+
+	assert.Equal(t, []string{"hook-0", "hook-1"}, source.names)
+	for _, types := range source.types {
+		assert.ElementsMatch(t, []signals.EventType{
+			signals.EventAssignmentCreated,
+			signals.EventAssignmentOverridden,
+			signals.EventAssignmentUnlocked,
+			signals.EventSyncCompleted,
+		}, types)
+	}
+}
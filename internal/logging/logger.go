@@ -12,17 +12,22 @@ import (
 
 // Initialize sets up the global logger with the specified configuration
 func Initialize(isDevelopment bool) {
+	InitializeWithOutput(isDevelopment, os.Stdout)
+}
+
+// InitializeWithOutput sets up the global logger writing to out instead of stdout
+func InitializeWithOutput(isDevelopment bool, out io.Writer) {
 	// Set global time field format
 	zerolog.TimeFieldFormat = time.RFC3339
 	// Set stack trace marshaler
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 
 	// Configure output writer based on environment
-	var output io.Writer = os.Stdout
+	output := out
 	if isDevelopment {
 		// Use pretty console writer for development
 		output = zerolog.ConsoleWriter{
-			Out:        os.Stdout,
+			Out:        out,
 			TimeFormat: "15:04:05",
 		}
 	}
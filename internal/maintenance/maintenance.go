@@ -0,0 +1,148 @@
+// Package maintenance runs the heavy background jobs, pruning and checkpointing for example, in a
+// daily window so that they do not contend with the web requests or the calendar syncs on small hosts.
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// syncPollInterval is how often the coordinator checks whether the running sync is over
+const syncPollInterval = time.Minute
+
+// staleSyncAge is how long after its start a sync still running is considered left behind by a
+// crash, and no longer waited for
+const staleSyncAge = time.Hour
+
+// Job is a maintenance job. ctx is cancelled when the window ends.
+type Job func(ctx context.Context) error
+
+// BusySource tells whether a sync started after since is still running, implemented by database.SyncRunStore
+type BusySource interface {
+	SyncRunning(since time.Time) (bool, error)
+}
+
+// namedJob is a registered job with the name it is logged with
+type namedJob struct {
+	name string
+	run  Job
+}
+
+// Coordinator runs the registered jobs one after the other, in their order of registration, once
+// per maintenance window. A job waits for the running sync to finish before starting. The jobs left
+// when the window ends are skipped until the next window.
+type Coordinator struct {
+	window       config.QuietHours
+	busy         BusySource // nil to never wait for a sync
+	jobs         []namedJob
+	now          func() time.Time // injectable for testing; defaults to time.Now
+	pollInterval time.Duration
+	logger       zerolog.Logger
+}
+
+// NewCoordinator creates a coordinator running its jobs within window. busy may be nil.
+func NewCoordinator(window config.QuietHours, busy BusySource) *Coordinator {
+	return &Coordinator{
+		window:       window,
+		busy:         busy,
+		now:          time.Now,
+		pollInterval: syncPollInterval,
+		logger:       logging.GetLogger("maintenance"),
+	}
+}
+
+// Register adds job to the jobs of each window, after the ones already registered
+func (c *Coordinator) Register(name string, job Job) {
+	c.jobs = append(c.jobs, namedJob{name: name, run: job})
+}
+
+// Run waits for each maintenance window and runs the jobs within it until ctx is cancelled. A
+// start within the window runs the jobs at once.
+func (c *Coordinator) Run(ctx context.Context) {
+	c.logger.Info().Str("window", c.window.String()).Int("jobs", len(c.jobs)).Msg("Maintenance window enabled")
+	for {
+		now := c.now()
+		if !sleep(ctx, c.window.StartAfter(now).Sub(now)) {
+			return
+		}
+		c.RunWindow(ctx)
+
+		// Do not run the jobs twice within the same window
+		now = c.now()
+		if !sleep(ctx, c.window.EndAfter(now).Sub(now)) {
+			return
+		}
+	}
+}
+
+// RunWindow runs the jobs of the window now running, until they are all done or the window ends.
+// A failed job is logged and does not stop the next ones.
+func (c *Coordinator) RunWindow(ctx context.Context) {
+	now := c.now()
+	ctx, cancel := context.WithTimeout(ctx, c.window.EndAfter(now).Sub(now))
+	defer cancel()
+
+	windowLogger := c.logger.With().Str("window", c.window.String()).Logger()
+	windowLogger.Info().Int("jobs", len(c.jobs)).Msg("Maintenance window started")
+	for i, job := range c.jobs {
+		if !c.waitForSync(ctx) {
+			windowLogger.Warn().Int("skipped", len(c.jobs)-i).Str("next_job", job.name).Msg("Maintenance window ended before every job ran")
+			return
+		}
+
+		jobLogger := windowLogger.With().Str("job", job.name).Logger()
+		start := time.Now()
+		err := job.run(ctx)
+		duration := time.Since(start)
+		if err != nil {
+			jobLogger.Error().Err(err).Dur("duration", duration).Msg("Maintenance job failed")
+			continue
+		}
+		jobLogger.Info().Dur("duration", duration).Msg("Maintenance job done")
+	}
+	windowLogger.Info().Msg("Maintenance window done")
+}
+
+// waitForSync waits for the running sync to finish. It returns false when ctx is done first. A
+// failure to check is logged and does not block the jobs.
+func (c *Coordinator) waitForSync(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if c.busy == nil {
+		return true
+	}
+	for {
+		running, err := c.busy.SyncRunning(c.now().Add(-staleSyncAge))
+		if err != nil {
+			c.logger.Warn().Err(err).Msg("Failed to check for a running sync, running the job anyway")
+			return true
+		}
+		if !running {
+			return true
+		}
+		c.logger.Debug().Dur("poll_interval", c.pollInterval).Msg("Waiting for the running sync to finish")
+		if !sleep(ctx, c.pollInterval) {
+			return false
+		}
+	}
+}
+
+// sleep waits for d, returning false when ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,109 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// busySyncs reports a running sync for the first checks, failing every check when err is set
+type busySyncs struct {
+	running int
+	checks  int
+	err     error
+}
+
+func (s *busySyncs) SyncRunning(time.Time) (bool, error) {
+	s.checks++
+	return s.checks <= s.running, s.err
+}
+
+func newTestCoordinator(t *testing.T, now time.Time, busy BusySource) *Coordinator {
+	window, err := config.ParseQuietHours("03:00-05:00")
+	require.NoError(t, err)
+	coordinator := NewCoordinator(window, busy)
+	coordinator.now = func() time.Time { return now }
+	coordinator.pollInterval = time.Millisecond
+	return coordinator
+}
+
+func TestCoordinator_RunWindow(t *testing.T) {
+	coordinator := newTestCoordinator(t, time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC), nil)
+	var ran []string
+	coordinator.Register("prune", func(ctx context.Context) error {
+		ran = append(ran, "prune")
+		return errors.New("database is locked")
+	})
+	coordinator.Register("checkpoint", func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok, "the jobs stop with the window")
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), deadline, time.Minute)
+		ran = append(ran, "checkpoint")
+		return nil
+	})
+
+	coordinator.RunWindow(context.Background())
+	assert.Equal(t, []string{"prune", "checkpoint"}, ran, "a failed job does not stop the next ones")
+}
+
+func TestCoordinator_WaitsForTheRunningSync(t *testing.T) {
+	busy := &busySyncs{running: 2}
+	coordinator := newTestCoordinator(t, time.Date(2026, 10, 16, 4, 0, 0, 0, time.UTC), busy)
+	ran := 0
+	coordinator.Register("prune", func(ctx context.Context) error {
+		ran++
+		return nil
+	})
+
+	coordinator.RunWindow(context.Background())
+	assert.Equal(t, 1, ran)
+	assert.Equal(t, 3, busy.checks, "checked until the sync is over")
+
+	busy = &busySyncs{running: 10, err: errors.New("database is locked")}
+	coordinator.busy = busy
+	coordinator.RunWindow(context.Background())
+	assert.Equal(t, 2, ran, "a failed check does not block the jobs")
+}
+
+func TestCoordinator_SkipsTheJobsLeftAtTheEndOfTheWindow(t *testing.T) {
+	coordinator := newTestCoordinator(t, time.Date(2026, 10, 16, 4, 59, 59, 900_000_000, time.UTC), nil)
+	var ran []string
+	coordinator.Register("vacuum", func(ctx context.Context) error {
+		ran = append(ran, "vacuum")
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	coordinator.Register("checkpoint", func(ctx context.Context) error {
+		ran = append(ran, "checkpoint")
+		return nil
+	})
+
+	coordinator.RunWindow(context.Background())
+	assert.Equal(t, []string{"vacuum"}, ran)
+}
+
+func TestCoordinator_RunStopsWithContext(t *testing.T) {
+	coordinator := newTestCoordinator(t, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), nil)
+	coordinator.Register("prune", func(ctx context.Context) error {
+		t.Error("no job runs outside the window")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		coordinator.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop with its context")
+	}
+}
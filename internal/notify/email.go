@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+)
+
+// emailChannel is the name of the email channel
+const emailChannel = "email"
+
+// EmailNotifier sends messages by email through an SMTP server
+type EmailNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+var _ Notifier = (*EmailNotifier)(nil)
+
+// NewEmailNotifier creates a notifier sending through the configured SMTP server
+func NewEmailNotifier(cfg config.NotifyConfig) *EmailNotifier {
+	return &EmailNotifier{
+		addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		host:     cfg.SMTPHost,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.EmailFrom,
+		to:       cfg.EmailTo,
+	}
+}
+
+// Name returns the channel name
+func (n *EmailNotifier) Name() string {
+	return emailChannel
+}
+
+// Notify sends the message to the addresses of its recipients, or to the household addresses when
+// none of its recipients has one. STARTTLS is used when the server offers it.
+func (n *EmailNotifier) Notify(ctx context.Context, msg Message) error {
+	to := msg.recipientEmails()
+	if len(to) == 0 {
+		to = n.to
+	}
+	var auth smtp.Auth
+	if n.username != "" {
+		auth = smtp.PlainAuth("", n.username, n.password, n.host)
+	}
+
+	// smtp.SendMail takes no context; run it aside so that cancellation is honored
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(n.addr, auth, n.from, to, buildEmail(n.from, to, msg, time.Now()))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// buildEmail formats a plain text email
+func buildEmail(from string, to []string, msg Message, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	// The subject holds the names of the parents, encoded when they are not ASCII
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", sanitizeHeader(msg.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// sanitizeHeader keeps a header value on a single line
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
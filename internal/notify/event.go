@@ -0,0 +1,212 @@
+package notify
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Event identifies a kind of notification and the template rendering it
+type Event string
+
+// Events delivered by the application
+const (
+	// EventFailureAlert reports a source failing repeatedly, with FailureData
+	EventFailureAlert Event = "failure_alert"
+	// EventFailureRecovered reports a source succeeding again after an alert, with FailureData
+	EventFailureRecovered Event = "failure_recovered"
+	// EventTokenRefreshFailed reports that the Google token can no longer be refreshed, with TokenRefreshFailedData
+	EventTokenRefreshFailed Event = "token_refresh_failed"
+	// EventMonthlyReport sends the summary of the past month, with MonthlyReportData
+	EventMonthlyReport Event = "monthly_report"
+	// EventDutyReminder reminds the parent on duty in the evening, with DutyReminderData
+	EventDutyReminder Event = "duty_reminder"
+	// EventDutyEscalation tells the other parents that the parent on duty did not acknowledge their reminder, with DutyEscalationData
+	EventDutyEscalation Event = "duty_escalation"
+	// EventTonightClaimed tells the other parent that a parent took tonight, with TonightClaimedData
+	EventTonightClaimed Event = "tonight_claimed"
+	// EventScheduleRepaired reports the nights the consistency check repaired, with ScheduleRepairedData
+	EventScheduleRepaired Event = "schedule_repaired"
+	// EventImbalanceAlert reports a parent doing many more nights than the other, with ImbalanceAlertData
+	EventImbalanceAlert Event = "imbalance_alert"
+	// EventAbsenceSuggestion suggests unavailable days from nights overridden week after week, with AbsenceSuggestionData
+	EventAbsenceSuggestion Event = "absence_suggestion"
+	// EventSwapProposed asks a parent to confirm a swap of nights, with SwapData
+	EventSwapProposed Event = "swap_proposed"
+	// EventSwapAnswered tells the parent who proposed a swap whether it was accepted, with SwapData
+	EventSwapAnswered Event = "swap_answered"
+	// EventWeeklySummary emails the nights of the week ahead to the parents subscribed to it, with WeeklySummaryData
+	EventWeeklySummary Event = "weekly_summary"
+	// EventCalendarOverride emails a night changed from Google Calendar to the parents subscribed to it, with CalendarOverrideData
+	EventCalendarOverride Event = "calendar_override"
+)
+
+// String returns the event name
+func (e Event) String() string {
+	return string(e)
+}
+
+// FailureData is rendered by the failure alert and recovery templates
+type FailureData struct {
+	Source    string
+	Failures  int
+	LastError string // Empty for a recovery
+}
+
+// TokenRefreshFailedData is rendered by the token refresh failure template
+type TokenRefreshFailedData struct {
+	Error  string
+	AppURL string // Where the household reconnects Google Calendar; empty when unknown
+}
+
+// MonthlyReportData is rendered by the monthly report template
+type MonthlyReportData struct {
+	Month     string // e.g. "September 2026"
+	Totals    []MonthlyReportTotal
+	Overrides int    // Nights set by hand
+	ReportURL string // Where the full report is downloaded; empty when unknown
+}
+
+// MonthlyReportTotal is the number of nights of a caregiver in the monthly report
+type MonthlyReportTotal struct {
+	Name       string
+	Nights     int
+	Babysitter bool
+}
+
+// DutyReminderData is rendered by the duty reminder template
+type DutyReminderData struct {
+	Parent       string
+	Date         string   // e.g. "Friday 16 October"
+	PendingItems []string // Checklist items not done yet
+	AppURL       string   // Where the checklist is ticked; empty when unknown
+}
+
+// DutyEscalationData is rendered by the duty escalation template
+type DutyEscalationData struct {
+	Parent string // Parent on duty who did not acknowledge the reminder
+	Date   string // e.g. "Friday 16 October"
+	Delay  string // Time waited for the acknowledgement, e.g. "30 minutes"
+	Phone  string // Phone of the parent on duty; empty when unknown
+	AppURL string // Where tonight is taken; empty when unknown
+}
+
+// TonightClaimedData is rendered by the tonight claimed template
+type TonightClaimedData struct {
+	Parent            string // Parent who took tonight
+	OtherParent       string
+	Date              string // e.g. "Friday 16 October"
+	PreviousCaregiver string // Caregiver who was on duty before, a parent or a babysitter
+}
+
+// SwapData is rendered by the swap proposed and answered templates
+type SwapData struct {
+	Proposer      string // Parent who proposed the swap
+	ProposerDate  string // Night the proposer gives away, e.g. "Friday 16 October"
+	Responder     string // Parent asked to confirm the swap
+	ResponderDate string // Night the proposer takes in exchange
+	Accepted      bool   // Whether the responder accepted, for the swap answered event
+	AppURL        string // Where the swap is answered; empty when unknown
+}
+
+// WeeklySummaryData is rendered by the weekly summary template
+type WeeklySummaryData struct {
+	Week   string // First night of the week, e.g. "Monday 12 October"
+	Nights []WeeklySummaryNight
+	AppURL string // Where the schedule is shown; empty when unknown
+}
+
+// WeeklySummaryNight is a night of the weekly summary
+type WeeklySummaryNight struct {
+	Date       string // e.g. "Monday 12 October"
+	Caregiver  string
+	Babysitter bool
+}
+
+// CalendarOverrideData is rendered by the calendar override template
+type CalendarOverrideData struct {
+	Date              string // e.g. "Friday 16 October"
+	Caregiver         string // Caregiver now on duty, a parent or a babysitter
+	Babysitter        bool
+	PreviousCaregiver string
+	AppURL            string // Where the schedule is shown; empty when unknown
+}
+
+// ScheduleRepairedData is rendered by the schedule repaired template. Dates are YYYY-MM-DD.
+type ScheduleRepairedData struct {
+	MissingAssignments []string // Nights assigned by the repair
+	MissingEvents      []string // Nights whose calendar event was created by the repair
+	Remaining          []string // Nights still incomplete after the repair
+}
+
+// ImbalanceAlertData is rendered by the imbalance alert template
+type ImbalanceAlertData struct {
+	Busier      string // Parent with the most nights over the last 30 days
+	Other       string
+	BusierCount int
+	OtherCount  int
+	AppURL      string // Where the settings are reviewed; empty when unknown
+}
+
+// AbsenceSuggestionData is rendered by the absence suggestion template
+type AbsenceSuggestionData struct {
+	Suggestions []AbsenceSuggestion
+	AppURL      string // Where the settings are reviewed; empty when unknown
+}
+
+// AbsenceSuggestion is a weekday a parent was overridden on week after week
+type AbsenceSuggestion struct {
+	Parent     string
+	Weekday    string // e.g. "Wednesday"
+	Overridden int    // Occurrences of the weekday the parent was overridden on
+	Weeks      int    // Occurrences of the weekday looked at
+}
+
+// loadTemplates parses the templates of every event once. Each template file defines
+// a "subject" and a "body" template.
+var loadTemplates = sync.OnceValues(func() (map[Event]*template.Template, error) {
+	files, err := templateFS.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	templates := make(map[Event]*template.Template, len(files))
+	for _, file := range files {
+		event := Event(strings.TrimSuffix(file.Name(), ".tmpl"))
+		tmpl, err := template.New(file.Name()).Option("missingkey=error").ParseFS(templateFS, "templates/"+file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template of %s: %w", event, err)
+		}
+		templates[event] = tmpl
+	}
+	return templates, nil
+})
+
+// Render renders the message of event from data
+func Render(event Event, data any) (Message, error) {
+	templates, err := loadTemplates()
+	if err != nil {
+		return Message{}, err
+	}
+	tmpl, ok := templates[event]
+	if !ok {
+		return Message{}, fmt.Errorf("no template for event %s", event)
+	}
+
+	var subject, body strings.Builder
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render subject of %s: %w", event, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render body of %s: %w", event, err)
+	}
+	return Message{
+		Event:   event,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()),
+	}, nil
+}
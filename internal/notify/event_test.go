@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       Event
+		data        any
+		wantSubject string
+		wantBody    string
+	}{
+		{
+			name:        "failure alert",
+			event:       EventFailureAlert,
+			data:        FailureData{Source: "sync", Failures: 3, LastError: "token revoked"},
+			wantSubject: "Night Routine: sync failing",
+			wantBody:    "The sync has failed 3 times in a row.\nLast error: token revoked",
+		},
+		{
+			name:        "failure recovered",
+			event:       EventFailureRecovered,
+			data:        FailureData{Source: "webhook", Failures: 4},
+			wantSubject: "Night Routine: webhook recovered",
+			wantBody:    "The webhook succeeded again after 4 consecutive failures.",
+		},
+		{
+			name:        "token refresh failed",
+			event:       EventTokenRefreshFailed,
+			data:        TokenRefreshFailedData{Error: "invalid_grant", AppURL: "https://night-routine.example.com"},
+			wantSubject: "Night Routine: Google Calendar disconnected",
+			wantBody: "The Google token can no longer be refreshed, the schedule is not synced anymore.\n" +
+				"Reconnect Google Calendar from the home page: https://night-routine.example.com.\nError: invalid_grant",
+		},
+		{
+			name:        "token refresh failed without URL",
+			event:       EventTokenRefreshFailed,
+			data:        TokenRefreshFailedData{Error: "invalid_grant"},
+			wantSubject: "Night Routine: Google Calendar disconnected",
+			wantBody: "The Google token can no longer be refreshed, the schedule is not synced anymore.\n" +
+				"Reconnect Google Calendar from the home page.\nError: invalid_grant",
+		},
+		{
+			name:  "monthly report",
+			event: EventMonthlyReport,
+			data: MonthlyReportData{
+				Month:     "September 2026",
+				Totals:    []MonthlyReportTotal{{Name: "Alice", Nights: 14}, {Name: "Bob", Nights: 15}, {Name: "Grandma", Nights: 1, Babysitter: true}},
+				Overrides: 2,
+				ReportURL: "https://night-routine.example.com/statistics/report?month=2026-09",
+			},
+			wantSubject: "Night Routine: September 2026 report",
+			wantBody: "Here is the night routine summary of September 2026.\n\n" +
+				"- Alice: 14 nights\n- Bob: 15 nights\n- Grandma (babysitter): 1 night\n\n" +
+				"Nights set by hand: 2\n" +
+				"The full report, with the calendar and the fairness trend: https://night-routine.example.com/statistics/report?month=2026-09",
+		},
+		{
+			name:        "monthly report without nights",
+			event:       EventMonthlyReport,
+			data:        MonthlyReportData{Month: "September 2026"},
+			wantSubject: "Night Routine: September 2026 report",
+			wantBody:    "Here is the night routine summary of September 2026.\n\nNo night was assigned.\n\nNights set by hand: 0",
+		},
+		{
+			name:        "duty reminder",
+			event:       EventDutyReminder,
+			data:        DutyReminderData{Parent: "Alice", Date: "Friday 16 October", PendingItems: []string{"Bath", "Story"}, AppURL: "https://night-routine.example.com"},
+			wantSubject: "Night Routine: Alice is on duty tonight",
+			wantBody: "Alice is on night routine duty tonight, Friday 16 October.\n\n" +
+				"Still to do:\n- Bath\n- Story\n\n" +
+				"Acknowledge tonight and tick the checklist from the home page: https://night-routine.example.com/#acknowledge-tonight",
+		},
+		{
+			name:        "duty reminder without checklist",
+			event:       EventDutyReminder,
+			data:        DutyReminderData{Parent: "Bob", Date: "Saturday 17 October"},
+			wantSubject: "Night Routine: Bob is on duty tonight",
+			wantBody:    "Bob is on night routine duty tonight, Saturday 17 October.",
+		},
+		{
+			name:        "duty escalation",
+			event:       EventDutyEscalation,
+			data:        DutyEscalationData{Parent: "Alice", Date: "Friday 16 October", Delay: "30 minutes", Phone: "+33 6 12 34 56 78", AppURL: "https://night-routine.example.com"},
+			wantSubject: "Night Routine: Alice has not acknowledged tonight",
+			wantBody: "Alice is on night routine duty tonight, Friday 16 October, and has not acknowledged the reminder after 30 minutes.\n" +
+				"Call Alice: +33 6 12 34 56 78\n\n" +
+				"Take tonight from the home page if needed: https://night-routine.example.com",
+		},
+		{
+			name:        "duty escalation without phone",
+			event:       EventDutyEscalation,
+			data:        DutyEscalationData{Parent: "Bob", Date: "Saturday 17 October", Delay: "60 minutes"},
+			wantSubject: "Night Routine: Bob has not acknowledged tonight",
+			wantBody:    "Bob is on night routine duty tonight, Saturday 17 October, and has not acknowledged the reminder after 60 minutes.",
+		},
+		{
+			name:        "tonight claimed",
+			event:       EventTonightClaimed,
+			data:        TonightClaimedData{Parent: "Alice", OtherParent: "Bob", Date: "Friday 16 October", PreviousCaregiver: "Bob"},
+			wantSubject: "Night Routine: Alice takes tonight",
+			wantBody:    "Alice takes the night routine tonight, Friday 16 October, instead of Bob.\n\nBob, you are off tonight.",
+		},
+		{
+			name:        "tonight claimed from a babysitter",
+			event:       EventTonightClaimed,
+			data:        TonightClaimedData{Parent: "Alice", OtherParent: "Bob", Date: "Friday 16 October", PreviousCaregiver: "Grandma"},
+			wantSubject: "Night Routine: Alice takes tonight",
+			wantBody:    "Alice takes the night routine tonight, Friday 16 October, instead of Grandma.",
+		},
+		{
+			name:        "swap proposed",
+			event:       EventSwapProposed,
+			data:        SwapData{Proposer: "Alice", ProposerDate: "Friday 16 October", Responder: "Bob", ResponderDate: "Tuesday 20 October", AppURL: "https://night-routine.example.com"},
+			wantSubject: "Night Routine: Alice proposes a swap",
+			wantBody: "Alice proposes to swap nights with you, Bob: you would take Friday 16 October and Alice would take Tuesday 20 October.\n\n" +
+				"Accept or decline it from the home page: https://night-routine.example.com",
+		},
+		{
+			name:        "swap accepted",
+			event:       EventSwapAnswered,
+			data:        SwapData{Proposer: "Alice", ProposerDate: "Friday 16 October", Responder: "Bob", ResponderDate: "Tuesday 20 October", Accepted: true},
+			wantSubject: "Night Routine: Bob accepted your swap",
+			wantBody:    "Bob accepted your swap: Bob takes Friday 16 October and you take Tuesday 20 October. The calendar is updated.",
+		},
+		{
+			name:        "swap declined",
+			event:       EventSwapAnswered,
+			data:        SwapData{Proposer: "Alice", ProposerDate: "Friday 16 October", Responder: "Bob", ResponderDate: "Tuesday 20 October"},
+			wantSubject: "Night Routine: Bob declined your swap",
+			wantBody:    "Bob declined your swap of Friday 16 October against Tuesday 20 October. Both nights stay as they were.",
+		},
+		{
+			name:        "schedule repaired",
+			event:       EventScheduleRepaired,
+			data:        ScheduleRepairedData{MissingAssignments: []string{"2026-10-17"}, MissingEvents: []string{"2026-10-18", "2026-10-19"}},
+			wantSubject: "Night Routine: gaps in the schedule were repaired",
+			wantBody:    "Some nights of the schedule were incomplete after an earlier sync failed and have been repaired.\n\nNights without caregiver, now assigned:\n- 2026-10-17\n\nNights missing from Google Calendar, now synced:\n- 2026-10-18\n- 2026-10-19",
+		},
+		{
+			name:        "imbalance alert",
+			event:       EventImbalanceAlert,
+			data:        ImbalanceAlertData{Busier: "Bob", Other: "Alice", BusierCount: 19, OtherCount: 11, AppURL: "https://night.example.com"},
+			wantSubject: "Night Routine: Bob is doing most of the nights",
+			wantBody:    "Over the last 30 days, Bob did 19 nights and Alice 11.\nThe nights set by hand or the availability settings may keep the schedule from evening out.\nReview them in the settings: https://night.example.com/settings.",
+		},
+		{
+			name:  "absence suggestion",
+			event: EventAbsenceSuggestion,
+			data: AbsenceSuggestionData{Suggestions: []AbsenceSuggestion{
+				{Parent: "Bob", Weekday: "Wednesday", Overridden: 4, Weeks: 4},
+				{Parent: "Alice", Weekday: "Friday", Overridden: 3, Weeks: 4},
+			}},
+			wantSubject: "Night Routine: some nights are overridden week after week",
+			wantBody:    "The same nights keep being set by hand to another caregiver:\n- Bob on Wednesday, overridden 4 of the last 4 weeks\n- Alice on Friday, overridden 3 of the last 4 weeks\n\nMarking these days unavailable lets the schedule plan around them.\nApply the suggestions in the settings.",
+		},
+		{
+			name:  "weekly summary",
+			event: EventWeeklySummary,
+			data: WeeklySummaryData{
+				Week:   "Monday 12 October",
+				Nights: []WeeklySummaryNight{{Date: "Monday 12 October", Caregiver: "Alice"}, {Date: "Tuesday 13 October", Caregiver: "Grandma", Babysitter: true}},
+				AppURL: "https://night-routine.example.com",
+			},
+			wantSubject: "Night Routine: the week of Monday 12 October",
+			wantBody: "Here are the nights of the week starting Monday 12 October.\n\n" +
+				"- Monday 12 October: Alice\n- Tuesday 13 October: Grandma (babysitter)\n\n" +
+				"The full schedule: https://night-routine.example.com",
+		},
+		{
+			name:        "weekly summary without nights",
+			event:       EventWeeklySummary,
+			data:        WeeklySummaryData{Week: "Monday 12 October"},
+			wantSubject: "Night Routine: the week of Monday 12 October",
+			wantBody:    "Here are the nights of the week starting Monday 12 October.\n\nNo night is scheduled yet.",
+		},
+		{
+			name:        "calendar override",
+			event:       EventCalendarOverride,
+			data:        CalendarOverrideData{Date: "Friday 16 October", Caregiver: "Grandma", Babysitter: true, PreviousCaregiver: "Bob", AppURL: "https://night-routine.example.com"},
+			wantSubject: "Night Routine: Friday 16 October changed in Google Calendar",
+			wantBody: "The night of Friday 16 October was changed in Google Calendar: Grandma (babysitter) is now on duty instead of Bob.\n" +
+				"The following nights are recalculated to keep the schedule fair.\nSee the schedule: https://night-routine.example.com",
+		},
+		{
+			name:        "schedule partly repaired",
+			event:       EventScheduleRepaired,
+			data:        ScheduleRepairedData{MissingEvents: []string{"2026-10-18"}, Remaining: []string{"2026-10-19"}},
+			wantSubject: "Night Routine: gaps in the schedule were repaired",
+			wantBody:    "Some nights of the schedule were incomplete after an earlier sync failed and have been repaired.\n\nNights missing from Google Calendar, now synced:\n- 2026-10-18\n\nStill incomplete, retried on the next check:\n- 2026-10-19",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Render(tt.event, tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.event, msg.Event)
+			assert.Equal(t, tt.wantSubject, msg.Subject)
+			assert.Equal(t, tt.wantBody, msg.Body)
+		})
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	_, err := Render(Event("unknown"), nil)
+	assert.ErrorContains(t, err, "no template for event unknown")
+
+	_, err = Render(EventFailureAlert, map[string]any{"Source": "sync"})
+	assert.Error(t, err, "missing data fails instead of rendering <no value>")
+}
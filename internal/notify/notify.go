@@ -0,0 +1,73 @@
+// Package notify delivers notifications to the household through the configured channels.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/belphemur/night-routine/internal/config"
+)
+
+// Message is a notification to deliver
+type Message struct {
+	Event   Event // Kind of notification, empty for a message not rendered from a template
+	Subject string
+	Body    string
+	// Recipients are the contacts of the parents the message is meant for; empty for the household
+	Recipients []config.ParentContact
+}
+
+// recipientEmails returns the addresses of the recipients having one
+func (m Message) recipientEmails() []string {
+	var emails []string
+	for _, recipient := range m.Recipients {
+		if recipient.Email != "" {
+			emails = append(emails, recipient.Email)
+		}
+	}
+	return emails
+}
+
+// Notifier delivers messages through one channel
+type Notifier interface {
+	// Name identifies the channel in logs
+	Name() string
+	// Notify delivers the message
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Multi delivers every message through all of its notifiers
+type Multi []Notifier
+
+var _ Notifier = Multi(nil)
+
+// Name returns the name of the combined channels
+func (m Multi) Name() string {
+	return "multi"
+}
+
+// Notify delivers the message through every notifier. A failing channel does not prevent
+// delivery through the others; the errors of all failing channels are returned together.
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// New returns the notifiers enabled in the configuration. The result is empty, and delivers
+// nothing, when no channel is configured.
+func New(cfg config.NotifyConfig) Multi {
+	var notifiers Multi
+	if cfg.SlackWebhookURL != "" {
+		notifiers = append(notifiers, NewSlackNotifier(cfg.SlackWebhookURL))
+	}
+	if cfg.SMTPHost != "" {
+		notifiers = append(notifiers, NewEmailNotifier(cfg))
+	}
+	return notifiers
+}
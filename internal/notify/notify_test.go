@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier records the messages it receives and fails with err when set
+type recordingNotifier struct {
+	name     string
+	err      error
+	messages []Message
+}
+
+func (n *recordingNotifier) Name() string { return n.name }
+
+func (n *recordingNotifier) Notify(_ context.Context, msg Message) error {
+	n.messages = append(n.messages, msg)
+	return n.err
+}
+
+func TestMulti_Notify(t *testing.T) {
+	ok := &recordingNotifier{name: "ok"}
+	failing := &recordingNotifier{name: "failing", err: errors.New("unreachable")}
+	last := &recordingNotifier{name: "last"}
+
+	err := Multi{failing, ok, last}.Notify(context.Background(), Message{Subject: "s", Body: "b"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failing: unreachable")
+	assert.Len(t, ok.messages, 1, "a failing channel does not stop the others")
+	assert.Len(t, last.messages, 1)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.NotifyConfig
+		expected []string
+	}{
+		{name: "nothing configured", cfg: config.NotifyConfig{}, expected: nil},
+		{name: "slack", cfg: config.NotifyConfig{SlackWebhookURL: "https://hooks.slack.com/services/x"}, expected: []string{"slack"}},
+		{
+			name:     "slack and email",
+			cfg:      config.NotifyConfig{SlackWebhookURL: "https://hooks.slack.com/services/x", SMTPHost: "smtp.example.com", SMTPPort: 587, EmailFrom: "a@example.com", EmailTo: []string{"b@example.com"}},
+			expected: []string{"slack", "email"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, n := range New(tt.cfg) {
+				names = append(names, n.Name())
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := NewSlackNotifier(server.URL).Notify(context.Background(), Message{Subject: "Sync failing", Body: "3 failures"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "*Sync failing*\n3 failures", received["text"])
+}
+
+func TestSlackNotifier_MentionsRecipients(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := NewSlackNotifier(server.URL).Notify(context.Background(), Message{
+		Subject:    "Alice is on duty tonight",
+		Body:       "Bath",
+		Recipients: []config.ParentContact{{SlackMemberID: "U024BE7LH"}, {Email: "bob@example.com"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "<@U024BE7LH> *Alice is on duty tonight*\nBath", received["text"])
+}
+
+func TestSlackNotifier_NotifyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewSlackNotifier(server.URL).Notify(context.Background(), Message{Subject: "s"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
+
+func TestBuildEmail(t *testing.T) {
+	date := time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC)
+	email := string(buildEmail("nr@example.com", []string{"a@example.com", "b@example.com"},
+		Message{Subject: "Sync\r\nBcc: evil@example.com", Body: "line 1\nline 2"}, date))
+
+	assert.True(t, strings.HasPrefix(email, "From: nr@example.com\r\nTo: a@example.com, b@example.com\r\n"))
+	assert.Contains(t, email, "Subject: Sync  Bcc: evil@example.com\r\n", "the subject cannot inject headers")
+	assert.Contains(t, email, "Date: Fri, 16 Oct 2026 08:30:00 +0000\r\n")
+	assert.Contains(t, email, "Content-Type: text/plain; charset=UTF-8\r\n\r\nline 1\r\nline 2\r\n")
+}
+
+func TestBuildEmail_EncodesNonASCIISubject(t *testing.T) {
+	email := string(buildEmail("nr@example.com", []string{"a@example.com"},
+		Message{Subject: "Zoë is on duty tonight", Body: "body"}, time.Now()))
+
+	headers, _, found := strings.Cut(email, "\r\n\r\n")
+	require.True(t, found)
+	var subject string
+	for _, line := range strings.Split(headers, "\r\n") {
+		if value, ok := strings.CutPrefix(line, "Subject: "); ok {
+			subject = value
+		}
+	}
+	for _, r := range subject {
+		require.Less(t, r, rune(128), "the subject header is ASCII: %q", subject)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(subject)
+	require.NoError(t, err)
+	assert.Equal(t, "Zoë is on duty tonight", decoded)
+}
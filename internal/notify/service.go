@@ -0,0 +1,180 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// ChannelSettings tells which channels are enabled from the settings page
+type ChannelSettings interface {
+	IsNotifyChannelEnabled(channel string) (bool, error)
+}
+
+// DeliveryLog records the outcome of every delivery; a nil err is a success
+type DeliveryLog interface {
+	RecordDelivery(channel, event, subject string, err error) error
+}
+
+// ContactDirectory reads how the notifications meant for a parent reach them, implemented by
+// database.ParentContactStore
+type ContactDirectory interface {
+	// ContactOf returns the contact of the parent named name, the zero contact when there is none
+	ContactOf(name string) (config.ParentContact, error)
+	// EmailSubscribers returns the names of the parents subscribed to subscription
+	EmailSubscribers(subscription config.EmailSubscription) ([]string, error)
+}
+
+// Service is the entry point of the notification subsystem. It renders the events, delivers
+// them through the configured channels enabled in the settings and records every delivery.
+type Service struct {
+	notifiers Multi
+	settings  ChannelSettings
+	log       DeliveryLog
+	directory ContactDirectory
+	logger    zerolog.Logger
+}
+
+var _ Notifier = (*Service)(nil)
+
+// NewService creates a service delivering through notifiers. A nil settings enables every
+// channel, a nil log records nothing and a nil directory addresses every message to the household.
+func NewService(notifiers Multi, settings ChannelSettings, log DeliveryLog, directory ContactDirectory) *Service {
+	return &Service{
+		notifiers: notifiers,
+		settings:  settings,
+		log:       log,
+		directory: directory,
+		logger:    logging.GetLogger("notify"),
+	}
+}
+
+// Channels returns the names of the configured channels, enabled or not
+func (s *Service) Channels() []string {
+	names := make([]string, 0, len(s.notifiers))
+	for _, n := range s.notifiers {
+		names = append(names, n.Name())
+	}
+	return names
+}
+
+// Name returns the name of the service
+func (s *Service) Name() string {
+	return "notify"
+}
+
+// Send renders event from data and delivers it
+func (s *Service) Send(ctx context.Context, event Event, data any) error {
+	msg, err := Render(event, data)
+	if err != nil {
+		return err
+	}
+	return s.Notify(ctx, msg)
+}
+
+// SendTo renders event from data and delivers it to the parents named in parents, through their
+// contacts. A parent whose contact cannot be read or is empty is reached through the household
+// channels, like a message sent to nobody in particular.
+func (s *Service) SendTo(ctx context.Context, event Event, data any, parents ...string) error {
+	msg, err := Render(event, data)
+	if err != nil {
+		return err
+	}
+	if s.directory != nil {
+		for _, parent := range parents {
+			contact, err := s.directory.ContactOf(parent)
+			if err != nil {
+				s.logger.Warn().Err(err).Str("parent", parent).Str("event", event.String()).Msg("Failed to read the contact of the parent, sending to the household")
+				continue
+			}
+			if !contact.IsZero() {
+				msg.Recipients = append(msg.Recipients, contact)
+			}
+		}
+	}
+	return s.Notify(ctx, msg)
+}
+
+// EmailSubscribers renders event from data and emails it to the parents subscribed to subscription
+// on the settings page, through the email channel only. Nothing is sent without directory, email
+// channel or subscriber.
+func (s *Service) EmailSubscribers(ctx context.Context, subscription config.EmailSubscription, event Event, data any) error {
+	if s.directory == nil {
+		return nil
+	}
+	var email Multi
+	for _, n := range s.notifiers {
+		if n.Name() == emailChannel {
+			email = append(email, n)
+		}
+	}
+	if len(email) == 0 {
+		return nil
+	}
+	subscribers, err := s.directory.EmailSubscribers(subscription)
+	if err != nil {
+		return fmt.Errorf("failed to get the subscribers of %s: %w", subscription, err)
+	}
+
+	msg, err := Render(event, data)
+	if err != nil {
+		return err
+	}
+	for _, parent := range subscribers {
+		contact, err := s.directory.ContactOf(parent)
+		if err != nil {
+			return fmt.Errorf("failed to get the contact of %s: %w", parent, err)
+		}
+		if contact.Subscribes(subscription) {
+			msg.Recipients = append(msg.Recipients, contact)
+		}
+	}
+	if len(msg.Recipients) == 0 {
+		s.logger.Debug().Str("event", event.String()).Str("subscription", string(subscription)).Msg("No parent subscribed, email skipped")
+		return nil
+	}
+	return s.deliver(ctx, email, msg)
+}
+
+// Notify delivers the message through every enabled channel and records each delivery. A failing
+// channel does not prevent delivery through the others; the errors of all failing channels are
+// returned together. A channel whose state cannot be read is used, an alert is better sent twice
+// than lost.
+func (s *Service) Notify(ctx context.Context, msg Message) error {
+	return s.deliver(ctx, s.notifiers, msg)
+}
+
+// deliver delivers the message through the enabled channels of notifiers, as described by Notify
+func (s *Service) deliver(ctx context.Context, notifiers Multi, msg Message) error {
+	var errs []error
+	for _, n := range notifiers {
+		channelLogger := s.logger.With().Str("channel", n.Name()).Str("event", msg.Event.String()).Logger()
+		if s.settings != nil {
+			enabled, err := s.settings.IsNotifyChannelEnabled(n.Name())
+			if err != nil {
+				channelLogger.Warn().Err(err).Msg("Failed to read whether the channel is enabled, delivering anyway")
+			} else if !enabled {
+				channelLogger.Debug().Msg("Channel disabled, notification skipped")
+				continue
+			}
+		}
+
+		err := n.Notify(ctx, msg)
+		if err != nil {
+			channelLogger.Error().Err(err).Msg("Failed to deliver notification")
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		} else {
+			channelLogger.Debug().Str("subject", msg.Subject).Msg("Notification delivered")
+		}
+		if s.log != nil {
+			if logErr := s.log.RecordDelivery(n.Name(), msg.Event.String(), msg.Subject, err); logErr != nil {
+				channelLogger.Warn().Err(logErr).Msg("Failed to record notification delivery")
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChannelSettings enables the channels not listed as disabled
+type fakeChannelSettings struct {
+	disabled map[string]bool
+	err      error
+}
+
+func (f *fakeChannelSettings) IsNotifyChannelEnabled(channel string) (bool, error) {
+	return !f.disabled[channel], f.err
+}
+
+// delivery is one delivery recorded by fakeDeliveryLog
+type delivery struct {
+	channel, event, subject string
+	err                     error
+}
+
+type fakeDeliveryLog struct {
+	deliveries []delivery
+}
+
+func (f *fakeDeliveryLog) RecordDelivery(channel, event, subject string, err error) error {
+	f.deliveries = append(f.deliveries, delivery{channel: channel, event: event, subject: subject, err: err})
+	return nil
+}
+
+func TestService_Send(t *testing.T) {
+	slack := &recordingNotifier{name: "slack"}
+	email := &recordingNotifier{name: "email", err: errors.New("connection refused")}
+	log := &fakeDeliveryLog{}
+	service := NewService(Multi{slack, email}, &fakeChannelSettings{}, log, nil)
+
+	err := service.Send(context.Background(), EventFailureAlert, FailureData{Source: "sync", Failures: 3, LastError: "boom"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "email: connection refused")
+	require.Len(t, slack.messages, 1, "a failing channel does not stop the others")
+	assert.Equal(t, "Night Routine: sync failing", slack.messages[0].Subject)
+	assert.Equal(t, []delivery{
+		{channel: "slack", event: "failure_alert", subject: "Night Routine: sync failing"},
+		{channel: "email", event: "failure_alert", subject: "Night Routine: sync failing", err: email.err},
+	}, log.deliveries)
+}
+
+func TestService_SkipsDisabledChannels(t *testing.T) {
+	slack := &recordingNotifier{name: "slack"}
+	email := &recordingNotifier{name: "email"}
+	log := &fakeDeliveryLog{}
+	service := NewService(Multi{slack, email}, &fakeChannelSettings{disabled: map[string]bool{"slack": true}}, log, nil)
+
+	require.NoError(t, service.Notify(context.Background(), Message{Subject: "s", Body: "b"}))
+
+	assert.Empty(t, slack.messages)
+	assert.Len(t, email.messages, 1)
+	require.Len(t, log.deliveries, 1, "skipped channels are not recorded")
+	assert.Equal(t, "email", log.deliveries[0].channel)
+}
+
+func TestService_DeliversWhenSettingsFail(t *testing.T) {
+	slack := &recordingNotifier{name: "slack"}
+	service := NewService(Multi{slack}, &fakeChannelSettings{err: errors.New("database is locked")}, nil, nil)
+
+	require.NoError(t, service.Notify(context.Background(), Message{Subject: "s"}))
+	assert.Len(t, slack.messages, 1)
+}
+
+func TestService_Channels(t *testing.T) {
+	assert.Empty(t, NewService(nil, nil, nil, nil).Channels())
+	service := NewService(Multi{&recordingNotifier{name: "slack"}, &recordingNotifier{name: "email"}}, nil, nil, nil)
+	assert.Equal(t, []string{"slack", "email"}, service.Channels())
+}
+
+// fakeDirectory returns the contacts it holds by name, failing for the names in errs
+type fakeDirectory struct {
+	contacts map[string]config.ParentContact
+	errs     map[string]error
+}
+
+func (f *fakeDirectory) ContactOf(name string) (config.ParentContact, error) {
+	return f.contacts[name], f.errs[name]
+}
+
+func (f *fakeDirectory) EmailSubscribers(subscription config.EmailSubscription) ([]string, error) {
+	var subscribers []string
+	for name, contact := range f.contacts {
+		if contact.Subscribes(subscription) {
+			subscribers = append(subscribers, name)
+		}
+	}
+	return subscribers, nil
+}
+
+func TestService_SendTo(t *testing.T) {
+	alice := config.ParentContact{Email: "alice@example.com", SlackMemberID: "U024BE7LH"}
+	email := &recordingNotifier{name: "email"}
+	directory := &fakeDirectory{
+		contacts: map[string]config.ParentContact{"Alice": alice},
+		errs:     map[string]error{"Carol": errors.New("database is locked")},
+	}
+	service := NewService(Multi{email}, nil, nil, directory)
+
+	data := DutyReminderData{Parent: "Alice", Date: "Friday 16 October"}
+	require.NoError(t, service.SendTo(context.Background(), EventDutyReminder, data, "Alice", "Bob", "Carol"))
+	require.Len(t, email.messages, 1)
+	assert.Equal(t, []config.ParentContact{alice}, email.messages[0].Recipients, "parents without contact are skipped")
+
+	// Without directory every message goes to the household
+	require.NoError(t, NewService(Multi{email}, nil, nil, nil).SendTo(context.Background(), EventDutyReminder, data, "Alice"))
+	assert.Empty(t, email.messages[1].Recipients)
+}
+
+func TestService_EmailSubscribers(t *testing.T) {
+	alice := config.ParentContact{Email: "alice@example.com", WeeklySummary: true}
+	directory := &fakeDirectory{contacts: map[string]config.ParentContact{
+		"Alice": alice,
+		"Bob":   {Email: "bob@example.com"},
+	}}
+	slack := &recordingNotifier{name: "slack"}
+	email := &recordingNotifier{name: "email"}
+	log := &fakeDeliveryLog{}
+	service := NewService(Multi{slack, email}, nil, log, directory)
+
+	data := WeeklySummaryData{Week: "Monday 12 October"}
+	require.NoError(t, service.EmailSubscribers(context.Background(), config.EmailWeeklySummary, EventWeeklySummary, data))
+	assert.Empty(t, slack.messages, "only emailed")
+	require.Len(t, email.messages, 1)
+	assert.Equal(t, []config.ParentContact{alice}, email.messages[0].Recipients)
+	assert.Equal(t, []delivery{{channel: "email", event: "weekly_summary", subject: "Night Routine: the week of Monday 12 October"}}, log.deliveries)
+
+	// Nobody subscribed to the override notices, which never fall back to the household addresses
+	require.NoError(t, service.EmailSubscribers(context.Background(), config.EmailOverrideNotices, EventCalendarOverride, CalendarOverrideData{}))
+	assert.Len(t, email.messages, 1)
+
+	// Without email channel nothing is sent
+	require.NoError(t, NewService(Multi{slack}, nil, nil, directory).EmailSubscribers(context.Background(), config.EmailWeeklySummary, EventWeeklySummary, data))
+	assert.Empty(t, slack.messages)
+}
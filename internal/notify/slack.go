@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slackTimeout bounds the delivery of a Slack message
+const slackTimeout = 10 * time.Second
+
+// SlackNotifier posts messages to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+var _ Notifier = (*SlackNotifier)(nil)
+
+// NewSlackNotifier creates a notifier posting to the given incoming webhook URL
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: slackTimeout},
+	}
+}
+
+// Name returns the channel name
+func (n *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// Notify posts the message, with its subject in bold after the mentions of its recipients
+func (n *SlackNotifier) Notify(ctx context.Context, msg Message) error {
+	var mentions strings.Builder
+	for _, recipient := range msg.Recipients {
+		if recipient.SlackMemberID != "" {
+			fmt.Fprintf(&mentions, "<@%s> ", recipient.SlackMemberID)
+		}
+	}
+	payload, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("%s*%s*\n%s", mentions.String(), msg.Subject, msg.Body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post Slack message: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack answered %s", resp.Status)
+	}
+	return nil
+}
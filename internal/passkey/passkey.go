@@ -0,0 +1,282 @@
+// Package passkey verifies the WebAuthn ceremonies of the passkey login of the web interface. It
+// implements the subset of WebAuthn the login needs: the browser sends the public key of a new
+// passkey as SubjectPublicKeyInfo (PublicKeyCredential.response.getPublicKey()), so that no CBOR
+// is decoded, and attestation statements are not checked: a passkey is trusted because it is
+// registered from a session already signed in.
+package passkey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// COSE identifiers of the supported signature algorithms
+const (
+	AlgorithmES256 = -7   // ECDSA P-256 with SHA-256
+	AlgorithmEdDSA = -8   // Ed25519
+	AlgorithmRS256 = -257 // RSASSA-PKCS1-v1_5 with SHA-256
+)
+
+// Flags of the authenticator data
+const (
+	flagUserPresent      = 0x01
+	flagAttestedCredData = 0x40
+)
+
+// Client data types of the two ceremonies
+const (
+	clientDataCreate = "webauthn.create"
+	clientDataGet    = "webauthn.get"
+)
+
+// authenticatorDataMinLength is the length of the RP ID hash, flags and signature counter
+const authenticatorDataMinLength = 37
+
+var (
+	// ErrInvalidResponse is returned for a malformed response of the authenticator
+	ErrInvalidResponse = errors.New("invalid authenticator response")
+	// ErrChallengeMismatch is returned when the response does not answer a challenge of the server
+	ErrChallengeMismatch = errors.New("unknown or expired challenge")
+	// ErrOriginMismatch is returned when the response was made for another site
+	ErrOriginMismatch = errors.New("response made for another origin")
+	// ErrUserNotPresent is returned when the authenticator did not check the presence of the user
+	ErrUserNotPresent = errors.New("user presence not confirmed")
+	// ErrInvalidSignature is returned when the signature does not match the passkey
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrUnsupportedAlgorithm is returned for a public key of an algorithm other than ES256, EdDSA and RS256
+	ErrUnsupportedAlgorithm = errors.New("unsupported public key algorithm")
+	// ErrCounterRegression is returned when the signature counter went back, the passkey may be cloned
+	ErrCounterRegression = errors.New("signature counter went back")
+)
+
+// RelyingParty is the web interface the passkeys are bound to: its host is the RP ID and its
+// scheme, host and port the origin the browser reports
+type RelyingParty struct {
+	ID     string // e.g. "night-routine.example.com"
+	Origin string // e.g. "https://night-routine.example.com"
+	Name   string // Shown by the browser when creating a passkey
+}
+
+// NewRelyingParty returns the relying party of the web interface at appURL
+func NewRelyingParty(appURL, name string) (RelyingParty, error) {
+	u, err := url.Parse(appURL)
+	if err != nil {
+		return RelyingParty{}, fmt.Errorf("invalid app_url %q: %w", appURL, err)
+	}
+	if u.Scheme == "" || u.Hostname() == "" {
+		return RelyingParty{}, fmt.Errorf("invalid app_url %q: scheme and host are required", appURL)
+	}
+	return RelyingParty{ID: u.Hostname(), Origin: u.Scheme + "://" + u.Host, Name: name}, nil
+}
+
+// NewChallenge returns a random challenge, base64url encoded as the browser reports it
+func NewChallenge() (string, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(challenge), nil
+}
+
+// Registration is the response of the browser creating a passkey
+type Registration struct {
+	ClientDataJSON    []byte // response.clientDataJSON
+	AuthenticatorData []byte // response.getAuthenticatorData()
+	PublicKey         []byte // response.getPublicKey(), DER SubjectPublicKeyInfo
+	Algorithm         int    // response.getPublicKeyAlgorithm(), COSE identifier
+}
+
+// Credential is a registered passkey
+type Credential struct {
+	ID        []byte // Credential ID chosen by the authenticator
+	PublicKey []byte // DER SubjectPublicKeyInfo
+	Algorithm int    // COSE identifier
+	SignCount uint32 // Signature counter at registration, 0 for authenticators without counter
+}
+
+// Assertion is the response of the browser signing in with a passkey
+type Assertion struct {
+	ClientDataJSON    []byte // response.clientDataJSON
+	AuthenticatorData []byte // response.authenticatorData
+	Signature         []byte // response.signature
+}
+
+// clientData is the part of the client data JSON the ceremonies check
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// authenticatorData is the parsed authenticator data
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte // Only with attested credential data
+}
+
+// VerifyRegistration checks the response of the browser creating a passkey and returns the
+// credential to store. consumeChallenge reports whether the challenge of the response was issued
+// by the server and not used yet.
+func (rp RelyingParty) VerifyRegistration(reg Registration, consumeChallenge func(challenge string) bool) (*Credential, error) {
+	if err := rp.verifyClientData(reg.ClientDataJSON, clientDataCreate, consumeChallenge); err != nil {
+		return nil, err
+	}
+	authData, err := rp.verifyAuthenticatorData(reg.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if authData.credentialID == nil {
+		return nil, fmt.Errorf("%w: no attested credential data", ErrInvalidResponse)
+	}
+	if _, err := parsePublicKey(reg.PublicKey, reg.Algorithm); err != nil {
+		return nil, err
+	}
+	return &Credential{
+		ID:        authData.credentialID,
+		PublicKey: reg.PublicKey,
+		Algorithm: reg.Algorithm,
+		SignCount: authData.signCount,
+	}, nil
+}
+
+// VerifyAssertion checks the response of the browser signing in with the passkey of publicKey,
+// algorithm and last signCount, and returns the new signature counter. consumeChallenge reports
+// whether the challenge of the response was issued by the server and not used yet.
+func (rp RelyingParty) VerifyAssertion(assertion Assertion, publicKey []byte, algorithm int, signCount uint32, consumeChallenge func(challenge string) bool) (uint32, error) {
+	if err := rp.verifyClientData(assertion.ClientDataJSON, clientDataGet, consumeChallenge); err != nil {
+		return 0, err
+	}
+	authData, err := rp.verifyAuthenticatorData(assertion.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	key, err := parsePublicKey(publicKey, algorithm)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	signed := append(bytes.Clone(assertion.AuthenticatorData), clientDataHash[:]...)
+	if !verifySignature(key, signed, assertion.Signature) {
+		return 0, ErrInvalidSignature
+	}
+	// Authenticators without counter always report 0
+	if (authData.signCount != 0 || signCount != 0) && authData.signCount <= signCount {
+		return 0, ErrCounterRegression
+	}
+	return authData.signCount, nil
+}
+
+// verifyClientData checks the type, challenge and origin of the client data JSON
+func (rp RelyingParty) verifyClientData(raw []byte, ceremony string, consumeChallenge func(string) bool) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("%w: client data: %w", ErrInvalidResponse, err)
+	}
+	if data.Type != ceremony {
+		return fmt.Errorf("%w: client data type %q, expected %q", ErrInvalidResponse, data.Type, ceremony)
+	}
+	if data.Origin != rp.Origin {
+		return fmt.Errorf("%w: %q", ErrOriginMismatch, data.Origin)
+	}
+	if data.Challenge == "" || !consumeChallenge(data.Challenge) {
+		return ErrChallengeMismatch
+	}
+	return nil
+}
+
+// verifyAuthenticatorData parses the authenticator data and checks it was made for the RP ID with
+// the user present
+func (rp RelyingParty) verifyAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	data, err := parseAuthenticatorData(raw)
+	if err != nil {
+		return nil, err
+	}
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(data.rpIDHash, rpIDHash[:]) != 1 {
+		return nil, ErrOriginMismatch
+	}
+	if data.flags&flagUserPresent == 0 {
+		return nil, ErrUserNotPresent
+	}
+	return data, nil
+}
+
+// parseAuthenticatorData reads the RP ID hash, flags, signature counter and, when attested, the
+// credential ID of the authenticator data
+func parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < authenticatorDataMinLength {
+		return nil, fmt.Errorf("%w: authenticator data of %d bytes", ErrInvalidResponse, len(raw))
+	}
+	data := &authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	if data.flags&flagAttestedCredData == 0 {
+		return data, nil
+	}
+	// AAGUID (16 bytes), credential ID length (2 bytes), credential ID, then the COSE key
+	rest := raw[authenticatorDataMinLength:]
+	if len(rest) < 18 {
+		return nil, fmt.Errorf("%w: truncated attested credential data", ErrInvalidResponse)
+	}
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	if idLength == 0 || len(rest) < 18+idLength {
+		return nil, fmt.Errorf("%w: truncated credential ID", ErrInvalidResponse)
+	}
+	data.credentialID = bytes.Clone(rest[18 : 18+idLength])
+	return data, nil
+}
+
+// parsePublicKey parses a DER SubjectPublicKeyInfo and checks it matches the algorithm
+func parsePublicKey(der []byte, algorithm int) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public key: %w", ErrInvalidResponse, err)
+	}
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if algorithm == AlgorithmES256 && k.Curve.Params().Name == "P-256" {
+			return k, nil
+		}
+	case ed25519.PublicKey:
+		if algorithm == AlgorithmEdDSA {
+			return k, nil
+		}
+	case *rsa.PublicKey:
+		if algorithm == AlgorithmRS256 {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %T with algorithm %d", ErrUnsupportedAlgorithm, key, algorithm)
+}
+
+// verifySignature checks the signature of message by key
+func verifySignature(key crypto.PublicKey, message, signature []byte) bool {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(k, digest[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, message, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
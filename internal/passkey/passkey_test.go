@@ -0,0 +1,213 @@
+package passkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAuthenticator is a software authenticator holding one ES256 passkey
+type testAuthenticator struct {
+	t         *testing.T
+	key       *ecdsa.PrivateKey
+	id        []byte
+	rpID      string
+	origin    string
+	signCount uint32
+}
+
+func newTestAuthenticator(t *testing.T, rp RelyingParty) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &testAuthenticator{t: t, key: key, id: []byte("credential-1"), rpID: rp.ID, origin: rp.Origin, signCount: 1}
+}
+
+func (a *testAuthenticator) clientData(ceremony, challenge string) []byte {
+	raw, err := json.Marshal(clientData{Type: ceremony, Challenge: challenge, Origin: a.origin})
+	require.NoError(a.t, err)
+	return raw
+}
+
+func (a *testAuthenticator) authenticatorData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	flags := byte(flagUserPresent)
+	if attested {
+		flags |= flagAttestedCredData
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.id)))
+		data = append(data, a.id...)
+	}
+	return data
+}
+
+func (a *testAuthenticator) register(challenge string) Registration {
+	publicKey, err := x509.MarshalPKIXPublicKey(&a.key.PublicKey)
+	require.NoError(a.t, err)
+	return Registration{
+		ClientDataJSON:    a.clientData(clientDataCreate, challenge),
+		AuthenticatorData: a.authenticatorData(true),
+		PublicKey:         publicKey,
+		Algorithm:         AlgorithmES256,
+	}
+}
+
+func (a *testAuthenticator) sign(challenge string) Assertion {
+	a.signCount++
+	assertion := Assertion{
+		ClientDataJSON:    a.clientData(clientDataGet, challenge),
+		AuthenticatorData: a.authenticatorData(false),
+	}
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, assertion.AuthenticatorData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(a.t, err)
+	assertion.Signature = signature
+	return assertion
+}
+
+// challenges issues single-use challenges
+type challenges map[string]bool
+
+func (c challenges) issue(t *testing.T) string {
+	challenge, err := NewChallenge()
+	require.NoError(t, err)
+	c[challenge] = true
+	return challenge
+}
+
+func (c challenges) consume(challenge string) bool {
+	ok := c[challenge]
+	delete(c, challenge)
+	return ok
+}
+
+func TestNewRelyingParty(t *testing.T) {
+	rp, err := NewRelyingParty("https://night.example.com:8443/app/", "Night Routine")
+	require.NoError(t, err)
+	assert.Equal(t, RelyingParty{ID: "night.example.com", Origin: "https://night.example.com:8443", Name: "Night Routine"}, rp)
+
+	_, err = NewRelyingParty("night.example.com", "Night Routine")
+	assert.Error(t, err)
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	rp, err := NewRelyingParty("http://localhost:8888", "Night Routine")
+	require.NoError(t, err)
+	authenticator := newTestAuthenticator(t, rp)
+	issued := challenges{}
+
+	credential, err := rp.VerifyRegistration(authenticator.register(issued.issue(t)), issued.consume)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("credential-1"), credential.ID)
+	assert.Equal(t, uint32(1), credential.SignCount)
+
+	assertion := authenticator.sign(issued.issue(t))
+	signCount, err := rp.VerifyAssertion(assertion, credential.PublicKey, credential.Algorithm, credential.SignCount, issued.consume)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), signCount)
+
+	// A challenge is used once
+	_, err = rp.VerifyAssertion(assertion, credential.PublicKey, credential.Algorithm, signCount, issued.consume)
+	assert.ErrorIs(t, err, ErrChallengeMismatch)
+
+	// A counter not going forward is rejected
+	_, err = rp.VerifyAssertion(authenticator.sign(issued.issue(t)), credential.PublicKey, credential.Algorithm, 10, issued.consume)
+	assert.ErrorIs(t, err, ErrCounterRegression)
+}
+
+func TestVerifyAssertionRejects(t *testing.T) {
+	rp, err := NewRelyingParty("https://night.example.com", "Night Routine")
+	require.NoError(t, err)
+	issued := challenges{}
+	authenticator := newTestAuthenticator(t, rp)
+	credential, err := rp.VerifyRegistration(authenticator.register(issued.issue(t)), issued.consume)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		before func(a *testAuthenticator) // Changes the authenticator before signing
+		after  func(assertion *Assertion) // Changes the signed assertion
+		want   error
+	}{
+		{
+			name:   "other origin",
+			before: func(a *testAuthenticator) { a.origin = "https://evil.example.com" },
+			want:   ErrOriginMismatch,
+		},
+		{
+			name:   "other RP ID",
+			before: func(a *testAuthenticator) { a.rpID = "evil.example.com" },
+			want:   ErrOriginMismatch,
+		},
+		{
+			name:  "tampered signature",
+			after: func(assertion *Assertion) { assertion.Signature[len(assertion.Signature)-1] ^= 0xff },
+			want:  ErrInvalidSignature,
+		},
+		{
+			name:  "truncated authenticator data",
+			after: func(assertion *Assertion) { assertion.AuthenticatorData = assertion.AuthenticatorData[:20] },
+			want:  ErrInvalidResponse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := *authenticator
+			a.t = t
+			if tt.before != nil {
+				tt.before(&a)
+			}
+			assertion := a.sign(issued.issue(t))
+			if tt.after != nil {
+				tt.after(&assertion)
+			}
+			_, err := rp.VerifyAssertion(assertion, credential.PublicKey, credential.Algorithm, 0, issued.consume)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestVerifyRegistrationRejectsMismatchedAlgorithm(t *testing.T) {
+	rp, err := NewRelyingParty("https://night.example.com", "Night Routine")
+	require.NoError(t, err)
+	issued := challenges{}
+	authenticator := newTestAuthenticator(t, rp)
+
+	reg := authenticator.register(issued.issue(t))
+	reg.Algorithm = AlgorithmRS256
+	_, err = rp.VerifyRegistration(reg, issued.consume)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	// Ed25519 passkeys are supported
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	reg = authenticator.register(issued.issue(t))
+	reg.PublicKey, err = x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	reg.Algorithm = AlgorithmEdDSA
+	credential, err := rp.VerifyRegistration(reg, issued.consume)
+	require.NoError(t, err)
+
+	assertion := Assertion{
+		ClientDataJSON:    authenticator.clientData(clientDataGet, issued.issue(t)),
+		AuthenticatorData: authenticator.authenticatorData(false),
+	}
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	assertion.Signature = ed25519.Sign(privateKey, append(append([]byte{}, assertion.AuthenticatorData...), clientDataHash[:]...))
+	_, err = rp.VerifyAssertion(assertion, credential.PublicKey, credential.Algorithm, 0, issued.consume)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,197 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// ContactSource reads the contact of a parent, implemented by database.ParentContactStore
+type ContactSource interface {
+	ContactOf(name string) (config.ParentContact, error)
+}
+
+// AcknowledgementSource reads the acknowledgement of a night, implemented by database.AcknowledgementStore
+type AcknowledgementSource interface {
+	GetAcknowledgement(assignmentID int64) (*database.Acknowledgement, error)
+}
+
+// Escalator notifies the other parents when the parent on duty did not acknowledge the duty
+// reminder within the escalation delay of their contact. Acknowledging the night, even before the
+// reminder, or ticking an item of the checklist after the reminder acknowledges it. The time of the reminder and of a past escalation are read from the
+// DeliveryHistory, so that an escalation survives a restart and is sent once a day.
+type Escalator struct {
+	assignments AssignmentSource
+	checklists  ChecklistSource       // nil when the checklists are not available
+	acks        AcknowledgementSource // nil when the acknowledgements are not available
+	contacts    ContactSource
+	roster      config.RosterSource
+	sender      Sender
+	history     DeliveryHistory
+	appURL      string
+	now         func() time.Time // injectable for testing; defaults to time.Now
+	logger      zerolog.Logger
+
+	doneDay string // YYYY-MM-DD of the last day handled by this escalator, escalated or not
+}
+
+// NewEscalator creates an escalator of the duty reminders sent through sender to the parents of
+// roster. appURL is the public URL of the application, linked from the escalation; it may be empty.
+func NewEscalator(assignments AssignmentSource, checklists ChecklistSource, acks AcknowledgementSource, contacts ContactSource, roster config.RosterSource, sender Sender, history DeliveryHistory, appURL string) *Escalator {
+	return &Escalator{
+		assignments: assignments,
+		checklists:  checklists,
+		acks:        acks,
+		contacts:    contacts,
+		roster:      roster,
+		sender:      sender,
+		history:     history,
+		appURL:      strings.TrimRight(appURL, "/"),
+		now:         time.Now,
+		logger:      logging.GetLogger("duty-escalation"),
+	}
+}
+
+// Run checks every minute whether the reminder of today escalates until ctx is cancelled
+func (e *Escalator) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	e.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.check(ctx)
+		}
+	}
+}
+
+// check escalates the reminder of today once its delay is passed without acknowledgement, unless
+// today was already handled. A failed lookup or delivery is retried on the next check.
+func (e *Escalator) check(ctx context.Context) {
+	now := e.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dayKey := today.Format(time.DateOnly)
+	if e.doneDay == dayKey {
+		return
+	}
+	logger := e.logger.With().Str("date", dayKey).Logger()
+
+	remindedAt, err := e.history.LastSuccessfulDelivery(notify.EventDutyReminder.String())
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to read when the duty reminder was sent")
+		return
+	}
+	if remindedAt.Before(today) {
+		return
+	}
+	escalatedAt, err := e.history.LastSuccessfulDelivery(notify.EventDutyEscalation.String())
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to read when the duty reminder last escalated")
+		return
+	}
+	if !escalatedAt.Before(today) {
+		e.doneDay = dayKey
+		return
+	}
+
+	done, err := e.escalate(ctx, logger, today, remindedAt, now)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to escalate duty reminder, retrying on the next check")
+		return
+	}
+	if done {
+		e.doneDay = dayKey
+	}
+}
+
+// escalate notifies the other parents when the parent on duty the night of day did not acknowledge
+// the reminder sent at remindedAt within their escalation delay. It reports whether the day is
+// handled: false while the delay is not over.
+func (e *Escalator) escalate(ctx context.Context, logger zerolog.Logger, day, remindedAt, now time.Time) (bool, error) {
+	assignment, err := e.assignments.GetAssignmentByDate(ctx, day)
+	if err != nil {
+		return false, fmt.Errorf("failed to get assignment of %s: %w", day.Format(time.DateOnly), err)
+	}
+	if assignment == nil || assignment.CaregiverType != fairness.CaregiverTypeParent {
+		return true, nil
+	}
+	logger = logger.With().Str("parent", assignment.Parent).Logger()
+
+	contact, err := e.contacts.ContactOf(assignment.Parent)
+	if err != nil {
+		return false, fmt.Errorf("failed to get contact of %s: %w", assignment.Parent, err)
+	}
+	if contact.EscalationDelay == 0 {
+		logger.Debug().Msg("No escalation for the parent on duty")
+		return true, nil
+	}
+	if now.Before(remindedAt.Add(contact.EscalationDelay)) {
+		return false, nil
+	}
+
+	acknowledged, err := e.acknowledged(assignment, remindedAt)
+	if err != nil {
+		return false, err
+	}
+	if acknowledged {
+		logger.Debug().Msg("Duty reminder acknowledged, no escalation")
+		return true, nil
+	}
+
+	roster, err := config.Roster(e.roster)
+	if err != nil {
+		return false, fmt.Errorf("failed to get parents: %w", err)
+	}
+	others := slices.DeleteFunc(roster, func(parent string) bool { return parent == assignment.Parent })
+	if len(others) == 0 {
+		return true, nil
+	}
+	data := notify.DutyEscalationData{
+		Parent: assignment.Parent,
+		Date:   day.Format("Monday 2 January"),
+		Delay:  fmt.Sprintf("%d minutes", int(contact.EscalationDelay/time.Minute)),
+		Phone:  contact.Phone,
+		AppURL: e.appURL,
+	}
+	if err := e.sender.SendTo(ctx, notify.EventDutyEscalation, data, others...); err != nil {
+		return false, err
+	}
+	logger.Info().Strs("notified", others).Msg("Duty reminder escalated")
+	return true, nil
+}
+
+// acknowledged reports whether the parent on duty acknowledged the night of assignment or ticked an
+// item of its checklist since remindedAt
+func (e *Escalator) acknowledged(assignment *fairness.Assignment, remindedAt time.Time) (bool, error) {
+	if e.acks != nil {
+		ack, err := e.acks.GetAcknowledgement(assignment.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get acknowledgement of assignment %d: %w", assignment.ID, err)
+		}
+		if ack != nil {
+			return true, nil
+		}
+	}
+	if e.checklists == nil {
+		return false, nil
+	}
+	items, err := e.checklists.GetChecklist(assignment.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get checklist of assignment %d: %w", assignment.ID, err)
+	}
+	return slices.ContainsFunc(items, func(item *database.ChecklistItem) bool {
+		return item.Done() && !item.CompletedAt.Before(remindedAt)
+	}), nil
+}
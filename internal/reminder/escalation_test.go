@@ -0,0 +1,166 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventHistory returns the last delivery of each event it holds
+type eventHistory map[notify.Event]time.Time
+
+func (h eventHistory) LastSuccessfulDelivery(event string) (time.Time, error) {
+	return h[notify.Event(event)], nil
+}
+
+type staticContacts map[string]config.ParentContact
+
+func (c staticContacts) ContactOf(name string) (config.ParentContact, error) {
+	return c[name], nil
+}
+
+// staticAcknowledgements returns the acknowledgement of the night of any assignment
+type staticAcknowledgements struct{ acknowledgedAt time.Time }
+
+func (a staticAcknowledgements) GetAcknowledgement(assignmentID int64) (*database.Acknowledgement, error) {
+	return &database.Acknowledgement{AssignmentID: assignmentID, Parent: "Alice", AcknowledgedAt: a.acknowledgedAt}, nil
+}
+
+// staticRoster has Alice, Bob and Carol taking turns
+type staticRoster struct{}
+
+func (staticRoster) GetParents() (string, string, error) { return "Alice", "Bob", nil }
+func (staticRoster) GetExtraParents() ([]config.ExtraParent, error) {
+	return []config.ExtraParent{{Name: "Carol"}}, nil
+}
+
+var (
+	remindedAt   = tonight.Add(18 * time.Hour)
+	aliceContact = staticContacts{"Alice": {Phone: "+33 6 12 34 56 78", EscalationDelay: 30 * time.Minute}}
+)
+
+func newTestEscalator(assignments AssignmentSource, checklists ChecklistSource, acks AcknowledgementSource, contacts ContactSource, history DeliveryHistory, now time.Time) (*Escalator, *recordingSender) {
+	sender := &recordingSender{}
+	escalator := NewEscalator(assignments, checklists, acks, contacts, staticRoster{}, sender, history, "https://night-routine.example.com/")
+	escalator.now = func() time.Time { return now }
+	return escalator, sender
+}
+
+func TestEscalator_NotifiesTheOtherParentsOnce(t *testing.T) {
+	history := eventHistory{notify.EventDutyReminder: remindedAt}
+	escalator, sender := newTestEscalator(staticAssignments{assignment: aliceNight}, staticChecklists{}, nil, aliceContact, history, remindedAt.Add(31*time.Minute))
+
+	escalator.check(context.Background())
+	escalator.check(context.Background())
+
+	require.Len(t, sender.events, 1, "escalated once per day")
+	assert.Equal(t, notify.EventDutyEscalation, sender.events[0])
+	assert.Equal(t, []string{"Bob", "Carol"}, sender.parents[0])
+	assert.Equal(t, notify.DutyEscalationData{
+		Parent: "Alice",
+		Date:   "Friday 16 October",
+		Delay:  "30 minutes",
+		Phone:  "+33 6 12 34 56 78",
+		AppURL: "https://night-routine.example.com",
+	}, sender.data[0])
+}
+
+func TestEscalator_DoesNotEscalate(t *testing.T) {
+	ticked := remindedAt.Add(10 * time.Minute)
+	tickedBefore := remindedAt.Add(-time.Hour)
+	tests := []struct {
+		name        string
+		now         time.Time
+		assignments AssignmentSource
+		checklists  ChecklistSource
+		acks        AcknowledgementSource
+		contacts    ContactSource
+		history     eventHistory
+	}{
+		{
+			name:        "before the delay",
+			now:         remindedAt.Add(29 * time.Minute),
+			assignments: staticAssignments{assignment: aliceNight},
+			contacts:    aliceContact,
+			history:     eventHistory{notify.EventDutyReminder: remindedAt},
+		},
+		{
+			name:        "no reminder today",
+			now:         remindedAt.Add(time.Hour),
+			assignments: staticAssignments{assignment: aliceNight},
+			contacts:    aliceContact,
+			history:     eventHistory{notify.EventDutyReminder: remindedAt.AddDate(0, 0, -1)},
+		},
+		{
+			name:        "already escalated, before a restart",
+			now:         remindedAt.Add(time.Hour),
+			assignments: staticAssignments{assignment: aliceNight},
+			contacts:    aliceContact,
+			history:     eventHistory{notify.EventDutyReminder: remindedAt, notify.EventDutyEscalation: remindedAt.Add(30 * time.Minute)},
+		},
+		{
+			name:        "no escalation delay",
+			now:         remindedAt.Add(time.Hour),
+			assignments: staticAssignments{assignment: aliceNight},
+			contacts:    staticContacts{"Alice": {Phone: "+33 6 12 34 56 78"}},
+			history:     eventHistory{notify.EventDutyReminder: remindedAt},
+		},
+		{
+			name:        "checklist ticked after the reminder",
+			now:         remindedAt.Add(time.Hour),
+			assignments: staticAssignments{assignment: aliceNight},
+			checklists:  staticChecklists{items: []*database.ChecklistItem{{Label: "Bath", CompletedAt: &ticked}, {Label: "Story"}}},
+			contacts:    aliceContact,
+			history:     eventHistory{notify.EventDutyReminder: remindedAt},
+		},
+		{
+			name:        "night acknowledged before the reminder",
+			now:         remindedAt.Add(time.Hour),
+			assignments: staticAssignments{assignment: aliceNight},
+			acks:        staticAcknowledgements{acknowledgedAt: tickedBefore},
+			contacts:    aliceContact,
+			history:     eventHistory{notify.EventDutyReminder: remindedAt},
+		},
+		{
+			name:        "babysitter night",
+			now:         remindedAt.Add(time.Hour),
+			assignments: staticAssignments{assignment: &fairness.Assignment{ID: 2, Parent: "Grandma", Date: tonight, CaregiverType: fairness.CaregiverTypeBabysitter}},
+			contacts:    aliceContact,
+			history:     eventHistory{notify.EventDutyReminder: remindedAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			escalator, sender := newTestEscalator(tt.assignments, tt.checklists, tt.acks, tt.contacts, tt.history, tt.now)
+			escalator.check(context.Background())
+			assert.Empty(t, sender.events)
+		})
+	}
+
+	t.Run("checklist ticked before the reminder", func(t *testing.T) {
+		checklists := staticChecklists{items: []*database.ChecklistItem{{Label: "Bath", CompletedAt: &tickedBefore}}}
+		escalator, sender := newTestEscalator(staticAssignments{assignment: aliceNight}, checklists, nil, aliceContact, eventHistory{notify.EventDutyReminder: remindedAt}, remindedAt.Add(time.Hour))
+		escalator.check(context.Background())
+		assert.Len(t, sender.events, 1, "only a tick after the reminder acknowledges it")
+	})
+}
+
+func TestEscalator_RetriesAfterFailure(t *testing.T) {
+	history := eventHistory{notify.EventDutyReminder: remindedAt}
+	escalator, sender := newTestEscalator(staticAssignments{assignment: aliceNight}, nil, nil, aliceContact, history, remindedAt.Add(time.Hour))
+	sender.err = errors.New("smtp down")
+
+	escalator.check(context.Background())
+	sender.err = nil
+	escalator.check(context.Background())
+
+	assert.Len(t, sender.events, 2, "the failed escalation is retried")
+}
@@ -0,0 +1,176 @@
+// Package reminder notifies the parent on duty in the evening of their night.
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// checkInterval is how often the reminder checks whether it is due
+const checkInterval = time.Minute
+
+// timeFormat is the format of the reminder time of the day
+const timeFormat = "15:04"
+
+// AssignmentSource reads the assignment of a day, implemented by fairness.Tracker
+type AssignmentSource interface {
+	GetAssignmentByDate(ctx context.Context, date time.Time) (*fairness.Assignment, error)
+}
+
+// ChecklistSource reads the checklist of an assignment, implemented by database.ChecklistStore
+type ChecklistSource interface {
+	GetChecklist(assignmentID int64) ([]*database.ChecklistItem, error)
+}
+
+// Sender delivers a notification event to parents through their contacts, implemented by notify.Service
+type Sender interface {
+	SendTo(ctx context.Context, event notify.Event, data any, parents ...string) error
+}
+
+// DeliveryHistory tells when an event was last delivered, implemented by database.NotificationDeliveryStore
+type DeliveryHistory interface {
+	LastSuccessfulDelivery(event string) (time.Time, error)
+}
+
+// Reminder notifies the parent on duty once a day at a configured time. Nights of a babysitter and
+// nights whose checklist is already fully ticked are skipped. A reminder already delivered that day,
+// before a restart for example, is not sent again; a failed delivery is retried on the next check.
+type Reminder struct {
+	hour, minute int
+	assignments  AssignmentSource
+	checklists   ChecklistSource // nil when the checklists are not available
+	sender       Sender
+	history      DeliveryHistory // nil to rely on the in-memory state only
+	appURL       string
+	now          func() time.Time // injectable for testing; defaults to time.Now
+	logger       zerolog.Logger
+
+	doneDay string // YYYY-MM-DD of the last day handled by this reminder, sent or skipped
+}
+
+// NewReminder creates a reminder sent through sender every day at at (HH:MM, local time). appURL is
+// the public URL of the application, linked from the reminder; it may be empty.
+func NewReminder(at string, assignments AssignmentSource, checklists ChecklistSource, sender Sender, history DeliveryHistory, appURL string) (*Reminder, error) {
+	parsed, err := time.Parse(timeFormat, at)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reminder time %q: %w", at, err)
+	}
+	return &Reminder{
+		hour:        parsed.Hour(),
+		minute:      parsed.Minute(),
+		assignments: assignments,
+		checklists:  checklists,
+		sender:      sender,
+		history:     history,
+		appURL:      strings.TrimRight(appURL, "/"),
+		now:         time.Now,
+		logger:      logging.GetLogger("duty-reminder"),
+	}, nil
+}
+
+// Run checks every minute whether the reminder is due until ctx is cancelled
+func (r *Reminder) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	r.logger.Info().Str("at", fmt.Sprintf("%02d:%02d", r.hour, r.minute)).Msg("Duty reminders enabled")
+	r.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check(ctx)
+		}
+	}
+}
+
+// check sends the reminder of today once the reminder time is passed, unless today was already handled
+func (r *Reminder) check(ctx context.Context) {
+	now := r.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.Before(today.Add(time.Duration(r.hour)*time.Hour + time.Duration(r.minute)*time.Minute)) {
+		return
+	}
+	dayKey := today.Format(time.DateOnly)
+	if r.doneDay == dayKey {
+		return
+	}
+	logger := r.logger.With().Str("date", dayKey).Logger()
+
+	if r.history != nil {
+		lastSent, err := r.history.LastSuccessfulDelivery(notify.EventDutyReminder.String())
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to read when the duty reminder was last sent, sending it anyway")
+		} else if !lastSent.Before(today) {
+			logger.Debug().Time("last_sent", lastSent).Msg("Duty reminder already sent")
+			r.doneDay = dayKey
+			return
+		}
+	}
+
+	sent, err := r.Send(ctx, today)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to send duty reminder, retrying on the next check")
+		return
+	}
+	r.doneDay = dayKey
+	if sent {
+		logger.Info().Msg("Duty reminder sent")
+	}
+}
+
+// Send reminds the parent on duty the night of day through their contact, reporting whether a reminder was sent. Nothing
+// is sent for a day without assignment, a babysitter night or a night whose checklist is all done.
+func (r *Reminder) Send(ctx context.Context, day time.Time) (bool, error) {
+	logger := r.logger.With().Str("date", day.Format(time.DateOnly)).Logger()
+
+	assignment, err := r.assignments.GetAssignmentByDate(ctx, day)
+	if err != nil {
+		return false, fmt.Errorf("failed to get assignment of %s: %w", day.Format(time.DateOnly), err)
+	}
+	if assignment == nil {
+		logger.Debug().Msg("No assignment tonight, no reminder")
+		return false, nil
+	}
+	if assignment.CaregiverType != fairness.CaregiverTypeParent {
+		logger.Debug().Str("caregiver", assignment.Parent).Msg("No parent on duty tonight, no reminder")
+		return false, nil
+	}
+
+	var pending []string
+	if r.checklists != nil {
+		items, err := r.checklists.GetChecklist(assignment.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get checklist of assignment %d: %w", assignment.ID, err)
+		}
+		for _, item := range items {
+			if !item.Done() {
+				pending = append(pending, item.Label)
+			}
+		}
+		if len(items) > 0 && len(pending) == 0 {
+			logger.Debug().Str("parent", assignment.Parent).Msg("Night already done, no reminder")
+			return false, nil
+		}
+	}
+
+	data := notify.DutyReminderData{
+		Parent:       assignment.Parent,
+		Date:         day.Format("Monday 2 January"),
+		PendingItems: pending,
+		AppURL:       r.appURL,
+	}
+	if err := r.sender.SendTo(ctx, notify.EventDutyReminder, data, assignment.Parent); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,183 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticAssignments struct {
+	assignment *fairness.Assignment
+	err        error
+}
+
+func (s staticAssignments) GetAssignmentByDate(context.Context, time.Time) (*fairness.Assignment, error) {
+	return s.assignment, s.err
+}
+
+type staticChecklists struct {
+	items []*database.ChecklistItem
+}
+
+func (s staticChecklists) GetChecklist(int64) ([]*database.ChecklistItem, error) {
+	return s.items, nil
+}
+
+type recordingSender struct {
+	events  []notify.Event
+	data    []any
+	parents [][]string
+	err     error
+}
+
+func (s *recordingSender) SendTo(_ context.Context, event notify.Event, data any, parents ...string) error {
+	s.events = append(s.events, event)
+	s.data = append(s.data, data)
+	s.parents = append(s.parents, parents)
+	return s.err
+}
+
+type staticHistory struct {
+	lastSent time.Time
+	err      error
+}
+
+func (h staticHistory) LastSuccessfulDelivery(string) (time.Time, error) {
+	return h.lastSent, h.err
+}
+
+var (
+	tonight    = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	completed  = time.Date(2026, time.October, 16, 19, 30, 0, 0, time.UTC)
+	aliceNight = &fairness.Assignment{ID: 1, Parent: "Alice", Date: tonight, CaregiverType: fairness.CaregiverTypeParent}
+)
+
+func newTestReminder(t *testing.T, assignments AssignmentSource, checklists ChecklistSource, history DeliveryHistory, now time.Time) (*Reminder, *recordingSender) {
+	t.Helper()
+	sender := &recordingSender{}
+	reminder, err := NewReminder("18:00", assignments, checklists, sender, history, "https://night-routine.example.com/")
+	require.NoError(t, err)
+	reminder.now = func() time.Time { return now }
+	return reminder, sender
+}
+
+func TestNewReminder_InvalidTime(t *testing.T) {
+	_, err := NewReminder("6pm", staticAssignments{}, nil, &recordingSender{}, nil, "")
+	assert.Error(t, err)
+}
+
+func TestReminder_SendsOnceAfterReminderTime(t *testing.T) {
+	checklists := staticChecklists{items: []*database.ChecklistItem{
+		{Label: "Bath", CompletedAt: &completed},
+		{Label: "Story"},
+	}}
+	reminder, sender := newTestReminder(t, staticAssignments{assignment: aliceNight}, checklists, staticHistory{}, tonight.Add(18*time.Hour+time.Minute))
+
+	reminder.check(context.Background())
+	reminder.check(context.Background())
+
+	require.Len(t, sender.events, 1, "sent once per day")
+	assert.Equal(t, notify.EventDutyReminder, sender.events[0])
+	assert.Equal(t, notify.DutyReminderData{
+		Parent:       "Alice",
+		Date:         "Friday 16 October",
+		PendingItems: []string{"Story"},
+		AppURL:       "https://night-routine.example.com",
+	}, sender.data[0])
+	assert.Equal(t, []string{"Alice"}, sender.parents[0], "sent to the parent on duty")
+}
+
+func TestReminder_Skips(t *testing.T) {
+	evening := tonight.Add(19 * time.Hour)
+	tests := []struct {
+		name        string
+		now         time.Time
+		assignments AssignmentSource
+		checklists  ChecklistSource
+		history     DeliveryHistory
+	}{
+		{
+			name:        "before the reminder time",
+			now:         tonight.Add(17*time.Hour + 59*time.Minute),
+			assignments: staticAssignments{assignment: aliceNight},
+			history:     staticHistory{},
+		},
+		{
+			name:        "already sent before a restart",
+			now:         evening,
+			assignments: staticAssignments{assignment: aliceNight},
+			history:     staticHistory{lastSent: tonight.Add(18 * time.Hour)},
+		},
+		{
+			name:        "no assignment tonight",
+			now:         evening,
+			history:     staticHistory{},
+			assignments: staticAssignments{},
+		},
+		{
+			name: "babysitter night",
+			now:  evening,
+			assignments: staticAssignments{assignment: &fairness.Assignment{
+				ID: 2, Parent: "Grandma", Date: tonight, CaregiverType: fairness.CaregiverTypeBabysitter, Override: true,
+			}},
+			history: staticHistory{},
+		},
+		{
+			name:        "night already done",
+			now:         evening,
+			assignments: staticAssignments{assignment: aliceNight},
+			checklists: staticChecklists{items: []*database.ChecklistItem{
+				{Label: "Bath", CompletedAt: &completed},
+				{Label: "Story", CompletedAt: &completed},
+			}},
+			history: staticHistory{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder, sender := newTestReminder(t, tt.assignments, tt.checklists, tt.history, tt.now)
+
+			reminder.check(context.Background())
+
+			assert.Empty(t, sender.events)
+		})
+	}
+}
+
+func TestReminder_SendsWithoutChecklist(t *testing.T) {
+	reminder, sender := newTestReminder(t, staticAssignments{assignment: aliceNight}, staticChecklists{}, nil, tonight.Add(20*time.Hour))
+
+	reminder.check(context.Background())
+
+	require.Len(t, sender.events, 1, "an empty checklist does not mark the night done")
+	assert.Empty(t, sender.data[0].(notify.DutyReminderData).PendingItems)
+}
+
+func TestReminder_RetriesAfterFailure(t *testing.T) {
+	reminder, sender := newTestReminder(t, staticAssignments{assignment: aliceNight}, nil, staticHistory{err: errors.New("database is locked")}, tonight.Add(19*time.Hour))
+	sender.err = errors.New("smtp down")
+
+	reminder.check(context.Background())
+	sender.err = nil
+	reminder.check(context.Background())
+	reminder.check(context.Background())
+
+	assert.Len(t, sender.events, 2, "retried once after the failure, then sent")
+}
+
+func TestReminder_AssignmentError(t *testing.T) {
+	reminder, sender := newTestReminder(t, staticAssignments{err: errors.New("database is locked")}, nil, nil, tonight.Add(19*time.Hour))
+
+	reminder.check(context.Background())
+
+	assert.Empty(t, sender.events)
+	assert.Empty(t, reminder.doneDay, "retried on the next check")
+}
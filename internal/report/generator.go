@@ -0,0 +1,60 @@
+package report
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+//go:embed templates/monthly.html
+var templateFS embed.FS
+
+// segmentColors is the number of colors of the fairness trend bars, reused past that many parents
+const segmentColors = 4
+
+// monthlyTemplate renders a MonthlyReport as a standalone HTML page, printable to PDF from a browser
+var monthlyTemplate = template.Must(template.New("monthly.html").Funcs(template.FuncMap{
+	"segment": func(i int) int { return i % segmentColors },
+}).ParseFS(templateFS, "templates/monthly.html"))
+
+// AssignmentSource reads the assignments of a date range, implemented by fairness.Tracker
+type AssignmentSource interface {
+	GetAssignmentsInRange(ctx context.Context, start, end time.Time) ([]*fairness.Assignment, error)
+}
+
+// Generator produces the monthly reports from the recorded assignments
+type Generator struct {
+	assignments AssignmentSource
+	now         func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewGenerator creates a report generator reading the assignments from assignments
+func NewGenerator(assignments AssignmentSource) *Generator {
+	return &Generator{assignments: assignments, now: time.Now}
+}
+
+// Generate builds the report of the month of month
+func (g *Generator) Generate(ctx context.Context, month time.Time) (*MonthlyReport, error) {
+	month = StartOfMonth(month)
+	start := month.AddDate(0, -TrendMonths+1, 0)
+	end := month.AddDate(0, 1, -1)
+
+	assignments, err := g.assignments.GetAssignmentsInRange(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments of report %s: %w", month.Format(monthFormat), err)
+	}
+	return Build(month, assignments, g.now()), nil
+}
+
+// RenderHTML writes the report as a standalone HTML page
+func RenderHTML(w io.Writer, report *MonthlyReport) error {
+	if err := monthlyTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render report %s: %w", report.MonthKey(), err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// mailerDueDays is how many days from the 1st the report of the past month is due, so that a
+// maintenance window missed or failed is caught up by the next ones
+const mailerDueDays = 7
+
+// Sender delivers a notification event, implemented by notify.Service
+type Sender interface {
+	Send(ctx context.Context, event notify.Event, data any) error
+}
+
+// DeliveryHistory tells when an event was last delivered, implemented by database.NotificationDeliveryStore
+type DeliveryHistory interface {
+	LastSuccessfulDelivery(event string) (time.Time, error)
+}
+
+// Mailer sends the report of the past month through the notifier during the first week of each
+// month, checked by the maintenance window. A report already delivered that month, before a restart
+// for example, is not sent again. A failed delivery is retried in the next window, a report is
+// better sent twice than lost.
+type Mailer struct {
+	generator *Generator
+	sender    Sender
+	history   DeliveryHistory // nil to rely on the in-memory state only
+	appURL    string
+	now       func() time.Time // injectable for testing; defaults to time.Now
+	logger    zerolog.Logger
+
+	sentMonth string // YYYY-MM of the last report sent by this mailer
+}
+
+// NewMailer creates a mailer sending the reports of generator through sender. appURL is the public
+// URL of the application, used to link the full report; it may be empty.
+func NewMailer(generator *Generator, sender Sender, history DeliveryHistory, appURL string) *Mailer {
+	return &Mailer{
+		generator: generator,
+		sender:    sender,
+		history:   history,
+		appURL:    strings.TrimRight(appURL, "/"),
+		now:       time.Now,
+		logger:    logging.GetLogger("report-mailer"),
+	}
+}
+
+// Check sends the report of the past month when it is due and was not sent yet
+func (m *Mailer) Check(ctx context.Context) error {
+	now := m.now()
+	if now.Day() > mailerDueDays {
+		return nil
+	}
+	month := StartOfMonth(now).AddDate(0, -1, 0)
+	monthKey := month.Format(monthFormat)
+	if m.sentMonth == monthKey {
+		return nil
+	}
+	logger := m.logger.With().Str("month", monthKey).Logger()
+
+	if m.history != nil {
+		lastSent, err := m.history.LastSuccessfulDelivery(notify.EventMonthlyReport.String())
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to read when the monthly report was last sent, sending it anyway")
+		} else if !lastSent.Before(StartOfMonth(now)) {
+			logger.Debug().Time("last_sent", lastSent).Msg("Monthly report already sent")
+			m.sentMonth = monthKey
+			return nil
+		}
+	}
+
+	if err := m.Send(ctx, month); err != nil {
+		return fmt.Errorf("failed to send the monthly report of %s: %w", monthKey, err)
+	}
+	m.sentMonth = monthKey
+	logger.Info().Msg("Monthly report sent")
+	return nil
+}
+
+// Send generates the report of month and delivers its summary through the notifier
+func (m *Mailer) Send(ctx context.Context, month time.Time) error {
+	report, err := m.generator.Generate(ctx, month)
+	if err != nil {
+		return err
+	}
+
+	data := notify.MonthlyReportData{
+		Month:     report.Title(),
+		Overrides: len(report.Overrides),
+		ReportURL: m.reportURL(report.MonthKey()),
+	}
+	for _, total := range report.Totals {
+		data.Totals = append(data.Totals, notify.MonthlyReportTotal{Name: total.Name, Nights: total.Nights, Babysitter: total.Babysitter})
+	}
+	return m.sender.Send(ctx, notify.EventMonthlyReport, data)
+}
+
+// reportURL returns the link to the full report of month, empty without application URL
+func (m *Mailer) reportURL(month string) string {
+	if m.appURL == "" {
+		return ""
+	}
+	return m.appURL + "/statistics/report?" + url.Values{"month": {month}}.Encode()
+}
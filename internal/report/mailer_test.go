@@ -0,0 +1,127 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	events []notify.Event
+	data   []any
+	err    error
+}
+
+func (s *recordingSender) Send(_ context.Context, event notify.Event, data any) error {
+	s.events = append(s.events, event)
+	s.data = append(s.data, data)
+	return s.err
+}
+
+type staticHistory struct {
+	lastSent time.Time
+	err      error
+}
+
+func (h staticHistory) LastSuccessfulDelivery(event string) (time.Time, error) {
+	return h.lastSent, h.err
+}
+
+func newTestMailer(history DeliveryHistory, now time.Time) (*Mailer, *recordingSender) {
+	source := &staticAssignments{assignments: []*fairness.Assignment{
+		parentNight("Alice", date(2026, time.September, 1), false),
+		parentNight("Bob", date(2026, time.September, 2), true),
+		babysitterNight("Grandma", date(2026, time.September, 3)),
+	}}
+	sender := &recordingSender{}
+	mailer := NewMailer(NewGenerator(source), sender, history, "https://night-routine.example.com/")
+	mailer.now = func() time.Time { return now }
+	return mailer, sender
+}
+
+func TestMailer_SendsPastMonthOnTheFirst(t *testing.T) {
+	mailer, sender := newTestMailer(staticHistory{}, time.Date(2026, time.October, 1, 7, 0, 0, 0, time.UTC))
+
+	require.NoError(t, mailer.Check(context.Background()))
+	require.NoError(t, mailer.Check(context.Background()))
+
+	require.Len(t, sender.events, 1, "sent once per month")
+	assert.Equal(t, notify.EventMonthlyReport, sender.events[0])
+	assert.Equal(t, notify.MonthlyReportData{
+		Month: "September 2026",
+		Totals: []notify.MonthlyReportTotal{
+			{Name: "Alice", Nights: 1},
+			{Name: "Bob", Nights: 1},
+			{Name: "Grandma", Nights: 1, Babysitter: true},
+		},
+		Overrides: 2,
+		ReportURL: "https://night-routine.example.com/statistics/report?month=2026-09",
+	}, sender.data[0])
+}
+
+func TestMailer_Skips(t *testing.T) {
+	tests := []struct {
+		name    string
+		now     time.Time
+		history DeliveryHistory
+	}{
+		{
+			name:    "after the first week of the month",
+			now:     time.Date(2026, time.October, 8, 3, 0, 0, 0, time.UTC),
+			history: staticHistory{},
+		},
+		{
+			name:    "already sent before a restart",
+			now:     time.Date(2026, time.October, 2, 3, 0, 0, 0, time.UTC),
+			history: staticHistory{lastSent: time.Date(2026, time.October, 1, 3, 0, 0, 0, time.UTC)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer, sender := newTestMailer(tt.history, tt.now)
+			require.NoError(t, mailer.Check(context.Background()))
+			assert.Empty(t, sender.events)
+		})
+	}
+}
+
+func TestMailer_SendsWhenHistoryIsOlderOrUnreadable(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 7, 0, 0, 0, time.UTC)
+	for _, history := range []DeliveryHistory{
+		staticHistory{lastSent: time.Date(2026, time.September, 1, 7, 0, 0, 0, time.UTC)},
+		staticHistory{err: errors.New("database is locked")},
+		nil,
+	} {
+		mailer, sender := newTestMailer(history, now)
+		require.NoError(t, mailer.Check(context.Background()))
+		assert.Len(t, sender.events, 1)
+	}
+}
+
+func TestMailer_RetriesAfterFailure(t *testing.T) {
+	mailer, sender := newTestMailer(staticHistory{}, time.Date(2026, time.October, 1, 7, 0, 0, 0, time.UTC))
+	sender.err = errors.New("smtp: connection refused")
+
+	require.Error(t, mailer.Check(context.Background()))
+	sender.err = nil
+	mailer.now = func() time.Time { return time.Date(2026, time.October, 2, 3, 0, 0, 0, time.UTC) }
+	require.NoError(t, mailer.Check(context.Background()))
+	require.NoError(t, mailer.Check(context.Background()))
+
+	assert.Len(t, sender.events, 2, "retried in the next window, then considered sent")
+}
+
+func TestMailer_ReportURLWithoutAppURL(t *testing.T) {
+	mailer, sender := newTestMailer(nil, time.Date(2026, time.October, 1, 7, 0, 0, 0, time.UTC))
+	mailer.appURL = ""
+
+	require.NoError(t, mailer.Send(context.Background(), date(2026, time.September, 1)))
+	require.Len(t, sender.data, 1)
+	assert.Empty(t, sender.data[0].(notify.MonthlyReportData).ReportURL)
+}
@@ -0,0 +1,216 @@
+// Package report generates the monthly summaries of the night routine.
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+)
+
+// TrendMonths is the number of months covered by the fairness trend, the month of the report included
+const TrendMonths = 6
+
+// monthFormat is the format of the months in the reports and their URLs
+const monthFormat = "2006-01"
+
+// MonthlyReport is the summary of the nights of one month
+type MonthlyReport struct {
+	Month       time.Time    // First day of the month
+	Weeks       [][]Day      // Calendar grid, Monday first; days of the neighbouring months are blank
+	Totals      []Total      // Nights per caregiver, parents first, then by nights and name
+	Overrides   []Override   // Nights set by hand, oldest first
+	Trend       []TrendMonth // Fairness of the last TrendMonths months, oldest first
+	GeneratedAt time.Time
+}
+
+// Day is a cell of the calendar grid
+type Day struct {
+	Date       time.Time // Zero for the blank cells outside the month
+	Caregiver  string    // Empty when nobody is assigned
+	Babysitter bool
+	Override   bool
+}
+
+// InMonth reports whether the cell is a day of the month
+func (d Day) InMonth() bool {
+	return !d.Date.IsZero()
+}
+
+// Total is the number of nights of a caregiver during the month
+type Total struct {
+	Name       string
+	Nights     int
+	Babysitter bool
+}
+
+// Override is a night whose caregiver was set by hand
+type Override struct {
+	Date       time.Time
+	Caregiver  string
+	Babysitter bool
+}
+
+// TrendMonth is the split of the parent nights of a month
+type TrendMonth struct {
+	Month  string         // YYYY-MM
+	Nights map[string]int // Parent nights per parent
+	Gap    int            // Nights between the parents who did the most and the fewest nights
+}
+
+// Share returns the rounded percentage of the parent nights of the month done by parent
+func (t TrendMonth) Share(parent string) int {
+	total := 0
+	for _, nights := range t.Nights {
+		total += nights
+	}
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(100 * float64(t.Nights[parent]) / float64(total)))
+}
+
+// Title returns the month of the report, e.g. "September 2026"
+func (r *MonthlyReport) Title() string {
+	return r.Month.Format("January 2006")
+}
+
+// MonthKey returns the month of the report as YYYY-MM
+func (r *MonthlyReport) MonthKey() string {
+	return r.Month.Format(monthFormat)
+}
+
+// Parents returns the names of the parents of the fairness trend, sorted
+func (r *MonthlyReport) Parents() []string {
+	set := make(map[string]struct{})
+	for _, month := range r.Trend {
+		for name := range month.Nights {
+			set[name] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseMonth parses a YYYY-MM month into the first day of the month in loc
+func ParseMonth(value string, loc *time.Location) (time.Time, error) {
+	month, err := time.ParseInLocation(monthFormat, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q, expected YYYY-MM: %w", value, err)
+	}
+	return month, nil
+}
+
+// StartOfMonth returns the first day of the month of t
+func StartOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// Build computes the report of month from the assignments of the TrendMonths months ending with it.
+// Assignments outside that window are ignored.
+func Build(month time.Time, assignments []*fairness.Assignment, generatedAt time.Time) *MonthlyReport {
+	month = StartOfMonth(month)
+	monthKey := month.Format(monthFormat)
+	report := &MonthlyReport{Month: month, GeneratedAt: generatedAt}
+
+	trendStart := month.AddDate(0, -TrendMonths+1, 0)
+	report.Trend = make([]TrendMonth, TrendMonths)
+	trendIndex := make(map[string]int, TrendMonths)
+	for i := range report.Trend {
+		key := trendStart.AddDate(0, i, 0).Format(monthFormat)
+		report.Trend[i] = TrendMonth{Month: key, Nights: make(map[string]int)}
+		trendIndex[key] = i
+	}
+
+	byDay := make(map[int]*fairness.Assignment)
+	totals := make(map[Total]int)
+	parents := make(map[string]struct{})
+	for _, a := range assignments {
+		key := a.Date.Format(monthFormat)
+		babysitter := a.CaregiverType == fairness.CaregiverTypeBabysitter
+		if i, ok := trendIndex[key]; ok && !babysitter {
+			report.Trend[i].Nights[a.Parent]++
+			parents[a.Parent] = struct{}{}
+		}
+		if key != monthKey {
+			continue
+		}
+		byDay[a.Date.Day()] = a
+		totals[Total{Name: a.Parent, Babysitter: babysitter}]++
+		if a.Override {
+			report.Overrides = append(report.Overrides, Override{Date: a.Date, Caregiver: a.Parent, Babysitter: babysitter})
+		}
+	}
+	sort.Slice(report.Overrides, func(i, j int) bool { return report.Overrides[i].Date.Before(report.Overrides[j].Date) })
+
+	// Every parent of the trend has a total, even without nights this month
+	for name := range parents {
+		if _, ok := totals[Total{Name: name}]; !ok {
+			totals[Total{Name: name}] = 0
+		}
+	}
+	for total, nights := range totals {
+		total.Nights = nights
+		report.Totals = append(report.Totals, total)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool {
+		a, b := report.Totals[i], report.Totals[j]
+		if a.Babysitter != b.Babysitter {
+			return !a.Babysitter
+		}
+		if a.Nights != b.Nights {
+			return a.Nights > b.Nights
+		}
+		return a.Name < b.Name
+	})
+
+	for i := range report.Trend {
+		fewest, most := -1, 0
+		for name := range parents {
+			nights := report.Trend[i].Nights[name]
+			most = max(most, nights)
+			if fewest < 0 || nights < fewest {
+				fewest = nights
+			}
+		}
+		if fewest >= 0 {
+			report.Trend[i].Gap = most - fewest
+		}
+	}
+
+	report.Weeks = calendarGrid(month, byDay)
+	return report
+}
+
+// calendarGrid lays the days of month out in weeks starting on Monday
+func calendarGrid(month time.Time, byDay map[int]*fairness.Assignment) [][]Day {
+	// Monday is the first column: Sunday moves from 0 to 6
+	offset := (int(month.Weekday()) + 6) % 7
+	daysInMonth := month.AddDate(0, 1, -1).Day()
+
+	var weeks [][]Day
+	week := make([]Day, offset, 7)
+	for day := 1; day <= daysInMonth; day++ {
+		cell := Day{Date: time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, month.Location())}
+		if a, ok := byDay[day]; ok {
+			cell.Caregiver = a.Parent
+			cell.Babysitter = a.CaregiverType == fairness.CaregiverTypeBabysitter
+			cell.Override = a.Override
+		}
+		week = append(week, cell)
+		if len(week) == 7 {
+			weeks = append(weeks, week)
+			week = make([]Day, 0, 7)
+		}
+	}
+	if len(week) > 0 {
+		weeks = append(weeks, append(week, make([]Day, 7-len(week))...))
+	}
+	return weeks
+}
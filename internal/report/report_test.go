@@ -0,0 +1,153 @@
+package report
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func parentNight(parent string, d time.Time, override bool) *fairness.Assignment {
+	return &fairness.Assignment{Parent: parent, Date: d, Override: override, CaregiverType: fairness.CaregiverTypeParent}
+}
+
+func babysitterNight(name string, d time.Time) *fairness.Assignment {
+	return &fairness.Assignment{Parent: name, Date: d, Override: true, CaregiverType: fairness.CaregiverTypeBabysitter}
+}
+
+func TestBuild(t *testing.T) {
+	assignments := []*fairness.Assignment{
+		parentNight("Alice", date(2026, time.September, 1), false),
+		parentNight("Bob", date(2026, time.September, 2), true),
+		parentNight("Alice", date(2026, time.September, 3), false),
+		babysitterNight("Grandma", date(2026, time.September, 30)),
+		parentNight("Alice", date(2026, time.August, 10), false),
+		parentNight("Alice", date(2026, time.August, 11), false),
+		parentNight("Bob", date(2026, time.August, 12), false),
+		parentNight("Charlie", date(2026, time.February, 1), false), // Outside the trend window
+		parentNight("Bob", date(2026, time.October, 1), false),      // After the month of the report
+	}
+	generatedAt := time.Date(2026, time.October, 1, 8, 0, 0, 0, time.UTC)
+
+	report := Build(date(2026, time.September, 17), assignments, generatedAt)
+
+	assert.Equal(t, date(2026, time.September, 1), report.Month)
+	assert.Equal(t, "September 2026", report.Title())
+	assert.Equal(t, "2026-09", report.MonthKey())
+	assert.Equal(t, generatedAt, report.GeneratedAt)
+
+	t.Run("calendar grid", func(t *testing.T) {
+		// September 2026 starts on a Tuesday and ends on a Wednesday
+		require.Len(t, report.Weeks, 5)
+		for _, week := range report.Weeks {
+			assert.Len(t, week, 7)
+		}
+		assert.False(t, report.Weeks[0][0].InMonth(), "Monday August 31 is blank")
+		assert.Equal(t, Day{Date: date(2026, time.September, 1), Caregiver: "Alice"}, report.Weeks[0][1])
+		assert.Equal(t, Day{Date: date(2026, time.September, 2), Caregiver: "Bob", Override: true}, report.Weeks[0][2])
+		assert.Equal(t, Day{Date: date(2026, time.September, 4)}, report.Weeks[0][4], "nobody assigned")
+		assert.Equal(t, Day{Date: date(2026, time.September, 30), Caregiver: "Grandma", Babysitter: true, Override: true}, report.Weeks[4][2])
+		assert.False(t, report.Weeks[4][3].InMonth(), "Thursday October 1 is blank")
+	})
+
+	t.Run("totals", func(t *testing.T) {
+		assert.Equal(t, []Total{
+			{Name: "Alice", Nights: 2},
+			{Name: "Bob", Nights: 1},
+			{Name: "Grandma", Nights: 1, Babysitter: true},
+		}, report.Totals)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		assert.Equal(t, []Override{
+			{Date: date(2026, time.September, 2), Caregiver: "Bob"},
+			{Date: date(2026, time.September, 30), Caregiver: "Grandma", Babysitter: true},
+		}, report.Overrides)
+	})
+
+	t.Run("fairness trend", func(t *testing.T) {
+		require.Len(t, report.Trend, TrendMonths)
+		assert.Equal(t, "2026-04", report.Trend[0].Month)
+		assert.Equal(t, 0, report.Trend[0].Gap)
+		assert.Equal(t, TrendMonth{Month: "2026-08", Nights: map[string]int{"Alice": 2, "Bob": 1}, Gap: 1}, report.Trend[4])
+		assert.Equal(t, TrendMonth{Month: "2026-09", Nights: map[string]int{"Alice": 2, "Bob": 1}, Gap: 1}, report.Trend[5])
+		assert.Equal(t, 67, report.Trend[5].Share("Alice"))
+		assert.Equal(t, 0, report.Trend[0].Share("Alice"), "no nights")
+		assert.Equal(t, []string{"Alice", "Bob"}, report.Parents())
+	})
+}
+
+func TestBuild_ParentWithoutNightsThisMonth(t *testing.T) {
+	report := Build(date(2026, time.September, 1), []*fairness.Assignment{
+		parentNight("Alice", date(2026, time.September, 1), false),
+		parentNight("Bob", date(2026, time.July, 1), false),
+	}, time.Now())
+
+	assert.Equal(t, []Total{{Name: "Alice", Nights: 1}, {Name: "Bob", Nights: 0}}, report.Totals)
+	assert.Equal(t, 1, report.Trend[5].Gap, "Bob did no night in September")
+}
+
+func TestBuild_Empty(t *testing.T) {
+	report := Build(date(2026, time.February, 1), nil, time.Now())
+
+	assert.Empty(t, report.Totals)
+	assert.Empty(t, report.Overrides)
+	assert.Empty(t, report.Parents())
+	// February 2026 starts on a Sunday, the last column of the first week
+	assert.Len(t, report.Weeks, 5)
+	assert.False(t, report.Weeks[0][5].InMonth())
+	assert.Equal(t, date(2026, time.February, 1), report.Weeks[0][6].Date)
+}
+
+func TestParseMonth(t *testing.T) {
+	month, err := ParseMonth("2026-09", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, date(2026, time.September, 1), month)
+
+	for _, value := range []string{"", "2026-13", "2026-9", "September"} {
+		_, err := ParseMonth(value, time.UTC)
+		assert.Error(t, err, value)
+	}
+}
+
+type staticAssignments struct {
+	assignments []*fairness.Assignment
+	start, end  time.Time
+}
+
+func (s *staticAssignments) GetAssignmentsInRange(_ context.Context, start, end time.Time) ([]*fairness.Assignment, error) {
+	s.start, s.end = start, end
+	return s.assignments, nil
+}
+
+func TestGeneratorAndRenderHTML(t *testing.T) {
+	source := &staticAssignments{assignments: []*fairness.Assignment{
+		parentNight("Alice", date(2026, time.September, 1), false),
+		parentNight("<Bob>", date(2026, time.September, 2), true),
+	}}
+	generator := NewGenerator(source)
+	generator.now = func() time.Time { return time.Date(2026, time.October, 1, 8, 0, 0, 0, time.UTC) }
+
+	report, err := generator.Generate(context.Background(), date(2026, time.September, 15))
+	require.NoError(t, err)
+	assert.Equal(t, date(2026, time.April, 1), source.start, "trend window starts 5 months before")
+	assert.Equal(t, date(2026, time.September, 30), source.end)
+
+	var html strings.Builder
+	require.NoError(t, RenderHTML(&html, report))
+	out := html.String()
+	assert.Contains(t, out, "<title>Night Routine - September 2026</title>")
+	assert.Contains(t, out, "Generated on 2026-10-01 08:00")
+	assert.Contains(t, out, "Wed 2026-09-02")
+	assert.Contains(t, out, "&lt;Bob&gt;", "names are escaped")
+	assert.NotContains(t, out, "<Bob>")
+	assert.Contains(t, out, `style="width: 50%"`)
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/rs/zerolog"
+)
+
+// weeklyDateFormat formats the nights of the weekly summary, e.g. "Monday 12 October"
+const weeklyDateFormat = "Monday 2 January"
+
+// SubscriptionSender emails an event to the parents subscribed to it, implemented by notify.Service
+type SubscriptionSender interface {
+	EmailSubscribers(ctx context.Context, subscription config.EmailSubscription, event notify.Event, data any) error
+}
+
+// WeeklyMailer emails the nights of the week to the parents subscribed to the weekly summary, once
+// per week from Monday, checked by the maintenance window. A summary already delivered that week,
+// before a restart for example, is not sent again; a failed delivery is retried in the next window.
+type WeeklyMailer struct {
+	assignments AssignmentSource
+	sender      SubscriptionSender
+	history     DeliveryHistory // nil to rely on the in-memory state only
+	appURL      string
+	now         func() time.Time // injectable for testing; defaults to time.Now
+	logger      zerolog.Logger
+
+	sentWeek string // Monday of the last week sent by this mailer, YYYY-MM-DD
+}
+
+// NewWeeklyMailer creates a mailer summing up the nights of assignments through sender. appURL is
+// the public URL of the application, used to link the schedule; it may be empty.
+func NewWeeklyMailer(assignments AssignmentSource, sender SubscriptionSender, history DeliveryHistory, appURL string) *WeeklyMailer {
+	return &WeeklyMailer{
+		assignments: assignments,
+		sender:      sender,
+		history:     history,
+		appURL:      strings.TrimRight(appURL, "/"),
+		now:         time.Now,
+		logger:      logging.GetLogger("weekly-mailer"),
+	}
+}
+
+// Check sends the summary of the current week when it was not sent yet
+func (m *WeeklyMailer) Check(ctx context.Context) error {
+	monday := startOfWeek(m.now())
+	weekKey := monday.Format(time.DateOnly)
+	if m.sentWeek == weekKey {
+		return nil
+	}
+	logger := m.logger.With().Str("week", weekKey).Logger()
+
+	if m.history != nil {
+		lastSent, err := m.history.LastSuccessfulDelivery(notify.EventWeeklySummary.String())
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to read when the weekly summary was last sent, sending it anyway")
+		} else if !lastSent.Before(monday) {
+			logger.Debug().Time("last_sent", lastSent).Msg("Weekly summary already sent")
+			m.sentWeek = weekKey
+			return nil
+		}
+	}
+
+	if err := m.Send(ctx, monday); err != nil {
+		return fmt.Errorf("failed to send the weekly summary of %s: %w", weekKey, err)
+	}
+	m.sentWeek = weekKey
+	logger.Info().Msg("Weekly summary sent")
+	return nil
+}
+
+// Send emails the nights of the 7 days from monday to the subscribed parents
+func (m *WeeklyMailer) Send(ctx context.Context, monday time.Time) error {
+	assignments, err := m.assignments.GetAssignmentsInRange(ctx, monday, monday.AddDate(0, 0, 6))
+	if err != nil {
+		return fmt.Errorf("failed to get the assignments of the week: %w", err)
+	}
+
+	data := notify.WeeklySummaryData{Week: monday.Format(weeklyDateFormat), AppURL: m.appURL}
+	for _, assignment := range assignments {
+		data.Nights = append(data.Nights, notify.WeeklySummaryNight{
+			Date:       assignment.Date.Format(weeklyDateFormat),
+			Caregiver:  assignment.Parent,
+			Babysitter: assignment.CaregiverType == fairness.CaregiverTypeBabysitter,
+		})
+	}
+	return m.sender.EmailSubscribers(ctx, config.EmailWeeklySummary, notify.EventWeeklySummary, data)
+}
+
+// startOfWeek returns midnight of the Monday of the week of t, in the location of t
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
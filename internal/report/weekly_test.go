@@ -0,0 +1,87 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/fairness"
+	"github.com/belphemur/night-routine/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSubscriptionSender struct {
+	subscriptions []config.EmailSubscription
+	events        []notify.Event
+	data          []any
+	err           error
+}
+
+func (s *recordingSubscriptionSender) EmailSubscribers(_ context.Context, subscription config.EmailSubscription, event notify.Event, data any) error {
+	s.subscriptions = append(s.subscriptions, subscription)
+	s.events = append(s.events, event)
+	s.data = append(s.data, data)
+	return s.err
+}
+
+func newTestWeeklyMailer(history DeliveryHistory, now time.Time) (*WeeklyMailer, *recordingSubscriptionSender, *staticAssignments) {
+	source := &staticAssignments{assignments: []*fairness.Assignment{
+		parentNight("Alice", date(2026, time.October, 12), false),
+		parentNight("Bob", date(2026, time.October, 13), false),
+		babysitterNight("Grandma", date(2026, time.October, 14)),
+	}}
+	sender := &recordingSubscriptionSender{}
+	mailer := NewWeeklyMailer(source, sender, history, "https://night-routine.example.com/")
+	mailer.now = func() time.Time { return now }
+	return mailer, sender, source
+}
+
+func TestWeeklyMailer_SendsTheWeekOnce(t *testing.T) {
+	// Friday 16 October belongs to the week starting Monday 12 October
+	mailer, sender, source := newTestWeeklyMailer(staticHistory{}, time.Date(2026, time.October, 16, 3, 0, 0, 0, time.UTC))
+
+	require.NoError(t, mailer.Check(context.Background()))
+	require.NoError(t, mailer.Check(context.Background()))
+
+	require.Len(t, sender.events, 1, "sent once per week")
+	assert.Equal(t, date(2026, time.October, 12), source.start)
+	assert.Equal(t, date(2026, time.October, 18), source.end)
+	assert.Equal(t, config.EmailWeeklySummary, sender.subscriptions[0])
+	assert.Equal(t, notify.EventWeeklySummary, sender.events[0])
+	assert.Equal(t, notify.WeeklySummaryData{
+		Week: "Monday 12 October",
+		Nights: []notify.WeeklySummaryNight{
+			{Date: "Monday 12 October", Caregiver: "Alice"},
+			{Date: "Tuesday 13 October", Caregiver: "Bob"},
+			{Date: "Wednesday 14 October", Caregiver: "Grandma", Babysitter: true},
+		},
+		AppURL: "https://night-routine.example.com",
+	}, sender.data[0])
+
+	// The next week is sent from its Monday
+	mailer.now = func() time.Time { return time.Date(2026, time.October, 19, 3, 0, 0, 0, time.UTC) }
+	require.NoError(t, mailer.Check(context.Background()))
+	require.Len(t, sender.events, 2)
+	assert.Equal(t, date(2026, time.October, 19), source.start)
+}
+
+func TestWeeklyMailer_SkipsAfterRestart(t *testing.T) {
+	history := staticHistory{lastSent: time.Date(2026, time.October, 12, 3, 0, 0, 0, time.UTC)}
+	mailer, sender, _ := newTestWeeklyMailer(history, time.Date(2026, time.October, 16, 3, 0, 0, 0, time.UTC))
+
+	require.NoError(t, mailer.Check(context.Background()))
+	assert.Empty(t, sender.events)
+}
+
+func TestWeeklyMailer_RetriesAfterFailure(t *testing.T) {
+	mailer, sender, _ := newTestWeeklyMailer(staticHistory{err: errors.New("database locked")}, time.Date(2026, time.October, 12, 3, 0, 0, 0, time.UTC))
+	sender.err = errors.New("smtp unreachable")
+
+	require.Error(t, mailer.Check(context.Background()))
+	sender.err = nil
+	require.NoError(t, mailer.Check(context.Background()))
+	assert.Len(t, sender.events, 2, "retried in the next window")
+}
@@ -0,0 +1,339 @@
+package signals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// EventType names a domain event persisted by the Bus
+type EventType string
+
+// Domain events persisted by the Bus
+const (
+	// EventTokenSetup is published when the Google token is saved or cleared, with a TokenSetupData payload
+	EventTokenSetup EventType = "token_setup"
+	// EventCalendarSelected is published when a calendar is selected, with a CalendarSelectedData payload
+	EventCalendarSelected EventType = "calendar_selected"
+	// EventAssignmentOverridden is published when an assignment is set by hand, with an AssignmentOverriddenData payload
+	EventAssignmentOverridden EventType = "assignment_overridden"
+	// EventAssignmentCreated is published when a date gets its first assignment, with an AssignmentCreatedData payload
+	EventAssignmentCreated EventType = "assignment_created"
+	// EventAssignmentUnlocked is published when an override is removed, with an AssignmentUnlockedData payload
+	EventAssignmentUnlocked EventType = "assignment_unlocked"
+	// EventSyncCompleted is published when a schedule sync ends, with a SyncCompletedPayload payload
+	EventSyncCompleted EventType = "sync_completed"
+)
+
+// Event is a domain event as persisted by the Bus
+type Event struct {
+	ID         int64 // Increasing identifier assigned by the store, 0 when the event could not be persisted
+	Type       EventType
+	Payload    json.RawMessage
+	OccurredAt time.Time
+}
+
+// Decode unmarshals the payload of the event into v
+func (e Event) Decode(v any) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode payload of event %d (%s): %w", e.ID, e.Type, err)
+	}
+	return nil
+}
+
+// SyncCompletedPayload is the payload of EventSyncCompleted; the error is kept as its message
+type SyncCompletedPayload struct {
+	Trigger          string `json:"trigger"`
+	AssignmentsCount int    `json:"assignments_count"`
+	Error            string `json:"error,omitempty"`
+}
+
+// EventStore persists the domain events
+type EventStore interface {
+	// AppendEvent stores the event and returns its identifier
+	AppendEvent(ctx context.Context, event Event) (int64, error)
+	// ListEvents returns at most limit events stored after the event afterID, oldest first
+	ListEvents(ctx context.Context, afterID int64, limit int) ([]Event, error)
+}
+
+// SubscriberFunc handles an event delivered to a subscriber. A returned error makes the Bus retry.
+type SubscriberFunc func(ctx context.Context, event Event) error
+
+// retryPolicy bounds the retries of a failing subscriber
+type retryPolicy struct {
+	attempts     int           // Total number of attempts, including the first one
+	initialDelay time.Duration // Delay before the first retry, doubled on every retry
+	maxDelay     time.Duration // Upper bound of the delay between two attempts
+}
+
+// defaultRetryPolicy retries a failing subscriber for about two minutes
+var defaultRetryPolicy = retryPolicy{
+	attempts:     6,
+	initialDelay: 2 * time.Second,
+	maxDelay:     time.Minute,
+}
+
+// Bus persists domain events and delivers them to asynchronous subscribers. Each subscriber
+// receives the events in order from its own queue, so a slow or failing subscriber neither blocks
+// the publisher nor the other subscribers. A failing delivery is retried with an exponential backoff,
+// then dropped; the event stays in the store and can be read again with Events.
+type Bus struct {
+	store  EventStore
+	retry  retryPolicy
+	logger zerolog.Logger
+
+	// ctx is cancelled by Close to abort the deliveries still retrying
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	subscribers []*subscriber
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// subscriber is the queue of events waiting for one subscriber
+type subscriber struct {
+	name    string
+	types   map[EventType]bool // Empty to receive every event
+	handler SubscriberFunc
+
+	mu      sync.Mutex
+	queue   []Event
+	wake    chan struct{} // Signaled when the queue grows or the bus closes
+	closing bool
+}
+
+// NewBus creates a bus persisting events to store. A nil store only delivers the events.
+func NewBus(store EventStore) *Bus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Bus{
+		store:  store,
+		retry:  defaultRetryPolicy,
+		logger: logging.GetLogger("event-bus"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Subscribe registers handler for the events of the given types, every event when none is given.
+// The handler runs in a goroutine dedicated to the subscriber and only receives the events
+// published after the subscription.
+func (b *Bus) Subscribe(name string, handler SubscriberFunc, types ...EventType) {
+	sub := &subscriber{
+		name:    name,
+		types:   make(map[EventType]bool, len(types)),
+		handler: handler,
+		wake:    make(chan struct{}, 1),
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		b.logger.Warn().Str("subscriber", name).Msg("Bus closed, subscription ignored")
+		return
+	}
+	b.subscribers = append(b.subscribers, sub)
+	b.wg.Add(1)
+	go b.run(sub)
+}
+
+// Publish persists an event of type eventType with payload encoded as JSON, then queues it for the
+// subscribers. The event is still delivered when it cannot be persisted; the store error is returned.
+func (b *Bus) Publish(ctx context.Context, eventType EventType, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload of %s: %w", eventType, err)
+	}
+	event := Event{Type: eventType, Payload: data, OccurredAt: time.Now().UTC()}
+
+	var storeErr error
+	if b.store != nil {
+		event.ID, storeErr = b.store.AppendEvent(ctx, event)
+		if storeErr != nil {
+			b.logger.Error().Err(storeErr).Str("type", string(eventType)).Msg("Failed to persist event")
+			storeErr = fmt.Errorf("failed to persist %s: %w", eventType, storeErr)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errors.Join(storeErr, fmt.Errorf("bus closed, %s not delivered", eventType))
+	}
+	for _, sub := range b.subscribers {
+		sub.push(event)
+	}
+	return storeErr
+}
+
+// Events returns at most limit persisted events published after the event afterID, oldest first.
+// A subscriber that missed events, after a restart for example, catches up from the last ID it handled.
+func (b *Bus) Events(ctx context.Context, afterID int64, limit int) ([]Event, error) {
+	if b.store == nil {
+		return nil, errors.New("no event store configured")
+	}
+	return b.store.ListEvents(ctx, afterID, limit)
+}
+
+// Close stops accepting events and waits for the subscribers to drain their queues, at most until
+// ctx is done. The deliveries still waiting after that are abandoned.
+func (b *Bus) Close(ctx context.Context) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, sub := range b.subscribers {
+		sub.close()
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		b.logger.Warn().Msg("Subscribers did not drain their events in time, abandoning them")
+	}
+	// Abort the deliveries waiting for a retry, the queues are then drained without retrying
+	b.cancel()
+	<-done
+}
+
+// push queues event when the subscriber wants it
+func (s *subscriber) push(event Event) {
+	if len(s.types) > 0 && !s.types[event.Type] {
+		return
+	}
+	s.mu.Lock()
+	s.queue = append(s.queue, event)
+	s.mu.Unlock()
+	s.signal()
+}
+
+// close makes the subscriber exit once its queue is empty
+func (s *subscriber) close() {
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+	s.signal()
+}
+
+// signal wakes the subscriber goroutine up without blocking
+func (s *subscriber) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next pops the oldest queued event; ok is false once the subscriber is closed and drained
+func (s *subscriber) next() (event Event, ok bool) {
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			event = s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+			return event, true
+		}
+		closing := s.closing
+		s.mu.Unlock()
+		if closing {
+			return Event{}, false
+		}
+		<-s.wake
+	}
+}
+
+// run delivers the queued events to the subscriber until it is closed
+func (b *Bus) run(sub *subscriber) {
+	defer b.wg.Done()
+	for {
+		event, ok := sub.next()
+		if !ok {
+			return
+		}
+		b.deliver(sub, event)
+	}
+}
+
+// deliver hands event to the subscriber, retrying with an exponential backoff while it fails
+func (b *Bus) deliver(sub *subscriber, event Event) {
+	logger := b.logger.With().Str("subscriber", sub.name).Int64("event_id", event.ID).Str("type", string(event.Type)).Logger()
+	delay := b.retry.initialDelay
+	for attempt := 1; ; attempt++ {
+		err := b.handle(sub, event)
+		if err == nil {
+			return
+		}
+		if attempt >= b.retry.attempts {
+			logger.Error().Err(err).Int("attempts", attempt).Msg("Subscriber failed to handle event, giving up")
+			return
+		}
+		logger.Warn().Err(err).Int("attempt", attempt).Dur("wait", delay).Msg("Subscriber failed to handle event, retrying")
+		select {
+		case <-b.ctx.Done():
+			logger.Warn().Msg("Bus closed, event abandoned")
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, b.retry.maxDelay)
+	}
+}
+
+// handle runs the subscriber handler, turning a panic into an error so it is retried like one
+func (b *Bus) handle(sub *subscriber, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber panicked: %v", r)
+		}
+	}()
+	return sub.handler(b.ctx, event)
+}
+
+// PersistSignals publishes the token setup, calendar selection, assignment creation, override and
+// unlock, and sync completion signals on the bus, so that they are persisted and delivered to its subscribers.
+func (b *Bus) PersistSignals() {
+	publish := func(ctx context.Context, eventType EventType, payload any) {
+		if err := b.Publish(ctx, eventType, payload); err != nil {
+			b.logger.Warn().Err(err).Str("type", string(eventType)).Msg("Failed to publish signal on the bus")
+		}
+	}
+	OnTokenSetup(func(ctx context.Context, data TokenSetupData) {
+		publish(ctx, EventTokenSetup, data)
+	}, "event-bus-token-setup")
+	OnCalendarSelected(func(ctx context.Context, data CalendarSelectedData) {
+		publish(ctx, EventCalendarSelected, data)
+	}, "event-bus-calendar-selected")
+	OnAssignmentOverridden(func(ctx context.Context, data AssignmentOverriddenData) {
+		publish(ctx, EventAssignmentOverridden, data)
+	}, "event-bus-assignment-overridden")
+	OnAssignmentCreated(func(ctx context.Context, data AssignmentCreatedData) {
+		publish(ctx, EventAssignmentCreated, data)
+	}, "event-bus-assignment-created")
+	OnAssignmentUnlocked(func(ctx context.Context, data AssignmentUnlockedData) {
+		publish(ctx, EventAssignmentUnlocked, data)
+	}, "event-bus-assignment-unlocked")
+	OnSyncCompleted(func(ctx context.Context, data SyncCompletedData) {
+		payload := SyncCompletedPayload{Trigger: data.Trigger, AssignmentsCount: data.AssignmentsCount}
+		if data.Err != nil {
+			payload.Error = data.Err.Error()
+		}
+		publish(ctx, EventSyncCompleted, payload)
+	}, "event-bus-sync-completed")
+}
@@ -0,0 +1,197 @@
+package signals
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory EventStore
+type memoryStore struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+}
+
+func (s *memoryStore) AppendEvent(_ context.Context, event Event) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	event.ID = int64(len(s.events) + 1)
+	s.events = append(s.events, event)
+	return event.ID, nil
+}
+
+func (s *memoryStore) ListEvents(_ context.Context, afterID int64, limit int) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []Event
+	for _, event := range s.events {
+		if event.ID > afterID && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// recorder collects the events delivered to a subscriber
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) handle(_ context.Context, event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recorder) types() []EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]EventType, 0, len(r.events))
+	for _, event := range r.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// newTestBus creates a bus retrying without waiting
+func newTestBus(store EventStore) *Bus {
+	bus := NewBus(store)
+	bus.retry = retryPolicy{attempts: 3, initialDelay: time.Millisecond, maxDelay: time.Millisecond}
+	return bus
+}
+
+func TestBus_PublishPersistsAndDelivers(t *testing.T) {
+	store := &memoryStore{}
+	bus := newTestBus(store)
+	all, syncs := &recorder{}, &recorder{}
+	bus.Subscribe("all", all.handle)
+	bus.Subscribe("syncs", syncs.handle, EventSyncCompleted)
+
+	require.NoError(t, bus.Publish(context.Background(), EventCalendarSelected, CalendarSelectedData{CalendarID: "family"}))
+	require.NoError(t, bus.Publish(context.Background(), EventSyncCompleted, SyncCompletedPayload{Trigger: "manual", AssignmentsCount: 3}))
+	bus.Close(context.Background())
+
+	assert.Equal(t, []EventType{EventCalendarSelected, EventSyncCompleted}, all.types())
+	assert.Equal(t, []EventType{EventSyncCompleted}, syncs.types(), "subscribers only receive the types they asked for")
+
+	events, err := bus.Events(context.Background(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, int64(1), all.events[0].ID, "subscribers receive the persisted ID")
+	var selected CalendarSelectedData
+	require.NoError(t, events[0].Decode(&selected))
+	assert.Equal(t, "family", selected.CalendarID)
+	assert.JSONEq(t, `{"trigger":"manual","assignments_count":3}`, string(events[1].Payload))
+}
+
+func TestBus_RetriesFailingSubscriber(t *testing.T) {
+	bus := newTestBus(nil)
+	var mu sync.Mutex
+	attempts := map[int64]int{}
+	bus.Subscribe("flaky", func(_ context.Context, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		var payload SyncCompletedPayload
+		require.NoError(t, event.Decode(&payload))
+		attempts[int64(payload.AssignmentsCount)]++
+		switch payload.AssignmentsCount {
+		case 1:
+			if attempts[1] < 2 {
+				return errors.New("temporary failure")
+			}
+		case 2:
+			return errors.New("permanent failure")
+		case 3:
+			panic("broken subscriber")
+		}
+		return nil
+	})
+
+	for count := 1; count <= 4; count++ {
+		require.NoError(t, bus.Publish(context.Background(), EventSyncCompleted, SyncCompletedPayload{AssignmentsCount: count}))
+	}
+	bus.Close(context.Background())
+
+	assert.Equal(t, map[int64]int{1: 2, 2: 3, 3: 3, 4: 1}, attempts, "retried until success or the last attempt, panics included")
+}
+
+func TestBus_StoreFailureStillDelivers(t *testing.T) {
+	bus := newTestBus(&memoryStore{err: errors.New("database is locked")})
+	received := &recorder{}
+	bus.Subscribe("received", received.handle)
+
+	err := bus.Publish(context.Background(), EventTokenSetup, TokenSetupData{Success: true})
+	bus.Close(context.Background())
+
+	assert.ErrorContains(t, err, "failed to persist token_setup")
+	assert.Equal(t, []EventType{EventTokenSetup}, received.types())
+}
+
+func TestBus_Closed(t *testing.T) {
+	bus := newTestBus(nil)
+	bus.Close(context.Background())
+
+	assert.Error(t, bus.Publish(context.Background(), EventTokenSetup, TokenSetupData{}))
+	_, err := bus.Events(context.Background(), 0, 10)
+	assert.Error(t, err, "no store to read from")
+	bus.Close(context.Background())
+}
+
+func TestBus_CloseAbandonsPendingRetries(t *testing.T) {
+	bus := NewBus(nil)
+	bus.retry = retryPolicy{attempts: 10, initialDelay: time.Hour, maxDelay: time.Hour}
+	bus.Subscribe("failing", func(context.Context, Event) error { return errors.New("down") })
+	require.NoError(t, bus.Publish(context.Background(), EventTokenSetup, TokenSetupData{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	bus.Close(ctx)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestBus_PersistSignals(t *testing.T) {
+	store := &memoryStore{}
+	bus := newTestBus(store)
+	bus.PersistSignals()
+	t.Cleanup(func() {
+		TokenSetup.RemoveListener("event-bus-token-setup")
+		CalendarSelected.RemoveListener("event-bus-calendar-selected")
+		AssignmentOverridden.RemoveListener("event-bus-assignment-overridden")
+		AssignmentCreated.RemoveListener("event-bus-assignment-created")
+		AssignmentUnlocked.RemoveListener("event-bus-assignment-unlocked")
+		SyncCompleted.RemoveListener("event-bus-sync-completed")
+	})
+
+	ctx := context.Background()
+	EmitTokenSetup(ctx, true)
+	EmitCalendarSelected(ctx, "family")
+	EmitAssignmentOverridden(ctx, 7, "Babysitter", "babysitter")
+	EmitSyncCompleted(ctx, "manual", 0, errors.New("token revoked"))
+	EmitAssignmentCreated(ctx, 8, time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC), "Alice", "parent")
+	EmitAssignmentUnlocked(ctx, 7)
+
+	events, err := bus.Events(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 6)
+	assert.Equal(t, EventTokenSetup, events[0].Type)
+	assert.JSONEq(t, `{"success":true}`, string(events[0].Payload))
+	assert.JSONEq(t, `{"calendar_id":"family"}`, string(events[1].Payload))
+	assert.JSONEq(t, `{"assignment_id":7,"caregiver":"Babysitter","caregiver_type":"babysitter"}`, string(events[2].Payload))
+	assert.JSONEq(t, `{"trigger":"manual","assignments_count":0,"error":"token revoked"}`, string(events[3].Payload))
+	assert.Equal(t, EventAssignmentCreated, events[4].Type)
+	assert.JSONEq(t, `{"assignment_id":8,"date":"2026-10-16","caregiver":"Alice","caregiver_type":"parent"}`, string(events[4].Payload))
+	assert.Equal(t, EventAssignmentUnlocked, events[5].Type)
+	assert.JSONEq(t, `{"assignment_id":7}`, string(events[5].Payload))
+}
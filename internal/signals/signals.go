@@ -2,6 +2,7 @@ package signals
 
 import (
 	"context"
+	"time"
 
 	"github.com/maniartech/signals"
 )
@@ -9,17 +10,101 @@ import (
 // TokenSetupData contains data associated with token setup signal
 type TokenSetupData struct {
 	// You can add additional fields here if needed
-	Success bool
+	Success bool `json:"success"`
 }
 
 // CalendarSelectedData contains data associated with calendar selection signal
 type CalendarSelectedData struct {
+	CalendarID string `json:"calendar_id"`
+}
+
+// TokenRefreshFailedData contains data associated with a permanent token refresh failure
+type TokenRefreshFailedData struct {
+	Err error
+}
+
+// SyncCompletedData contains data associated with the end of a schedule sync
+type SyncCompletedData struct {
+	Trigger          string
+	AssignmentsCount int
+	Err              error // nil when the sync succeeded
+}
+
+// SyncProgressData contains data associated with the progress of a schedule sync
+type SyncProgressData struct {
+	RunID     int64    `json:"run_id"` // 0 when the sync run could not be recorded
+	Trigger   string   `json:"trigger"`
+	Status    string   `json:"status"`    // running until the sync ends, then success or failed
+	Total     int      `json:"total"`     // Assignments to sync, known once the calendar sync starts
+	Processed int      `json:"processed"` // Assignments synced or failed
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"` // Latest errors, see maxSyncProgressErrors
+}
+
+// WebhookProcessedData contains data associated with the processing of a calendar webhook
+type WebhookProcessedData struct {
 	CalendarID string
+	Err        error // nil when the changes were processed
+}
+
+// Sections of the runtime configuration reported by ConfigChanged
+const (
+	ConfigSectionParents      = "parents"
+	ConfigSectionAvailability = "availability"
+	ConfigSectionSchedule     = "schedule"
+	ConfigSectionNotify       = "notify"
+	ConfigSectionChecklist    = "checklist"
+	ConfigSectionComments     = "comments"
+	ConfigSectionVacation     = "vacation"
+	ConfigSectionSkipDates    = "skip_dates"
+	ConfigSectionRestDays     = "rest_days"
+	ConfigSectionDayWeights   = "day_weights"
+	ConfigSectionSyncExclude  = "sync_exclusions"
+	ConfigSectionAll          = "all" // Every section, e.g. once the data is wiped
+)
+
+// ConfigChangedData contains data associated with a runtime configuration write
+type ConfigChangedData struct {
+	Section string
+}
+
+// AssignmentsChangedData contains data associated with an assignment write
+type AssignmentsChangedData struct {
+	Date time.Time // Date of the written assignment; zero when the write is not tied to a known date
+}
+
+// AssignmentOverriddenData contains data associated with an assignment set by hand
+type AssignmentOverriddenData struct {
+	AssignmentID  int64  `json:"assignment_id"`
+	Caregiver     string `json:"caregiver"`      // Parent or babysitter now in charge
+	CaregiverType string `json:"caregiver_type"` // parent or babysitter
+}
+
+// AssignmentCreatedData contains data associated with the first assignment of a date
+type AssignmentCreatedData struct {
+	AssignmentID  int64  `json:"assignment_id"`
+	Date          string `json:"date"`           // YYYY-MM-DD
+	Caregiver     string `json:"caregiver"`      // Parent or babysitter in charge
+	CaregiverType string `json:"caregiver_type"` // parent or babysitter
+}
+
+// AssignmentUnlockedData contains data associated with an assignment handed back to the scheduler
+type AssignmentUnlockedData struct {
+	AssignmentID int64 `json:"assignment_id"`
 }
 
 // Signal definitions using generics
 var TokenSetup = signals.New[TokenSetupData]()
 var CalendarSelected = signals.New[CalendarSelectedData]()
+var TokenRefreshFailed = signals.New[TokenRefreshFailedData]()
+var SyncCompleted = signals.New[SyncCompletedData]()
+var SyncProgress = signals.New[SyncProgressData]()
+var WebhookProcessed = signals.New[WebhookProcessedData]()
+var ConfigChanged = signals.New[ConfigChangedData]()
+var AssignmentsChanged = signals.New[AssignmentsChangedData]()
+var AssignmentOverridden = signals.New[AssignmentOverriddenData]()
+var AssignmentCreated = signals.New[AssignmentCreatedData]()
+var AssignmentUnlocked = signals.New[AssignmentUnlockedData]()
 
 // EmitTokenSetup emits a signal when a token is successfully set up
 func EmitTokenSetup(ctx context.Context, success bool) {
@@ -35,6 +120,76 @@ func EmitCalendarSelected(ctx context.Context, calendarID string) {
 	})
 }
 
+// EmitTokenRefreshFailed emits a signal when the token can no longer be refreshed
+// and the user has to authenticate again
+func EmitTokenRefreshFailed(ctx context.Context, err error) {
+	TokenRefreshFailed.Emit(ctx, TokenRefreshFailedData{
+		Err: err,
+	})
+}
+
+// EmitSyncCompleted emits a signal when a schedule sync ends, successfully or not
+func EmitSyncCompleted(ctx context.Context, trigger string, assignmentsCount int, err error) {
+	SyncCompleted.Emit(ctx, SyncCompletedData{
+		Trigger:          trigger,
+		AssignmentsCount: assignmentsCount,
+		Err:              err,
+	})
+}
+
+// EmitSyncProgress emits a signal when a schedule sync made progress or ended
+func EmitSyncProgress(ctx context.Context, data SyncProgressData) {
+	SyncProgress.Emit(ctx, data)
+}
+
+// EmitWebhookProcessed emits a signal when a calendar webhook has been processed, successfully or not
+func EmitWebhookProcessed(ctx context.Context, calendarID string, err error) {
+	WebhookProcessed.Emit(ctx, WebhookProcessedData{
+		CalendarID: calendarID,
+		Err:        err,
+	})
+}
+
+// EmitConfigChanged emits a signal when a section of the runtime configuration was saved
+func EmitConfigChanged(ctx context.Context, section string) {
+	ConfigChanged.Emit(ctx, ConfigChangedData{
+		Section: section,
+	})
+}
+
+// EmitAssignmentsChanged emits a signal when the assignment of date was written, a zero date when it is not known
+func EmitAssignmentsChanged(ctx context.Context, date time.Time) {
+	AssignmentsChanged.Emit(ctx, AssignmentsChangedData{
+		Date: date,
+	})
+}
+
+// EmitAssignmentOverridden emits a signal when an assignment was set by hand to caregiver
+func EmitAssignmentOverridden(ctx context.Context, assignmentID int64, caregiver, caregiverType string) {
+	AssignmentOverridden.Emit(ctx, AssignmentOverriddenData{
+		AssignmentID:  assignmentID,
+		Caregiver:     caregiver,
+		CaregiverType: caregiverType,
+	})
+}
+
+// EmitAssignmentCreated emits a signal when a date got its first assignment
+func EmitAssignmentCreated(ctx context.Context, assignmentID int64, date time.Time, caregiver, caregiverType string) {
+	AssignmentCreated.Emit(ctx, AssignmentCreatedData{
+		AssignmentID:  assignmentID,
+		Date:          date.Format("2006-01-02"),
+		Caregiver:     caregiver,
+		CaregiverType: caregiverType,
+	})
+}
+
+// EmitAssignmentUnlocked emits a signal when the override of an assignment was removed
+func EmitAssignmentUnlocked(ctx context.Context, assignmentID int64) {
+	AssignmentUnlocked.Emit(ctx, AssignmentUnlockedData{
+		AssignmentID: assignmentID,
+	})
+}
+
 // OnTokenSetup registers a handler for token setup events
 func OnTokenSetup(handler func(ctx context.Context, data TokenSetupData), key ...string) {
 	if len(key) > 0 {
@@ -52,3 +207,84 @@ func OnCalendarSelected(handler func(ctx context.Context, data CalendarSelectedD
 		CalendarSelected.AddListener(handler)
 	}
 }
+
+// OnTokenRefreshFailed registers a handler for permanent token refresh failures
+func OnTokenRefreshFailed(handler func(ctx context.Context, data TokenRefreshFailedData), key ...string) {
+	if len(key) > 0 {
+		TokenRefreshFailed.AddListener(handler, key[0])
+	} else {
+		TokenRefreshFailed.AddListener(handler)
+	}
+}
+
+// OnSyncCompleted registers a handler for the end of schedule syncs
+func OnSyncCompleted(handler func(ctx context.Context, data SyncCompletedData), key ...string) {
+	if len(key) > 0 {
+		SyncCompleted.AddListener(handler, key[0])
+	} else {
+		SyncCompleted.AddListener(handler)
+	}
+}
+
+// OnSyncProgress registers a handler for the progress of schedule syncs
+func OnSyncProgress(handler func(ctx context.Context, data SyncProgressData), key ...string) {
+	if len(key) > 0 {
+		SyncProgress.AddListener(handler, key[0])
+	} else {
+		SyncProgress.AddListener(handler)
+	}
+}
+
+// OnWebhookProcessed registers a handler for processed calendar webhooks
+func OnWebhookProcessed(handler func(ctx context.Context, data WebhookProcessedData), key ...string) {
+	if len(key) > 0 {
+		WebhookProcessed.AddListener(handler, key[0])
+	} else {
+		WebhookProcessed.AddListener(handler)
+	}
+}
+
+// OnConfigChanged registers a handler for runtime configuration writes
+func OnConfigChanged(handler func(ctx context.Context, data ConfigChangedData), key ...string) {
+	if len(key) > 0 {
+		ConfigChanged.AddListener(handler, key[0])
+	} else {
+		ConfigChanged.AddListener(handler)
+	}
+}
+
+// OnAssignmentsChanged registers a handler for assignment writes
+func OnAssignmentsChanged(handler func(ctx context.Context, data AssignmentsChangedData), key ...string) {
+	if len(key) > 0 {
+		AssignmentsChanged.AddListener(handler, key[0])
+	} else {
+		AssignmentsChanged.AddListener(handler)
+	}
+}
+
+// OnAssignmentOverridden registers a handler for assignments set by hand
+func OnAssignmentOverridden(handler func(ctx context.Context, data AssignmentOverriddenData), key ...string) {
+	if len(key) > 0 {
+		AssignmentOverridden.AddListener(handler, key[0])
+	} else {
+		AssignmentOverridden.AddListener(handler)
+	}
+}
+
+// OnAssignmentCreated registers a handler for the first assignment of a date
+func OnAssignmentCreated(handler func(ctx context.Context, data AssignmentCreatedData), key ...string) {
+	if len(key) > 0 {
+		AssignmentCreated.AddListener(handler, key[0])
+	} else {
+		AssignmentCreated.AddListener(handler)
+	}
+}
+
+// OnAssignmentUnlocked registers a handler for assignments handed back to the scheduler
+func OnAssignmentUnlocked(handler func(ctx context.Context, data AssignmentUnlockedData), key ...string) {
+	if len(key) > 0 {
+		AssignmentUnlocked.AddListener(handler, key[0])
+	} else {
+		AssignmentUnlocked.AddListener(handler)
+	}
+}
@@ -0,0 +1,85 @@
+package signals
+
+import (
+	"context"
+	"sync"
+)
+
+// maxSyncProgressErrors bounds the errors kept in the progress of a sync, the latest ones
+const maxSyncProgressErrors = 10
+
+// Status of a sync still in progress, see SyncProgressData
+const SyncStatusRunning = "running"
+
+// syncProgressKey is the context key of the progress of the running sync
+type syncProgressKey struct{}
+
+// SyncRunProgress accumulates the progress of a sync run, reported through its context by the
+// calendar sync. Every report emits SyncProgress.
+type SyncRunProgress struct {
+	mu   sync.Mutex // Guards data
+	data SyncProgressData
+}
+
+// WithSyncProgress returns a context carrying a new progress of the sync run runID started by trigger
+func WithSyncProgress(ctx context.Context, runID int64, trigger string) (context.Context, *SyncRunProgress) {
+	progress := &SyncRunProgress{data: SyncProgressData{RunID: runID, Trigger: trigger, Status: SyncStatusRunning}}
+	return context.WithValue(ctx, syncProgressKey{}, progress), progress
+}
+
+// Snapshot returns the progress so far
+func (p *SyncRunProgress) Snapshot() SyncProgressData {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.copyData()
+}
+
+// copyData returns a copy of the progress, the caller holding mu
+func (p *SyncRunProgress) copyData() SyncProgressData {
+	data := p.data
+	data.Errors = append([]string(nil), p.data.Errors...)
+	return data
+}
+
+// Finish records the status the sync ended with and emits the final progress, returned
+func (p *SyncRunProgress) Finish(ctx context.Context, status string) SyncProgressData {
+	return p.update(ctx, func(data *SyncProgressData) { data.Status = status })
+}
+
+// update applies change to the progress and emits the result, outside the lock
+func (p *SyncRunProgress) update(ctx context.Context, change func(data *SyncProgressData)) SyncProgressData {
+	p.mu.Lock()
+	change(&p.data)
+	data := p.copyData()
+	p.mu.Unlock()
+	EmitSyncProgress(ctx, data)
+	return data
+}
+
+// ReportSyncTotal adds n assignments to sync to the progress of the sync run of ctx. Does nothing
+// outside a sync run.
+func ReportSyncTotal(ctx context.Context, n int) {
+	if progress, ok := ctx.Value(syncProgressKey{}).(*SyncRunProgress); ok {
+		progress.update(ctx, func(data *SyncProgressData) { data.Total += n })
+	}
+}
+
+// ReportSyncStep records an assignment processed by the sync run of ctx, failed when err is not nil.
+// Does nothing outside a sync run.
+func ReportSyncStep(ctx context.Context, err error) {
+	progress, ok := ctx.Value(syncProgressKey{}).(*SyncRunProgress)
+	if !ok {
+		return
+	}
+	progress.update(ctx, func(data *SyncProgressData) {
+		data.Processed++
+		if err == nil {
+			return
+		}
+		data.Failed++
+		data.Errors = append(data.Errors, err.Error())
+		if len(data.Errors) > maxSyncProgressErrors {
+			data.Errors = data.Errors[len(data.Errors)-maxSyncProgressErrors:]
+		}
+	})
+}
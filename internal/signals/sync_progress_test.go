@@ -0,0 +1,52 @@
+package signals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncRunProgress(t *testing.T) {
+	var emitted []SyncProgressData
+	OnSyncProgress(func(_ context.Context, data SyncProgressData) {
+		emitted = append(emitted, data)
+	}, "test-sync-progress")
+	defer SyncProgress.RemoveListener("test-sync-progress")
+
+	ctx, progress := WithSyncProgress(context.Background(), 7, "manual")
+	ReportSyncTotal(ctx, 3)
+	ReportSyncStep(ctx, nil)
+	ReportSyncStep(ctx, errors.New("quota exceeded"))
+	ReportSyncStep(ctx, nil)
+	summary := progress.Finish(ctx, "failed")
+
+	assert.Equal(t, SyncProgressData{
+		RunID: 7, Trigger: "manual", Status: "failed",
+		Total: 3, Processed: 3, Failed: 1, Errors: []string{"quota exceeded"},
+	}, summary)
+	assert.Len(t, emitted, 5, "every report is emitted")
+	assert.Equal(t, SyncStatusRunning, emitted[0].Status)
+	assert.Equal(t, 3, emitted[0].Total)
+	assert.Equal(t, summary, emitted[4])
+}
+
+func TestSyncRunProgress_KeepsLatestErrors(t *testing.T) {
+	ctx, progress := WithSyncProgress(context.Background(), 1, "scheduled")
+	for i := range maxSyncProgressErrors + 5 {
+		ReportSyncStep(ctx, fmt.Errorf("error %d", i))
+	}
+
+	summary := progress.Snapshot()
+	assert.Equal(t, maxSyncProgressErrors+5, summary.Failed)
+	assert.Len(t, summary.Errors, maxSyncProgressErrors)
+	assert.Equal(t, "error 5", summary.Errors[0])
+}
+
+func TestSyncRunProgress_OutsideRun(t *testing.T) {
+	// Nothing to report to: the reports are ignored
+	ReportSyncTotal(context.Background(), 3)
+	ReportSyncStep(context.Background(), errors.New("ignored"))
+}
@@ -0,0 +1,214 @@
+// Package telemetry builds the anonymous usage report of the instance and, once opted in, sends it
+// at a fixed interval to help prioritize development. The report only holds aggregates: no name,
+// date, calendar, URL, token or identifier of the instance.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/features"
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// sendTimeout bounds a single report
+const sendTimeout = 10 * time.Second
+
+// firstReportDelay leaves the startup sync time to complete before the first report
+const firstReportDelay = time.Hour
+
+// Report is the payload sent to the telemetry endpoint
+type Report struct {
+	Version       string   `json:"version"`
+	OS            string   `json:"os"`
+	Arch          string   `json:"arch"`
+	DatabaseSize  string   `json:"database_size"`   // Size bucket, e.g. "1-10MB"
+	Features      []string `json:"features"`        // Optional features enabled by the configuration, sorted
+	FeatureFlags  []string `json:"feature_flags"`   // Feature flags enabled, sorted
+	PeriodDays    int      `json:"period_days"`     // Period of the sync counts
+	Syncs         int      `json:"syncs"`           // Syncs finished over the period
+	SyncErrorRate float64  `json:"sync_error_rate"` // Share of these syncs that failed, rounded to two decimals
+}
+
+// SizeSource gives the size of the database in bytes, implemented by database.DB
+type SizeSource interface {
+	Size(ctx context.Context) (int64, error)
+}
+
+// FlagSource lists the feature flags, implemented by features.Flags
+type FlagSource interface {
+	List() ([]features.State, error)
+}
+
+// SyncSource counts the syncs started since a time and the failed ones, implemented by database.SyncRunStore
+type SyncSource interface {
+	CountRuns(since time.Time) (total int, failed int, err error)
+}
+
+// Collector builds the report from the running instance. It is used for the preview even while the
+// telemetry is disabled, so the payload can be checked before opting in.
+type Collector struct {
+	version  string
+	features []string
+	period   time.Duration
+	size     SizeSource
+	flags    FlagSource
+	syncs    SyncSource
+	now      func() time.Time // injectable for testing; defaults to time.Now
+}
+
+// NewCollector creates a collector reporting version and the optional features of the build, with
+// the sync counts of the last period
+func NewCollector(version string, enabledFeatures []string, period time.Duration, size SizeSource, flags FlagSource, syncs SyncSource) *Collector {
+	return &Collector{
+		version:  version,
+		features: enabledFeatures,
+		period:   period,
+		size:     size,
+		flags:    flags,
+		syncs:    syncs,
+		now:      time.Now,
+	}
+}
+
+// Collect builds the report
+func (c *Collector) Collect(ctx context.Context) (Report, error) {
+	size, err := c.size.Size(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	states, err := c.flags.List()
+	if err != nil {
+		return Report{}, err
+	}
+	flags := []string{}
+	for _, state := range states {
+		if state.Enabled {
+			flags = append(flags, string(state.Flag))
+		}
+	}
+	slices.Sort(flags)
+
+	total, failed, err := c.syncs.CountRuns(c.now().Add(-c.period))
+	if err != nil {
+		return Report{}, err
+	}
+	errorRate := 0.0
+	if total > 0 {
+		errorRate = math.Round(float64(failed)/float64(total)*100) / 100
+	}
+
+	enabledFeatures := slices.Clone(c.features)
+	if enabledFeatures == nil {
+		enabledFeatures = []string{}
+	}
+	slices.Sort(enabledFeatures)
+
+	return Report{
+		Version:       c.version,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		DatabaseSize:  sizeBucket(size),
+		Features:      enabledFeatures,
+		FeatureFlags:  flags,
+		PeriodDays:    int(c.period.Hours() / 24),
+		Syncs:         total,
+		SyncErrorRate: errorRate,
+	}, nil
+}
+
+// sizeBucket rounds a database size to a coarse bucket, so that it tells nothing of the family
+func sizeBucket(size int64) string {
+	const mb = 1 << 20
+	switch {
+	case size < mb:
+		return "<1MB"
+	case size < 10*mb:
+		return "1-10MB"
+	case size < 100*mb:
+		return "10-100MB"
+	case size < 1000*mb:
+		return "100MB-1GB"
+	default:
+		return ">1GB"
+	}
+}
+
+// Reporter sends the report of a collector to the telemetry endpoint at a fixed interval
+type Reporter struct {
+	endpoint  string
+	interval  time.Duration
+	collector *Collector
+	client    *http.Client
+	logger    zerolog.Logger
+}
+
+// NewReporter creates a reporter posting the report of collector to endpoint every interval
+func NewReporter(endpoint string, interval time.Duration, collector *Collector) *Reporter {
+	return &Reporter{
+		endpoint:  endpoint,
+		interval:  interval,
+		collector: collector,
+		client:    &http.Client{Timeout: sendTimeout},
+		logger:    logging.GetLogger("telemetry"),
+	}
+}
+
+// Send collects the report and posts it as JSON. Any 2xx answer is a success.
+func (r *Reporter) Send(ctx context.Context) error {
+	report, err := r.collector.Collect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to collect telemetry report: %w", err)
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Run sends the report one hour after the start, then every interval until ctx is cancelled. A
+// failed report is logged and not retried before the next one.
+func (r *Reporter) Run(ctx context.Context) {
+	timer := time.NewTimer(firstReportDelay)
+	defer timer.Stop()
+
+	r.logger.Info().Dur("interval", r.interval).Msg("Anonymous usage telemetry enabled")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if err := r.Send(ctx); err != nil {
+				r.logger.Warn().Err(err).Msg("Failed to send telemetry report")
+			} else {
+				r.logger.Debug().Msg("Telemetry report sent")
+			}
+			timer.Reset(r.interval)
+		}
+	}
+}
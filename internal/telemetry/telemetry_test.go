@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/features"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedSize int64
+
+func (s fixedSize) Size(context.Context) (int64, error) { return int64(s), nil }
+
+type fixedFlags []features.State
+
+func (f fixedFlags) List() ([]features.State, error) { return f, nil }
+
+// fixedSyncs counts the syncs, recording the start of the period asked
+type fixedSyncs struct {
+	total, failed int
+	err           error
+	since         time.Time
+}
+
+func (s *fixedSyncs) CountRuns(since time.Time) (int, int, error) {
+	s.since = since
+	return s.total, s.failed, s.err
+}
+
+func newTestCollector(syncs *fixedSyncs) *Collector {
+	flags := fixedFlags{
+		{Definition: features.Definition{Flag: features.WebhookPolling}, Enabled: true},
+		{Definition: features.Definition{Flag: features.ICSFeed}, Enabled: true, Set: true},
+		{Definition: features.Definition{Flag: features.ImbalanceAlert}, Enabled: false, Set: true},
+	}
+	collector := NewCollector("1.2.3", []string{"hooks", "demo_mode"}, 7*24*time.Hour, fixedSize(12<<20), flags, syncs)
+	collector.now = func() time.Time { return time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC) }
+	return collector
+}
+
+func TestCollector_Collect(t *testing.T) {
+	syncs := &fixedSyncs{total: 30, failed: 2}
+	report, err := newTestCollector(syncs).Collect(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, Report{
+		Version:       "1.2.3",
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		DatabaseSize:  "10-100MB",
+		Features:      []string{"demo_mode", "hooks"},
+		FeatureFlags:  []string{"ics_feed", "webhook_polling"},
+		PeriodDays:    7,
+		Syncs:         30,
+		SyncErrorRate: 0.07,
+	}, report)
+	assert.Equal(t, time.Date(2026, 10, 9, 20, 0, 0, 0, time.UTC), syncs.since)
+
+	report, err = newTestCollector(&fixedSyncs{}).Collect(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.SyncErrorRate, "no sync is no error")
+
+	_, err = newTestCollector(&fixedSyncs{err: errors.New("database is locked")}).Collect(context.Background())
+	require.Error(t, err)
+}
+
+func TestSizeBucket(t *testing.T) {
+	assert.Equal(t, "<1MB", sizeBucket(4096))
+	assert.Equal(t, "1-10MB", sizeBucket(1<<20))
+	assert.Equal(t, "100MB-1GB", sizeBucket(500<<20))
+	assert.Equal(t, ">1GB", sizeBucket(2<<30))
+}
+
+func TestReporter_Send(t *testing.T) {
+	var received map[string]any
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	reporter := NewReporter(server.URL+"/v1/report", time.Hour, newTestCollector(&fixedSyncs{total: 4, failed: 1}))
+	require.NoError(t, reporter.Send(context.Background()))
+	assert.Equal(t, "1.2.3", received["version"])
+	assert.Equal(t, 0.25, received["sync_error_rate"])
+	assert.Len(t, received, 9, "nothing but the aggregates is sent")
+
+	status = http.StatusInternalServerError
+	err := reporter.Send(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "telemetry endpoint answered 500")
+}
@@ -0,0 +1,72 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/belphemur/night-routine/internal/logging"
+	"github.com/rs/zerolog"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+// Keyring entry identifying the stored OAuth token
+const (
+	keyringService = "night-routine"
+	keyringUser    = "google-oauth-token"
+)
+
+// KeyringStorage stores the OAuth token in the operating system keyring
+// (Secret Service on Linux, Keychain on macOS, Credential Manager on Windows)
+type KeyringStorage struct {
+	logger zerolog.Logger
+}
+
+var _ Storage = (*KeyringStorage)(nil)
+
+// NewKeyringStorage creates a keyring-backed token storage
+func NewKeyringStorage() *KeyringStorage {
+	return &KeyringStorage{logger: logging.GetLogger("token-keyring")}
+}
+
+// GetToken retrieves the token from the keyring, returning nil when none is stored
+func (k *KeyringStorage) GetToken() (*oauth2.Token, error) {
+	k.logger.Debug().Msg("Retrieving OAuth token from keyring")
+	secret, err := keyring.Get(keyringService, keyringUser)
+	if errors.Is(err, keyring.ErrNotFound) {
+		k.logger.Debug().Msg("No OAuth token found in keyring")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token from keyring: %w", err)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal([]byte(secret), &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	return &token, nil
+}
+
+// SaveToken stores the token in the keyring, replacing any previous one
+func (k *KeyringStorage) SaveToken(token *oauth2.Token) error {
+	k.logger.Debug().Msg("Saving OAuth token to keyring")
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal token: %w", err)
+	}
+	if err := keyring.Set(keyringService, keyringUser, string(tokenJSON)); err != nil {
+		return fmt.Errorf("failed to write token to keyring: %w", err)
+	}
+	return nil
+}
+
+// ClearToken removes the token from the keyring. Clearing an absent token is not an error.
+func (k *KeyringStorage) ClearToken() error {
+	k.logger.Debug().Msg("Clearing OAuth token from keyring")
+	if err := keyring.Delete(keyringService, keyringUser); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete token from keyring: %w", err)
+	}
+	return nil
+}
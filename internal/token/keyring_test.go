@@ -0,0 +1,60 @@
+package token
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+func TestKeyringStorage(t *testing.T) {
+	keyring.MockInit()
+	storage := NewKeyringStorage()
+
+	token, err := storage.GetToken()
+	require.NoError(t, err)
+	assert.Nil(t, token, "empty keyring should return no token")
+
+	require.NoError(t, storage.SaveToken(&oauth2.Token{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}))
+
+	token, err = storage.GetToken()
+	require.NoError(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, "access", token.AccessToken)
+	assert.Equal(t, "refresh", token.RefreshToken)
+
+	require.NoError(t, storage.ClearToken())
+	token, err = storage.GetToken()
+	require.NoError(t, err)
+	assert.Nil(t, token)
+
+	assert.NoError(t, storage.ClearToken(), "clearing an absent token should succeed")
+}
+
+func TestKeyringStorage_Unavailable(t *testing.T) {
+	keyring.MockInitWithError(errors.New("secret service unavailable"))
+	t.Cleanup(keyring.MockInit)
+	storage := NewKeyringStorage()
+
+	_, err := storage.GetToken()
+	assert.Error(t, err)
+	assert.Error(t, storage.SaveToken(&oauth2.Token{AccessToken: "access"}))
+}
+
+func TestTokenManager_WithKeyringStorage(t *testing.T) {
+	keyring.MockInit()
+	tm := NewTokenManager(NewKeyringStorage(), &oauth2.Config{})
+
+	hasToken, err := tm.HasToken()
+	require.NoError(t, err)
+	assert.False(t, hasToken)
+
+	require.NoError(t, tm.SaveToken(t.Context(), &oauth2.Token{AccessToken: "access"}))
+
+	hasToken, err = tm.HasToken()
+	require.NoError(t, err)
+	assert.True(t, hasToken)
+}
@@ -2,24 +2,57 @@ package token
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 
 	"github.com/belphemur/night-routine/internal/database"
+	"github.com/belphemur/night-routine/internal/logging"
 	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/rs/zerolog"
 	"golang.org/x/oauth2"
 )
 
+// googleRevokeURL is Google's OAuth2 token revocation endpoint
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// ErrReauthenticationRequired is returned when the stored token can no longer be refreshed
+// and the user has to connect Google Calendar again
+var ErrReauthenticationRequired = errors.New("google authorization expired or was revoked, re-authentication required")
+
+// Storage persists the OAuth token. The SQLite-backed database.TokenStore is the default;
+// KeyringStorage keeps the token in the operating system keyring instead.
+type Storage interface {
+	GetToken() (*oauth2.Token, error)
+	SaveToken(token *oauth2.Token) error
+	ClearToken() error
+}
+
+var _ Storage = (*database.TokenStore)(nil)
+
 // TokenManager handles OAuth token storage and refreshing
 type TokenManager struct {
-	tokenStore  *database.TokenStore
+	tokenStore  Storage
 	oauthConfig *oauth2.Config
+	revokeURL   string
+	logger      zerolog.Logger
+
+	// refreshMu serializes refreshes between the background job and on-demand callers
+	refreshMu sync.Mutex
+	// refreshFailed is set once a refresh failed permanently, until a new token is saved
+	refreshFailed bool
 }
 
 // NewTokenManager creates a new TokenManager
-func NewTokenManager(tokenStore *database.TokenStore, oauthConfig *oauth2.Config) *TokenManager {
+func NewTokenManager(tokenStore Storage, oauthConfig *oauth2.Config) *TokenManager {
 	return &TokenManager{
 		tokenStore:  tokenStore,
 		oauthConfig: oauthConfig,
+		revokeURL:   googleRevokeURL,
+		logger:      logging.GetLogger("token-manager"),
 	}
 }
 
@@ -32,7 +65,22 @@ func (tm *TokenManager) HasToken() (bool, error) {
 	return token != nil, nil
 }
 
-// GetValidToken retrieves a valid token, refreshing it if necessary
+// GetToken returns the stored token without validating or refreshing it, or nil when there is none
+func (tm *TokenManager) GetToken() (*oauth2.Token, error) {
+	token, err := tm.tokenStore.GetToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve token: %w", err)
+	}
+	return token, nil
+}
+
+// NeedsReauthentication reports whether the stored token was permanently rejected by Google
+func (tm *TokenManager) NeedsReauthentication() bool {
+	return tm.hasRefreshFailed()
+}
+
+// GetValidToken retrieves a valid token. Refreshing is normally handled ahead of expiry by
+// the background job; an expired token is still refreshed here as a fallback.
 func (tm *TokenManager) GetValidToken(ctx context.Context) (*oauth2.Token, error) {
 	token, err := tm.tokenStore.GetToken()
 	if err != nil {
@@ -44,16 +92,11 @@ func (tm *TokenManager) GetValidToken(ctx context.Context) (*oauth2.Token, error
 	}
 
 	if !token.Valid() {
-		newToken, err := tm.oauthConfig.TokenSource(ctx, token).Token()
-		if err != nil {
-			return nil, fmt.Errorf("failed to refresh token: %w", err)
+		// Retrying a refresh Google already rejected would only fail again
+		if tm.hasRefreshFailed() {
+			return nil, ErrReauthenticationRequired
 		}
-
-		if err := tm.tokenStore.SaveToken(newToken); err != nil {
-			return nil, fmt.Errorf("failed to save refreshed token: %w", err)
-		}
-
-		token = newToken
+		return tm.refreshToken(ctx, token)
 	}
 
 	return token, nil
@@ -64,6 +107,7 @@ func (tm *TokenManager) SaveToken(ctx context.Context, token *oauth2.Token) erro
 	if err := tm.tokenStore.SaveToken(token); err != nil {
 		return fmt.Errorf("failed to save token: %w", err)
 	}
+	tm.resetRefreshFailure()
 
 	// Emit token setup signal with the updated context
 	signals.EmitTokenSetup(ctx, true)
@@ -71,11 +115,54 @@ func (tm *TokenManager) SaveToken(ctx context.Context, token *oauth2.Token) erro
 	return nil
 }
 
+// RevokeToken revokes the stored token at Google without removing it from the store.
+// The refresh token is revoked when present, which also invalidates its access tokens.
+// It is a no-op when no token is stored.
+func (tm *TokenManager) RevokeToken(ctx context.Context) error {
+	token, err := tm.tokenStore.GetToken()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve token: %w", err)
+	}
+	if token == nil {
+		return nil
+	}
+
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+	if value == "" {
+		return nil
+	}
+
+	form := url.Values{"token": {value}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tm.revokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create revoke request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Google answers 400 invalid_token when the token was already revoked or expired,
+	// which leaves us in the desired state anyway.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("failed to revoke token: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
 // ClearToken removes the token from the store and emits a signal
 func (tm *TokenManager) ClearToken(ctx context.Context) error {
 	if err := tm.tokenStore.ClearToken(); err != nil {
 		return fmt.Errorf("failed to clear token: %w", err)
 	}
+	tm.resetRefreshFailure()
 
 	// Emit token setup signal with false to indicate token was cleared
 	signals.EmitTokenSetup(ctx, false)
@@ -0,0 +1,107 @@
+package token
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/belphemur/night-routine/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func setupTestTokenManager(t *testing.T) (*TokenManager, *database.TokenStore) {
+	db, err := database.New(database.SQLiteOptions{
+		Path:        ":memory:",
+		Mode:        "rwc",
+		Cache:       database.CacheShared,
+		Journal:     database.JournalWAL,
+		ForeignKeys: true,
+		BusyTimeout: 5000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.MigrateDatabase())
+	t.Cleanup(func() { db.Close() })
+
+	tokenStore, err := database.NewTokenStore(db)
+	require.NoError(t, err)
+
+	return NewTokenManager(tokenStore, &oauth2.Config{}), tokenStore
+}
+
+func TestTokenManager_RevokeToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         *oauth2.Token
+		status        int
+		expectedValue string
+		expectError   bool
+	}{
+		{
+			name:          "revokes refresh token when present",
+			token:         &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"},
+			status:        http.StatusOK,
+			expectedValue: "refresh",
+		},
+		{
+			name:          "falls back to access token",
+			token:         &oauth2.Token{AccessToken: "access"},
+			status:        http.StatusOK,
+			expectedValue: "access",
+		},
+		{
+			name:          "already revoked token is accepted",
+			token:         &oauth2.Token{RefreshToken: "refresh"},
+			status:        http.StatusBadRequest,
+			expectedValue: "refresh",
+		},
+		{
+			name:          "server error is reported",
+			token:         &oauth2.Token{RefreshToken: "refresh"},
+			status:        http.StatusInternalServerError,
+			expectedValue: "refresh",
+			expectError:   true,
+		},
+		{
+			name:  "no token is a no-op",
+			token: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				assert.Equal(t, http.MethodPost, r.Method)
+				require.NoError(t, r.ParseForm())
+				received = r.PostForm.Get("token")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			tm, tokenStore := setupTestTokenManager(t)
+			tm.revokeURL = server.URL
+			if tt.token != nil {
+				require.NoError(t, tokenStore.SaveToken(tt.token))
+			}
+
+			err := tm.RevokeToken(context.Background())
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.token == nil {
+				assert.Equal(t, 0, calls)
+				return
+			}
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, tt.expectedValue, received)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/googleclient"
+	"github.com/belphemur/night-routine/internal/signals"
+	"golang.org/x/oauth2"
+)
+
+// refreshCheckInterval bounds how long the background job sleeps between checks,
+// so newly saved tokens and transient failures are picked up quickly
+const refreshCheckInterval = time.Minute
+
+// StartBackgroundRefresh launches a goroutine that refreshes the stored token margin
+// before it expires. It returns immediately; the goroutine stops when ctx is cancelled.
+func (tm *TokenManager) StartBackgroundRefresh(ctx context.Context, margin time.Duration) {
+	tm.logger.Info().Dur("margin", margin).Msg("Starting background token refresh")
+	go func() {
+		for {
+			wait := tm.refreshIfDue(ctx, margin)
+			select {
+			case <-ctx.Done():
+				tm.logger.Info().Msg("Background token refresh stopped")
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// refreshIfDue refreshes the stored token when it expires within margin and
+// returns how long to wait before the next check
+func (tm *TokenManager) refreshIfDue(ctx context.Context, margin time.Duration) time.Duration {
+	token, err := tm.tokenStore.GetToken()
+	if err != nil {
+		tm.logger.Warn().Err(err).Msg("Failed to read token for background refresh")
+		return refreshCheckInterval
+	}
+	if token == nil || token.RefreshToken == "" || token.Expiry.IsZero() {
+		return refreshCheckInterval
+	}
+	if tm.hasRefreshFailed() {
+		// Nothing to do until the user authenticates again
+		return refreshCheckInterval
+	}
+
+	if wait := time.Until(token.Expiry.Add(-margin)); wait > 0 {
+		return min(wait, refreshCheckInterval)
+	}
+
+	tm.logger.Debug().Time("expiry", token.Expiry).Msg("Token is about to expire, refreshing")
+	if _, err := tm.refreshToken(ctx, token); err != nil {
+		tm.logger.Warn().Err(err).Msg("Background token refresh failed")
+	}
+	return refreshCheckInterval
+}
+
+// refreshToken exchanges the refresh token for a new access token and stores it.
+// A permanent failure emits the TokenRefreshFailed signal once per stored token.
+func (tm *TokenManager) refreshToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+	newToken, err := tm.exchangeRefreshToken(ctx, token)
+	if err != nil {
+		if isPermanentRefreshError(err) && tm.markRefreshFailed() {
+			tm.logger.Error().Err(err).Msg("Token refresh failed permanently, re-authentication required")
+			// Emitted outside refreshMu since listeners may ask for a token themselves
+			signals.EmitTokenRefreshFailed(ctx, err)
+		}
+		return nil, err
+	}
+	return newToken, nil
+}
+
+// exchangeRefreshToken performs the refresh under refreshMu so the background job and
+// on-demand callers never refresh concurrently
+func (tm *TokenManager) exchangeRefreshToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+	tm.refreshMu.Lock()
+	defer tm.refreshMu.Unlock()
+
+	// Dropping the access token forces the token source to use the refresh token
+	newToken, err := tm.oauthConfig.TokenSource(googleclient.Context(ctx), &oauth2.Token{RefreshToken: token.RefreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	if err := tm.tokenStore.SaveToken(newToken); err != nil {
+		return nil, fmt.Errorf("failed to save refreshed token: %w", err)
+	}
+	tm.logger.Debug().Time("expiry", newToken.Expiry).Msg("Token refreshed")
+
+	return newToken, nil
+}
+
+// markRefreshFailed records a permanent failure and reports whether it is a new one
+func (tm *TokenManager) markRefreshFailed() bool {
+	tm.refreshMu.Lock()
+	defer tm.refreshMu.Unlock()
+	if tm.refreshFailed {
+		return false
+	}
+	tm.refreshFailed = true
+	return true
+}
+
+// hasRefreshFailed reports whether the stored token can no longer be refreshed
+func (tm *TokenManager) hasRefreshFailed() bool {
+	tm.refreshMu.Lock()
+	defer tm.refreshMu.Unlock()
+	return tm.refreshFailed
+}
+
+// resetRefreshFailure clears the permanent failure state after the token changed
+func (tm *TokenManager) resetRefreshFailure() {
+	tm.refreshMu.Lock()
+	defer tm.refreshMu.Unlock()
+	tm.refreshFailed = false
+}
+
+// isPermanentRefreshError reports whether a refresh error will not resolve by retrying,
+// such as an invalid_grant after the user revoked access. Rate limiting is retryable.
+func isPermanentRefreshError(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) || retrieveErr.Response == nil {
+		return false
+	}
+	code := retrieveErr.Response.StatusCode
+	return code >= http.StatusBadRequest && code < http.StatusInternalServerError && code != http.StatusTooManyRequests
+}
@@ -0,0 +1,129 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/signals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// newTokenServer returns a token endpoint answering with the given status and counting requests
+func newTokenServer(t *testing.T, status int, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			fmt.Fprint(w, `{"access_token":"new-access","token_type":"Bearer","expires_in":3600}`)
+			return
+		}
+		fmt.Fprint(w, `{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTokenManager_RefreshIfDue(t *testing.T) {
+	margin := 10 * time.Minute
+
+	tests := []struct {
+		name          string
+		expiry        time.Time
+		expectRefresh bool
+	}{
+		{name: "token outside margin is kept", expiry: time.Now().Add(time.Hour), expectRefresh: false},
+		{name: "token inside margin is refreshed", expiry: time.Now().Add(5 * time.Minute), expectRefresh: true},
+		{name: "expired token is refreshed", expiry: time.Now().Add(-time.Minute), expectRefresh: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := newTokenServer(t, http.StatusOK, &calls)
+
+			tm, tokenStore := setupTestTokenManager(t)
+			tm.oauthConfig.Endpoint = oauth2.Endpoint{TokenURL: server.URL, AuthStyle: oauth2.AuthStyleInParams}
+			require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "old-access", RefreshToken: "refresh", Expiry: tt.expiry}))
+
+			wait := tm.refreshIfDue(context.Background(), margin)
+			assert.LessOrEqual(t, wait, refreshCheckInterval)
+
+			stored, err := tokenStore.GetToken()
+			require.NoError(t, err)
+			if tt.expectRefresh {
+				assert.Equal(t, int32(1), calls.Load())
+				assert.Equal(t, "new-access", stored.AccessToken)
+				assert.Equal(t, "refresh", stored.RefreshToken, "refresh token must be kept when Google omits it")
+			} else {
+				assert.Equal(t, int32(0), calls.Load())
+				assert.Equal(t, "old-access", stored.AccessToken)
+			}
+		})
+	}
+}
+
+func TestTokenManager_RefreshPermanentFailure(t *testing.T) {
+	var calls atomic.Int32
+	server := newTokenServer(t, http.StatusBadRequest, &calls)
+
+	tm, tokenStore := setupTestTokenManager(t)
+	tm.oauthConfig.Endpoint = oauth2.Endpoint{TokenURL: server.URL, AuthStyle: oauth2.AuthStyleInParams}
+	require.NoError(t, tokenStore.SaveToken(&oauth2.Token{AccessToken: "old-access", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)}))
+
+	var emitted atomic.Int32
+	signals.TokenRefreshFailed.AddListener(func(ctx context.Context, data signals.TokenRefreshFailedData) {
+		assert.Error(t, data.Err)
+		emitted.Add(1)
+	}, "test-refresh-failed")
+	t.Cleanup(func() { signals.TokenRefreshFailed.RemoveListener("test-refresh-failed") })
+
+	// On-demand refresh reports the failure and flags the token
+	_, err := tm.GetValidToken(context.Background())
+	require.Error(t, err)
+	assert.True(t, tm.hasRefreshFailed())
+
+	// The background job stops trying until a new token is saved
+	tm.refreshIfDue(context.Background(), 10*time.Minute)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Further on-demand calls fail fast without contacting Google or emitting again
+	_, err = tm.GetValidToken(context.Background())
+	assert.ErrorIs(t, err, ErrReauthenticationRequired)
+	assert.True(t, tm.NeedsReauthentication())
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, int32(1), emitted.Load())
+
+	require.NoError(t, tm.SaveToken(context.Background(), &oauth2.Token{AccessToken: "fresh", RefreshToken: "refresh-2", Expiry: time.Now().Add(time.Hour)}))
+	assert.False(t, tm.hasRefreshFailed())
+}
+
+func TestIsPermanentRefreshError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "invalid grant", err: &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusBadRequest}, ErrorCode: "invalid_grant"}, expected: true},
+		{name: "unauthorized client", err: &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusUnauthorized}}, expected: true},
+		{name: "rate limited", err: &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusTooManyRequests}}, expected: false},
+		{name: "server error", err: &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}, expected: false},
+		{name: "wrapped invalid grant", err: fmt.Errorf("failed: %w", &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusBadRequest}}), expected: true},
+		{name: "network error", err: errors.New("connection refused"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isPermanentRefreshError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package token
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Parameters of the passphrase-based encryption of transfer bundles
+const (
+	transferFormatVersion = 1
+	transferKDF           = "pbkdf2-sha256"
+	transferIterations    = 600_000
+	// A bundle asking for fewer or more iterations is corrupted or crafted: too few would make the
+	// key cheap to guess, too many would hang the import
+	transferMinIterations = 100_000
+	transferMaxIterations = 10_000_000
+	transferSaltSize      = 16
+	transferKeySize       = 32
+)
+
+// ErrInvalidPassphrase is returned when a transfer bundle cannot be decrypted
+var ErrInvalidPassphrase = errors.New("invalid passphrase or corrupted token bundle")
+
+// TransferBundle is what moves between hosts: the OAuth token and the calendar selection
+type TransferBundle struct {
+	Token        *oauth2.Token `json:"token"`
+	CalendarID   string        `json:"calendar_id,omitempty"`
+	CalendarName string        `json:"calendar_name,omitempty"`
+	ExportedAt   time.Time     `json:"exported_at"`
+}
+
+// sealedBundle is the on-disk, encrypted form of a TransferBundle
+type sealedBundle struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// SealTransferBundle encrypts the bundle with a key derived from the passphrase (AES-256-GCM)
+func SealTransferBundle(bundle *TransferBundle, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase must not be empty")
+	}
+
+	plaintext, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal token bundle: %w", err)
+	}
+
+	sealed := sealedBundle{
+		Version:    transferFormatVersion,
+		KDF:        transferKDF,
+		Iterations: transferIterations,
+		Salt:       make([]byte, transferSaltSize),
+	}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := newTransferAEAD(passphrase, sealed.Salt, sealed.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed.Ciphertext = aead.Seal(nil, sealed.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(sealed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sealed bundle: %w", err)
+	}
+	return data, nil
+}
+
+// OpenTransferBundle decrypts a bundle produced by SealTransferBundle
+func OpenTransferBundle(data []byte, passphrase string) (*TransferBundle, error) {
+	var sealed sealedBundle
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, fmt.Errorf("failed to parse token bundle: %w", err)
+	}
+	if sealed.Version != transferFormatVersion || sealed.KDF != transferKDF {
+		return nil, fmt.Errorf("unsupported token bundle format: version %d, kdf %q", sealed.Version, sealed.KDF)
+	}
+	if sealed.Iterations < transferMinIterations || sealed.Iterations > transferMaxIterations {
+		return nil, fmt.Errorf("invalid token bundle: %d key derivation iterations, expected between %d and %d", sealed.Iterations, transferMinIterations, transferMaxIterations)
+	}
+
+	aead, err := newTransferAEAD(passphrase, sealed.Salt, sealed.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, ErrInvalidPassphrase
+	}
+	plaintext, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	var bundle TransferBundle
+	if err := json.Unmarshal(plaintext, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token bundle: %w", err)
+	}
+	if bundle.Token == nil {
+		return nil, errors.New("token bundle contains no token")
+	}
+	return &bundle, nil
+}
+
+// newTransferAEAD derives the bundle key from the passphrase
+func newTransferAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, transferKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
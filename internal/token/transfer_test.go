@@ -0,0 +1,76 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestTransferBundle_RoundTrip(t *testing.T) {
+	bundle := &TransferBundle{
+		Token:        &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", Expiry: time.Now().Add(time.Hour).UTC().Truncate(time.Second)},
+		CalendarID:   "family@group.calendar.google.com",
+		CalendarName: "Family",
+		ExportedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+
+	data, err := SealTransferBundle(bundle, "correct horse")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "refresh", "the token must not be stored in clear")
+
+	opened, err := OpenTransferBundle(data, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, bundle.Token.RefreshToken, opened.Token.RefreshToken)
+	assert.True(t, bundle.Token.Expiry.Equal(opened.Token.Expiry))
+	assert.Equal(t, bundle.CalendarID, opened.CalendarID)
+	assert.Equal(t, bundle.CalendarName, opened.CalendarName)
+}
+
+func TestTransferBundle_Errors(t *testing.T) {
+	data, err := SealTransferBundle(&TransferBundle{Token: &oauth2.Token{RefreshToken: "refresh"}}, "secret")
+	require.NoError(t, err)
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := OpenTransferBundle(data, "other")
+		assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		var sealed sealedBundle
+		require.NoError(t, json.Unmarshal(data, &sealed))
+		sealed.Ciphertext[0] ^= 0xff
+		tampered, err := json.Marshal(sealed)
+		require.NoError(t, err)
+
+		_, err = OpenTransferBundle(tampered, "secret")
+		assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := OpenTransferBundle([]byte(`{"version":99,"kdf":"pbkdf2-sha256"}`), "secret")
+		assert.ErrorContains(t, err, "unsupported")
+	})
+
+	for _, iterations := range []int{0, 1_000, 1_000_000_000} {
+		t.Run(fmt.Sprintf("%d iterations", iterations), func(t *testing.T) {
+			var sealed sealedBundle
+			require.NoError(t, json.Unmarshal(data, &sealed))
+			sealed.Iterations = iterations
+			crafted, err := json.Marshal(sealed)
+			require.NoError(t, err)
+
+			_, err = OpenTransferBundle(crafted, "secret")
+			assert.ErrorContains(t, err, "iterations")
+		})
+	}
+
+	t.Run("empty passphrase", func(t *testing.T) {
+		_, err := SealTransferBundle(&TransferBundle{Token: &oauth2.Token{}}, "")
+		assert.Error(t, err)
+	})
+}
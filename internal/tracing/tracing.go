@@ -0,0 +1,93 @@
+// Package tracing sets up OpenTelemetry tracing and exports spans to an OTLP/HTTP collector.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/belphemur/night-routine/internal/config"
+	"github.com/belphemur/night-routine/internal/logging"
+)
+
+// instrumentationPrefix prefixes the name of the tracers handed out by Tracer
+const instrumentationPrefix = "github.com/belphemur/night-routine/"
+
+// tracesPath is the OTLP/HTTP path used when the endpoint has none
+const tracesPath = "/v1/traces"
+
+// Setup installs the global tracer provider exporting to the configured OTLP endpoint.
+// The returned function flushes pending spans and must be called on shutdown.
+// When tracing is disabled, the global no-op provider is kept and shutdown does nothing.
+func Setup(ctx context.Context, cfg config.TracingConfig, version string) (func(context.Context) error, error) {
+	logger := logging.GetLogger("tracing")
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	endpoint, err := exporterURL(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn().Err(err).Msg("OpenTelemetry error")
+	}))
+
+	logger.Info().Str("endpoint", endpoint).Float64("sample_ratio", cfg.SampleRatio).Msg("Tracing enabled")
+	return provider.Shutdown, nil
+}
+
+// exporterURL returns the URL spans are posted to, defaulting the path to /v1/traces
+func exporterURL(endpoint string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid tracing endpoint '%s': %w", endpoint, err)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = tracesPath
+	}
+	return parsed.String(), nil
+}
+
+// Tracer returns the tracer of a package given its path in the module, e.g. Tracer("internal/calendar")
+func Tracer(pkg string) trace.Tracer {
+	return otel.Tracer(instrumentationPrefix + pkg)
+}
+
+// RecordError marks the span as failed when err is not nil and returns err unchanged
+func RecordError(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/belphemur/night-routine/internal/config"
+)
+
+func TestExporterURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		expected string
+	}{
+		{name: "No path", endpoint: "http://collector:4318", expected: "http://collector:4318/v1/traces"},
+		{name: "Root path", endpoint: "http://collector:4318/", expected: "http://collector:4318/v1/traces"},
+		{name: "Explicit path", endpoint: "https://otel.example.com/custom/traces", expected: "https://otel.example.com/custom/traces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exporterURL(tt.endpoint)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Enabled: false}, "test")
+	require.NoError(t, err)
+	require.NotNil(t, shutdown)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup_Enabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{
+		Enabled:     true,
+		Endpoint:    "http://127.0.0.1:1",
+		ServiceName: "night-routine-test",
+		SampleRatio: 1,
+	}, "test")
+	require.NoError(t, err)
+	require.NotNil(t, shutdown)
+	// Nothing was recorded, so shutting down does not reach the unreachable collector
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestRecordError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, okSpan := provider.Tracer("test").Start(context.Background(), "ok")
+	assert.NoError(t, RecordError(okSpan, nil))
+	okSpan.End()
+
+	_, failedSpan := provider.Tracer("test").Start(context.Background(), "failed")
+	syncErr := errors.New("sync failed")
+	assert.Equal(t, syncErr, RecordError(failedSpan, syncErr))
+	failedSpan.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "sync failed", spans[1].Status().Description)
+}
@@ -0,0 +1,218 @@
+// Package validation checks the settings that the configuration file, the database and the web
+// interface share, so that all of them accept the same values
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+)
+
+// Bounds of the numeric settings
+const (
+	MinLookAheadDays          = 1
+	MaxLookAheadDays          = 365
+	MaxPastEventThresholdDays = 30
+	// MaxMinRestDays is the most nights off a parent can be given after a block of consecutive nights
+	MaxMinRestDays = 7
+	// MaxParents is the most parents taking turns, parent A and parent B included
+	MaxParents = 6
+)
+
+// Bounds of the weight of a night in the fairness totals, set in steps of DayWeightStep
+const (
+	MinDayWeight  = 0.5
+	MaxDayWeight  = 5.0
+	DayWeightStep = 0.5
+)
+
+// UpdateFrequencies are the valid values of the schedule update frequency
+var UpdateFrequencies = []string{"daily", "weekly", "monthly", "disabled"}
+
+// Errors returned by the checks, wrapped with the rejected value
+var (
+	ErrMissingParents                = errors.New("both parent names are required")
+	ErrSameParents                   = errors.New("parent names must be different")
+	ErrTooManyParents                = errors.New("too many parents")
+	ErrInvalidDayOfWeek              = errors.New("invalid day of week")
+	ErrInvalidUpdateFrequency        = errors.New("invalid update frequency")
+	ErrInvalidLookAheadDays          = errors.New("invalid look ahead days")
+	ErrInvalidPastEventThresholdDays = errors.New("invalid past event threshold days")
+	ErrInvalidStatsOrder             = errors.New("invalid stats order")
+	ErrInvalidMinRestDays            = errors.New("invalid minimum rest days")
+	ErrInvalidDayWeight              = errors.New("invalid day weight")
+)
+
+// Parents checks that both parents are named, with different names
+func Parents(parentA, parentB string) error {
+	if parentA == "" || parentB == "" {
+		return ErrMissingParents
+	}
+	if parentA == parentB {
+		return fmt.Errorf("%w: %s", ErrSameParents, parentA)
+	}
+	return nil
+}
+
+// Roster checks that the parents taking turns, parent A and parent B first, are between 2 and
+// MaxParents, all named, with different names
+func Roster(names []string) error {
+	if len(names) < 2 {
+		return ErrMissingParents
+	}
+	if len(names) > MaxParents {
+		return fmt.Errorf("%w: %d, at most %d", ErrTooManyParents, len(names), MaxParents)
+	}
+	for i, name := range names {
+		if name == "" {
+			return ErrMissingParents
+		}
+		if slices.Contains(names[:i], name) {
+			return fmt.Errorf("%w: %s", ErrSameParents, name)
+		}
+	}
+	return nil
+}
+
+// DaysOfWeek checks that every day is an English day name, e.g. "Monday"
+func DaysOfWeek(days []string) error {
+	for _, day := range days {
+		if !constants.IsValidDayOfWeek(day) {
+			return fmt.Errorf("%w: %s", ErrInvalidDayOfWeek, day)
+		}
+	}
+	return nil
+}
+
+// UpdateFrequency checks that the frequency is one of UpdateFrequencies
+func UpdateFrequency(frequency string) error {
+	if !slices.Contains(UpdateFrequencies, frequency) {
+		return fmt.Errorf("%w: %s", ErrInvalidUpdateFrequency, frequency)
+	}
+	return nil
+}
+
+// LookAheadDays checks that the days scheduled ahead are between MinLookAheadDays and MaxLookAheadDays
+func LookAheadDays(days int) error {
+	if days < MinLookAheadDays || days > MaxLookAheadDays {
+		return fmt.Errorf("%w: %d is not between %d and %d", ErrInvalidLookAheadDays, days, MinLookAheadDays, MaxLookAheadDays)
+	}
+	return nil
+}
+
+// TriggerLookAheadDays checks the days scheduled ahead by the syncs of a trigger: 0 to use the look
+// ahead days of the schedule, or between MinLookAheadDays and MaxLookAheadDays
+func TriggerLookAheadDays(days int) error {
+	if days == 0 {
+		return nil
+	}
+	if days < MinLookAheadDays || days > MaxLookAheadDays {
+		return fmt.Errorf("%w: %d is neither 0 nor between %d and %d", ErrInvalidLookAheadDays, days, MinLookAheadDays, MaxLookAheadDays)
+	}
+	return nil
+}
+
+// PastEventThresholdDays checks that the days in the past whose events can still be changed are
+// between 0 and MaxPastEventThresholdDays
+func PastEventThresholdDays(days int) error {
+	if days < 0 || days > MaxPastEventThresholdDays {
+		return fmt.Errorf("%w: %d is not between 0 and %d", ErrInvalidPastEventThresholdDays, days, MaxPastEventThresholdDays)
+	}
+	return nil
+}
+
+// StatsOrder checks that the order of the statistics is desc or asc
+func StatsOrder(order constants.StatsOrder) error {
+	if !order.IsValid() {
+		return fmt.Errorf("%w: %s (must be 'desc' or 'asc')", ErrInvalidStatsOrder, order)
+	}
+	return nil
+}
+
+// MinRestDays checks that the nights off after a block of consecutive nights are between 0 and
+// MaxMinRestDays
+func MinRestDays(days int) error {
+	if days < 0 || days > MaxMinRestDays {
+		return fmt.Errorf("%w: %d is not between 0 and %d", ErrInvalidMinRestDays, days, MaxMinRestDays)
+	}
+	return nil
+}
+
+// DayWeights checks that the weight of each day of the week is between MinDayWeight and
+// MaxDayWeight, in steps of DayWeightStep, so the weighted totals add up exactly
+func DayWeights(weights map[time.Weekday]float64) error {
+	for day, weight := range weights {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("%w: unknown day %d", ErrInvalidDayWeight, day)
+		}
+		if weight < MinDayWeight || weight > MaxDayWeight || math.Mod(weight, DayWeightStep) != 0 {
+			return fmt.Errorf("%w: %g for %s is not between %g and %g in steps of %g", ErrInvalidDayWeight, weight, day, MinDayWeight, MaxDayWeight, DayWeightStep)
+		}
+	}
+	return nil
+}
+
+// Schedule checks the settings of the schedule
+func Schedule(updateFrequency string, lookAheadDays, pastEventThresholdDays int, statsOrder constants.StatsOrder) error {
+	if err := UpdateFrequency(updateFrequency); err != nil {
+		return err
+	}
+	if err := LookAheadDays(lookAheadDays); err != nil {
+		return err
+	}
+	if err := PastEventThresholdDays(pastEventThresholdDays); err != nil {
+		return err
+	}
+	return StatsOrder(statsOrder)
+}
+
+// Settings are the settings edited together by the settings page and the settings API
+type Settings struct {
+	ParentA                string
+	ParentB                string
+	ExtraParents           []string // Names of the parents taking turns after parent A and parent B
+	ParentAUnavailable     []string
+	ParentBUnavailable     []string
+	UpdateFrequency        string
+	LookAheadDays          int
+	PastEventThresholdDays int
+	StatsOrder             constants.StatsOrder
+	MinRestDays            int
+	DayWeights             map[time.Weekday]float64 // Weight of the nights in the fairness totals, 1 for the days left out
+	// Days scheduled ahead by the periodic, webhook and manual syncs, 0 for LookAheadDays
+	ScheduledLookAheadDays int
+	WebhookLookAheadDays   int
+	ManualLookAheadDays    int
+}
+
+// Validate returns the error of the first invalid setting, nil when all are valid
+func (s Settings) Validate() error {
+	if err := Parents(s.ParentA, s.ParentB); err != nil {
+		return err
+	}
+	if err := Roster(append([]string{s.ParentA, s.ParentB}, s.ExtraParents...)); err != nil {
+		return err
+	}
+	if err := DaysOfWeek(s.ParentAUnavailable); err != nil {
+		return err
+	}
+	if err := DaysOfWeek(s.ParentBUnavailable); err != nil {
+		return err
+	}
+	if err := Schedule(s.UpdateFrequency, s.LookAheadDays, s.PastEventThresholdDays, s.StatsOrder); err != nil {
+		return err
+	}
+	for _, days := range []int{s.ScheduledLookAheadDays, s.WebhookLookAheadDays, s.ManualLookAheadDays} {
+		if err := TriggerLookAheadDays(days); err != nil {
+			return err
+		}
+	}
+	if err := MinRestDays(s.MinRestDays); err != nil {
+		return err
+	}
+	return DayWeights(s.DayWeights)
+}
@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belphemur/night-routine/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func validSettings() Settings {
+	return Settings{
+		ParentA:                "Alice",
+		ParentB:                "Bob",
+		ParentAUnavailable:     []string{"Monday"},
+		ParentBUnavailable:     []string{"Friday", "Sunday"},
+		UpdateFrequency:        "weekly",
+		LookAheadDays:          30,
+		PastEventThresholdDays: 5,
+		StatsOrder:             constants.StatsOrderDesc,
+		MinRestDays:            2,
+		DayWeights:             map[time.Weekday]float64{time.Friday: 2},
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	assert.NoError(t, validSettings().Validate())
+
+	tests := []struct {
+		name    string
+		change  func(s *Settings)
+		wantErr error
+	}{
+		{"Missing parent", func(s *Settings) { s.ParentB = "" }, ErrMissingParents},
+		{"Same parents", func(s *Settings) { s.ParentB = "Alice" }, ErrSameParents},
+		{"Extra parent named as a parent", func(s *Settings) { s.ExtraParents = []string{"Grandma", "Bob"} }, ErrSameParents},
+		{"Unnamed extra parent", func(s *Settings) { s.ExtraParents = []string{""} }, ErrMissingParents},
+		{"Too many parents", func(s *Settings) { s.ExtraParents = []string{"C", "D", "E", "F", "G"} }, ErrTooManyParents},
+		{"Abbreviated day", func(s *Settings) { s.ParentAUnavailable = []string{"Mon"} }, ErrInvalidDayOfWeek},
+		{"Lowercase day", func(s *Settings) { s.ParentBUnavailable = []string{"friday"} }, ErrInvalidDayOfWeek},
+		{"Unknown frequency", func(s *Settings) { s.UpdateFrequency = "yearly" }, ErrInvalidUpdateFrequency},
+		{"No look ahead", func(s *Settings) { s.LookAheadDays = 0 }, ErrInvalidLookAheadDays},
+		{"Look ahead over a year", func(s *Settings) { s.LookAheadDays = MaxLookAheadDays + 1 }, ErrInvalidLookAheadDays},
+		{"Negative threshold", func(s *Settings) { s.PastEventThresholdDays = -1 }, ErrInvalidPastEventThresholdDays},
+		{"Threshold over a month", func(s *Settings) { s.PastEventThresholdDays = MaxPastEventThresholdDays + 1 }, ErrInvalidPastEventThresholdDays},
+		{"Unknown stats order", func(s *Settings) { s.StatsOrder = "random" }, ErrInvalidStatsOrder},
+		{"Negative rest days", func(s *Settings) { s.MinRestDays = -1 }, ErrInvalidMinRestDays},
+		{"Negative webhook look ahead", func(s *Settings) { s.WebhookLookAheadDays = -1 }, ErrInvalidLookAheadDays},
+		{"Manual look ahead over a year", func(s *Settings) { s.ManualLookAheadDays = MaxLookAheadDays + 1 }, ErrInvalidLookAheadDays},
+		{"Too many rest days", func(s *Settings) { s.MinRestDays = MaxMinRestDays + 1 }, ErrInvalidMinRestDays},
+		{"Weightless day", func(s *Settings) { s.DayWeights = map[time.Weekday]float64{time.Friday: 0} }, ErrInvalidDayWeight},
+		{"Day weight out of step", func(s *Settings) { s.DayWeights = map[time.Weekday]float64{time.Friday: 1.2} }, ErrInvalidDayWeight},
+		{"Day weight too heavy", func(s *Settings) { s.DayWeights = map[time.Weekday]float64{time.Friday: MaxDayWeight + DayWeightStep} }, ErrInvalidDayWeight},
+		{"Unknown weighted day", func(s *Settings) { s.DayWeights = map[time.Weekday]float64{7: 2} }, ErrInvalidDayWeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := validSettings()
+			tt.change(&settings)
+			assert.ErrorIs(t, settings.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestBoundsAreInclusive(t *testing.T) {
+	assert.NoError(t, LookAheadDays(MinLookAheadDays))
+	assert.NoError(t, LookAheadDays(MaxLookAheadDays))
+	assert.NoError(t, TriggerLookAheadDays(0), "0 uses the look ahead days of the schedule")
+	assert.NoError(t, TriggerLookAheadDays(MaxLookAheadDays))
+	assert.NoError(t, PastEventThresholdDays(0))
+	assert.NoError(t, PastEventThresholdDays(MaxPastEventThresholdDays))
+	assert.NoError(t, MinRestDays(0))
+	assert.NoError(t, MinRestDays(MaxMinRestDays))
+	assert.NoError(t, DayWeights(map[time.Weekday]float64{time.Sunday: MinDayWeight, time.Saturday: MaxDayWeight}))
+	for _, frequency := range UpdateFrequencies {
+		assert.NoError(t, UpdateFrequency(frequency), frequency)
+	}
+	assert.NoError(t, DaysOfWeek(nil), "no unavailable day is valid")
+}
+
+func TestErrorNamesTheValue(t *testing.T) {
+	assert.EqualError(t, UpdateFrequency("yearly"), "invalid update frequency: yearly")
+	assert.EqualError(t, LookAheadDays(366), "invalid look ahead days: 366 is not between 1 and 365")
+	assert.EqualError(t, DaysOfWeek([]string{"Monday", "Funday"}), "invalid day of week: Funday")
+}
@@ -9,7 +9,10 @@ type DisplayAssignment struct {
 	ID             int64
 	Date           time.Time
 	Parent         string // Display name of the assigned caregiver
-	ParentType     string // "ParentA", "ParentB", or "Babysitter"
+	ParentType     string // "ParentA", "ParentB", "OtherParent", or "Babysitter"
 	CaregiverType  string // "parent" or "babysitter"
 	DecisionReason string // e.g. "Total Count", "Alternating", "Override"
+	Tag            string // "sick_kid", "parent_away" or empty
+	Color          string // Color of the parent as #rrggbb, empty for a babysitter
+	Badge          string // Avatar or initial of the parent, empty for a babysitter
 }
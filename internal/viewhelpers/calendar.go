@@ -11,6 +11,7 @@ type CalendarDay struct {
 	DayOfMonth     int
 	IsCurrentMonth bool               // Is this day within the primary month being displayed?
 	Assignment     *DisplayAssignment // Assignment for this day (nil if none)
+	Locked         bool               // Is this day within a locked range, kept by the schedule regeneration?
 }
 
 // CalculateCalendarRange determines the start and end dates for a calendar view